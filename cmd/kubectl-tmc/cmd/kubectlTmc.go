@@ -0,0 +1,95 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	goflags "flag"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/component-base/version"
+	"k8s.io/klog/v2"
+
+	approvecmd "github.com/kcp-dev/kcp/pkg/cliplugins/approve/cmd"
+	approvesynctargetcmd "github.com/kcp-dev/kcp/pkg/cliplugins/approvesynctarget/cmd"
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	comparedecisionscmd "github.com/kcp-dev/kcp/pkg/cliplugins/comparedecisions/cmd"
+	configbundlecmd "github.com/kcp-dev/kcp/pkg/cliplugins/configbundle/cmd"
+	decisionscmd "github.com/kcp-dev/kcp/pkg/cliplugins/decisions/cmd"
+	deprecationscmd "github.com/kcp-dev/kcp/pkg/cliplugins/deprecations/cmd"
+	devcmd "github.com/kcp-dev/kcp/pkg/cliplugins/dev/cmd"
+	draincmd "github.com/kcp-dev/kcp/pkg/cliplugins/drain/cmd"
+	dumpsyncercmd "github.com/kcp-dev/kcp/pkg/cliplugins/dumpsyncer/cmd"
+	featurescmd "github.com/kcp-dev/kcp/pkg/cliplugins/features/cmd"
+	placementscmd "github.com/kcp-dev/kcp/pkg/cliplugins/placements/cmd"
+	simulatefailurecmd "github.com/kcp-dev/kcp/pkg/cliplugins/simulatefailure/cmd"
+	syncerrbaccmd "github.com/kcp-dev/kcp/pkg/cliplugins/syncerrbac/cmd"
+	synctargetscmd "github.com/kcp-dev/kcp/pkg/cliplugins/synctargets/cmd"
+	workloadsyncercmd "github.com/kcp-dev/kcp/pkg/cliplugins/workloadsyncer/cmd"
+	"github.com/kcp-dev/kcp/sdk/cmd/help"
+)
+
+func KubectlTmcCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:   "tmc",
+		Short: "kubectl plugin for TMC",
+		Long: help.Doc(`
+			TMC (transparent multi-cluster) syncs workloads placed in kcp onto
+			physical clusters.
+
+			This command provides TMC specific sub-commands for kubectl.
+		`),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	// setup klog
+	fs := goflags.NewFlagSet("klog", goflags.PanicOnError)
+	klog.InitFlags(fs)
+	root.PersistentFlags().AddGoFlagSet(fs)
+
+	if v := version.Get().String(); len(v) == 0 {
+		root.Version = "<unknown>"
+	} else {
+		root.Version = v
+	}
+
+	streams := base.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
+
+	root.AddCommand(approvecmd.New(streams))
+	root.AddCommand(approvesynctargetcmd.New(streams))
+	root.AddCommand(comparedecisionscmd.New(streams))
+	root.AddCommand(decisionscmd.New(streams))
+	root.AddCommand(deprecationscmd.New(streams))
+	root.AddCommand(devcmd.New(streams))
+	root.AddCommand(draincmd.NewCordon(streams))
+	root.AddCommand(draincmd.NewUncordon(streams))
+	root.AddCommand(draincmd.NewDrain(streams))
+	root.AddCommand(dumpsyncercmd.New(streams))
+	root.AddCommand(featurescmd.New(streams))
+	root.AddCommand(placementscmd.New(streams))
+	root.AddCommand(configbundlecmd.NewExport(streams))
+	root.AddCommand(configbundlecmd.NewImport(streams))
+	root.AddCommand(simulatefailurecmd.New(streams))
+	root.AddCommand(simulatefailurecmd.NewSimulate(streams))
+	root.AddCommand(syncerrbaccmd.New(streams))
+	root.AddCommand(synctargetscmd.New(streams))
+	root.AddCommand(workloadsyncercmd.New(streams))
+
+	return root
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/client-go/rest"
+	logsapiv1 "k8s.io/component-base/logs/api/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/cmd/syncer/options"
+	kcpfeatures "github.com/kcp-dev/kcp/pkg/features"
+	"github.com/kcp-dev/kcp/pkg/syncer"
+	"github.com/kcp-dev/kcp/pkg/syncer/audit"
+	"github.com/kcp-dev/kcp/pkg/syncer/diagnostics"
+	"github.com/kcp-dev/kcp/pkg/syncer/multitarget"
+	"github.com/kcp-dev/kcp/pkg/syncer/relocation"
+	kcpclientset "github.com/kcp-dev/kcp/sdk/client/clientset/versioned/cluster"
+	"github.com/kcp-dev/kcp/sdk/cmd/help"
+)
+
+func main() {
+	opts := options.NewOptions()
+	// Default to -v=2
+	opts.Logs.Verbosity = logsapiv1.VerbosityLevel(2)
+
+	cmd := &cobra.Command{
+		Use:   "syncer",
+		Short: "Sync the workloads placed on SyncTargets to their physical cluster",
+		Long: help.Doc(`
+					Sync the workloads placed on SyncTargets to their physical cluster
+					The syncer runs on the physical cluster of its SyncTargets, usually as
+					generated by kubectl tmc workload-syncer generate. It renews the heartbeat
+					of every SyncTarget and syncs the objects of the configured resources from
+					the workspace of the SyncTarget, through the syncer virtual workspace, to
+					the physical cluster.
+				`),
+		Example:      "syncer --from-kubeconfig=/etc/kcp/kubeconfig --sync-targets=root:org:edge-1",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := logsapiv1.ValidateAndApply(opts.Logs, kcpfeatures.DefaultFeatureGate); err != nil {
+				return err
+			}
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+			return run(genericapiserver.SetupSignalContext(), opts)
+		},
+	}
+
+	opts.AddFlags(cmd.Flags())
+	help.FitTerminal(cmd.OutOrStdout())
+
+	if err := cmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, o *options.Options) error {
+	upstream, err := o.UpstreamConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load --from-kubeconfig: %w", err)
+	}
+	downstream, err := o.DownstreamConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load the physical cluster config: %w", err)
+	}
+	targets, err := o.MultiTarget.Targets()
+	if err != nil {
+		return err
+	}
+
+	// The diagnostics are shared by the syncers of all SyncTargets.
+	server := diagnostics.NewServer(o.Diagnostics, o.Syncer)
+	server.InstallErrorHandler()
+	o.Syncer.Diagnostics = server
+
+	// The mutation audit log, too, records the events of all SyncTargets,
+	// see audit.Log.ForSyncTarget.
+	auditLog, err := audit.NewLog(o.Audit, "")
+	if err != nil {
+		return fmt.Errorf("failed to open the mutation audit log: %w", err)
+	}
+	defer auditLog.Close()
+	go auditLog.Run(ctx)
+	if auditLog != nil {
+		server.SetAuditQuery(auditLog.Serve)
+	}
+	o.Syncer.AuditLog = auditLog
+
+	// Moves of the workspaces are followed for all SyncTargets, too, so
+	// that restarted syncers find their moved workspace, see package
+	// relocation.
+	kcpURL, err := url.Parse(upstream.Host)
+	if err != nil {
+		return err
+	}
+	kcpURL.Path = ""
+	kcpConfig := rest.CopyConfig(upstream)
+	kcpConfig.Host = kcpURL.String()
+	kcpClient, err := kcpclientset.NewForConfig(kcpConfig)
+	if err != nil {
+		return err
+	}
+	o.Syncer.Relocations = relocation.NewHandler(kcpClient)
+	go func() {
+		if err := server.Run(ctx); err != nil {
+			klog.FromContext(ctx).Error(err, "failed to serve syncer diagnostics")
+		}
+	}()
+
+	klog.FromContext(ctx).Info("Starting syncer", "syncTargets", o.MultiTarget.SyncTargets)
+	supervisor := multitarget.NewSupervisor(o.MultiTarget, upstream, downstream, func(ctx context.Context, target multitarget.Target, upstream, downstream *rest.Config) error {
+		return syncer.Run(ctx, o.Syncer, target, upstream, downstream)
+	})
+	supervisor.Run(ctx, targets)
+	return nil
+}
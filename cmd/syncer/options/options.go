@@ -0,0 +1,117 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/component-base/logs"
+	logsapiv1 "k8s.io/component-base/logs/api/v1"
+
+	kcpfeatures "github.com/kcp-dev/kcp/pkg/features"
+	"github.com/kcp-dev/kcp/pkg/syncer"
+	"github.com/kcp-dev/kcp/pkg/syncer/audit"
+	"github.com/kcp-dev/kcp/pkg/syncer/diagnostics"
+	"github.com/kcp-dev/kcp/pkg/syncer/multitarget"
+)
+
+type Options struct {
+	FromKubeconfig string
+	FromContext    string
+	ToKubeconfig   string
+	ToContext      string
+
+	Logs *logs.Options
+
+	MultiTarget *multitarget.Options
+	Syncer      *syncer.Options
+	Diagnostics *diagnostics.Options
+	Audit       *audit.Options
+}
+
+func NewOptions() *Options {
+	return &Options{
+		Logs:        logs.NewOptions(),
+		MultiTarget: multitarget.NewOptions(),
+		Syncer:      syncer.NewOptions(),
+		Diagnostics: diagnostics.NewOptions(),
+		Audit:       audit.NewOptions(),
+	}
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.FromKubeconfig, "from-kubeconfig", o.FromKubeconfig, "Kubeconfig of the syncer for kcp, pointing to the workspace of its SyncTargets.")
+	fs.StringVar(&o.FromContext, "from-context", o.FromContext, "Context of --from-kubeconfig to use.")
+	fs.StringVar(&o.ToKubeconfig, "to-kubeconfig", o.ToKubeconfig, "Kubeconfig of the physical cluster. The in-cluster configuration is used without it.")
+	fs.StringVar(&o.ToContext, "to-context", o.ToContext, "Context of --to-kubeconfig to use.")
+	fs.Var(kcpfeatures.NewFlagValue(), "feature-gates", ""+
+		"A set of key=value pairs that describe feature gates for alpha/experimental features. "+
+		"Options are:\n"+strings.Join(kcpfeatures.KnownFeatures(), "\n"))
+
+	logsapiv1.AddFlags(o.Logs, fs)
+	o.MultiTarget.AddFlags(fs)
+	o.Syncer.AddFlags(fs)
+	o.Diagnostics.AddFlags(fs)
+	o.Audit.AddFlags(fs)
+}
+
+func (o *Options) Validate() error {
+	var errs []error
+	if o.FromKubeconfig == "" {
+		errs = append(errs, fmt.Errorf("--from-kubeconfig is required"))
+	}
+	if err := o.MultiTarget.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := o.Syncer.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := o.Diagnostics.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := o.Audit.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// UpstreamConfig returns the config of the syncer for kcp.
+func (o *Options) UpstreamConfig() (*rest.Config, error) {
+	return loadConfig(o.FromKubeconfig, o.FromContext)
+}
+
+// DownstreamConfig returns the config of the syncer for the physical
+// cluster.
+func (o *Options) DownstreamConfig() (*rest.Config, error) {
+	if o.ToKubeconfig == "" {
+		return rest.InClusterConfig()
+	}
+	return loadConfig(o.ToKubeconfig, o.ToContext)
+}
+
+func loadConfig(kubeconfig, context string) (*rest.Config, error) {
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig},
+		&clientcmd.ConfigOverrides{CurrentContext: context},
+	).ClientConfig()
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kcp-dev/kcp/pkg/placement/scenario"
+	"github.com/kcp-dev/kcp/sdk/cmd/help"
+)
+
+func main() {
+	var verbose bool
+	cmd := &cobra.Command{
+		Use:   "tmc-scenarios PATH...",
+		Short: "Run placement scenarios and check the expected decisions",
+		Long: help.Doc(`
+					Run placement scenarios and check the expected decisions
+					Each YAML file describes SyncTargets, PlacementPolicies and workloads
+					with the placement decisions expected for them. The workloads are placed
+					with the placement engine of the TMC controllers. Directories are
+					searched for .yaml and .yml files recursively.
+				`),
+		Example:      "tmc-scenarios ./placement-scenarios",
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			files, err := scenarioFiles(args)
+			if err != nil {
+				return err
+			}
+			if failed := run(cmd.OutOrStdout(), files, verbose); failed > 0 {
+				return fmt.Errorf("%d workloads not placed as expected", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", verbose, "Print passing workloads")
+	help.FitTerminal(cmd.OutOrStdout())
+
+	if err := cmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run runs the scenarios in files and returns the number of failed
+// workloads, counting invalid scenarios as one.
+func run(out io.Writer, files []string, verbose bool) int {
+	passed, failed := 0, 0
+	for _, file := range files {
+		s, err := scenario.LoadFile(file)
+		if err != nil {
+			fmt.Fprintf(out, "FAIL %v\n", err)
+			failed++
+			continue
+		}
+		for _, w := range s.Run().Workloads {
+			if w.Passed() {
+				passed++
+				if verbose {
+					fmt.Fprintf(out, "PASS %s: %s: %s\n", file, s.Name, w.Name)
+				}
+				continue
+			}
+			failed++
+			fmt.Fprintf(out, "FAIL %s: %s: %s\n", file, s.Name, w.Name)
+			for _, f := range w.Failures {
+				fmt.Fprintf(out, "    %s\n", f)
+			}
+		}
+	}
+	fmt.Fprintf(out, "%d passed, %d failed\n", passed, failed)
+	return failed
+}
+
+func scenarioFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			if ext := strings.ToLower(filepath.Ext(file)); file == path || ext == ".yaml" || ext == ".yml" {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	genericapiserver "k8s.io/apiserver/pkg/server"
+
+	"github.com/kcp-dev/kcp/pkg/tmc/webhook"
+	"github.com/kcp-dev/kcp/sdk/cmd/help"
+)
+
+func main() {
+	options := webhook.Options{BindAddress: ":9443"}
+	cmd := &cobra.Command{
+		Use:   "tmc-webhook",
+		Short: "Serve the conversion and defaulting webhooks of the TMC API groups",
+		Long: help.Doc(`
+					Serve the conversion and defaulting webhooks of the TMC API groups
+					The CRDs of tmc.kcp.io, placement.kcp.io and workload.kcp.io reference
+					the conversion webhook at /convert once they serve more than one version,
+					and a MutatingWebhookConfiguration references the defaulting webhook at
+					/default.
+				`),
+		Example:      "tmc-webhook --tls-cert-file=tls.crt --tls-private-key-file=tls.key",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if options.CertFile == "" || options.KeyFile == "" {
+				return fmt.Errorf("--tls-cert-file and --tls-private-key-file are required")
+			}
+			registry, err := webhook.NewTMCRegistry()
+			if err != nil {
+				return err
+			}
+			return webhook.Run(genericapiserver.SetupSignalContext(), registry, options)
+		},
+	}
+
+	cmd.Flags().StringVar(&options.BindAddress, "bind-address", options.BindAddress, "Address to serve the webhooks on")
+	cmd.Flags().StringVar(&options.CertFile, "tls-cert-file", options.CertFile, "File containing the serving certificate")
+	cmd.Flags().StringVar(&options.KeyFile, "tls-private-key-file", options.KeyFile, "File containing the serving certificate key")
+	help.FitTerminal(cmd.OutOrStdout())
+
+	if err := cmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -37,6 +37,12 @@ REPO_ROOT=$(cd "$(dirname "${BASH_SOURCE[0]}")/.." && pwd)
         output:crd:artifacts:config="${REPO_ROOT}"/config/crds
 )
 
+# The TMC API groups are not system CRDs, they are bootstrapped from
+# config/tmc when the TMCAPIs feature gate is enabled.
+rm -rf "${REPO_ROOT}"/config/tmc/crds
+mkdir -p "${REPO_ROOT}"/config/tmc/crds
+mv "${REPO_ROOT}"/config/crds/{tmc,placement,workload}.kcp.io_*.yaml "${REPO_ROOT}"/config/tmc/crds/
+
 for CRD in "${REPO_ROOT}"/config/crds/*.yaml; do
     if [ -f "${CRD}-patch" ]; then
         echo "Applying ${CRD}"
@@ -50,6 +56,10 @@ done
   --ignore-export-schemas cachedobjects.cache.kcp.io
 )
 
+(
+  ${KCP_APIGEN_GEN} --input-dir "${REPO_ROOT}"/config/tmc/crds --output-dir "${REPO_ROOT}"/config/tmc
+)
+
 
 # Tests CRDs
 
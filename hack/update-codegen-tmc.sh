@@ -0,0 +1,42 @@
+#!/usr/bin/env bash
+
+# Copyright 2025 The KCP Authors.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Generates deepcopy functions for the TMC API groups in sdk/apis. These
+# groups have no generated clients; controllers use dynamic clients and
+# convert via the scheme.
+
+set -o errexit
+set -o nounset
+set -o pipefail
+set -o xtrace
+
+export GOPATH=$(go env GOPATH)
+
+SCRIPT_ROOT=$(cd "$(dirname "${BASH_SOURCE[0]}")/.." && pwd)
+BOILERPLATE_HEADER="${SCRIPT_ROOT}/hack/boilerplate/boilerplate.generatego.txt"
+CODEGEN_PKG=${CODEGEN_PKG:-$(cd "${SCRIPT_ROOT}"; go list -f '{{.Dir}}' -m k8s.io/code-generator)}
+
+go install "${CODEGEN_PKG}"/cmd/deepcopy-gen
+
+cd "${SCRIPT_ROOT}"
+"$GOPATH"/bin/deepcopy-gen \
+  --go-header-file "${BOILERPLATE_HEADER}" \
+  --output-file zz_generated.deepcopy.go \
+  $(find ./sdk/apis/tmc ./sdk/apis/placement ./sdk/apis/workload -name doc.go -path '*/v1*' -exec dirname {} \; | sort | sed 's|^\./|github.com/kcp-dev/kcp/|')
+
+# The valid condition types and phases of the TMC kinds, validated on status
+# writes, are generated from the constants of the same packages.
+go run ./pkg/statusvalues/gen
@@ -0,0 +1,324 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multiclusterresourcequota
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/admission"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	kcpdynamicinformer "github.com/kcp-dev/client-go/dynamic/dynamicinformer"
+	kcpinformers "github.com/kcp-dev/client-go/informers"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	kcpinitializers "github.com/kcp-dev/kcp/pkg/admission/initializers"
+	"github.com/kcp-dev/kcp/pkg/placement/quota"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/resourcequota"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// PluginName is the name used to identify this admission webhook.
+const PluginName = "tmc.kcp.io/MultiClusterResourceQuota"
+
+const resyncPeriod = 10 * time.Hour
+
+// scalableResources are the resources with a scale subresource whose pods
+// are counted, see quota.WithReplicas.
+var scalableResources = []schema.GroupVersionResource{
+	{Version: "v1", Resource: "replicationcontrollers"},
+	{Group: "apps", Version: "v1", Resource: "deployments"},
+	{Group: "apps", Version: "v1", Resource: "replicasets"},
+	{Group: "apps", Version: "v1", Resource: "statefulsets"},
+}
+
+// Register registers the MultiClusterResourceQuota admission webhook.
+func Register(plugins *admission.Plugins) {
+	plugins.Register(PluginName,
+		func(_ io.Reader) (admission.Interface, error) {
+			return &MultiClusterResourceQuotaAdmission{
+				Handler: admission.NewHandler(admission.Create, admission.Update),
+			}, nil
+		})
+}
+
+// MultiClusterResourceQuotaAdmission rejects creations and updates of placed
+// workloads, e.g. scaling them up, that would exceed a MultiClusterResourceQuota
+// of their workspace on the SyncTargets they are placed on. Placing workloads
+// onto more SyncTargets is checked by the placement controller. Quotas,
+// WorkloadDistributions and scaled workloads are read from informers, and
+// requests are rejected until quotas and WorkloadDistributions are synced.
+type MultiClusterResourceQuotaAdmission struct {
+	*admission.Handler
+
+	serverDone       <-chan struct{}
+	informers        kcpdynamicinformer.DynamicSharedInformerFactory
+	informersStarter sync.Once
+
+	listQuotas        func(ctx context.Context, clusterName logicalcluster.Name) ([]*tmcv1alpha1.MultiClusterResourceQuota, error)
+	listDistributions func(ctx context.Context, clusterName logicalcluster.Name, namespace string) ([]*workloadv1alpha1.WorkloadDistribution, error)
+	getWorkload       func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error)
+}
+
+// Ensure that the required admission interfaces are implemented.
+var (
+	_ = admission.ValidationInterface(&MultiClusterResourceQuotaAdmission{})
+	_ = admission.InitializationValidator(&MultiClusterResourceQuotaAdmission{})
+	_ = kcpinitializers.WantsDynamicClusterClient(&MultiClusterResourceQuotaAdmission{})
+	_ = kcpinitializers.WantsServerShutdownChannel(&MultiClusterResourceQuotaAdmission{})
+)
+
+// Validate ensures that the resources a created or updated workload uses
+// more on its SyncTargets fit the MultiClusterResourceQuotas of the
+// workspace.
+func (p *MultiClusterResourceQuotaAdmission) Validate(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) error {
+	if a.GetSubresource() != "" && a.GetSubresource() != "scale" {
+		return nil
+	}
+	if a.GetSubresource() == "" && !quota.HasPods(a.GetKind().GroupKind()) {
+		return nil
+	}
+	clusterName, err := genericapirequest.ClusterNameFrom(ctx)
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	p.informersStarter.Do(func() {
+		if p.informers != nil {
+			p.informers.Start(p.serverDone)
+		}
+	})
+	if !p.WaitForReady() {
+		return admission.NewForbidden(a, fmt.Errorf("not yet ready to handle request"))
+	}
+
+	quotas, err := p.listQuotas(ctx, clusterName)
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	if len(quotas) == 0 {
+		return nil
+	}
+
+	oldWorkload, workload, err := p.workloads(ctx, clusterName, a)
+	if err != nil || workload == nil {
+		return err
+	}
+	gr := a.GetResource().GroupResource()
+	oldUsed := corev1.ResourceList{}
+	if oldWorkload != nil {
+		if oldUsed, err = quota.Usage(oldWorkload, gr); err != nil {
+			return apierrors.NewInternalError(err)
+		}
+	}
+	used, err := quota.Usage(workload, gr)
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	increase := quotav1.Subtract(used, oldUsed)
+	if !grows(increase) {
+		return nil
+	}
+
+	distributions, err := p.listDistributions(ctx, clusterName, a.GetNamespace())
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	targets := int64(0)
+	for _, d := range distributions {
+		if references(d, workload) {
+			targets += int64(len(d.Status.Targets))
+		}
+	}
+	if targets == 0 {
+		return nil
+	}
+
+	if message := quota.Check(quotas, quota.Multiply(increase, targets)); message != "" {
+		return admission.NewForbidden(a, fmt.Errorf("%s %s/%s is placed on %d SyncTargets: %s", workload.GetKind(), a.GetNamespace(), a.GetName(), targets, message))
+	}
+	return nil
+}
+
+// workloads returns the workload before and after the request, the former
+// nil on creation. Updates of the scale subresource are applied to the
+// workload. It returns nil if the workload does not run pods.
+func (p *MultiClusterResourceQuotaAdmission) workloads(ctx context.Context, clusterName logicalcluster.Name, a admission.Attributes) (*unstructured.Unstructured, *unstructured.Unstructured, error) {
+	obj, err := toUnstructured(a.GetObject())
+	if err != nil {
+		return nil, nil, err
+	}
+	// Typed objects may come without their kind.
+	if a.GetOperation() == admission.Create {
+		if a.GetSubresource() != "" {
+			return nil, nil, nil
+		}
+		obj.SetGroupVersionKind(a.GetKind())
+		return nil, obj, nil
+	}
+	oldObj, err := toUnstructured(a.GetOldObject())
+	if err != nil {
+		return nil, nil, err
+	}
+	if a.GetSubresource() == "" {
+		oldObj.SetGroupVersionKind(a.GetKind())
+		obj.SetGroupVersionKind(a.GetKind())
+		return oldObj, obj, nil
+	}
+
+	workload, err := p.getWorkload(ctx, clusterName, a.GetResource(), a.GetNamespace(), a.GetName())
+	if apierrors.IsNotFound(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, apierrors.NewInternalError(err)
+	}
+	oldReplicas, _, _ := unstructured.NestedInt64(oldObj.Object, "spec", "replicas")
+	replicas, _, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	oldWorkload, ok, err := quota.WithReplicas(workload, oldReplicas)
+	if err != nil || !ok {
+		return nil, nil, err
+	}
+	workload, _, err = quota.WithReplicas(workload, replicas)
+	if err != nil {
+		return nil, nil, err
+	}
+	return oldWorkload, workload, nil
+}
+
+func toUnstructured(obj runtime.Object) (*unstructured.Unstructured, error) {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return u.DeepCopy(), nil
+	}
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %T to unstructured: %w", obj, err)
+	}
+	return &unstructured.Unstructured{Object: raw}, nil
+}
+
+// grows returns whether any resource increases.
+func grows(increase corev1.ResourceList) bool {
+	for _, q := range increase {
+		if q.Sign() > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// references returns whether the distribution distributes the workload.
+func references(d *workloadv1alpha1.WorkloadDistribution, workload *unstructured.Unstructured) bool {
+	ref := d.Spec.WorkloadRef
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return false
+	}
+	return ref.Name == workload.GetName() && ref.Kind == workload.GetKind() && gv.Group == workload.GroupVersionKind().Group
+}
+
+// ValidateInitialization ensures the required injected fields are set.
+func (p *MultiClusterResourceQuotaAdmission) ValidateInitialization() error {
+	if p.listQuotas == nil {
+		return fmt.Errorf(PluginName + " plugin needs a dynamic cluster client")
+	}
+	if p.serverDone == nil {
+		return fmt.Errorf(PluginName + " plugin needs a server shutdown channel")
+	}
+	return nil
+}
+
+func (p *MultiClusterResourceQuotaAdmission) SetDynamicClusterClient(client kcpdynamic.ClusterInterface) {
+	p.informers = kcpdynamicinformer.NewDynamicSharedInformerFactory(client, resyncPeriod)
+	quotas := p.informers.ForResource(resourcequota.MultiClusterResourceQuotasGVR)
+	distributions := p.informers.ForResource(resourcequota.WorkloadDistributionsGVR)
+	workloads := make(map[schema.GroupVersionResource]kcpinformers.GenericClusterInformer, len(scalableResources))
+	for _, gvr := range scalableResources {
+		workloads[gvr] = p.informers.ForResource(gvr)
+	}
+
+	p.SetReadyFunc(func() bool {
+		return quotas.Informer().HasSynced() && distributions.Informer().HasSynced()
+	})
+	p.listQuotas = func(_ context.Context, clusterName logicalcluster.Name) ([]*tmcv1alpha1.MultiClusterResourceQuota, error) {
+		objs, err := quotas.Lister().ByCluster(clusterName).List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		return fromObjects[tmcv1alpha1.MultiClusterResourceQuota](objs)
+	}
+	p.listDistributions = func(_ context.Context, clusterName logicalcluster.Name, namespace string) ([]*workloadv1alpha1.WorkloadDistribution, error) {
+		objs, err := distributions.Lister().ByCluster(clusterName).ByNamespace(namespace).List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		return fromObjects[workloadv1alpha1.WorkloadDistribution](objs)
+	}
+	p.getWorkload = func(_ context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+		inf, found := workloads[gvr]
+		if !found {
+			return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+		}
+		// Scaled workloads are only read for quotas, so they are not waited
+		// for in the ready func.
+		if !inf.Informer().HasSynced() {
+			return nil, fmt.Errorf("%s are not synced yet", gvr.GroupResource())
+		}
+		obj, err := inf.Lister().ByCluster(clusterName).ByNamespace(namespace).Get(name)
+		if err != nil {
+			return nil, err
+		}
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return nil, fmt.Errorf("unexpected object type %T", obj)
+		}
+		return u.DeepCopy(), nil
+	}
+}
+
+func (p *MultiClusterResourceQuotaAdmission) SetServerShutdownChannel(ch <-chan struct{}) {
+	p.serverDone = ch
+}
+
+// fromObjects converts unstructured objects of an informer.
+func fromObjects[T any](objs []runtime.Object) ([]*T, error) {
+	typed := make([]*T, 0, len(objs))
+	for _, obj := range objs {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return nil, fmt.Errorf("unexpected object type %T", obj)
+		}
+		t := new(T)
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, t); err != nil {
+			return nil, err
+		}
+		typed = append(typed, t)
+	}
+	return typed, nil
+}
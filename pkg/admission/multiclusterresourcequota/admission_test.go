@@ -0,0 +1,214 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multiclusterresourcequota
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/rest"
+
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var deploymentsGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+func deployment(replicas int64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"namespace": "default", "name": "web"},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+			"template": map[string]interface{}{"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "web", "resources": map[string]interface{}{"requests": map[string]interface{}{"cpu": "500m"}}},
+				},
+			}},
+		},
+	}}
+}
+
+func scale(replicas int64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "autoscaling/v1",
+		"kind":       "Scale",
+		"metadata":   map[string]interface{}{"namespace": "default", "name": "web"},
+		"spec":       map[string]interface{}{"replicas": replicas},
+	}}
+}
+
+func updateAttr(oldObj, obj *unstructured.Unstructured, subresource string) admission.Attributes {
+	return admission.NewAttributesRecord(
+		obj,
+		oldObj,
+		obj.GroupVersionKind(),
+		"default",
+		"web",
+		deploymentsGVR,
+		subresource,
+		admission.Update,
+		&metav1.UpdateOptions{},
+		false,
+		&user.DefaultInfo{},
+	)
+}
+
+func createAttr(obj *unstructured.Unstructured) admission.Attributes {
+	return admission.NewAttributesRecord(
+		obj,
+		nil,
+		obj.GroupVersionKind(),
+		"default",
+		"web",
+		deploymentsGVR,
+		"",
+		admission.Create,
+		&metav1.CreateOptions{},
+		false,
+		&user.DefaultInfo{},
+	)
+}
+
+func TestValidate(t *testing.T) {
+	placed := &workloadv1alpha1.WorkloadDistribution{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec: workloadv1alpha1.WorkloadDistributionSpec{
+			WorkloadRef: workloadv1alpha1.WorkloadReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+		},
+		Status: workloadv1alpha1.WorkloadDistributionStatus{
+			Targets: []workloadv1alpha1.TargetPlacement{{SyncTarget: "eu-1"}, {SyncTarget: "us-1"}},
+		},
+	}
+	cpuQuota := func(hard, used string) *tmcv1alpha1.MultiClusterResourceQuota {
+		return &tmcv1alpha1.MultiClusterResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "team"},
+			Spec:       tmcv1alpha1.MultiClusterResourceQuotaSpec{Hard: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(hard)}},
+			Status:     tmcv1alpha1.MultiClusterResourceQuotaStatus{Used: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(used)}},
+		}
+	}
+
+	tests := map[string]struct {
+		attr          admission.Attributes
+		quotas        []*tmcv1alpha1.MultiClusterResourceQuota
+		distributions []*workloadv1alpha1.WorkloadDistribution
+		wantErr       string
+	}{
+		"scale up within the quota": {
+			attr:          updateAttr(deployment(2), deployment(4), ""),
+			quotas:        []*tmcv1alpha1.MultiClusterResourceQuota{cpuQuota("5", "3")},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{placed},
+		},
+		"scale up beyond the quota": {
+			attr:          updateAttr(deployment(2), deployment(4), ""),
+			quotas:        []*tmcv1alpha1.MultiClusterResourceQuota{cpuQuota("4", "3")},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{placed},
+			wantErr:       `Deployment default/web is placed on 2 SyncTargets: MultiClusterResourceQuota "team" would be exceeded: cpu: 5 of at most 4`,
+		},
+		"scale subresource beyond the quota": {
+			attr:          updateAttr(scale(2), scale(4), "scale"),
+			quotas:        []*tmcv1alpha1.MultiClusterResourceQuota{cpuQuota("4", "3")},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{placed},
+			wantErr:       `MultiClusterResourceQuota "team" would be exceeded: cpu: 5 of at most 4`,
+		},
+		"scale down beyond the quota": {
+			attr:          updateAttr(deployment(4), deployment(2), ""),
+			quotas:        []*tmcv1alpha1.MultiClusterResourceQuota{cpuQuota("4", "6")},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{placed},
+		},
+		"create within the quota": {
+			attr:          createAttr(deployment(2)),
+			quotas:        []*tmcv1alpha1.MultiClusterResourceQuota{cpuQuota("5", "3")},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{placed},
+		},
+		"create beyond the quota": {
+			attr:          createAttr(deployment(2)),
+			quotas:        []*tmcv1alpha1.MultiClusterResourceQuota{cpuQuota("4", "3")},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{placed},
+			wantErr:       `Deployment default/web is placed on 2 SyncTargets: MultiClusterResourceQuota "team" would be exceeded: cpu: 5 of at most 4`,
+		},
+		"without quotas": {
+			attr:          updateAttr(deployment(2), deployment(4), ""),
+			distributions: []*workloadv1alpha1.WorkloadDistribution{placed},
+		},
+		"not placed": {
+			attr:   updateAttr(deployment(2), deployment(4), ""),
+			quotas: []*tmcv1alpha1.MultiClusterResourceQuota{cpuQuota("4", "3")},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := &MultiClusterResourceQuotaAdmission{
+				Handler: admission.NewHandler(admission.Create, admission.Update),
+				listQuotas: func(_ context.Context, _ logicalcluster.Name) ([]*tmcv1alpha1.MultiClusterResourceQuota, error) {
+					return tc.quotas, nil
+				},
+				listDistributions: func(_ context.Context, _ logicalcluster.Name, _ string) ([]*workloadv1alpha1.WorkloadDistribution, error) {
+					return tc.distributions, nil
+				},
+				getWorkload: func(_ context.Context, _ logicalcluster.Name, _ schema.GroupVersionResource, _, _ string) (*unstructured.Unstructured, error) {
+					return deployment(3), nil
+				},
+			}
+			ctx := request.WithCluster(context.Background(), request.Cluster{Name: "root:org"})
+			err := p.Validate(ctx, tc.attr, nil)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+// TestValidateNotReady runs the informers of the plugin against a server
+// that does not serve the TMC APIs, so that they never sync.
+func TestValidateNotReady(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+	client, err := kcpdynamic.NewForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+	stop := make(chan struct{})
+	t.Cleanup(func() { close(stop) })
+
+	p := &MultiClusterResourceQuotaAdmission{Handler: admission.NewHandler(admission.Create, admission.Update)}
+	p.SetDynamicClusterClient(client)
+	p.SetServerShutdownChannel(stop)
+	require.NoError(t, p.ValidateInitialization())
+
+	ctx := request.WithCluster(context.Background(), request.Cluster{Name: "root:org"})
+	err = p.Validate(ctx, updateAttr(deployment(2), deployment(4), ""), nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not yet ready to handle request")
+}
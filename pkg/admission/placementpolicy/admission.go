@@ -0,0 +1,177 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placementpolicy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/warning"
+
+	"github.com/kcp-dev/kcp/pkg/placement/validation"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+)
+
+// PluginName is the name used to identify this admission webhook.
+const PluginName = "placement.kcp.io/PlacementPolicy"
+
+// Register registers the PlacementPolicy admission webhook.
+func Register(plugins *admission.Plugins) {
+	plugins.Register(PluginName,
+		func(_ io.Reader) (admission.Interface, error) {
+			return NewPlacementPolicyAdmission(), nil
+		})
+}
+
+// PlacementPolicyAdmission rejects PlacementPolicies, and rules of
+// WorkloadPlacementAdvanced composite policies, the placement engine cannot
+// apply, as validated by package validation, and warns about settings that
+// do not apply or constraints that are expensive to evaluate. It
+// records who approved a rollout and when, and rejects approvals of other
+// revisions than the one rolled out.
+type PlacementPolicyAdmission struct {
+	*admission.Handler
+
+	now func() time.Time
+}
+
+// NewPlacementPolicyAdmission constructs a new PlacementPolicyAdmission admission plugin.
+func NewPlacementPolicyAdmission() *PlacementPolicyAdmission {
+	return &PlacementPolicyAdmission{
+		Handler: admission.NewHandler(admission.Create, admission.Update),
+		now:     time.Now,
+	}
+}
+
+// Ensure that the required admission interfaces are implemented.
+var (
+	_ = admission.MutationInterface(&PlacementPolicyAdmission{})
+	_ = admission.ValidationInterface(&PlacementPolicyAdmission{})
+)
+
+// Admit sets the approver and time of new rollout approvals, and keeps
+// those of existing approvals, so they cannot be forged.
+func (p *PlacementPolicyAdmission) Admit(_ context.Context, a admission.Attributes, _ admission.ObjectInterfaces) error {
+	if a.GetResource().GroupResource() != placementv1alpha1.Resource("placementpolicies") || a.GetSubresource() != "status" || a.GetOperation() != admission.Update {
+		return nil
+	}
+
+	u, ok := a.GetObject().(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unexpected type %T", a.GetObject())
+	}
+	approval, err := approvalOf(u)
+	if err != nil || approval == nil {
+		return err
+	}
+	var oldApproval *placementv1alpha1.RolloutApproval
+	if old, ok := a.GetOldObject().(*unstructured.Unstructured); ok {
+		if oldApproval, err = approvalOf(old); err != nil {
+			return err
+		}
+	}
+
+	if oldApproval != nil && oldApproval.Revision == approval.Revision && oldApproval.Message == approval.Message {
+		approval.ApprovedBy, approval.ApprovedAt = oldApproval.ApprovedBy, oldApproval.ApprovedAt
+	} else {
+		approval.ApprovedBy = a.GetUserInfo().GetName()
+		approval.ApprovedAt = &metav1.Time{Time: p.now()}
+	}
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(approval)
+	if err != nil {
+		return err
+	}
+	return unstructured.SetNestedMap(u.Object, raw, "status", "rollout", "approval")
+}
+
+func approvalOf(u *unstructured.Unstructured) (*placementv1alpha1.RolloutApproval, error) {
+	raw, found, err := unstructured.NestedMap(u.Object, "status", "rollout", "approval")
+	if err != nil || !found {
+		return nil, err
+	}
+	approval := &placementv1alpha1.RolloutApproval{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, approval); err != nil {
+		return nil, fmt.Errorf("failed to convert rollout approval: %w", err)
+	}
+	return approval, nil
+}
+
+// Validate ensures that the PlacementPolicy or WorkloadPlacementAdvanced is valid.
+func (p *PlacementPolicyAdmission) Validate(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) error {
+	var errs field.ErrorList
+	var warnings []string
+	switch {
+	case a.GetResource().GroupResource() == placementv1alpha1.Resource("placementpolicies") && a.GetKind().GroupKind() == placementv1alpha1.Kind("PlacementPolicy"):
+		policy := &placementv1alpha1.PlacementPolicy{}
+		if err := fromUnstructured(a.GetObject(), policy); err != nil {
+			return fmt.Errorf("failed to convert unstructured to PlacementPolicy: %w", err)
+		}
+		errs, warnings = validation.ValidatePlacementPolicySpec(&policy.Spec, field.NewPath("spec"))
+		if a.GetSubresource() == "status" {
+			errs = append(errs, validateApproval(policy, a.GetOldObject())...)
+		}
+	case a.GetResource().GroupResource() == placementv1alpha1.Resource("workloadplacementadvanceds") && a.GetKind().GroupKind() == placementv1alpha1.Kind("WorkloadPlacementAdvanced"):
+		advanced := &placementv1alpha1.WorkloadPlacementAdvanced{}
+		if err := fromUnstructured(a.GetObject(), advanced); err != nil {
+			return fmt.Errorf("failed to convert unstructured to WorkloadPlacementAdvanced: %w", err)
+		}
+		errs, warnings = validation.ValidateWorkloadPlacementAdvancedSpec(&advanced.Spec, field.NewPath("spec"))
+	default:
+		return nil
+	}
+
+	for _, w := range warnings {
+		warning.AddWarning(ctx, "", w)
+	}
+	if len(errs) > 0 {
+		return admission.NewForbidden(a, errs.ToAggregate())
+	}
+	return nil
+}
+
+// validateApproval rejects new approvals of other revisions than the one
+// rolled out.
+func validateApproval(policy *placementv1alpha1.PlacementPolicy, oldObj runtime.Object) field.ErrorList {
+	rollout := policy.Status.Rollout
+	if rollout == nil || rollout.Approval == nil {
+		return nil
+	}
+	if old, ok := oldObj.(*unstructured.Unstructured); ok {
+		if oldApproval, err := approvalOf(old); err == nil && oldApproval != nil && oldApproval.Revision == rollout.Approval.Revision {
+			return nil
+		}
+	}
+	if rollout.Approval.Revision != rollout.Revision {
+		return field.ErrorList{field.Invalid(field.NewPath("status", "rollout", "approval", "revision"), rollout.Approval.Revision, fmt.Sprintf("must be the revision rolled out, %s", rollout.Revision))}
+	}
+	return nil
+}
+
+func fromUnstructured(obj runtime.Object, into interface{}) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unexpected type %T", obj)
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, into)
+}
@@ -0,0 +1,358 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placementpolicy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/warning"
+
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+)
+
+type recorder struct {
+	warnings []string
+}
+
+func (r *recorder) AddWarning(_, text string) {
+	r.warnings = append(r.warnings, text)
+}
+
+func createAttr(t *testing.T, spec placementv1alpha1.PlacementPolicySpec) admission.Attributes {
+	t.Helper()
+	policy := &placementv1alpha1.PlacementPolicy{
+		TypeMeta:   metav1.TypeMeta{APIVersion: placementv1alpha1.SchemeGroupVersion.String(), Kind: "PlacementPolicy"},
+		ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+		Spec:       spec,
+	}
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(policy)
+	require.NoError(t, err)
+	obj := &unstructured.Unstructured{Object: raw}
+	return admission.NewAttributesRecord(
+		obj,
+		nil,
+		placementv1alpha1.Kind("PlacementPolicy").WithVersion("v1alpha1"),
+		"",
+		"policy",
+		placementv1alpha1.Resource("placementpolicies").WithVersion("v1alpha1"),
+		"",
+		admission.Create,
+		&metav1.CreateOptions{},
+		false,
+		&user.DefaultInfo{},
+	)
+}
+
+func TestValidate(t *testing.T) {
+	tests := map[string]struct {
+		spec         placementv1alpha1.PlacementPolicySpec
+		wantErr      string
+		wantWarnings int
+	}{
+		"valid": {
+			spec: placementv1alpha1.PlacementPolicySpec{
+				LocationSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"region": "eu"}},
+				TopologyKey:      "topology.kubernetes.io/zone",
+				DecisionTTL:      &metav1.Duration{Duration: time.Hour},
+				Requirements:     &placementv1alpha1.TargetRequirements{MinKubernetesVersion: "v1.29"},
+				Constraints: []placementv1alpha1.TargetConstraint{
+					{Expression: `target.metadata.labels["tier"] == "gold"`},
+				},
+			},
+		},
+		"invalid selector operator": {
+			spec: placementv1alpha1.PlacementPolicySpec{
+				LocationSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "region", Operator: "Near", Values: []string{"eu"}},
+				}},
+			},
+			wantErr: "spec.locationSelector.matchExpressions[0].operator",
+		},
+		"invalid selector label": {
+			spec: placementv1alpha1.PlacementPolicySpec{
+				LocationSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"not a key": "eu"}},
+			},
+			wantErr: "spec.locationSelector.matchLabels",
+		},
+		"invalid topology key": {
+			spec:    placementv1alpha1.PlacementPolicySpec{TopologyKey: "-zone"},
+			wantErr: "spec.topologyKey",
+		},
+		"decision TTL too short": {
+			spec:    placementv1alpha1.PlacementPolicySpec{DecisionTTL: &metav1.Duration{Duration: time.Second}},
+			wantErr: "spec.decisionTTL",
+		},
+		"invalid minimum Kubernetes version": {
+			spec: placementv1alpha1.PlacementPolicySpec{Requirements: &placementv1alpha1.TargetRequirements{
+				MinKubernetesVersion: "latest",
+			}},
+			wantErr: "spec.requirements.minKubernetesVersion",
+		},
+		"syntax error": {
+			spec: placementv1alpha1.PlacementPolicySpec{Constraints: []placementv1alpha1.TargetConstraint{
+				{Expression: `target.metadata.labels["tier"] ==`},
+			}},
+			wantErr: "spec.constraints[0].expression",
+		},
+		"not a bool": {
+			spec: placementv1alpha1.PlacementPolicySpec{Constraints: []placementv1alpha1.TargetConstraint{
+				{Expression: `true`},
+				{Expression: `"gold"`},
+			}},
+			wantErr: "spec.constraints[1].expression",
+		},
+		"undeclared variable": {
+			spec: placementv1alpha1.PlacementPolicySpec{Constraints: []placementv1alpha1.TargetConstraint{
+				{Expression: `workload.spec.replicas > 1`},
+			}},
+			wantErr: "undeclared reference",
+		},
+		"weighted spread": {
+			spec: placementv1alpha1.PlacementPolicySpec{
+				Strategy:        placementv1alpha1.PlacementStrategyWeightedSpread,
+				LocationWeights: []placementv1alpha1.LocationWeight{{Location: "eu", Weight: 3}, {Location: "us", Weight: 0}},
+			},
+		},
+		"weighted spread without weights": {
+			spec: placementv1alpha1.PlacementPolicySpec{
+				Strategy:        placementv1alpha1.PlacementStrategyWeightedSpread,
+				LocationWeights: []placementv1alpha1.LocationWeight{{Location: "eu", Weight: 0}},
+			},
+			wantErr: "spec.locationWeights",
+		},
+		"duplicate location weight": {
+			spec: placementv1alpha1.PlacementPolicySpec{
+				Strategy:        placementv1alpha1.PlacementStrategyWeightedSpread,
+				LocationWeights: []placementv1alpha1.LocationWeight{{Location: "eu", Weight: 1}, {Location: "eu", Weight: 2}},
+			},
+			wantErr: "spec.locationWeights[1].location",
+		},
+		"location weights of another strategy are allowed with a warning": {
+			spec: placementv1alpha1.PlacementPolicySpec{
+				LocationWeights: []placementv1alpha1.LocationWeight{{Location: "eu", Weight: 1}},
+			},
+			wantWarnings: 1,
+		},
+		"expensive expression is allowed with a warning": {
+			spec: placementv1alpha1.PlacementPolicySpec{Constraints: []placementv1alpha1.TargetConstraint{
+				{Expression: `target.status.conditions.all(c, target.status.conditions.exists(d, d.type == c.type))`},
+			}},
+			wantWarnings: 1,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := &recorder{}
+			ctx := warning.WithWarningRecorder(context.Background(), r)
+			err := NewPlacementPolicyAdmission().Validate(ctx, createAttr(t, tc.spec), nil)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				require.True(t, apierrors.IsForbidden(err), "expected forbidden, got %v", err)
+				require.Contains(t, err.Error(), tc.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Len(t, r.warnings, tc.wantWarnings, "warnings: %v", r.warnings)
+		})
+	}
+}
+
+func TestValidateAdvanced(t *testing.T) {
+	tests := map[string]struct {
+		rules        []placementv1alpha1.PlacementRule
+		wantErr      string
+		wantWarnings int
+	}{
+		"valid": {
+			rules: []placementv1alpha1.PlacementRule{
+				{Name: "db", Priority: 10, WorkloadSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "db"}},
+					Placement: placementv1alpha1.PlacementPolicySpec{Strategy: placementv1alpha1.PlacementStrategyHighAvailability}},
+				{Name: "default"},
+			},
+		},
+		"invalid workload selector": {
+			rules: []placementv1alpha1.PlacementRule{
+				{Name: "db", WorkloadSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "tier", Operator: "Near"},
+				}}},
+			},
+			wantErr: "spec.rules[0].workloadSelector.matchExpressions[0].operator",
+		},
+		"invalid placement": {
+			rules: []placementv1alpha1.PlacementRule{
+				{Name: "default"},
+				{Name: "db", Placement: placementv1alpha1.PlacementPolicySpec{TopologyKey: "-zone"}},
+			},
+			wantErr: "spec.rules[1].placement.topologyKey",
+		},
+		"rollout is allowed with a warning": {
+			rules: []placementv1alpha1.PlacementRule{
+				{Name: "default", Placement: placementv1alpha1.PlacementPolicySpec{Rollout: &placementv1alpha1.PolicyRollout{CanaryPercent: 10}}},
+			},
+			wantWarnings: 1,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			advanced := &placementv1alpha1.WorkloadPlacementAdvanced{
+				TypeMeta:   metav1.TypeMeta{APIVersion: placementv1alpha1.SchemeGroupVersion.String(), Kind: "WorkloadPlacementAdvanced"},
+				ObjectMeta: metav1.ObjectMeta{Name: "composite"},
+				Spec:       placementv1alpha1.WorkloadPlacementAdvancedSpec{Rules: tc.rules},
+			}
+			raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(advanced)
+			require.NoError(t, err)
+			attr := admission.NewAttributesRecord(
+				&unstructured.Unstructured{Object: raw},
+				nil,
+				placementv1alpha1.Kind("WorkloadPlacementAdvanced").WithVersion("v1alpha1"),
+				"",
+				"composite",
+				placementv1alpha1.Resource("workloadplacementadvanceds").WithVersion("v1alpha1"),
+				"",
+				admission.Create,
+				&metav1.CreateOptions{},
+				false,
+				&user.DefaultInfo{},
+			)
+
+			r := &recorder{}
+			ctx := warning.WithWarningRecorder(context.Background(), r)
+			err = NewPlacementPolicyAdmission().Validate(ctx, attr, nil)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				require.True(t, apierrors.IsForbidden(err), "expected forbidden, got %v", err)
+				require.Contains(t, err.Error(), tc.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Len(t, r.warnings, tc.wantWarnings, "warnings: %v", r.warnings)
+		})
+	}
+}
+
+func TestValidateIgnoresOtherResources(t *testing.T) {
+	attr := admission.NewAttributesRecord(
+		&unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{"topologyKey": "-zone"}}},
+		nil,
+		placementv1alpha1.Kind("PlacementPolicyRevision").WithVersion("v1alpha1"),
+		"",
+		"policy-abc",
+		placementv1alpha1.Resource("placementpolicyrevisions").WithVersion("v1alpha1"),
+		"",
+		admission.Create,
+		&metav1.CreateOptions{},
+		false,
+		&user.DefaultInfo{},
+	)
+	require.NoError(t, NewPlacementPolicyAdmission().Validate(context.Background(), attr, nil))
+}
+
+func statusAttr(t *testing.T, rollout, oldRollout *placementv1alpha1.PolicyRolloutStatus) admission.Attributes {
+	t.Helper()
+	toUnstructured := func(rollout *placementv1alpha1.PolicyRolloutStatus) *unstructured.Unstructured {
+		policy := &placementv1alpha1.PlacementPolicy{
+			TypeMeta:   metav1.TypeMeta{APIVersion: placementv1alpha1.SchemeGroupVersion.String(), Kind: "PlacementPolicy"},
+			ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+			Status:     placementv1alpha1.PlacementPolicyStatus{Rollout: rollout},
+		}
+		raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(policy)
+		require.NoError(t, err)
+		return &unstructured.Unstructured{Object: raw}
+	}
+	return admission.NewAttributesRecord(
+		toUnstructured(rollout),
+		toUnstructured(oldRollout),
+		placementv1alpha1.Kind("PlacementPolicy").WithVersion("v1alpha1"),
+		"",
+		"policy",
+		placementv1alpha1.Resource("placementpolicies").WithVersion("v1alpha1"),
+		"status",
+		admission.Update,
+		&metav1.UpdateOptions{},
+		false,
+		&user.DefaultInfo{Name: "alice"},
+	)
+}
+
+func TestAdmitApproval(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	earlier := metav1.NewTime(now.Add(-time.Hour))
+
+	tests := map[string]struct {
+		approval      *placementv1alpha1.RolloutApproval
+		oldApproval   *placementv1alpha1.RolloutApproval
+		want          *placementv1alpha1.RolloutApproval
+		wantForbidden bool
+	}{
+		"no approval": {},
+		"new approval is stamped": {
+			approval: &placementv1alpha1.RolloutApproval{Revision: "rev-2", ApprovedBy: "mallory", Message: "lgtm"},
+			want:     &placementv1alpha1.RolloutApproval{Revision: "rev-2", ApprovedBy: "alice", ApprovedAt: &metav1.Time{Time: now}, Message: "lgtm"},
+		},
+		"existing approval is kept": {
+			approval:    &placementv1alpha1.RolloutApproval{Revision: "rev-2", ApprovedBy: "mallory", Message: "lgtm"},
+			oldApproval: &placementv1alpha1.RolloutApproval{Revision: "rev-2", ApprovedBy: "bob", ApprovedAt: &earlier, Message: "lgtm"},
+			want:        &placementv1alpha1.RolloutApproval{Revision: "rev-2", ApprovedBy: "bob", ApprovedAt: &earlier, Message: "lgtm"},
+		},
+		"changed message is stamped again": {
+			approval:    &placementv1alpha1.RolloutApproval{Revision: "rev-2", Message: "really"},
+			oldApproval: &placementv1alpha1.RolloutApproval{Revision: "rev-2", ApprovedBy: "bob", ApprovedAt: &earlier, Message: "lgtm"},
+			want:        &placementv1alpha1.RolloutApproval{Revision: "rev-2", ApprovedBy: "alice", ApprovedAt: &metav1.Time{Time: now}, Message: "really"},
+		},
+		"other revision is rejected": {
+			approval:      &placementv1alpha1.RolloutApproval{Revision: "rev-1"},
+			wantForbidden: true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			rollout := &placementv1alpha1.PolicyRolloutStatus{Revision: "rev-2", Phase: placementv1alpha1.PolicyRolloutAwaitingApproval, Approval: tc.approval}
+			oldRollout := &placementv1alpha1.PolicyRolloutStatus{Revision: "rev-2", Phase: placementv1alpha1.PolicyRolloutAwaitingApproval, Approval: tc.oldApproval}
+			attr := statusAttr(t, rollout, oldRollout)
+
+			plugin := NewPlacementPolicyAdmission()
+			plugin.now = func() time.Time { return now }
+			require.NoError(t, plugin.Admit(context.Background(), attr, nil))
+
+			got, err := approvalOf(attr.GetObject().(*unstructured.Unstructured))
+			require.NoError(t, err)
+			if tc.want != nil {
+				require.Equal(t, tc.want.Revision, got.Revision)
+				require.Equal(t, tc.want.ApprovedBy, got.ApprovedBy)
+				require.Equal(t, tc.want.Message, got.Message)
+				require.True(t, tc.want.ApprovedAt.Equal(got.ApprovedAt), "expected approval at %v, got %v", tc.want.ApprovedAt, got.ApprovedAt)
+			}
+
+			err = plugin.Validate(context.Background(), attr, nil)
+			if tc.wantForbidden {
+				require.True(t, apierrors.IsForbidden(err), "expected forbidden error, got %v", err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
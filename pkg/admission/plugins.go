@@ -53,17 +53,24 @@ import (
 	"github.com/kcp-dev/kcp/pkg/admission/kubequota"
 	"github.com/kcp-dev/kcp/pkg/admission/logicalcluster"
 	"github.com/kcp-dev/kcp/pkg/admission/logicalclusterfinalizer"
+	"github.com/kcp-dev/kcp/pkg/admission/multiclusterresourcequota"
 	kcpmutatingwebhook "github.com/kcp-dev/kcp/pkg/admission/mutatingwebhook"
 	workspacenamespacelifecycle "github.com/kcp-dev/kcp/pkg/admission/namespacelifecycle"
 	"github.com/kcp-dev/kcp/pkg/admission/pathannotation"
 	"github.com/kcp-dev/kcp/pkg/admission/permissionclaims"
+	"github.com/kcp-dev/kcp/pkg/admission/placementpolicy"
 	"github.com/kcp-dev/kcp/pkg/admission/reservedcrdannotations"
 	"github.com/kcp-dev/kcp/pkg/admission/reservedcrdgroups"
 	"github.com/kcp-dev/kcp/pkg/admission/reservedmetadata"
 	"github.com/kcp-dev/kcp/pkg/admission/reservednames"
+	"github.com/kcp-dev/kcp/pkg/admission/schedulingprofile"
 	"github.com/kcp-dev/kcp/pkg/admission/shard"
+	"github.com/kcp-dev/kcp/pkg/admission/tmcapireadiness"
+	"github.com/kcp-dev/kcp/pkg/admission/tmcdeprecation"
+	"github.com/kcp-dev/kcp/pkg/admission/tmcstatus"
 	kcpvalidatingadmissionpolicy "github.com/kcp-dev/kcp/pkg/admission/validatingadmissionpolicy"
 	kcpvalidatingwebhook "github.com/kcp-dev/kcp/pkg/admission/validatingwebhook"
+	"github.com/kcp-dev/kcp/pkg/admission/workloadpriorityclass"
 	"github.com/kcp-dev/kcp/pkg/admission/workspace"
 	"github.com/kcp-dev/kcp/pkg/admission/workspacetype"
 	"github.com/kcp-dev/kcp/pkg/admission/workspacetypeexists"
@@ -96,6 +103,13 @@ var AllOrderedPlugins = beforeWebhooks(kubeapiserveroptions.AllOrderedPlugins,
 	kubequota.PluginName,
 	mutatingadmissionpolicy.PluginName,
 	cachedresource.PluginName,
+	placementpolicy.PluginName,
+	schedulingprofile.PluginName,
+	workloadpriorityclass.PluginName,
+	tmcdeprecation.PluginName,
+	tmcstatus.PluginName,
+	tmcapireadiness.PluginName,
+	multiclusterresourcequota.PluginName,
 )
 
 func beforeWebhooks(recommended []string, plugins ...string) []string {
@@ -136,6 +150,13 @@ func RegisterAllKcpAdmissionPlugins(plugins *admission.Plugins) {
 	pathannotation.Register(plugins)
 	kubequota.Register(plugins)
 	cachedresource.Register(plugins)
+	placementpolicy.Register(plugins)
+	schedulingprofile.Register(plugins)
+	workloadpriorityclass.Register(plugins)
+	tmcdeprecation.Register(plugins)
+	tmcstatus.Register(plugins)
+	tmcapireadiness.Register(plugins)
+	multiclusterresourcequota.Register(plugins)
 }
 
 var defaultOnPluginsInKcp = sets.New[string](
@@ -166,6 +187,18 @@ var defaultOnPluginsInKcp = sets.New[string](
 	pathannotation.PluginName,
 	kubequota.PluginName,
 	cachedresource.PluginName,
+).Union(tmcPlugins)
+
+// tmcPlugins are the default-on plugins of the TMC APIs. They are off unless
+// the TMCAPIs feature gate is enabled, see TMCAdmissionPlugins.
+var tmcPlugins = sets.New[string](
+	placementpolicy.PluginName,
+	schedulingprofile.PluginName,
+	workloadpriorityclass.PluginName,
+	tmcdeprecation.PluginName,
+	tmcstatus.PluginName,
+	tmcapireadiness.PluginName,
+	multiclusterresourcequota.PluginName,
 )
 
 // defaultOnKubePluginsInKube is a copy of kubeapiserveroptions.defaultOnKubePlugins.
@@ -201,3 +234,10 @@ var defaultOnKubePluginsInKube = sets.New[string](
 func DefaultOffAdmissionPlugins() sets.Set[string] {
 	return sets.New[string](AllOrderedPlugins...).Difference(defaultOnPluginsInKcp)
 }
+
+// TMCAdmissionPlugins returns the default-on admission plugins of the TMC
+// APIs, which are turned off once the feature gates are known, unless the
+// TMCAPIs feature gate is enabled.
+func TMCAdmissionPlugins() sets.Set[string] {
+	return tmcPlugins.Clone()
+}
@@ -34,3 +34,12 @@ func TestPluginDrift(t *testing.T) {
 		t.Errorf("Default-on plugins got removed in kube. Remove in defaultOnKubePluginsInKube, and decide whether to remove from defaultOnPluginsInKcp: %v", sets.List[string](goneInKube))
 	}
 }
+
+func TestTMCPluginsDefaultOn(t *testing.T) {
+	if missing := tmcPlugins.Difference(sets.New[string](AllOrderedPlugins...)); missing.Len() > 0 {
+		t.Errorf("TMC plugins are not ordered: %v", sets.List[string](missing))
+	}
+	if off := tmcPlugins.Intersection(DefaultOffAdmissionPlugins()); off.Len() > 0 {
+		t.Errorf("TMC plugins are off by default regardless of the TMCAPIs feature gate: %v", sets.List[string](off))
+	}
+}
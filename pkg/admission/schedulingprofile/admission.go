@@ -0,0 +1,173 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedulingprofile
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/warning"
+
+	"github.com/kcp-dev/kcp/pkg/placement/engine"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+)
+
+// PluginName is the name used to identify this admission webhook.
+const PluginName = "placement.kcp.io/SchedulingProfile"
+
+// Register registers the SchedulingProfile admission webhook.
+func Register(plugins *admission.Plugins) {
+	plugins.Register(PluginName,
+		func(_ io.Reader) (admission.Interface, error) {
+			return NewSchedulingProfileAdmission(), nil
+		})
+}
+
+// SchedulingProfileAdmission rejects SchedulingProfiles with unknown scorers
+// or weights out of range, and warns about profiles that have no effect. It
+// records the user freezing placement in spec.freeze.frozenBy.
+type SchedulingProfileAdmission struct {
+	*admission.Handler
+}
+
+// NewSchedulingProfileAdmission constructs a new SchedulingProfileAdmission admission plugin.
+func NewSchedulingProfileAdmission() *SchedulingProfileAdmission {
+	return &SchedulingProfileAdmission{
+		Handler: admission.NewHandler(admission.Create, admission.Update),
+	}
+}
+
+// Ensure that the required admission interfaces are implemented.
+var _ = admission.ValidationInterface(&SchedulingProfileAdmission{})
+var _ = admission.MutationInterface(&SchedulingProfileAdmission{})
+
+// Admit sets spec.freeze.frozenBy to the requesting user when placement is
+// frozen or the freeze changes, and keeps it otherwise.
+func (p *SchedulingProfileAdmission) Admit(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) error {
+	if a.GetResource().GroupResource() != placementv1alpha1.Resource("schedulingprofiles") || a.GetKind().GroupKind() != placementv1alpha1.Kind("SchedulingProfile") || a.GetSubresource() != "" {
+		return nil
+	}
+
+	u, ok := a.GetObject().(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unexpected type %T", a.GetObject())
+	}
+	profile := &placementv1alpha1.SchedulingProfile{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, profile); err != nil {
+		return fmt.Errorf("failed to convert unstructured to SchedulingProfile: %w", err)
+	}
+	if profile.Spec.Freeze == nil {
+		return nil
+	}
+
+	var oldFreeze *placementv1alpha1.PlacementFreeze
+	if a.GetOperation() == admission.Update {
+		oldU, ok := a.GetOldObject().(*unstructured.Unstructured)
+		if !ok {
+			return fmt.Errorf("unexpected type %T", a.GetOldObject())
+		}
+		old := &placementv1alpha1.SchedulingProfile{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(oldU.Object, old); err != nil {
+			return fmt.Errorf("failed to convert unstructured to SchedulingProfile: %w", err)
+		}
+		oldFreeze = old.Spec.Freeze
+	}
+
+	frozenBy := a.GetUserInfo().GetName()
+	if oldFreeze != nil && oldFreeze.Reason == profile.Spec.Freeze.Reason && oldFreeze.Until.Equal(profile.Spec.Freeze.Until) {
+		frozenBy = oldFreeze.FrozenBy
+	}
+	return unstructured.SetNestedField(u.Object, frozenBy, "spec", "freeze", "frozenBy")
+}
+
+// Validate ensures that the SchedulingProfile is valid.
+func (p *SchedulingProfileAdmission) Validate(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) error {
+	if a.GetResource().GroupResource() != placementv1alpha1.Resource("schedulingprofiles") || a.GetKind().GroupKind() != placementv1alpha1.Kind("SchedulingProfile") {
+		return nil
+	}
+
+	u, ok := a.GetObject().(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unexpected type %T", a.GetObject())
+	}
+	profile := &placementv1alpha1.SchedulingProfile{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, profile); err != nil {
+		return fmt.Errorf("failed to convert unstructured to SchedulingProfile: %w", err)
+	}
+
+	if profile.Name != placementv1alpha1.DefaultSchedulingProfileName {
+		warning.AddWarning(ctx, "", fmt.Sprintf("only the SchedulingProfile named %q is used by the placement engine", placementv1alpha1.DefaultSchedulingProfileName))
+	}
+	if errs := ValidateSpec(&profile.Spec, field.NewPath("spec")); len(errs) > 0 {
+		return admission.NewForbidden(a, errs.ToAggregate())
+	}
+	if allZero(engine.Weights(&profile.Spec)) {
+		warning.AddWarning(ctx, "", "all scorers are disabled, SyncTargets are chosen by name")
+	}
+	return nil
+}
+
+// ValidateSpec validates a SchedulingProfile spec.
+func ValidateSpec(spec *placementv1alpha1.SchedulingProfileSpec, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	seen := sets.New[placementv1alpha1.ScorerName]()
+	for i, scorer := range spec.Scorers {
+		scorerPath := path.Child("scorers").Index(i)
+		switch {
+		case !slices.Contains(placementv1alpha1.Scorers, scorer.Name):
+			errs = append(errs, field.NotSupported(scorerPath.Child("name"), scorer.Name, placementv1alpha1.Scorers))
+		case seen.Has(scorer.Name):
+			errs = append(errs, field.Duplicate(scorerPath.Child("name"), scorer.Name))
+		}
+		seen.Insert(scorer.Name)
+		if scorer.Weight != nil && (*scorer.Weight < 0 || *scorer.Weight > placementv1alpha1.MaxScorerWeight) {
+			errs = append(errs, field.Invalid(scorerPath.Child("weight"), *scorer.Weight, fmt.Sprintf("must be between 0 and %d", placementv1alpha1.MaxScorerWeight)))
+		}
+	}
+
+	if spec.Freeze != nil && spec.Freeze.Reason == "" {
+		errs = append(errs, field.Required(path.Child("freeze", "reason"), "tell why placement is frozen"))
+	}
+
+	switch spec.DefaultStrategy {
+	case "", placementv1alpha1.PlacementStrategySingleton, placementv1alpha1.PlacementStrategyHighAvailability, placementv1alpha1.PlacementStrategySpread:
+	default:
+		errs = append(errs, field.NotSupported(path.Child("defaultStrategy"), spec.DefaultStrategy, []placementv1alpha1.PlacementStrategy{
+			placementv1alpha1.PlacementStrategySingleton,
+			placementv1alpha1.PlacementStrategyHighAvailability,
+			placementv1alpha1.PlacementStrategySpread,
+		}))
+	}
+	return errs
+}
+
+func allZero(weights map[placementv1alpha1.ScorerName]int32) bool {
+	for _, weight := range weights {
+		if weight > 0 {
+			return false
+		}
+	}
+	return true
+}
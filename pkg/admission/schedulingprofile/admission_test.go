@@ -0,0 +1,194 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedulingprofile
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/warning"
+	"k8s.io/utils/ptr"
+
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+)
+
+type recorder struct {
+	warnings []string
+}
+
+func (r *recorder) AddWarning(_, text string) {
+	r.warnings = append(r.warnings, text)
+}
+
+func createAttr(t *testing.T, name string, spec placementv1alpha1.SchedulingProfileSpec) admission.Attributes {
+	t.Helper()
+	profile := &placementv1alpha1.SchedulingProfile{
+		TypeMeta:   metav1.TypeMeta{APIVersion: placementv1alpha1.SchemeGroupVersion.String(), Kind: "SchedulingProfile"},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       spec,
+	}
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(profile)
+	require.NoError(t, err)
+	return admission.NewAttributesRecord(
+		&unstructured.Unstructured{Object: raw},
+		nil,
+		placementv1alpha1.Kind("SchedulingProfile").WithVersion("v1alpha1"),
+		"",
+		name,
+		placementv1alpha1.Resource("schedulingprofiles").WithVersion("v1alpha1"),
+		"",
+		admission.Create,
+		&metav1.CreateOptions{},
+		false,
+		&user.DefaultInfo{},
+	)
+}
+
+func TestValidate(t *testing.T) {
+	tests := map[string]struct {
+		name         string
+		spec         placementv1alpha1.SchedulingProfileSpec
+		wantErr      string
+		wantWarnings int
+	}{
+		"valid": {
+			name: "default",
+			spec: placementv1alpha1.SchedulingProfileSpec{
+				Scorers: []placementv1alpha1.ScorerConfig{
+					{Name: placementv1alpha1.ScorerCost, Weight: ptr.To[int32](100)},
+					{Name: placementv1alpha1.ScorerBalance, Disabled: true},
+				},
+				DefaultStrategy: placementv1alpha1.PlacementStrategyHighAvailability,
+			},
+		},
+		"unused name": {
+			name:         "fast",
+			wantWarnings: 1,
+		},
+		"weight out of range": {
+			name: "default",
+			spec: placementv1alpha1.SchedulingProfileSpec{Scorers: []placementv1alpha1.ScorerConfig{
+				{Name: placementv1alpha1.ScorerLocality, Weight: ptr.To[int32](101)},
+			}},
+			wantErr: "spec.scorers[0].weight",
+		},
+		"unknown scorer": {
+			name: "default",
+			spec: placementv1alpha1.SchedulingProfileSpec{Scorers: []placementv1alpha1.ScorerConfig{
+				{Name: "Latency"},
+			}},
+			wantErr: "spec.scorers[0].name",
+		},
+		"duplicate scorer": {
+			name: "default",
+			spec: placementv1alpha1.SchedulingProfileSpec{Scorers: []placementv1alpha1.ScorerConfig{
+				{Name: placementv1alpha1.ScorerCost},
+				{Name: placementv1alpha1.ScorerCost, Weight: ptr.To[int32](1)},
+			}},
+			wantErr: "spec.scorers[1].name",
+		},
+		"unknown strategy": {
+			name:    "default",
+			spec:    placementv1alpha1.SchedulingProfileSpec{DefaultStrategy: "RoundRobin"},
+			wantErr: "spec.defaultStrategy",
+		},
+		"freeze without reason": {
+			name:    "default",
+			spec:    placementv1alpha1.SchedulingProfileSpec{Freeze: &placementv1alpha1.PlacementFreeze{}},
+			wantErr: "spec.freeze.reason",
+		},
+		"all scorers off": {
+			name:         "default",
+			spec:         placementv1alpha1.SchedulingProfileSpec{Scorers: allScorersOff()},
+			wantWarnings: 1,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := &recorder{}
+			ctx := warning.WithWarningRecorder(context.Background(), r)
+			err := NewSchedulingProfileAdmission().Validate(ctx, createAttr(t, tc.name, tc.spec), nil)
+			if tc.wantErr != "" {
+				require.True(t, apierrors.IsForbidden(err), "expected forbidden, got %v", err)
+				require.Contains(t, err.Error(), tc.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Len(t, r.warnings, tc.wantWarnings, "warnings: %v", r.warnings)
+		})
+	}
+}
+
+// allScorersOff turns off every scorer, the first one with a zero weight and
+// the others disabled.
+func allScorersOff() []placementv1alpha1.ScorerConfig {
+	scorers := make([]placementv1alpha1.ScorerConfig, 0, len(placementv1alpha1.Scorers))
+	for i, name := range placementv1alpha1.Scorers {
+		if i == 0 {
+			scorers = append(scorers, placementv1alpha1.ScorerConfig{Name: name, Weight: ptr.To[int32](0)})
+			continue
+		}
+		scorers = append(scorers, placementv1alpha1.ScorerConfig{Name: name, Disabled: true})
+	}
+	return scorers
+}
+
+func TestAdmitFrozenBy(t *testing.T) {
+	toUnstructured := func(spec placementv1alpha1.SchedulingProfileSpec) *unstructured.Unstructured {
+		raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&placementv1alpha1.SchedulingProfile{ObjectMeta: metav1.ObjectMeta{Name: "default"}, Spec: spec})
+		require.NoError(t, err)
+		return &unstructured.Unstructured{Object: raw}
+	}
+	admit := func(userName string, oldSpec *placementv1alpha1.SchedulingProfileSpec, spec placementv1alpha1.SchedulingProfileSpec) *placementv1alpha1.PlacementFreeze {
+		t.Helper()
+		obj := toUnstructured(spec)
+		operation, old := admission.Create, runtime.Object(nil)
+		if oldSpec != nil {
+			operation, old = admission.Update, toUnstructured(*oldSpec)
+		}
+		attr := admission.NewAttributesRecord(obj, old,
+			placementv1alpha1.Kind("SchedulingProfile").WithVersion("v1alpha1"), "", "default",
+			placementv1alpha1.Resource("schedulingprofiles").WithVersion("v1alpha1"), "",
+			operation, nil, false, &user.DefaultInfo{Name: userName})
+		require.NoError(t, NewSchedulingProfileAdmission().Admit(context.Background(), attr, nil))
+		profile := &placementv1alpha1.SchedulingProfile{}
+		require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, profile))
+		return profile.Spec.Freeze
+	}
+
+	frozen := placementv1alpha1.SchedulingProfileSpec{Freeze: &placementv1alpha1.PlacementFreeze{Reason: "incident 42", FrozenBy: "mallory"}}
+	require.Equal(t, "alice", admit("alice", nil, frozen).FrozenBy, "frozenBy is set by admission")
+
+	old := frozen.DeepCopy()
+	old.Freeze.FrozenBy = "alice"
+	require.Equal(t, "alice", admit("bob", old, frozen).FrozenBy, "unchanged freezes keep who froze")
+
+	changed := frozen.DeepCopy()
+	changed.Freeze.Until = &metav1.Time{Time: metav1.Now().Add(time.Hour)}
+	require.Equal(t, "bob", admit("bob", old, *changed).FrozenBy)
+
+	require.Nil(t, admit("bob", old, placementv1alpha1.SchedulingProfileSpec{}))
+}
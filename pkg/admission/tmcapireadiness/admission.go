@@ -0,0 +1,139 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tmcapireadiness
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/admission"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	kcpinitializers "github.com/kcp-dev/kcp/pkg/admission/initializers"
+	"github.com/kcp-dev/kcp/pkg/indexers"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/tmcexport"
+	apisv1alpha2 "github.com/kcp-dev/kcp/sdk/apis/apis/v1alpha2"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	kcpinformers "github.com/kcp-dev/kcp/sdk/client/informers/externalversions"
+)
+
+// PluginName is the name used to identify this admission webhook.
+const PluginName = "tmc.kcp.io/APIReadiness"
+
+// Register registers the TMC API readiness admission webhook.
+func Register(plugins *admission.Plugins) {
+	plugins.Register(PluginName,
+		func(_ io.Reader) (admission.Interface, error) {
+			p := &APIReadinessAdmission{
+				Handler: admission.NewHandler(admission.Create),
+			}
+			p.getAPIExport = func(path logicalcluster.Path, name string) (*apisv1alpha2.APIExport, error) {
+				return indexers.ByPathAndNameWithFallback[*apisv1alpha2.APIExport](apisv1alpha2.Resource("apiexports"), p.apiExportIndexer, p.cacheAPIExportIndexer, path, name)
+			}
+			return p, nil
+		})
+}
+
+// APIReadinessAdmission rejects APIBindings to an APIExport of the TMC APIs
+// whose TMCControllersReady condition is false, so that no TMC resources
+// are created in workspaces before the TMC controllers can reconcile them.
+// APIExports without the condition, e.g. when the TMC controllers are not
+// running on their shard, are not checked.
+type APIReadinessAdmission struct {
+	*admission.Handler
+
+	getAPIExport func(path logicalcluster.Path, name string) (*apisv1alpha2.APIExport, error)
+
+	apiExportIndexer      cache.Indexer
+	cacheAPIExportIndexer cache.Indexer
+}
+
+// Ensure that the required admission interfaces are implemented.
+var (
+	_ = admission.ValidationInterface(&APIReadinessAdmission{})
+	_ = admission.InitializationValidator(&APIReadinessAdmission{})
+	_ = kcpinitializers.WantsKcpInformers(&APIReadinessAdmission{})
+)
+
+// Validate ensures that new APIBindings only bind APIExports of the TMC APIs
+// whose controllers are ready.
+func (p *APIReadinessAdmission) Validate(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) error {
+	if a.GetResource().GroupResource() != apisv1alpha2.Resource("apibindings") {
+		return nil
+	}
+	clusterName, err := genericapirequest.ClusterNameFrom(ctx)
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+
+	u, ok := a.GetObject().(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unexpected type %T", a.GetObject())
+	}
+	apiBinding := &apisv1alpha2.APIBinding{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, apiBinding); err != nil {
+		return fmt.Errorf("failed to convert unstructured to APIBinding: %w", err)
+	}
+	if apiBinding.Spec.Reference.Export == nil {
+		return nil
+	}
+
+	path := clusterName.Path()
+	if apiBinding.Spec.Reference.Export.Path != "" {
+		path = logicalcluster.NewPath(apiBinding.Spec.Reference.Export.Path)
+	}
+	apiExport, err := p.getAPIExport(path, apiBinding.Spec.Reference.Export.Name)
+	if err != nil {
+		// Missing exports are reported by the APIBinding plugin, without
+		// leaking their existence.
+		return nil
+	}
+	if !tmcexport.ExportsTMC(apiExport) || !conditions.IsFalse(apiExport, tmcexport.TMCControllersReady) {
+		return nil
+	}
+
+	return admission.NewForbidden(a, fmt.Errorf("the TMC controllers are not ready to serve APIExport %s: %s",
+		path.Join(apiBinding.Spec.Reference.Export.Name), conditions.GetMessage(apiExport, tmcexport.TMCControllersReady)))
+}
+
+// ValidateInitialization ensures the required injected fields are set.
+func (p *APIReadinessAdmission) ValidateInitialization() error {
+	if p.apiExportIndexer == nil {
+		return fmt.Errorf(PluginName + " plugin needs an APIExport indexer")
+	}
+	if p.cacheAPIExportIndexer == nil {
+		return fmt.Errorf(PluginName + " plugin needs a cache APIExport indexer")
+	}
+	return nil
+}
+
+func (p *APIReadinessAdmission) SetKcpInformers(local, global kcpinformers.SharedInformerFactory) {
+	apiExportsReady := local.Apis().V1alpha2().APIExports().Informer().HasSynced
+	cacheAPIExportsReady := global.Apis().V1alpha2().APIExports().Informer().HasSynced
+	p.SetReadyFunc(func() bool {
+		return apiExportsReady() && cacheAPIExportsReady()
+	})
+	p.apiExportIndexer = local.Apis().V1alpha2().APIExports().Informer().GetIndexer()
+	p.cacheAPIExportIndexer = global.Apis().V1alpha2().APIExports().Informer().GetIndexer()
+}
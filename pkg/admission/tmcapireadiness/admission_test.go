@@ -0,0 +1,140 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tmcapireadiness
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/tmcexport"
+	apisv1alpha2 "github.com/kcp-dev/kcp/sdk/apis/apis/v1alpha2"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+)
+
+func createAttr(t *testing.T, binding *apisv1alpha2.APIBinding) admission.Attributes {
+	t.Helper()
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(binding)
+	require.NoError(t, err)
+	return admission.NewAttributesRecord(
+		&unstructured.Unstructured{Object: obj},
+		nil,
+		apisv1alpha2.Kind("APIBinding").WithVersion("v1alpha2"),
+		"",
+		binding.Name,
+		apisv1alpha2.Resource("apibindings").WithVersion("v1alpha2"),
+		"",
+		admission.Create,
+		&metav1.CreateOptions{},
+		false,
+		&user.DefaultInfo{},
+	)
+}
+
+func TestValidate(t *testing.T) {
+	tmcExport := func(status conditionsv1alpha1.Condition) *apisv1alpha2.APIExport {
+		apiExport := &apisv1alpha2.APIExport{
+			ObjectMeta: metav1.ObjectMeta{Name: "tmc.kcp.io"},
+			Spec: apisv1alpha2.APIExportSpec{Resources: []apisv1alpha2.ResourceSchema{
+				{Group: "tmc.kcp.io", Name: "synctargets", Schema: "v1.synctargets.tmc.kcp.io"},
+			}},
+		}
+		if status.Type != "" {
+			conditions.Set(apiExport, &status)
+		}
+		return apiExport
+	}
+	notReady := *conditions.FalseCondition(tmcexport.TMCControllersReady, tmcexport.ControllersNotReadyReason, conditionsv1alpha1.ConditionSeverityError,
+		"PlacementController: WaitingForCaches: informers have not synced")
+
+	tests := map[string]struct {
+		export  *apisv1alpha2.APIExport
+		path    string
+		wantErr string
+	}{
+		"ready": {
+			export: tmcExport(*conditions.TrueCondition(tmcexport.TMCControllersReady)),
+			path:   "root:tmc",
+		},
+		"not ready": {
+			export:  tmcExport(notReady),
+			path:    "root:tmc",
+			wantErr: "the TMC controllers are not ready to serve APIExport root:tmc:tmc.kcp.io: PlacementController: WaitingForCaches: informers have not synced",
+		},
+		"not ready in the same workspace": {
+			export:  tmcExport(notReady),
+			wantErr: "the TMC controllers are not ready to serve APIExport root:org:tmc.kcp.io",
+		},
+		"without condition": {
+			export: tmcExport(conditionsv1alpha1.Condition{}),
+			path:   "root:tmc",
+		},
+		"not TMC": {
+			export: &apisv1alpha2.APIExport{
+				ObjectMeta: metav1.ObjectMeta{Name: "tmc.kcp.io"},
+				Status:     apisv1alpha2.APIExportStatus{Conditions: conditionsv1alpha1.Conditions{notReady}},
+			},
+			path: "root:tmc",
+		},
+		"missing export": {
+			path: "root:tmc",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := &APIReadinessAdmission{
+				Handler: admission.NewHandler(admission.Create),
+				getAPIExport: func(path logicalcluster.Path, name string) (*apisv1alpha2.APIExport, error) {
+					wantPath := tc.path
+					if wantPath == "" {
+						wantPath = "root:org"
+					}
+					if tc.export == nil || path.String() != wantPath {
+						return nil, apierrors.NewNotFound(apisv1alpha2.Resource("apiexports"), name)
+					}
+					return tc.export, nil
+				},
+			}
+			binding := &apisv1alpha2.APIBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "tmc"},
+				Spec: apisv1alpha2.APIBindingSpec{Reference: apisv1alpha2.BindingReference{
+					Export: &apisv1alpha2.ExportBindingReference{Path: tc.path, Name: "tmc.kcp.io"},
+				}},
+			}
+			ctx := request.WithCluster(context.Background(), request.Cluster{Name: "root:org"})
+			err := p.Validate(ctx, createAttr(t, binding), nil)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
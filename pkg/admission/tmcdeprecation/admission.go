@@ -0,0 +1,82 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tmcdeprecation
+
+import (
+	"context"
+	"io"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apiserver/pkg/admission"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/warning"
+
+	"github.com/kcp-dev/kcp/pkg/deprecation"
+)
+
+// PluginName is the name used to identify this admission webhook.
+const PluginName = "tmc.kcp.io/Deprecation"
+
+// Register registers the TMC deprecation admission webhook.
+func Register(plugins *admission.Plugins) {
+	plugins.Register(PluginName,
+		func(_ io.Reader) (admission.Interface, error) {
+			return NewDeprecationAdmission(), nil
+		})
+}
+
+// DeprecationAdmission warns about deprecated TMC API fields set in created
+// or updated objects, and counts their usage per workspace.
+type DeprecationAdmission struct {
+	*admission.Handler
+
+	registry *deprecation.Registry
+}
+
+// NewDeprecationAdmission constructs a new DeprecationAdmission admission plugin.
+func NewDeprecationAdmission() *DeprecationAdmission {
+	deprecation.Register()
+
+	return &DeprecationAdmission{
+		Handler:  admission.NewHandler(admission.Create, admission.Update),
+		registry: deprecation.Default,
+	}
+}
+
+// Ensure that the required admission interfaces are implemented.
+var _ = admission.ValidationInterface(&DeprecationAdmission{})
+
+// Validate warns about deprecated fields. It never rejects objects.
+func (p *DeprecationAdmission) Validate(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) error {
+	if a.GetSubresource() != "" || !p.registry.Has(a.GetResource()) {
+		return nil
+	}
+	u, ok := a.GetObject().(*unstructured.Unstructured)
+	if !ok {
+		return nil
+	}
+
+	var workspace string
+	if clusterName, err := genericapirequest.ClusterNameFrom(ctx); err == nil {
+		workspace = clusterName.String()
+	}
+	for _, f := range p.registry.Find(a.GetResource(), u.Object) {
+		warning.AddWarning(ctx, "", f.Warning())
+		deprecation.RecordUsage(workspace, f)
+	}
+	return nil
+}
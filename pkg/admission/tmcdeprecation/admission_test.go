@@ -0,0 +1,98 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tmcdeprecation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/warning"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/deprecation"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+type recorder struct {
+	warnings []string
+}
+
+func (r *recorder) AddWarning(_, text string) {
+	r.warnings = append(r.warnings, text)
+}
+
+func createAttr(obj map[string]interface{}, subresource string) admission.Attributes {
+	return admission.NewAttributesRecord(
+		&unstructured.Unstructured{Object: obj},
+		nil,
+		tmcv1alpha1.Kind("SyncTarget").WithVersion("v1alpha1"),
+		"",
+		"edge-1",
+		tmcv1alpha1.Resource("synctargets").WithVersion("v1alpha1"),
+		subresource,
+		admission.Create,
+		&metav1.CreateOptions{},
+		false,
+		&user.DefaultInfo{},
+	)
+}
+
+func TestValidate(t *testing.T) {
+	field := deprecation.Field{
+		Resource: tmcv1alpha1.SchemeGroupVersion.WithResource("synctargets"),
+		Kind:     "SyncTarget",
+		Path:     "spec.legacyRegion",
+		Message:  "use spec.location instead",
+	}
+	p := NewDeprecationAdmission()
+	p.registry = deprecation.NewRegistry(field)
+
+	tests := map[string]struct {
+		obj          map[string]interface{}
+		subresource  string
+		wantWarnings []string
+	}{
+		"deprecated field set": {
+			obj:          map[string]interface{}{"spec": map[string]interface{}{"legacyRegion": "eu", "location": "eu"}},
+			wantWarnings: []string{"tmc.kcp.io/v1alpha1 SyncTarget spec.legacyRegion is deprecated: use spec.location instead"},
+		},
+		"deprecated field unset": {
+			obj: map[string]interface{}{"spec": map[string]interface{}{"location": "eu"}},
+		},
+		"status subresource": {
+			obj:         map[string]interface{}{"spec": map[string]interface{}{"legacyRegion": "eu"}},
+			subresource: "status",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := &recorder{}
+			ctx := warning.WithWarningRecorder(context.Background(), r)
+			ctx = request.WithCluster(ctx, request.Cluster{Name: logicalcluster.Name("root:org")})
+			require.NoError(t, p.Validate(ctx, createAttr(tc.obj, tc.subresource), nil))
+			require.Equal(t, tc.wantWarnings, r.warnings)
+		})
+	}
+}
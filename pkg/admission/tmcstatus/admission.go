@@ -0,0 +1,82 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tmcstatus
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apiserver/pkg/admission"
+
+	"github.com/kcp-dev/kcp/pkg/statusvalues"
+)
+
+// PluginName is the name used to identify this admission webhook.
+const PluginName = "tmc.kcp.io/StatusValues"
+
+// Register registers the TMC status values admission webhook.
+func Register(plugins *admission.Plugins) {
+	plugins.Register(PluginName,
+		func(_ io.Reader) (admission.Interface, error) {
+			return NewStatusValuesAdmission(), nil
+		})
+}
+
+// StatusValuesAdmission rejects status writes of TMC objects with unknown
+// phases or condition types, or malformed conditions. Status is written
+// through the status subresource by the TMC controllers and the syncers,
+// directly or through virtual workspaces, which all pass this plugin.
+type StatusValuesAdmission struct {
+	*admission.Handler
+
+	registry *statusvalues.Registry
+}
+
+// NewStatusValuesAdmission constructs a new StatusValuesAdmission admission plugin.
+func NewStatusValuesAdmission() *StatusValuesAdmission {
+	return &StatusValuesAdmission{
+		Handler:  admission.NewHandler(admission.Update),
+		registry: statusvalues.Default,
+	}
+}
+
+// Ensure that the required admission interfaces are implemented.
+var _ = admission.ValidationInterface(&StatusValuesAdmission{})
+
+// Validate ensures that the phases and conditions written to the status are
+// valid. Values unchanged from the old object are accepted.
+func (p *StatusValuesAdmission) Validate(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) error {
+	if a.GetSubresource() != "status" || !p.registry.Has(a.GetKind().GroupKind()) {
+		return nil
+	}
+
+	u, ok := a.GetObject().(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unexpected type %T", a.GetObject())
+	}
+	var old map[string]interface{}
+	if oldU, ok := a.GetOldObject().(*unstructured.Unstructured); ok {
+		old = oldU.Object
+	}
+
+	if errs := p.registry.Validate(a.GetKind().GroupKind(), u.Object, old); len(errs) > 0 {
+		return admission.NewForbidden(a, errs.ToAggregate())
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tmcstatus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func updateAttr(kind string, obj, old map[string]interface{}, subresource string) admission.Attributes {
+	return admission.NewAttributesRecord(
+		&unstructured.Unstructured{Object: obj},
+		&unstructured.Unstructured{Object: old},
+		tmcv1alpha1.Kind(kind).WithVersion("v1alpha1"),
+		"",
+		"edge-1",
+		tmcv1alpha1.Resource("synctargets").WithVersion("v1alpha1"),
+		subresource,
+		admission.Update,
+		&metav1.UpdateOptions{},
+		false,
+		&user.DefaultInfo{},
+	)
+}
+
+func withCondition(typ, status string) map[string]interface{} {
+	return map[string]interface{}{"status": map[string]interface{}{"conditions": []interface{}{
+		map[string]interface{}{"type": typ, "status": status, "reason": "Reason", "lastTransitionTime": "2025-01-01T00:00:00Z"},
+	}}}
+}
+
+func TestValidate(t *testing.T) {
+	tests := map[string]struct {
+		kind        string
+		obj         map[string]interface{}
+		subresource string
+		wantErr     string
+	}{
+		"valid status": {
+			kind:        "SyncTarget",
+			obj:         withCondition("SyncerReady", "True"),
+			subresource: "status",
+		},
+		"unknown condition type": {
+			kind:        "SyncTarget",
+			obj:         withCondition("SyncerRedy", "True"),
+			subresource: "status",
+			wantErr:     `status.conditions[0].type: Unsupported value: "SyncerRedy"`,
+		},
+		"invalid condition status": {
+			kind:        "SyncTarget",
+			obj:         withCondition("SyncerReady", "Yes"),
+			subresource: "status",
+			wantErr:     `status.conditions[0].status: Unsupported value: "Yes"`,
+		},
+		"main resource is not validated": {
+			kind: "SyncTarget",
+			obj:  withCondition("SyncerRedy", "True"),
+		},
+		"kind without registered values": {
+			kind:        "TMCFeatureStatus",
+			obj:         withCondition("Anything", "True"),
+			subresource: "status",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := NewStatusValuesAdmission().Validate(context.Background(), updateAttr(tc.kind, tc.obj, map[string]interface{}{}, tc.subresource), nil)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadpriorityclass
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/admission"
+	schedulingapi "k8s.io/kubernetes/pkg/apis/scheduling"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// PluginName is the name used to identify this admission webhook.
+const PluginName = "workload.kcp.io/WorkloadPriorityClass"
+
+// Register registers the WorkloadPriorityClass admission webhook.
+func Register(plugins *admission.Plugins) {
+	plugins.Register(PluginName,
+		func(_ io.Reader) (admission.Interface, error) {
+			return NewWorkloadPriorityClassAdmission(), nil
+		})
+}
+
+// WorkloadPriorityClassAdmission rejects WorkloadPriorityClasses using
+// priorities reserved for system components.
+type WorkloadPriorityClassAdmission struct {
+	*admission.Handler
+}
+
+// NewWorkloadPriorityClassAdmission constructs a new WorkloadPriorityClassAdmission admission plugin.
+func NewWorkloadPriorityClassAdmission() *WorkloadPriorityClassAdmission {
+	return &WorkloadPriorityClassAdmission{
+		Handler: admission.NewHandler(admission.Create, admission.Update),
+	}
+}
+
+// Ensure that the required admission interfaces are implemented.
+var _ = admission.ValidationInterface(&WorkloadPriorityClassAdmission{})
+
+// Validate ensures that the WorkloadPriorityClass is valid.
+func (p *WorkloadPriorityClassAdmission) Validate(_ context.Context, a admission.Attributes, _ admission.ObjectInterfaces) error {
+	if a.GetResource().GroupResource() != workloadv1alpha1.Resource("workloadpriorityclasses") || a.GetKind().GroupKind() != workloadv1alpha1.Kind("WorkloadPriorityClass") {
+		return nil
+	}
+
+	u, ok := a.GetObject().(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unexpected type %T", a.GetObject())
+	}
+	class := &workloadv1alpha1.WorkloadPriorityClass{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, class); err != nil {
+		return fmt.Errorf("failed to convert unstructured to WorkloadPriorityClass: %w", err)
+	}
+
+	var errs field.ErrorList
+	if strings.HasPrefix(class.Name, schedulingapi.SystemPriorityClassPrefix) {
+		errs = append(errs, field.Forbidden(field.NewPath("metadata", "name"), fmt.Sprintf("names starting with %q are reserved for system priority classes", schedulingapi.SystemPriorityClassPrefix)))
+	}
+	errs = append(errs, ValidateSpec(&class.Spec, field.NewPath("spec"))...)
+	if len(errs) > 0 {
+		return admission.NewForbidden(a, errs.ToAggregate())
+	}
+	return nil
+}
+
+// ValidateSpec validates a WorkloadPriorityClass spec.
+func ValidateSpec(spec *workloadv1alpha1.WorkloadPriorityClassSpec, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if spec.Value > schedulingapi.HighestUserDefinablePriority {
+		errs = append(errs, field.Invalid(path.Child("value"), spec.Value, fmt.Sprintf("values above %d are reserved for system priority classes", schedulingapi.HighestUserDefinablePriority)))
+	}
+	if spec.PreemptionPolicy != nil {
+		switch *spec.PreemptionPolicy {
+		case corev1.PreemptLowerPriority, corev1.PreemptNever:
+		default:
+			errs = append(errs, field.NotSupported(path.Child("preemptionPolicy"), *spec.PreemptionPolicy, []corev1.PreemptionPolicy{corev1.PreemptLowerPriority, corev1.PreemptNever}))
+		}
+	}
+	if name := spec.DownstreamName; name != "" {
+		namePath := path.Child("downstreamName")
+		for _, msg := range validation.IsDNS1123Subdomain(name) {
+			errs = append(errs, field.Invalid(namePath, name, msg))
+		}
+		if strings.HasPrefix(name, schedulingapi.SystemPriorityClassPrefix) {
+			errs = append(errs, field.Forbidden(namePath, fmt.Sprintf("names starting with %q are reserved for system priority classes", schedulingapi.SystemPriorityClassPrefix)))
+		}
+	}
+	switch spec.CreationPolicy {
+	case "", workloadv1alpha1.PriorityClassCreateIfAbsent, workloadv1alpha1.PriorityClassCreateNever:
+	default:
+		errs = append(errs, field.NotSupported(path.Child("creationPolicy"), spec.CreationPolicy, []workloadv1alpha1.PriorityClassCreationPolicy{
+			workloadv1alpha1.PriorityClassCreateIfAbsent,
+			workloadv1alpha1.PriorityClassCreateNever,
+		}))
+	}
+	return errs
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadpriorityclass
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/utils/ptr"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+func createAttr(t *testing.T, name string, spec workloadv1alpha1.WorkloadPriorityClassSpec) admission.Attributes {
+	t.Helper()
+	class := &workloadv1alpha1.WorkloadPriorityClass{
+		TypeMeta:   metav1.TypeMeta{APIVersion: workloadv1alpha1.SchemeGroupVersion.String(), Kind: "WorkloadPriorityClass"},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       spec,
+	}
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(class)
+	require.NoError(t, err)
+	return admission.NewAttributesRecord(
+		&unstructured.Unstructured{Object: raw},
+		nil,
+		workloadv1alpha1.Kind("WorkloadPriorityClass").WithVersion("v1alpha1"),
+		"",
+		name,
+		workloadv1alpha1.Resource("workloadpriorityclasses").WithVersion("v1alpha1"),
+		"",
+		admission.Create,
+		&metav1.CreateOptions{},
+		false,
+		&user.DefaultInfo{},
+	)
+}
+
+func TestValidate(t *testing.T) {
+	tests := map[string]struct {
+		name    string
+		spec    workloadv1alpha1.WorkloadPriorityClassSpec
+		wantErr string
+	}{
+		"valid": {
+			name: "high",
+			spec: workloadv1alpha1.WorkloadPriorityClassSpec{
+				Value:            1000000000,
+				PreemptionPolicy: ptr.To(corev1.PreemptNever),
+				DownstreamName:   "tenant-high",
+				CreationPolicy:   workloadv1alpha1.PriorityClassCreateNever,
+			},
+		},
+		"negative value": {
+			name: "low",
+			spec: workloadv1alpha1.WorkloadPriorityClassSpec{Value: -10},
+		},
+		"reserved value": {
+			name:    "critical",
+			spec:    workloadv1alpha1.WorkloadPriorityClassSpec{Value: 2000000000},
+			wantErr: "spec.value: Invalid value",
+		},
+		"reserved name": {
+			name:    "system-critical",
+			spec:    workloadv1alpha1.WorkloadPriorityClassSpec{Value: 1},
+			wantErr: "metadata.name: Forbidden",
+		},
+		"reserved downstream name": {
+			name:    "critical",
+			spec:    workloadv1alpha1.WorkloadPriorityClassSpec{Value: 1, DownstreamName: "system-node-critical"},
+			wantErr: "spec.downstreamName: Forbidden",
+		},
+		"invalid downstream name": {
+			name:    "high",
+			spec:    workloadv1alpha1.WorkloadPriorityClassSpec{Value: 1, DownstreamName: "Not_A_Name"},
+			wantErr: "spec.downstreamName: Invalid value",
+		},
+		"unknown preemption policy": {
+			name:    "high",
+			spec:    workloadv1alpha1.WorkloadPriorityClassSpec{Value: 1, PreemptionPolicy: ptr.To(corev1.PreemptionPolicy("Sometimes"))},
+			wantErr: "spec.preemptionPolicy: Unsupported value",
+		},
+		"unknown creation policy": {
+			name:    "high",
+			spec:    workloadv1alpha1.WorkloadPriorityClassSpec{Value: 1, CreationPolicy: "Always"},
+			wantErr: "spec.creationPolicy: Unsupported value",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := NewWorkloadPriorityClassAdmission().Validate(context.Background(), createAttr(t, tc.name, tc.spec), nil)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.True(t, apierrors.IsForbidden(err), "expected forbidden, got %v", err)
+			require.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
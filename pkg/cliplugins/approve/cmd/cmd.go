@@ -0,0 +1,62 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/approve/plugin"
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+)
+
+var (
+	approveExample = `
+# Approve promoting the revision rolled out by a placement policy.
+%[1]s approve spread-across-zones --message "canaries look good"
+`
+)
+
+// New provides a command for approving placement policy rollouts.
+func New(streams base.IOStreams) *cobra.Command {
+	approveOptions := plugin.NewApproveOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "approve PLACEMENT_POLICY",
+		Short:        "Approve promoting the revision rolled out by a placement policy",
+		Long:         "Approve promoting the revision rolled out by a placement policy with spec.rollout.requireApproval. The approver and time are recorded in the rollout history.",
+		Example:      fmt.Sprintf(approveExample, "kubectl tmc"),
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := approveOptions.Complete(args); err != nil {
+				return err
+			}
+
+			if err := approveOptions.Validate(); err != nil {
+				return err
+			}
+
+			return approveOptions.Run(c.Context())
+		},
+	}
+
+	approveOptions.BindFlags(cmd)
+
+	return cmd
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+)
+
+var placementPolicyGVR = placementv1alpha1.SchemeGroupVersion.WithResource("placementpolicies")
+
+// ApproveOptions contains options for approving the rollout of a
+// PlacementPolicy revision.
+type ApproveOptions struct {
+	*base.Options
+
+	// Name is the PlacementPolicy whose rollout is approved.
+	Name string
+	// Message is recorded with the approval in the rollout history.
+	Message string
+
+	// getPolicy gets a PlacementPolicy of the current workspace.
+	getPolicy func(ctx context.Context, name string) (*placementv1alpha1.PlacementPolicy, error)
+	// patchStatus merge-patches the status of a PlacementPolicy of the
+	// current workspace.
+	patchStatus func(ctx context.Context, name string, patch []byte) error
+}
+
+// NewApproveOptions returns a new ApproveOptions.
+func NewApproveOptions(streams base.IOStreams) *ApproveOptions {
+	return &ApproveOptions{
+		Options: base.NewOptions(streams),
+	}
+}
+
+// BindFlags binds fields ApproveOptions as command line flags to cmd's flagset.
+func (o *ApproveOptions) BindFlags(cmd *cobra.Command) {
+	o.Options.BindFlags(cmd)
+
+	cmd.Flags().StringVar(&o.Message, "message", o.Message, "Message recorded with the approval")
+}
+
+// Complete ensures all dynamically populated fields are initialized.
+func (o *ApproveOptions) Complete(args []string) error {
+	if len(args) > 0 {
+		o.Name = args[0]
+	}
+	if err := o.Options.Complete(); err != nil {
+		return err
+	}
+	if o.getPolicy != nil && o.patchStatus != nil {
+		return nil
+	}
+
+	config, err := o.ClientConfig.ClientConfig()
+	if err != nil {
+		return err
+	}
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	o.getPolicy = func(ctx context.Context, name string) (*placementv1alpha1.PlacementPolicy, error) {
+		u, err := client.Resource(placementPolicyGVR).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		policy := &placementv1alpha1.PlacementPolicy{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, policy); err != nil {
+			return nil, err
+		}
+		return policy, nil
+	}
+	o.patchStatus = func(ctx context.Context, name string, patch []byte) error {
+		_, err := client.Resource(placementPolicyGVR).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{}, "status")
+		return err
+	}
+	return nil
+}
+
+// Validate validates the ApproveOptions are complete and usable.
+func (o *ApproveOptions) Validate() error {
+	var errs []error
+
+	if err := o.Options.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if o.Name == "" {
+		errs = append(errs, fmt.Errorf("a placement policy name is required"))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// Run approves the revision currently rolled out by the placement policy.
+func (o *ApproveOptions) Run(ctx context.Context) error {
+	policy, err := o.getPolicy(ctx, o.Name)
+	if err != nil {
+		return err
+	}
+	rollout := policy.Status.Rollout
+	if rollout == nil || rollout.Revision == "" || rollout.Phase == placementv1alpha1.PolicyRolloutPromoted || rollout.Phase == placementv1alpha1.PolicyRolloutRolledBack {
+		return fmt.Errorf("placement policy %q has no rollout in progress", o.Name)
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"rollout": map[string]interface{}{
+				"approval": placementv1alpha1.RolloutApproval{
+					Revision: rollout.Revision,
+					Message:  o.Message,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err := o.patchStatus(ctx, o.Name, patch); err != nil {
+		return fmt.Errorf("failed to approve the rollout of placement policy %q: %w", o.Name, err)
+	}
+
+	if rollout.Phase == placementv1alpha1.PolicyRolloutAwaitingApproval {
+		fmt.Fprintf(o.Out, "Approved revision %s of placement policy %q.\n", rollout.Revision, o.Name)
+	} else {
+		fmt.Fprintf(o.Out, "Approved revision %s of placement policy %q ahead of its canary analysis.\n", rollout.Revision, o.Name)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+)
+
+func TestApprove(t *testing.T) {
+	tests := map[string]struct {
+		rollout   *placementv1alpha1.PolicyRolloutStatus
+		wantPatch string
+		wantErr   string
+	}{
+		"awaiting approval": {
+			rollout:   &placementv1alpha1.PolicyRolloutStatus{Revision: "rev-2", Phase: placementv1alpha1.PolicyRolloutAwaitingApproval},
+			wantPatch: `{"status":{"rollout":{"approval":{"revision":"rev-2","message":"lgtm"}}}}`,
+		},
+		"progressing": {
+			rollout:   &placementv1alpha1.PolicyRolloutStatus{Revision: "rev-2", Phase: placementv1alpha1.PolicyRolloutProgressing},
+			wantPatch: `{"status":{"rollout":{"approval":{"revision":"rev-2","message":"lgtm"}}}}`,
+		},
+		"no rollout": {
+			wantErr: `placement policy "policy" has no rollout in progress`,
+		},
+		"promoted": {
+			rollout: &placementv1alpha1.PolicyRolloutStatus{Revision: "rev-2", Phase: placementv1alpha1.PolicyRolloutPromoted},
+			wantErr: `placement policy "policy" has no rollout in progress`,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var patch string
+			out := &bytes.Buffer{}
+			o := NewApproveOptions(base.IOStreams{Out: out, ErrOut: &bytes.Buffer{}})
+			o.Name = "policy"
+			o.Message = "lgtm"
+			o.getPolicy = func(_ context.Context, name string) (*placementv1alpha1.PlacementPolicy, error) {
+				require.Equal(t, "policy", name)
+				return &placementv1alpha1.PlacementPolicy{Status: placementv1alpha1.PlacementPolicyStatus{Rollout: tc.rollout}}, nil
+			}
+			o.patchStatus = func(_ context.Context, name string, p []byte) error {
+				require.Equal(t, "policy", name)
+				patch = string(p)
+				return nil
+			}
+
+			err := o.Run(context.Background())
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				require.Empty(t, patch)
+				return
+			}
+			require.NoError(t, err)
+			require.JSONEq(t, tc.wantPatch, patch)
+			require.Contains(t, out.String(), "Approved revision rev-2")
+		})
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/approvesynctarget/plugin"
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+)
+
+var (
+	approveSyncTargetExample = `
+# Approve the registration of a SyncTarget after comparing the key fingerprint with the one the registering cluster printed.
+%[1]s approve-synctarget us-east-1 --fingerprint SHA256:2c6bU5I1ZlR3zjJ8s3uF6lq4Zq9Q0tq6rQ5cT3JpR1s
+`
+)
+
+// New provides a command for approving self-registered SyncTargets.
+func New(streams base.IOStreams) *cobra.Command {
+	approveOptions := plugin.NewApproveSyncTargetOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "approve-synctarget SYNC_TARGET",
+		Short:        "Approve the registration of a SyncTarget",
+		Long:         "Approve the registration of a SyncTarget requested through the onboarding virtual workspace. Once approved, the registering cluster can fetch its syncer bootstrap manifests, and workloads are placed on the SyncTarget.",
+		Example:      fmt.Sprintf(approveSyncTargetExample, "kubectl tmc"),
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := approveOptions.Complete(args); err != nil {
+				return err
+			}
+
+			if err := approveOptions.Validate(); err != nil {
+				return err
+			}
+
+			return approveOptions.Run(c.Context())
+		},
+	}
+
+	approveOptions.BindFlags(cmd)
+
+	return cmd
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/pkg/virtual/onboarding"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+var syncTargetGVR = tmcv1alpha1.SchemeGroupVersion.WithResource("synctargets")
+
+// ApproveSyncTargetOptions contains options for approving the registration
+// of a SyncTarget.
+type ApproveSyncTargetOptions struct {
+	*base.Options
+
+	// Name is the SyncTarget whose registration is approved.
+	Name string
+	// Fingerprint, if set, must match the fingerprint of the registered
+	// public key.
+	Fingerprint string
+
+	// getSyncTarget gets a SyncTarget of the current workspace.
+	getSyncTarget func(ctx context.Context, name string) (*tmcv1alpha1.SyncTarget, error)
+	// patchSyncTarget merge-patches a SyncTarget of the current workspace.
+	patchSyncTarget func(ctx context.Context, name string, patch []byte) error
+}
+
+// NewApproveSyncTargetOptions returns a new ApproveSyncTargetOptions.
+func NewApproveSyncTargetOptions(streams base.IOStreams) *ApproveSyncTargetOptions {
+	return &ApproveSyncTargetOptions{
+		Options: base.NewOptions(streams),
+	}
+}
+
+// BindFlags binds fields ApproveSyncTargetOptions as command line flags to cmd's flagset.
+func (o *ApproveSyncTargetOptions) BindFlags(cmd *cobra.Command) {
+	o.Options.BindFlags(cmd)
+
+	cmd.Flags().StringVar(&o.Fingerprint, "fingerprint", o.Fingerprint, "Expected fingerprint of the registered public key, as printed by the registering cluster")
+}
+
+// Complete ensures all dynamically populated fields are initialized.
+func (o *ApproveSyncTargetOptions) Complete(args []string) error {
+	if len(args) > 0 {
+		o.Name = args[0]
+	}
+	if err := o.Options.Complete(); err != nil {
+		return err
+	}
+	if o.getSyncTarget != nil && o.patchSyncTarget != nil {
+		return nil
+	}
+
+	config, err := o.ClientConfig.ClientConfig()
+	if err != nil {
+		return err
+	}
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	o.getSyncTarget = func(ctx context.Context, name string) (*tmcv1alpha1.SyncTarget, error) {
+		u, err := client.Resource(syncTargetGVR).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		syncTarget := &tmcv1alpha1.SyncTarget{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, syncTarget); err != nil {
+			return nil, err
+		}
+		return syncTarget, nil
+	}
+	o.patchSyncTarget = func(ctx context.Context, name string, patch []byte) error {
+		_, err := client.Resource(syncTargetGVR).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return err
+	}
+	return nil
+}
+
+// Validate validates the ApproveSyncTargetOptions are complete and usable.
+func (o *ApproveSyncTargetOptions) Validate() error {
+	var errs []error
+
+	if err := o.Options.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if o.Name == "" {
+		errs = append(errs, fmt.Errorf("a sync target name is required"))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// Run approves the registration of the SyncTarget.
+func (o *ApproveSyncTargetOptions) Run(ctx context.Context) error {
+	syncTarget, err := o.getSyncTarget(ctx, o.Name)
+	if err != nil {
+		return err
+	}
+	registration := syncTarget.Spec.Registration
+	if registration == nil {
+		return fmt.Errorf("sync target %q was not registered through onboarding", o.Name)
+	}
+	if registration.Approved {
+		return fmt.Errorf("the registration of sync target %q is already approved", o.Name)
+	}
+
+	fingerprint, err := onboarding.Fingerprint(registration.PublicKey)
+	if err != nil {
+		return fmt.Errorf("sync target %q has an invalid public key: %w", o.Name, err)
+	}
+	fmt.Fprintf(o.Out, "Sync target %q was requested by %q with key %s.\n", o.Name, registration.RequestedBy, fingerprint)
+	if o.Fingerprint != "" && o.Fingerprint != fingerprint {
+		return fmt.Errorf("the key of sync target %q does not match fingerprint %s", o.Name, o.Fingerprint)
+	}
+
+	if err := o.patchSyncTarget(ctx, o.Name, []byte(`{"spec":{"registration":{"approved":true}}}`)); err != nil {
+		return fmt.Errorf("failed to approve sync target %q: %w", o.Name, err)
+	}
+	fmt.Fprintf(o.Out, "Approved the registration of sync target %q.\n", o.Name)
+	return nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/pkg/virtual/onboarding"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func TestApproveSyncTarget(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	publicKey, err := onboarding.EncodePublicKey(key.Public())
+	require.NoError(t, err)
+	fingerprint, err := onboarding.Fingerprint(publicKey)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		registration *tmcv1alpha1.SyncTargetRegistration
+		fingerprint  string
+		wantErr      string
+	}{
+		"pending": {
+			registration: &tmcv1alpha1.SyncTargetRegistration{PublicKey: publicKey, RequestedBy: "admin"},
+		},
+		"matching fingerprint": {
+			registration: &tmcv1alpha1.SyncTargetRegistration{PublicKey: publicKey, RequestedBy: "admin"},
+			fingerprint:  fingerprint,
+		},
+		"other fingerprint": {
+			registration: &tmcv1alpha1.SyncTargetRegistration{PublicKey: publicKey, RequestedBy: "admin"},
+			fingerprint:  "SHA256:other",
+			wantErr:      `the key of sync target "east" does not match fingerprint SHA256:other`,
+		},
+		"not registered": {
+			wantErr: `sync target "east" was not registered through onboarding`,
+		},
+		"approved": {
+			registration: &tmcv1alpha1.SyncTargetRegistration{PublicKey: publicKey, Approved: true},
+			wantErr:      `the registration of sync target "east" is already approved`,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var patch string
+			out := &bytes.Buffer{}
+			o := NewApproveSyncTargetOptions(base.IOStreams{Out: out, ErrOut: &bytes.Buffer{}})
+			o.Name = "east"
+			o.Fingerprint = tc.fingerprint
+			o.getSyncTarget = func(_ context.Context, name string) (*tmcv1alpha1.SyncTarget, error) {
+				require.Equal(t, "east", name)
+				return &tmcv1alpha1.SyncTarget{Spec: tmcv1alpha1.SyncTargetSpec{Registration: tc.registration}}, nil
+			}
+			o.patchSyncTarget = func(_ context.Context, name string, p []byte) error {
+				require.Equal(t, "east", name)
+				patch = string(p)
+				return nil
+			}
+
+			err := o.Run(context.Background())
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				require.Empty(t, patch)
+				return
+			}
+			require.NoError(t, err)
+			require.JSONEq(t, `{"spec":{"registration":{"approved":true}}}`, patch)
+			require.Contains(t, out.String(), fingerprint)
+			require.Contains(t, out.String(), `requested by "admin"`)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package base contains options shared by the kubectl-tmc plugin commands.
+// It mirrors github.com/kcp-dev/kcp/cli/pkg/base for commands that need the
+// TMC libraries of the main module.
+package base
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// IOStreams provides the standard names for iostreams.
+type IOStreams struct {
+	// In think, os.Stdin
+	In io.Reader
+	// Out think, os.Stdout
+	Out io.Writer
+	// ErrOut think, os.Stderr
+	ErrOut io.Writer
+}
+
+// Options contains options common to most CLI plugins, including settings for connecting to kcp (kubeconfig, etc).
+type Options struct {
+	// Kubeconfig specifies kubeconfig file(s).
+	Kubeconfig string
+	// KubectlOverrides stores the extra client connection fields, such as context, user, etc.
+	KubectlOverrides *clientcmd.ConfigOverrides
+
+	IOStreams
+
+	// ClientConfig is the resolved clientcmd.ClientConfig based on the client connection flags. This is only valid
+	// after calling Complete.
+	ClientConfig clientcmd.ClientConfig
+}
+
+// NewOptions provides an instance of Options with default values.
+func NewOptions(streams IOStreams) *Options {
+	return &Options{
+		KubectlOverrides: &clientcmd.ConfigOverrides{},
+		IOStreams:        streams,
+	}
+}
+
+// BindFlags binds options fields to cmd's flagset.
+func (o *Options) BindFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.Kubeconfig, "kubeconfig", o.Kubeconfig, "path to the kubeconfig file")
+
+	// We add only a subset of kubeconfig-related flags to the plugin.
+	// All those with LongName == "" will be ignored.
+	kubectlConfigOverrideFlags := clientcmd.RecommendedConfigOverrideFlags("")
+	kubectlConfigOverrideFlags.AuthOverrideFlags.ClientCertificate.LongName = ""
+	kubectlConfigOverrideFlags.AuthOverrideFlags.ClientKey.LongName = ""
+	kubectlConfigOverrideFlags.AuthOverrideFlags.Impersonate.LongName = ""
+	kubectlConfigOverrideFlags.AuthOverrideFlags.ImpersonateGroups.LongName = ""
+	kubectlConfigOverrideFlags.ContextOverrideFlags.ClusterName.LongName = ""
+	kubectlConfigOverrideFlags.Timeout.LongName = ""
+
+	clientcmd.BindOverrideFlags(o.KubectlOverrides, cmd.PersistentFlags(), kubectlConfigOverrideFlags)
+}
+
+// Complete initializes ClientConfig based on Kubeconfig and KubectlOverrides.
+func (o *Options) Complete() error {
+	if o.ClientConfig == nil {
+		loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+		loadingRules.ExplicitPath = o.Kubeconfig
+
+		startingConfig, err := loadingRules.GetStartingConfig()
+		if err != nil {
+			return err
+		}
+
+		o.ClientConfig = clientcmd.NewDefaultClientConfig(*startingConfig, o.KubectlOverrides)
+	}
+
+	return nil
+}
+
+// Validate validates the configured options.
+func (o *Options) Validate() error {
+	return nil
+}
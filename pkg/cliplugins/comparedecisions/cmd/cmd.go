@@ -0,0 +1,65 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/pkg/cliplugins/comparedecisions/plugin"
+)
+
+var (
+	compareDecisionsExample = `
+# Show why a workload moved between two placement decisions.
+%[1]s compare-decisions 41 42 --history decisions.json
+
+# Print the difference as JSON.
+%[1]s compare-decisions 41 42 --history decisions.json -o json
+`
+)
+
+// New provides a command for comparing two placement decisions.
+func New(streams base.IOStreams) *cobra.Command {
+	compareOptions := plugin.NewCompareDecisionsOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "compare-decisions <from-id> <to-id>",
+		Short:        "Compare two placement decisions for the same workload",
+		Long:         "Compare two placement decisions for the same workload: the SyncTargets added and removed, the scores that changed per scorer, and the SyncTargets whose constraints flipped.",
+		Example:      fmt.Sprintf(compareDecisionsExample, "kubectl tmc"),
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := compareOptions.Complete(args); err != nil {
+				return err
+			}
+
+			if err := compareOptions.Validate(); err != nil {
+				return err
+			}
+
+			return compareOptions.Run(c.Context())
+		},
+	}
+
+	compareOptions.BindFlags(cmd)
+
+	return cmd
+}
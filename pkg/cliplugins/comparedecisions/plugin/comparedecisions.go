@@ -0,0 +1,225 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/pkg/placement/decision"
+)
+
+// CompareDecisionsOptions contains options for comparing two placement
+// decisions.
+type CompareDecisionsOptions struct {
+	*base.Options
+
+	// History is a JSON array of decision records, or - for stdin.
+	History string
+	// Workspace restricts the records to a workspace, for histories
+	// spanning several workspaces with clashing IDs.
+	Workspace string
+	// Output is the output format, table or json.
+	Output string
+
+	fromID, toID string
+
+	// readHistory reads the decision records.
+	readHistory func() ([]decision.DecisionRecord, error)
+}
+
+// NewCompareDecisionsOptions returns a new CompareDecisionsOptions.
+func NewCompareDecisionsOptions(streams base.IOStreams) *CompareDecisionsOptions {
+	return &CompareDecisionsOptions{
+		Options: base.NewOptions(streams),
+		Output:  "table",
+	}
+}
+
+// BindFlags binds fields CompareDecisionsOptions as command line flags to cmd's flagset.
+func (o *CompareDecisionsOptions) BindFlags(cmd *cobra.Command) {
+	o.Options.BindFlags(cmd)
+
+	cmd.Flags().StringVar(&o.History, "history", o.History, "JSON array of the decision records to look the IDs up in, or - to read it from stdin")
+	cmd.Flags().StringVar(&o.Workspace, "workspace", o.Workspace, "Workspace of the decisions, if the history spans several workspaces")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format, table or json")
+}
+
+// Complete ensures all dynamically populated fields are initialized.
+func (o *CompareDecisionsOptions) Complete(args []string) error {
+	if err := o.Options.Complete(); err != nil {
+		return err
+	}
+	if len(args) == 2 {
+		o.fromID, o.toID = args[0], args[1]
+	}
+	if o.readHistory != nil {
+		return nil
+	}
+
+	o.readHistory = func() ([]decision.DecisionRecord, error) {
+		var data []byte
+		var err error
+		if o.History == "-" {
+			data, err = io.ReadAll(o.In)
+		} else {
+			data, err = os.ReadFile(o.History)
+		}
+		if err != nil {
+			return nil, err
+		}
+		var records []decision.DecisionRecord
+		if err := json.Unmarshal(data, &records); err != nil {
+			return nil, fmt.Errorf("failed to decode history %s: %w", o.History, err)
+		}
+		return records, nil
+	}
+	return nil
+}
+
+// Validate validates the CompareDecisionsOptions are complete and usable.
+func (o *CompareDecisionsOptions) Validate() error {
+	var errs []error
+
+	if err := o.Options.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if o.fromID == "" || o.toID == "" {
+		errs = append(errs, fmt.Errorf("two decision IDs are required"))
+	}
+	if o.History == "" {
+		errs = append(errs, fmt.Errorf("a decision history is required, use --history"))
+	}
+	if o.Output != "table" && o.Output != "json" {
+		errs = append(errs, fmt.Errorf("invalid output format %q, must be table or json", o.Output))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// Run looks up both decisions and prints how they differ.
+func (o *CompareDecisionsOptions) Run(ctx context.Context) error {
+	records, err := o.readHistory()
+	if err != nil {
+		return err
+	}
+	from, err := o.find(records, o.fromID)
+	if err != nil {
+		return err
+	}
+	to, err := o.find(records, o.toID)
+	if err != nil {
+		return err
+	}
+	diff, err := decision.Compare(from, to)
+	if err != nil {
+		return err
+	}
+
+	if o.Output == "json" {
+		enc := json.NewEncoder(o.Out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(diff)
+	}
+	if diff.Empty() {
+		fmt.Fprintf(o.ErrOut, "Decisions %s and %s do not differ.\n", from.ID, to.ID)
+		return nil
+	}
+	return printDiff(o.Out, diff)
+}
+
+// find returns the record with id, which must be unique in the workspace.
+func (o *CompareDecisionsOptions) find(records []decision.DecisionRecord, id string) (*decision.DecisionRecord, error) {
+	var found *decision.DecisionRecord
+	for i := range records {
+		r := &records[i]
+		if r.ID != id || (o.Workspace != "" && r.Workspace != logicalcluster.Name(o.Workspace)) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("decision %s is ambiguous, it exists in workspaces %s and %s, use --workspace", id, found.Workspace, r.Workspace)
+		}
+		found = r
+	}
+	if found == nil {
+		return nil, fmt.Errorf("decision %s not found", id)
+	}
+	return found, nil
+}
+
+func printDiff(out io.Writer, diff *decision.DecisionDiff) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CHANGE\tSYNCTARGET\tSCORER\tFROM\tTO")
+	for _, c := range []struct {
+		name   string
+		change *decision.Change
+	}{
+		{"Policy", diff.PolicyChange},
+		{"PolicyRevision", diff.PolicyRevisionChange},
+		{"Status", diff.StatusChange},
+	} {
+		if c.change != nil {
+			fmt.Fprintf(w, "%s\t-\t-\t%s\t%s\n", c.name, c.change.From, c.change.To)
+		}
+	}
+	for _, name := range diff.TargetsAdded {
+		fmt.Fprintf(w, "Added\t%s\t-\t-\t-\n", name)
+	}
+	for _, name := range diff.TargetsRemoved {
+		fmt.Fprintf(w, "Removed\t%s\t-\t-\t-\n", name)
+	}
+	for _, c := range diff.ReplicaChanges {
+		fmt.Fprintf(w, "Replicas\t%s\t-\t%s\t%s\n", c.SyncTarget, optional(c.From), optional(c.To))
+	}
+	for _, c := range diff.ScoreChanges {
+		scorer := c.Scorer
+		if scorer == "" {
+			scorer = "weighted"
+		}
+		fmt.Fprintf(w, "Score\t%s\t%s\t%s\t%s\n", c.SyncTarget, scorer, optional(c.From), optional(c.To))
+	}
+	for _, c := range diff.ConstraintChanges {
+		fmt.Fprintf(w, "Constraint\t%s\t-\t%s\t%s\n", c.SyncTarget, reason(c.FromReason), reason(c.ToReason))
+	}
+	return w.Flush()
+}
+
+func optional[T int | int32](v *T) string {
+	if v == nil {
+		return "-"
+	}
+	return strconv.Itoa(int(*v))
+}
+
+func reason(r string) string {
+	if r == "" {
+		return "feasible"
+	}
+	return r
+}
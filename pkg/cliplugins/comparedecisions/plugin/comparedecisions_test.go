@@ -0,0 +1,82 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/pkg/placement/decision"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+func TestCompareDecisions(t *testing.T) {
+	records := []decision.DecisionRecord{
+		{
+			ID: "1", Workspace: "root:org", Namespace: "default", Name: "app", Status: decision.StatusSucceeded,
+			Targets:      []workloadv1alpha1.TargetPlacement{{SyncTarget: "eu-1"}},
+			Scores:       map[string]int{"eu-1": 80, "us-1": 40},
+			ScorerScores: map[string]map[string]int{"Locality": {"eu-1": 80, "us-1": 40}},
+		},
+		{
+			ID: "2", Workspace: "root:org", Namespace: "default", Name: "app", Status: decision.StatusSucceeded,
+			Targets:      []workloadv1alpha1.TargetPlacement{{SyncTarget: "us-1"}},
+			Scores:       map[string]int{"us-1": 40},
+			ScorerScores: map[string]map[string]int{"Locality": {"us-1": 40}},
+			Rejected:     map[string]string{"eu-1": "is cordoned"},
+		},
+		{ID: "1", Workspace: "root:other", Namespace: "default", Name: "app"},
+	}
+
+	run := func(workspace string, args ...string) (string, string, error) {
+		out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+		o := NewCompareDecisionsOptions(base.IOStreams{Out: out, ErrOut: errOut})
+		o.History = "decisions.json"
+		o.Workspace = workspace
+		o.readHistory = func() ([]decision.DecisionRecord, error) { return records, nil }
+		require.NoError(t, o.Complete(args))
+		if err := o.Validate(); err != nil {
+			return "", "", err
+		}
+		err := o.Run(context.Background())
+		return out.String(), errOut.String(), err
+	}
+
+	_, _, err := run("", "1", "2")
+	require.ErrorContains(t, err, "decision 1 is ambiguous")
+
+	out, _, err := run("root:org", "1", "2")
+	require.NoError(t, err)
+	require.Equal(t, `CHANGE      SYNCTARGET  SCORER    FROM      TO
+Added       us-1        -         -         -
+Removed     eu-1        -         -         -
+Score       eu-1        weighted  80        -
+Score       eu-1        Locality  80        -
+Constraint  eu-1        -         feasible  is cordoned
+`, out)
+
+	_, errOut, err := run("root:org", "2", "2")
+	require.NoError(t, err)
+	require.Contains(t, errOut, "Decisions 2 and 2 do not differ.")
+
+	_, _, err = run("root:org", "1", "3")
+	require.ErrorContains(t, err, "decision 3 not found")
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/pkg/cliplugins/configbundle/plugin"
+)
+
+var (
+	exportConfigExample = `
+# Export the TMC configuration of the current workspace.
+%[1]s export-config > staging.yaml
+`
+
+	importConfigExample = `
+# Show what importing a bundle exported from staging changes in the current workspace.
+%[1]s import-config -f staging.yaml --dry-run
+
+# Import the bundle, moving the references to the staging SyncTargets to the production ones.
+%[1]s import-config -f staging.yaml --sync-target-map staging-east=prod-east,staging-west=prod-west
+`
+)
+
+// NewExport provides a command for exporting the TMC configuration of a workspace.
+func NewExport(streams base.IOStreams) *cobra.Command {
+	exportOptions := plugin.NewExportOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "export-config",
+		Short:        "Export the TMC configuration of a workspace",
+		Long:         "Export the TMC configuration of the current workspace, i.e. its SyncTargetGroups, scheduling profiles, data locations, placement policies, priority classes, workload templates and distributions, as a versioned bundle. Status and server-populated metadata are left out, so the bundle can be imported into another workspace or environment with import-config.",
+		Example:      fmt.Sprintf(exportConfigExample, "kubectl tmc"),
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := exportOptions.Complete(args); err != nil {
+				return err
+			}
+
+			if err := exportOptions.Validate(); err != nil {
+				return err
+			}
+
+			return exportOptions.Run(c.Context())
+		},
+	}
+
+	exportOptions.BindFlags(cmd)
+
+	return cmd
+}
+
+// NewImport provides a command for importing a TMC configuration bundle into a workspace.
+func NewImport(streams base.IOStreams) *cobra.Command {
+	importOptions := plugin.NewImportOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "import-config -f BUNDLE",
+		Short:        "Import a TMC configuration bundle into a workspace",
+		Long:         "Import a bundle written by export-config into the current workspace. Missing objects are created and changed ones updated; objects not in the bundle are kept. With --dry-run, the changes are validated by the server and printed with a diff, but not made.",
+		Example:      fmt.Sprintf(importConfigExample, "kubectl tmc"),
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := importOptions.Complete(args); err != nil {
+				return err
+			}
+
+			if err := importOptions.Validate(); err != nil {
+				return err
+			}
+
+			return importOptions.Run(c.Context())
+		},
+	}
+
+	importOptions.BindFlags(cmd)
+
+	return cmd
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/sdk/apis/core"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+const (
+	// BundleAPIVersion is the version of the bundle format.
+	BundleAPIVersion = "tmc.kcp.io/v1alpha1"
+	// BundleKind is the kind of bundles.
+	BundleKind = "ConfigBundle"
+)
+
+// Resource is a TMC configuration resource carried in bundles.
+type Resource struct {
+	schema.GroupVersionResource
+	Kind       string
+	Namespaced bool
+}
+
+// Resources are the resources exported into bundles, in the order they are
+// imported, i.e. referenced objects before the objects referencing them.
+// Objects generated by controllers, like PlacementPolicyRevisions and
+// RightPlacementRecommendations, are left out, as are SyncTargets, which
+// belong to their environment.
+var Resources = []Resource{
+	{GroupVersionResource: tmcv1alpha1.SchemeGroupVersion.WithResource("synctargetgroups"), Kind: "SyncTargetGroup"},
+	{GroupVersionResource: placementv1alpha1.SchemeGroupVersion.WithResource("schedulingprofiles"), Kind: "SchedulingProfile"},
+	{GroupVersionResource: tmcv1alpha1.SchemeGroupVersion.WithResource("evictionpolicies"), Kind: "EvictionPolicy"},
+	{GroupVersionResource: tmcv1alpha1.SchemeGroupVersion.WithResource("multiclusterresourcequotas"), Kind: "MultiClusterResourceQuota"},
+	{GroupVersionResource: placementv1alpha1.SchemeGroupVersion.WithResource("datalocations"), Kind: "DataLocation"},
+	{GroupVersionResource: placementv1alpha1.SchemeGroupVersion.WithResource("placementpolicies"), Kind: "PlacementPolicy"},
+	{GroupVersionResource: placementv1alpha1.SchemeGroupVersion.WithResource("workloadplacementadvanceds"), Kind: "WorkloadPlacementAdvanced"},
+	{GroupVersionResource: workloadv1alpha1.SchemeGroupVersion.WithResource("workloadpriorityclasses"), Kind: "WorkloadPriorityClass"},
+	{GroupVersionResource: workloadv1alpha1.SchemeGroupVersion.WithResource("propagationpolicies"), Kind: "PropagationPolicy"},
+	{GroupVersionResource: workloadv1alpha1.SchemeGroupVersion.WithResource("schedulingdefaults"), Kind: "SchedulingDefaults"},
+	{GroupVersionResource: workloadv1alpha1.SchemeGroupVersion.WithResource("statusaggregationpolicies"), Kind: "StatusAggregationPolicy"},
+	{GroupVersionResource: workloadv1alpha1.SchemeGroupVersion.WithResource("syncconfigurations"), Kind: "SyncConfiguration"},
+	{GroupVersionResource: workloadv1alpha1.SchemeGroupVersion.WithResource("workloadtemplates"), Kind: "WorkloadTemplate", Namespaced: true},
+	{GroupVersionResource: workloadv1alpha1.SchemeGroupVersion.WithResource("workloaddistributions"), Kind: "WorkloadDistribution", Namespaced: true},
+	{GroupVersionResource: workloadv1alpha1.SchemeGroupVersion.WithResource("serviceexports"), Kind: "ServiceExport", Namespaced: true},
+}
+
+// resourceFor returns the bundle resource of the given object.
+func resourceFor(obj *unstructured.Unstructured) (Resource, bool) {
+	gvk := obj.GroupVersionKind()
+	for _, r := range Resources {
+		if r.GroupVersion() == gvk.GroupVersion() && r.Kind == gvk.Kind {
+			return r, true
+		}
+	}
+	return Resource{}, false
+}
+
+// Bundle is the TMC configuration of a workspace.
+type Bundle struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Source is the workspace the bundle was exported from.
+	Source string `json:"source,omitempty"`
+	// ExportedAt is when the bundle was exported.
+	ExportedAt metav1.Time `json:"exportedAt"`
+	// Objects are the exported objects, in import order.
+	Objects []unstructured.Unstructured `json:"objects"`
+}
+
+// Validate checks that the bundle is of a known version and only carries
+// bundle resources.
+func (b *Bundle) Validate() error {
+	if b.APIVersion != BundleAPIVersion || b.Kind != BundleKind {
+		return fmt.Errorf("unsupported bundle %s, Kind=%s, expected %s, Kind=%s", b.APIVersion, b.Kind, BundleAPIVersion, BundleKind)
+	}
+	for i := range b.Objects {
+		obj := &b.Objects[i]
+		r, ok := resourceFor(obj)
+		if !ok {
+			return fmt.Errorf("object %d: %s %q is not a TMC configuration resource", i, obj.GroupVersionKind(), obj.GetName())
+		}
+		if obj.GetName() == "" {
+			return fmt.Errorf("object %d: %s has no name", i, r.Kind)
+		}
+		if r.Namespaced != (obj.GetNamespace() != "") {
+			return fmt.Errorf("object %d: %s %q has an invalid namespace %q", i, r.Kind, obj.GetName(), obj.GetNamespace())
+		}
+	}
+	return nil
+}
+
+// clean strips the fields of obj that belong to the environment it was read
+// from: status, server-populated metadata and owner references.
+func clean(obj *unstructured.Unstructured) {
+	unstructured.RemoveNestedField(obj.Object, "status")
+	for _, field := range []string{"uid", "resourceVersion", "generation", "creationTimestamp", "deletionTimestamp", "deletionGracePeriodSeconds", "managedFields", "ownerReferences", "generateName"} {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+
+	annotations := obj.GetAnnotations()
+	delete(annotations, logicalcluster.AnnotationKey)
+	delete(annotations, core.LogicalClusterPathAnnotationKey)
+	delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	obj.SetAnnotations(annotations)
+}
+
+// SyncTargetMap renames the SyncTargets referenced by bundle objects, for
+// environments whose SyncTargets are named differently.
+type SyncTargetMap map[string]string
+
+// ParseSyncTargetMap parses old=new pairs.
+func ParseSyncTargetMap(pairs []string) (SyncTargetMap, error) {
+	m := SyncTargetMap{}
+	for _, pair := range pairs {
+		from, to, ok := strings.Cut(pair, "=")
+		if !ok || from == "" || to == "" {
+			return nil, fmt.Errorf("invalid sync target mapping %q, expected OLD=NEW", pair)
+		}
+		if _, found := m[from]; found {
+			return nil, fmt.Errorf("sync target %q is mapped more than once", from)
+		}
+		m[from] = to
+	}
+	return m, nil
+}
+
+// FixUp rewrites the SyncTarget references of obj and returns the number of
+// rewritten references.
+func (m SyncTargetMap) FixUp(obj *unstructured.Unstructured) (int, error) {
+	if len(m) == 0 {
+		return 0, nil
+	}
+	var fixed int
+	rename := func(name string) string {
+		if to, ok := m[name]; ok {
+			fixed++
+			return to
+		}
+		return name
+	}
+
+	switch obj.GetKind() {
+	case "SyncTargetGroup":
+		members, found, err := unstructured.NestedSlice(obj.Object, "spec", "members")
+		if err != nil || !found {
+			return 0, err
+		}
+		for _, member := range members {
+			if member, ok := member.(map[string]interface{}); ok {
+				if name, ok := member["name"].(string); ok {
+					member["name"] = rename(name)
+				}
+			}
+		}
+		return fixed, unstructured.SetNestedSlice(obj.Object, members, "spec", "members")
+	case "DataLocation":
+		syncTargets, found, err := unstructured.NestedStringSlice(obj.Object, "spec", "syncTargets")
+		if err != nil || !found {
+			return 0, err
+		}
+		for i := range syncTargets {
+			syncTargets[i] = rename(syncTargets[i])
+		}
+		return fixed, unstructured.SetNestedStringSlice(obj.Object, syncTargets, "spec", "syncTargets")
+	case "WorkloadDistribution":
+		overrides, found, err := unstructured.NestedSlice(obj.Object, "spec", "targetOverrides")
+		if err != nil || !found {
+			return 0, err
+		}
+		for _, override := range overrides {
+			if override, ok := override.(map[string]interface{}); ok {
+				for _, field := range []string{"from", "to"} {
+					if name, ok := override[field].(string); ok {
+						override[field] = rename(name)
+					}
+				}
+			}
+		}
+		return fixed, unstructured.SetNestedSlice(obj.Object, overrides, "spec", "targetOverrides")
+	}
+	return 0, nil
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+)
+
+func newObject(r Resource, namespace, name string, spec map[string]interface{}) unstructured.Unstructured {
+	obj := unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	obj.SetGroupVersionKind(r.GroupVersion().WithKind(r.Kind))
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+func resource(kind string) Resource {
+	for _, r := range Resources {
+		if r.Kind == kind {
+			return r
+		}
+	}
+	panic(kind)
+}
+
+func TestExportImport(t *testing.T) {
+	group, dataLocation, distribution := resource("SyncTargetGroup"), resource("DataLocation"), resource("WorkloadDistribution")
+
+	staging := map[schema.GroupVersionResource][]unstructured.Unstructured{
+		group.GroupVersionResource: {
+			newObject(group, "", "gpus", map[string]interface{}{"members": []interface{}{
+				map[string]interface{}{"name": "staging-east", "weight": int64(2)},
+				map[string]interface{}{"name": "edge"},
+			}}),
+		},
+		dataLocation.GroupVersionResource: {
+			newObject(dataLocation, "", "eu", map[string]interface{}{"syncTargets": []interface{}{"staging-east"}}),
+		},
+		distribution.GroupVersionResource: {
+			newObject(distribution, "team-b", "web", map[string]interface{}{"workloadRef": map[string]interface{}{"name": "web"}}),
+			newObject(distribution, "team-a", "api", map[string]interface{}{"targetOverrides": []interface{}{
+				map[string]interface{}{"from": "staging-east", "to": "staging-west"},
+			}}),
+		},
+	}
+	for _, objs := range staging {
+		for i := range objs {
+			objs[i].SetUID("0123")
+			objs[i].SetResourceVersion("42")
+			objs[i].SetAnnotations(map[string]string{"kcp.io/cluster": "staging", "team": "a"})
+			objs[i].Object["status"] = map[string]interface{}{"phase": "Ready"}
+		}
+	}
+
+	out := &bytes.Buffer{}
+	export := NewExportOptions(base.IOStreams{Out: out})
+	export.source = "root:staging"
+	export.now = func() time.Time { return time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC) }
+	export.listObjects = func(ctx context.Context, gvr schema.GroupVersionResource) ([]unstructured.Unstructured, error) {
+		return staging[gvr], nil
+	}
+	require.NoError(t, export.Run(context.Background()))
+	require.Contains(t, out.String(), "kind: ConfigBundle")
+	require.Contains(t, out.String(), "source: root:staging")
+	require.NotContains(t, out.String(), "uid:")
+	require.NotContains(t, out.String(), "resourceVersion:")
+	require.NotContains(t, out.String(), "kcp.io/cluster")
+	require.NotContains(t, out.String(), "status:")
+
+	bundlePath := filepath.Join(t.TempDir(), "staging.yaml")
+	require.NoError(t, os.WriteFile(bundlePath, out.Bytes(), 0o600))
+
+	// production already has the data location, and an outdated distribution
+	prod := map[string]*unstructured.Unstructured{}
+	key := func(gvr schema.GroupVersionResource, namespace, name string) string {
+		return gvr.Resource + "/" + namespace + "/" + name
+	}
+	eu := newObject(dataLocation, "", "eu", map[string]interface{}{"syncTargets": []interface{}{"prod-east"}})
+	eu.SetAnnotations(map[string]string{"kcp.io/cluster": "prod", "team": "a"})
+	eu.SetResourceVersion("7")
+	prod[key(dataLocation.GroupVersionResource, "", "eu")] = &eu
+	api := newObject(distribution, "team-a", "api", map[string]interface{}{"paused": true})
+	api.SetAnnotations(map[string]string{"kcp.io/cluster": "prod"})
+	api.SetResourceVersion("8")
+	api.Object["status"] = map[string]interface{}{"phase": "Paused"}
+	prod[key(distribution.GroupVersionResource, "team-a", "api")] = &api
+
+	var created, updated []*unstructured.Unstructured
+	out.Reset()
+	o := NewImportOptions(base.IOStreams{Out: out})
+	o.Filename = bundlePath
+	o.DryRun = true
+	o.SyncTargetMappings = []string{"staging-east=prod-east", "staging-west=prod-west"}
+	o.getObject = func(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+		if obj, ok := prod[key(gvr, namespace, name)]; ok {
+			return obj.DeepCopy(), nil
+		}
+		return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+	}
+	o.createObject = func(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, dryRun bool) error {
+		require.True(t, dryRun)
+		created = append(created, obj)
+		return nil
+	}
+	o.updateObject = func(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, dryRun bool) error {
+		require.True(t, dryRun)
+		updated = append(updated, obj)
+		return nil
+	}
+	require.NoError(t, o.Validate())
+	require.NoError(t, o.Run(context.Background()))
+
+	lines := strings.Split(out.String(), "\n")
+	require.Equal(t, []string{
+		"synctargetgroup.tmc.kcp.io/gpus created (dry run)",
+		"datalocation.placement.kcp.io/eu unchanged",
+		"team-a/workloaddistribution.workload.kcp.io/api configured (dry run)",
+	}, lines[:3])
+	// the diff is of the existing object to the imported one
+	// cmp randomly uses non-breaking spaces in its diffs.
+	require.Regexp(t, `\n[\s\x{a0}]+-[\s\x{a0}]+"paused":[\s\x{a0}]+bool\(true\)`, out.String())
+	require.Regexp(t, `\n[\s\x{a0}]+\+[\s\x{a0}]+"targetOverrides":.*string\("prod-west"\)`, out.String())
+	require.Equal(t, "team-b/workloaddistribution.workload.kcp.io/web created (dry run)", lines[len(lines)-3])
+	require.Equal(t, "2 created, 1 configured, 1 unchanged (dry run)", lines[len(lines)-2])
+
+	require.Len(t, created, 2)
+	members, _, _ := unstructured.NestedSlice(created[0].Object, "spec", "members")
+	require.Equal(t, "prod-east", members[0].(map[string]interface{})["name"])
+	require.Equal(t, "edge", members[1].(map[string]interface{})["name"])
+
+	require.Len(t, updated, 1)
+	require.Equal(t, "8", updated[0].GetResourceVersion())
+	require.Equal(t, map[string]string{"kcp.io/cluster": "prod", "team": "a"}, updated[0].GetAnnotations())
+	require.Equal(t, map[string]interface{}{"phase": "Paused"}, updated[0].Object["status"])
+	_, paused, _ := unstructured.NestedBool(updated[0].Object, "spec", "paused")
+	require.False(t, paused)
+}
+
+func TestImportInvalidBundle(t *testing.T) {
+	for name, tc := range map[string]struct {
+		bundle  string
+		wantErr string
+	}{
+		"unknown version": {
+			bundle:  "apiVersion: tmc.kcp.io/v2\nkind: ConfigBundle\nexportedAt: null\nobjects: []\n",
+			wantErr: "unsupported bundle tmc.kcp.io/v2, Kind=ConfigBundle",
+		},
+		"unknown resource": {
+			bundle:  "apiVersion: tmc.kcp.io/v1alpha1\nkind: ConfigBundle\nexportedAt: null\nobjects:\n- apiVersion: tmc.kcp.io/v1alpha1\n  kind: SyncTarget\n  metadata:\n    name: east\n",
+			wantErr: `SyncTarget "east" is not a TMC configuration resource`,
+		},
+		"missing namespace": {
+			bundle:  "apiVersion: tmc.kcp.io/v1alpha1\nkind: ConfigBundle\nexportedAt: null\nobjects:\n- apiVersion: workload.kcp.io/v1alpha1\n  kind: WorkloadTemplate\n  metadata:\n    name: web\n",
+			wantErr: `WorkloadTemplate "web" has an invalid namespace ""`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			o := NewImportOptions(base.IOStreams{In: strings.NewReader(tc.bundle), Out: &bytes.Buffer{}})
+			o.Filename = "-"
+			require.NoError(t, o.Validate())
+			require.ErrorContains(t, o.Run(context.Background()), tc.wantErr)
+		})
+	}
+}
+
+func TestParseSyncTargetMap(t *testing.T) {
+	m, err := ParseSyncTargetMap([]string{"a=b", "c=d"})
+	require.NoError(t, err)
+	require.Equal(t, SyncTargetMap{"a": "b", "c": "d"}, m)
+
+	_, err = ParseSyncTargetMap([]string{"a"})
+	require.ErrorContains(t, err, `invalid sync target mapping "a"`)
+	_, err = ParseSyncTargetMap([]string{"a=b", "a=c"})
+	require.ErrorContains(t, err, `sync target "a" is mapped more than once`)
+}
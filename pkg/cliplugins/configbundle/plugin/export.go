@@ -0,0 +1,147 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+)
+
+var clusterPathRegexp = regexp.MustCompile(`/clusters/([^/]+)/?$`)
+
+// ExportOptions contains options for exporting the TMC configuration of a
+// workspace.
+type ExportOptions struct {
+	*base.Options
+
+	// source is the workspace exported from.
+	source string
+	// now returns the export time.
+	now func() time.Time
+	// listObjects lists the objects of a resource in the current workspace.
+	listObjects func(ctx context.Context, gvr schema.GroupVersionResource) ([]unstructured.Unstructured, error)
+}
+
+// NewExportOptions returns a new ExportOptions.
+func NewExportOptions(streams base.IOStreams) *ExportOptions {
+	return &ExportOptions{
+		Options: base.NewOptions(streams),
+		now:     time.Now,
+	}
+}
+
+// BindFlags binds fields ExportOptions as command line flags to cmd's flagset.
+func (o *ExportOptions) BindFlags(cmd *cobra.Command) {
+	o.Options.BindFlags(cmd)
+}
+
+// Complete ensures all dynamically populated fields are initialized.
+func (o *ExportOptions) Complete(args []string) error {
+	if err := o.Options.Complete(); err != nil {
+		return err
+	}
+	if o.listObjects != nil {
+		return nil
+	}
+
+	config, err := o.ClientConfig.ClientConfig()
+	if err != nil {
+		return err
+	}
+	u, err := url.Parse(config.Host)
+	if err != nil {
+		return err
+	}
+	if match := clusterPathRegexp.FindStringSubmatch(u.Path); match != nil {
+		o.source = match[1]
+	}
+
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	o.listObjects = func(ctx context.Context, gvr schema.GroupVersionResource) ([]unstructured.Unstructured, error) {
+		list, err := client.Resource(gvr).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	}
+	return nil
+}
+
+// Validate validates the ExportOptions are complete and usable.
+func (o *ExportOptions) Validate() error {
+	return o.Options.Validate()
+}
+
+// Run exports the TMC configuration of the current workspace as a bundle.
+func (o *ExportOptions) Run(ctx context.Context) error {
+	bundle, err := o.export(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(bundle)
+	if err != nil {
+		return err
+	}
+	_, err = o.Out.Write(data)
+	return err
+}
+
+func (o *ExportOptions) export(ctx context.Context) (*Bundle, error) {
+	bundle := &Bundle{
+		TypeMeta:   metav1.TypeMeta{APIVersion: BundleAPIVersion, Kind: BundleKind},
+		Source:     o.source,
+		ExportedAt: metav1.NewTime(o.now().UTC().Truncate(time.Second)),
+		Objects:    []unstructured.Unstructured{},
+	}
+	for _, r := range Resources {
+		objs, err := o.listObjects(ctx, r.GroupVersionResource)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", r.GroupVersionResource, err)
+		}
+		sort.Slice(objs, func(i, j int) bool {
+			if objs[i].GetNamespace() != objs[j].GetNamespace() {
+				return objs[i].GetNamespace() < objs[j].GetNamespace()
+			}
+			return objs[i].GetName() < objs[j].GetName()
+		})
+		for i := range objs {
+			obj := objs[i].DeepCopy()
+			// list items are not guaranteed to carry their kind
+			obj.SetGroupVersionKind(r.GroupVersion().WithKind(r.Kind))
+			clean(obj)
+			bundle.Objects = append(bundle.Objects, *obj)
+		}
+	}
+	return bundle, nil
+}
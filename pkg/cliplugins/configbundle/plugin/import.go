@@ -0,0 +1,271 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+)
+
+// ImportOptions contains options for importing a TMC configuration bundle
+// into a workspace.
+type ImportOptions struct {
+	*base.Options
+
+	// Filename is the bundle to import, or - for stdin.
+	Filename string
+	// DryRun only reports and diffs the changes an import makes.
+	DryRun bool
+	// SyncTargetMappings are OLD=NEW renames of referenced SyncTargets.
+	SyncTargetMappings []string
+
+	syncTargetMap SyncTargetMap
+
+	// getObject gets an object of the current workspace.
+	getObject func(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error)
+	// createObject creates an object in the current workspace.
+	createObject func(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, dryRun bool) error
+	// updateObject updates an object of the current workspace.
+	updateObject func(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, dryRun bool) error
+}
+
+// NewImportOptions returns a new ImportOptions.
+func NewImportOptions(streams base.IOStreams) *ImportOptions {
+	return &ImportOptions{
+		Options: base.NewOptions(streams),
+	}
+}
+
+// BindFlags binds fields ImportOptions as command line flags to cmd's flagset.
+func (o *ImportOptions) BindFlags(cmd *cobra.Command) {
+	o.Options.BindFlags(cmd)
+
+	cmd.Flags().StringVarP(&o.Filename, "filename", "f", o.Filename, "Bundle to import, as written by export-config, or - to read it from stdin")
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", o.DryRun, "Only print the changes the import would make, with a diff of each changed object")
+	cmd.Flags().StringSliceVar(&o.SyncTargetMappings, "sync-target-map", o.SyncTargetMappings, "OLD=NEW rename of a SyncTarget referenced by the bundle, for environments naming their SyncTargets differently")
+}
+
+// Complete ensures all dynamically populated fields are initialized.
+func (o *ImportOptions) Complete(args []string) error {
+	if err := o.Options.Complete(); err != nil {
+		return err
+	}
+	if o.getObject != nil && o.createObject != nil && o.updateObject != nil {
+		return nil
+	}
+
+	config, err := o.ClientConfig.ClientConfig()
+	if err != nil {
+		return err
+	}
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	dryRunAll := func(dryRun bool) []string {
+		if dryRun {
+			return []string{metav1.DryRunAll}
+		}
+		return nil
+	}
+	o.getObject = func(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+		return client.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	}
+	o.createObject = func(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, dryRun bool) error {
+		_, err := client.Resource(gvr).Namespace(obj.GetNamespace()).Create(ctx, obj, metav1.CreateOptions{DryRun: dryRunAll(dryRun)})
+		return err
+	}
+	o.updateObject = func(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, dryRun bool) error {
+		_, err := client.Resource(gvr).Namespace(obj.GetNamespace()).Update(ctx, obj, metav1.UpdateOptions{DryRun: dryRunAll(dryRun)})
+		return err
+	}
+	return nil
+}
+
+// Validate validates the ImportOptions are complete and usable.
+func (o *ImportOptions) Validate() error {
+	var errs []error
+
+	if err := o.Options.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if o.Filename == "" {
+		errs = append(errs, fmt.Errorf("a bundle is required, use --filename"))
+	}
+	syncTargetMap, err := ParseSyncTargetMap(o.SyncTargetMappings)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	o.syncTargetMap = syncTargetMap
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// Run applies the bundle to the current workspace: missing objects are
+// created, changed objects updated, and unchanged objects left alone.
+// Objects of the workspace not in the bundle are kept.
+func (o *ImportOptions) Run(ctx context.Context) error {
+	bundle, err := o.readBundle()
+	if err != nil {
+		return err
+	}
+
+	suffix := ""
+	if o.DryRun {
+		suffix = " (dry run)"
+	}
+	var errs []error
+	var created, configured, unchanged int
+	for i := range bundle.Objects {
+		obj := bundle.Objects[i].DeepCopy()
+		r, _ := resourceFor(obj)
+		ref := strings.ToLower(r.Kind) + "." + r.Group + "/" + obj.GetName()
+		if obj.GetNamespace() != "" {
+			ref = obj.GetNamespace() + "/" + ref
+		}
+		if _, err := o.syncTargetMap.FixUp(obj); err != nil {
+			errs = append(errs, fmt.Errorf("failed to rewrite the sync targets of %s: %w", ref, err))
+			continue
+		}
+
+		existing, err := o.getObject(ctx, r.GroupVersionResource, obj.GetNamespace(), obj.GetName())
+		switch {
+		case apierrors.IsNotFound(err):
+			if err := o.createObject(ctx, r.GroupVersionResource, obj, o.DryRun); err != nil {
+				errs = append(errs, fmt.Errorf("failed to create %s: %w", ref, err))
+				continue
+			}
+			created++
+			fmt.Fprintf(o.Out, "%s created%s\n", ref, suffix)
+		case err != nil:
+			errs = append(errs, fmt.Errorf("failed to get %s: %w", ref, err))
+		default:
+			diff := cmp.Diff(applied(existing).Object, applied(obj).Object)
+			if diff == "" {
+				unchanged++
+				fmt.Fprintf(o.Out, "%s unchanged\n", ref)
+				continue
+			}
+			if err := o.updateObject(ctx, r.GroupVersionResource, merge(existing, obj), o.DryRun); err != nil {
+				errs = append(errs, fmt.Errorf("failed to update %s: %w", ref, err))
+				continue
+			}
+			configured++
+			fmt.Fprintf(o.Out, "%s configured%s\n", ref, suffix)
+			if o.DryRun {
+				fmt.Fprint(o.Out, indent(diff))
+			}
+		}
+	}
+	fmt.Fprintf(o.Out, "%d created, %d configured, %d unchanged%s\n", created, configured, unchanged, suffix)
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func (o *ImportOptions) readBundle() (*Bundle, error) {
+	var data []byte
+	var err error
+	if o.Filename == "-" {
+		data, err = io.ReadAll(o.In)
+	} else {
+		data, err = os.ReadFile(o.Filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+	bundle := &Bundle{}
+	if err := yaml.UnmarshalStrict(data, bundle); err != nil {
+		return nil, fmt.Errorf("failed to decode bundle %s: %w", o.Filename, err)
+	}
+	if err := bundle.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid bundle %s: %w", o.Filename, err)
+	}
+	return bundle, nil
+}
+
+// applied returns the part of obj an import sets.
+func applied(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	out := obj.DeepCopy()
+	clean(out)
+	metadata := map[string]interface{}{"name": out.GetName()}
+	if out.GetNamespace() != "" {
+		metadata["namespace"] = out.GetNamespace()
+	}
+	if labels := out.GetLabels(); len(labels) > 0 {
+		metadata["labels"] = out.Object["metadata"].(map[string]interface{})["labels"]
+	}
+	if annotations := out.GetAnnotations(); len(annotations) > 0 {
+		metadata["annotations"] = out.Object["metadata"].(map[string]interface{})["annotations"]
+	}
+	out.Object["metadata"] = metadata
+	return out
+}
+
+// merge returns existing with the content, labels and annotations of desired.
+// Status and the metadata populated by the server are kept.
+func merge(existing, desired *unstructured.Unstructured) *unstructured.Unstructured {
+	out := existing.DeepCopy()
+	for key := range out.Object {
+		if key != "metadata" && key != "status" {
+			delete(out.Object, key)
+		}
+	}
+	for key, value := range desired.DeepCopy().Object {
+		if key != "metadata" && key != "status" {
+			out.Object[key] = value
+		}
+	}
+	out.SetLabels(desired.GetLabels())
+
+	// keep the annotations the server sets, e.g. the logical cluster
+	annotations := desired.GetAnnotations()
+	imported := applied(existing).GetAnnotations()
+	for key, value := range existing.GetAnnotations() {
+		if _, ok := imported[key]; !ok {
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			annotations[key] = value
+		}
+	}
+	out.SetAnnotations(annotations)
+	return out
+}
+
+func indent(diff string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(diff, "\n"), "\n") {
+		b.WriteString("    " + line + "\n")
+	}
+	return b.String()
+}
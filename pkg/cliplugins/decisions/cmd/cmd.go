@@ -0,0 +1,79 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/pkg/cliplugins/decisions/plugin"
+)
+
+var (
+	historyExample = `
+# Show the recent placement decisions of a workload.
+%[1]s decisions history web -n shop
+
+# Show the failed decisions of the current workspace in the last hour.
+%[1]s decisions history --status Failed --since 1h
+
+# Show why a workload moved between two of its decisions.
+%[1]s decisions history web -o json | %[1]s compare-decisions 41 42 --history -
+`
+)
+
+// New provides a command for inspecting placement decisions.
+func New(streams base.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "decisions",
+		Aliases: []string{"decision"},
+		Short:   "Inspect recorded placement decisions",
+		Long:    "Inspect the placement decisions recorded as PlacementDecisionRecords, if recording is enabled on the kcp server.",
+	}
+	cmd.AddCommand(newHistory(streams))
+	return cmd
+}
+
+func newHistory(streams base.IOStreams) *cobra.Command {
+	historyOptions := plugin.NewHistoryOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "history [WORKLOAD_DISTRIBUTION]",
+		Short:        "Show the history of placement decisions",
+		Long:         "Show the most recent placement decisions of a WorkloadDistribution, or of all WorkloadDistributions of the workspace, oldest first.",
+		Example:      fmt.Sprintf(historyExample, "kubectl tmc"),
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := historyOptions.Complete(args); err != nil {
+				return err
+			}
+
+			if err := historyOptions.Validate(); err != nil {
+				return err
+			}
+
+			return historyOptions.Run(c.Context())
+		},
+	}
+
+	historyOptions.BindFlags(cmd)
+
+	return cmd
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/pkg/placement/decision"
+)
+
+var clusterPathRegexp = regexp.MustCompile(`/clusters/([^/]+)/?$`)
+
+// HistoryOptions contains options for showing the history of placement
+// decisions.
+type HistoryOptions struct {
+	*base.Options
+
+	// Name of the WorkloadDistribution whose decisions are shown, all if
+	// empty.
+	Name string
+	// Namespace of the WorkloadDistribution, when Name is set.
+	Namespace string
+	// AllWorkspaces shows the decisions of all workspaces rather than of
+	// the current one.
+	AllWorkspaces bool
+	// Status restricts the decisions to those with the outcome, Succeeded,
+	// Failed or Pending.
+	Status string
+	// Since restricts the decisions to those made in that duration.
+	Since time.Duration
+	// Limit is the number of most recent decisions shown, zero for all.
+	Limit int
+	// Output is the output format, table or json.
+	Output string
+
+	now func() time.Time
+	// history returns the recorded decisions matching the query.
+	history func(ctx context.Context, query decision.HistoryQuery) ([]decision.DecisionRecord, error)
+}
+
+// NewHistoryOptions returns a new HistoryOptions.
+func NewHistoryOptions(streams base.IOStreams) *HistoryOptions {
+	return &HistoryOptions{
+		Options: base.NewOptions(streams),
+		Limit:   20,
+		Output:  "table",
+		now:     time.Now,
+	}
+}
+
+// BindFlags binds fields HistoryOptions as command line flags to cmd's flagset.
+func (o *HistoryOptions) BindFlags(cmd *cobra.Command) {
+	o.Options.BindFlags(cmd)
+
+	cmd.Flags().BoolVar(&o.AllWorkspaces, "all-workspaces", o.AllWorkspaces, "Show the decisions of all workspaces, rather than of the current workspace")
+	cmd.Flags().StringVar(&o.Status, "status", o.Status, "Show only decisions with this outcome, Succeeded, Failed or Pending")
+	cmd.Flags().DurationVar(&o.Since, "since", o.Since, "Show only decisions made within this duration, e.g. 1h")
+	cmd.Flags().IntVar(&o.Limit, "limit", o.Limit, "Number of most recent decisions to show, 0 for all")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format, table or json. JSON can be passed to compare-decisions --history")
+}
+
+// Complete ensures all dynamically populated fields are initialized.
+func (o *HistoryOptions) Complete(args []string) error {
+	if len(args) > 0 {
+		o.Name = args[0]
+	}
+	if err := o.Options.Complete(); err != nil {
+		return err
+	}
+	if o.Name != "" && o.Namespace == "" {
+		var err error
+		if o.Namespace, _, err = o.ClientConfig.Namespace(); err != nil {
+			return err
+		}
+	}
+	if o.history != nil {
+		return nil
+	}
+
+	config, err := o.ClientConfig.ClientConfig()
+	if err != nil {
+		return err
+	}
+	// decision records are read with the storage of the placement
+	// controller, which addresses workspaces by name
+	u, err := url.Parse(config.Host)
+	if err != nil {
+		return err
+	}
+	var workspace logicalcluster.Name
+	if match := clusterPathRegexp.FindStringSubmatch(u.Path); match != nil && !o.AllWorkspaces {
+		workspace = logicalcluster.Name(match[1])
+	}
+	u.Path = clusterPathRegexp.ReplaceAllString(u.Path, "")
+	config.Host = u.String()
+	clusterClient, err := kcpdynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	storage := decision.NewCRDStorage(clusterClient)
+	o.history = func(ctx context.Context, query decision.HistoryQuery) ([]decision.DecisionRecord, error) {
+		query.Workspace = workspace
+		page, err := storage.History(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		return page.Records, nil
+	}
+	return nil
+}
+
+// Validate validates the HistoryOptions are complete and usable.
+func (o *HistoryOptions) Validate() error {
+	var errs []error
+
+	if err := o.Options.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if o.Status != "" && !slices.Contains([]decision.Status{decision.StatusSucceeded, decision.StatusFailed, decision.StatusPending}, decision.Status(o.Status)) {
+		errs = append(errs, fmt.Errorf("invalid status %q, must be Succeeded, Failed or Pending", o.Status))
+	}
+	if o.Since < 0 {
+		errs = append(errs, fmt.Errorf("--since must not be negative"))
+	}
+	if o.Limit < 0 {
+		errs = append(errs, fmt.Errorf("--limit must not be negative"))
+	}
+	if o.Output != "table" && o.Output != "json" {
+		errs = append(errs, fmt.Errorf("invalid output format %q, must be table or json", o.Output))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// Run prints the most recent matching decisions, oldest first.
+func (o *HistoryOptions) Run(ctx context.Context) error {
+	query := decision.HistoryQuery{
+		Namespace:  o.Namespace,
+		Name:       o.Name,
+		Status:     decision.Status(o.Status),
+		Descending: true,
+		Limit:      o.Limit,
+	}
+	if o.Name == "" {
+		query.Namespace = ""
+	}
+	if o.Since > 0 {
+		query.Since = o.now().Add(-o.Since)
+	}
+	records, err := o.history(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to read the placement decisions: %w", err)
+	}
+	slices.Reverse(records)
+
+	if o.Output == "json" {
+		if records == nil {
+			records = []decision.DecisionRecord{}
+		}
+		enc := json.NewEncoder(o.Out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	}
+	if len(records) == 0 {
+		fmt.Fprintln(o.ErrOut, "No placement decisions found. Decisions are recorded only if enabled on the kcp server.")
+		return nil
+	}
+	return printHistory(o.Out, records)
+}
+
+func printHistory(out io.Writer, records []decision.DecisionRecord) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTIME\tWORKSPACE\tNAMESPACE\tNAME\tSTATUS\tTARGETS\tMESSAGE")
+	for _, r := range records {
+		targets := make([]string, 0, len(r.Targets))
+		for _, t := range r.Targets {
+			targets = append(targets, t.SyncTarget)
+		}
+		sort.Strings(targets)
+		joined := strings.Join(targets, ",")
+		if joined == "" {
+			joined = "<none>"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.ID, r.Time.UTC().Format(time.RFC3339), r.Workspace, r.Namespace, r.Name, r.Status, joined, r.Message)
+	}
+	return w.Flush()
+}
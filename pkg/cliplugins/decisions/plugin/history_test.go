@@ -0,0 +1,66 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/pkg/placement/decision"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+func TestHistory(t *testing.T) {
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := []decision.DecisionRecord{
+		{ID: "42", Workspace: "root:org", Namespace: "default", Name: "web", Status: decision.StatusSucceeded, Time: t0.Add(time.Minute), Message: "placed on 2 targets",
+			Targets: []workloadv1alpha1.TargetPlacement{{SyncTarget: "us-1"}, {SyncTarget: "eu-2"}}},
+		{ID: "41", Workspace: "root:org", Namespace: "default", Name: "web", Status: decision.StatusFailed, Time: t0, Message: "no feasible targets"},
+	}
+
+	var query decision.HistoryQuery
+	out := &bytes.Buffer{}
+	o := NewHistoryOptions(base.IOStreams{Out: out, ErrOut: &bytes.Buffer{}})
+	o.Name = "web"
+	o.Namespace = "default"
+	o.Since = time.Hour
+	o.now = func() time.Time { return t0.Add(time.Hour) }
+	o.history = func(_ context.Context, q decision.HistoryQuery) ([]decision.DecisionRecord, error) {
+		query = q
+		return append([]decision.DecisionRecord(nil), records...), nil
+	}
+
+	require.NoError(t, o.Run(context.Background()))
+	require.Equal(t, decision.HistoryQuery{Namespace: "default", Name: "web", Since: t0, Descending: true, Limit: 20}, query)
+	require.Equal(t, `ID  TIME                  WORKSPACE  NAMESPACE  NAME  STATUS     TARGETS    MESSAGE
+41  2025-01-01T00:00:00Z  root:org   default    web   Failed     <none>     no feasible targets
+42  2025-01-01T00:01:00Z  root:org   default    web   Succeeded  eu-2,us-1  placed on 2 targets
+`, out.String(), "oldest first")
+
+	out.Reset()
+	o.Output = "json"
+	require.NoError(t, o.Run(context.Background()))
+	var decoded []decision.DecisionRecord
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded), "JSON is what compare-decisions reads")
+	require.Equal(t, []string{"41", "42"}, []string{decoded[0].ID, decoded[1].ID})
+}
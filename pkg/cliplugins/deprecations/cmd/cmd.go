@@ -0,0 +1,62 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/pkg/cliplugins/deprecations/plugin"
+)
+
+var (
+	deprecationsExample = `
+# List the objects in all workspaces that use deprecated TMC API fields.
+%[1]s deprecations
+`
+)
+
+// New provides a command for reporting deprecated TMC API field usage.
+func New(streams base.IOStreams) *cobra.Command {
+	deprecationsOptions := plugin.NewDeprecationsOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "deprecations",
+		Short:        "List objects using deprecated TMC API fields",
+		Long:         "List the objects in all workspaces that set deprecated TMC API fields, to migrate them before the fields are removed. Requires permission to list the resources across workspaces.",
+		Example:      fmt.Sprintf(deprecationsExample, "kubectl tmc"),
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := deprecationsOptions.Complete(args); err != nil {
+				return err
+			}
+
+			if err := deprecationsOptions.Validate(); err != nil {
+				return err
+			}
+
+			return deprecationsOptions.Run(c.Context())
+		},
+	}
+
+	deprecationsOptions.BindFlags(cmd)
+
+	return cmd
+}
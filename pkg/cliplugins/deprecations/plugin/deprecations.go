@@ -0,0 +1,171 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/pkg/deprecation"
+)
+
+var clusterPathRegexp = regexp.MustCompile(`/clusters/[^/]+/?$`)
+
+// DeprecationsOptions contains options for reporting objects using
+// deprecated TMC API fields.
+type DeprecationsOptions struct {
+	*base.Options
+
+	// Registry holds the deprecated fields to report.
+	Registry *deprecation.Registry
+
+	// listObjects lists the objects of a resource across all workspaces.
+	listObjects func(ctx context.Context, gvr schema.GroupVersionResource) ([]unstructured.Unstructured, error)
+}
+
+// NewDeprecationsOptions returns a new DeprecationsOptions.
+func NewDeprecationsOptions(streams base.IOStreams) *DeprecationsOptions {
+	return &DeprecationsOptions{
+		Options:  base.NewOptions(streams),
+		Registry: deprecation.Default,
+	}
+}
+
+// BindFlags binds fields DeprecationsOptions as command line flags to cmd's flagset.
+func (o *DeprecationsOptions) BindFlags(cmd *cobra.Command) {
+	o.Options.BindFlags(cmd)
+}
+
+// Complete ensures all dynamically populated fields are initialized.
+func (o *DeprecationsOptions) Complete(args []string) error {
+	if err := o.Options.Complete(); err != nil {
+		return err
+	}
+	if o.listObjects != nil {
+		return nil
+	}
+
+	config, err := o.ClientConfig.ClientConfig()
+	if err != nil {
+		return err
+	}
+	// list across all workspaces, independent of the current workspace
+	u, err := url.Parse(config.Host)
+	if err != nil {
+		return err
+	}
+	u.Path = clusterPathRegexp.ReplaceAllString(u.Path, "")
+	config.Host = u.String()
+
+	client, err := kcpdynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	o.listObjects = func(ctx context.Context, gvr schema.GroupVersionResource) ([]unstructured.Unstructured, error) {
+		list, err := client.Resource(gvr).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	}
+	return nil
+}
+
+// Validate validates the DeprecationsOptions are complete and usable.
+func (o *DeprecationsOptions) Validate() error {
+	return o.Options.Validate()
+}
+
+// Run lists the objects of all resources with deprecated fields and prints
+// those setting them.
+func (o *DeprecationsOptions) Run(ctx context.Context) error {
+	resources := o.Registry.Resources()
+	if len(resources) == 0 {
+		fmt.Fprintln(o.ErrOut, "No TMC API fields are deprecated.")
+		return nil
+	}
+
+	type usage struct {
+		workspace, namespace, kind, name string
+		field                            deprecation.Field
+	}
+	var usages []usage
+	var errs []error
+	for _, gvr := range resources {
+		objs, err := o.listObjects(ctx, gvr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to list %s: %w", gvr, err))
+			continue
+		}
+		for _, obj := range objs {
+			for _, f := range o.Registry.Find(gvr, obj.Object) {
+				usages = append(usages, usage{
+					workspace: logicalcluster.From(&obj).String(),
+					namespace: obj.GetNamespace(),
+					kind:      f.Kind,
+					name:      obj.GetName(),
+					field:     f,
+				})
+			}
+		}
+	}
+
+	if len(usages) == 0 {
+		fmt.Fprintln(o.ErrOut, "No objects use deprecated TMC API fields.")
+		return utilerrors.NewAggregate(errs)
+	}
+
+	sort.SliceStable(usages, func(i, j int) bool {
+		a, b := usages[i], usages[j]
+		if a.workspace != b.workspace {
+			return a.workspace < b.workspace
+		}
+		if a.namespace != b.namespace {
+			return a.namespace < b.namespace
+		}
+		if a.kind != b.kind {
+			return a.kind < b.kind
+		}
+		return a.name < b.name
+	})
+
+	w := tabwriter.NewWriter(o.Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "WORKSPACE\tNAMESPACE\tKIND\tNAME\tFIELD\tMESSAGE")
+	for _, u := range usages {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", u.workspace, u.namespace, u.kind, u.name, u.field.Path, u.field.Message)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
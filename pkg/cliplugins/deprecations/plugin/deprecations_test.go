@@ -0,0 +1,77 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/pkg/deprecation"
+)
+
+func TestDeprecationsReport(t *testing.T) {
+	widgets := schema.GroupVersionResource{Group: "tmc.kcp.io", Version: "v1alpha1", Resource: "widgets"}
+	gadgets := schema.GroupVersionResource{Group: "tmc.kcp.io", Version: "v1alpha1", Resource: "gadgets"}
+
+	object := func(workspace, name string, spec map[string]interface{}) unstructured.Unstructured {
+		obj := unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+		obj.SetName(name)
+		obj.SetNamespace("default")
+		obj.SetAnnotations(map[string]string{logicalcluster.AnnotationKey: workspace})
+		return obj
+	}
+
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	o := NewDeprecationsOptions(base.IOStreams{Out: out, ErrOut: errOut})
+	o.Registry = deprecation.NewRegistry(
+		deprecation.Field{Resource: widgets, Kind: "Widget", Path: "spec.size", Message: "use spec.replicas instead"},
+		deprecation.Field{Resource: gadgets, Kind: "Gadget", Path: "spec.color", Message: "no longer used"},
+	)
+	o.listObjects = func(ctx context.Context, gvr schema.GroupVersionResource) ([]unstructured.Unstructured, error) {
+		if gvr == gadgets {
+			return nil, errors.New("forbidden")
+		}
+		return []unstructured.Unstructured{
+			object("root:b", "w1", map[string]interface{}{"size": int64(1)}),
+			object("root:a", "w2", map[string]interface{}{"replicas": int64(1)}),
+			object("root:a", "w3", map[string]interface{}{"size": int64(1)}),
+		}, nil
+	}
+
+	err := o.Run(context.Background())
+	require.ErrorContains(t, err, "failed to list tmc.kcp.io/v1alpha1, Resource=gadgets: forbidden")
+	require.Equal(t, `WORKSPACE  NAMESPACE  KIND    NAME  FIELD      MESSAGE
+root:a     default    Widget  w3    spec.size  use spec.replicas instead
+root:b     default    Widget  w1    spec.size  use spec.replicas instead
+`, out.String())
+
+	out.Reset()
+	o.Registry = deprecation.NewRegistry()
+	require.NoError(t, o.Run(context.Background()))
+	require.Empty(t, out.String())
+	require.Contains(t, errOut.String(), "No TMC API fields are deprecated.")
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/pkg/cliplugins/dev/plugin"
+)
+
+var (
+	upExample = `
+# Bring up kcp with two kind clusters registered as SyncTargets, using a locally built syncer image.
+%[1]s dev up --syncer-image kind.local/syncer:dev --load-syncer-image
+
+# Bring up three clusters and bind the TMC APIs from an APIExport.
+%[1]s dev up --clusters 3 --api-export root:tmc:tmc.kcp.io
+`
+
+	downExample = `
+# Tear down the dev environment of the working directory.
+%[1]s dev down
+`
+)
+
+// New provides a command for running a local TMC dev environment.
+func New(streams base.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dev",
+		Short: "Run a local TMC dev environment",
+		Long:  "Run kcp with kind clusters registered as SyncTargets locally, to exercise placement and sync end to end. Requires kcp, kind, kubectl and docker.",
+	}
+	cmd.AddCommand(newUp(streams))
+	cmd.AddCommand(newDown(streams))
+	return cmd
+}
+
+func newUp(streams base.IOStreams) *cobra.Command {
+	upOptions := plugin.NewUpOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "up",
+		Short:        "Bring up a local TMC dev environment",
+		Long:         "Create kind clusters, start kcp listening on the kind network, create a workspace with the TMC APIs, and register each cluster as a SyncTarget with a syncer deployed on it.",
+		Example:      fmt.Sprintf(upExample, "kubectl tmc"),
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := upOptions.Complete(); err != nil {
+				return err
+			}
+
+			if err := upOptions.Validate(); err != nil {
+				return err
+			}
+
+			return upOptions.Run(c.Context())
+		},
+	}
+
+	upOptions.BindFlags(cmd)
+
+	return cmd
+}
+
+func newDown(streams base.IOStreams) *cobra.Command {
+	downOptions := plugin.NewDownOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "down",
+		Short:        "Tear down a local TMC dev environment",
+		Long:         "Stop kcp, delete the kind clusters and remove the directory of a dev environment brought up with dev up.",
+		Example:      fmt.Sprintf(downExample, "kubectl tmc"),
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := downOptions.Complete(); err != nil {
+				return err
+			}
+
+			if err := downOptions.Validate(); err != nil {
+				return err
+			}
+
+			return downOptions.Run(c.Context())
+		},
+	}
+
+	downOptions.BindFlags(cmd)
+
+	return cmd
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+)
+
+func TestUpFailsHalfWay(t *testing.T) {
+	dir := t.TempDir()
+	var commands []string
+	o := NewUpOptions(base.IOStreams{Out: &bytes.Buffer{}})
+	o.Dir = dir
+	o.run = func(_ context.Context, _ []byte, name string, args ...string) ([]byte, error) {
+		commands = append(commands, name+" "+strings.Join(args[:2], " "))
+		if name == "docker" {
+			return []byte("fc00:f853:ccd:e793::1 172.18.0.1 "), nil
+		}
+		return nil, nil
+	}
+	o.startKCP = func(args []string, _ string) (int, error) {
+		require.Contains(t, args, "--bind-address=172.18.0.1")
+		return 0, errors.New("kcp not found")
+	}
+	require.NoError(t, o.Validate())
+
+	err := o.Run(context.Background())
+	require.ErrorContains(t, err, "kcp not found")
+	require.ErrorContains(t, err, "kubectl tmc dev down")
+	require.Equal(t, []string{"kind create cluster", "kind create cluster", "docker network inspect"}, commands)
+
+	env, err := loadEnvironment(dir)
+	require.NoError(t, err)
+	require.Equal(t, &Environment{Clusters: []string{"tmc-dev-1", "tmc-dev-2"}}, env, "the clusters are recorded for teardown")
+
+	require.ErrorContains(t, o.Run(context.Background()), "already up")
+}
+
+func TestDown(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "env")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, (&Environment{KCPPID: 42, Clusters: []string{"a", "b"}}).save(dir))
+
+	var deleted []string
+	var stopped int
+	fail := true
+	o := NewDownOptions(base.IOStreams{Out: &bytes.Buffer{}})
+	o.Dir = dir
+	o.run = func(_ context.Context, _ []byte, name string, args ...string) ([]byte, error) {
+		cluster := args[len(args)-1]
+		if cluster == "b" && fail {
+			return nil, errors.New("docker is not running")
+		}
+		deleted = append(deleted, cluster)
+		return nil, nil
+	}
+	o.stopKCP = func(_ context.Context, pid int) error {
+		stopped = pid
+		return nil
+	}
+
+	require.ErrorContains(t, o.Run(context.Background()), "docker is not running")
+	require.Equal(t, 42, stopped)
+	require.Equal(t, []string{"a"}, deleted)
+	require.DirExists(t, dir, "the environment is kept to retry")
+
+	fail = false
+	require.NoError(t, o.Run(context.Background()))
+	require.NoDirExists(t, dir)
+	require.ErrorContains(t, o.Run(context.Background()), "there is no dev environment")
+}
+
+func TestKindGateway(t *testing.T) {
+	gw, err := kindGateway("fc00:f853:ccd:e793::1 172.18.0.1 ")
+	require.NoError(t, err)
+	require.Equal(t, "172.18.0.1", gw)
+
+	_, err = kindGateway("fc00:f853:ccd:e793::1")
+	require.ErrorContains(t, err, "no IPv4 gateway")
+}
+
+func TestSplitAPIExport(t *testing.T) {
+	path, name, err := splitAPIExport("root:tmc:tmc.kcp.io")
+	require.NoError(t, err)
+	require.Equal(t, "root:tmc", path)
+	require.Equal(t, "tmc.kcp.io", name)
+
+	for _, invalid := range []string{"tmc", ":tmc", "root:"} {
+		_, _, err := splitAPIExport(invalid)
+		require.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+)
+
+// DownOptions contains options for tearing down a dev environment.
+type DownOptions struct {
+	base.IOStreams
+
+	// Dir holds the state of the environment.
+	Dir string
+
+	run     runFunc
+	stopKCP func(ctx context.Context, pid int) error
+}
+
+// NewDownOptions returns a new DownOptions.
+func NewDownOptions(streams base.IOStreams) *DownOptions {
+	return &DownOptions{
+		IOStreams: streams,
+		Dir:       DefaultDir,
+	}
+}
+
+// BindFlags binds fields of DownOptions as command line flags to cmd's flagset.
+func (o *DownOptions) BindFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.Dir, "dir", o.Dir, "Directory holding the state of the environment")
+}
+
+// Complete ensures all dynamically populated fields are initialized.
+func (o *DownOptions) Complete() error {
+	if o.run == nil {
+		o.run = run
+	}
+	if o.stopKCP == nil {
+		o.stopKCP = stopProcess
+	}
+	return nil
+}
+
+// Validate validates the DownOptions are complete and usable.
+func (o *DownOptions) Validate() error {
+	if o.Dir == "" {
+		return fmt.Errorf("--dir is required")
+	}
+	return nil
+}
+
+// Run stops kcp, deletes the kind clusters and removes the directory of
+// the environment. The directory is kept if anything fails, to retry.
+func (o *DownOptions) Run(ctx context.Context) error {
+	env, err := loadEnvironment(o.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("there is no dev environment in %s", o.Dir)
+	}
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	if env.KCPPID != 0 {
+		fmt.Fprintf(o.Out, "Stopping kcp...\n")
+		if err := o.stopKCP(ctx, env.KCPPID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop kcp: %w", err))
+		}
+	}
+	for _, name := range env.Clusters {
+		fmt.Fprintf(o.Out, "Deleting kind cluster %q...\n", name)
+		if _, err := o.run(ctx, nil, "kind", "delete", "cluster", "--name", name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return utilerrors.NewAggregate(errs)
+	}
+	if err := os.RemoveAll(o.Dir); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "The dev environment is down.\n")
+	return nil
+}
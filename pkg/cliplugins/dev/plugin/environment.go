@@ -0,0 +1,136 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package plugin implements kubectl tmc dev, which runs a local kcp with
+// kind clusters registered as SyncTargets to exercise placement and sync
+// end to end.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// DefaultDir is the directory of the dev environment, relative to the
+	// working directory.
+	DefaultDir = ".tmc-dev"
+
+	environmentFile = "environment.yaml"
+)
+
+// Environment is what a dev environment runs, recorded in its directory as
+// it is brought up, so that it can be torn down even if bringing it up
+// failed half way.
+type Environment struct {
+	// KCPPID is the process ID of kcp, or 0 if it was not started.
+	KCPPID int `json:"kcpPID,omitempty"`
+	// Clusters are the names of the kind clusters.
+	Clusters []string `json:"clusters,omitempty"`
+}
+
+func loadEnvironment(dir string) (*Environment, error) {
+	data, err := os.ReadFile(filepath.Join(dir, environmentFile))
+	if err != nil {
+		return nil, err
+	}
+	env := &Environment{}
+	if err := yaml.UnmarshalStrict(data, env); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", filepath.Join(dir, environmentFile), err)
+	}
+	return env, nil
+}
+
+func (e *Environment) save(dir string) error {
+	data, err := yaml.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, environmentFile), data, 0o600)
+}
+
+// runFunc runs a command with the given stdin and returns its output.
+type runFunc func(ctx context.Context, stdin []byte, name string, args ...string) ([]byte, error)
+
+func run(ctx context.Context, stdin []byte, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return out, fmt.Errorf("%s %s failed: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// startProcess starts a process that outlives the command, logging to
+// logFile, and returns its ID.
+func startProcess(name string, args []string, logFile string) (int, error) {
+	log, err := os.Create(logFile)
+	if err != nil {
+		return 0, err
+	}
+	defer log.Close()
+	cmd := exec.Command(name, args...)
+	cmd.Stdout, cmd.Stderr = log, log
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+	pid := cmd.Process.Pid
+	return pid, cmd.Process.Release()
+}
+
+// stopProcess interrupts a process and waits for it to exit, killing it if
+// it does not in time.
+func stopProcess(ctx context.Context, pid int) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return nil
+	}
+	if err := p.Signal(os.Interrupt); err != nil {
+		if errors.Is(err, os.ErrProcessDone) {
+			return nil
+		}
+		return p.Kill()
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return p.Kill()
+		case <-ticker.C:
+			if err := p.Signal(syscall.Signal(0)); err != nil {
+				return nil
+			}
+		}
+	}
+}
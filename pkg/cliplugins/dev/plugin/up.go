@@ -0,0 +1,485 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	bootstrap "github.com/kcp-dev/kcp/pkg/reconciler/tmc/onboarding"
+	"github.com/kcp-dev/kcp/pkg/virtual/onboarding"
+	apisv1alpha2 "github.com/kcp-dev/kcp/sdk/apis/apis/v1alpha2"
+	corev1alpha1 "github.com/kcp-dev/kcp/sdk/apis/core/v1alpha1"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tenancy/v1alpha1"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// kcpFeatureGates are the feature gates kcp is started with.
+const kcpFeatureGates = "TMCFeature=true,TMCAPIs=true,TMCControllers=true,TMCPlacement=true"
+
+var (
+	workspacesGVR  = tenancyv1alpha1.SchemeGroupVersion.WithResource("workspaces")
+	apiBindingsGVR = apisv1alpha2.SchemeGroupVersion.WithResource("apibindings")
+	syncTargetsGVR = tmcv1alpha1.SchemeGroupVersion.WithResource("synctargets")
+)
+
+// UpOptions contains options for bringing up a dev environment.
+type UpOptions struct {
+	base.IOStreams
+
+	// Dir holds the state, kubeconfigs and logs of the environment.
+	Dir string
+	// Clusters is the number of kind clusters.
+	Clusters int
+	// Prefix names the kind clusters and their SyncTargets, numbered from 1.
+	Prefix string
+	// Workspace is the name of the workspace under root.
+	Workspace string
+	// KCPBinary is the kcp binary to run.
+	KCPBinary string
+	// SyncerImage is the image of the syncers.
+	SyncerImage string
+	// LoadSyncerImage loads the syncer image from the local docker daemon
+	// into the kind clusters, for syncers built locally.
+	LoadSyncerImage bool
+	// APIExport is the APIExport serving the TMC APIs, as path:name, bound
+	// in the workspace. The APIs must otherwise already be served there.
+	APIExport string
+	// Timeout bounds bringing the environment up.
+	Timeout time.Duration
+
+	run      runFunc
+	startKCP func(args []string, logFile string) (int, error)
+}
+
+// NewUpOptions returns a new UpOptions.
+func NewUpOptions(streams base.IOStreams) *UpOptions {
+	return &UpOptions{
+		IOStreams:   streams,
+		Dir:         DefaultDir,
+		Clusters:    2,
+		Prefix:      "tmc-dev",
+		Workspace:   "tmc-dev",
+		KCPBinary:   "kcp",
+		SyncerImage: "ghcr.io/kcp-dev/kcp/syncer:main",
+		Timeout:     10 * time.Minute,
+	}
+}
+
+// BindFlags binds fields of UpOptions as command line flags to cmd's flagset.
+func (o *UpOptions) BindFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.Dir, "dir", o.Dir, "Directory holding the state, kubeconfigs and logs of the environment")
+	cmd.Flags().IntVar(&o.Clusters, "clusters", o.Clusters, "Number of kind clusters registered as SyncTargets")
+	cmd.Flags().StringVar(&o.Prefix, "prefix", o.Prefix, "Prefix of the names of the kind clusters and their SyncTargets")
+	cmd.Flags().StringVar(&o.Workspace, "workspace", o.Workspace, "Name of the workspace created under root")
+	cmd.Flags().StringVar(&o.KCPBinary, "kcp-binary", o.KCPBinary, "kcp binary to run")
+	cmd.Flags().StringVar(&o.SyncerImage, "syncer-image", o.SyncerImage, "Image of the syncers")
+	cmd.Flags().BoolVar(&o.LoadSyncerImage, "load-syncer-image", o.LoadSyncerImage, "Load the syncer image from the local docker daemon into the kind clusters")
+	cmd.Flags().StringVar(&o.APIExport, "api-export", o.APIExport, "APIExport serving the TMC APIs, as path:name, to bind in the workspace")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "Time to wait for the environment to come up")
+}
+
+// Complete ensures all dynamically populated fields are initialized.
+func (o *UpOptions) Complete() error {
+	if o.run == nil {
+		o.run = run
+	}
+	if o.startKCP == nil {
+		o.startKCP = func(args []string, logFile string) (int, error) {
+			return startProcess(o.KCPBinary, args, logFile)
+		}
+	}
+	return nil
+}
+
+// Validate validates the UpOptions are complete and usable.
+func (o *UpOptions) Validate() error {
+	var errs []error
+
+	if o.Dir == "" {
+		errs = append(errs, fmt.Errorf("--dir is required"))
+	}
+	if o.Clusters < 1 {
+		errs = append(errs, fmt.Errorf("--clusters must be at least 1"))
+	}
+	if o.Prefix == "" {
+		errs = append(errs, fmt.Errorf("--prefix is required"))
+	}
+	if o.Workspace == "" {
+		errs = append(errs, fmt.Errorf("--workspace is required"))
+	}
+	if o.APIExport != "" {
+		if _, _, err := splitAPIExport(o.APIExport); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// Run brings the environment up: the kind clusters, kcp, the workspace,
+// and a SyncTarget and syncer per cluster.
+func (o *UpOptions) Run(ctx context.Context) error {
+	if _, err := loadEnvironment(o.Dir); err == nil {
+		return fmt.Errorf("a dev environment is already up in %s, tear it down with kubectl tmc dev down", o.Dir)
+	}
+	if err := os.MkdirAll(o.Dir, 0o755); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, o.Timeout)
+	defer cancel()
+
+	if err := o.up(ctx, &Environment{}); err != nil {
+		return fmt.Errorf("%w; clean up with kubectl tmc dev down --dir %s", err, o.Dir)
+	}
+	return nil
+}
+
+func (o *UpOptions) up(ctx context.Context, env *Environment) error {
+	for i := 1; i <= o.Clusters; i++ {
+		name := fmt.Sprintf("%s-%d", o.Prefix, i)
+		fmt.Fprintf(o.Out, "Creating kind cluster %q...\n", name)
+		if _, err := o.run(ctx, nil, "kind", "create", "cluster", "--name", name, "--kubeconfig", o.clusterKubeconfig(name), "--wait", "2m"); err != nil {
+			return err
+		}
+		env.Clusters = append(env.Clusters, name)
+		if err := env.save(o.Dir); err != nil {
+			return err
+		}
+		if o.LoadSyncerImage {
+			if _, err := o.run(ctx, nil, "kind", "load", "docker-image", o.SyncerImage, "--name", name); err != nil {
+				return err
+			}
+		}
+	}
+
+	// kcp listens on the gateway of the kind network, which both the host
+	// and the syncers in the kind clusters reach.
+	out, err := o.run(ctx, nil, "docker", "network", "inspect", "kind", "--format", "{{range .IPAM.Config}}{{.Gateway}} {{end}}")
+	if err != nil {
+		return err
+	}
+	host, err := kindGateway(string(out))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "Starting kcp on %s...\n", host)
+	rootDir, err := filepath.Abs(filepath.Join(o.Dir, "kcp"))
+	if err != nil {
+		return err
+	}
+	env.KCPPID, err = o.startKCP([]string{
+		"start",
+		"--root-directory=" + rootDir,
+		"--bind-address=" + host,
+		"--feature-gates=" + kcpFeatureGates,
+		"--tmc-syncer-image=" + o.SyncerImage,
+	}, filepath.Join(o.Dir, "kcp.log"))
+	if err != nil {
+		return fmt.Errorf("failed to start kcp: %w", err)
+	}
+	if err := env.save(o.Dir); err != nil {
+		return err
+	}
+	admin, config, err := o.waitForKCP(ctx, filepath.Join(rootDir, "admin.kubeconfig"))
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(o.Out, "Creating workspace root:%s...\n", o.Workspace)
+	cluster, err := o.createWorkspace(ctx, config)
+	if err != nil {
+		return err
+	}
+	wsConfig := rest.CopyConfig(config)
+	wsConfig.Host = config.Host + cluster.Path().RequestPath()
+	if err := o.bindTMC(ctx, wsConfig); err != nil {
+		return err
+	}
+
+	for _, name := range env.Clusters {
+		fmt.Fprintf(o.Out, "Registering SyncTarget %q and deploying its syncer...\n", name)
+		if err := o.registerSyncTarget(ctx, wsConfig, cluster, name); err != nil {
+			return err
+		}
+	}
+
+	kubeconfig := filepath.Join(o.Dir, "kcp.kubeconfig")
+	if err := writeWorkspaceKubeconfig(admin, kubeconfig, wsConfig.Host, o.Workspace); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "\nThe dev environment is up. Place workloads in workspace root:%s with:\n\n  export KUBECONFIG=%s\n\n", o.Workspace, kubeconfig)
+	for _, name := range env.Clusters {
+		fmt.Fprintf(o.Out, "kind cluster %q: %s\n", name, o.clusterKubeconfig(name))
+	}
+	fmt.Fprintf(o.Out, "kcp logs: %s\n", filepath.Join(o.Dir, "kcp.log"))
+	return nil
+}
+
+func (o *UpOptions) clusterKubeconfig(name string) string {
+	return filepath.Join(o.Dir, name+".kubeconfig")
+}
+
+// waitForKCP waits for kcp to write its admin kubeconfig and to be ready,
+// and returns the kubeconfig and the client config of its base context.
+func (o *UpOptions) waitForKCP(ctx context.Context, path string) (*clientcmdapi.Config, *rest.Config, error) {
+	var admin *clientcmdapi.Config
+	var config *rest.Config
+	err := wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		var err error
+		if admin, err = clientcmd.LoadFromFile(path); err != nil {
+			return false, nil
+		}
+		if config, err = clientcmd.NewNonInteractiveClientConfig(*admin, "base", &clientcmd.ConfigOverrides{}, nil).ClientConfig(); err != nil {
+			return false, err
+		}
+		client, err := kubernetes.NewForConfig(config)
+		if err != nil {
+			return false, err
+		}
+		_, err = client.Discovery().RESTClient().Get().AbsPath("/readyz").DoRaw(ctx)
+		return err == nil, nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("kcp did not become ready, see %s: %w", filepath.Join(o.Dir, "kcp.log"), err)
+	}
+	return admin, config, nil
+}
+
+// createWorkspace creates the workspace under root and returns its logical
+// cluster once it is ready.
+func (o *UpOptions) createWorkspace(ctx context.Context, config *rest.Config) (logicalcluster.Name, error) {
+	rootConfig := rest.CopyConfig(config)
+	rootConfig.Host = config.Host + logicalcluster.NewPath("root").RequestPath()
+	client, err := dynamic.NewForConfig(rootConfig)
+	if err != nil {
+		return "", err
+	}
+	workspaces := client.Resource(workspacesGVR)
+	ws := &unstructured.Unstructured{}
+	ws.SetAPIVersion(tenancyv1alpha1.SchemeGroupVersion.String())
+	ws.SetKind("Workspace")
+	ws.SetName(o.Workspace)
+	if _, err := workspaces.Create(ctx, ws, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", err
+	}
+
+	var cluster logicalcluster.Name
+	err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		u, err := workspaces.Get(ctx, o.Workspace, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		ws := &tenancyv1alpha1.Workspace{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, ws); err != nil {
+			return false, err
+		}
+		cluster = logicalcluster.Name(ws.Spec.Cluster)
+		return ws.Status.Phase == corev1alpha1.LogicalClusterPhaseReady && !cluster.Empty(), nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("workspace root:%s did not become ready: %w", o.Workspace, err)
+	}
+	return cluster, nil
+}
+
+// bindTMC binds the TMC APIExport, if any, and waits for the TMC APIs to be
+// served in the workspace.
+func (o *UpOptions) bindTMC(ctx context.Context, config *rest.Config) error {
+	if o.APIExport != "" {
+		path, name, err := splitAPIExport(o.APIExport)
+		if err != nil {
+			return err
+		}
+		client, err := dynamic.NewForConfig(config)
+		if err != nil {
+			return err
+		}
+		binding := &apisv1alpha2.APIBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: apisv1alpha2.SchemeGroupVersion.String(), Kind: "APIBinding"},
+			ObjectMeta: metav1.ObjectMeta{Name: "tmc"},
+			Spec: apisv1alpha2.APIBindingSpec{
+				Reference: apisv1alpha2.BindingReference{Export: &apisv1alpha2.ExportBindingReference{Path: path, Name: name}},
+			},
+		}
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(binding)
+		if err != nil {
+			return err
+		}
+		if _, err := client.Resource(apiBindingsGVR).Create(ctx, &unstructured.Unstructured{Object: obj}, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to bind APIExport %s: %w", o.APIExport, err)
+		}
+	}
+
+	client, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return err
+	}
+	err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		resources, err := client.ServerResourcesForGroupVersion(tmcv1alpha1.SchemeGroupVersion.String())
+		if err != nil {
+			return false, nil
+		}
+		for _, r := range resources.APIResources {
+			if r.Name == syncTargetsGVR.Resource {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return fmt.Errorf("the TMC APIs are not served in workspace root:%s, set --api-export: %w", o.Workspace, err)
+	}
+	return nil
+}
+
+// registerSyncTarget creates an approved SyncTarget for a kind cluster,
+// waits for the onboarding controller to issue the token of its syncer and
+// deploys the syncer on the cluster.
+func (o *UpOptions) registerSyncTarget(ctx context.Context, config *rest.Config, cluster logicalcluster.Name, name string) error {
+	// The registration is approved right away, the key only identifies the
+	// registering cluster in the onboarding flow.
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	publicKey, err := onboarding.EncodePublicKey(key.Public())
+	if err != nil {
+		return err
+	}
+	syncTarget := &tmcv1alpha1.SyncTarget{
+		TypeMeta:   metav1.TypeMeta{APIVersion: tmcv1alpha1.SchemeGroupVersion.String(), Kind: "SyncTarget"},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: tmcv1alpha1.SyncTargetSpec{
+			Location: name,
+			Registration: &tmcv1alpha1.SyncTargetRegistration{
+				PublicKey:   publicKey,
+				RequestedBy: "kubectl tmc dev",
+				Approved:    true,
+			},
+		},
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(syncTarget)
+	if err != nil {
+		return err
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	if _, err := dynamicClient.Resource(syncTargetsGVR).Create(ctx, &unstructured.Unstructured{Object: obj}, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create SyncTarget %q: %w", name, err)
+	}
+
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	var token []byte
+	err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		secret, err := client.CoreV1().Secrets(bootstrap.BootstrapNamespace).Get(ctx, bootstrap.SyncerName(name)+"-token", metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		token = secret.Data[corev1.ServiceAccountTokenKey]
+		return len(token) > 0, nil
+	})
+	if err != nil {
+		return fmt.Errorf("the token of the syncer of SyncTarget %q was not issued: %w", name, err)
+	}
+
+	manifests, err := bootstrap.Render(syncTarget, bootstrap.BootstrapConfig{
+		Server:      config.Host,
+		Token:       string(token),
+		SyncerImage: o.SyncerImage,
+		Cluster:     cluster,
+		CAData:      config.CAData,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = o.run(ctx, manifests, "kubectl", "--kubeconfig", o.clusterKubeconfig(name), "apply", "-f", "-")
+	return err
+}
+
+// writeWorkspaceKubeconfig writes a kubeconfig for the workspace with the
+// credentials of the base context of the admin kubeconfig.
+func writeWorkspaceKubeconfig(admin *clientcmdapi.Config, path, server, name string) error {
+	base, found := admin.Contexts["base"]
+	if !found {
+		return errors.New("the admin kubeconfig of kcp has no base context")
+	}
+	cluster := admin.Clusters[base.Cluster].DeepCopy()
+	cluster.Server = server
+	config := clientcmdapi.Config{
+		Clusters:       map[string]*clientcmdapi.Cluster{name: cluster},
+		AuthInfos:      map[string]*clientcmdapi.AuthInfo{base.AuthInfo: admin.AuthInfos[base.AuthInfo]},
+		Contexts:       map[string]*clientcmdapi.Context{name: {Cluster: name, AuthInfo: base.AuthInfo}},
+		CurrentContext: name,
+	}
+	return clientcmd.WriteToFile(config, path)
+}
+
+// kindGateway returns the IPv4 gateway of the kind docker network from the
+// gateways listed by docker network inspect.
+func kindGateway(gateways string) (string, error) {
+	for _, gw := range strings.Fields(gateways) {
+		if ip := net.ParseIP(gw); ip != nil && ip.To4() != nil {
+			return ip.String(), nil
+		}
+	}
+	return "", fmt.Errorf("the kind docker network has no IPv4 gateway: %q", strings.TrimSpace(gateways))
+}
+
+// splitAPIExport splits path:name.
+func splitAPIExport(export string) (string, string, error) {
+	i := strings.LastIndex(export, ":")
+	if i <= 0 || i == len(export)-1 {
+		return "", "", fmt.Errorf("invalid APIExport %q, expected path:name", export)
+	}
+	return export[:i], export[i+1:], nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/pkg/cliplugins/drain/plugin"
+)
+
+var (
+	cordonExample = `
+# Stop placing new workloads on a sync target, keeping the ones placed on it.
+%[1]s cordon us-east-1
+`
+
+	uncordonExample = `
+# Place workloads on a sync target again, stopping its drain if any.
+%[1]s uncordon us-east-1
+`
+
+	drainExample = `
+# Move the workloads off a sync target one at a time and wait until they moved.
+%[1]s drain us-east-1 --wait
+
+# Move three workloads at a time, and the remaining ones at once after an hour.
+%[1]s drain us-east-1 --max-unavailable 3 --timeout 1h
+`
+)
+
+// NewCordon provides a command for cordoning a SyncTarget.
+func NewCordon(streams base.IOStreams) *cobra.Command {
+	return newCordon(streams, true)
+}
+
+// NewUncordon provides a command for uncordoning a SyncTarget.
+func NewUncordon(streams base.IOStreams) *cobra.Command {
+	return newCordon(streams, false)
+}
+
+func newCordon(streams base.IOStreams, unschedulable bool) *cobra.Command {
+	cordonOptions := plugin.NewCordonOptions(streams, unschedulable)
+
+	cmd := &cobra.Command{
+		Use:          "cordon SYNC_TARGET",
+		Short:        "Mark a SyncTarget as unschedulable",
+		Long:         "Mark a SyncTarget as unschedulable. New workloads are not placed on it, while the workloads already placed on it stay.",
+		Example:      fmt.Sprintf(cordonExample, "kubectl tmc"),
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := cordonOptions.Complete(args); err != nil {
+				return err
+			}
+
+			if err := cordonOptions.Validate(); err != nil {
+				return err
+			}
+
+			return cordonOptions.Run(c.Context())
+		},
+	}
+	if !unschedulable {
+		cmd.Use = "uncordon SYNC_TARGET"
+		cmd.Short = "Mark a SyncTarget as schedulable"
+		cmd.Long = "Mark a SyncTarget as schedulable again, and stop its drain if any. Workloads moved off it are not moved back."
+		cmd.Example = fmt.Sprintf(uncordonExample, "kubectl tmc")
+	}
+
+	cordonOptions.BindFlags(cmd)
+
+	return cmd
+}
+
+// NewDrain provides a command for draining a SyncTarget.
+func NewDrain(streams base.IOStreams) *cobra.Command {
+	drainOptions := plugin.NewDrainOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "drain SYNC_TARGET",
+		Short:        "Move the workloads off a SyncTarget",
+		Long:         "Cordon a SyncTarget and move the workloads placed on it to other SyncTargets, a few at a time. A workload counts as moving until it is ready on its new SyncTargets. Uncordon the SyncTarget to stop the drain.",
+		Example:      fmt.Sprintf(drainExample, "kubectl tmc"),
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := drainOptions.Complete(args); err != nil {
+				return err
+			}
+
+			if err := drainOptions.Validate(); err != nil {
+				return err
+			}
+
+			return drainOptions.Run(c.Context())
+		},
+	}
+
+	drainOptions.BindFlags(cmd)
+
+	return cmd
+}
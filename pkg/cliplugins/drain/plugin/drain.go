@@ -0,0 +1,276 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+var syncTargetGVR = tmcv1alpha1.SchemeGroupVersion.WithResource("synctargets")
+
+// CordonOptions contains options for cordoning or uncordoning a SyncTarget.
+type CordonOptions struct {
+	*base.Options
+
+	// Name is the SyncTarget to cordon or uncordon.
+	Name string
+	// Unschedulable is true to cordon and false to uncordon.
+	Unschedulable bool
+
+	// patchSyncTarget merge-patches a SyncTarget of the current workspace.
+	patchSyncTarget func(ctx context.Context, name string, patch []byte) error
+}
+
+// NewCordonOptions returns a new CordonOptions, cordoning if unschedulable.
+func NewCordonOptions(streams base.IOStreams, unschedulable bool) *CordonOptions {
+	return &CordonOptions{
+		Options:       base.NewOptions(streams),
+		Unschedulable: unschedulable,
+	}
+}
+
+// BindFlags binds fields CordonOptions as command line flags to cmd's flagset.
+func (o *CordonOptions) BindFlags(cmd *cobra.Command) {
+	o.Options.BindFlags(cmd)
+}
+
+// Complete ensures all dynamically populated fields are initialized.
+func (o *CordonOptions) Complete(args []string) error {
+	if len(args) > 0 {
+		o.Name = args[0]
+	}
+	if err := o.Options.Complete(); err != nil {
+		return err
+	}
+	if o.patchSyncTarget != nil {
+		return nil
+	}
+
+	client, err := newClient(o.Options)
+	if err != nil {
+		return err
+	}
+	o.patchSyncTarget = patcher(client)
+	return nil
+}
+
+// Validate validates the CordonOptions are complete and usable.
+func (o *CordonOptions) Validate() error {
+	var errs []error
+
+	if err := o.Options.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if o.Name == "" {
+		errs = append(errs, fmt.Errorf("a sync target name is required"))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// Run cordons or uncordons the SyncTarget. Uncordoning also stops a drain.
+func (o *CordonOptions) Run(ctx context.Context) error {
+	spec := map[string]interface{}{"unschedulable": o.Unschedulable}
+	if !o.Unschedulable {
+		spec["drain"] = nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{"spec": spec})
+	if err != nil {
+		return err
+	}
+	if err := o.patchSyncTarget(ctx, o.Name, patch); err != nil {
+		return fmt.Errorf("failed to patch sync target %q: %w", o.Name, err)
+	}
+	if o.Unschedulable {
+		fmt.Fprintf(o.Out, "sync target %q cordoned\n", o.Name)
+	} else {
+		fmt.Fprintf(o.Out, "sync target %q uncordoned\n", o.Name)
+	}
+	return nil
+}
+
+// DrainOptions contains options for draining a SyncTarget.
+type DrainOptions struct {
+	*base.Options
+
+	// Name is the SyncTarget to drain.
+	Name string
+	// MaxUnavailable is how many workloads may be moving at the same time.
+	MaxUnavailable int32
+	// Timeout is how long the drain waits for workloads to move before it
+	// evicts the remaining ones at once. Zero waits as long as it takes.
+	Timeout time.Duration
+	// Wait waits until the SyncTarget is drained.
+	Wait bool
+	// Interval is how often the progress is checked while waiting.
+	Interval time.Duration
+
+	// patchSyncTarget merge-patches a SyncTarget of the current workspace.
+	patchSyncTarget func(ctx context.Context, name string, patch []byte) error
+	// getSyncTarget gets a SyncTarget of the current workspace.
+	getSyncTarget func(ctx context.Context, name string) (*tmcv1alpha1.SyncTarget, error)
+	// sleep waits for d, or until ctx is done.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewDrainOptions returns a new DrainOptions.
+func NewDrainOptions(streams base.IOStreams) *DrainOptions {
+	return &DrainOptions{
+		Options:        base.NewOptions(streams),
+		MaxUnavailable: 1,
+		Interval:       5 * time.Second,
+		sleep: func(ctx context.Context, d time.Duration) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(d):
+				return nil
+			}
+		},
+	}
+}
+
+// BindFlags binds fields DrainOptions as command line flags to cmd's flagset.
+func (o *DrainOptions) BindFlags(cmd *cobra.Command) {
+	o.Options.BindFlags(cmd)
+
+	cmd.Flags().Int32Var(&o.MaxUnavailable, "max-unavailable", o.MaxUnavailable, "How many workloads may be moving off the sync target at the same time")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "How long to wait for workloads to move before evicting the remaining ones at once, 0 to wait as long as it takes")
+	cmd.Flags().BoolVar(&o.Wait, "wait", o.Wait, "Wait until the sync target is drained")
+	cmd.Flags().DurationVar(&o.Interval, "interval", o.Interval, "How often the progress is checked with --wait")
+}
+
+// Complete ensures all dynamically populated fields are initialized.
+func (o *DrainOptions) Complete(args []string) error {
+	if len(args) > 0 {
+		o.Name = args[0]
+	}
+	if err := o.Options.Complete(); err != nil {
+		return err
+	}
+	if o.patchSyncTarget != nil && o.getSyncTarget != nil {
+		return nil
+	}
+
+	client, err := newClient(o.Options)
+	if err != nil {
+		return err
+	}
+	o.patchSyncTarget = patcher(client)
+	o.getSyncTarget = func(ctx context.Context, name string) (*tmcv1alpha1.SyncTarget, error) {
+		u, err := client.Resource(syncTargetGVR).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		syncTarget := &tmcv1alpha1.SyncTarget{}
+		return syncTarget, runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, syncTarget)
+	}
+	return nil
+}
+
+// Validate validates the DrainOptions are complete and usable.
+func (o *DrainOptions) Validate() error {
+	var errs []error
+
+	if err := o.Options.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if o.Name == "" {
+		errs = append(errs, fmt.Errorf("a sync target name is required"))
+	}
+	if o.MaxUnavailable < 1 {
+		errs = append(errs, fmt.Errorf("--max-unavailable must be at least 1"))
+	}
+	if o.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("--timeout must not be negative"))
+	}
+	if o.Wait && o.Interval <= 0 {
+		errs = append(errs, fmt.Errorf("--interval must be positive"))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// Run cordons and drains the SyncTarget and, with Wait, waits until it is
+// drained or ctx is done.
+func (o *DrainOptions) Run(ctx context.Context) error {
+	drain := map[string]interface{}{"maxUnavailable": o.MaxUnavailable, "timeout": nil}
+	if o.Timeout > 0 {
+		drain["timeout"] = o.Timeout.String()
+	}
+	patch, err := json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"unschedulable": true, "drain": drain}})
+	if err != nil {
+		return err
+	}
+	if err := o.patchSyncTarget(ctx, o.Name, patch); err != nil {
+		return fmt.Errorf("failed to drain sync target %q: %w", o.Name, err)
+	}
+	fmt.Fprintf(o.Out, "sync target %q cordoned and draining\n", o.Name)
+	if !o.Wait {
+		return nil
+	}
+
+	var last string
+	for {
+		syncTarget, err := o.getSyncTarget(ctx, o.Name)
+		if err != nil {
+			return fmt.Errorf("failed to get sync target %q: %w", o.Name, err)
+		}
+		if conditions.IsTrue(syncTarget, tmcv1alpha1.Drained) {
+			fmt.Fprintf(o.Out, "sync target %q drained\n", o.Name)
+			return nil
+		}
+		if msg := conditions.GetMessage(syncTarget, tmcv1alpha1.Drained); msg != "" && msg != last {
+			fmt.Fprintln(o.ErrOut, msg)
+			last = msg
+		}
+		if err := o.sleep(ctx, o.Interval); err != nil {
+			return fmt.Errorf("sync target %q is not drained yet: %w", o.Name, err)
+		}
+	}
+}
+
+// newClient returns a dynamic client for the current workspace.
+func newClient(o *base.Options) (dynamic.Interface, error) {
+	config, err := o.ClientConfig.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	return dynamic.NewForConfig(config)
+}
+
+func patcher(client dynamic.Interface) func(ctx context.Context, name string, patch []byte) error {
+	return func(ctx context.Context, name string, patch []byte) error {
+		_, err := client.Resource(syncTargetGVR).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return err
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func TestCordon(t *testing.T) {
+	var patches []string
+	patch := func(_ context.Context, name string, patch []byte) error {
+		require.Equal(t, "eu-1", name)
+		patches = append(patches, string(patch))
+		return nil
+	}
+
+	for _, unschedulable := range []bool{true, false} {
+		o := NewCordonOptions(base.IOStreams{Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}, unschedulable)
+		o.Name = "eu-1"
+		o.patchSyncTarget = patch
+		require.NoError(t, o.Run(context.Background()))
+	}
+	require.Equal(t, []string{
+		`{"spec":{"unschedulable":true}}`,
+		`{"spec":{"drain":null,"unschedulable":false}}`,
+	}, patches)
+}
+
+func TestDrain(t *testing.T) {
+	var patches []string
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	o := NewDrainOptions(base.IOStreams{Out: out, ErrOut: errOut})
+	o.Name = "eu-1"
+	o.MaxUnavailable = 2
+	o.Timeout = time.Hour
+	o.Wait = true
+	o.patchSyncTarget = func(_ context.Context, _ string, patch []byte) error {
+		patches = append(patches, string(patch))
+		return nil
+	}
+	progress := []*conditionsv1alpha1.Condition{
+		{Type: tmcv1alpha1.Drained, Status: corev1.ConditionFalse, Message: "2 workloads are still placed on the SyncTarget, moving: default/a"},
+		{Type: tmcv1alpha1.Drained, Status: corev1.ConditionFalse, Message: "2 workloads are still placed on the SyncTarget, moving: default/a"},
+		{Type: tmcv1alpha1.Drained, Status: corev1.ConditionTrue},
+	}
+	calls := 0
+	o.getSyncTarget = func(context.Context, string) (*tmcv1alpha1.SyncTarget, error) {
+		syncTarget := &tmcv1alpha1.SyncTarget{}
+		conditions.Set(syncTarget, progress[calls])
+		calls++
+		return syncTarget, nil
+	}
+	o.sleep = func(context.Context, time.Duration) error { return nil }
+
+	require.NoError(t, o.Run(context.Background()))
+	require.Equal(t, []string{`{"spec":{"drain":{"maxUnavailable":2,"timeout":"1h0m0s"},"unschedulable":true}}`}, patches)
+	require.Equal(t, 3, calls)
+	require.Equal(t, "2 workloads are still placed on the SyncTarget, moving: default/a\n", errOut.String(), "progress is printed when it changes")
+	require.Equal(t, "sync target \"eu-1\" cordoned and draining\nsync target \"eu-1\" drained\n", out.String())
+}
+
+func TestDrainWaitInterrupted(t *testing.T) {
+	o := NewDrainOptions(base.IOStreams{Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}})
+	o.Name = "eu-1"
+	o.Wait = true
+	o.patchSyncTarget = func(context.Context, string, []byte) error { return nil }
+	o.getSyncTarget = func(context.Context, string) (*tmcv1alpha1.SyncTarget, error) {
+		return &tmcv1alpha1.SyncTarget{}, nil
+	}
+	o.sleep = func(context.Context, time.Duration) error { return context.Canceled }
+
+	require.True(t, errors.Is(o.Run(context.Background()), context.Canceled))
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/pkg/cliplugins/dumpsyncer/plugin"
+)
+
+var (
+	dumpSyncerExample = `
+# Capture a support bundle from a syncer pod, using the physical cluster kubeconfig.
+%[1]s dump-syncer kcp-syncer-us-east-1-5d8f7c9b4-x2x7q -n kcp-syncer-us-east-1 --kubeconfig pcluster.kubeconfig
+
+# Capture a support bundle from a port-forwarded or locally running syncer.
+%[1]s dump-syncer --address http://127.0.0.1:6060 -o bundle.tar.gz
+`
+)
+
+// New provides a command for capturing syncer support bundles.
+func New(streams base.IOStreams) *cobra.Command {
+	dumpSyncerOptions := plugin.NewDumpSyncerOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "dump-syncer [POD_NAME]",
+		Short:        "Capture a support bundle from a syncer for filing issues",
+		Long:         "Capture a support bundle with the configuration, queue state, recent errors, goroutines and metrics of a syncer. The syncer must run with --diagnostics-bind-address.",
+		Example:      fmt.Sprintf(dumpSyncerExample, "kubectl tmc"),
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := dumpSyncerOptions.Complete(args); err != nil {
+				return err
+			}
+
+			if err := dumpSyncerOptions.Validate(); err != nil {
+				return err
+			}
+
+			return dumpSyncerOptions.Run(c.Context())
+		},
+	}
+
+	dumpSyncerOptions.BindFlags(cmd)
+
+	return cmd
+}
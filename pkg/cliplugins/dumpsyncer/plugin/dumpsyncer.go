@@ -0,0 +1,193 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/pkg/syncer/diagnostics"
+)
+
+// DumpSyncerOptions contains options for capturing a syncer support bundle.
+type DumpSyncerOptions struct {
+	*base.Options
+
+	// PodName is the syncer pod to capture the bundle from, reached through
+	// the API server proxy of the physical cluster.
+	PodName string
+	// Namespace is the namespace of the syncer pod, taken from the
+	// kubeconfig and --namespace.
+	Namespace string
+	// Port is the diagnostics port of the syncer pod.
+	Port int
+	// Address is the diagnostics address of the syncer, e.g.
+	// http://127.0.0.1:6060. If set, the kubeconfig is not used.
+	Address string
+	// OutputFile is the file the bundle is written to, or - for stdout.
+	OutputFile string
+	// Timeout bounds capturing the bundle.
+	Timeout time.Duration
+}
+
+// NewDumpSyncerOptions returns a new DumpSyncerOptions.
+func NewDumpSyncerOptions(streams base.IOStreams) *DumpSyncerOptions {
+	return &DumpSyncerOptions{
+		Options: base.NewOptions(streams),
+		Port:    6060,
+		Timeout: time.Minute,
+	}
+}
+
+// BindFlags binds fields DumpSyncerOptions as command line flags to cmd's flagset.
+func (o *DumpSyncerOptions) BindFlags(cmd *cobra.Command) {
+	o.Options.BindFlags(cmd)
+
+	cmd.Flags().IntVar(&o.Port, "port", o.Port, "Diagnostics port of the syncer, as set by --diagnostics-bind-address")
+	cmd.Flags().StringVar(&o.Address, "address", o.Address, "Diagnostics address of the syncer, e.g. http://127.0.0.1:6060. Bypasses the kubeconfig")
+	cmd.Flags().StringVarP(&o.OutputFile, "output-file", "o", o.OutputFile, "File to write the bundle to, or - for stdout. Defaults to a timestamped file in the current directory")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "Time to wait for the bundle")
+}
+
+// Complete ensures all dynamically populated fields are initialized.
+func (o *DumpSyncerOptions) Complete(args []string) error {
+	if len(args) > 0 {
+		o.PodName = args[0]
+	}
+	if o.OutputFile == "" {
+		o.OutputFile = diagnostics.BundleFileName(time.Now())
+	}
+	if o.Address != "" {
+		return nil
+	}
+
+	if err := o.Options.Complete(); err != nil {
+		return err
+	}
+	namespace, _, err := o.ClientConfig.Namespace()
+	if err != nil {
+		return err
+	}
+	o.Namespace = namespace
+	return nil
+}
+
+// Validate validates the DumpSyncerOptions are complete and usable.
+func (o *DumpSyncerOptions) Validate() error {
+	var errs []error
+
+	if err := o.Options.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if o.Address != "" {
+		if o.PodName != "" {
+			errs = append(errs, fmt.Errorf("a pod name and --address are mutually exclusive"))
+		}
+		if u, err := url.Parse(o.Address); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("invalid --address %q, expected e.g. http://127.0.0.1:6060", o.Address))
+		}
+	} else if o.PodName == "" {
+		errs = append(errs, fmt.Errorf("a syncer pod name or --address is required"))
+	}
+	if o.Port <= 0 || o.Port > 65535 {
+		errs = append(errs, fmt.Errorf("--port must be between 1 and 65535"))
+	}
+	if o.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("--timeout must be positive"))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// Run captures the support bundle and writes it to the output file.
+func (o *DumpSyncerOptions) Run(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, o.Timeout)
+	defer cancel()
+
+	bundle, err := o.openBundle(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to capture syncer support bundle: %w", err)
+	}
+	defer bundle.Close()
+
+	if o.OutputFile == "-" {
+		_, err := io.Copy(o.Out, bundle)
+		return err
+	}
+
+	f, err := os.Create(o.OutputFile)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, bundle); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", o.OutputFile, err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(o.ErrOut, "Wrote syncer support bundle to %s. Please attach it when filing an issue.\n", o.OutputFile)
+	return nil
+}
+
+func (o *DumpSyncerOptions) openBundle(ctx context.Context) (io.ReadCloser, error) {
+	if o.Address != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(o.Address, "/")+diagnostics.BundlePath, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("unexpected status %s from %s", resp.Status, o.Address)
+		}
+		return resp.Body, nil
+	}
+
+	config, err := o.ClientConfig.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return client.CoreV1().RESTClient().Get().
+		Namespace(o.Namespace).
+		Resource("pods").
+		Name(fmt.Sprintf("%s:%d", o.PodName, o.Port)).
+		SubResource("proxy").
+		Suffix(diagnostics.BundlePath).
+		Stream(ctx)
+}
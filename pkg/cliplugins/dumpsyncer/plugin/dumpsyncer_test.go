@@ -0,0 +1,66 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/pkg/syncer/diagnostics"
+)
+
+func TestDumpSyncerFromAddress(t *testing.T) {
+	server := httptest.NewServer(diagnostics.NewServer(diagnostics.NewOptions(), nil).Handler())
+	defer server.Close()
+
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	o := NewDumpSyncerOptions(base.IOStreams{Out: out, ErrOut: errOut})
+	o.Address = server.URL
+	o.OutputFile = "-"
+	require.NoError(t, o.Complete(nil))
+	require.NoError(t, o.Validate())
+	require.NoError(t, o.Run(context.Background()))
+
+	_, err := gzip.NewReader(out)
+	require.NoError(t, err)
+
+	o = NewDumpSyncerOptions(base.IOStreams{Out: out, ErrOut: errOut})
+	o.Address = server.URL
+	o.OutputFile = filepath.Join(t.TempDir(), "bundle.tar.gz")
+	require.NoError(t, o.Complete(nil))
+	require.NoError(t, o.Run(context.Background()))
+	require.FileExists(t, o.OutputFile)
+	require.Contains(t, errOut.String(), o.OutputFile)
+}
+
+func TestDumpSyncerValidate(t *testing.T) {
+	o := NewDumpSyncerOptions(base.IOStreams{})
+	require.ErrorContains(t, o.Validate(), "pod name or --address is required")
+
+	o.PodName = "syncer"
+	o.Address = "127.0.0.1:6060"
+	err := o.Validate()
+	require.ErrorContains(t, err, "mutually exclusive")
+	require.ErrorContains(t, err, "invalid --address")
+}
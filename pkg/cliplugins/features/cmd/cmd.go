@@ -0,0 +1,63 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/pkg/cliplugins/features/plugin"
+)
+
+var (
+	featuresExample = `
+# List the active TMC features of all workspaces, and the feature gates
+# the TMC controllers and syncers disagree on.
+%[1]s features
+`
+)
+
+// New provides a command for reporting the TMC features of workspaces.
+func New(streams base.IOStreams) *cobra.Command {
+	featuresOptions := plugin.NewFeaturesOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "features",
+		Short:        "List the active TMC features of workspaces",
+		Long:         "List the active TMC features of all workspaces from their TMCFeatureStatus, and the feature gates the TMC controllers and the syncers of SyncTargets disagree on. Requires permission to list TMCFeatureStatuses across workspaces.",
+		Example:      fmt.Sprintf(featuresExample, "kubectl tmc"),
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := featuresOptions.Complete(args); err != nil {
+				return err
+			}
+
+			if err := featuresOptions.Validate(); err != nil {
+				return err
+			}
+
+			return featuresOptions.Run(c.Context())
+		},
+	}
+
+	featuresOptions.BindFlags(cmd)
+
+	return cmd
+}
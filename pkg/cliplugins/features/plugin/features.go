@@ -0,0 +1,160 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+var (
+	clusterPathRegexp = regexp.MustCompile(`/clusters/[^/]+/?$`)
+
+	featureStatusesGVR = tmcv1alpha1.SchemeGroupVersion.WithResource("tmcfeaturestatuses")
+)
+
+// FeaturesOptions contains options for reporting the TMC features of
+// workspaces.
+type FeaturesOptions struct {
+	*base.Options
+
+	// listFeatureStatuses lists the TMCFeatureStatuses of all workspaces.
+	listFeatureStatuses func(ctx context.Context) ([]tmcv1alpha1.TMCFeatureStatus, error)
+}
+
+// NewFeaturesOptions returns a new FeaturesOptions.
+func NewFeaturesOptions(streams base.IOStreams) *FeaturesOptions {
+	return &FeaturesOptions{
+		Options: base.NewOptions(streams),
+	}
+}
+
+// BindFlags binds fields FeaturesOptions as command line flags to cmd's flagset.
+func (o *FeaturesOptions) BindFlags(cmd *cobra.Command) {
+	o.Options.BindFlags(cmd)
+}
+
+// Complete ensures all dynamically populated fields are initialized.
+func (o *FeaturesOptions) Complete(args []string) error {
+	if err := o.Options.Complete(); err != nil {
+		return err
+	}
+	if o.listFeatureStatuses != nil {
+		return nil
+	}
+
+	config, err := o.ClientConfig.ClientConfig()
+	if err != nil {
+		return err
+	}
+	// list across all workspaces, independent of the current workspace
+	u, err := url.Parse(config.Host)
+	if err != nil {
+		return err
+	}
+	u.Path = clusterPathRegexp.ReplaceAllString(u.Path, "")
+	config.Host = u.String()
+
+	client, err := kcpdynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	o.listFeatureStatuses = func(ctx context.Context) ([]tmcv1alpha1.TMCFeatureStatus, error) {
+		list, err := client.Resource(featureStatusesGVR).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		statuses := make([]tmcv1alpha1.TMCFeatureStatus, 0, len(list.Items))
+		for _, item := range list.Items {
+			var status tmcv1alpha1.TMCFeatureStatus
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &status); err != nil {
+				return nil, err
+			}
+			statuses = append(statuses, status)
+		}
+		return statuses, nil
+	}
+	return nil
+}
+
+// Validate validates the FeaturesOptions are complete and usable.
+func (o *FeaturesOptions) Validate() error {
+	return o.Options.Validate()
+}
+
+// Run prints the active features of every workspace with a TMCFeatureStatus,
+// followed by the feature gate mismatches, if any.
+func (o *FeaturesOptions) Run(ctx context.Context) error {
+	statuses, err := o.listFeatureStatuses(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list TMCFeatureStatuses: %w", err)
+	}
+	if len(statuses) == 0 {
+		fmt.Fprintln(o.ErrOut, "No workspaces use TMC.")
+		return nil
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return logicalcluster.From(&statuses[i]).String() < logicalcluster.From(&statuses[j]).String()
+	})
+
+	w := tabwriter.NewWriter(o.Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "WORKSPACE\tACTIVE\tSYNCERS\tMISMATCHES")
+	mismatches := 0
+	for _, s := range statuses {
+		var active []string
+		for _, feature := range s.Status.Workspace {
+			if feature.Active {
+				active = append(active, feature.Name)
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\n", logicalcluster.From(&s), orNone(strings.Join(active, ",")), len(s.Status.SyncTargets), len(s.Status.Mismatches))
+		mismatches += len(s.Status.Mismatches)
+	}
+	if mismatches > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "WORKSPACE\tSYNCTARGET\tFEATURE GATE\tMESSAGE")
+		for _, s := range statuses {
+			for _, m := range s.Status.Mismatches {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", logicalcluster.From(&s), m.SyncTarget, m.FeatureGate, m.Message)
+			}
+		}
+	}
+	return w.Flush()
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func TestFeaturesReport(t *testing.T) {
+	featureStatus := func(workspace string, status tmcv1alpha1.TMCFeatureStatusStatus) tmcv1alpha1.TMCFeatureStatus {
+		return tmcv1alpha1.TMCFeatureStatus{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        tmcv1alpha1.TMCFeatureStatusName,
+				Annotations: map[string]string{logicalcluster.AnnotationKey: workspace},
+			},
+			Status: status,
+		}
+	}
+
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	o := NewFeaturesOptions(base.IOStreams{Out: out, ErrOut: errOut})
+	var statuses []tmcv1alpha1.TMCFeatureStatus
+	o.listFeatureStatuses = func(ctx context.Context) ([]tmcv1alpha1.TMCFeatureStatus, error) {
+		return statuses, nil
+	}
+
+	require.NoError(t, o.Run(context.Background()))
+	require.Empty(t, out.String())
+	require.Contains(t, errOut.String(), "No workspaces use TMC.")
+
+	statuses = []tmcv1alpha1.TMCFeatureStatus{
+		featureStatus("root:b", tmcv1alpha1.TMCFeatureStatusStatus{
+			Workspace: []tmcv1alpha1.WorkspaceFeature{{Name: "TMCControllers"}, {Name: "TMCPlacement"}},
+		}),
+		featureStatus("root:a", tmcv1alpha1.TMCFeatureStatusStatus{
+			Workspace:   []tmcv1alpha1.WorkspaceFeature{{Name: "TMCControllers", Active: true}, {Name: "TMCPlacement", Active: true}},
+			SyncTargets: []tmcv1alpha1.SyncTargetFeatureGates{{Name: "us-1"}, {Name: "eu-1"}},
+			Mismatches: []tmcv1alpha1.FeatureMismatch{{
+				FeatureGate: "TMCPlacement", SyncTarget: "us-1",
+				Message: "TMCPlacement is disabled in the syncer, but enabled in the TMC controllers",
+			}},
+		}),
+	}
+	require.NoError(t, o.Run(context.Background()))
+	require.Equal(t, `WORKSPACE  ACTIVE                       SYNCERS  MISMATCHES
+root:a     TMCControllers,TMCPlacement  2        1
+root:b     <none>                       0        0
+
+WORKSPACE  SYNCTARGET  FEATURE GATE  MESSAGE
+root:a     us-1        TMCPlacement  TMCPlacement is disabled in the syncer, but enabled in the TMC controllers
+`, out.String())
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/pkg/cliplugins/placements/plugin"
+)
+
+var (
+	listExample = `
+# List where the workloads of the current namespace are placed.
+%[1]s placements list
+
+# List the placements of all namespaces of all workspaces as JSON.
+%[1]s placements list -A --all-workspaces -o json
+`
+
+	describeExample = `
+# Show where a workload is placed and why.
+%[1]s placements describe web -n shop
+`
+)
+
+// New provides a command for inspecting the placements of workloads.
+func New(streams base.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "placements",
+		Aliases: []string{"placement"},
+		Short:   "Inspect where workloads are placed",
+		Long:    "Inspect the SyncTargets workloads are placed on, as decided for their WorkloadDistributions.",
+	}
+	cmd.AddCommand(newList(streams))
+	cmd.AddCommand(newDescribe(streams))
+	return cmd
+}
+
+func newList(streams base.IOStreams) *cobra.Command {
+	listOptions := plugin.NewListOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "list",
+		Short:        "List the placements of workloads",
+		Long:         "List the WorkloadDistributions with the SyncTargets their workloads are placed on, and whether they are placed and ready.",
+		Example:      fmt.Sprintf(listExample, "kubectl tmc"),
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := listOptions.Complete(args); err != nil {
+				return err
+			}
+
+			if err := listOptions.Validate(); err != nil {
+				return err
+			}
+
+			return listOptions.Run(c.Context())
+		},
+	}
+
+	listOptions.BindFlags(cmd)
+
+	return cmd
+}
+
+func newDescribe(streams base.IOStreams) *cobra.Command {
+	describeOptions := plugin.NewDescribeOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "describe WORKLOAD_DISTRIBUTION",
+		Short:        "Describe the placement of a workload",
+		Long:         "Describe the SyncTargets a workload is placed on and its conditions, with the scores and rejected SyncTargets of its latest recorded placement decision.",
+		Example:      fmt.Sprintf(describeExample, "kubectl tmc"),
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := describeOptions.Complete(args); err != nil {
+				return err
+			}
+
+			if err := describeOptions.Validate(); err != nil {
+				return err
+			}
+
+			return describeOptions.Run(c.Context())
+		},
+	}
+
+	describeOptions.BindFlags(cmd)
+
+	return cmd
+}
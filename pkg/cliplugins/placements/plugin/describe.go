@@ -0,0 +1,240 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/dynamic"
+
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/pkg/placement/decision"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// DescribeOptions contains options for describing the placement of a
+// workload.
+type DescribeOptions struct {
+	*base.Options
+
+	// Namespace and Name of the WorkloadDistribution to describe.
+	Namespace string
+	Name      string
+
+	// getDistribution gets a WorkloadDistribution of the current workspace.
+	getDistribution func(ctx context.Context, namespace, name string) (*workloadv1alpha1.WorkloadDistribution, error)
+	// lastDecision returns the latest recorded placement decision of a
+	// WorkloadDistribution, or nil if none is recorded.
+	lastDecision func(ctx context.Context, namespace, name string) (*decision.DecisionRecord, error)
+}
+
+// NewDescribeOptions returns a new DescribeOptions.
+func NewDescribeOptions(streams base.IOStreams) *DescribeOptions {
+	return &DescribeOptions{
+		Options: base.NewOptions(streams),
+	}
+}
+
+// BindFlags binds fields DescribeOptions as command line flags to cmd's flagset.
+func (o *DescribeOptions) BindFlags(cmd *cobra.Command) {
+	o.Options.BindFlags(cmd)
+}
+
+// Complete ensures all dynamically populated fields are initialized.
+func (o *DescribeOptions) Complete(args []string) error {
+	if len(args) > 0 {
+		o.Name = args[0]
+	}
+	if err := o.Options.Complete(); err != nil {
+		return err
+	}
+	if o.Namespace == "" {
+		var err error
+		if o.Namespace, _, err = o.ClientConfig.Namespace(); err != nil {
+			return err
+		}
+	}
+	if o.getDistribution != nil && o.lastDecision != nil {
+		return nil
+	}
+
+	config, err := o.ClientConfig.ClientConfig()
+	if err != nil {
+		return err
+	}
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	o.getDistribution = func(ctx context.Context, namespace, name string) (*workloadv1alpha1.WorkloadDistribution, error) {
+		u, err := client.Resource(distributionGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		d := &workloadv1alpha1.WorkloadDistribution{}
+		return d, runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, d)
+	}
+
+	// decision records are looked up with the storage of the placement
+	// controller, which addresses workspaces by name
+	u, err := url.Parse(config.Host)
+	if err != nil {
+		return err
+	}
+	var workspace logicalcluster.Name
+	if match := clusterPathRegexp.FindStringSubmatch(u.Path); match != nil {
+		workspace = logicalcluster.Name(match[1])
+	}
+	u.Path = clusterPathRegexp.ReplaceAllString(u.Path, "")
+	config.Host = u.String()
+	clusterClient, err := kcpdynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	storage := decision.NewCRDStorage(clusterClient)
+	o.lastDecision = func(ctx context.Context, namespace, name string) (*decision.DecisionRecord, error) {
+		page, err := storage.History(ctx, decision.HistoryQuery{Workspace: workspace, Namespace: namespace, Name: name, Descending: true, Limit: 1})
+		if err != nil || len(page.Records) == 0 {
+			return nil, err
+		}
+		return &page.Records[0], nil
+	}
+	return nil
+}
+
+// Validate validates the DescribeOptions are complete and usable.
+func (o *DescribeOptions) Validate() error {
+	var errs []error
+
+	if err := o.Options.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if o.Name == "" {
+		errs = append(errs, fmt.Errorf("a workload distribution name is required"))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// Run prints the placement of the workload, and why it was placed there
+// according to the latest recorded decision.
+func (o *DescribeOptions) Run(ctx context.Context) error {
+	d, err := o.getDistribution(ctx, o.Namespace, o.Name)
+	if err != nil {
+		return fmt.Errorf("failed to get workload distribution %s/%s: %w", o.Namespace, o.Name, err)
+	}
+	record, err := o.lastDecision(ctx, o.Namespace, o.Name)
+	if err != nil {
+		// decisions are recorded only if enabled on the server, so the
+		// placement is described regardless
+		fmt.Fprintf(o.ErrOut, "Failed to look up the placement decisions of %s/%s: %v\n", o.Namespace, o.Name, err)
+	}
+	return describe(o.Out, d, record)
+}
+
+func describe(out io.Writer, d *workloadv1alpha1.WorkloadDistribution, record *decision.DecisionRecord) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", d.Name)
+	fmt.Fprintf(w, "Namespace:\t%s\n", d.Namespace)
+	if workspace := logicalcluster.From(d); !workspace.Empty() {
+		fmt.Fprintf(w, "Workspace:\t%s\n", workspace)
+	}
+	fmt.Fprintf(w, "Workload:\t%s %s/%s\n", d.Spec.WorkloadRef.APIVersion, d.Spec.WorkloadRef.Kind, d.Spec.WorkloadRef.Name)
+	policy := d.Spec.PolicyRef.Name
+	if d.Status.PolicyRevision != "" {
+		policy += " (revision " + d.Status.PolicyRevision + ")"
+	}
+	fmt.Fprintf(w, "Policy:\t%s\n", policy)
+
+	fmt.Fprintln(w, "Targets:")
+	if len(d.Status.Targets) == 0 {
+		fmt.Fprintln(w, "  <none>")
+	}
+	for _, t := range d.Status.Targets {
+		var details []string
+		if t.Location != "" {
+			details = append(details, "location "+t.Location)
+		}
+		if t.Replicas != nil {
+			details = append(details, fmt.Sprintf("%d replicas", *t.Replicas))
+		}
+		if len(details) == 0 {
+			fmt.Fprintf(w, "  %s\n", t.SyncTarget)
+			continue
+		}
+		fmt.Fprintf(w, "  %s\t%s\n", t.SyncTarget, strings.Join(details, ", "))
+	}
+	if len(d.Status.DisplacedTargets) > 0 {
+		fmt.Fprintf(w, "Displaced Targets:\t%s\n", strings.Join(d.Status.DisplacedTargets, ","))
+	}
+
+	fmt.Fprintln(w, "Conditions:")
+	for _, c := range d.Status.Conditions {
+		fields := []string{"  " + string(c.Type), string(c.Status)}
+		if c.Reason != "" {
+			fields = append(fields, c.Reason)
+		}
+		if c.Message != "" {
+			fields = append(fields, c.Message)
+		}
+		fmt.Fprintln(w, strings.Join(fields, "\t"))
+	}
+
+	if record != nil {
+		fmt.Fprintf(w, "Last Decision:\t%s at %s, %s in %s\n", record.ID, record.Time.UTC().Format(time.RFC3339), record.Status, record.Duration)
+		if record.Message != "" {
+			fmt.Fprintf(w, "  Message:\t%s\n", record.Message)
+		}
+		if len(record.Scores) > 0 {
+			fmt.Fprintln(w, "  Scores:")
+			for _, name := range sortedKeys(record.Scores) {
+				fmt.Fprintf(w, "    %s\t%d\n", name, record.Scores[name])
+			}
+		}
+		if len(record.Rejected) > 0 {
+			fmt.Fprintln(w, "  Rejected:")
+			for _, name := range sortedKeys(record.Rejected) {
+				fmt.Fprintf(w, "    %s\t%s\n", name, record.Rejected[name])
+			}
+		}
+	}
+	return w.Flush()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,276 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/dynamic"
+
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var (
+	distributionGVR = workloadv1alpha1.SchemeGroupVersion.WithResource("workloaddistributions")
+
+	clusterPathRegexp = regexp.MustCompile(`/clusters/([^/]+)/?$`)
+)
+
+// ListOptions contains options for listing the placements of workloads.
+type ListOptions struct {
+	*base.Options
+
+	// AllNamespaces lists the placements of all namespaces rather than of
+	// the current one.
+	AllNamespaces bool
+	// AllWorkspaces lists the placements of all workspaces rather than of
+	// the current one.
+	AllWorkspaces bool
+	// Output is the output format, table or json.
+	Output string
+
+	// listDistributions lists the WorkloadDistributions to show.
+	listDistributions func(ctx context.Context) ([]workloadv1alpha1.WorkloadDistribution, error)
+}
+
+// NewListOptions returns a new ListOptions.
+func NewListOptions(streams base.IOStreams) *ListOptions {
+	return &ListOptions{
+		Options: base.NewOptions(streams),
+		Output:  "table",
+	}
+}
+
+// BindFlags binds fields ListOptions as command line flags to cmd's flagset.
+func (o *ListOptions) BindFlags(cmd *cobra.Command) {
+	o.Options.BindFlags(cmd)
+
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "List the placements of all namespaces, rather than of the current namespace")
+	cmd.Flags().BoolVar(&o.AllWorkspaces, "all-workspaces", o.AllWorkspaces, "List the placements of all workspaces, rather than of the current workspace")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format, table or json")
+}
+
+// Complete ensures all dynamically populated fields are initialized.
+func (o *ListOptions) Complete(args []string) error {
+	if err := o.Options.Complete(); err != nil {
+		return err
+	}
+	if o.listDistributions != nil {
+		return nil
+	}
+
+	namespace := ""
+	if !o.AllNamespaces {
+		var err error
+		if namespace, _, err = o.ClientConfig.Namespace(); err != nil {
+			return err
+		}
+	}
+	list, err := distributionLister(o.Options, o.AllWorkspaces)
+	if err != nil {
+		return err
+	}
+	o.listDistributions = func(ctx context.Context) ([]workloadv1alpha1.WorkloadDistribution, error) {
+		distributions, err := list(ctx, namespace)
+		if err != nil {
+			return nil, err
+		}
+		// the listing of all workspaces is not restricted to a namespace
+		filtered := distributions[:0]
+		for _, d := range distributions {
+			if namespace == "" || d.Namespace == namespace {
+				filtered = append(filtered, d)
+			}
+		}
+		return filtered, nil
+	}
+	return nil
+}
+
+// Validate validates the ListOptions are complete and usable.
+func (o *ListOptions) Validate() error {
+	var errs []error
+
+	if err := o.Options.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if o.Output != "table" && o.Output != "json" {
+		errs = append(errs, fmt.Errorf("invalid output format %q, must be table or json", o.Output))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// Placement is the placement of a workload.
+type Placement struct {
+	Workspace string                             `json:"workspace,omitempty"`
+	Namespace string                             `json:"namespace"`
+	Name      string                             `json:"name"`
+	Workload  workloadv1alpha1.WorkloadReference `json:"workload"`
+	Policy    workloadv1alpha1.PolicyReference   `json:"policy"`
+	Targets   []workloadv1alpha1.TargetPlacement `json:"targets,omitempty"`
+	// Placed and Ready summarize the conditions of the same name.
+	Placed string `json:"placed"`
+	Ready  string `json:"ready"`
+}
+
+// Run prints the placements of the workloads.
+func (o *ListOptions) Run(ctx context.Context) error {
+	distributions, err := o.listDistributions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list workload distributions: %w", err)
+	}
+	placements := make([]Placement, 0, len(distributions))
+	for i := range distributions {
+		d := &distributions[i]
+		placements = append(placements, Placement{
+			Workspace: logicalcluster.From(d).String(),
+			Namespace: d.Namespace,
+			Name:      d.Name,
+			Workload:  d.Spec.WorkloadRef,
+			Policy:    d.Spec.PolicyRef,
+			Targets:   d.Status.Targets,
+			Placed:    summary(d, workloadv1alpha1.WorkloadPlaced),
+			Ready:     summary(d, workloadv1alpha1.WorkloadReady),
+		})
+	}
+	sort.Slice(placements, func(i, j int) bool {
+		a, b := placements[i], placements[j]
+		if a.Workspace != b.Workspace {
+			return a.Workspace < b.Workspace
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+
+	if o.Output == "json" {
+		enc := json.NewEncoder(o.Out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(placements)
+	}
+	if len(placements) == 0 {
+		fmt.Fprintln(o.ErrOut, "No workload distributions found.")
+		return nil
+	}
+	return printPlacements(o.Out, placements)
+}
+
+func printPlacements(out io.Writer, placements []Placement) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "WORKSPACE\tNAMESPACE\tNAME\tWORKLOAD\tPOLICY\tTARGETS\tPLACED\tREADY")
+	for _, p := range placements {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s/%s\t%s\t%s\t%s\t%s\n", p.Workspace, p.Namespace, p.Name, p.Workload.Kind, p.Workload.Name, p.Policy.Name, targetNames(p.Targets), p.Placed, p.Ready)
+	}
+	return w.Flush()
+}
+
+// targetNames returns the comma separated names of the targets.
+func targetNames(targets []workloadv1alpha1.TargetPlacement) string {
+	if len(targets) == 0 {
+		return "<none>"
+	}
+	names := make([]string, 0, len(targets))
+	for _, t := range targets {
+		names = append(names, t.SyncTarget)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// summary returns the status of the condition, with its reason unless true.
+func summary(getter conditions.Getter, t conditionsv1alpha1.ConditionType) string {
+	c := conditions.Get(getter, t)
+	switch {
+	case c == nil:
+		return "-"
+	case c.Reason == "" || conditions.IsTrue(getter, t):
+		return string(c.Status)
+	default:
+		return fmt.Sprintf("%s (%s)", c.Status, c.Reason)
+	}
+}
+
+// distributionLister returns a function listing the WorkloadDistributions
+// of a namespace of the current workspace, or of all namespaces if empty.
+// With allWorkspaces it lists those of all workspaces instead, regardless of
+// the namespace.
+func distributionLister(o *base.Options, allWorkspaces bool) (func(ctx context.Context, namespace string) ([]workloadv1alpha1.WorkloadDistribution, error), error) {
+	config, err := o.ClientConfig.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	list := func(ctx context.Context, namespace string) (*unstructured.UnstructuredList, error) {
+		return client.Resource(distributionGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	}
+	if allWorkspaces {
+		// list across all workspaces, independent of the current workspace
+		u, err := url.Parse(config.Host)
+		if err != nil {
+			return nil, err
+		}
+		u.Path = clusterPathRegexp.ReplaceAllString(u.Path, "")
+		config.Host = u.String()
+		clusterClient, err := kcpdynamic.NewForConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		list = func(ctx context.Context, _ string) (*unstructured.UnstructuredList, error) {
+			return clusterClient.Resource(distributionGVR).List(ctx, metav1.ListOptions{})
+		}
+	}
+	return func(ctx context.Context, namespace string) ([]workloadv1alpha1.WorkloadDistribution, error) {
+		l, err := list(ctx, namespace)
+		if err != nil {
+			return nil, err
+		}
+		distributions := make([]workloadv1alpha1.WorkloadDistribution, 0, len(l.Items))
+		for _, item := range l.Items {
+			var d workloadv1alpha1.WorkloadDistribution
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &d); err != nil {
+				return nil, err
+			}
+			distributions = append(distributions, d)
+		}
+		return distributions, nil
+	}, nil
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/pkg/placement/decision"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+func distribution(name string, targets ...string) workloadv1alpha1.WorkloadDistribution {
+	d := workloadv1alpha1.WorkloadDistribution{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Annotations: map[string]string{"kcp.io/cluster": "root:org"}},
+		Spec: workloadv1alpha1.WorkloadDistributionSpec{
+			WorkloadRef: workloadv1alpha1.WorkloadReference{APIVersion: "apps/v1", Kind: "Deployment", Name: name},
+			PolicyRef:   workloadv1alpha1.PolicyReference{Name: "spread"},
+		},
+	}
+	for _, t := range targets {
+		d.Status.Targets = append(d.Status.Targets, workloadv1alpha1.TargetPlacement{SyncTarget: t})
+	}
+	return d
+}
+
+func TestList(t *testing.T) {
+	web := distribution("web", "us-1", "eu-1")
+	web.Status.Conditions = conditionsv1alpha1.Conditions{
+		{Type: workloadv1alpha1.WorkloadPlaced, Status: corev1.ConditionTrue},
+		{Type: workloadv1alpha1.WorkloadReady, Status: corev1.ConditionFalse, Reason: "Progressing"},
+	}
+	db := distribution("db")
+	db.Status.Conditions = conditionsv1alpha1.Conditions{
+		{Type: workloadv1alpha1.WorkloadPlaced, Status: corev1.ConditionFalse, Reason: workloadv1alpha1.NoFeasibleTargetsReason},
+	}
+
+	out := &bytes.Buffer{}
+	o := NewListOptions(base.IOStreams{Out: out, ErrOut: &bytes.Buffer{}})
+	o.listDistributions = func(context.Context) ([]workloadv1alpha1.WorkloadDistribution, error) {
+		return []workloadv1alpha1.WorkloadDistribution{web, db}, nil
+	}
+
+	require.NoError(t, o.Run(context.Background()))
+	require.Equal(t, `WORKSPACE  NAMESPACE  NAME  WORKLOAD        POLICY  TARGETS    PLACED                     READY
+root:org   default    db    Deployment/db   spread  <none>     False (NoFeasibleTargets)  -
+root:org   default    web   Deployment/web  spread  eu-1,us-1  True                       False (Progressing)
+`, out.String())
+}
+
+func TestDescribe(t *testing.T) {
+	web := distribution("web", "eu-1")
+	web.Status.PolicyRevision = "spread-2"
+	web.Status.Conditions = conditionsv1alpha1.Conditions{{Type: workloadv1alpha1.WorkloadPlaced, Status: corev1.ConditionTrue}}
+
+	out := &bytes.Buffer{}
+	o := NewDescribeOptions(base.IOStreams{Out: out, ErrOut: &bytes.Buffer{}})
+	o.Namespace = "default"
+	o.Name = "web"
+	o.getDistribution = func(_ context.Context, namespace, name string) (*workloadv1alpha1.WorkloadDistribution, error) {
+		require.Equal(t, "default/web", namespace+"/"+name)
+		return &web, nil
+	}
+	o.lastDecision = func(context.Context, string, string) (*decision.DecisionRecord, error) {
+		return &decision.DecisionRecord{
+			ID:       "42",
+			Status:   decision.StatusSucceeded,
+			Time:     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			Duration: 3 * time.Millisecond,
+			Scores:   map[string]int{"eu-1": 80, "eu-2": 40},
+			Rejected: map[string]string{"us-1": "is unschedulable"},
+		}, nil
+	}
+
+	require.NoError(t, o.Run(context.Background()))
+	require.Equal(t, `Name:       web
+Namespace:  default
+Workspace:  root:org
+Workload:   apps/v1 Deployment/web
+Policy:     spread (revision spread-2)
+Targets:
+  eu-1
+Conditions:
+  Placed        True
+Last Decision:  42 at 2025-01-01T00:00:00Z, Succeeded in 3ms
+  Scores:
+    eu-1  80
+    eu-2  40
+  Rejected:
+    us-1  is unschedulable
+`, out.String())
+}
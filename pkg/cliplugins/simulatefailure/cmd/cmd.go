@@ -0,0 +1,83 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/pkg/cliplugins/simulatefailure/plugin"
+)
+
+var (
+	simulateFailureExample = `
+# Rehearse the failover of the workloads on a sync target for ten minutes and report how they moved.
+%[1]s us-east-1 --duration 10m
+
+# Observe workloads of all workspaces and print the report as JSON.
+%[1]s us-east-1 --all-workspaces -o json
+`
+)
+
+// New provides a command for simulating the failure of a SyncTarget.
+func New(streams base.IOStreams) *cobra.Command {
+	return newSimulateFailure(streams, "simulate-failure", "kubectl tmc simulate-failure")
+}
+
+// NewSimulate provides a command for simulating events, to rehearse how
+// placement reacts to them.
+func NewSimulate(streams base.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "Simulate events to rehearse how placement reacts",
+		Long:  "Simulate events at the placement layer, without touching the physical clusters, to rehearse how placement reacts to them.",
+	}
+	cmd.AddCommand(newSimulateFailure(streams, "failure", "kubectl tmc simulate failure"))
+	return cmd
+}
+
+// newSimulateFailure returns the command simulating a failure, named name
+// and invoked as invocation.
+func newSimulateFailure(streams base.IOStreams, name, invocation string) *cobra.Command {
+	simulateOptions := plugin.NewSimulateFailureOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          name + " SYNC_TARGET",
+		Short:        "Simulate the failure of a SyncTarget to rehearse failover",
+		Long:         "Simulate the failure of a SyncTarget at the placement layer, without touching the physical cluster. Placement treats the SyncTarget as failed for the given duration, so that Singleton and HighAvailability workloads fail over. The placement of the workloads on the SyncTarget is observed meanwhile and reported once the simulation ends or is interrupted.",
+		Example:      fmt.Sprintf(simulateFailureExample, invocation),
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := simulateOptions.Complete(args); err != nil {
+				return err
+			}
+
+			if err := simulateOptions.Validate(); err != nil {
+				return err
+			}
+
+			return simulateOptions.Run(c.Context())
+		},
+	}
+
+	simulateOptions.BindFlags(cmd)
+
+	return cmd
+}
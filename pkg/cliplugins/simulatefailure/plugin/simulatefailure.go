@@ -0,0 +1,347 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/dynamic"
+
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var (
+	syncTargetGVR   = tmcv1alpha1.SchemeGroupVersion.WithResource("synctargets")
+	distributionGVR = workloadv1alpha1.SchemeGroupVersion.WithResource("workloaddistributions")
+
+	clusterPathRegexp = regexp.MustCompile(`/clusters/[^/]+/?$`)
+)
+
+// SimulateFailureOptions contains options for simulating the failure of a
+// SyncTarget.
+type SimulateFailureOptions struct {
+	*base.Options
+
+	// Name is the SyncTarget whose failure is simulated.
+	Name string
+	// Duration is how long the failure is simulated.
+	Duration time.Duration
+	// Interval is how often the placement of the workloads is observed.
+	Interval time.Duration
+	// AllWorkspaces observes the workloads of all workspaces rather than of
+	// the current one.
+	AllWorkspaces bool
+	// Output is the output format of the report, table or json.
+	Output string
+
+	// patchSyncTarget merge-patches a SyncTarget of the current workspace.
+	patchSyncTarget func(ctx context.Context, name string, patch []byte) error
+	// listDistributions lists the WorkloadDistributions to observe.
+	listDistributions func(ctx context.Context) ([]workloadv1alpha1.WorkloadDistribution, error)
+	now               func() time.Time
+	// sleep waits for d, or until ctx is done.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewSimulateFailureOptions returns a new SimulateFailureOptions.
+func NewSimulateFailureOptions(streams base.IOStreams) *SimulateFailureOptions {
+	return &SimulateFailureOptions{
+		Options:  base.NewOptions(streams),
+		Duration: 10 * time.Minute,
+		Interval: 5 * time.Second,
+		Output:   "table",
+		now:      time.Now,
+		sleep: func(ctx context.Context, d time.Duration) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(d):
+				return nil
+			}
+		},
+	}
+}
+
+// BindFlags binds fields SimulateFailureOptions as command line flags to cmd's flagset.
+func (o *SimulateFailureOptions) BindFlags(cmd *cobra.Command) {
+	o.Options.BindFlags(cmd)
+
+	cmd.Flags().DurationVar(&o.Duration, "duration", o.Duration, "How long the failure is simulated")
+	cmd.Flags().DurationVar(&o.Interval, "interval", o.Interval, "How often the placement of the workloads is observed")
+	cmd.Flags().BoolVar(&o.AllWorkspaces, "all-workspaces", o.AllWorkspaces, "Observe the workloads of all workspaces placed on a sync target of that name, rather than of the current workspace")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format of the report, table or json")
+}
+
+// Complete ensures all dynamically populated fields are initialized.
+func (o *SimulateFailureOptions) Complete(args []string) error {
+	if len(args) > 0 {
+		o.Name = args[0]
+	}
+	if err := o.Options.Complete(); err != nil {
+		return err
+	}
+	if o.patchSyncTarget != nil && o.listDistributions != nil {
+		return nil
+	}
+
+	config, err := o.ClientConfig.ClientConfig()
+	if err != nil {
+		return err
+	}
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	o.patchSyncTarget = func(ctx context.Context, name string, patch []byte) error {
+		_, err := client.Resource(syncTargetGVR).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return err
+	}
+
+	list := client.Resource(distributionGVR).List
+	if o.AllWorkspaces {
+		// list across all workspaces, independent of the current workspace
+		u, err := url.Parse(config.Host)
+		if err != nil {
+			return err
+		}
+		u.Path = clusterPathRegexp.ReplaceAllString(u.Path, "")
+		config.Host = u.String()
+		clusterClient, err := kcpdynamic.NewForConfig(config)
+		if err != nil {
+			return err
+		}
+		list = clusterClient.Resource(distributionGVR).List
+	}
+	o.listDistributions = func(ctx context.Context) ([]workloadv1alpha1.WorkloadDistribution, error) {
+		l, err := list(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		distributions := make([]workloadv1alpha1.WorkloadDistribution, 0, len(l.Items))
+		for _, item := range l.Items {
+			var d workloadv1alpha1.WorkloadDistribution
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &d); err != nil {
+				return nil, err
+			}
+			distributions = append(distributions, d)
+		}
+		return distributions, nil
+	}
+	return nil
+}
+
+// Validate validates the SimulateFailureOptions are complete and usable.
+func (o *SimulateFailureOptions) Validate() error {
+	var errs []error
+
+	if err := o.Options.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if o.Name == "" {
+		errs = append(errs, fmt.Errorf("a sync target name is required"))
+	}
+	if o.Duration <= 0 {
+		errs = append(errs, fmt.Errorf("--duration must be positive"))
+	}
+	if o.Interval <= 0 {
+		errs = append(errs, fmt.Errorf("--interval must be positive"))
+	}
+	if o.Output != "table" && o.Output != "json" {
+		errs = append(errs, fmt.Errorf("invalid output format %q, must be table or json", o.Output))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// Report is the timeline of a simulated failure of a SyncTarget.
+type Report struct {
+	SyncTarget string    `json:"syncTarget"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	// Workloads are the workloads placed on the SyncTarget when the failure
+	// started.
+	Workloads []*WorkloadTimeline `json:"workloads"`
+}
+
+// WorkloadTimeline is how the placement of a workload changed during a
+// simulated failure.
+type WorkloadTimeline struct {
+	Workspace string `json:"workspace,omitempty"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// FailedOverAfter is how long after the start of the failure the
+	// workload was moved off the SyncTarget, unset if it was not.
+	FailedOverAfter *metav1.Duration `json:"failedOverAfter,omitempty"`
+	// Placements are the observed targets of the workload, starting with
+	// the ones at the start of the failure.
+	Placements []Placement `json:"placements"`
+}
+
+// Placement are the targets of a workload from Time on.
+type Placement struct {
+	Time    time.Time `json:"time"`
+	Targets []string  `json:"targets"`
+}
+
+// Run simulates the failure, observes the workloads placed on the SyncTarget
+// until it ends or ctx is done, ends it and prints the report.
+func (o *SimulateFailureOptions) Run(ctx context.Context) error {
+	report := &Report{SyncTarget: o.Name, Start: o.now()}
+	until := report.Start.Add(o.Duration)
+	patch, err := json.Marshal(map[string]interface{}{"metadata": map[string]interface{}{"annotations": map[string]interface{}{
+		tmcv1alpha1.AnnotationSimulatedFailureUntil: until.UTC().Format(time.RFC3339),
+	}}})
+	if err != nil {
+		return err
+	}
+	if err := o.patchSyncTarget(ctx, o.Name, patch); err != nil {
+		return fmt.Errorf("failed to simulate the failure of sync target %q: %w", o.Name, err)
+	}
+	fmt.Fprintf(o.ErrOut, "Simulating a failure of sync target %q until %s.\n", o.Name, until.UTC().Format(time.RFC3339))
+
+	var errs []error
+	timelines := map[string]*WorkloadTimeline{}
+	for first := true; ; first = false {
+		if err := o.observe(ctx, report, timelines, first); err != nil {
+			if ctx.Err() == nil {
+				errs = append(errs, err)
+			}
+			break
+		}
+		if !o.now().Before(until) || o.sleep(ctx, min(o.Interval, until.Sub(o.now()))) != nil {
+			break
+		}
+	}
+
+	// The failure ends by itself at the given time, but is ended right away
+	// when the simulation is interrupted.
+	end := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:null}}}`, tmcv1alpha1.AnnotationSimulatedFailureUntil))
+	if err := o.patchSyncTarget(context.WithoutCancel(ctx), o.Name, end); err != nil {
+		errs = append(errs, fmt.Errorf("failed to end the simulated failure of sync target %q: %w", o.Name, err))
+	}
+	report.End = o.now()
+
+	if err := o.print(report); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// observe records the current targets of the workloads placed on the
+// SyncTarget at the first observation.
+func (o *SimulateFailureOptions) observe(ctx context.Context, report *Report, timelines map[string]*WorkloadTimeline, first bool) error {
+	distributions, err := o.listDistributions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list workload distributions: %w", err)
+	}
+	now := o.now()
+	for i := range distributions {
+		d := &distributions[i]
+		workspace := logicalcluster.From(d).String()
+		key := workspace + "|" + d.Namespace + "/" + d.Name
+		targets := make([]string, 0, len(d.Status.Targets))
+		for _, t := range d.Status.Targets {
+			targets = append(targets, t.SyncTarget)
+		}
+		sort.Strings(targets)
+
+		timeline, found := timelines[key]
+		if !found {
+			if !first || !slices.Contains(targets, o.Name) {
+				continue
+			}
+			timeline = &WorkloadTimeline{Workspace: workspace, Namespace: d.Namespace, Name: d.Name}
+			timelines[key] = timeline
+			report.Workloads = append(report.Workloads, timeline)
+		}
+		if n := len(timeline.Placements); n > 0 && slices.Equal(timeline.Placements[n-1].Targets, targets) {
+			continue
+		}
+		timeline.Placements = append(timeline.Placements, Placement{Time: now, Targets: targets})
+		if timeline.FailedOverAfter == nil && !slices.Contains(targets, o.Name) {
+			timeline.FailedOverAfter = &metav1.Duration{Duration: now.Sub(report.Start)}
+		}
+	}
+	if first {
+		sort.Slice(report.Workloads, func(i, j int) bool {
+			a, b := report.Workloads[i], report.Workloads[j]
+			if a.Workspace != b.Workspace {
+				return a.Workspace < b.Workspace
+			}
+			if a.Namespace != b.Namespace {
+				return a.Namespace < b.Namespace
+			}
+			return a.Name < b.Name
+		})
+	}
+	return nil
+}
+
+func (o *SimulateFailureOptions) print(report *Report) error {
+	if o.Output == "json" {
+		enc := json.NewEncoder(o.Out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	if len(report.Workloads) == 0 {
+		fmt.Fprintf(o.ErrOut, "No workloads were placed on sync target %q.\n", report.SyncTarget)
+		return nil
+	}
+	return printReport(o.Out, report)
+}
+
+func printReport(out io.Writer, report *Report) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "WORKSPACE\tNAMESPACE\tNAME\tFAILED OVER AFTER\tTIMELINE")
+	for _, t := range report.Workloads {
+		failedOver := "-"
+		if t.FailedOverAfter != nil {
+			failedOver = t.FailedOverAfter.Duration.String()
+		}
+		placements := make([]string, 0, len(t.Placements))
+		for _, p := range t.Placements {
+			targets := strings.Join(p.Targets, ",")
+			if targets == "" {
+				targets = "<none>"
+			}
+			placements = append(placements, fmt.Sprintf("+%s %s", p.Time.Sub(report.Start), targets))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", t.Workspace, t.Namespace, t.Name, failedOver, strings.Join(placements, "; "))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+func distribution(name string, targets ...string) workloadv1alpha1.WorkloadDistribution {
+	d := workloadv1alpha1.WorkloadDistribution{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+	for _, t := range targets {
+		d.Status.Targets = append(d.Status.Targets, workloadv1alpha1.TargetPlacement{SyncTarget: t})
+	}
+	return d
+}
+
+func TestSimulateFailure(t *testing.T) {
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := t0
+	snapshots := [][]workloadv1alpha1.WorkloadDistribution{
+		{distribution("web", "eu-1"), distribution("db", "us-1"), distribution("api", "eu-1", "us-1")},
+		{distribution("web", "eu-2"), distribution("db", "us-1"), distribution("api", "eu-1", "us-1")},
+		{distribution("web", "eu-2"), distribution("db", "us-1"), distribution("api", "us-1")},
+	}
+
+	var patches []string
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	o := NewSimulateFailureOptions(base.IOStreams{Out: out, ErrOut: errOut})
+	o.Name = "eu-1"
+	o.Duration = 12 * time.Second
+	o.now = func() time.Time { return now }
+	o.sleep = func(_ context.Context, d time.Duration) error {
+		now = now.Add(d)
+		return nil
+	}
+	o.patchSyncTarget = func(_ context.Context, name string, patch []byte) error {
+		require.Equal(t, "eu-1", name)
+		patches = append(patches, string(patch))
+		return nil
+	}
+	calls := 0
+	o.listDistributions = func(context.Context) ([]workloadv1alpha1.WorkloadDistribution, error) {
+		snapshot := snapshots[min(calls, len(snapshots)-1)]
+		calls++
+		return snapshot, nil
+	}
+
+	require.NoError(t, o.Run(context.Background()))
+	require.Equal(t, 4, calls, "observed every interval until the end of the failure")
+	require.Equal(t, []string{
+		`{"metadata":{"annotations":{"tmc.kcp.io/simulated-failure-until":"2025-01-01T00:00:12Z"}}}`,
+		`{"metadata":{"annotations":{"tmc.kcp.io/simulated-failure-until":null}}}`,
+	}, patches)
+	require.Contains(t, errOut.String(), `Simulating a failure of sync target "eu-1" until 2025-01-01T00:00:12Z.`)
+	require.Equal(t, `WORKSPACE  NAMESPACE  NAME  FAILED OVER AFTER  TIMELINE
+           default    api   10s                +0s eu-1,us-1; +10s us-1
+           default    web   5s                 +0s eu-1; +5s eu-2
+`, out.String())
+}
+
+func TestSimulateFailureEndsOnError(t *testing.T) {
+	var patches int
+	o := NewSimulateFailureOptions(base.IOStreams{Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}})
+	o.Name = "eu-1"
+	o.patchSyncTarget = func(context.Context, string, []byte) error {
+		patches++
+		return nil
+	}
+	o.listDistributions = func(context.Context) ([]workloadv1alpha1.WorkloadDistribution, error) {
+		return nil, errors.New("forbidden")
+	}
+
+	require.EqualError(t, o.Run(context.Background()), "failed to list workload distributions: forbidden")
+	require.Equal(t, 2, patches, "the failure is ended")
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/pkg/cliplugins/syncerrbac/plugin"
+)
+
+var (
+	syncerRBACExample = `
+# Print the Role and ClusterRole manifests the syncer of SyncTarget edge needs.
+%[1]s syncer-rbac edge --resource-config resources.yaml --capacity
+
+# Report the permissions the syncer service account lacks on the physical cluster.
+%[1]s syncer-rbac edge --resource-config resources.yaml --physical-cluster-kubeconfig edge.kubeconfig
+
+# Print the read-only manifests of a syncer evaluating TMC in observer mode.
+%[1]s syncer-rbac edge --resource-config resources.yaml --capacity --observer
+`
+)
+
+// New provides a command for computing the RBAC of a syncer.
+func New(streams base.IOStreams) *cobra.Command {
+	syncerRBACOptions := plugin.NewSyncerRBACOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "syncer-rbac <sync-target-name>",
+		Short:        "Compute the RBAC a syncer needs on a physical cluster",
+		Long:         "Compute the minimal RBAC rules the syncer of a SyncTarget needs on its physical cluster for the synced resources and enabled features, and print them as Role and ClusterRole manifests, or report the permissions its service account lacks.",
+		Example:      fmt.Sprintf(syncerRBACExample, "kubectl tmc"),
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := syncerRBACOptions.Complete(args); err != nil {
+				return err
+			}
+
+			if err := syncerRBACOptions.Validate(); err != nil {
+				return err
+			}
+
+			return syncerRBACOptions.Run(c.Context())
+		},
+	}
+
+	syncerRBACOptions.BindFlags(cmd)
+
+	return cmd
+}
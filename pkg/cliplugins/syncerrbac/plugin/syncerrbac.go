@@ -0,0 +1,227 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/pkg/syncer/bundles"
+	"github.com/kcp-dev/kcp/pkg/syncer/controllermanager"
+	"github.com/kcp-dev/kcp/pkg/syncer/permissions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+var syncTargetsGVR = tmcv1alpha1.SchemeGroupVersion.WithResource("synctargets")
+
+// SyncerRBACOptions contains options for computing the RBAC of a syncer.
+type SyncerRBACOptions struct {
+	*base.Options
+
+	// SyncTargetName is the SyncTarget in the current workspace.
+	SyncTargetName string
+	// ResourceConfig is the syncer resource configuration file listing the
+	// synced resources.
+	ResourceConfig string
+	// Bundles are the sync bundles enabled for the workspace, see package
+	// bundles.
+	Bundles []string
+	// Features are the enabled syncer features.
+	Features permissions.Features
+	// SyncerNamespace is the namespace of the syncer on the physical cluster.
+	SyncerNamespace string
+	// ServiceAccount is the service account of the syncer.
+	ServiceAccount string
+	// Name of the generated roles and bindings. Defaults to
+	// kcp-syncer-<SyncTarget>.
+	Name string
+	// PhysicalClusterKubeconfig is the kubeconfig of the physical cluster.
+	// If set, the permissions the service account lacks are reported
+	// instead of printing the manifests.
+	PhysicalClusterKubeconfig string
+
+	getSyncTarget func(ctx context.Context, name string) (*tmcv1alpha1.SyncTarget, error)
+	review        permissions.ReviewFunc
+}
+
+// NewSyncerRBACOptions returns a new SyncerRBACOptions.
+func NewSyncerRBACOptions(streams base.IOStreams) *SyncerRBACOptions {
+	return &SyncerRBACOptions{
+		Options:         base.NewOptions(streams),
+		SyncerNamespace: "kcp-syncer",
+		ServiceAccount:  "kcp-syncer",
+	}
+}
+
+// BindFlags binds fields SyncerRBACOptions as command line flags to cmd's flagset.
+func (o *SyncerRBACOptions) BindFlags(cmd *cobra.Command) {
+	o.Options.BindFlags(cmd)
+
+	cmd.Flags().StringVar(&o.ResourceConfig, "resource-config", o.ResourceConfig, "Syncer resource configuration file listing the synced resources")
+	cmd.Flags().StringSliceVar(&o.Bundles, "bundles", o.Bundles, "Sync bundles enabled for the workspace, e.g. observability. Their resources are included if the physical cluster serves them")
+	cmd.Flags().BoolVar(&o.Features.Capacity, "capacity", o.Features.Capacity, "Whether the syncer reports the capacity of the physical cluster")
+	cmd.Flags().BoolVar(&o.Features.PriorityClasses, "priority-classes", o.Features.PriorityClasses, "Whether the syncer creates the PriorityClasses of synced pods")
+	cmd.Flags().BoolVar(&o.Features.LeaderElection, "leader-election", o.Features.LeaderElection, "Whether the syncer replicas elect a leader")
+	cmd.Flags().BoolVar(&o.Features.Observer, "observer", o.Features.Observer, "Whether the syncer runs with --mode=observer, which only needs read access to the physical cluster")
+	cmd.Flags().StringVar(&o.SyncerNamespace, "syncer-namespace", o.SyncerNamespace, "Namespace of the syncer on the physical cluster")
+	cmd.Flags().StringVar(&o.ServiceAccount, "service-account", o.ServiceAccount, "Service account of the syncer on the physical cluster")
+	cmd.Flags().StringVar(&o.Name, "name", o.Name, "Name of the generated roles and bindings. Defaults to kcp-syncer-<sync target name>")
+	cmd.Flags().StringVar(&o.PhysicalClusterKubeconfig, "physical-cluster-kubeconfig", o.PhysicalClusterKubeconfig, "Kubeconfig of the physical cluster. If set, the permissions the service account lacks are reported instead of printing manifests")
+}
+
+// Complete ensures all dynamically populated fields are initialized.
+func (o *SyncerRBACOptions) Complete(args []string) error {
+	if len(args) > 0 {
+		o.SyncTargetName = args[0]
+	}
+	if o.Name == "" && o.SyncTargetName != "" {
+		o.Name = "kcp-syncer-" + o.SyncTargetName
+	}
+
+	if o.getSyncTarget == nil {
+		if err := o.Options.Complete(); err != nil {
+			return err
+		}
+		config, err := o.ClientConfig.ClientConfig()
+		if err != nil {
+			return err
+		}
+		client, err := dynamic.NewForConfig(config)
+		if err != nil {
+			return err
+		}
+		o.getSyncTarget = func(ctx context.Context, name string) (*tmcv1alpha1.SyncTarget, error) {
+			u, err := client.Resource(syncTargetsGVR).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			target := &tmcv1alpha1.SyncTarget{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, target); err != nil {
+				return nil, err
+			}
+			return target, nil
+		}
+	}
+
+	if o.review == nil && o.PhysicalClusterKubeconfig != "" {
+		config, err := clientcmd.BuildConfigFromFlags("", o.PhysicalClusterKubeconfig)
+		if err != nil {
+			return err
+		}
+		client, err := kubernetes.NewForConfig(config)
+		if err != nil {
+			return err
+		}
+		o.review = permissions.ServiceAccountReview(client, o.SyncerNamespace, o.ServiceAccount)
+	}
+	return nil
+}
+
+// Validate validates the SyncerRBACOptions are complete and usable.
+func (o *SyncerRBACOptions) Validate() error {
+	var errs []error
+
+	if err := o.Options.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if o.SyncTargetName == "" {
+		errs = append(errs, fmt.Errorf("a SyncTarget name is required"))
+	}
+	if o.ResourceConfig == "" {
+		errs = append(errs, fmt.Errorf("--resource-config is required"))
+	}
+	for _, name := range o.Bundles {
+		if _, found := bundles.Known[name]; !found {
+			errs = append(errs, fmt.Errorf("unknown sync bundle %q", name))
+		}
+	}
+	if o.SyncerNamespace == "" || o.ServiceAccount == "" {
+		errs = append(errs, fmt.Errorf("--syncer-namespace and --service-account must not be empty"))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// Run prints the RBAC manifests the syncer needs, or the permissions its
+// service account lacks on the physical cluster.
+func (o *SyncerRBACOptions) Run(ctx context.Context) error {
+	data, err := os.ReadFile(o.ResourceConfig)
+	if err != nil {
+		return err
+	}
+	config, err := controllermanager.ParseConfig(data)
+	if err != nil {
+		return fmt.Errorf("invalid resource configuration %s: %w", o.ResourceConfig, err)
+	}
+
+	target, err := o.getSyncTarget(ctx, o.SyncTargetName)
+	if err != nil {
+		return err
+	}
+	enabled := make([]bundles.Bundle, 0, len(o.Bundles))
+	for _, name := range o.Bundles {
+		enabled = append(enabled, bundles.Known[name])
+	}
+	config = bundles.Apply(config, enabled, bundles.Served(target))
+	resources := make([]schema.GroupVersionResource, 0, len(config))
+	for gvr := range config {
+		resources = append(resources, gvr)
+	}
+	req := permissions.ForSyncTarget(target, resources, o.Features)
+
+	if o.review != nil {
+		missing, err := permissions.Missing(ctx, o.review, o.SyncerNamespace, req)
+		if err != nil {
+			return err
+		}
+		if len(missing) == 0 {
+			fmt.Fprintf(o.ErrOut, "Service account %s/%s has all permissions the syncer needs.\n", o.SyncerNamespace, o.ServiceAccount)
+			return nil
+		}
+		for _, p := range missing {
+			fmt.Fprintln(o.Out, p.String())
+		}
+		return fmt.Errorf("service account %s/%s lacks %d permissions the syncer needs", o.SyncerNamespace, o.ServiceAccount, len(missing))
+	}
+
+	for i, obj := range permissions.Manifests(o.Name, o.SyncerNamespace, o.ServiceAccount, req) {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			fmt.Fprintln(o.Out, "---")
+		}
+		if _, err := o.Out.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func newTestOptions(t *testing.T) (*SyncerRBACOptions, *bytes.Buffer) {
+	t.Helper()
+	config := filepath.Join(t.TempDir(), "resources.yaml")
+	require.NoError(t, os.WriteFile(config, []byte(`
+resources:
+- group: apps
+  version: v1
+  resource: deployments
+`), 0o600))
+
+	out := &bytes.Buffer{}
+	o := NewSyncerRBACOptions(base.IOStreams{Out: out, ErrOut: &bytes.Buffer{}})
+	o.ResourceConfig = config
+	o.getSyncTarget = func(ctx context.Context, name string) (*tmcv1alpha1.SyncTarget, error) {
+		return &tmcv1alpha1.SyncTarget{Spec: tmcv1alpha1.SyncTargetSpec{
+			ClusterScopedResources: []tmcv1alpha1.ClusterScopedResource{{Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions"}},
+		}}, nil
+	}
+	return o, out
+}
+
+func TestSyncerRBACManifests(t *testing.T) {
+	o, out := newTestOptions(t)
+	o.Features.LeaderElection = true
+	require.NoError(t, o.Complete([]string{"edge"}))
+	require.NoError(t, o.Validate())
+	require.NoError(t, o.Run(context.Background()))
+
+	require.Contains(t, out.String(), "kind: ClusterRole\n")
+	require.Contains(t, out.String(), "kind: Role\n")
+	require.Contains(t, out.String(), "name: kcp-syncer-edge\n")
+	require.Contains(t, out.String(), "- deployments\n")
+	require.Contains(t, out.String(), "- customresourcedefinitions\n")
+	require.Contains(t, out.String(), "- leases\n")
+}
+
+func TestSyncerRBACMissing(t *testing.T) {
+	o, out := newTestOptions(t)
+	o.review = func(ctx context.Context, attrs authorizationv1.ResourceAttributes) (bool, error) {
+		return attrs.Resource != "customresourcedefinitions" || attrs.Verb != "delete", nil
+	}
+	require.NoError(t, o.Complete([]string{"edge"}))
+	require.ErrorContains(t, o.Run(context.Background()), "lacks 1 permissions")
+	require.Equal(t, "delete customresourcedefinitions.apiextensions.k8s.io\n", out.String())
+}
+
+func TestSyncerRBACValidate(t *testing.T) {
+	o := NewSyncerRBACOptions(base.IOStreams{})
+	err := o.Validate()
+	require.ErrorContains(t, err, "SyncTarget name is required")
+	require.ErrorContains(t, err, "--resource-config is required")
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	draincmd "github.com/kcp-dev/kcp/pkg/cliplugins/drain/cmd"
+	"github.com/kcp-dev/kcp/pkg/cliplugins/synctargets/plugin"
+)
+
+var (
+	getExample = `
+# Show the sync targets of the current workspace with the number of workloads placed on them.
+%[1]s synctargets get
+
+# Show the drain progress of a sync target as JSON.
+%[1]s synctargets get us-east-1 -o json
+`
+)
+
+// New provides a command for inspecting and operating SyncTargets.
+func New(streams base.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "synctargets",
+		Aliases: []string{"synctarget"},
+		Short:   "Inspect and operate SyncTargets",
+		Long:    "Inspect the SyncTargets of the current workspace, and cordon or drain them for maintenance of their physical clusters.",
+	}
+	cmd.AddCommand(newGet(streams))
+	cmd.AddCommand(draincmd.NewCordon(streams))
+	cmd.AddCommand(draincmd.NewUncordon(streams))
+	cmd.AddCommand(draincmd.NewDrain(streams))
+	return cmd
+}
+
+func newGet(streams base.IOStreams) *cobra.Command {
+	getOptions := plugin.NewGetOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "get [SYNC_TARGET...]",
+		Short:        "Show SyncTargets",
+		Long:         "Show SyncTargets with their readiness, whether they are cordoned or paused, the number of workloads of the current workspace placed on them, and the progress of their drain.",
+		Example:      fmt.Sprintf(getExample, "kubectl tmc"),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := getOptions.Complete(args); err != nil {
+				return err
+			}
+
+			if err := getOptions.Validate(); err != nil {
+				return err
+			}
+
+			return getOptions.Run(c.Context())
+		},
+	}
+
+	getOptions.BindFlags(cmd)
+
+	return cmd
+}
@@ -0,0 +1,257 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var (
+	syncTargetGVR   = tmcv1alpha1.SchemeGroupVersion.WithResource("synctargets")
+	distributionGVR = workloadv1alpha1.SchemeGroupVersion.WithResource("workloaddistributions")
+)
+
+// GetOptions contains options for showing SyncTargets.
+type GetOptions struct {
+	*base.Options
+
+	// Names are the SyncTargets to show, all if empty.
+	Names []string
+	// Output is the output format, table or json.
+	Output string
+
+	// listSyncTargets lists the SyncTargets of the current workspace.
+	listSyncTargets func(ctx context.Context) ([]tmcv1alpha1.SyncTarget, error)
+	// listDistributions lists the WorkloadDistributions of the current
+	// workspace.
+	listDistributions func(ctx context.Context) ([]workloadv1alpha1.WorkloadDistribution, error)
+}
+
+// NewGetOptions returns a new GetOptions.
+func NewGetOptions(streams base.IOStreams) *GetOptions {
+	return &GetOptions{
+		Options: base.NewOptions(streams),
+		Output:  "table",
+	}
+}
+
+// BindFlags binds fields GetOptions as command line flags to cmd's flagset.
+func (o *GetOptions) BindFlags(cmd *cobra.Command) {
+	o.Options.BindFlags(cmd)
+
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format, table or json")
+}
+
+// Complete ensures all dynamically populated fields are initialized.
+func (o *GetOptions) Complete(args []string) error {
+	o.Names = args
+	if err := o.Options.Complete(); err != nil {
+		return err
+	}
+	if o.listSyncTargets != nil && o.listDistributions != nil {
+		return nil
+	}
+
+	config, err := o.ClientConfig.ClientConfig()
+	if err != nil {
+		return err
+	}
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	o.listSyncTargets = func(ctx context.Context) ([]tmcv1alpha1.SyncTarget, error) {
+		return list[tmcv1alpha1.SyncTarget](ctx, client, syncTargetGVR)
+	}
+	o.listDistributions = func(ctx context.Context) ([]workloadv1alpha1.WorkloadDistribution, error) {
+		return list[workloadv1alpha1.WorkloadDistribution](ctx, client, distributionGVR)
+	}
+	return nil
+}
+
+// Validate validates the GetOptions are complete and usable.
+func (o *GetOptions) Validate() error {
+	var errs []error
+
+	if err := o.Options.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if o.Output != "table" && o.Output != "json" {
+		errs = append(errs, fmt.Errorf("invalid output format %q, must be table or json", o.Output))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// SyncTargetSummary summarizes the state of a SyncTarget.
+type SyncTargetSummary struct {
+	Name     string `json:"name"`
+	Location string `json:"location,omitempty"`
+	// Ready summarizes the Ready condition.
+	Ready string `json:"ready"`
+	// Scheduling is Schedulable, Cordoned or Paused.
+	Scheduling string `json:"scheduling"`
+	// Workloads is the number of workloads of the current workspace placed
+	// on the SyncTarget.
+	Workloads int `json:"workloads"`
+	// Drain is the progress of the drain of the SyncTarget, if drained.
+	Drain *tmcv1alpha1.SyncTargetDrainStatus `json:"drain,omitempty"`
+	// Drained summarizes the Drained condition while drained.
+	Drained string `json:"drained,omitempty"`
+}
+
+// Run prints the SyncTargets with the number of workloads placed on them.
+func (o *GetOptions) Run(ctx context.Context) error {
+	syncTargets, err := o.listSyncTargets(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list sync targets: %w", err)
+	}
+	distributions, err := o.listDistributions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list workload distributions: %w", err)
+	}
+	workloads := map[string]int{}
+	for _, d := range distributions {
+		for _, t := range d.Status.Targets {
+			workloads[t.SyncTarget]++
+		}
+	}
+
+	found := map[string]bool{}
+	summaries := make([]SyncTargetSummary, 0, len(syncTargets))
+	for i := range syncTargets {
+		st := &syncTargets[i]
+		if len(o.Names) > 0 && !slices.Contains(o.Names, st.Name) {
+			continue
+		}
+		found[st.Name] = true
+		summary := SyncTargetSummary{
+			Name:       st.Name,
+			Location:   st.Spec.Location,
+			Ready:      conditionSummary(st, conditionsv1alpha1.ReadyCondition),
+			Scheduling: "Schedulable",
+			Workloads:  workloads[st.Name],
+		}
+		switch {
+		case st.Spec.Unschedulable:
+			summary.Scheduling = "Cordoned"
+		case st.Spec.Paused:
+			summary.Scheduling = "Paused"
+		}
+		if st.Spec.Drain != nil {
+			summary.Drain = st.Status.Drain
+			summary.Drained = conditionSummary(st, tmcv1alpha1.Drained)
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Name < summaries[j].Name })
+
+	var errs []error
+	for _, name := range o.Names {
+		if !found[name] {
+			errs = append(errs, fmt.Errorf("sync target %q not found", name))
+		}
+	}
+
+	if o.Output == "json" {
+		enc := json.NewEncoder(o.Out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(summaries); err != nil {
+			errs = append(errs, err)
+		}
+		return utilerrors.NewAggregate(errs)
+	}
+	if len(summaries) > 0 {
+		if err := printSummaries(o.Out, summaries); err != nil {
+			errs = append(errs, err)
+		}
+	} else if len(errs) == 0 {
+		fmt.Fprintln(o.ErrOut, "No sync targets found.")
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func printSummaries(out io.Writer, summaries []SyncTargetSummary) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tLOCATION\tREADY\tSCHEDULING\tWORKLOADS\tDRAIN")
+	for _, s := range summaries {
+		drain := "-"
+		if s.Drained != "" {
+			drain = s.Drained
+			if s.Drain != nil && s.Drained != string(corev1.ConditionTrue) {
+				drain = fmt.Sprintf("%d remaining, %d moving, %d moved", s.Drain.Remaining, len(s.Drain.Evicting), s.Drain.Moved)
+			}
+		}
+		location := s.Location
+		if location == "" {
+			location = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", s.Name, location, s.Ready, s.Scheduling, s.Workloads, drain)
+	}
+	return w.Flush()
+}
+
+// conditionSummary returns the status of the condition, with its reason
+// unless true.
+func conditionSummary(getter conditions.Getter, t conditionsv1alpha1.ConditionType) string {
+	c := conditions.Get(getter, t)
+	switch {
+	case c == nil:
+		return "-"
+	case c.Reason == "" || conditions.IsTrue(getter, t):
+		return string(c.Status)
+	default:
+		return fmt.Sprintf("%s (%s)", c.Status, c.Reason)
+	}
+}
+
+func list[T any](ctx context.Context, client dynamic.Interface, gvr schema.GroupVersionResource) ([]T, error) {
+	l, err := client.Resource(gvr).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]T, 0, len(l.Items))
+	for _, item := range l.Items {
+		var t T
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &t); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
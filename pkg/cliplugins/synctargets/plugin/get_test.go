@@ -0,0 +1,77 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+func TestGet(t *testing.T) {
+	syncTargets := []tmcv1alpha1.SyncTarget{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "us-1"},
+			Spec:       tmcv1alpha1.SyncTargetSpec{Location: "us"},
+			Status: tmcv1alpha1.SyncTargetStatus{Conditions: conditionsv1alpha1.Conditions{
+				{Type: conditionsv1alpha1.ReadyCondition, Status: corev1.ConditionTrue},
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "eu-1"},
+			Spec:       tmcv1alpha1.SyncTargetSpec{Unschedulable: true, Drain: &tmcv1alpha1.SyncTargetDrain{}},
+			Status: tmcv1alpha1.SyncTargetStatus{
+				Drain: &tmcv1alpha1.SyncTargetDrainStatus{Evicting: []tmcv1alpha1.DrainedWorkload{{Namespace: "default", Name: "web"}}, Remaining: 2, Moved: 1},
+				Conditions: conditionsv1alpha1.Conditions{
+					{Type: conditionsv1alpha1.ReadyCondition, Status: corev1.ConditionFalse, Reason: "HeartbeatMissed"},
+					{Type: tmcv1alpha1.Drained, Status: corev1.ConditionFalse, Reason: tmcv1alpha1.DrainingReason},
+				},
+			},
+		},
+	}
+	distributions := []workloadv1alpha1.WorkloadDistribution{
+		{Status: workloadv1alpha1.WorkloadDistributionStatus{Targets: []workloadv1alpha1.TargetPlacement{{SyncTarget: "eu-1"}, {SyncTarget: "us-1"}}}},
+		{Status: workloadv1alpha1.WorkloadDistributionStatus{Targets: []workloadv1alpha1.TargetPlacement{{SyncTarget: "eu-1"}}}},
+	}
+
+	out := &bytes.Buffer{}
+	o := NewGetOptions(base.IOStreams{Out: out, ErrOut: &bytes.Buffer{}})
+	o.listSyncTargets = func(context.Context) ([]tmcv1alpha1.SyncTarget, error) { return syncTargets, nil }
+	o.listDistributions = func(context.Context) ([]workloadv1alpha1.WorkloadDistribution, error) { return distributions, nil }
+
+	require.NoError(t, o.Run(context.Background()))
+	require.Equal(t, `NAME  LOCATION  READY                    SCHEDULING   WORKLOADS  DRAIN
+eu-1  -         False (HeartbeatMissed)  Cordoned     2          2 remaining, 1 moving, 1 moved
+us-1  us        True                     Schedulable  1          -
+`, out.String())
+
+	out.Reset()
+	o.Names = []string{"us-1", "ap-1"}
+	require.EqualError(t, o.Run(context.Background()), `sync target "ap-1" not found`)
+	require.Contains(t, out.String(), "us-1")
+	require.NotContains(t, out.String(), "eu-1")
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/pkg/cliplugins/workloadsyncer/plugin"
+)
+
+var (
+	generateExample = `
+# Print the manifests running the syncer of SyncTarget edge, and apply them on its physical cluster.
+%[1]s workload-syncer generate edge --syncer-image ghcr.io/kcp-dev/kcp/syncer:v0.1.0 --resource-config resources.yaml | kubectl --kubeconfig edge.kubeconfig apply -f -
+
+# Use an existing token and the address kcp is reachable at from the physical cluster.
+%[1]s workload-syncer generate edge --syncer-image ghcr.io/kcp-dev/kcp/syncer:v0.1.0 --token "$TOKEN" --server https://kcp.example.com/clusters/root:org
+
+# Enable feature gates of the syncer.
+%[1]s workload-syncer generate edge --syncer-image ghcr.io/kcp-dev/kcp/syncer:v0.1.0 --feature-gates TMCPlacement=true
+`
+)
+
+// New provides the workload-syncer command.
+func New(streams base.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "workload-syncer",
+		Short: "Manage the syncers of SyncTargets",
+	}
+	cmd.AddCommand(newGenerate(streams))
+	return cmd
+}
+
+// newGenerate provides a command for generating the manifests of a syncer.
+func newGenerate(streams base.IOStreams) *cobra.Command {
+	generateOptions := plugin.NewGenerateOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "generate <sync-target-name>",
+		Short:        "Generate the manifests running the syncer of a SyncTarget",
+		Long:         "Generate the namespace, service account, RBAC, kubeconfig secret, resource configuration and deployment of the syncer of a SyncTarget of the current workspace, to apply on its physical cluster. Unless a token is given, the service account of the syncer is created in the workspace, with access to the SyncTarget only.",
+		Example:      fmt.Sprintf(generateExample, "kubectl tmc"),
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := generateOptions.Complete(args); err != nil {
+				return err
+			}
+
+			if err := generateOptions.Validate(); err != nil {
+				return err
+			}
+
+			return generateOptions.Run(c.Context())
+		},
+	}
+
+	generateOptions.BindFlags(cmd)
+
+	return cmd
+}
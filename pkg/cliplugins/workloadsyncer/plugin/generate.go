@@ -0,0 +1,275 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	cliflag "k8s.io/component-base/cli/flag"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/onboarding"
+	"github.com/kcp-dev/kcp/pkg/syncer/controllermanager"
+	"github.com/kcp-dev/kcp/pkg/syncer/permissions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+var (
+	clusterPathRegexp = regexp.MustCompile(`/clusters/([^/]+)/?$`)
+
+	syncTargetsGVR = tmcv1alpha1.SchemeGroupVersion.WithResource("synctargets")
+	secretsGVR     = corev1.SchemeGroupVersion.WithResource("secrets")
+)
+
+// GenerateOptions contains options for generating the manifests of a
+// syncer.
+type GenerateOptions struct {
+	*base.Options
+
+	// SyncTargetName is the SyncTarget in the current workspace.
+	SyncTargetName string
+	// SyncerImage is the image of the syncer.
+	SyncerImage string
+	// Server is the URL the syncer reaches the workspace at. Defaults to the
+	// server of the kubeconfig.
+	Server string
+	// ResourceConfig is the syncer resource configuration file listing the
+	// synced resources.
+	ResourceConfig string
+	// FeatureGates are the feature gates of the syncer.
+	FeatureGates map[string]bool
+	// Features are the enabled syncer features.
+	Features permissions.Features
+	// Token authenticates the syncer in the workspace. If empty, the
+	// service account of the syncer is created in the workspace and its
+	// token is used.
+	Token string
+	// TokenTimeout is how long to wait for the token of the service account.
+	TokenTimeout time.Duration
+
+	// cluster is the logical cluster of the current workspace.
+	cluster logicalcluster.Name
+	// caData verifies the server.
+	caData []byte
+
+	getSyncTarget   func(ctx context.Context, name string) (*tmcv1alpha1.SyncTarget, error)
+	createIfMissing func(ctx context.Context, gvr schema.GroupVersionResource, obj runtime.Object) error
+	getSecret       func(ctx context.Context, namespace, name string) (*corev1.Secret, error)
+}
+
+// NewGenerateOptions returns a new GenerateOptions.
+func NewGenerateOptions(streams base.IOStreams) *GenerateOptions {
+	return &GenerateOptions{
+		Options:      base.NewOptions(streams),
+		Features:     permissions.Features{Capacity: true, LeaderElection: true},
+		TokenTimeout: 30 * time.Second,
+	}
+}
+
+// BindFlags binds fields GenerateOptions as command line flags to cmd's flagset.
+func (o *GenerateOptions) BindFlags(cmd *cobra.Command) {
+	o.Options.BindFlags(cmd)
+
+	cmd.Flags().StringVar(&o.SyncerImage, "syncer-image", o.SyncerImage, "Image of the syncer")
+	cmd.Flags().StringVar(&o.Server, "server", o.Server, "URL the syncer reaches the workspace at. Defaults to the server of the kubeconfig")
+	cmd.Flags().StringVar(&o.ResourceConfig, "resource-config", o.ResourceConfig, "Syncer resource configuration file listing the synced resources")
+	cmd.Flags().Var(cliflag.NewMapStringBool(&o.FeatureGates), "feature-gates", "Feature gates of the syncer, e.g. TMCPlacement=true")
+	cmd.Flags().BoolVar(&o.Features.Capacity, "capacity", o.Features.Capacity, "Whether the syncer reports the capacity of the physical cluster")
+	cmd.Flags().BoolVar(&o.Features.PriorityClasses, "priority-classes", o.Features.PriorityClasses, "Whether the syncer creates the PriorityClasses of synced pods")
+	cmd.Flags().BoolVar(&o.Features.LeaderElection, "leader-election", o.Features.LeaderElection, "Whether the syncer replicas elect a leader")
+	cmd.Flags().StringVar(&o.Token, "token", o.Token, "Token of the syncer in the workspace. If empty, the service account of the syncer is created in the workspace and its token is used")
+	cmd.Flags().DurationVar(&o.TokenTimeout, "token-timeout", o.TokenTimeout, "How long to wait for the token of the service account of the syncer")
+}
+
+// Complete ensures all dynamically populated fields are initialized.
+func (o *GenerateOptions) Complete(args []string) error {
+	if len(args) > 0 {
+		o.SyncTargetName = args[0]
+	}
+	if o.getSyncTarget != nil {
+		return nil
+	}
+
+	if err := o.Options.Complete(); err != nil {
+		return err
+	}
+	config, err := o.ClientConfig.ClientConfig()
+	if err != nil {
+		return err
+	}
+	u, err := url.Parse(config.Host)
+	if err != nil {
+		return err
+	}
+	if match := clusterPathRegexp.FindStringSubmatch(u.Path); match != nil {
+		o.cluster = logicalcluster.Name(match[1])
+	}
+	if o.Server == "" {
+		o.Server = config.Host
+	}
+	o.caData = config.CAData
+	if len(o.caData) == 0 && config.CAFile != "" {
+		if o.caData, err = os.ReadFile(config.CAFile); err != nil {
+			return err
+		}
+	}
+
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	o.getSyncTarget = func(ctx context.Context, name string) (*tmcv1alpha1.SyncTarget, error) {
+		u, err := client.Resource(syncTargetsGVR).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		target := &tmcv1alpha1.SyncTarget{}
+		return target, runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, target)
+	}
+	o.createIfMissing = func(ctx context.Context, gvr schema.GroupVersionResource, obj runtime.Object) error {
+		raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return err
+		}
+		u := &unstructured.Unstructured{Object: raw}
+		_, err = client.Resource(gvr).Namespace(u.GetNamespace()).Create(ctx, u, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			return nil
+		}
+		return err
+	}
+	o.getSecret = func(ctx context.Context, namespace, name string) (*corev1.Secret, error) {
+		u, err := client.Resource(secretsGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		secret := &corev1.Secret{}
+		return secret, runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, secret)
+	}
+	return nil
+}
+
+// Validate validates the GenerateOptions are complete and usable.
+func (o *GenerateOptions) Validate() error {
+	var errs []error
+
+	if err := o.Options.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if o.SyncTargetName == "" {
+		errs = append(errs, fmt.Errorf("a SyncTarget name is required"))
+	}
+	if o.SyncerImage == "" {
+		errs = append(errs, fmt.Errorf("--syncer-image is required"))
+	}
+	if o.TokenTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("--token-timeout must be positive"))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// Run prints the manifests to apply on the physical cluster of the
+// SyncTarget to run its syncer.
+func (o *GenerateOptions) Run(ctx context.Context) error {
+	var resourceConfig []byte
+	if o.ResourceConfig != "" {
+		data, err := os.ReadFile(o.ResourceConfig)
+		if err != nil {
+			return err
+		}
+		if _, err := controllermanager.ParseConfig(data); err != nil {
+			return fmt.Errorf("invalid resource configuration %s: %w", o.ResourceConfig, err)
+		}
+		resourceConfig = data
+	}
+
+	target, err := o.getSyncTarget(ctx, o.SyncTargetName)
+	if err != nil {
+		return err
+	}
+
+	token := o.Token
+	if token == "" {
+		if token, err = o.serviceAccountToken(ctx); err != nil {
+			return err
+		}
+	}
+
+	manifests, err := onboarding.Render(target, onboarding.BootstrapConfig{
+		Server:         o.Server,
+		Token:          token,
+		SyncerImage:    o.SyncerImage,
+		Cluster:        o.cluster,
+		CAData:         o.caData,
+		ResourceConfig: resourceConfig,
+		FeatureGates:   o.FeatureGates,
+		Features:       &o.Features,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = o.Out.Write(manifests)
+	return err
+}
+
+// serviceAccountToken creates the service account of the syncer in the
+// workspace, with the RBAC letting it sync the SyncTarget, and returns its
+// token.
+func (o *GenerateOptions) serviceAccountToken(ctx context.Context) (string, error) {
+	for _, obj := range onboarding.Credentials(o.SyncTargetName) {
+		if err := o.createIfMissing(ctx, obj.GVR, obj.Object); err != nil {
+			return "", fmt.Errorf("failed to create %s of the syncer: %w", obj.GVR.Resource, err)
+		}
+	}
+
+	name := onboarding.TokenSecretName(o.SyncTargetName)
+	var token []byte
+	err := wait.PollUntilContextTimeout(ctx, 500*time.Millisecond, o.TokenTimeout, true, func(ctx context.Context) (bool, error) {
+		secret, err := o.getSecret(ctx, onboarding.BootstrapNamespace, name)
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		token = secret.Data[corev1.ServiceAccountTokenKey]
+		return len(token) > 0, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get the token of secret %s/%s: %w", onboarding.BootstrapNamespace, name, err)
+	}
+	return string(token), nil
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func newTestOptions(t *testing.T) (*GenerateOptions, *bytes.Buffer, map[string]runtime.Object) {
+	t.Helper()
+	config := filepath.Join(t.TempDir(), "resources.yaml")
+	require.NoError(t, os.WriteFile(config, []byte(`
+resources:
+- group: apps
+  version: v1
+  resource: deployments
+`), 0o600))
+
+	created := map[string]runtime.Object{}
+	out := &bytes.Buffer{}
+	o := NewGenerateOptions(base.IOStreams{Out: out, ErrOut: &bytes.Buffer{}})
+	o.SyncerImage = "ghcr.io/kcp-dev/kcp/syncer:v0.1.0"
+	o.Server = "https://kcp.example.com/clusters/root:org"
+	o.cluster = "root:org"
+	o.ResourceConfig = config
+	o.TokenTimeout = time.Second
+	o.getSyncTarget = func(ctx context.Context, name string) (*tmcv1alpha1.SyncTarget, error) {
+		return &tmcv1alpha1.SyncTarget{ObjectMeta: metav1.ObjectMeta{Name: name}}, nil
+	}
+	o.createIfMissing = func(ctx context.Context, gvr schema.GroupVersionResource, obj runtime.Object) error {
+		created[gvr.Resource] = obj
+		return nil
+	}
+	o.getSecret = func(ctx context.Context, namespace, name string) (*corev1.Secret, error) {
+		if _, found := created["secrets"]; !found {
+			return nil, apierrors.NewNotFound(secretsGVR.GroupResource(), name)
+		}
+		return &corev1.Secret{Data: map[string][]byte{corev1.ServiceAccountTokenKey: []byte("s3cr3t")}}, nil
+	}
+	return o, out, created
+}
+
+// token returns the token in the kubeconfig of the syncer in manifests.
+func token(t *testing.T, manifests string) string {
+	t.Helper()
+	for _, doc := range strings.Split(manifests, "---\n") {
+		var secret corev1.Secret
+		require.NoError(t, yaml.Unmarshal([]byte(doc), &secret))
+		if secret.Kind != "Secret" {
+			continue
+		}
+		kubeconfig, err := clientcmd.Load(secret.Data["kubeconfig"])
+		require.NoError(t, err)
+		return kubeconfig.AuthInfos["syncer"].Token
+	}
+	t.Fatal("no kubeconfig secret")
+	return ""
+}
+
+func TestGenerate(t *testing.T) {
+	o, out, created := newTestOptions(t)
+	o.FeatureGates = map[string]bool{"TMCPlacement": true}
+	require.NoError(t, o.Complete([]string{"edge"}))
+	require.NoError(t, o.Validate())
+	require.NoError(t, o.Run(context.Background()))
+
+	require.Contains(t, created, "serviceaccounts")
+	require.Contains(t, created, "clusterrolebindings")
+	require.Contains(t, out.String(), "kind: Deployment\n")
+	require.Contains(t, out.String(), "kind: ConfigMap\n")
+	require.Contains(t, out.String(), "- deployments\n")
+	require.Contains(t, out.String(), "--sync-targets=root:org:edge")
+	require.Contains(t, out.String(), "--feature-gates=TMCPlacement=true")
+	require.Equal(t, "s3cr3t", token(t, out.String()))
+}
+
+func TestGenerateToken(t *testing.T) {
+	o, out, created := newTestOptions(t)
+	o.Token = "given"
+	require.NoError(t, o.Complete([]string{"edge"}))
+	require.NoError(t, o.Run(context.Background()))
+	require.Empty(t, created, "no service account is created for a given token")
+	require.Equal(t, "given", token(t, out.String()))
+}
+
+func TestGenerateValidate(t *testing.T) {
+	o := NewGenerateOptions(base.IOStreams{})
+	err := o.Validate()
+	require.ErrorContains(t, err, "SyncTarget name is required")
+	require.ErrorContains(t, err, "--syncer-image is required")
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryption
+
+import (
+	"context"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/kcp-dev/kcp/pkg/crypto"
+)
+
+// NewAESGCMKey returns a key sealing with AES-GCM. secret must be 16, 24 or
+// 32 bytes long. Nonces are random, so a key should not seal more than
+// 2^32 messages.
+func NewAESGCMKey(id string, secret []byte) (Key, error) {
+	aead, err := newAESGCM(secret)
+	if err != nil {
+		return nil, err
+	}
+	return &aeadKey{id: id, aead: aead}, nil
+}
+
+// NewChaCha20Poly1305Key returns a key sealing with XChaCha20-Poly1305,
+// whose nonces are long enough to be random. secret must be 32 bytes long.
+func NewChaCha20Poly1305Key(id string, secret []byte) (Key, error) {
+	if len(secret) != chacha20poly1305.KeySize {
+		return nil, fmt.Errorf("invalid key size %d, must be %d", len(secret), chacha20poly1305.KeySize)
+	}
+	aead, err := chacha20poly1305.NewX(secret)
+	if err != nil {
+		return nil, err
+	}
+	return &aeadKey{id: id, aead: aead}, nil
+}
+
+// aeadKey seals with an AEAD, prefixing the sealed data with the nonce.
+type aeadKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+func (k *aeadKey) ID() string {
+	return k.id
+}
+
+func (k *aeadKey) Seal(_ context.Context, plaintext []byte) ([]byte, error) {
+	return seal(k.aead, plaintext), nil
+}
+
+func (k *aeadKey) Open(_ context.Context, sealed []byte) ([]byte, error) {
+	return open(k.aead, sealed)
+}
+
+func seal(aead cipher.AEAD, plaintext []byte) []byte {
+	nonce := crypto.RandomBits(8 * aead.NonceSize())
+	return aead.Seal(nonce, nonce, plaintext, nil)
+}
+
+func open(aead cipher.AEAD, sealed []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("sealed data is too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryption
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Algorithm is the algorithm of a key in a Config.
+type Algorithm string
+
+const (
+	AlgorithmAESGCM           Algorithm = "aes-gcm"
+	AlgorithmChaCha20Poly1305 Algorithm = "chacha20-poly1305"
+)
+
+// Config is the configuration of a keyring, usually read from a file.
+// Keys held by an external KMS are added with NewKMSKey.
+type Config struct {
+	// Keys are the keys data is decrypted with. The first one is the primary
+	// key data is encrypted with.
+	Keys []KeyConfig `json:"keys"`
+	// AcceptPlaintext is whether data that is not encrypted is read as is,
+	// so that encryption can be enabled for existing data.
+	AcceptPlaintext bool `json:"acceptPlaintext,omitempty"`
+}
+
+// KeyConfig is a key of a Config.
+type KeyConfig struct {
+	// ID identifies the key in encrypted data.
+	ID        string    `json:"id"`
+	Algorithm Algorithm `json:"algorithm"`
+	// Secret is the base64 encoded secret of the key.
+	Secret string `json:"secret"`
+}
+
+// LoadKeyring returns the keyring configured by the YAML or JSON Config in
+// the file at path.
+func LoadKeyring(path string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config Config
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to decode encryption configuration %s: %w", path, err)
+	}
+	keyring, err := config.Keyring()
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption configuration %s: %w", path, err)
+	}
+	return keyring, nil
+}
+
+// Keyring returns the keyring of the configuration.
+func (c *Config) Keyring() (*Keyring, error) {
+	if len(c.Keys) == 0 {
+		return nil, fmt.Errorf("no keys")
+	}
+	keys := make([]Key, 0, len(c.Keys))
+	for _, kc := range c.Keys {
+		secret, err := base64.StdEncoding.DecodeString(kc.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret of key %q: %w", kc.ID, err)
+		}
+		var key Key
+		switch kc.Algorithm {
+		case AlgorithmAESGCM:
+			key, err = NewAESGCMKey(kc.ID, secret)
+		case AlgorithmChaCha20Poly1305:
+			key, err = NewChaCha20Poly1305Key(kc.ID, secret)
+		default:
+			return nil, fmt.Errorf("unknown algorithm %q of key %q", kc.Algorithm, kc.ID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", kc.ID, err)
+		}
+		keys = append(keys, key)
+	}
+	return NewKeyring(keys[0], keys[1:], c.AcceptPlaintext)
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package encryption encrypts data that TMC components store outside of
+// the API server, like the syncer mutation audit log and the placement
+// checkpoints.
+//
+// Data is encrypted with the primary key of a Keyring, and the ID of the
+// key is stored in front of the ciphertext, so that data encrypted with
+// older keys can still be decrypted. Keys are rotated in steps, as every
+// replica must be able to decrypt what any other one encrypts:
+//
+//  1. the new key is added as a secondary key to every replica;
+//  2. it is made the primary key of every replica;
+//  3. data encrypted with the old key is rewritten, or ages out;
+//  4. the old key is removed.
+//
+// While a rotation is in progress, data may be encrypted with a key a
+// replica does not know yet, and decrypting it fails with ErrUnknownKey
+// until the replica is updated.
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// magic prefixes encrypted data, followed by the key ID and a separator.
+const magic = "kcpenc:v1:"
+
+var (
+	// ErrUnknownKey is returned when decrypting data encrypted with a key
+	// that is not in the keyring, e.g. while a key rotation is in progress.
+	ErrUnknownKey = errors.New("data is encrypted with an unknown key, a key rotation may be in progress")
+	// ErrNotEncrypted is returned when decrypting data that is not
+	// encrypted by a keyring that does not accept plaintext.
+	ErrNotEncrypted = errors.New("data is not encrypted")
+)
+
+// Provider encrypts and decrypts stored data.
+type Provider interface {
+	// Encrypt returns the encrypted plaintext.
+	Encrypt(ctx context.Context, plaintext []byte) ([]byte, error)
+	// Decrypt returns the plaintext of data returned by Encrypt.
+	Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error)
+}
+
+// Key seals and opens data with a single key.
+type Key interface {
+	// ID identifies the key in encrypted data. It must not contain colons.
+	ID() string
+	Seal(ctx context.Context, plaintext []byte) ([]byte, error)
+	Open(ctx context.Context, sealed []byte) ([]byte, error)
+}
+
+// Keyring is a Provider encrypting with its primary key and decrypting with
+// any of its keys.
+type Keyring struct {
+	primary Key
+	keys    map[string]Key
+	// acceptPlaintext is whether data that is not encrypted is returned as
+	// is by Decrypt.
+	acceptPlaintext bool
+}
+
+var _ Provider = &Keyring{}
+
+// NewKeyring returns a keyring encrypting with primary and decrypting with
+// primary and secondary. If acceptPlaintext is true, data that is not
+// encrypted is decrypted as is, so that encryption can be enabled for data
+// stored before.
+func NewKeyring(primary Key, secondary []Key, acceptPlaintext bool) (*Keyring, error) {
+	if primary == nil {
+		return nil, errors.New("a primary key is required")
+	}
+	k := &Keyring{primary: primary, keys: map[string]Key{}, acceptPlaintext: acceptPlaintext}
+	for _, key := range append([]Key{primary}, secondary...) {
+		id := key.ID()
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid key ID %q: must be non-empty and must not contain colons", id)
+		}
+		if _, found := k.keys[id]; found {
+			return nil, fmt.Errorf("duplicate key ID %q", id)
+		}
+		k.keys[id] = key
+	}
+	return k, nil
+}
+
+// Encrypt encrypts plaintext with the primary key.
+func (k *Keyring) Encrypt(ctx context.Context, plaintext []byte) ([]byte, error) {
+	sealed, err := k.primary.Seal(ctx, plaintext)
+	if err != nil {
+		operations.WithLabelValues("encrypt", "failure").Inc()
+		return nil, fmt.Errorf("failed to encrypt with key %q: %w", k.primary.ID(), err)
+	}
+	operations.WithLabelValues("encrypt", "success").Inc()
+	out := make([]byte, 0, len(magic)+len(k.primary.ID())+1+len(sealed))
+	out = append(out, magic...)
+	out = append(out, k.primary.ID()...)
+	out = append(out, ':')
+	return append(out, sealed...), nil
+}
+
+// Decrypt decrypts data encrypted with any key of the keyring.
+func (k *Keyring) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	id, sealed, encrypted := split(ciphertext)
+	if !encrypted {
+		if !k.acceptPlaintext {
+			operations.WithLabelValues("decrypt", "failure").Inc()
+			return nil, ErrNotEncrypted
+		}
+		operations.WithLabelValues("decrypt", "stale").Inc()
+		return ciphertext, nil
+	}
+	key, found := k.keys[id]
+	if !found {
+		operations.WithLabelValues("decrypt", "failure").Inc()
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+	plaintext, err := key.Open(ctx, sealed)
+	if err != nil {
+		operations.WithLabelValues("decrypt", "failure").Inc()
+		return nil, fmt.Errorf("failed to decrypt with key %q: %w", id, err)
+	}
+	if id != k.primary.ID() {
+		operations.WithLabelValues("decrypt", "stale").Inc()
+	} else {
+		operations.WithLabelValues("decrypt", "success").Inc()
+	}
+	return plaintext, nil
+}
+
+// Stale returns whether data is not encrypted with the primary key, and
+// should be rewritten to complete a key rotation.
+func (k *Keyring) Stale(ciphertext []byte) bool {
+	id, _, encrypted := split(ciphertext)
+	return !encrypted || id != k.primary.ID()
+}
+
+// Primary returns the ID of the primary key.
+func (k *Keyring) Primary() string {
+	return k.primary.ID()
+}
+
+// Encrypted returns whether data was returned by Encrypt.
+func Encrypted(data []byte) bool {
+	_, _, encrypted := split(data)
+	return encrypted
+}
+
+func split(data []byte) (id string, sealed []byte, encrypted bool) {
+	if !bytes.HasPrefix(data, []byte(magic)) {
+		return "", nil, false
+	}
+	rest := data[len(magic):]
+	i := bytes.IndexByte(rest, ':')
+	if i <= 0 {
+		return "", nil, false
+	}
+	return string(rest[:i]), rest[i+1:], true
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKMS wraps data keys by XOR, counting calls.
+type fakeKMS struct {
+	wraps, unwraps int
+}
+
+func (k *fakeKMS) Wrap(_ context.Context, dataKey []byte) ([]byte, error) {
+	k.wraps++
+	return xor(dataKey), nil
+}
+
+func (k *fakeKMS) Unwrap(_ context.Context, wrapped []byte) ([]byte, error) {
+	k.unwraps++
+	return xor(wrapped), nil
+}
+
+func xor(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i := range b {
+		out[i] = b[i] ^ 0x5a
+	}
+	return out
+}
+
+func TestKeys(t *testing.T) {
+	ctx := context.Background()
+	aesKey, err := NewAESGCMKey("aes", bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	chachaKey, err := NewChaCha20Poly1305Key("chacha", bytes.Repeat([]byte{2}, 32))
+	require.NoError(t, err)
+	kms := &fakeKMS{}
+
+	for _, key := range []Key{aesKey, chachaKey, NewKMSKey("kms", kms)} {
+		t.Run(key.ID(), func(t *testing.T) {
+			keyring, err := NewKeyring(key, nil, false)
+			require.NoError(t, err)
+
+			plaintext := []byte(`{"secret":"value"}`)
+			first, err := keyring.Encrypt(ctx, plaintext)
+			require.NoError(t, err)
+			second, err := keyring.Encrypt(ctx, plaintext)
+			require.NoError(t, err)
+			require.NotContains(t, string(first), "value")
+			require.NotEqual(t, first, second, "nonces are random")
+			require.True(t, Encrypted(first))
+
+			decrypted, err := keyring.Decrypt(ctx, first)
+			require.NoError(t, err)
+			require.Equal(t, plaintext, decrypted)
+
+			first[len(first)-1] ^= 1
+			_, err = keyring.Decrypt(ctx, first)
+			require.Error(t, err, "tampered data is rejected")
+		})
+	}
+	require.Equal(t, 1, kms.wraps, "the data key is reused")
+	require.Equal(t, 0, kms.unwraps, "the data key is cached")
+
+	reopened, err := NewKeyring(NewKMSKey("kms", kms), nil, false)
+	require.NoError(t, err)
+	keyring, err := NewKeyring(NewKMSKey("kms", kms), nil, false)
+	require.NoError(t, err)
+	encrypted, err := keyring.Encrypt(ctx, []byte("data"))
+	require.NoError(t, err)
+	for range 2 {
+		decrypted, err := reopened.Decrypt(ctx, encrypted)
+		require.NoError(t, err)
+		require.Equal(t, "data", string(decrypted))
+	}
+	require.Equal(t, 1, kms.unwraps, "unwrapped data keys are cached")
+
+	_, err = NewAESGCMKey("short", []byte("short"))
+	require.Error(t, err)
+	_, err = NewChaCha20Poly1305Key("short", []byte("short"))
+	require.Error(t, err)
+}
+
+func TestRotation(t *testing.T) {
+	ctx := context.Background()
+	oldKey, err := NewAESGCMKey("old", bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	newKey, err := NewChaCha20Poly1305Key("new", bytes.Repeat([]byte{2}, 32))
+	require.NoError(t, err)
+
+	before, err := NewKeyring(oldKey, nil, false)
+	require.NoError(t, err)
+	encryptedBefore, err := before.Encrypt(ctx, []byte("before"))
+	require.NoError(t, err)
+
+	// The new key is made primary on one replica before the other knows it.
+	rotated, err := NewKeyring(newKey, []Key{oldKey}, false)
+	require.NoError(t, err)
+	encryptedAfter, err := rotated.Encrypt(ctx, []byte("after"))
+	require.NoError(t, err)
+	_, err = before.Decrypt(ctx, encryptedAfter)
+	require.True(t, errors.Is(err, ErrUnknownKey))
+
+	decrypted, err := rotated.Decrypt(ctx, encryptedBefore)
+	require.NoError(t, err)
+	require.Equal(t, "before", string(decrypted))
+	require.True(t, rotated.Stale(encryptedBefore), "data of the old key is rewritten")
+	require.False(t, rotated.Stale(encryptedAfter))
+	require.Equal(t, "new", rotated.Primary())
+
+	_, err = rotated.Decrypt(ctx, []byte("plaintext"))
+	require.True(t, errors.Is(err, ErrNotEncrypted))
+	migrating, err := NewKeyring(newKey, nil, true)
+	require.NoError(t, err)
+	decrypted, err = migrating.Decrypt(ctx, []byte("plaintext"))
+	require.NoError(t, err)
+	require.Equal(t, "plaintext", string(decrypted))
+	require.True(t, migrating.Stale([]byte("plaintext")))
+
+	_, err = NewKeyring(newKey, []Key{newKey}, false)
+	require.Error(t, err, "key IDs are unique")
+	invalid, err := NewAESGCMKey("in:valid", bytes.Repeat([]byte{1}, 16))
+	require.NoError(t, err)
+	_, err = NewKeyring(invalid, nil, false)
+	require.Error(t, err)
+}
+
+func TestLoadKeyring(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "encryption.yaml")
+	secret := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{3}, 32))
+	require.NoError(t, os.WriteFile(path, []byte(`
+keys:
+- id: "2025-02"
+  algorithm: chacha20-poly1305
+  secret: `+secret+`
+- id: "2025-01"
+  algorithm: aes-gcm
+  secret: `+secret+`
+`), 0o600))
+	keyring, err := LoadKeyring(path)
+	require.NoError(t, err)
+	require.Equal(t, "2025-02", keyring.Primary())
+
+	require.NoError(t, os.WriteFile(path, []byte("keys:\n- id: a\n  algorithm: rot13\n  secret: "+secret+"\n"), 0o600))
+	_, err = LoadKeyring(path)
+	require.Error(t, err)
+	require.NoError(t, os.WriteFile(path, []byte("keys: []\n"), 0o600))
+	_, err = LoadKeyring(path)
+	require.Error(t, err)
+}
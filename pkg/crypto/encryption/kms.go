@@ -0,0 +1,150 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/kcp-dev/kcp/pkg/crypto"
+)
+
+const (
+	// dataKeyMaxUses is the number of messages sealed with a data key
+	// before a new one is generated, well below the limit of random
+	// AES-GCM nonces.
+	dataKeyMaxUses = 1 << 20
+	// maxCachedDataKeys bounds the unwrapped data keys kept to open
+	// messages without calling the KMS.
+	maxCachedDataKeys = 1024
+)
+
+// KMS is an external key management service holding a key encryption key.
+type KMS interface {
+	// Wrap encrypts a data key.
+	Wrap(ctx context.Context, dataKey []byte) ([]byte, error)
+	// Unwrap decrypts a data key returned by Wrap.
+	Unwrap(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// NewKMSKey returns a key doing envelope encryption: messages are sealed
+// with AES-GCM data keys, which are stored wrapped by the KMS next to the
+// messages. Data keys are reused for many messages and cached unwrapped,
+// so that the KMS is rarely called.
+func NewKMSKey(id string, kms KMS) Key {
+	return &kmsKey{id: id, kms: kms, unwrapped: map[string]cipher.AEAD{}}
+}
+
+type kmsKey struct {
+	id  string
+	kms KMS
+
+	lock sync.Mutex
+	// current is the data key messages are sealed with, wrapped.
+	current     cipher.AEAD
+	wrapped     []byte
+	currentUses int
+	// unwrapped are AEADs by wrapped data key.
+	unwrapped map[string]cipher.AEAD
+}
+
+func (k *kmsKey) ID() string {
+	return k.id
+}
+
+// Seal returns the length of the wrapped data key as a 32-bit big-endian
+// integer, the wrapped data key, and the message sealed with it.
+func (k *kmsKey) Seal(ctx context.Context, plaintext []byte) ([]byte, error) {
+	aead, wrapped, err := k.dataKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := binary.BigEndian.AppendUint32(nil, uint32(len(wrapped)))
+	out = append(out, wrapped...)
+	return append(out, seal(aead, plaintext)...), nil
+}
+
+func (k *kmsKey) Open(ctx context.Context, sealed []byte) ([]byte, error) {
+	if len(sealed) < 4 {
+		return nil, errors.New("sealed data is too short")
+	}
+	n := binary.BigEndian.Uint32(sealed)
+	if uint64(len(sealed)-4) < uint64(n) {
+		return nil, errors.New("sealed data is too short")
+	}
+	wrapped, rest := sealed[4:4+n], sealed[4+n:]
+
+	k.lock.Lock()
+	aead, found := k.unwrapped[string(wrapped)]
+	k.lock.Unlock()
+	if !found {
+		dataKey, err := k.kms.Unwrap(ctx, wrapped)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+		}
+		if aead, err = newAESGCM(dataKey); err != nil {
+			return nil, err
+		}
+		k.lock.Lock()
+		k.cache(wrapped, aead)
+		k.lock.Unlock()
+	}
+	return open(aead, rest)
+}
+
+// dataKey returns the current data key, generating and wrapping a new one
+// when it has been used too often.
+func (k *kmsKey) dataKey(ctx context.Context) (cipher.AEAD, []byte, error) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if k.current == nil || k.currentUses >= dataKeyMaxUses {
+		dataKey := crypto.RandomBits(256)
+		wrapped, err := k.kms.Wrap(ctx, dataKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to wrap data key: %w", err)
+		}
+		aead, err := newAESGCM(dataKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		k.current, k.wrapped, k.currentUses = aead, wrapped, 0
+		k.cache(wrapped, aead)
+	}
+	k.currentUses++
+	return k.current, k.wrapped, nil
+}
+
+func (k *kmsKey) cache(wrapped []byte, aead cipher.AEAD) {
+	if len(k.unwrapped) >= maxCachedDataKeys {
+		k.unwrapped = map[string]cipher.AEAD{}
+	}
+	k.unwrapped[string(wrapped)] = aead
+}
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
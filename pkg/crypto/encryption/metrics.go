@@ -0,0 +1,46 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryption
+
+import (
+	"sync"
+
+	compbasemetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+var operations = compbasemetrics.NewCounterVec(
+	&compbasemetrics.CounterOpts{
+		Name:           "tmc_storage_encryption_operations_total",
+		Help:           "Number of encryptions and decryptions of stored data, by result. Decryptions of data not encrypted with the primary key are stale, and show that a key rotation is not complete.",
+		StabilityLevel: compbasemetrics.ALPHA,
+	},
+	[]string{"operation", "result"},
+)
+
+var registerMetrics sync.Once
+
+// Register metrics.
+func Register() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(operations)
+	})
+}
+
+func init() {
+	Register()
+}
@@ -0,0 +1,397 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dashboard serves a read-only JSON summary of the TMC fleet: the
+// SyncTargets and their health, the placements per workspace, recent
+// placement decisions and the most frequent errors. It backs dashboards
+// and terminal UIs that refresh often. The summary is computed from
+// informer caches and cached itself for a short interval, so refreshes do
+// not load the API servers.
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kcpinformers "github.com/kcp-dev/client-go/informers"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+const (
+	// Path is the path prefix the dashboard API is served at.
+	Path = "/tmc/dashboard/"
+
+	// SummaryPath serves the full Summary.
+	SummaryPath = Path + "summary"
+	// TargetsPath serves Summary.Targets.
+	TargetsPath = Path + "targets"
+	// WorkspacesPath serves Summary.Workspaces.
+	WorkspacesPath = Path + "workspaces"
+	// DecisionsPath serves Summary.RecentDecisions.
+	DecisionsPath = Path + "decisions"
+	// ErrorsPath serves Summary.TopErrors.
+	ErrorsPath = Path + "errors"
+
+	defaultRefreshInterval = 5 * time.Second
+	defaultMaxDecisions    = 50
+	defaultMaxErrors       = 20
+)
+
+// Summary is the state of the fleet.
+type Summary struct {
+	// GeneratedAt is when the summary was computed.
+	GeneratedAt metav1.Time `json:"generatedAt"`
+
+	Targets         []Target    `json:"targets"`
+	Workspaces      []Workspace `json:"workspaces"`
+	RecentDecisions []Decision  `json:"recentDecisions"`
+	TopErrors       []Error     `json:"topErrors"`
+}
+
+// Target is a SyncTarget and its health.
+type Target struct {
+	Workspace string `json:"workspace"`
+	Name      string `json:"name"`
+	Location  string `json:"location,omitempty"`
+	// Ready is whether the syncer of the target is ready.
+	Ready bool `json:"ready"`
+	// Schedulable is whether new workloads are placed on the target.
+	Schedulable bool `json:"schedulable"`
+	// LastHeartbeatTime is when the syncer last reported.
+	LastHeartbeatTime *metav1.Time `json:"lastHeartbeatTime,omitempty"`
+	// Workloads is the number of workloads placed on the target.
+	Workloads int `json:"workloads"`
+}
+
+// Workspace summarizes the placements of a workspace.
+type Workspace struct {
+	Workspace string `json:"workspace"`
+	// Workloads is the number of WorkloadDistributions.
+	Workloads int `json:"workloads"`
+	// Placed is the number of workloads placed on at least one target.
+	Placed int `json:"placed"`
+	// Failed is the number of workloads that could not be placed.
+	Failed int `json:"failed"`
+	// Frozen is the number of workloads whose placement is frozen.
+	Frozen int `json:"frozen"`
+	// Placements is the number of workload placements on targets.
+	Placements int `json:"placements"`
+}
+
+// Decision is the latest placement decision of a workload.
+type Decision struct {
+	Workspace      string      `json:"workspace"`
+	Namespace      string      `json:"namespace"`
+	Name           string      `json:"name"`
+	Policy         string      `json:"policy"`
+	PolicyRevision string      `json:"policyRevision,omitempty"`
+	Placed         bool        `json:"placed"`
+	Reason         string      `json:"reason,omitempty"`
+	Message        string      `json:"message,omitempty"`
+	Targets        []string    `json:"targets,omitempty"`
+	Time           metav1.Time `json:"time"`
+}
+
+// Error is a failing condition shared by objects of a kind.
+type Error struct {
+	Kind      string `json:"kind"`
+	Condition string `json:"condition"`
+	Reason    string `json:"reason,omitempty"`
+	Message   string `json:"message,omitempty"`
+	// Count is the number of objects with the error.
+	Count int `json:"count"`
+	// Example is one of the objects, as workspace:namespace/name.
+	Example string `json:"example"`
+}
+
+// Options configure a Server. Zero values mean the defaults.
+type Options struct {
+	// RefreshInterval is how long a summary is served before it is
+	// computed again.
+	RefreshInterval time.Duration
+	// MaxDecisions bounds the recent decisions.
+	MaxDecisions int
+	// MaxErrors bounds the top errors.
+	MaxErrors int
+}
+
+// Server serves the dashboard API.
+type Server struct {
+	options Options
+	now     func() time.Time
+
+	listSyncTargets   func() ([]*tmcv1alpha1.SyncTarget, error)
+	listDistributions func() ([]*workloadv1alpha1.WorkloadDistribution, error)
+
+	lock    sync.Mutex
+	summary *Summary
+}
+
+// NewServer returns a server summarizing the SyncTargets and
+// WorkloadDistributions of the informers across all workspaces.
+func NewServer(syncTargetClusterInformer, distributionClusterInformer kcpinformers.GenericClusterInformer, options Options) *Server {
+	return newServer(
+		func() ([]*tmcv1alpha1.SyncTarget, error) {
+			return list[tmcv1alpha1.SyncTarget](syncTargetClusterInformer)
+		},
+		func() ([]*workloadv1alpha1.WorkloadDistribution, error) {
+			return list[workloadv1alpha1.WorkloadDistribution](distributionClusterInformer)
+		},
+		options,
+	)
+}
+
+func newServer(listSyncTargets func() ([]*tmcv1alpha1.SyncTarget, error), listDistributions func() ([]*workloadv1alpha1.WorkloadDistribution, error), options Options) *Server {
+	if options.RefreshInterval <= 0 {
+		options.RefreshInterval = defaultRefreshInterval
+	}
+	if options.MaxDecisions <= 0 {
+		options.MaxDecisions = defaultMaxDecisions
+	}
+	if options.MaxErrors <= 0 {
+		options.MaxErrors = defaultMaxErrors
+	}
+	return &Server{
+		options:           options,
+		now:               time.Now,
+		listSyncTargets:   listSyncTargets,
+		listDistributions: listDistributions,
+	}
+}
+
+func list[T any](informer kcpinformers.GenericClusterInformer) ([]*T, error) {
+	objs, err := informer.Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*T, 0, len(objs))
+	for _, obj := range objs {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return nil, fmt.Errorf("expected *unstructured.Unstructured, got %T", obj)
+		}
+		t := new(T)
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, t); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// Summary returns the summary of the fleet, computing it if the cached one
+// is older than the refresh interval.
+func (s *Server) Summary() (*Summary, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := s.now()
+	if s.summary != nil && now.Sub(s.summary.GeneratedAt.Time) < s.options.RefreshInterval {
+		return s.summary, nil
+	}
+	syncTargets, err := s.listSyncTargets()
+	if err != nil {
+		return nil, err
+	}
+	distributions, err := s.listDistributions()
+	if err != nil {
+		return nil, err
+	}
+	s.summary = s.summarize(now, syncTargets, distributions)
+	return s.summary, nil
+}
+
+// Handler returns the handler of the API. It only serves GET requests;
+// authentication and authorization are up to the handler chain it is
+// served in.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(SummaryPath, s.serve(func(summary *Summary) interface{} { return summary }))
+	mux.HandleFunc(TargetsPath, s.serve(func(summary *Summary) interface{} { return summary.Targets }))
+	mux.HandleFunc(WorkspacesPath, s.serve(func(summary *Summary) interface{} { return summary.Workspaces }))
+	mux.HandleFunc(DecisionsPath, s.serve(func(summary *Summary) interface{} { return summary.RecentDecisions }))
+	mux.HandleFunc(ErrorsPath, s.serve(func(summary *Summary) interface{} { return summary.TopErrors }))
+	return mux
+}
+
+func (s *Server) serve(part func(summary *Summary) interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "the TMC dashboard API is read-only", http.StatusMethodNotAllowed)
+			return
+		}
+		summary, err := s.Summary()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(part(summary))
+	}
+}
+
+type targetKey struct {
+	workspace logicalcluster.Name
+	name      string
+}
+
+type errorKey struct {
+	kind, condition, reason, message string
+}
+
+func (s *Server) summarize(now time.Time, syncTargets []*tmcv1alpha1.SyncTarget, distributions []*workloadv1alpha1.WorkloadDistribution) *Summary {
+	summary := &Summary{
+		GeneratedAt:     metav1.NewTime(now),
+		Targets:         []Target{},
+		Workspaces:      []Workspace{},
+		RecentDecisions: []Decision{},
+		TopErrors:       []Error{},
+	}
+
+	errs := map[errorKey]*Error{}
+	addErrors := func(kind, example string, conds conditionsv1alpha1.Conditions) {
+		for _, c := range conds {
+			if c.Status != corev1.ConditionFalse || c.Severity == conditionsv1alpha1.ConditionSeverityInfo {
+				continue
+			}
+			k := errorKey{kind: kind, condition: string(c.Type), reason: c.Reason, message: c.Message}
+			e, found := errs[k]
+			if !found {
+				e = &Error{Kind: kind, Condition: string(c.Type), Reason: c.Reason, Message: c.Message, Example: example}
+				errs[k] = e
+			}
+			e.Count++
+		}
+	}
+
+	workloads := map[targetKey]int{}
+	workspaces := map[logicalcluster.Name]*Workspace{}
+	for _, d := range distributions {
+		clusterName := logicalcluster.From(d)
+		ws, found := workspaces[clusterName]
+		if !found {
+			ws = &Workspace{Workspace: clusterName.String()}
+			workspaces[clusterName] = ws
+		}
+		ws.Workloads++
+		ws.Placements += len(d.Status.Targets)
+		if len(d.Status.Targets) > 0 {
+			ws.Placed++
+		}
+		if conditions.IsFalse(d, workloadv1alpha1.WorkloadPlaced) && conditions.GetSeverity(d, workloadv1alpha1.WorkloadPlaced) != nil &&
+			*conditions.GetSeverity(d, workloadv1alpha1.WorkloadPlaced) == conditionsv1alpha1.ConditionSeverityError {
+			ws.Failed++
+		}
+		if conditions.IsTrue(d, workloadv1alpha1.PlacementFrozen) {
+			ws.Frozen++
+		}
+		targets := make([]string, 0, len(d.Status.Targets))
+		for _, t := range d.Status.Targets {
+			workloads[targetKey{workspace: clusterName, name: t.SyncTarget}]++
+			targets = append(targets, t.SyncTarget)
+		}
+
+		example := fmt.Sprintf("%s:%s/%s", clusterName, d.Namespace, d.Name)
+		addErrors("WorkloadDistribution", example, d.Status.Conditions)
+
+		if placed := conditions.Get(d, workloadv1alpha1.WorkloadPlaced); placed != nil {
+			summary.RecentDecisions = append(summary.RecentDecisions, Decision{
+				Workspace:      clusterName.String(),
+				Namespace:      d.Namespace,
+				Name:           d.Name,
+				Policy:         d.Spec.PolicyRef.Name,
+				PolicyRevision: d.Status.PolicyRevision,
+				Placed:         placed.Status == corev1.ConditionTrue,
+				Reason:         placed.Reason,
+				Message:        placed.Message,
+				Targets:        targets,
+				Time:           placed.LastTransitionTime,
+			})
+		}
+	}
+
+	for _, syncTarget := range syncTargets {
+		clusterName := logicalcluster.From(syncTarget)
+		summary.Targets = append(summary.Targets, Target{
+			Workspace:         clusterName.String(),
+			Name:              syncTarget.Name,
+			Location:          syncTarget.Spec.Location,
+			Ready:             conditions.IsTrue(syncTarget, tmcv1alpha1.SyncerReady),
+			Schedulable:       !syncTarget.Spec.Unschedulable && !conditions.IsTrue(syncTarget, tmcv1alpha1.SchedulingDisabled),
+			LastHeartbeatTime: syncTarget.Status.LastSyncerHeartbeatTime,
+			Workloads:         workloads[targetKey{workspace: clusterName, name: syncTarget.Name}],
+		})
+		addErrors("SyncTarget", fmt.Sprintf("%s:%s", clusterName, syncTarget.Name), syncTarget.Status.Conditions)
+	}
+	sort.Slice(summary.Targets, func(i, j int) bool {
+		a, b := summary.Targets[i], summary.Targets[j]
+		if a.Workspace != b.Workspace {
+			return a.Workspace < b.Workspace
+		}
+		return a.Name < b.Name
+	})
+
+	for _, ws := range workspaces {
+		summary.Workspaces = append(summary.Workspaces, *ws)
+	}
+	sort.Slice(summary.Workspaces, func(i, j int) bool { return summary.Workspaces[i].Workspace < summary.Workspaces[j].Workspace })
+
+	sort.SliceStable(summary.RecentDecisions, func(i, j int) bool {
+		return summary.RecentDecisions[j].Time.Before(&summary.RecentDecisions[i].Time)
+	})
+	if len(summary.RecentDecisions) > s.options.MaxDecisions {
+		summary.RecentDecisions = summary.RecentDecisions[:s.options.MaxDecisions]
+	}
+
+	for _, e := range errs {
+		summary.TopErrors = append(summary.TopErrors, *e)
+	}
+	sort.Slice(summary.TopErrors, func(i, j int) bool {
+		a, b := summary.TopErrors[i], summary.TopErrors[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Reason != b.Reason {
+			return a.Reason < b.Reason
+		}
+		return a.Message < b.Message
+	})
+	if len(summary.TopErrors) > s.options.MaxErrors {
+		summary.TopErrors = summary.TopErrors[:s.options.MaxErrors]
+	}
+	return summary
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var now = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func syncTarget(cluster, name string, ready bool) *tmcv1alpha1.SyncTarget {
+	status := corev1.ConditionTrue
+	severity := conditionsv1alpha1.ConditionSeverityNone
+	if !ready {
+		status = corev1.ConditionFalse
+		severity = conditionsv1alpha1.ConditionSeverityError
+	}
+	return &tmcv1alpha1.SyncTarget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{logicalcluster.AnnotationKey: cluster},
+		},
+		Spec: tmcv1alpha1.SyncTargetSpec{Location: "eu"},
+		Status: tmcv1alpha1.SyncTargetStatus{
+			Conditions: conditionsv1alpha1.Conditions{{
+				Type:     tmcv1alpha1.SyncerReady,
+				Status:   status,
+				Severity: severity,
+				Reason:   "HeartbeatMissed",
+			}},
+		},
+	}
+}
+
+func distribution(cluster, name string, placedAt time.Time, targets ...string) *workloadv1alpha1.WorkloadDistribution {
+	d := &workloadv1alpha1.WorkloadDistribution{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        name,
+			Annotations: map[string]string{logicalcluster.AnnotationKey: cluster},
+		},
+		Spec: workloadv1alpha1.WorkloadDistributionSpec{
+			PolicyRef: workloadv1alpha1.PolicyReference{Name: "policy"},
+		},
+	}
+	for _, t := range targets {
+		d.Status.Targets = append(d.Status.Targets, workloadv1alpha1.TargetPlacement{SyncTarget: t})
+	}
+	placed := conditionsv1alpha1.Condition{
+		Type:               workloadv1alpha1.WorkloadPlaced,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(placedAt),
+	}
+	if len(targets) == 0 {
+		placed.Status = corev1.ConditionFalse
+		placed.Severity = conditionsv1alpha1.ConditionSeverityError
+		placed.Reason = workloadv1alpha1.NoFeasibleTargetsReason
+	}
+	d.Status.Conditions = append(d.Status.Conditions, placed)
+	return d
+}
+
+func newTestServer(listCalls *int) *Server {
+	syncTargets := []*tmcv1alpha1.SyncTarget{
+		syncTarget("root:b", "west", true),
+		syncTarget("root:a", "east", true),
+		syncTarget("root:a", "north", false),
+	}
+	distributions := []*workloadv1alpha1.WorkloadDistribution{
+		distribution("root:a", "web", now.Add(-time.Hour), "east"),
+		distribution("root:a", "db", now.Add(-time.Minute), "east", "north"),
+		distribution("root:a", "cache", now.Add(-2*time.Minute)),
+		distribution("root:b", "api", now.Add(-3*time.Minute)),
+	}
+	s := newServer(
+		func() ([]*tmcv1alpha1.SyncTarget, error) {
+			*listCalls++
+			return syncTargets, nil
+		},
+		func() ([]*workloadv1alpha1.WorkloadDistribution, error) {
+			return distributions, nil
+		},
+		Options{MaxDecisions: 3},
+	)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestSummary(t *testing.T) {
+	var listCalls int
+	s := newTestServer(&listCalls)
+
+	summary, err := s.Summary()
+	require.NoError(t, err)
+
+	require.Equal(t, []Target{
+		{Workspace: "root:a", Name: "east", Location: "eu", Ready: true, Schedulable: true, Workloads: 2},
+		{Workspace: "root:a", Name: "north", Location: "eu", Ready: false, Schedulable: true, Workloads: 1},
+		{Workspace: "root:b", Name: "west", Location: "eu", Ready: true, Schedulable: true, Workloads: 0},
+	}, summary.Targets)
+
+	require.Equal(t, []Workspace{
+		{Workspace: "root:a", Workloads: 3, Placed: 2, Failed: 1, Placements: 3},
+		{Workspace: "root:b", Workloads: 1, Placed: 0, Failed: 1, Placements: 0},
+	}, summary.Workspaces)
+
+	require.Len(t, summary.RecentDecisions, 3, "decisions are bounded")
+	var names []string
+	for _, d := range summary.RecentDecisions {
+		names = append(names, d.Name)
+	}
+	require.Equal(t, []string{"db", "cache", "api"}, names, "newest decisions first")
+	require.Equal(t, []string{"east", "north"}, summary.RecentDecisions[0].Targets)
+
+	require.Equal(t, []Error{
+		{Kind: "WorkloadDistribution", Condition: "Placed", Reason: workloadv1alpha1.NoFeasibleTargetsReason, Count: 2, Example: "root:a:default/cache"},
+		{Kind: "SyncTarget", Condition: string(tmcv1alpha1.SyncerReady), Reason: "HeartbeatMissed", Count: 1, Example: "root:a:north"},
+	}, summary.TopErrors)
+
+	_, err = s.Summary()
+	require.NoError(t, err)
+	require.Equal(t, 1, listCalls, "summary is cached within the refresh interval")
+
+	s.now = func() time.Time { return now.Add(defaultRefreshInterval) }
+	_, err = s.Summary()
+	require.NoError(t, err)
+	require.Equal(t, 2, listCalls, "summary is recomputed after the refresh interval")
+}
+
+func TestHandler(t *testing.T) {
+	var listCalls int
+	handler := newTestServer(&listCalls).Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, TargetsPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var targets []Target
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &targets))
+	require.Len(t, targets, 3)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, SummaryPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var summary Summary
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &summary))
+	require.Len(t, summary.Workspaces, 2)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, SummaryPath, nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code, "the API is read-only")
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path+"unknown", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
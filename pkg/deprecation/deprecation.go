@@ -0,0 +1,96 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package deprecation tracks deprecated fields of the TMC APIs. Objects
+// setting them get warnings on admission and through virtual workspaces,
+// their usage is counted per workspace, and kubectl tmc deprecations reports
+// them fleet-wide.
+package deprecation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Field is a deprecated field of an API resource.
+type Field struct {
+	// Resource and Kind of the objects the field belongs to.
+	Resource schema.GroupVersionResource
+	Kind     string
+	// Path is the dot separated path of the field, e.g. spec.cells.
+	Path string
+	// Message tells what to use instead, e.g. "use spec.groups instead".
+	Message string
+}
+
+// Warning returns the warning for objects setting the field.
+func (f Field) Warning() string {
+	return fmt.Sprintf("%s %s %s is deprecated: %s", f.Resource.GroupVersion(), f.Kind, f.Path, f.Message)
+}
+
+// TMCFields are the deprecated fields of the TMC APIs. Deprecate a field by
+// adding it here and to the doc comment of the field in the API types; it is
+// removed from the API in a later version.
+var TMCFields = []Field{}
+
+// Default is the registry of TMCFields.
+var Default = NewRegistry(TMCFields...)
+
+// Registry looks up the deprecated fields set in objects.
+type Registry struct {
+	byResource map[schema.GroupVersionResource][]Field
+}
+
+// NewRegistry returns a registry of the given deprecated fields.
+func NewRegistry(fields ...Field) *Registry {
+	r := &Registry{byResource: map[schema.GroupVersionResource][]Field{}}
+	for _, f := range fields {
+		r.byResource[f.Resource] = append(r.byResource[f.Resource], f)
+	}
+	return r
+}
+
+// Resources returns the resources with deprecated fields, sorted.
+func (r *Registry) Resources() []schema.GroupVersionResource {
+	resources := make([]schema.GroupVersionResource, 0, len(r.byResource))
+	for gvr := range r.byResource {
+		resources = append(resources, gvr)
+	}
+	sort.Slice(resources, func(i, j int) bool { return resources[i].String() < resources[j].String() })
+	return resources
+}
+
+// Has returns whether the resource has deprecated fields.
+func (r *Registry) Has(gvr schema.GroupVersionResource) bool {
+	return len(r.byResource[gvr]) > 0
+}
+
+// Find returns the deprecated fields of the resource set in obj.
+func (r *Registry) Find(gvr schema.GroupVersionResource, obj map[string]interface{}) []Field {
+	var found []Field
+	for _, f := range r.byResource[gvr] {
+		value, exists, err := unstructured.NestedFieldNoCopy(obj, strings.Split(f.Path, ".")...)
+		if err != nil || !exists || value == nil {
+			continue
+		}
+		found = append(found, f)
+	}
+	return found
+}
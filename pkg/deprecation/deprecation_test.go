@@ -0,0 +1,54 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deprecation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/component-base/metrics/testutil"
+)
+
+func TestRegistry(t *testing.T) {
+	syncTargets := schema.GroupVersionResource{Group: "tmc.kcp.io", Version: "v1alpha1", Resource: "synctargets"}
+	policies := schema.GroupVersionResource{Group: "placement.kcp.io", Version: "v1alpha1", Resource: "placementpolicies"}
+	region := Field{Resource: syncTargets, Kind: "SyncTarget", Path: "spec.legacyRegion", Message: "use spec.location instead"}
+	zone := Field{Resource: syncTargets, Kind: "SyncTarget", Path: "spec.legacyZone", Message: "use labels instead"}
+	r := NewRegistry(region, zone, Field{Resource: policies, Kind: "PlacementPolicy", Path: "spec.legacy"})
+
+	require.Equal(t, []schema.GroupVersionResource{policies, syncTargets}, r.Resources())
+	require.True(t, r.Has(syncTargets))
+	require.False(t, r.Has(syncTargets.GroupResource().WithVersion("v1beta1")))
+
+	obj := map[string]interface{}{"spec": map[string]interface{}{"legacyRegion": "eu", "legacyZone": nil}}
+	require.Equal(t, []Field{region}, r.Find(syncTargets, obj), "null fields are not set")
+	require.Empty(t, r.Find(syncTargets, map[string]interface{}{"spec": "invalid"}))
+	require.Equal(t, "tmc.kcp.io/v1alpha1 SyncTarget spec.legacyRegion is deprecated: use spec.location instead", region.Warning())
+}
+
+func TestRecordUsage(t *testing.T) {
+	Register()
+	field := Field{Resource: schema.GroupVersionResource{Group: "tmc.kcp.io", Version: "v1alpha1", Resource: "synctargets"}, Path: "spec.legacyRegion"}
+	RecordUsage("root:org", field)
+	RecordUsage("root:org", field)
+
+	count, err := testutil.GetCounterMetricValue(usageCounter.WithLabelValues("root:org", "tmc.kcp.io", "synctargets", "spec.legacyRegion"))
+	require.NoError(t, err)
+	require.Equal(t, float64(2), count)
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deprecation
+
+import (
+	"sync"
+
+	compbasemetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+var (
+	usageCounter = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Name:           "tmc_deprecated_field_usage_total",
+			Help:           "Number of writes setting deprecated TMC API fields, by workspace, resource and field.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"workspace", "group", "resource", "field"},
+	)
+)
+
+var registerMetrics sync.Once
+
+// Register metrics.
+func Register() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(usageCounter)
+	})
+}
+
+// RecordUsage counts a write setting the deprecated field in the workspace.
+func RecordUsage(workspace string, f Field) {
+	usageCounter.WithLabelValues(workspace, f.Resource.Group, f.Resource.Resource, f.Path).Inc()
+}
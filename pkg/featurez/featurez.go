@@ -0,0 +1,121 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package featurez reports which TMC feature gates a process runs with. Both
+// kcp and the syncer serve them at /featurez, syncers also report them in the
+// status of their SyncTarget, and the TMC controllers compare them with their
+// own to find components that disagree.
+package featurez
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	kcpfeatures "github.com/kcp-dev/kcp/pkg/features"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// Path is the path the feature gates are served at.
+const Path = "/featurez"
+
+// ControllersPeer names the TMC controllers in mismatch messages.
+const ControllersPeer = "the TMC controllers"
+
+// Report is what is served at Path.
+type Report struct {
+	// Component is the process reporting, e.g. kcp or syncer.
+	Component string `json:"component"`
+	// FeatureGates are its feature gates, sorted by name.
+	FeatureGates []tmcv1alpha1.FeatureGate `json:"featureGates"`
+}
+
+// Process returns the TMC feature gates of this process. Gates are reported
+// as effectively enabled, i.e. a sub-feature is disabled while the TMCFeature
+// gate is.
+func Process() []tmcv1alpha1.FeatureGate {
+	return []tmcv1alpha1.FeatureGate{
+		{Name: string(kcpfeatures.TMCAPIs), Enabled: kcpfeatures.TMCAPIsEnabled()},
+		{Name: string(kcpfeatures.TMCControllers), Enabled: kcpfeatures.TMCControllersEnabled()},
+		{Name: string(kcpfeatures.TMCFeature), Enabled: kcpfeatures.TMCEnabled()},
+		{Name: string(kcpfeatures.TMCPlacement), Enabled: kcpfeatures.TMCPlacementEnabled()},
+	}
+}
+
+// Handler serves the feature gates returned by gates as a Report of
+// component.
+func Handler(component string, gates func() []tmcv1alpha1.FeatureGate) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		report := Report{Component: component, FeatureGates: Sorted(gates())}
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+	})
+}
+
+// Sorted returns a copy of gates sorted by name.
+func Sorted(gates []tmcv1alpha1.FeatureGate) []tmcv1alpha1.FeatureGate {
+	sorted := append([]tmcv1alpha1.FeatureGate{}, gates...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
+}
+
+// SetStatus reports the feature gates of the syncer in the status of its
+// SyncTarget.
+func SetStatus(syncTarget *tmcv1alpha1.SyncTarget, gates []tmcv1alpha1.FeatureGate) {
+	syncTarget.Status.SyncerFeatureGates = Sorted(gates)
+}
+
+// Mismatches returns the feature gates that the controllers and the syncer
+// of a SyncTarget both report, but with different values. Gates only one of
+// them knows are not mismatches, e.g. syncer-only features. The messages
+// name the component reporting the controller gates peer, e.g. ControllersPeer.
+func Mismatches(peer string, controller []tmcv1alpha1.FeatureGate, syncTargets []tmcv1alpha1.SyncTargetFeatureGates) []tmcv1alpha1.FeatureMismatch {
+	enabled := make(map[string]bool, len(controller))
+	for _, gate := range controller {
+		enabled[gate.Name] = gate.Enabled
+	}
+
+	var mismatches []tmcv1alpha1.FeatureMismatch
+	for _, syncTarget := range syncTargets {
+		for _, gate := range Sorted(syncTarget.FeatureGates) {
+			controllerEnabled, found := enabled[gate.Name]
+			if !found || controllerEnabled == gate.Enabled {
+				continue
+			}
+			mismatches = append(mismatches, tmcv1alpha1.FeatureMismatch{
+				FeatureGate: gate.Name,
+				SyncTarget:  syncTarget.Name,
+				Message:     fmt.Sprintf("%s is %s in the syncer, but %s in %s", gate.Name, state(gate.Enabled), state(controllerEnabled), peer),
+			})
+		}
+	}
+	sort.SliceStable(mismatches, func(i, j int) bool { return mismatches[i].SyncTarget < mismatches[j].SyncTarget })
+	return mismatches
+}
+
+func state(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+	return "disabled"
+}
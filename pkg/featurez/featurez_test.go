@@ -0,0 +1,83 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package featurez
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func TestHandler(t *testing.T) {
+	server := httptest.NewServer(Handler("kcp", func() []tmcv1alpha1.FeatureGate {
+		return []tmcv1alpha1.FeatureGate{{Name: "TMCPlacement", Enabled: true}, {Name: "TMCFeature", Enabled: true}}
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + Path)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	var report Report
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	require.Equal(t, Report{
+		Component:    "kcp",
+		FeatureGates: []tmcv1alpha1.FeatureGate{{Name: "TMCFeature", Enabled: true}, {Name: "TMCPlacement", Enabled: true}},
+	}, report)
+
+	resp, err = http.Post(server.URL+Path, "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestProcess(t *testing.T) {
+	gates := map[string]bool{}
+	for _, gate := range Process() {
+		gates[gate.Name] = gate.Enabled
+	}
+	require.Equal(t, map[string]bool{"TMCFeature": false, "TMCAPIs": false, "TMCControllers": false, "TMCPlacement": false}, gates)
+}
+
+func TestMismatches(t *testing.T) {
+	controller := []tmcv1alpha1.FeatureGate{
+		{Name: "TMCFeature", Enabled: true},
+		{Name: "TMCPlacement", Enabled: true},
+		{Name: "TMCAPIs", Enabled: false},
+	}
+	mismatches := Mismatches(ControllersPeer, controller, []tmcv1alpha1.SyncTargetFeatureGates{
+		{Name: "us-1", FeatureGates: []tmcv1alpha1.FeatureGate{
+			{Name: "TMCPlacement", Enabled: false},
+			{Name: "TMCAPIs", Enabled: true},
+			{Name: "Observer", Enabled: true},
+		}},
+		{Name: "eu-1", FeatureGates: []tmcv1alpha1.FeatureGate{
+			{Name: "TMCFeature", Enabled: true},
+			{Name: "TMCPlacement", Enabled: true},
+		}},
+	})
+	require.Equal(t, []tmcv1alpha1.FeatureMismatch{
+		{FeatureGate: "TMCAPIs", SyncTarget: "us-1", Message: "TMCAPIs is enabled in the syncer, but disabled in the TMC controllers"},
+		{FeatureGate: "TMCPlacement", SyncTarget: "us-1", Message: "TMCPlacement is disabled in the syncer, but enabled in the TMC controllers"},
+	}, mismatches)
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package capacity resolves the capacity and feasibility of SyncTargets that
+// delegate them to an external capacity provider webhook.
+package capacity
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// QueryFunc queries the capacity provider of a SyncTarget.
+type QueryFunc func(ctx context.Context, provider *tmcv1alpha1.CapacityProvider, req *Request) (*Response, error)
+
+// Resolver asks the capacity providers of SyncTargets about a workload.
+type Resolver struct {
+	query QueryFunc
+
+	ttl    time.Duration
+	now    func() time.Time
+	lock   sync.Mutex
+	cache  map[cacheKey]CacheEntry
+	pruned time.Time
+}
+
+type cacheKey struct {
+	Request
+	URL string
+}
+
+// CacheEntry is a cached answer of a capacity provider.
+type CacheEntry struct {
+	// URL is the URL of the provider.
+	URL      string   `json:"url"`
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+	// Time is when the provider answered.
+	Time metav1.Time `json:"time"`
+}
+
+// NewResolver returns a resolver querying providers with query.
+func NewResolver(query QueryFunc) *Resolver {
+	return &Resolver{query: query, now: time.Now}
+}
+
+// NewCachingResolver returns a resolver querying providers with query, and
+// reusing their answers for ttl. Failed queries are not cached.
+func NewCachingResolver(query QueryFunc, ttl time.Duration) *Resolver {
+	r := NewResolver(query)
+	r.ttl = ttl
+	r.cache = map[cacheKey]CacheEntry{}
+	return r
+}
+
+// Snapshot returns the cached answers that have not expired.
+func (r *Resolver) Snapshot() []CacheEntry {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.prune()
+	entries := make([]CacheEntry, 0, len(r.cache))
+	for _, entry := range r.cache {
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// prune drops expired answers. The lock must be held.
+func (r *Resolver) prune() {
+	now := r.now()
+	for key, entry := range r.cache {
+		if now.Sub(entry.Time.Time) >= r.ttl {
+			delete(r.cache, key)
+		}
+	}
+	r.pruned = now
+}
+
+// Restore adds answers of an earlier snapshot to the cache, skipping those
+// that have expired or are older than cached ones. It returns the number of
+// answers added.
+func (r *Resolver) Restore(entries []CacheEntry) int {
+	if r.cache == nil {
+		return 0
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := r.now()
+	var restored int
+	for _, entry := range entries {
+		if now.Sub(entry.Time.Time) >= r.ttl {
+			continue
+		}
+		key := cacheKey{Request: entry.Request, URL: entry.URL}
+		if cached, found := r.cache[key]; found && !cached.Time.Before(&entry.Time) {
+			continue
+		}
+		r.cache[key] = entry
+		restored++
+	}
+	return restored
+}
+
+func (r *Resolver) cached(provider *tmcv1alpha1.CapacityProvider, req *Request) (*Response, bool) {
+	if r.cache == nil {
+		return nil, false
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	entry, found := r.cache[cacheKey{Request: *req, URL: provider.URL}]
+	if !found || r.now().Sub(entry.Time.Time) >= r.ttl {
+		return nil, false
+	}
+	resp := entry.Response
+	resp.Capacity = resp.Capacity.DeepCopy()
+	resp.Allocatable = resp.Allocatable.DeepCopy()
+	return &resp, true
+}
+
+func (r *Resolver) queryCached(ctx context.Context, provider *tmcv1alpha1.CapacityProvider, req *Request) (*Response, error) {
+	if resp, found := r.cached(provider, req); found {
+		return resp, nil
+	}
+	resp, err := r.query(ctx, provider, req)
+	if err != nil || r.cache == nil {
+		return resp, err
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.now().Sub(r.pruned) >= r.ttl {
+		r.prune()
+	}
+	r.cache[cacheKey{Request: *req, URL: provider.URL}] = CacheEntry{
+		URL:      provider.URL,
+		Request:  *req,
+		Response: *resp,
+		Time:     metav1.NewTime(r.now()),
+	}
+	return resp, nil
+}
+
+// Resolve queries the capacity provider of every SyncTarget that has one
+// about the workload of req. It returns the targets with the capacity and
+// allocatable resources answered by the providers, and the names of
+// infeasible targets mapped to the reason. Targets without a provider are
+// returned unchanged.
+func (r *Resolver) Resolve(ctx context.Context, syncTargets []*tmcv1alpha1.SyncTarget, req Request) ([]*tmcv1alpha1.SyncTarget, map[string]string) {
+	logger := klog.FromContext(ctx)
+
+	resolved := make([]*tmcv1alpha1.SyncTarget, 0, len(syncTargets))
+	infeasible := map[string]string{}
+	for _, syncTarget := range syncTargets {
+		provider := syncTarget.Spec.CapacityProvider
+		if provider == nil {
+			resolved = append(resolved, syncTarget)
+			continue
+		}
+
+		req := req
+		req.SyncTarget = syncTarget.Name
+		resp, err := r.queryCached(ctx, provider, &req)
+		switch {
+		case err != nil && provider.FailurePolicy == tmcv1alpha1.CapacityProviderIgnore:
+			logger.V(2).Info("ignoring failed capacity provider", "syncTarget", syncTarget.Name, "err", err)
+			resolved = append(resolved, syncTarget)
+			continue
+		case err != nil:
+			infeasible[syncTarget.Name] = fmt.Sprintf("has a failing capacity provider: %v", err)
+		case !resp.Feasible:
+			reason := resp.Reason
+			if reason == "" {
+				reason = "no reason given"
+			}
+			infeasible[syncTarget.Name] = fmt.Sprintf("is infeasible according to its capacity provider: %s", reason)
+		}
+		if resp == nil || len(resp.Capacity) == 0 && len(resp.Allocatable) == 0 {
+			resolved = append(resolved, syncTarget)
+			continue
+		}
+
+		syncTarget = syncTarget.DeepCopy()
+		if len(resp.Capacity) > 0 {
+			syncTarget.Status.Capacity = &resp.Capacity
+		}
+		if len(resp.Allocatable) > 0 {
+			syncTarget.Status.Allocatable = &resp.Allocatable
+		}
+		resolved = append(resolved, syncTarget)
+	}
+	return resolved, infeasible
+}
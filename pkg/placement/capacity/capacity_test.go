@@ -0,0 +1,163 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacity
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/tmc/credentials"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func TestWebhookClientQuery(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cr3t" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var review Review
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		review.Response = &Response{Feasible: review.Request.SyncTarget == "gpu-pool", Reason: "no GPUs"}
+		_ = json.NewEncoder(w).Encode(&review)
+	}))
+	defer server.Close()
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	c := NewWebhookClient(credentials.NewResolver(func(_ context.Context, clusterName logicalcluster.Name, namespace, name string) (*corev1.Secret, error) {
+		require.Equal(t, logicalcluster.Name("root:org"), clusterName)
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: map[string]string{tmcv1alpha1.LabelCredentialsFor: name}},
+			Data:       map[string][]byte{"token": []byte("s3cr3t")},
+		}, nil
+	}))
+	provider := func(secret string) *tmcv1alpha1.CapacityProvider {
+		return &tmcv1alpha1.CapacityProvider{URL: server.URL, CABundle: caBundle, Credentials: &tmcv1alpha1.SyncTargetCredentials{
+			TokenSecretRef: &tmcv1alpha1.SecretKeyReference{SecretReference: tmcv1alpha1.SecretReference{Namespace: "default", Name: secret}, Key: "token"},
+		}}
+	}
+	resp, err := c.Query(context.Background(), provider("gpu-pool"), &Request{Workspace: "root:org", SyncTarget: "gpu-pool"})
+	require.NoError(t, err)
+	require.True(t, resp.Feasible)
+
+	resp, err = c.Query(context.Background(), provider("cpu-pool"), &Request{Workspace: "root:org", SyncTarget: "cpu-pool"})
+	require.NoError(t, err)
+	require.False(t, resp.Feasible)
+	require.Equal(t, "no GPUs", resp.Reason)
+
+	_, err = c.Query(context.Background(), provider("gpu-pool"), &Request{Workspace: "root:org", SyncTarget: "cpu-pool"})
+	require.EqualError(t, err, "failed to get token secret default/gpu-pool: not labeled tmc.kcp.io/credentials-for=cpu-pool", "secrets of other SyncTargets are not sent")
+
+	_, err = c.Query(context.Background(), &tmcv1alpha1.CapacityProvider{URL: server.URL, CABundle: caBundle}, &Request{})
+	require.EqualError(t, err, "unexpected status 401", "no token is sent without credentials")
+
+	_, err = c.Query(context.Background(), &tmcv1alpha1.CapacityProvider{URL: server.URL}, &Request{})
+	require.Error(t, err, "the certificate is not trusted without the CA bundle")
+}
+
+func TestResolve(t *testing.T) {
+	syncTargets := []*tmcv1alpha1.SyncTarget{
+		{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "vm-pool"}, Spec: tmcv1alpha1.SyncTargetSpec{CapacityProvider: &tmcv1alpha1.CapacityProvider{URL: "https://vm-pool"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "serverless"}, Spec: tmcv1alpha1.SyncTargetSpec{CapacityProvider: &tmcv1alpha1.CapacityProvider{URL: "https://serverless"}}},
+	}
+	r := NewResolver(func(_ context.Context, provider *tmcv1alpha1.CapacityProvider, req *Request) (*Response, error) {
+		if req.SyncTarget == "serverless" {
+			return &Response{}, nil
+		}
+		return &Response{
+			Feasible:    true,
+			Capacity:    corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("64")},
+			Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("16")},
+		}, nil
+	})
+
+	resolved, infeasible := r.Resolve(context.Background(), syncTargets, Request{Name: "app"})
+	require.Len(t, resolved, 3)
+	require.Same(t, syncTargets[0], resolved[0], "targets without a provider are unchanged")
+	require.Equal(t, "16", resolved[1].Status.Allocatable.Cpu().String())
+	require.Nil(t, syncTargets[1].Status.Allocatable, "the informer copy is not modified")
+	require.Equal(t, map[string]string{"serverless": "is infeasible according to its capacity provider: no reason given"}, infeasible)
+}
+
+func TestCachingResolver(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	syncTargets := []*tmcv1alpha1.SyncTarget{
+		{ObjectMeta: metav1.ObjectMeta{Name: "vm-pool"}, Spec: tmcv1alpha1.SyncTargetSpec{CapacityProvider: &tmcv1alpha1.CapacityProvider{URL: "https://vm-pool"}}},
+	}
+	var queries int
+	failing := false
+	query := func(_ context.Context, provider *tmcv1alpha1.CapacityProvider, req *Request) (*Response, error) {
+		queries++
+		if failing {
+			return nil, errors.New("unavailable")
+		}
+		return &Response{Feasible: true, Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("16")}}, nil
+	}
+	r := NewCachingResolver(query, time.Minute)
+	r.now = func() time.Time { return now }
+
+	_, infeasible := r.Resolve(context.Background(), syncTargets, Request{Name: "app"})
+	require.Empty(t, infeasible)
+	_, infeasible = r.Resolve(context.Background(), syncTargets, Request{Name: "app"})
+	require.Empty(t, infeasible)
+	require.Equal(t, 1, queries, "answers are cached")
+
+	_, _ = r.Resolve(context.Background(), syncTargets, Request{Name: "other"})
+	require.Equal(t, 2, queries, "answers are cached per workload")
+
+	snapshot := r.Snapshot()
+	require.Len(t, snapshot, 2)
+
+	// A new resolver warms up from the snapshot.
+	restored := NewCachingResolver(query, time.Minute)
+	restored.now = func() time.Time { return now.Add(30 * time.Second) }
+	require.Equal(t, 2, restored.Restore(snapshot))
+	resolved, _ := restored.Resolve(context.Background(), syncTargets, Request{Name: "app"})
+	require.Equal(t, 2, queries, "restored answers are used")
+	require.Equal(t, "16", resolved[0].Status.Allocatable.Cpu().String())
+
+	// Expired answers are neither restored nor used.
+	expired := NewCachingResolver(query, time.Minute)
+	expired.now = func() time.Time { return now.Add(time.Minute) }
+	require.Zero(t, expired.Restore(snapshot))
+
+	failing = true
+	r.now = func() time.Time { return now.Add(time.Minute) }
+	_, infeasible = r.Resolve(context.Background(), syncTargets, Request{Name: "app"})
+	require.Contains(t, infeasible["vm-pool"], "unavailable")
+	require.Empty(t, r.Snapshot(), "failures are not cached, expired answers are dropped")
+
+	require.Zero(t, NewResolver(query).Restore(snapshot), "resolvers without a cache restore nothing")
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacity
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/tmc/credentials"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+const maxResponseBytes = 1 << 20
+
+// Review is the body of a capacity provider webhook call. The request is
+// sent, and the webhook returns the review with the response set.
+type Review struct {
+	Request  *Request  `json:"request,omitempty"`
+	Response *Response `json:"response,omitempty"`
+}
+
+// Request asks whether a workload fits on a SyncTarget.
+type Request struct {
+	// Workspace, Namespace and Name of the WorkloadDistribution placed.
+	Workspace string `json:"workspace"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Workload is the distributed workload.
+	WorkloadAPIVersion string `json:"workloadAPIVersion"`
+	WorkloadKind       string `json:"workloadKind"`
+	WorkloadName       string `json:"workloadName"`
+	// SyncTarget is the name of the SyncTarget asked for.
+	SyncTarget string `json:"syncTarget"`
+}
+
+// Response is the answer of a capacity provider.
+type Response struct {
+	// Feasible is whether the workload may be placed on the target.
+	Feasible bool `json:"feasible"`
+	// Reason explains why the target is infeasible.
+	Reason string `json:"reason,omitempty"`
+	// Capacity and Allocatable replace those reported by the syncer for
+	// scoring, if set.
+	Capacity    corev1.ResourceList `json:"capacity,omitempty"`
+	Allocatable corev1.ResourceList `json:"allocatable,omitempty"`
+}
+
+// WebhookClient queries capacity provider webhooks. Clients are reused per
+// CA bundle and client certificate.
+type WebhookClient struct {
+	resolve credentials.ResolveFunc
+
+	lock    sync.Mutex
+	clients map[string]*http.Client
+}
+
+// NewWebhookClient returns a client of capacity provider webhooks, authenticating
+// with the credentials resolve returns.
+func NewWebhookClient(resolve credentials.ResolveFunc) *WebhookClient {
+	return &WebhookClient{resolve: resolve, clients: map[string]*http.Client{}}
+}
+
+// Query sends req to the webhook of provider and returns its response.
+func (c *WebhookClient) Query(ctx context.Context, provider *tmcv1alpha1.CapacityProvider, req *Request) (*Response, error) {
+	creds, err := c.resolve(ctx, logicalcluster.Name(req.Workspace), req.SyncTarget, provider.Credentials)
+	if err != nil {
+		return nil, err
+	}
+	client, err := c.client(provider.CABundle, creds)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(&Review{Request: req})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(ptr.Deref(provider.TimeoutSeconds, 5))*time.Second)
+	defer cancel()
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, provider.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if token := creds.BearerToken(); token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", httpResp.StatusCode)
+	}
+
+	var review Review
+	if err := json.NewDecoder(io.LimitReader(httpResp.Body, maxResponseBytes)).Decode(&review); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	if review.Response == nil {
+		return nil, errors.New("invalid response: no response set")
+	}
+	return review.Response, nil
+}
+
+func (c *WebhookClient) client(caBundle []byte, creds *credentials.Credentials) (*http.Client, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	key := string(caBundle) + "\x00" + creds.ClientKey()
+	if client, found := c.clients[key]; found {
+		return client, nil
+	}
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if len(caBundle) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caBundle) {
+			return nil, errors.New("invalid CA bundle")
+		}
+		config.RootCAs = pool
+	}
+	if creds != nil && creds.Certificate != nil {
+		config.Certificates = []tls.Certificate{*creds.Certificate}
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+	c.clients[key] = client
+	return client, nil
+}
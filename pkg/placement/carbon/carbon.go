@@ -0,0 +1,143 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package carbon resolves the carbon intensity of the electricity
+// SyncTargets run on, for green-aware placement. SyncTargets publish it
+// statically in the tmc.kcp.io/carbon-intensity annotation, or a Provider
+// looks it up per electricity grid region, e.g. from a grid data service.
+package carbon
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// Provider returns the current carbon intensity of an electricity grid
+// region, in gCO2eq/kWh.
+type Provider interface {
+	Intensity(ctx context.Context, region string) (float64, error)
+}
+
+// Intensity returns the carbon intensity published by the SyncTarget, and
+// whether it publishes a valid one.
+func Intensity(syncTarget *tmcv1alpha1.SyncTarget) (float64, bool) {
+	value, found := syncTarget.Annotations[tmcv1alpha1.AnnotationCarbonIntensity]
+	if !found {
+		return 0, false
+	}
+	intensity, err := strconv.ParseFloat(value, 64)
+	if err != nil || intensity < 0 {
+		return 0, false
+	}
+	return intensity, true
+}
+
+// Region returns the electricity grid region of the SyncTarget.
+func Region(syncTarget *tmcv1alpha1.SyncTarget) string {
+	if region := syncTarget.Annotations[tmcv1alpha1.AnnotationCarbonRegion]; region != "" {
+		return region
+	}
+	return syncTarget.Spec.Location
+}
+
+// Resolver fills in the carbon intensity of SyncTargets from a Provider.
+// Answers are reused per region for a TTL, as grid data changes slowly.
+type Resolver struct {
+	provider Provider
+	ttl      time.Duration
+	now      func() time.Time
+
+	lock  sync.Mutex
+	cache map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	intensity float64
+	time      time.Time
+}
+
+// NewResolver returns a resolver asking provider, and reusing its answers
+// for ttl. Failed queries are not cached.
+func NewResolver(provider Provider, ttl time.Duration) *Resolver {
+	return &Resolver{
+		provider: provider,
+		ttl:      ttl,
+		now:      time.Now,
+		cache:    map[string]cacheEntry{},
+	}
+}
+
+// Resolve returns the SyncTargets with the carbon intensity annotation set
+// from the provider. Targets publishing a valid carbon intensity, without a
+// region, or whose region the provider fails for, are returned unchanged,
+// so that a failing provider does not block placement.
+func (r *Resolver) Resolve(ctx context.Context, syncTargets []*tmcv1alpha1.SyncTarget) []*tmcv1alpha1.SyncTarget {
+	logger := klog.FromContext(ctx)
+
+	resolved := make([]*tmcv1alpha1.SyncTarget, 0, len(syncTargets))
+	failed := map[string]bool{}
+	for _, syncTarget := range syncTargets {
+		region := Region(syncTarget)
+		if _, published := Intensity(syncTarget); published || region == "" || failed[region] {
+			resolved = append(resolved, syncTarget)
+			continue
+		}
+		intensity, err := r.intensity(ctx, region)
+		if err != nil {
+			logger.V(2).Info("failed to get carbon intensity", "region", region, "err", err)
+			failed[region] = true
+			resolved = append(resolved, syncTarget)
+			continue
+		}
+
+		syncTarget = syncTarget.DeepCopy()
+		if syncTarget.Annotations == nil {
+			syncTarget.Annotations = map[string]string{}
+		}
+		syncTarget.Annotations[tmcv1alpha1.AnnotationCarbonIntensity] = strconv.FormatFloat(intensity, 'f', -1, 64)
+		resolved = append(resolved, syncTarget)
+	}
+	return resolved
+}
+
+func (r *Resolver) intensity(ctx context.Context, region string) (float64, error) {
+	r.lock.Lock()
+	entry, found := r.cache[region]
+	r.lock.Unlock()
+	if found && r.now().Sub(entry.time) < r.ttl {
+		return entry.intensity, nil
+	}
+
+	intensity, err := r.provider.Intensity(ctx, region)
+	if err != nil {
+		return 0, err
+	}
+	if intensity < 0 {
+		return 0, fmt.Errorf("negative carbon intensity %v", intensity)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.cache[region] = cacheEntry{intensity: intensity, time: r.now()}
+	return intensity, nil
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package carbon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+type providerFunc func(region string) (float64, error)
+
+func (f providerFunc) Intensity(_ context.Context, region string) (float64, error) {
+	return f(region)
+}
+
+func syncTarget(name, location string, annotations map[string]string) *tmcv1alpha1.SyncTarget {
+	return &tmcv1alpha1.SyncTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations},
+		Spec:       tmcv1alpha1.SyncTargetSpec{Location: location},
+	}
+}
+
+func TestIntensity(t *testing.T) {
+	for value, want := range map[string]float64{"120": 120, "0.5": 0.5, "-1": -1, "high": -1} {
+		intensity, found := Intensity(syncTarget("a", "eu", map[string]string{tmcv1alpha1.AnnotationCarbonIntensity: value}))
+		if want < 0 {
+			require.False(t, found, value)
+			continue
+		}
+		require.True(t, found, value)
+		require.Equal(t, want, intensity)
+	}
+	_, found := Intensity(syncTarget("a", "eu", nil))
+	require.False(t, found)
+}
+
+func TestResolve(t *testing.T) {
+	var queried []string
+	resolver := NewResolver(providerFunc(func(region string) (float64, error) {
+		queried = append(queried, region)
+		if region == "us" {
+			return 0, errors.New("service unavailable")
+		}
+		return 80, nil
+	}), time.Minute)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	resolver.now = func() time.Time { return now }
+
+	targets := []*tmcv1alpha1.SyncTarget{
+		syncTarget("eu-1", "eu", nil),
+		syncTarget("eu-2", "eu", map[string]string{tmcv1alpha1.AnnotationCarbonIntensity: "300"}),
+		syncTarget("eu-3", "somewhere", map[string]string{tmcv1alpha1.AnnotationCarbonRegion: "eu"}),
+		syncTarget("us-1", "us", nil),
+		syncTarget("us-2", "us", nil),
+		syncTarget("edge", "", nil),
+	}
+	resolved := resolver.Resolve(context.Background(), targets)
+	require.Equal(t, []string{"eu", "us"}, queried, "regions are queried once, failed regions are not retried")
+
+	intensities := map[string]string{}
+	for _, syncTarget := range resolved {
+		intensities[syncTarget.Name] = syncTarget.Annotations[tmcv1alpha1.AnnotationCarbonIntensity]
+	}
+	require.Equal(t, map[string]string{"eu-1": "80", "eu-2": "300", "eu-3": "80", "us-1": "", "us-2": "", "edge": ""}, intensities)
+	require.Empty(t, targets[0].Annotations, "SyncTargets are not modified")
+	require.Same(t, targets[3], resolved[3])
+
+	resolver.Resolve(context.Background(), targets[:1])
+	require.Equal(t, []string{"eu", "us"}, queried, "answers are reused")
+
+	now = now.Add(time.Minute)
+	resolver.Resolve(context.Background(), targets[:1])
+	require.Equal(t, []string{"eu", "us", "eu"}, queried, "answers expire")
+}
+
+func TestHTTPProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch region := r.URL.Query().Get("region"); region {
+		case "eu":
+			fmt.Fprintf(w, `{"region": %q, "carbonIntensity": 42.5}`, region)
+		case "us":
+			fmt.Fprint(w, `{"region": "us"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewHTTPProvider(server.URL + "/intensity?token=abc")
+	require.NoError(t, err)
+
+	intensity, err := provider.Intensity(context.Background(), "eu")
+	require.NoError(t, err)
+	require.Equal(t, 42.5, intensity)
+
+	_, err = provider.Intensity(context.Background(), "us")
+	require.EqualError(t, err, "invalid response: no carbon intensity")
+
+	_, err = provider.Intensity(context.Background(), "mars")
+	require.EqualError(t, err, "unexpected status 404")
+
+	_, err = NewHTTPProvider("file:///etc/intensity.json")
+	require.Error(t, err)
+}
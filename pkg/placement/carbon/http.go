@@ -0,0 +1,97 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package carbon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	maxResponseBytes = 1 << 20
+
+	httpTimeout = 5 * time.Second
+)
+
+// Reading is the answer of an HTTP carbon intensity provider.
+type Reading struct {
+	// Region the reading is for.
+	Region string `json:"region"`
+	// CarbonIntensity of the region in gCO2eq/kWh.
+	CarbonIntensity *float64 `json:"carbonIntensity"`
+}
+
+// HTTPProvider asks an HTTP service for the carbon intensity of a region,
+// with a GET request to its URL with the region in the region query
+// parameter. The service answers with a Reading. It is typically a thin
+// adapter in front of a grid data service.
+type HTTPProvider struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPProvider returns a provider asking the service at rawURL.
+func NewHTTPProvider(rawURL string) (*HTTPProvider, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return nil, fmt.Errorf("carbon intensity provider URL %q must use http or https", rawURL)
+	}
+	return &HTTPProvider{url: rawURL, client: &http.Client{Timeout: httpTimeout}}, nil
+}
+
+// Intensity implements Provider.
+func (p *HTTPProvider) Intensity(ctx context.Context, region string) (float64, error) {
+	u, err := url.Parse(p.url)
+	if err != nil {
+		return 0, err
+	}
+	query := u.Query()
+	query.Set("region", region)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var reading Reading
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&reading); err != nil {
+		return 0, fmt.Errorf("invalid response: %w", err)
+	}
+	if reading.CarbonIntensity == nil {
+		return 0, errors.New("invalid response: no carbon intensity")
+	}
+	return *reading.CarbonIntensity, nil
+}
@@ -0,0 +1,287 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package checkpoint hands the in-memory state of the placement controller
+// over between replicas. The leader periodically saves a snapshot of its
+// state, and a replica taking over restores it before placing, so that it
+// does not start cold. Snapshots that are too old or of another version are
+// discarded, and the state is rebuilt on demand instead. Snapshots can be
+// encrypted.
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/pkg/crypto/encryption"
+	"github.com/kcp-dev/kcp/pkg/placement/capacity"
+)
+
+const (
+	// Version is the version of the State format. Snapshots of other
+	// versions are not restored.
+	Version = 1
+
+	// dataKey is the ConfigMap key holding the state, in Data if it is
+	// not encrypted and in BinaryData otherwise.
+	dataKey = "state.json"
+	// maxSize keeps the state below the size limit of ConfigMaps.
+	maxSize = 900 * 1024
+
+	defaultInterval = 30 * time.Second
+	defaultMaxAge   = 2 * time.Minute
+)
+
+// State is a snapshot of the in-memory state of the placement controller.
+type State struct {
+	Version int `json:"version"`
+	// Leader is the identity of the replica that took the snapshot.
+	Leader string `json:"leader"`
+	// TakenAt is when the snapshot was taken.
+	TakenAt metav1.Time `json:"takenAt"`
+
+	// Capacity holds the cached answers of capacity providers.
+	Capacity []capacity.CacheEntry `json:"capacity,omitempty"`
+}
+
+// Store persists snapshots.
+type Store interface {
+	// Load returns the saved snapshot, or nil if there is none.
+	Load(ctx context.Context) (*State, error)
+	// Save replaces the saved snapshot.
+	Save(ctx context.Context, state *State) error
+}
+
+// NewConfigMapStore returns a store saving snapshots in the ConfigMap with
+// the given namespace and name, encrypted by provider if it is not nil.
+func NewConfigMapStore(client kubernetes.Interface, namespace, name string, provider encryption.Provider) Store {
+	return &configMapStore{client: client, namespace: namespace, name: name, provider: provider}
+}
+
+type configMapStore struct {
+	client          kubernetes.Interface
+	namespace, name string
+	provider        encryption.Provider
+}
+
+func (s *configMapStore) Load(ctx context.Context) (*State, error) {
+	cm, err := s.client.CoreV1().ConfigMaps(s.namespace).Get(ctx, s.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	if encrypted, found := cm.BinaryData[dataKey]; found {
+		if s.provider == nil {
+			return nil, fmt.Errorf("checkpoint %s/%s is encrypted but no encryption is configured", s.namespace, s.name)
+		}
+		if data, err = s.provider.Decrypt(ctx, encrypted); err != nil {
+			return nil, fmt.Errorf("failed to decrypt checkpoint %s/%s: %w", s.namespace, s.name, err)
+		}
+	} else if plain, found := cm.Data[dataKey]; found {
+		data = []byte(plain)
+	} else {
+		return nil, nil
+	}
+	state := &State{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to decode checkpoint %s/%s: %w", s.namespace, s.name, err)
+	}
+	return state, nil
+}
+
+func (s *configMapStore) Save(ctx context.Context, state *State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if s.provider != nil {
+		if data, err = s.provider.Encrypt(ctx, data); err != nil {
+			return err
+		}
+	}
+	cm, err := s.client.CoreV1().ConfigMaps(s.namespace).Get(ctx, s.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: s.namespace, Name: s.name}}
+		s.setData(cm, data)
+		_, err = s.client.CoreV1().ConfigMaps(s.namespace).Create(ctx, cm, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	cm = cm.DeepCopy()
+	s.setData(cm, data)
+	_, err = s.client.CoreV1().ConfigMaps(s.namespace).Update(ctx, cm, metav1.UpdateOptions{})
+	return err
+}
+
+// setData replaces the state in cm, removing the state of the other
+// encoding when encryption is enabled or disabled.
+func (s *configMapStore) setData(cm *corev1.ConfigMap, data []byte) {
+	if s.provider != nil {
+		delete(cm.Data, dataKey)
+		if cm.BinaryData == nil {
+			cm.BinaryData = map[string][]byte{}
+		}
+		cm.BinaryData[dataKey] = data
+		return
+	}
+	delete(cm.BinaryData, dataKey)
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[dataKey] = string(data)
+}
+
+// Result is the outcome of restoring a snapshot.
+type Result string
+
+const (
+	// Restored means the snapshot was restored.
+	Restored Result = "Restored"
+	// Missing means there was no snapshot.
+	Missing Result = "Missing"
+	// Stale means the snapshot was older than the maximum age.
+	Stale Result = "Stale"
+	// Incompatible means the snapshot was of another version.
+	Incompatible Result = "Incompatible"
+	// Failed means the snapshot could not be loaded.
+	Failed Result = "Failed"
+)
+
+// Options configure a Checkpointer. Zero values mean the defaults.
+type Options struct {
+	// Interval is how often snapshots are saved.
+	Interval time.Duration
+	// MaxAge is the age beyond which snapshots are not restored.
+	MaxAge time.Duration
+}
+
+// Checkpointer saves and restores snapshots of a replica's state.
+type Checkpointer struct {
+	store    Store
+	identity string
+	options  Options
+	now      func() time.Time
+
+	snapshot func(state *State)
+	restore  func(state *State)
+}
+
+// New returns a checkpointer saving snapshots filled in by snapshot to
+// store, and handing restored snapshots to restore. identity names the
+// replica in the snapshots.
+func New(store Store, identity string, options Options, snapshot, restore func(state *State)) *Checkpointer {
+	Register()
+	if options.Interval <= 0 {
+		options.Interval = defaultInterval
+	}
+	if options.MaxAge <= 0 {
+		options.MaxAge = defaultMaxAge
+	}
+	return &Checkpointer{
+		store:    store,
+		identity: identity,
+		options:  options,
+		now:      time.Now,
+		snapshot: snapshot,
+		restore:  restore,
+	}
+}
+
+// Restore restores the saved snapshot, if it is recent enough and of the
+// current version. Otherwise the state is left to be rebuilt on demand.
+func (c *Checkpointer) Restore(ctx context.Context) Result {
+	result, state := c.load(ctx)
+	restores.WithLabelValues(string(result)).Inc()
+
+	logger := klog.FromContext(ctx).WithValues("result", result)
+	if state != nil {
+		logger = logger.WithValues("leader", state.Leader, "takenAt", state.TakenAt.Time)
+	}
+	if result != Restored {
+		logger.Info("not restoring placement state, rebuilding it on demand")
+		return result
+	}
+	c.restore(state)
+	logger.Info("restored placement state")
+	return result
+}
+
+func (c *Checkpointer) load(ctx context.Context) (Result, *State) {
+	state, err := c.store.Load(ctx)
+	switch {
+	case err != nil:
+		klog.FromContext(ctx).Error(err, "failed to load placement state checkpoint")
+		return Failed, nil
+	case state == nil:
+		return Missing, nil
+	case state.Version != Version:
+		return Incompatible, state
+	case c.now().Sub(state.TakenAt.Time) > c.options.MaxAge:
+		return Stale, state
+	}
+	return Restored, state
+}
+
+// Run saves snapshots until ctx is done.
+func (c *Checkpointer) Run(ctx context.Context) {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := c.Save(ctx); err != nil && ctx.Err() == nil {
+			klog.FromContext(ctx).Error(err, "failed to save placement state checkpoint")
+		}
+	}, c.options.Interval)
+}
+
+// Save saves a snapshot. The oldest capacity answers are left out of
+// snapshots that would be too large to store.
+func (c *Checkpointer) Save(ctx context.Context) error {
+	state := &State{Version: Version, Leader: c.identity, TakenAt: metav1.NewTime(c.now())}
+	c.snapshot(state)
+	sort.Slice(state.Capacity, func(i, j int) bool {
+		return state.Capacity[j].Time.Before(&state.Capacity[i].Time)
+	})
+	for {
+		data, err := json.Marshal(state)
+		if err != nil {
+			return err
+		}
+		if len(data) <= maxSize || len(state.Capacity) == 0 {
+			break
+		}
+		state.Capacity = state.Capacity[:len(state.Capacity)/2]
+	}
+
+	if err := c.store.Save(ctx, state); err != nil {
+		saves.WithLabelValues("failure").Inc()
+		return err
+	}
+	saves.WithLabelValues("success").Inc()
+	return nil
+}
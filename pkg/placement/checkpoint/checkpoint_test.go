@@ -0,0 +1,137 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/kcp-dev/kcp/pkg/crypto/encryption"
+	"github.com/kcp-dev/kcp/pkg/placement/capacity"
+)
+
+type failingStore struct{}
+
+func (failingStore) Load(context.Context) (*State, error) { return nil, errors.New("unavailable") }
+func (failingStore) Save(context.Context, *State) error   { return errors.New("unavailable") }
+
+func TestSaveAndRestore(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := []capacity.CacheEntry{
+		{URL: "https://vm-pool", Request: capacity.Request{Name: "app", SyncTarget: "vm-pool"}, Response: capacity.Response{Feasible: true}, Time: metav1.NewTime(now)},
+	}
+	client := fake.NewSimpleClientset()
+	store := NewConfigMapStore(client, metav1.NamespaceSystem, "placement-state", nil)
+
+	leader := New(store, "replica-1", Options{}, func(state *State) { state.Capacity = entries }, nil)
+	leader.now = func() time.Time { return now }
+
+	var restored *State
+	standby := New(store, "replica-2", Options{}, nil, func(state *State) { restored = state })
+	standby.now = func() time.Time { return now.Add(time.Minute) }
+
+	require.Equal(t, Missing, standby.Restore(context.Background()))
+	require.Nil(t, restored)
+
+	require.NoError(t, leader.Save(context.Background()))
+	require.NoError(t, leader.Save(context.Background()), "saving again updates the ConfigMap")
+	cm, err := client.CoreV1().ConfigMaps(metav1.NamespaceSystem).Get(context.Background(), "placement-state", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Contains(t, cm.Data, dataKey)
+
+	require.Equal(t, Restored, standby.Restore(context.Background()))
+	require.NotNil(t, restored)
+	require.Equal(t, "replica-1", restored.Leader)
+	require.Equal(t, entries[0].Request, restored.Capacity[0].Request)
+
+	standby.now = func() time.Time { return now.Add(defaultMaxAge + time.Second) }
+	restored = nil
+	require.Equal(t, Stale, standby.Restore(context.Background()))
+	require.Nil(t, restored, "stale snapshots are rebuilt instead")
+
+	cm.Data[dataKey] = `{"version":0}`
+	_, err = client.CoreV1().ConfigMaps(metav1.NamespaceSystem).Update(context.Background(), cm, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.Equal(t, Incompatible, standby.Restore(context.Background()))
+
+	cm.Data[dataKey] = `not json`
+	_, err = client.CoreV1().ConfigMaps(metav1.NamespaceSystem).Update(context.Background(), cm, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.Equal(t, Failed, standby.Restore(context.Background()))
+
+	require.Equal(t, Failed, New(failingStore{}, "replica-2", Options{}, nil, nil).Restore(context.Background()))
+}
+
+func TestSaveTrimsLargeSnapshots(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var entries []capacity.CacheEntry
+	for i := range 20000 {
+		entries = append(entries, capacity.CacheEntry{
+			URL:      "https://capacity-provider.example.com/some/long/path",
+			Request:  capacity.Request{Workspace: "root:org:team", Namespace: "default", Name: "app", SyncTarget: "vm-pool"},
+			Response: capacity.Response{Feasible: true},
+			Time:     metav1.NewTime(now.Add(time.Duration(i) * time.Second)),
+		})
+	}
+	client := fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: metav1.NamespaceSystem}})
+	store := NewConfigMapStore(client, metav1.NamespaceSystem, "placement-state", nil)
+	c := New(store, "replica-1", Options{}, func(state *State) { state.Capacity = entries }, nil)
+	c.now = func() time.Time { return now }
+	require.NoError(t, c.Save(context.Background()))
+
+	state, err := store.Load(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, state.Capacity)
+	require.Less(t, len(state.Capacity), len(entries))
+	require.True(t, now.Add(19999*time.Second).Equal(state.Capacity[0].Time.Time), "the newest answers are kept")
+}
+
+func TestEncryptedStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	client := fake.NewSimpleClientset()
+	plain := NewConfigMapStore(client, metav1.NamespaceSystem, "placement-state", nil)
+	require.NoError(t, plain.Save(ctx, &State{Version: Version, Leader: "replica-1", TakenAt: metav1.NewTime(now)}))
+
+	key, err := encryption.NewAESGCMKey("1", []byte("0123456789abcdef"))
+	require.NoError(t, err)
+	strict, err := encryption.NewKeyring(key, nil, false)
+	require.NoError(t, err)
+	_, err = NewConfigMapStore(client, metav1.NamespaceSystem, "placement-state", strict).Load(ctx)
+	require.NoError(t, err, "snapshots saved before encryption was enabled are read")
+
+	store := NewConfigMapStore(client, metav1.NamespaceSystem, "placement-state", strict)
+	require.NoError(t, store.Save(ctx, &State{Version: Version, Leader: "replica-2", TakenAt: metav1.NewTime(now)}))
+	cm, err := client.CoreV1().ConfigMaps(metav1.NamespaceSystem).Get(ctx, "placement-state", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotContains(t, cm.Data, dataKey)
+	require.NotContains(t, string(cm.BinaryData[dataKey]), "replica-2")
+
+	state, err := store.Load(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "replica-2", state.Leader)
+	_, err = plain.Load(ctx)
+	require.Error(t, err, "encrypted snapshots are not read without keys")
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checkpoint
+
+import (
+	"sync"
+
+	compbasemetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+var (
+	restores = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Name:           "tmc_placement_checkpoint_restores_total",
+			Help:           "Number of attempts to restore the placement state from a checkpoint, by result.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"result"},
+	)
+	saves = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Name:           "tmc_placement_checkpoint_saves_total",
+			Help:           "Number of placement state checkpoints saved, by result.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"result"},
+	)
+)
+
+var registerMetrics sync.Once
+
+// Register metrics.
+func Register() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(restores)
+		legacyregistry.MustRegister(saves)
+	})
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package closure resolves the objects exported with a workload. Reference
+// extractors per kind find the objects a workload references, e.g. the
+// ConfigMaps mounted by a Deployment, and the objects those reference in
+// turn, building the dependency graph of the export.
+package closure
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// DefaultMaxObjects bounds the size of a closure.
+const DefaultMaxObjects = 256
+
+// GetFunc returns the referenced object, or a NotFound error.
+type GetFunc func(ctx context.Context, ref Reference) (*unstructured.Unstructured, error)
+
+// Closure is the set of objects exported with a workload.
+type Closure struct {
+	// Objects are ordered so that referenced objects come before the objects
+	// referencing them, ending with the workload.
+	Objects []*unstructured.Unstructured
+	// Edges is the dependency graph, from each object to the objects it
+	// references.
+	Edges map[Reference][]Reference
+	// Missing are referenced objects that do not exist, e.g. optional
+	// Secrets.
+	Missing []Reference
+	// Cycles are the reference cycles found. Objects on a cycle are
+	// exported once, in an arbitrary order among themselves.
+	Cycles [][]Reference
+}
+
+// Resolver resolves closures.
+type Resolver struct {
+	Extractors *Extractors
+	Get        GetFunc
+	// MaxObjects bounds the number of objects in a closure. Defaults to
+	// DefaultMaxObjects.
+	MaxObjects int
+}
+
+// Resolve returns the closure of root in the given mode. In mode None, or
+// without a mode, the closure is the root only.
+func (r *Resolver) Resolve(ctx context.Context, root *unstructured.Unstructured, mode workloadv1alpha1.ClosureMode) (*Closure, error) {
+	c := &Closure{Edges: map[Reference][]Reference{}}
+	if mode != workloadv1alpha1.ClosureModeTransitive {
+		c.Objects = []*unstructured.Unstructured{root}
+		return c, nil
+	}
+
+	maxObjects := r.MaxObjects
+	if maxObjects <= 0 {
+		maxObjects = DefaultMaxObjects
+	}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := map[Reference]int{}
+	var path []Reference
+
+	var visit func(obj *unstructured.Unstructured) error
+	visit = func(obj *unstructured.Unstructured) error {
+		ref := ReferenceTo(obj)
+		state[ref] = visiting
+		path = append(path, ref)
+
+		refs := r.Extractors.References(obj)
+		c.Edges[ref] = refs
+		for _, dep := range refs {
+			switch state[dep] {
+			case visited:
+				continue
+			case visiting:
+				for i := len(path) - 1; i >= 0; i-- {
+					if path[i] == dep {
+						c.Cycles = append(c.Cycles, append([]Reference(nil), path[i:]...))
+						break
+					}
+				}
+				continue
+			}
+
+			depObj, err := r.Get(ctx, dep)
+			if apierrors.IsNotFound(err) {
+				state[dep] = visited
+				c.Missing = append(c.Missing, dep)
+				continue
+			} else if err != nil {
+				return fmt.Errorf("failed to get %s referenced by %s: %w", dep, ref, err)
+			}
+			if err := visit(depObj); err != nil {
+				return err
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[ref] = visited
+		c.Objects = append(c.Objects, obj)
+		if len(c.Objects) > maxObjects {
+			return fmt.Errorf("closure of %s exceeds %d objects", ReferenceTo(root), maxObjects)
+		}
+		return nil
+	}
+	if err := visit(root); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
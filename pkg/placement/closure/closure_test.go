@@ -0,0 +1,192 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package closure
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+func object(apiVersion, kind, name string, fields map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: fields}
+	if obj.Object == nil {
+		obj.Object = map[string]interface{}{}
+	}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace("default")
+	obj.SetName(name)
+	return obj
+}
+
+func deployment() *unstructured.Unstructured {
+	return object("apps/v1", "Deployment", "web", map[string]interface{}{
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+			"serviceAccountName": "web",
+			"containers": []interface{}{map[string]interface{}{
+				"envFrom": []interface{}{map[string]interface{}{"configMapRef": map[string]interface{}{"name": "settings"}}},
+				"env": []interface{}{map[string]interface{}{
+					"valueFrom": map[string]interface{}{"secretKeyRef": map[string]interface{}{"name": "optional"}},
+				}},
+			}},
+			"volumes": []interface{}{
+				map[string]interface{}{"configMap": map[string]interface{}{"name": "settings"}},
+				map[string]interface{}{"secret": map[string]interface{}{"secretName": "tls"}},
+			},
+		}}},
+	})
+}
+
+type store map[Reference]*unstructured.Unstructured
+
+func (s store) get(_ context.Context, ref Reference) (*unstructured.Unstructured, error) {
+	if obj, found := s[ref]; found {
+		return obj, nil
+	}
+	return nil, apierrors.NewNotFound(schema.GroupResource{Resource: ref.Kind}, ref.Name)
+}
+
+func (s store) add(objs ...*unstructured.Unstructured) store {
+	for _, obj := range objs {
+		s[ReferenceTo(obj)] = obj
+	}
+	return s
+}
+
+func names(objs []*unstructured.Unstructured) []string {
+	var ret []string
+	for _, obj := range objs {
+		ret = append(ret, obj.GetKind()+"/"+obj.GetName())
+	}
+	return ret
+}
+
+func TestResolve(t *testing.T) {
+	objects := store{}.add(
+		object("v1", "ConfigMap", "settings", nil),
+		object("v1", "Secret", "tls", nil),
+		object("v1", "Secret", "token", nil),
+		object("v1", "ServiceAccount", "web", map[string]interface{}{
+			"secrets": []interface{}{map[string]interface{}{"name": "token"}},
+		}),
+	)
+	r := &Resolver{Extractors: NewExtractors(), Get: objects.get}
+
+	c, err := r.Resolve(context.Background(), deployment(), workloadv1alpha1.ClosureModeNone)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Deployment/web"}, names(c.Objects), "closure is opt-in")
+
+	c, err = r.Resolve(context.Background(), deployment(), workloadv1alpha1.ClosureModeTransitive)
+	require.NoError(t, err)
+	require.Equal(t, []string{"ConfigMap/settings", "Secret/tls", "Secret/token", "ServiceAccount/web", "Deployment/web"}, names(c.Objects))
+	require.Equal(t, []Reference{{GroupKind: schema.GroupKind{Kind: "Secret"}, Namespace: "default", Name: "optional"}}, c.Missing)
+	require.Len(t, c.Edges[ReferenceTo(deployment())], 4, "duplicate references are merged")
+	require.Empty(t, c.Cycles)
+
+	r.MaxObjects = 3
+	_, err = r.Resolve(context.Background(), deployment(), workloadv1alpha1.ClosureModeTransitive)
+	require.ErrorContains(t, err, "exceeds 3 objects")
+}
+
+func TestResolveCycles(t *testing.T) {
+	widgets := schema.GroupKind{Group: "example.io", Kind: "Widget"}
+	extractors := NewExtractors()
+	require.NoError(t, extractors.AddFields([]workloadv1alpha1.ReferenceField{
+		{Group: "example.io", Kind: "Widget", Path: "spec.peers[].name", TargetGroup: "example.io", TargetKind: "Widget"},
+	}))
+	peers := func(names ...string) map[string]interface{} {
+		var list []interface{}
+		for _, name := range names {
+			list = append(list, map[string]interface{}{"name": name})
+		}
+		return map[string]interface{}{"spec": map[string]interface{}{"peers": list}}
+	}
+	objects := store{}.add(
+		object("example.io/v1", "Widget", "a", peers("b")),
+		object("example.io/v1", "Widget", "b", peers("c", "a")),
+		object("example.io/v1", "Widget", "c", peers("b")),
+	)
+	r := &Resolver{Extractors: extractors, Get: objects.get}
+
+	c, err := r.Resolve(context.Background(), objects[Reference{GroupKind: widgets, Namespace: "default", Name: "a"}], workloadv1alpha1.ClosureModeTransitive)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Widget/c", "Widget/b", "Widget/a"}, names(c.Objects), "every object is exported once")
+	ref := func(name string) Reference { return Reference{GroupKind: widgets, Namespace: "default", Name: name} }
+	require.Equal(t, [][]Reference{{ref("a"), ref("b")}, {ref("b"), ref("c")}}, c.Cycles)
+}
+
+func TestFieldExtractor(t *testing.T) {
+	for _, path := range []string{"", "spec..name", "spec.items[]", "[].name"} {
+		_, err := FieldExtractor(path, schema.GroupKind{Kind: "Secret"})
+		require.Error(t, err, path)
+	}
+	_, err := FieldExtractor("spec.name", schema.GroupKind{})
+	require.Error(t, err)
+
+	extractor, err := FieldExtractor("spec.items[].ref", schema.GroupKind{Kind: "Secret"})
+	require.NoError(t, err)
+	refs := extractor(object("v1", "Thing", "x", map[string]interface{}{"spec": map[string]interface{}{
+		"items": []interface{}{map[string]interface{}{"ref": "a"}, map[string]interface{}{"ref": 1}, "junk", map[string]interface{}{"ref": "b"}},
+	}}))
+	require.Equal(t, []Reference{
+		{GroupKind: schema.GroupKind{Kind: "Secret"}, Namespace: "default", Name: "a"},
+		{GroupKind: schema.GroupKind{Kind: "Secret"}, Namespace: "default", Name: "b"},
+	}, refs)
+}
+
+func TestPlaced(t *testing.T) {
+	s := store{}.add(deployment(), object("v1", "ConfigMap", "settings", nil), object("v1", "ServiceAccount", "web", nil))
+	distribution := func(name string, mode workloadv1alpha1.ClosureMode, rollout *workloadv1alpha1.RolloutStatus, targets ...string) *workloadv1alpha1.WorkloadDistribution {
+		d := &workloadv1alpha1.WorkloadDistribution{}
+		d.Namespace, d.Name = "default", name
+		d.Spec.WorkloadRef = workloadv1alpha1.WorkloadReference{APIVersion: "apps/v1", Kind: "Deployment", Name: name}
+		d.Spec.Closure = &workloadv1alpha1.ExportClosure{Mode: mode}
+		for _, target := range targets {
+			d.Status.Targets = append(d.Status.Targets, workloadv1alpha1.TargetPlacement{SyncTarget: target})
+		}
+		if rollout != nil {
+			d.Spec.RolloutStrategy = &workloadv1alpha1.RolloutStrategy{}
+			d.Status.Rollout = rollout
+		}
+		return d
+	}
+	deployments := schema.GroupKind{Group: "apps", Kind: "Deployment"}
+
+	placed, err := Placed(context.Background(), []*workloadv1alpha1.WorkloadDistribution{
+		distribution("web", workloadv1alpha1.ClosureModeTransitive, nil, "east"),
+		distribution("api", workloadv1alpha1.ClosureModeNone, nil, "east", "west"),
+		distribution("batch", workloadv1alpha1.ClosureModeNone, nil, "west"),
+		distribution("canary", workloadv1alpha1.ClosureModeNone, &workloadv1alpha1.RolloutStatus{SyncedTargets: []string{"west"}}, "east", "west"),
+	}, "east", s.get)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []Reference{
+		{GroupKind: deployments, Namespace: "default", Name: "web"},
+		{GroupKind: schema.GroupKind{Kind: "ConfigMap"}, Namespace: "default", Name: "settings"},
+		{GroupKind: schema.GroupKind{Kind: "ServiceAccount"}, Namespace: "default", Name: "web"},
+		{GroupKind: schema.GroupKind{Kind: "Secret"}, Namespace: "default", Name: "optional"},
+		{GroupKind: schema.GroupKind{Kind: "Secret"}, Namespace: "default", Name: "tls"},
+		{GroupKind: deployments, Namespace: "default", Name: "api"},
+	}, placed.UnsortedList(), "the canary is not rolled out to east yet")
+}
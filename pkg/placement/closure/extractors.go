@@ -0,0 +1,206 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package closure
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// Reference identifies a namespaced object.
+type Reference struct {
+	schema.GroupKind
+	Namespace string
+	Name      string
+}
+
+func (r Reference) String() string {
+	return fmt.Sprintf("%s %s/%s", r.GroupKind, r.Namespace, r.Name)
+}
+
+// ReferenceTo returns the reference to obj.
+func ReferenceTo(obj *unstructured.Unstructured) Reference {
+	return Reference{
+		GroupKind: obj.GroupVersionKind().GroupKind(),
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+	}
+}
+
+// Extractor returns the objects referenced by obj.
+type Extractor func(obj *unstructured.Unstructured) []Reference
+
+// Extractors holds the reference extractors per kind.
+type Extractors struct {
+	byKind map[schema.GroupKind][]Extractor
+}
+
+var (
+	configMaps      = schema.GroupKind{Kind: "ConfigMap"}
+	secrets         = schema.GroupKind{Kind: "Secret"}
+	serviceAccounts = schema.GroupKind{Kind: "ServiceAccount"}
+	claims          = schema.GroupKind{Kind: "PersistentVolumeClaim"}
+)
+
+// podSpecReferences are the reference fields of a pod spec.
+var podSpecReferences = []struct {
+	path   string
+	target schema.GroupKind
+}{
+	{"serviceAccountName", serviceAccounts},
+	{"imagePullSecrets[].name", secrets},
+	{"volumes[].configMap.name", configMaps},
+	{"volumes[].secret.secretName", secrets},
+	{"volumes[].persistentVolumeClaim.claimName", claims},
+	{"volumes[].projected.sources[].configMap.name", configMaps},
+	{"volumes[].projected.sources[].secret.name", secrets},
+	{"containers[].env[].valueFrom.configMapKeyRef.name", configMaps},
+	{"containers[].env[].valueFrom.secretKeyRef.name", secrets},
+	{"containers[].envFrom[].configMapRef.name", configMaps},
+	{"containers[].envFrom[].secretRef.name", secrets},
+	{"initContainers[].env[].valueFrom.configMapKeyRef.name", configMaps},
+	{"initContainers[].env[].valueFrom.secretKeyRef.name", secrets},
+	{"initContainers[].envFrom[].configMapRef.name", configMaps},
+	{"initContainers[].envFrom[].secretRef.name", secrets},
+}
+
+// podSpecPaths are the paths of the pod spec in kinds running pods.
+var podSpecPaths = map[schema.GroupKind]string{
+	{Kind: "Pod"}:                        "spec",
+	{Group: "apps", Kind: "Deployment"}:  "spec.template.spec",
+	{Group: "apps", Kind: "ReplicaSet"}:  "spec.template.spec",
+	{Group: "apps", Kind: "StatefulSet"}: "spec.template.spec",
+	{Group: "apps", Kind: "DaemonSet"}:   "spec.template.spec",
+	{Group: "batch", Kind: "Job"}:        "spec.template.spec",
+	{Group: "batch", Kind: "CronJob"}:    "spec.jobTemplate.spec.template.spec",
+	{Kind: "ReplicationController"}:      "spec.template.spec",
+}
+
+// NewExtractors returns extractors for the references of pods, the
+// built-in workload controllers and ServiceAccounts.
+func NewExtractors() *Extractors {
+	e := &Extractors{byKind: map[schema.GroupKind][]Extractor{}}
+	for gk, prefix := range podSpecPaths {
+		for _, ref := range podSpecReferences {
+			e.Register(gk, mustFieldExtractor(prefix+"."+ref.path, ref.target))
+		}
+	}
+	e.Register(serviceAccounts, mustFieldExtractor("secrets[].name", secrets))
+	e.Register(serviceAccounts, mustFieldExtractor("imagePullSecrets[].name", secrets))
+	return e
+}
+
+// Register adds an extractor for objects of the given kind.
+func (e *Extractors) Register(gk schema.GroupKind, extractor Extractor) {
+	e.byKind[gk] = append(e.byKind[gk], extractor)
+}
+
+// AddFields registers extractors for the given reference fields.
+func (e *Extractors) AddFields(fields []workloadv1alpha1.ReferenceField) error {
+	for _, field := range fields {
+		extractor, err := FieldExtractor(field.Path, schema.GroupKind{Group: field.TargetGroup, Kind: field.TargetKind})
+		if err != nil {
+			return fmt.Errorf("invalid reference field of %s: %w", schema.GroupKind{Group: field.Group, Kind: field.Kind}, err)
+		}
+		e.Register(schema.GroupKind{Group: field.Group, Kind: field.Kind}, extractor)
+	}
+	return nil
+}
+
+// References returns the objects referenced by obj, sorted and without
+// duplicates.
+func (e *Extractors) References(obj *unstructured.Unstructured) []Reference {
+	seen := map[Reference]bool{}
+	var refs []Reference
+	for _, extractor := range e.byKind[obj.GroupVersionKind().GroupKind()] {
+		for _, ref := range extractor(obj) {
+			if !seen[ref] {
+				seen[ref] = true
+				refs = append(refs, ref)
+			}
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].String() < refs[j].String() })
+	return refs
+}
+
+// FieldExtractor returns an extractor for the names found at path, which
+// are references to objects of the target kind in the namespace of the
+// referencing object. Path is a dot-separated list of fields, where fields
+// suffixed with [] iterate over a list.
+func FieldExtractor(path string, target schema.GroupKind) (Extractor, error) {
+	if target.Kind == "" {
+		return nil, fmt.Errorf("target kind is required")
+	}
+	fields := strings.Split(path, ".")
+	for _, f := range fields {
+		if strings.TrimSuffix(f, "[]") == "" {
+			return nil, fmt.Errorf("invalid path %q", path)
+		}
+	}
+	if strings.HasSuffix(fields[len(fields)-1], "[]") {
+		return nil, fmt.Errorf("invalid path %q: must end with a field holding a name", path)
+	}
+	return func(obj *unstructured.Unstructured) []Reference {
+		var refs []Reference
+		for _, name := range collect(obj.Object, fields) {
+			if name == "" || (target == serviceAccounts && name == "default") {
+				// The default ServiceAccount exists in every namespace.
+				continue
+			}
+			refs = append(refs, Reference{GroupKind: target, Namespace: obj.GetNamespace(), Name: name})
+		}
+		return refs
+	}, nil
+}
+
+func mustFieldExtractor(path string, target schema.GroupKind) Extractor {
+	extractor, err := FieldExtractor(path, target)
+	if err != nil {
+		panic(err)
+	}
+	return extractor
+}
+
+func collect(value interface{}, fields []string) []string {
+	if len(fields) == 0 {
+		if s, ok := value.(string); ok {
+			return []string{s}
+		}
+		return nil
+	}
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	field, list := strings.CutSuffix(fields[0], "[]")
+	if !list {
+		return collect(m[field], fields[1:])
+	}
+	items, _ := m[field].([]interface{})
+	var names []string
+	for _, item := range items {
+		names = append(names, collect(item, fields[1:])...)
+	}
+	return names
+}
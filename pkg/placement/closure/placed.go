@@ -0,0 +1,111 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package closure
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// PlacedOn returns whether the workload of d is synced to the SyncTarget.
+// While a rollout is in progress, these are the SyncTargets of the waves
+// rolled out so far, see WorkloadDistribution.SyncedTargets.
+func PlacedOn(d *workloadv1alpha1.WorkloadDistribution, syncTarget string) bool {
+	return slices.Contains(d.SyncedTargets(), syncTarget)
+}
+
+// WorkloadReference returns the reference to the workload of d.
+func WorkloadReference(d *workloadv1alpha1.WorkloadDistribution) (Reference, error) {
+	gv, err := schema.ParseGroupVersion(d.Spec.WorkloadRef.APIVersion)
+	if err != nil {
+		return Reference{}, err
+	}
+	return Reference{
+		GroupKind: schema.GroupKind{Group: gv.Group, Kind: d.Spec.WorkloadRef.Kind},
+		Namespace: d.Namespace,
+		Name:      d.Spec.WorkloadRef.Name,
+	}, nil
+}
+
+// Placed returns the objects placed on the SyncTarget by the distributions:
+// their workloads and the objects exported with them, including referenced
+// objects that do not exist yet. Distributions with invalid workload
+// references or closures are skipped, as placement reports them.
+func Placed(ctx context.Context, distributions []*workloadv1alpha1.WorkloadDistribution, syncTarget string, get GetFunc) (sets.Set[Reference], error) {
+	workloads, err := PlacedWorkloads(ctx, distributions, syncTarget, get)
+	if err != nil {
+		return nil, err
+	}
+	placed := sets.New[Reference]()
+	for _, objs := range workloads {
+		placed = placed.Union(objs)
+	}
+	return placed, nil
+}
+
+// PlacedWorkloads returns the objects placed on the SyncTarget by the
+// distributions per workload, see Placed. The objects of a workload include
+// the workload itself.
+func PlacedWorkloads(ctx context.Context, distributions []*workloadv1alpha1.WorkloadDistribution, syncTarget string, get GetFunc) (map[Reference]sets.Set[Reference], error) {
+	workloads := map[Reference]sets.Set[Reference]{}
+	for _, d := range distributions {
+		if !PlacedOn(d, syncTarget) {
+			continue
+		}
+		ref, err := WorkloadReference(d)
+		if err != nil {
+			continue
+		}
+		placed, found := workloads[ref]
+		if !found {
+			placed = sets.New(ref)
+			workloads[ref] = placed
+		}
+		root, err := get(ctx, ref)
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		extractors := NewExtractors()
+		mode := workloadv1alpha1.ClosureModeNone
+		if d.Spec.Closure != nil {
+			mode = d.Spec.Closure.Mode
+			if err := extractors.AddFields(d.Spec.Closure.References); err != nil {
+				continue
+			}
+		}
+		resolver := &Resolver{Extractors: extractors, Get: get}
+		c, err := resolver.Resolve(ctx, root, mode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve the closure of WorkloadDistribution %s/%s: %w", d.Namespace, d.Name, err)
+		}
+		for _, obj := range c.Objects {
+			placed.Insert(ReferenceTo(obj))
+		}
+		placed.Insert(c.Missing...)
+	}
+	return workloads, nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package composite evaluates the rules of WorkloadPlacementAdvanced
+// composite policies: which rule places a workload, and which workloads
+// each rule places.
+package composite
+
+import (
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// Rules are the rules of a WorkloadPlacementAdvanced in order of precedence.
+type Rules struct {
+	spec  *placementv1alpha1.WorkloadPlacementAdvancedSpec
+	rules []rule
+
+	// Invalid maps the names of rules with invalid workload selectors to
+	// the error. These rules select no workloads.
+	Invalid map[string]error
+}
+
+type rule struct {
+	*placementv1alpha1.PlacementRule
+	selector labels.Selector
+}
+
+// Compile orders the rules by descending priority, and rules of equal
+// priority by their position in the spec.
+func Compile(spec *placementv1alpha1.WorkloadPlacementAdvancedSpec) *Rules {
+	r := &Rules{spec: spec, Invalid: map[string]error{}}
+	for i := range spec.Rules {
+		selector := labels.Everything()
+		if spec.Rules[i].WorkloadSelector != nil {
+			var err error
+			selector, err = metav1.LabelSelectorAsSelector(spec.Rules[i].WorkloadSelector)
+			if err != nil {
+				r.Invalid[spec.Rules[i].Name] = err
+				continue
+			}
+		}
+		r.rules = append(r.rules, rule{PlacementRule: &spec.Rules[i], selector: selector})
+	}
+	sort.SliceStable(r.rules, func(i, j int) bool {
+		return r.rules[i].Priority > r.rules[j].Priority
+	})
+	return r
+}
+
+// Match returns the rule placing a workload with the given labels, or nil
+// if no rule selects it.
+func (r *Rules) Match(workloadLabels map[string]string) *placementv1alpha1.PlacementRule {
+	set := labels.Set(workloadLabels)
+	for _, rule := range r.rules {
+		if rule.selector.Matches(set) {
+			return rule.PlacementRule
+		}
+	}
+	return nil
+}
+
+// Assign returns the workloads each rule places, in the order of the rules
+// in the spec, and the number of workloads no rule selects.
+func (r *Rules) Assign(distributions []*workloadv1alpha1.WorkloadDistribution) ([]placementv1alpha1.PlacementRuleStatus, int32) {
+	matched := map[string][]string{}
+	var unmatched int32
+	for _, d := range distributions {
+		rule := r.Match(d.Labels)
+		if rule == nil {
+			unmatched++
+			continue
+		}
+		matched[rule.Name] = append(matched[rule.Name], fmt.Sprintf("%s/%s", d.Namespace, d.Name))
+	}
+
+	statuses := make([]placementv1alpha1.PlacementRuleStatus, 0, len(r.spec.Rules))
+	for _, rule := range r.spec.Rules {
+		workloads := matched[rule.Name]
+		sort.Strings(workloads)
+		status := placementv1alpha1.PlacementRuleStatus{Name: rule.Name, Matched: int32(len(workloads))}
+		if len(workloads) > placementv1alpha1.MaxListedWorkloads {
+			workloads = workloads[:placementv1alpha1.MaxListedWorkloads]
+		}
+		status.Workloads = workloads
+		statuses = append(statuses, status)
+	}
+	return statuses, unmatched
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+func selector(key, value string) *metav1.LabelSelector {
+	return &metav1.LabelSelector{MatchLabels: map[string]string{key: value}}
+}
+
+func TestMatch(t *testing.T) {
+	spec := &placementv1alpha1.WorkloadPlacementAdvancedSpec{
+		Rules: []placementv1alpha1.PlacementRule{
+			{Name: "default"},
+			{Name: "databases", Priority: 10, WorkloadSelector: selector("tier", "db")},
+			{Name: "critical-databases", Priority: 10, WorkloadSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"tier": "db", "critical": "true"},
+			}},
+			{Name: "batch", Priority: 5, WorkloadSelector: selector("tier", "batch")},
+			{Name: "broken", Priority: 100, WorkloadSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: "Bogus"}},
+			}},
+		},
+	}
+	rules := Compile(spec)
+	require.Contains(t, rules.Invalid, "broken")
+	require.Len(t, rules.Invalid, 1)
+
+	tests := map[string]struct {
+		labels map[string]string
+		want   string
+	}{
+		"no labels falls through to the catch-all": {labels: nil, want: "default"},
+		"higher priority wins over list order":     {labels: map[string]string{"tier": "batch"}, want: "batch"},
+		"equal priority is decided by list order":  {labels: map[string]string{"tier": "db", "critical": "true"}, want: "databases"},
+		"selector matches":                         {labels: map[string]string{"tier": "db"}, want: "databases"},
+		"invalid rules select nothing, not all":    {labels: map[string]string{"tier": "web"}, want: "default"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rule := rules.Match(tt.labels)
+			require.NotNil(t, rule)
+			require.Equal(t, tt.want, rule.Name)
+		})
+	}
+
+	require.Nil(t, Compile(&placementv1alpha1.WorkloadPlacementAdvancedSpec{
+		Rules: []placementv1alpha1.PlacementRule{{Name: "db", WorkloadSelector: selector("tier", "db")}},
+	}).Match(map[string]string{"tier": "web"}))
+}
+
+func TestAssign(t *testing.T) {
+	spec := &placementv1alpha1.WorkloadPlacementAdvancedSpec{
+		Rules: []placementv1alpha1.PlacementRule{
+			{Name: "web", WorkloadSelector: selector("tier", "web")},
+			{Name: "db", Priority: 1, WorkloadSelector: selector("tier", "db")},
+			{Name: "unused", WorkloadSelector: selector("tier", "cache")},
+		},
+	}
+	distribution := func(namespace, name, tier string) *workloadv1alpha1.WorkloadDistribution {
+		return &workloadv1alpha1.WorkloadDistribution{ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace, Name: name, Labels: map[string]string{"tier": tier},
+		}}
+	}
+
+	statuses, unmatched := Compile(spec).Assign([]*workloadv1alpha1.WorkloadDistribution{
+		distribution("b", "frontend", "web"),
+		distribution("a", "frontend", "web"),
+		distribution("a", "postgres", "db"),
+		distribution("a", "job", "batch"),
+	})
+	require.Equal(t, []placementv1alpha1.PlacementRuleStatus{
+		{Name: "web", Matched: 2, Workloads: []string{"a/frontend", "b/frontend"}},
+		{Name: "db", Matched: 1, Workloads: []string{"a/postgres"}},
+		{Name: "unused", Matched: 0},
+	}, statuses)
+	require.Equal(t, int32(1), unmatched)
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package constraint compiles and evaluates the CEL constraints of
+// PlacementPolicies against SyncTargets.
+package constraint
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/lru"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+const (
+	// TargetVariable is the name of the variable holding the SyncTarget.
+	TargetVariable = "target"
+
+	// ExpensiveCost is the estimated cost above which an expression is
+	// considered expensive to evaluate for every SyncTarget.
+	ExpensiveCost = 10_000
+
+	// CostLimit is the cost after which the evaluation of an expression
+	// is aborted.
+	CostLimit = 1_000_000
+
+	// maxSize bounds the size of lists, maps and strings of a SyncTarget for
+	// cost estimation.
+	maxSize = 1024
+)
+
+// Constraint is a compiled CEL constraint.
+type Constraint struct {
+	expression string
+	program    cel.Program
+	// Cost is the estimated worst-case cost of the expression.
+	Cost uint64
+}
+
+// Compile compiles a CEL expression that must evaluate to a bool.
+func Compile(expression string) (*Constraint, error) {
+	env, err := cel.NewEnv(cel.Variable(TargetVariable, cel.MapType(cel.StringType, cel.DynType)))
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if !ast.OutputType().IsExactType(cel.BoolType) && !ast.OutputType().IsExactType(cel.DynType) {
+		return nil, fmt.Errorf("must evaluate to bool, not %s", ast.OutputType())
+	}
+	cost, err := env.EstimateCost(ast, sizeEstimator{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate cost: %w", err)
+	}
+	program, err := env.Program(ast, cel.CostLimit(CostLimit))
+	if err != nil {
+		return nil, err
+	}
+	return &Constraint{expression: expression, program: program, Cost: cost.Max}, nil
+}
+
+// Cache caches compiled constraints by expression, so that they are not
+// compiled again for every placement decision. Failures to compile are
+// cached too. It is safe for concurrent use.
+type Cache struct {
+	compiled *lru.Cache
+}
+
+// compiled is the outcome of compiling an expression.
+type compiled struct {
+	constraint *Constraint
+	err        error
+}
+
+// NewCache returns a cache of up to size compiled constraints, evicting the
+// least recently used.
+func NewCache(size int) *Cache {
+	return &Cache{compiled: lru.New(size)}
+}
+
+// Compile returns the compiled expression, compiling it if it is not
+// cached.
+func (c *Cache) Compile(expression string) (*Constraint, error) {
+	if v, found := c.compiled.Get(expression); found {
+		out := v.(compiled)
+		return out.constraint, out.err
+	}
+	constraint, err := Compile(expression)
+	c.compiled.Add(expression, compiled{constraint: constraint, err: err})
+	return constraint, err
+}
+
+// Len returns the number of cached expressions.
+func (c *Cache) Len() int {
+	return c.compiled.Len()
+}
+
+// Matches evaluates the constraint for the SyncTarget.
+func (c *Constraint) Matches(syncTarget *tmcv1alpha1.SyncTarget) (bool, error) {
+	target, err := runtime.DefaultUnstructuredConverter.ToUnstructured(syncTarget)
+	if err != nil {
+		return false, err
+	}
+	out, _, err := c.program.Eval(map[string]interface{}{TargetVariable: target})
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate %q: %w", c.expression, err)
+	}
+	matches, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("%q evaluated to %s, not bool", c.expression, out.Type().TypeName())
+	}
+	return matches, nil
+}
+
+// sizeEstimator bounds the size of all values, which are unknown for the
+// dynamically typed SyncTarget.
+type sizeEstimator struct{}
+
+func (sizeEstimator) EstimateSize(checker.AstNode) *checker.SizeEstimate {
+	return &checker.SizeEstimate{Min: 0, Max: maxSize}
+}
+
+func (sizeEstimator) EstimateCallCost(string, string, *checker.AstNode, []checker.AstNode) *checker.CallEstimate {
+	return nil
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decision
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// DecisionDiff is the difference between two decisions for the same
+// workload, e.g. to tell why the workload moved.
+type DecisionDiff struct {
+	// From and To are the IDs of the compared records.
+	From string `json:"from"`
+	To   string `json:"to"`
+
+	PolicyChange         *Change `json:"policyChange,omitempty"`
+	PolicyRevisionChange *Change `json:"policyRevisionChange,omitempty"`
+	StatusChange         *Change `json:"statusChange,omitempty"`
+
+	// TargetsAdded and TargetsRemoved are the SyncTargets chosen by only
+	// one of the decisions.
+	TargetsAdded   []string `json:"targetsAdded,omitempty"`
+	TargetsRemoved []string `json:"targetsRemoved,omitempty"`
+	// ReplicaChanges are the SyncTargets chosen by both decisions with
+	// different replicas.
+	ReplicaChanges []ReplicaChange `json:"replicaChanges,omitempty"`
+	// ScoreChanges are the scores that differ, by SyncTarget and scorer.
+	ScoreChanges []ScoreChange `json:"scoreChanges,omitempty"`
+	// ConstraintChanges are the SyncTargets whose feasibility or reason of
+	// rejection differs.
+	ConstraintChanges []ConstraintChange `json:"constraintChanges,omitempty"`
+}
+
+// Change is a changed value.
+type Change struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ReplicaChange is a change of the replicas placed on a SyncTarget. Nil
+// means the workload is not scalable.
+type ReplicaChange struct {
+	SyncTarget string `json:"syncTarget"`
+	From       *int32 `json:"from,omitempty"`
+	To         *int32 `json:"to,omitempty"`
+}
+
+// ScoreChange is a change of the score of a SyncTarget. Nil means the
+// SyncTarget was not scored.
+type ScoreChange struct {
+	SyncTarget string `json:"syncTarget"`
+	// Scorer is the name of the scorer, or empty for the weighted score.
+	Scorer string `json:"scorer,omitempty"`
+	From   *int   `json:"from,omitempty"`
+	To     *int   `json:"to,omitempty"`
+}
+
+// ConstraintChange is a change of the feasibility of a SyncTarget. An empty
+// reason means the SyncTarget was not rejected.
+type ConstraintChange struct {
+	SyncTarget string `json:"syncTarget"`
+	FromReason string `json:"fromReason,omitempty"`
+	ToReason   string `json:"toReason,omitempty"`
+}
+
+// Empty returns whether the decisions do not differ.
+func (d *DecisionDiff) Empty() bool {
+	return d.PolicyChange == nil && d.PolicyRevisionChange == nil && d.StatusChange == nil &&
+		len(d.TargetsAdded) == 0 && len(d.TargetsRemoved) == 0 && len(d.ReplicaChanges) == 0 &&
+		len(d.ScoreChanges) == 0 && len(d.ConstraintChanges) == 0
+}
+
+// Compare returns how the decision to differs from the decision from. Both
+// must be decisions for the same workload.
+func Compare(from, to *DecisionRecord) (*DecisionDiff, error) {
+	if from.Workspace != to.Workspace || from.Namespace != to.Namespace || from.Name != to.Name {
+		return nil, fmt.Errorf("decisions %s and %s are for different workloads: %s|%s/%s and %s|%s/%s",
+			from.ID, to.ID, from.Workspace, from.Namespace, from.Name, to.Workspace, to.Namespace, to.Name)
+	}
+
+	diff := &DecisionDiff{
+		From:                 from.ID,
+		To:                   to.ID,
+		PolicyChange:         change(from.Policy, to.Policy),
+		PolicyRevisionChange: change(from.PolicyRevision, to.PolicyRevision),
+		StatusChange:         change(string(from.Status), string(to.Status)),
+	}
+
+	fromTargets, toTargets := targets(from.Targets), targets(to.Targets)
+	for _, name := range sortedKeys(toTargets) {
+		if _, found := fromTargets[name]; !found {
+			diff.TargetsAdded = append(diff.TargetsAdded, name)
+		}
+	}
+	for _, name := range sortedKeys(fromTargets) {
+		t, found := toTargets[name]
+		if !found {
+			diff.TargetsRemoved = append(diff.TargetsRemoved, name)
+			continue
+		}
+		if f := fromTargets[name]; !equalReplicas(f.Replicas, t.Replicas) {
+			diff.ReplicaChanges = append(diff.ReplicaChanges, ReplicaChange{SyncTarget: name, From: f.Replicas, To: t.Replicas})
+		}
+	}
+
+	diff.ScoreChanges = scoreChanges("", from.Scores, to.Scores)
+	scorers := sets.KeySet(from.ScorerScores).Union(sets.KeySet(to.ScorerScores))
+	for _, scorer := range sets.List(scorers) {
+		diff.ScoreChanges = append(diff.ScoreChanges, scoreChanges(scorer, from.ScorerScores[scorer], to.ScorerScores[scorer])...)
+	}
+	sort.SliceStable(diff.ScoreChanges, func(i, j int) bool {
+		return diff.ScoreChanges[i].SyncTarget < diff.ScoreChanges[j].SyncTarget
+	})
+
+	rejected := sets.KeySet(from.Rejected).Union(sets.KeySet(to.Rejected))
+	for _, name := range sets.List(rejected) {
+		if f, t := from.Rejected[name], to.Rejected[name]; f != t {
+			diff.ConstraintChanges = append(diff.ConstraintChanges, ConstraintChange{SyncTarget: name, FromReason: f, ToReason: t})
+		}
+	}
+
+	return diff, nil
+}
+
+func change(from, to string) *Change {
+	if from == to {
+		return nil
+	}
+	return &Change{From: from, To: to}
+}
+
+func targets(placements []workloadv1alpha1.TargetPlacement) map[string]workloadv1alpha1.TargetPlacement {
+	m := make(map[string]workloadv1alpha1.TargetPlacement, len(placements))
+	for _, p := range placements {
+		m[p.SyncTarget] = p
+	}
+	return m
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	return sets.List(sets.KeySet(m))
+}
+
+func equalReplicas(a, b *int32) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// scoreChanges returns the changes of the scores of scorer, sorted by
+// SyncTarget.
+func scoreChanges(scorer string, from, to map[string]int) []ScoreChange {
+	var changes []ScoreChange
+	for _, name := range sets.List(sets.KeySet(from).Union(sets.KeySet(to))) {
+		f, inFrom := from[name]
+		t, inTo := to[name]
+		if inFrom && inTo && f == t {
+			continue
+		}
+		c := ScoreChange{SyncTarget: name, Scorer: scorer}
+		if inFrom {
+			c.From = &f
+		}
+		if inTo {
+			c.To = &t
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decision
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/utils/ptr"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+func TestCompare(t *testing.T) {
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	from := record("root:org", "app", StatusSucceeded, t0, 0, "eu-1", "eu-2")
+	from.ID = "1"
+	from.Policy, from.PolicyRevision = "spread", "1"
+	from.Targets[1].Replicas = ptr.To[int32](2)
+	from.Scores = map[string]int{"eu-1": 80, "eu-2": 60, "us-1": 40}
+	from.ScorerScores = map[string]map[string]int{
+		"LeastAllocated": {"eu-1": 80, "eu-2": 60, "us-1": 40},
+	}
+	from.Rejected = map[string]string{"ap-1": "is not ready"}
+
+	to := record("root:org", "app", StatusSucceeded, t0.Add(time.Hour), 0, "eu-2", "us-1")
+	to.ID = "2"
+	to.Policy, to.PolicyRevision = "spread", "2"
+	to.Targets[0].Replicas = ptr.To[int32](3)
+	to.Scores = map[string]int{"eu-2": 60, "us-1": 90}
+	to.ScorerScores = map[string]map[string]int{
+		"LeastAllocated": {"eu-2": 60, "us-1": 90},
+	}
+	to.Rejected = map[string]string{"eu-1": "is cordoned"}
+
+	diff, err := Compare(&from, &to)
+	require.NoError(t, err)
+	require.Equal(t, &DecisionDiff{
+		From:                 "1",
+		To:                   "2",
+		PolicyRevisionChange: &Change{From: "1", To: "2"},
+		TargetsAdded:         []string{"us-1"},
+		TargetsRemoved:       []string{"eu-1"},
+		ReplicaChanges:       []ReplicaChange{{SyncTarget: "eu-2", From: ptr.To[int32](2), To: ptr.To[int32](3)}},
+		ScoreChanges: []ScoreChange{
+			{SyncTarget: "eu-1", From: ptr.To(80)},
+			{SyncTarget: "eu-1", Scorer: "LeastAllocated", From: ptr.To(80)},
+			{SyncTarget: "us-1", From: ptr.To(40), To: ptr.To(90)},
+			{SyncTarget: "us-1", Scorer: "LeastAllocated", From: ptr.To(40), To: ptr.To(90)},
+		},
+		ConstraintChanges: []ConstraintChange{
+			{SyncTarget: "ap-1", FromReason: "is not ready"},
+			{SyncTarget: "eu-1", ToReason: "is cordoned"},
+		},
+	}, diff)
+	require.False(t, diff.Empty())
+
+	diff, err = Compare(&from, &from)
+	require.NoError(t, err)
+	require.True(t, diff.Empty())
+
+	other := record("root:org", "db", StatusSucceeded, t0, 0)
+	_, err = Compare(&from, &other)
+	require.ErrorContains(t, err, "different workloads")
+
+	// Targets without replicas are not scalable and do not change.
+	from.Targets = []workloadv1alpha1.TargetPlacement{{SyncTarget: "eu-1"}}
+	to.Targets = []workloadv1alpha1.TargetPlacement{{SyncTarget: "eu-1"}}
+	diff, err = Compare(&from, &to)
+	require.NoError(t, err)
+	require.Empty(t, diff.ReplicaChanges)
+}
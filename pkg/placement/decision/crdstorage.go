@@ -0,0 +1,341 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decision
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+)
+
+// RecordsGVR is the resource decision records are stored as.
+var RecordsGVR = placementv1alpha1.SchemeGroupVersion.WithResource("placementdecisionrecords")
+
+// CRDStorage stores decision records as PlacementDecisionRecords in the
+// workspace of their workload, named by their ID.
+type CRDStorage struct {
+	now func() time.Time
+
+	get func(ctx context.Context, workspace logicalcluster.Name, name string) (*unstructured.Unstructured, error)
+	// list lists the records of a workspace, or of all workspaces if empty.
+	list   func(ctx context.Context, workspace logicalcluster.Name) ([]unstructured.Unstructured, error)
+	create func(ctx context.Context, workspace logicalcluster.Name, obj *unstructured.Unstructured) error
+	update func(ctx context.Context, workspace logicalcluster.Name, obj *unstructured.Unstructured) error
+	delete func(ctx context.Context, workspace logicalcluster.Name, name string) error
+}
+
+var _ DecisionStorage = &CRDStorage{}
+
+// NewCRDStorage returns a storage of records with client.
+func NewCRDStorage(client kcpdynamic.ClusterInterface) *CRDStorage {
+	return &CRDStorage{
+		now: time.Now,
+		get: func(ctx context.Context, workspace logicalcluster.Name, name string) (*unstructured.Unstructured, error) {
+			return client.Cluster(workspace.Path()).Resource(RecordsGVR).Get(ctx, name, metav1.GetOptions{})
+		},
+		list: func(ctx context.Context, workspace logicalcluster.Name) ([]unstructured.Unstructured, error) {
+			var list *unstructured.UnstructuredList
+			var err error
+			if workspace.Empty() {
+				list, err = client.Resource(RecordsGVR).List(ctx, metav1.ListOptions{})
+			} else {
+				list, err = client.Cluster(workspace.Path()).Resource(RecordsGVR).List(ctx, metav1.ListOptions{})
+			}
+			if err != nil {
+				return nil, err
+			}
+			return list.Items, nil
+		},
+		create: func(ctx context.Context, workspace logicalcluster.Name, obj *unstructured.Unstructured) error {
+			_, err := client.Cluster(workspace.Path()).Resource(RecordsGVR).Create(ctx, obj, metav1.CreateOptions{})
+			return err
+		},
+		update: func(ctx context.Context, workspace logicalcluster.Name, obj *unstructured.Unstructured) error {
+			_, err := client.Cluster(workspace.Path()).Resource(RecordsGVR).Update(ctx, obj, metav1.UpdateOptions{})
+			return err
+		},
+		delete: func(ctx context.Context, workspace logicalcluster.Name, name string) error {
+			return client.Cluster(workspace.Path()).Resource(RecordsGVR).Delete(ctx, name, metav1.DeleteOptions{})
+		},
+	}
+}
+
+// Record creates the record, or replaces the stored record with its ID.
+func (s *CRDStorage) Record(ctx context.Context, record *DecisionRecord) error {
+	if record.ID == "" || record.Workspace.Empty() {
+		return fmt.Errorf("decision record of %s/%s has no ID or workspace", record.Namespace, record.Name)
+	}
+	obj, err := toObject(record)
+	if err != nil {
+		return err
+	}
+	err = s.create(ctx, record.Workspace, obj)
+	if !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	existing, err := s.get(ctx, record.Workspace, record.ID)
+	if err != nil {
+		return err
+	}
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	return s.update(ctx, record.Workspace, obj)
+}
+
+// Get returns the record with the given ID in a workspace.
+func (s *CRDStorage) Get(ctx context.Context, workspace logicalcluster.Name, id string) (*DecisionRecord, error) {
+	obj, err := s.get(ctx, workspace, id)
+	if err != nil {
+		return nil, err
+	}
+	return fromObject(workspace, obj)
+}
+
+// History returns the records matching the query. The stored records are
+// filtered and sorted on every call, and pages are offsets into the
+// result, so concurrent changes may shift records between pages.
+func (s *CRDStorage) History(ctx context.Context, query HistoryQuery) (*HistoryPage, error) {
+	start := 0
+	if query.Continue != "" {
+		var err error
+		if start, err = strconv.Atoi(query.Continue); err != nil || start < 0 {
+			return nil, fmt.Errorf("invalid continue token %q", query.Continue)
+		}
+	}
+
+	records, err := s.records(ctx, query.Workspace)
+	if err != nil {
+		return nil, err
+	}
+	matching := records[:0]
+	for _, r := range records {
+		if matches(r, query) {
+			matching = append(matching, r)
+		}
+	}
+	sortRecords(matching, query.SortBy, query.Descending)
+
+	page := &HistoryPage{}
+	if start >= len(matching) {
+		return page, nil
+	}
+	end := len(matching)
+	if query.Limit > 0 && start+query.Limit < end {
+		end = start + query.Limit
+		page.Continue = strconv.Itoa(end)
+	}
+	page.Records = matching[start:end]
+	return page, nil
+}
+
+// Purge deletes records older than the maximum age, and the oldest records
+// of workloads beyond the maximum number per workload.
+func (s *CRDStorage) Purge(ctx context.Context, policy RetentionPolicy) (int, error) {
+	if policy.MaxAge <= 0 && policy.MaxRecordsPerWorkload <= 0 {
+		return 0, nil
+	}
+	records, err := s.records(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+	sortRecords(records, SortByTime, true)
+
+	cutoff := s.now().Add(-policy.MaxAge)
+	kept := map[workload]int{}
+	var purged int
+	var errs []error
+	for _, r := range records {
+		w := workload{workspace: r.Workspace, namespace: r.Namespace, name: r.Name}
+		tooOld := policy.MaxAge > 0 && r.Time.Before(cutoff)
+		tooMany := policy.MaxRecordsPerWorkload > 0 && kept[w] >= policy.MaxRecordsPerWorkload
+		if !tooOld && !tooMany {
+			kept[w]++
+			continue
+		}
+		if err := s.delete(ctx, r.Workspace, r.ID); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, err)
+			continue
+		}
+		purged++
+	}
+	return purged, utilerrors.NewAggregate(errs)
+}
+
+// records returns the records of a workspace, or of all workspaces if
+// empty. Objects that are not valid records are skipped.
+func (s *CRDStorage) records(ctx context.Context, workspace logicalcluster.Name) ([]DecisionRecord, error) {
+	objs, err := s.list(ctx, workspace)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]DecisionRecord, 0, len(objs))
+	for i := range objs {
+		r, err := fromObject(logicalcluster.From(&objs[i]), &objs[i])
+		if err != nil {
+			continue
+		}
+		records = append(records, *r)
+	}
+	return records, nil
+}
+
+func matches(r DecisionRecord, query HistoryQuery) bool {
+	switch {
+	case !query.Workspace.Empty() && r.Workspace != query.Workspace:
+		return false
+	case query.Namespace != "" && r.Namespace != query.Namespace:
+		return false
+	case query.Name != "" && r.Name != query.Name:
+		return false
+	case query.Status != "" && r.Status != query.Status:
+		return false
+	case !query.Since.IsZero() && r.Time.Before(query.Since):
+		return false
+	case !query.Until.IsZero() && !r.Time.Before(query.Until):
+		return false
+	}
+	return true
+}
+
+// sortRecords sorts records by field, breaking ties by ID so that pages
+// are stable.
+func sortRecords(records []DecisionRecord, field SortField, descending bool) {
+	less := func(a, b *DecisionRecord) bool {
+		if field == SortByDuration && a.Duration != b.Duration {
+			return a.Duration < b.Duration
+		}
+		if !a.Time.Equal(b.Time) {
+			return a.Time.Before(b.Time)
+		}
+		if a.Workspace != b.Workspace {
+			return a.Workspace < b.Workspace
+		}
+		return a.ID < b.ID
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		if descending {
+			return less(&records[j], &records[i])
+		}
+		return less(&records[i], &records[j])
+	})
+}
+
+func toObject(r *DecisionRecord) (*unstructured.Unstructured, error) {
+	record := &placementv1alpha1.PlacementDecisionRecord{
+		TypeMeta:   metav1.TypeMeta{APIVersion: placementv1alpha1.SchemeGroupVersion.String(), Kind: "PlacementDecisionRecord"},
+		ObjectMeta: metav1.ObjectMeta{Name: r.ID},
+		Spec: placementv1alpha1.PlacementDecisionRecordSpec{
+			Namespace:      r.Namespace,
+			Distribution:   r.Name,
+			Policy:         r.Policy,
+			PolicyRevision: r.PolicyRevision,
+			Strategy:       r.Strategy,
+			Status:         string(r.Status),
+			Message:        r.Message,
+			Targets:        r.Targets,
+			Rejected:       r.Rejected,
+			Scores:         toInt32(r.Scores),
+			Time:           metav1.NewTime(r.Time),
+			Duration:       metav1.Duration{Duration: r.Duration},
+		},
+	}
+	if r.ScorerScores != nil {
+		record.Spec.ScorerScores = make(map[string]map[string]int32, len(r.ScorerScores))
+		for scorer, scores := range r.ScorerScores {
+			record.Spec.ScorerScores[scorer] = toInt32(scores)
+		}
+	}
+	for _, a := range r.Attempts {
+		record.Spec.Attempts = append(record.Spec.Attempts, placementv1alpha1.PlacementDecisionAttempt{
+			Time:    metav1.NewTime(a.Time),
+			Status:  string(a.Status),
+			Message: a.Message,
+		})
+	}
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(record)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: raw}, nil
+}
+
+func fromObject(workspace logicalcluster.Name, obj *unstructured.Unstructured) (*DecisionRecord, error) {
+	record := &placementv1alpha1.PlacementDecisionRecord{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, record); err != nil {
+		return nil, err
+	}
+	r := &DecisionRecord{
+		ID:             record.Name,
+		Workspace:      workspace,
+		Namespace:      record.Spec.Namespace,
+		Name:           record.Spec.Distribution,
+		Policy:         record.Spec.Policy,
+		PolicyRevision: record.Spec.PolicyRevision,
+		Strategy:       record.Spec.Strategy,
+		Status:         Status(record.Spec.Status),
+		Message:        record.Spec.Message,
+		Targets:        record.Spec.Targets,
+		Rejected:       record.Spec.Rejected,
+		Scores:         fromInt32(record.Spec.Scores),
+		Time:           record.Spec.Time.UTC(),
+		Duration:       record.Spec.Duration.Duration,
+	}
+	if record.Spec.ScorerScores != nil {
+		r.ScorerScores = make(map[string]map[string]int, len(record.Spec.ScorerScores))
+		for scorer, scores := range record.Spec.ScorerScores {
+			r.ScorerScores[scorer] = fromInt32(scores)
+		}
+	}
+	for _, a := range record.Spec.Attempts {
+		r.Attempts = append(r.Attempts, DecisionAttempt{Time: a.Time.UTC(), Status: Status(a.Status), Message: a.Message})
+	}
+	return r, nil
+}
+
+func toInt32(m map[string]int) map[string]int32 {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]int32, len(m))
+	for k, v := range m {
+		out[k] = int32(v)
+	}
+	return out
+}
+
+func fromInt32(m map[string]int32) map[string]int {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]int, len(m))
+	for k, v := range m {
+		out[k] = int(v)
+	}
+	return out
+}
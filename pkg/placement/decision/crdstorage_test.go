@@ -0,0 +1,193 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decision
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+)
+
+// memoryStorage returns a CRDStorage of objects kept in memory.
+func memoryStorage(now time.Time) *CRDStorage {
+	objects := map[logicalcluster.Name]map[string]*unstructured.Unstructured{}
+	version := 0
+	return &CRDStorage{
+		now: func() time.Time { return now },
+		get: func(_ context.Context, workspace logicalcluster.Name, name string) (*unstructured.Unstructured, error) {
+			obj, found := objects[workspace][name]
+			if !found {
+				return nil, apierrors.NewNotFound(RecordsGVR.GroupResource(), name)
+			}
+			return obj.DeepCopy(), nil
+		},
+		list: func(_ context.Context, workspace logicalcluster.Name) ([]unstructured.Unstructured, error) {
+			var items []unstructured.Unstructured
+			for ws, objs := range objects {
+				if !workspace.Empty() && ws != workspace {
+					continue
+				}
+				for _, obj := range objs {
+					item := obj.DeepCopy()
+					item.SetAnnotations(map[string]string{logicalcluster.AnnotationKey: ws.String()})
+					items = append(items, *item)
+				}
+			}
+			sort.Slice(items, func(i, j int) bool { return items[i].GetName() < items[j].GetName() })
+			return items, nil
+		},
+		create: func(_ context.Context, workspace logicalcluster.Name, obj *unstructured.Unstructured) error {
+			if _, found := objects[workspace][obj.GetName()]; found {
+				return apierrors.NewAlreadyExists(RecordsGVR.GroupResource(), obj.GetName())
+			}
+			if objects[workspace] == nil {
+				objects[workspace] = map[string]*unstructured.Unstructured{}
+			}
+			version++
+			obj.SetResourceVersion(fmt.Sprint(version))
+			objects[workspace][obj.GetName()] = obj.DeepCopy()
+			return nil
+		},
+		update: func(_ context.Context, workspace logicalcluster.Name, obj *unstructured.Unstructured) error {
+			existing, found := objects[workspace][obj.GetName()]
+			if !found {
+				return apierrors.NewNotFound(RecordsGVR.GroupResource(), obj.GetName())
+			}
+			if existing.GetResourceVersion() != obj.GetResourceVersion() {
+				return apierrors.NewConflict(RecordsGVR.GroupResource(), obj.GetName(), fmt.Errorf("resource version changed"))
+			}
+			version++
+			obj.SetResourceVersion(fmt.Sprint(version))
+			objects[workspace][obj.GetName()] = obj.DeepCopy()
+			return nil
+		},
+		delete: func(_ context.Context, workspace logicalcluster.Name, name string) error {
+			if _, found := objects[workspace][name]; !found {
+				return apierrors.NewNotFound(RecordsGVR.GroupResource(), name)
+			}
+			delete(objects[workspace], name)
+			return nil
+		},
+	}
+}
+
+func storeRecords(t *testing.T, s DecisionStorage, records []DecisionRecord) {
+	t.Helper()
+	for i := range records {
+		records[i].ID = fmt.Sprintf("%s-%02d", records[i].Name, i)
+		require.NoError(t, s.Record(context.Background(), &records[i]))
+	}
+}
+
+func TestCRDStorageRecord(t *testing.T) {
+	ctx := context.Background()
+	s := memoryStorage(time.Now())
+
+	r := record("root:org", "app", StatusSucceeded, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), 20*time.Millisecond, "eu-1", "us-1")
+	r.ID = "app-1"
+	r.Rejected = map[string]string{"ap-1": "does not match the target selector"}
+	r.Scores = map[string]int{"eu-1": 80, "us-1": 55}
+	r.ScorerScores = map[string]map[string]int{"Spread": {"eu-1": 100, "us-1": 50}}
+	require.NoError(t, s.Record(ctx, &r))
+
+	got, err := s.Get(ctx, "root:org", "app-1")
+	require.NoError(t, err)
+	require.Equal(t, &r, got)
+
+	r.Attempts = []DecisionAttempt{{Time: r.Time, Status: StatusSucceeded}}
+	r.Time = r.Time.Add(time.Minute)
+	require.NoError(t, s.Record(ctx, &r), "records with an existing ID are replaced")
+	got, err = s.Get(ctx, "root:org", "app-1")
+	require.NoError(t, err)
+	require.Equal(t, &r, got)
+
+	_, err = s.Get(ctx, "root:team", "app-1")
+	require.True(t, apierrors.IsNotFound(err))
+}
+
+func TestCRDStorageHistory(t *testing.T) {
+	ctx := context.Background()
+	s := memoryStorage(time.Now())
+	records := testRecords()
+	storeRecords(t, s, records)
+
+	page, err := s.History(ctx, HistoryQuery{Workspace: "root:team"})
+	require.NoError(t, err)
+	require.Equal(t, []DecisionRecord{records[20]}, page.Records)
+	require.Empty(t, page.Continue)
+
+	page, err = s.History(ctx, HistoryQuery{Status: StatusFailed})
+	require.NoError(t, err)
+	require.Equal(t, []DecisionRecord{records[20]}, page.Records, "queries without a workspace span all workspaces")
+
+	page, err = s.History(ctx, HistoryQuery{Name: "app", Since: records[18].Time})
+	require.NoError(t, err)
+	require.Equal(t, []DecisionRecord{records[18]}, page.Records)
+
+	page, err = s.History(ctx, HistoryQuery{Workspace: "root:org", SortBy: SortByDuration, Descending: true, Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, []DecisionRecord{records[19], records[18]}, page.Records)
+	require.NotEmpty(t, page.Continue)
+
+	var all []DecisionRecord
+	query := HistoryQuery{Name: "app", Until: records[5].Time, Limit: 2}
+	for {
+		page, err := s.History(ctx, query)
+		require.NoError(t, err)
+		all = append(all, page.Records...)
+		if page.Continue == "" {
+			break
+		}
+		query.Continue = page.Continue
+	}
+	require.Equal(t, records[:5], all)
+
+	_, err = s.History(ctx, HistoryQuery{Continue: "next"})
+	require.Error(t, err)
+}
+
+func TestCRDStoragePurge(t *testing.T) {
+	ctx := context.Background()
+	records := testRecords()
+	s := memoryStorage(records[0].Time.Add(time.Minute))
+	storeRecords(t, s, records)
+
+	purged, err := s.Purge(ctx, RetentionPolicy{MaxRecordsPerWorkload: 5})
+	require.NoError(t, err)
+	require.Equal(t, 14, purged)
+
+	page, err := s.History(ctx, HistoryQuery{Name: "app"})
+	require.NoError(t, err)
+	require.Equal(t, records[14:19], page.Records, "the most recent records are kept")
+
+	purged, err = s.Purge(ctx, RetentionPolicy{MaxAge: 30 * time.Second})
+	require.NoError(t, err)
+	require.Equal(t, 6, purged)
+
+	page, err = s.History(ctx, HistoryQuery{})
+	require.NoError(t, err)
+	require.Equal(t, []DecisionRecord{records[18]}, page.Records)
+}
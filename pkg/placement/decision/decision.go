@@ -0,0 +1,156 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package decision defines the audit trail of placement decisions: the
+// records of what placement decided for a workload and why, the storage
+// they are kept in, and metrics aggregated from them.
+package decision
+
+import (
+	"context"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// Status is the outcome of a placement decision.
+type Status string
+
+const (
+	// StatusSucceeded means targets were chosen.
+	StatusSucceeded Status = "Succeeded"
+	// StatusFailed means no targets could be chosen.
+	StatusFailed Status = "Failed"
+	// StatusPending means placement waits, e.g. for dependencies.
+	StatusPending Status = "Pending"
+)
+
+// DecisionRecord is the record of one placement decision for a workload.
+type DecisionRecord struct {
+	// ID identifies the record within its workspace.
+	ID string
+	// Workspace is the logical cluster of the workload.
+	Workspace logicalcluster.Name
+	// Namespace and Name of the WorkloadDistribution.
+	Namespace string
+	Name      string
+
+	// Policy and PolicyRevision the decision was made with.
+	Policy         string
+	PolicyRevision string
+	Strategy       placementv1alpha1.PlacementStrategy
+
+	Status  Status
+	Message string
+	// Targets are the chosen targets.
+	Targets []workloadv1alpha1.TargetPlacement
+	// Rejected maps names of infeasible SyncTargets to the reason.
+	Rejected map[string]string
+	// Scores maps names of feasible SyncTargets to their weighted score.
+	Scores map[string]int
+	// ScorerScores maps the names of the scorers that applied to the scores
+	// they gave the feasible SyncTargets, before weighting.
+	ScorerScores map[string]map[string]int
+
+	// Time the decision was made at, and how long it took.
+	Time     time.Time
+	Duration time.Duration
+
+	// Attempts are earlier attempts that did not change the outcome, e.g.
+	// retries after conflicts.
+	Attempts []DecisionAttempt
+}
+
+// DecisionAttempt is one attempt of making a decision.
+type DecisionAttempt struct {
+	Time    time.Time
+	Status  Status
+	Message string
+}
+
+// SortField is a field history can be sorted by.
+type SortField string
+
+const (
+	SortByTime     SortField = "Time"
+	SortByDuration SortField = "Duration"
+)
+
+// HistoryQuery selects decision records. Zero fields do not restrict.
+type HistoryQuery struct {
+	Workspace logicalcluster.Name
+	Namespace string
+	Name      string
+	Status    Status
+	Since     time.Time
+	Until     time.Time
+
+	// SortBy defaults to SortByTime.
+	SortBy     SortField
+	Descending bool
+
+	// Limit is the maximum number of records per page, zero for all.
+	Limit int
+	// Continue is the token of the page to return, from HistoryPage.Continue.
+	Continue string
+}
+
+// HistoryPage is one page of query results.
+type HistoryPage struct {
+	Records []DecisionRecord
+	// Continue is the token of the next page, or empty on the last page.
+	Continue string
+}
+
+// RetentionPolicy bounds the stored history.
+type RetentionPolicy struct {
+	// MaxAge is the age after which records are purged. Zero keeps records
+	// regardless of age.
+	MaxAge time.Duration
+	// MaxRecordsPerWorkload is the number of most recent records kept per
+	// workload. Zero keeps all.
+	MaxRecordsPerWorkload int
+}
+
+// DecisionStorage persists decision records.
+type DecisionStorage interface {
+	// Record stores a decision record. Records with an existing ID replace
+	// the stored record.
+	Record(ctx context.Context, record *DecisionRecord) error
+	// Get returns the record with the given ID in a workspace.
+	Get(ctx context.Context, workspace logicalcluster.Name, id string) (*DecisionRecord, error)
+	// History returns records matching the query, one page at a time.
+	History(ctx context.Context, query HistoryQuery) (*HistoryPage, error)
+	// Purge deletes records outside the retention policy and returns how
+	// many were deleted.
+	Purge(ctx context.Context, policy RetentionPolicy) (int, error)
+}
+
+// RecorderConfig configures recording of decisions.
+type RecorderConfig struct {
+	// Retention bounds the stored history.
+	Retention RetentionPolicy
+	// EnableMetrics observes the latency of decisions as they are recorded.
+	// Metrics aggregated from the history are exported by an Exporter.
+	EnableMetrics bool
+	// MetricsInterval is how often metrics are refreshed.
+	MetricsInterval time.Duration
+	// MetricsWindow is the history metrics are aggregated over.
+	MetricsWindow time.Duration
+}
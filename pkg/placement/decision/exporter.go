@@ -0,0 +1,199 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decision
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	compbasemetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+)
+
+const (
+	defaultMetricsInterval = 30 * time.Second
+	defaultMetricsWindow   = time.Hour
+)
+
+var (
+	decisionsGauge = compbasemetrics.NewGaugeVec(
+		&compbasemetrics.GaugeOpts{
+			Name:           "tmc_placement_decisions",
+			Help:           "Number of placement decisions in the metrics window, by status.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"status"},
+	)
+	strategyDecisionsGauge = compbasemetrics.NewGaugeVec(
+		&compbasemetrics.GaugeOpts{
+			Name:           "tmc_placement_strategy_decisions",
+			Help:           "Number of placement decisions in the metrics window, by placement strategy.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"strategy"},
+	)
+	rejectionsGauge = compbasemetrics.NewGaugeVec(
+		&compbasemetrics.GaugeOpts{
+			Name:           "tmc_placement_rejections",
+			Help:           "Number of rejections of SyncTargets by placement decisions in the metrics window, by reason.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"reason"},
+	)
+	decisionDurationGauge = compbasemetrics.NewGaugeVec(
+		&compbasemetrics.GaugeOpts{
+			Name:           "tmc_placement_decision_duration_seconds",
+			Help:           "Placement decision duration in the metrics window, by statistic: avg, p50 or p95.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"statistic"},
+	)
+	decisionLatency = compbasemetrics.NewHistogramVec(
+		&compbasemetrics.HistogramOpts{
+			Name:           "tmc_placement_decision_latency_seconds",
+			Help:           "Latency of placement decisions, by placement strategy and status.",
+			Buckets:        []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"strategy", "status"},
+	)
+	workspaceWorkloadsGauge = compbasemetrics.NewGaugeVec(
+		&compbasemetrics.GaugeOpts{
+			Name:           "tmc_placement_workspace_workloads",
+			Help:           "Number of placed workloads, by workspace.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"workspace"},
+	)
+	workspacePlacementsGauge = compbasemetrics.NewGaugeVec(
+		&compbasemetrics.GaugeOpts{
+			Name:           "tmc_placement_workspace_placements",
+			Help:           "Number of workload placements on SyncTargets, by workspace.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"workspace"},
+	)
+	workspaceSyncTargetsGauge = compbasemetrics.NewGaugeVec(
+		&compbasemetrics.GaugeOpts{
+			Name:           "tmc_placement_workspace_sync_targets",
+			Help:           "Number of SyncTargets with placed workloads, by workspace.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"workspace"},
+	)
+)
+
+var registerMetrics sync.Once
+
+// Register metrics.
+func Register() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(decisionsGauge)
+		legacyregistry.MustRegister(strategyDecisionsGauge)
+		legacyregistry.MustRegister(rejectionsGauge)
+		legacyregistry.MustRegister(decisionDurationGauge)
+		legacyregistry.MustRegister(decisionLatency)
+		legacyregistry.MustRegister(workspaceWorkloadsGauge)
+		legacyregistry.MustRegister(workspacePlacementsGauge)
+		legacyregistry.MustRegister(workspaceSyncTargetsGauge)
+	})
+}
+
+// Exporter periodically aggregates DecisionMetrics from a DecisionStorage
+// and exports them as Prometheus gauges. Decision latencies are observed
+// by the Recorder as decisions are made instead, see
+// RecorderConfig.EnableMetrics.
+type Exporter struct {
+	storage  DecisionStorage
+	interval time.Duration
+	window   time.Duration
+	now      func() time.Time
+}
+
+// NewExporter returns an exporter of the metrics of the records in storage,
+// refreshed and windowed as in config.
+func NewExporter(storage DecisionStorage, config RecorderConfig) *Exporter {
+	Register()
+
+	e := &Exporter{
+		storage:  storage,
+		interval: config.MetricsInterval,
+		window:   config.MetricsWindow,
+		now:      time.Now,
+	}
+	if e.interval <= 0 {
+		e.interval = defaultMetricsInterval
+	}
+	if e.window <= 0 {
+		e.window = defaultMetricsWindow
+	}
+	return e
+}
+
+// Run refreshes the metrics until ctx is done.
+func (e *Exporter) Run(ctx context.Context) {
+	logger := klog.FromContext(ctx).WithValues("component", "decision-metrics")
+	logger.Info("Starting placement decision metrics exporter")
+	defer logger.Info("Shutting down placement decision metrics exporter")
+
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := e.Refresh(ctx); err != nil {
+			utilruntime.HandleError(fmt.Errorf("failed to refresh placement decision metrics: %w", err))
+		}
+	}, e.interval)
+}
+
+// Refresh aggregates and exports the metrics once.
+func (e *Exporter) Refresh(ctx context.Context) error {
+	m, err := MetricsFromStorage(ctx, e.storage, e.now().Add(-e.window))
+	if err != nil {
+		return err
+	}
+
+	decisionsGauge.Reset()
+	for _, status := range []Status{StatusSucceeded, StatusFailed, StatusPending} {
+		decisionsGauge.WithLabelValues(string(status)).Set(float64(m.DecisionsByStatus[status]))
+	}
+	// Strategies and reasons no longer seen disappear.
+	strategyDecisionsGauge.Reset()
+	for strategy, n := range m.DecisionsByStrategy {
+		strategyDecisionsGauge.WithLabelValues(string(strategy)).Set(float64(n))
+	}
+	rejectionsGauge.Reset()
+	for reason, n := range m.RejectionsByReason {
+		rejectionsGauge.WithLabelValues(reason).Set(float64(n))
+	}
+	decisionDurationGauge.WithLabelValues("avg").Set(m.AverageDuration.Seconds())
+	decisionDurationGauge.WithLabelValues("p50").Set(m.MedianDuration.Seconds())
+	decisionDurationGauge.WithLabelValues("p95").Set(m.P95Duration.Seconds())
+
+	// Workspaces without placed workloads disappear.
+	workspaceWorkloadsGauge.Reset()
+	workspacePlacementsGauge.Reset()
+	workspaceSyncTargetsGauge.Reset()
+	for workspace, u := range m.WorkspaceUtilization {
+		workspaceWorkloadsGauge.WithLabelValues(workspace.String()).Set(float64(u.Workloads))
+		workspacePlacementsGauge.WithLabelValues(workspace.String()).Set(float64(u.Placements))
+		workspaceSyncTargetsGauge.WithLabelValues(workspace.String()).Set(float64(u.SyncTargets))
+	}
+	return nil
+}
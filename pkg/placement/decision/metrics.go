@@ -0,0 +1,181 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decision
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+)
+
+// DecisionMetrics are aggregated from decision records.
+type DecisionMetrics struct {
+	// TotalDecisions is the number of decisions.
+	TotalDecisions int64
+	// DecisionsByStatus counts decisions by status.
+	DecisionsByStatus map[Status]int64
+	// DecisionsByStrategy counts decisions by placement strategy.
+	DecisionsByStrategy map[placementv1alpha1.PlacementStrategy]int64
+	// RejectionsByReason counts rejections of SyncTargets by reason, see
+	// RejectionReason.
+	RejectionsByReason map[string]int64
+
+	// AverageDuration, MedianDuration and P95Duration are the mean, the
+	// 50th and the 95th percentile of decision durations.
+	AverageDuration time.Duration
+	MedianDuration  time.Duration
+	P95Duration     time.Duration
+
+	// WorkspaceUtilization is the placement footprint per workspace, from
+	// the latest successful decision of every workload.
+	WorkspaceUtilization map[logicalcluster.Name]WorkspaceUtilization
+}
+
+// WorkspaceUtilization is the placement footprint of a workspace.
+type WorkspaceUtilization struct {
+	// Workloads is the number of placed workloads.
+	Workloads int64
+	// Placements is the number of workload placements on SyncTargets.
+	Placements int64
+	// SyncTargets is the number of SyncTargets with at least one workload.
+	SyncTargets int64
+}
+
+// GetMetrics aggregates metrics from records.
+func GetMetrics(records []DecisionRecord) DecisionMetrics {
+	m := DecisionMetrics{
+		DecisionsByStatus:    map[Status]int64{},
+		DecisionsByStrategy:  map[placementv1alpha1.PlacementStrategy]int64{},
+		RejectionsByReason:   map[string]int64{},
+		WorkspaceUtilization: map[logicalcluster.Name]WorkspaceUtilization{},
+	}
+
+	durations := make([]time.Duration, 0, len(records))
+	var total time.Duration
+	latest := map[workload]*DecisionRecord{}
+	for i := range records {
+		r := &records[i]
+		m.TotalDecisions++
+		m.DecisionsByStatus[r.Status]++
+		m.DecisionsByStrategy[r.Strategy]++
+		for _, reason := range r.Rejected {
+			m.RejectionsByReason[RejectionReason(reason)]++
+		}
+		durations = append(durations, r.Duration)
+		total += r.Duration
+
+		if r.Status != StatusSucceeded {
+			continue
+		}
+		key := workload{r.Workspace, r.Namespace, r.Name}
+		if prev, found := latest[key]; !found || prev.Time.Before(r.Time) {
+			latest[key] = r
+		}
+	}
+	if len(durations) > 0 {
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		m.AverageDuration = total / time.Duration(len(durations))
+		m.MedianDuration = percentile(durations, 50)
+		m.P95Duration = percentile(durations, 95)
+	}
+
+	targets := map[logicalcluster.Name]sets.Set[string]{}
+	for key, r := range latest {
+		u := m.WorkspaceUtilization[key.workspace]
+		u.Workloads++
+		u.Placements += int64(len(r.Targets))
+		if targets[key.workspace] == nil {
+			targets[key.workspace] = sets.New[string]()
+		}
+		for _, t := range r.Targets {
+			targets[key.workspace].Insert(t.SyncTarget)
+		}
+		u.SyncTargets = int64(targets[key.workspace].Len())
+		m.WorkspaceUtilization[key.workspace] = u
+	}
+	return m
+}
+
+// MetricsFromStorage aggregates metrics from the records of the given
+// window up to now.
+func MetricsFromStorage(ctx context.Context, storage DecisionStorage, since time.Time) (DecisionMetrics, error) {
+	var records []DecisionRecord
+	query := HistoryQuery{Since: since, Limit: 500}
+	for {
+		page, err := storage.History(ctx, query)
+		if err != nil {
+			return DecisionMetrics{}, err
+		}
+		records = append(records, page.Records...)
+		if page.Continue == "" {
+			break
+		}
+		query.Continue = page.Continue
+	}
+	return GetMetrics(records), nil
+}
+
+// percentile returns the nearest-rank percentile p of sorted durations.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// maxReasonWords bounds the words of the reasons returned by
+// RejectionReason.
+const maxReasonWords = 6
+
+// RejectionReason returns the leading words of the reason a SyncTarget was
+// rejected for, up to the first word naming or quantifying something, e.g.
+// "has no topology label" for `has no topology label "zone"`. Reasons are
+// used as metric labels, which must not carry names or quantities.
+func RejectionReason(reason string) string {
+	words := strings.Fields(reason)
+	var kept []string
+	for _, word := range words {
+		if len(kept) == maxReasonWords {
+			break
+		}
+		if strings.ContainsFunc(word, func(r rune) bool {
+			return unicode.IsUpper(r) || unicode.IsDigit(r) || strings.ContainsRune(`"'-./=`, r)
+		}) {
+			break
+		}
+		trimmed := strings.TrimRight(word, ":,;")
+		if trimmed != "" {
+			kept = append(kept, trimmed)
+		}
+		if trimmed != word {
+			break
+		}
+	}
+	if len(kept) == 0 {
+		return "other"
+	}
+	return strings.Join(kept, " ")
+}
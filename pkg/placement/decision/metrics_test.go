@@ -0,0 +1,142 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decision
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/component-base/metrics/testutil"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// pagedStorage serves History from a fixed set of records, two per page.
+type pagedStorage struct {
+	DecisionStorage
+	records []DecisionRecord
+	since   time.Time
+}
+
+func (s *pagedStorage) History(_ context.Context, query HistoryQuery) (*HistoryPage, error) {
+	s.since = query.Since
+	start := 0
+	if query.Continue != "" {
+		start, _ = strconv.Atoi(query.Continue)
+	}
+	end := min(start+2, len(s.records))
+	page := &HistoryPage{Records: s.records[start:end]}
+	if end < len(s.records) {
+		page.Continue = strconv.Itoa(end)
+	}
+	return page, nil
+}
+
+func record(workspace, name string, status Status, at time.Time, duration time.Duration, targets ...string) DecisionRecord {
+	r := DecisionRecord{Workspace: logicalcluster.Name(workspace), Namespace: "default", Name: name, Status: status, Time: at, Duration: duration}
+	for _, t := range targets {
+		r.Targets = append(r.Targets, workloadv1alpha1.TargetPlacement{SyncTarget: t})
+	}
+	return r
+}
+
+func testRecords() []DecisionRecord {
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var records []DecisionRecord
+	for i := range 18 {
+		records = append(records, record("root:org", "app", StatusSucceeded, t0.Add(time.Duration(i)*time.Second), 10*time.Millisecond, "eu-1"))
+	}
+	failed := record("root:team", "web", StatusFailed, t0, 1240*time.Millisecond)
+	failed.Strategy = placementv1alpha1.PlacementStrategySpread
+	failed.Rejected = map[string]string{
+		"eu-1": `has no topology label "zone"`,
+		"us-1": `has no topology label "rack"`,
+		"ap-1": "syncer is not ready",
+	}
+	return append(records,
+		// The latest decision of app moves it to two targets.
+		record("root:org", "app", StatusSucceeded, t0.Add(time.Minute), 20*time.Millisecond, "eu-1", "us-1"),
+		record("root:org", "db", StatusSucceeded, t0, 30*time.Millisecond, "eu-1"),
+		failed,
+	)
+}
+
+func TestGetMetrics(t *testing.T) {
+	m := GetMetrics(testRecords())
+
+	require.Equal(t, int64(21), m.TotalDecisions)
+	require.Equal(t, map[Status]int64{StatusSucceeded: 20, StatusFailed: 1}, m.DecisionsByStatus)
+	require.Equal(t, map[placementv1alpha1.PlacementStrategy]int64{"": 20, placementv1alpha1.PlacementStrategySpread: 1}, m.DecisionsByStrategy)
+	require.Equal(t, map[string]int64{"has no topology label": 2, "syncer is not ready": 1}, m.RejectionsByReason)
+	require.Equal(t, 70*time.Millisecond, m.AverageDuration)
+	require.Equal(t, 10*time.Millisecond, m.MedianDuration)
+	require.Equal(t, 30*time.Millisecond, m.P95Duration)
+	require.Equal(t, map[logicalcluster.Name]WorkspaceUtilization{
+		"root:org": {Workloads: 2, Placements: 3, SyncTargets: 2},
+	}, m.WorkspaceUtilization)
+
+	require.Equal(t, DecisionMetrics{
+		DecisionsByStatus:    map[Status]int64{},
+		DecisionsByStrategy:  map[placementv1alpha1.PlacementStrategy]int64{},
+		RejectionsByReason:   map[string]int64{},
+		WorkspaceUtilization: map[logicalcluster.Name]WorkspaceUtilization{},
+	}, GetMetrics(nil))
+}
+
+func TestRejectionReason(t *testing.T) {
+	for reason, want := range map[string]string{
+		`has no topology label "zone"`:                              "has no topology label",
+		`is not a member of SyncTargetGroup "edge"`:                 "is not a member of",
+		`does not satisfy constraint: target.spec.location == "us"`: "does not satisfy constraint",
+		"is in a disruption window until 2025-01-01T01:00:00Z":      "is in a disruption window until",
+		"has 2 of cpu allocatable, less than 4":                     "has",
+		"syncer is not ready":                                       "syncer is not ready",
+		`"eu" is full`:                                              "other",
+		"":                                                          "other",
+	} {
+		require.Equal(t, want, RejectionReason(reason), reason)
+	}
+}
+
+func TestExporterRefresh(t *testing.T) {
+	now := time.Date(2025, 1, 1, 1, 0, 0, 0, time.UTC)
+	storage := &pagedStorage{records: testRecords()}
+	e := NewExporter(storage, RecorderConfig{EnableMetrics: true, MetricsWindow: 10 * time.Minute})
+	e.now = func() time.Time { return now }
+
+	require.NoError(t, e.Refresh(context.Background()))
+	require.Equal(t, now.Add(-10*time.Minute), storage.since)
+
+	gauge := func(v float64, err error) float64 {
+		require.NoError(t, err)
+		return v
+	}
+	require.Equal(t, 20.0, gauge(testutil.GetGaugeMetricValue(decisionsGauge.WithLabelValues("Succeeded"))))
+	require.Equal(t, 1.0, gauge(testutil.GetGaugeMetricValue(decisionsGauge.WithLabelValues("Failed"))))
+	require.Equal(t, 1.0, gauge(testutil.GetGaugeMetricValue(strategyDecisionsGauge.WithLabelValues("Spread"))))
+	require.Equal(t, 2.0, gauge(testutil.GetGaugeMetricValue(rejectionsGauge.WithLabelValues("has no topology label"))))
+	require.Equal(t, 0.07, gauge(testutil.GetGaugeMetricValue(decisionDurationGauge.WithLabelValues("avg"))))
+	require.Equal(t, 3.0, gauge(testutil.GetGaugeMetricValue(workspacePlacementsGauge.WithLabelValues("root:org"))))
+	require.Equal(t, 2.0, gauge(testutil.GetGaugeMetricValue(workspaceSyncTargetsGauge.WithLabelValues("root:org"))))
+}
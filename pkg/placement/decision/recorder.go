@@ -0,0 +1,176 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decision
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+)
+
+const (
+	// recorderQueueSize is the number of records waiting to be stored
+	// before new ones are dropped.
+	recorderQueueSize = 1000
+	// maxAttempts is the number of earlier attempts kept per record.
+	maxAttempts = 10
+	// purgeInterval is how often records outside the retention policy are
+	// purged.
+	purgeInterval = 10 * time.Minute
+	// maxIDPrefix bounds the workload part of IDs, so that IDs are valid
+	// object names.
+	maxIDPrefix = 200
+)
+
+type workload struct {
+	workspace       logicalcluster.Name
+	namespace, name string
+}
+
+// Recorder stores decision records in the background, so that placement
+// does not wait for the storage, and purges the records outside the
+// retention policy. Decisions with the same outcome as the previous one of
+// their workload are added as attempts to its record rather than stored as
+// new records.
+type Recorder struct {
+	storage   DecisionStorage
+	retention RetentionPolicy
+	metrics   bool
+	now       func() time.Time
+
+	pending chan *DecisionRecord
+
+	lock sync.Mutex
+	// latest is the latest stored record per workload.
+	latest map[workload]*DecisionRecord
+}
+
+// NewRecorder returns a recorder of decisions into storage, retained as in
+// config.
+func NewRecorder(storage DecisionStorage, config RecorderConfig) *Recorder {
+	if config.EnableMetrics {
+		Register()
+	}
+	return &Recorder{
+		storage:   storage,
+		retention: config.Retention,
+		metrics:   config.EnableMetrics,
+		now:       time.Now,
+		pending:   make(chan *DecisionRecord, recorderQueueSize),
+		latest:    map[workload]*DecisionRecord{},
+	}
+}
+
+// Record observes the latency of the decision, if metrics are enabled, and
+// queues record to be stored. It does not block; records are dropped when
+// the queue is full.
+func (r *Recorder) Record(record *DecisionRecord) {
+	if r.metrics {
+		decisionLatency.WithLabelValues(string(record.Strategy), string(record.Status)).Observe(record.Duration.Seconds())
+	}
+	select {
+	case r.pending <- record:
+	default:
+		utilruntime.HandleError(fmt.Errorf("dropping placement decision record of %s|%s/%s: queue is full", record.Workspace, record.Namespace, record.Name))
+	}
+}
+
+// Run stores queued records and purges records periodically until ctx is
+// done.
+func (r *Recorder) Run(ctx context.Context) {
+	logger := klog.FromContext(ctx).WithValues("component", "decision-recorder")
+	logger.Info("Starting placement decision recorder")
+	defer logger.Info("Shutting down placement decision recorder")
+
+	if r.retention != (RetentionPolicy{}) {
+		go wait.UntilWithContext(ctx, func(ctx context.Context) {
+			purged, err := r.storage.Purge(ctx, r.retention)
+			if err != nil {
+				utilruntime.HandleError(fmt.Errorf("failed to purge placement decision records: %w", err))
+			}
+			if purged > 0 {
+				logger.V(2).Info("purged placement decision records", "count", purged)
+			}
+		}, purgeInterval)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case record := <-r.pending:
+			if err := r.store(ctx, record); err != nil {
+				utilruntime.HandleError(fmt.Errorf("failed to store placement decision record of %s|%s/%s: %w", record.Workspace, record.Namespace, record.Name, err))
+			}
+		}
+	}
+}
+
+// store stores record, coalescing it with the latest record of its
+// workload if the outcome did not change.
+func (r *Recorder) store(ctx context.Context, record *DecisionRecord) error {
+	key := workload{workspace: record.Workspace, namespace: record.Namespace, name: record.Name}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if record.Time.IsZero() {
+		record.Time = r.now()
+	}
+	if prev := r.latest[key]; prev != nil && record.ID == "" && sameOutcome(prev, record) {
+		coalesced := *record
+		coalesced.ID = prev.ID
+		coalesced.Attempts = append(append([]DecisionAttempt{}, prev.Attempts...), DecisionAttempt{Time: prev.Time, Status: prev.Status, Message: prev.Message})
+		if len(coalesced.Attempts) > maxAttempts {
+			coalesced.Attempts = coalesced.Attempts[len(coalesced.Attempts)-maxAttempts:]
+		}
+		record = &coalesced
+	}
+	if record.ID == "" {
+		record.ID = newID(record)
+	}
+	if err := r.storage.Record(ctx, record); err != nil {
+		return err
+	}
+	r.latest[key] = record
+	return nil
+}
+
+// sameOutcome is whether two decisions of a workload had the same outcome.
+func sameOutcome(a, b *DecisionRecord) bool {
+	return a.Status == b.Status &&
+		a.Message == b.Message &&
+		a.PolicyRevision == b.PolicyRevision &&
+		reflect.DeepEqual(a.Targets, b.Targets)
+}
+
+// newID returns a new ID for a record of the workload, unique by time.
+func newID(record *DecisionRecord) string {
+	prefix := record.Namespace + "." + record.Name
+	if len(prefix) > maxIDPrefix {
+		prefix = prefix[:maxIDPrefix]
+	}
+	return fmt.Sprintf("%s.%x", prefix, record.Time.UnixNano())
+}
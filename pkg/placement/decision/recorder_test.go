@@ -0,0 +1,66 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decision
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/component-base/metrics/testutil"
+)
+
+func TestRecorderStore(t *testing.T) {
+	ctx := context.Background()
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := memoryStorage(t0)
+	r := NewRecorder(s, RecorderConfig{})
+
+	for i := range 12 {
+		attempt := record("root:org", "app", StatusSucceeded, t0.Add(time.Duration(i)*time.Second), 0, "eu-1")
+		require.NoError(t, r.store(ctx, &attempt))
+	}
+	moved := record("root:org", "app", StatusSucceeded, t0.Add(time.Minute), 0, "us-1")
+	require.NoError(t, r.store(ctx, &moved))
+
+	page, err := s.History(ctx, HistoryQuery{})
+	require.NoError(t, err)
+	require.Len(t, page.Records, 2, "decisions with the same outcome are coalesced")
+
+	coalesced := page.Records[0]
+	require.Equal(t, "default.app.1816687ec0570000", coalesced.ID)
+	require.Equal(t, t0.Add(11*time.Second), coalesced.Time)
+	require.Len(t, coalesced.Attempts, maxAttempts)
+	require.Equal(t, t0.Add(time.Second), coalesced.Attempts[0].Time, "the oldest attempts are dropped")
+	require.Equal(t, t0.Add(10*time.Second), coalesced.Attempts[maxAttempts-1].Time)
+
+	require.Equal(t, "us-1", page.Records[1].Targets[0].SyncTarget)
+	require.Empty(t, page.Records[1].Attempts)
+}
+
+func TestRecorderObservesLatency(t *testing.T) {
+	r := NewRecorder(memoryStorage(time.Now()), RecorderConfig{EnableMetrics: true})
+	failed := record("root:org", "app", StatusFailed, time.Now(), 40*time.Millisecond)
+	failed.Strategy = "Singleton"
+	r.Record(&failed)
+
+	count, err := testutil.GetHistogramMetricCount(decisionLatency.WithLabelValues("Singleton", "Failed"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), count)
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dependency evaluates the dependsOn relation between
+// WorkloadDistributions: whether the dependencies of a distribution are
+// ready, and which SyncTargets the distribution may be placed on so that it
+// is colocated with them.
+package dependency
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// Lookup returns the distribution with the given name in the namespace of
+// the evaluated distribution, or false if it does not exist.
+type Lookup func(name string) (*workloadv1alpha1.WorkloadDistribution, bool)
+
+// Result is the outcome of evaluating the dependencies of a distribution.
+type Result struct {
+	// Ready is true if all dependencies are ready.
+	Ready bool
+	// Reason and Message explain why dependencies are not ready.
+	Reason  string
+	Message string
+
+	// Targets restricts placement to these SyncTargets, if not nil.
+	Targets sets.Set[string]
+	// Locations restricts placement to these locations, if not nil.
+	Locations sets.Set[string]
+}
+
+// Allows returns whether the SyncTarget satisfies the colocation
+// requirements of the result.
+func (r Result) Allows(syncTarget *tmcv1alpha1.SyncTarget) bool {
+	if r.Targets != nil && !r.Targets.Has(syncTarget.Name) {
+		return false
+	}
+	if r.Locations != nil && !r.Locations.Has(syncTarget.Spec.Location) {
+		return false
+	}
+	return true
+}
+
+// Evaluate checks the dependencies of d. Dependencies form a cycle if d
+// transitively depends on itself; such distributions are never ready.
+func Evaluate(d *workloadv1alpha1.WorkloadDistribution, lookup Lookup) Result {
+	if len(d.Spec.DependsOn) == 0 {
+		return Result{Ready: true}
+	}
+
+	if cycle := FindCycle(d, lookup); cycle != nil {
+		return Result{
+			Reason:  workloadv1alpha1.DependencyCycleReason,
+			Message: fmt.Sprintf("Dependencies form a cycle: %s", strings.Join(cycle, " -> ")),
+		}
+	}
+
+	result := Result{Ready: true}
+	var missing, waiting []string
+	for _, dep := range d.Spec.DependsOn {
+		other, found := lookup(dep.Name)
+		if !found {
+			missing = append(missing, dep.Name)
+			continue
+		}
+		if !conditions.IsTrue(other, workloadv1alpha1.WorkloadReady) || len(other.Status.Targets) == 0 {
+			waiting = append(waiting, dep.Name)
+			continue
+		}
+
+		switch dep.Colocation {
+		case workloadv1alpha1.ColocationAny:
+		case workloadv1alpha1.ColocationSameLocation:
+			locations := sets.New[string]()
+			for _, t := range other.Status.Targets {
+				locations.Insert(t.Location)
+			}
+			result.Locations = intersect(result.Locations, locations)
+		default:
+			targets := sets.New[string]()
+			for _, t := range other.Status.Targets {
+				targets.Insert(t.SyncTarget)
+			}
+			result.Targets = intersect(result.Targets, targets)
+		}
+	}
+
+	switch {
+	case len(missing) > 0:
+		return Result{
+			Reason:  workloadv1alpha1.DependencyNotFoundReason,
+			Message: fmt.Sprintf("Dependencies not found: %s", strings.Join(missing, ", ")),
+		}
+	case len(waiting) > 0:
+		return Result{
+			Reason:  workloadv1alpha1.WaitingForDependenciesReason,
+			Message: fmt.Sprintf("Waiting for dependencies to become ready: %s", strings.Join(waiting, ", ")),
+		}
+	}
+	return result
+}
+
+// FindCycle returns the names along a dependency cycle through d, starting
+// and ending with d, or nil if d is not part of a cycle.
+func FindCycle(d *workloadv1alpha1.WorkloadDistribution, lookup Lookup) []string {
+	visited := sets.New[string]()
+	var path []string
+
+	var visit func(name string, deps []workloadv1alpha1.DistributionDependency) bool
+	visit = func(name string, deps []workloadv1alpha1.DistributionDependency) bool {
+		path = append(path, name)
+		names := make([]string, 0, len(deps))
+		for _, dep := range deps {
+			names = append(names, dep.Name)
+		}
+		sort.Strings(names)
+		for _, next := range names {
+			if next == d.Name {
+				path = append(path, next)
+				return true
+			}
+			if visited.Has(next) {
+				continue
+			}
+			visited.Insert(next)
+			other, found := lookup(next)
+			if !found {
+				continue
+			}
+			if visit(next, other.Spec.DependsOn) {
+				return true
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+
+	if visit(d.Name, d.Spec.DependsOn) {
+		return path
+	}
+	return nil
+}
+
+func intersect(a, b sets.Set[string]) sets.Set[string] {
+	if a == nil {
+		return b
+	}
+	return a.Intersection(b)
+}
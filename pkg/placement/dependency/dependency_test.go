@@ -0,0 +1,106 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dependency
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+func distribution(name string, deps ...workloadv1alpha1.DistributionDependency) *workloadv1alpha1.WorkloadDistribution {
+	return &workloadv1alpha1.WorkloadDistribution{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec:       workloadv1alpha1.WorkloadDistributionSpec{DependsOn: deps},
+	}
+}
+
+func ready(d *workloadv1alpha1.WorkloadDistribution, targets ...workloadv1alpha1.TargetPlacement) *workloadv1alpha1.WorkloadDistribution {
+	d.Status.Targets = targets
+	d.Status.Conditions = conditionsv1alpha1.Conditions{{Type: workloadv1alpha1.WorkloadReady, Status: corev1.ConditionTrue}}
+	return d
+}
+
+func lookupIn(ds ...*workloadv1alpha1.WorkloadDistribution) Lookup {
+	return func(name string) (*workloadv1alpha1.WorkloadDistribution, bool) {
+		for _, d := range ds {
+			if d.Name == name {
+				return d, true
+			}
+		}
+		return nil, false
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	db := distribution("db")
+	cache := distribution("cache")
+	app := distribution("app",
+		workloadv1alpha1.DistributionDependency{Name: "db"},
+		workloadv1alpha1.DistributionDependency{Name: "cache", Colocation: workloadv1alpha1.ColocationSameLocation},
+	)
+
+	require.True(t, Evaluate(db, lookupIn()).Ready, "no dependencies")
+
+	result := Evaluate(app, lookupIn(db))
+	require.False(t, result.Ready)
+	require.Equal(t, workloadv1alpha1.DependencyNotFoundReason, result.Reason)
+	require.Contains(t, result.Message, "cache")
+
+	result = Evaluate(app, lookupIn(db, cache))
+	require.Equal(t, workloadv1alpha1.WaitingForDependenciesReason, result.Reason)
+	require.Contains(t, result.Message, "db, cache")
+
+	ready(db, workloadv1alpha1.TargetPlacement{SyncTarget: "eu-1", Location: "eu"}, workloadv1alpha1.TargetPlacement{SyncTarget: "us-1", Location: "us"})
+	ready(cache, workloadv1alpha1.TargetPlacement{SyncTarget: "eu-2", Location: "eu"})
+	result = Evaluate(app, lookupIn(db, cache))
+	require.True(t, result.Ready)
+	require.Equal(t, sets.New("eu-1", "us-1"), result.Targets)
+	require.Equal(t, sets.New("eu"), result.Locations)
+
+	require.True(t, result.Allows(&tmcv1alpha1.SyncTarget{ObjectMeta: metav1.ObjectMeta{Name: "eu-1"}, Spec: tmcv1alpha1.SyncTargetSpec{Location: "eu"}}))
+	require.False(t, result.Allows(&tmcv1alpha1.SyncTarget{ObjectMeta: metav1.ObjectMeta{Name: "us-1"}, Spec: tmcv1alpha1.SyncTargetSpec{Location: "us"}}))
+	require.False(t, result.Allows(&tmcv1alpha1.SyncTarget{ObjectMeta: metav1.ObjectMeta{Name: "eu-2"}, Spec: tmcv1alpha1.SyncTargetSpec{Location: "eu"}}))
+}
+
+func TestEvaluateDetectsCycles(t *testing.T) {
+	a := distribution("a", workloadv1alpha1.DistributionDependency{Name: "b"})
+	b := distribution("b", workloadv1alpha1.DistributionDependency{Name: "c"})
+	c := distribution("c", workloadv1alpha1.DistributionDependency{Name: "a"})
+	lookup := lookupIn(a, b, c)
+
+	require.Equal(t, []string{"a", "b", "c", "a"}, FindCycle(a, lookup))
+	result := Evaluate(a, lookup)
+	require.False(t, result.Ready)
+	require.Equal(t, workloadv1alpha1.DependencyCycleReason, result.Reason)
+	require.Contains(t, result.Message, "a -> b -> c -> a")
+
+	// Depending on a cycle is not being part of one.
+	d := distribution("d", workloadv1alpha1.DistributionDependency{Name: "a"})
+	require.Nil(t, FindCycle(d, lookupIn(a, b, c, d)))
+
+	self := distribution("self", workloadv1alpha1.DistributionDependency{Name: "self"})
+	require.Equal(t, []string{"self", "self"}, FindCycle(self, lookupIn(self)))
+}
@@ -0,0 +1,296 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
+
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// The benchmarks place workloads on generated fleets that resemble
+// production: targets spread over regions and zones, some cordoned, not
+// ready or in a disruption window, reporting capacity, costs, capabilities
+// and reachability, and a mix of policies using every filter and scorer.
+// Run them with
+//
+//	go test ./pkg/placement/engine -run '^$' -bench . -benchmem
+//
+// and compare runs with benchstat. -placement-profiles=<dir> writes a cpu
+// and a heap profile per benchmark to inspect with go tool pprof.
+
+var profileDir = flag.String("placement-profiles", "", "Directory to write a cpu and heap profile per placement benchmark to.")
+
+const (
+	benchmarkWorkloads = 10000
+	benchmarkSeed      = 42
+)
+
+var (
+	benchmarkNow     = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	benchmarkRegions = []string{"us-east", "us-west", "eu-west", "eu-central", "ap-south", "ap-northeast"}
+)
+
+// benchmarkFleet returns n SyncTargets, the same for the same n.
+func benchmarkFleet(n int) []*tmcv1alpha1.SyncTarget {
+	r := rand.New(rand.NewSource(benchmarkSeed))
+	fleet := make([]*tmcv1alpha1.SyncTarget, 0, n)
+	for i := range n {
+		region := benchmarkRegions[i%len(benchmarkRegions)]
+		t := syncTarget(fmt.Sprintf("cluster-%04d", i), region)
+		t.Labels["zone"] = fmt.Sprintf("%s-%c", region, 'a'+rune(r.Intn(3)))
+		t.Labels["tier"] = []string{"gold", "silver", "bronze"}[r.Intn(3)]
+		t.Annotations = map[string]string{tmcv1alpha1.AnnotationCost: fmt.Sprintf("%.2f", 0.5+r.Float64()*2)}
+		cpu := int64(64 + r.Intn(192))
+		t.Status.Capacity = &corev1.ResourceList{
+			corev1.ResourceCPU:    *resource.NewQuantity(cpu, resource.DecimalSI),
+			corev1.ResourceMemory: *resource.NewQuantity(cpu*4<<30, resource.BinarySI),
+		}
+		t.Status.Allocatable = &corev1.ResourceList{
+			corev1.ResourceCPU:    *resource.NewQuantity(r.Int63n(cpu), resource.DecimalSI),
+			corev1.ResourceMemory: *resource.NewQuantity(r.Int63n(cpu*4<<30), resource.BinarySI),
+		}
+		t.Status.KubernetesVersion = fmt.Sprintf("v1.%d.%d", 28+r.Intn(4), r.Intn(10))
+		t.Status.Capabilities = &tmcv1alpha1.SyncTargetCapabilities{
+			APIs: []string{"apps/v1", "batch/v1"},
+		}
+		if r.Intn(2) == 0 {
+			t.Status.Capabilities.APIs = append(t.Status.Capabilities.APIs, "gateway.networking.k8s.io/v1")
+		}
+		t.Status.Reachability = []tmcv1alpha1.EndpointReachability{
+			{Name: "database", Reachable: r.Intn(10) > 0},
+			{Name: "cache", Reachable: r.Intn(10) > 1},
+		}
+		switch p := r.Intn(100); {
+		case p < 2:
+			t.Spec.Unschedulable = true
+		case p < 4:
+			t.Status.Conditions = nil
+		case p < 5:
+			t.Spec.DisruptionWindow = &tmcv1alpha1.DisruptionWindow{End: metav1.NewTime(benchmarkNow.Add(time.Hour))}
+		}
+		fleet = append(fleet, t)
+	}
+	return fleet
+}
+
+// benchmarkRequests returns n placement requests for the fleet, the same
+// for the same arguments.
+func benchmarkRequests(n int, fleet []*tmcv1alpha1.SyncTarget) []Request {
+	r := rand.New(rand.NewSource(benchmarkSeed))
+	dataTargets := make([]string, 0, len(fleet)/10)
+	for i := 0; i < len(fleet); i += 10 {
+		dataTargets = append(dataTargets, fleet[i].Name)
+	}
+	dataLocations := map[string]*placementv1alpha1.DataLocationSpec{
+		"orders": {SyncTargets: dataTargets, Size: ptr.To(resource.MustParse("10Ti"))},
+	}
+
+	requests := make([]Request, 0, n)
+	for i := range n {
+		req := Request{SyncTargets: fleet}
+		region := benchmarkRegions[r.Intn(len(benchmarkRegions))]
+		switch p := r.Intn(100); {
+		case p < 40:
+			req.Policy = placementv1alpha1.PlacementPolicySpec{Strategy: placementv1alpha1.PlacementStrategySpread, NumberOfTargets: ptr.To[int32](3)}
+			req.Replicas = ptr.To(int32(1 + r.Intn(30)))
+		case p < 60:
+			req.Policy = placementv1alpha1.PlacementPolicySpec{
+				LocationSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"region": region}},
+			}
+		case p < 75:
+			req.Policy = placementv1alpha1.PlacementPolicySpec{Strategy: placementv1alpha1.PlacementStrategyHighAvailability, TopologyKey: "zone"}
+		case p < 85:
+			req.Policy = placementv1alpha1.PlacementPolicySpec{
+				Constraints: []placementv1alpha1.TargetConstraint{{Expression: `target.metadata.labels["tier"] != "bronze"`}},
+			}
+		case p < 95:
+			req.Policy = placementv1alpha1.PlacementPolicySpec{
+				Requirements: &placementv1alpha1.TargetRequirements{MinKubernetesVersion: "1.30", APIs: []string{"gateway.networking.k8s.io/v1"}},
+			}
+		default:
+			req.Policy = placementv1alpha1.PlacementPolicySpec{
+				DataAffinity:      []placementv1alpha1.DataAffinityTerm{{DataLocation: "orders", Type: placementv1alpha1.DataAffinityPreferred}},
+				RequiredEndpoints: []string{"database", "cache"},
+			}
+			req.DataLocations = dataLocations
+			req.PreferredLocations = []string{region}
+		}
+		// Most workloads are already placed and are placed again on changes.
+		if i%4 != 0 {
+			t := fleet[r.Intn(len(fleet))]
+			req.Current = []workloadv1alpha1.TargetPlacement{{SyncTarget: t.Name, Location: t.Spec.Location}}
+		}
+		requests = append(requests, req)
+	}
+	return requests
+}
+
+// profile writes a cpu profile of the benchmark until the returned function
+// is called, and a heap profile then, if -placement-profiles is set.
+func profile(b *testing.B) func() {
+	b.Helper()
+	if *profileDir == "" {
+		return func() {}
+	}
+	if err := os.MkdirAll(*profileDir, 0755); err != nil {
+		b.Fatal(err)
+	}
+	name := filepath.Join(*profileDir, strings.NewReplacer("/", "_", "=", "-").Replace(b.Name()))
+	cpu, err := os.Create(name + ".cpu.pprof")
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := pprof.StartCPUProfile(cpu); err != nil {
+		b.Fatal(err)
+	}
+	return func() {
+		pprof.StopCPUProfile()
+		if err := cpu.Close(); err != nil {
+			b.Error(err)
+		}
+		heap, err := os.Create(name + ".heap.pprof")
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer heap.Close()
+		runtime.GC()
+		if err := pprof.WriteHeapProfile(heap); err != nil {
+			b.Error(err)
+		}
+	}
+}
+
+// BenchmarkPlace measures placing single workloads, cycling through 10k
+// workloads, on fleets of increasing size. Besides time and allocations per
+// decision it reports the decisions per second and the p99 latency of a
+// decision.
+func BenchmarkPlace(b *testing.B) {
+	for _, size := range []int{100, 300, 1000} {
+		b.Run(fmt.Sprintf("targets=%d", size), func(b *testing.B) {
+			e := NewEngineWithClock(func() time.Time { return benchmarkNow })
+			requests := benchmarkRequests(benchmarkWorkloads, benchmarkFleet(size))
+			latencies := make([]time.Duration, b.N)
+
+			stop := profile(b)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := range b.N {
+				start := time.Now()
+				if _, err := e.Place(requests[i%len(requests)]); err != nil && err != ErrNoFeasibleTargets {
+					b.Fatal(err)
+				}
+				latencies[i] = time.Since(start)
+			}
+			b.StopTimer()
+			stop()
+
+			slices.Sort(latencies)
+			b.ReportMetric(float64(latencies[len(latencies)*99/100].Nanoseconds()), "p99-ns/decision")
+			b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "decisions/s")
+		})
+	}
+}
+
+// BenchmarkFilter measures filtering 1000 targets for a policy using every
+// filter, the hot path of every decision.
+func BenchmarkFilter(b *testing.B) {
+	e := NewEngineWithClock(func() time.Time { return benchmarkNow })
+	fleet := benchmarkFleet(1000)
+	tc := placementv1alpha1.TargetConstraint{Expression: `target.metadata.labels["tier"] != "bronze"`}
+	compiled, err := e.constraints.Compile(tc.Expression)
+	if err != nil {
+		b.Fatal(err)
+	}
+	constraint := constraintFilter(tc, compiled)
+	requirements, err := requirementsFilter(&placementv1alpha1.TargetRequirements{MinKubernetesVersion: "1.30", APIs: []string{"gateway.networking.k8s.io/v1"}})
+	if err != nil {
+		b.Fatal(err)
+	}
+	filters := []Filter{e.schedulable, ready, constraint, requirements}
+
+	stop := profile(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		for _, syncTarget := range fleet {
+			filter(syncTarget, filters)
+		}
+	}
+	b.StopTimer()
+	stop()
+}
+
+// BenchmarkScore measures scoring 1000 feasible targets with every scorer.
+func BenchmarkScore(b *testing.B) {
+	fleet := benchmarkFleet(1000)
+	data := []dataWeight{{size: 1 << 40, syncTargets: sets.New(fleet[0].Name, fleet[10].Name)}}
+	weights := Weights(nil)
+
+	stop := profile(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		score(fleet, weights, sets.New("us-east"), data, []string{"database", "cache"}, NewTaintEvaluator(nil, time.Now()), &WorkloadAffinityEvaluator{}, nil, nil)
+	}
+	b.StopTimer()
+	stop()
+}
+
+// TestPlaceAllocationBudget fails when placing a workload on a fleet of
+// 1000 targets allocates considerably more than it used to. Allocations are
+// deterministic, unlike timings, so this catches regressions in the
+// filtering and scoring hot paths in every test run. Raise the budget
+// deliberately when a feature needs the allocations.
+func TestPlaceAllocationBudget(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping allocation budget in short mode")
+	}
+	const budget = 30000
+
+	e := NewEngineWithClock(func() time.Time { return benchmarkNow })
+	requests := benchmarkRequests(100, benchmarkFleet(1000))
+	i := 0
+	allocs := testing.AllocsPerRun(len(requests), func() {
+		if _, err := e.Place(requests[i%len(requests)]); err != nil && err != ErrNoFeasibleTargets {
+			t.Fatal(err)
+		}
+		i++
+	})
+	t.Logf("%.0f allocations per decision", allocs)
+	if allocs > budget {
+		t.Errorf("placing a workload on 1000 targets takes %.0f allocations, more than the budget of %d", allocs, budget)
+	}
+}
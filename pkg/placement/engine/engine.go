@@ -0,0 +1,701 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package engine chooses the SyncTargets a workload is placed on according
+// to a PlacementPolicy.
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/utils/ptr"
+
+	"github.com/kcp-dev/kcp/pkg/placement/constraint"
+	"github.com/kcp-dev/kcp/pkg/placement/framework"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// ErrNoFeasibleTargets is returned if no SyncTarget passes the filters.
+var ErrNoFeasibleTargets = errors.New("no feasible SyncTargets")
+
+// Filter rejects a SyncTarget with a reason, or returns an empty string if
+// the target is feasible.
+type Filter func(syncTarget *tmcv1alpha1.SyncTarget) string
+
+// Request is the input of a placement decision.
+type Request struct {
+	// Policy is the placement policy spec to apply.
+	Policy placementv1alpha1.PlacementPolicySpec
+	// SyncTargets are the candidate targets in the workspace.
+	SyncTargets []*tmcv1alpha1.SyncTarget
+	// Current are the targets the workload is placed on today. Placement
+	// prefers them to avoid moving workloads.
+	Current []workloadv1alpha1.TargetPlacement
+	// Displaced are targets the workload was moved off because of their
+	// disruption window. Placement prefers them once the window has passed.
+	Displaced []string
+	// Replicas of the workload to divide over the chosen targets, or nil if
+	// the workload is not scalable.
+	Replicas *int32
+	// Filters are applied in addition to the policy filters.
+	Filters []Filter
+	// Profile is the SchedulingProfile of the workspace, or nil for the
+	// default profile.
+	Profile *placementv1alpha1.SchedulingProfileSpec
+	// PreferredLocations are preferred by the Locality scorer in addition to
+	// the locations of the current targets, e.g. those of dependencies.
+	PreferredLocations []string
+	// Group is the SyncTargetGroup the policy targets, or nil. Only its
+	// members are feasible, and replicas are split by their weights.
+	Group *tmcv1alpha1.SyncTargetGroupSpec
+	// DataLocations are the DataLocations referenced by the data affinity
+	// terms of the policy, by name. Missing ones host data nowhere.
+	DataLocations map[string]*placementv1alpha1.DataLocationSpec
+	// Workload is the workload to place. Workload affinity terms default to
+	// its namespace and never select it.
+	Workload PlacedWorkload
+	// Placements are the workloads placed on the candidate SyncTargets, for
+	// the workload affinity terms of the policy.
+	Placements PlacementIndex
+}
+
+// Decision is the outcome of a placement.
+type Decision struct {
+	// Targets are the chosen targets in order of preference.
+	Targets []workloadv1alpha1.TargetPlacement
+	// Rejected maps names of infeasible SyncTargets to the reason.
+	Rejected map[string]string
+	// Displaced are current or displaced targets that are infeasible
+	// because of an open disruption window.
+	Displaced []string
+	// RecheckAfter is when the next disruption window of a candidate opens
+	// or closes, its simulated failure ends, or the toleration of a NoExecute
+	// taint of its cells expires, or zero if there is none.
+	RecheckAfter time.Duration
+	// Scores maps names of feasible SyncTargets to their weighted score from
+	// 0 to 100.
+	Scores map[string]int
+	// ScorerScores maps the names of the scorers that applied to the scores
+	// from 0 to 100 they gave the feasible SyncTargets, before weighting.
+	ScorerScores map[string]map[string]int
+	// Emissions estimates the carbon intensity of the chosen targets
+	// relative to the feasible ones, or is nil if they do not report their
+	// carbon intensity.
+	Emissions *EmissionsEstimate
+}
+
+// constraintCacheSize bounds the compiled CEL constraints an engine keeps.
+const constraintCacheSize = 1024
+
+// Engine chooses SyncTargets for workloads.
+type Engine struct {
+	now     func() time.Time
+	plugins *framework.Registry
+	// constraints caches the compiled CEL constraints of policies.
+	constraints *constraint.Cache
+}
+
+// NewEngine returns a placement engine.
+func NewEngine() *Engine {
+	return NewEngineWithClock(time.Now)
+}
+
+// NewEngineWithClock returns a placement engine that evaluates disruption
+// windows at the time returned by now.
+func NewEngineWithClock(now func() time.Time) *Engine {
+	return &Engine{now: now, plugins: framework.DefaultRegistry, constraints: constraint.NewCache(constraintCacheSize)}
+}
+
+// WithPlugins sets the registry the scheduler plugins selected by policies
+// are looked up in, framework.DefaultRegistry by default.
+func (e *Engine) WithPlugins(registry *framework.Registry) *Engine {
+	e.plugins = registry
+	return e
+}
+
+// Place filters and scores the candidate SyncTargets of the request and
+// chooses as many as the policy strategy asks for. Current targets are
+// preferred over better scored ones to avoid moving workloads.
+func (e *Engine) Place(req Request) (Decision, error) {
+	selector := labels.Everything()
+	if req.Policy.LocationSelector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(req.Policy.LocationSelector); err != nil {
+			return Decision{}, fmt.Errorf("invalid location selector: %w", err)
+		}
+	}
+	filters := []Filter{
+		e.schedulable,
+		ready,
+		func(syncTarget *tmcv1alpha1.SyncTarget) string {
+			if !selector.Matches(labels.Set(syncTarget.Labels)) {
+				return "does not match the location selector"
+			}
+			return ""
+		},
+	}
+	if group := req.Group; group != nil {
+		filters = append(filters, func(syncTarget *tmcv1alpha1.SyncTarget) string {
+			if _, member := group.Weight(syncTarget.Name); !member {
+				return fmt.Sprintf("is not a member of SyncTargetGroup %q", req.Policy.SyncTargetGroup)
+			}
+			return ""
+		})
+	}
+	if key := req.Policy.TopologyKey; key != "" {
+		filters = append(filters, func(syncTarget *tmcv1alpha1.SyncTarget) string {
+			if _, found := syncTarget.Labels[key]; !found {
+				return fmt.Sprintf("has no topology label %q", key)
+			}
+			return ""
+		})
+	}
+	spread := NewTopologySpreadEvaluator(req.Policy.TopologySpreadConstraints)
+	if len(req.Policy.TopologySpreadConstraints) > 0 {
+		filters = append(filters, spread.Filter)
+	}
+	affinity, err := NewWorkloadAffinityEvaluator(req.Policy.WorkloadAffinity, req.Workload, req.SyncTargets, req.Placements)
+	if err != nil {
+		return Decision{}, err
+	}
+	if len(req.Policy.WorkloadAffinity) > 0 {
+		filters = append(filters, affinity.Filter)
+	}
+	var data []dataWeight
+	for _, term := range req.Policy.DataAffinity {
+		d := dataWeight{size: placementv1alpha1.DefaultDataSize.Value(), syncTargets: sets.New[string]()}
+		if location, found := req.DataLocations[term.DataLocation]; found {
+			d.syncTargets.Insert(location.SyncTargets...)
+			if location.Size != nil {
+				d.size = location.Size.Value()
+			}
+		}
+		if term.Type == placementv1alpha1.DataAffinityRequired {
+			filters = append(filters, func(syncTarget *tmcv1alpha1.SyncTarget) string {
+				if !d.syncTargets.Has(syncTarget.Name) {
+					return fmt.Sprintf("does not host the data of DataLocation %q", term.DataLocation)
+				}
+				return ""
+			})
+			continue
+		}
+		data = append(data, d)
+	}
+	var preferred []weightedConstraint
+	for i, tc := range req.Policy.Constraints {
+		c, err := e.constraints.Compile(tc.Expression)
+		if err != nil {
+			return Decision{}, fmt.Errorf("invalid constraint %d: %w", i, err)
+		}
+		if tc.Type == placementv1alpha1.ConstraintPreferred {
+			preferred = append(preferred, weightedConstraint{constraint: c, weight: max(1, int(tc.Weight))})
+			continue
+		}
+		filters = append(filters, constraintFilter(tc, c))
+	}
+	if r := req.Policy.Requirements; r != nil {
+		f, err := requirementsFilter(r)
+		if err != nil {
+			return Decision{}, fmt.Errorf("invalid requirements: %w", err)
+		}
+		filters = append(filters, f)
+	}
+	strategy := Strategy(req.Policy, req.Profile)
+	var locationWeights map[string]int32
+	if strategy == placementv1alpha1.PlacementStrategyWeightedSpread {
+		locationWeights = make(map[string]int32, len(req.Policy.LocationWeights))
+		for _, lw := range req.Policy.LocationWeights {
+			locationWeights[lw.Location] = lw.Weight
+		}
+		filters = append(filters, func(syncTarget *tmcv1alpha1.SyncTarget) string {
+			if locationWeights[syncTarget.Spec.Location] <= 0 {
+				return fmt.Sprintf("location %q has no weight", syncTarget.Spec.Location)
+			}
+			return ""
+		})
+	}
+	plugins, err := e.plugins.Profile(schedulerProfile(req.Policy))
+	if err != nil {
+		return Decision{}, err
+	}
+	for _, p := range plugins.Filters {
+		filters = append(filters, func(syncTarget *tmcv1alpha1.SyncTarget) string {
+			return p.Filter(&req.Policy, syncTarget)
+		})
+	}
+	filters = append(filters, req.Filters...)
+
+	current := map[string]bool{}
+	preferredLocations := sets.New(req.PreferredLocations...)
+	for _, t := range req.Current {
+		current[t.SyncTarget] = true
+		if t.Location != "" {
+			preferredLocations.Insert(t.Location)
+		}
+	}
+	displaced := map[string]bool{}
+	for _, name := range req.Displaced {
+		displaced[name] = true
+	}
+	// Spread workloads tolerate the disruption of one of their targets, the
+	// others must keep their minimum number of targets available.
+	keepDuringDisruption := strategy == placementv1alpha1.PlacementStrategySpread || strategy == placementv1alpha1.PlacementStrategyWeightedSpread
+
+	now := e.now()
+	taints := NewTaintEvaluator(req.Policy.Tolerations, now)
+	decision := Decision{Rejected: map[string]string{}}
+	var feasible []*tmcv1alpha1.SyncTarget
+	for _, syncTarget := range req.SyncTargets {
+		if reason := filter(syncTarget, filters); reason != "" {
+			decision.Rejected[syncTarget.Name] = reason
+			continue
+		}
+		reason, until := taints.Evaluate(syncTarget, current[syncTarget.Name])
+		if reason != "" {
+			decision.Rejected[syncTarget.Name] = reason
+			continue
+		}
+		if d := until.Sub(now); !until.IsZero() && (decision.RecheckAfter == 0 || d < decision.RecheckAfter) {
+			decision.RecheckAfter = d
+		}
+		if until, found := simulatedFailure(syncTarget); found && now.Before(until) {
+			decision.Rejected[syncTarget.Name] = fmt.Sprintf("has a simulated failure until %s", until.UTC().Format(time.RFC3339))
+			if d := until.Sub(now); decision.RecheckAfter == 0 || d < decision.RecheckAfter {
+				decision.RecheckAfter = d
+			}
+			continue
+		}
+		// Workloads stay on targets that are cordoned, paused or reached
+		// their guardrails, but no new ones are added.
+		if reason := schedulingDisabled(syncTarget); reason != "" && !current[syncTarget.Name] {
+			decision.Rejected[syncTarget.Name] = reason
+			continue
+		}
+		if window := syncTarget.Spec.DisruptionWindow; window != nil {
+			decision.RecheckAfter = nextRecheck(decision.RecheckAfter, window, now)
+			if window.IsOpen(now) && !(keepDuringDisruption && current[syncTarget.Name]) {
+				decision.Rejected[syncTarget.Name] = fmt.Sprintf("is in a disruption window until %s", window.End.UTC().Format(time.RFC3339))
+				if current[syncTarget.Name] || displaced[syncTarget.Name] {
+					decision.Displaced = append(decision.Displaced, syncTarget.Name)
+				}
+				continue
+			}
+		}
+		feasible = append(feasible, syncTarget)
+	}
+	sort.Strings(decision.Displaced)
+	if len(feasible) == 0 {
+		return decision, ErrNoFeasibleTargets
+	}
+
+	rank := func(name string) int {
+		switch {
+		case displaced[name]:
+			return 0
+		case current[name]:
+			return 1
+		default:
+			return 2
+		}
+	}
+	decision.Scores, decision.ScorerScores = score(feasible, Weights(req.Profile), preferredLocations, data, req.Policy.RequiredEndpoints, taints, affinity, preferred, pluginScorers(&req.Policy, plugins.Scores))
+	sort.SliceStable(feasible, func(i, j int) bool {
+		if ri, rj := rank(feasible[i].Name), rank(feasible[j].Name); ri != rj {
+			return ri < rj
+		}
+		// Capacity that exists is used before capacity to provision.
+		if pi, pj := provisionable(feasible[i]), provisionable(feasible[j]); pi != pj {
+			return pj
+		}
+		if si, sj := decision.Scores[feasible[i].Name], decision.Scores[feasible[j].Name]; si != sj {
+			return si > sj
+		}
+		return feasible[i].Name < feasible[j].Name
+	})
+
+	var chosen []*tmcv1alpha1.SyncTarget
+	switch strategy {
+	case placementv1alpha1.PlacementStrategySingleton:
+		chosen = feasible[:1]
+	case placementv1alpha1.PlacementStrategyHighAvailability:
+		// Failure domains are used round-robin, within the spread constraints.
+		candidates := distinctDomains(feasible, len(feasible), topologyDomain(req.Policy.TopologyKey))
+		chosen = spread.Choose(candidates, max(2, int(ptr.Deref(req.Policy.NumberOfTargets, 2))))
+	default:
+		n := len(feasible)
+		if req.Policy.NumberOfTargets != nil {
+			n = min(n, int(*req.Policy.NumberOfTargets))
+		}
+		chosen = spread.Choose(feasible, n)
+	}
+
+	weights := make([]int32, len(chosen))
+	for i, syncTarget := range chosen {
+		weights[i] = 1
+		if req.Group != nil {
+			weights[i], _ = req.Group.Weight(syncTarget.Name)
+		}
+	}
+	var shares []int32
+	if req.Replicas != nil {
+		if locationWeights != nil {
+			shares = splitByLocation(*req.Replicas, chosen, weights, locationWeights)
+		} else {
+			shares = splitReplicas(*req.Replicas, weights)
+		}
+	}
+	for i, syncTarget := range chosen {
+		t := workloadv1alpha1.TargetPlacement{SyncTarget: syncTarget.Name, Location: syncTarget.Spec.Location}
+		if shares != nil {
+			t.Replicas = ptr.To(shares[i])
+		}
+		decision.Targets = append(decision.Targets, t)
+	}
+	decision.Emissions = estimateEmissions(decision.Targets, feasible)
+	return decision, nil
+}
+
+// Bind runs the bind plugins selected by the policy of the request on the
+// targets of the decision, in order, and stops at the first error.
+func (e *Engine) Bind(ctx context.Context, workload framework.Workload, req Request, decision Decision) error {
+	plugins, err := e.plugins.Profile(schedulerProfile(req.Policy))
+	if err != nil {
+		return err
+	}
+	for _, p := range plugins.Binds {
+		if err := p.Bind(ctx, workload, decision.Targets); err != nil {
+			return fmt.Errorf("scheduler plugin %q failed to bind: %w", p.Name(), err)
+		}
+	}
+	return nil
+}
+
+// schedulerProfile returns the scheduler profile of the policy, followed by
+// the plugin of its extender, if any.
+func schedulerProfile(policy placementv1alpha1.PlacementPolicySpec) *placementv1alpha1.SchedulerProfile {
+	if policy.Extender == nil {
+		return policy.SchedulerProfile
+	}
+	profile := &placementv1alpha1.SchedulerProfile{}
+	if policy.SchedulerProfile != nil {
+		profile.Plugins = slices.Clone(policy.SchedulerProfile.Plugins)
+	}
+	profile.Plugins = append(profile.Plugins, placementv1alpha1.SchedulerPlugin{
+		Name:   framework.ExtenderPluginName(policy.Extender.Name),
+		Weight: policy.Extender.Weight,
+	})
+	return profile
+}
+
+// splitReplicas splits replicas in proportion to the weights. Replicas left
+// over by rounding down go to the largest remainders, ties to the earlier,
+// preferred targets.
+func splitReplicas(replicas int32, weights []int32) []int32 {
+	var total int64
+	for _, w := range weights {
+		total += int64(w)
+	}
+	shares := make([]int32, len(weights))
+	if total == 0 {
+		return shares
+	}
+	remainders := make([]int64, len(weights))
+	left := replicas
+	for i, w := range weights {
+		exact := int64(replicas) * int64(w)
+		shares[i] = int32(exact / total)
+		remainders[i] = exact % total
+		left -= shares[i]
+	}
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return remainders[order[i]] > remainders[order[j]] })
+	for _, i := range order[:left] {
+		shares[i]++
+	}
+	return shares
+}
+
+// splitByLocation splits replicas between the locations of the targets in
+// proportion to the location weights, then the replicas of each location
+// between its targets in proportion to their weights.
+func splitByLocation(replicas int32, targets []*tmcv1alpha1.SyncTarget, weights []int32, locationWeights map[string]int32) []int32 {
+	var locations []string
+	members := map[string][]int{}
+	for i, syncTarget := range targets {
+		location := syncTarget.Spec.Location
+		if _, found := members[location]; !found {
+			locations = append(locations, location)
+		}
+		members[location] = append(members[location], i)
+	}
+	perLocation := make([]int32, len(locations))
+	for i, location := range locations {
+		perLocation[i] = locationWeights[location]
+	}
+
+	shares := make([]int32, len(targets))
+	for i, share := range splitReplicas(replicas, perLocation) {
+		indexes := members[locations[i]]
+		memberWeights := make([]int32, len(indexes))
+		for j, index := range indexes {
+			memberWeights[j] = weights[index]
+		}
+		for j, s := range splitReplicas(share, memberWeights) {
+			shares[indexes[j]] = s
+		}
+	}
+	return shares
+}
+
+// Strategy returns the strategy of the policy, defaulted by the profile.
+func Strategy(policy placementv1alpha1.PlacementPolicySpec, profile *placementv1alpha1.SchedulingProfileSpec) placementv1alpha1.PlacementStrategy {
+	switch {
+	case policy.Strategy != "":
+		return policy.Strategy
+	case profile != nil && profile.DefaultStrategy != "":
+		return profile.DefaultStrategy
+	default:
+		return placementv1alpha1.PlacementStrategySpread
+	}
+}
+
+func filter(syncTarget *tmcv1alpha1.SyncTarget, filters []Filter) string {
+	for _, f := range filters {
+		if reason := f(syncTarget); reason != "" {
+			return reason
+		}
+	}
+	return ""
+}
+
+func (e *Engine) schedulable(syncTarget *tmcv1alpha1.SyncTarget) string {
+	if !syncTarget.DeletionTimestamp.IsZero() {
+		return "is being deleted"
+	}
+	if r := syncTarget.Spec.Registration; r != nil && !r.Approved {
+		return "is pending registration approval"
+	}
+	// Whether workloads tolerate the taints a SyncTarget is evicted for is
+	// up to the TaintEvaluator.
+	if now := e.now(); syncTarget.Spec.EvictAfter != nil && !now.Before(syncTarget.Spec.EvictAfter.Time) && !evictedForTaints(syncTarget, now) {
+		return "is being evicted"
+	}
+	return ""
+}
+
+func ready(syncTarget *tmcv1alpha1.SyncTarget) string {
+	switch {
+	case conditions.IsTrue(syncTarget, tmcv1alpha1.ObserveOnly):
+		return "syncer is observe-only"
+	case conditions.IsTrue(syncTarget, tmcv1alpha1.SyncerReady):
+		return ""
+	case syncTarget.Spec.Provisioning == nil:
+		return "syncer is not ready"
+	case conditions.GetReason(syncTarget, tmcv1alpha1.Provisioned) == tmcv1alpha1.ProvisioningFailedReason:
+		return "failed to provision: " + conditions.GetMessage(syncTarget, tmcv1alpha1.Provisioned)
+	}
+	// Placing on a provisionable target requests its capacity.
+	return ""
+}
+
+// provisionable returns whether the target is a placeholder for capacity that
+// is not provisioned yet.
+func provisionable(syncTarget *tmcv1alpha1.SyncTarget) bool {
+	return syncTarget.Spec.Provisioning != nil && !conditions.IsTrue(syncTarget, tmcv1alpha1.SyncerReady)
+}
+
+func schedulingDisabled(syncTarget *tmcv1alpha1.SyncTarget) string {
+	if syncTarget.Spec.Unschedulable {
+		return "is unschedulable"
+	}
+	if syncTarget.Spec.Paused {
+		return "is paused"
+	}
+	if violations := syncTarget.Spec.Guardrails.Violations(syncTarget.Status.Usage); len(violations) > 0 {
+		return "exceeds its guardrails: " + strings.Join(violations, ", ")
+	}
+	if conditions.IsTrue(syncTarget, tmcv1alpha1.SchedulingDisabled) {
+		return "has scheduling disabled: " + conditions.GetMessage(syncTarget, tmcv1alpha1.SchedulingDisabled)
+	}
+	return ""
+}
+
+// nextRecheck returns the earlier of after and the time until the window
+// next opens or closes.
+// simulatedFailure returns until when a failure of the SyncTarget is
+// simulated. Invalid times are ignored.
+func simulatedFailure(syncTarget *tmcv1alpha1.SyncTarget) (time.Time, bool) {
+	value, found := syncTarget.Annotations[tmcv1alpha1.AnnotationSimulatedFailureUntil]
+	if !found {
+		return time.Time{}, false
+	}
+	until, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return until, true
+}
+
+func nextRecheck(after time.Duration, window *tmcv1alpha1.DisruptionWindow, now time.Time) time.Duration {
+	next := window.End.Time
+	if window.Start != nil && now.Before(window.Start.Time) {
+		next = window.Start.Time
+	}
+	d := next.Sub(now)
+	if d <= 0 || (after > 0 && after < d) {
+		return after
+	}
+	return d
+}
+
+// topologyDomain returns the failure domain of a SyncTarget, which is its
+// location unless the policy has a topology key.
+func topologyDomain(key string) func(*tmcv1alpha1.SyncTarget) string {
+	if key == "" {
+		return func(syncTarget *tmcv1alpha1.SyncTarget) string { return syncTarget.Spec.Location }
+	}
+	return func(syncTarget *tmcv1alpha1.SyncTarget) string { return syncTarget.Labels[key] }
+}
+
+// distinctDomains picks n targets in preference order, using a domain again
+// only once every domain is used.
+func distinctDomains(feasible []*tmcv1alpha1.SyncTarget, n int, domain func(*tmcv1alpha1.SyncTarget) string) []*tmcv1alpha1.SyncTarget {
+	n = min(n, len(feasible))
+	chosen := make([]*tmcv1alpha1.SyncTarget, 0, n)
+	picked := map[string]bool{}
+	for len(chosen) < n {
+		domains := map[string]bool{}
+		for _, syncTarget := range feasible {
+			if len(chosen) == n {
+				break
+			}
+			if picked[syncTarget.Name] || domains[domain(syncTarget)] {
+				continue
+			}
+			picked[syncTarget.Name] = true
+			domains[domain(syncTarget)] = true
+			chosen = append(chosen, syncTarget)
+		}
+	}
+	return chosen
+}
+
+// constraintFilter returns a filter rejecting SyncTargets that do not
+// satisfy the compiled CEL constraint tc.
+func constraintFilter(tc placementv1alpha1.TargetConstraint, c *constraint.Constraint) Filter {
+	message := tc.Message
+	if message == "" {
+		message = tc.Expression
+	}
+	name := ""
+	if tc.Name != "" {
+		name = fmt.Sprintf(" %q", tc.Name)
+	}
+	return func(syncTarget *tmcv1alpha1.SyncTarget) string {
+		matches, err := c.Matches(syncTarget)
+		if err != nil {
+			return err.Error()
+		}
+		if !matches {
+			return fmt.Sprintf("does not satisfy constraint%s: %s", name, message)
+		}
+		return ""
+	}
+}
+
+// requirementsFilter returns a filter rejecting SyncTargets that lack a
+// required capability.
+func requirementsFilter(r *placementv1alpha1.TargetRequirements) (Filter, error) {
+	var minVersion *version.Version
+	if r.MinKubernetesVersion != "" {
+		var err error
+		if minVersion, err = version.ParseGeneric(r.MinKubernetesVersion); err != nil {
+			return nil, fmt.Errorf("invalid minimum Kubernetes version: %w", err)
+		}
+	}
+	return func(syncTarget *tmcv1alpha1.SyncTarget) string {
+		if minVersion != nil {
+			v, err := version.ParseGeneric(syncTarget.Status.KubernetesVersion)
+			if err != nil {
+				return "has not reported a valid Kubernetes version"
+			}
+			if v.LessThan(minVersion) {
+				return fmt.Sprintf("runs Kubernetes %s, older than %s", syncTarget.Status.KubernetesVersion, r.MinKubernetesVersion)
+			}
+		}
+		if reason := allocatable(syncTarget, r.Resources); reason != "" {
+			return reason
+		}
+		if len(r.FeatureGates) == 0 && len(r.APIs) == 0 && len(r.Addons) == 0 {
+			return ""
+		}
+		capabilities := syncTarget.Status.Capabilities
+		if capabilities == nil {
+			return "has not reported its capabilities"
+		}
+		if missing := sets.List(sets.New(r.FeatureGates...).Difference(sets.New(capabilities.FeatureGates...))); len(missing) > 0 {
+			return "lacks feature gates " + strings.Join(missing, ", ")
+		}
+		if missing := sets.List(sets.New(r.APIs...).Difference(sets.New(capabilities.APIs...))); len(missing) > 0 {
+			return "does not serve APIs " + strings.Join(missing, ", ")
+		}
+		if missing := sets.List(sets.New(r.Addons...).Difference(sets.New(capabilities.Addons...))); len(missing) > 0 {
+			return "lacks addons " + strings.Join(missing, ", ")
+		}
+		return ""
+	}, nil
+}
+
+// allocatable returns why the allocatable resources of syncTarget do not
+// cover required, or an empty string if they do.
+func allocatable(syncTarget *tmcv1alpha1.SyncTarget, required corev1.ResourceList) string {
+	if len(required) == 0 {
+		return ""
+	}
+	if syncTarget.Status.Allocatable == nil {
+		return "has not reported its allocatable resources"
+	}
+	names := make([]string, 0, len(required))
+	for name := range required {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		want := required[corev1.ResourceName(name)]
+		got := (*syncTarget.Status.Allocatable)[corev1.ResourceName(name)]
+		if got.Cmp(want) < 0 {
+			return fmt.Sprintf("has %s of %s allocatable, less than %s", got.String(), name, want.String())
+		}
+	}
+	return ""
+}
@@ -0,0 +1,831 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/kcp-dev/kcp/pkg/placement/framework"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+func syncTarget(name, location string) *tmcv1alpha1.SyncTarget {
+	return &tmcv1alpha1.SyncTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"region": location}},
+		Spec:       tmcv1alpha1.SyncTargetSpec{Location: location},
+		Status: tmcv1alpha1.SyncTargetStatus{
+			Conditions: conditionsv1alpha1.Conditions{{Type: tmcv1alpha1.SyncerReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func names(targets []workloadv1alpha1.TargetPlacement) []string {
+	var out []string
+	for _, t := range targets {
+		out = append(out, t.SyncTarget)
+	}
+	return out
+}
+
+func TestPlaceFilters(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := &Engine{now: func() time.Time { return now }}
+
+	cordoned := syncTarget("cordoned", "eu")
+	cordoned.Spec.Unschedulable = true
+	evicted := syncTarget("evicted", "eu")
+	evicted.Spec.EvictAfter = &metav1.Time{Time: now.Add(-time.Minute)}
+	notReady := syncTarget("not-ready", "eu")
+	notReady.Status.Conditions = nil
+	deleting := syncTarget("deleting", "eu")
+	deleting.DeletionTimestamp = &metav1.Time{Time: now}
+	pending := syncTarget("pending", "eu")
+	pending.Spec.Registration = &tmcv1alpha1.SyncTargetRegistration{PublicKey: "key"}
+	observer := syncTarget("observer", "eu")
+	observer.Status.Conditions = append(observer.Status.Conditions, conditionsv1alpha1.Condition{Type: tmcv1alpha1.ObserveOnly, Status: corev1.ConditionTrue})
+
+	decision, err := e.Place(Request{
+		Policy: placementv1alpha1.PlacementPolicySpec{
+			LocationSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"region": "eu"}},
+		},
+		SyncTargets: []*tmcv1alpha1.SyncTarget{cordoned, evicted, notReady, deleting, pending, observer, syncTarget("us-1", "us"), syncTarget("eu-1", "eu")},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-1"}, names(decision.Targets))
+	require.Equal(t, map[string]string{
+		"cordoned":  "is unschedulable",
+		"deleting":  "is being deleted",
+		"evicted":   "is being evicted",
+		"not-ready": "syncer is not ready",
+		"observer":  "syncer is observe-only",
+		"pending":   "is pending registration approval",
+		"us-1":      "does not match the location selector",
+	}, decision.Rejected)
+
+	_, err = e.Place(Request{
+		SyncTargets: []*tmcv1alpha1.SyncTarget{syncTarget("eu-1", "eu")},
+		Filters:     []Filter{func(*tmcv1alpha1.SyncTarget) string { return "is excluded" }},
+	})
+	require.ErrorIs(t, err, ErrNoFeasibleTargets)
+}
+
+func TestPlaceStrategies(t *testing.T) {
+	e := NewEngine()
+	targets := []*tmcv1alpha1.SyncTarget{syncTarget("eu-1", "eu"), syncTarget("eu-2", "eu"), syncTarget("us-1", "us")}
+
+	decision, err := e.Place(Request{
+		Policy:      placementv1alpha1.PlacementPolicySpec{Strategy: placementv1alpha1.PlacementStrategySingleton},
+		SyncTargets: targets,
+		Current:     []workloadv1alpha1.TargetPlacement{{SyncTarget: "us-1"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"us-1"}, names(decision.Targets), "current targets are preferred")
+
+	decision, err = e.Place(Request{
+		Policy:      placementv1alpha1.PlacementPolicySpec{Strategy: placementv1alpha1.PlacementStrategyHighAvailability},
+		SyncTargets: targets,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-1", "us-1"}, names(decision.Targets), "locations are distinct")
+
+	decision, err = e.Place(Request{
+		Policy:      placementv1alpha1.PlacementPolicySpec{Strategy: placementv1alpha1.PlacementStrategySpread},
+		SyncTargets: targets,
+		Replicas:    ptr.To[int32](5),
+	})
+	require.NoError(t, err)
+	require.Equal(t, []workloadv1alpha1.TargetPlacement{
+		{SyncTarget: "eu-1", Location: "eu", Replicas: ptr.To[int32](2)},
+		{SyncTarget: "eu-2", Location: "eu", Replicas: ptr.To[int32](2)},
+		{SyncTarget: "us-1", Location: "us", Replicas: ptr.To[int32](1)},
+	}, decision.Targets)
+}
+
+func TestPlaceWeightedSpread(t *testing.T) {
+	e := NewEngine()
+	targets := []*tmcv1alpha1.SyncTarget{syncTarget("eu-1", "eu"), syncTarget("eu-2", "eu"), syncTarget("us-1", "us"), syncTarget("ap-1", "ap")}
+	policy := placementv1alpha1.PlacementPolicySpec{
+		Strategy: placementv1alpha1.PlacementStrategyWeightedSpread,
+		LocationWeights: []placementv1alpha1.LocationWeight{
+			{Location: "eu", Weight: 3},
+			{Location: "us", Weight: 1},
+			{Location: "ap", Weight: 0},
+		},
+	}
+
+	decision, err := e.Place(Request{Policy: policy, SyncTargets: targets, Replicas: ptr.To[int32](8)})
+	require.NoError(t, err)
+	require.Equal(t, []workloadv1alpha1.TargetPlacement{
+		{SyncTarget: "eu-1", Location: "eu", Replicas: ptr.To[int32](3)},
+		{SyncTarget: "eu-2", Location: "eu", Replicas: ptr.To[int32](3)},
+		{SyncTarget: "us-1", Location: "us", Replicas: ptr.To[int32](2)},
+	}, decision.Targets, "replicas are split by location weight, then evenly in a location")
+	require.Equal(t, `location "ap" has no weight`, decision.Rejected["ap-1"])
+
+	notReady := syncTarget("us-1", "us")
+	notReady.Status.Conditions = nil
+	decision, err = e.Place(Request{
+		Policy:      policy,
+		SyncTargets: []*tmcv1alpha1.SyncTarget{targets[0], targets[1], notReady, targets[3]},
+		Current:     decision.Targets,
+		Replicas:    ptr.To[int32](8),
+	})
+	require.NoError(t, err)
+	require.Equal(t, []workloadv1alpha1.TargetPlacement{
+		{SyncTarget: "eu-1", Location: "eu", Replicas: ptr.To[int32](4)},
+		{SyncTarget: "eu-2", Location: "eu", Replicas: ptr.To[int32](4)},
+	}, decision.Targets, "the replicas of an unavailable location go to the others")
+
+	_, err = e.Place(Request{Policy: placementv1alpha1.PlacementPolicySpec{Strategy: placementv1alpha1.PlacementStrategyWeightedSpread}, SyncTargets: targets})
+	require.ErrorIs(t, err, ErrNoFeasibleTargets, "locations without weights are not eligible")
+}
+
+func TestPlaceConstraintsAndTopology(t *testing.T) {
+	e := NewEngine()
+	zoned := func(name, location, zone string) *tmcv1alpha1.SyncTarget {
+		syncTarget := syncTarget(name, location)
+		if zone != "" {
+			syncTarget.Labels["zone"] = zone
+		}
+		return syncTarget
+	}
+	targets := []*tmcv1alpha1.SyncTarget{zoned("eu-1", "eu", "a"), zoned("eu-2", "eu", "b"), zoned("eu-3", "eu", "b"), zoned("us-1", "us", "")}
+
+	decision, err := e.Place(Request{
+		Policy: placementv1alpha1.PlacementPolicySpec{
+			Strategy:    placementv1alpha1.PlacementStrategyHighAvailability,
+			TopologyKey: "zone",
+			Constraints: []placementv1alpha1.TargetConstraint{
+				{Expression: `target.metadata.name != "eu-1"`, Message: "eu-1 is reserved"},
+			},
+		},
+		SyncTargets: append(targets, zoned("eu-4", "eu", "c")),
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-2", "eu-4"}, names(decision.Targets), "zones are distinct")
+	require.Equal(t, map[string]string{
+		"eu-1": "does not satisfy constraint: eu-1 is reserved",
+		"us-1": `has no topology label "zone"`,
+	}, decision.Rejected)
+
+	decision, err = e.Place(Request{
+		Policy: placementv1alpha1.PlacementPolicySpec{
+			Constraints: []placementv1alpha1.TargetConstraint{{Expression: `target.spec.location == "us"`}},
+		},
+		SyncTargets: targets,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"us-1"}, names(decision.Targets))
+	require.Equal(t, `does not satisfy constraint: target.spec.location == "us"`, decision.Rejected["eu-1"])
+
+	_, err = e.Place(Request{
+		Policy: placementv1alpha1.PlacementPolicySpec{
+			Constraints: []placementv1alpha1.TargetConstraint{{Expression: `target.spec.location ==`}},
+		},
+		SyncTargets: targets,
+	})
+	require.ErrorContains(t, err, "invalid constraint 0")
+}
+
+func TestPlacePreferredConstraints(t *testing.T) {
+	e := NewEngine()
+	tiered := func(name, location, tier string) *tmcv1alpha1.SyncTarget {
+		syncTarget := syncTarget(name, location)
+		if tier != "" {
+			syncTarget.Labels["tier"] = tier
+		}
+		return syncTarget
+	}
+	targets := []*tmcv1alpha1.SyncTarget{tiered("eu-1", "eu", "gold"), tiered("eu-2", "eu", "silver"), tiered("us-1", "us", "bronze")}
+	policy := placementv1alpha1.PlacementPolicySpec{
+		Strategy: placementv1alpha1.PlacementStrategySingleton,
+		Constraints: []placementv1alpha1.TargetConstraint{
+			{Name: "not-silver", Expression: `target.metadata.labels.tier != "silver"`, Message: "silver is full"},
+			{Name: "gold", Expression: `target.metadata.labels.tier == "gold"`, Type: placementv1alpha1.ConstraintPreferred, Weight: 10},
+			{Expression: `target.spec.location == "us"`, Type: placementv1alpha1.ConstraintPreferred},
+		},
+	}
+
+	decision, err := e.Place(Request{Policy: policy, SyncTargets: targets})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-1"}, names(decision.Targets), "the heavier preferred constraint wins")
+	require.Equal(t, map[string]string{"eu-2": `does not satisfy constraint "not-silver": silver is full`}, decision.Rejected)
+
+	preferred := []weightedConstraint{{weight: 10}, {weight: 1}}
+	for i, tc := range policy.Constraints[1:] {
+		preferred[i].constraint, err = e.constraints.Compile(tc.Expression)
+		require.NoError(t, err)
+	}
+	require.Equal(t, map[string]int{"eu-1": 90, "eu-2": 0, "us-1": 9, "us-2": 9}, constraintsScorer(preferred)(append(targets, tiered("us-2", "us", ""))),
+		"failing evaluations do not satisfy a constraint")
+	require.Nil(t, constraintsScorer(nil)(targets))
+
+	_, err = e.Place(Request{Policy: policy, SyncTargets: targets})
+	require.NoError(t, err)
+	require.Equal(t, 3, e.constraints.Len(), "compiled constraints are reused")
+}
+
+func TestSimulate(t *testing.T) {
+	e := NewEngine()
+	notReady := syncTarget("eu-3", "eu")
+	notReady.Status.Conditions = nil
+	targets := []*tmcv1alpha1.SyncTarget{syncTarget("eu-1", "eu"), syncTarget("eu-2", "eu"), notReady, syncTarget("us-1", "us")}
+
+	sim, err := e.Simulate(Request{
+		Policy: placementv1alpha1.PlacementPolicySpec{
+			Strategy: placementv1alpha1.PlacementStrategySingleton,
+			Constraints: []placementv1alpha1.TargetConstraint{
+				{Expression: `target.spec.location == "eu"`},
+				{Expression: `target.metadata.labels.tier == "gold"`},
+			},
+		},
+		SyncTargets: targets,
+		Current:     []workloadv1alpha1.TargetPlacement{{SyncTarget: "us-1"}},
+	})
+	require.Error(t, err, "the second constraint rejects all targets")
+	require.True(t, errors.Is(err, ErrNoFeasibleTargets))
+	require.Empty(t, sim.Candidates)
+	require.Equal(t, []string{"us-1"}, sim.Removed)
+	require.Len(t, sim.Constraints, 2)
+	require.Equal(t, []string{"eu-1", "eu-2", "eu-3"}, sim.Constraints[0].Satisfied, "constraints are evaluated for targets rejected by other filters")
+	require.Equal(t, []string{"us-1"}, sim.Constraints[0].Unsatisfied)
+	require.Len(t, sim.Constraints[1].Errors, 4, "missing labels fail the evaluation")
+	require.Equal(t, "syncer is not ready", sim.Rejected["eu-3"])
+
+	sim, err = e.Simulate(Request{
+		Policy: placementv1alpha1.PlacementPolicySpec{
+			Strategy:    placementv1alpha1.PlacementStrategySingleton,
+			Constraints: []placementv1alpha1.TargetConstraint{{Expression: `target.spec.location == "eu"`}},
+		},
+		SyncTargets: targets,
+		Current:     []workloadv1alpha1.TargetPlacement{{SyncTarget: "us-1"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-1"}, names(sim.Targets))
+	require.Equal(t, []Candidate{
+		{SyncTarget: "eu-1", Score: sim.Scores["eu-1"], Chosen: true},
+		{SyncTarget: "eu-2", Score: sim.Scores["eu-2"]},
+	}, sim.Candidates)
+	require.Equal(t, []string{"eu-1"}, sim.Added)
+	require.Equal(t, []string{"us-1"}, sim.Removed)
+	require.NotZero(t, sim.Constraints[0].Cost)
+}
+
+func TestPlaceDisruptionWindows(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := &Engine{now: func() time.Time { return now }}
+
+	upgrading := syncTarget("eu-1", "eu")
+	upgrading.Spec.DisruptionWindow = &tmcv1alpha1.DisruptionWindow{End: metav1.NewTime(now.Add(time.Hour)), Reason: "upgrade to 1.31"}
+	scheduled := syncTarget("us-1", "us")
+	scheduled.Spec.DisruptionWindow = &tmcv1alpha1.DisruptionWindow{Start: ptr.To(metav1.NewTime(now.Add(10 * time.Minute))), End: metav1.NewTime(now.Add(2 * time.Hour))}
+	targets := []*tmcv1alpha1.SyncTarget{upgrading, syncTarget("eu-2", "eu"), scheduled}
+	current := []workloadv1alpha1.TargetPlacement{{SyncTarget: "eu-1"}}
+
+	decision, err := e.Place(Request{
+		Policy:      placementv1alpha1.PlacementPolicySpec{Strategy: placementv1alpha1.PlacementStrategySingleton},
+		SyncTargets: targets,
+		Current:     current,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-2"}, names(decision.Targets), "singletons move off disrupted targets")
+	require.Equal(t, []string{"eu-1"}, decision.Displaced)
+	require.Equal(t, "is in a disruption window until 2025-01-01T01:00:00Z", decision.Rejected["eu-1"])
+	require.Equal(t, 10*time.Minute, decision.RecheckAfter, "the next window opens first")
+
+	decision, err = e.Place(Request{
+		Policy:      placementv1alpha1.PlacementPolicySpec{Strategy: placementv1alpha1.PlacementStrategySpread},
+		SyncTargets: targets,
+		Current:     current,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-1", "eu-2", "us-1"}, names(decision.Targets), "spread workloads stay, no new placements")
+	decision, err = e.Place(Request{SyncTargets: targets})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-2", "us-1"}, names(decision.Targets))
+
+	// After the window, the workload moves back.
+	now = now.Add(time.Hour)
+	decision, err = e.Place(Request{
+		Policy:      placementv1alpha1.PlacementPolicySpec{Strategy: placementv1alpha1.PlacementStrategySingleton},
+		SyncTargets: targets,
+		Current:     []workloadv1alpha1.TargetPlacement{{SyncTarget: "eu-2"}},
+		Displaced:   []string{"eu-1"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-1"}, names(decision.Targets))
+	require.Empty(t, decision.Displaced)
+}
+
+func TestPlaceSimulatedFailure(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := &Engine{now: func() time.Time { return now }}
+
+	failing := syncTarget("eu-1", "eu")
+	failing.Annotations = map[string]string{tmcv1alpha1.AnnotationSimulatedFailureUntil: "2025-01-01T00:10:00Z"}
+	targets := []*tmcv1alpha1.SyncTarget{failing, syncTarget("eu-2", "eu")}
+	request := Request{
+		Policy:      placementv1alpha1.PlacementPolicySpec{Strategy: placementv1alpha1.PlacementStrategySingleton},
+		SyncTargets: targets,
+		Current:     []workloadv1alpha1.TargetPlacement{{SyncTarget: "eu-1"}},
+	}
+
+	decision, err := e.Place(request)
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-2"}, names(decision.Targets), "singletons fail over")
+	require.Equal(t, "has a simulated failure until 2025-01-01T00:10:00Z", decision.Rejected["eu-1"])
+	require.Equal(t, 10*time.Minute, decision.RecheckAfter)
+
+	now = now.Add(10 * time.Minute)
+	decision, err = e.Place(request)
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-1"}, names(decision.Targets), "the simulation ended")
+	require.Zero(t, decision.RecheckAfter)
+}
+
+func TestPlaceScoring(t *testing.T) {
+	e := NewEngine()
+	withCost := func(syncTarget *tmcv1alpha1.SyncTarget, cost string) *tmcv1alpha1.SyncTarget {
+		syncTarget.Annotations = map[string]string{tmcv1alpha1.AnnotationCost: cost}
+		return syncTarget
+	}
+	withAllocatable := func(syncTarget *tmcv1alpha1.SyncTarget, allocatable, capacity string) *tmcv1alpha1.SyncTarget {
+		syncTarget.Status.Allocatable = &corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(allocatable)}
+		syncTarget.Status.Capacity = &corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(capacity)}
+		return syncTarget
+	}
+	targets := []*tmcv1alpha1.SyncTarget{
+		withAllocatable(withCost(syncTarget("eu-1", "eu"), "3"), "1", "10"),
+		withAllocatable(withCost(syncTarget("eu-2", "eu"), "1"), "2", "10"),
+		withAllocatable(withCost(syncTarget("us-1", "us"), "2"), "9", "10"),
+	}
+	singleton := placementv1alpha1.PlacementPolicySpec{Strategy: placementv1alpha1.PlacementStrategySingleton}
+
+	decision, err := e.Place(Request{Policy: singleton, SyncTargets: targets})
+	require.NoError(t, err)
+	require.Equal(t, []string{"us-1"}, names(decision.Targets), "balance outweighs cost by default")
+	require.Equal(t, map[string]int{"eu-1": 3, "eu-2": 26, "us-1": 37}, decision.Scores)
+
+	decision, err = e.Place(Request{
+		Policy:      singleton,
+		SyncTargets: targets,
+		Profile: &placementv1alpha1.SchedulingProfileSpec{Scorers: []placementv1alpha1.ScorerConfig{
+			{Name: placementv1alpha1.ScorerBalance, Disabled: true},
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-2"}, names(decision.Targets), "cost decides without balance")
+
+	decision, err = e.Place(Request{Policy: singleton, SyncTargets: targets, PreferredLocations: []string{"eu"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-2"}, names(decision.Targets), "locality outweighs balance")
+
+	decision, err = e.Place(Request{
+		SyncTargets: targets,
+		Profile:     &placementv1alpha1.SchedulingProfileSpec{DefaultStrategy: placementv1alpha1.PlacementStrategySingleton},
+	})
+	require.NoError(t, err)
+	require.Len(t, decision.Targets, 1, "the profile sets the default strategy")
+}
+
+func TestWeights(t *testing.T) {
+	require.Equal(t, DefaultWeights, Weights(nil))
+	require.Equal(t, map[placementv1alpha1.ScorerName]int32{
+		placementv1alpha1.ScorerLocality:         50,
+		placementv1alpha1.ScorerCost:             80,
+		placementv1alpha1.ScorerDataGravity:      50,
+		placementv1alpha1.ScorerReachability:     50,
+		placementv1alpha1.ScorerCarbon:           20,
+		placementv1alpha1.ScorerTaintToleration:  30,
+		placementv1alpha1.ScorerWorkloadAffinity: 50,
+		placementv1alpha1.ScorerConstraints:      50,
+	}, Weights(&placementv1alpha1.SchedulingProfileSpec{Scorers: []placementv1alpha1.ScorerConfig{
+		{Name: placementv1alpha1.ScorerCost, Weight: ptr.To[int32](80)},
+		{Name: placementv1alpha1.ScorerBalance, Disabled: true},
+	}}))
+}
+
+type filterPlugin struct {
+	name   string
+	filter func(syncTarget *tmcv1alpha1.SyncTarget) string
+}
+
+func (p filterPlugin) Name() string { return p.name }
+
+func (p filterPlugin) Filter(_ *placementv1alpha1.PlacementPolicySpec, syncTarget *tmcv1alpha1.SyncTarget) string {
+	return p.filter(syncTarget)
+}
+
+type scorePlugin struct {
+	name   string
+	scores map[string]int
+}
+
+func (p scorePlugin) Name() string { return p.name }
+
+func (p scorePlugin) Score(_ *placementv1alpha1.PlacementPolicySpec, _ []*tmcv1alpha1.SyncTarget) map[string]int {
+	return p.scores
+}
+
+type bindPlugin struct {
+	name  string
+	bound *[]string
+}
+
+func (p bindPlugin) Name() string { return p.name }
+
+func (p bindPlugin) Bind(_ context.Context, workload framework.Workload, targets []workloadv1alpha1.TargetPlacement) error {
+	if len(targets) == 0 {
+		return errors.New("no targets")
+	}
+	*p.bound = append(*p.bound, p.name+":"+workload.Name+":"+targets[0].SyncTarget)
+	return nil
+}
+
+func TestPlacePlugins(t *testing.T) {
+	var bound []string
+	registry := framework.NewRegistry()
+	require.NoError(t, registry.Register(filterPlugin{name: "no-us", filter: func(syncTarget *tmcv1alpha1.SyncTarget) string {
+		if syncTarget.Spec.Location == "us" {
+			return "is in the us"
+		}
+		return ""
+	}}))
+	require.NoError(t, registry.Register(scorePlugin{name: "favorite", scores: map[string]int{"eu-2": 100}}))
+	require.NoError(t, registry.Register(bindPlugin{name: "first", bound: &bound}))
+	require.NoError(t, registry.Register(bindPlugin{name: "second", bound: &bound}))
+	e := NewEngine().WithPlugins(registry)
+	targets := []*tmcv1alpha1.SyncTarget{syncTarget("eu-1", "eu"), syncTarget("eu-2", "eu"), syncTarget("us-1", "us")}
+	singleton := placementv1alpha1.PlacementPolicySpec{Strategy: placementv1alpha1.PlacementStrategySingleton}
+
+	decision, err := e.Place(Request{Policy: singleton, SyncTargets: targets})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-1"}, names(decision.Targets), "no plugins run without a scheduler profile")
+
+	policy := singleton
+	policy.SchedulerProfile = &placementv1alpha1.SchedulerProfile{Plugins: []placementv1alpha1.SchedulerPlugin{
+		{Name: "no-us"}, {Name: "favorite", Weight: ptr.To[int32](100)}, {Name: "second"}, {Name: "first"},
+	}}
+	req := Request{Policy: policy, SyncTargets: targets}
+	decision, err = e.Place(req)
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-2"}, names(decision.Targets), "the score plugin decides")
+	require.Equal(t, "is in the us", decision.Rejected["us-1"])
+	require.Greater(t, decision.Scores["eu-2"], decision.Scores["eu-1"])
+
+	require.NoError(t, e.Bind(context.Background(), framework.Workload{Name: "web"}, req, decision))
+	require.Equal(t, []string{"second:web:eu-2", "first:web:eu-2"}, bound, "bind plugins run in the order of the profile")
+	require.Error(t, e.Bind(context.Background(), framework.Workload{Name: "web"}, req, Decision{}))
+
+	policy.SchedulerProfile.Plugins[1].Weight = ptr.To[int32](0)
+	decision, err = e.Place(Request{Policy: policy, SyncTargets: targets})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-1"}, names(decision.Targets), "plugins with no weight do not score")
+
+	policy.SchedulerProfile.Plugins = append(policy.SchedulerProfile.Plugins, placementv1alpha1.SchedulerPlugin{Name: "missing"})
+	_, err = e.Place(Request{Policy: policy, SyncTargets: targets})
+	require.ErrorContains(t, err, `unknown scheduler plugin "missing"`)
+
+	policy = singleton
+	policy.Extender = &placementv1alpha1.ExtenderReference{Name: "ranker", Weight: ptr.To[int32](100)}
+	_, err = e.Place(Request{Policy: policy, SyncTargets: targets})
+	require.ErrorContains(t, err, `unknown scheduler plugin "extender/ranker"`)
+	require.NoError(t, registry.Register(scorePlugin{name: framework.ExtenderPluginName("ranker"), scores: map[string]int{"eu-2": 100}}))
+	decision, err = e.Place(Request{Policy: policy, SyncTargets: targets})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-2"}, names(decision.Targets), "the extender decides")
+}
+
+func TestPlaceDataAffinity(t *testing.T) {
+	e := NewEngine()
+	targets := []*tmcv1alpha1.SyncTarget{syncTarget("eu-1", "eu"), syncTarget("eu-2", "eu"), syncTarget("us-1", "us")}
+	dataLocations := map[string]*placementv1alpha1.DataLocationSpec{
+		"orders":  {SyncTargets: []string{"eu-1", "us-1"}, Size: ptr.To(resource.MustParse("10Gi"))},
+		"catalog": {SyncTargets: []string{"eu-2"}},
+	}
+	policy := func(terms ...placementv1alpha1.DataAffinityTerm) placementv1alpha1.PlacementPolicySpec {
+		return placementv1alpha1.PlacementPolicySpec{Strategy: placementv1alpha1.PlacementStrategySingleton, DataAffinity: terms}
+	}
+
+	decision, err := e.Place(Request{
+		Policy:        policy(placementv1alpha1.DataAffinityTerm{DataLocation: "catalog", Type: placementv1alpha1.DataAffinityRequired}),
+		SyncTargets:   targets,
+		DataLocations: dataLocations,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-2"}, names(decision.Targets))
+	require.Equal(t, `does not host the data of DataLocation "catalog"`, decision.Rejected["eu-1"])
+
+	decision, err = e.Place(Request{
+		Policy: policy(
+			placementv1alpha1.DataAffinityTerm{DataLocation: "orders", Type: placementv1alpha1.DataAffinityPreferred},
+			placementv1alpha1.DataAffinityTerm{DataLocation: "catalog", Type: placementv1alpha1.DataAffinityPreferred},
+		),
+		SyncTargets:   targets,
+		DataLocations: dataLocations,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-1"}, names(decision.Targets), "the larger dataset avoids more transfer")
+	require.Greater(t, decision.Scores["us-1"], decision.Scores["eu-2"])
+
+	_, err = e.Place(Request{
+		Policy:      policy(placementv1alpha1.DataAffinityTerm{DataLocation: "missing", Type: placementv1alpha1.DataAffinityRequired}),
+		SyncTargets: targets,
+	})
+	require.ErrorIs(t, err, ErrNoFeasibleTargets)
+}
+
+func TestPlaceReachability(t *testing.T) {
+	e := NewEngine()
+	reaching := func(name string, reachable ...bool) *tmcv1alpha1.SyncTarget {
+		syncTarget := syncTarget(name, "eu")
+		for i, r := range reachable {
+			syncTarget.Status.Reachability = append(syncTarget.Status.Reachability, tmcv1alpha1.EndpointReachability{Name: fmt.Sprintf("endpoint-%d", i), Reachable: r})
+		}
+		return syncTarget
+	}
+	targets := []*tmcv1alpha1.SyncTarget{reaching("eu-1", false, true), reaching("eu-2"), reaching("eu-3", true, true)}
+	policy := placementv1alpha1.PlacementPolicySpec{Strategy: placementv1alpha1.PlacementStrategySingleton, RequiredEndpoints: []string{"endpoint-0", "endpoint-1"}}
+
+	decision, err := e.Place(Request{Policy: policy, SyncTargets: targets})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-3"}, names(decision.Targets))
+	require.Greater(t, decision.Scores["eu-3"], decision.Scores["eu-1"])
+	require.Equal(t, decision.Scores["eu-1"], decision.Scores["eu-2"], "unprobed endpoints count half")
+
+	policy.RequiredEndpoints = nil
+	decision, err = e.Place(Request{Policy: policy, SyncTargets: targets})
+	require.NoError(t, err)
+	require.Equal(t, decision.Scores["eu-1"], decision.Scores["eu-3"])
+}
+
+func TestPlaceCarbon(t *testing.T) {
+	e := NewEngine()
+	withIntensity := func(syncTarget *tmcv1alpha1.SyncTarget, intensity string) *tmcv1alpha1.SyncTarget {
+		syncTarget.Annotations = map[string]string{tmcv1alpha1.AnnotationCarbonIntensity: intensity}
+		return syncTarget
+	}
+	targets := []*tmcv1alpha1.SyncTarget{
+		withIntensity(syncTarget("eu-1", "eu"), "300"),
+		withIntensity(syncTarget("eu-2", "eu"), "100"),
+		withIntensity(syncTarget("us-1", "us"), "50"),
+		syncTarget("eu-3", "eu"),
+	}
+	policy := placementv1alpha1.PlacementPolicySpec{
+		Strategy:         placementv1alpha1.PlacementStrategySingleton,
+		LocationSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"region": "eu"}},
+	}
+
+	decision, err := e.Place(Request{Policy: policy, SyncTargets: targets})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-2"}, names(decision.Targets), "the lowest carbon intensity within the location selector wins")
+	require.Greater(t, decision.Scores["eu-3"], decision.Scores["eu-1"], "targets not reporting their carbon intensity are neutral")
+	require.Equal(t, &EmissionsEstimate{Chosen: 100, Feasible: 200}, decision.Emissions)
+	require.Equal(t, "chosen SyncTargets run at 100 gCO2eq/kWh, 100 gCO2eq/kWh (50%) below the 200 gCO2eq/kWh average of feasible SyncTargets", decision.Emissions.String())
+
+	decision, err = e.Place(Request{Policy: policy, SyncTargets: targets, Current: []workloadv1alpha1.TargetPlacement{{SyncTarget: "eu-1"}}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-1"}, names(decision.Targets))
+	require.InDelta(t, 100, decision.Emissions.Delta(), 0.001, "staying on a carbon-intensive target is reported")
+
+	decision, err = e.Place(Request{Policy: policy, SyncTargets: []*tmcv1alpha1.SyncTarget{syncTarget("eu-1", "eu"), syncTarget("eu-2", "eu")}})
+	require.NoError(t, err)
+	require.Nil(t, decision.Emissions)
+	require.Equal(t, decision.Scores["eu-1"], decision.Scores["eu-2"])
+}
+
+func TestPlaceRequirements(t *testing.T) {
+	e := NewEngine()
+	capable := func(name, kubernetesVersion string, capabilities *tmcv1alpha1.SyncTargetCapabilities) *tmcv1alpha1.SyncTarget {
+		syncTarget := syncTarget(name, "eu")
+		syncTarget.Status.KubernetesVersion = kubernetesVersion
+		syncTarget.Status.Capabilities = capabilities
+		return syncTarget
+	}
+	full := &tmcv1alpha1.SyncTargetCapabilities{
+		FeatureGates: []string{"SidecarContainers"},
+		APIs:         []string{"v1", "snapshot.storage.k8s.io/v1"},
+		Addons:       []string{"ebs.csi.aws.com", "nvidia.com/gpu"},
+	}
+
+	decision, err := e.Place(Request{
+		Policy: placementv1alpha1.PlacementPolicySpec{
+			Strategy: placementv1alpha1.PlacementStrategySpread,
+			Requirements: &placementv1alpha1.TargetRequirements{
+				MinKubernetesVersion: "v1.29",
+				FeatureGates:         []string{"SidecarContainers"},
+				APIs:                 []string{"snapshot.storage.k8s.io/v1"},
+				Addons:               []string{"nvidia.com/gpu"},
+			},
+		},
+		SyncTargets: []*tmcv1alpha1.SyncTarget{
+			capable("old", "v1.27.3", full),
+			capable("unknown", "", full),
+			capable("unreported", "v1.30.0", nil),
+			capable("no-sidecars", "v1.30.0", &tmcv1alpha1.SyncTargetCapabilities{APIs: full.APIs, Addons: full.Addons}),
+			capable("no-snapshots", "v1.30.0", &tmcv1alpha1.SyncTargetCapabilities{FeatureGates: full.FeatureGates, APIs: []string{"v1"}, Addons: full.Addons}),
+			capable("no-gpu", "v1.30.0", &tmcv1alpha1.SyncTargetCapabilities{FeatureGates: full.FeatureGates, APIs: full.APIs}),
+			capable("k3s", "v1.29.4+k3s1", full),
+			capable("eu-1", "v1.31.0", full),
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-1", "k3s"}, names(decision.Targets))
+	require.Equal(t, map[string]string{
+		"old":          "runs Kubernetes v1.27.3, older than v1.29",
+		"unknown":      "has not reported a valid Kubernetes version",
+		"unreported":   "has not reported its capabilities",
+		"no-sidecars":  "lacks feature gates SidecarContainers",
+		"no-snapshots": "does not serve APIs snapshot.storage.k8s.io/v1",
+		"no-gpu":       "lacks addons nvidia.com/gpu",
+	}, decision.Rejected)
+
+	_, err = e.Place(Request{
+		Policy:      placementv1alpha1.PlacementPolicySpec{Requirements: &placementv1alpha1.TargetRequirements{MinKubernetesVersion: "latest"}},
+		SyncTargets: []*tmcv1alpha1.SyncTarget{syncTarget("eu-1", "eu")},
+	})
+	require.ErrorContains(t, err, "invalid requirements")
+}
+
+func TestPlaceResourceRequirements(t *testing.T) {
+	e := NewEngine()
+	withAllocatable := func(name string, allocatable corev1.ResourceList) *tmcv1alpha1.SyncTarget {
+		syncTarget := syncTarget(name, "eu")
+		if allocatable != nil {
+			syncTarget.Status.Allocatable = &allocatable
+		}
+		return syncTarget
+	}
+
+	decision, err := e.Place(Request{
+		Policy: placementv1alpha1.PlacementPolicySpec{
+			Strategy: placementv1alpha1.PlacementStrategySpread,
+			Requirements: &placementv1alpha1.TargetRequirements{Resources: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("4"),
+				"nvidia.com/gpu":   resource.MustParse("2"),
+			}},
+		},
+		SyncTargets: []*tmcv1alpha1.SyncTarget{
+			withAllocatable("unreported", nil),
+			withAllocatable("small", corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("3500m"), "nvidia.com/gpu": resource.MustParse("8")}),
+			withAllocatable("no-gpu", corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("64")}),
+			withAllocatable("eu-1", corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4"), "nvidia.com/gpu": resource.MustParse("2")}),
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-1"}, names(decision.Targets))
+	require.Equal(t, map[string]string{
+		"unreported": "has not reported its allocatable resources",
+		"small":      "has 3500m of cpu allocatable, less than 4",
+		"no-gpu":     "has 0 of nvidia.com/gpu allocatable, less than 2",
+	}, decision.Rejected)
+}
+
+func TestPlaceGuardrails(t *testing.T) {
+	e := NewEngine()
+	full := syncTarget("eu-1", "eu")
+	full.Spec.Guardrails = &tmcv1alpha1.SyncTargetGuardrails{MaxObjects: ptr.To[int64](10)}
+	full.Status.Usage = &tmcv1alpha1.SyncTargetUsage{Objects: 10}
+	disabled := syncTarget("eu-2", "eu")
+	disabled.Status.Conditions = append(disabled.Status.Conditions, conditionsv1alpha1.Condition{
+		Type: tmcv1alpha1.SchedulingDisabled, Status: corev1.ConditionTrue, Message: "maintenance",
+	})
+	targets := []*tmcv1alpha1.SyncTarget{full, disabled, syncTarget("us-1", "us")}
+
+	decision, err := e.Place(Request{SyncTargets: targets})
+	require.NoError(t, err)
+	require.Equal(t, []string{"us-1"}, names(decision.Targets))
+	require.Equal(t, map[string]string{
+		"eu-1": "exceeds its guardrails: 10 of at most 10 objects synced",
+		"eu-2": "has scheduling disabled: maintenance",
+	}, decision.Rejected)
+
+	decision, err = e.Place(Request{SyncTargets: targets, Current: []workloadv1alpha1.TargetPlacement{{SyncTarget: "eu-1"}}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-1", "us-1"}, names(decision.Targets), "current targets are kept")
+}
+
+func TestPlacePaused(t *testing.T) {
+	e := NewEngine()
+	paused := syncTarget("eu-1", "eu")
+	paused.Spec.Paused = true
+	targets := []*tmcv1alpha1.SyncTarget{paused, syncTarget("us-1", "us")}
+
+	decision, err := e.Place(Request{SyncTargets: targets})
+	require.NoError(t, err)
+	require.Equal(t, []string{"us-1"}, names(decision.Targets))
+	require.Equal(t, map[string]string{"eu-1": "is paused"}, decision.Rejected)
+
+	decision, err = e.Place(Request{SyncTargets: targets, Current: []workloadv1alpha1.TargetPlacement{{SyncTarget: "eu-1"}}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-1", "us-1"}, names(decision.Targets), "workloads are not moved off paused targets")
+	require.Empty(t, decision.Displaced)
+}
+
+func TestPlaceCordoned(t *testing.T) {
+	e := NewEngine()
+	cordoned := syncTarget("eu-1", "eu")
+	cordoned.Spec.Unschedulable = true
+	targets := []*tmcv1alpha1.SyncTarget{cordoned, syncTarget("us-1", "us")}
+
+	decision, err := e.Place(Request{SyncTargets: targets})
+	require.NoError(t, err)
+	require.Equal(t, []string{"us-1"}, names(decision.Targets))
+
+	decision, err = e.Place(Request{SyncTargets: targets, Current: []workloadv1alpha1.TargetPlacement{{SyncTarget: "eu-1"}}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-1", "us-1"}, names(decision.Targets), "workloads are not moved off cordoned targets")
+}
+
+func TestPlaceSyncTargetGroup(t *testing.T) {
+	e := NewEngine()
+	group := &tmcv1alpha1.SyncTargetGroupSpec{Members: []tmcv1alpha1.SyncTargetGroupMember{
+		{Name: "large", Weight: ptr.To[int32](3)},
+		{Name: "medium", Weight: ptr.To[int32](2)},
+		{Name: "small"},
+	}}
+
+	decision, err := e.Place(Request{
+		Policy:      placementv1alpha1.PlacementPolicySpec{SyncTargetGroup: "fleet", Strategy: placementv1alpha1.PlacementStrategySpread},
+		SyncTargets: []*tmcv1alpha1.SyncTarget{syncTarget("large", "eu"), syncTarget("medium", "eu"), syncTarget("small", "us"), syncTarget("other", "us")},
+		Replicas:    ptr.To[int32](13),
+		Group:       group,
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"other": `is not a member of SyncTargetGroup "fleet"`}, decision.Rejected)
+	replicas := map[string]int32{}
+	for _, target := range decision.Targets {
+		replicas[target.SyncTarget] = *target.Replicas
+	}
+	require.Equal(t, map[string]int32{"large": 7, "medium": 4, "small": 2}, replicas, "replicas are split by weight")
+}
+
+func TestPlaceProvisionable(t *testing.T) {
+	e := NewEngine()
+
+	burst := syncTarget("burst", "eu")
+	burst.Spec.Provisioning = &tmcv1alpha1.SyncTargetProvisioning{URL: "https://provisioner"}
+	burst.Status.Conditions = nil
+	failed := syncTarget("failed", "eu")
+	failed.Spec.Provisioning = &tmcv1alpha1.SyncTargetProvisioning{URL: "https://provisioner"}
+	failed.Status.Conditions = conditionsv1alpha1.Conditions{{
+		Type: tmcv1alpha1.Provisioned, Status: corev1.ConditionFalse, Reason: tmcv1alpha1.ProvisioningFailedReason, Message: "not provisioned within 15m0s",
+	}}
+
+	decision, err := e.Place(Request{
+		Policy:      placementv1alpha1.PlacementPolicySpec{NumberOfTargets: ptr.To[int32](1)},
+		SyncTargets: []*tmcv1alpha1.SyncTarget{burst, failed, syncTarget("eu-1", "eu")},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-1"}, names(decision.Targets), "existing capacity is preferred")
+	require.Equal(t, map[string]string{"failed": "failed to provision: not provisioned within 15m0s"}, decision.Rejected)
+
+	decision, err = e.Place(Request{
+		Policy:      placementv1alpha1.PlacementPolicySpec{NumberOfTargets: ptr.To[int32](2)},
+		SyncTargets: []*tmcv1alpha1.SyncTarget{burst, failed, syncTarget("eu-1", "eu")},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-1", "burst"}, names(decision.Targets), "provisionable targets burst")
+}
+
+func TestSplitReplicas(t *testing.T) {
+	require.Equal(t, []int32{4, 3, 3}, splitReplicas(10, []int32{1, 1, 1}), "earlier targets get the remainder")
+	require.Equal(t, []int32{6, 3, 1}, splitReplicas(10, []int32{6, 3, 1}))
+	require.Equal(t, []int32{1, 0, 0}, splitReplicas(1, []int32{1, 1, 1}))
+	require.Equal(t, []int32{0, 1}, splitReplicas(1, []int32{1, 3}), "the largest remainder wins")
+	require.Equal(t, []int32{0, 0}, splitReplicas(0, []int32{1, 3}))
+}
+
+func TestSplitByLocation(t *testing.T) {
+	targets := []*tmcv1alpha1.SyncTarget{syncTarget("eu-1", "eu"), syncTarget("us-1", "us"), syncTarget("eu-2", "eu")}
+	require.Equal(t, []int32{4, 3, 3}, splitByLocation(10, targets, []int32{1, 1, 1}, map[string]int32{"eu": 7, "us": 3}))
+	require.Equal(t, []int32{4, 5, 1}, splitByLocation(10, targets, []int32{3, 1, 1}, map[string]int32{"eu": 1, "us": 1}), "target weights split the replicas of a location")
+}
@@ -0,0 +1,452 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kcp-dev/kcp/pkg/placement/carbon"
+	"github.com/kcp-dev/kcp/pkg/placement/constraint"
+	"github.com/kcp-dev/kcp/pkg/placement/framework"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+const (
+	maxScore     = 100
+	neutralScore = maxScore / 2
+)
+
+// DefaultWeights are the scorer weights of the default SchedulingProfile.
+// DataGravity only applies to policies with preferred data affinity,
+// WorkloadAffinity to policies with preferred workload affinity terms,
+// Constraints to policies with preferred CEL constraints,
+// Reachability to policies with required endpoints, Carbon if a feasible
+// SyncTarget reports its carbon intensity, and TaintToleration if a feasible
+// SyncTarget has cells with PreferNoSchedule taints the policy does not
+// tolerate.
+var DefaultWeights = map[placementv1alpha1.ScorerName]int32{
+	placementv1alpha1.ScorerLocality:         50,
+	placementv1alpha1.ScorerBalance:          30,
+	placementv1alpha1.ScorerCost:             20,
+	placementv1alpha1.ScorerDataGravity:      50,
+	placementv1alpha1.ScorerReachability:     50,
+	placementv1alpha1.ScorerCarbon:           20,
+	placementv1alpha1.ScorerTaintToleration:  30,
+	placementv1alpha1.ScorerWorkloadAffinity: 50,
+	placementv1alpha1.ScorerConstraints:      50,
+}
+
+// Weights returns the scorer weights of the profile, with disabled scorers
+// left out. A nil profile is the default profile.
+func Weights(profile *placementv1alpha1.SchedulingProfileSpec) map[placementv1alpha1.ScorerName]int32 {
+	weights := make(map[placementv1alpha1.ScorerName]int32, len(DefaultWeights))
+	for name, weight := range DefaultWeights {
+		weights[name] = weight
+	}
+	if profile == nil {
+		return weights
+	}
+	for _, scorer := range profile.Scorers {
+		switch {
+		case scorer.Disabled:
+			delete(weights, scorer.Name)
+		case scorer.Weight != nil:
+			weights[scorer.Name] = *scorer.Weight
+		}
+	}
+	return weights
+}
+
+// scorer scores feasible SyncTargets from 0 to 100, higher is better. A nil
+// result means the scorer does not apply and its weight is ignored.
+type scorer func(feasible []*tmcv1alpha1.SyncTarget) map[string]int
+
+// weightedScorer is a scorer of a scheduler plugin and its weight.
+type weightedScorer struct {
+	name   string
+	scorer scorer
+	weight int32
+}
+
+// pluginScorers returns the scorers of the score plugins of a policy.
+func pluginScorers(policy *placementv1alpha1.PlacementPolicySpec, plugins []framework.WeightedScorePlugin) []weightedScorer {
+	scorers := make([]weightedScorer, 0, len(plugins))
+	for _, p := range plugins {
+		scorers = append(scorers, weightedScorer{
+			name: p.Name(),
+			scorer: func(feasible []*tmcv1alpha1.SyncTarget) map[string]int {
+				return p.Score(policy, feasible)
+			},
+			weight: p.Weight,
+		})
+	}
+	return scorers
+}
+
+// score returns the weighted average score of the feasible targets, by the
+// built-in scorers and those of scheduler plugins, and the scores of the
+// scorers that applied, by scorer name.
+func score(feasible []*tmcv1alpha1.SyncTarget, weights map[placementv1alpha1.ScorerName]int32, preferredLocations sets.Set[string], data []dataWeight, requiredEndpoints []string, taints *TaintEvaluator, affinity *WorkloadAffinityEvaluator, preferred []weightedConstraint, plugins []weightedScorer) (map[string]int, map[string]map[string]int) {
+	scorers := map[placementv1alpha1.ScorerName]scorer{
+		placementv1alpha1.ScorerLocality:         localityScorer(preferredLocations),
+		placementv1alpha1.ScorerBalance:          balanceScorer,
+		placementv1alpha1.ScorerCost:             costScorer,
+		placementv1alpha1.ScorerDataGravity:      dataGravityScorer(data),
+		placementv1alpha1.ScorerReachability:     reachabilityScorer(requiredEndpoints),
+		placementv1alpha1.ScorerCarbon:           carbonScorer,
+		placementv1alpha1.ScorerTaintToleration:  taintTolerationScorer(taints),
+		placementv1alpha1.ScorerWorkloadAffinity: affinity.Score,
+		placementv1alpha1.ScorerConstraints:      constraintsScorer(preferred),
+	}
+
+	total := map[string]int{}
+	byScorer := map[string]map[string]int{}
+	var sum int
+	add := func(name string, s scorer, weight int32) {
+		if weight <= 0 {
+			return
+		}
+		scores := s(feasible)
+		if scores == nil {
+			return
+		}
+		sum += int(weight)
+		clamped := make(map[string]int, len(scores))
+		for target, v := range scores {
+			clamped[target] = min(maxScore, max(0, v))
+			total[target] += int(weight) * clamped[target]
+		}
+		byScorer[name] = clamped
+	}
+	for name, weight := range weights {
+		if s, found := scorers[name]; found {
+			add(string(name), s, weight)
+		}
+	}
+	for _, p := range plugins {
+		add(p.name, p.scorer, p.weight)
+	}
+	if sum == 0 {
+		return map[string]int{}, byScorer
+	}
+	for target := range total {
+		total[target] /= sum
+	}
+	return total, byScorer
+}
+
+// localityScorer prefers SyncTargets in the preferred locations.
+func localityScorer(preferred sets.Set[string]) scorer {
+	return func(feasible []*tmcv1alpha1.SyncTarget) map[string]int {
+		scores := make(map[string]int, len(feasible))
+		for _, syncTarget := range feasible {
+			if preferred.Has(syncTarget.Spec.Location) {
+				scores[syncTarget.Name] = maxScore
+			} else {
+				scores[syncTarget.Name] = 0
+			}
+		}
+		return scores
+	}
+}
+
+// weightedConstraint is a preferred CEL constraint and its weight.
+type weightedConstraint struct {
+	constraint *constraint.Constraint
+	weight     int
+}
+
+// constraintsScorer prefers SyncTargets satisfying preferred constraints of
+// more total weight. SyncTargets a constraint fails to evaluate for do not
+// satisfy it. It does not apply without preferred constraints.
+func constraintsScorer(preferred []weightedConstraint) scorer {
+	return func(feasible []*tmcv1alpha1.SyncTarget) map[string]int {
+		if len(preferred) == 0 {
+			return nil
+		}
+		total := 0
+		for _, c := range preferred {
+			total += c.weight
+		}
+		scores := make(map[string]int, len(feasible))
+		for _, syncTarget := range feasible {
+			satisfied := 0
+			for _, c := range preferred {
+				if matches, err := c.constraint.Matches(syncTarget); err == nil && matches {
+					satisfied += c.weight
+				}
+			}
+			scores[syncTarget.Name] = maxScore * satisfied / total
+		}
+		return scores
+	}
+}
+
+// taintTolerationScorer prefers SyncTargets with a cell with fewer
+// PreferNoSchedule taints the policy does not tolerate. It does not apply if
+// no feasible SyncTarget has such taints.
+func taintTolerationScorer(taints *TaintEvaluator) scorer {
+	return func(feasible []*tmcv1alpha1.SyncTarget) map[string]int {
+		counts := make(map[string]int, len(feasible))
+		most := 0
+		for _, syncTarget := range feasible {
+			counts[syncTarget.Name] = taints.PreferNoSchedule(syncTarget)
+			most = max(most, counts[syncTarget.Name])
+		}
+		if most == 0 {
+			return nil
+		}
+		scores := make(map[string]int, len(feasible))
+		for name, n := range counts {
+			scores[name] = maxScore - maxScore*n/most
+		}
+		return scores
+	}
+}
+
+// balanceScorer prefers SyncTargets with a larger share of their cpu and
+// memory capacity allocatable. Targets that do not report capacity get a
+// neutral score.
+func balanceScorer(feasible []*tmcv1alpha1.SyncTarget) map[string]int {
+	scores := make(map[string]int, len(feasible))
+	for _, syncTarget := range feasible {
+		scores[syncTarget.Name] = neutralScore
+		allocatable, capacity := syncTarget.Status.Allocatable, syncTarget.Status.Capacity
+		if allocatable == nil || capacity == nil {
+			continue
+		}
+		var shares []float64
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			a, aFound := (*allocatable)[name]
+			c, cFound := (*capacity)[name]
+			if !aFound || !cFound || c.IsZero() {
+				continue
+			}
+			shares = append(shares, min(1, a.AsApproximateFloat64()/c.AsApproximateFloat64()))
+		}
+		if len(shares) == 0 {
+			continue
+		}
+		var sum float64
+		for _, share := range shares {
+			sum += share
+		}
+		scores[syncTarget.Name] = int(maxScore * sum / float64(len(shares)))
+	}
+	return scores
+}
+
+// costScorer prefers SyncTargets with a lower cost, relative to the other
+// feasible targets. Targets without a valid cost annotation get a neutral
+// score.
+func costScorer(feasible []*tmcv1alpha1.SyncTarget) map[string]int {
+	costs := map[string]float64{}
+	lowest, highest := -1.0, -1.0
+	for _, syncTarget := range feasible {
+		cost, err := strconv.ParseFloat(syncTarget.Annotations[tmcv1alpha1.AnnotationCost], 64)
+		if err != nil || cost < 0 {
+			continue
+		}
+		costs[syncTarget.Name] = cost
+		if lowest < 0 || cost < lowest {
+			lowest = cost
+		}
+		highest = max(highest, cost)
+	}
+
+	scores := make(map[string]int, len(feasible))
+	for _, syncTarget := range feasible {
+		cost, found := costs[syncTarget.Name]
+		switch {
+		case !found || highest == lowest:
+			scores[syncTarget.Name] = neutralScore
+		default:
+			scores[syncTarget.Name] = int(maxScore * (highest - cost) / (highest - lowest))
+		}
+	}
+	return scores
+}
+
+// dataWeight is the size of a dataset preferred by the policy, and the
+// SyncTargets it is present on.
+type dataWeight struct {
+	size        int64
+	syncTargets sets.Set[string]
+}
+
+// dataGravityScorer prefers SyncTargets hosting the larger share of the
+// preferred data, i.e. those avoiding the most data transfer. It does not
+// apply without preferred data.
+func dataGravityScorer(data []dataWeight) scorer {
+	return func(feasible []*tmcv1alpha1.SyncTarget) map[string]int {
+		var total int64
+		for _, d := range data {
+			total += d.size
+		}
+		if total <= 0 {
+			return nil
+		}
+		scores := make(map[string]int, len(feasible))
+		for _, syncTarget := range feasible {
+			var hosted int64
+			for _, d := range data {
+				if d.syncTargets.Has(syncTarget.Name) {
+					hosted += d.size
+				}
+			}
+			scores[syncTarget.Name] = int(maxScore * hosted / total)
+		}
+		return scores
+	}
+}
+
+// reachabilityScorer prefers SyncTargets whose syncer reaches more of the
+// required endpoints. Endpoints the syncer did not report count half. It
+// does not apply without required endpoints.
+func reachabilityScorer(required []string) scorer {
+	return func(feasible []*tmcv1alpha1.SyncTarget) map[string]int {
+		if len(required) == 0 {
+			return nil
+		}
+		scores := make(map[string]int, len(feasible))
+		for _, syncTarget := range feasible {
+			reachable := map[string]bool{}
+			for _, r := range syncTarget.Status.Reachability {
+				reachable[r.Name] = r.Reachable
+			}
+			var sum int
+			for _, name := range required {
+				switch r, probed := reachable[name]; {
+				case !probed:
+					sum += neutralScore
+				case r:
+					sum += maxScore
+				}
+			}
+			scores[syncTarget.Name] = sum / len(required)
+		}
+		return scores
+	}
+}
+
+// carbonScorer prefers SyncTargets with a lower carbon intensity, relative
+// to the other feasible targets. Targets that do not report one get a
+// neutral score. It does not apply if no target reports one.
+func carbonScorer(feasible []*tmcv1alpha1.SyncTarget) map[string]int {
+	intensities := map[string]float64{}
+	lowest, highest := math.Inf(1), math.Inf(-1)
+	for _, syncTarget := range feasible {
+		intensity, found := carbon.Intensity(syncTarget)
+		if !found {
+			continue
+		}
+		intensities[syncTarget.Name] = intensity
+		lowest, highest = min(lowest, intensity), max(highest, intensity)
+	}
+	if len(intensities) == 0 {
+		return nil
+	}
+
+	scores := make(map[string]int, len(feasible))
+	for _, syncTarget := range feasible {
+		intensity, found := intensities[syncTarget.Name]
+		switch {
+		case !found || highest == lowest:
+			scores[syncTarget.Name] = neutralScore
+		default:
+			scores[syncTarget.Name] = int(maxScore * (highest - intensity) / (highest - lowest))
+		}
+	}
+	return scores
+}
+
+// EmissionsEstimate compares the carbon intensity of the chosen SyncTargets
+// with that of all feasible ones, so that the emissions saved, or added, by
+// a decision can be reported.
+type EmissionsEstimate struct {
+	// Chosen is the average carbon intensity of the chosen targets that
+	// report one, weighted by their replicas, in gCO2eq/kWh.
+	Chosen float64
+	// Feasible is the average carbon intensity of the feasible targets
+	// that report one, in gCO2eq/kWh.
+	Feasible float64
+}
+
+// Delta is the carbon intensity of the decision relative to the average of
+// the feasible targets in gCO2eq/kWh. It is negative if the decision saves
+// emissions.
+func (e *EmissionsEstimate) Delta() float64 {
+	return e.Chosen - e.Feasible
+}
+
+func (e *EmissionsEstimate) String() string {
+	delta := e.Delta()
+	direction := "below"
+	if delta > 0 {
+		direction = "above"
+	}
+	relative := ""
+	if e.Feasible > 0 {
+		relative = fmt.Sprintf(" (%.0f%%)", math.Abs(delta)/e.Feasible*100)
+	}
+	return fmt.Sprintf("chosen SyncTargets run at %.0f gCO2eq/kWh, %.0f gCO2eq/kWh%s %s the %.0f gCO2eq/kWh average of feasible SyncTargets",
+		e.Chosen, math.Abs(delta), relative, direction, e.Feasible)
+}
+
+// estimateEmissions returns the emissions estimate of the chosen targets,
+// or nil if none of them reports its carbon intensity.
+func estimateEmissions(chosen []workloadv1alpha1.TargetPlacement, feasible []*tmcv1alpha1.SyncTarget) *EmissionsEstimate {
+	intensities := map[string]float64{}
+	var sum float64
+	for _, syncTarget := range feasible {
+		if intensity, found := carbon.Intensity(syncTarget); found {
+			intensities[syncTarget.Name] = intensity
+			sum += intensity
+		}
+	}
+	if len(intensities) == 0 {
+		return nil
+	}
+
+	var chosenSum, weights float64
+	for _, t := range chosen {
+		intensity, found := intensities[t.SyncTarget]
+		if !found {
+			continue
+		}
+		weight := 1.0
+		if t.Replicas != nil {
+			weight = float64(*t.Replicas)
+		}
+		chosenSum += weight * intensity
+		weights += weight
+	}
+	if weights == 0 {
+		return nil
+	}
+	return &EmissionsEstimate{
+		Chosen:   chosenSum / weights,
+		Feasible: sum / float64(len(intensities)),
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+)
+
+// Simulation is a placement decision computed to preview it, e.g. of a
+// changed PlacementPolicy, together with how it was reached.
+type Simulation struct {
+	Decision
+	// Candidates are the feasible SyncTargets, best scored first.
+	Candidates []Candidate
+	// Constraints are the results of the CEL constraints of the policy,
+	// evaluated for every SyncTarget of the request regardless of the other
+	// filters.
+	Constraints []ConstraintResult
+	// Added are the chosen SyncTargets the workload is not placed on today.
+	Added []string
+	// Removed are the SyncTargets the workload is placed on today that are
+	// not chosen.
+	Removed []string
+}
+
+// Candidate is a feasible SyncTarget.
+type Candidate struct {
+	SyncTarget string
+	// Score is the weighted score from 0 to 100.
+	Score int
+	// Chosen is whether the workload is placed on the SyncTarget.
+	Chosen bool
+}
+
+// ConstraintResult is the outcome of a CEL constraint for the SyncTargets.
+type ConstraintResult struct {
+	Name       string
+	Expression string
+	Type       placementv1alpha1.ConstraintType
+	// Cost is the estimated worst-case cost of the expression.
+	Cost uint64
+	// Satisfied and Unsatisfied are the names of the SyncTargets the
+	// expression evaluated to true and false for, sorted.
+	Satisfied   []string
+	Unsatisfied []string
+	// Errors maps names of SyncTargets the expression failed to evaluate
+	// for to the error.
+	Errors map[string]string
+}
+
+// Simulate computes the placement decision of the request like Place, and
+// explains it. Like Place, it does not change anything. If no SyncTarget is
+// feasible, the simulation is returned along with ErrNoFeasibleTargets.
+func (e *Engine) Simulate(req Request) (Simulation, error) {
+	decision, err := e.Place(req)
+	if err != nil && !errors.Is(err, ErrNoFeasibleTargets) {
+		return Simulation{}, err
+	}
+	sim := Simulation{Decision: decision}
+
+	chosen := sets.New[string]()
+	for _, t := range decision.Targets {
+		chosen.Insert(t.SyncTarget)
+	}
+	for name, score := range decision.Scores {
+		sim.Candidates = append(sim.Candidates, Candidate{SyncTarget: name, Score: score, Chosen: chosen.Has(name)})
+	}
+	sort.Slice(sim.Candidates, func(i, j int) bool {
+		if sim.Candidates[i].Score != sim.Candidates[j].Score {
+			return sim.Candidates[i].Score > sim.Candidates[j].Score
+		}
+		return sim.Candidates[i].SyncTarget < sim.Candidates[j].SyncTarget
+	})
+
+	for i, tc := range req.Policy.Constraints {
+		c, err := e.constraints.Compile(tc.Expression)
+		if err != nil {
+			return Simulation{}, fmt.Errorf("invalid constraint %d: %w", i, err)
+		}
+		result := ConstraintResult{Name: tc.Name, Expression: tc.Expression, Type: tc.Type, Cost: c.Cost}
+		for _, syncTarget := range req.SyncTargets {
+			matches, err := c.Matches(syncTarget)
+			switch {
+			case err != nil:
+				if result.Errors == nil {
+					result.Errors = map[string]string{}
+				}
+				result.Errors[syncTarget.Name] = err.Error()
+			case matches:
+				result.Satisfied = append(result.Satisfied, syncTarget.Name)
+			default:
+				result.Unsatisfied = append(result.Unsatisfied, syncTarget.Name)
+			}
+		}
+		sort.Strings(result.Satisfied)
+		sort.Strings(result.Unsatisfied)
+		sim.Constraints = append(sim.Constraints, result)
+	}
+
+	current := sets.New[string]()
+	for _, t := range req.Current {
+		current.Insert(t.SyncTarget)
+	}
+	sim.Added = sets.List(chosen.Difference(current))
+	sim.Removed = sets.List(current.Difference(chosen))
+	return sim, err
+}
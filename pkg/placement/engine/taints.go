@@ -0,0 +1,163 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// TaintEvaluator matches the tolerations of a policy against the taints of
+// the cells of SyncTargets. Workloads may run in a cell if they tolerate all
+// its NoSchedule and NoExecute taints. NoSchedule taints only keep out
+// workloads not placed on the SyncTarget yet, and NoExecute taints evict
+// placed workloads only once an EvictionPolicy evicted the SyncTarget for
+// them. SyncTargets without cells have no taints.
+type TaintEvaluator struct {
+	tolerations []corev1.Toleration
+	now         time.Time
+}
+
+// NewTaintEvaluator returns an evaluator of the tolerations at the time now.
+func NewTaintEvaluator(tolerations []corev1.Toleration, now time.Time) *TaintEvaluator {
+	return &TaintEvaluator{tolerations: tolerations, now: now}
+}
+
+// Evaluate returns why workloads may not run in any cell of syncTarget, or
+// an empty string if they may. current is whether they are placed on it.
+// If the NoExecute taints of the cells they may run in are tolerated for a
+// limited time only, until is when the last of those cells stops being
+// tolerated, and zero otherwise.
+func (e *TaintEvaluator) Evaluate(syncTarget *tmcv1alpha1.SyncTarget, current bool) (reason string, until time.Time) {
+	noSchedule, noExecute := !current, !current || evictedForTaints(syncTarget, e.now)
+	tolerated := false
+	for _, cell := range syncTarget.Spec.Cells {
+		taint, end := e.cell(cell, syncTarget.Spec.EvictAfter, noSchedule, noExecute)
+		if taint != nil {
+			if reason == "" {
+				reason = fmt.Sprintf("does not tolerate the taints of any cell: cell %q is tainted with %s", cell.Name, taint.ToString())
+			}
+			continue
+		}
+		if end.IsZero() {
+			return "", time.Time{}
+		}
+		if !tolerated || end.After(until) {
+			until = end
+		}
+		tolerated = true
+	}
+	if tolerated {
+		return "", until
+	}
+	return reason, time.Time{}
+}
+
+// cell returns the first taint of the cell that is not tolerated, if any,
+// and until when its NoExecute taints are tolerated, or zero if for ever.
+// Tolerations with tolerationSeconds tolerate a NoExecute taint for that
+// long after it was added or, if it has no time, after evictAfter.
+func (e *TaintEvaluator) cell(cell tmcv1alpha1.Cell, evictAfter *metav1.Time, noSchedule, noExecute bool) (*corev1.Taint, time.Time) {
+	var until time.Time
+	for i := range cell.Taints {
+		taint := &cell.Taints[i]
+		switch {
+		case taint.Effect == corev1.TaintEffectNoSchedule && noSchedule:
+			if !e.tolerates(taint) {
+				return taint, time.Time{}
+			}
+		case taint.Effect == corev1.TaintEffectNoExecute && noExecute:
+			seconds, tolerated := e.tolerationSeconds(taint)
+			if !tolerated {
+				return taint, time.Time{}
+			}
+			since := evictAfter
+			if taint.TimeAdded != nil {
+				since = taint.TimeAdded
+			}
+			if seconds == nil || since == nil {
+				continue
+			}
+			end := since.Add(time.Duration(*seconds) * time.Second)
+			if !e.now.Before(end) {
+				return taint, time.Time{}
+			}
+			if until.IsZero() || end.Before(until) {
+				until = end
+			}
+		}
+	}
+	return nil, until
+}
+
+// PreferNoSchedule returns the least number of PreferNoSchedule taints not
+// tolerated in a cell of syncTarget.
+func (e *TaintEvaluator) PreferNoSchedule(syncTarget *tmcv1alpha1.SyncTarget) int {
+	least := -1
+	for _, cell := range syncTarget.Spec.Cells {
+		n := 0
+		for i := range cell.Taints {
+			if taint := &cell.Taints[i]; taint.Effect == corev1.TaintEffectPreferNoSchedule && !e.tolerates(taint) {
+				n++
+			}
+		}
+		if least < 0 || n < least {
+			least = n
+		}
+	}
+	return max(0, least)
+}
+
+func (e *TaintEvaluator) tolerates(taint *corev1.Taint) bool {
+	for i := range e.tolerations {
+		if e.tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// tolerationSeconds returns whether the taint is tolerated, and for how
+// long, the least tolerationSeconds of the matching tolerations, or nil if
+// none limits it, like the taint manager of Kubernetes.
+func (e *TaintEvaluator) tolerationSeconds(taint *corev1.Taint) (*int64, bool) {
+	var seconds *int64
+	tolerated := false
+	for i := range e.tolerations {
+		toleration := &e.tolerations[i]
+		if !toleration.ToleratesTaint(taint) {
+			continue
+		}
+		tolerated = true
+		if s := toleration.TolerationSeconds; s != nil && (seconds == nil || *s < *seconds) {
+			seconds = s
+		}
+	}
+	return seconds, tolerated
+}
+
+// evictedForTaints returns whether an EvictionPolicy evicted syncTarget only
+// because of the NoExecute taints of its cells.
+func evictedForTaints(syncTarget *tmcv1alpha1.SyncTarget, now time.Time) bool {
+	return syncTarget.Annotations[tmcv1alpha1.AnnotationEvictedForTaints] == "true" &&
+		syncTarget.Spec.EvictAfter != nil && !now.Before(syncTarget.Spec.EvictAfter.Time)
+}
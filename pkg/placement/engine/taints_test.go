@@ -0,0 +1,134 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var (
+	gpuTaint         = corev1.Taint{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}
+	unreachableTaint = corev1.Taint{Key: "node.kubernetes.io/unreachable", Effect: corev1.TaintEffectNoExecute}
+	spotTaint        = corev1.Taint{Key: "spot", Effect: corev1.TaintEffectPreferNoSchedule}
+)
+
+// taintedTarget returns a SyncTarget with a cell per list of taints.
+func taintedTarget(name string, cells ...[]corev1.Taint) *tmcv1alpha1.SyncTarget {
+	syncTarget := syncTarget(name, "eu")
+	for i, taints := range cells {
+		syncTarget.Spec.Cells = append(syncTarget.Spec.Cells, tmcv1alpha1.Cell{Name: string(rune('a' + i)), Taints: taints})
+	}
+	return syncTarget
+}
+
+func TestPlaceTaints(t *testing.T) {
+	targets := func() []*tmcv1alpha1.SyncTarget {
+		return []*tmcv1alpha1.SyncTarget{
+			syncTarget("plain", "eu"),
+			taintedTarget("gpu", []corev1.Taint{gpuTaint}),
+			taintedTarget("edge", []corev1.Taint{unreachableTaint}, nil),
+			taintedTarget("spot", []corev1.Taint{spotTaint}),
+		}
+	}
+
+	decision, err := NewEngine().Place(Request{SyncTargets: targets()})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"plain", "edge", "spot"}, names(decision.Targets), "workloads run in the untainted cell of edge")
+	require.Equal(t, map[string]string{"gpu": `does not tolerate the taints of any cell: cell "a" is tainted with dedicated=gpu:NoSchedule`}, decision.Rejected)
+	require.Equal(t, map[string]int{"plain": 100, "edge": 100, "spot": 0}, decision.ScorerScores[string(placementv1alpha1.ScorerTaintToleration)])
+
+	decision, err = NewEngine().Place(Request{
+		Policy: placementv1alpha1.PlacementPolicySpec{Tolerations: []corev1.Toleration{
+			{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "gpu"},
+			{Key: "spot", Operator: corev1.TolerationOpExists},
+		}},
+		SyncTargets: targets(),
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"plain", "gpu", "edge", "spot"}, names(decision.Targets))
+	require.NotContains(t, decision.ScorerScores, string(placementv1alpha1.ScorerTaintToleration), "tolerated PreferNoSchedule taints do not score")
+
+	decision, err = NewEngine().Place(Request{
+		SyncTargets: []*tmcv1alpha1.SyncTarget{taintedTarget("gpu", []corev1.Taint{gpuTaint})},
+		Current:     []workloadv1alpha1.TargetPlacement{{SyncTarget: "gpu"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"gpu"}, names(decision.Targets), "NoSchedule taints do not move placed workloads")
+}
+
+func TestPlaceNoExecuteEviction(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	e := NewEngineWithClock(func() time.Time { return now })
+	down := func(evictedForTaints bool, taint corev1.Taint) *tmcv1alpha1.SyncTarget {
+		syncTarget := taintedTarget("down", []corev1.Taint{taint})
+		syncTarget.Spec.EvictAfter = &metav1.Time{Time: now.Add(-time.Minute)}
+		syncTarget.Annotations = map[string]string{tmcv1alpha1.AnnotationEvictedBy: "default"}
+		if evictedForTaints {
+			syncTarget.Annotations[tmcv1alpha1.AnnotationEvictedForTaints] = "true"
+		}
+		return syncTarget
+	}
+	place := func(tolerations []corev1.Toleration, down *tmcv1alpha1.SyncTarget) Decision {
+		t.Helper()
+		decision, _ := e.Place(Request{
+			Policy:      placementv1alpha1.PlacementPolicySpec{Tolerations: tolerations},
+			SyncTargets: []*tmcv1alpha1.SyncTarget{syncTarget("up", "eu"), down},
+			Current:     []workloadv1alpha1.TargetPlacement{{SyncTarget: "down"}},
+		})
+		return decision
+	}
+	tolerate := []corev1.Toleration{{Key: unreachableTaint.Key, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute}}
+
+	decision := place(nil, taintedTarget("down", []corev1.Taint{unreachableTaint}))
+	require.Equal(t, []string{"down", "up"}, names(decision.Targets), "placed workloads stay until the SyncTarget is evicted")
+
+	decision = place(nil, down(true, unreachableTaint))
+	require.Equal(t, []string{"up"}, names(decision.Targets))
+	require.Contains(t, decision.Rejected["down"], "node.kubernetes.io/unreachable:NoExecute")
+
+	decision = place(tolerate, down(true, unreachableTaint))
+	require.Equal(t, []string{"down", "up"}, names(decision.Targets), "tolerating workloads stay")
+
+	decision = place(tolerate, down(false, unreachableTaint))
+	require.Equal(t, map[string]string{"down": "is being evicted"}, decision.Rejected, "tolerations do not keep workloads on unhealthy SyncTargets")
+
+	tolerate[0].TolerationSeconds = ptr.To[int64](300)
+	added := unreachableTaint
+	added.TimeAdded = &metav1.Time{Time: now.Add(-100 * time.Second)}
+	decision = place(tolerate, down(true, added))
+	require.Equal(t, []string{"down", "up"}, names(decision.Targets))
+	require.Equal(t, 200*time.Second, decision.RecheckAfter, "placement is rechecked when the toleration expires")
+
+	now = now.Add(200 * time.Second)
+	decision = place(tolerate, down(true, added))
+	require.Equal(t, []string{"up"}, names(decision.Targets), "expired tolerations evict")
+
+	decision = place(tolerate, down(true, unreachableTaint))
+	require.Equal(t, []string{"down", "up"}, names(decision.Targets), "taints without time are tolerated from evictAfter on")
+	require.Equal(t, 4*time.Minute, decision.RecheckAfter, "evicted a minute ago, tolerated for five")
+}
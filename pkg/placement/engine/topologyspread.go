@@ -0,0 +1,164 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// TopologySpreadEvaluator chooses SyncTargets so that they are spread over
+// the topology domains of the spread constraints of a policy. The domains of
+// a SyncTarget are the values of a topology key in its labels or, if it has
+// none, in the labels of its cells.
+type TopologySpreadEvaluator struct {
+	constraints []placementv1alpha1.TopologySpreadConstraint
+}
+
+// NewTopologySpreadEvaluator returns an evaluator of the constraints.
+func NewTopologySpreadEvaluator(constraints []placementv1alpha1.TopologySpreadConstraint) *TopologySpreadEvaluator {
+	return &TopologySpreadEvaluator{constraints: constraints}
+}
+
+// Domains returns the topology domains of syncTarget for the key.
+func Domains(syncTarget *tmcv1alpha1.SyncTarget, key string) sets.Set[string] {
+	if value, found := syncTarget.Labels[key]; found {
+		return sets.New(value)
+	}
+	domains := sets.New[string]()
+	for _, cell := range syncTarget.Spec.Cells {
+		if value, found := cell.Labels[key]; found {
+			domains.Insert(value)
+		}
+	}
+	return domains
+}
+
+// Filter rejects SyncTargets without a domain for a DoNotSchedule
+// constraint.
+func (e *TopologySpreadEvaluator) Filter(syncTarget *tmcv1alpha1.SyncTarget) string {
+	for _, c := range e.constraints {
+		if hard(c) && Domains(syncTarget, c.TopologyKey).Len() == 0 {
+			return fmt.Sprintf("has no topology label %q, neither on itself nor on its cells", c.TopologyKey)
+		}
+	}
+	return ""
+}
+
+// Choose picks up to n of the candidates, in order of preference, such that
+// no DoNotSchedule constraint exceeds its skew. Among candidates that fit,
+// those exceeding the skew of ScheduleAnyway constraints the least are
+// picked first. It picks fewer than n if no other candidate fits.
+func (e *TopologySpreadEvaluator) Choose(candidates []*tmcv1alpha1.SyncTarget, n int) []*tmcv1alpha1.SyncTarget {
+	if len(e.constraints) == 0 {
+		return candidates[:min(n, len(candidates))]
+	}
+
+	// counts are the chosen SyncTargets per domain, by constraint. The
+	// domains of all candidates count, even if none is chosen in them.
+	counts := make([]map[string]int, len(e.constraints))
+	for i, c := range e.constraints {
+		counts[i] = map[string]int{}
+		for _, syncTarget := range candidates {
+			for domain := range Domains(syncTarget, c.TopologyKey) {
+				counts[i][domain] = 0
+			}
+		}
+	}
+
+	chosen := make([]*tmcv1alpha1.SyncTarget, 0, n)
+	picked := make([]bool, len(candidates))
+	for len(chosen) < n {
+		best, bestPenalty := -1, 0
+		for j, syncTarget := range candidates {
+			if picked[j] {
+				continue
+			}
+			penalty, fits := e.penalty(syncTarget, counts)
+			if fits && (best < 0 || penalty < bestPenalty) {
+				best, bestPenalty = j, penalty
+			}
+		}
+		if best < 0 {
+			break
+		}
+		picked[best] = true
+		chosen = append(chosen, candidates[best])
+		for i, c := range e.constraints {
+			for domain := range Domains(candidates[best], c.TopologyKey) {
+				counts[i][domain]++
+			}
+		}
+	}
+	return chosen
+}
+
+// penalty returns by how much choosing syncTarget exceeds the skews of the
+// ScheduleAnyway constraints, and whether it keeps within the skews of the
+// DoNotSchedule ones.
+func (e *TopologySpreadEvaluator) penalty(syncTarget *tmcv1alpha1.SyncTarget, counts []map[string]int) (int, bool) {
+	penalty := 0
+	for i, c := range e.constraints {
+		domains := Domains(syncTarget, c.TopologyKey)
+		if domains.Len() == 0 {
+			continue
+		}
+		// Like with pod topology spread, the skew is measured against the
+		// domain with the fewest before syncTarget is added.
+		lowest, _ := minMax(counts[i])
+		for domain := range domains {
+			skew := counts[i][domain] + 1 - lowest
+			if skew <= maxSkew(c) {
+				continue
+			}
+			if hard(c) {
+				return 0, false
+			}
+			penalty += skew - maxSkew(c)
+		}
+	}
+	return penalty, true
+}
+
+func minMax(counts map[string]int) (int, int) {
+	values := make([]int, 0, len(counts))
+	for _, count := range counts {
+		values = append(values, count)
+	}
+	if len(values) == 0 {
+		return 0, 0
+	}
+	sort.Ints(values)
+	return values[0], values[len(values)-1]
+}
+
+func hard(c placementv1alpha1.TopologySpreadConstraint) bool {
+	return c.WhenUnsatisfiable != corev1.ScheduleAnyway
+}
+
+func maxSkew(c placementv1alpha1.TopologySpreadConstraint) int {
+	if c.MaxSkew < 1 {
+		return 1
+	}
+	return int(c.MaxSkew)
+}
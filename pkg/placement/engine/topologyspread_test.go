@@ -0,0 +1,84 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// zonedTargets returns SyncTargets in zone a, one in zone b by its cells,
+// and one without zone.
+func zonedTargets() []*tmcv1alpha1.SyncTarget {
+	var targets []*tmcv1alpha1.SyncTarget
+	for _, name := range []string{"a-1", "a-2", "a-3"} {
+		syncTarget := syncTarget(name, "eu")
+		syncTarget.Labels["zone"] = "a"
+		targets = append(targets, syncTarget)
+	}
+	b := syncTarget("b-1", "eu")
+	b.Spec.Cells = []tmcv1alpha1.Cell{{Name: "rack-1", Labels: map[string]string{"zone": "b"}}}
+	return append(targets, b, syncTarget("c-1", "eu"))
+}
+
+func targetNames(targets []*tmcv1alpha1.SyncTarget) []string {
+	var out []string
+	for _, t := range targets {
+		out = append(out, t.Name)
+	}
+	return out
+}
+
+func TestPlaceTopologySpread(t *testing.T) {
+	decision, err := NewEngine().Place(Request{
+		Policy: placementv1alpha1.PlacementPolicySpec{
+			Strategy:                  placementv1alpha1.PlacementStrategySpread,
+			TopologySpreadConstraints: []placementv1alpha1.TopologySpreadConstraint{{TopologyKey: "zone", MaxSkew: 1}},
+		},
+		SyncTargets: zonedTargets(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a-1", "b-1", "a-2"}, names(decision.Targets), "a third target in zone a would exceed the skew")
+	require.Equal(t, map[string]string{"c-1": `has no topology label "zone", neither on itself nor on its cells`}, decision.Rejected)
+}
+
+func TestTopologySpreadChoose(t *testing.T) {
+	soft := NewTopologySpreadEvaluator([]placementv1alpha1.TopologySpreadConstraint{{TopologyKey: "zone", WhenUnsatisfiable: corev1.ScheduleAnyway}})
+	require.Empty(t, soft.Filter(zonedTargets()[4]))
+	require.Equal(t, []string{"a-1", "b-1", "a-2", "c-1", "a-3"}, targetNames(soft.Choose(zonedTargets(), 5)), "targets keeping the skew are preferred")
+
+	hard := NewTopologySpreadEvaluator([]placementv1alpha1.TopologySpreadConstraint{{TopologyKey: "zone", MaxSkew: 2}})
+	require.Equal(t, []string{"a-1", "a-2", "b-1", "a-3"}, targetNames(hard.Choose(zonedTargets()[:4], 4)))
+
+	// A SyncTarget spanning both zones counts in each.
+	both := syncTarget("ab-1", "eu")
+	both.Spec.Cells = []tmcv1alpha1.Cell{
+		{Name: "rack-1", Labels: map[string]string{"zone": "a"}},
+		{Name: "rack-2", Labels: map[string]string{"zone": "b"}},
+	}
+	hard = NewTopologySpreadEvaluator([]placementv1alpha1.TopologySpreadConstraint{{TopologyKey: "zone", MaxSkew: 1}})
+	candidates := append([]*tmcv1alpha1.SyncTarget{both}, zonedTargets()[:4]...)
+	require.Equal(t, []string{"ab-1", "a-1", "b-1", "a-2"}, targetNames(hard.Choose(candidates, 5)))
+
+	require.Equal(t, []string{"a-1", "a-2"}, targetNames(NewTopologySpreadEvaluator(nil).Choose(zonedTargets(), 2)))
+}
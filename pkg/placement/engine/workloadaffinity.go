@@ -0,0 +1,141 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// PlacedWorkload is a workload placed by its WorkloadDistribution.
+type PlacedWorkload struct {
+	Namespace string
+	Name      string
+	// Labels are the labels of the WorkloadDistribution.
+	Labels map[string]string
+}
+
+// PlacementIndex maps the names of SyncTargets to the workloads placed on
+// them.
+type PlacementIndex map[string][]PlacedWorkload
+
+// WorkloadAffinityEvaluator evaluates the workload affinity terms of a
+// policy against the workloads placed on the candidate SyncTargets.
+type WorkloadAffinityEvaluator struct {
+	terms []affinityTerm
+}
+
+type affinityTerm struct {
+	placementv1alpha1.WorkloadAffinityTerm
+	// hosts are the SyncTargets a selected workload is placed on.
+	hosts sets.Set[string]
+	// selectsSelf is whether the term selects the workload itself.
+	selectsSelf bool
+}
+
+// NewWorkloadAffinityEvaluator returns an evaluator of the terms for the
+// workload, looking up the workloads placed on syncTargets in placements.
+func NewWorkloadAffinityEvaluator(terms []placementv1alpha1.WorkloadAffinityTerm, workload PlacedWorkload, syncTargets []*tmcv1alpha1.SyncTarget, placements PlacementIndex) (*WorkloadAffinityEvaluator, error) {
+	e := &WorkloadAffinityEvaluator{terms: make([]affinityTerm, 0, len(terms))}
+	for i, term := range terms {
+		selector, err := metav1.LabelSelectorAsSelector(&term.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector of workload affinity term %d: %w", i, err)
+		}
+		namespaces := sets.New(term.Namespaces...)
+		if namespaces.Len() == 0 {
+			namespaces.Insert(workload.Namespace)
+		}
+		selects := func(w PlacedWorkload) bool {
+			return namespaces.Has(w.Namespace) && selector.Matches(labels.Set(w.Labels))
+		}
+		t := affinityTerm{WorkloadAffinityTerm: term, hosts: sets.New[string](), selectsSelf: selects(workload)}
+		for _, syncTarget := range syncTargets {
+			for _, w := range placements[syncTarget.Name] {
+				if (w.Namespace != workload.Namespace || w.Name != workload.Name) && selects(w) {
+					t.hosts.Insert(syncTarget.Name)
+					break
+				}
+			}
+		}
+		e.terms = append(e.terms, t)
+	}
+	return e, nil
+}
+
+// Filter rejects SyncTargets that do not satisfy the required terms.
+func (e *WorkloadAffinityEvaluator) Filter(syncTarget *tmcv1alpha1.SyncTarget) string {
+	for _, t := range e.terms {
+		if t.Type != placementv1alpha1.WorkloadAffinityRequired {
+			continue
+		}
+		hosts := t.hosts.Has(syncTarget.Name)
+		switch t.Relation {
+		case placementv1alpha1.WorkloadAffinityPlaceWith:
+			if !hosts && !(t.selectsSelf && t.hosts.Len() == 0) {
+				return fmt.Sprintf("hosts no workload matching %s", metav1.FormatLabelSelector(&t.Selector))
+			}
+		case placementv1alpha1.WorkloadAffinityNeverColocateWith:
+			if hosts {
+				return fmt.Sprintf("hosts a workload matching %s", metav1.FormatLabelSelector(&t.Selector))
+			}
+		}
+	}
+	return ""
+}
+
+// Score prefers feasible SyncTargets satisfying the preferred terms of
+// higher total weight. It returns nil if the terms do not tell the
+// SyncTargets apart.
+func (e *WorkloadAffinityEvaluator) Score(feasible []*tmcv1alpha1.SyncTarget) map[string]int {
+	raw := make(map[string]int, len(feasible))
+	lowest, highest := 0, 0
+	for i, syncTarget := range feasible {
+		sum := 0
+		for _, t := range e.terms {
+			if t.Type == placementv1alpha1.WorkloadAffinityRequired || !t.hosts.Has(syncTarget.Name) {
+				continue
+			}
+			weight := max(1, int(t.Weight))
+			if t.Relation == placementv1alpha1.WorkloadAffinityNeverColocateWith {
+				weight = -weight
+			}
+			sum += weight
+		}
+		raw[syncTarget.Name] = sum
+		if i == 0 || sum < lowest {
+			lowest = sum
+		}
+		if i == 0 || sum > highest {
+			highest = sum
+		}
+	}
+	if lowest == highest {
+		return nil
+	}
+	scores := make(map[string]int, len(raw))
+	for name, sum := range raw {
+		scores[name] = maxScore * (sum - lowest) / (highest - lowest)
+	}
+	return scores
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func workloadAffinityTerm(relation placementv1alpha1.WorkloadAffinityRelation, typ placementv1alpha1.WorkloadAffinityType, app string) placementv1alpha1.WorkloadAffinityTerm {
+	return placementv1alpha1.WorkloadAffinityTerm{
+		Relation: relation,
+		Selector: metav1.LabelSelector{MatchLabels: map[string]string{"app": app}},
+		Type:     typ,
+	}
+}
+
+func TestPlaceWorkloadAffinity(t *testing.T) {
+	targets := []*tmcv1alpha1.SyncTarget{syncTarget("eu-1", "eu"), syncTarget("eu-2", "eu"), syncTarget("us-1", "us")}
+	placements := PlacementIndex{
+		"eu-1": {{Namespace: "default", Name: "db", Labels: map[string]string{"app": "db"}}},
+		"eu-2": {{Namespace: "other", Name: "db", Labels: map[string]string{"app": "db"}}},
+		"us-1": {{Namespace: "default", Name: "batch", Labels: map[string]string{"app": "batch"}}},
+	}
+	workload := PlacedWorkload{Namespace: "default", Name: "web", Labels: map[string]string{"app": "web"}}
+
+	decision, err := NewEngine().Place(Request{
+		Policy: placementv1alpha1.PlacementPolicySpec{WorkloadAffinity: []placementv1alpha1.WorkloadAffinityTerm{
+			workloadAffinityTerm(placementv1alpha1.WorkloadAffinityPlaceWith, placementv1alpha1.WorkloadAffinityRequired, "db"),
+		}},
+		SyncTargets: targets,
+		Workload:    workload,
+		Placements:  placements,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-1"}, names(decision.Targets), "terms select workloads in the namespace of the workload by default")
+	require.Equal(t, "hosts no workload matching app=db", decision.Rejected["eu-2"])
+
+	decision, err = NewEngine().Place(Request{
+		Policy: placementv1alpha1.PlacementPolicySpec{WorkloadAffinity: []placementv1alpha1.WorkloadAffinityTerm{
+			workloadAffinityTerm(placementv1alpha1.WorkloadAffinityNeverColocateWith, placementv1alpha1.WorkloadAffinityRequired, "batch"),
+			workloadAffinityTerm(placementv1alpha1.WorkloadAffinityPlaceWith, placementv1alpha1.WorkloadAffinityPreferred, "db"),
+		}},
+		SyncTargets: targets,
+		Workload:    workload,
+		Placements:  placements,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu-1", "eu-2"}, names(decision.Targets))
+	require.Equal(t, "hosts a workload matching app=batch", decision.Rejected["us-1"])
+	require.Equal(t, map[string]int{"eu-1": 100, "eu-2": 0}, decision.ScorerScores[string(placementv1alpha1.ScorerWorkloadAffinity)])
+}
+
+func TestWorkloadAffinitySelectsSelf(t *testing.T) {
+	targets := []*tmcv1alpha1.SyncTarget{syncTarget("eu-1", "eu"), syncTarget("us-1", "us")}
+	terms := []placementv1alpha1.WorkloadAffinityTerm{
+		workloadAffinityTerm(placementv1alpha1.WorkloadAffinityPlaceWith, placementv1alpha1.WorkloadAffinityRequired, "web"),
+	}
+	web := PlacedWorkload{Namespace: "default", Name: "web-1", Labels: map[string]string{"app": "web"}}
+
+	e, err := NewWorkloadAffinityEvaluator(terms, web, targets, PlacementIndex{"us-1": {web}})
+	require.NoError(t, err)
+	require.Empty(t, e.Filter(targets[0]), "the first workload of a group is placed anywhere")
+
+	e, err = NewWorkloadAffinityEvaluator(terms, web, targets, PlacementIndex{"us-1": {{Namespace: "default", Name: "web-2", Labels: map[string]string{"app": "web"}}}})
+	require.NoError(t, err)
+	require.NotEmpty(t, e.Filter(targets[0]))
+	require.Empty(t, e.Filter(targets[1]), "later ones join the group")
+}
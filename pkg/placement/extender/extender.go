@@ -0,0 +1,214 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extender
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kcp-dev/kcp/pkg/placement/framework"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// defaultTimeout bounds the calls of extenders whose timeout is not set.
+const defaultTimeout = 5 * time.Second
+
+// Configuration configures the extenders of the placement controller,
+// usually read from a file.
+type Configuration struct {
+	Extenders []Config `json:"extenders"`
+}
+
+// Config configures an extender.
+type Config struct {
+	// Name PlacementPolicies select the extender by.
+	Name string `json:"name"`
+	// Address is the gRPC target of the extender, e.g. extender.example.com:443.
+	Address string `json:"address"`
+	// CAFile verifies the serving certificate of the extender. The system
+	// roots verify it if empty.
+	CAFile string `json:"caFile,omitempty"`
+	// ServerName overrides the name the serving certificate is verified for.
+	ServerName string `json:"serverName,omitempty"`
+	// Insecure connects without TLS.
+	Insecure bool `json:"insecure,omitempty"`
+	// Timeout bounds every call. Defaults to 5s.
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+	// Ignorable makes placement go on as if the extender was not selected
+	// when it fails, rather than rejecting all SyncTargets or failing
+	// binding.
+	Ignorable bool `json:"ignorable,omitempty"`
+}
+
+// LoadConfiguration returns the YAML or JSON Configuration in the file at
+// path.
+func LoadConfiguration(path string) (*Configuration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config Configuration
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to decode extender configuration %s: %w", path, err)
+	}
+	names := map[string]bool{}
+	for i, e := range config.Extenders {
+		switch {
+		case e.Name == "":
+			return nil, fmt.Errorf("invalid extender configuration %s: extender %d has no name", path, i)
+		case e.Address == "":
+			return nil, fmt.Errorf("invalid extender configuration %s: extender %q has no address", path, e.Name)
+		case names[e.Name]:
+			return nil, fmt.Errorf("invalid extender configuration %s: extender %q is configured twice", path, e.Name)
+		}
+		names[e.Name] = true
+	}
+	return &config, nil
+}
+
+// Register connects to the extenders of config and registers them with
+// registry.
+func Register(registry *framework.Registry, config *Configuration) error {
+	for _, c := range config.Extenders {
+		p, err := NewPlugin(c)
+		if err != nil {
+			return err
+		}
+		if err := registry.Register(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Plugin is the scheduler plugin of an extender. Every extension point
+// calls the extender, which skips those it does not implement.
+type Plugin struct {
+	config Config
+	conn   grpc.ClientConnInterface
+}
+
+var (
+	_ framework.FilterPlugin = &Plugin{}
+	_ framework.ScorePlugin  = &Plugin{}
+	_ framework.BindPlugin   = &Plugin{}
+)
+
+// NewPlugin returns the scheduler plugin of the extender. It connects
+// lazily, on the first call.
+func NewPlugin(config Config) (*Plugin, error) {
+	creds := insecure.NewCredentials()
+	if !config.Insecure {
+		if config.CAFile != "" {
+			var err error
+			if creds, err = credentials.NewClientTLSFromFile(config.CAFile, config.ServerName); err != nil {
+				return nil, fmt.Errorf("invalid CA file of extender %q: %w", config.Name, err)
+			}
+		} else {
+			creds = credentials.NewClientTLSFromCert(nil, config.ServerName)
+		}
+	}
+	conn, err := grpc.NewClient(config.Address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("invalid address of extender %q: %w", config.Name, err)
+	}
+	return newPlugin(config, conn), nil
+}
+
+func newPlugin(config Config, conn grpc.ClientConnInterface) *Plugin {
+	if config.Timeout.Duration <= 0 {
+		config.Timeout.Duration = defaultTimeout
+	}
+	return &Plugin{config: config, conn: conn}
+}
+
+// Name returns the plugin name of the extender.
+func (p *Plugin) Name() string {
+	return framework.ExtenderPluginName(p.config.Name)
+}
+
+// Filter asks the extender whether syncTarget is feasible. SyncTargets are
+// rejected while the extender fails, unless it is ignorable.
+func (p *Plugin) Filter(policy *placementv1alpha1.PlacementPolicySpec, syncTarget *tmcv1alpha1.SyncTarget) string {
+	resp := &FilterResponse{}
+	if err := p.invoke(context.Background(), filterMethod, &FilterRequest{Policy: policy, SyncTarget: syncTarget}, resp); err != nil {
+		if p.skip(err) {
+			return ""
+		}
+		return fmt.Sprintf("extender %q failed: %v", p.config.Name, err)
+	}
+	return resp.Reason
+}
+
+// Score asks the extender for the scores of the feasible SyncTargets. The
+// scores of a failing extender are ignored.
+func (p *Plugin) Score(policy *placementv1alpha1.PlacementPolicySpec, feasible []*tmcv1alpha1.SyncTarget) map[string]int {
+	resp := &ScoreResponse{}
+	if err := p.invoke(context.Background(), scoreMethod, &ScoreRequest{Policy: policy, SyncTargets: feasible}, resp); err != nil {
+		if !p.skip(err) {
+			utilruntime.HandleError(fmt.Errorf("extender %q failed to score: %w", p.config.Name, err))
+		}
+		return nil
+	}
+	if len(resp.Scores) == 0 {
+		return nil
+	}
+	return resp.Scores
+}
+
+// Bind tells the extender the targets chosen for workload. Binding fails
+// while the extender fails, unless it is ignorable.
+func (p *Plugin) Bind(ctx context.Context, workload framework.Workload, targets []workloadv1alpha1.TargetPlacement) error {
+	req := &BindRequest{Workspace: workload.ClusterName.String(), Namespace: workload.Namespace, Name: workload.Name, Targets: targets}
+	if err := p.invoke(ctx, bindMethod, req, &BindResponse{}); err != nil && !p.skip(err) {
+		return fmt.Errorf("extender %q failed: %w", p.config.Name, err)
+	}
+	return nil
+}
+
+func (p *Plugin) invoke(ctx context.Context, method string, req, resp interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, p.config.Timeout.Duration)
+	defer cancel()
+	return p.conn.Invoke(ctx, method, req, resp, grpc.CallContentSubtype(CodecName))
+}
+
+// skip returns whether the call failing with err is skipped, because the
+// extender does not implement it or is ignorable.
+func (p *Plugin) skip(err error) bool {
+	if status.Code(err) == codes.Unimplemented {
+		return true
+	}
+	if p.config.Ignorable {
+		utilruntime.HandleError(fmt.Errorf("ignoring failure of extender %q: %w", p.config.Name, err))
+		return true
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extender
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kcp-dev/kcp/pkg/placement/framework"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// euOnly filters and scores SyncTargets by location and does not bind.
+type euOnly struct {
+	UnimplementedExtenderServer
+	bound []string
+}
+
+func (e *euOnly) Filter(_ context.Context, req *FilterRequest) (*FilterResponse, error) {
+	if req.SyncTarget.Spec.Location != "eu" {
+		return &FilterResponse{Reason: "is not in the eu"}, nil
+	}
+	return &FilterResponse{}, nil
+}
+
+func (e *euOnly) Score(_ context.Context, req *ScoreRequest) (*ScoreResponse, error) {
+	scores := map[string]int{}
+	for _, t := range req.SyncTargets {
+		scores[t.Name] = len(t.Name) * 10
+	}
+	return &ScoreResponse{Scores: scores}, nil
+}
+
+// failing fails every call.
+type failing struct{}
+
+func (failing) Filter(context.Context, *FilterRequest) (*FilterResponse, error) {
+	return nil, errors.New("boom")
+}
+
+func (failing) Score(context.Context, *ScoreRequest) (*ScoreResponse, error) {
+	return nil, errors.New("boom")
+}
+
+func (failing) Bind(context.Context, *BindRequest) (*BindResponse, error) {
+	return nil, errors.New("boom")
+}
+
+func serve(t *testing.T, srv ExtenderServer) grpc.ClientConnInterface {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	RegisterExtenderServer(s, srv)
+	go func() { _ = s.Serve(listener) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func syncTarget(name, location string) *tmcv1alpha1.SyncTarget {
+	return &tmcv1alpha1.SyncTarget{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: tmcv1alpha1.SyncTargetSpec{Location: location}}
+}
+
+func TestPlugin(t *testing.T) {
+	srv := &euOnly{}
+	p := newPlugin(Config{Name: "eu-only"}, serve(t, srv))
+	policy := &placementv1alpha1.PlacementPolicySpec{}
+
+	require.Equal(t, "extender/eu-only", p.Name())
+	require.Empty(t, p.Filter(policy, syncTarget("eu-1", "eu")))
+	require.Equal(t, "is not in the eu", p.Filter(policy, syncTarget("us-1", "us")))
+	require.Equal(t, map[string]int{"eu-1": 40, "eu-west-1": 90}, p.Score(policy, []*tmcv1alpha1.SyncTarget{syncTarget("eu-1", "eu"), syncTarget("eu-west-1", "eu")}))
+	require.NoError(t, p.Bind(context.Background(), framework.Workload{Name: "web"}, []workloadv1alpha1.TargetPlacement{{SyncTarget: "eu-1"}}), "unimplemented methods are skipped")
+}
+
+func TestPluginFailures(t *testing.T) {
+	conn := serve(t, failing{})
+	policy := &placementv1alpha1.PlacementPolicySpec{}
+	targets := []*tmcv1alpha1.SyncTarget{syncTarget("eu-1", "eu")}
+
+	p := newPlugin(Config{Name: "flaky"}, conn)
+	require.Equal(t, `extender "flaky" failed: rpc error: code = Unknown desc = boom`, p.Filter(policy, targets[0]))
+	require.Nil(t, p.Score(policy, targets))
+	require.ErrorContains(t, p.Bind(context.Background(), framework.Workload{Name: "web"}, nil), "boom")
+
+	p = newPlugin(Config{Name: "flaky", Ignorable: true}, conn)
+	require.Empty(t, p.Filter(policy, targets[0]))
+	require.Nil(t, p.Score(policy, targets))
+	require.NoError(t, p.Bind(context.Background(), framework.Workload{Name: "web"}, nil))
+}
+
+func TestLoadConfiguration(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "extenders.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	config, err := LoadConfiguration(write(`
+extenders:
+- name: cost
+  address: cost.example.com:443
+  timeout: 2s
+  ignorable: true
+`))
+	require.NoError(t, err)
+	require.Equal(t, []Config{{Name: "cost", Address: "cost.example.com:443", Timeout: metav1.Duration{Duration: 2e9}, Ignorable: true}}, config.Extenders)
+
+	registry := framework.NewRegistry()
+	require.NoError(t, Register(registry, config))
+	require.Equal(t, []string{"extender/cost"}, registry.Names())
+
+	_, err = LoadConfiguration(write(`
+extenders:
+- name: cost
+  address: a:443
+- name: cost
+  address: b:443
+`))
+	require.ErrorContains(t, err, `extender "cost" is configured twice`)
+	_, err = LoadConfiguration(write("extenders:\n- name: cost\n"))
+	require.ErrorContains(t, err, `extender "cost" has no address`)
+}
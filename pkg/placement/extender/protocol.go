@@ -0,0 +1,162 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package extender lets the placement engine delegate filtering, scoring
+// and binding of SyncTargets to out-of-process services, like kube-scheduler
+// extenders. Extenders are configured on the placement controller by name,
+// and PlacementPolicies select them in spec.extender.
+//
+// Extenders serve the gRPC service tmc.placement.v1alpha1.Extender with the
+// unary methods Filter, Score and Bind. Messages are the request and
+// response types of this package, encoded as JSON with the gRPC content
+// subtype "json", i.e. the content type application/grpc+json. Go extenders
+// register an ExtenderServer with RegisterExtenderServer. Methods an
+// extender does not implement return the Unimplemented status code and are
+// skipped.
+package extender
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/status"
+
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+const (
+	// ServiceName is the name of the gRPC service extenders serve.
+	ServiceName = "tmc.placement.v1alpha1.Extender"
+	// CodecName is the gRPC content subtype of the messages.
+	CodecName = "json"
+
+	filterMethod = "/" + ServiceName + "/Filter"
+	scoreMethod  = "/" + ServiceName + "/Score"
+	bindMethod   = "/" + ServiceName + "/Bind"
+)
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+// codec encodes messages as JSON.
+type codec struct{}
+
+func (codec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (codec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (codec) Name() string                               { return CodecName }
+
+// FilterRequest asks whether a SyncTarget is feasible for the workloads of
+// a policy.
+type FilterRequest struct {
+	Policy     *placementv1alpha1.PlacementPolicySpec `json:"policy"`
+	SyncTarget *tmcv1alpha1.SyncTarget                `json:"syncTarget"`
+}
+
+// FilterResponse tells why the SyncTarget is rejected, or nothing if it is
+// feasible.
+type FilterResponse struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// ScoreRequest asks for the scores of the feasible SyncTargets for the
+// workloads of a policy.
+type ScoreRequest struct {
+	Policy      *placementv1alpha1.PlacementPolicySpec `json:"policy"`
+	SyncTargets []*tmcv1alpha1.SyncTarget              `json:"syncTargets"`
+}
+
+// ScoreResponse scores SyncTargets from 0 to 100 by name, higher is better.
+// Missing targets score 0. No scores means the extender does not apply.
+type ScoreResponse struct {
+	Scores map[string]int `json:"scores,omitempty"`
+}
+
+// BindRequest tells the SyncTargets chosen for a workload, before the
+// decision is recorded.
+type BindRequest struct {
+	Workspace string                             `json:"workspace"`
+	Namespace string                             `json:"namespace"`
+	Name      string                             `json:"name"`
+	Targets   []workloadv1alpha1.TargetPlacement `json:"targets"`
+}
+
+// BindResponse acknowledges a BindRequest. An error fails the placement,
+// which is retried.
+type BindResponse struct{}
+
+// ExtenderServer is the server side of the extender protocol.
+type ExtenderServer interface {
+	Filter(context.Context, *FilterRequest) (*FilterResponse, error)
+	Score(context.Context, *ScoreRequest) (*ScoreResponse, error)
+	Bind(context.Context, *BindRequest) (*BindResponse, error)
+}
+
+// UnimplementedExtenderServer implements no method. Extenders embed it to
+// implement only some methods.
+type UnimplementedExtenderServer struct{}
+
+func (UnimplementedExtenderServer) Filter(context.Context, *FilterRequest) (*FilterResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "Filter is not implemented")
+}
+
+func (UnimplementedExtenderServer) Score(context.Context, *ScoreRequest) (*ScoreResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "Score is not implemented")
+}
+
+func (UnimplementedExtenderServer) Bind(context.Context, *BindRequest) (*BindResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "Bind is not implemented")
+}
+
+// RegisterExtenderServer registers the extender service of srv with s.
+func RegisterExtenderServer(s grpc.ServiceRegistrar, srv ExtenderServer) {
+	s.RegisterService(&serviceDesc, srv)
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*ExtenderServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryMethod("Filter", ExtenderServer.Filter),
+		unaryMethod("Score", ExtenderServer.Score),
+		unaryMethod("Bind", ExtenderServer.Bind),
+	},
+}
+
+// unaryMethod describes the method name of the service, served by call.
+func unaryMethod[Req, Resp any](name string, call func(ExtenderServer, context.Context, *Req) (*Resp, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			req := new(Req)
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return call(srv.(ExtenderServer), ctx, req)
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + ServiceName + "/" + name}
+			return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return call(srv.(ExtenderServer), ctx, req.(*Req))
+			})
+		},
+	}
+}
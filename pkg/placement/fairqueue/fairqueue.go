@@ -0,0 +1,275 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fairqueue provides workqueue storage that shares the workers of a
+// controller fairly between workspaces, so that a workspace flooding the
+// queue does not starve the others. Workspaces are served in proportion to
+// their shares, the placement policies of a workspace in turn, and
+// workspaces with items waiting longer than the starvation threshold are
+// served first.
+package fairqueue
+
+import (
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	defaultShare               = 1
+	defaultStarvationThreshold = 30 * time.Second
+)
+
+// Flow identifies the items sharing a FIFO.
+type Flow struct {
+	// Workspace is the logical cluster of the item. Workspaces are served in
+	// proportion to their shares.
+	Workspace string
+	// Policy is the placement policy of the item. The policies of a
+	// workspace are served in turn.
+	Policy string
+}
+
+// Options configure a queue. Zero values mean the defaults.
+type Options struct {
+	// Shares are the shares of workspaces, by logical cluster name. A
+	// workspace with twice the shares of another is served twice as often
+	// while both have queued items.
+	Shares map[string]int
+	// DefaultShare is the share of workspaces not in Shares.
+	DefaultShare int
+	// StarvationThreshold is how long an item may wait before it is served
+	// ahead of the fair order.
+	StarvationThreshold time.Duration
+}
+
+type entry[T comparable] struct {
+	item  T
+	added time.Time
+}
+
+type workspace[T comparable] struct {
+	// finish is the virtual time at which the workspace was last served,
+	// advancing by 1/share per served item.
+	finish float64
+	// policies holds a FIFO per policy, served round robin in the order
+	// of policyOrder.
+	policies    map[string][]entry[T]
+	policyOrder []string
+	next        int
+	len         int
+}
+
+// queue is a workqueue.Queue serving flows fairly.
+type queue[T comparable] struct {
+	flowOf  func(item T) Flow
+	options Options
+	now     func() time.Time
+
+	// workspaces holds the workspaces with queued items, and idle ones
+	// served ahead of the virtual time until it catches up.
+	workspaces map[string]*workspace[T]
+	flows      map[T]Flow
+	// virtual is the virtual time of the last served workspace. Workspaces
+	// becoming active start there, so that idle workspaces do not bank
+	// service.
+	virtual float64
+}
+
+// NewQueue returns the storage of a workqueue that serves the flows of
+// items, as returned by flowOf, fairly.
+func NewQueue[T comparable](flowOf func(item T) Flow, options Options) workqueue.Queue[T] {
+	return newQueue(flowOf, options)
+}
+
+func newQueue[T comparable](flowOf func(item T) Flow, options Options) *queue[T] {
+	if options.DefaultShare <= 0 {
+		options.DefaultShare = defaultShare
+	}
+	if options.StarvationThreshold <= 0 {
+		options.StarvationThreshold = defaultStarvationThreshold
+	}
+	return &queue[T]{
+		flowOf:     flowOf,
+		options:    options,
+		now:        time.Now,
+		workspaces: map[string]*workspace[T]{},
+		flows:      map[T]Flow{},
+	}
+}
+
+// NewRateLimitingQueue returns a rate limiting workqueue that serves the
+// flows of items fairly.
+func NewRateLimitingQueue[T comparable](name string, flowOf func(item T) Flow, options Options) workqueue.TypedRateLimitingInterface[T] {
+	Register()
+	return workqueue.NewTypedRateLimitingQueueWithConfig(
+		workqueue.DefaultTypedControllerRateLimiter[T](),
+		workqueue.TypedRateLimitingQueueConfig[T]{
+			Name: name,
+			DelayingQueue: workqueue.NewTypedDelayingQueueWithConfig(workqueue.TypedDelayingQueueConfig[T]{
+				Name: name,
+				Queue: workqueue.NewTypedWithConfig(workqueue.TypedQueueConfig[T]{
+					Name:  name,
+					Queue: NewQueue(flowOf, options),
+				}),
+			}),
+		},
+	)
+}
+
+func (q *queue[T]) share(workspace string) int {
+	if share, found := q.options.Shares[workspace]; found && share > 0 {
+		return share
+	}
+	return q.options.DefaultShare
+}
+
+// Touch keeps the position of an item queued again.
+func (q *queue[T]) Touch(item T) {}
+
+func (q *queue[T]) Push(item T) {
+	flow := q.flowOf(item)
+	ws, found := q.workspaces[flow.Workspace]
+	if !found {
+		ws = &workspace[T]{policies: map[string][]entry[T]{}}
+		q.workspaces[flow.Workspace] = ws
+	}
+	if ws.len == 0 {
+		ws.finish = max(ws.finish, q.virtual)
+	}
+	if _, found := ws.policies[flow.Policy]; !found {
+		ws.policyOrder = append(ws.policyOrder, flow.Policy)
+	}
+	ws.policies[flow.Policy] = append(ws.policies[flow.Policy], entry[T]{item: item, added: q.now()})
+	ws.len++
+	q.flows[item] = flow
+}
+
+func (q *queue[T]) Len() int {
+	return len(q.flows)
+}
+
+func (q *queue[T]) Pop() T {
+	var zero T
+	if len(q.flows) == 0 {
+		return zero
+	}
+	now := q.now()
+
+	// Serve the workspace furthest behind, or the one furthest behind among
+	// those with a starving item. Boosting per workspace rather than serving
+	// the globally oldest item keeps a flooding workspace, whose items all
+	// starve eventually, from turning the queue into a FIFO.
+	var selected, starving *workspace[T]
+	var selectedName, starvingName string
+	for name, ws := range q.workspaces {
+		if ws.len == 0 {
+			if ws.finish <= q.virtual {
+				delete(q.workspaces, name)
+			}
+			continue
+		}
+		if behind(ws, name, selected, selectedName) {
+			selected, selectedName = ws, name
+		}
+		if _, oldest := ws.oldest(); now.Sub(oldest) >= q.options.StarvationThreshold && behind(ws, name, starving, starvingName) {
+			starving, starvingName = ws, name
+		}
+	}
+	if starving != nil {
+		policy, _ := starving.oldest()
+		starvedItems.WithLabelValues(starvingName).Inc()
+		return q.take(Flow{Workspace: starvingName, Policy: policy}, now)
+	}
+
+	// Otherwise serve the next policy of the workspace furthest behind.
+	for range selected.policyOrder {
+		policy := selected.policyOrder[selected.next%len(selected.policyOrder)]
+		selected.next++
+		if len(selected.policies[policy]) > 0 {
+			return q.take(Flow{Workspace: selectedName, Policy: policy}, now)
+		}
+	}
+	return zero
+}
+
+// behind returns whether workspace ws is served before other, the one
+// furthest behind first, so that Pop does not depend on the order of map
+// iteration.
+func behind[T comparable](ws *workspace[T], name string, other *workspace[T], otherName string) bool {
+	return other == nil || ws.finish < other.finish || (ws.finish == other.finish && name < otherName)
+}
+
+// oldest returns the policy of the longest waiting item of the workspace and
+// when it was added.
+func (ws *workspace[T]) oldest() (string, time.Time) {
+	var policy string
+	var added time.Time
+	for _, p := range ws.policyOrder {
+		if fifo := ws.policies[p]; len(fifo) > 0 && (added.IsZero() || fifo[0].added.Before(added)) {
+			policy, added = p, fifo[0].added
+		}
+	}
+	return policy, added
+}
+
+// take removes the head of the FIFO of flow and charges its workspace.
+func (q *queue[T]) take(flow Flow, now time.Time) T {
+	ws := q.workspaces[flow.Workspace]
+	fifo := ws.policies[flow.Policy]
+	e := fifo[0]
+	// Avoid memory leaks of the backing array, as in the default queue.
+	fifo[0] = entry[T]{}
+	ws.policies[flow.Policy] = fifo[1:]
+	ws.len--
+	delete(q.flows, e.item)
+
+	q.virtual = max(q.virtual, ws.finish)
+	ws.finish += 1 / float64(q.share(flow.Workspace))
+	waitDuration.Observe(now.Sub(e.added).Seconds())
+
+	if len(ws.policies[flow.Policy]) == 0 {
+		q.dropPolicy(ws, flow.Policy)
+	}
+	switch {
+	case len(q.flows) == 0:
+		// Nobody waits, so no workspace is owed service.
+		clear(q.workspaces)
+		q.virtual = 0
+	case ws.len == 0 && ws.finish <= q.virtual:
+		delete(q.workspaces, flow.Workspace)
+	}
+	return e.item
+}
+
+func (q *queue[T]) dropPolicy(ws *workspace[T], policy string) {
+	delete(ws.policies, policy)
+	for i, p := range ws.policyOrder {
+		if p != policy {
+			continue
+		}
+		ws.policyOrder = append(ws.policyOrder[:i], ws.policyOrder[i+1:]...)
+		if ws.next > i {
+			ws.next--
+		}
+		break
+	}
+	if len(ws.policyOrder) > 0 {
+		ws.next %= len(ws.policyOrder)
+	} else {
+		ws.next = 0
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fairqueue
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// flowOfKey parses keys of the form workspace/policy/name.
+func flowOfKey(key string) Flow {
+	parts := strings.SplitN(key, "/", 3)
+	return Flow{Workspace: parts[0], Policy: parts[1]}
+}
+
+func drain(q *queue[string]) []string {
+	var order []string
+	for q.Len() > 0 {
+		order = append(order, q.Pop())
+	}
+	return order
+}
+
+func TestQueueSharesWorkspaces(t *testing.T) {
+	q := newQueue(flowOfKey, Options{Shares: map[string]int{"big": 2}})
+	now := time.Now()
+	q.now = func() time.Time { return now }
+
+	// The flooding workspace is queued first.
+	for i := range 6 {
+		q.Push(fmt.Sprintf("flood/p/%d", i))
+	}
+	for i := range 2 {
+		q.Push(fmt.Sprintf("small/p/%d", i))
+	}
+	for i := range 4 {
+		q.Push(fmt.Sprintf("big/p/%d", i))
+	}
+	require.Equal(t, 12, q.Len())
+
+	// While all have items, big is served twice as often as the others.
+	require.Equal(t, []string{
+		"big/p/0", "flood/p/0", "small/p/0", "big/p/1",
+		"big/p/2", "flood/p/1", "small/p/1", "big/p/3",
+		"flood/p/2", "flood/p/3", "flood/p/4", "flood/p/5",
+	}, drain(q))
+}
+
+func TestQueueRoundRobinsPolicies(t *testing.T) {
+	q := newQueue(flowOfKey, Options{})
+	for i := range 3 {
+		q.Push(fmt.Sprintf("ws/a/%d", i))
+	}
+	q.Push("ws/b/0")
+	q.Push("ws/c/0")
+
+	require.Equal(t, []string{"ws/a/0", "ws/b/0", "ws/c/0", "ws/a/1", "ws/a/2"}, drain(q))
+}
+
+func TestQueueIdleWorkspacesDoNotBankService(t *testing.T) {
+	q := newQueue(flowOfKey, Options{})
+	for i := range 4 {
+		q.Push(fmt.Sprintf("busy/p/%d", i))
+	}
+	require.Equal(t, "busy/p/0", q.Pop())
+	require.Equal(t, "busy/p/1", q.Pop())
+
+	// A workspace arriving late shares from now on instead of catching up.
+	q.Push("late/p/0")
+	q.Push("late/p/1")
+	require.Equal(t, []string{"late/p/0", "busy/p/2", "late/p/1", "busy/p/3"}, drain(q))
+}
+
+func TestQueueForgetsIdleWorkspaces(t *testing.T) {
+	q := newQueue(flowOfKey, Options{})
+	for i := range 100 {
+		q.Push(fmt.Sprintf("ws-%d/p/0", i))
+	}
+	drain(q)
+	require.Empty(t, q.workspaces)
+
+	for i := range 4 {
+		q.Push(fmt.Sprintf("busy/p/%d", i))
+	}
+	q.Push("once/p/0")
+	require.Equal(t, "busy/p/0", q.Pop())
+	require.Equal(t, "once/p/0", q.Pop())
+	require.Equal(t, "busy/p/1", q.Pop())
+	require.Contains(t, q.workspaces, "once", "served ahead of the virtual time")
+	require.Equal(t, "busy/p/2", q.Pop())
+	require.NotContains(t, q.workspaces, "once")
+	require.Equal(t, "busy/p/3", q.Pop())
+	require.Empty(t, q.workspaces)
+}
+
+func TestQueueBoostsStarvingItems(t *testing.T) {
+	q := newQueue(flowOfKey, Options{Shares: map[string]int{"flood": 100}, StarvationThreshold: time.Minute})
+	now := time.Now()
+	q.now = func() time.Time { return now }
+
+	q.Push("quiet/p/0")
+	for i := range 5 {
+		q.Push(fmt.Sprintf("flood/p/%d", i))
+	}
+	require.Equal(t, "flood/p/0", q.Pop())
+
+	now = now.Add(2 * time.Minute)
+	q.Push("flood/p/5")
+	require.Equal(t, "quiet/p/0", q.Pop(), "the oldest item starves")
+	require.Equal(t, "flood/p/1", q.Pop())
+}
+
+func TestQueueBoostsStarvingWorkspacesFairly(t *testing.T) {
+	q := newQueue(flowOfKey, Options{StarvationThreshold: time.Minute})
+	now := time.Now()
+	q.now = func() time.Time { return now }
+
+	for i := range 4 {
+		q.Push(fmt.Sprintf("flood/p/%d", i))
+	}
+	now = now.Add(time.Second)
+	q.Push("quiet/p/0")
+	q.Push("quiet/p/1")
+
+	// Everything starves, and the workspaces still share instead of the
+	// flooding workspace being drained first.
+	now = now.Add(2 * time.Minute)
+	require.Equal(t, []string{
+		"flood/p/0", "quiet/p/0", "flood/p/1", "quiet/p/1", "flood/p/2", "flood/p/3",
+	}, drain(q))
+}
+
+func TestRateLimitingQueue(t *testing.T) {
+	q := NewRateLimitingQueue[string]("test", flowOfKey, Options{})
+	defer q.ShutDown()
+
+	for _, key := range []string{"a/p/0", "a/p/1", "a/p/2", "b/p/0"} {
+		q.Add(key)
+	}
+	// Re-adding a queued item keeps its position.
+	q.Add("a/p/0")
+
+	var order []string
+	for q.Len() > 0 {
+		key, _ := q.Get()
+		order = append(order, key)
+		q.Done(key)
+	}
+	require.Equal(t, []string{"a/p/0", "b/p/0", "a/p/1", "a/p/2"}, order)
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fairqueue
+
+import (
+	"sync"
+
+	compbasemetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+var (
+	starvedItems = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Name:           "tmc_placement_queue_starved_items_total",
+			Help:           "Number of placement queue items served ahead of the fair order because they waited longer than the starvation threshold, by workspace.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"workspace"},
+	)
+	waitDuration = compbasemetrics.NewHistogram(
+		&compbasemetrics.HistogramOpts{
+			Name:           "tmc_placement_queue_wait_duration_seconds",
+			Help:           "How long items waited in the placement queue before being served.",
+			Buckets:        compbasemetrics.ExponentialBuckets(0.001, 4, 10),
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+	)
+)
+
+var registerMetrics sync.Once
+
+// Register metrics.
+func Register() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(starvedItems)
+		legacyregistry.MustRegister(waitDuration)
+	})
+}
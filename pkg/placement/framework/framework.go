@@ -0,0 +1,193 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package framework lets third parties extend the placement engine with
+// scheduler plugins, like the kube-scheduler framework. Plugins are
+// registered by name, at any time, and PlacementPolicies select them in
+// spec.schedulerProfile. A plugin implements one or more extension points:
+//
+//   - Filter plugins reject SyncTargets, after the built-in filters;
+//   - Score plugins score feasible SyncTargets, weighted along the built-in
+//     scorers;
+//   - Bind plugins are told the SyncTargets chosen for a workload before the
+//     decision is recorded, and can fail it.
+package framework
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// DefaultWeight is the weight of score plugins whose weight is not set.
+const DefaultWeight = 20
+
+// ExtenderPluginName returns the name the plugin of the placement extender
+// with the given name is registered with. PlacementPolicies select
+// extenders in spec.extender rather than in spec.schedulerProfile.
+func ExtenderPluginName(name string) string {
+	return "extender/" + name
+}
+
+// Plugin is a scheduler plugin.
+type Plugin interface {
+	// Name is the name the plugin is registered with and selected by.
+	Name() string
+}
+
+// FilterPlugin rejects SyncTargets.
+type FilterPlugin interface {
+	Plugin
+	// Filter returns why a SyncTarget is rejected for workloads of the
+	// policy, or an empty string if it is feasible.
+	Filter(policy *placementv1alpha1.PlacementPolicySpec, syncTarget *tmcv1alpha1.SyncTarget) string
+}
+
+// ScorePlugin scores feasible SyncTargets.
+type ScorePlugin interface {
+	Plugin
+	// Score scores the feasible SyncTargets for workloads of the policy from
+	// 0 to 100, higher is better, by name. Missing targets score 0. A nil
+	// result means the plugin does not apply and its weight is ignored.
+	Score(policy *placementv1alpha1.PlacementPolicySpec, feasible []*tmcv1alpha1.SyncTarget) map[string]int
+}
+
+// BindPlugin is told the SyncTargets chosen for a workload.
+type BindPlugin interface {
+	Plugin
+	// Bind is called when the SyncTargets chosen for a workload change,
+	// before the decision is recorded. An error fails the placement, which
+	// is retried.
+	Bind(ctx context.Context, workload Workload, targets []workloadv1alpha1.TargetPlacement) error
+}
+
+// Workload identifies the WorkloadDistribution being placed.
+type Workload struct {
+	ClusterName logicalcluster.Name
+	Namespace   string
+	Name        string
+}
+
+// Registry holds scheduler plugins by name. It is safe for concurrent use.
+type Registry struct {
+	lock    sync.RWMutex
+	plugins map[string]Plugin
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{plugins: map[string]Plugin{}}
+}
+
+// DefaultRegistry is the registry of the placement engine of the placement
+// controller.
+var DefaultRegistry = NewRegistry()
+
+// Register adds a plugin. It fails if the plugin implements no extension
+// point or if another plugin has the same name.
+func (r *Registry) Register(plugin Plugin) error {
+	name := plugin.Name()
+	if name == "" {
+		return fmt.Errorf("scheduler plugins must have a name")
+	}
+	_, filter := plugin.(FilterPlugin)
+	_, score := plugin.(ScorePlugin)
+	_, bind := plugin.(BindPlugin)
+	if !filter && !score && !bind {
+		return fmt.Errorf("scheduler plugin %q implements no extension point", name)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, found := r.plugins[name]; found {
+		return fmt.Errorf("scheduler plugin %q is already registered", name)
+	}
+	r.plugins[name] = plugin
+	return nil
+}
+
+// Unregister removes the plugin with the given name, if any. Policies
+// selecting it fail to place until it is registered again.
+func (r *Registry) Unregister(name string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.plugins, name)
+}
+
+// Names returns the names of the registered plugins, sorted.
+func (r *Registry) Names() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	names := make([]string, 0, len(r.plugins))
+	for name := range r.plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Profile is the plugins selected by a SchedulerProfile, by extension point,
+// in the order they are listed.
+type Profile struct {
+	Filters []FilterPlugin
+	Scores  []WeightedScorePlugin
+	Binds   []BindPlugin
+}
+
+// WeightedScorePlugin is a score plugin and its weight.
+type WeightedScorePlugin struct {
+	ScorePlugin
+	Weight int32
+}
+
+// Profile returns the plugins selected by the profile. It fails if a plugin
+// is not registered. A nil profile selects no plugins.
+func (r *Registry) Profile(profile *placementv1alpha1.SchedulerProfile) (Profile, error) {
+	var p Profile
+	if profile == nil {
+		return p, nil
+	}
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	for _, config := range profile.Plugins {
+		plugin, found := r.plugins[config.Name]
+		if !found {
+			return Profile{}, fmt.Errorf("unknown scheduler plugin %q", config.Name)
+		}
+		if filter, ok := plugin.(FilterPlugin); ok {
+			p.Filters = append(p.Filters, filter)
+		}
+		if score, ok := plugin.(ScorePlugin); ok {
+			weight := int32(DefaultWeight)
+			if config.Weight != nil {
+				weight = *config.Weight
+			}
+			p.Scores = append(p.Scores, WeightedScorePlugin{ScorePlugin: score, Weight: weight})
+		}
+		if bind, ok := plugin.(BindPlugin); ok {
+			p.Binds = append(p.Binds, bind)
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/utils/ptr"
+
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+type named string
+
+func (n named) Name() string { return string(n) }
+
+type scorePlugin struct{ named }
+
+func (scorePlugin) Score(*placementv1alpha1.PlacementPolicySpec, []*tmcv1alpha1.SyncTarget) map[string]int {
+	return nil
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	require.ErrorContains(t, r.Register(named("nothing")), "no extension point")
+	require.ErrorContains(t, r.Register(scorePlugin{""}), "must have a name")
+	require.NoError(t, r.Register(scorePlugin{"b"}))
+	require.NoError(t, r.Register(scorePlugin{"a"}))
+	require.ErrorContains(t, r.Register(scorePlugin{"a"}), "already registered")
+	require.Equal(t, []string{"a", "b"}, r.Names())
+
+	p, err := r.Profile(nil)
+	require.NoError(t, err)
+	require.Empty(t, p.Scores)
+
+	p, err = r.Profile(&placementv1alpha1.SchedulerProfile{Plugins: []placementv1alpha1.SchedulerPlugin{
+		{Name: "b", Weight: ptr.To[int32](70)}, {Name: "a"},
+	}})
+	require.NoError(t, err)
+	require.Empty(t, p.Filters)
+	require.Empty(t, p.Binds)
+	require.Len(t, p.Scores, 2)
+	require.Equal(t, "b", p.Scores[0].Name())
+	require.Equal(t, int32(70), p.Scores[0].Weight)
+	require.Equal(t, int32(DefaultWeight), p.Scores[1].Weight)
+
+	r.Unregister("a")
+	_, err = r.Profile(&placementv1alpha1.SchedulerProfile{Plugins: []placementv1alpha1.SchedulerPlugin{{Name: "a"}}})
+	require.ErrorContains(t, err, `unknown scheduler plugin "a"`)
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package provisioning calls the webhooks creating the capacity of
+// provisionable SyncTargets.
+package provisioning
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/utils/ptr"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/tmc/credentials"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+const maxResponseBytes = 1 << 20
+
+// Review is the body of a provisioning webhook call. The request is sent,
+// and the webhook returns the review with the response set.
+type Review struct {
+	Request  *Request  `json:"request,omitempty"`
+	Response *Response `json:"response,omitempty"`
+}
+
+// Request asks to provision the capacity of a SyncTarget. The webhook may be
+// called more than once for the same capacity and must be idempotent.
+type Request struct {
+	// Workspace and SyncTarget name the SyncTarget to provision.
+	Workspace  string `json:"workspace"`
+	SyncTarget string `json:"syncTarget"`
+	// Workloads are the WorkloadDistributions placed on the SyncTarget, as
+	// namespace/name.
+	Workloads []string `json:"workloads"`
+	// Replicas is the number of replicas of scalable workloads placed on the
+	// SyncTarget.
+	Replicas int32 `json:"replicas"`
+}
+
+// Response is the answer of a provisioning webhook.
+type Response struct {
+	// Accepted is whether the capacity is being provisioned. The syncer of
+	// the SyncTarget is expected to become ready within the provisioning
+	// timeout.
+	Accepted bool `json:"accepted"`
+	// Message explains the answer, e.g. why provisioning was declined.
+	Message string `json:"message,omitempty"`
+}
+
+// WebhookClient calls provisioning webhooks. Clients are reused per CA
+// bundle and client certificate.
+type WebhookClient struct {
+	resolve credentials.ResolveFunc
+
+	lock    sync.Mutex
+	clients map[string]*http.Client
+}
+
+// NewWebhookClient returns a client of provisioning webhooks, authenticating
+// with the credentials resolve returns.
+func NewWebhookClient(resolve credentials.ResolveFunc) *WebhookClient {
+	return &WebhookClient{resolve: resolve, clients: map[string]*http.Client{}}
+}
+
+// Provision sends req to the webhook of provisioning and returns its
+// response.
+func (c *WebhookClient) Provision(ctx context.Context, provisioning *tmcv1alpha1.SyncTargetProvisioning, req *Request) (*Response, error) {
+	creds, err := c.resolve(ctx, logicalcluster.Name(req.Workspace), req.SyncTarget, provisioning.Credentials)
+	if err != nil {
+		return nil, err
+	}
+	client, err := c.client(provisioning.CABundle, creds)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(&Review{Request: req})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(ptr.Deref(provisioning.WebhookTimeoutSeconds, 10))*time.Second)
+	defer cancel()
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, provisioning.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if token := creds.BearerToken(); token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", httpResp.StatusCode)
+	}
+
+	var review Review
+	if err := json.NewDecoder(io.LimitReader(httpResp.Body, maxResponseBytes)).Decode(&review); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	if review.Response == nil {
+		return nil, errors.New("invalid response: no response set")
+	}
+	return review.Response, nil
+}
+
+func (c *WebhookClient) client(caBundle []byte, creds *credentials.Credentials) (*http.Client, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	key := string(caBundle) + "\x00" + creds.ClientKey()
+	if client, found := c.clients[key]; found {
+		return client, nil
+	}
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if len(caBundle) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caBundle) {
+			return nil, errors.New("invalid CA bundle")
+		}
+		config.RootCAs = pool
+	}
+	if creds != nil && creds.Certificate != nil {
+		config.Certificates = []tls.Certificate{*creds.Certificate}
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+	c.clients[key] = client
+	return client, nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioning
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kcp-dev/kcp/pkg/tmc/credentials"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func TestWebhookClientProvision(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var review Review
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if review.Request.SyncTarget == "broken" {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		review.Response = &Response{Accepted: review.Request.Replicas <= 10, Message: "pool is limited to 10 replicas"}
+		_ = json.NewEncoder(w).Encode(&review)
+	}))
+	defer server.Close()
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	c := NewWebhookClient(credentials.NewResolver(nil))
+	provisioning := &tmcv1alpha1.SyncTargetProvisioning{URL: server.URL, CABundle: caBundle}
+	resp, err := c.Provision(context.Background(), provisioning, &Request{SyncTarget: "burst", Replicas: 3})
+	require.NoError(t, err)
+	require.True(t, resp.Accepted)
+
+	resp, err = c.Provision(context.Background(), provisioning, &Request{SyncTarget: "burst", Replicas: 30})
+	require.NoError(t, err)
+	require.False(t, resp.Accepted)
+	require.Equal(t, "pool is limited to 10 replicas", resp.Message)
+
+	_, err = c.Provision(context.Background(), provisioning, &Request{SyncTarget: "broken"})
+	require.EqualError(t, err, "unexpected status 500")
+
+	_, err = c.Provision(context.Background(), &tmcv1alpha1.SyncTargetProvisioning{URL: server.URL}, &Request{})
+	require.Error(t, err, "the certificate is not trusted without the CA bundle")
+}
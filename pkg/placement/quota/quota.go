@@ -0,0 +1,210 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package quota computes the resources workloads use on the SyncTargets they
+// are placed on, and checks them against the MultiClusterResourceQuotas of
+// their workspace.
+package quota
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// workloadKind is where the pod template and replicas of a kind of workload
+// are.
+type workloadKind struct {
+	podSpec []string
+	// replicas is the path to the number of pods, which is 1 if unset.
+	replicas []string
+}
+
+var workloadKinds = map[schema.GroupKind]workloadKind{
+	{Kind: "Pod"}:                        {podSpec: []string{"spec"}},
+	{Kind: "ReplicationController"}:      {podSpec: []string{"spec", "template", "spec"}, replicas: []string{"spec", "replicas"}},
+	{Group: "apps", Kind: "Deployment"}:  {podSpec: []string{"spec", "template", "spec"}, replicas: []string{"spec", "replicas"}},
+	{Group: "apps", Kind: "ReplicaSet"}:  {podSpec: []string{"spec", "template", "spec"}, replicas: []string{"spec", "replicas"}},
+	{Group: "apps", Kind: "StatefulSet"}: {podSpec: []string{"spec", "template", "spec"}, replicas: []string{"spec", "replicas"}},
+	// The pods of a DaemonSet depend on the nodes of the SyncTarget, which
+	// are not known. One pod is counted.
+	{Group: "apps", Kind: "DaemonSet"}: {podSpec: []string{"spec", "template", "spec"}},
+	{Group: "batch", Kind: "Job"}:      {podSpec: []string{"spec", "template", "spec"}, replicas: []string{"spec", "parallelism"}},
+	{Group: "batch", Kind: "CronJob"}:  {podSpec: []string{"spec", "jobTemplate", "spec", "template", "spec"}, replicas: []string{"spec", "jobTemplate", "spec", "parallelism"}},
+}
+
+// HasPods returns whether workloads of the kind run pods using resources.
+func HasPods(gk schema.GroupKind) bool {
+	_, found := workloadKinds[gk]
+	return found
+}
+
+// WithReplicas returns a copy of the workload running the given number of
+// pods, or false if the number of pods of its kind cannot be set.
+func WithReplicas(workload *unstructured.Unstructured, replicas int64) (*unstructured.Unstructured, bool, error) {
+	kind, found := workloadKinds[workload.GroupVersionKind().GroupKind()]
+	if !found || kind.replicas == nil {
+		return nil, false, nil
+	}
+	workload = workload.DeepCopy()
+	if err := unstructured.SetNestedField(workload.Object, replicas, kind.replicas...); err != nil {
+		return nil, false, err
+	}
+	return workload, true, nil
+}
+
+// Count returns the resource counting the placements of workloads of the
+// resource gr, e.g. count/deployments.apps.
+func Count(gr schema.GroupResource) corev1.ResourceName {
+	return corev1.ResourceName("count/" + gr.String())
+}
+
+// Usage returns the resources the workload, of the resource gr, uses on
+// every SyncTarget it is placed on: the cpu and memory requested by its pods
+// times their number, and one placement of its resource. Workloads without
+// pods only count as a placement.
+func Usage(workload *unstructured.Unstructured, gr schema.GroupResource) (corev1.ResourceList, error) {
+	used := corev1.ResourceList{Count(gr): *resource.NewQuantity(1, resource.DecimalSI)}
+	kind, found := workloadKinds[workload.GroupVersionKind().GroupKind()]
+	if !found {
+		return used, nil
+	}
+	raw, found, err := unstructured.NestedMap(workload.Object, kind.podSpec...)
+	if err != nil || !found {
+		return used, err
+	}
+	spec := &corev1.PodSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, spec); err != nil {
+		return nil, fmt.Errorf("failed to convert the pod template of %s %s/%s: %w", workload.GetKind(), workload.GetNamespace(), workload.GetName(), err)
+	}
+	replicas := int64(1)
+	if kind.replicas != nil {
+		n, found, err := unstructured.NestedInt64(workload.Object, kind.replicas...)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			replicas = n
+		}
+	}
+	return quotav1.Add(used, Multiply(podRequests(spec), replicas)), nil
+}
+
+// podRequests returns the cpu and memory requests of a pod: those of its
+// containers, or of its largest init container if larger, and its
+// overhead.
+func podRequests(spec *corev1.PodSpec) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for _, c := range spec.Containers {
+		requests = quotav1.Add(requests, cpuAndMemory(c.Resources.Requests))
+	}
+	for _, c := range spec.InitContainers {
+		requests = quotav1.Max(requests, cpuAndMemory(c.Resources.Requests))
+	}
+	return quotav1.Add(requests, cpuAndMemory(spec.Overhead))
+}
+
+func cpuAndMemory(requests corev1.ResourceList) corev1.ResourceList {
+	return quotav1.Mask(requests, []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory})
+}
+
+// Multiply returns the resources of list times n.
+func Multiply(list corev1.ResourceList, n int64) corev1.ResourceList {
+	out := make(corev1.ResourceList, len(list))
+	for name, q := range list {
+		q := q.DeepCopy()
+		q.Mul(n)
+		out[name] = q
+	}
+	return out
+}
+
+// Exceeded returns a message for every resource of hard that used exceeds,
+// or nil if used is within hard.
+func Exceeded(hard, used corev1.ResourceList) []string {
+	var exceeded []string
+	for _, name := range sortedNames(hard) {
+		limit := hard[name]
+		if q, found := used[name]; found && q.Cmp(limit) > 0 {
+			exceeded = append(exceeded, fmt.Sprintf("%s: %s of at most %s", name, q.String(), limit.String()))
+		}
+	}
+	return exceeded
+}
+
+// Check returns why using increase more than the quotas report as used
+// would exceed them, or nothing if it would not. Only resources increase
+// adds to are checked, so that workloads using resources already over a
+// lowered quota can still shrink or move.
+func Check(quotas []*tmcv1alpha1.MultiClusterResourceQuota, increase corev1.ResourceList) string {
+	var increased []corev1.ResourceName
+	for name, q := range increase {
+		if q.Sign() > 0 {
+			increased = append(increased, name)
+		}
+	}
+	if len(increased) == 0 {
+		return ""
+	}
+	var messages []string
+	for _, q := range quotas {
+		hard := quotav1.Mask(q.Spec.Hard, increased)
+		if exceeded := Exceeded(hard, quotav1.Add(q.Status.Used, increase)); len(exceeded) > 0 {
+			messages = append(messages, fmt.Sprintf("MultiClusterResourceQuota %q would be exceeded: %s", q.Name, strings.Join(exceeded, ", ")))
+		}
+	}
+	sort.Strings(messages)
+	return strings.Join(messages, "; ")
+}
+
+// Marshal encodes the resources for the AnnotationResourceUsage annotation.
+func Marshal(used corev1.ResourceList) (string, error) {
+	data, err := json.Marshal(used)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Unmarshal decodes the AnnotationResourceUsage annotation of obj, and
+// returns false if it is not set.
+func Unmarshal(obj interface{ GetAnnotations() map[string]string }) (corev1.ResourceList, bool, error) {
+	value, found := obj.GetAnnotations()[tmcv1alpha1.AnnotationResourceUsage]
+	if !found {
+		return nil, false, nil
+	}
+	used := corev1.ResourceList{}
+	if err := json.Unmarshal([]byte(value), &used); err != nil {
+		return nil, true, fmt.Errorf("invalid %s annotation: %w", tmcv1alpha1.AnnotationResourceUsage, err)
+	}
+	return used, true, nil
+}
+
+func sortedNames(list corev1.ResourceList) []corev1.ResourceName {
+	names := quotav1.ResourceNames(list)
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+var deployments = schema.GroupResource{Group: "apps", Resource: "deployments"}
+
+func deployment(replicas int64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"namespace": "default", "name": "web"},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+			"template": map[string]interface{}{"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "web", "resources": map[string]interface{}{"requests": map[string]interface{}{"cpu": "250m", "memory": "128Mi"}}},
+					map[string]interface{}{"name": "proxy", "resources": map[string]interface{}{"requests": map[string]interface{}{"cpu": "250m", "nvidia.com/gpu": "1"}}},
+				},
+				"initContainers": []interface{}{
+					map[string]interface{}{"name": "migrate", "resources": map[string]interface{}{"requests": map[string]interface{}{"memory": "1Gi"}}},
+				},
+			}},
+		},
+	}}
+}
+
+func requireEqualUsage(t *testing.T, want, got corev1.ResourceList) {
+	t.Helper()
+	require.Len(t, got, len(want))
+	for name, q := range want {
+		require.Zero(t, q.Cmp(got[name]), "%s: want %s, got %s", name, q.String(), got[name])
+	}
+}
+
+func TestUsage(t *testing.T) {
+	used, err := Usage(deployment(3), deployments)
+	require.NoError(t, err)
+	requireEqualUsage(t, corev1.ResourceList{
+		"count/deployments.apps": resource.MustParse("1"),
+		corev1.ResourceCPU:       resource.MustParse("1500m"),
+		corev1.ResourceMemory:    resource.MustParse("3Gi"),
+	}, used)
+
+	workload := deployment(0)
+	unstructured.RemoveNestedField(workload.Object, "spec", "replicas")
+	used, err = Usage(workload, deployments)
+	require.NoError(t, err)
+	requireEqualUsage(t, corev1.ResourceList{
+		"count/deployments.apps": resource.MustParse("1"),
+		corev1.ResourceCPU:       resource.MustParse("500m"),
+		corev1.ResourceMemory:    resource.MustParse("1Gi"),
+	}, used)
+
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap"}}
+	used, err = Usage(configMap, schema.GroupResource{Resource: "configmaps"})
+	require.NoError(t, err)
+	requireEqualUsage(t, corev1.ResourceList{"count/configmaps": resource.MustParse("1")}, used)
+}
+
+func TestCheck(t *testing.T) {
+	quotas := []*tmcv1alpha1.MultiClusterResourceQuota{{
+		ObjectMeta: metav1.ObjectMeta{Name: "team"},
+		Spec: tmcv1alpha1.MultiClusterResourceQuotaSpec{Hard: corev1.ResourceList{
+			corev1.ResourceCPU:       resource.MustParse("4"),
+			"count/deployments.apps": resource.MustParse("2"),
+		}},
+		Status: tmcv1alpha1.MultiClusterResourceQuotaStatus{Used: corev1.ResourceList{
+			corev1.ResourceCPU:       resource.MustParse("3"),
+			"count/deployments.apps": resource.MustParse("3"),
+		}},
+	}}
+
+	require.Empty(t, Check(quotas, corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}))
+	require.Equal(t, `MultiClusterResourceQuota "team" would be exceeded: cpu: 5 of at most 4`,
+		Check(quotas, corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("1Gi")}))
+	require.Empty(t, Check(quotas, corev1.ResourceList{"count/deployments.apps": resource.MustParse("-1")}),
+		"usage over a lowered quota may shrink")
+}
+
+func TestMarshal(t *testing.T) {
+	used := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), "count/deployments.apps": resource.MustParse("1")}
+	value, err := Marshal(used)
+	require.NoError(t, err)
+
+	obj := &metav1.ObjectMeta{Annotations: map[string]string{tmcv1alpha1.AnnotationResourceUsage: value}}
+	decoded, found, err := Unmarshal(obj)
+	require.NoError(t, err)
+	require.True(t, found)
+	requireEqualUsage(t, used, decoded)
+
+	_, found, err = Unmarshal(&metav1.ObjectMeta{})
+	require.NoError(t, err)
+	require.False(t, found)
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package reference resolves the object references of the TMC APIs, e.g.
+// the PlacementPolicy of a WorkloadDistribution or the members of a
+// SyncTargetGroup, from informer caches. References point into the
+// workspace of the referencing object unless they name a workspace path.
+// Converted objects are cached by resource version.
+package reference
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/lru"
+
+	kcpcache "github.com/kcp-dev/apimachinery/v2/pkg/cache"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/indexers"
+)
+
+// cacheSize bounds the converted objects kept.
+const cacheSize = 4096
+
+// ErrAmbiguous is wrapped by AmbiguousError.
+var ErrAmbiguous = errors.New("ambiguous reference")
+
+// Reference identifies an object.
+type Reference struct {
+	// Path is the workspace of the object. Empty for the workspace of the
+	// referencing object.
+	Path      logicalcluster.Path
+	Resource  schema.GroupResource
+	Namespace string
+	Name      string
+}
+
+func (r Reference) String() string {
+	name := r.Name
+	if r.Namespace != "" {
+		name = r.Namespace + "/" + name
+	}
+	if !r.Path.Empty() {
+		name = r.Path.Join(name).String()
+	}
+	return fmt.Sprintf("%s %s", r.Resource, name)
+}
+
+// AmbiguousError is returned for references matching more than one object,
+// e.g. a workspace path annotated on several logical clusters.
+type AmbiguousError struct {
+	Reference Reference
+	// Clusters are the logical clusters of the matching objects.
+	Clusters []logicalcluster.Name
+}
+
+func (e *AmbiguousError) Error() string {
+	clusters := make([]string, 0, len(e.Clusters))
+	for _, c := range e.Clusters {
+		clusters = append(clusters, c.String())
+	}
+	return fmt.Sprintf("%s matches objects in logical clusters %s", e.Reference, strings.Join(clusters, ", "))
+}
+
+func (e *AmbiguousError) Unwrap() error {
+	return ErrAmbiguous
+}
+
+// IsAmbiguous returns whether err is an AmbiguousError.
+func IsAmbiguous(err error) bool {
+	return errors.Is(err, ErrAmbiguous)
+}
+
+// Store is the part of a cache.Indexer references are resolved from.
+type Store interface {
+	GetByKey(key string) (interface{}, bool, error)
+	ByIndex(indexName, indexedValue string) ([]interface{}, error)
+}
+
+// Informer is an informer of a resource across logical clusters, e.g. a
+// tmcinformers.Informer, whose store uses cluster-aware keys.
+type Informer interface {
+	Store
+	AddIndexers(indexers cache.Indexers)
+}
+
+// Resolver resolves references from the indexers of cluster-aware informers.
+type Resolver struct {
+	lock     sync.RWMutex
+	indexers map[schema.GroupResource]Store
+
+	converted *lru.Cache
+}
+
+// NewResolver returns a resolver without resources.
+func NewResolver() *Resolver {
+	return &Resolver{
+		indexers:  map[schema.GroupResource]Store{},
+		converted: lru.New(cacheSize),
+	}
+}
+
+// AddInformer resolves references to the resource from the informer,
+// indexing it by workspace path.
+func (r *Resolver) AddInformer(resource schema.GroupResource, informer Informer) {
+	informer.AddIndexers(cache.Indexers{
+		indexers.ByLogicalClusterPathAndName: indexers.IndexByLogicalClusterPathAndName,
+	})
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.indexers[resource] = informer
+}
+
+// AddIndexer resolves references to the resource from the indexer, which
+// must use cluster-aware keys. References with a path need the
+// ByLogicalClusterPathAndName index.
+func (r *Resolver) AddIndexer(resource schema.GroupResource, indexer cache.Indexer) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.indexers[resource] = indexer
+}
+
+// Resolve returns the referenced object, resolving references without path
+// in the logical cluster from. It returns a NotFound error if the object
+// does not exist, and an AmbiguousError if the path matches several.
+func (r *Resolver) Resolve(from logicalcluster.Name, ref Reference) (*unstructured.Unstructured, error) {
+	r.lock.RLock()
+	indexer, found := r.indexers[ref.Resource]
+	r.lock.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("cannot resolve %s: resource %s is not known", ref, ref.Resource)
+	}
+
+	var objs []interface{}
+	if cluster, isName := ref.Path.Name(); ref.Path.Empty() || isName {
+		if !ref.Path.Empty() {
+			from = cluster
+		}
+		obj, exists, err := indexer.GetByKey(kcpcache.ToClusterAwareKey(from.String(), ref.Namespace, ref.Name))
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			objs = append(objs, obj)
+		}
+	} else {
+		matches, err := indexer.ByIndex(indexers.ByLogicalClusterPathAndName, ref.Path.Join(ref.Name).String())
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range matches {
+			if u, ok := obj.(*unstructured.Unstructured); ok && u.GetNamespace() == ref.Namespace {
+				objs = append(objs, obj)
+			}
+		}
+	}
+
+	switch len(objs) {
+	case 0:
+		return nil, apierrors.NewNotFound(ref.Resource, ref.String())
+	case 1:
+		u, ok := objs[0].(*unstructured.Unstructured)
+		if !ok {
+			return nil, fmt.Errorf("expected *unstructured.Unstructured, got %T", objs[0])
+		}
+		return u, nil
+	}
+	ambiguous := &AmbiguousError{Reference: ref}
+	for _, obj := range objs {
+		if u, ok := obj.(*unstructured.Unstructured); ok {
+			ambiguous.Clusters = append(ambiguous.Clusters, logicalcluster.From(u))
+		}
+	}
+	return nil, ambiguous
+}
+
+// Object is a pointer to a typed API object.
+type Object[T any] interface {
+	*T
+	runtime.Object
+}
+
+// Get resolves the reference into a typed object. Callers get their own
+// copy and may modify it.
+func Get[T any, PT Object[T]](r *Resolver, from logicalcluster.Name, ref Reference) (PT, error) {
+	u, err := r.Resolve(from, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("%s|%s|%s", ref.Resource, kcpcache.ToClusterAwareKey(logicalcluster.From(u).String(), u.GetNamespace(), u.GetName()), u.GetResourceVersion())
+	if cached, found := r.converted.Get(key); found {
+		if obj, ok := cached.(PT); ok {
+			return obj.DeepCopyObject().(PT), nil
+		}
+	}
+
+	obj := PT(new(T))
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj); err != nil {
+		return nil, err
+	}
+	r.converted.Add(key, obj.DeepCopyObject())
+	return obj, nil
+}
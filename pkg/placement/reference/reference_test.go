@@ -0,0 +1,111 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reference
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+
+	kcpcache "github.com/kcp-dev/apimachinery/v2/pkg/cache"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/indexers"
+	"github.com/kcp-dev/kcp/sdk/apis/core"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+)
+
+func policy(cluster, path, name, resourceVersion string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion(placementv1alpha1.SchemeGroupVersion.String())
+	u.SetKind("PlacementPolicy")
+	u.SetName(name)
+	u.SetResourceVersion(resourceVersion)
+	annotations := map[string]string{logicalcluster.AnnotationKey: cluster}
+	if path != "" {
+		annotations[core.LogicalClusterPathAnnotationKey] = path
+	}
+	u.SetAnnotations(annotations)
+	_ = unstructured.SetNestedField(u.Object, "Spread", "spec", "strategy")
+	return u
+}
+
+func TestResolve(t *testing.T) {
+	policies := placementv1alpha1.Resource("placementpolicies")
+	indexer := cache.NewIndexer(kcpcache.MetaClusterNamespaceKeyFunc, cache.Indexers{
+		indexers.ByLogicalClusterPathAndName: indexers.IndexByLogicalClusterPathAndName,
+	})
+	require.NoError(t, indexer.Add(policy("c1", "root:org:team-a", "spread", "1")))
+	require.NoError(t, indexer.Add(policy("c2", "root:org:team-b", "spread", "1")))
+	require.NoError(t, indexer.Add(policy("c3", "root:org:team-b", "spread", "1")))
+
+	r := NewResolver()
+	r.AddIndexer(policies, indexer)
+
+	u, err := r.Resolve("c1", Reference{Resource: policies, Name: "spread"})
+	require.NoError(t, err)
+	require.Equal(t, logicalcluster.Name("c1"), logicalcluster.From(u), "references without path stay in the workspace")
+
+	u, err = r.Resolve("c1", Reference{Path: logicalcluster.NewPath("c2"), Resource: policies, Name: "spread"})
+	require.NoError(t, err)
+	require.Equal(t, logicalcluster.Name("c2"), logicalcluster.From(u))
+
+	u, err = r.Resolve("c2", Reference{Path: logicalcluster.NewPath("root:org:team-a"), Resource: policies, Name: "spread"})
+	require.NoError(t, err)
+	require.Equal(t, logicalcluster.Name("c1"), logicalcluster.From(u))
+
+	_, err = r.Resolve("c1", Reference{Path: logicalcluster.NewPath("root:org:team-b"), Resource: policies, Name: "spread"})
+	require.True(t, IsAmbiguous(err))
+	require.EqualError(t, err, "placementpolicies.placement.kcp.io root:org:team-b:spread matches objects in logical clusters c2, c3")
+
+	_, err = r.Resolve("c1", Reference{Resource: policies, Name: "singleton"})
+	require.True(t, apierrors.IsNotFound(err))
+
+	_, err = r.Resolve("c1", Reference{Resource: placementv1alpha1.Resource("datalocations"), Name: "eu"})
+	require.EqualError(t, err, "cannot resolve datalocations.placement.kcp.io eu: resource datalocations.placement.kcp.io is not known")
+}
+
+func TestGet(t *testing.T) {
+	policies := placementv1alpha1.Resource("placementpolicies")
+	indexer := cache.NewIndexer(kcpcache.MetaClusterNamespaceKeyFunc, cache.Indexers{})
+	require.NoError(t, indexer.Add(policy("c1", "", "spread", "1")))
+
+	r := NewResolver()
+	r.AddIndexer(policies, indexer)
+	ref := Reference{Resource: policies, Name: "spread"}
+
+	p, err := Get[placementv1alpha1.PlacementPolicy](r, "c1", ref)
+	require.NoError(t, err)
+	require.Equal(t, placementv1alpha1.PlacementStrategySpread, p.Spec.Strategy)
+	require.Equal(t, 1, r.converted.Len())
+
+	p.Spec.Strategy = placementv1alpha1.PlacementStrategySingleton
+	p, err = Get[placementv1alpha1.PlacementPolicy](r, "c1", ref)
+	require.NoError(t, err)
+	require.Equal(t, placementv1alpha1.PlacementStrategySpread, p.Spec.Strategy, "cached objects are copied")
+
+	updated := policy("c1", "", "spread", "2")
+	_ = unstructured.SetNestedField(updated.Object, "Singleton", "spec", "strategy")
+	require.NoError(t, indexer.Update(updated))
+	p, err = Get[placementv1alpha1.PlacementPolicy](r, "c1", ref)
+	require.NoError(t, err)
+	require.Equal(t, placementv1alpha1.PlacementStrategySingleton, p.Spec.Strategy, "new resource versions are converted")
+}
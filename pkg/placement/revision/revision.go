@@ -0,0 +1,58 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package revision computes the revisions of PlacementPolicies. A revision
+// captures only the placement-relevant part of a policy spec, so that
+// changing rollout settings does not start a new rollout.
+package revision
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+
+	"k8s.io/apimachinery/pkg/util/rand"
+
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+)
+
+// Spec returns the placement-relevant part of spec, i.e. without rollout,
+// history and revalidation settings.
+func Spec(spec placementv1alpha1.PlacementPolicySpec) placementv1alpha1.PlacementPolicySpec {
+	out := *spec.DeepCopy()
+	out.Rollout = nil
+	out.RevisionHistoryLimit = nil
+	out.DecisionTTL = nil
+	return out
+}
+
+// Hash returns a short, stable hash of the placement-relevant part of spec.
+func Hash(spec placementv1alpha1.PlacementPolicySpec) string {
+	// json.Marshal is deterministic for structs and sorts map keys.
+	data, err := json.Marshal(Spec(spec))
+	if err != nil {
+		// The spec only contains marshallable types.
+		panic(fmt.Sprintf("failed to marshal PlacementPolicySpec: %v", err))
+	}
+	hasher := fnv.New32a()
+	_, _ = hasher.Write(data)
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
+}
+
+// Name returns the name of the revision of the policy with the given spec.
+func Name(policyName string, spec placementv1alpha1.PlacementPolicySpec) string {
+	return policyName + "-" + Hash(spec)
+}
@@ -0,0 +1,258 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package rightplacement mines the placement decision history and the
+// utilization of SyncTargets for workloads that are better placed elsewhere,
+// and publishes them as RightPlacementRecommendations, grouped per namespace
+// in a RebalancePlan describing their effect before any is applied.
+package rightplacement
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kcp-dev/kcp/pkg/placement/decision"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+const (
+	defaultMinCostSavingPercent   = 20
+	defaultMinHeadroomGainPercent = 25
+	defaultMinPlacedFor           = 24 * time.Hour
+)
+
+// Options tune which moves are recommended.
+type Options struct {
+	// MinCostSavingPercent is the estimated cost saving a move must reach to
+	// be recommended for its cost. Defaults to 20.
+	MinCostSavingPercent int32
+	// MinHeadroomGainPercent is the gain of allocatable CPU share, in
+	// percentage points, a move must reach to be recommended for its
+	// headroom. Defaults to 25.
+	MinHeadroomGainPercent int32
+	// MinPlacedFor is how long a workload must have been placed on a target
+	// before moving it off is recommended, so that recent decisions settle.
+	// Defaults to 24h.
+	MinPlacedFor time.Duration
+}
+
+func (o Options) withDefaults() Options {
+	if o.MinCostSavingPercent <= 0 {
+		o.MinCostSavingPercent = defaultMinCostSavingPercent
+	}
+	if o.MinHeadroomGainPercent <= 0 {
+		o.MinHeadroomGainPercent = defaultMinHeadroomGainPercent
+	}
+	if o.MinPlacedFor <= 0 {
+		o.MinPlacedFor = defaultMinPlacedFor
+	}
+	return o
+}
+
+// Analyze returns the recommended moves for the workloads of records, which
+// are the decision history of one workspace, onto syncTargets of that
+// workspace. At most one move is recommended per placement of a workload.
+func Analyze(records []decision.DecisionRecord, syncTargets []*tmcv1alpha1.SyncTarget, options Options, now time.Time) []*placementv1alpha1.RightPlacementRecommendation {
+	options = options.withDefaults()
+
+	type workload struct{ namespace, name string }
+	history := map[workload][]*decision.DecisionRecord{}
+	for i := range records {
+		r := &records[i]
+		if r.Status != decision.StatusSucceeded {
+			continue
+		}
+		key := workload{r.Namespace, r.Name}
+		history[key] = append(history[key], r)
+	}
+
+	var recommendations []*placementv1alpha1.RightPlacementRecommendation
+	for key, rs := range history {
+		sort.SliceStable(rs, func(i, j int) bool { return rs[i].Time.Before(rs[j].Time) })
+		latest := rs[len(rs)-1]
+		for _, t := range latest.Targets {
+			if now.Sub(placedSince(rs, t.SyncTarget)) < options.MinPlacedFor {
+				continue
+			}
+			from := find(syncTargets, t.SyncTarget)
+			if from == nil {
+				continue
+			}
+			if r := recommend(key.namespace, key.name, from, candidates(latest, syncTargets), options); r != nil {
+				recommendations = append(recommendations, r)
+			}
+		}
+	}
+	sort.Slice(recommendations, func(i, j int) bool {
+		if recommendations[i].Namespace != recommendations[j].Namespace {
+			return recommendations[i].Namespace < recommendations[j].Namespace
+		}
+		return recommendations[i].Name < recommendations[j].Name
+	})
+	return recommendations
+}
+
+// Name returns the name of the recommendation to move the workload of a
+// distribution off a SyncTarget.
+func Name(distribution, from string) string {
+	return distribution + "-" + from
+}
+
+// placedSince returns the time of the first decision of the latest
+// uninterrupted run of decisions, sorted by time, placing on target.
+func placedSince(rs []*decision.DecisionRecord, target string) time.Time {
+	since := rs[len(rs)-1].Time
+	for i := len(rs) - 1; i >= 0; i-- {
+		if !placesOn(rs[i], target) {
+			break
+		}
+		since = rs[i].Time
+	}
+	return since
+}
+
+func placesOn(r *decision.DecisionRecord, target string) bool {
+	for _, t := range r.Targets {
+		if t.SyncTarget == target {
+			return true
+		}
+	}
+	return false
+}
+
+func find(syncTargets []*tmcv1alpha1.SyncTarget, name string) *tmcv1alpha1.SyncTarget {
+	for _, st := range syncTargets {
+		if st.Name == name {
+			return st
+		}
+	}
+	return nil
+}
+
+// candidates returns the SyncTargets a workload can move to: those that were
+// feasible in its latest decision, are not chosen already, and are ready and
+// schedulable today.
+func candidates(latest *decision.DecisionRecord, syncTargets []*tmcv1alpha1.SyncTarget) []*tmcv1alpha1.SyncTarget {
+	var out []*tmcv1alpha1.SyncTarget
+	for _, st := range syncTargets {
+		if _, rejected := latest.Rejected[st.Name]; rejected || placesOn(latest, st.Name) {
+			continue
+		}
+		if st.Spec.Unschedulable || !conditions.IsTrue(st, tmcv1alpha1.SyncerReady) || conditions.IsTrue(st, tmcv1alpha1.SchedulingDisabled) {
+			continue
+		}
+		out = append(out, st)
+	}
+	return out
+}
+
+// recommend returns the best move off from, or nil if no candidate is
+// better by the margins of the options. Lower cost wins over more headroom.
+func recommend(namespace, distribution string, from *tmcv1alpha1.SyncTarget, candidates []*tmcv1alpha1.SyncTarget, options Options) *placementv1alpha1.RightPlacementRecommendation {
+	fromCost, fromHasCost := cost(from)
+	fromHeadroom, fromHasHeadroom := headroom(from)
+
+	var best *placementv1alpha1.RightPlacementRecommendationSpec
+	better := func(spec *placementv1alpha1.RightPlacementRecommendationSpec) bool {
+		switch {
+		case best == nil:
+			return true
+		case spec.Reason != best.Reason:
+			return spec.Reason == placementv1alpha1.RecommendationReasonLowerCost
+		case spec.CostSavingPercent != best.CostSavingPercent:
+			return spec.CostSavingPercent > best.CostSavingPercent
+		case spec.HeadroomGainPercent != best.HeadroomGainPercent:
+			return spec.HeadroomGainPercent > best.HeadroomGainPercent
+		default:
+			return spec.To < best.To
+		}
+	}
+	for _, to := range candidates {
+		var saving, gain int32
+		var knownSaving, knownGain bool
+		if toCost, ok := cost(to); ok && fromHasCost && fromCost > 0 {
+			saving, knownSaving = int32(100*(fromCost-toCost)/fromCost), true
+		}
+		if toHeadroom, ok := headroom(to); ok && fromHasHeadroom {
+			gain, knownGain = toHeadroom-fromHeadroom, true
+		}
+
+		spec := &placementv1alpha1.RightPlacementRecommendationSpec{
+			DistributionRef:     placementv1alpha1.LocalReference{Name: distribution},
+			From:                from.Name,
+			To:                  to.Name,
+			CostSavingPercent:   max(saving, 0),
+			HeadroomGainPercent: max(gain, 0),
+			Action:              placementv1alpha1.RecommendationActionPending,
+		}
+		switch {
+		// Cheaper targets must not be tighter on capacity.
+		case knownSaving && saving >= options.MinCostSavingPercent && (!knownGain || gain >= 0):
+			spec.Reason = placementv1alpha1.RecommendationReasonLowerCost
+			spec.Message = fmt.Sprintf("Moving from SyncTarget %q to %q saves an estimated %d%% of cost.", from.Name, to.Name, saving)
+		// Targets with more headroom must not be more expensive.
+		case knownGain && gain >= options.MinHeadroomGainPercent && (!knownSaving || saving >= 0):
+			spec.Reason = placementv1alpha1.RecommendationReasonMoreHeadroom
+			spec.Message = fmt.Sprintf("Moving from SyncTarget %q to %q gains %d percentage points of allocatable CPU.", from.Name, to.Name, gain)
+		default:
+			continue
+		}
+		if better(spec) {
+			best = spec
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	return &placementv1alpha1.RightPlacementRecommendation{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: placementv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "RightPlacementRecommendation",
+		},
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: Name(distribution, from.Name)},
+		Spec:       *best,
+	}
+}
+
+// cost returns the cost annotation of a SyncTarget, if valid.
+func cost(syncTarget *tmcv1alpha1.SyncTarget) (float64, bool) {
+	c, err := strconv.ParseFloat(syncTarget.Annotations[tmcv1alpha1.AnnotationCost], 64)
+	if err != nil || c < 0 {
+		return 0, false
+	}
+	return c, true
+}
+
+// headroom returns the allocatable share of the CPU capacity of a
+// SyncTarget in percent, if reported.
+func headroom(syncTarget *tmcv1alpha1.SyncTarget) (int32, bool) {
+	if syncTarget.Status.Capacity == nil || syncTarget.Status.Allocatable == nil {
+		return 0, false
+	}
+	capacity, found := (*syncTarget.Status.Capacity)[corev1.ResourceCPU]
+	if !found || capacity.IsZero() {
+		return 0, false
+	}
+	allocatable := (*syncTarget.Status.Allocatable)[corev1.ResourceCPU]
+	return int32(100 * allocatable.MilliValue() / capacity.MilliValue()), true
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rightplacement
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/placement/decision"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var t0 = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func syncTarget(name, cost, allocatableCPU string) *tmcv1alpha1.SyncTarget {
+	st := &tmcv1alpha1.SyncTarget{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if cost != "" {
+		st.Annotations = map[string]string{tmcv1alpha1.AnnotationCost: cost}
+	}
+	st.Status.Capacity = &corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10")}
+	st.Status.Allocatable = &corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(allocatableCPU)}
+	conditions.MarkTrue(st, tmcv1alpha1.SyncerReady)
+	return st
+}
+
+func record(name string, at time.Time, targets ...string) decision.DecisionRecord {
+	r := decision.DecisionRecord{Workspace: "root:org", Namespace: "default", Name: name, Status: decision.StatusSucceeded, Time: at}
+	for _, t := range targets {
+		r.Targets = append(r.Targets, workloadv1alpha1.TargetPlacement{SyncTarget: t})
+	}
+	return r
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := map[string]struct {
+		records     []decision.DecisionRecord
+		syncTargets []*tmcv1alpha1.SyncTarget
+		want        []placementv1alpha1.RightPlacementRecommendationSpec
+	}{
+		"cheaper target with as much headroom": {
+			records:     []decision.DecisionRecord{record("app", t0, "eu-1")},
+			syncTargets: []*tmcv1alpha1.SyncTarget{syncTarget("eu-1", "10", "5"), syncTarget("eu-2", "7", "5"), syncTarget("eu-3", "8", "5")},
+			want: []placementv1alpha1.RightPlacementRecommendationSpec{{
+				DistributionRef:   placementv1alpha1.LocalReference{Name: "app"},
+				From:              "eu-1",
+				To:                "eu-2",
+				Reason:            placementv1alpha1.RecommendationReasonLowerCost,
+				CostSavingPercent: 30,
+				Message:           `Moving from SyncTarget "eu-1" to "eu-2" saves an estimated 30% of cost.`,
+				Action:            placementv1alpha1.RecommendationActionPending,
+			}},
+		},
+		"cheaper target with less headroom": {
+			records:     []decision.DecisionRecord{record("app", t0, "eu-1")},
+			syncTargets: []*tmcv1alpha1.SyncTarget{syncTarget("eu-1", "10", "5"), syncTarget("eu-2", "5", "2")},
+		},
+		"target with more headroom at the same cost": {
+			records:     []decision.DecisionRecord{record("app", t0, "eu-1")},
+			syncTargets: []*tmcv1alpha1.SyncTarget{syncTarget("eu-1", "", "2"), syncTarget("eu-2", "", "8")},
+			want: []placementv1alpha1.RightPlacementRecommendationSpec{{
+				DistributionRef:     placementv1alpha1.LocalReference{Name: "app"},
+				From:                "eu-1",
+				To:                  "eu-2",
+				Reason:              placementv1alpha1.RecommendationReasonMoreHeadroom,
+				HeadroomGainPercent: 60,
+				Message:             `Moving from SyncTarget "eu-1" to "eu-2" gains 60 percentage points of allocatable CPU.`,
+				Action:              placementv1alpha1.RecommendationActionPending,
+			}},
+		},
+		"margins not reached": {
+			records:     []decision.DecisionRecord{record("app", t0, "eu-1")},
+			syncTargets: []*tmcv1alpha1.SyncTarget{syncTarget("eu-1", "10", "5"), syncTarget("eu-2", "9", "6")},
+		},
+		"recently placed": {
+			records:     []decision.DecisionRecord{record("app", t0, "eu-2"), record("app", t0.Add(47*time.Hour), "eu-1")},
+			syncTargets: []*tmcv1alpha1.SyncTarget{syncTarget("eu-1", "10", "5"), syncTarget("eu-2", "5", "5")},
+		},
+		"rejected in the latest decision": {
+			records: func() []decision.DecisionRecord {
+				r := record("app", t0, "eu-1")
+				r.Rejected = map[string]string{"eu-2": "does not match the location selector"}
+				return []decision.DecisionRecord{r}
+			}(),
+			syncTargets: []*tmcv1alpha1.SyncTarget{syncTarget("eu-1", "10", "5"), syncTarget("eu-2", "5", "5")},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got []placementv1alpha1.RightPlacementRecommendationSpec
+			for _, r := range Analyze(tc.records, tc.syncTargets, Options{}, t0.Add(48*time.Hour)) {
+				require.Equal(t, "default", r.Namespace)
+				require.Equal(t, Name(r.Spec.DistributionRef.Name, r.Spec.From), r.Name)
+				got = append(got, r.Spec)
+			}
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+type fakeStorage struct {
+	decision.DecisionStorage
+	records []decision.DecisionRecord
+}
+
+func (s *fakeStorage) History(_ context.Context, _ decision.HistoryQuery) (*decision.HistoryPage, error) {
+	return &decision.HistoryPage{Records: s.records}, nil
+}
+
+func TestRecommenderRefresh(t *testing.T) {
+	published := map[string]*placementv1alpha1.RightPlacementRecommendation{}
+	var plan *placementv1alpha1.RebalancePlan
+	var creates, updates, planUpdates int
+	r := &Recommender{
+		storage: &fakeStorage{records: []decision.DecisionRecord{record("app", t0, "eu-1"), record("db", t0, "eu-1")}},
+		window:  defaultWindow,
+		now:     func() time.Time { return t0.Add(48 * time.Hour) },
+		listSyncTargets: func(logicalcluster.Name) ([]*tmcv1alpha1.SyncTarget, error) {
+			return []*tmcv1alpha1.SyncTarget{syncTarget("eu-1", "10", "5"), syncTarget("eu-2", "5", "5")}, nil
+		},
+		getRecommendation: func(_ logicalcluster.Name, _, name string) (*placementv1alpha1.RightPlacementRecommendation, error) {
+			if r, found := published[name]; found {
+				return r, nil
+			}
+			return nil, apierrors.NewNotFound(placementv1alpha1.Resource("rightplacementrecommendations"), name)
+		},
+		createRecommendation: func(_ context.Context, _ logicalcluster.Name, r *placementv1alpha1.RightPlacementRecommendation) error {
+			creates++
+			published[r.Name] = r
+			return nil
+		},
+		updateRecommendation: func(_ context.Context, _ logicalcluster.Name, r *placementv1alpha1.RightPlacementRecommendation) error {
+			updates++
+			published[r.Name] = r
+			return nil
+		},
+		getPlan: func(_ logicalcluster.Name, _ string) (*placementv1alpha1.RebalancePlan, error) {
+			if plan == nil {
+				return nil, apierrors.NewNotFound(placementv1alpha1.Resource("rebalanceplans"), placementv1alpha1.RebalancePlanName)
+			}
+			return plan, nil
+		},
+		createPlan: func(_ context.Context, _ logicalcluster.Name, p *placementv1alpha1.RebalancePlan) error {
+			plan = p
+			return nil
+		},
+		updatePlan: func(_ context.Context, _ logicalcluster.Name, p *placementv1alpha1.RebalancePlan) error {
+			planUpdates++
+			plan = p
+			return nil
+		},
+	}
+
+	require.NoError(t, r.Refresh(context.Background()))
+	require.Equal(t, 2, creates)
+	require.Contains(t, published, "app-eu-1")
+	require.Contains(t, published, "db-eu-1")
+	require.Equal(t, []string{"app-eu-1", "db-eu-1"}, moves(plan))
+	require.Equal(t, placementv1alpha1.RecommendationActionPending, plan.Spec.Action)
+
+	published["app-eu-1"].Spec.Action = placementv1alpha1.RecommendationActionDismissed
+	published["db-eu-1"].Spec.Message = "stale"
+	require.NoError(t, r.Refresh(context.Background()))
+	require.Equal(t, 2, creates)
+	require.Equal(t, 1, updates, "only the pending recommendation is refreshed")
+	require.Equal(t, placementv1alpha1.RecommendationActionDismissed, published["app-eu-1"].Spec.Action)
+	require.NotEqual(t, "stale", published["db-eu-1"].Spec.Message)
+	require.Equal(t, []string{"db-eu-1"}, moves(plan), "dismissed recommendations are not planned")
+
+	plan.Spec.Action = placementv1alpha1.RecommendationActionAccepted
+	require.NoError(t, r.Refresh(context.Background()))
+	require.Equal(t, 1, planUpdates, "plans with the same moves keep their decision")
+	require.Equal(t, placementv1alpha1.RecommendationActionAccepted, plan.Spec.Action)
+}
+
+func moves(plan *placementv1alpha1.RebalancePlan) []string {
+	var names []string
+	for _, m := range plan.Spec.Moves {
+		names = append(names, m.Recommendation)
+	}
+	return names
+}
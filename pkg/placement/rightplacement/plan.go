@@ -0,0 +1,130 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rightplacement
+
+import (
+	"math"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kcp-dev/kcp/pkg/placement/decision"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// PlanOptions configure how RebalancePlans are accepted.
+type PlanOptions struct {
+	// Approval is how plans are accepted. Defaults to Manual.
+	Approval placementv1alpha1.RebalanceApproval
+	// AutoApproveMinScoreDelta is the score delta a plan must reach to be
+	// accepted automatically.
+	AutoApproveMinScoreDelta int32
+	// AutoApproveMaxDisruptedReplicas is the most replicas a plan accepted
+	// automatically may disrupt. Zero means no limit.
+	AutoApproveMaxDisruptedReplicas int32
+}
+
+// Plan returns the RebalancePlan of the pending recommendations of a
+// namespace, or nil if there are none. The skew is computed from the latest
+// decisions of the workloads of the namespace in records, over the
+// SyncTargets they are placed on or move to.
+func Plan(namespace string, recommendations []*placementv1alpha1.RightPlacementRecommendation, records []decision.DecisionRecord, options PlanOptions) *placementv1alpha1.RebalancePlan {
+	if len(recommendations) == 0 {
+		return nil
+	}
+
+	latest := map[string]*decision.DecisionRecord{}
+	for i := range records {
+		r := &records[i]
+		if r.Namespace != namespace || r.Status != decision.StatusSucceeded {
+			continue
+		}
+		if l, found := latest[r.Name]; !found || l.Time.Before(r.Time) {
+			latest[r.Name] = r
+		}
+	}
+	load := map[string]int32{}
+	for _, r := range latest {
+		for _, t := range r.Targets {
+			load[t.SyncTarget] += replicas(t)
+		}
+	}
+
+	spec := placementv1alpha1.RebalancePlanSpec{SkewBefore: skew(load)}
+	for _, r := range recommendations {
+		move := placementv1alpha1.RebalanceMove{
+			Recommendation:  r.Name,
+			DistributionRef: r.Spec.DistributionRef,
+			From:            r.Spec.From,
+			To:              r.Spec.To,
+			ScoreDelta:      r.Spec.CostSavingPercent + r.Spec.HeadroomGainPercent,
+		}
+		moved := int32(1)
+		if l, found := latest[r.Spec.DistributionRef.Name]; found {
+			for _, t := range l.Targets {
+				if t.SyncTarget == r.Spec.From && t.Replicas != nil {
+					moved, move.Replicas = *t.Replicas, *t.Replicas
+				}
+			}
+		}
+		load[r.Spec.From] -= moved
+		load[r.Spec.To] += moved
+
+		spec.Moves = append(spec.Moves, move)
+		spec.ScoreDelta += move.ScoreDelta
+		spec.DisruptedReplicas += moved
+	}
+	spec.MoveCount = int32(len(spec.Moves))
+	spec.SkewAfter = skew(load)
+
+	spec.Action = placementv1alpha1.RecommendationActionPending
+	if options.Approval == placementv1alpha1.RebalanceApprovalAutomatic && spec.ScoreDelta >= options.AutoApproveMinScoreDelta &&
+		(options.AutoApproveMaxDisruptedReplicas <= 0 || spec.DisruptedReplicas <= options.AutoApproveMaxDisruptedReplicas) {
+		spec.Approval = placementv1alpha1.RebalanceApprovalAutomatic
+		spec.Action = placementv1alpha1.RecommendationActionAccepted
+	}
+
+	return &placementv1alpha1.RebalancePlan{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: placementv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "RebalancePlan",
+		},
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: placementv1alpha1.RebalancePlanName},
+		Spec:       spec,
+	}
+}
+
+// replicas returns the replicas of a placement, one for workloads without
+// replicas.
+func replicas(t workloadv1alpha1.TargetPlacement) int32 {
+	if t.Replicas == nil {
+		return 1
+	}
+	return *t.Replicas
+}
+
+// skew returns the difference between the most and the least load.
+func skew(load map[string]int32) int32 {
+	if len(load) == 0 {
+		return 0
+	}
+	most, least := int32(math.MinInt32), int32(math.MaxInt32)
+	for _, l := range load {
+		most, least = max(most, l), min(least, l)
+	}
+	return most - least
+}
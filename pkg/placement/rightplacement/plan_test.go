@@ -0,0 +1,81 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rightplacement
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/kcp-dev/kcp/pkg/placement/decision"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+)
+
+func TestPlan(t *testing.T) {
+	web := record("web", t0, "eu-1")
+	web.Targets[0].Replicas = ptr.To[int32](4)
+	records := []decision.DecisionRecord{
+		record("app", t0, "eu-2"),
+		record("app", t0.Add(time.Hour), "eu-1"),
+		web,
+		record("db", t0, "eu-3"),
+	}
+	recommendations := []*placementv1alpha1.RightPlacementRecommendation{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app-eu-1"}, Spec: placementv1alpha1.RightPlacementRecommendationSpec{
+			DistributionRef: placementv1alpha1.LocalReference{Name: "app"}, From: "eu-1", To: "eu-2", CostSavingPercent: 30,
+		}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-eu-1"}, Spec: placementv1alpha1.RightPlacementRecommendationSpec{
+			DistributionRef: placementv1alpha1.LocalReference{Name: "web"}, From: "eu-1", To: "eu-3", HeadroomGainPercent: 25,
+		}},
+	}
+
+	require.Nil(t, Plan("default", nil, records, PlanOptions{}))
+
+	plan := Plan("default", recommendations, records, PlanOptions{})
+	require.Equal(t, placementv1alpha1.RebalancePlanName, plan.Name)
+	require.Equal(t, placementv1alpha1.RebalancePlanSpec{
+		Moves: []placementv1alpha1.RebalanceMove{
+			{Recommendation: "app-eu-1", DistributionRef: placementv1alpha1.LocalReference{Name: "app"}, From: "eu-1", To: "eu-2", ScoreDelta: 30},
+			{Recommendation: "web-eu-1", DistributionRef: placementv1alpha1.LocalReference{Name: "web"}, From: "eu-1", To: "eu-3", ScoreDelta: 25, Replicas: 4},
+		},
+		MoveCount: 2,
+		// eu-1 runs app and 4 replicas of web, eu-3 runs db: 5 - 1.
+		SkewBefore: 4,
+		// eu-1 runs nothing, eu-2 app, eu-3 db and web: 5 - 0.
+		SkewAfter:         5,
+		ScoreDelta:        55,
+		DisruptedReplicas: 5,
+		Action:            placementv1alpha1.RecommendationActionPending,
+	}, plan.Spec)
+
+	auto := PlanOptions{Approval: placementv1alpha1.RebalanceApprovalAutomatic, AutoApproveMinScoreDelta: 50, AutoApproveMaxDisruptedReplicas: 5}
+	plan = Plan("default", recommendations, records, auto)
+	require.Equal(t, placementv1alpha1.RecommendationActionAccepted, plan.Spec.Action)
+	require.Equal(t, placementv1alpha1.RebalanceApprovalAutomatic, plan.Spec.Approval)
+
+	auto.AutoApproveMaxDisruptedReplicas = 4
+	plan = Plan("default", recommendations, records, auto)
+	require.Equal(t, placementv1alpha1.RecommendationActionPending, plan.Spec.Action, "plans disrupting too much await an operator")
+
+	auto.AutoApproveMaxDisruptedReplicas, auto.AutoApproveMinScoreDelta = 0, 60
+	plan = Plan("default", recommendations, records, auto)
+	require.Equal(t, placementv1alpha1.RecommendationActionPending, plan.Spec.Action, "plans improving too little await an operator")
+}
@@ -0,0 +1,300 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rightplacement
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	kcpinformers "github.com/kcp-dev/client-go/informers"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/placement/decision"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+const (
+	defaultInterval = time.Hour
+	defaultWindow   = 7 * 24 * time.Hour
+)
+
+var (
+	// RecommendationsGVR is the resource of RightPlacementRecommendations.
+	RecommendationsGVR = placementv1alpha1.SchemeGroupVersion.WithResource("rightplacementrecommendations")
+	// RebalancePlansGVR is the resource of RebalancePlans.
+	RebalancePlansGVR = placementv1alpha1.SchemeGroupVersion.WithResource("rebalanceplans")
+)
+
+// RecommenderConfig configures a Recommender.
+type RecommenderConfig struct {
+	Options
+	// Interval is how often the history is analyzed. Defaults to 1h.
+	Interval time.Duration
+	// Window is the history analyzed. Defaults to 7 days.
+	Window time.Duration
+	// Plans configure how RebalancePlans are accepted.
+	Plans PlanOptions
+}
+
+// Recommender periodically analyzes the decision history in a
+// DecisionStorage and creates or refreshes RightPlacementRecommendations,
+// and the RebalancePlan of the pending recommendations of each namespace.
+// Recommendations an operator accepted or dismissed are left alone.
+type Recommender struct {
+	storage     decision.DecisionStorage
+	options     Options
+	planOptions PlanOptions
+	interval    time.Duration
+	window      time.Duration
+	now         func() time.Time
+
+	listSyncTargets      func(clusterName logicalcluster.Name) ([]*tmcv1alpha1.SyncTarget, error)
+	getRecommendation    func(clusterName logicalcluster.Name, namespace, name string) (*placementv1alpha1.RightPlacementRecommendation, error)
+	createRecommendation func(ctx context.Context, clusterName logicalcluster.Name, r *placementv1alpha1.RightPlacementRecommendation) error
+	updateRecommendation func(ctx context.Context, clusterName logicalcluster.Name, r *placementv1alpha1.RightPlacementRecommendation) error
+	getPlan              func(clusterName logicalcluster.Name, namespace string) (*placementv1alpha1.RebalancePlan, error)
+	createPlan           func(ctx context.Context, clusterName logicalcluster.Name, plan *placementv1alpha1.RebalancePlan) error
+	updatePlan           func(ctx context.Context, clusterName logicalcluster.Name, plan *placementv1alpha1.RebalancePlan) error
+}
+
+// NewRecommender returns a recommender of moves found in the records of
+// storage, configured by config.
+func NewRecommender(
+	storage decision.DecisionStorage,
+	config RecommenderConfig,
+	syncTargetClusterInformer kcpinformers.GenericClusterInformer,
+	recommendationClusterInformer kcpinformers.GenericClusterInformer,
+	planClusterInformer kcpinformers.GenericClusterInformer,
+	dynamicClusterClient kcpdynamic.ClusterInterface,
+) *Recommender {
+	r := &Recommender{
+		storage:     storage,
+		options:     config.Options,
+		planOptions: config.Plans,
+		interval:    config.Interval,
+		window:      config.Window,
+		now:         time.Now,
+		listSyncTargets: func(clusterName logicalcluster.Name) ([]*tmcv1alpha1.SyncTarget, error) {
+			objs, err := syncTargetClusterInformer.Lister().ByCluster(clusterName).List(labels.Everything())
+			if err != nil {
+				return nil, err
+			}
+			syncTargets := make([]*tmcv1alpha1.SyncTarget, 0, len(objs))
+			for _, obj := range objs {
+				syncTarget := &tmcv1alpha1.SyncTarget{}
+				if err := fromUnstructured(obj, syncTarget); err != nil {
+					return nil, err
+				}
+				syncTargets = append(syncTargets, syncTarget)
+			}
+			return syncTargets, nil
+		},
+		getRecommendation: func(clusterName logicalcluster.Name, namespace, name string) (*placementv1alpha1.RightPlacementRecommendation, error) {
+			obj, err := recommendationClusterInformer.Lister().ByCluster(clusterName).ByNamespace(namespace).Get(name)
+			if err != nil {
+				return nil, err
+			}
+			recommendation := &placementv1alpha1.RightPlacementRecommendation{}
+			return recommendation, fromUnstructured(obj, recommendation)
+		},
+		createRecommendation: func(ctx context.Context, clusterName logicalcluster.Name, r *placementv1alpha1.RightPlacementRecommendation) error {
+			u, err := toUnstructured(r)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(RecommendationsGVR).Namespace(r.Namespace).Create(ctx, u, metav1.CreateOptions{})
+			return err
+		},
+		updateRecommendation: func(ctx context.Context, clusterName logicalcluster.Name, r *placementv1alpha1.RightPlacementRecommendation) error {
+			u, err := toUnstructured(r)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(RecommendationsGVR).Namespace(r.Namespace).Update(ctx, u, metav1.UpdateOptions{})
+			return err
+		},
+		getPlan: func(clusterName logicalcluster.Name, namespace string) (*placementv1alpha1.RebalancePlan, error) {
+			obj, err := planClusterInformer.Lister().ByCluster(clusterName).ByNamespace(namespace).Get(placementv1alpha1.RebalancePlanName)
+			if err != nil {
+				return nil, err
+			}
+			plan := &placementv1alpha1.RebalancePlan{}
+			return plan, fromUnstructured(obj, plan)
+		},
+		createPlan: func(ctx context.Context, clusterName logicalcluster.Name, plan *placementv1alpha1.RebalancePlan) error {
+			u, err := toUnstructured(plan)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(RebalancePlansGVR).Namespace(plan.Namespace).Create(ctx, u, metav1.CreateOptions{})
+			return err
+		},
+		updatePlan: func(ctx context.Context, clusterName logicalcluster.Name, plan *placementv1alpha1.RebalancePlan) error {
+			u, err := toUnstructured(plan)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(RebalancePlansGVR).Namespace(plan.Namespace).Update(ctx, u, metav1.UpdateOptions{})
+			return err
+		},
+	}
+	if r.interval <= 0 {
+		r.interval = defaultInterval
+	}
+	if r.window <= 0 {
+		r.window = defaultWindow
+	}
+	return r
+}
+
+// Run refreshes the recommendations until ctx is done.
+func (r *Recommender) Run(ctx context.Context) {
+	logger := klog.FromContext(ctx).WithValues("component", "right-placement")
+	ctx = klog.NewContext(ctx, logger)
+	logger.Info("Starting right-placement recommender")
+	defer logger.Info("Shutting down right-placement recommender")
+
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := r.Refresh(ctx); err != nil {
+			utilruntime.HandleError(fmt.Errorf("failed to refresh right-placement recommendations: %w", err))
+		}
+	}, r.interval)
+}
+
+// Refresh analyzes the history of the window once, creates or updates the
+// pending recommendations found, and plans them per namespace.
+func (r *Recommender) Refresh(ctx context.Context) error {
+	now := r.now()
+	byWorkspace := map[logicalcluster.Name][]decision.DecisionRecord{}
+	query := decision.HistoryQuery{Since: now.Add(-r.window), Limit: 500}
+	for {
+		page, err := r.storage.History(ctx, query)
+		if err != nil {
+			return err
+		}
+		for _, record := range page.Records {
+			byWorkspace[record.Workspace] = append(byWorkspace[record.Workspace], record)
+		}
+		if page.Continue == "" {
+			break
+		}
+		query.Continue = page.Continue
+	}
+
+	var errs []error
+	for workspace, records := range byWorkspace {
+		syncTargets, err := r.listSyncTargets(workspace)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		pending := map[string][]*placementv1alpha1.RightPlacementRecommendation{}
+		for _, recommendation := range Analyze(records, syncTargets, r.options, now) {
+			published, err := r.publish(ctx, workspace, recommendation)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if published {
+				pending[recommendation.Namespace] = append(pending[recommendation.Namespace], recommendation)
+			}
+		}
+		for namespace, recommendations := range pending {
+			if err := r.publishPlan(ctx, workspace, Plan(namespace, recommendations, records, r.planOptions)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// publish creates or updates a recommendation, and returns whether it is
+// pending, i.e. not accepted or dismissed by an operator.
+func (r *Recommender) publish(ctx context.Context, workspace logicalcluster.Name, recommendation *placementv1alpha1.RightPlacementRecommendation) (bool, error) {
+	logger := klog.FromContext(ctx).WithValues("workspace", workspace, "namespace", recommendation.Namespace, "name", recommendation.Name)
+
+	existing, err := r.getRecommendation(workspace, recommendation.Namespace, recommendation.Name)
+	if apierrors.IsNotFound(err) {
+		logger.V(2).Info("creating RightPlacementRecommendation", "to", recommendation.Spec.To, "reason", recommendation.Spec.Reason)
+		return true, r.createRecommendation(ctx, workspace, recommendation)
+	}
+	if err != nil {
+		return false, err
+	}
+	if existing.Spec.Action != "" && existing.Spec.Action != placementv1alpha1.RecommendationActionPending {
+		return false, nil
+	}
+	if existing.Spec == recommendation.Spec {
+		return true, nil
+	}
+	updated := existing.DeepCopy()
+	updated.Spec = recommendation.Spec
+	logger.V(2).Info("updating RightPlacementRecommendation", "to", recommendation.Spec.To, "reason", recommendation.Spec.Reason)
+	return true, r.updateRecommendation(ctx, workspace, updated)
+}
+
+// publishPlan creates or replaces the RebalancePlan of a namespace. A plan
+// with the same moves is left alone, keeping the decision on it.
+func (r *Recommender) publishPlan(ctx context.Context, workspace logicalcluster.Name, plan *placementv1alpha1.RebalancePlan) error {
+	logger := klog.FromContext(ctx).WithValues("workspace", workspace, "namespace", plan.Namespace)
+
+	existing, err := r.getPlan(workspace, plan.Namespace)
+	if apierrors.IsNotFound(err) {
+		logger.V(2).Info("creating RebalancePlan", "moves", plan.Spec.MoveCount, "scoreDelta", plan.Spec.ScoreDelta, "action", plan.Spec.Action)
+		return r.createPlan(ctx, workspace, plan)
+	}
+	if err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(existing.Spec.Moves, plan.Spec.Moves) {
+		return nil
+	}
+	updated := existing.DeepCopy()
+	updated.Spec = plan.Spec
+	logger.V(2).Info("updating RebalancePlan", "moves", plan.Spec.MoveCount, "scoreDelta", plan.Spec.ScoreDelta, "action", plan.Spec.Action)
+	return r.updatePlan(ctx, workspace, updated)
+}
+
+func fromUnstructured(obj runtime.Object, into interface{}) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("expected *unstructured.Unstructured, got %T", obj)
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, into)
+}
+
+func toUnstructured(obj interface{}) (*unstructured.Unstructured, error) {
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: raw}, nil
+}
@@ -0,0 +1,322 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package scenario runs placement scenarios: SyncTargets, policies and
+// workloads described in YAML together with the placement decisions
+// expected for the workloads. Scenarios are placed with the placement
+// engine of the controller, so that users can keep regression suites of
+// their policies under version control.
+package scenario
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
+
+	"github.com/kcp-dev/kcp/pkg/placement/engine"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// Scenario describes the objects of a workspace and the placement decisions
+// expected for its workloads.
+type Scenario struct {
+	// Name describes the scenario.
+	Name string `json:"name"`
+	// Now is the time the workloads are placed at, e.g. to test disruption
+	// windows. Defaults to the current time.
+	Now *metav1.Time `json:"now,omitempty"`
+
+	SyncTargets       []tmcv1alpha1.SyncTarget             `json:"syncTargets"`
+	SyncTargetGroups  []tmcv1alpha1.SyncTargetGroup        `json:"syncTargetGroups,omitempty"`
+	DataLocations     []placementv1alpha1.DataLocation     `json:"dataLocations,omitempty"`
+	PlacementPolicies []placementv1alpha1.PlacementPolicy  `json:"placementPolicies"`
+	SchedulingProfile *placementv1alpha1.SchedulingProfile `json:"schedulingProfile,omitempty"`
+	Workloads         []Workload                           `json:"workloads"`
+}
+
+// Workload is a workload placed by a scenario.
+type Workload struct {
+	// Name describes the workload.
+	Name string `json:"name"`
+	// Policy is the name of the PlacementPolicy of the workload.
+	Policy string `json:"policy"`
+	// Replicas of the workload, or nil if it is not scalable.
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Current are the SyncTargets the workload is placed on today.
+	Current []string `json:"current,omitempty"`
+	// Displaced are the SyncTargets the workload was moved off because of
+	// their disruption window.
+	Displaced []string `json:"displaced,omitempty"`
+	// Expect is the expected placement decision.
+	Expect Expectation `json:"expect"`
+}
+
+// Expectation is the expected placement decision of a workload.
+type Expectation struct {
+	// Targets are the SyncTargets expected to be chosen, in order of
+	// preference.
+	Targets []string `json:"targets,omitempty"`
+	// Replicas maps chosen SyncTargets to the replicas expected on them.
+	// Targets not listed are not checked.
+	Replicas map[string]int32 `json:"replicas,omitempty"`
+	// Rejected maps SyncTargets expected to be infeasible to a substring
+	// of the reason. Targets not listed are not checked.
+	Rejected map[string]string `json:"rejected,omitempty"`
+	// Error is a substring of the error the placement is expected to fail
+	// with, e.g. "no feasible SyncTargets". Targets are not checked if
+	// set.
+	Error string `json:"error,omitempty"`
+}
+
+// Result is the outcome of running a scenario.
+type Result struct {
+	// Scenario is the name of the scenario.
+	Scenario string
+	// Workloads are the outcomes of the workloads, in scenario order.
+	Workloads []WorkloadResult
+}
+
+// Passed returns whether all workloads were placed as expected.
+func (r Result) Passed() bool {
+	for _, w := range r.Workloads {
+		if !w.Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// WorkloadResult is the outcome of placing a workload.
+type WorkloadResult struct {
+	// Name is the name of the workload.
+	Name string
+	// Decision is the decision of the placement engine.
+	Decision engine.Decision
+	// Err is the error of the placement engine.
+	Err error
+	// Failures describe how the decision differs from the expectation.
+	Failures []string
+}
+
+// Passed returns whether the workload was placed as expected.
+func (r WorkloadResult) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// Load parses and validates a scenario. Unknown fields are rejected to catch
+// typos in expectations.
+func Load(data []byte) (*Scenario, error) {
+	s := &Scenario{}
+	if err := yaml.UnmarshalStrict(data, s); err != nil {
+		return nil, err
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// LoadFile parses and validates the scenario in the file at path.
+func LoadFile(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := Load(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *Scenario) validate() error {
+	var errs []string
+	syncTargets := map[string]bool{}
+	for _, t := range s.SyncTargets {
+		if syncTargets[t.Name] {
+			errs = append(errs, fmt.Sprintf("duplicate SyncTarget %q", t.Name))
+		}
+		syncTargets[t.Name] = true
+	}
+	policies := map[string]bool{}
+	for _, p := range s.PlacementPolicies {
+		policies[p.Name] = true
+	}
+	workloads := map[string]bool{}
+	for _, w := range s.Workloads {
+		if w.Name == "" {
+			errs = append(errs, "workload without name")
+		}
+		if workloads[w.Name] {
+			errs = append(errs, fmt.Sprintf("duplicate workload %q", w.Name))
+		}
+		workloads[w.Name] = true
+		if !policies[w.Policy] {
+			errs = append(errs, fmt.Sprintf("workload %q references unknown PlacementPolicy %q", w.Name, w.Policy))
+		}
+		for _, name := range append(append([]string{}, w.Current...), w.Displaced...) {
+			if !syncTargets[name] {
+				errs = append(errs, fmt.Sprintf("workload %q references unknown SyncTarget %q", w.Name, name))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// Run places the workloads of the scenario and compares the decisions with
+// the expectations.
+func (s *Scenario) Run() Result {
+	now := time.Now
+	if s.Now != nil {
+		now = func() time.Time { return s.Now.Time }
+	}
+	e := engine.NewEngineWithClock(now)
+
+	syncTargets := make([]*tmcv1alpha1.SyncTarget, 0, len(s.SyncTargets))
+	locations := map[string]string{}
+	for i := range s.SyncTargets {
+		syncTargets = append(syncTargets, &s.SyncTargets[i])
+		locations[s.SyncTargets[i].Name] = s.SyncTargets[i].Spec.Location
+	}
+	policies := map[string]placementv1alpha1.PlacementPolicySpec{}
+	for _, p := range s.PlacementPolicies {
+		policies[p.Name] = p.Spec
+	}
+	groups := map[string]*tmcv1alpha1.SyncTargetGroupSpec{}
+	for i := range s.SyncTargetGroups {
+		groups[s.SyncTargetGroups[i].Name] = &s.SyncTargetGroups[i].Spec
+	}
+	var profile *placementv1alpha1.SchedulingProfileSpec
+	if s.SchedulingProfile != nil {
+		profile = &s.SchedulingProfile.Spec
+	}
+
+	result := Result{Scenario: s.Name}
+	for _, w := range s.Workloads {
+		policy := policies[w.Policy]
+		r := WorkloadResult{Name: w.Name}
+
+		var group *tmcv1alpha1.SyncTargetGroupSpec
+		if policy.SyncTargetGroup != "" {
+			if group = groups[policy.SyncTargetGroup]; group == nil {
+				// The controller does not place workloads of policies
+				// with missing groups.
+				r.Err = fmt.Errorf("SyncTargetGroup %q not found", policy.SyncTargetGroup)
+			}
+		}
+		if r.Err == nil {
+			current := make([]workloadv1alpha1.TargetPlacement, 0, len(w.Current))
+			for _, name := range w.Current {
+				current = append(current, workloadv1alpha1.TargetPlacement{SyncTarget: name, Location: locations[name]})
+			}
+			r.Decision, r.Err = e.Place(engine.Request{
+				Policy:        policy,
+				SyncTargets:   syncTargets,
+				Current:       current,
+				Displaced:     w.Displaced,
+				Replicas:      w.Replicas,
+				Profile:       profile,
+				Group:         group,
+				DataLocations: s.dataLocations(policy),
+			})
+		}
+		r.Failures = compare(w.Expect, r.Decision, r.Err)
+		result.Workloads = append(result.Workloads, r)
+	}
+	return result
+}
+
+func (s *Scenario) dataLocations(policy placementv1alpha1.PlacementPolicySpec) map[string]*placementv1alpha1.DataLocationSpec {
+	dataLocations := map[string]*placementv1alpha1.DataLocationSpec{}
+	for _, term := range policy.DataAffinity {
+		for i := range s.DataLocations {
+			if s.DataLocations[i].Name == term.DataLocation {
+				dataLocations[term.DataLocation] = &s.DataLocations[i].Spec
+			}
+		}
+	}
+	return dataLocations
+}
+
+func compare(expect Expectation, decision engine.Decision, err error) []string {
+	var failures []string
+	if expect.Error != "" {
+		if err == nil || !strings.Contains(err.Error(), expect.Error) {
+			failures = append(failures, fmt.Sprintf("expected error containing %q, got %v", expect.Error, err))
+		}
+	} else if err != nil {
+		failures = append(failures, fmt.Sprintf("unexpected error: %v%s", err, rejections(decision.Rejected)))
+	} else {
+		var targets []string
+		replicas := map[string]*int32{}
+		for _, t := range decision.Targets {
+			targets = append(targets, t.SyncTarget)
+			replicas[t.SyncTarget] = t.Replicas
+		}
+		if strings.Join(targets, ",") != strings.Join(expect.Targets, ",") {
+			failures = append(failures, fmt.Sprintf("expected targets [%s], got [%s]%s", strings.Join(expect.Targets, ", "), strings.Join(targets, ", "), rejections(decision.Rejected)))
+		}
+		for _, name := range sets.List(sets.KeySet(expect.Replicas)) {
+			got, chosen := replicas[name]
+			switch {
+			case !chosen:
+				failures = append(failures, fmt.Sprintf("expected %d replicas on %s, which was not chosen", expect.Replicas[name], name))
+			case got == nil || *got != expect.Replicas[name]:
+				failures = append(failures, fmt.Sprintf("expected %d replicas on %s, got %s", expect.Replicas[name], name, replicasString(got)))
+			}
+		}
+	}
+	for _, name := range sets.List(sets.KeySet(expect.Rejected)) {
+		reason, rejected := decision.Rejected[name]
+		switch {
+		case !rejected:
+			failures = append(failures, fmt.Sprintf("expected %s to be rejected because it %s, but it is feasible", name, expect.Rejected[name]))
+		case !strings.Contains(reason, expect.Rejected[name]):
+			failures = append(failures, fmt.Sprintf("expected %s to be rejected because it %s, but it %s", name, expect.Rejected[name], reason))
+		}
+	}
+	return failures
+}
+
+func rejections(rejected map[string]string) string {
+	if len(rejected) == 0 {
+		return ""
+	}
+	var reasons []string
+	for _, name := range sets.List(sets.KeySet(rejected)) {
+		reasons = append(reasons, fmt.Sprintf("%s %s", name, rejected[name]))
+	}
+	return " (rejected: " + strings.Join(reasons, "; ") + ")"
+}
+
+func replicasString(replicas *int32) string {
+	if replicas == nil {
+		return "none"
+	}
+	return fmt.Sprintf("%d", ptr.Deref(replicas, 0))
+}
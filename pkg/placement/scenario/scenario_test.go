@@ -0,0 +1,103 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scenario
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunExample(t *testing.T) {
+	s, err := LoadFile("testdata/regional.yaml")
+	require.NoError(t, err)
+
+	result := s.Run()
+	for _, w := range result.Workloads {
+		require.Empty(t, w.Failures, w.Name)
+	}
+	require.True(t, result.Passed())
+}
+
+func TestRunReportsFailures(t *testing.T) {
+	s, err := Load([]byte(`
+name: failing
+syncTargets:
+- metadata:
+    name: a
+  status:
+    conditions:
+    - type: SyncerReady
+      status: "True"
+- metadata:
+    name: b
+placementPolicies:
+- metadata:
+    name: spread
+workloads:
+- name: wrong targets
+  policy: spread
+  replicas: 2
+  expect:
+    targets: [a, b]
+    replicas:
+      a: 1
+    rejected:
+      a: is unschedulable
+- name: unexpected success
+  policy: spread
+  expect:
+    error: no feasible SyncTargets
+`))
+	require.NoError(t, err)
+
+	result := s.Run()
+	require.False(t, result.Passed())
+	require.Equal(t, []string{
+		"expected targets [a, b], got [a] (rejected: b syncer is not ready)",
+		"expected 1 replicas on a, got 2",
+		"expected a to be rejected because it is unschedulable, but it is feasible",
+	}, result.Workloads[0].Failures)
+	require.Equal(t, []string{
+		`expected error containing "no feasible SyncTargets", got <nil>`,
+	}, result.Workloads[1].Failures)
+}
+
+func TestLoadRejectsInvalidScenarios(t *testing.T) {
+	_, err := Load([]byte(`
+name: typo
+syncTargets: []
+placementPolicies: []
+workloads:
+- name: app
+  policy: spread
+  expect:
+    target: [a]
+`))
+	require.ErrorContains(t, err, `unknown field "target"`)
+
+	_, err = Load([]byte(`
+name: unknown references
+syncTargets: []
+placementPolicies: []
+workloads:
+- name: app
+  policy: spread
+  current: [a]
+`))
+	require.EqualError(t, err, `workload "app" references unknown PlacementPolicy "spread", workload "app" references unknown SyncTarget "a"`)
+}
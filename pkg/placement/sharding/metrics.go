@@ -0,0 +1,67 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"sync"
+
+	compbasemetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+var (
+	memberCount = compbasemetrics.NewGauge(
+		&compbasemetrics.GaugeOpts{
+			Name:           "tmc_placement_shard_members",
+			Help:           "Number of placement controller replicas sharing the workspaces.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+	)
+	ownedWorkspaces = compbasemetrics.NewGauge(
+		&compbasemetrics.GaugeOpts{
+			Name:           "tmc_placement_shard_owned_workspaces",
+			Help:           "Number of workspaces this placement controller replica places in.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+	)
+	skew = compbasemetrics.NewGauge(
+		&compbasemetrics.GaugeOpts{
+			Name:           "tmc_placement_shard_skew",
+			Help:           "Ratio of the workspaces of the most loaded placement controller replica to the mean, 1 if evenly spread.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+	)
+	membershipChanges = compbasemetrics.NewCounter(
+		&compbasemetrics.CounterOpts{
+			Name:           "tmc_placement_shard_membership_changes_total",
+			Help:           "Number of changes of the placement controller replicas sharing the workspaces, as seen by this replica.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+	)
+)
+
+var registerMetrics sync.Once
+
+// Register metrics.
+func Register() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(memberCount)
+		legacyregistry.MustRegister(ownedWorkspaces)
+		legacyregistry.MustRegister(skew)
+		legacyregistry.MustRegister(membershipChanges)
+	})
+}
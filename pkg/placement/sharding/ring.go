@@ -0,0 +1,102 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// virtualNodes is the number of points of each member on the ring, which
+// evens out the share of workspaces of members.
+const virtualNodes = 128
+
+// Ring assigns workspaces to members by consistent hashing, so that a
+// membership change only moves the workspaces of the members that joined or
+// left.
+type Ring struct {
+	members []string
+	points  []point
+}
+
+type point struct {
+	hash   uint64
+	member string
+}
+
+// NewRing returns the ring of the given members.
+func NewRing(members []string) *Ring {
+	r := &Ring{members: append([]string(nil), members...)}
+	sort.Strings(r.members)
+	for _, m := range r.members {
+		for i := range virtualNodes {
+			r.points = append(r.points, point{hash: hash(m + "#" + strconv.Itoa(i)), member: m})
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool {
+		if r.points[i].hash != r.points[j].hash {
+			return r.points[i].hash < r.points[j].hash
+		}
+		return r.points[i].member < r.points[j].member
+	})
+	return r
+}
+
+// Members returns the members of the ring, sorted.
+func (r *Ring) Members() []string {
+	return r.members
+}
+
+// Owner returns the member owning the workspace, or "" if the ring is empty.
+func (r *Ring) Owner(workspace string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+	h := hash(workspace)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i].hash >= h })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.points[i].member
+}
+
+// Equal returns whether two rings have the same members.
+func (r *Ring) Equal(other *Ring) bool {
+	if len(r.members) != len(other.members) {
+		return false
+	}
+	for i := range r.members {
+		if r.members[i] != other.members[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	// Mix the bits, FNV hashes of similar strings are close.
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
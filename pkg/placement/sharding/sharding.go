@@ -0,0 +1,285 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sharding splits the workspaces placed by the placement controller
+// between its replicas. Every replica holds a Lease, renewed periodically,
+// and the replicas holding unexpired Leases are the members of a
+// consistent hash ring assigning each workspace to one of them.
+//
+// Replicas notice membership changes at different times, so a replica only
+// takes over a workspace from another one once the handover delay has
+// passed since it noticed the change, by which time the previous owner has
+// noticed it too and stopped placing in the workspace.
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// MemberLabel marks the Leases of the members.
+	MemberLabel = "tmc.kcp.io/placement-shard"
+
+	leasePrefix          = "kcp-tmc-placement-shard-"
+	defaultLeaseDuration = 30 * time.Second
+	// expiredLeaseGracePeriods is the number of lease durations after which
+	// the expired Leases of replicas that did not stop cleanly are deleted.
+	expiredLeaseGracePeriods = 10
+)
+
+// Options configure a Sharder. Zero values mean the defaults.
+type Options struct {
+	// LeaseDuration is how long a replica is a member after it last renewed
+	// its Lease, and the handover delay. Leases are renewed every third of
+	// it.
+	LeaseDuration time.Duration
+	// Workspaces returns the workspaces known to the replica, to report the
+	// skew of the shards. Skew is not reported if nil.
+	Workspaces func() []string
+}
+
+// Sharder tells which workspaces a replica owns.
+type Sharder struct {
+	client    kubernetes.Interface
+	namespace string
+	identity  string
+	options   Options
+	now       func() time.Time
+
+	lock sync.RWMutex
+	// ring is the current membership, and previous the membership before
+	// the current handover, if any. A workspace is owned if the replica
+	// owns it in both.
+	ring, previous *Ring
+	changedAt      time.Time
+	handedOver     bool
+	handlers       []func()
+}
+
+// New returns a sharder for the replica with the given identity, holding
+// its Lease in namespace. It owns no workspace until it runs.
+func New(client kubernetes.Interface, namespace, identity string, options Options) *Sharder {
+	Register()
+	if options.LeaseDuration <= 0 {
+		options.LeaseDuration = defaultLeaseDuration
+	}
+	empty := NewRing(nil)
+	return &Sharder{
+		client:     client,
+		namespace:  namespace,
+		identity:   identity,
+		options:    options,
+		now:        time.Now,
+		ring:       empty,
+		previous:   empty,
+		handedOver: true,
+	}
+}
+
+// AddHandler adds a function called when the workspaces the replica owns
+// may have changed.
+func (s *Sharder) AddHandler(handler func()) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.handlers = append(s.handlers, handler)
+}
+
+// Owns returns whether the replica owns the workspace.
+func (s *Sharder) Owns(workspace string) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.ring.Owner(workspace) == s.identity && s.previous.Owner(workspace) == s.identity
+}
+
+// Members returns the current members.
+func (s *Sharder) Members() []string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.ring.Members()
+}
+
+// Run renews the Lease of the replica and follows the membership until ctx
+// is done, then releases the Lease.
+func (s *Sharder) Run(ctx context.Context) {
+	logger := klog.FromContext(ctx).WithValues("identity", s.identity)
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := s.sync(ctx); err != nil && ctx.Err() == nil {
+			logger.Error(err, "failed to sync placement shard membership")
+		}
+	}, s.options.LeaseDuration/3)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.client.CoordinationV1().Leases(s.namespace).Delete(ctx, s.leaseName(s.identity), metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		logger.Error(err, "failed to release placement shard lease")
+	}
+}
+
+func (s *Sharder) sync(ctx context.Context) error {
+	if err := s.renew(ctx); err != nil {
+		return err
+	}
+	members, err := s.members(ctx)
+	if err != nil {
+		return err
+	}
+	ring := NewRing(members)
+
+	now := s.now()
+	s.lock.Lock()
+	changed := false
+	if !ring.Equal(s.ring) {
+		klog.FromContext(ctx).Info("placement shard membership changed", "members", ring.Members())
+		membershipChanges.Inc()
+		// A handover in progress keeps waiting for the workspaces gained
+		// since the last completed one.
+		if s.handedOver {
+			s.previous = s.ring
+		}
+		s.ring, s.changedAt, s.handedOver = ring, now, false
+		changed = true
+	}
+	if !s.handedOver && !now.Before(s.changedAt.Add(s.options.LeaseDuration)) {
+		s.previous, s.handedOver = s.ring, true
+		changed = true
+	}
+	handlers := s.handlers
+	s.lock.Unlock()
+
+	s.report(ring)
+	if changed {
+		for _, handler := range handlers {
+			handler()
+		}
+	}
+	return nil
+}
+
+// renew creates or renews the Lease of the replica.
+func (s *Sharder) renew(ctx context.Context) error {
+	leases := s.client.CoordinationV1().Leases(s.namespace)
+	now := metav1.NewMicroTime(s.now())
+	lease, err := leases.Get(ctx, s.leaseName(s.identity), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = leases.Create(ctx, &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: s.namespace,
+				Name:      s.leaseName(s.identity),
+				Labels:    map[string]string{MemberLabel: "true"},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       ptr.To(s.identity),
+				LeaseDurationSeconds: ptr.To(int32(s.options.LeaseDuration.Seconds())),
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	lease = lease.DeepCopy()
+	lease.Spec.HolderIdentity = ptr.To(s.identity)
+	lease.Spec.LeaseDurationSeconds = ptr.To(int32(s.options.LeaseDuration.Seconds()))
+	lease.Spec.RenewTime = &now
+	_, err = leases.Update(ctx, lease, metav1.UpdateOptions{})
+	return err
+}
+
+// members returns the holders of unexpired Leases, and deletes Leases
+// expired long ago.
+func (s *Sharder) members(ctx context.Context) ([]string, error) {
+	leases, err := s.client.CoordinationV1().Leases(s.namespace).List(ctx, metav1.ListOptions{LabelSelector: MemberLabel + "=true"})
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	var members []string
+	for i := range leases.Items {
+		lease := &leases.Items[i]
+		if lease.Spec.HolderIdentity == nil || lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+			continue
+		}
+		expiry := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+		if now.Before(expiry) {
+			members = append(members, *lease.Spec.HolderIdentity)
+			continue
+		}
+		if now.Sub(expiry) > expiredLeaseGracePeriods*s.options.LeaseDuration {
+			err := s.client.CoordinationV1().Leases(s.namespace).Delete(ctx, lease.Name, metav1.DeleteOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				klog.FromContext(ctx).Error(err, "failed to delete expired placement shard lease", "lease", lease.Name)
+			}
+		}
+	}
+	return members, nil
+}
+
+// report updates the metrics of the shards.
+func (s *Sharder) report(ring *Ring) {
+	memberCount.Set(float64(len(ring.Members())))
+	if s.options.Workspaces == nil {
+		return
+	}
+	counts := map[string]int{}
+	owned := 0
+	for _, ws := range s.options.Workspaces() {
+		counts[ring.Owner(ws)]++
+		if s.Owns(ws) {
+			owned++
+		}
+	}
+	ownedWorkspaces.Set(float64(owned))
+	skew.Set(skewOf(counts, len(ring.Members())))
+}
+
+// skewOf returns the ratio of the workspaces of the most loaded member to
+// the mean, 1 if the workspaces are evenly spread.
+func skewOf(counts map[string]int, members int) float64 {
+	if members == 0 {
+		return 0
+	}
+	total, most := 0, 0
+	for _, n := range counts {
+		total += n
+		most = max(most, n)
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(most) / (float64(total) / float64(members))
+}
+
+// leaseName returns the name of the Lease of a replica. Identities are not
+// valid names, so they are hashed.
+func (s *Sharder) leaseName(identity string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(identity))
+	return fmt.Sprintf("%s%016x", leasePrefix, h.Sum64())
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func workspaces(n int) []string {
+	out := make([]string, 0, n)
+	for i := range n {
+		out = append(out, fmt.Sprintf("root:org:ws-%d", i))
+	}
+	return out
+}
+
+func TestRing(t *testing.T) {
+	require.Empty(t, NewRing(nil).Owner("root:org"))
+
+	ring := NewRing([]string{"c", "a", "b"})
+	require.Equal(t, []string{"a", "b", "c"}, ring.Members())
+	require.True(t, ring.Equal(NewRing([]string{"a", "b", "c"})))
+	require.False(t, ring.Equal(NewRing([]string{"a", "b"})))
+
+	counts := map[string]int{}
+	for _, ws := range workspaces(3000) {
+		counts[ring.Owner(ws)]++
+	}
+	require.Less(t, skewOf(counts, 3), 1.3, "workspaces are spread evenly: %v", counts)
+
+	grown := NewRing([]string{"a", "b", "c", "d"})
+	moved := 0
+	for _, ws := range workspaces(3000) {
+		if owner := grown.Owner(ws); owner != ring.Owner(ws) {
+			require.Equal(t, "d", owner, "workspaces only move to the new member")
+			moved++
+		}
+	}
+	require.InDelta(t, 750, moved, 250)
+}
+
+func TestSharder(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	all := workspaces(100)
+	newSharder := func(identity string) (*Sharder, *int) {
+		s := New(client, metav1.NamespaceSystem, identity, Options{LeaseDuration: 30 * time.Second, Workspaces: func() []string { return all }})
+		s.now = clock
+		changes := 0
+		s.AddHandler(func() { changes++ })
+		return s, &changes
+	}
+	owned := func(s *Sharder) int {
+		n := 0
+		for _, ws := range all {
+			if s.Owns(ws) {
+				n++
+			}
+		}
+		return n
+	}
+
+	a, aChanges := newSharder("replica-a")
+	require.Zero(t, owned(a), "nothing is owned before the first sync")
+	require.NoError(t, a.sync(ctx))
+	require.Equal(t, []string{"replica-a"}, a.Members())
+	require.Zero(t, owned(a), "workspaces are taken over after the handover delay")
+	require.Equal(t, 1, *aChanges)
+
+	now = now.Add(30 * time.Second)
+	require.NoError(t, a.sync(ctx))
+	require.Equal(t, 100, owned(a))
+	require.Equal(t, 2, *aChanges)
+
+	b, _ := newSharder("replica-b")
+	require.NoError(t, b.sync(ctx))
+	require.NoError(t, a.sync(ctx))
+	require.Equal(t, []string{"replica-a", "replica-b"}, a.Members())
+	require.Zero(t, owned(b))
+	lost := 100 - owned(a)
+	require.NotZero(t, lost, "workspaces of the new member are released at once")
+
+	now = now.Add(10 * time.Second)
+	require.NoError(t, a.sync(ctx))
+	require.NoError(t, b.sync(ctx))
+	require.Zero(t, owned(b), "the handover delay has not passed")
+
+	now = now.Add(20 * time.Second)
+	require.NoError(t, a.sync(ctx))
+	require.NoError(t, b.sync(ctx))
+	require.Equal(t, lost, owned(b))
+	require.Equal(t, 100, owned(a)+owned(b))
+
+	// b stops without releasing its Lease, which expires.
+	now = now.Add(31 * time.Second)
+	require.NoError(t, a.sync(ctx))
+	require.Equal(t, []string{"replica-a"}, a.Members())
+	require.Equal(t, 100-lost, owned(a))
+	now = now.Add(30 * time.Second)
+	require.NoError(t, a.sync(ctx))
+	require.Equal(t, 100, owned(a))
+
+	now = now.Add(expiredLeaseGracePeriods * 30 * time.Second)
+	require.NoError(t, a.sync(ctx))
+	leases, err := client.CoordinationV1().Leases(metav1.NamespaceSystem).List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, leases.Items, 1, "expired Leases are deleted eventually")
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	a.Run(ctx)
+	leases, err = client.CoordinationV1().Leases(metav1.NamespaceSystem).List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Empty(t, leases.Items, "the Lease is released when stopping")
+}
+
+func TestSkew(t *testing.T) {
+	require.Zero(t, skewOf(nil, 0))
+	require.Zero(t, skewOf(map[string]int{}, 2))
+	require.Equal(t, 1.0, skewOf(map[string]int{"a": 5, "b": 5}, 2))
+	require.Equal(t, 2.0, skewOf(map[string]int{"a": 10}, 2), "members without workspaces count")
+}
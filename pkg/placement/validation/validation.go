@@ -0,0 +1,408 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validation validates placement specs beyond what their CRD
+// schemas can, e.g. CEL constraints, location selectors no SyncTarget can
+// match or strategies contradicting the number of targets. It is used at
+// admission, so invalid policies are rejected when they are written rather
+// than when the placement controller fails to apply them.
+package validation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/sets"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"github.com/kcp-dev/kcp/pkg/placement/constraint"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+)
+
+// minDecisionTTL bounds how often placement decisions are revalidated.
+const minDecisionTTL = time.Minute
+
+var strategies = []string{
+	string(placementv1alpha1.PlacementStrategySingleton),
+	string(placementv1alpha1.PlacementStrategyHighAvailability),
+	string(placementv1alpha1.PlacementStrategySpread),
+	string(placementv1alpha1.PlacementStrategyWeightedSpread),
+}
+
+// ValidateWorkloadPlacementAdvancedSpec validates the rules of a
+// WorkloadPlacementAdvanced spec like PlacementPolicy specs, and their
+// workload selectors. It warns about rollout and revision history settings,
+// which do not apply to rules.
+func ValidateWorkloadPlacementAdvancedSpec(spec *placementv1alpha1.WorkloadPlacementAdvancedSpec, path *field.Path) (field.ErrorList, []string) {
+	var errs field.ErrorList
+	var warnings []string
+
+	for i, rule := range spec.Rules {
+		rulePath := path.Child("rules").Index(i)
+		if rule.WorkloadSelector != nil {
+			errs = append(errs, metav1validation.ValidateLabelSelector(rule.WorkloadSelector, metav1validation.LabelSelectorValidationOptions{}, rulePath.Child("workloadSelector"))...)
+		}
+		ruleErrs, ruleWarnings := ValidatePlacementPolicySpec(&rule.Placement, rulePath.Child("placement"))
+		errs = append(errs, ruleErrs...)
+		warnings = append(warnings, ruleWarnings...)
+		if rule.Placement.Rollout != nil || rule.Placement.RevisionHistoryLimit != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: rollout and revisionHistoryLimit do not apply to rules, changes apply to all workloads of the rule at once", rulePath.Child("placement")))
+		}
+	}
+	return errs, warnings
+}
+
+// ValidatePlacementPolicySpec validates the parts of a PlacementPolicy spec
+// that the CRD schema cannot. It returns the errors and warnings about
+// settings that do not apply or expressions that are expensive to
+// evaluate.
+func ValidatePlacementPolicySpec(spec *placementv1alpha1.PlacementPolicySpec, path *field.Path) (field.ErrorList, []string) {
+	var errs field.ErrorList
+	var warnings []string
+
+	if spec.LocationSelector != nil {
+		selectorPath := path.Child("locationSelector")
+		selectorErrs := metav1validation.ValidateLabelSelector(spec.LocationSelector, metav1validation.LabelSelectorValidationOptions{}, selectorPath)
+		if len(selectorErrs) == 0 {
+			selectorErrs = validateSatisfiable(spec.LocationSelector, selectorPath)
+		}
+		errs = append(errs, selectorErrs...)
+	}
+	if spec.TopologyKey != "" {
+		errs = append(errs, metav1validation.ValidateLabelName(spec.TopologyKey, path.Child("topologyKey"))...)
+	}
+	if spec.DecisionTTL != nil && spec.DecisionTTL.Duration < minDecisionTTL {
+		errs = append(errs, field.Invalid(path.Child("decisionTTL"), spec.DecisionTTL.Duration.String(), fmt.Sprintf("must be at least %s", minDecisionTTL)))
+	}
+	errs = append(errs, validateTopologySpreadConstraints(spec.TopologySpreadConstraints, path.Child("topologySpreadConstraints"))...)
+	if spec.Strategy == placementv1alpha1.PlacementStrategySingleton && len(spec.TopologySpreadConstraints) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%s: does not apply to the %s strategy", path.Child("topologySpreadConstraints"), spec.Strategy))
+	}
+	errs = append(errs, validateTolerations(spec.Tolerations, path.Child("tolerations"))...)
+	errs = append(errs, validateRequirements(spec.Requirements, path.Child("requirements"))...)
+	errs = append(errs, validateDataAffinity(spec.DataAffinity, path.Child("dataAffinity"))...)
+	affinityErrs, affinityWarnings := validateWorkloadAffinity(spec.WorkloadAffinity, path.Child("workloadAffinity"))
+	errs = append(errs, affinityErrs...)
+	warnings = append(warnings, affinityWarnings...)
+
+	strategyErrs, strategyWarnings := validateStrategy(spec, path)
+	errs = append(errs, strategyErrs...)
+	warnings = append(warnings, strategyWarnings...)
+
+	errs = append(errs, validateLocationWeights(spec, path)...)
+	if spec.Strategy != placementv1alpha1.PlacementStrategyWeightedSpread && len(spec.LocationWeights) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%s: only applies to the %s strategy", path.Child("locationWeights"), placementv1alpha1.PlacementStrategyWeightedSpread))
+	}
+	constraintErrs, constraintWarnings := validateConstraints(spec.Constraints, path.Child("constraints"))
+	errs = append(errs, constraintErrs...)
+	warnings = append(warnings, constraintWarnings...)
+	return errs, warnings
+}
+
+// validateStrategy rejects unknown strategies and numbers of targets the
+// strategy cannot place on.
+func validateStrategy(spec *placementv1alpha1.PlacementPolicySpec, path *field.Path) (field.ErrorList, []string) {
+	var errs field.ErrorList
+	var warnings []string
+
+	if spec.Strategy != "" && !sets.New(strategies...).Has(string(spec.Strategy)) {
+		errs = append(errs, field.NotSupported(path.Child("strategy"), spec.Strategy, strategies))
+	}
+	if spec.NumberOfTargets == nil {
+		return errs, warnings
+	}
+	n, nPath := *spec.NumberOfTargets, path.Child("numberOfTargets")
+	switch {
+	case n < 1:
+		errs = append(errs, field.Invalid(nPath, n, "must be at least 1"))
+	case spec.Strategy == placementv1alpha1.PlacementStrategySingleton && n != 1:
+		errs = append(errs, field.Invalid(nPath, n, fmt.Sprintf("the %s strategy places on exactly one SyncTarget", spec.Strategy)))
+	case spec.Strategy == placementv1alpha1.PlacementStrategyHighAvailability && n < 2:
+		errs = append(errs, field.Invalid(nPath, n, fmt.Sprintf("the %s strategy places on at least two SyncTargets", spec.Strategy)))
+	case spec.Strategy == placementv1alpha1.PlacementStrategyWeightedSpread:
+		warnings = append(warnings, fmt.Sprintf("%s: does not apply to the %s strategy, which places by location weights", nPath, spec.Strategy))
+	}
+	return errs, warnings
+}
+
+// validateSatisfiable rejects location selectors no SyncTarget can match,
+// because their requirements on a label contradict each other.
+func validateSatisfiable(selector *metav1.LabelSelector, path *field.Path) field.ErrorList {
+	type labelRequirements struct {
+		// allowed are the values the label may have, any if nil.
+		allowed  sets.Set[string]
+		excluded sets.Set[string]
+		exists   bool
+		absent   bool
+	}
+	byKey := map[string]*labelRequirements{}
+	requirementsOf := func(key string) *labelRequirements {
+		r, ok := byKey[key]
+		if !ok {
+			r = &labelRequirements{excluded: sets.New[string]()}
+			byKey[key] = r
+		}
+		return r
+	}
+	allow := func(r *labelRequirements, values ...string) {
+		r.exists = true
+		if r.allowed == nil {
+			r.allowed = sets.New(values...)
+		} else {
+			r.allowed = r.allowed.Intersection(sets.New(values...))
+		}
+	}
+
+	for key, value := range selector.MatchLabels {
+		allow(requirementsOf(key), value)
+	}
+	for _, e := range selector.MatchExpressions {
+		r := requirementsOf(e.Key)
+		switch e.Operator {
+		case metav1.LabelSelectorOpIn:
+			allow(r, e.Values...)
+		case metav1.LabelSelectorOpNotIn:
+			r.excluded.Insert(e.Values...)
+		case metav1.LabelSelectorOpExists:
+			r.exists = true
+		case metav1.LabelSelectorOpDoesNotExist:
+			r.absent = true
+		}
+	}
+
+	keys := make([]string, 0, len(byKey))
+	for key := range byKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var contradictions []string
+	for _, key := range keys {
+		r := byKey[key]
+		switch {
+		case r.exists && r.absent:
+			contradictions = append(contradictions, fmt.Sprintf("label %q must be both set and unset", key))
+		case r.allowed != nil && r.allowed.Difference(r.excluded).Len() == 0:
+			contradictions = append(contradictions, fmt.Sprintf("no value of label %q satisfies all requirements", key))
+		}
+	}
+	if len(contradictions) == 0 {
+		return nil
+	}
+	return field.ErrorList{field.Invalid(path, metav1.FormatLabelSelector(selector), "never matches a SyncTarget: "+strings.Join(contradictions, ", "))}
+}
+
+// validateTopologySpreadConstraints rejects invalid topology keys and
+// constraints repeating a topology key with the same action.
+func validateTopologySpreadConstraints(constraints []placementv1alpha1.TopologySpreadConstraint, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	seen := map[[2]string]bool{}
+	for i, c := range constraints {
+		keyPath := path.Index(i).Child("topologyKey")
+		errs = append(errs, metav1validation.ValidateLabelName(c.TopologyKey, keyPath)...)
+		if c.MaxSkew < 0 {
+			errs = append(errs, field.Invalid(path.Index(i).Child("maxSkew"), c.MaxSkew, "must be at least 1"))
+		}
+		key := [2]string{c.TopologyKey, string(c.WhenUnsatisfiable)}
+		if seen[key] {
+			errs = append(errs, field.Duplicate(keyPath, c.TopologyKey))
+		}
+		seen[key] = true
+	}
+	return errs
+}
+
+// validateTolerations rejects tolerations the core API rejects on pods.
+func validateTolerations(tolerations []corev1.Toleration, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, t := range tolerations {
+		tPath := path.Index(i)
+		if t.Key != "" {
+			errs = append(errs, metav1validation.ValidateLabelName(t.Key, tPath.Child("key"))...)
+		}
+		switch t.Operator {
+		case corev1.TolerationOpEqual, "":
+			if t.Key == "" {
+				errs = append(errs, field.Invalid(tPath.Child("operator"), t.Operator, "must be Exists when key is empty"))
+			}
+			for _, msg := range utilvalidation.IsValidLabelValue(t.Value) {
+				errs = append(errs, field.Invalid(tPath.Child("value"), t.Value, msg))
+			}
+		case corev1.TolerationOpExists:
+			if t.Value != "" {
+				errs = append(errs, field.Invalid(tPath.Child("value"), t.Value, "must be empty when operator is Exists"))
+			}
+		default:
+			errs = append(errs, field.NotSupported(tPath.Child("operator"), t.Operator, []corev1.TolerationOperator{corev1.TolerationOpEqual, corev1.TolerationOpExists}))
+		}
+		switch t.Effect {
+		case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+		default:
+			errs = append(errs, field.NotSupported(tPath.Child("effect"), t.Effect, []corev1.TaintEffect{corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute}))
+		}
+		if t.TolerationSeconds != nil && t.Effect != corev1.TaintEffectNoExecute {
+			errs = append(errs, field.Invalid(tPath.Child("effect"), t.Effect, "must be NoExecute when tolerationSeconds is set"))
+		}
+	}
+	return errs
+}
+
+// validateRequirements rejects requirements no SyncTarget can satisfy.
+func validateRequirements(r *placementv1alpha1.TargetRequirements, path *field.Path) field.ErrorList {
+	if r == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	if r.MinKubernetesVersion != "" {
+		if _, err := version.ParseGeneric(r.MinKubernetesVersion); err != nil {
+			errs = append(errs, field.Invalid(path.Child("minKubernetesVersion"), r.MinKubernetesVersion, err.Error()))
+		}
+	}
+
+	names := make([]string, 0, len(r.Resources))
+	for name := range r.Resources {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		namePath := path.Child("resources").Key(name)
+		for _, msg := range utilvalidation.IsQualifiedName(name) {
+			errs = append(errs, field.Invalid(namePath, name, msg))
+		}
+		if q := r.Resources[corev1.ResourceName(name)]; q.Sign() < 0 {
+			errs = append(errs, field.Invalid(namePath, q.String(), "must not be negative"))
+		}
+	}
+	return errs
+}
+
+// validateDataAffinity rejects terms on the same DataLocation, which may
+// contradict each other.
+func validateDataAffinity(terms []placementv1alpha1.DataAffinityTerm, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	seen := sets.New[string]()
+	for i, term := range terms {
+		if seen.Has(term.DataLocation) {
+			errs = append(errs, field.Duplicate(path.Index(i).Child("dataLocation"), term.DataLocation))
+		}
+		seen.Insert(term.DataLocation)
+	}
+	return errs
+}
+
+// validateWorkloadAffinity rejects unknown relations and types, invalid
+// selectors and namespaces, and warns about weights of required terms.
+func validateWorkloadAffinity(terms []placementv1alpha1.WorkloadAffinityTerm, path *field.Path) (field.ErrorList, []string) {
+	var errs field.ErrorList
+	var warnings []string
+	for i, term := range terms {
+		termPath := path.Index(i)
+		switch term.Relation {
+		case placementv1alpha1.WorkloadAffinityPlaceWith, placementv1alpha1.WorkloadAffinityNeverColocateWith:
+		default:
+			errs = append(errs, field.NotSupported(termPath.Child("relation"), term.Relation, []placementv1alpha1.WorkloadAffinityRelation{
+				placementv1alpha1.WorkloadAffinityPlaceWith, placementv1alpha1.WorkloadAffinityNeverColocateWith,
+			}))
+		}
+		switch term.Type {
+		case "", placementv1alpha1.WorkloadAffinityPreferred:
+		case placementv1alpha1.WorkloadAffinityRequired:
+			if term.Weight != 0 {
+				warnings = append(warnings, fmt.Sprintf("%s: only applies to Preferred terms", termPath.Child("weight")))
+			}
+		default:
+			errs = append(errs, field.NotSupported(termPath.Child("type"), term.Type, []placementv1alpha1.WorkloadAffinityType{
+				placementv1alpha1.WorkloadAffinityRequired, placementv1alpha1.WorkloadAffinityPreferred,
+			}))
+		}
+		errs = append(errs, metav1validation.ValidateLabelSelector(&term.Selector, metav1validation.LabelSelectorValidationOptions{}, termPath.Child("selector"))...)
+		for j, namespace := range term.Namespaces {
+			for _, msg := range utilvalidation.IsDNS1123Label(namespace) {
+				errs = append(errs, field.Invalid(termPath.Child("namespaces").Index(j), namespace, msg))
+			}
+		}
+	}
+	return errs, warnings
+}
+
+// validateConstraints rejects invalid expressions, duplicate names and
+// unknown types, and warns about expensive expressions and weights of
+// required constraints.
+func validateConstraints(constraints []placementv1alpha1.TargetConstraint, path *field.Path) (field.ErrorList, []string) {
+	var errs field.ErrorList
+	var warnings []string
+	names := sets.New[string]()
+	for i, tc := range constraints {
+		tcPath := path.Index(i)
+		if tc.Name != "" {
+			if names.Has(tc.Name) {
+				errs = append(errs, field.Duplicate(tcPath.Child("name"), tc.Name))
+			}
+			names.Insert(tc.Name)
+		}
+		switch tc.Type {
+		case "", placementv1alpha1.ConstraintRequired:
+			if tc.Weight != 0 {
+				warnings = append(warnings, fmt.Sprintf("%s: only applies to Preferred constraints", tcPath.Child("weight")))
+			}
+		case placementv1alpha1.ConstraintPreferred:
+		default:
+			errs = append(errs, field.NotSupported(tcPath.Child("type"), tc.Type, []placementv1alpha1.ConstraintType{
+				placementv1alpha1.ConstraintRequired, placementv1alpha1.ConstraintPreferred,
+			}))
+		}
+		exprPath := tcPath.Child("expression")
+		c, err := constraint.Compile(tc.Expression)
+		if err != nil {
+			errs = append(errs, field.Invalid(exprPath, tc.Expression, err.Error()))
+			continue
+		}
+		if c.Cost > constraint.ExpensiveCost {
+			warnings = append(warnings, fmt.Sprintf("%s: estimated cost %d exceeds %d, evaluating it for every SyncTarget may slow down placement", exprPath, c.Cost, constraint.ExpensiveCost))
+		}
+	}
+	return errs, warnings
+}
+
+// validateLocationWeights requires a positive weight for some location with
+// the WeightedSpread strategy, which places nothing otherwise.
+func validateLocationWeights(spec *placementv1alpha1.PlacementPolicySpec, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	weightsPath := path.Child("locationWeights")
+	seen := sets.New[string]()
+	positive := false
+	for i, lw := range spec.LocationWeights {
+		if seen.Has(lw.Location) {
+			errs = append(errs, field.Duplicate(weightsPath.Index(i).Child("location"), lw.Location))
+		}
+		seen.Insert(lw.Location)
+		if lw.Weight < 0 {
+			errs = append(errs, field.Invalid(weightsPath.Index(i).Child("weight"), lw.Weight, "must not be negative"))
+		}
+		positive = positive || lw.Weight > 0
+	}
+	if spec.Strategy == placementv1alpha1.PlacementStrategyWeightedSpread && !positive {
+		errs = append(errs, field.Required(weightsPath, fmt.Sprintf("the %s strategy needs a location with a positive weight", placementv1alpha1.PlacementStrategyWeightedSpread)))
+	}
+	return errs
+}
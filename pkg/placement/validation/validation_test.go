@@ -0,0 +1,192 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+)
+
+func TestValidatePlacementPolicySpec(t *testing.T) {
+	tests := map[string]struct {
+		spec         placementv1alpha1.PlacementPolicySpec
+		wantErr      string
+		wantWarnings int
+	}{
+		"valid": {
+			spec: placementv1alpha1.PlacementPolicySpec{
+				LocationSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"region": "eu"},
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "region", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"us"}},
+						{Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"gold", "silver"}},
+						{Key: "tier", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"gold"}},
+					},
+				},
+				Strategy:        placementv1alpha1.PlacementStrategyHighAvailability,
+				NumberOfTargets: ptr.To[int32](3),
+				Requirements: &placementv1alpha1.TargetRequirements{Resources: corev1.ResourceList{
+					"nvidia.com/gpu": resource.MustParse("2"),
+				}},
+				DataAffinity: []placementv1alpha1.DataAffinityTerm{
+					{DataLocation: "orders", Type: placementv1alpha1.DataAffinityRequired},
+					{DataLocation: "catalog"},
+				},
+				Tolerations: []corev1.Toleration{
+					{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+					{Key: "node.kubernetes.io/unreachable", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute, TolerationSeconds: ptr.To[int64](300)},
+					{Operator: corev1.TolerationOpExists},
+				},
+			},
+		},
+		"unknown strategy": {
+			spec:    placementv1alpha1.PlacementPolicySpec{Strategy: "RoundRobin"},
+			wantErr: `spec.strategy: Unsupported value: "RoundRobin"`,
+		},
+		"no targets": {
+			spec:    placementv1alpha1.PlacementPolicySpec{NumberOfTargets: ptr.To[int32](0)},
+			wantErr: "spec.numberOfTargets",
+		},
+		"several singleton targets": {
+			spec:    placementv1alpha1.PlacementPolicySpec{Strategy: placementv1alpha1.PlacementStrategySingleton, NumberOfTargets: ptr.To[int32](2)},
+			wantErr: "exactly one SyncTarget",
+		},
+		"one highly available target": {
+			spec:    placementv1alpha1.PlacementPolicySpec{Strategy: placementv1alpha1.PlacementStrategyHighAvailability, NumberOfTargets: ptr.To[int32](1)},
+			wantErr: "at least two SyncTargets",
+		},
+		"number of weighted spread targets is allowed with a warning": {
+			spec: placementv1alpha1.PlacementPolicySpec{
+				Strategy:        placementv1alpha1.PlacementStrategyWeightedSpread,
+				NumberOfTargets: ptr.To[int32](2),
+				LocationWeights: []placementv1alpha1.LocationWeight{{Location: "eu", Weight: 1}},
+			},
+			wantWarnings: 1,
+		},
+		"label both set and unset": {
+			spec: placementv1alpha1.PlacementPolicySpec{LocationSelector: &metav1.LabelSelector{
+				MatchLabels:      map[string]string{"region": "eu"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "region", Operator: metav1.LabelSelectorOpDoesNotExist}},
+			}},
+			wantErr: `label "region" must be both set and unset`,
+		},
+		"disjoint label values": {
+			spec: placementv1alpha1.PlacementPolicySpec{LocationSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "region", Operator: metav1.LabelSelectorOpIn, Values: []string{"eu", "us"}},
+					{Key: "region", Operator: metav1.LabelSelectorOpIn, Values: []string{"ap"}},
+				},
+			}},
+			wantErr: `no value of label "region" satisfies all requirements`,
+		},
+		"excluded label values": {
+			spec: placementv1alpha1.PlacementPolicySpec{LocationSelector: &metav1.LabelSelector{
+				MatchLabels:      map[string]string{"region": "eu"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "region", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"eu"}}},
+			}},
+			wantErr: `no value of label "region" satisfies all requirements`,
+		},
+		"negative resource requirement": {
+			spec: placementv1alpha1.PlacementPolicySpec{Requirements: &placementv1alpha1.TargetRequirements{Resources: corev1.ResourceList{
+				"nvidia.com/gpu": resource.MustParse("-1"),
+			}}},
+			wantErr: "spec.requirements.resources[nvidia.com/gpu]",
+		},
+		"invalid resource name": {
+			spec: placementv1alpha1.PlacementPolicySpec{Requirements: &placementv1alpha1.TargetRequirements{Resources: corev1.ResourceList{
+				"nvidia gpu": resource.MustParse("1"),
+			}}},
+			wantErr: "spec.requirements.resources[nvidia gpu]",
+		},
+		"duplicate data affinity": {
+			spec: placementv1alpha1.PlacementPolicySpec{DataAffinity: []placementv1alpha1.DataAffinityTerm{
+				{DataLocation: "orders", Type: placementv1alpha1.DataAffinityRequired},
+				{DataLocation: "orders", Type: placementv1alpha1.DataAffinityPreferred},
+			}},
+			wantErr: "spec.dataAffinity[1].dataLocation",
+		},
+		"unknown workload affinity relation": {
+			spec: placementv1alpha1.PlacementPolicySpec{WorkloadAffinity: []placementv1alpha1.WorkloadAffinityTerm{
+				{Relation: "AvoidRegion", Selector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}},
+			}},
+			wantErr: `spec.workloadAffinity[0].relation: Unsupported value: "AvoidRegion"`,
+		},
+		"weight of required workload affinity": {
+			spec: placementv1alpha1.PlacementPolicySpec{WorkloadAffinity: []placementv1alpha1.WorkloadAffinityTerm{{
+				Relation:   placementv1alpha1.WorkloadAffinityPlaceWith,
+				Selector:   metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+				Namespaces: []string{"shop"},
+				Type:       placementv1alpha1.WorkloadAffinityRequired,
+				Weight:     10,
+			}}},
+			wantWarnings: 1,
+		},
+		"duplicate constraint names": {
+			spec: placementv1alpha1.PlacementPolicySpec{Constraints: []placementv1alpha1.TargetConstraint{
+				{Name: "gold", Expression: `target.metadata.labels.tier == "gold"`},
+				{Name: "gold", Expression: `target.spec.location == "eu"`, Type: placementv1alpha1.ConstraintPreferred},
+			}},
+			wantErr: "spec.constraints[1].name",
+		},
+		"unknown constraint type": {
+			spec: placementv1alpha1.PlacementPolicySpec{Constraints: []placementv1alpha1.TargetConstraint{
+				{Expression: `target.spec.location == "eu"`, Type: "Optional"},
+			}},
+			wantErr: `spec.constraints[0].type: Unsupported value: "Optional"`,
+		},
+		"weight of required constraint": {
+			spec: placementv1alpha1.PlacementPolicySpec{Constraints: []placementv1alpha1.TargetConstraint{
+				{Expression: `target.spec.location == "eu"`, Type: placementv1alpha1.ConstraintRequired, Weight: 10},
+			}},
+			wantWarnings: 1,
+		},
+		"toleration without key": {
+			spec:    placementv1alpha1.PlacementPolicySpec{Tolerations: []corev1.Toleration{{Value: "gpu"}}},
+			wantErr: "spec.tolerations[0].operator",
+		},
+		"toleration with value and Exists": {
+			spec:    placementv1alpha1.PlacementPolicySpec{Tolerations: []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists, Value: "gpu"}}},
+			wantErr: "spec.tolerations[0].value",
+		},
+		"toleration seconds without NoExecute": {
+			spec: placementv1alpha1.PlacementPolicySpec{Tolerations: []corev1.Toleration{
+				{Key: "dedicated", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule, TolerationSeconds: ptr.To[int64](60)},
+			}},
+			wantErr: "spec.tolerations[0].effect",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			errs, warnings := ValidatePlacementPolicySpec(&tc.spec, field.NewPath("spec"))
+			if tc.wantErr != "" {
+				require.ErrorContains(t, errs.ToAggregate(), tc.wantErr)
+			} else {
+				require.Empty(t, errs)
+			}
+			require.Len(t, warnings, tc.wantWarnings, "warnings: %v", warnings)
+		})
+	}
+}
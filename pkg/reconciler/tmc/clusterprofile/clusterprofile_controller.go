@@ -0,0 +1,225 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterprofile
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	kcpcache "github.com/kcp-dev/apimachinery/v2/pkg/cache"
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/tmcinformers"
+	"github.com/kcp-dev/kcp/pkg/tmc/workspacequeue"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+const (
+	ControllerName = "kcp-tmc-clusterprofile"
+
+	// DefaultNamespace is the namespace ClusterProfiles are exported into
+	// when no namespace is configured.
+	DefaultNamespace = "tmc-inventory"
+)
+
+var (
+	// SyncTargetsGVR is the resource the exporter reads.
+	SyncTargetsGVR = tmcv1alpha1.SchemeGroupVersion.WithResource("synctargets")
+	// ClusterProfilesGVR is the SIG-Multicluster ClusterInventory resource the
+	// exporter writes.
+	ClusterProfilesGVR = schema.GroupVersionResource{Group: "multicluster.x-k8s.io", Version: "v1alpha1", Resource: "clusterprofiles"}
+	namespacesGVR      = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+)
+
+// NewController returns a controller that exports every SyncTarget as a
+// ClusterProfile in the same workspace, so that tooling built against the
+// ClusterInventory API can discover the TMC fleet.
+func NewController(
+	namespace string,
+	syncTargetInformer *tmcinformers.Informer,
+	dynamicClusterClient kcpdynamic.ClusterInterface,
+) (*controller, error) {
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+
+	c := &controller{
+		queue:     workspacequeue.NewRateLimitingQueue(ControllerName),
+		namespace: namespace,
+		getSyncTarget: func(clusterName logicalcluster.Name, name string) (*tmcv1alpha1.SyncTarget, error) {
+			obj, err := syncTargetInformer.Lister(clusterName).Get(name)
+			if err != nil {
+				return nil, err
+			}
+			return toSyncTarget(obj)
+		},
+		getClusterProfile: func(ctx context.Context, clusterName logicalcluster.Name, namespace, name string) (*unstructured.Unstructured, error) {
+			return dynamicClusterClient.Cluster(clusterName.Path()).Resource(ClusterProfilesGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		},
+		createClusterProfile: func(ctx context.Context, clusterName logicalcluster.Name, profile *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+			return dynamicClusterClient.Cluster(clusterName.Path()).Resource(ClusterProfilesGVR).Namespace(profile.GetNamespace()).Create(ctx, profile, metav1.CreateOptions{})
+		},
+		updateClusterProfile: func(ctx context.Context, clusterName logicalcluster.Name, profile *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+			return dynamicClusterClient.Cluster(clusterName.Path()).Resource(ClusterProfilesGVR).Namespace(profile.GetNamespace()).Update(ctx, profile, metav1.UpdateOptions{})
+		},
+		updateClusterProfileStatus: func(ctx context.Context, clusterName logicalcluster.Name, profile *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+			return dynamicClusterClient.Cluster(clusterName.Path()).Resource(ClusterProfilesGVR).Namespace(profile.GetNamespace()).UpdateStatus(ctx, profile, metav1.UpdateOptions{})
+		},
+		deleteClusterProfile: func(ctx context.Context, clusterName logicalcluster.Name, namespace, name string) error {
+			return dynamicClusterClient.Cluster(clusterName.Path()).Resource(ClusterProfilesGVR).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+		},
+		createNamespace: func(ctx context.Context, clusterName logicalcluster.Name, name string) error {
+			ns := &unstructured.Unstructured{}
+			ns.SetAPIVersion("v1")
+			ns.SetKind("Namespace")
+			ns.SetName(name)
+			_, err := dynamicClusterClient.Cluster(clusterName.Path()).Resource(namespacesGVR).Create(ctx, ns, metav1.CreateOptions{})
+			if errors.IsAlreadyExists(err) {
+				return nil
+			}
+			return err
+		},
+	}
+
+	syncTargetInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueue(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+		DeleteFunc: func(obj interface{}) { c.enqueue(obj) },
+	})
+
+	return c, nil
+}
+
+// controller keeps one ClusterProfile per SyncTarget in sync.
+type controller struct {
+	queue workqueue.TypedRateLimitingInterface[string]
+
+	namespace string
+
+	getSyncTarget              func(clusterName logicalcluster.Name, name string) (*tmcv1alpha1.SyncTarget, error)
+	getClusterProfile          func(ctx context.Context, clusterName logicalcluster.Name, namespace, name string) (*unstructured.Unstructured, error)
+	createClusterProfile       func(ctx context.Context, clusterName logicalcluster.Name, profile *unstructured.Unstructured) (*unstructured.Unstructured, error)
+	updateClusterProfile       func(ctx context.Context, clusterName logicalcluster.Name, profile *unstructured.Unstructured) (*unstructured.Unstructured, error)
+	updateClusterProfileStatus func(ctx context.Context, clusterName logicalcluster.Name, profile *unstructured.Unstructured) (*unstructured.Unstructured, error)
+	deleteClusterProfile       func(ctx context.Context, clusterName logicalcluster.Name, namespace, name string) error
+	createNamespace            func(ctx context.Context, clusterName logicalcluster.Name, name string) error
+}
+
+func (c *controller) enqueue(obj interface{}) {
+	key, err := kcpcache.DeletionHandlingMetaClusterNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+
+	logger := logging.WithQueueKey(logging.WithReconciler(klog.Background(), ControllerName), key)
+	logger.V(4).Info("queueing SyncTarget")
+	c.queue.Add(key)
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	logger := logging.WithReconciler(klog.FromContext(ctx), ControllerName)
+	ctx = klog.NewContext(ctx, logger)
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	for range numThreads {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+
+	logger := logging.WithQueueKey(klog.FromContext(ctx), key)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(4).Info("processing key")
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		utilruntime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", ControllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) error {
+	clusterName, _, name, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return nil
+	}
+
+	syncTarget, err := c.getSyncTarget(clusterName, name)
+	if errors.IsNotFound(err) {
+		return c.reconcileDeleted(ctx, clusterName, name)
+	}
+	if err != nil {
+		return err
+	}
+	if !syncTarget.DeletionTimestamp.IsZero() {
+		return c.reconcileDeleted(ctx, clusterName, name)
+	}
+
+	return c.reconcile(ctx, clusterName, syncTarget)
+}
+
+func toSyncTarget(obj runtime.Object) (*tmcv1alpha1.SyncTarget, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("expected *unstructured.Unstructured, got %T", obj)
+	}
+	syncTarget := &tmcv1alpha1.SyncTarget{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, syncTarget); err != nil {
+		return nil, err
+	}
+	return syncTarget, nil
+}
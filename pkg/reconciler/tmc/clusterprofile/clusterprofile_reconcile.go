@@ -0,0 +1,243 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterprofile
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+const (
+	// ClusterManagerName identifies TMC as the manager of exported profiles.
+	ClusterManagerName = "kcp-tmc"
+
+	// LabelClusterManager is the ClusterInventory label naming the manager of a ClusterProfile.
+	LabelClusterManager = "x-k8s.io/cluster-manager"
+	// LabelSyncTarget records which SyncTarget a ClusterProfile was exported from.
+	LabelSyncTarget = "tmc.kcp.io/sync-target"
+
+	// ConditionControlPlaneHealthy is the ClusterInventory health condition.
+	ConditionControlPlaneHealthy = "ControlPlaneHealthy"
+	// ConditionJoined is the ClusterInventory membership condition.
+	ConditionJoined = "Joined"
+
+	// Property names published in status.properties.
+	PropertyLocation      = "tmc.kcp.io/location"
+	PropertyUnschedulable = "tmc.kcp.io/unschedulable"
+	PropertyCells         = "tmc.kcp.io/cells"
+	propertyCapacity      = "capacity.tmc.kcp.io/"
+	propertyAllocatable   = "allocatable.tmc.kcp.io/"
+)
+
+func (c *controller) reconcile(ctx context.Context, clusterName logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) error {
+	logger := klog.FromContext(ctx)
+
+	desired := BuildClusterProfile(syncTarget, c.namespace)
+
+	existing, err := c.getClusterProfile(ctx, clusterName, c.namespace, syncTarget.Name)
+	if errors.IsNotFound(err) {
+		logger.V(2).Info("creating ClusterProfile")
+		existing, err = c.createClusterProfile(ctx, clusterName, desired)
+		if errors.IsNotFound(err) {
+			if err := c.createNamespace(ctx, clusterName, c.namespace); err != nil {
+				return err
+			}
+			existing, err = c.createClusterProfile(ctx, clusterName, desired)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if existing.GetLabels()[LabelSyncTarget] != syncTarget.Name {
+		logger.Info("not touching ClusterProfile not exported by TMC", "namespace", existing.GetNamespace(), "name", existing.GetName())
+		return nil
+	}
+
+	spec, _, _ := unstructured.NestedMap(existing.Object, "spec")
+	if !equality.Semantic.DeepEqual(spec, desired.Object["spec"]) || !equality.Semantic.DeepEqual(existing.GetLabels(), desired.GetLabels()) {
+		updated := existing.DeepCopy()
+		updated.Object["spec"] = desired.Object["spec"]
+		updated.SetLabels(desired.GetLabels())
+		if existing, err = c.updateClusterProfile(ctx, clusterName, updated); err != nil {
+			return err
+		}
+	}
+
+	status, err := buildStatus(syncTarget, existing)
+	if err != nil {
+		return err
+	}
+	if !equality.Semantic.DeepEqual(existing.Object["status"], status) {
+		updated := existing.DeepCopy()
+		updated.Object["status"] = status
+		if _, err := c.updateClusterProfileStatus(ctx, clusterName, updated); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *controller) reconcileDeleted(ctx context.Context, clusterName logicalcluster.Name, name string) error {
+	existing, err := c.getClusterProfile(ctx, clusterName, c.namespace, name)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.GetLabels()[LabelSyncTarget] != name {
+		return nil
+	}
+	klog.FromContext(ctx).V(2).Info("deleting ClusterProfile of removed SyncTarget")
+	if err := c.deleteClusterProfile(ctx, clusterName, c.namespace, name); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// BuildClusterProfile returns the ClusterProfile, without status, that
+// represents the given SyncTarget.
+func BuildClusterProfile(syncTarget *tmcv1alpha1.SyncTarget, namespace string) *unstructured.Unstructured {
+	profile := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"displayName": syncTarget.Name,
+			"clusterManager": map[string]interface{}{
+				"name": ClusterManagerName,
+			},
+		},
+	}}
+	profile.SetAPIVersion(ClusterProfilesGVR.GroupVersion().String())
+	profile.SetKind("ClusterProfile")
+	profile.SetNamespace(namespace)
+	profile.SetName(syncTarget.Name)
+	profile.SetLabels(map[string]string{
+		LabelClusterManager: ClusterManagerName,
+		LabelSyncTarget:     syncTarget.Name,
+	})
+	return profile
+}
+
+// profileStatus mirrors the ClusterProfile status schema of
+// sigs.k8s.io/cluster-inventory-api.
+type profileStatus struct {
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	Version    *profileVersion    `json:"version,omitempty"`
+	Properties []profileProperty  `json:"properties,omitempty"`
+}
+
+type profileVersion struct {
+	Kubernetes string `json:"kubernetes,omitempty"`
+}
+
+type profileProperty struct {
+	Name             string      `json:"name"`
+	Value            string      `json:"value"`
+	LastObservedTime metav1.Time `json:"lastObservedTime,omitempty"`
+}
+
+// buildStatus computes the desired ClusterProfile status, keeping condition
+// transition and property observation times of unchanged entries.
+func buildStatus(syncTarget *tmcv1alpha1.SyncTarget, existing *unstructured.Unstructured) (map[string]interface{}, error) {
+	var current profileStatus
+	if raw, ok := existing.Object["status"].(map[string]interface{}); ok {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &current); err != nil {
+			return nil, fmt.Errorf("failed to decode ClusterProfile status: %w", err)
+		}
+	}
+
+	now := metav1.Now()
+	status := profileStatus{Conditions: current.Conditions}
+
+	healthy := metav1.Condition{
+		Type:               ConditionControlPlaneHealthy,
+		Status:             metav1.ConditionUnknown,
+		Reason:             "SyncerNotReporting",
+		Message:            "The syncer has not reported readiness yet",
+		ObservedGeneration: existing.GetGeneration(),
+	}
+	if c := conditions.Get(syncTarget, tmcv1alpha1.SyncerReady); c != nil {
+		healthy.Status = metav1.ConditionStatus(c.Status)
+		healthy.Reason = c.Reason
+		healthy.Message = c.Message
+		if healthy.Reason == "" {
+			healthy.Reason = string(tmcv1alpha1.SyncerReady)
+		}
+	}
+	meta.SetStatusCondition(&status.Conditions, healthy)
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               ConditionJoined,
+		Status:             metav1.ConditionTrue,
+		Reason:             "SyncTargetRegistered",
+		Message:            "The cluster is registered as a TMC SyncTarget",
+		ObservedGeneration: existing.GetGeneration(),
+	})
+
+	if v := syncTarget.Status.KubernetesVersion; v != "" {
+		status.Version = &profileVersion{Kubernetes: v}
+	}
+
+	observed := map[string]profileProperty{}
+	for _, p := range current.Properties {
+		observed[p.Name] = p
+	}
+	for name, value := range properties(syncTarget) {
+		p := profileProperty{Name: name, Value: value, LastObservedTime: now}
+		if old, ok := observed[name]; ok && old.Value == value {
+			p.LastObservedTime = old.LastObservedTime
+		}
+		status.Properties = append(status.Properties, p)
+	}
+	sort.Slice(status.Properties, func(i, j int) bool { return status.Properties[i].Name < status.Properties[j].Name })
+
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(&status)
+}
+
+func properties(syncTarget *tmcv1alpha1.SyncTarget) map[string]string {
+	props := map[string]string{
+		PropertyUnschedulable: fmt.Sprintf("%t", syncTarget.Spec.Unschedulable),
+		PropertyCells:         fmt.Sprintf("%d", len(syncTarget.Spec.Cells)),
+	}
+	if syncTarget.Spec.Location != "" {
+		props[PropertyLocation] = syncTarget.Spec.Location
+	}
+	if syncTarget.Status.Capacity != nil {
+		for name, q := range *syncTarget.Status.Capacity {
+			props[propertyCapacity+string(name)] = q.String()
+		}
+	}
+	if syncTarget.Status.Allocatable != nil {
+		for name, q := range *syncTarget.Status.Allocatable {
+			props[propertyAllocatable+string(name)] = q.String()
+		}
+	}
+	return props
+}
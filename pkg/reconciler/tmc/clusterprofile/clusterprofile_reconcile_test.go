@@ -0,0 +1,138 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterprofile
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func TestReconcile(t *testing.T) {
+	syncTarget := &tmcv1alpha1.SyncTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "us-east-1"},
+		Spec:       tmcv1alpha1.SyncTargetSpec{Location: "us-east"},
+		Status: tmcv1alpha1.SyncTargetStatus{
+			KubernetesVersion: "v1.33.1",
+			Capacity:          &corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("16")},
+			Conditions: conditionsv1alpha1.Conditions{
+				{Type: tmcv1alpha1.SyncerReady, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+
+	profiles := map[string]*unstructured.Unstructured{}
+	var createdNamespace string
+	c := &controller{
+		namespace: DefaultNamespace,
+		getClusterProfile: func(ctx context.Context, clusterName logicalcluster.Name, namespace, name string) (*unstructured.Unstructured, error) {
+			if p, ok := profiles[namespace+"/"+name]; ok {
+				return p.DeepCopy(), nil
+			}
+			return nil, apierrors.NewNotFound(ClusterProfilesGVR.GroupResource(), name)
+		},
+		createClusterProfile: func(ctx context.Context, clusterName logicalcluster.Name, profile *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+			if createdNamespace == "" {
+				return nil, apierrors.NewNotFound(namespacesGVR.GroupResource(), profile.GetNamespace())
+			}
+			profiles[profile.GetNamespace()+"/"+profile.GetName()] = profile.DeepCopy()
+			return profile, nil
+		},
+		updateClusterProfile: func(ctx context.Context, clusterName logicalcluster.Name, profile *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+			profiles[profile.GetNamespace()+"/"+profile.GetName()] = profile.DeepCopy()
+			return profile, nil
+		},
+		updateClusterProfileStatus: func(ctx context.Context, clusterName logicalcluster.Name, profile *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+			profiles[profile.GetNamespace()+"/"+profile.GetName()] = profile.DeepCopy()
+			return profile, nil
+		},
+		deleteClusterProfile: func(ctx context.Context, clusterName logicalcluster.Name, namespace, name string) error {
+			delete(profiles, namespace+"/"+name)
+			return nil
+		},
+		createNamespace: func(ctx context.Context, clusterName logicalcluster.Name, name string) error {
+			createdNamespace = name
+			return nil
+		},
+	}
+
+	clusterName := logicalcluster.Name("root:fleet")
+	require.NoError(t, c.reconcile(context.Background(), clusterName, syncTarget))
+	require.Equal(t, DefaultNamespace, createdNamespace)
+
+	profile := profiles[DefaultNamespace+"/us-east-1"]
+	require.NotNil(t, profile)
+	require.Equal(t, ClusterManagerName, profile.GetLabels()[LabelClusterManager])
+
+	version, _, _ := unstructured.NestedString(profile.Object, "status", "version", "kubernetes")
+	require.Equal(t, "v1.33.1", version)
+
+	conds, _, _ := unstructured.NestedSlice(profile.Object, "status", "conditions")
+	statuses := map[string]string{}
+	for _, cond := range conds {
+		m := cond.(map[string]interface{})
+		statuses[m["type"].(string)] = m["status"].(string)
+	}
+	require.Equal(t, map[string]string{ConditionControlPlaneHealthy: "True", ConditionJoined: "True"}, statuses)
+
+	props, _, _ := unstructured.NestedSlice(profile.Object, "status", "properties")
+	values := map[string]string{}
+	for _, p := range props {
+		m := p.(map[string]interface{})
+		values[m["name"].(string)] = m["value"].(string)
+	}
+	require.Equal(t, "us-east", values[PropertyLocation])
+	require.Equal(t, "16", values["capacity.tmc.kcp.io/cpu"])
+
+	require.NoError(t, c.reconcileDeleted(context.Background(), clusterName, "us-east-1"))
+	require.Empty(t, profiles)
+}
+
+func TestReconcileSkipsForeignProfiles(t *testing.T) {
+	foreign := BuildClusterProfile(&tmcv1alpha1.SyncTarget{ObjectMeta: metav1.ObjectMeta{Name: "edge"}}, DefaultNamespace)
+	foreign.SetLabels(map[string]string{LabelClusterManager: "ocm"})
+
+	c := &controller{
+		namespace: DefaultNamespace,
+		getClusterProfile: func(ctx context.Context, clusterName logicalcluster.Name, namespace, name string) (*unstructured.Unstructured, error) {
+			return foreign.DeepCopy(), nil
+		},
+		updateClusterProfile: func(ctx context.Context, clusterName logicalcluster.Name, profile *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+			t.Fatal("must not update a foreign ClusterProfile")
+			return nil, nil
+		},
+		deleteClusterProfile: func(ctx context.Context, clusterName logicalcluster.Name, namespace, name string) error {
+			t.Fatal("must not delete a foreign ClusterProfile")
+			return nil
+		},
+	}
+
+	require.NoError(t, c.reconcile(context.Background(), "root", &tmcv1alpha1.SyncTarget{ObjectMeta: metav1.ObjectMeta{Name: "edge"}}))
+	require.NoError(t, c.reconcileDeleted(context.Background(), "root", "edge"))
+}
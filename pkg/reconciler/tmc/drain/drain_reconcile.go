@@ -0,0 +1,156 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// maxWorkloadsInMessage bounds the workloads listed in the condition.
+const maxWorkloadsInMessage = 5
+
+// reconcile drains the SyncTarget, and returns when to reconcile it again
+// for the drain to time out.
+func (c *controller) reconcile(ctx context.Context, clusterName logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) (time.Duration, error) {
+	logger := klog.FromContext(ctx)
+
+	if syncTarget.Spec.Drain == nil {
+		if syncTarget.Status.Drain == nil && !conditions.Has(syncTarget, tmcv1alpha1.Drained) {
+			return 0, nil
+		}
+		st := syncTarget.DeepCopy()
+		st.Status.Drain = nil
+		conditions.Delete(st, tmcv1alpha1.Drained)
+		logger.V(2).Info("SyncTarget drain stopped")
+		return 0, c.updateSyncTargetStatus(ctx, clusterName, st)
+	}
+
+	if !syncTarget.Spec.Unschedulable {
+		// Cordon first, so that the evicted workloads are not placed back.
+		st := syncTarget.DeepCopy()
+		st.Spec.Unschedulable = true
+		logger.V(2).Info("cordoning SyncTarget to drain it")
+		return 0, c.updateSyncTarget(ctx, clusterName, st)
+	}
+
+	distributions, err := c.listDistributions(clusterName)
+	if err != nil {
+		return 0, err
+	}
+	byKey := make(map[tmcv1alpha1.DrainedWorkload]*workloadv1alpha1.WorkloadDistribution, len(distributions))
+	var placed []tmcv1alpha1.DrainedWorkload
+	for _, d := range distributions {
+		w := tmcv1alpha1.DrainedWorkload{Namespace: d.Namespace, Name: d.Name}
+		byKey[w] = d
+		if placedOn(d, syncTarget.Name) {
+			placed = append(placed, w)
+		}
+	}
+	slices.SortFunc(placed, func(a, b tmcv1alpha1.DrainedWorkload) int {
+		return strings.Compare(a.Namespace+"/"+a.Name, b.Namespace+"/"+b.Name)
+	})
+
+	now := c.now()
+	status := &tmcv1alpha1.SyncTargetDrainStatus{StartTime: metav1.NewTime(now)}
+	if syncTarget.Status.Drain != nil {
+		status = syncTarget.Status.Drain.DeepCopy()
+	}
+
+	// Workloads stop moving once they are off the target and ready on their
+	// other targets, or deleted.
+	evicting := status.Evicting[:0:0]
+	for _, w := range status.Evicting {
+		d, ok := byKey[w]
+		if ok && (placedOn(d, syncTarget.Name) || !conditions.IsTrue(d, workloadv1alpha1.WorkloadReady)) {
+			evicting = append(evicting, w)
+			continue
+		}
+		status.Moved++
+	}
+
+	var requeueAfter time.Duration
+	timedOut := false
+	if timeout := syncTarget.Spec.Drain.Timeout; timeout != nil {
+		deadline := status.StartTime.Add(timeout.Duration)
+		timedOut = !now.Before(deadline)
+		if !timedOut {
+			requeueAfter = deadline.Sub(now)
+		}
+	}
+
+	maxUnavailable := 1
+	if syncTarget.Spec.Drain.MaxUnavailable != nil {
+		maxUnavailable = int(*syncTarget.Spec.Drain.MaxUnavailable)
+	}
+	for _, w := range placed {
+		if len(evicting) >= maxUnavailable && !timedOut {
+			break
+		}
+		if !slices.Contains(evicting, w) {
+			evicting = append(evicting, w)
+		}
+	}
+	status.Evicting = evicting
+	status.Remaining = int32(len(placed))
+
+	st := syncTarget.DeepCopy()
+	st.Status.Drain = status
+	switch {
+	case len(placed) == 0 && len(evicting) == 0:
+		conditions.MarkTrue(st, tmcv1alpha1.Drained)
+	default:
+		reason := tmcv1alpha1.DrainingReason
+		if timedOut {
+			reason = tmcv1alpha1.DrainTimedOutReason
+		}
+		moving := make([]string, 0, len(evicting))
+		for _, w := range evicting {
+			moving = append(moving, w.Namespace+"/"+w.Name)
+		}
+		if len(moving) > maxWorkloadsInMessage {
+			moving = append(moving[:maxWorkloadsInMessage:maxWorkloadsInMessage], fmt.Sprintf("and %d more", len(evicting)-maxWorkloadsInMessage))
+		}
+		conditions.MarkFalse(st, tmcv1alpha1.Drained, reason, conditionsv1alpha1.ConditionSeverityInfo,
+			"%d workloads are still placed on the SyncTarget, moving: %s", len(placed), strings.Join(moving, ", "))
+	}
+
+	if equality.Semantic.DeepEqual(syncTarget.Status, st.Status) {
+		return requeueAfter, nil
+	}
+	logger.V(2).Info("SyncTarget draining", "remaining", status.Remaining, "evicting", len(status.Evicting), "moved", status.Moved)
+	return requeueAfter, c.updateSyncTargetStatus(ctx, clusterName, st)
+}
+
+// placedOn returns whether the workload of d is placed on the SyncTarget.
+func placedOn(d *workloadv1alpha1.WorkloadDistribution, syncTarget string) bool {
+	return slices.ContainsFunc(d.Status.Targets, func(t workloadv1alpha1.TargetPlacement) bool { return t.SyncTarget == syncTarget })
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var start = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type fixture struct {
+	now           time.Time
+	syncTarget    *tmcv1alpha1.SyncTarget
+	distributions []*workloadv1alpha1.WorkloadDistribution
+}
+
+func (f *fixture) controller() *controller {
+	return &controller{
+		now: func() time.Time { return f.now },
+		listDistributions: func(logicalcluster.Name) ([]*workloadv1alpha1.WorkloadDistribution, error) {
+			return f.distributions, nil
+		},
+		updateSyncTarget: func(_ context.Context, _ logicalcluster.Name, st *tmcv1alpha1.SyncTarget) error {
+			f.syncTarget = st
+			return nil
+		},
+		updateSyncTargetStatus: func(_ context.Context, _ logicalcluster.Name, st *tmcv1alpha1.SyncTarget) error {
+			f.syncTarget = st
+			return nil
+		},
+	}
+}
+
+func newFixture(names ...string) *fixture {
+	f := &fixture{
+		now: start,
+		syncTarget: &tmcv1alpha1.SyncTarget{
+			ObjectMeta: metav1.ObjectMeta{Name: "edge-1"},
+			Spec:       tmcv1alpha1.SyncTargetSpec{Drain: &tmcv1alpha1.SyncTargetDrain{}},
+		},
+	}
+	for _, name := range names {
+		f.distributions = append(f.distributions, &workloadv1alpha1.WorkloadDistribution{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Status:     workloadv1alpha1.WorkloadDistributionStatus{Targets: []workloadv1alpha1.TargetPlacement{{SyncTarget: "edge-1"}}},
+		})
+	}
+	return f
+}
+
+// move places d on edge-2 and marks it ready, like placement and the syncer
+// do for evicted workloads.
+func move(d *workloadv1alpha1.WorkloadDistribution) {
+	d.Status.Targets = []workloadv1alpha1.TargetPlacement{{SyncTarget: "edge-2"}}
+	conditions.Set(d, &conditionsv1alpha1.Condition{Type: workloadv1alpha1.WorkloadReady, Status: corev1.ConditionTrue})
+}
+
+func TestReconcile(t *testing.T) {
+	f := newFixture("a", "b")
+	c := f.controller()
+	reconcile := func() time.Duration {
+		t.Helper()
+		requeueAfter, err := c.reconcile(context.Background(), "root:org", f.syncTarget)
+		require.NoError(t, err)
+		return requeueAfter
+	}
+
+	reconcile()
+	require.True(t, f.syncTarget.Spec.Unschedulable, "the SyncTarget is cordoned first")
+	require.Nil(t, f.syncTarget.Status.Drain)
+
+	reconcile()
+	require.Equal(t, []tmcv1alpha1.DrainedWorkload{{Namespace: "default", Name: "a"}}, f.syncTarget.Status.Drain.Evicting)
+	require.Equal(t, int32(2), f.syncTarget.Status.Drain.Remaining)
+	require.Equal(t, tmcv1alpha1.DrainingReason, conditions.GetReason(f.syncTarget, tmcv1alpha1.Drained))
+	require.Equal(t, "2 workloads are still placed on the SyncTarget, moving: default/a", conditions.GetMessage(f.syncTarget, tmcv1alpha1.Drained))
+
+	f.distributions[0].Status.Targets = []workloadv1alpha1.TargetPlacement{{SyncTarget: "edge-2"}}
+	reconcile()
+	require.Equal(t, []tmcv1alpha1.DrainedWorkload{{Namespace: "default", Name: "a"}}, f.syncTarget.Status.Drain.Evicting, "a is not ready elsewhere yet")
+	require.Equal(t, int32(1), f.syncTarget.Status.Drain.Remaining)
+
+	move(f.distributions[0])
+	reconcile()
+	require.Equal(t, []tmcv1alpha1.DrainedWorkload{{Namespace: "default", Name: "b"}}, f.syncTarget.Status.Drain.Evicting)
+	require.Equal(t, int32(1), f.syncTarget.Status.Drain.Moved)
+
+	f.distributions = f.distributions[:1]
+	reconcile()
+	require.Empty(t, f.syncTarget.Status.Drain.Evicting)
+	require.Equal(t, int32(2), f.syncTarget.Status.Drain.Moved, "deleted workloads count as moved")
+	require.True(t, conditions.IsTrue(f.syncTarget, tmcv1alpha1.Drained))
+
+	f.syncTarget.Spec.Drain = nil
+	reconcile()
+	require.Nil(t, f.syncTarget.Status.Drain)
+	require.False(t, conditions.Has(f.syncTarget, tmcv1alpha1.Drained))
+	require.True(t, f.syncTarget.Spec.Unschedulable, "the SyncTarget stays cordoned")
+}
+
+func TestReconcileTimeout(t *testing.T) {
+	f := newFixture("a", "b", "c")
+	f.syncTarget.Spec.Unschedulable = true
+	f.syncTarget.Spec.Drain.Timeout = &metav1.Duration{Duration: time.Hour}
+	c := f.controller()
+
+	requeueAfter, err := c.reconcile(context.Background(), "root:org", f.syncTarget)
+	require.NoError(t, err)
+	require.Equal(t, time.Hour, requeueAfter)
+	require.Len(t, f.syncTarget.Status.Drain.Evicting, 1)
+
+	f.now = start.Add(time.Hour)
+	requeueAfter, err = c.reconcile(context.Background(), "root:org", f.syncTarget)
+	require.NoError(t, err)
+	require.Zero(t, requeueAfter)
+	require.Len(t, f.syncTarget.Status.Drain.Evicting, 3, "the remaining workloads are evicted at once")
+	require.Equal(t, tmcv1alpha1.DrainTimedOutReason, conditions.GetReason(f.syncTarget, tmcv1alpha1.Drained))
+}
@@ -0,0 +1,252 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eviction
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	kcpcache "github.com/kcp-dev/apimachinery/v2/pkg/cache"
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/clusterprofile"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/tmcinformers"
+	"github.com/kcp-dev/kcp/pkg/tmc/workspacequeue"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+const (
+	ControllerName = "kcp-tmc-eviction"
+)
+
+var EvictionPoliciesGVR = tmcv1alpha1.SchemeGroupVersion.WithResource("evictionpolicies")
+
+// NewController returns a controller that evicts the workloads of SyncTargets
+// that stay tainted, over-utilized or unhealthy for longer than allowed by
+// an EvictionPolicy, by setting their spec.evictAfter. The placement
+// controller then moves the workloads to other SyncTargets.
+func NewController(
+	policyClusterInformer *tmcinformers.Informer,
+	syncTargetClusterInformer *tmcinformers.Informer,
+	dynamicClusterClient kcpdynamic.ClusterInterface,
+) (*controller, error) {
+	c := &controller{
+		queue: workspacequeue.NewRateLimitingQueue(ControllerName),
+		now:   time.Now,
+		getPolicy: func(clusterName logicalcluster.Name, name string) (*tmcv1alpha1.EvictionPolicy, error) {
+			obj, err := policyClusterInformer.Lister(clusterName).Get(name)
+			if err != nil {
+				return nil, err
+			}
+			policy := &tmcv1alpha1.EvictionPolicy{}
+			return policy, fromUnstructured(obj, policy)
+		},
+		listSyncTargets: func(clusterName logicalcluster.Name) ([]*tmcv1alpha1.SyncTarget, error) {
+			objs, err := syncTargetClusterInformer.Lister(clusterName).List(labels.Everything())
+			if err != nil {
+				return nil, err
+			}
+			syncTargets := make([]*tmcv1alpha1.SyncTarget, 0, len(objs))
+			for _, obj := range objs {
+				syncTarget := &tmcv1alpha1.SyncTarget{}
+				if err := fromUnstructured(obj, syncTarget); err != nil {
+					return nil, err
+				}
+				syncTargets = append(syncTargets, syncTarget)
+			}
+			return syncTargets, nil
+		},
+		updateSyncTarget: func(ctx context.Context, clusterName logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) error {
+			u, err := toUnstructured(syncTarget)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(clusterprofile.SyncTargetsGVR).Update(ctx, u, metav1.UpdateOptions{})
+			return err
+		},
+		updatePolicyStatus: func(ctx context.Context, clusterName logicalcluster.Name, policy *tmcv1alpha1.EvictionPolicy) error {
+			u, err := toUnstructured(policy)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(EvictionPoliciesGVR).UpdateStatus(ctx, u, metav1.UpdateOptions{})
+			return err
+		},
+	}
+
+	policyClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueue(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+		DeleteFunc: func(obj interface{}) { c.enqueue(obj) },
+	})
+	syncTargetClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueuePoliciesInCluster(policyClusterInformer, obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueuePoliciesInCluster(policyClusterInformer, obj) },
+		DeleteFunc: func(obj interface{}) { c.enqueuePoliciesInCluster(policyClusterInformer, obj) },
+	})
+
+	return c, nil
+}
+
+// controller maintains the eviction of SyncTargets selected by
+// EvictionPolicies.
+type controller struct {
+	queue workqueue.TypedRateLimitingInterface[string]
+
+	now func() time.Time
+
+	getPolicy          func(clusterName logicalcluster.Name, name string) (*tmcv1alpha1.EvictionPolicy, error)
+	listSyncTargets    func(clusterName logicalcluster.Name) ([]*tmcv1alpha1.SyncTarget, error)
+	updateSyncTarget   func(ctx context.Context, clusterName logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) error
+	updatePolicyStatus func(ctx context.Context, clusterName logicalcluster.Name, policy *tmcv1alpha1.EvictionPolicy) error
+}
+
+func (c *controller) enqueue(obj interface{}) {
+	key, err := kcpcache.DeletionHandlingMetaClusterNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+
+	logger := logging.WithQueueKey(logging.WithReconciler(klog.Background(), ControllerName), key)
+	logger.V(4).Info("queueing EvictionPolicy")
+	c.queue.Add(key)
+}
+
+// enqueuePoliciesInCluster enqueues the policies in the logical cluster of
+// the SyncTarget. Policies are few, so they are not indexed by selector.
+func (c *controller) enqueuePoliciesInCluster(policyClusterInformer *tmcinformers.Informer, obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		utilruntime.HandleError(fmt.Errorf("expected *unstructured.Unstructured, got %T", obj))
+		return
+	}
+
+	policies, err := policyClusterInformer.Lister(logicalcluster.From(u)).List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, policy := range policies {
+		c.enqueue(policy)
+	}
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	logger := logging.WithReconciler(klog.FromContext(ctx), ControllerName)
+	ctx = klog.NewContext(ctx, logger)
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	for range numThreads {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+
+	logger := logging.WithQueueKey(klog.FromContext(ctx), key)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(4).Info("processing key")
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	requeueAfter, err := c.process(ctx, key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", ControllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	if requeueAfter > 0 {
+		c.queue.AddAfter(key, requeueAfter)
+	}
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) (time.Duration, error) {
+	clusterName, _, name, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return 0, nil
+	}
+
+	policy, err := c.getPolicy(clusterName, name)
+	if errors.IsNotFound(err) {
+		// SyncTargets evicted by a deleted policy are no longer evicted.
+		return 0, c.releaseAll(ctx, clusterName, name)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !policy.DeletionTimestamp.IsZero() {
+		return 0, nil
+	}
+
+	return c.reconcile(ctx, clusterName, policy)
+}
+
+func fromUnstructured(obj runtime.Object, into interface{}) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("expected *unstructured.Unstructured, got %T", obj)
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, into)
+}
+
+func toUnstructured(obj interface{}) (*unstructured.Unstructured, error) {
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: raw}, nil
+}
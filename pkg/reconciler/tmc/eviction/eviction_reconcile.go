@@ -0,0 +1,207 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eviction
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func (c *controller) reconcile(ctx context.Context, clusterName logicalcluster.Name, policy *tmcv1alpha1.EvictionPolicy) (time.Duration, error) {
+	logger := klog.FromContext(ctx)
+
+	selector := labels.Everything()
+	if policy.Spec.SyncTargetSelector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(policy.Spec.SyncTargetSelector); err != nil {
+			// An invalid selector does not get better by retrying.
+			logger.Error(err, "invalid SyncTarget selector")
+			return 0, nil
+		}
+	}
+
+	syncTargets, err := c.listSyncTargets(clusterName)
+	if err != nil {
+		return 0, err
+	}
+	sort.Slice(syncTargets, func(i, j int) bool { return syncTargets[i].Name < syncTargets[j].Name })
+
+	since := make(map[string]metav1.Time, len(policy.Status.SyncTargets))
+	for _, e := range policy.Status.SyncTargets {
+		since[e.Name] = e.Since
+	}
+
+	now := c.now()
+	var requeueAfter time.Duration
+	var evictions []tmcv1alpha1.SyncTargetEviction
+	for _, syncTarget := range syncTargets {
+		reason, tolerable := "", false
+		if selector.Matches(labels.Set(syncTarget.Labels)) && syncTarget.DeletionTimestamp.IsZero() {
+			reason, tolerable = evictionReason(syncTarget, &policy.Spec)
+		}
+		if reason == "" {
+			if err := c.release(ctx, clusterName, policy.Name, syncTarget); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		e := tmcv1alpha1.SyncTargetEviction{Name: syncTarget.Name, Reason: reason, Since: metav1.NewTime(now.Truncate(time.Second))}
+		if s, found := since[syncTarget.Name]; found {
+			e.Since = s
+		}
+		deadline := e.Since.Add(policy.Spec.EvictAfter.Duration)
+		if now.Before(deadline) {
+			if wait := deadline.Sub(now); requeueAfter == 0 || wait < requeueAfter {
+				requeueAfter = wait
+			}
+		} else {
+			e.Evicted = true
+			if err := c.evict(ctx, clusterName, policy.Name, syncTarget, deadline, tolerable); err != nil {
+				return 0, err
+			}
+		}
+		evictions = append(evictions, e)
+	}
+
+	if equality.Semantic.DeepEqual(policy.Status.SyncTargets, evictions) {
+		return requeueAfter, nil
+	}
+	p := policy.DeepCopy()
+	p.Status.SyncTargets = evictions
+	logger.V(2).Info("updating EvictionPolicy status", "syncTargets", len(evictions))
+	return requeueAfter, c.updatePolicyStatus(ctx, clusterName, p)
+}
+
+// evictionReason returns why the workloads of the SyncTarget are to be
+// evicted, or "" if they are not, and whether NoExecute taints are the only
+// reason, so that workloads tolerating them may stay.
+func evictionReason(syncTarget *tmcv1alpha1.SyncTarget, spec *tmcv1alpha1.EvictionPolicySpec) (string, bool) {
+	reason := unhealthyReason(syncTarget, spec)
+	for _, cell := range syncTarget.Spec.Cells {
+		for _, taint := range cell.Taints {
+			if taint.Effect == corev1.TaintEffectNoExecute {
+				return fmt.Sprintf("cell %q is tainted with %s", cell.Name, taint.ToString()), reason == ""
+			}
+		}
+	}
+	return reason, false
+}
+
+// unhealthyReason returns why the SyncTarget is unhealthy or over-utilized,
+// or "".
+func unhealthyReason(syncTarget *tmcv1alpha1.SyncTarget, spec *tmcv1alpha1.EvictionPolicySpec) string {
+	if conditions.IsFalse(syncTarget, tmcv1alpha1.SyncerReady) {
+		return "syncer is not ready"
+	}
+	if conditions.IsFalse(syncTarget, tmcv1alpha1.HeartbeatHealthy) {
+		return "syncer heartbeat is missing"
+	}
+	if spec.MaxUtilizationPercent != nil {
+		return overUtilized(syncTarget, *spec.MaxUtilizationPercent)
+	}
+	return ""
+}
+
+// overUtilized returns which resource of the SyncTarget is utilized above
+// maxPercent of its capacity, or "".
+func overUtilized(syncTarget *tmcv1alpha1.SyncTarget, maxPercent int32) string {
+	allocatable, capacity := syncTarget.Status.Allocatable, syncTarget.Status.Capacity
+	if allocatable == nil || capacity == nil {
+		return ""
+	}
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		a, aFound := (*allocatable)[name]
+		c, cFound := (*capacity)[name]
+		if !aFound || !cFound || c.IsZero() {
+			continue
+		}
+		utilization := 100 * (1 - a.AsApproximateFloat64()/c.AsApproximateFloat64())
+		if utilization > float64(maxPercent) {
+			return fmt.Sprintf("%s utilization is %.0f%%, above %d%%", name, utilization, maxPercent)
+		}
+	}
+	return ""
+}
+
+// evict sets spec.evictAfter of the SyncTarget, unless it is set already,
+// e.g. by hand or by another policy. Evictions of the policy are marked
+// tolerable while only NoExecute taints are the reason.
+func (c *controller) evict(ctx context.Context, clusterName logicalcluster.Name, policyName string, syncTarget *tmcv1alpha1.SyncTarget, at time.Time, tolerable bool) error {
+	if syncTarget.Spec.EvictAfter != nil {
+		evictedForTaints := syncTarget.Annotations[tmcv1alpha1.AnnotationEvictedForTaints] == "true"
+		if syncTarget.Annotations[tmcv1alpha1.AnnotationEvictedBy] != policyName || evictedForTaints == tolerable {
+			return nil
+		}
+	}
+	klog.FromContext(ctx).V(2).Info("evicting workloads of SyncTarget", "syncTarget", syncTarget.Name, "tolerable", tolerable)
+	st := syncTarget.DeepCopy()
+	if st.Spec.EvictAfter == nil {
+		st.Spec.EvictAfter = &metav1.Time{Time: at}
+	}
+	if st.Annotations == nil {
+		st.Annotations = map[string]string{}
+	}
+	st.Annotations[tmcv1alpha1.AnnotationEvictedBy] = policyName
+	if tolerable {
+		st.Annotations[tmcv1alpha1.AnnotationEvictedForTaints] = "true"
+	} else {
+		delete(st.Annotations, tmcv1alpha1.AnnotationEvictedForTaints)
+	}
+	return c.updateSyncTarget(ctx, clusterName, st)
+}
+
+// release clears spec.evictAfter of the SyncTarget if it was set by the
+// policy, so that workloads are placed on it again.
+func (c *controller) release(ctx context.Context, clusterName logicalcluster.Name, policyName string, syncTarget *tmcv1alpha1.SyncTarget) error {
+	if syncTarget.Annotations[tmcv1alpha1.AnnotationEvictedBy] != policyName {
+		return nil
+	}
+	klog.FromContext(ctx).V(2).Info("releasing SyncTarget from eviction", "syncTarget", syncTarget.Name)
+	st := syncTarget.DeepCopy()
+	st.Spec.EvictAfter = nil
+	delete(st.Annotations, tmcv1alpha1.AnnotationEvictedBy)
+	delete(st.Annotations, tmcv1alpha1.AnnotationEvictedForTaints)
+	return c.updateSyncTarget(ctx, clusterName, st)
+}
+
+// releaseAll releases the SyncTargets evicted by the policy.
+func (c *controller) releaseAll(ctx context.Context, clusterName logicalcluster.Name, policyName string) error {
+	syncTargets, err := c.listSyncTargets(clusterName)
+	if err != nil {
+		return err
+	}
+	for _, syncTarget := range syncTargets {
+		if err := c.release(ctx, clusterName, policyName, syncTarget); err != nil {
+			return err
+		}
+	}
+	return nil
+}
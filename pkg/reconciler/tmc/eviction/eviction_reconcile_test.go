@@ -0,0 +1,136 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eviction
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func TestReconcile(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	syncTargets := map[string]*tmcv1alpha1.SyncTarget{}
+	for _, name := range []string{"east", "west", "edge"} {
+		st := &tmcv1alpha1.SyncTarget{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"evict": "true"}}}
+		conditions.MarkTrue(st, tmcv1alpha1.SyncerReady)
+		syncTargets[name] = st
+	}
+	syncTargets["edge"].Labels = nil
+	conditions.MarkFalse(syncTargets["edge"], tmcv1alpha1.SyncerReady, "Down", conditionsv1alpha1.ConditionSeverityError, "")
+
+	policy := &tmcv1alpha1.EvictionPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: tmcv1alpha1.EvictionPolicySpec{
+			SyncTargetSelector:    &metav1.LabelSelector{MatchLabels: map[string]string{"evict": "true"}},
+			EvictAfter:            metav1.Duration{Duration: 5 * time.Minute},
+			MaxUtilizationPercent: ptr.To[int32](90),
+		},
+	}
+
+	statusUpdates := 0
+	c := &controller{
+		now: func() time.Time { return now },
+		listSyncTargets: func(_ logicalcluster.Name) ([]*tmcv1alpha1.SyncTarget, error) {
+			var list []*tmcv1alpha1.SyncTarget
+			for _, st := range syncTargets {
+				list = append(list, st)
+			}
+			return list, nil
+		},
+		updateSyncTarget: func(_ context.Context, _ logicalcluster.Name, st *tmcv1alpha1.SyncTarget) error {
+			syncTargets[st.Name] = st
+			return nil
+		},
+		updatePolicyStatus: func(_ context.Context, _ logicalcluster.Name, p *tmcv1alpha1.EvictionPolicy) error {
+			statusUpdates++
+			policy = p
+			return nil
+		},
+	}
+	reconcile := func() time.Duration {
+		t.Helper()
+		requeueAfter, err := c.reconcile(context.Background(), "root:org", policy)
+		require.NoError(t, err)
+		return requeueAfter
+	}
+
+	require.Zero(t, reconcile(), "healthy targets are not tracked")
+	require.Zero(t, statusUpdates, "unselected targets are ignored")
+
+	syncTargets["east"].Spec.Cells = []tmcv1alpha1.Cell{{Name: "a", Taints: []corev1.Taint{
+		{Key: "maintenance", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "node.kubernetes.io/unreachable", Effect: corev1.TaintEffectNoExecute},
+	}}}
+	syncTargets["west"].Status.Capacity = &corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10")}
+	syncTargets["west"].Status.Allocatable = &corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")}
+	require.Equal(t, 5*time.Minute, reconcile())
+	require.Equal(t, 1, statusUpdates)
+	require.Equal(t, []tmcv1alpha1.SyncTargetEviction{
+		{Name: "east", Reason: `cell "a" is tainted with node.kubernetes.io/unreachable:NoExecute`, Since: metav1.NewTime(now)},
+		{Name: "west", Reason: "cpu utilization is 95%, above 90%", Since: metav1.NewTime(now)},
+	}, policy.Status.SyncTargets)
+	require.Nil(t, syncTargets["east"].Spec.EvictAfter, "targets are not evicted before evictAfter")
+
+	now = now.Add(2 * time.Minute)
+	syncTargets["west"].Status.Allocatable = &corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("5")}
+	require.Equal(t, 3*time.Minute, reconcile(), "the wait continues from when the target was first seen")
+	require.Len(t, policy.Status.SyncTargets, 1)
+
+	now = now.Add(3 * time.Minute)
+	manual := metav1.NewTime(now.Add(time.Hour))
+	syncTargets["west"].Spec.EvictAfter = &manual
+	require.Zero(t, reconcile())
+	require.True(t, policy.Status.SyncTargets[0].Evicted)
+	require.Equal(t, now, syncTargets["east"].Spec.EvictAfter.Time)
+	require.Equal(t, "default", syncTargets["east"].Annotations[tmcv1alpha1.AnnotationEvictedBy])
+	require.Equal(t, "true", syncTargets["east"].Annotations[tmcv1alpha1.AnnotationEvictedForTaints], "workloads tolerating the taints may stay")
+
+	conditions.MarkFalse(syncTargets["east"], tmcv1alpha1.SyncerReady, "Down", conditionsv1alpha1.ConditionSeverityError, "")
+	reconcile()
+	require.Equal(t, now, syncTargets["east"].Spec.EvictAfter.Time)
+	require.NotContains(t, syncTargets["east"].Annotations, tmcv1alpha1.AnnotationEvictedForTaints, "unhealthy targets evict all workloads")
+	conditions.MarkTrue(syncTargets["east"], tmcv1alpha1.SyncerReady)
+
+	syncTargets["east"].Spec.Cells = nil
+	reconcile()
+	require.Empty(t, policy.Status.SyncTargets)
+	require.Nil(t, syncTargets["east"].Spec.EvictAfter, "recovered targets are released")
+	require.NotContains(t, syncTargets["east"].Annotations, tmcv1alpha1.AnnotationEvictedBy)
+	require.NotContains(t, syncTargets["east"].Annotations, tmcv1alpha1.AnnotationEvictedForTaints)
+	require.Equal(t, &manual, syncTargets["west"].Spec.EvictAfter, "evictions set by hand are kept")
+
+	syncTargets["east"].Spec.Cells = []tmcv1alpha1.Cell{{Name: "a", Taints: []corev1.Taint{{Key: "gone", Effect: corev1.TaintEffectNoExecute}}}}
+	policy.Status.SyncTargets = []tmcv1alpha1.SyncTargetEviction{{Name: "east", Since: metav1.NewTime(now.Add(-time.Hour))}}
+	reconcile()
+	require.NotNil(t, syncTargets["east"].Spec.EvictAfter)
+	require.NoError(t, c.releaseAll(context.Background(), "root:org", "default"))
+	require.Nil(t, syncTargets["east"].Spec.EvictAfter, "targets evicted by a deleted policy are released")
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package featurestatus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	kcpcache "github.com/kcp-dev/apimachinery/v2/pkg/cache"
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/featurez"
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/tmcinformers"
+	"github.com/kcp-dev/kcp/pkg/tmc/workspacequeue"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+const (
+	ControllerName = "kcp-tmc-featurestatus"
+)
+
+var (
+	// TMCFeatureStatusesGVR is the resource the controller maintains.
+	TMCFeatureStatusesGVR = tmcv1alpha1.SchemeGroupVersion.WithResource("tmcfeaturestatuses")
+)
+
+// NewController returns a controller that maintains the TMCFeatureStatus of
+// every workspace with SyncTargets or WorkloadDistributions. The
+// distribution informer is nil if the TMCPlacement feature gate is disabled.
+func NewController(
+	featureStatusClusterInformer *tmcinformers.Informer,
+	syncTargetClusterInformer *tmcinformers.Informer,
+	distributionClusterInformer *tmcinformers.Informer,
+	dynamicClusterClient kcpdynamic.ClusterInterface,
+) (*controller, error) {
+	c := &controller{
+		queue:        workspacequeue.NewRateLimitingQueue(ControllerName),
+		now:          time.Now,
+		featureGates: featurez.Process,
+		getFeatureStatus: func(clusterName logicalcluster.Name) (*tmcv1alpha1.TMCFeatureStatus, error) {
+			obj, err := featureStatusClusterInformer.Lister(clusterName).Get(tmcv1alpha1.TMCFeatureStatusName)
+			if err != nil {
+				return nil, err
+			}
+			status := &tmcv1alpha1.TMCFeatureStatus{}
+			return status, fromUnstructured(obj, status)
+		},
+		listSyncTargets: func(clusterName logicalcluster.Name) ([]*tmcv1alpha1.SyncTarget, error) {
+			objs, err := syncTargetClusterInformer.Lister(clusterName).List(labels.Everything())
+			if err != nil {
+				return nil, err
+			}
+			syncTargets := make([]*tmcv1alpha1.SyncTarget, 0, len(objs))
+			for _, obj := range objs {
+				syncTarget := &tmcv1alpha1.SyncTarget{}
+				if err := fromUnstructured(obj, syncTarget); err != nil {
+					return nil, err
+				}
+				syncTargets = append(syncTargets, syncTarget)
+			}
+			return syncTargets, nil
+		},
+		countDistributions: func(clusterName logicalcluster.Name) (int, error) {
+			if distributionClusterInformer == nil {
+				return 0, nil
+			}
+			objs, err := distributionClusterInformer.Lister(clusterName).List(labels.Everything())
+			return len(objs), err
+		},
+		createFeatureStatus: func(ctx context.Context, clusterName logicalcluster.Name, status *tmcv1alpha1.TMCFeatureStatus) (*tmcv1alpha1.TMCFeatureStatus, error) {
+			u, err := toUnstructured(status)
+			if err != nil {
+				return nil, err
+			}
+			u.SetAPIVersion(tmcv1alpha1.SchemeGroupVersion.String())
+			u.SetKind("TMCFeatureStatus")
+			created, err := dynamicClusterClient.Cluster(clusterName.Path()).Resource(TMCFeatureStatusesGVR).Create(ctx, u, metav1.CreateOptions{})
+			if err != nil {
+				return nil, err
+			}
+			status = &tmcv1alpha1.TMCFeatureStatus{}
+			return status, fromUnstructured(created, status)
+		},
+		updateFeatureStatus: func(ctx context.Context, clusterName logicalcluster.Name, status *tmcv1alpha1.TMCFeatureStatus) error {
+			u, err := toUnstructured(status)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(TMCFeatureStatusesGVR).UpdateStatus(ctx, u, metav1.UpdateOptions{})
+			return err
+		},
+	}
+
+	featureStatusClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+		DeleteFunc: func(obj interface{}) { c.enqueue(obj) },
+	})
+	syncTargetClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueue(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+		DeleteFunc: func(obj interface{}) { c.enqueue(obj) },
+	})
+	if distributionClusterInformer != nil {
+		distributionClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { c.enqueue(obj) },
+			DeleteFunc: func(obj interface{}) { c.enqueue(obj) },
+		})
+	}
+
+	return c, nil
+}
+
+// controller maintains the TMCFeatureStatus of workspaces.
+type controller struct {
+	queue workqueue.TypedRateLimitingInterface[string]
+
+	now          func() time.Time
+	featureGates func() []tmcv1alpha1.FeatureGate
+
+	getFeatureStatus    func(clusterName logicalcluster.Name) (*tmcv1alpha1.TMCFeatureStatus, error)
+	listSyncTargets     func(clusterName logicalcluster.Name) ([]*tmcv1alpha1.SyncTarget, error)
+	countDistributions  func(clusterName logicalcluster.Name) (int, error)
+	createFeatureStatus func(ctx context.Context, clusterName logicalcluster.Name, status *tmcv1alpha1.TMCFeatureStatus) (*tmcv1alpha1.TMCFeatureStatus, error)
+	updateFeatureStatus func(ctx context.Context, clusterName logicalcluster.Name, status *tmcv1alpha1.TMCFeatureStatus) error
+}
+
+// enqueue enqueues the TMCFeatureStatus of the logical cluster of obj.
+func (c *controller) enqueue(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	metaObj, ok := obj.(metav1.Object)
+	if !ok {
+		utilruntime.HandleError(fmt.Errorf("expected metav1.Object, got %T", obj))
+		return
+	}
+	key := kcpcache.ToClusterAwareKey(logicalcluster.From(metaObj).String(), "", tmcv1alpha1.TMCFeatureStatusName)
+
+	logger := logging.WithQueueKey(logging.WithReconciler(klog.Background(), ControllerName), key)
+	logger.V(4).Info("queueing TMCFeatureStatus")
+	c.queue.Add(key)
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	logger := logging.WithReconciler(klog.FromContext(ctx), ControllerName)
+	ctx = klog.NewContext(ctx, logger)
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	for range numThreads {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+
+	logger := logging.WithQueueKey(klog.FromContext(ctx), key)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(4).Info("processing key")
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		utilruntime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", ControllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) error {
+	clusterName, _, _, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return nil
+	}
+
+	status, err := c.getFeatureStatus(clusterName)
+	if errors.IsNotFound(err) {
+		status = nil
+	} else if err != nil {
+		return err
+	}
+
+	return c.reconcile(ctx, clusterName, status)
+}
+
+func fromUnstructured(obj runtime.Object, into interface{}) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("expected *unstructured.Unstructured, got %T", obj)
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, into)
+}
+
+func toUnstructured(obj interface{}) (*unstructured.Unstructured, error) {
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: raw}, nil
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package featurestatus
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/component-base/featuregate"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	kcpfeatures "github.com/kcp-dev/kcp/pkg/features"
+	"github.com/kcp-dev/kcp/pkg/featurez"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// reconcile updates the TMCFeatureStatus of the workspace, creating it if
+// the workspace uses TMC. existing is nil if it does not exist yet.
+func (c *controller) reconcile(ctx context.Context, clusterName logicalcluster.Name, existing *tmcv1alpha1.TMCFeatureStatus) error {
+	logger := klog.FromContext(ctx)
+
+	syncTargets, err := c.listSyncTargets(clusterName)
+	if err != nil {
+		return err
+	}
+	distributions, err := c.countDistributions(clusterName)
+	if err != nil {
+		return err
+	}
+	if existing == nil && len(syncTargets) == 0 && distributions == 0 {
+		return nil
+	}
+
+	gates := featurez.Sorted(c.featureGates())
+	status := tmcv1alpha1.TMCFeatureStatusStatus{
+		Controller: gates,
+		Workspace: []tmcv1alpha1.WorkspaceFeature{
+			workspaceFeature(gates, kcpfeatures.TMCControllers, len(syncTargets), "SyncTargets"),
+			workspaceFeature(gates, kcpfeatures.TMCPlacement, distributions, "WorkloadDistributions"),
+		},
+	}
+	sort.Slice(syncTargets, func(i, j int) bool { return syncTargets[i].Name < syncTargets[j].Name })
+	for _, syncTarget := range syncTargets {
+		if len(syncTarget.Status.SyncerFeatureGates) == 0 {
+			continue
+		}
+		status.SyncTargets = append(status.SyncTargets, tmcv1alpha1.SyncTargetFeatureGates{
+			Name:         syncTarget.Name,
+			FeatureGates: featurez.Sorted(syncTarget.Status.SyncerFeatureGates),
+		})
+	}
+	status.Mismatches = featurez.Mismatches(featurez.ControllersPeer, gates, status.SyncTargets)
+	status.MismatchCount = int32(len(status.Mismatches))
+
+	if existing == nil {
+		logger.V(2).Info("creating TMCFeatureStatus")
+		if existing, err = c.createFeatureStatus(ctx, clusterName, &tmcv1alpha1.TMCFeatureStatus{
+			ObjectMeta: metav1.ObjectMeta{Name: tmcv1alpha1.TMCFeatureStatusName},
+		}); err != nil {
+			return err
+		}
+	}
+
+	status.LastUpdateTime = existing.Status.LastUpdateTime
+	if equality.Semantic.DeepEqual(existing.Status, status) {
+		return nil
+	}
+	status.LastUpdateTime = &metav1.Time{Time: c.now()}
+	s := existing.DeepCopy()
+	s.Status = status
+	logger.V(2).Info("updating TMCFeatureStatus", "mismatches", status.MismatchCount)
+	return c.updateFeatureStatus(ctx, clusterName, s)
+}
+
+// workspaceFeature returns whether the feature of gate is active in the
+// workspace, which uses it with count objects of kind.
+func workspaceFeature(gates []tmcv1alpha1.FeatureGate, gate featuregate.Feature, count int, kind string) tmcv1alpha1.WorkspaceFeature {
+	feature := tmcv1alpha1.WorkspaceFeature{Name: string(gate)}
+	switch {
+	case !enabled(gates, string(gate)):
+		feature.Message = fmt.Sprintf("Disabled by the %s feature gate", gate)
+	case count == 0:
+		feature.Message = "No " + kind
+	default:
+		feature.Active = true
+		feature.Message = fmt.Sprintf("%d %s", count, kind)
+	}
+	return feature
+}
+
+func enabled(gates []tmcv1alpha1.FeatureGate, name string) bool {
+	for _, gate := range gates {
+		if gate.Name == name {
+			return gate.Enabled
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package featurestatus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func TestReconcile(t *testing.T) {
+	var syncTargets []*tmcv1alpha1.SyncTarget
+	distributions := 0
+	var featureStatus *tmcv1alpha1.TMCFeatureStatus
+	creates, updates := 0, 0
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := &controller{
+		now: func() time.Time { return now },
+		featureGates: func() []tmcv1alpha1.FeatureGate {
+			return []tmcv1alpha1.FeatureGate{
+				{Name: "TMCPlacement", Enabled: false},
+				{Name: "TMCControllers", Enabled: true},
+				{Name: "TMCFeature", Enabled: true},
+			}
+		},
+		listSyncTargets: func(clusterName logicalcluster.Name) ([]*tmcv1alpha1.SyncTarget, error) {
+			return syncTargets, nil
+		},
+		countDistributions: func(clusterName logicalcluster.Name) (int, error) {
+			return distributions, nil
+		},
+		createFeatureStatus: func(ctx context.Context, clusterName logicalcluster.Name, status *tmcv1alpha1.TMCFeatureStatus) (*tmcv1alpha1.TMCFeatureStatus, error) {
+			creates++
+			return status, nil
+		},
+		updateFeatureStatus: func(ctx context.Context, clusterName logicalcluster.Name, status *tmcv1alpha1.TMCFeatureStatus) error {
+			updates++
+			featureStatus = status
+			return nil
+		},
+	}
+	reconcile := func() {
+		t.Helper()
+		require.NoError(t, c.reconcile(context.Background(), "root:org", featureStatus))
+	}
+
+	reconcile()
+	require.Zero(t, creates, "workspaces without TMC objects get no status")
+
+	syncTargets = []*tmcv1alpha1.SyncTarget{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "us-1"},
+			Status: tmcv1alpha1.SyncTargetStatus{SyncerFeatureGates: []tmcv1alpha1.FeatureGate{
+				{Name: "TMCPlacement", Enabled: true},
+				{Name: "Observer", Enabled: true},
+				{Name: "TMCFeature", Enabled: true},
+			}},
+		},
+		{ObjectMeta: metav1.ObjectMeta{Name: "eu-1"}},
+	}
+	reconcile()
+	require.Equal(t, 1, creates)
+	require.Equal(t, 1, updates)
+	require.Equal(t, tmcv1alpha1.TMCFeatureStatusName, featureStatus.Name)
+	require.Equal(t, tmcv1alpha1.TMCFeatureStatusStatus{
+		Controller: []tmcv1alpha1.FeatureGate{
+			{Name: "TMCControllers", Enabled: true},
+			{Name: "TMCFeature", Enabled: true},
+			{Name: "TMCPlacement", Enabled: false},
+		},
+		Workspace: []tmcv1alpha1.WorkspaceFeature{
+			{Name: "TMCControllers", Active: true, Message: "2 SyncTargets"},
+			{Name: "TMCPlacement", Message: "Disabled by the TMCPlacement feature gate"},
+		},
+		SyncTargets: []tmcv1alpha1.SyncTargetFeatureGates{{
+			Name: "us-1",
+			FeatureGates: []tmcv1alpha1.FeatureGate{
+				{Name: "Observer", Enabled: true},
+				{Name: "TMCFeature", Enabled: true},
+				{Name: "TMCPlacement", Enabled: true},
+			},
+		}},
+		Mismatches: []tmcv1alpha1.FeatureMismatch{{
+			FeatureGate: "TMCPlacement",
+			SyncTarget:  "us-1",
+			Message:     "TMCPlacement is enabled in the syncer, but disabled in the TMC controllers",
+		}},
+		MismatchCount:  1,
+		LastUpdateTime: &metav1.Time{Time: now},
+	}, featureStatus.Status)
+
+	now = now.Add(time.Minute)
+	reconcile()
+	require.Equal(t, 1, updates, "unchanged status is not updated")
+
+	syncTargets = []*tmcv1alpha1.SyncTarget{{ObjectMeta: metav1.ObjectMeta{Name: "eu-1"}}}
+	reconcile()
+	require.Equal(t, 2, updates)
+	require.Empty(t, featureStatus.Status.Mismatches)
+	require.Zero(t, featureStatus.Status.MismatchCount)
+	require.Equal(t, "1 SyncTargets", featureStatus.Status.Workspace[0].Message)
+	require.Equal(t, now, featureStatus.Status.LastUpdateTime.Time)
+
+	syncTargets = nil
+	reconcile()
+	require.Equal(t, 1, creates)
+	require.Equal(t, tmcv1alpha1.WorkspaceFeature{Name: "TMCControllers", Message: "No SyncTargets"}, featureStatus.Status.Workspace[0],
+		"existing statuses are kept up to date")
+}
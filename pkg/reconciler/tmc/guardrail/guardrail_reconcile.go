@@ -0,0 +1,106 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package guardrail
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+const (
+	// GuardrailRecoveredReason is the reason of the event emitted when the
+	// usage of a SyncTarget is back within its guardrails.
+	GuardrailRecoveredReason = "GuardrailRecovered"
+)
+
+func (c *controller) reconcile(ctx context.Context, clusterName logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) error {
+	logger := klog.FromContext(ctx)
+
+	st := syncTarget.DeepCopy()
+	wasExceeded := conditions.GetReason(st, tmcv1alpha1.SchedulingDisabled) == tmcv1alpha1.GuardrailExceededReason
+
+	violations := st.Spec.Guardrails.Violations(st.Status.Usage)
+	var event *corev1.Event
+	switch {
+	case len(violations) > 0:
+		message := "Guardrails exceeded: " + strings.Join(violations, ", ")
+		conditions.Set(st, &conditionsv1alpha1.Condition{
+			Type:     tmcv1alpha1.SchedulingDisabled,
+			Status:   corev1.ConditionTrue,
+			Severity: conditionsv1alpha1.ConditionSeverityWarning,
+			Reason:   tmcv1alpha1.GuardrailExceededReason,
+			Message:  message,
+		})
+		if !wasExceeded {
+			event = c.newEvent(st, corev1.EventTypeWarning, tmcv1alpha1.GuardrailExceededReason, message+". No new workloads are placed on the SyncTarget.")
+		}
+	case wasExceeded:
+		conditions.Delete(st, tmcv1alpha1.SchedulingDisabled)
+		event = c.newEvent(st, corev1.EventTypeNormal, GuardrailRecoveredReason, "Usage is within guardrails again, new workloads can be placed on the SyncTarget.")
+	}
+
+	if equality.Semantic.DeepEqual(syncTarget.Status, st.Status) {
+		return nil
+	}
+	logger.V(2).Info("updating SyncTarget status", "violations", violations)
+	if err := c.updateSyncTargetStatus(ctx, clusterName, st); err != nil {
+		return err
+	}
+	if event != nil {
+		// Events are best effort, the condition is the source of truth.
+		if err := c.createEvent(ctx, clusterName, event); err != nil {
+			logger.Error(err, "failed to create event", "reason", event.Reason)
+		}
+	}
+	return nil
+}
+
+func (c *controller) newEvent(syncTarget *tmcv1alpha1.SyncTarget, eventType, reason, message string) *corev1.Event {
+	now := metav1.NewTime(c.now())
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: eventNamespace,
+			Name:      fmt.Sprintf("%s.%x", syncTarget.Name, now.UnixNano()),
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion:      tmcv1alpha1.SchemeGroupVersion.String(),
+			Kind:            "SyncTarget",
+			Name:            syncTarget.Name,
+			UID:             syncTarget.UID,
+			ResourceVersion: syncTarget.ResourceVersion,
+		},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        message,
+		Source:         corev1.EventSource{Component: ControllerName},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package guardrail
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func TestReconcile(t *testing.T) {
+	syncTarget := &tmcv1alpha1.SyncTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge-1"},
+		Spec: tmcv1alpha1.SyncTargetSpec{Guardrails: &tmcv1alpha1.SyncTargetGuardrails{
+			MaxObjects:       ptr.To[int64](100),
+			MaxManifestBytes: ptr.To(resource.MustParse("1Mi")),
+		}},
+		Status: tmcv1alpha1.SyncTargetStatus{Usage: &tmcv1alpha1.SyncTargetUsage{Objects: 10, ManifestBytes: 1000}},
+	}
+
+	var events []*corev1.Event
+	updates := 0
+	c := &controller{
+		now: func() time.Time { return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC) },
+		updateSyncTargetStatus: func(ctx context.Context, clusterName logicalcluster.Name, st *tmcv1alpha1.SyncTarget) error {
+			updates++
+			syncTarget = st
+			return nil
+		},
+		createEvent: func(ctx context.Context, clusterName logicalcluster.Name, event *corev1.Event) error {
+			events = append(events, event)
+			return nil
+		},
+	}
+	reconcile := func() {
+		t.Helper()
+		require.NoError(t, c.reconcile(context.Background(), "root:org", syncTarget))
+	}
+
+	reconcile()
+	require.Zero(t, updates, "within guardrails")
+	require.Nil(t, conditions.Get(syncTarget, tmcv1alpha1.SchedulingDisabled))
+
+	syncTarget.Status.Usage = &tmcv1alpha1.SyncTargetUsage{Objects: 100, ManifestBytes: 2 << 20}
+	reconcile()
+	require.True(t, conditions.IsTrue(syncTarget, tmcv1alpha1.SchedulingDisabled))
+	require.Equal(t, tmcv1alpha1.GuardrailExceededReason, conditions.GetReason(syncTarget, tmcv1alpha1.SchedulingDisabled))
+	require.Equal(t, "Guardrails exceeded: 100 of at most 100 objects synced, 2097152 of at most 1048576 manifest bytes synced",
+		conditions.GetMessage(syncTarget, tmcv1alpha1.SchedulingDisabled))
+	require.Len(t, events, 1)
+	require.Equal(t, corev1.EventTypeWarning, events[0].Type)
+	require.Equal(t, "edge-1", events[0].InvolvedObject.Name)
+	require.Equal(t, metav1.NamespaceDefault, events[0].Namespace)
+
+	reconcile()
+	require.Len(t, events, 1, "no event while the guardrails stay exceeded")
+
+	syncTarget.Status.Usage = &tmcv1alpha1.SyncTargetUsage{Objects: 50, ManifestBytes: 1000}
+	reconcile()
+	require.Nil(t, conditions.Get(syncTarget, tmcv1alpha1.SchedulingDisabled))
+	require.Len(t, events, 2)
+	require.Equal(t, GuardrailRecoveredReason, events[1].Reason)
+}
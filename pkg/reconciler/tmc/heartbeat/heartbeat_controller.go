@@ -0,0 +1,275 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package heartbeat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	kcpcache "github.com/kcp-dev/apimachinery/v2/pkg/cache"
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/clusterprofile"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/tmcinformers"
+	syncerheartbeat "github.com/kcp-dev/kcp/pkg/syncer/heartbeat"
+	"github.com/kcp-dev/kcp/pkg/tmc/workspacequeue"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+const (
+	ControllerName = "kcp-tmc-heartbeat"
+)
+
+// LeasesGVR is the resource of the heartbeat Leases.
+var LeasesGVR = coordinationv1.SchemeGroupVersion.WithResource("leases")
+
+// NewController returns a controller that creates the heartbeat Lease of
+// every SyncTarget, which its syncer renews, and keeps the HeartbeatHealthy
+// and SyncerReady conditions of the SyncTarget up to date with it. It marks
+// the SyncTarget not ready once the Lease was not renewed for
+// spec.heartbeatTimeout, and evicts its workloads if the stale action is
+// Evict.
+func NewController(
+	syncTargetClusterInformer *tmcinformers.Informer,
+	leaseClusterInformer *tmcinformers.Informer,
+	dynamicClusterClient kcpdynamic.ClusterInterface,
+) (*controller, error) {
+	c := &controller{
+		queue: workspacequeue.NewRateLimitingQueue(ControllerName),
+		now:   time.Now,
+		getSyncTarget: func(clusterName logicalcluster.Name, name string) (*tmcv1alpha1.SyncTarget, error) {
+			obj, err := syncTargetClusterInformer.Lister(clusterName).Get(name)
+			if err != nil {
+				return nil, err
+			}
+			syncTarget := &tmcv1alpha1.SyncTarget{}
+			return syncTarget, fromUnstructured(obj, syncTarget)
+		},
+		getLease: func(clusterName logicalcluster.Name, name string) (*coordinationv1.Lease, error) {
+			obj, err := leaseClusterInformer.Lister(clusterName).ByNamespace(syncerheartbeat.LeaseNamespace).Get(name)
+			if err != nil {
+				return nil, err
+			}
+			lease := &coordinationv1.Lease{}
+			return lease, fromUnstructured(obj, lease)
+		},
+		createLease: func(ctx context.Context, clusterName logicalcluster.Name, lease *coordinationv1.Lease) error {
+			u, err := toUnstructured(lease)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(LeasesGVR).Namespace(lease.Namespace).Create(ctx, u, metav1.CreateOptions{})
+			return err
+		},
+		updateLease: func(ctx context.Context, clusterName logicalcluster.Name, lease *coordinationv1.Lease) error {
+			u, err := toUnstructured(lease)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(LeasesGVR).Namespace(lease.Namespace).Update(ctx, u, metav1.UpdateOptions{})
+			return err
+		},
+		updateSyncTarget: func(ctx context.Context, clusterName logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) error {
+			u, err := toUnstructured(syncTarget)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(clusterprofile.SyncTargetsGVR).Update(ctx, u, metav1.UpdateOptions{})
+			return err
+		},
+		updateSyncTargetStatus: func(ctx context.Context, clusterName logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) error {
+			u, err := toUnstructured(syncTarget)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(clusterprofile.SyncTargetsGVR).UpdateStatus(ctx, u, metav1.UpdateOptions{})
+			return err
+		},
+	}
+
+	syncTargetClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueue(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+	})
+	leaseClusterInformer.AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: isHeartbeatLease,
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { c.enqueueLease(obj) },
+			UpdateFunc: func(_, obj interface{}) { c.enqueueLease(obj) },
+			DeleteFunc: func(obj interface{}) { c.enqueueLease(obj) },
+		},
+	})
+
+	return c, nil
+}
+
+// controller maintains the heartbeat of SyncTargets.
+type controller struct {
+	queue workqueue.TypedRateLimitingInterface[string]
+
+	now func() time.Time
+
+	getSyncTarget          func(clusterName logicalcluster.Name, name string) (*tmcv1alpha1.SyncTarget, error)
+	getLease               func(clusterName logicalcluster.Name, name string) (*coordinationv1.Lease, error)
+	createLease            func(ctx context.Context, clusterName logicalcluster.Name, lease *coordinationv1.Lease) error
+	updateLease            func(ctx context.Context, clusterName logicalcluster.Name, lease *coordinationv1.Lease) error
+	updateSyncTarget       func(ctx context.Context, clusterName logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) error
+	updateSyncTargetStatus func(ctx context.Context, clusterName logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) error
+}
+
+// leasePrefix is the name prefix of heartbeat Leases.
+var leasePrefix = syncerheartbeat.LeaseName("")
+
+func isHeartbeatLease(obj interface{}) bool {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	return ok && u.GetNamespace() == syncerheartbeat.LeaseNamespace && strings.HasPrefix(u.GetName(), leasePrefix)
+}
+
+func (c *controller) enqueue(obj interface{}) {
+	key, err := kcpcache.DeletionHandlingMetaClusterNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+
+	logger := logging.WithQueueKey(logging.WithReconciler(klog.Background(), ControllerName), key)
+	logger.V(4).Info("queueing SyncTarget")
+	c.queue.Add(key)
+}
+
+// enqueueLease enqueues the SyncTarget of a heartbeat Lease.
+func (c *controller) enqueueLease(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		utilruntime.HandleError(fmt.Errorf("expected *unstructured.Unstructured, got %T", obj))
+		return
+	}
+	key := kcpcache.ToClusterAwareKey(logicalcluster.From(u).String(), "", strings.TrimPrefix(u.GetName(), leasePrefix))
+
+	logger := logging.WithQueueKey(logging.WithReconciler(klog.Background(), ControllerName), key)
+	logger.V(4).Info("queueing SyncTarget of heartbeat Lease")
+	c.queue.Add(key)
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	logger := logging.WithReconciler(klog.FromContext(ctx), ControllerName)
+	ctx = klog.NewContext(ctx, logger)
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	for range numThreads {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+
+	logger := logging.WithQueueKey(klog.FromContext(ctx), key)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(4).Info("processing key")
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	requeueAfter, err := c.process(ctx, key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", ControllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	if requeueAfter > 0 {
+		c.queue.AddAfter(key, requeueAfter)
+	}
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) (time.Duration, error) {
+	clusterName, _, name, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return 0, nil
+	}
+
+	syncTarget, err := c.getSyncTarget(clusterName, name)
+	if errors.IsNotFound(err) {
+		// The Lease is owned by the SyncTarget and garbage collected.
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !syncTarget.DeletionTimestamp.IsZero() {
+		return 0, nil
+	}
+
+	return c.reconcile(ctx, clusterName, syncTarget)
+}
+
+func fromUnstructured(obj runtime.Object, into interface{}) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("expected *unstructured.Unstructured, got %T", obj)
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, into)
+}
+
+func toUnstructured(obj interface{}) (*unstructured.Unstructured, error) {
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: raw}, nil
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package heartbeat
+
+import (
+	"context"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	syncerheartbeat "github.com/kcp-dev/kcp/pkg/syncer/heartbeat"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func (c *controller) reconcile(ctx context.Context, clusterName logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) (time.Duration, error) {
+	logger := klog.FromContext(ctx)
+	timeout := syncTarget.Spec.GetHeartbeatTimeout()
+
+	lease, err := c.ensureLease(ctx, clusterName, syncTarget, timeout)
+	if err != nil {
+		return 0, err
+	}
+
+	now := c.now()
+	var renewed *metav1.Time
+	if lease.Spec.RenewTime != nil {
+		renewed = &metav1.Time{Time: lease.Spec.RenewTime.Time}
+	}
+	stale := renewed != nil && !now.Before(renewed.Add(timeout))
+	var requeueAfter time.Duration
+	if renewed != nil && !stale {
+		requeueAfter = renewed.Add(timeout).Sub(now)
+	}
+
+	// The spec is updated first, and the status once the update is seen,
+	// as both updates would conflict otherwise.
+	evict := stale && syncTarget.Spec.HeartbeatStaleAction == tmcv1alpha1.HeartbeatStaleActionEvict
+	if evict && syncTarget.Spec.EvictAfter == nil {
+		logger.V(2).Info("evicting workloads of SyncTarget with missing heartbeat")
+		st := syncTarget.DeepCopy()
+		st.Spec.EvictAfter = &metav1.Time{Time: renewed.Add(timeout)}
+		if st.Annotations == nil {
+			st.Annotations = map[string]string{}
+		}
+		st.Annotations[tmcv1alpha1.AnnotationEvictedBy] = ControllerName
+		return 0, c.updateSyncTarget(ctx, clusterName, st)
+	}
+	if !evict && syncTarget.Annotations[tmcv1alpha1.AnnotationEvictedBy] == ControllerName {
+		logger.V(2).Info("releasing SyncTarget from eviction")
+		st := syncTarget.DeepCopy()
+		st.Spec.EvictAfter = nil
+		delete(st.Annotations, tmcv1alpha1.AnnotationEvictedBy)
+		return 0, c.updateSyncTarget(ctx, clusterName, st)
+	}
+
+	st := syncTarget.DeepCopy()
+	switch {
+	case renewed == nil:
+		conditions.MarkUnknown(st, tmcv1alpha1.HeartbeatHealthy, tmcv1alpha1.WaitingForHeartbeatReason,
+			"The syncer has not renewed its heartbeat Lease %s/%s yet.", lease.Namespace, lease.Name)
+	case stale:
+		st.Status.LastSyncerHeartbeatTime = renewed
+		conditions.MarkFalse(st, tmcv1alpha1.HeartbeatHealthy, tmcv1alpha1.ErrorHeartbeatMissedReason, conditionsv1alpha1.ConditionSeverityWarning,
+			"The syncer has not renewed its heartbeat Lease since %s.", renewed.UTC().Format(time.RFC3339))
+		conditions.MarkFalse(st, tmcv1alpha1.SyncerReady, tmcv1alpha1.ErrorHeartbeatMissedReason, conditionsv1alpha1.ConditionSeverityError,
+			"The syncer heartbeat is missing for longer than %s.", timeout)
+	default:
+		st.Status.LastSyncerHeartbeatTime = renewed
+		conditions.MarkTrue(st, tmcv1alpha1.HeartbeatHealthy)
+		// Only the readiness taken away for a missing heartbeat is given
+		// back, the syncer reports the rest.
+		if conditions.GetReason(st, tmcv1alpha1.SyncerReady) == tmcv1alpha1.ErrorHeartbeatMissedReason {
+			conditions.MarkTrue(st, tmcv1alpha1.SyncerReady)
+		}
+	}
+	if equality.Semantic.DeepEqual(syncTarget.Status, st.Status) {
+		return requeueAfter, nil
+	}
+	logger.V(2).Info("updating SyncTarget heartbeat", "stale", stale)
+	return requeueAfter, c.updateSyncTargetStatus(ctx, clusterName, st)
+}
+
+// ensureLease returns the heartbeat Lease of the SyncTarget, created with
+// the heartbeat timeout as its duration if it is missing.
+func (c *controller) ensureLease(ctx context.Context, clusterName logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget, timeout time.Duration) (*coordinationv1.Lease, error) {
+	seconds := int32(timeout.Seconds())
+	lease, err := c.getLease(clusterName, syncerheartbeat.LeaseName(syncTarget.Name))
+	if errors.IsNotFound(err) {
+		klog.FromContext(ctx).V(2).Info("creating heartbeat Lease")
+		lease = &coordinationv1.Lease{
+			TypeMeta: metav1.TypeMeta{APIVersion: coordinationv1.SchemeGroupVersion.String(), Kind: "Lease"},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: syncerheartbeat.LeaseNamespace,
+				Name:      syncerheartbeat.LeaseName(syncTarget.Name),
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: tmcv1alpha1.SchemeGroupVersion.String(),
+					Kind:       "SyncTarget",
+					Name:       syncTarget.Name,
+					UID:        syncTarget.UID,
+				}},
+			},
+			Spec: coordinationv1.LeaseSpec{LeaseDurationSeconds: &seconds},
+		}
+		if err := c.createLease(ctx, clusterName, lease); err != nil && !errors.IsAlreadyExists(err) {
+			return nil, err
+		}
+		return lease, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if ptr.Deref(lease.Spec.LeaseDurationSeconds, 0) == seconds {
+		return lease, nil
+	}
+	// The syncer renews the Lease according to its duration.
+	lease = lease.DeepCopy()
+	lease.Spec.LeaseDurationSeconds = &seconds
+	return lease, c.updateLease(ctx, clusterName, lease)
+}
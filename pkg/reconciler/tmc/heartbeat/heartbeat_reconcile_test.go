@@ -0,0 +1,161 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package heartbeat
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	syncerheartbeat "github.com/kcp-dev/kcp/pkg/syncer/heartbeat"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func TestReconcile(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	syncTarget := &tmcv1alpha1.SyncTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge", UID: "uid"},
+		Spec:       tmcv1alpha1.SyncTargetSpec{HeartbeatTimeout: &metav1.Duration{Duration: 40 * time.Second}},
+	}
+	conditions.MarkTrue(syncTarget, tmcv1alpha1.SyncerReady)
+
+	var lease *coordinationv1.Lease
+	specUpdates := 0
+	c := &controller{
+		now: func() time.Time { return now },
+		getLease: func(_ logicalcluster.Name, name string) (*coordinationv1.Lease, error) {
+			if lease == nil {
+				return nil, apierrors.NewNotFound(LeasesGVR.GroupResource(), name)
+			}
+			return lease, nil
+		},
+		createLease: func(_ context.Context, _ logicalcluster.Name, l *coordinationv1.Lease) error {
+			lease = l
+			return nil
+		},
+		updateLease: func(_ context.Context, _ logicalcluster.Name, l *coordinationv1.Lease) error {
+			lease = l
+			return nil
+		},
+		updateSyncTarget: func(_ context.Context, _ logicalcluster.Name, st *tmcv1alpha1.SyncTarget) error {
+			specUpdates++
+			syncTarget = st
+			return nil
+		},
+		updateSyncTargetStatus: func(_ context.Context, _ logicalcluster.Name, st *tmcv1alpha1.SyncTarget) error {
+			syncTarget = st
+			return nil
+		},
+	}
+	reconcile := func() time.Duration {
+		t.Helper()
+		requeueAfter, err := c.reconcile(context.Background(), "root:org", syncTarget)
+		require.NoError(t, err)
+		return requeueAfter
+	}
+	renew := func(at time.Time) {
+		renewTime := metav1.NewMicroTime(at)
+		lease.Spec.RenewTime = &renewTime
+	}
+
+	require.Zero(t, reconcile())
+	require.Equal(t, syncerheartbeat.LeaseName("edge"), lease.Name)
+	require.Equal(t, int32(40), *lease.Spec.LeaseDurationSeconds)
+	require.Equal(t, "edge", lease.OwnerReferences[0].Name)
+	require.True(t, conditions.IsUnknown(syncTarget, tmcv1alpha1.HeartbeatHealthy))
+	require.True(t, conditions.IsTrue(syncTarget, tmcv1alpha1.SyncerReady), "targets are not marked not ready before the first heartbeat")
+
+	renew(now.Add(-10 * time.Second))
+	require.Equal(t, 30*time.Second, reconcile(), "the heartbeat is checked again when it expires")
+	require.True(t, conditions.IsTrue(syncTarget, tmcv1alpha1.HeartbeatHealthy))
+	require.Equal(t, now.Add(-10*time.Second), syncTarget.Status.LastSyncerHeartbeatTime.Time)
+
+	now = now.Add(30 * time.Second)
+	require.Zero(t, reconcile())
+	require.True(t, conditions.IsFalse(syncTarget, tmcv1alpha1.HeartbeatHealthy))
+	require.True(t, conditions.IsFalse(syncTarget, tmcv1alpha1.SyncerReady))
+	require.Equal(t, tmcv1alpha1.ErrorHeartbeatMissedReason, conditions.GetReason(syncTarget, tmcv1alpha1.SyncerReady))
+	require.Zero(t, specUpdates, "workloads are not evicted by default")
+
+	renew(now)
+	require.Equal(t, 40*time.Second, reconcile())
+	require.True(t, conditions.IsTrue(syncTarget, tmcv1alpha1.HeartbeatHealthy))
+	require.True(t, conditions.IsTrue(syncTarget, tmcv1alpha1.SyncerReady), "readiness is given back with the heartbeat")
+
+	conditions.MarkFalse(syncTarget, tmcv1alpha1.SyncerReady, "Down", conditionsv1alpha1.ConditionSeverityError, "")
+	reconcile()
+	require.True(t, conditions.IsFalse(syncTarget, tmcv1alpha1.SyncerReady), "readiness reported by the syncer is kept")
+}
+
+func TestReconcileEvict(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	syncTarget := &tmcv1alpha1.SyncTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge"},
+		Spec:       tmcv1alpha1.SyncTargetSpec{HeartbeatStaleAction: tmcv1alpha1.HeartbeatStaleActionEvict},
+	}
+	renewTime := metav1.NewMicroTime(now.Add(-2 * time.Minute))
+	lease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Namespace: syncerheartbeat.LeaseNamespace, Name: syncerheartbeat.LeaseName("edge")},
+		Spec:       coordinationv1.LeaseSpec{LeaseDurationSeconds: ptr.To[int32](60), RenewTime: &renewTime},
+	}
+	c := &controller{
+		now:      func() time.Time { return now },
+		getLease: func(logicalcluster.Name, string) (*coordinationv1.Lease, error) { return lease, nil },
+		updateSyncTarget: func(_ context.Context, _ logicalcluster.Name, st *tmcv1alpha1.SyncTarget) error {
+			syncTarget = st
+			return nil
+		},
+		updateSyncTargetStatus: func(_ context.Context, _ logicalcluster.Name, st *tmcv1alpha1.SyncTarget) error {
+			syncTarget = st
+			return nil
+		},
+	}
+	reconcile := func() {
+		t.Helper()
+		_, err := c.reconcile(context.Background(), "root:org", syncTarget)
+		require.NoError(t, err)
+	}
+
+	reconcile()
+	require.Equal(t, now.Add(-time.Minute), syncTarget.Spec.EvictAfter.Time, "workloads are evicted from when the heartbeat went missing")
+	require.Equal(t, ControllerName, syncTarget.Annotations[tmcv1alpha1.AnnotationEvictedBy])
+	reconcile()
+	require.True(t, conditions.IsFalse(syncTarget, tmcv1alpha1.HeartbeatHealthy))
+
+	renewTime = metav1.NewMicroTime(now)
+	reconcile()
+	require.Nil(t, syncTarget.Spec.EvictAfter, "targets are released once the heartbeat is back")
+	require.NotContains(t, syncTarget.Annotations, tmcv1alpha1.AnnotationEvictedBy)
+
+	manual := metav1.NewTime(now.Add(time.Hour))
+	syncTarget.Spec.EvictAfter = &manual
+	now = now.Add(2 * time.Minute)
+	reconcile()
+	require.Equal(t, &manual, syncTarget.Spec.EvictAfter, "evictions set by hand are kept")
+	require.NotContains(t, syncTarget.Annotations, tmcv1alpha1.AnnotationEvictedBy)
+}
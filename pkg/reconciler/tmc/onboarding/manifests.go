@@ -0,0 +1,200 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onboarding
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/controllermanager"
+	"github.com/kcp-dev/kcp/pkg/syncer/permissions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+const (
+	// syncerNamespace is the namespace of the syncer on the physical cluster.
+	syncerNamespace = "kcp-syncer"
+	// syncerServiceAccount is the service account of the syncer on the
+	// physical cluster.
+	syncerServiceAccount = "kcp-syncer"
+
+	kubeconfigKey  = "kubeconfig"
+	kubeconfigPath = "/etc/kcp"
+
+	resourceConfigKey  = "resources.yaml"
+	resourceConfigPath = "/etc/kcp-syncer"
+)
+
+// BootstrapConfig is what the bootstrap manifests of a syncer depend on.
+type BootstrapConfig struct {
+	// Server is the URL of the workspace of the SyncTarget.
+	Server string
+	// Token authenticates the syncer in the workspace.
+	Token string
+	// SyncerImage is the image of the syncer.
+	SyncerImage string
+	// Cluster is the logical cluster of the SyncTarget.
+	Cluster logicalcluster.Name
+	// CAData is the PEM encoded CA bundle the syncer verifies kcp with, or
+	// empty to use the system roots.
+	CAData []byte
+	// ResourceConfig is the syncer resource configuration listing the
+	// synced resources, see controllermanager.ParseConfig, or empty for
+	// the default resources of the syncer. The RBAC on the physical
+	// cluster covers the listed resources.
+	ResourceConfig []byte
+	// FeatureGates are passed to the syncer with --feature-gates.
+	FeatureGates map[string]bool
+	// Features are the syncer features the RBAC on the physical cluster is
+	// generated for. Defaults to capacity and leader election.
+	Features *permissions.Features
+}
+
+// Render returns the manifests to apply on the physical cluster of the
+// SyncTarget to run its syncer, as a multi-document YAML: the syncer
+// namespace and service account, its RBAC on the physical cluster, its
+// kubeconfig for kcp, its resource configuration if any and its
+// deployment. Without a resource configuration, synced resources need
+// further permissions, see kubectl tmc syncer-rbac.
+func Render(syncTarget *tmcv1alpha1.SyncTarget, config BootstrapConfig) ([]byte, error) {
+	var resources []schema.GroupVersionResource
+	if len(config.ResourceConfig) > 0 {
+		resourceConfig, err := controllermanager.ParseConfig(config.ResourceConfig)
+		if err != nil {
+			return nil, fmt.Errorf("invalid resource configuration: %w", err)
+		}
+		for gvr := range resourceConfig {
+			resources = append(resources, gvr)
+		}
+		sort.Slice(resources, func(i, j int) bool { return resources[i].String() < resources[j].String() })
+	}
+	features := permissions.Features{Capacity: true, LeaderElection: true}
+	if config.Features != nil {
+		features = *config.Features
+	}
+
+	kubeconfig, err := clientcmd.Write(clientcmdapi.Config{
+		Clusters:       map[string]*clientcmdapi.Cluster{"kcp": {Server: config.Server, CertificateAuthorityData: config.CAData}},
+		AuthInfos:      map[string]*clientcmdapi.AuthInfo{"syncer": {Token: config.Token}},
+		Contexts:       map[string]*clientcmdapi.Context{"kcp": {Cluster: "kcp", AuthInfo: "syncer"}},
+		CurrentContext: "kcp",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	labels := map[string]string{"app.kubernetes.io/name": "kcp-syncer", "tmc.kcp.io/sync-target": syncTarget.Name}
+	objs := []runtime.Object{
+		&corev1.Namespace{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+			ObjectMeta: metav1.ObjectMeta{Name: syncerNamespace},
+		},
+		&corev1.ServiceAccount{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: syncerNamespace, Name: syncerServiceAccount},
+		},
+	}
+	req := permissions.ForSyncTarget(syncTarget, resources, features)
+	objs = append(objs, permissions.Manifests(SyncerName(syncTarget.Name), syncerNamespace, syncerServiceAccount, req)...)
+	objs = append(objs, &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: syncerNamespace, Name: "kcp-syncer-kubeconfig"},
+		Data:       map[string][]byte{kubeconfigKey: kubeconfig},
+	})
+
+	args := []string{
+		"--from-kubeconfig=" + kubeconfigPath + "/" + kubeconfigKey,
+		"--sync-targets=" + config.Cluster.Path().Join(syncTarget.Name).String(),
+	}
+	volumeMounts := []corev1.VolumeMount{{Name: "kcp-kubeconfig", MountPath: kubeconfigPath, ReadOnly: true}}
+	volumes := []corev1.Volume{{
+		Name:         "kcp-kubeconfig",
+		VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "kcp-syncer-kubeconfig"}},
+	}}
+	if len(config.ResourceConfig) > 0 {
+		objs = append(objs, &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: syncerNamespace, Name: "kcp-syncer-resources"},
+			Data:       map[string]string{resourceConfigKey: string(config.ResourceConfig)},
+		})
+		args = append(args, "--resource-config="+resourceConfigPath+"/"+resourceConfigKey)
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: "resource-config", MountPath: resourceConfigPath, ReadOnly: true})
+		volumes = append(volumes, corev1.Volume{
+			Name: "resource-config",
+			VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "kcp-syncer-resources"},
+			}},
+		})
+	}
+	if len(config.FeatureGates) > 0 {
+		gates := make([]string, 0, len(config.FeatureGates))
+		for name, enabled := range config.FeatureGates {
+			gates = append(gates, fmt.Sprintf("%s=%t", name, enabled))
+		}
+		sort.Strings(gates)
+		args = append(args, "--feature-gates="+strings.Join(gates, ","))
+	}
+
+	objs = append(objs, &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String(), Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: syncerNamespace, Name: "kcp-syncer", Labels: labels},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To[int32](1),
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: syncerServiceAccount,
+					Containers: []corev1.Container{{
+						Name:         "syncer",
+						Image:        config.SyncerImage,
+						Args:         args,
+						VolumeMounts: volumeMounts,
+					}},
+					Volumes: volumes,
+				},
+			},
+		},
+	})
+
+	var out bytes.Buffer
+	for i, obj := range objs {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			out.WriteString("---\n")
+		}
+		out.Write(data)
+	}
+	return out.Bytes(), nil
+}
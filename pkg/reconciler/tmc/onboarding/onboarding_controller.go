@@ -0,0 +1,271 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onboarding
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	kcpcache "github.com/kcp-dev/apimachinery/v2/pkg/cache"
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/clusterprofile"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/tmcinformers"
+	"github.com/kcp-dev/kcp/pkg/tmc/workspacequeue"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+const (
+	ControllerName = "kcp-tmc-onboarding"
+
+	// BootstrapNamespace is the namespace of the workspace holding the
+	// credentials and bootstrap manifests of registered syncers.
+	BootstrapNamespace = metav1.NamespaceDefault
+
+	// ManifestsKey is the key of the bootstrap manifests in the bootstrap
+	// secret.
+	ManifestsKey = "manifests.yaml"
+)
+
+var (
+	serviceAccountsGVR     = schema.GroupVersionResource{Version: "v1", Resource: "serviceaccounts"}
+	secretsGVR             = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	clusterRolesGVR        = schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}
+	clusterRoleBindingsGVR = schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterrolebindings"}
+)
+
+// SyncerName returns the name of the service account, token secret and
+// RBAC of the syncer of a registered SyncTarget in its workspace.
+func SyncerName(syncTarget string) string {
+	return "kcp-syncer-" + syncTarget
+}
+
+// TokenSecretName returns the name of the token secret of the service
+// account of the syncer of a SyncTarget in its workspace.
+func TokenSecretName(syncTarget string) string {
+	return SyncerName(syncTarget) + "-token"
+}
+
+// BootstrapSecretName returns the name of the secret holding the bootstrap
+// manifests of a registered SyncTarget.
+func BootstrapSecretName(syncTarget string) string {
+	return SyncerName(syncTarget) + "-bootstrap"
+}
+
+// Options configure the generated bootstrap manifests.
+type Options struct {
+	// ExternalURL is the URL syncers reach kcp at.
+	ExternalURL func() string
+	// SyncerImage is the image of the syncer deployment.
+	SyncerImage string
+}
+
+// NewController returns a controller that generates the syncer bootstrap
+// manifests of SyncTargets once their registration is approved.
+func NewController(
+	options Options,
+	syncTargetClusterInformer *tmcinformers.Informer,
+	dynamicClusterClient kcpdynamic.ClusterInterface,
+) (*controller, error) {
+	c := &controller{
+		queue:   workspacequeue.NewRateLimitingQueue(ControllerName),
+		options: options,
+		getSyncTarget: func(clusterName logicalcluster.Name, name string) (*tmcv1alpha1.SyncTarget, error) {
+			obj, err := syncTargetClusterInformer.Lister(clusterName).Get(name)
+			if err != nil {
+				return nil, err
+			}
+			syncTarget := &tmcv1alpha1.SyncTarget{}
+			return syncTarget, fromUnstructured(obj, syncTarget)
+		},
+		updateSyncTargetStatus: func(ctx context.Context, clusterName logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) error {
+			u, err := toUnstructured(syncTarget)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(clusterprofile.SyncTargetsGVR).UpdateStatus(ctx, u, metav1.UpdateOptions{})
+			return err
+		},
+		createIfMissing: func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, obj runtime.Object) error {
+			u, err := toUnstructured(obj)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(gvr).Namespace(u.GetNamespace()).Create(ctx, u, metav1.CreateOptions{})
+			if errors.IsAlreadyExists(err) {
+				return nil
+			}
+			return err
+		},
+		getSecret: func(ctx context.Context, clusterName logicalcluster.Name, namespace, name string) (*corev1.Secret, error) {
+			u, err := dynamicClusterClient.Cluster(clusterName.Path()).Resource(secretsGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			secret := &corev1.Secret{}
+			return secret, fromUnstructured(u, secret)
+		},
+		updateSecret: func(ctx context.Context, clusterName logicalcluster.Name, secret *corev1.Secret) error {
+			u, err := toUnstructured(secret)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(secretsGVR).Namespace(secret.Namespace).Update(ctx, u, metav1.UpdateOptions{})
+			return err
+		},
+	}
+
+	syncTargetClusterInformer.AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			u, ok := obj.(*unstructured.Unstructured)
+			if !ok {
+				return false
+			}
+			_, found, _ := unstructured.NestedMap(u.Object, "spec", "registration")
+			return found
+		},
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { c.enqueue(obj) },
+			UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+		},
+	})
+
+	return c, nil
+}
+
+// controller generates the bootstrap manifests of registered SyncTargets.
+type controller struct {
+	queue workqueue.TypedRateLimitingInterface[string]
+
+	options Options
+
+	getSyncTarget          func(clusterName logicalcluster.Name, name string) (*tmcv1alpha1.SyncTarget, error)
+	updateSyncTargetStatus func(ctx context.Context, clusterName logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) error
+	createIfMissing        func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, obj runtime.Object) error
+	getSecret              func(ctx context.Context, clusterName logicalcluster.Name, namespace, name string) (*corev1.Secret, error)
+	updateSecret           func(ctx context.Context, clusterName logicalcluster.Name, secret *corev1.Secret) error
+}
+
+func (c *controller) enqueue(obj interface{}) {
+	key, err := kcpcache.DeletionHandlingMetaClusterNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+
+	logger := logging.WithQueueKey(logging.WithReconciler(klog.Background(), ControllerName), key)
+	logger.V(4).Info("queueing SyncTarget")
+	c.queue.Add(key)
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	logger := logging.WithReconciler(klog.FromContext(ctx), ControllerName)
+	ctx = klog.NewContext(ctx, logger)
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	for range numThreads {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+
+	logger := logging.WithQueueKey(klog.FromContext(ctx), key)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(4).Info("processing key")
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		utilruntime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", ControllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) error {
+	clusterName, _, name, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return nil
+	}
+
+	syncTarget, err := c.getSyncTarget(clusterName, name)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !syncTarget.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	return c.reconcile(ctx, clusterName, syncTarget)
+}
+
+func fromUnstructured(obj runtime.Object, into interface{}) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("expected *unstructured.Unstructured, got %T", obj)
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, into)
+}
+
+func toUnstructured(obj interface{}) (*unstructured.Unstructured, error) {
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: raw}, nil
+}
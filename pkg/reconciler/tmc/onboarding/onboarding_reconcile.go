@@ -0,0 +1,178 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onboarding
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/heartbeat"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+func (c *controller) reconcile(ctx context.Context, clusterName logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) error {
+	registration := syncTarget.Spec.Registration
+	if registration == nil {
+		return nil
+	}
+
+	st := syncTarget.DeepCopy()
+	err := c.bootstrap(ctx, clusterName, st)
+	if !equality.Semantic.DeepEqual(syncTarget.Status, st.Status) {
+		klog.FromContext(ctx).V(2).Info("updating SyncTarget status", "reason", conditions.GetReason(st, tmcv1alpha1.BootstrapReady))
+		if updateErr := c.updateSyncTargetStatus(ctx, clusterName, st); updateErr != nil {
+			return updateErr
+		}
+	}
+	return err
+}
+
+// errTokenPending requeues SyncTargets until the token of their service
+// account is issued.
+var errTokenPending = fmt.Errorf("syncer service account token is not issued yet")
+
+// bootstrap creates the credentials of the syncer in the workspace and the
+// bootstrap manifests, and sets the BootstrapReady condition.
+func (c *controller) bootstrap(ctx context.Context, clusterName logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) error {
+	if !syncTarget.Spec.Registration.Approved {
+		conditions.MarkFalse(syncTarget, tmcv1alpha1.BootstrapReady, tmcv1alpha1.WaitingForApprovalReason, conditionsv1alpha1.ConditionSeverityInfo, "The registration is not approved yet.")
+		return nil
+	}
+
+	name := SyncerName(syncTarget.Name)
+	for _, o := range Credentials(syncTarget.Name) {
+		if err := c.createIfMissing(ctx, clusterName, o.GVR, o.Object); err != nil {
+			return fmt.Errorf("failed to create %s %s: %w", o.GVR.Resource, name, err)
+		}
+	}
+
+	tokenSecret, err := c.getSecret(ctx, clusterName, BootstrapNamespace, TokenSecretName(syncTarget.Name))
+	if err != nil {
+		return err
+	}
+	token := tokenSecret.Data[corev1.ServiceAccountTokenKey]
+	if len(token) == 0 {
+		conditions.MarkFalse(syncTarget, tmcv1alpha1.BootstrapReady, tmcv1alpha1.WaitingForTokenReason, conditionsv1alpha1.ConditionSeverityInfo, "Waiting for the token of service account %s.", name)
+		return errTokenPending
+	}
+
+	manifests, err := Render(syncTarget, BootstrapConfig{
+		Server:      c.options.ExternalURL() + clusterName.Path().RequestPath(),
+		Token:       string(token),
+		SyncerImage: c.options.SyncerImage,
+		Cluster:     clusterName,
+	})
+	if err != nil {
+		return err
+	}
+	if err := c.storeManifests(ctx, clusterName, syncTarget.Name, manifests); err != nil {
+		return err
+	}
+	conditions.MarkTrue(syncTarget, tmcv1alpha1.BootstrapReady)
+	return nil
+}
+
+func (c *controller) storeManifests(ctx context.Context, clusterName logicalcluster.Name, syncTarget string, manifests []byte) error {
+	secret := &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: BootstrapNamespace, Name: BootstrapSecretName(syncTarget)},
+		Data:       map[string][]byte{ManifestsKey: manifests},
+	}
+	existing, err := c.getSecret(ctx, clusterName, secret.Namespace, secret.Name)
+	if apierrors.IsNotFound(err) {
+		return c.createIfMissing(ctx, clusterName, secretsGVR, secret)
+	}
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(existing.Data[ManifestsKey], manifests) {
+		return nil
+	}
+	existing.Data = secret.Data
+	return c.updateSecret(ctx, clusterName, existing)
+}
+
+// Object is an object of the given resource.
+type Object struct {
+	GVR    schema.GroupVersionResource
+	Object runtime.Object
+}
+
+// Credentials returns the service account of the syncer of a SyncTarget in
+// its workspace, its token secret, see TokenSecretName, and the RBAC
+// letting it sync the SyncTarget.
+func Credentials(syncTarget string) []Object {
+	name := SyncerName(syncTarget)
+	subjects := []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Namespace: BootstrapNamespace, Name: name}}
+	return []Object{
+		{serviceAccountsGVR, &corev1.ServiceAccount{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: BootstrapNamespace, Name: name},
+		}},
+		{secretsGVR, &corev1.Secret{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   BootstrapNamespace,
+				Name:        TokenSecretName(syncTarget),
+				Annotations: map[string]string{corev1.ServiceAccountNameKey: name},
+			},
+			Type: corev1.SecretTypeServiceAccountToken,
+		}},
+		{clusterRolesGVR, &rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Rules: []rbacv1.PolicyRule{{
+				APIGroups:     []string{tmcv1alpha1.SchemeGroupVersion.Group},
+				Resources:     []string{"synctargets", "synctargets/status"},
+				ResourceNames: []string{syncTarget},
+				Verbs:         []string{"get", "list", "watch", "update", "patch"},
+			}, {
+				// The heartbeat Lease is created by kcp, the syncer renews it.
+				APIGroups:     []string{coordinationv1.GroupName},
+				Resources:     []string{"leases"},
+				ResourceNames: []string{heartbeat.LeaseName(syncTarget)},
+				Verbs:         []string{"get", "update", "patch"},
+			}, {
+				APIGroups: []string{workloadv1alpha1.SchemeGroupVersion.Group},
+				Resources: []string{"syncconfigurations"},
+				Verbs:     []string{"get", "list", "watch"},
+			}},
+		}},
+		{clusterRoleBindingsGVR, &rbacv1.ClusterRoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: name},
+			Subjects:   subjects,
+		}},
+	}
+}
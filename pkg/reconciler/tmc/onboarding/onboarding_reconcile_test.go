@@ -0,0 +1,181 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onboarding
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func TestReconcile(t *testing.T) {
+	syncTarget := &tmcv1alpha1.SyncTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge-1"},
+		Spec: tmcv1alpha1.SyncTargetSpec{Registration: &tmcv1alpha1.SyncTargetRegistration{
+			PublicKey: "-----BEGIN PUBLIC KEY-----",
+		}},
+	}
+
+	created := map[string]runtime.Object{}
+	secrets := map[string]*corev1.Secret{}
+	updates := 0
+	c := &controller{
+		options: Options{
+			ExternalURL: func() string { return "https://kcp.example.com" },
+			SyncerImage: "ghcr.io/kcp-dev/kcp/syncer:v0.1.0",
+		},
+		updateSyncTargetStatus: func(_ context.Context, _ logicalcluster.Name, st *tmcv1alpha1.SyncTarget) error {
+			updates++
+			syncTarget = st
+			return nil
+		},
+		createIfMissing: func(_ context.Context, _ logicalcluster.Name, gvr schema.GroupVersionResource, obj runtime.Object) error {
+			if secret, ok := obj.(*corev1.Secret); ok {
+				if _, found := secrets[secret.Name]; !found {
+					secrets[secret.Name] = secret.DeepCopy()
+				}
+				return nil
+			}
+			created[gvr.Resource] = obj
+			return nil
+		},
+		getSecret: func(_ context.Context, _ logicalcluster.Name, namespace, name string) (*corev1.Secret, error) {
+			require.Equal(t, BootstrapNamespace, namespace)
+			if secret, found := secrets[name]; found {
+				return secret.DeepCopy(), nil
+			}
+			return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, name)
+		},
+		updateSecret: func(_ context.Context, _ logicalcluster.Name, secret *corev1.Secret) error {
+			secrets[secret.Name] = secret
+			return nil
+		},
+	}
+
+	require.NoError(t, c.reconcile(context.Background(), "abc", syncTarget))
+	require.Equal(t, tmcv1alpha1.WaitingForApprovalReason, conditions.GetReason(syncTarget, tmcv1alpha1.BootstrapReady))
+	require.Empty(t, created, "nothing is created before approval")
+
+	syncTarget.Spec.Registration.Approved = true
+	require.ErrorIs(t, c.reconcile(context.Background(), "abc", syncTarget), errTokenPending)
+	require.Equal(t, tmcv1alpha1.WaitingForTokenReason, conditions.GetReason(syncTarget, tmcv1alpha1.BootstrapReady))
+	require.Contains(t, created, "serviceaccounts")
+	require.Contains(t, created, "clusterroles")
+	require.Contains(t, created, "clusterrolebindings")
+	require.Equal(t, corev1.SecretTypeServiceAccountToken, secrets["kcp-syncer-edge-1-token"].Type)
+
+	secrets["kcp-syncer-edge-1-token"].Data = map[string][]byte{corev1.ServiceAccountTokenKey: []byte("s3cr3t")}
+	require.NoError(t, c.reconcile(context.Background(), "abc", syncTarget))
+	require.True(t, conditions.IsTrue(syncTarget, tmcv1alpha1.BootstrapReady))
+	manifests := secrets[BootstrapSecretName("edge-1")].Data[ManifestsKey]
+	require.NotEmpty(t, manifests)
+
+	updates = 0
+	require.NoError(t, c.reconcile(context.Background(), "abc", syncTarget))
+	require.Zero(t, updates, "nothing changed")
+}
+
+func TestRender(t *testing.T) {
+	syncTarget := &tmcv1alpha1.SyncTarget{ObjectMeta: metav1.ObjectMeta{Name: "edge-1"}}
+	manifests, err := Render(syncTarget, BootstrapConfig{
+		Server:      "https://kcp.example.com/clusters/abc",
+		Token:       "s3cr3t",
+		SyncerImage: "ghcr.io/kcp-dev/kcp/syncer:v0.1.0",
+		Cluster:     "abc",
+		CAData:      []byte("ca"),
+	})
+	require.NoError(t, err)
+
+	var kinds []string
+	var deployment appsv1.Deployment
+	var kubeconfigSecret corev1.Secret
+	for _, doc := range strings.Split(string(manifests), "---\n") {
+		var meta metav1.TypeMeta
+		require.NoError(t, yaml.Unmarshal([]byte(doc), &meta))
+		kinds = append(kinds, meta.Kind)
+		switch meta.Kind {
+		case "Deployment":
+			require.NoError(t, yaml.Unmarshal([]byte(doc), &deployment))
+		case "Secret":
+			require.NoError(t, yaml.Unmarshal([]byte(doc), &kubeconfigSecret))
+		}
+	}
+	require.Equal(t, []string{"Namespace", "ServiceAccount", "ClusterRole", "ClusterRoleBinding", "Role", "RoleBinding", "Secret", "Deployment"}, kinds)
+
+	container := deployment.Spec.Template.Spec.Containers[0]
+	require.Equal(t, "ghcr.io/kcp-dev/kcp/syncer:v0.1.0", container.Image)
+	require.Contains(t, container.Args, "--sync-targets=abc:edge-1")
+
+	kubeconfig, err := clientcmd.Load(kubeconfigSecret.Data[kubeconfigKey])
+	require.NoError(t, err)
+	require.Equal(t, "https://kcp.example.com/clusters/abc", kubeconfig.Clusters["kcp"].Server)
+	require.Equal(t, "s3cr3t", kubeconfig.AuthInfos["syncer"].Token)
+	require.Equal(t, []byte("ca"), kubeconfig.Clusters["kcp"].CertificateAuthorityData)
+}
+
+func TestRenderResourceConfig(t *testing.T) {
+	syncTarget := &tmcv1alpha1.SyncTarget{ObjectMeta: metav1.ObjectMeta{Name: "edge-1"}}
+	resourceConfig := []byte("resources:\n- group: apps\n  version: v1\n  resource: deployments\n")
+	manifests, err := Render(syncTarget, BootstrapConfig{
+		Server:         "https://kcp.example.com/clusters/abc",
+		Token:          "s3cr3t",
+		SyncerImage:    "ghcr.io/kcp-dev/kcp/syncer:v0.1.0",
+		Cluster:        "abc",
+		ResourceConfig: resourceConfig,
+		FeatureGates:   map[string]bool{"TMCPlacement": true, "TMCFeature": true},
+	})
+	require.NoError(t, err)
+
+	var deployment appsv1.Deployment
+	var configMap corev1.ConfigMap
+	for _, doc := range strings.Split(string(manifests), "---\n") {
+		var meta metav1.TypeMeta
+		require.NoError(t, yaml.Unmarshal([]byte(doc), &meta))
+		switch meta.Kind {
+		case "Deployment":
+			require.NoError(t, yaml.Unmarshal([]byte(doc), &deployment))
+		case "ConfigMap":
+			require.NoError(t, yaml.Unmarshal([]byte(doc), &configMap))
+		}
+	}
+	require.Equal(t, string(resourceConfig), configMap.Data[resourceConfigKey])
+	require.Contains(t, string(manifests), "deployments", "the RBAC covers the synced resources")
+
+	container := deployment.Spec.Template.Spec.Containers[0]
+	require.Contains(t, container.Args, "--resource-config="+resourceConfigPath+"/"+resourceConfigKey)
+	require.Contains(t, container.Args, "--feature-gates=TMCFeature=true,TMCPlacement=true")
+	require.Len(t, deployment.Spec.Template.Spec.Volumes, 2)
+
+	_, err = Render(syncTarget, BootstrapConfig{ResourceConfig: []byte("resources:\n- version: v1\n  resource: services\n  priority: urgent\n")})
+	require.ErrorContains(t, err, "invalid resource configuration")
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/placement/composite"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// reconcileAdvanced updates the status of a WorkloadPlacementAdvanced with
+// the workloads each of its rules places.
+func (c *controller) reconcileAdvanced(ctx context.Context, clusterName logicalcluster.Name, name string) error {
+	advanced, err := c.getAdvanced(clusterName, name)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !advanced.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	distributions, err := c.listDistributions(clusterName, metav1.NamespaceAll)
+	if err != nil {
+		return err
+	}
+	var referencing []*workloadv1alpha1.WorkloadDistribution
+	for _, d := range distributions {
+		if d.Spec.PolicyRef.IsAdvanced() && d.Spec.PolicyRef.Name == name {
+			referencing = append(referencing, d)
+		}
+	}
+
+	a := advanced.DeepCopy()
+	rules := composite.Compile(&a.Spec)
+	a.Status.Rules, a.Status.Unmatched = rules.Assign(referencing)
+	if len(rules.Invalid) > 0 {
+		invalid := make([]string, 0, len(rules.Invalid))
+		for name, err := range rules.Invalid {
+			invalid = append(invalid, name+": "+err.Error())
+		}
+		sort.Strings(invalid)
+		conditions.MarkFalse(a, placementv1alpha1.RulesValid, placementv1alpha1.InvalidWorkloadSelectorReason, conditionsv1alpha1.ConditionSeverityError,
+			"Rules with invalid workload selectors select no workloads: %s", strings.Join(invalid, "; "))
+	} else {
+		conditions.MarkTrue(a, placementv1alpha1.RulesValid)
+	}
+
+	if equality.Semantic.DeepEqual(advanced.Status, a.Status) {
+		return nil
+	}
+	klog.FromContext(ctx).V(2).Info("updating WorkloadPlacementAdvanced status", "rules", len(a.Status.Rules), "unmatched", a.Status.Unmatched)
+	return c.updateAdvancedStatus(ctx, clusterName, a)
+}
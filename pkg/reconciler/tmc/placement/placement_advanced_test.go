@@ -0,0 +1,87 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kcp-dev/kcp/pkg/placement/revision"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+func (f *fixture) addAdvanced(name, tier string) *workloadv1alpha1.WorkloadDistribution {
+	d := f.add(name)
+	d.Labels = map[string]string{"tier": tier}
+	d.Spec.PolicyRef = workloadv1alpha1.PolicyReference{Kind: workloadv1alpha1.WorkloadPlacementAdvancedKind, Name: "composite"}
+	return d
+}
+
+func TestReconcileAdvanced(t *testing.T) {
+	f := newFixture()
+	singleton := placementv1alpha1.PlacementPolicySpec{
+		Strategy:         placementv1alpha1.PlacementStrategySingleton,
+		LocationSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"region": "eu"}},
+	}
+	f.syncTargets[0].Labels = map[string]string{"region": "eu"}
+	f.advanced["composite"] = &placementv1alpha1.WorkloadPlacementAdvanced{
+		ObjectMeta: metav1.ObjectMeta{Name: "composite"},
+		Spec: placementv1alpha1.WorkloadPlacementAdvancedSpec{Rules: []placementv1alpha1.PlacementRule{
+			{Name: "web", WorkloadSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "web"}}},
+			{Name: "batch", Priority: 10, WorkloadSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "batch"}}, Placement: singleton},
+		}},
+	}
+	f.addAdvanced("frontend", "web")
+	f.addAdvanced("job", "batch")
+	f.addAdvanced("cache", "cache")
+	f.add("other")
+
+	f.reconcile(t, "frontend")
+	frontend := f.distributions["frontend"]
+	require.True(t, conditions.IsTrue(frontend, workloadv1alpha1.WorkloadPlaced))
+	require.Len(t, frontend.Status.Targets, 2)
+	require.Equal(t, revision.Name("composite-web", placementv1alpha1.PlacementPolicySpec{}), frontend.Status.PolicyRevision)
+
+	f.reconcile(t, "job")
+	job := f.distributions["job"]
+	require.True(t, conditions.IsTrue(job, workloadv1alpha1.WorkloadPlaced))
+	require.Equal(t, []workloadv1alpha1.TargetPlacement{{SyncTarget: "eu-1", Location: "eu"}}, job.Status.Targets)
+
+	f.reconcile(t, "cache")
+	cache := f.distributions["cache"]
+	require.Equal(t, workloadv1alpha1.NoMatchingRuleReason, conditions.GetReason(cache, workloadv1alpha1.WorkloadPlaced))
+	require.Empty(t, cache.Status.Targets)
+
+	require.NoError(t, f.controller().reconcileAdvanced(context.Background(), "root:org", "composite"))
+	status := f.advanced["composite"].Status
+	require.Equal(t, []placementv1alpha1.PlacementRuleStatus{
+		{Name: "web", Matched: 1, Workloads: []string{"default/frontend"}},
+		{Name: "batch", Matched: 1, Workloads: []string{"default/job"}},
+	}, status.Rules)
+	require.Equal(t, int32(1), status.Unmatched, "workloads of other policies are not counted")
+	require.True(t, conditions.IsTrue(f.advanced["composite"], placementv1alpha1.RulesValid))
+
+	delete(f.advanced, "composite")
+	f.reconcile(t, "frontend")
+	require.Equal(t, workloadv1alpha1.PolicyNotFoundReason, conditions.GetReason(f.distributions["frontend"], workloadv1alpha1.WorkloadPlaced))
+}
@@ -0,0 +1,698 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	kcpcache "github.com/kcp-dev/apimachinery/v2/pkg/cache"
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/placement/capacity"
+	"github.com/kcp-dev/kcp/pkg/placement/carbon"
+	"github.com/kcp-dev/kcp/pkg/placement/checkpoint"
+	"github.com/kcp-dev/kcp/pkg/placement/decision"
+	"github.com/kcp-dev/kcp/pkg/placement/engine"
+	"github.com/kcp-dev/kcp/pkg/placement/fairqueue"
+	"github.com/kcp-dev/kcp/pkg/placement/reference"
+	"github.com/kcp-dev/kcp/pkg/placement/sharding"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/policyrollout"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/tmcinformers"
+	"github.com/kcp-dev/kcp/pkg/tmc/credentials"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+const (
+	ControllerName = "kcp-tmc-placement"
+
+	// UnfrozenReason is the reason of events about placement resuming after
+	// a freeze.
+	UnfrozenReason = "Unfrozen"
+
+	// EmissionsReason is the reason of events estimating the emissions of
+	// newly placed workloads.
+	EmissionsReason = "Emissions"
+
+	// CheckpointName is the name of the ConfigMap the placement state is
+	// handed over between replicas in.
+	CheckpointName = "kcp-tmc-placement-state"
+
+	// capacityCacheTTL is how long answers of capacity providers are
+	// reused.
+	capacityCacheTTL = 30 * time.Second
+
+	// carbonCacheTTL is how long carbon intensities of grid regions are
+	// reused.
+	carbonCacheTTL = 5 * time.Minute
+)
+
+var (
+	// SchedulingProfilesGVR is the resource tuning placement per workspace.
+	SchedulingProfilesGVR = placementv1alpha1.SchemeGroupVersion.WithResource("schedulingprofiles")
+	// SyncTargetGroupsGVR is the resource grouping SyncTargets with weights.
+	SyncTargetGroupsGVR = tmcv1alpha1.SchemeGroupVersion.WithResource("synctargetgroups")
+	// DataLocationsGVR is the resource hinting where datasets are present.
+	DataLocationsGVR = placementv1alpha1.SchemeGroupVersion.WithResource("datalocations")
+	// WorkloadPlacementAdvancedGVR is the resource of composite policies.
+	WorkloadPlacementAdvancedGVR = placementv1alpha1.SchemeGroupVersion.WithResource("workloadplacementadvanceds")
+
+	eventsGVR = schema.GroupVersionResource{Version: "v1", Resource: "events"}
+)
+
+// bySyncTarget indexes distributions by the SyncTargets they are placed on.
+const bySyncTarget = "bySyncTarget"
+
+// NewController returns a controller that places WorkloadDistributions onto
+// SyncTargets according to their PlacementPolicy, or the matching rule of
+// their WorkloadPlacementAdvanced, once the distributions they depend on are
+// ready. It also reports the workloads each WorkloadPlacementAdvanced rule
+// places. While placement is frozen in a workspace, the
+// distributions keep their SyncTargets, and events record who froze it and
+// why. Workspaces share the queue according to queueOptions, so that one
+// workspace flooding it does not starve the others. SyncTargets not
+// publishing their carbon intensity get it from carbonProvider, unless nil.
+// Placing onto more SyncTargets must fit the MultiClusterResourceQuotas of
+// the workspace. If sharder is not nil, the controller only places in the
+// workspaces the sharder assigns to this replica. If recorder is not nil, the
+// decisions are recorded with it.
+func NewController(
+	queueOptions fairqueue.Options,
+	carbonProvider carbon.Provider,
+	sharder *sharding.Sharder,
+	recorder *decision.Recorder,
+	distributionClusterInformer *tmcinformers.Informer,
+	policyClusterInformer *tmcinformers.Informer,
+	revisionClusterInformer *tmcinformers.Informer,
+	syncTargetClusterInformer *tmcinformers.Informer,
+	profileClusterInformer *tmcinformers.Informer,
+	syncTargetGroupClusterInformer *tmcinformers.Informer,
+	dataLocationClusterInformer *tmcinformers.Informer,
+	advancedClusterInformer *tmcinformers.Informer,
+	quotaClusterInformer *tmcinformers.Informer,
+	dynamicClusterClient kcpdynamic.ClusterInterface,
+) (*controller, error) {
+	resolver := reference.NewResolver()
+	resolver.AddInformer(policyrollout.PlacementPoliciesGVR.GroupResource(), policyClusterInformer)
+	resolver.AddInformer(policyrollout.PlacementPolicyRevisionsGVR.GroupResource(), revisionClusterInformer)
+	resolver.AddInformer(SyncTargetGroupsGVR.GroupResource(), syncTargetGroupClusterInformer)
+	resolver.AddInformer(DataLocationsGVR.GroupResource(), dataLocationClusterInformer)
+	resolver.AddInformer(WorkloadPlacementAdvancedGVR.GroupResource(), advancedClusterInformer)
+
+	c := &controller{
+		queue: fairqueue.NewRateLimitingQueue(ControllerName, func(key string) fairqueue.Flow {
+			return flowOf(distributionClusterInformer, key)
+		}, queueOptions),
+		now:      time.Now,
+		engine:   engine.NewEngine(),
+		capacity: capacity.NewCachingResolver(capacity.NewWebhookClient(credentials.NewResolver(credentials.DynamicSecretGetter(dynamicClusterClient))).Query, capacityCacheTTL),
+		getDistribution: func(clusterName logicalcluster.Name, namespace, name string) (*workloadv1alpha1.WorkloadDistribution, error) {
+			obj, err := distributionClusterInformer.Lister(clusterName).ByNamespace(namespace).Get(name)
+			if err != nil {
+				return nil, err
+			}
+			distribution := &workloadv1alpha1.WorkloadDistribution{}
+			return distribution, fromUnstructured(obj, distribution)
+		},
+		listDistributions: func(clusterName logicalcluster.Name, namespace string) ([]*workloadv1alpha1.WorkloadDistribution, error) {
+			var objs []runtime.Object
+			var err error
+			if namespace == metav1.NamespaceAll {
+				objs, err = distributionClusterInformer.Lister(clusterName).List(labels.Everything())
+			} else {
+				objs, err = distributionClusterInformer.Lister(clusterName).ByNamespace(namespace).List(labels.Everything())
+			}
+			if err != nil {
+				return nil, err
+			}
+			distributions := make([]*workloadv1alpha1.WorkloadDistribution, 0, len(objs))
+			for _, obj := range objs {
+				distribution := &workloadv1alpha1.WorkloadDistribution{}
+				if err := fromUnstructured(obj, distribution); err != nil {
+					return nil, err
+				}
+				distributions = append(distributions, distribution)
+			}
+			return distributions, nil
+		},
+		listPlacedOn: func(clusterName logicalcluster.Name, syncTarget string) ([]*workloadv1alpha1.WorkloadDistribution, error) {
+			objs, err := distributionClusterInformer.ByIndex(bySyncTarget, syncTargetKey(clusterName, syncTarget))
+			if err != nil {
+				return nil, err
+			}
+			distributions := make([]*workloadv1alpha1.WorkloadDistribution, 0, len(objs))
+			for _, obj := range objs {
+				u, ok := obj.(*unstructured.Unstructured)
+				if !ok {
+					return nil, fmt.Errorf("expected *unstructured.Unstructured, got %T", obj)
+				}
+				distribution := &workloadv1alpha1.WorkloadDistribution{}
+				if err := fromUnstructured(u, distribution); err != nil {
+					return nil, err
+				}
+				distributions = append(distributions, distribution)
+			}
+			return distributions, nil
+		},
+		getPolicy: func(clusterName logicalcluster.Name, name string) (*placementv1alpha1.PlacementPolicy, error) {
+			return reference.Get[placementv1alpha1.PlacementPolicy](resolver, clusterName, reference.Reference{Resource: policyrollout.PlacementPoliciesGVR.GroupResource(), Name: name})
+		},
+		getRevision: func(clusterName logicalcluster.Name, name string) (*placementv1alpha1.PlacementPolicyRevision, error) {
+			return reference.Get[placementv1alpha1.PlacementPolicyRevision](resolver, clusterName, reference.Reference{Resource: policyrollout.PlacementPolicyRevisionsGVR.GroupResource(), Name: name})
+		},
+		getAdvanced: func(clusterName logicalcluster.Name, name string) (*placementv1alpha1.WorkloadPlacementAdvanced, error) {
+			return reference.Get[placementv1alpha1.WorkloadPlacementAdvanced](resolver, clusterName, reference.Reference{Resource: WorkloadPlacementAdvancedGVR.GroupResource(), Name: name})
+		},
+		getProfile: func(clusterName logicalcluster.Name) (*placementv1alpha1.SchedulingProfileSpec, error) {
+			obj, err := profileClusterInformer.Lister(clusterName).Get(placementv1alpha1.DefaultSchedulingProfileName)
+			if errors.IsNotFound(err) {
+				return nil, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			profile := &placementv1alpha1.SchedulingProfile{}
+			return &profile.Spec, fromUnstructured(obj, profile)
+		},
+		getSyncTargetGroup: func(clusterName logicalcluster.Name, name string) (*tmcv1alpha1.SyncTargetGroup, error) {
+			return reference.Get[tmcv1alpha1.SyncTargetGroup](resolver, clusterName, reference.Reference{Resource: SyncTargetGroupsGVR.GroupResource(), Name: name})
+		},
+		getDataLocation: func(clusterName logicalcluster.Name, name string) (*placementv1alpha1.DataLocation, error) {
+			return reference.Get[placementv1alpha1.DataLocation](resolver, clusterName, reference.Reference{Resource: DataLocationsGVR.GroupResource(), Name: name})
+		},
+		listSyncTargets: func(clusterName logicalcluster.Name) ([]*tmcv1alpha1.SyncTarget, error) {
+			objs, err := syncTargetClusterInformer.Lister(clusterName).List(labels.Everything())
+			if err != nil {
+				return nil, err
+			}
+			syncTargets := make([]*tmcv1alpha1.SyncTarget, 0, len(objs))
+			for _, obj := range objs {
+				syncTarget := &tmcv1alpha1.SyncTarget{}
+				if err := fromUnstructured(obj, syncTarget); err != nil {
+					return nil, err
+				}
+				syncTargets = append(syncTargets, syncTarget)
+			}
+			return syncTargets, nil
+		},
+		listQuotas: func(clusterName logicalcluster.Name) ([]*tmcv1alpha1.MultiClusterResourceQuota, error) {
+			objs, err := quotaClusterInformer.Lister(clusterName).List(labels.Everything())
+			if err != nil {
+				return nil, err
+			}
+			quotas := make([]*tmcv1alpha1.MultiClusterResourceQuota, 0, len(objs))
+			for _, obj := range objs {
+				resourceQuota := &tmcv1alpha1.MultiClusterResourceQuota{}
+				if err := fromUnstructured(obj, resourceQuota); err != nil {
+					return nil, err
+				}
+				quotas = append(quotas, resourceQuota)
+			}
+			return quotas, nil
+		},
+		updateDistributionStatus: func(ctx context.Context, clusterName logicalcluster.Name, distribution *workloadv1alpha1.WorkloadDistribution) error {
+			u, err := toUnstructured(distribution)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(policyrollout.WorkloadDistributionsGVR).Namespace(distribution.Namespace).UpdateStatus(ctx, u, metav1.UpdateOptions{})
+			return err
+		},
+		updateAdvancedStatus: func(ctx context.Context, clusterName logicalcluster.Name, advanced *placementv1alpha1.WorkloadPlacementAdvanced) error {
+			u, err := toUnstructured(advanced)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(WorkloadPlacementAdvancedGVR).UpdateStatus(ctx, u, metav1.UpdateOptions{})
+			return err
+		},
+		createEvent: func(ctx context.Context, clusterName logicalcluster.Name, event *corev1.Event) error {
+			u, err := toUnstructured(event)
+			if err != nil {
+				return err
+			}
+			u.SetAPIVersion("v1")
+			u.SetKind("Event")
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(eventsGVR).Namespace(event.Namespace).Create(ctx, u, metav1.CreateOptions{})
+			return err
+		},
+	}
+
+	distributionClusterInformer.AddIndexers(cache.Indexers{bySyncTarget: indexBySyncTarget})
+	distributionClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) { c.enqueueWithDependents(distributionClusterInformer, obj) },
+		UpdateFunc: func(oldObj, obj interface{}) {
+			c.enqueueWithDependents(distributionClusterInformer, obj)
+			// Workloads waiting for workload affinity may fit now.
+			if placementChanged(oldObj, obj) {
+				c.enqueueMatching(distributionClusterInformer, obj, unplaced)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.enqueueWithDependents(distributionClusterInformer, obj)
+			c.enqueueMatching(distributionClusterInformer, obj, unplaced)
+		},
+	})
+	policyClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueueMatching(distributionClusterInformer, obj, byPolicy) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueueMatching(distributionClusterInformer, obj, byPolicy) },
+		DeleteFunc: func(obj interface{}) { c.enqueueMatching(distributionClusterInformer, obj, byPolicy) },
+	})
+	syncTargetClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueueMatching(distributionClusterInformer, obj, all) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueueMatching(distributionClusterInformer, obj, all) },
+		DeleteFunc: func(obj interface{}) { c.enqueueMatching(distributionClusterInformer, obj, all) },
+	})
+	profileClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueueMatching(distributionClusterInformer, obj, byDefaultProfile) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueueMatching(distributionClusterInformer, obj, byDefaultProfile) },
+		DeleteFunc: func(obj interface{}) { c.enqueueMatching(distributionClusterInformer, obj, byDefaultProfile) },
+	})
+	syncTargetGroupClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueueMatching(distributionClusterInformer, obj, all) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueueMatching(distributionClusterInformer, obj, all) },
+		DeleteFunc: func(obj interface{}) { c.enqueueMatching(distributionClusterInformer, obj, all) },
+	})
+	dataLocationClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueueMatching(distributionClusterInformer, obj, all) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueueMatching(distributionClusterInformer, obj, all) },
+		DeleteFunc: func(obj interface{}) { c.enqueueMatching(distributionClusterInformer, obj, all) },
+	})
+	quotaClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueueMatching(distributionClusterInformer, obj, quotaExceeded) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueueMatching(distributionClusterInformer, obj, quotaExceeded) },
+		DeleteFunc: func(obj interface{}) { c.enqueueMatching(distributionClusterInformer, obj, quotaExceeded) },
+	})
+	advancedClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueue(obj)
+			c.enqueueMatching(distributionClusterInformer, obj, byAdvanced)
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			c.enqueue(obj)
+			c.enqueueMatching(distributionClusterInformer, obj, byAdvanced)
+		},
+		DeleteFunc: func(obj interface{}) { c.enqueueMatching(distributionClusterInformer, obj, byAdvanced) },
+	})
+
+	if carbonProvider != nil {
+		c.carbon = carbon.NewResolver(carbonProvider, carbonCacheTTL)
+	}
+	if recorder != nil {
+		c.record = recorder.Record
+	}
+	if sharder != nil {
+		c.owns = func(clusterName logicalcluster.Name) bool { return sharder.Owns(clusterName.String()) }
+		// Place in the workspaces this replica took over.
+		sharder.AddHandler(func() {
+			objs, err := distributionClusterInformer.List(labels.Everything())
+			if err != nil {
+				utilruntime.HandleError(err)
+				return
+			}
+			for _, obj := range objs {
+				if u, ok := obj.(*unstructured.Unstructured); ok {
+					c.enqueue(u)
+					c.enqueueAdvanced(u)
+				}
+			}
+		})
+	}
+
+	return c, nil
+}
+
+// controller places WorkloadDistributions.
+type controller struct {
+	queue workqueue.TypedRateLimitingInterface[string]
+
+	now      func() time.Time
+	engine   *engine.Engine
+	capacity *capacity.Resolver
+	// carbon resolves the carbon intensity of SyncTargets, if configured.
+	carbon *carbon.Resolver
+	// owns returns whether this replica places in a workspace, if the
+	// controller is sharded.
+	owns func(clusterName logicalcluster.Name) bool
+	// record records placement decisions, if configured.
+	record func(record *decision.DecisionRecord)
+
+	getDistribution          func(clusterName logicalcluster.Name, namespace, name string) (*workloadv1alpha1.WorkloadDistribution, error)
+	listDistributions        func(clusterName logicalcluster.Name, namespace string) ([]*workloadv1alpha1.WorkloadDistribution, error)
+	listPlacedOn             func(clusterName logicalcluster.Name, syncTarget string) ([]*workloadv1alpha1.WorkloadDistribution, error)
+	getPolicy                func(clusterName logicalcluster.Name, name string) (*placementv1alpha1.PlacementPolicy, error)
+	getRevision              func(clusterName logicalcluster.Name, name string) (*placementv1alpha1.PlacementPolicyRevision, error)
+	getAdvanced              func(clusterName logicalcluster.Name, name string) (*placementv1alpha1.WorkloadPlacementAdvanced, error)
+	getProfile               func(clusterName logicalcluster.Name) (*placementv1alpha1.SchedulingProfileSpec, error)
+	getSyncTargetGroup       func(clusterName logicalcluster.Name, name string) (*tmcv1alpha1.SyncTargetGroup, error)
+	getDataLocation          func(clusterName logicalcluster.Name, name string) (*placementv1alpha1.DataLocation, error)
+	listSyncTargets          func(clusterName logicalcluster.Name) ([]*tmcv1alpha1.SyncTarget, error)
+	listQuotas               func(clusterName logicalcluster.Name) ([]*tmcv1alpha1.MultiClusterResourceQuota, error)
+	updateDistributionStatus func(ctx context.Context, clusterName logicalcluster.Name, distribution *workloadv1alpha1.WorkloadDistribution) error
+	updateAdvancedStatus     func(ctx context.Context, clusterName logicalcluster.Name, advanced *placementv1alpha1.WorkloadPlacementAdvanced) error
+	createEvent              func(ctx context.Context, clusterName logicalcluster.Name, event *corev1.Event) error
+}
+
+// flowOf returns the workspace and placement policy of the distribution
+// or WorkloadPlacementAdvanced with the given key, for fair queuing.
+func flowOf(distributionClusterInformer *tmcinformers.Informer, key string) fairqueue.Flow {
+	clusterName, namespace, name, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		return fairqueue.Flow{}
+	}
+	flow := fairqueue.Flow{Workspace: clusterName.String()}
+	if namespace == "" {
+		flow.Policy = name
+		return flow
+	}
+	obj, err := distributionClusterInformer.Lister(clusterName).ByNamespace(namespace).Get(name)
+	if err != nil {
+		return flow
+	}
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		flow.Policy, _, _ = unstructured.NestedString(u.Object, "spec", "policyRef", "name")
+	}
+	return flow
+}
+
+func (c *controller) enqueue(obj interface{}) {
+	key, err := kcpcache.DeletionHandlingMetaClusterNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	if !c.ownsKey(key) {
+		return
+	}
+
+	logger := logging.WithQueueKey(logging.WithReconciler(klog.Background(), ControllerName), key)
+	logger.V(4).Info("queueing object")
+	c.queue.Add(key)
+}
+
+// enqueueAdvanced enqueues the WorkloadPlacementAdvanced referenced by a
+// distribution, if any, to update the workloads listed in its status.
+func (c *controller) enqueueAdvanced(distribution *unstructured.Unstructured) {
+	kind, _, _ := unstructured.NestedString(distribution.Object, "spec", "policyRef", "kind")
+	name, _, _ := unstructured.NestedString(distribution.Object, "spec", "policyRef", "name")
+	if kind != workloadv1alpha1.WorkloadPlacementAdvancedKind || name == "" {
+		return
+	}
+	if c.owns != nil && !c.owns(logicalcluster.From(distribution)) {
+		return
+	}
+	key := kcpcache.ToClusterAwareKey(logicalcluster.From(distribution).String(), "", name)
+	logger := logging.WithQueueKey(logging.WithReconciler(klog.Background(), ControllerName), key)
+	logger.V(4).Info("queueing WorkloadPlacementAdvanced because of WorkloadDistribution", "namespace", distribution.GetNamespace(), "name", distribution.GetName())
+	c.queue.Add(key)
+}
+
+// ownsKey returns whether this replica places the object with the given
+// key.
+func (c *controller) ownsKey(key string) bool {
+	if c.owns == nil {
+		return true
+	}
+	clusterName, _, _, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	return err == nil && c.owns(clusterName)
+}
+
+// enqueueWithDependents enqueues a distribution and the distributions in its
+// namespace that depend on it.
+func (c *controller) enqueueWithDependents(distributionClusterInformer *tmcinformers.Informer, obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		utilruntime.HandleError(fmt.Errorf("expected *unstructured.Unstructured, got %T", obj))
+		return
+	}
+	c.enqueue(u)
+	c.enqueueAdvanced(u)
+
+	objs, err := distributionClusterInformer.Lister(logicalcluster.From(u)).ByNamespace(u.GetNamespace()).List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, other := range objs {
+		otherU, ok := other.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		deps, _, _ := unstructured.NestedSlice(otherU.Object, "spec", "dependsOn")
+		for _, dep := range deps {
+			if m, ok := dep.(map[string]interface{}); ok && m["name"] == u.GetName() {
+				c.enqueue(otherU)
+				break
+			}
+		}
+	}
+}
+
+// enqueueMatching enqueues the distributions in the logical cluster of obj
+// that match.
+func (c *controller) enqueueMatching(distributionClusterInformer *tmcinformers.Informer, obj interface{}, match func(obj, distribution *unstructured.Unstructured) bool) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		utilruntime.HandleError(fmt.Errorf("expected *unstructured.Unstructured, got %T", obj))
+		return
+	}
+
+	objs, err := distributionClusterInformer.Lister(logicalcluster.From(u)).List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, other := range objs {
+		if otherU, ok := other.(*unstructured.Unstructured); ok && match(u, otherU) {
+			c.enqueue(otherU)
+		}
+	}
+}
+
+func byPolicy(policy, distribution *unstructured.Unstructured) bool {
+	kind, _, _ := unstructured.NestedString(distribution.Object, "spec", "policyRef", "kind")
+	name, _, _ := unstructured.NestedString(distribution.Object, "spec", "policyRef", "name")
+	return kind != workloadv1alpha1.WorkloadPlacementAdvancedKind && name == policy.GetName()
+}
+
+func byAdvanced(advanced, distribution *unstructured.Unstructured) bool {
+	kind, _, _ := unstructured.NestedString(distribution.Object, "spec", "policyRef", "kind")
+	name, _, _ := unstructured.NestedString(distribution.Object, "spec", "policyRef", "name")
+	return kind == workloadv1alpha1.WorkloadPlacementAdvancedKind && name == advanced.GetName()
+}
+
+// byDefaultProfile matches all distributions if the profile is the one used
+// by the workspace.
+func byDefaultProfile(profile, _ *unstructured.Unstructured) bool {
+	return profile.GetName() == placementv1alpha1.DefaultSchedulingProfileName
+}
+
+func all(_, _ *unstructured.Unstructured) bool {
+	return true
+}
+
+// unplaced matches distributions not placed on any SyncTarget, which may
+// wait for other workloads to be placed to satisfy their workload affinity.
+func unplaced(_, distribution *unstructured.Unstructured) bool {
+	targets, _, _ := unstructured.NestedSlice(distribution.Object, "status", "targets")
+	return len(targets) == 0
+}
+
+// quotaExceeded matches distributions that are not placed onto more
+// SyncTargets because of MultiClusterResourceQuotas, which may fit now.
+func quotaExceeded(_, distribution *unstructured.Unstructured) bool {
+	conds, _, _ := unstructured.NestedSlice(distribution.Object, "status", "conditions")
+	for _, cond := range conds {
+		m, ok := cond.(map[string]interface{})
+		if !ok || m["type"] != string(workloadv1alpha1.WorkloadPlaced) {
+			continue
+		}
+		return m["reason"] == workloadv1alpha1.QuotaExceededReason || m["reason"] == workloadv1alpha1.WaitingForResourceUsageReason
+	}
+	return false
+}
+
+// placementChanged returns whether the SyncTargets of a distribution changed.
+func placementChanged(oldObj, obj interface{}) bool {
+	oldU, ok := oldObj.(*unstructured.Unstructured)
+	if !ok {
+		return true
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return true
+	}
+	oldTargets, _, _ := unstructured.NestedSlice(oldU.Object, "status", "targets")
+	targets, _, _ := unstructured.NestedSlice(u.Object, "status", "targets")
+	return !equality.Semantic.DeepEqual(oldTargets, targets)
+}
+
+func syncTargetKey(clusterName logicalcluster.Name, syncTarget string) string {
+	return clusterName.String() + "|" + syncTarget
+}
+
+// indexBySyncTarget indexes distributions by the SyncTargets they are
+// placed on, to evaluate workload affinity.
+func indexBySyncTarget(obj interface{}) ([]string, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("expected *unstructured.Unstructured, got %T", obj)
+	}
+	targets, _, err := unstructured.NestedSlice(u.Object, "status", "targets")
+	if err != nil {
+		return nil, nil
+	}
+	keys := make([]string, 0, len(targets))
+	for _, t := range targets {
+		if m, ok := t.(map[string]interface{}); ok {
+			if name, ok := m["syncTarget"].(string); ok && name != "" {
+				keys = append(keys, syncTargetKey(logicalcluster.From(u), name))
+			}
+		}
+	}
+	return keys, nil
+}
+
+// Snapshot adds the in-memory state of the controller to a checkpoint.
+func (c *controller) Snapshot(state *checkpoint.State) {
+	state.Capacity = c.capacity.Snapshot()
+}
+
+// Restore restores the in-memory state of the controller from a checkpoint.
+func (c *controller) Restore(state *checkpoint.State) {
+	restored := c.capacity.Restore(state.Capacity)
+	logging.WithReconciler(klog.Background(), ControllerName).V(2).Info("restored capacity provider answers", "count", restored, "total", len(state.Capacity))
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	logger := logging.WithReconciler(klog.FromContext(ctx), ControllerName)
+	ctx = klog.NewContext(ctx, logger)
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	for range numThreads {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+
+	logger := logging.WithQueueKey(klog.FromContext(ctx), key)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(4).Info("processing key")
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	requeueAfter, err := c.process(ctx, key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", ControllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	if requeueAfter > 0 {
+		c.queue.AddAfter(key, requeueAfter)
+	}
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) (time.Duration, error) {
+	clusterName, namespace, name, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return 0, nil
+	}
+	if c.owns != nil && !c.owns(clusterName) {
+		// The workspace moved to another replica since it was queued.
+		return 0, nil
+	}
+	if namespace == "" {
+		return 0, c.reconcileAdvanced(ctx, clusterName, name)
+	}
+
+	distribution, err := c.getDistribution(clusterName, namespace, name)
+	if errors.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !distribution.DeletionTimestamp.IsZero() {
+		return 0, nil
+	}
+
+	return c.reconcile(ctx, clusterName, distribution)
+}
+
+func fromUnstructured(obj runtime.Object, into interface{}) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("expected *unstructured.Unstructured, got %T", obj)
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, into)
+}
+
+func toUnstructured(obj interface{}) (*unstructured.Unstructured, error) {
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: raw}, nil
+}
@@ -0,0 +1,617 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/placement/capacity"
+	"github.com/kcp-dev/kcp/pkg/placement/composite"
+	placementdecision "github.com/kcp-dev/kcp/pkg/placement/decision"
+	"github.com/kcp-dev/kcp/pkg/placement/dependency"
+	"github.com/kcp-dev/kcp/pkg/placement/engine"
+	"github.com/kcp-dev/kcp/pkg/placement/framework"
+	"github.com/kcp-dev/kcp/pkg/placement/quota"
+	"github.com/kcp-dev/kcp/pkg/placement/revision"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+func (c *controller) reconcile(ctx context.Context, clusterName logicalcluster.Name, distribution *workloadv1alpha1.WorkloadDistribution) (time.Duration, error) {
+	logger := klog.FromContext(ctx)
+
+	profile, err := c.getProfile(clusterName)
+	if err != nil {
+		return 0, err
+	}
+
+	d := distribution.DeepCopy()
+	wasFrozen := conditions.IsTrue(d, workloadv1alpha1.PlacementFrozen)
+	var requeueAfter time.Duration
+	var event *corev1.Event
+	if freeze := frozen(profile); freeze.IsActive(c.now()) {
+		message := freezeMessage(freeze)
+		conditions.Set(d, &conditionsv1alpha1.Condition{
+			Type:     workloadv1alpha1.PlacementFrozen,
+			Status:   corev1.ConditionTrue,
+			Severity: conditionsv1alpha1.ConditionSeverityInfo,
+			Reason:   workloadv1alpha1.FrozenReason,
+			Message:  message,
+		})
+		if !wasFrozen {
+			event = c.newEvent(d, corev1.EventTypeWarning, workloadv1alpha1.FrozenReason, message+". The WorkloadDistribution keeps its SyncTargets.")
+		}
+		if freeze.Until != nil {
+			requeueAfter = freeze.Until.Sub(c.now())
+		}
+	} else {
+		if wasFrozen {
+			conditions.Delete(d, workloadv1alpha1.PlacementFrozen)
+			event = c.newEvent(d, corev1.EventTypeNormal, UnfrozenReason, "Placement is no longer frozen.")
+		}
+		var placedEvent *corev1.Event
+		if requeueAfter, placedEvent, err = c.place(ctx, clusterName, profile, d); err != nil {
+			return 0, err
+		}
+		if event == nil {
+			event = placedEvent
+		}
+	}
+
+	if equality.Semantic.DeepEqual(distribution.Status, d.Status) {
+		return requeueAfter, nil
+	}
+	logger.V(2).Info("updating WorkloadDistribution status", "targets", len(d.Status.Targets), "policyRevision", d.Status.PolicyRevision)
+	if err := c.updateDistributionStatus(ctx, clusterName, d); err != nil {
+		return 0, err
+	}
+	if event != nil {
+		// Events are best effort, the condition is the source of truth.
+		if err := c.createEvent(ctx, clusterName, event); err != nil {
+			logger.Error(err, "failed to create event", "reason", event.Reason)
+		}
+	}
+	return requeueAfter, nil
+}
+
+func frozen(profile *placementv1alpha1.SchedulingProfileSpec) *placementv1alpha1.PlacementFreeze {
+	if profile == nil {
+		return nil
+	}
+	return profile.Freeze
+}
+
+func freezeMessage(freeze *placementv1alpha1.PlacementFreeze) string {
+	message := "Placement is frozen"
+	if freeze.FrozenBy != "" {
+		message += " by " + freeze.FrozenBy
+	}
+	if freeze.Until != nil {
+		message += " until " + freeze.Until.UTC().Format(time.RFC3339)
+	}
+	return message + ": " + freeze.Reason
+}
+
+func (c *controller) newEvent(distribution *workloadv1alpha1.WorkloadDistribution, eventType, reason, message string) *corev1.Event {
+	now := metav1.NewTime(c.now())
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: distribution.Namespace,
+			Name:      fmt.Sprintf("%s.%x", distribution.Name, now.UnixNano()),
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion:      workloadv1alpha1.SchemeGroupVersion.String(),
+			Kind:            "WorkloadDistribution",
+			Namespace:       distribution.Namespace,
+			Name:            distribution.Name,
+			UID:             distribution.UID,
+			ResourceVersion: distribution.ResourceVersion,
+		},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        message,
+		Source:         corev1.EventSource{Component: ControllerName},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+}
+
+// place updates the status of d with the placement decision. It returns an
+// event estimating the emissions of new targets, if their carbon intensity
+// is known.
+func (c *controller) place(ctx context.Context, clusterName logicalcluster.Name, profile *placementv1alpha1.SchedulingProfileSpec, d *workloadv1alpha1.WorkloadDistribution) (time.Duration, *corev1.Event, error) {
+	policy, err := c.resolvePolicy(ctx, clusterName, d)
+	if err != nil || policy == nil {
+		return 0, nil, err
+	}
+	revisionName, spec := policy.revision, policy.spec
+
+	siblings, err := c.listDistributions(clusterName, d.Namespace)
+	if err != nil {
+		return 0, nil, err
+	}
+	byName := make(map[string]*workloadv1alpha1.WorkloadDistribution, len(siblings))
+	for _, s := range siblings {
+		byName[s.Name] = s
+	}
+	deps := dependency.Evaluate(d, func(name string) (*workloadv1alpha1.WorkloadDistribution, bool) {
+		other, found := byName[name]
+		return other, found
+	})
+	if !deps.Ready {
+		requeueAfter := c.markDependenciesNotReady(d, deps)
+		if len(d.Status.Targets) == 0 {
+			conditions.MarkFalse(d, workloadv1alpha1.WorkloadPlaced, workloadv1alpha1.WaitingForDependenciesReason, conditionsv1alpha1.ConditionSeverityInfo,
+				"Placement waits for dependencies: %s", deps.Message)
+		}
+		c.recordDecision(clusterName, d, policy, placementdecision.StatusPending, "Placement waits for dependencies: "+deps.Message, c.now(), engine.Decision{})
+		return requeueAfter, nil, nil
+	}
+	if len(d.Spec.DependsOn) > 0 {
+		conditions.MarkTrue(d, workloadv1alpha1.DependenciesReady)
+	} else {
+		conditions.Delete(d, workloadv1alpha1.DependenciesReady)
+	}
+
+	var group *tmcv1alpha1.SyncTargetGroupSpec
+	if spec.SyncTargetGroup != "" {
+		g, err := c.getSyncTargetGroup(clusterName, spec.SyncTargetGroup)
+		if apierrors.IsNotFound(err) {
+			conditions.MarkFalse(d, workloadv1alpha1.WorkloadPlaced, workloadv1alpha1.SyncTargetGroupNotFoundReason, conditionsv1alpha1.ConditionSeverityError,
+				"SyncTargetGroup %q of %s not found", spec.SyncTargetGroup, policy.description)
+			return 0, nil, nil
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+		group = &g.Spec
+	}
+
+	dataLocations := map[string]*placementv1alpha1.DataLocationSpec{}
+	for _, term := range spec.DataAffinity {
+		location, err := c.getDataLocation(clusterName, term.DataLocation)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+		dataLocations[term.DataLocation] = &location.Spec
+	}
+
+	syncTargets, err := c.listSyncTargets(clusterName)
+	if err != nil {
+		return 0, nil, err
+	}
+	var dependencyLocations []string
+	for _, dep := range d.Spec.DependsOn {
+		if other, found := byName[dep.Name]; found {
+			for _, t := range other.Status.Targets {
+				dependencyLocations = append(dependencyLocations, t.Location)
+			}
+		}
+	}
+	syncTargets, infeasible := c.capacity.Resolve(ctx, syncTargets, capacity.Request{
+		Workspace:          clusterName.String(),
+		Namespace:          d.Namespace,
+		Name:               d.Name,
+		WorkloadAPIVersion: d.Spec.WorkloadRef.APIVersion,
+		WorkloadKind:       d.Spec.WorkloadRef.Kind,
+		WorkloadName:       d.Spec.WorkloadRef.Name,
+	})
+	if c.carbon != nil {
+		syncTargets = c.carbon.Resolve(ctx, syncTargets)
+	}
+	var placements engine.PlacementIndex
+	if len(spec.WorkloadAffinity) > 0 {
+		if placements, err = c.placementIndex(clusterName, syncTargets); err != nil {
+			return 0, nil, err
+		}
+	}
+	current, overridden := overrideTargets(d.Status.Targets, d.Spec.TargetOverrides)
+	req := engine.Request{
+		Policy:             spec,
+		SyncTargets:        syncTargets,
+		Current:            current,
+		Displaced:          d.Status.DisplacedTargets,
+		Profile:            profile,
+		PreferredLocations: dependencyLocations,
+		Group:              group,
+		DataLocations:      dataLocations,
+		Workload:           engine.PlacedWorkload{Namespace: d.Namespace, Name: d.Name, Labels: d.Labels},
+		Placements:         placements,
+		Filters: []engine.Filter{func(syncTarget *tmcv1alpha1.SyncTarget) string {
+			if !deps.Allows(syncTarget) {
+				return "is not colocated with dependencies"
+			}
+			return ""
+		}, func(syncTarget *tmcv1alpha1.SyncTarget) string {
+			if to, found := overridden[syncTarget.Name]; found {
+				return fmt.Sprintf("is overridden by SyncTarget %q", to)
+			}
+			return ""
+		}, func(syncTarget *tmcv1alpha1.SyncTarget) string {
+			return infeasible[syncTarget.Name]
+		}, func(syncTarget *tmcv1alpha1.SyncTarget) string {
+			if syncTarget.Status.Drain.Evicts(d.Namespace, d.Name) {
+				return "is being drained"
+			}
+			return ""
+		}},
+	}
+	started := c.now()
+	decision, err := c.engine.Place(req)
+	if errors.Is(err, engine.ErrNoFeasibleTargets) {
+		message := fmt.Sprintf("No SyncTarget satisfies %s: %s", policy.description, rejections(decision.Rejected))
+		d.Status.Targets = nil
+		d.Status.DisplacedTargets = decision.Displaced
+		d.Status.PolicyRevision = revisionName
+		conditions.MarkFalse(d, workloadv1alpha1.WorkloadPlaced, workloadv1alpha1.NoFeasibleTargetsReason, conditionsv1alpha1.ConditionSeverityError,
+			"%s", message)
+		c.recordDecision(clusterName, d, policy, placementdecision.StatusFailed, message, started, decision)
+		return decision.RecheckAfter, nil, nil
+	}
+	if err != nil {
+		// An invalid policy does not get better by retrying.
+		conditions.MarkFalse(d, workloadv1alpha1.WorkloadPlaced, workloadv1alpha1.NoFeasibleTargetsReason, conditionsv1alpha1.ConditionSeverityError,
+			"%v", err)
+		return 0, nil, nil
+	}
+
+	reason, message, err := c.checkQuotas(clusterName, d, decision.Targets)
+	if err != nil {
+		return 0, nil, err
+	}
+	if message != "" {
+		// The workload keeps its SyncTargets until the quota allows more.
+		conditions.MarkFalse(d, workloadv1alpha1.WorkloadPlaced, reason, conditionsv1alpha1.ConditionSeverityWarning, "%s", message)
+		c.recordDecision(clusterName, d, policy, placementdecision.StatusFailed, message, started, decision)
+		return 0, nil, nil
+	}
+
+	if !sameTargets(d.Status.Targets, decision.Targets) {
+		workload := framework.Workload{ClusterName: clusterName, Namespace: d.Namespace, Name: d.Name}
+		if err := c.engine.Bind(ctx, workload, req, decision); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	// Disruption windows open and close without SyncTarget events.
+	requeueAfter := decision.RecheckAfter
+	if ttl := policy.decisionTTL; ttl != nil && ttl.Duration > 0 {
+		revalidateAfter := c.revalidate(d, current, decision, syncTargets, ttl.Duration)
+		if requeueAfter == 0 || revalidateAfter < requeueAfter {
+			requeueAfter = revalidateAfter
+		}
+	} else {
+		d.Status.LastValidatedTime = nil
+		conditions.Delete(d, workloadv1alpha1.DecisionValid)
+	}
+
+	var event *corev1.Event
+	if decision.Emissions != nil && !sameTargets(d.Status.Targets, decision.Targets) {
+		event = c.newEvent(d, corev1.EventTypeNormal, EmissionsReason, "Placed onto "+targetNames(decision.Targets)+": "+decision.Emissions.String()+".")
+	}
+
+	c.recordDecision(clusterName, d, policy, placementdecision.StatusSucceeded, "Placed onto "+targetNames(decision.Targets), started, decision)
+
+	d.Status.Targets = decision.Targets
+	d.Status.DisplacedTargets = decision.Displaced
+	d.Status.PolicyRevision = revisionName
+	conditions.MarkTrue(d, workloadv1alpha1.WorkloadPlaced)
+	return requeueAfter, event, nil
+}
+
+// checkQuotas returns the reason and message why placing d onto targets
+// would exceed the MultiClusterResourceQuotas of the workspace, or nothing
+// if it would not. Only placing onto more SyncTargets than now is checked,
+// with the resource usage per SyncTarget the quota controller recorded on d.
+func (c *controller) checkQuotas(clusterName logicalcluster.Name, d *workloadv1alpha1.WorkloadDistribution, targets []workloadv1alpha1.TargetPlacement) (string, string, error) {
+	// The workload uses the same resources on every SyncTarget, so moving
+	// it does not use more.
+	added := int64(len(targets) - len(d.Status.Targets))
+	if added <= 0 {
+		return "", "", nil
+	}
+
+	quotas, err := c.listQuotas(clusterName)
+	if err != nil || len(quotas) == 0 {
+		return "", "", err
+	}
+	used, found, err := quota.Unmarshal(d)
+	if !found || err != nil {
+		return workloadv1alpha1.WaitingForResourceUsageReason, "Placement waits for the resource usage of the workload to be recorded", nil
+	}
+	if message := quota.Check(quotas, quota.Multiply(used, added)); message != "" {
+		return workloadv1alpha1.QuotaExceededReason, "Placing onto " + targetNames(targets) + " would exceed the quota: " + message, nil
+	}
+	return "", "", nil
+}
+
+// placementIndex returns the workloads placed on the SyncTargets.
+func (c *controller) placementIndex(clusterName logicalcluster.Name, syncTargets []*tmcv1alpha1.SyncTarget) (engine.PlacementIndex, error) {
+	index := make(engine.PlacementIndex, len(syncTargets))
+	for _, syncTarget := range syncTargets {
+		placed, err := c.listPlacedOn(clusterName, syncTarget.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, other := range placed {
+			index[syncTarget.Name] = append(index[syncTarget.Name], engine.PlacedWorkload{Namespace: other.Namespace, Name: other.Name, Labels: other.Labels})
+		}
+	}
+	return index, nil
+}
+
+// recordDecision records the outcome of placing d, started at the given
+// time, if decisions are recorded.
+func (c *controller) recordDecision(clusterName logicalcluster.Name, d *workloadv1alpha1.WorkloadDistribution, policy *resolvedPolicy, status placementdecision.Status, message string, started time.Time, placed engine.Decision) {
+	if c.record == nil {
+		return
+	}
+	c.record(&placementdecision.DecisionRecord{
+		Workspace:      clusterName,
+		Namespace:      d.Namespace,
+		Name:           d.Name,
+		Policy:         d.Spec.PolicyRef.Name,
+		PolicyRevision: policy.revision,
+		Strategy:       policy.spec.Strategy,
+		Status:         status,
+		Message:        message,
+		Targets:        placed.Targets,
+		Rejected:       placed.Rejected,
+		Scores:         placed.Scores,
+		ScorerScores:   placed.ScorerScores,
+		Time:           started,
+		Duration:       c.now().Sub(started),
+	})
+}
+
+func targetNames(targets []workloadv1alpha1.TargetPlacement) string {
+	names := make([]string, 0, len(targets))
+	for _, t := range targets {
+		names = append(names, t.SyncTarget)
+	}
+	return "SyncTarget " + strings.Join(names, ", ")
+}
+
+// revalidate revalidates the decision of d once its TTL passed, reporting
+// the outcome in the DecisionValid condition. Current targets that are no
+// longer feasible are already replaced in the new decision, so an invalid
+// decision is placed anew. A changed decision starts a new TTL. It returns
+// when the next revalidation is due.
+func (c *controller) revalidate(d *workloadv1alpha1.WorkloadDistribution, current []workloadv1alpha1.TargetPlacement, decision engine.Decision, syncTargets []*tmcv1alpha1.SyncTarget, ttl time.Duration) time.Duration {
+	now := c.now()
+	last := d.Status.LastValidatedTime
+	switch {
+	case last == nil || !now.Before(last.Add(ttl)):
+		exists := make(map[string]bool, len(syncTargets))
+		for _, syncTarget := range syncTargets {
+			exists[syncTarget.Name] = true
+		}
+		var invalid []string
+		for _, t := range current {
+			if reason, rejected := decision.Rejected[t.SyncTarget]; rejected {
+				invalid = append(invalid, fmt.Sprintf("%s %s", t.SyncTarget, reason))
+			} else if !exists[t.SyncTarget] {
+				invalid = append(invalid, fmt.Sprintf("%s no longer exists", t.SyncTarget))
+			}
+		}
+		if len(invalid) > 0 {
+			conditions.MarkFalse(d, workloadv1alpha1.DecisionValid, workloadv1alpha1.DecisionInvalidReason, conditionsv1alpha1.ConditionSeverityWarning,
+				"Placed anew because SyncTarget %s", strings.Join(invalid, ", SyncTarget "))
+		} else {
+			conditions.MarkTrue(d, workloadv1alpha1.DecisionValid)
+		}
+		d.Status.LastValidatedTime = &metav1.Time{Time: now.Truncate(time.Second)}
+	case !sameTargets(d.Status.Targets, decision.Targets):
+		d.Status.LastValidatedTime = &metav1.Time{Time: now.Truncate(time.Second)}
+	}
+	return max(d.Status.LastValidatedTime.Add(ttl).Sub(now), time.Second)
+}
+
+func sameTargets(a, b []workloadv1alpha1.TargetPlacement) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	names := make(map[string]bool, len(a))
+	for _, t := range a {
+		names[t.SyncTarget] = true
+	}
+	for _, t := range b {
+		if !names[t.SyncTarget] {
+			return false
+		}
+	}
+	return true
+}
+
+// resolvedPolicy is the placement a distribution is placed with.
+type resolvedPolicy struct {
+	// description names the policy in messages.
+	description string
+	revision    string
+	spec        placementv1alpha1.PlacementPolicySpec
+	decisionTTL *metav1.Duration
+}
+
+// resolvePolicy returns the placement d is placed with: the revision of its
+// PlacementPolicy, or the rule of its WorkloadPlacementAdvanced selecting
+// it. It returns nil if d cannot be placed, having set the Placed condition
+// accordingly.
+func (c *controller) resolvePolicy(ctx context.Context, clusterName logicalcluster.Name, d *workloadv1alpha1.WorkloadDistribution) (*resolvedPolicy, error) {
+	if d.Spec.PolicyRef.IsAdvanced() {
+		advanced, err := c.getAdvanced(clusterName, d.Spec.PolicyRef.Name)
+		if apierrors.IsNotFound(err) {
+			conditions.MarkFalse(d, workloadv1alpha1.WorkloadPlaced, workloadv1alpha1.PolicyNotFoundReason, conditionsv1alpha1.ConditionSeverityError,
+				"WorkloadPlacementAdvanced %q not found", d.Spec.PolicyRef.Name)
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rule := composite.Compile(&advanced.Spec).Match(d.Labels)
+		if rule == nil {
+			conditions.MarkFalse(d, workloadv1alpha1.WorkloadPlaced, workloadv1alpha1.NoMatchingRuleReason, conditionsv1alpha1.ConditionSeverityError,
+				"No rule of WorkloadPlacementAdvanced %q selects the workload", advanced.Name)
+			return nil, nil
+		}
+		// Rules have no revisions, the revision only identifies the rule
+		// and its spec.
+		spec := revision.Spec(rule.Placement)
+		return &resolvedPolicy{
+			description: fmt.Sprintf("rule %q of WorkloadPlacementAdvanced %q", rule.Name, advanced.Name),
+			revision:    revision.Name(advanced.Name+"-"+rule.Name, spec),
+			spec:        spec,
+			decisionTTL: rule.Placement.DecisionTTL,
+		}, nil
+	}
+
+	policy, err := c.getPolicy(clusterName, d.Spec.PolicyRef.Name)
+	if apierrors.IsNotFound(err) {
+		conditions.MarkFalse(d, workloadv1alpha1.WorkloadPlaced, workloadv1alpha1.PolicyNotFoundReason, conditionsv1alpha1.ConditionSeverityError,
+			"PlacementPolicy %q not found", d.Spec.PolicyRef.Name)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	revisionName, spec, err := c.resolveRevision(ctx, clusterName, policy, d)
+	if err != nil {
+		return nil, err
+	}
+	return &resolvedPolicy{
+		description: fmt.Sprintf("PlacementPolicy %q", policy.Name),
+		revision:    revisionName,
+		spec:        spec,
+		decisionTTL: policy.Spec.DecisionTTL,
+	}, nil
+}
+
+// resolveRevision returns the policy revision d is placed with: the revision
+// it is pinned to by the rollout controller, else the current revision of the
+// policy, else the policy spec itself.
+func (c *controller) resolveRevision(ctx context.Context, clusterName logicalcluster.Name, policy *placementv1alpha1.PlacementPolicy, d *workloadv1alpha1.WorkloadDistribution) (string, placementv1alpha1.PlacementPolicySpec, error) {
+	name := d.Annotations[workloadv1alpha1.AnnotationPolicyRevision]
+	if name == "" {
+		name = policy.Status.CurrentRevision
+	}
+	latest := revision.Name(policy.Name, policy.Spec)
+	if name == "" || name == latest {
+		return latest, revision.Spec(policy.Spec), nil
+	}
+
+	rev, err := c.getRevision(clusterName, name)
+	if apierrors.IsNotFound(err) {
+		klog.FromContext(ctx).V(2).Info("policy revision not found, using the latest", "revision", name, "latest", latest)
+		return latest, revision.Spec(policy.Spec), nil
+	}
+	if err != nil {
+		return "", placementv1alpha1.PlacementPolicySpec{}, err
+	}
+	return name, rev.Spec, nil
+}
+
+// markDependenciesNotReady sets the DependenciesReady condition to false. The
+// condition is an error once dependencies were not ready for longer than the
+// dependency timeout. It returns when the timeout is due.
+func (c *controller) markDependenciesNotReady(d *workloadv1alpha1.WorkloadDistribution, deps dependency.Result) time.Duration {
+	now := c.now()
+	since := now
+	if prev := conditions.Get(d, workloadv1alpha1.DependenciesReady); prev != nil && prev.Status != corev1.ConditionTrue && !prev.LastTransitionTime.IsZero() {
+		since = prev.LastTransitionTime.Time
+	}
+
+	reason, severity, message := deps.Reason, conditionsv1alpha1.ConditionSeverityInfo, deps.Message
+	var requeueAfter time.Duration
+	switch {
+	case deps.Reason == workloadv1alpha1.DependencyCycleReason:
+		severity = conditionsv1alpha1.ConditionSeverityError
+	case d.Spec.DependencyTimeout != nil:
+		deadline := since.Add(d.Spec.DependencyTimeout.Duration)
+		if now.Before(deadline) {
+			requeueAfter = deadline.Sub(now)
+			break
+		}
+		reason, severity = workloadv1alpha1.DependencyTimeoutReason, conditionsv1alpha1.ConditionSeverityError
+		message = fmt.Sprintf("Dependencies not ready within %s: %s", d.Spec.DependencyTimeout.Duration, deps.Message)
+	}
+
+	conditions.MarkFalse(d, workloadv1alpha1.DependenciesReady, reason, severity, "%s", message)
+	// The wait for dependencies starts when they become not ready, not when
+	// the reason for waiting last changed.
+	for i := range d.Status.Conditions {
+		if d.Status.Conditions[i].Type == workloadv1alpha1.DependenciesReady && since.Before(d.Status.Conditions[i].LastTransitionTime.Time) {
+			d.Status.Conditions[i].LastTransitionTime.Time = since
+		}
+	}
+	return requeueAfter
+}
+
+func rejections(rejected map[string]string) string {
+	if len(rejected) == 0 {
+		return "no SyncTargets exist"
+	}
+	names := make([]string, 0, len(rejected))
+	for name := range rejected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s %s", name, rejected[name]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// overrideTargets replaces the From targets of the overrides in current by
+// their To targets, so placement prefers these like current ones. It returns
+// the replaced targets, and the From targets mapped to their To target.
+func overrideTargets(current []workloadv1alpha1.TargetPlacement, overrides []workloadv1alpha1.TargetOverride) ([]workloadv1alpha1.TargetPlacement, map[string]string) {
+	if len(overrides) == 0 {
+		return current, nil
+	}
+	overridden := make(map[string]string, len(overrides))
+	for _, o := range overrides {
+		overridden[o.From] = o.To
+	}
+	replaced := make([]workloadv1alpha1.TargetPlacement, 0, len(current))
+	for _, t := range current {
+		if to, found := overridden[t.SyncTarget]; found {
+			t = workloadv1alpha1.TargetPlacement{SyncTarget: to}
+		}
+		replaced = append(replaced, t)
+	}
+	return replaced, overridden
+}
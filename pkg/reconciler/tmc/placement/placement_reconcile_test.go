@@ -0,0 +1,613 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	kcpcache "github.com/kcp-dev/apimachinery/v2/pkg/cache"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/placement/capacity"
+	"github.com/kcp-dev/kcp/pkg/placement/carbon"
+	"github.com/kcp-dev/kcp/pkg/placement/decision"
+	"github.com/kcp-dev/kcp/pkg/placement/engine"
+	"github.com/kcp-dev/kcp/pkg/placement/revision"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+type fixture struct {
+	now           time.Time
+	policies      map[string]*placementv1alpha1.PlacementPolicy
+	revisions     map[string]*placementv1alpha1.PlacementPolicyRevision
+	advanced      map[string]*placementv1alpha1.WorkloadPlacementAdvanced
+	distributions map[string]*workloadv1alpha1.WorkloadDistribution
+	syncTargets   []*tmcv1alpha1.SyncTarget
+	profile       *placementv1alpha1.SchedulingProfileSpec
+	groups        map[string]*tmcv1alpha1.SyncTargetGroup
+	dataLocations map[string]*placementv1alpha1.DataLocation
+	quotas        []*tmcv1alpha1.MultiClusterResourceQuota
+	capacity      func(provider *tmcv1alpha1.CapacityProvider, req *capacity.Request) (*capacity.Response, error)
+	carbon        carbon.Provider
+	events        []*corev1.Event
+	decisions     []*decision.DecisionRecord
+}
+
+func newFixture() *fixture {
+	ready := conditionsv1alpha1.Conditions{{Type: tmcv1alpha1.SyncerReady, Status: corev1.ConditionTrue}}
+	return &fixture{
+		now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		policies: map[string]*placementv1alpha1.PlacementPolicy{
+			"spread": {ObjectMeta: metav1.ObjectMeta{Name: "spread"}},
+		},
+		revisions:     map[string]*placementv1alpha1.PlacementPolicyRevision{},
+		advanced:      map[string]*placementv1alpha1.WorkloadPlacementAdvanced{},
+		distributions: map[string]*workloadv1alpha1.WorkloadDistribution{},
+		groups:        map[string]*tmcv1alpha1.SyncTargetGroup{},
+		dataLocations: map[string]*placementv1alpha1.DataLocation{},
+		syncTargets: []*tmcv1alpha1.SyncTarget{
+			{ObjectMeta: metav1.ObjectMeta{Name: "eu-1"}, Spec: tmcv1alpha1.SyncTargetSpec{Location: "eu"}, Status: tmcv1alpha1.SyncTargetStatus{Conditions: ready}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "us-1"}, Spec: tmcv1alpha1.SyncTargetSpec{Location: "us"}, Status: tmcv1alpha1.SyncTargetStatus{Conditions: ready}},
+		},
+	}
+}
+
+func (f *fixture) add(name string, deps ...workloadv1alpha1.DistributionDependency) *workloadv1alpha1.WorkloadDistribution {
+	d := &workloadv1alpha1.WorkloadDistribution{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: workloadv1alpha1.WorkloadDistributionSpec{
+			PolicyRef: workloadv1alpha1.PolicyReference{Name: "spread"},
+			DependsOn: deps,
+		},
+	}
+	f.distributions[name] = d
+	return d
+}
+
+func (f *fixture) controller() *controller {
+	notFound := func(resource, name string) error {
+		return apierrors.NewNotFound(schema.GroupResource{Resource: resource}, name)
+	}
+	return &controller{
+		now:    func() time.Time { return f.now },
+		engine: engine.NewEngine(),
+		capacity: capacity.NewResolver(func(_ context.Context, provider *tmcv1alpha1.CapacityProvider, req *capacity.Request) (*capacity.Response, error) {
+			return f.capacity(provider, req)
+		}),
+		listDistributions: func(clusterName logicalcluster.Name, namespace string) ([]*workloadv1alpha1.WorkloadDistribution, error) {
+			var out []*workloadv1alpha1.WorkloadDistribution
+			for _, d := range f.distributions {
+				out = append(out, d)
+			}
+			return out, nil
+		},
+		listPlacedOn: func(clusterName logicalcluster.Name, syncTarget string) ([]*workloadv1alpha1.WorkloadDistribution, error) {
+			var out []*workloadv1alpha1.WorkloadDistribution
+			for _, d := range f.distributions {
+				for _, t := range d.Status.Targets {
+					if t.SyncTarget == syncTarget {
+						out = append(out, d)
+					}
+				}
+			}
+			return out, nil
+		},
+		getPolicy: func(clusterName logicalcluster.Name, name string) (*placementv1alpha1.PlacementPolicy, error) {
+			if p, ok := f.policies[name]; ok {
+				return p, nil
+			}
+			return nil, notFound("placementpolicies", name)
+		},
+		getRevision: func(clusterName logicalcluster.Name, name string) (*placementv1alpha1.PlacementPolicyRevision, error) {
+			if r, ok := f.revisions[name]; ok {
+				return r, nil
+			}
+			return nil, notFound("placementpolicyrevisions", name)
+		},
+		getAdvanced: func(clusterName logicalcluster.Name, name string) (*placementv1alpha1.WorkloadPlacementAdvanced, error) {
+			if a, ok := f.advanced[name]; ok {
+				return a, nil
+			}
+			return nil, notFound("workloadplacementadvanceds", name)
+		},
+		getProfile: func(clusterName logicalcluster.Name) (*placementv1alpha1.SchedulingProfileSpec, error) {
+			return f.profile, nil
+		},
+		getSyncTargetGroup: func(clusterName logicalcluster.Name, name string) (*tmcv1alpha1.SyncTargetGroup, error) {
+			if g, ok := f.groups[name]; ok {
+				return g, nil
+			}
+			return nil, notFound("synctargetgroups", name)
+		},
+		getDataLocation: func(clusterName logicalcluster.Name, name string) (*placementv1alpha1.DataLocation, error) {
+			if l, ok := f.dataLocations[name]; ok {
+				return l, nil
+			}
+			return nil, notFound("datalocations", name)
+		},
+		listSyncTargets: func(clusterName logicalcluster.Name) ([]*tmcv1alpha1.SyncTarget, error) {
+			return f.syncTargets, nil
+		},
+		listQuotas: func(clusterName logicalcluster.Name) ([]*tmcv1alpha1.MultiClusterResourceQuota, error) {
+			return f.quotas, nil
+		},
+		updateDistributionStatus: func(ctx context.Context, clusterName logicalcluster.Name, d *workloadv1alpha1.WorkloadDistribution) error {
+			f.distributions[d.Name] = d
+			return nil
+		},
+		updateAdvancedStatus: func(ctx context.Context, clusterName logicalcluster.Name, a *placementv1alpha1.WorkloadPlacementAdvanced) error {
+			f.advanced[a.Name] = a
+			return nil
+		},
+		createEvent: func(ctx context.Context, clusterName logicalcluster.Name, event *corev1.Event) error {
+			f.events = append(f.events, event)
+			return nil
+		},
+		record: func(record *decision.DecisionRecord) {
+			f.decisions = append(f.decisions, record)
+		},
+	}
+}
+
+func (f *fixture) reconcileWithCarbon(t *testing.T, name string) {
+	t.Helper()
+	c := f.controller()
+	c.carbon = carbon.NewResolver(f.carbon, time.Minute)
+	_, err := c.reconcile(context.Background(), "root:org", f.distributions[name])
+	require.NoError(t, err)
+}
+
+func (f *fixture) reconcile(t *testing.T, name string) time.Duration {
+	t.Helper()
+	requeueAfter, err := f.controller().reconcile(context.Background(), "root:org", f.distributions[name])
+	require.NoError(t, err)
+	return requeueAfter
+}
+
+func TestReconcilePlacesAfterDependencies(t *testing.T) {
+	f := newFixture()
+	f.add("db")
+	f.add("app", workloadv1alpha1.DistributionDependency{Name: "db"})
+
+	f.reconcile(t, "app")
+	app := f.distributions["app"]
+	require.Empty(t, app.Status.Targets)
+	require.Equal(t, workloadv1alpha1.WaitingForDependenciesReason, conditions.GetReason(app, workloadv1alpha1.WorkloadPlaced))
+	require.Equal(t, workloadv1alpha1.WaitingForDependenciesReason, conditions.GetReason(app, workloadv1alpha1.DependenciesReady))
+
+	f.reconcile(t, "db")
+	db := f.distributions["db"]
+	require.True(t, conditions.IsTrue(db, workloadv1alpha1.WorkloadPlaced))
+	require.Len(t, db.Status.Targets, 2)
+	require.False(t, conditions.Has(db, workloadv1alpha1.DependenciesReady))
+	require.Equal(t, revision.Name("spread", placementv1alpha1.PlacementPolicySpec{}), db.Status.PolicyRevision)
+
+	// The database ends up on a single target, the app is colocated with it.
+	db.Status.Targets = db.Status.Targets[1:]
+	conditions.MarkTrue(db, workloadv1alpha1.WorkloadReady)
+
+	f.reconcile(t, "app")
+	app = f.distributions["app"]
+	require.True(t, conditions.IsTrue(app, workloadv1alpha1.DependenciesReady))
+	require.True(t, conditions.IsTrue(app, workloadv1alpha1.WorkloadPlaced))
+	require.Equal(t, []workloadv1alpha1.TargetPlacement{{SyncTarget: "us-1", Location: "us"}}, app.Status.Targets)
+}
+
+func TestReconcileRecordsDecisions(t *testing.T) {
+	f := newFixture()
+	f.add("db")
+	f.add("app", workloadv1alpha1.DistributionDependency{Name: "db"})
+
+	f.reconcile(t, "app")
+	f.reconcile(t, "db")
+	for _, syncTarget := range f.syncTargets {
+		syncTarget.Status.Conditions = nil
+	}
+	f.add("web")
+	f.reconcile(t, "web")
+
+	require.Len(t, f.decisions, 3)
+	pending, succeeded, failed := f.decisions[0], f.decisions[1], f.decisions[2]
+
+	require.Equal(t, decision.StatusPending, pending.Status)
+	require.Equal(t, "app", pending.Name)
+
+	require.Equal(t, decision.StatusSucceeded, succeeded.Status)
+	require.Equal(t, logicalcluster.Name("root:org"), succeeded.Workspace)
+	require.Equal(t, "spread", succeeded.Policy)
+	require.Equal(t, revision.Name("spread", placementv1alpha1.PlacementPolicySpec{}), succeeded.PolicyRevision)
+	require.Len(t, succeeded.Targets, 2)
+	require.Len(t, succeeded.Scores, 2)
+	require.Equal(t, f.now, succeeded.Time)
+
+	require.Equal(t, decision.StatusFailed, failed.Status)
+	require.Equal(t, map[string]string{"eu-1": "syncer is not ready", "us-1": "syncer is not ready"}, failed.Rejected)
+	require.Equal(t, conditions.GetMessage(f.distributions["web"], workloadv1alpha1.WorkloadPlaced), failed.Message)
+}
+
+func TestReconcileDependencyTimeout(t *testing.T) {
+	f := newFixture()
+	f.add("db")
+	app := f.add("app", workloadv1alpha1.DistributionDependency{Name: "db"})
+	app.Spec.DependencyTimeout = &metav1.Duration{Duration: 10 * time.Minute}
+
+	require.Equal(t, 10*time.Minute, f.reconcile(t, "app"))
+	require.Equal(t, conditionsv1alpha1.ConditionSeverityInfo, *conditions.GetSeverity(f.distributions["app"], workloadv1alpha1.DependenciesReady))
+
+	f.now = f.now.Add(4 * time.Minute)
+	require.Equal(t, 6*time.Minute, f.reconcile(t, "app"))
+
+	f.now = f.now.Add(6 * time.Minute)
+	require.Zero(t, f.reconcile(t, "app"))
+	app = f.distributions["app"]
+	require.Equal(t, workloadv1alpha1.DependencyTimeoutReason, conditions.GetReason(app, workloadv1alpha1.DependenciesReady))
+	require.Equal(t, conditionsv1alpha1.ConditionSeverityError, *conditions.GetSeverity(app, workloadv1alpha1.DependenciesReady))
+}
+
+func TestReconcileDependencyCycle(t *testing.T) {
+	f := newFixture()
+	f.add("a", workloadv1alpha1.DistributionDependency{Name: "b"})
+	f.add("b", workloadv1alpha1.DistributionDependency{Name: "a"})
+
+	f.reconcile(t, "a")
+	a := f.distributions["a"]
+	require.Equal(t, workloadv1alpha1.DependencyCycleReason, conditions.GetReason(a, workloadv1alpha1.DependenciesReady))
+	require.Contains(t, conditions.GetMessage(a, workloadv1alpha1.DependenciesReady), "a -> b -> a")
+}
+
+func TestReconcileUsesPinnedRevision(t *testing.T) {
+	f := newFixture()
+	f.policies["spread"].Spec.Strategy = placementv1alpha1.PlacementStrategySpread
+	f.revisions["spread-old"] = &placementv1alpha1.PlacementPolicyRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "spread-old"},
+		Spec:       placementv1alpha1.PlacementPolicySpec{Strategy: placementv1alpha1.PlacementStrategySingleton},
+	}
+	d := f.add("app")
+	d.Annotations = map[string]string{workloadv1alpha1.AnnotationPolicyRevision: "spread-old"}
+
+	f.reconcile(t, "app")
+	require.Equal(t, "spread-old", f.distributions["app"].Status.PolicyRevision)
+	require.Len(t, f.distributions["app"].Status.Targets, 1)
+
+	delete(f.policies, "spread")
+	f.reconcile(t, "app")
+	require.Equal(t, workloadv1alpha1.PolicyNotFoundReason, conditions.GetReason(f.distributions["app"], workloadv1alpha1.WorkloadPlaced))
+}
+
+func TestReconcileUsesSchedulingProfile(t *testing.T) {
+	f := newFixture()
+	f.add("db").Status.Targets = []workloadv1alpha1.TargetPlacement{{SyncTarget: "us-1", Location: "us"}}
+	conditions.MarkTrue(f.distributions["db"], workloadv1alpha1.WorkloadReady)
+	f.add("app", workloadv1alpha1.DistributionDependency{Name: "db", Colocation: workloadv1alpha1.ColocationAny})
+	f.profile = &placementv1alpha1.SchedulingProfileSpec{DefaultStrategy: placementv1alpha1.PlacementStrategySingleton}
+
+	f.reconcile(t, "app")
+	require.Equal(t, []workloadv1alpha1.TargetPlacement{{SyncTarget: "us-1", Location: "us"}}, f.distributions["app"].Status.Targets,
+		"the default strategy applies and locality prefers the location of dependencies")
+}
+
+func TestReconcileSyncTargetGroup(t *testing.T) {
+	f := newFixture()
+	f.policies["spread"].Spec.SyncTargetGroup = "fleet"
+	f.add("app")
+
+	f.reconcile(t, "app")
+	require.Equal(t, workloadv1alpha1.SyncTargetGroupNotFoundReason, conditions.GetReason(f.distributions["app"], workloadv1alpha1.WorkloadPlaced))
+
+	f.groups["fleet"] = &tmcv1alpha1.SyncTargetGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "fleet"},
+		Spec:       tmcv1alpha1.SyncTargetGroupSpec{Members: []tmcv1alpha1.SyncTargetGroupMember{{Name: "us-1"}}},
+	}
+	f.reconcile(t, "app")
+	require.True(t, conditions.IsTrue(f.distributions["app"], workloadv1alpha1.WorkloadPlaced))
+	require.Equal(t, []workloadv1alpha1.TargetPlacement{{SyncTarget: "us-1", Location: "us"}}, f.distributions["app"].Status.Targets, "only members are placed on")
+}
+
+func TestReconcileTargetOverrides(t *testing.T) {
+	f := newFixture()
+	f.policies["spread"].Spec.Strategy = placementv1alpha1.PlacementStrategySingleton
+	f.add("app").Status.Targets = []workloadv1alpha1.TargetPlacement{{SyncTarget: "eu-1", Location: "eu"}}
+
+	f.reconcile(t, "app")
+	require.Equal(t, []workloadv1alpha1.TargetPlacement{{SyncTarget: "eu-1", Location: "eu"}}, f.distributions["app"].Status.Targets)
+
+	f.distributions["app"].Spec.TargetOverrides = []workloadv1alpha1.TargetOverride{{From: "eu-1", To: "us-1"}}
+	f.reconcile(t, "app")
+	require.Equal(t, []workloadv1alpha1.TargetPlacement{{SyncTarget: "us-1", Location: "us"}}, f.distributions["app"].Status.Targets)
+}
+
+func TestReconcileFreeze(t *testing.T) {
+	f := newFixture()
+	f.policies["spread"].Spec.Strategy = placementv1alpha1.PlacementStrategySingleton
+	f.add("app").Status.Targets = []workloadv1alpha1.TargetPlacement{{SyncTarget: "eu-1", Location: "eu"}}
+	f.add("new")
+	f.profile = &placementv1alpha1.SchedulingProfileSpec{Freeze: &placementv1alpha1.PlacementFreeze{
+		Reason:   "incident 42",
+		FrozenBy: "alice",
+		Until:    &metav1.Time{Time: f.now.Add(time.Hour)},
+	}}
+
+	f.distributions["app"].Spec.TargetOverrides = []workloadv1alpha1.TargetOverride{{From: "eu-1", To: "us-1"}}
+	requeueAfter := f.reconcile(t, "app")
+	require.Equal(t, time.Hour, requeueAfter, "placement unfreezes automatically")
+	require.Equal(t, []workloadv1alpha1.TargetPlacement{{SyncTarget: "eu-1", Location: "eu"}}, f.distributions["app"].Status.Targets, "no moves while frozen")
+	require.True(t, conditions.IsTrue(f.distributions["app"], workloadv1alpha1.PlacementFrozen))
+	require.Len(t, f.events, 1)
+	require.Equal(t, workloadv1alpha1.FrozenReason, f.events[0].Reason)
+	require.Equal(t, "app", f.events[0].InvolvedObject.Name)
+	require.Contains(t, f.events[0].Message, "Placement is frozen by alice until ")
+	require.Contains(t, f.events[0].Message, ": incident 42")
+
+	f.reconcile(t, "app")
+	require.Len(t, f.events, 1, "no event while placement stays frozen")
+
+	f.reconcile(t, "new")
+	require.Empty(t, f.distributions["new"].Status.Targets, "no new placements while frozen")
+
+	f.now = f.now.Add(time.Hour)
+	f.reconcile(t, "app")
+	require.False(t, conditions.Has(f.distributions["app"], workloadv1alpha1.PlacementFrozen))
+	require.Equal(t, []workloadv1alpha1.TargetPlacement{{SyncTarget: "us-1", Location: "us"}}, f.distributions["app"].Status.Targets)
+	require.Len(t, f.events, 3)
+	require.Equal(t, UnfrozenReason, f.events[2].Reason)
+}
+
+func TestReconcileCapacityProvider(t *testing.T) {
+	f := newFixture()
+	f.syncTargets[0].Spec.CapacityProvider = &tmcv1alpha1.CapacityProvider{URL: "https://vm-pool.example.com"}
+	f.add("app").Spec.WorkloadRef = workloadv1alpha1.WorkloadReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "app"}
+
+	var requests []capacity.Request
+	f.capacity = func(_ *tmcv1alpha1.CapacityProvider, req *capacity.Request) (*capacity.Response, error) {
+		requests = append(requests, *req)
+		return &capacity.Response{Feasible: false, Reason: "pool exhausted"}, nil
+	}
+	f.reconcile(t, "app")
+	require.Equal(t, []capacity.Request{{
+		Workspace: "root:org", Namespace: "default", Name: "app",
+		WorkloadAPIVersion: "apps/v1", WorkloadKind: "Deployment", WorkloadName: "app",
+		SyncTarget: "eu-1",
+	}}, requests, "only targets with a provider are queried")
+	require.Equal(t, []workloadv1alpha1.TargetPlacement{{SyncTarget: "us-1", Location: "us"}}, f.distributions["app"].Status.Targets)
+
+	f.capacity = func(*tmcv1alpha1.CapacityProvider, *capacity.Request) (*capacity.Response, error) {
+		return nil, errors.New("connection refused")
+	}
+	f.distributions["app"].Status.Targets = nil
+	f.reconcile(t, "app")
+	require.Equal(t, []workloadv1alpha1.TargetPlacement{{SyncTarget: "us-1", Location: "us"}}, f.distributions["app"].Status.Targets, "failing providers fail closed")
+
+	f.syncTargets[0].Spec.CapacityProvider.FailurePolicy = tmcv1alpha1.CapacityProviderIgnore
+	f.reconcile(t, "app")
+	require.Equal(t, []workloadv1alpha1.TargetPlacement{{SyncTarget: "us-1", Location: "us"}, {SyncTarget: "eu-1", Location: "eu"}}, f.distributions["app"].Status.Targets,
+		"ignored failures fall back to the capacity reported by the syncer")
+}
+
+type carbonProviderFunc func(region string) (float64, error)
+
+func (f carbonProviderFunc) Intensity(_ context.Context, region string) (float64, error) {
+	return f(region)
+}
+
+func TestReconcileCarbonIntensity(t *testing.T) {
+	f := newFixture()
+	f.policies["green"] = &placementv1alpha1.PlacementPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "green"},
+		Spec:       placementv1alpha1.PlacementPolicySpec{Strategy: placementv1alpha1.PlacementStrategySingleton},
+	}
+	f.add("app").Spec.PolicyRef.Name = "green"
+
+	f.reconcile(t, "app")
+	require.Len(t, f.distributions["app"].Status.Targets, 1)
+	require.Empty(t, f.events, "no emissions are estimated without carbon intensities")
+
+	f.distributions["app"].Status.Targets = nil
+	f.carbon = carbonProviderFunc(func(region string) (float64, error) {
+		return map[string]float64{"eu": 50, "us": 400}[region], nil
+	})
+	f.reconcileWithCarbon(t, "app")
+	require.Equal(t, []workloadv1alpha1.TargetPlacement{{SyncTarget: "eu-1", Location: "eu"}}, f.distributions["app"].Status.Targets)
+	require.Len(t, f.events, 1)
+	require.Equal(t, EmissionsReason, f.events[0].Reason)
+	require.Equal(t, "Placed onto SyncTarget eu-1: chosen SyncTargets run at 50 gCO2eq/kWh, 175 gCO2eq/kWh (78%) below the 225 gCO2eq/kWh average of feasible SyncTargets.", f.events[0].Message)
+
+	f.reconcileWithCarbon(t, "app")
+	require.Len(t, f.events, 1, "no event while the targets stay the same")
+
+	f.syncTargets[0].Annotations = map[string]string{tmcv1alpha1.AnnotationCarbonIntensity: "900"}
+	f.syncTargets[1].Annotations = map[string]string{tmcv1alpha1.AnnotationCarbonIntensity: "10"}
+	f.carbon = carbonProviderFunc(func(string) (float64, error) {
+		return 0, errors.New("service unavailable")
+	})
+	f.distributions["app"].Status.Targets = nil
+	f.reconcileWithCarbon(t, "app")
+	require.Equal(t, []workloadv1alpha1.TargetPlacement{{SyncTarget: "us-1", Location: "us"}}, f.distributions["app"].Status.Targets,
+		"published carbon intensities are used when the provider fails")
+}
+
+func TestReconcileDataAffinity(t *testing.T) {
+	f := newFixture()
+	f.policies["spread"].Spec.DataAffinity = []placementv1alpha1.DataAffinityTerm{{DataLocation: "orders", Type: placementv1alpha1.DataAffinityRequired}}
+	f.add("app")
+
+	f.reconcile(t, "app")
+	require.Equal(t, workloadv1alpha1.NoFeasibleTargetsReason, conditions.GetReason(f.distributions["app"], workloadv1alpha1.WorkloadPlaced),
+		"missing DataLocations host data nowhere")
+
+	f.dataLocations["orders"] = &placementv1alpha1.DataLocation{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders"},
+		Spec:       placementv1alpha1.DataLocationSpec{SyncTargets: []string{"us-1"}},
+	}
+	f.reconcile(t, "app")
+	require.Equal(t, []workloadv1alpha1.TargetPlacement{{SyncTarget: "us-1", Location: "us"}}, f.distributions["app"].Status.Targets)
+}
+
+func TestReconcileWorkloadAffinity(t *testing.T) {
+	f := newFixture()
+	f.policies["cache"] = &placementv1alpha1.PlacementPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "cache"},
+		Spec: placementv1alpha1.PlacementPolicySpec{WorkloadAffinity: []placementv1alpha1.WorkloadAffinityTerm{
+			{Relation: placementv1alpha1.WorkloadAffinityPlaceWith, Selector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "shop"}}, Type: placementv1alpha1.WorkloadAffinityRequired},
+			{Relation: placementv1alpha1.WorkloadAffinityNeverColocateWith, Selector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "batch"}}, Type: placementv1alpha1.WorkloadAffinityRequired},
+		}},
+	}
+	cache := f.add("cache")
+	cache.Spec.PolicyRef.Name = "cache"
+
+	f.reconcile(t, "cache")
+	require.Equal(t, workloadv1alpha1.NoFeasibleTargetsReason, conditions.GetReason(f.distributions["cache"], workloadv1alpha1.WorkloadPlaced),
+		"the workload waits for the workloads it is placed with")
+
+	shop := f.add("shop")
+	shop.Labels = map[string]string{"app": "shop"}
+	shop.Status.Targets = []workloadv1alpha1.TargetPlacement{{SyncTarget: "eu-1", Location: "eu"}, {SyncTarget: "us-1", Location: "us"}}
+	batch := f.add("batch")
+	batch.Labels = map[string]string{"app": "batch"}
+	batch.Status.Targets = []workloadv1alpha1.TargetPlacement{{SyncTarget: "eu-1", Location: "eu"}}
+	f.reconcile(t, "cache")
+	require.Equal(t, []workloadv1alpha1.TargetPlacement{{SyncTarget: "us-1", Location: "us"}}, f.distributions["cache"].Status.Targets)
+}
+
+func TestReconcileDecisionTTL(t *testing.T) {
+	f := newFixture()
+	f.policies["spread"].Spec.Strategy = placementv1alpha1.PlacementStrategySingleton
+	f.policies["spread"].Spec.DecisionTTL = &metav1.Duration{Duration: 10 * time.Minute}
+	f.add("app")
+
+	require.Equal(t, 10*time.Minute, f.reconcile(t, "app"), "revalidation is due after the TTL")
+	app := f.distributions["app"]
+	require.Equal(t, "eu-1", app.Status.Targets[0].SyncTarget)
+	require.Equal(t, f.now, app.Status.LastValidatedTime.Time)
+
+	// Before the TTL passed, the decision is not revalidated.
+	f.now = f.now.Add(4 * time.Minute)
+	require.Equal(t, 6*time.Minute, f.reconcile(t, "app"))
+	require.Same(t, app, f.distributions["app"], "status is unchanged")
+
+	// Revalidation finds the target no longer ready and places anew.
+	f.now = f.now.Add(6 * time.Minute)
+	f.syncTargets[0].Status.Conditions = nil
+	require.Equal(t, 10*time.Minute, f.reconcile(t, "app"))
+	app = f.distributions["app"]
+	require.Equal(t, "us-1", app.Status.Targets[0].SyncTarget)
+	require.True(t, conditions.IsFalse(app, workloadv1alpha1.DecisionValid))
+	require.Equal(t, workloadv1alpha1.DecisionInvalidReason, conditions.GetReason(app, workloadv1alpha1.DecisionValid))
+	require.Equal(t, "Placed anew because SyncTarget eu-1 syncer is not ready", conditions.GetMessage(app, workloadv1alpha1.DecisionValid))
+	require.Equal(t, f.now, app.Status.LastValidatedTime.Time)
+
+	// The next revalidation finds the decision valid.
+	f.now = f.now.Add(10 * time.Minute)
+	f.reconcile(t, "app")
+	app = f.distributions["app"]
+	require.Equal(t, "us-1", app.Status.Targets[0].SyncTarget)
+	require.True(t, conditions.IsTrue(app, workloadv1alpha1.DecisionValid))
+	require.Equal(t, f.now, app.Status.LastValidatedTime.Time)
+
+	// Without a TTL, decisions are not revalidated.
+	f.policies["spread"].Spec.DecisionTTL = nil
+	require.Zero(t, f.reconcile(t, "app"))
+	app = f.distributions["app"]
+	require.Nil(t, app.Status.LastValidatedTime)
+	require.Nil(t, conditions.Get(app, workloadv1alpha1.DecisionValid))
+}
+
+func TestReconcileResourceQuota(t *testing.T) {
+	f := newFixture()
+	f.quotas = []*tmcv1alpha1.MultiClusterResourceQuota{{
+		ObjectMeta: metav1.ObjectMeta{Name: "team"},
+		Spec:       tmcv1alpha1.MultiClusterResourceQuotaSpec{Hard: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("3")}},
+		Status: tmcv1alpha1.MultiClusterResourceQuotaStatus{
+			Used: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+		},
+	}}
+	app := f.add("app")
+
+	f.reconcile(t, "app")
+	app = f.distributions["app"]
+	require.Empty(t, app.Status.Targets)
+	require.Equal(t, workloadv1alpha1.WaitingForResourceUsageReason, conditions.GetReason(app, workloadv1alpha1.WorkloadPlaced))
+
+	app.Annotations = map[string]string{tmcv1alpha1.AnnotationResourceUsage: `{"cpu":"1"}`}
+	f.reconcile(t, "app")
+	app = f.distributions["app"]
+	require.Empty(t, app.Status.Targets, "the workload is not placed beyond the quota")
+	require.Equal(t, workloadv1alpha1.QuotaExceededReason, conditions.GetReason(app, workloadv1alpha1.WorkloadPlaced))
+	require.Equal(t, `Placing onto SyncTarget eu-1, us-1 would exceed the quota: MultiClusterResourceQuota "team" would be exceeded: cpu: 4 of at most 3`,
+		conditions.GetMessage(app, workloadv1alpha1.WorkloadPlaced))
+	require.Equal(t, decision.StatusFailed, f.decisions[len(f.decisions)-1].Status)
+
+	f.quotas[0].Spec.Hard[corev1.ResourceCPU] = resource.MustParse("4")
+	f.reconcile(t, "app")
+	app = f.distributions["app"]
+	require.Len(t, app.Status.Targets, 2)
+	require.True(t, conditions.IsTrue(app, workloadv1alpha1.WorkloadPlaced))
+
+	// Placements that do not grow are not checked.
+	f.quotas[0].Status.Used[corev1.ResourceCPU] = resource.MustParse("4")
+	f.syncTargets[0].Status.Conditions = nil
+	f.reconcile(t, "app")
+	require.Equal(t, []workloadv1alpha1.TargetPlacement{{SyncTarget: "us-1", Location: "us"}}, f.distributions["app"].Status.Targets)
+}
+
+func TestReconcileDrain(t *testing.T) {
+	f := newFixture()
+	f.add("app")
+	f.reconcile(t, "app")
+	require.Len(t, f.distributions["app"].Status.Targets, 2)
+
+	f.syncTargets[0].Spec.Unschedulable = true
+	f.reconcile(t, "app")
+	require.Len(t, f.distributions["app"].Status.Targets, 2, "cordoned targets keep their workloads")
+
+	f.syncTargets[0].Status.Drain = &tmcv1alpha1.SyncTargetDrainStatus{Evicting: []tmcv1alpha1.DrainedWorkload{{Namespace: "default", Name: "app"}}}
+	f.reconcile(t, "app")
+	require.Equal(t, []workloadv1alpha1.TargetPlacement{{SyncTarget: "us-1", Location: "us"}}, f.distributions["app"].Status.Targets)
+}
+
+func TestProcessSkipsWorkspacesOfOtherShards(t *testing.T) {
+	f := newFixture()
+	f.add("app")
+	c := f.controller()
+	c.getDistribution = func(clusterName logicalcluster.Name, namespace, name string) (*workloadv1alpha1.WorkloadDistribution, error) {
+		return f.distributions[name], nil
+	}
+	c.owns = func(clusterName logicalcluster.Name) bool { return clusterName == "root:org" }
+
+	_, err := c.process(context.Background(), kcpcache.ToClusterAwareKey("root:other", "default", "app"))
+	require.NoError(t, err)
+	require.Empty(t, f.distributions["app"].Status.Targets, "the workspace is placed by another replica")
+	require.False(t, c.ownsKey(kcpcache.ToClusterAwareKey("root:other", "default", "app")))
+
+	_, err = c.process(context.Background(), kcpcache.ToClusterAwareKey("root:org", "default", "app"))
+	require.NoError(t, err)
+	require.NotEmpty(t, f.distributions["app"].Status.Targets)
+}
@@ -0,0 +1,303 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policyrollout
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	kcpcache "github.com/kcp-dev/apimachinery/v2/pkg/cache"
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/tmcinformers"
+	"github.com/kcp-dev/kcp/pkg/tmc/workspacequeue"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+const (
+	ControllerName = "kcp-tmc-policyrollout"
+)
+
+var (
+	// PlacementPoliciesGVR is the resource whose rollouts are managed.
+	PlacementPoliciesGVR = placementv1alpha1.SchemeGroupVersion.WithResource("placementpolicies")
+	// PlacementPolicyRevisionsGVR is the resource recording policy history.
+	PlacementPolicyRevisionsGVR = placementv1alpha1.SchemeGroupVersion.WithResource("placementpolicyrevisions")
+	// WorkloadDistributionsGVR is the resource pinned to policy revisions.
+	WorkloadDistributionsGVR = workloadv1alpha1.SchemeGroupVersion.WithResource("workloaddistributions")
+)
+
+// NewController returns a controller that records a PlacementPolicyRevision
+// for every placement-relevant change of a PlacementPolicy and rolls it out
+// to the WorkloadDistributions using the policy in stages, by pinning each
+// distribution to a revision.
+func NewController(
+	policyClusterInformer *tmcinformers.Informer,
+	revisionClusterInformer *tmcinformers.Informer,
+	distributionClusterInformer *tmcinformers.Informer,
+	dynamicClusterClient kcpdynamic.ClusterInterface,
+) (*controller, error) {
+	c := &controller{
+		queue: workspacequeue.NewRateLimitingQueue(ControllerName),
+		now:   time.Now,
+		getPolicy: func(clusterName logicalcluster.Name, name string) (*placementv1alpha1.PlacementPolicy, error) {
+			obj, err := policyClusterInformer.Lister(clusterName).Get(name)
+			if err != nil {
+				return nil, err
+			}
+			policy := &placementv1alpha1.PlacementPolicy{}
+			return policy, fromUnstructured(obj, policy)
+		},
+		listRevisions: func(clusterName logicalcluster.Name, policyName string) ([]*placementv1alpha1.PlacementPolicyRevision, error) {
+			objs, err := revisionClusterInformer.Lister(clusterName).List(labels.SelectorFromSet(labels.Set{placementv1alpha1.LabelPolicy: policyName}))
+			if err != nil {
+				return nil, err
+			}
+			revisions := make([]*placementv1alpha1.PlacementPolicyRevision, 0, len(objs))
+			for _, obj := range objs {
+				revision := &placementv1alpha1.PlacementPolicyRevision{}
+				if err := fromUnstructured(obj, revision); err != nil {
+					return nil, err
+				}
+				revisions = append(revisions, revision)
+			}
+			return revisions, nil
+		},
+		createRevision: func(ctx context.Context, clusterName logicalcluster.Name, revision *placementv1alpha1.PlacementPolicyRevision) error {
+			u, err := toUnstructured(revision)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(PlacementPolicyRevisionsGVR).Create(ctx, u, metav1.CreateOptions{})
+			return err
+		},
+		deleteRevision: func(ctx context.Context, clusterName logicalcluster.Name, name string) error {
+			return dynamicClusterClient.Cluster(clusterName.Path()).Resource(PlacementPolicyRevisionsGVR).Delete(ctx, name, metav1.DeleteOptions{})
+		},
+		listDistributions: func(clusterName logicalcluster.Name, policyName string) ([]*workloadv1alpha1.WorkloadDistribution, error) {
+			objs, err := distributionClusterInformer.Lister(clusterName).List(labels.Everything())
+			if err != nil {
+				return nil, err
+			}
+			var distributions []*workloadv1alpha1.WorkloadDistribution
+			for _, obj := range objs {
+				distribution := &workloadv1alpha1.WorkloadDistribution{}
+				if err := fromUnstructured(obj, distribution); err != nil {
+					return nil, err
+				}
+				if !distribution.Spec.PolicyRef.IsAdvanced() && distribution.Spec.PolicyRef.Name == policyName {
+					distributions = append(distributions, distribution)
+				}
+			}
+			return distributions, nil
+		},
+		pinDistribution: func(ctx context.Context, clusterName logicalcluster.Name, namespace, name, revision string) error {
+			patch, err := json.Marshal(map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{
+						workloadv1alpha1.AnnotationPolicyRevision: revision,
+					},
+				},
+			})
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(WorkloadDistributionsGVR).Namespace(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+			return err
+		},
+		updatePolicyStatus: func(ctx context.Context, clusterName logicalcluster.Name, policy *placementv1alpha1.PlacementPolicy) error {
+			u, err := toUnstructured(policy)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(PlacementPoliciesGVR).UpdateStatus(ctx, u, metav1.UpdateOptions{})
+			return err
+		},
+	}
+
+	policyClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueue(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+	})
+	revisionClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		DeleteFunc: func(obj interface{}) { c.enqueueOwningPolicy(obj) },
+	})
+	distributionClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueueOwningPolicy(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueueOwningPolicy(obj) },
+		DeleteFunc: func(obj interface{}) { c.enqueueOwningPolicy(obj) },
+	})
+
+	return c, nil
+}
+
+// controller rolls out PlacementPolicy revisions.
+type controller struct {
+	queue workqueue.TypedRateLimitingInterface[string]
+
+	now func() time.Time
+
+	getPolicy          func(clusterName logicalcluster.Name, name string) (*placementv1alpha1.PlacementPolicy, error)
+	listRevisions      func(clusterName logicalcluster.Name, policyName string) ([]*placementv1alpha1.PlacementPolicyRevision, error)
+	createRevision     func(ctx context.Context, clusterName logicalcluster.Name, revision *placementv1alpha1.PlacementPolicyRevision) error
+	deleteRevision     func(ctx context.Context, clusterName logicalcluster.Name, name string) error
+	listDistributions  func(clusterName logicalcluster.Name, policyName string) ([]*workloadv1alpha1.WorkloadDistribution, error)
+	pinDistribution    func(ctx context.Context, clusterName logicalcluster.Name, namespace, name, revision string) error
+	updatePolicyStatus func(ctx context.Context, clusterName logicalcluster.Name, policy *placementv1alpha1.PlacementPolicy) error
+}
+
+func (c *controller) enqueue(obj interface{}) {
+	key, err := kcpcache.DeletionHandlingMetaClusterNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+
+	logger := logging.WithQueueKey(logging.WithReconciler(klog.Background(), ControllerName), key)
+	logger.V(4).Info("queueing PlacementPolicy")
+	c.queue.Add(key)
+}
+
+// enqueueOwningPolicy enqueues the policy a revision or distribution belongs to.
+func (c *controller) enqueueOwningPolicy(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		utilruntime.HandleError(fmt.Errorf("expected *unstructured.Unstructured, got %T", obj))
+		return
+	}
+
+	policyName := u.GetLabels()[placementv1alpha1.LabelPolicy]
+	if kind, _, _ := unstructured.NestedString(u.Object, "spec", "policyRef", "kind"); policyName == "" && kind != workloadv1alpha1.WorkloadPlacementAdvancedKind {
+		policyName, _, _ = unstructured.NestedString(u.Object, "spec", "policyRef", "name")
+	}
+	if policyName == "" {
+		return
+	}
+
+	key := kcpcache.ToClusterAwareKey(logicalcluster.From(u).String(), "", policyName)
+	logger := logging.WithQueueKey(logging.WithReconciler(klog.Background(), ControllerName), key)
+	logger.V(4).Info("queueing PlacementPolicy because of related object", "kind", u.GetKind(), "name", u.GetName())
+	c.queue.Add(key)
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	logger := logging.WithReconciler(klog.FromContext(ctx), ControllerName)
+	ctx = klog.NewContext(ctx, logger)
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	for range numThreads {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+
+	logger := logging.WithQueueKey(klog.FromContext(ctx), key)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(4).Info("processing key")
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	requeueAfter, err := c.process(ctx, key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", ControllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	if requeueAfter > 0 {
+		c.queue.AddAfter(key, requeueAfter)
+	}
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) (time.Duration, error) {
+	clusterName, _, name, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return 0, nil
+	}
+
+	policy, err := c.getPolicy(clusterName, name)
+	if errors.IsNotFound(err) {
+		// Revisions are garbage collected through their owner reference.
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !policy.DeletionTimestamp.IsZero() {
+		return 0, nil
+	}
+
+	return c.reconcile(ctx, clusterName, policy)
+}
+
+func fromUnstructured(obj runtime.Object, into interface{}) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("expected *unstructured.Unstructured, got %T", obj)
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, into)
+}
+
+func toUnstructured(obj interface{}) (*unstructured.Unstructured, error) {
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: raw}, nil
+}
@@ -0,0 +1,406 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policyrollout
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/placement/revision"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+const (
+	defaultRevisionHistoryLimit = 10
+	defaultCanaryPercent        = 10
+	defaultAnalysisPeriod       = 5 * time.Minute
+
+	// pendingCanaryRecheck is how often a rollout waiting for canaries is
+	// re-evaluated in the absence of distribution events.
+	pendingCanaryRecheck = 30 * time.Second
+)
+
+func (c *controller) reconcile(ctx context.Context, clusterName logicalcluster.Name, policy *placementv1alpha1.PlacementPolicy) (time.Duration, error) {
+	logger := klog.FromContext(ctx)
+
+	revisions, err := c.listRevisions(clusterName, policy.Name)
+	if err != nil {
+		return 0, err
+	}
+	updateRevision, err := c.ensureRevision(ctx, clusterName, policy, revisions)
+	if err != nil {
+		return 0, err
+	}
+
+	distributions, err := c.listDistributions(clusterName, policy.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	plan := planRollout(policy, updateRevision, distributions, c.now())
+
+	var errs []error
+	for _, d := range distributions {
+		pin := plan.pins[d.Namespace+"/"+d.Name]
+		if d.Annotations[workloadv1alpha1.AnnotationPolicyRevision] == pin {
+			continue
+		}
+		logger.V(2).Info("pinning WorkloadDistribution to policy revision", "namespace", d.Namespace, "name", d.Name, "revision", pin)
+		if err := c.pinDistribution(ctx, clusterName, d.Namespace, d.Name, pin); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, err)
+		}
+	}
+
+	keep := map[string]bool{plan.status.CurrentRevision: true, updateRevision: true}
+	for _, pin := range plan.pins {
+		keep[pin] = true
+	}
+	if err := c.pruneRevisions(ctx, clusterName, policy, revisions, keep); err != nil {
+		errs = append(errs, err)
+	}
+
+	if !equality.Semantic.DeepEqual(policy.Status, plan.status) {
+		updated := policy.DeepCopy()
+		updated.Status = plan.status
+		if err := c.updatePolicyStatus(ctx, clusterName, updated); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return plan.requeueAfter, utilerrors.NewAggregate(errs)
+}
+
+// ensureRevision creates the revision of the current policy spec if it does
+// not exist yet, and returns its name.
+func (c *controller) ensureRevision(ctx context.Context, clusterName logicalcluster.Name, policy *placementv1alpha1.PlacementPolicy, revisions []*placementv1alpha1.PlacementPolicyRevision) (string, error) {
+	name := revision.Name(policy.Name, policy.Spec)
+
+	var latest int64
+	for _, r := range revisions {
+		if r.Name == name {
+			return name, nil
+		}
+		if r.Revision > latest {
+			latest = r.Revision
+		}
+	}
+
+	klog.FromContext(ctx).V(2).Info("creating PlacementPolicyRevision", "revision", name)
+	err := c.createRevision(ctx, clusterName, &placementv1alpha1.PlacementPolicyRevision{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: placementv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "PlacementPolicyRevision",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{placementv1alpha1.LabelPolicy: policy.Name},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: placementv1alpha1.SchemeGroupVersion.String(),
+				Kind:       "PlacementPolicy",
+				Name:       policy.Name,
+				UID:        policy.UID,
+				Controller: ptr.To(true),
+			}},
+		},
+		Revision: latest + 1,
+		Spec:     revision.Spec(policy.Spec),
+	})
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return "", err
+	}
+	return name, nil
+}
+
+// pruneRevisions deletes the oldest revisions beyond the history limit,
+// never deleting the revisions to keep, i.e. the current and update
+// revisions, and those workloads are pinned to.
+func (c *controller) pruneRevisions(ctx context.Context, clusterName logicalcluster.Name, policy *placementv1alpha1.PlacementPolicy, revisions []*placementv1alpha1.PlacementPolicyRevision, keep map[string]bool) error {
+	limit := defaultRevisionHistoryLimit
+	if policy.Spec.RevisionHistoryLimit != nil {
+		limit = int(*policy.Spec.RevisionHistoryLimit)
+	}
+
+	var old []*placementv1alpha1.PlacementPolicyRevision
+	for _, r := range revisions {
+		if !keep[r.Name] {
+			old = append(old, r)
+		}
+	}
+	if len(old) <= limit {
+		return nil
+	}
+	sort.Slice(old, func(i, j int) bool { return old[i].Revision < old[j].Revision })
+
+	var errs []error
+	for _, r := range old[:len(old)-limit] {
+		klog.FromContext(ctx).V(4).Info("pruning PlacementPolicyRevision", "revision", r.Name)
+		if err := c.deleteRevision(ctx, clusterName, r.Name); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+type rolloutPlan struct {
+	status placementv1alpha1.PlacementPolicyStatus
+	// pins maps namespace/name of every distribution to its revision.
+	pins         map[string]string
+	requeueAfter time.Duration
+}
+
+// planRollout decides which revision each distribution is placed with and
+// how the rollout of updateRevision progresses. Paused distributions stay on
+// the revision they are pinned to.
+func planRollout(policy *placementv1alpha1.PlacementPolicy, updateRevision string, distributions []*workloadv1alpha1.WorkloadDistribution, now time.Time) (plan rolloutPlan) {
+	plan.pins = map[string]string{}
+
+	// Work on a copy for the conditions helpers, returning its status.
+	policy = policy.DeepCopy()
+	status := &policy.Status
+
+	var paused []*workloadv1alpha1.WorkloadDistribution
+	active := make([]*workloadv1alpha1.WorkloadDistribution, 0, len(distributions))
+	for _, d := range distributions {
+		if d.Spec.Paused {
+			paused = append(paused, d)
+		} else {
+			active = append(active, d)
+		}
+	}
+	defer func() {
+		for _, d := range paused {
+			pin := d.Annotations[workloadv1alpha1.AnnotationPolicyRevision]
+			if pin == "" {
+				pin = status.CurrentRevision
+			}
+			plan.pins[d.Namespace+"/"+d.Name] = pin
+		}
+		if status.Rollout != nil {
+			status.Rollout.PausedWorkloads = int32(len(paused))
+		}
+		plan.status = *status
+	}()
+
+	status.ObservedGeneration = policy.Generation
+	status.UpdateRevision = updateRevision
+
+	pinAll := func(revision string) {
+		for _, d := range active {
+			plan.pins[d.Namespace+"/"+d.Name] = revision
+		}
+	}
+
+	// Without a rollout strategy, and for new policies, changes apply at once.
+	if status.CurrentRevision == "" || policy.Spec.Rollout == nil {
+		status.CurrentRevision = updateRevision
+	}
+	if status.CurrentRevision == updateRevision {
+		if status.Rollout != nil && status.Rollout.Revision != updateRevision {
+			status.Rollout = nil
+		}
+		if status.Rollout != nil && (status.Rollout.Phase == placementv1alpha1.PolicyRolloutProgressing || status.Rollout.Phase == placementv1alpha1.PolicyRolloutAwaitingApproval) {
+			status.Rollout.Phase = placementv1alpha1.PolicyRolloutPromoted
+		}
+		conditions.MarkTrue(policy, placementv1alpha1.PolicyRolloutHealthy)
+		pinAll(updateRevision)
+		return plan
+	}
+
+	rollout := status.Rollout
+	if rollout == nil || rollout.Revision != updateRevision {
+		rollout = &placementv1alpha1.PolicyRolloutStatus{
+			Revision:  updateRevision,
+			Phase:     placementv1alpha1.PolicyRolloutProgressing,
+			StartTime: &metav1.Time{Time: now},
+		}
+		status.Rollout = rollout
+	}
+
+	if rollout.Phase == placementv1alpha1.PolicyRolloutRolledBack {
+		// Stay on the current revision until the spec changes again.
+		pinAll(status.CurrentRevision)
+		return plan
+	}
+
+	canaries := selectCanaries(active, updateRevision, canaryPercent(policy.Spec.Rollout))
+	rollout.Canaries = int32(len(canaries))
+	rollout.HealthyCanaries, rollout.FailedCanaries = 0, 0
+	for _, d := range canaries {
+		switch canaryState(d, updateRevision) {
+		case canaryHealthy:
+			rollout.HealthyCanaries++
+		case canaryFailed:
+			rollout.FailedCanaries++
+		}
+	}
+
+	if rollout.FailedCanaries > 0 && int64(rollout.FailedCanaries)*100 > int64(policy.Spec.Rollout.MaxFailedPercent)*int64(rollout.Canaries) {
+		rollout.Phase = placementv1alpha1.PolicyRolloutRolledBack
+		conditions.MarkFalse(policy, placementv1alpha1.PolicyRolloutHealthy, placementv1alpha1.RolloutRolledBackReason, conditionsv1alpha1.ConditionSeverityError,
+			"Revision %s was rolled back: %d of %d canaries failed", updateRevision, rollout.FailedCanaries, rollout.Canaries)
+		pinAll(status.CurrentRevision)
+		return plan
+	}
+
+	remaining := rollout.StartTime.Add(analysisPeriod(policy.Spec.Rollout)).Sub(now)
+	analyzed := rollout.HealthyCanaries == rollout.Canaries && remaining <= 0
+	approved := rollout.Approval != nil && rollout.Approval.Revision == updateRevision
+	switch {
+	case analyzed && (!policy.Spec.Rollout.RequireApproval || approved):
+		rollout.Phase = placementv1alpha1.PolicyRolloutPromoted
+		status.CurrentRevision = updateRevision
+		if policy.Spec.Rollout.RequireApproval {
+			recordGate(status, placementv1alpha1.RolloutGateApproval, rollout.Approval, now)
+		}
+		conditions.MarkTrue(policy, placementv1alpha1.PolicyRolloutHealthy)
+		pinAll(updateRevision)
+		return plan
+	case analyzed:
+		rollout.Phase = placementv1alpha1.PolicyRolloutAwaitingApproval
+	default:
+		rollout.Phase = placementv1alpha1.PolicyRolloutProgressing
+	}
+
+	conditions.MarkTrue(policy, placementv1alpha1.PolicyRolloutHealthy)
+	pinAll(status.CurrentRevision)
+	for _, d := range canaries {
+		plan.pins[d.Namespace+"/"+d.Name] = updateRevision
+	}
+	plan.requeueAfter = pendingCanaryRecheck
+	if remaining > 0 && remaining < plan.requeueAfter {
+		plan.requeueAfter = remaining
+	}
+	return plan
+}
+
+// recordGate appends a passed gate to the history of the policy, dropping
+// the oldest records beyond MaxRolloutHistory.
+func recordGate(status *placementv1alpha1.PlacementPolicyStatus, gate placementv1alpha1.RolloutGate, approval *placementv1alpha1.RolloutApproval, now time.Time) {
+	status.History = append(status.History, placementv1alpha1.RolloutGateRecord{
+		Gate:       gate,
+		Revision:   approval.Revision,
+		ApprovedBy: approval.ApprovedBy,
+		ApprovedAt: approval.ApprovedAt,
+		PassedAt:   metav1.NewTime(now),
+		Message:    approval.Message,
+	})
+	if len(status.History) > placementv1alpha1.MaxRolloutHistory {
+		status.History = status.History[len(status.History)-placementv1alpha1.MaxRolloutHistory:]
+	}
+}
+
+// selectCanaries deterministically picks percent of the distributions, at
+// least one. The choice is stable for a revision and differs between
+// revisions, so that not always the same workloads take the risk.
+func selectCanaries(distributions []*workloadv1alpha1.WorkloadDistribution, revision string, percent int32) []*workloadv1alpha1.WorkloadDistribution {
+	if len(distributions) == 0 {
+		return nil
+	}
+
+	type scored struct {
+		d    *workloadv1alpha1.WorkloadDistribution
+		hash uint32
+	}
+	all := make([]scored, 0, len(distributions))
+	for _, d := range distributions {
+		hasher := fnv.New32a()
+		_, _ = fmt.Fprintf(hasher, "%s/%s/%s", revision, d.Namespace, d.Name)
+		all = append(all, scored{d: d, hash: hasher.Sum32()})
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].hash != all[j].hash {
+			return all[i].hash < all[j].hash
+		}
+		return all[i].d.Namespace+"/"+all[i].d.Name < all[j].d.Namespace+"/"+all[j].d.Name
+	})
+
+	n := (len(all)*int(percent) + 99) / 100
+	if n < 1 {
+		n = 1
+	}
+	canaries := make([]*workloadv1alpha1.WorkloadDistribution, 0, n)
+	for _, s := range all[:n] {
+		canaries = append(canaries, s.d)
+	}
+	return canaries
+}
+
+type canaryResult int
+
+const (
+	canaryPending canaryResult = iota
+	canaryHealthy
+	canaryFailed
+)
+
+// canaryState classifies a canary. Canaries not yet placed with the revision,
+// or whose readiness is unknown, are pending; a False Placed or Ready
+// condition after placing with the revision counts as failure. Canaries
+// pre-pulling their images are pending until the images are pulled, and
+// fail if pre-pulling failed.
+func canaryState(d *workloadv1alpha1.WorkloadDistribution, revision string) canaryResult {
+	if d.Status.PolicyRevision != revision {
+		if conditions.IsFalse(d, workloadv1alpha1.WorkloadPlaced) && d.Annotations[workloadv1alpha1.AnnotationPolicyRevision] == revision {
+			return canaryFailed
+		}
+		return canaryPending
+	}
+	if conditions.IsFalse(d, workloadv1alpha1.WorkloadPlaced) || conditions.IsFalse(d, workloadv1alpha1.WorkloadReady) {
+		return canaryFailed
+	}
+	if d.Spec.PrePull != nil {
+		if conditions.GetReason(d, workloadv1alpha1.ImagesPrePulled) == workloadv1alpha1.PrePullFailedReason {
+			return canaryFailed
+		}
+		if !conditions.IsTrue(d, workloadv1alpha1.ImagesPrePulled) {
+			return canaryPending
+		}
+	}
+	if conditions.IsTrue(d, workloadv1alpha1.WorkloadPlaced) && conditions.IsTrue(d, workloadv1alpha1.WorkloadReady) {
+		return canaryHealthy
+	}
+	return canaryPending
+}
+
+func canaryPercent(rollout *placementv1alpha1.PolicyRollout) int32 {
+	if rollout == nil || rollout.CanaryPercent <= 0 {
+		return defaultCanaryPercent
+	}
+	return rollout.CanaryPercent
+}
+
+func analysisPeriod(rollout *placementv1alpha1.PolicyRollout) time.Duration {
+	if rollout == nil || rollout.AnalysisPeriod == nil {
+		return defaultAnalysisPeriod
+	}
+	return rollout.AnalysisPeriod.Duration
+}
@@ -0,0 +1,307 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policyrollout
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/placement/revision"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+func distributions(n int) []*workloadv1alpha1.WorkloadDistribution {
+	var ds []*workloadv1alpha1.WorkloadDistribution
+	for i := range n {
+		ds = append(ds, &workloadv1alpha1.WorkloadDistribution{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: fmt.Sprintf("app-%d", i)},
+			Spec:       workloadv1alpha1.WorkloadDistributionSpec{PolicyRef: workloadv1alpha1.PolicyReference{Name: "regional"}},
+		})
+	}
+	return ds
+}
+
+func markCanaries(ds []*workloadv1alpha1.WorkloadDistribution, pins map[string]string, rev string, ready corev1.ConditionStatus) {
+	for _, d := range ds {
+		if pins[d.Namespace+"/"+d.Name] != rev {
+			continue
+		}
+		d.Annotations = map[string]string{workloadv1alpha1.AnnotationPolicyRevision: rev}
+		d.Status.PolicyRevision = rev
+		d.Status.Conditions = conditionsv1alpha1.Conditions{
+			{Type: workloadv1alpha1.WorkloadPlaced, Status: corev1.ConditionTrue},
+			{Type: workloadv1alpha1.WorkloadReady, Status: ready},
+		}
+	}
+}
+
+func countPins(pins map[string]string, rev string) int {
+	n := 0
+	for _, p := range pins {
+		if p == rev {
+			n++
+		}
+	}
+	return n
+}
+
+func TestPlanRollout(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	policy := &placementv1alpha1.PlacementPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "regional", Generation: 1},
+		Spec: placementv1alpha1.PlacementPolicySpec{
+			Strategy: placementv1alpha1.PlacementStrategySpread,
+			Rollout: &placementv1alpha1.PolicyRollout{
+				CanaryPercent:  20,
+				AnalysisPeriod: &metav1.Duration{Duration: 10 * time.Minute},
+			},
+		},
+	}
+	ds := distributions(10)
+
+	// A new policy applies to everything at once.
+	plan := planRollout(policy, "regional-a", ds, start)
+	require.Equal(t, "regional-a", plan.status.CurrentRevision)
+	require.Equal(t, 10, countPins(plan.pins, "regional-a"))
+	policy.Status = plan.status
+
+	// A change starts with the canaries.
+	plan = planRollout(policy, "regional-b", ds, start)
+	require.Equal(t, "regional-a", plan.status.CurrentRevision)
+	require.Equal(t, placementv1alpha1.PolicyRolloutProgressing, plan.status.Rollout.Phase)
+	require.Equal(t, int32(2), plan.status.Rollout.Canaries)
+	require.Equal(t, 2, countPins(plan.pins, "regional-b"))
+	require.Equal(t, 8, countPins(plan.pins, "regional-a"))
+	policy.Status = plan.status
+
+	// Healthy canaries are not promoted before the analysis period ends.
+	markCanaries(ds, plan.pins, "regional-b", corev1.ConditionTrue)
+	plan = planRollout(policy, "regional-b", ds, start.Add(5*time.Minute))
+	require.Equal(t, int32(2), plan.status.Rollout.HealthyCanaries)
+	require.Equal(t, placementv1alpha1.PolicyRolloutProgressing, plan.status.Rollout.Phase)
+	require.Equal(t, pendingCanaryRecheck, plan.requeueAfter)
+
+	plan = planRollout(policy, "regional-b", ds, start.Add(10*time.Minute))
+	require.Equal(t, placementv1alpha1.PolicyRolloutPromoted, plan.status.Rollout.Phase)
+	require.Equal(t, "regional-b", plan.status.CurrentRevision)
+	require.Equal(t, 10, countPins(plan.pins, "regional-b"))
+}
+
+func TestPlanRolloutApprovalAndPause(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	policy := &placementv1alpha1.PlacementPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "regional"},
+		Spec: placementv1alpha1.PlacementPolicySpec{
+			Rollout: &placementv1alpha1.PolicyRollout{
+				CanaryPercent:   20,
+				AnalysisPeriod:  &metav1.Duration{Duration: 10 * time.Minute},
+				RequireApproval: true,
+			},
+		},
+	}
+	ds := distributions(10)
+	policy.Status = planRollout(policy, "regional-a", ds, start).status
+	for _, d := range ds {
+		d.Annotations = map[string]string{workloadv1alpha1.AnnotationPolicyRevision: "regional-a"}
+	}
+	ds[9].Spec.Paused = true
+
+	plan := planRollout(policy, "regional-b", ds, start)
+	require.Equal(t, int32(1), plan.status.Rollout.PausedWorkloads)
+	require.Equal(t, "regional-a", plan.pins["default/app-9"], "paused workloads are not canaries")
+	policy.Status = plan.status
+
+	// Analyzed canaries wait for approval.
+	markCanaries(ds, plan.pins, "regional-b", corev1.ConditionTrue)
+	plan = planRollout(policy, "regional-b", ds, start.Add(10*time.Minute))
+	require.Equal(t, placementv1alpha1.PolicyRolloutAwaitingApproval, plan.status.Rollout.Phase)
+	require.Equal(t, "regional-a", plan.status.CurrentRevision)
+	require.Equal(t, 2, countPins(plan.pins, "regional-b"))
+	policy.Status = plan.status
+
+	// An approval of another revision does not open the gate.
+	policy.Status.Rollout.Approval = &placementv1alpha1.RolloutApproval{Revision: "regional-c"}
+	plan = planRollout(policy, "regional-b", ds, start.Add(11*time.Minute))
+	require.Equal(t, placementv1alpha1.PolicyRolloutAwaitingApproval, plan.status.Rollout.Phase)
+
+	approvedAt := metav1.NewTime(start.Add(12 * time.Minute))
+	policy.Status.Rollout.Approval = &placementv1alpha1.RolloutApproval{Revision: "regional-b", ApprovedBy: "alice", ApprovedAt: &approvedAt, Message: "LGTM"}
+	plan = planRollout(policy, "regional-b", ds, start.Add(13*time.Minute))
+	require.Equal(t, placementv1alpha1.PolicyRolloutPromoted, plan.status.Rollout.Phase)
+	require.Equal(t, "regional-b", plan.status.CurrentRevision)
+	require.Equal(t, 9, countPins(plan.pins, "regional-b"))
+	require.Equal(t, "regional-a", plan.pins["default/app-9"], "paused workloads are held on their revision")
+	require.Equal(t, []placementv1alpha1.RolloutGateRecord{{
+		Gate:       placementv1alpha1.RolloutGateApproval,
+		Revision:   "regional-b",
+		ApprovedBy: "alice",
+		ApprovedAt: &approvedAt,
+		PassedAt:   metav1.NewTime(start.Add(13 * time.Minute)),
+		Message:    "LGTM",
+	}}, plan.status.History)
+	policy.Status = plan.status
+
+	// Resumed workloads catch up.
+	ds[9].Spec.Paused = false
+	plan = planRollout(policy, "regional-b", ds, start.Add(14*time.Minute))
+	require.Equal(t, 10, countPins(plan.pins, "regional-b"))
+	require.Len(t, plan.status.History, 1, "passed gates are recorded once")
+}
+
+func TestPlanRolloutRollsBack(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	policy := &placementv1alpha1.PlacementPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "regional"},
+		Spec: placementv1alpha1.PlacementPolicySpec{
+			Rollout: &placementv1alpha1.PolicyRollout{CanaryPercent: 50},
+		},
+		Status: placementv1alpha1.PlacementPolicyStatus{CurrentRevision: "regional-a"},
+	}
+	ds := distributions(4)
+
+	plan := planRollout(policy, "regional-b", ds, start)
+	policy.Status = plan.status
+	markCanaries(ds, plan.pins, "regional-b", corev1.ConditionFalse)
+
+	plan = planRollout(policy, "regional-b", ds, start.Add(time.Minute))
+	require.Equal(t, placementv1alpha1.PolicyRolloutRolledBack, plan.status.Rollout.Phase)
+	require.Equal(t, int32(2), plan.status.Rollout.FailedCanaries)
+	require.Equal(t, 4, countPins(plan.pins, "regional-a"))
+	require.Equal(t, placementv1alpha1.RolloutRolledBackReason, plan.status.Conditions[0].Reason)
+	policy.Status = plan.status
+
+	// A rolled back revision stays rolled back, a new revision starts over.
+	plan = planRollout(policy, "regional-b", ds, start.Add(time.Hour))
+	require.Equal(t, placementv1alpha1.PolicyRolloutRolledBack, plan.status.Rollout.Phase)
+	plan = planRollout(policy, "regional-c", ds, start.Add(time.Hour))
+	require.Equal(t, placementv1alpha1.PolicyRolloutProgressing, plan.status.Rollout.Phase)
+	require.Equal(t, 2, countPins(plan.pins, "regional-c"))
+}
+
+func TestCanaryStateWaitsForPrePull(t *testing.T) {
+	d := distributions(1)[0]
+	markCanaries([]*workloadv1alpha1.WorkloadDistribution{d}, map[string]string{"default/app-0": "rev"}, "rev", corev1.ConditionTrue)
+	require.Equal(t, canaryHealthy, canaryState(d, "rev"))
+
+	d.Spec.PrePull = &workloadv1alpha1.ImagePrePull{}
+	require.Equal(t, canaryPending, canaryState(d, "rev"))
+
+	prePulled := conditionsv1alpha1.Condition{Type: workloadv1alpha1.ImagesPrePulled, Status: corev1.ConditionFalse, Reason: workloadv1alpha1.PrePullInProgressReason}
+	d.Status.Conditions = append(d.Status.Conditions, prePulled)
+	require.Equal(t, canaryPending, canaryState(d, "rev"))
+
+	d.Status.Conditions[2].Reason = workloadv1alpha1.PrePullFailedReason
+	require.Equal(t, canaryFailed, canaryState(d, "rev"))
+
+	d.Status.Conditions[2].Status, d.Status.Conditions[2].Reason = corev1.ConditionTrue, ""
+	require.Equal(t, canaryHealthy, canaryState(d, "rev"))
+}
+
+func TestSelectCanariesIsStable(t *testing.T) {
+	ds := distributions(20)
+	first := selectCanaries(ds, "rev", 25)
+	require.Len(t, first, 5)
+	reversed := make([]*workloadv1alpha1.WorkloadDistribution, len(ds))
+	for i := range ds {
+		reversed[len(ds)-1-i] = ds[i]
+	}
+	require.Equal(t, first, selectCanaries(reversed, "rev", 25))
+	require.Len(t, selectCanaries(ds[:1], "rev", 1), 1)
+}
+
+func TestReconcileRecordsAndPrunesRevisions(t *testing.T) {
+	policy := &placementv1alpha1.PlacementPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "regional", UID: "uid"},
+		Spec: placementv1alpha1.PlacementPolicySpec{
+			Strategy:             placementv1alpha1.PlacementStrategySingleton,
+			RevisionHistoryLimit: ptr.To[int32](1),
+		},
+	}
+	revisions := map[string]*placementv1alpha1.PlacementPolicyRevision{}
+	for i, name := range []string{"regional-old1", "regional-old2", "regional-old3"} {
+		revisions[name] = &placementv1alpha1.PlacementPolicyRevision{ObjectMeta: metav1.ObjectMeta{Name: name}, Revision: int64(i + 1)}
+	}
+	var updated *placementv1alpha1.PlacementPolicy
+	pinned := map[string]string{}
+
+	c := &controller{
+		now: time.Now,
+		listRevisions: func(clusterName logicalcluster.Name, policyName string) ([]*placementv1alpha1.PlacementPolicyRevision, error) {
+			var out []*placementv1alpha1.PlacementPolicyRevision
+			for _, r := range revisions {
+				out = append(out, r)
+			}
+			return out, nil
+		},
+		createRevision: func(ctx context.Context, clusterName logicalcluster.Name, r *placementv1alpha1.PlacementPolicyRevision) error {
+			revisions[r.Name] = r
+			return nil
+		},
+		deleteRevision: func(ctx context.Context, clusterName logicalcluster.Name, name string) error {
+			delete(revisions, name)
+			return nil
+		},
+		listDistributions: func(clusterName logicalcluster.Name, policyName string) ([]*workloadv1alpha1.WorkloadDistribution, error) {
+			return distributions(2), nil
+		},
+		pinDistribution: func(ctx context.Context, clusterName logicalcluster.Name, namespace, name, rev string) error {
+			pinned[namespace+"/"+name] = rev
+			return nil
+		},
+		updatePolicyStatus: func(ctx context.Context, clusterName logicalcluster.Name, p *placementv1alpha1.PlacementPolicy) error {
+			updated = p
+			return nil
+		},
+	}
+
+	_, err := c.reconcile(context.Background(), "root:org", policy)
+	require.NoError(t, err)
+
+	name := revision.Name("regional", policy.Spec)
+	require.Contains(t, revisions, name)
+	require.Equal(t, int64(4), revisions[name].Revision)
+	require.Nil(t, revisions[name].Spec.RevisionHistoryLimit)
+	require.Equal(t, "regional", revisions[name].Labels[placementv1alpha1.LabelPolicy])
+	require.Len(t, revisions, 2, "one old revision is kept besides the current one")
+	require.Contains(t, revisions, "regional-old3")
+
+	require.Equal(t, name, updated.Status.CurrentRevision)
+	require.Equal(t, map[string]string{"default/app-0": name, "default/app-1": name}, pinned)
+}
+
+func TestRevisionHashIgnoresRolloutSettings(t *testing.T) {
+	spec := placementv1alpha1.PlacementPolicySpec{Strategy: placementv1alpha1.PlacementStrategySpread}
+	withRollout := *spec.DeepCopy()
+	withRollout.Rollout = &placementv1alpha1.PolicyRollout{CanaryPercent: 50}
+	require.Equal(t, revision.Hash(spec), revision.Hash(withRollout))
+
+	changed := *spec.DeepCopy()
+	changed.NumberOfTargets = ptr.To[int32](2)
+	require.NotEqual(t, revision.Hash(spec), revision.Hash(changed))
+}
@@ -0,0 +1,267 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	kcpcache "github.com/kcp-dev/apimachinery/v2/pkg/cache"
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/placement/provisioning"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/clusterprofile"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/tmcinformers"
+	"github.com/kcp-dev/kcp/pkg/tmc/credentials"
+	"github.com/kcp-dev/kcp/pkg/tmc/workspacequeue"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+const (
+	ControllerName = "kcp-tmc-synctarget-provisioning"
+
+	// eventNamespace is the namespace of events about cluster-scoped objects.
+	eventNamespace = metav1.NamespaceDefault
+)
+
+var eventsGVR = schema.GroupVersionResource{Version: "v1", Resource: "events"}
+
+// NewController returns a controller that calls the provisioning webhook of
+// provisionable SyncTargets once workloads are placed on them, and marks
+// provisioning failed if their syncer does not become ready in time, so
+// placement places the workloads elsewhere.
+func NewController(
+	syncTargetClusterInformer *tmcinformers.Informer,
+	distributionClusterInformer *tmcinformers.Informer,
+	dynamicClusterClient kcpdynamic.ClusterInterface,
+) (*controller, error) {
+	webhook := provisioning.NewWebhookClient(credentials.NewResolver(credentials.DynamicSecretGetter(dynamicClusterClient)))
+	c := &controller{
+		queue:     workspacequeue.NewRateLimitingQueue(ControllerName),
+		now:       time.Now,
+		provision: webhook.Provision,
+		getSyncTarget: func(clusterName logicalcluster.Name, name string) (*tmcv1alpha1.SyncTarget, error) {
+			obj, err := syncTargetClusterInformer.Lister(clusterName).Get(name)
+			if err != nil {
+				return nil, err
+			}
+			syncTarget := &tmcv1alpha1.SyncTarget{}
+			return syncTarget, fromUnstructured(obj, syncTarget)
+		},
+		listDistributions: func(clusterName logicalcluster.Name) ([]*workloadv1alpha1.WorkloadDistribution, error) {
+			objs, err := distributionClusterInformer.Lister(clusterName).List(labels.Everything())
+			if err != nil {
+				return nil, err
+			}
+			distributions := make([]*workloadv1alpha1.WorkloadDistribution, 0, len(objs))
+			for _, obj := range objs {
+				d := &workloadv1alpha1.WorkloadDistribution{}
+				if err := fromUnstructured(obj, d); err != nil {
+					return nil, err
+				}
+				distributions = append(distributions, d)
+			}
+			return distributions, nil
+		},
+		updateSyncTargetStatus: func(ctx context.Context, clusterName logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) error {
+			u, err := toUnstructured(syncTarget)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(clusterprofile.SyncTargetsGVR).UpdateStatus(ctx, u, metav1.UpdateOptions{})
+			return err
+		},
+		createEvent: func(ctx context.Context, clusterName logicalcluster.Name, event *corev1.Event) error {
+			u, err := toUnstructured(event)
+			if err != nil {
+				return err
+			}
+			u.SetAPIVersion("v1")
+			u.SetKind("Event")
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(eventsGVR).Namespace(event.Namespace).Create(ctx, u, metav1.CreateOptions{})
+			return err
+		},
+	}
+
+	syncTargetClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueue(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+	})
+	distributionClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueueProvisionableInCluster(syncTargetClusterInformer, obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueueProvisionableInCluster(syncTargetClusterInformer, obj) },
+		DeleteFunc: func(obj interface{}) { c.enqueueProvisionableInCluster(syncTargetClusterInformer, obj) },
+	})
+
+	return c, nil
+}
+
+// controller provisions the capacity of provisionable SyncTargets.
+type controller struct {
+	queue workqueue.TypedRateLimitingInterface[string]
+
+	now       func() time.Time
+	provision func(ctx context.Context, spec *tmcv1alpha1.SyncTargetProvisioning, req *provisioning.Request) (*provisioning.Response, error)
+
+	getSyncTarget          func(clusterName logicalcluster.Name, name string) (*tmcv1alpha1.SyncTarget, error)
+	listDistributions      func(clusterName logicalcluster.Name) ([]*workloadv1alpha1.WorkloadDistribution, error)
+	updateSyncTargetStatus func(ctx context.Context, clusterName logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) error
+	createEvent            func(ctx context.Context, clusterName logicalcluster.Name, event *corev1.Event) error
+}
+
+func (c *controller) enqueue(obj interface{}) {
+	key, err := kcpcache.DeletionHandlingMetaClusterNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+
+	logger := logging.WithQueueKey(logging.WithReconciler(klog.Background(), ControllerName), key)
+	logger.V(4).Info("queueing SyncTarget")
+	c.queue.Add(key)
+}
+
+// enqueueProvisionableInCluster enqueues the provisionable SyncTargets in the
+// logical cluster of the WorkloadDistribution, which may have been placed on
+// or moved off them.
+func (c *controller) enqueueProvisionableInCluster(syncTargetClusterInformer *tmcinformers.Informer, obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		utilruntime.HandleError(fmt.Errorf("expected *unstructured.Unstructured, got %T", obj))
+		return
+	}
+
+	syncTargets, err := syncTargetClusterInformer.Lister(logicalcluster.From(u)).List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, syncTarget := range syncTargets {
+		if u, ok := syncTarget.(*unstructured.Unstructured); ok {
+			if _, found, _ := unstructured.NestedMap(u.Object, "spec", "provisioning"); found {
+				c.enqueue(syncTarget)
+			}
+		}
+	}
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	logger := logging.WithReconciler(klog.FromContext(ctx), ControllerName)
+	ctx = klog.NewContext(ctx, logger)
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	for range numThreads {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+
+	logger := logging.WithQueueKey(klog.FromContext(ctx), key)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(4).Info("processing key")
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	requeueAfter, err := c.process(ctx, key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", ControllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	if requeueAfter > 0 {
+		c.queue.AddAfter(key, requeueAfter)
+	}
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) (time.Duration, error) {
+	clusterName, _, name, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return 0, nil
+	}
+
+	syncTarget, err := c.getSyncTarget(clusterName, name)
+	if errors.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !syncTarget.DeletionTimestamp.IsZero() {
+		return 0, nil
+	}
+
+	return c.reconcile(ctx, clusterName, syncTarget)
+}
+
+func fromUnstructured(obj runtime.Object, into interface{}) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("expected *unstructured.Unstructured, got %T", obj)
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, into)
+}
+
+func toUnstructured(obj interface{}) (*unstructured.Unstructured, error) {
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: raw}, nil
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/placement/provisioning"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+const (
+	// ProvisionedReason is the reason of the event emitted when the syncer of
+	// a provisioned SyncTarget became ready.
+	ProvisionedReason = "Provisioned"
+)
+
+// reconcile drives the Provisioned condition of a SyncTarget. Provisioning
+// is requested once workloads are placed on a provisionable target whose
+// syncer is not ready, and fails if the webhook declines or the syncer does
+// not become ready within the timeout. A failed target is infeasible for
+// placement for another timeout, and is then provisioned anew when chosen
+// again. It returns when the target is due to be reconciled again.
+func (c *controller) reconcile(ctx context.Context, clusterName logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) (time.Duration, error) {
+	logger := klog.FromContext(ctx)
+
+	st := syncTarget.DeepCopy()
+	spec := st.Spec.Provisioning
+	now := c.now()
+
+	var requeueAfter time.Duration
+	var event *corev1.Event
+	var provisionErr error
+	switch {
+	case spec == nil:
+		conditions.Delete(st, tmcv1alpha1.Provisioned)
+		st.Status.ProvisioningStartTime = nil
+	case conditions.IsTrue(st, tmcv1alpha1.SyncerReady):
+		if !conditions.IsTrue(st, tmcv1alpha1.Provisioned) {
+			conditions.MarkTrue(st, tmcv1alpha1.Provisioned)
+			if start := st.Status.ProvisioningStartTime; start != nil {
+				event = c.newEvent(st, corev1.EventTypeNormal, ProvisionedReason, fmt.Sprintf("The syncer is ready %s after provisioning was requested.", now.Sub(start.Time).Truncate(time.Second)))
+			}
+		}
+		st.Status.ProvisioningStartTime = nil
+	default:
+		requeueAfter, event, provisionErr = c.provisionTarget(ctx, clusterName, st, now)
+	}
+
+	if !equality.Semantic.DeepEqual(syncTarget.Status, st.Status) {
+		logger.V(2).Info("updating SyncTarget status", "reason", conditions.GetReason(st, tmcv1alpha1.Provisioned))
+		if err := c.updateSyncTargetStatus(ctx, clusterName, st); err != nil {
+			return 0, err
+		}
+		if event != nil {
+			// Events are best effort, the condition is the source of truth.
+			if err := c.createEvent(ctx, clusterName, event); err != nil {
+				logger.Error(err, "failed to create event", "reason", event.Reason)
+			}
+		}
+	}
+	return requeueAfter, provisionErr
+}
+
+// provisionTarget advances the provisioning of st, whose syncer is not
+// ready. It returns when to check again, the event to emit, and the error of
+// a failed webhook call, which is retried.
+func (c *controller) provisionTarget(ctx context.Context, clusterName logicalcluster.Name, st *tmcv1alpha1.SyncTarget, now time.Time) (time.Duration, *corev1.Event, error) {
+	timeout := st.Spec.Provisioning.ProvisioningTimeout()
+
+	// A provisioned target whose syncer went away lost its capacity, e.g.
+	// because the pool was scaled down.
+	if conditions.IsTrue(st, tmcv1alpha1.Provisioned) {
+		conditions.Delete(st, tmcv1alpha1.Provisioned)
+	}
+	if cond := conditions.Get(st, tmcv1alpha1.Provisioned); cond != nil && cond.Reason == tmcv1alpha1.ProvisioningFailedReason {
+		if retryAt := cond.LastTransitionTime.Add(timeout); now.Before(retryAt) {
+			return retryAt.Sub(now), nil, nil
+		}
+		conditions.Delete(st, tmcv1alpha1.Provisioned)
+	}
+
+	workloads, replicas, err := c.placedOn(clusterName, st.Name)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(workloads) == 0 {
+		// Nothing needs the capacity.
+		conditions.Delete(st, tmcv1alpha1.Provisioned)
+		st.Status.ProvisioningStartTime = nil
+		return 0, nil, nil
+	}
+
+	if st.Status.ProvisioningStartTime == nil {
+		st.Status.ProvisioningStartTime = &metav1.Time{Time: now.Truncate(time.Second)}
+	}
+	deadline := st.Status.ProvisioningStartTime.Add(timeout)
+	fail := func(format string, args ...interface{}) (time.Duration, *corev1.Event, error) {
+		conditions.MarkFalse(st, tmcv1alpha1.Provisioned, tmcv1alpha1.ProvisioningFailedReason, conditionsv1alpha1.ConditionSeverityError, format, args...)
+		st.Status.ProvisioningStartTime = nil
+		message := conditions.GetMessage(st, tmcv1alpha1.Provisioned)
+		return timeout, c.newEvent(st, corev1.EventTypeWarning, tmcv1alpha1.ProvisioningFailedReason, message+". Workloads are placed on other SyncTargets."), nil
+	}
+
+	switch {
+	case !now.Before(deadline):
+		return fail("Not provisioned within %s", timeout)
+	case conditions.GetReason(st, tmcv1alpha1.Provisioned) == tmcv1alpha1.ProvisioningRequestedReason:
+		return deadline.Sub(now), nil, nil
+	}
+
+	resp, err := c.provision(ctx, st.Spec.Provisioning, &provisioning.Request{
+		Workspace:  clusterName.String(),
+		SyncTarget: st.Name,
+		Workloads:  workloads,
+		Replicas:   replicas,
+	})
+	switch {
+	case err != nil:
+		conditions.MarkFalse(st, tmcv1alpha1.Provisioned, tmcv1alpha1.ProvisioningRequestFailedReason, conditionsv1alpha1.ConditionSeverityWarning,
+			"Provisioning webhook failed: %v", err)
+		return 0, nil, fmt.Errorf("failed to call the provisioning webhook of SyncTarget %q: %w", st.Name, err)
+	case !resp.Accepted:
+		message := resp.Message
+		if message == "" {
+			message = "no reason given"
+		}
+		return fail("Provisioning declined: %s", message)
+	}
+	message := fmt.Sprintf("Provisioning requested for %d workloads", len(workloads))
+	if len(workloads) == 1 {
+		message = "Provisioning requested for workload " + workloads[0]
+	}
+	if resp.Message != "" {
+		message += ": " + resp.Message
+	}
+	conditions.MarkFalse(st, tmcv1alpha1.Provisioned, tmcv1alpha1.ProvisioningRequestedReason, conditionsv1alpha1.ConditionSeverityInfo, "%s", message)
+	return deadline.Sub(now), c.newEvent(st, corev1.EventTypeNormal, tmcv1alpha1.ProvisioningRequestedReason, message+"."), nil
+}
+
+// placedOn returns the WorkloadDistributions placed on the SyncTarget, as
+// namespace/name, and their replicas.
+func (c *controller) placedOn(clusterName logicalcluster.Name, name string) ([]string, int32, error) {
+	distributions, err := c.listDistributions(clusterName)
+	if err != nil {
+		return nil, 0, err
+	}
+	var workloads []string
+	var replicas int32
+	for _, d := range distributions {
+		for _, t := range d.Status.Targets {
+			if t.SyncTarget != name {
+				continue
+			}
+			workloads = append(workloads, d.Namespace+"/"+d.Name)
+			if t.Replicas != nil {
+				replicas += *t.Replicas
+			}
+		}
+	}
+	sort.Strings(workloads)
+	return workloads, replicas, nil
+}
+
+func (c *controller) newEvent(syncTarget *tmcv1alpha1.SyncTarget, eventType, reason, message string) *corev1.Event {
+	now := metav1.NewTime(c.now())
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: eventNamespace,
+			Name:      fmt.Sprintf("%s.%x", syncTarget.Name, now.UnixNano()),
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion:      tmcv1alpha1.SchemeGroupVersion.String(),
+			Kind:            "SyncTarget",
+			Name:            syncTarget.Name,
+			UID:             syncTarget.UID,
+			ResourceVersion: syncTarget.ResourceVersion,
+		},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        message,
+		Source:         corev1.EventSource{Component: ControllerName},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/placement/provisioning"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+type fixture struct {
+	now           time.Time
+	syncTarget    *tmcv1alpha1.SyncTarget
+	distributions []*workloadv1alpha1.WorkloadDistribution
+	response      *provisioning.Response
+	webhookErr    error
+
+	requests []*provisioning.Request
+	events   []*corev1.Event
+}
+
+func newFixture() *fixture {
+	return &fixture{
+		now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+		syncTarget: &tmcv1alpha1.SyncTarget{
+			ObjectMeta: metav1.ObjectMeta{Name: "burst"},
+			Spec: tmcv1alpha1.SyncTargetSpec{
+				Provisioning: &tmcv1alpha1.SyncTargetProvisioning{URL: "https://provisioner", Timeout: &metav1.Duration{Duration: 10 * time.Minute}},
+			},
+		},
+		distributions: []*workloadv1alpha1.WorkloadDistribution{{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+			Status: workloadv1alpha1.WorkloadDistributionStatus{
+				Targets: []workloadv1alpha1.TargetPlacement{{SyncTarget: "eu-1", Replicas: ptr.To[int32](2)}, {SyncTarget: "burst", Replicas: ptr.To[int32](3)}},
+			},
+		}},
+		response: &provisioning.Response{Accepted: true},
+	}
+}
+
+func (f *fixture) reconcile(t *testing.T) (time.Duration, error) {
+	t.Helper()
+	c := &controller{
+		now: func() time.Time { return f.now },
+		provision: func(_ context.Context, _ *tmcv1alpha1.SyncTargetProvisioning, req *provisioning.Request) (*provisioning.Response, error) {
+			f.requests = append(f.requests, req)
+			return f.response, f.webhookErr
+		},
+		listDistributions: func(logicalcluster.Name) ([]*workloadv1alpha1.WorkloadDistribution, error) {
+			return f.distributions, nil
+		},
+		updateSyncTargetStatus: func(_ context.Context, _ logicalcluster.Name, st *tmcv1alpha1.SyncTarget) error {
+			f.syncTarget = st
+			return nil
+		},
+		createEvent: func(_ context.Context, _ logicalcluster.Name, event *corev1.Event) error {
+			f.events = append(f.events, event)
+			return nil
+		},
+	}
+	return c.reconcile(context.Background(), logicalcluster.Name("root"), f.syncTarget)
+}
+
+func (f *fixture) setCondition(reason string, since time.Time) {
+	f.syncTarget.Status.Conditions = conditionsv1alpha1.Conditions{{
+		Type: tmcv1alpha1.Provisioned, Status: corev1.ConditionFalse, Reason: reason, LastTransitionTime: metav1.NewTime(since),
+	}}
+}
+
+func TestReconcileRequestsProvisioning(t *testing.T) {
+	f := newFixture()
+	requeueAfter, err := f.reconcile(t)
+	require.NoError(t, err)
+	require.Equal(t, 10*time.Minute, requeueAfter)
+	require.Equal(t, []*provisioning.Request{{Workspace: "root", SyncTarget: "burst", Workloads: []string{"default/web"}, Replicas: 3}}, f.requests)
+	require.Equal(t, tmcv1alpha1.ProvisioningRequestedReason, conditions.GetReason(f.syncTarget, tmcv1alpha1.Provisioned))
+	require.Equal(t, f.now, f.syncTarget.Status.ProvisioningStartTime.Time)
+	require.Len(t, f.events, 1)
+	require.Equal(t, "Provisioning requested for workload default/web.", f.events[0].Message)
+
+	// requested provisioning is not requested again
+	f.now = f.now.Add(4 * time.Minute)
+	requeueAfter, err = f.reconcile(t)
+	require.NoError(t, err)
+	require.Equal(t, 6*time.Minute, requeueAfter)
+	require.Len(t, f.requests, 1)
+
+	// the syncer did not become ready in time
+	f.now = f.now.Add(6 * time.Minute)
+	requeueAfter, err = f.reconcile(t)
+	require.NoError(t, err)
+	require.Equal(t, 10*time.Minute, requeueAfter, "the failure is kept for another timeout")
+	require.Equal(t, tmcv1alpha1.ProvisioningFailedReason, conditions.GetReason(f.syncTarget, tmcv1alpha1.Provisioned))
+	require.Equal(t, "Not provisioned within 10m0s", conditions.GetMessage(f.syncTarget, tmcv1alpha1.Provisioned))
+	require.Nil(t, f.syncTarget.Status.ProvisioningStartTime)
+	require.Len(t, f.events, 2)
+	require.Equal(t, corev1.EventTypeWarning, f.events[1].Type)
+}
+
+func TestReconcileFailedProvisioningIsRetried(t *testing.T) {
+	f := newFixture()
+	f.setCondition(tmcv1alpha1.ProvisioningFailedReason, f.now.Add(-5*time.Minute))
+	requeueAfter, err := f.reconcile(t)
+	require.NoError(t, err)
+	require.Equal(t, 5*time.Minute, requeueAfter)
+	require.Empty(t, f.requests)
+
+	f.now = f.now.Add(5 * time.Minute)
+	_, err = f.reconcile(t)
+	require.NoError(t, err)
+	require.Len(t, f.requests, 1)
+	require.Equal(t, tmcv1alpha1.ProvisioningRequestedReason, conditions.GetReason(f.syncTarget, tmcv1alpha1.Provisioned))
+}
+
+func TestReconcileDeclined(t *testing.T) {
+	f := newFixture()
+	f.response = &provisioning.Response{Message: "quota exhausted"}
+	_, err := f.reconcile(t)
+	require.NoError(t, err)
+	require.Equal(t, tmcv1alpha1.ProvisioningFailedReason, conditions.GetReason(f.syncTarget, tmcv1alpha1.Provisioned))
+	require.Equal(t, "Provisioning declined: quota exhausted", conditions.GetMessage(f.syncTarget, tmcv1alpha1.Provisioned))
+}
+
+func TestReconcileWebhookError(t *testing.T) {
+	f := newFixture()
+	f.webhookErr = errors.New("connection refused")
+	_, err := f.reconcile(t)
+	require.ErrorContains(t, err, "connection refused")
+	require.Equal(t, tmcv1alpha1.ProvisioningRequestFailedReason, conditions.GetReason(f.syncTarget, tmcv1alpha1.Provisioned))
+	require.Equal(t, f.now, f.syncTarget.Status.ProvisioningStartTime.Time)
+
+	// the timeout counts from the first attempt
+	f.now = f.now.Add(10 * time.Minute)
+	_, err = f.reconcile(t)
+	require.NoError(t, err)
+	require.Len(t, f.requests, 1)
+	require.Equal(t, tmcv1alpha1.ProvisioningFailedReason, conditions.GetReason(f.syncTarget, tmcv1alpha1.Provisioned))
+}
+
+func TestReconcileNothingPlaced(t *testing.T) {
+	f := newFixture()
+	f.distributions = nil
+	f.setCondition(tmcv1alpha1.ProvisioningRequestFailedReason, f.now)
+	f.syncTarget.Status.ProvisioningStartTime = &metav1.Time{Time: f.now}
+	_, err := f.reconcile(t)
+	require.NoError(t, err)
+	require.Empty(t, f.requests)
+	require.Nil(t, conditions.Get(f.syncTarget, tmcv1alpha1.Provisioned))
+	require.Nil(t, f.syncTarget.Status.ProvisioningStartTime)
+}
+
+func TestReconcileProvisioned(t *testing.T) {
+	f := newFixture()
+	f.setCondition(tmcv1alpha1.ProvisioningRequestedReason, f.now.Add(-3*time.Minute))
+	f.syncTarget.Status.ProvisioningStartTime = &metav1.Time{Time: f.now.Add(-3 * time.Minute)}
+	conditions.MarkTrue(f.syncTarget, tmcv1alpha1.SyncerReady)
+	_, err := f.reconcile(t)
+	require.NoError(t, err)
+	require.True(t, conditions.IsTrue(f.syncTarget, tmcv1alpha1.Provisioned))
+	require.Nil(t, f.syncTarget.Status.ProvisioningStartTime)
+	require.Len(t, f.events, 1)
+	require.Equal(t, "The syncer is ready 3m0s after provisioning was requested.", f.events[0].Message)
+
+	// the capacity is gone once the syncer is, and is provisioned anew
+	conditions.Delete(f.syncTarget, tmcv1alpha1.SyncerReady)
+	_, err = f.reconcile(t)
+	require.NoError(t, err)
+	require.Len(t, f.requests, 1)
+	require.Equal(t, tmcv1alpha1.ProvisioningRequestedReason, conditions.GetReason(f.syncTarget, tmcv1alpha1.Provisioned))
+}
@@ -0,0 +1,312 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcequota
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	kcpcache "github.com/kcp-dev/apimachinery/v2/pkg/cache"
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/reconciler/dynamicrestmapper"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/tmcinformers"
+	"github.com/kcp-dev/kcp/pkg/tmc/workspacequeue"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+const (
+	ControllerName = "kcp-tmc-resource-quota"
+
+	// syncWait is how long to wait before retrying a quota whose workload
+	// informers have not synced yet.
+	syncWait = time.Second
+)
+
+var (
+	// MultiClusterResourceQuotasGVR is the resource of MultiClusterResourceQuotas.
+	MultiClusterResourceQuotasGVR = tmcv1alpha1.SchemeGroupVersion.WithResource("multiclusterresourcequotas")
+	// WorkloadDistributionsGVR is the resource of WorkloadDistributions.
+	WorkloadDistributionsGVR = workloadv1alpha1.SchemeGroupVersion.WithResource("workloaddistributions")
+)
+
+// NewController returns a controller that reports the usage of the
+// workloads of a workspace against its MultiClusterResourceQuotas, in total
+// and by SyncTarget. It records the usage of every workload on its
+// WorkloadDistributions, where the placement controller checks it against
+// the quotas before placing the workload on more SyncTargets.
+// workloadInformer returns the started informer of a workload resource,
+// which the controller watches once a workspace with quotas distributes a
+// workload of the resource.
+func NewController(
+	quotaClusterInformer *tmcinformers.Informer,
+	distributionClusterInformer *tmcinformers.Informer,
+	workloadInformer func(gvr schema.GroupVersionResource) *tmcinformers.Informer,
+	dynamicClusterClient kcpdynamic.ClusterInterface,
+	dynRESTMapper *dynamicrestmapper.DynamicRESTMapper,
+) (*controller, error) {
+	c := &controller{
+		queue:    workspacequeue.NewRateLimitingQueue(ControllerName),
+		informed: map[schema.GroupVersionResource]*tmcinformers.Informer{},
+		getQuota: func(clusterName logicalcluster.Name, name string) (*tmcv1alpha1.MultiClusterResourceQuota, error) {
+			obj, err := quotaClusterInformer.Lister(clusterName).Get(name)
+			if err != nil {
+				return nil, err
+			}
+			quota := &tmcv1alpha1.MultiClusterResourceQuota{}
+			return quota, fromUnstructured(obj, quota)
+		},
+		listDistributions: func(clusterName logicalcluster.Name) ([]*workloadv1alpha1.WorkloadDistribution, error) {
+			objs, err := distributionClusterInformer.Lister(clusterName).List(labels.Everything())
+			if err != nil {
+				return nil, err
+			}
+			distributions := make([]*workloadv1alpha1.WorkloadDistribution, 0, len(objs))
+			for _, obj := range objs {
+				distribution := &workloadv1alpha1.WorkloadDistribution{}
+				if err := fromUnstructured(obj, distribution); err != nil {
+					return nil, err
+				}
+				distributions = append(distributions, distribution)
+			}
+			return distributions, nil
+		},
+		restMapping: func(clusterName logicalcluster.Name, gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+			return dynRESTMapper.ForCluster(clusterName).RESTMapping(gvk.GroupKind(), gvk.Version)
+		},
+		updateDistribution: func(ctx context.Context, clusterName logicalcluster.Name, distribution *workloadv1alpha1.WorkloadDistribution) error {
+			u, err := toUnstructured(distribution)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(WorkloadDistributionsGVR).Namespace(distribution.Namespace).Update(ctx, u, metav1.UpdateOptions{})
+			return err
+		},
+		updateQuotaStatus: func(ctx context.Context, clusterName logicalcluster.Name, quota *tmcv1alpha1.MultiClusterResourceQuota) error {
+			u, err := toUnstructured(quota)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(MultiClusterResourceQuotasGVR).UpdateStatus(ctx, u, metav1.UpdateOptions{})
+			return err
+		},
+	}
+	c.getWorkload = func(clusterName logicalcluster.Name, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, bool, error) {
+		informer := c.informWorkloads(gvr, workloadInformer, quotaClusterInformer)
+		if !informer.HasSynced() {
+			return nil, false, nil
+		}
+		obj, err := informer.Lister(clusterName).ByNamespace(namespace).Get(name)
+		if err != nil {
+			return nil, true, err
+		}
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return nil, true, fmt.Errorf("expected *unstructured.Unstructured, got %T", obj)
+		}
+		return u, true, nil
+	}
+
+	quotaClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueue(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+	})
+	distributionClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueueQuotasInCluster(quotaClusterInformer, obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueueQuotasInCluster(quotaClusterInformer, obj) },
+		DeleteFunc: func(obj interface{}) { c.enqueueQuotasInCluster(quotaClusterInformer, obj) },
+	})
+
+	return c, nil
+}
+
+// controller maintains the status of MultiClusterResourceQuotas and the
+// resource usage of WorkloadDistributions.
+type controller struct {
+	queue workqueue.TypedRateLimitingInterface[string]
+
+	// informed are the informers of the workload resources the controller
+	// handles events of.
+	informedLock sync.Mutex
+	informed     map[schema.GroupVersionResource]*tmcinformers.Informer
+
+	getQuota          func(clusterName logicalcluster.Name, name string) (*tmcv1alpha1.MultiClusterResourceQuota, error)
+	listDistributions func(clusterName logicalcluster.Name) ([]*workloadv1alpha1.WorkloadDistribution, error)
+	restMapping       func(clusterName logicalcluster.Name, gvk schema.GroupVersionKind) (*meta.RESTMapping, error)
+	// getWorkload returns the workload, and false if the informer of its
+	// resource has not synced yet.
+	getWorkload        func(clusterName logicalcluster.Name, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, bool, error)
+	updateDistribution func(ctx context.Context, clusterName logicalcluster.Name, distribution *workloadv1alpha1.WorkloadDistribution) error
+	updateQuotaStatus  func(ctx context.Context, clusterName logicalcluster.Name, quota *tmcv1alpha1.MultiClusterResourceQuota) error
+}
+
+// informWorkloads returns the informer of the workload resource gvr, and
+// enqueues the quotas of the workspace of its objects when they change.
+func (c *controller) informWorkloads(gvr schema.GroupVersionResource, workloadInformer func(gvr schema.GroupVersionResource) *tmcinformers.Informer, quotaClusterInformer *tmcinformers.Informer) *tmcinformers.Informer {
+	c.informedLock.Lock()
+	defer c.informedLock.Unlock()
+
+	if informer, found := c.informed[gvr]; found {
+		return informer
+	}
+	informer := workloadInformer(gvr)
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueueQuotasInCluster(quotaClusterInformer, obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueueQuotasInCluster(quotaClusterInformer, obj) },
+	})
+	c.informed[gvr] = informer
+	return informer
+}
+
+func (c *controller) enqueue(obj interface{}) {
+	key, err := kcpcache.DeletionHandlingMetaClusterNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+
+	logger := logging.WithQueueKey(logging.WithReconciler(klog.Background(), ControllerName), key)
+	logger.V(4).Info("queueing MultiClusterResourceQuota")
+	c.queue.Add(key)
+}
+
+// enqueueQuotasInCluster enqueues the quotas in the logical cluster of obj.
+// Workspaces have few quotas, so they are not indexed.
+func (c *controller) enqueueQuotasInCluster(quotaClusterInformer *tmcinformers.Informer, obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		utilruntime.HandleError(fmt.Errorf("expected *unstructured.Unstructured, got %T", obj))
+		return
+	}
+
+	quotas, err := quotaClusterInformer.Lister(logicalcluster.From(u)).List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, quota := range quotas {
+		c.enqueue(quota)
+	}
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	logger := logging.WithReconciler(klog.FromContext(ctx), ControllerName)
+	ctx = klog.NewContext(ctx, logger)
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	for range numThreads {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+
+	logger := logging.WithQueueKey(klog.FromContext(ctx), key)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(4).Info("processing key")
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	requeueAfter, err := c.process(ctx, key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", ControllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	if requeueAfter > 0 {
+		c.queue.AddAfter(key, requeueAfter)
+	}
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) (time.Duration, error) {
+	clusterName, _, name, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return 0, nil
+	}
+
+	quota, err := c.getQuota(clusterName, name)
+	if errors.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !quota.DeletionTimestamp.IsZero() {
+		return 0, nil
+	}
+
+	return c.reconcile(ctx, clusterName, quota)
+}
+
+func fromUnstructured(obj runtime.Object, into interface{}) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("expected *unstructured.Unstructured, got %T", obj)
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, into)
+}
+
+func toUnstructured(obj interface{}) (*unstructured.Unstructured, error) {
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: raw}, nil
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcequota
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/placement/quota"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+func (c *controller) reconcile(ctx context.Context, clusterName logicalcluster.Name, resourceQuota *tmcv1alpha1.MultiClusterResourceQuota) (time.Duration, error) {
+	logger := klog.FromContext(ctx)
+
+	distributions, err := c.listDistributions(clusterName)
+	if err != nil {
+		return 0, err
+	}
+	var requeueAfter time.Duration
+	bySyncTarget := map[string]corev1.ResourceList{}
+	for _, d := range distributions {
+		used, synced, err := c.usage(ctx, clusterName, d)
+		if err != nil {
+			return 0, err
+		}
+		if !synced {
+			requeueAfter = syncWait
+		}
+		for _, t := range d.Status.Targets {
+			bySyncTarget[t.SyncTarget] = quotav1.Add(bySyncTarget[t.SyncTarget], used)
+		}
+	}
+
+	names := quotav1.ResourceNames(resourceQuota.Spec.Hard)
+	rq := resourceQuota.DeepCopy()
+	rq.Status.Hard = resourceQuota.Spec.Hard
+	rq.Status.Used = corev1.ResourceList{}
+	rq.Status.SyncTargets = nil
+	for syncTarget, used := range bySyncTarget {
+		used = quotav1.Mask(used, names)
+		if len(used) == 0 {
+			continue
+		}
+		rq.Status.Used = quotav1.Add(rq.Status.Used, used)
+		rq.Status.SyncTargets = append(rq.Status.SyncTargets, tmcv1alpha1.SyncTargetResourceUsage{SyncTarget: syncTarget, Used: used})
+	}
+	// Limited resources nothing uses are reported as 0.
+	for _, name := range names {
+		if _, found := rq.Status.Used[name]; !found {
+			rq.Status.Used[name] = *resource.NewQuantity(0, resourceQuota.Spec.Hard[name].Format)
+		}
+	}
+	sort.Slice(rq.Status.SyncTargets, func(i, j int) bool { return rq.Status.SyncTargets[i].SyncTarget < rq.Status.SyncTargets[j].SyncTarget })
+
+	if exceeded := quota.Exceeded(resourceQuota.Spec.Hard, rq.Status.Used); len(exceeded) > 0 {
+		conditions.Set(rq, &conditionsv1alpha1.Condition{
+			Type:     tmcv1alpha1.QuotaExceeded,
+			Status:   corev1.ConditionTrue,
+			Severity: conditionsv1alpha1.ConditionSeverityWarning,
+			Reason:   tmcv1alpha1.UsageExceedsHardReason,
+			Message:  "Usage exceeds the quota: " + strings.Join(exceeded, ", "),
+		})
+	} else {
+		conditions.Set(rq, &conditionsv1alpha1.Condition{Type: tmcv1alpha1.QuotaExceeded, Status: corev1.ConditionFalse})
+	}
+
+	if equality.Semantic.DeepEqual(resourceQuota.Status, rq.Status) {
+		return requeueAfter, nil
+	}
+	logger.V(2).Info("updating MultiClusterResourceQuota status", "used", rq.Status.Used)
+	if err := c.updateQuotaStatus(ctx, clusterName, rq); err != nil {
+		return 0, err
+	}
+	return requeueAfter, nil
+}
+
+// usage returns the resources the workload of d uses on every SyncTarget,
+// and records them on d if they changed. It returns the recorded usage, and
+// false, while the informer of the workload resource has not synced.
+func (c *controller) usage(ctx context.Context, clusterName logicalcluster.Name, d *workloadv1alpha1.WorkloadDistribution) (corev1.ResourceList, bool, error) {
+	recorded, _, err := quota.Unmarshal(d)
+	if err != nil {
+		utilruntime.HandleError(err)
+	}
+
+	ref := d.Spec.WorkloadRef
+	gvk := schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind)
+	mapping, err := c.restMapping(clusterName, gvk)
+	if err != nil {
+		// The resource may not be served yet, e.g. of a CRD being created.
+		utilruntime.HandleError(fmt.Errorf("failed to map %s: %w", gvk, err))
+		return recorded, true, nil
+	}
+	workload, synced, err := c.getWorkload(clusterName, mapping.Resource, d.Namespace, ref.Name)
+	if !synced {
+		return recorded, false, nil
+	}
+	if errors.IsNotFound(err) {
+		// Nothing is synced to the SyncTargets of a missing workload.
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, true, err
+	}
+
+	used, err := quota.Usage(workload, mapping.Resource.GroupResource())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return recorded, true, nil
+	}
+	if recorded != nil && quotav1.Equals(recorded, used) {
+		return used, true, nil
+	}
+	value, err := quota.Marshal(used)
+	if err != nil {
+		return nil, true, err
+	}
+	d = d.DeepCopy()
+	if d.Annotations == nil {
+		d.Annotations = map[string]string{}
+	}
+	d.Annotations[tmcv1alpha1.AnnotationResourceUsage] = value
+	klog.FromContext(ctx).V(2).Info("recording resource usage of WorkloadDistribution", "namespace", d.Namespace, "name", d.Name, "used", value)
+	return used, true, c.updateDistribution(ctx, clusterName, d)
+}
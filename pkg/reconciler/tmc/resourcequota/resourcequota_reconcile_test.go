@@ -0,0 +1,181 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcequota
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var deploymentsGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+func newDistribution(name string, syncTargets ...string) *workloadv1alpha1.WorkloadDistribution {
+	distribution := &workloadv1alpha1.WorkloadDistribution{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: workloadv1alpha1.WorkloadDistributionSpec{
+			WorkloadRef: workloadv1alpha1.WorkloadReference{APIVersion: "apps/v1", Kind: "Deployment", Name: name},
+		},
+	}
+	for _, syncTarget := range syncTargets {
+		distribution.Status.Targets = append(distribution.Status.Targets, workloadv1alpha1.TargetPlacement{SyncTarget: syncTarget})
+	}
+	return distribution
+}
+
+func newWorkload(name string, replicas int64, cpu string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"namespace": "default", "name": name},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+			"template": map[string]interface{}{"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": name, "resources": map[string]interface{}{"requests": map[string]interface{}{"cpu": cpu}}},
+				},
+			}},
+		},
+	}}
+}
+
+func newQuota(hard corev1.ResourceList) *tmcv1alpha1.MultiClusterResourceQuota {
+	return &tmcv1alpha1.MultiClusterResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "team"},
+		Spec:       tmcv1alpha1.MultiClusterResourceQuotaSpec{Hard: hard},
+	}
+}
+
+type fakes struct {
+	distributions []*workloadv1alpha1.WorkloadDistribution
+	workloads     map[string]*unstructured.Unstructured
+	unsynced      bool
+
+	updatedDistributions []*workloadv1alpha1.WorkloadDistribution
+	updatedQuota         *tmcv1alpha1.MultiClusterResourceQuota
+}
+
+func (f *fakes) controller() *controller {
+	return &controller{
+		listDistributions: func(logicalcluster.Name) ([]*workloadv1alpha1.WorkloadDistribution, error) {
+			return f.distributions, nil
+		},
+		restMapping: func(_ logicalcluster.Name, gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+			return &meta.RESTMapping{Resource: deploymentsGVR, GroupVersionKind: gvk, Scope: meta.RESTScopeNamespace}, nil
+		},
+		getWorkload: func(_ logicalcluster.Name, _ schema.GroupVersionResource, _, name string) (*unstructured.Unstructured, bool, error) {
+			if f.unsynced {
+				return nil, false, nil
+			}
+			if workload, found := f.workloads[name]; found {
+				return workload, true, nil
+			}
+			return nil, true, apierrors.NewNotFound(deploymentsGVR.GroupResource(), name)
+		},
+		updateDistribution: func(_ context.Context, _ logicalcluster.Name, distribution *workloadv1alpha1.WorkloadDistribution) error {
+			f.updatedDistributions = append(f.updatedDistributions, distribution)
+			return nil
+		},
+		updateQuotaStatus: func(_ context.Context, _ logicalcluster.Name, quota *tmcv1alpha1.MultiClusterResourceQuota) error {
+			f.updatedQuota = quota
+			return nil
+		},
+	}
+}
+
+func requireQuantities(t *testing.T, want map[corev1.ResourceName]string, got corev1.ResourceList) {
+	t.Helper()
+	require.Len(t, got, len(want))
+	for name, q := range want {
+		want := resource.MustParse(q)
+		actual := got[name]
+		require.Zero(t, want.Cmp(actual), "%s: want %s, got %s", name, q, actual.String())
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	f := &fakes{
+		distributions: []*workloadv1alpha1.WorkloadDistribution{
+			newDistribution("web", "east", "west"),
+			newDistribution("api", "east"),
+			newDistribution("unplaced"),
+			newDistribution("deleted", "west"),
+		},
+		workloads: map[string]*unstructured.Unstructured{
+			"web":      newWorkload("web", 2, "500m"),
+			"api":      newWorkload("api", 1, "2"),
+			"unplaced": newWorkload("unplaced", 1, "8"),
+		},
+	}
+	quota := newQuota(corev1.ResourceList{
+		corev1.ResourceCPU:       resource.MustParse("4"),
+		corev1.ResourceMemory:    resource.MustParse("1Gi"),
+		"count/deployments.apps": resource.MustParse("5"),
+	})
+
+	requeueAfter, err := f.controller().reconcile(context.Background(), "root:org:ws", quota)
+	require.NoError(t, err)
+	require.Zero(t, requeueAfter)
+
+	require.Len(t, f.updatedDistributions, 3, "the usage of existing workloads is recorded")
+	require.Equal(t, `{"count/deployments.apps":"1","cpu":"1"}`, f.updatedDistributions[0].Annotations[tmcv1alpha1.AnnotationResourceUsage])
+
+	status := f.updatedQuota.Status
+	requireQuantities(t, map[corev1.ResourceName]string{"cpu": "4", "memory": "0", "count/deployments.apps": "3"}, status.Used)
+	require.Len(t, status.SyncTargets, 2)
+	require.Equal(t, "east", status.SyncTargets[0].SyncTarget)
+	requireQuantities(t, map[corev1.ResourceName]string{"cpu": "3", "count/deployments.apps": "2"}, status.SyncTargets[0].Used)
+	require.Equal(t, "west", status.SyncTargets[1].SyncTarget)
+	requireQuantities(t, map[corev1.ResourceName]string{"cpu": "1", "count/deployments.apps": "1"}, status.SyncTargets[1].Used)
+	require.True(t, conditions.IsFalse(f.updatedQuota, tmcv1alpha1.QuotaExceeded))
+
+	// The usage is recorded now, and the quota lowered.
+	f.distributions = []*workloadv1alpha1.WorkloadDistribution{f.updatedDistributions[0], f.updatedDistributions[1]}
+	f.updatedDistributions = nil
+	quota.Spec.Hard[corev1.ResourceCPU] = resource.MustParse("3")
+	_, err = f.controller().reconcile(context.Background(), "root:org:ws", quota)
+	require.NoError(t, err)
+	require.Empty(t, f.updatedDistributions, "recorded usage is not updated")
+	require.True(t, conditions.IsTrue(f.updatedQuota, tmcv1alpha1.QuotaExceeded))
+	require.Equal(t, "Usage exceeds the quota: cpu: 4 of at most 3", conditions.GetMessage(f.updatedQuota, tmcv1alpha1.QuotaExceeded))
+}
+
+func TestReconcileInformerNotSynced(t *testing.T) {
+	recorded := newDistribution("web", "east")
+	recorded.Annotations = map[string]string{tmcv1alpha1.AnnotationResourceUsage: `{"cpu":"2"}`}
+	f := &fakes{distributions: []*workloadv1alpha1.WorkloadDistribution{recorded}, unsynced: true}
+
+	requeueAfter, err := f.controller().reconcile(context.Background(), "root:org:ws", newQuota(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")}))
+	require.NoError(t, err)
+	require.Equal(t, syncWait, requeueAfter)
+	requireQuantities(t, map[corev1.ResourceName]string{"cpu": "2"}, f.updatedQuota.Status.Used)
+}
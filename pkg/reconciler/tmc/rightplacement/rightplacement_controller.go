@@ -0,0 +1,266 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rightplacement
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	kcpcache "github.com/kcp-dev/apimachinery/v2/pkg/cache"
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/placement/reference"
+	"github.com/kcp-dev/kcp/pkg/placement/rightplacement"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/policyrollout"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/tmcinformers"
+	"github.com/kcp-dev/kcp/pkg/tmc/workspacequeue"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+const (
+	ControllerName = "kcp-tmc-rightplacement"
+)
+
+// NewController returns a controller that applies accepted
+// RightPlacementRecommendations, and the recommendations of accepted
+// RebalancePlans, as target overrides of their WorkloadDistributions.
+func NewController(
+	recommendationClusterInformer *tmcinformers.Informer,
+	planClusterInformer *tmcinformers.Informer,
+	distributionClusterInformer *tmcinformers.Informer,
+	dynamicClusterClient kcpdynamic.ClusterInterface,
+) (*controller, error) {
+	resolver := reference.NewResolver()
+	resolver.AddInformer(policyrollout.WorkloadDistributionsGVR.GroupResource(), distributionClusterInformer)
+
+	c := &controller{
+		queue: workspacequeue.NewRateLimitingQueue(ControllerName),
+		getRecommendation: func(clusterName logicalcluster.Name, namespace, name string) (*placementv1alpha1.RightPlacementRecommendation, error) {
+			obj, err := recommendationClusterInformer.Lister(clusterName).ByNamespace(namespace).Get(name)
+			if err != nil {
+				return nil, err
+			}
+			recommendation := &placementv1alpha1.RightPlacementRecommendation{}
+			return recommendation, fromUnstructured(obj, recommendation)
+		},
+		getPlan: func(clusterName logicalcluster.Name, namespace string) (*placementv1alpha1.RebalancePlan, error) {
+			obj, err := planClusterInformer.Lister(clusterName).ByNamespace(namespace).Get(placementv1alpha1.RebalancePlanName)
+			if err != nil {
+				return nil, err
+			}
+			plan := &placementv1alpha1.RebalancePlan{}
+			return plan, fromUnstructured(obj, plan)
+		},
+		getDistribution: func(clusterName logicalcluster.Name, namespace, name string) (*workloadv1alpha1.WorkloadDistribution, error) {
+			return reference.Get[workloadv1alpha1.WorkloadDistribution](resolver, clusterName, reference.Reference{Resource: policyrollout.WorkloadDistributionsGVR.GroupResource(), Namespace: namespace, Name: name})
+		},
+		updateDistribution: func(ctx context.Context, clusterName logicalcluster.Name, distribution *workloadv1alpha1.WorkloadDistribution) error {
+			u, err := toUnstructured(distribution)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(policyrollout.WorkloadDistributionsGVR).Namespace(distribution.Namespace).Update(ctx, u, metav1.UpdateOptions{})
+			return err
+		},
+		updateRecommendationStatus: func(ctx context.Context, clusterName logicalcluster.Name, recommendation *placementv1alpha1.RightPlacementRecommendation) error {
+			u, err := toUnstructured(recommendation)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(rightplacement.RecommendationsGVR).Namespace(recommendation.Namespace).UpdateStatus(ctx, u, metav1.UpdateOptions{})
+			return err
+		},
+	}
+
+	recommendationClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueue(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+	})
+	planClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueueForPlan(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueueForPlan(obj) },
+	})
+	distributionClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) { c.enqueueForDistribution(recommendationClusterInformer, obj) },
+	})
+
+	return c, nil
+}
+
+// controller applies accepted RightPlacementRecommendations.
+type controller struct {
+	queue workqueue.TypedRateLimitingInterface[string]
+
+	getRecommendation          func(clusterName logicalcluster.Name, namespace, name string) (*placementv1alpha1.RightPlacementRecommendation, error)
+	getPlan                    func(clusterName logicalcluster.Name, namespace string) (*placementv1alpha1.RebalancePlan, error)
+	getDistribution            func(clusterName logicalcluster.Name, namespace, name string) (*workloadv1alpha1.WorkloadDistribution, error)
+	updateDistribution         func(ctx context.Context, clusterName logicalcluster.Name, distribution *workloadv1alpha1.WorkloadDistribution) error
+	updateRecommendationStatus func(ctx context.Context, clusterName logicalcluster.Name, recommendation *placementv1alpha1.RightPlacementRecommendation) error
+}
+
+func (c *controller) enqueue(obj interface{}) {
+	key, err := kcpcache.DeletionHandlingMetaClusterNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+
+	logger := logging.WithQueueKey(logging.WithReconciler(klog.Background(), ControllerName), key)
+	logger.V(4).Info("queueing RightPlacementRecommendation")
+	c.queue.Add(key)
+}
+
+// enqueueForPlan enqueues the recommendations of the moves of a plan.
+func (c *controller) enqueueForPlan(obj interface{}) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		utilruntime.HandleError(fmt.Errorf("expected *unstructured.Unstructured, got %T", obj))
+		return
+	}
+	plan := &placementv1alpha1.RebalancePlan{}
+	if err := fromUnstructured(u, plan); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, move := range plan.Spec.Moves {
+		c.queue.Add(kcpcache.ToClusterAwareKey(logicalcluster.From(u).String(), u.GetNamespace(), move.Recommendation))
+	}
+}
+
+// enqueueForDistribution enqueues the recommendations of a distribution that
+// was created after them.
+func (c *controller) enqueueForDistribution(recommendationClusterInformer *tmcinformers.Informer, obj interface{}) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		utilruntime.HandleError(fmt.Errorf("expected *unstructured.Unstructured, got %T", obj))
+		return
+	}
+
+	objs, err := recommendationClusterInformer.Lister(logicalcluster.From(u)).ByNamespace(u.GetNamespace()).List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, obj := range objs {
+		recommendation := &placementv1alpha1.RightPlacementRecommendation{}
+		if err := fromUnstructured(obj, recommendation); err != nil {
+			utilruntime.HandleError(err)
+			continue
+		}
+		if recommendation.Spec.DistributionRef.Name == u.GetName() {
+			c.enqueue(obj)
+		}
+	}
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	logger := logging.WithReconciler(klog.FromContext(ctx), ControllerName)
+	ctx = klog.NewContext(ctx, logger)
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	for range numThreads {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+
+	logger := logging.WithQueueKey(klog.FromContext(ctx), key)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(4).Info("processing key")
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		utilruntime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", ControllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) error {
+	clusterName, namespace, name, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return nil
+	}
+
+	recommendation, err := c.getRecommendation(clusterName, namespace, name)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !recommendation.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	return c.reconcile(ctx, clusterName, recommendation)
+}
+
+func fromUnstructured(obj runtime.Object, into interface{}) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("expected *unstructured.Unstructured, got %T", obj)
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, into)
+}
+
+func toUnstructured(obj interface{}) (*unstructured.Unstructured, error) {
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: raw}, nil
+}
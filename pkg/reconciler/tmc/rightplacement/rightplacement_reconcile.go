@@ -0,0 +1,117 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rightplacement
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+func (c *controller) reconcile(ctx context.Context, clusterName logicalcluster.Name, recommendation *placementv1alpha1.RightPlacementRecommendation) error {
+	logger := klog.FromContext(ctx)
+
+	accepted, err := c.accepted(clusterName, recommendation)
+	if err != nil || !accepted {
+		return err
+	}
+
+	r := recommendation.DeepCopy()
+	if err := c.apply(ctx, clusterName, r); err != nil {
+		return err
+	}
+
+	if equality.Semantic.DeepEqual(recommendation.Status, r.Status) {
+		return nil
+	}
+	logger.V(2).Info("updating RightPlacementRecommendation status", "applied", conditions.IsTrue(r, placementv1alpha1.RecommendationApplied))
+	return c.updateRecommendationStatus(ctx, clusterName, r)
+}
+
+// accepted returns whether a recommendation is accepted by an operator, or
+// is pending and a move of the accepted RebalancePlan of its namespace.
+func (c *controller) accepted(clusterName logicalcluster.Name, recommendation *placementv1alpha1.RightPlacementRecommendation) (bool, error) {
+	switch recommendation.Spec.Action {
+	case placementv1alpha1.RecommendationActionAccepted:
+		return true, nil
+	case "", placementv1alpha1.RecommendationActionPending:
+	default:
+		return false, nil
+	}
+
+	plan, err := c.getPlan(clusterName, recommendation.Namespace)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if plan.Spec.Action != placementv1alpha1.RecommendationActionAccepted {
+		return false, nil
+	}
+	for _, move := range plan.Spec.Moves {
+		// The recommendation may have changed since it was planned.
+		if move.Recommendation == recommendation.Name && move.From == recommendation.Spec.From && move.To == recommendation.Spec.To {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// apply adds the target override of r to its distribution, replacing an
+// earlier override of the same target, and updates the status of r.
+func (c *controller) apply(ctx context.Context, clusterName logicalcluster.Name, r *placementv1alpha1.RightPlacementRecommendation) error {
+	distribution, err := c.getDistribution(clusterName, r.Namespace, r.Spec.DistributionRef.Name)
+	if apierrors.IsNotFound(err) {
+		conditions.MarkFalse(r, placementv1alpha1.RecommendationApplied, placementv1alpha1.DistributionNotFoundReason, conditionsv1alpha1.ConditionSeverityError,
+			"WorkloadDistribution %q not found", r.Spec.DistributionRef.Name)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	override := workloadv1alpha1.TargetOverride{From: r.Spec.From, To: r.Spec.To}
+	d := distribution.DeepCopy()
+	found := false
+	for i, o := range d.Spec.TargetOverrides {
+		if o.From == override.From {
+			d.Spec.TargetOverrides[i], found = override, true
+		}
+	}
+	if !found {
+		d.Spec.TargetOverrides = append(d.Spec.TargetOverrides, override)
+	}
+	if !equality.Semantic.DeepEqual(distribution.Spec, d.Spec) {
+		klog.FromContext(ctx).V(2).Info("adding target override to WorkloadDistribution", "distribution", d.Name, "from", override.From, "to", override.To)
+		if err := c.updateDistribution(ctx, clusterName, d); err != nil {
+			return err
+		}
+	}
+
+	conditions.MarkTrue(r, placementv1alpha1.RecommendationApplied)
+	return nil
+}
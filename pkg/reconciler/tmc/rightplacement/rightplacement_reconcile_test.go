@@ -0,0 +1,152 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rightplacement
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+func TestReconcile(t *testing.T) {
+	recommendation := &placementv1alpha1.RightPlacementRecommendation{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app-eu-1"},
+		Spec: placementv1alpha1.RightPlacementRecommendationSpec{
+			DistributionRef: placementv1alpha1.LocalReference{Name: "app"},
+			From:            "eu-1",
+			To:              "eu-2",
+			Reason:          placementv1alpha1.RecommendationReasonLowerCost,
+			Action:          placementv1alpha1.RecommendationActionPending,
+		},
+	}
+	var distribution *workloadv1alpha1.WorkloadDistribution
+
+	var distributionUpdates, statusUpdates int
+	c := &controller{
+		getPlan: func(_ logicalcluster.Name, _ string) (*placementv1alpha1.RebalancePlan, error) {
+			return nil, apierrors.NewNotFound(placementv1alpha1.Resource("rebalanceplans"), placementv1alpha1.RebalancePlanName)
+		},
+		getDistribution: func(_ logicalcluster.Name, _, name string) (*workloadv1alpha1.WorkloadDistribution, error) {
+			if distribution == nil {
+				return nil, apierrors.NewNotFound(workloadv1alpha1.Resource("workloaddistributions"), name)
+			}
+			return distribution, nil
+		},
+		updateDistribution: func(_ context.Context, _ logicalcluster.Name, d *workloadv1alpha1.WorkloadDistribution) error {
+			distributionUpdates++
+			distribution = d
+			return nil
+		},
+		updateRecommendationStatus: func(_ context.Context, _ logicalcluster.Name, r *placementv1alpha1.RightPlacementRecommendation) error {
+			statusUpdates++
+			recommendation = r
+			return nil
+		},
+	}
+	reconcile := func() {
+		t.Helper()
+		require.NoError(t, c.reconcile(context.Background(), "root:org", recommendation))
+	}
+
+	reconcile()
+	require.Zero(t, statusUpdates, "pending recommendations are not applied")
+
+	recommendation.Spec.Action = placementv1alpha1.RecommendationActionAccepted
+	reconcile()
+	require.Equal(t, placementv1alpha1.DistributionNotFoundReason, conditions.GetReason(recommendation, placementv1alpha1.RecommendationApplied))
+
+	distribution = &workloadv1alpha1.WorkloadDistribution{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"},
+		Spec: workloadv1alpha1.WorkloadDistributionSpec{TargetOverrides: []workloadv1alpha1.TargetOverride{
+			{From: "eu-1", To: "eu-3"},
+			{From: "us-1", To: "us-2"},
+		}},
+	}
+	reconcile()
+	require.True(t, conditions.IsTrue(recommendation, placementv1alpha1.RecommendationApplied))
+	require.Equal(t, 1, distributionUpdates)
+	require.Equal(t, []workloadv1alpha1.TargetOverride{{From: "eu-1", To: "eu-2"}, {From: "us-1", To: "us-2"}}, distribution.Spec.TargetOverrides,
+		"the earlier override of the same target is replaced")
+
+	reconcile()
+	require.Equal(t, 1, distributionUpdates)
+	require.Equal(t, 2, statusUpdates, "applied recommendations are stable")
+}
+
+func TestReconcileAcceptedPlan(t *testing.T) {
+	recommendation := &placementv1alpha1.RightPlacementRecommendation{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app-eu-1"},
+		Spec: placementv1alpha1.RightPlacementRecommendationSpec{
+			DistributionRef: placementv1alpha1.LocalReference{Name: "app"},
+			From:            "eu-1",
+			To:              "eu-2",
+			Action:          placementv1alpha1.RecommendationActionPending,
+		},
+	}
+	plan := &placementv1alpha1.RebalancePlan{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: placementv1alpha1.RebalancePlanName},
+		Spec: placementv1alpha1.RebalancePlanSpec{
+			Moves:  []placementv1alpha1.RebalanceMove{{Recommendation: "app-eu-1", From: "eu-1", To: "eu-3"}},
+			Action: placementv1alpha1.RecommendationActionAccepted,
+		},
+	}
+	distribution := &workloadv1alpha1.WorkloadDistribution{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"}}
+
+	c := &controller{
+		getPlan: func(_ logicalcluster.Name, _ string) (*placementv1alpha1.RebalancePlan, error) {
+			return plan, nil
+		},
+		getDistribution: func(_ logicalcluster.Name, _, _ string) (*workloadv1alpha1.WorkloadDistribution, error) {
+			return distribution, nil
+		},
+		updateDistribution: func(_ context.Context, _ logicalcluster.Name, d *workloadv1alpha1.WorkloadDistribution) error {
+			distribution = d
+			return nil
+		},
+		updateRecommendationStatus: func(_ context.Context, _ logicalcluster.Name, r *placementv1alpha1.RightPlacementRecommendation) error {
+			recommendation = r
+			return nil
+		},
+	}
+	reconcile := func() {
+		t.Helper()
+		require.NoError(t, c.reconcile(context.Background(), "root:org", recommendation))
+	}
+
+	reconcile()
+	require.Empty(t, distribution.Spec.TargetOverrides, "recommendations changed since planning are not applied")
+
+	plan.Spec.Moves[0].To = "eu-2"
+	plan.Spec.Action = placementv1alpha1.RecommendationActionPending
+	reconcile()
+	require.Empty(t, distribution.Spec.TargetOverrides, "moves of pending plans are not applied")
+
+	plan.Spec.Action = placementv1alpha1.RecommendationActionAccepted
+	reconcile()
+	require.Equal(t, []workloadv1alpha1.TargetOverride{{From: "eu-1", To: "eu-2"}}, distribution.Spec.TargetOverrides)
+	require.True(t, conditions.IsTrue(recommendation, placementv1alpha1.RecommendationApplied))
+}
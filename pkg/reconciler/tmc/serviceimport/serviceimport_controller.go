@@ -0,0 +1,268 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serviceimport
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	kcpcache "github.com/kcp-dev/apimachinery/v2/pkg/cache"
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/tmcinformers"
+	"github.com/kcp-dev/kcp/pkg/tmc/workspacequeue"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+const (
+	ControllerName = "kcp-tmc-serviceimport"
+)
+
+var (
+	// ServiceExportsGVR is the resource the controller reconciles.
+	ServiceExportsGVR = workloadv1alpha1.SchemeGroupVersion.WithResource("serviceexports")
+	// ServiceImportsGVR is the resource maintained for ServiceExports.
+	ServiceImportsGVR = workloadv1alpha1.SchemeGroupVersion.WithResource("serviceimports")
+	// ServicesGVR is the resource of exported Services.
+	ServicesGVR = corev1.SchemeGroupVersion.WithResource("services")
+)
+
+// NewController returns a controller that maintains the ServiceImport of
+// every ServiceExport, with the ports of the exported Service and the
+// endpoints the syncers report for it on their SyncTargets.
+func NewController(
+	exportClusterInformer *tmcinformers.Informer,
+	importClusterInformer *tmcinformers.Informer,
+	serviceClusterInformer *tmcinformers.Informer,
+	dynamicClusterClient kcpdynamic.ClusterInterface,
+) (*controller, error) {
+	c := &controller{
+		queue: workspacequeue.NewRateLimitingQueue(ControllerName),
+		getExport: func(clusterName logicalcluster.Name, namespace, name string) (*workloadv1alpha1.ServiceExport, error) {
+			obj, err := exportClusterInformer.Lister(clusterName).ByNamespace(namespace).Get(name)
+			if err != nil {
+				return nil, err
+			}
+			export := &workloadv1alpha1.ServiceExport{}
+			return export, fromUnstructured(obj, export)
+		},
+		getImport: func(clusterName logicalcluster.Name, namespace, name string) (*workloadv1alpha1.ServiceImport, error) {
+			obj, err := importClusterInformer.Lister(clusterName).ByNamespace(namespace).Get(name)
+			if err != nil {
+				return nil, err
+			}
+			imp := &workloadv1alpha1.ServiceImport{}
+			return imp, fromUnstructured(obj, imp)
+		},
+		getService: func(clusterName logicalcluster.Name, namespace, name string) (*corev1.Service, error) {
+			obj, err := serviceClusterInformer.Lister(clusterName).ByNamespace(namespace).Get(name)
+			if err != nil {
+				return nil, err
+			}
+			service := &corev1.Service{}
+			return service, fromUnstructured(obj, service)
+		},
+		createImport: func(ctx context.Context, clusterName logicalcluster.Name, imp *workloadv1alpha1.ServiceImport) (*workloadv1alpha1.ServiceImport, error) {
+			return writeImport(imp, func(u *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+				return dynamicClusterClient.Cluster(clusterName.Path()).Resource(ServiceImportsGVR).Namespace(imp.Namespace).Create(ctx, u, metav1.CreateOptions{})
+			})
+		},
+		updateImport: func(ctx context.Context, clusterName logicalcluster.Name, imp *workloadv1alpha1.ServiceImport) (*workloadv1alpha1.ServiceImport, error) {
+			return writeImport(imp, func(u *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+				return dynamicClusterClient.Cluster(clusterName.Path()).Resource(ServiceImportsGVR).Namespace(imp.Namespace).Update(ctx, u, metav1.UpdateOptions{})
+			})
+		},
+		updateImportStatus: func(ctx context.Context, clusterName logicalcluster.Name, imp *workloadv1alpha1.ServiceImport) error {
+			_, err := writeImport(imp, func(u *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+				return dynamicClusterClient.Cluster(clusterName.Path()).Resource(ServiceImportsGVR).Namespace(imp.Namespace).UpdateStatus(ctx, u, metav1.UpdateOptions{})
+			})
+			return err
+		},
+		deleteImport: func(ctx context.Context, clusterName logicalcluster.Name, namespace, name string) error {
+			return dynamicClusterClient.Cluster(clusterName.Path()).Resource(ServiceImportsGVR).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+		},
+		updateExportStatus: func(ctx context.Context, clusterName logicalcluster.Name, export *workloadv1alpha1.ServiceExport) error {
+			u, err := toUnstructured(export)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(ServiceExportsGVR).Namespace(export.Namespace).UpdateStatus(ctx, u, metav1.UpdateOptions{})
+			return err
+		},
+	}
+
+	exportClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueue(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+	})
+	// ServiceExports and ServiceImports share the name of the exported
+	// Service.
+	serviceClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueue(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+		DeleteFunc: func(obj interface{}) { c.enqueue(obj) },
+	})
+	importClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+		DeleteFunc: func(obj interface{}) { c.enqueue(obj) },
+	})
+
+	return c, nil
+}
+
+// controller maintains ServiceImports.
+type controller struct {
+	queue workqueue.TypedRateLimitingInterface[string]
+
+	getExport          func(clusterName logicalcluster.Name, namespace, name string) (*workloadv1alpha1.ServiceExport, error)
+	getImport          func(clusterName logicalcluster.Name, namespace, name string) (*workloadv1alpha1.ServiceImport, error)
+	getService         func(clusterName logicalcluster.Name, namespace, name string) (*corev1.Service, error)
+	createImport       func(ctx context.Context, clusterName logicalcluster.Name, imp *workloadv1alpha1.ServiceImport) (*workloadv1alpha1.ServiceImport, error)
+	updateImport       func(ctx context.Context, clusterName logicalcluster.Name, imp *workloadv1alpha1.ServiceImport) (*workloadv1alpha1.ServiceImport, error)
+	updateImportStatus func(ctx context.Context, clusterName logicalcluster.Name, imp *workloadv1alpha1.ServiceImport) error
+	deleteImport       func(ctx context.Context, clusterName logicalcluster.Name, namespace, name string) error
+	updateExportStatus func(ctx context.Context, clusterName logicalcluster.Name, export *workloadv1alpha1.ServiceExport) error
+}
+
+// enqueue enqueues the ServiceExport of the name of obj, which is a
+// ServiceExport, a ServiceImport or a Service.
+func (c *controller) enqueue(obj interface{}) {
+	key, err := kcpcache.DeletionHandlingMetaClusterNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+
+	logger := logging.WithQueueKey(logging.WithReconciler(klog.Background(), ControllerName), key)
+	logger.V(4).Info("queueing ServiceExport")
+	c.queue.Add(key)
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	logger := logging.WithReconciler(klog.FromContext(ctx), ControllerName)
+	ctx = klog.NewContext(ctx, logger)
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	for range numThreads {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+
+	logger := logging.WithQueueKey(klog.FromContext(ctx), key)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(4).Info("processing key")
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		utilruntime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", ControllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) error {
+	clusterName, namespace, name, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return nil
+	}
+
+	export, err := c.getExport(clusterName, namespace, name)
+	if errors.IsNotFound(err) {
+		// The ServiceImport is garbage collected through its owner
+		// reference.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !export.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	return c.reconcile(ctx, clusterName, export)
+}
+
+func fromUnstructured(obj runtime.Object, into interface{}) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("expected *unstructured.Unstructured, got %T", obj)
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, into)
+}
+
+func toUnstructured(obj interface{}) (*unstructured.Unstructured, error) {
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: raw}, nil
+}
+
+// writeImport writes imp with write and returns the written ServiceImport.
+func writeImport(imp *workloadv1alpha1.ServiceImport, write func(u *unstructured.Unstructured) (*unstructured.Unstructured, error)) (*workloadv1alpha1.ServiceImport, error) {
+	u, err := toUnstructured(imp)
+	if err != nil {
+		return nil, err
+	}
+	u.SetAPIVersion(workloadv1alpha1.SchemeGroupVersion.String())
+	u.SetKind("ServiceImport")
+	written, err := write(u)
+	if err != nil {
+		return nil, err
+	}
+	result := &workloadv1alpha1.ServiceImport{}
+	return result, fromUnstructured(written, result)
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serviceimport
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/servicediscovery"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// reconcile validates export and maintains its ServiceImport.
+func (c *controller) reconcile(ctx context.Context, clusterName logicalcluster.Name, export *workloadv1alpha1.ServiceExport) error {
+	logger := klog.FromContext(ctx)
+
+	e := export.DeepCopy()
+	service, err := c.getService(clusterName, export.Namespace, export.Name)
+	switch {
+	case errors.IsNotFound(err):
+		conditions.MarkFalse(e, workloadv1alpha1.ServiceExportValid, workloadv1alpha1.ServiceNotFoundReason, conditionsv1alpha1.ConditionSeverityError,
+			"Service %s/%s does not exist.", export.Namespace, export.Name)
+		service = nil
+	case err != nil:
+		return err
+	case service.Spec.Type == corev1.ServiceTypeExternalName:
+		conditions.MarkFalse(e, workloadv1alpha1.ServiceExportValid, workloadv1alpha1.ServiceTypeNotSupportedReason, conditionsv1alpha1.ConditionSeverityError,
+			"Services of type %s cannot be exported.", corev1.ServiceTypeExternalName)
+		service = nil
+	default:
+		conditions.MarkTrue(e, workloadv1alpha1.ServiceExportValid)
+	}
+	if !equality.Semantic.DeepEqual(export.Status, e.Status) {
+		logger.V(2).Info("updating ServiceExport status", "reason", conditions.GetReason(e, workloadv1alpha1.ServiceExportValid))
+		if err := c.updateExportStatus(ctx, clusterName, e); err != nil {
+			return err
+		}
+	}
+
+	if service == nil {
+		// Syncers remove the mirrors of a ServiceImport that is gone.
+		imp, err := c.getImport(clusterName, export.Namespace, export.Name)
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !metav1.IsControlledBy(imp, export) {
+			return nil
+		}
+		logger.V(2).Info("deleting ServiceImport")
+		if err := c.deleteImport(ctx, clusterName, export.Namespace, export.Name); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	targets, err := servicediscovery.Reported(export)
+	if err != nil {
+		// A malformed report of a syncer does not keep the others from
+		// being imported.
+		logger.Error(err, "ignoring endpoints reported for ServiceExport")
+	}
+
+	desired := &workloadv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: export.Namespace,
+			Name:      export.Name,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(export, workloadv1alpha1.SchemeGroupVersion.WithKind("ServiceExport")),
+			},
+		},
+		Spec: importSpec(service),
+		Status: workloadv1alpha1.ServiceImportStatus{
+			Targets: targets,
+		},
+	}
+
+	imp, err := c.getImport(clusterName, export.Namespace, export.Name)
+	switch {
+	case errors.IsNotFound(err):
+		logger.V(2).Info("creating ServiceImport")
+		if imp, err = c.createImport(ctx, clusterName, desired); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	case !metav1.IsControlledBy(imp, export):
+		return fmt.Errorf("ServiceImport %s/%s is not controlled by its ServiceExport", export.Namespace, export.Name)
+	case !equality.Semantic.DeepEqual(imp.Spec, desired.Spec):
+		i := imp.DeepCopy()
+		i.Spec = desired.Spec
+		logger.V(2).Info("updating ServiceImport")
+		if imp, err = c.updateImport(ctx, clusterName, i); err != nil {
+			return err
+		}
+	}
+
+	if equality.Semantic.DeepEqual(imp.Status, desired.Status) {
+		return nil
+	}
+	i := imp.DeepCopy()
+	i.Status = desired.Status
+	logger.V(2).Info("updating ServiceImport status", "targets", len(i.Status.Targets))
+	return c.updateImportStatus(ctx, clusterName, i)
+}
+
+// importSpec returns the ServiceImport spec of service.
+func importSpec(service *corev1.Service) workloadv1alpha1.ServiceImportSpec {
+	spec := workloadv1alpha1.ServiceImportSpec{Type: workloadv1alpha1.ClusterSetIP}
+	if service.Spec.ClusterIP == corev1.ClusterIPNone {
+		spec.Type = workloadv1alpha1.Headless
+	}
+	for _, p := range service.Spec.Ports {
+		spec.Ports = append(spec.Ports, workloadv1alpha1.ServicePort{
+			Name:        p.Name,
+			Protocol:    p.Protocol,
+			AppProtocol: p.AppProtocol,
+			Port:        p.Port,
+		})
+	}
+	return spec
+}
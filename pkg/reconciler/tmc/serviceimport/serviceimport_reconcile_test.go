@@ -0,0 +1,149 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serviceimport
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/servicediscovery"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+type fakeCluster struct {
+	service *corev1.Service
+	imp     *workloadv1alpha1.ServiceImport
+	writes  int
+}
+
+func (f *fakeCluster) controller(export **workloadv1alpha1.ServiceExport) *controller {
+	return &controller{
+		getService: func(_ logicalcluster.Name, namespace, name string) (*corev1.Service, error) {
+			if f.service == nil {
+				return nil, apierrors.NewNotFound(ServicesGVR.GroupResource(), name)
+			}
+			return f.service, nil
+		},
+		getImport: func(_ logicalcluster.Name, namespace, name string) (*workloadv1alpha1.ServiceImport, error) {
+			if f.imp == nil {
+				return nil, apierrors.NewNotFound(ServiceImportsGVR.GroupResource(), name)
+			}
+			return f.imp, nil
+		},
+		createImport: func(_ context.Context, _ logicalcluster.Name, imp *workloadv1alpha1.ServiceImport) (*workloadv1alpha1.ServiceImport, error) {
+			f.writes++
+			f.imp = imp.DeepCopy()
+			f.imp.Status = workloadv1alpha1.ServiceImportStatus{}
+			return f.imp, nil
+		},
+		updateImport: func(_ context.Context, _ logicalcluster.Name, imp *workloadv1alpha1.ServiceImport) (*workloadv1alpha1.ServiceImport, error) {
+			f.writes++
+			f.imp = imp
+			return imp, nil
+		},
+		updateImportStatus: func(_ context.Context, _ logicalcluster.Name, imp *workloadv1alpha1.ServiceImport) error {
+			f.writes++
+			f.imp = imp
+			return nil
+		},
+		deleteImport: func(_ context.Context, _ logicalcluster.Name, namespace, name string) error {
+			if f.imp == nil {
+				return apierrors.NewNotFound(ServiceImportsGVR.GroupResource(), name)
+			}
+			f.writes++
+			f.imp = nil
+			return nil
+		},
+		updateExportStatus: func(_ context.Context, _ logicalcluster.Name, e *workloadv1alpha1.ServiceExport) error {
+			f.writes++
+			*export = e
+			return nil
+		},
+	}
+}
+
+func report(t *testing.T, export *workloadv1alpha1.ServiceExport, endpoints workloadv1alpha1.ServiceEndpoints) {
+	t.Helper()
+	raw, err := json.Marshal(endpoints)
+	require.NoError(t, err)
+	if export.Annotations == nil {
+		export.Annotations = map[string]string{}
+	}
+	export.Annotations[servicediscovery.EndpointsAnnotation(endpoints.SyncTarget)] = string(raw)
+}
+
+func TestReconcile(t *testing.T) {
+	ctx := context.Background()
+	export := &workloadv1alpha1.ServiceExport{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", UID: "1234"}}
+	f := &fakeCluster{}
+	c := f.controller(&export)
+
+	require.NoError(t, c.reconcile(ctx, "abc", export))
+	require.Equal(t, workloadv1alpha1.ServiceNotFoundReason, conditions.GetReason(export, workloadv1alpha1.ServiceExportValid))
+	require.Nil(t, f.imp)
+
+	f.service = &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: "10.96.0.10",
+			Ports:     []corev1.ServicePort{{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80}},
+		},
+	}
+	report(t, export, workloadv1alpha1.ServiceEndpoints{SyncTarget: "edge-2", Addresses: []string{"10.1.0.1"}})
+	report(t, export, workloadv1alpha1.ServiceEndpoints{SyncTarget: "edge-1", Addresses: []string{"10.0.0.1"}})
+	require.NoError(t, c.reconcile(ctx, "abc", export))
+	require.True(t, conditions.IsTrue(export, workloadv1alpha1.ServiceExportValid))
+	require.NotNil(t, f.imp)
+	require.True(t, metav1.IsControlledBy(f.imp, export))
+	require.Equal(t, workloadv1alpha1.ClusterSetIP, f.imp.Spec.Type)
+	require.Equal(t, []workloadv1alpha1.ServicePort{{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80}}, f.imp.Spec.Ports)
+	require.Len(t, f.imp.Status.Targets, 2)
+	require.Equal(t, "edge-1", f.imp.Status.Targets[0].SyncTarget)
+
+	f.writes = 0
+	require.NoError(t, c.reconcile(ctx, "abc", export))
+	require.Zero(t, f.writes, "nothing changed")
+
+	f.service.Spec.ClusterIP = corev1.ClusterIPNone
+	require.NoError(t, c.reconcile(ctx, "abc", export))
+	require.Equal(t, workloadv1alpha1.Headless, f.imp.Spec.Type)
+
+	f.service.Spec.Type = corev1.ServiceTypeExternalName
+	require.NoError(t, c.reconcile(ctx, "abc", export))
+	require.Equal(t, workloadv1alpha1.ServiceTypeNotSupportedReason, conditions.GetReason(export, workloadv1alpha1.ServiceExportValid))
+	require.Nil(t, f.imp, "the ServiceImport of an invalid export is deleted")
+}
+
+func TestReconcileForeignImport(t *testing.T) {
+	export := &workloadv1alpha1.ServiceExport{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", UID: "1234"}}
+	f := &fakeCluster{
+		service: &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"}},
+		imp:     &workloadv1alpha1.ServiceImport{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"}},
+	}
+	err := f.controller(&export).reconcile(context.Background(), "abc", export)
+	require.ErrorContains(t, err, "not controlled by its ServiceExport")
+}
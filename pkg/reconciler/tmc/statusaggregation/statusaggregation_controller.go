@@ -0,0 +1,336 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statusaggregation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	kcpcache "github.com/kcp-dev/apimachinery/v2/pkg/cache"
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/reconciler/dynamicrestmapper"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/tmcinformers"
+	"github.com/kcp-dev/kcp/pkg/tmc/workspacequeue"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+const (
+	ControllerName = "kcp-tmc-status-aggregation"
+
+	// fieldManager owns the aggregated status fields.
+	fieldManager = "kcp-tmc-status-aggregation"
+
+	// byWorkload indexes WorkloadDistributions by their workload.
+	byWorkload = "statusaggregation-byWorkload"
+
+	// syncWait is how long to wait before retrying a WorkloadDistribution
+	// whose workload informer has not synced yet.
+	syncWait = time.Second
+)
+
+// StatusAggregationPoliciesGVR is the resource of StatusAggregationPolicies.
+var StatusAggregationPoliciesGVR = workloadv1alpha1.SchemeGroupVersion.WithResource("statusaggregationpolicies")
+
+// NewController returns a controller that aggregates the statuses the
+// SyncTargets of a WorkloadDistribution report on its workload into the
+// status of the workload. workloadInformer returns the started informer of
+// a workload resource, which the controller watches once it distributes a
+// workload of the resource.
+func NewController(
+	distributionClusterInformer *tmcinformers.Informer,
+	policyClusterInformer *tmcinformers.Informer,
+	workloadInformer func(gvr schema.GroupVersionResource) *tmcinformers.Informer,
+	dynamicClusterClient kcpdynamic.ClusterInterface,
+	dynRESTMapper *dynamicrestmapper.DynamicRESTMapper,
+) (*controller, error) {
+	c := &controller{
+		queue:    workspacequeue.NewRateLimitingQueue(ControllerName),
+		now:      time.Now,
+		informed: map[schema.GroupVersionResource]*tmcinformers.Informer{},
+		getDistribution: func(clusterName logicalcluster.Name, namespace, name string) (*workloadv1alpha1.WorkloadDistribution, error) {
+			obj, err := distributionClusterInformer.Lister(clusterName).ByNamespace(namespace).Get(name)
+			if err != nil {
+				return nil, err
+			}
+			distribution := &workloadv1alpha1.WorkloadDistribution{}
+			return distribution, fromUnstructured(obj, distribution)
+		},
+		listPolicies: func(clusterName logicalcluster.Name) ([]*workloadv1alpha1.StatusAggregationPolicy, error) {
+			objs, err := policyClusterInformer.Lister(clusterName).List(labels.Everything())
+			if err != nil {
+				return nil, err
+			}
+			policies := make([]*workloadv1alpha1.StatusAggregationPolicy, 0, len(objs))
+			for _, obj := range objs {
+				policy := &workloadv1alpha1.StatusAggregationPolicy{}
+				if err := fromUnstructured(obj, policy); err != nil {
+					return nil, err
+				}
+				policies = append(policies, policy)
+			}
+			return policies, nil
+		},
+		restMapping: func(clusterName logicalcluster.Name, gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+			return dynRESTMapper.ForCluster(clusterName).RESTMapping(gvk.GroupKind(), gvk.Version)
+		},
+		applyStatus: func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+			_, err := dynamicClusterClient.Cluster(clusterName.Path()).Resource(gvr).Namespace(obj.GetNamespace()).ApplyStatus(ctx, obj.GetName(), obj, metav1.ApplyOptions{
+				FieldManager: fieldManager,
+				Force:        true,
+			})
+			return err
+		},
+	}
+	c.getWorkload = func(clusterName logicalcluster.Name, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, bool, error) {
+		informer := c.informWorkloads(gvr, workloadInformer, distributionClusterInformer)
+		if !informer.HasSynced() {
+			return nil, false, nil
+		}
+		obj, err := informer.Lister(clusterName).ByNamespace(namespace).Get(name)
+		if err != nil {
+			return nil, true, err
+		}
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return nil, true, fmt.Errorf("expected *unstructured.Unstructured, got %T", obj)
+		}
+		return u, true, nil
+	}
+
+	distributionClusterInformer.AddIndexers(cache.Indexers{byWorkload: indexByWorkload})
+	distributionClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueue(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+	})
+	policyClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueueDistributionsInCluster(distributionClusterInformer, obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueueDistributionsInCluster(distributionClusterInformer, obj) },
+		DeleteFunc: func(obj interface{}) { c.enqueueDistributionsInCluster(distributionClusterInformer, obj) },
+	})
+
+	return c, nil
+}
+
+// controller aggregates the statuses of distributed workloads.
+type controller struct {
+	queue workqueue.TypedRateLimitingInterface[string]
+
+	now func() time.Time
+
+	// informed are the informers of the workload resources the controller
+	// handles events of.
+	informedLock sync.Mutex
+	informed     map[schema.GroupVersionResource]*tmcinformers.Informer
+
+	getDistribution func(clusterName logicalcluster.Name, namespace, name string) (*workloadv1alpha1.WorkloadDistribution, error)
+	listPolicies    func(clusterName logicalcluster.Name) ([]*workloadv1alpha1.StatusAggregationPolicy, error)
+	restMapping     func(clusterName logicalcluster.Name, gvk schema.GroupVersionKind) (*meta.RESTMapping, error)
+	// getWorkload returns the workload, and false if the informer of its
+	// resource has not synced yet.
+	getWorkload func(clusterName logicalcluster.Name, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, bool, error)
+	applyStatus func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error
+}
+
+// informWorkloads returns the informer of the workload resource gvr, and
+// enqueues the WorkloadDistributions of its objects when they change.
+func (c *controller) informWorkloads(gvr schema.GroupVersionResource, workloadInformer func(gvr schema.GroupVersionResource) *tmcinformers.Informer, distributionClusterInformer *tmcinformers.Informer) *tmcinformers.Informer {
+	c.informedLock.Lock()
+	defer c.informedLock.Unlock()
+
+	if informer, found := c.informed[gvr]; found {
+		return informer
+	}
+	informer := workloadInformer(gvr)
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueueDistributionsOf(distributionClusterInformer, obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueueDistributionsOf(distributionClusterInformer, obj) },
+	})
+	c.informed[gvr] = informer
+	return informer
+}
+
+// workloadKey identifies a workload across logical clusters and versions.
+func workloadKey(clusterName logicalcluster.Name, namespace, group, kind, name string) string {
+	return strings.Join([]string{clusterName.String(), namespace, group, kind, name}, "|")
+}
+
+func indexByWorkload(obj interface{}) ([]string, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("expected *unstructured.Unstructured, got %T", obj)
+	}
+	distribution := &workloadv1alpha1.WorkloadDistribution{}
+	if err := fromUnstructured(u, distribution); err != nil {
+		return nil, err
+	}
+	gv, err := schema.ParseGroupVersion(distribution.Spec.WorkloadRef.APIVersion)
+	if err != nil {
+		return nil, nil
+	}
+	return []string{workloadKey(logicalcluster.From(u), u.GetNamespace(), gv.Group, distribution.Spec.WorkloadRef.Kind, distribution.Spec.WorkloadRef.Name)}, nil
+}
+
+func (c *controller) enqueue(obj interface{}) {
+	key, err := kcpcache.DeletionHandlingMetaClusterNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+
+	logger := logging.WithQueueKey(logging.WithReconciler(klog.Background(), ControllerName), key)
+	logger.V(4).Info("queueing WorkloadDistribution")
+	c.queue.Add(key)
+}
+
+// enqueueDistributionsOf enqueues the WorkloadDistributions of a workload.
+func (c *controller) enqueueDistributionsOf(distributionClusterInformer *tmcinformers.Informer, obj interface{}) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		utilruntime.HandleError(fmt.Errorf("expected *unstructured.Unstructured, got %T", obj))
+		return
+	}
+	distributions, err := distributionClusterInformer.ByIndex(byWorkload, workloadKey(logicalcluster.From(u), u.GetNamespace(), u.GroupVersionKind().Group, u.GetKind(), u.GetName()))
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, distribution := range distributions {
+		c.enqueue(distribution)
+	}
+}
+
+// enqueueDistributionsInCluster enqueues the WorkloadDistributions in the
+// logical cluster of a StatusAggregationPolicy. Policies change rarely, so
+// distributions are not indexed by resource.
+func (c *controller) enqueueDistributionsInCluster(distributionClusterInformer *tmcinformers.Informer, obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		utilruntime.HandleError(fmt.Errorf("expected *unstructured.Unstructured, got %T", obj))
+		return
+	}
+
+	distributions, err := distributionClusterInformer.Lister(logicalcluster.From(u)).List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, distribution := range distributions {
+		c.enqueue(distribution)
+	}
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	logger := logging.WithReconciler(klog.FromContext(ctx), ControllerName)
+	ctx = klog.NewContext(ctx, logger)
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	for range numThreads {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+
+	logger := logging.WithQueueKey(klog.FromContext(ctx), key)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(4).Info("processing key")
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	requeueAfter, err := c.process(ctx, key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", ControllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	if requeueAfter > 0 {
+		c.queue.AddAfter(key, requeueAfter)
+	}
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) (time.Duration, error) {
+	clusterName, namespace, name, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return 0, nil
+	}
+
+	distribution, err := c.getDistribution(clusterName, namespace, name)
+	if errors.IsNotFound(err) {
+		// The status of the workload is left as it is.
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !distribution.DeletionTimestamp.IsZero() {
+		return 0, nil
+	}
+
+	return c.reconcile(ctx, clusterName, distribution)
+}
+
+func fromUnstructured(obj runtime.Object, into interface{}) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("expected *unstructured.Unstructured, got %T", obj)
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, into)
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statusaggregation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	aggregation "github.com/kcp-dev/kcp/pkg/statusaggregation"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// reconcile writes the aggregated status of the workload of distribution.
+func (c *controller) reconcile(ctx context.Context, clusterName logicalcluster.Name, distribution *workloadv1alpha1.WorkloadDistribution) (time.Duration, error) {
+	logger := klog.FromContext(ctx)
+
+	syncTargets := distribution.SyncedTargets()
+	if len(syncTargets) == 0 {
+		return 0, nil
+	}
+
+	ref := distribution.Spec.WorkloadRef
+	gvk := schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind)
+	mapping, err := c.restMapping(clusterName, gvk)
+	if err != nil {
+		return 0, fmt.Errorf("failed to map %s: %w", gvk, err)
+	}
+
+	workload, synced, err := c.getWorkload(clusterName, mapping.Resource, distribution.Namespace, ref.Name)
+	if !synced {
+		logger.V(4).Info("waiting for the informer of the workload resource to sync", "resource", mapping.Resource)
+		return syncWait, nil
+	}
+	if errors.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	statuses, err := aggregation.TargetStatuses(workload, syncTargets)
+	if err != nil {
+		// The status is reported again when it changes.
+		utilruntime.HandleError(fmt.Errorf("failed to read the statuses of %s %s|%s/%s: %w", gvk.Kind, clusterName, workload.GetNamespace(), workload.GetName(), err))
+		return 0, nil
+	}
+
+	policies, err := c.listPolicies(clusterName)
+	if err != nil {
+		return 0, err
+	}
+	var selected []*workloadv1alpha1.StatusAggregationPolicy
+	for _, policy := range policies {
+		if aggregation.Selects(policy, mapping.Resource.GroupResource()) {
+			selected = append(selected, policy)
+		}
+	}
+	aggregator, err := aggregation.New(mapping.Resource.GroupResource(), selected)
+	if err != nil {
+		// The distribution is queued again when the policy is fixed.
+		utilruntime.HandleError(fmt.Errorf("invalid StatusAggregationPolicy for %s in %s: %w", mapping.Resource.GroupResource(), clusterName, err))
+		return 0, nil
+	}
+
+	current, _, _ := unstructured.NestedMap(workload.Object, "status")
+	status, err := aggregator.Aggregate(current, statuses, syncTargets, c.now())
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("failed to aggregate the status of %s %s|%s/%s: %w", gvk.Kind, clusterName, workload.GetNamespace(), workload.GetName(), err))
+		return 0, nil
+	}
+	if len(status) == 0 || upToDate(current, status) {
+		return 0, nil
+	}
+
+	applied := &unstructured.Unstructured{Object: map[string]interface{}{"status": status}}
+	applied.SetAPIVersion(workload.GetAPIVersion())
+	applied.SetKind(workload.GetKind())
+	applied.SetNamespace(workload.GetNamespace())
+	applied.SetName(workload.GetName())
+	logger.V(2).Info("applying aggregated status", "resource", mapping.Resource, "name", workload.GetName(), "syncTargets", syncTargets)
+	if err := c.applyStatus(ctx, clusterName, mapping.Resource, applied); err != nil && !errors.IsNotFound(err) {
+		return 0, err
+	}
+	return 0, nil
+}
+
+// upToDate returns whether the aggregated fields have their values in
+// current. Fields of current that are not aggregated are ignored.
+func upToDate(current, aggregated map[string]interface{}) bool {
+	for k, v := range aggregated {
+		if nested, ok := v.(map[string]interface{}); ok {
+			if currentNested, ok := current[k].(map[string]interface{}); !ok || !upToDate(currentNested, nested) {
+				return false
+			}
+			continue
+		}
+		if !equality.Semantic.DeepEqual(current[k], v) {
+			return false
+		}
+	}
+	return true
+}
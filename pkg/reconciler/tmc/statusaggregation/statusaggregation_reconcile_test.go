@@ -0,0 +1,175 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statusaggregation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	aggregation "github.com/kcp-dev/kcp/pkg/statusaggregation"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var deploymentsGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+func newDistribution(syncTargets ...string) *workloadv1alpha1.WorkloadDistribution {
+	distribution := &workloadv1alpha1.WorkloadDistribution{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec: workloadv1alpha1.WorkloadDistributionSpec{
+			WorkloadRef: workloadv1alpha1.WorkloadReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+		},
+	}
+	for _, syncTarget := range syncTargets {
+		distribution.Status.Targets = append(distribution.Status.Targets, workloadv1alpha1.TargetPlacement{SyncTarget: syncTarget})
+	}
+	return distribution
+}
+
+func newWorkload(status map[string]interface{}, reported map[string]string) *unstructured.Unstructured {
+	workload := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"namespace": "default", "name": "web"},
+	}}
+	if status != nil {
+		workload.Object["status"] = status
+	}
+	annotations := map[string]string{}
+	for syncTarget, s := range reported {
+		annotations[aggregation.TargetAnnotation(syncTarget)] = s
+	}
+	workload.SetAnnotations(annotations)
+	return workload
+}
+
+type fakes struct {
+	workload *unstructured.Unstructured
+	synced   bool
+	policies []*workloadv1alpha1.StatusAggregationPolicy
+	applied  []*unstructured.Unstructured
+}
+
+func (f *fakes) controller() *controller {
+	return &controller{
+		now: func() time.Time { return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC) },
+		listPolicies: func(logicalcluster.Name) ([]*workloadv1alpha1.StatusAggregationPolicy, error) {
+			return f.policies, nil
+		},
+		restMapping: func(_ logicalcluster.Name, gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+			return &meta.RESTMapping{Resource: deploymentsGVR, GroupVersionKind: gvk, Scope: meta.RESTScopeNamespace}, nil
+		},
+		getWorkload: func(_ logicalcluster.Name, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, bool, error) {
+			return f.workload, f.synced, nil
+		},
+		applyStatus: func(_ context.Context, _ logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+			f.applied = append(f.applied, obj)
+			return nil
+		},
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	clusterName := logicalcluster.Name("root:org:ws")
+	reported := map[string]string{
+		"east": `{"replicas":2,"readyReplicas":2}`,
+		"west": `{"replicas":3,"readyReplicas":1}`,
+	}
+
+	tests := map[string]struct {
+		distribution *workloadv1alpha1.WorkloadDistribution
+		workload     *unstructured.Unstructured
+		unsynced     bool
+		policies     []*workloadv1alpha1.StatusAggregationPolicy
+
+		wantRequeue time.Duration
+		wantStatus  map[string]interface{}
+	}{
+		"not placed": {
+			distribution: newDistribution(),
+			workload:     newWorkload(nil, reported),
+		},
+		"informer not synced": {
+			distribution: newDistribution("east", "west"),
+			unsynced:     true,
+			wantRequeue:  syncWait,
+		},
+		"replicas are summed": {
+			distribution: newDistribution("east", "west"),
+			workload:     newWorkload(nil, reported),
+			wantStatus:   map[string]interface{}{"replicas": int64(5), "readyReplicas": int64(3)},
+		},
+		"up to date": {
+			distribution: newDistribution("east", "west"),
+			workload:     newWorkload(map[string]interface{}{"replicas": int64(5), "readyReplicas": int64(3), "observedGeneration": int64(1)}, reported),
+		},
+		"policy": {
+			distribution: newDistribution("east", "west"),
+			workload:     newWorkload(nil, reported),
+			policies: []*workloadv1alpha1.StatusAggregationPolicy{{
+				ObjectMeta: metav1.ObjectMeta{Name: "min"},
+				Spec: workloadv1alpha1.StatusAggregationPolicySpec{
+					Resources: []string{"deployments.apps"},
+					Fields:    []workloadv1alpha1.AggregatedField{{Path: "readyReplicas", Strategy: workloadv1alpha1.AggregationMin}},
+				},
+			}},
+			wantStatus: map[string]interface{}{"replicas": int64(5), "readyReplicas": int64(1)},
+		},
+		"invalid policy": {
+			distribution: newDistribution("east", "west"),
+			workload:     newWorkload(nil, reported),
+			policies: []*workloadv1alpha1.StatusAggregationPolicy{{
+				ObjectMeta: metav1.ObjectMeta{Name: "invalid"},
+				Spec: workloadv1alpha1.StatusAggregationPolicySpec{
+					Resources: []string{"deployments.apps"},
+					Fields:    []workloadv1alpha1.AggregatedField{{Path: "readyReplicas", Strategy: workloadv1alpha1.AggregationCEL, Expression: "values +"}},
+				},
+			}},
+		},
+		"invalid reported status": {
+			distribution: newDistribution("east"),
+			workload:     newWorkload(nil, map[string]string{"east": `{`}),
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			f := &fakes{workload: tc.workload, synced: !tc.unsynced, policies: tc.policies}
+			requeue, err := f.controller().reconcile(context.Background(), clusterName, tc.distribution)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantRequeue, requeue)
+
+			if tc.wantStatus == nil {
+				require.Empty(t, f.applied)
+				return
+			}
+			require.Len(t, f.applied, 1)
+			require.Equal(t, "web", f.applied[0].GetName())
+			require.Equal(t, "default", f.applied[0].GetNamespace())
+			require.Equal(t, "Deployment", f.applied[0].GetKind())
+			require.Equal(t, map[string]interface{}{"status": tc.wantStatus, "apiVersion": "apps/v1", "kind": "Deployment", "metadata": map[string]interface{}{"namespace": "default", "name": "web"}}, f.applied[0].Object)
+		})
+	}
+}
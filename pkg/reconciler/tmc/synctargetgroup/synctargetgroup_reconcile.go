@@ -0,0 +1,96 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package synctargetgroup
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func (c *controller) reconcile(ctx context.Context, clusterName logicalcluster.Name, group *tmcv1alpha1.SyncTargetGroup) error {
+	logger := klog.FromContext(ctx)
+
+	g := group.DeepCopy()
+	g.Status.Members, g.Status.ReadyMembers = 0, 0
+	var capacity, allocatable corev1.ResourceList
+	var missing []string
+	for _, member := range g.Spec.Members {
+		syncTarget, err := c.getSyncTarget(clusterName, member.Name)
+		if apierrors.IsNotFound(err) {
+			missing = append(missing, member.Name)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		g.Status.Members++
+		if !conditions.IsTrue(syncTarget, tmcv1alpha1.SyncerReady) {
+			continue
+		}
+		g.Status.ReadyMembers++
+		capacity = add(capacity, syncTarget.Status.Capacity)
+		allocatable = add(allocatable, syncTarget.Status.Allocatable)
+	}
+	g.Status.Capacity = ptrOrNil(capacity)
+	g.Status.Allocatable = ptrOrNil(allocatable)
+
+	if len(missing) > 0 {
+		conditions.MarkFalse(g, tmcv1alpha1.MembersFound, tmcv1alpha1.MemberNotFoundReason, conditionsv1alpha1.ConditionSeverityWarning,
+			"SyncTargets not found: %s", strings.Join(missing, ", "))
+	} else {
+		conditions.MarkTrue(g, tmcv1alpha1.MembersFound)
+	}
+
+	if equality.Semantic.DeepEqual(group.Status, g.Status) {
+		return nil
+	}
+	logger.V(2).Info("updating SyncTargetGroup status", "members", g.Status.Members, "readyMembers", g.Status.ReadyMembers)
+	return c.updateGroupStatus(ctx, clusterName, g)
+}
+
+func add(sum corev1.ResourceList, resources *corev1.ResourceList) corev1.ResourceList {
+	if resources == nil {
+		return sum
+	}
+	if sum == nil {
+		sum = corev1.ResourceList{}
+	}
+	for name, quantity := range *resources {
+		total := sum[name]
+		total.Add(quantity)
+		sum[name] = total
+	}
+	return sum
+}
+
+func ptrOrNil(resources corev1.ResourceList) *corev1.ResourceList {
+	if len(resources) == 0 {
+		return nil
+	}
+	return &resources
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package synctargetgroup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func TestReconcile(t *testing.T) {
+	syncTarget := func(name string, ready bool, cpu string) *tmcv1alpha1.SyncTarget {
+		st := &tmcv1alpha1.SyncTarget{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		st.Status.Capacity = &corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}
+		st.Status.Allocatable = &corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}
+		if ready {
+			conditions.MarkTrue(st, tmcv1alpha1.SyncerReady)
+		}
+		return st
+	}
+	syncTargets := map[string]*tmcv1alpha1.SyncTarget{
+		"east": syncTarget("east", true, "4"),
+		"west": syncTarget("west", true, "8"),
+		"edge": syncTarget("edge", false, "2"),
+	}
+
+	group := &tmcv1alpha1.SyncTargetGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "regional"},
+		Spec: tmcv1alpha1.SyncTargetGroupSpec{Members: []tmcv1alpha1.SyncTargetGroupMember{
+			{Name: "east"}, {Name: "west"}, {Name: "edge"},
+		}},
+	}
+
+	updates := 0
+	c := &controller{
+		getSyncTarget: func(_ logicalcluster.Name, name string) (*tmcv1alpha1.SyncTarget, error) {
+			if st, ok := syncTargets[name]; ok {
+				return st, nil
+			}
+			return nil, apierrors.NewNotFound(tmcv1alpha1.Resource("synctargets"), name)
+		},
+		updateGroupStatus: func(_ context.Context, _ logicalcluster.Name, g *tmcv1alpha1.SyncTargetGroup) error {
+			updates++
+			group = g
+			return nil
+		},
+	}
+	reconcile := func() {
+		t.Helper()
+		require.NoError(t, c.reconcile(context.Background(), "root:org", group))
+	}
+
+	reconcile()
+	require.Equal(t, 1, updates)
+	require.Equal(t, int32(3), group.Status.Members)
+	require.Equal(t, int32(2), group.Status.ReadyMembers)
+	require.Equal(t, "12", group.Status.Capacity.Cpu().String(), "only ready members count")
+	require.Equal(t, "12", group.Status.Allocatable.Cpu().String())
+	require.True(t, conditions.IsTrue(group, tmcv1alpha1.MembersFound))
+
+	reconcile()
+	require.Equal(t, 1, updates, "unchanged status is not written")
+
+	group.Spec.Members = append(group.Spec.Members, tmcv1alpha1.SyncTargetGroupMember{Name: "gone"})
+	reconcile()
+	require.Equal(t, 2, updates)
+	require.Equal(t, int32(3), group.Status.Members)
+	require.False(t, conditions.IsTrue(group, tmcv1alpha1.MembersFound))
+	require.Equal(t, tmcv1alpha1.MemberNotFoundReason, conditions.GetReason(group, tmcv1alpha1.MembersFound))
+	require.Equal(t, "SyncTargets not found: gone", conditions.GetMessage(group, tmcv1alpha1.MembersFound))
+}
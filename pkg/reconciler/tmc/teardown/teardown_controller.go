@@ -0,0 +1,275 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package teardown
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	kcpcache "github.com/kcp-dev/apimachinery/v2/pkg/cache"
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/clusterprofile"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/placement"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/policyrollout"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/tmcinformers"
+	"github.com/kcp-dev/kcp/pkg/tmc/workspacequeue"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+const (
+	ControllerName = "kcp-tmc-synctarget-teardown"
+)
+
+// NewController returns a controller that holds SyncTargets being deleted
+// until their workloads are placed elsewhere and the syncer removed the
+// downstream resources, and then removes them from SyncTargetGroups,
+// DataLocations and target overrides of WorkloadDistributions.
+func NewController(
+	syncTargetClusterInformer *tmcinformers.Informer,
+	distributionClusterInformer *tmcinformers.Informer,
+	groupClusterInformer *tmcinformers.Informer,
+	dataLocationClusterInformer *tmcinformers.Informer,
+	dynamicClusterClient kcpdynamic.ClusterInterface,
+) (*controller, error) {
+	update := func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, namespace string, obj interface{}) error {
+		u, err := toUnstructured(obj)
+		if err != nil {
+			return err
+		}
+		_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(gvr).Namespace(namespace).Update(ctx, u, metav1.UpdateOptions{})
+		return err
+	}
+
+	c := &controller{
+		queue: workspacequeue.NewRateLimitingQueue(ControllerName),
+		getSyncTarget: func(clusterName logicalcluster.Name, name string) (*tmcv1alpha1.SyncTarget, error) {
+			obj, err := syncTargetClusterInformer.Lister(clusterName).Get(name)
+			if err != nil {
+				return nil, err
+			}
+			syncTarget := &tmcv1alpha1.SyncTarget{}
+			return syncTarget, fromUnstructured(obj, syncTarget)
+		},
+		listDistributions: func(clusterName logicalcluster.Name) ([]*workloadv1alpha1.WorkloadDistribution, error) {
+			return list[workloadv1alpha1.WorkloadDistribution](distributionClusterInformer, clusterName)
+		},
+		listGroups: func(clusterName logicalcluster.Name) ([]*tmcv1alpha1.SyncTargetGroup, error) {
+			return list[tmcv1alpha1.SyncTargetGroup](groupClusterInformer, clusterName)
+		},
+		listDataLocations: func(clusterName logicalcluster.Name) ([]*placementv1alpha1.DataLocation, error) {
+			return list[placementv1alpha1.DataLocation](dataLocationClusterInformer, clusterName)
+		},
+		updateSyncTarget: func(ctx context.Context, clusterName logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) error {
+			return update(ctx, clusterName, clusterprofile.SyncTargetsGVR, "", syncTarget)
+		},
+		updateSyncTargetStatus: func(ctx context.Context, clusterName logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) error {
+			u, err := toUnstructured(syncTarget)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(clusterprofile.SyncTargetsGVR).UpdateStatus(ctx, u, metav1.UpdateOptions{})
+			return err
+		},
+		updateDistribution: func(ctx context.Context, clusterName logicalcluster.Name, d *workloadv1alpha1.WorkloadDistribution) error {
+			return update(ctx, clusterName, policyrollout.WorkloadDistributionsGVR, d.Namespace, d)
+		},
+		updateGroup: func(ctx context.Context, clusterName logicalcluster.Name, group *tmcv1alpha1.SyncTargetGroup) error {
+			return update(ctx, clusterName, placement.SyncTargetGroupsGVR, "", group)
+		},
+		updateDataLocation: func(ctx context.Context, clusterName logicalcluster.Name, location *placementv1alpha1.DataLocation) error {
+			return update(ctx, clusterName, placement.DataLocationsGVR, "", location)
+		},
+	}
+
+	syncTargetClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueue(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+	})
+	distributionClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(_, obj interface{}) { c.enqueueDeletingInCluster(syncTargetClusterInformer, obj) },
+		DeleteFunc: func(obj interface{}) { c.enqueueDeletingInCluster(syncTargetClusterInformer, obj) },
+	})
+
+	return c, nil
+}
+
+// controller tears down SyncTargets being deleted.
+type controller struct {
+	queue workqueue.TypedRateLimitingInterface[string]
+
+	getSyncTarget     func(clusterName logicalcluster.Name, name string) (*tmcv1alpha1.SyncTarget, error)
+	listDistributions func(clusterName logicalcluster.Name) ([]*workloadv1alpha1.WorkloadDistribution, error)
+	listGroups        func(clusterName logicalcluster.Name) ([]*tmcv1alpha1.SyncTargetGroup, error)
+	listDataLocations func(clusterName logicalcluster.Name) ([]*placementv1alpha1.DataLocation, error)
+
+	updateSyncTarget       func(ctx context.Context, clusterName logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) error
+	updateSyncTargetStatus func(ctx context.Context, clusterName logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) error
+	updateDistribution     func(ctx context.Context, clusterName logicalcluster.Name, d *workloadv1alpha1.WorkloadDistribution) error
+	updateGroup            func(ctx context.Context, clusterName logicalcluster.Name, group *tmcv1alpha1.SyncTargetGroup) error
+	updateDataLocation     func(ctx context.Context, clusterName logicalcluster.Name, location *placementv1alpha1.DataLocation) error
+}
+
+func (c *controller) enqueue(obj interface{}) {
+	key, err := kcpcache.DeletionHandlingMetaClusterNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+
+	logger := logging.WithQueueKey(logging.WithReconciler(klog.Background(), ControllerName), key)
+	logger.V(4).Info("queueing SyncTarget")
+	c.queue.Add(key)
+}
+
+// enqueueDeletingInCluster enqueues the SyncTargets being deleted in the
+// logical cluster of the WorkloadDistribution, which may wait for it to be
+// placed elsewhere.
+func (c *controller) enqueueDeletingInCluster(syncTargetClusterInformer *tmcinformers.Informer, obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		utilruntime.HandleError(fmt.Errorf("expected *unstructured.Unstructured, got %T", obj))
+		return
+	}
+
+	syncTargets, err := syncTargetClusterInformer.Lister(logicalcluster.From(u)).List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, syncTarget := range syncTargets {
+		if m, ok := syncTarget.(metav1.Object); ok && m.GetDeletionTimestamp() != nil {
+			c.enqueue(syncTarget)
+		}
+	}
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	logger := logging.WithReconciler(klog.FromContext(ctx), ControllerName)
+	ctx = klog.NewContext(ctx, logger)
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	for range numThreads {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+
+	logger := logging.WithQueueKey(klog.FromContext(ctx), key)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(4).Info("processing key")
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		utilruntime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", ControllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) error {
+	clusterName, _, name, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return nil
+	}
+
+	syncTarget, err := c.getSyncTarget(clusterName, name)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.reconcile(ctx, clusterName, syncTarget)
+}
+
+func list[T any](informer *tmcinformers.Informer, clusterName logicalcluster.Name) ([]*T, error) {
+	objs, err := informer.Lister(clusterName).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*T, 0, len(objs))
+	for _, obj := range objs {
+		t := new(T)
+		if err := fromUnstructured(obj, t); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func fromUnstructured(obj runtime.Object, into interface{}) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("expected *unstructured.Unstructured, got %T", obj)
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, into)
+}
+
+func toUnstructured(obj interface{}) (*unstructured.Unstructured, error) {
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: raw}, nil
+}
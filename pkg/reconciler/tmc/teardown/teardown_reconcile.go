@@ -0,0 +1,165 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package teardown
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// maxWorkloadsInMessage bounds the workloads listed in the condition.
+const maxWorkloadsInMessage = 5
+
+func (c *controller) reconcile(ctx context.Context, clusterName logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) error {
+	logger := klog.FromContext(ctx)
+
+	if syncTarget.DeletionTimestamp.IsZero() {
+		if slices.Contains(syncTarget.Finalizers, tmcv1alpha1.SyncTargetFinalizer) {
+			return nil
+		}
+		st := syncTarget.DeepCopy()
+		st.Finalizers = append(st.Finalizers, tmcv1alpha1.SyncTargetFinalizer)
+		logger.V(2).Info("adding finalizer to SyncTarget")
+		return c.updateSyncTarget(ctx, clusterName, st)
+	}
+	if !slices.Contains(syncTarget.Finalizers, tmcv1alpha1.SyncTargetFinalizer) {
+		return nil
+	}
+
+	if syncTarget.Annotations[tmcv1alpha1.AnnotationForceDelete] != "true" {
+		blocked, err := c.blocked(clusterName, syncTarget)
+		if err != nil {
+			return err
+		}
+		if blocked != nil {
+			st := syncTarget.DeepCopy()
+			conditions.Set(st, blocked)
+			if equality.Semantic.DeepEqual(syncTarget.Status, st.Status) {
+				return nil
+			}
+			logger.V(2).Info("SyncTarget teardown waiting", "reason", blocked.Reason)
+			return c.updateSyncTargetStatus(ctx, clusterName, st)
+		}
+	}
+
+	if err := c.removeReferences(ctx, clusterName, syncTarget.Name); err != nil {
+		return err
+	}
+
+	st := syncTarget.DeepCopy()
+	st.Finalizers = slices.DeleteFunc(st.Finalizers, func(f string) bool { return f == tmcv1alpha1.SyncTargetFinalizer })
+	logger.V(2).Info("SyncTarget torn down, removing finalizer", "forced", syncTarget.Annotations[tmcv1alpha1.AnnotationForceDelete] == "true")
+	return c.updateSyncTarget(ctx, clusterName, st)
+}
+
+// blocked returns the TornDown condition of a SyncTarget that cannot be torn
+// down yet, or nil. Workloads are moved off first, as placement does not
+// choose SyncTargets being deleted, then the syncer removes what it synced.
+func (c *controller) blocked(clusterName logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) (*conditionsv1alpha1.Condition, error) {
+	distributions, err := c.listDistributions(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	var placed []string
+	for _, d := range distributions {
+		for _, t := range d.Status.Targets {
+			if t.SyncTarget == syncTarget.Name {
+				placed = append(placed, d.Namespace+"/"+d.Name)
+				break
+			}
+		}
+	}
+	if len(placed) > 0 {
+		slices.Sort(placed)
+		listed := placed
+		if len(listed) > maxWorkloadsInMessage {
+			listed = append(listed[:maxWorkloadsInMessage:maxWorkloadsInMessage], fmt.Sprintf("and %d more", len(placed)-maxWorkloadsInMessage))
+		}
+		return conditions.FalseCondition(tmcv1alpha1.TornDown, tmcv1alpha1.WaitingForWorkloadsReason, conditionsv1alpha1.ConditionSeverityInfo,
+			"%d workloads are still placed on the SyncTarget: %s", len(placed), strings.Join(listed, ", ")), nil
+	}
+
+	if !conditions.IsTrue(syncTarget, tmcv1alpha1.DownstreamResourcesRemoved) {
+		return conditions.FalseCondition(tmcv1alpha1.TornDown, tmcv1alpha1.WaitingForSyncerReason, conditionsv1alpha1.ConditionSeverityWarning,
+			"Waiting for the syncer to remove the downstream resources. Annotate the SyncTarget with %s=true if the physical cluster is gone.", tmcv1alpha1.AnnotationForceDelete), nil
+	}
+	return nil, nil
+}
+
+// removeReferences removes the SyncTarget from SyncTargetGroups,
+// DataLocations and target overrides of WorkloadDistributions.
+func (c *controller) removeReferences(ctx context.Context, clusterName logicalcluster.Name, name string) error {
+	groups, err := c.listGroups(clusterName)
+	if err != nil {
+		return err
+	}
+	for _, group := range groups {
+		members := slices.DeleteFunc(slices.Clone(group.Spec.Members), func(m tmcv1alpha1.SyncTargetGroupMember) bool { return m.Name == name })
+		if len(members) == len(group.Spec.Members) {
+			continue
+		}
+		group = group.DeepCopy()
+		group.Spec.Members = members
+		if err := c.updateGroup(ctx, clusterName, group); err != nil {
+			return fmt.Errorf("failed to remove SyncTarget %q from SyncTargetGroup %q: %w", name, group.Name, err)
+		}
+	}
+
+	locations, err := c.listDataLocations(clusterName)
+	if err != nil {
+		return err
+	}
+	for _, location := range locations {
+		if !slices.Contains(location.Spec.SyncTargets, name) {
+			continue
+		}
+		location = location.DeepCopy()
+		location.Spec.SyncTargets = slices.DeleteFunc(location.Spec.SyncTargets, func(n string) bool { return n == name })
+		if err := c.updateDataLocation(ctx, clusterName, location); err != nil {
+			return fmt.Errorf("failed to remove SyncTarget %q from DataLocation %q: %w", name, location.Name, err)
+		}
+	}
+
+	distributions, err := c.listDistributions(clusterName)
+	if err != nil {
+		return err
+	}
+	for _, d := range distributions {
+		overrides := slices.DeleteFunc(slices.Clone(d.Spec.TargetOverrides), func(o workloadv1alpha1.TargetOverride) bool { return o.From == name || o.To == name })
+		if len(overrides) == len(d.Spec.TargetOverrides) {
+			continue
+		}
+		d = d.DeepCopy()
+		d.Spec.TargetOverrides = overrides
+		if err := c.updateDistribution(ctx, clusterName, d); err != nil {
+			return fmt.Errorf("failed to remove SyncTarget %q from the target overrides of WorkloadDistribution %s/%s: %w", name, d.Namespace, d.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package teardown
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+type fixture struct {
+	syncTarget    *tmcv1alpha1.SyncTarget
+	distributions []*workloadv1alpha1.WorkloadDistribution
+	groups        []*tmcv1alpha1.SyncTargetGroup
+	locations     []*placementv1alpha1.DataLocation
+}
+
+func (f *fixture) controller() *controller {
+	return &controller{
+		listDistributions: func(logicalcluster.Name) ([]*workloadv1alpha1.WorkloadDistribution, error) {
+			return f.distributions, nil
+		},
+		listGroups: func(logicalcluster.Name) ([]*tmcv1alpha1.SyncTargetGroup, error) {
+			return f.groups, nil
+		},
+		listDataLocations: func(logicalcluster.Name) ([]*placementv1alpha1.DataLocation, error) {
+			return f.locations, nil
+		},
+		updateSyncTarget: func(_ context.Context, _ logicalcluster.Name, st *tmcv1alpha1.SyncTarget) error {
+			f.syncTarget = st
+			return nil
+		},
+		updateSyncTargetStatus: func(_ context.Context, _ logicalcluster.Name, st *tmcv1alpha1.SyncTarget) error {
+			f.syncTarget = st
+			return nil
+		},
+		updateDistribution: func(_ context.Context, _ logicalcluster.Name, d *workloadv1alpha1.WorkloadDistribution) error {
+			f.distributions[0] = d
+			return nil
+		},
+		updateGroup: func(_ context.Context, _ logicalcluster.Name, group *tmcv1alpha1.SyncTargetGroup) error {
+			f.groups[0] = group
+			return nil
+		},
+		updateDataLocation: func(_ context.Context, _ logicalcluster.Name, location *placementv1alpha1.DataLocation) error {
+			f.locations[0] = location
+			return nil
+		},
+	}
+}
+
+func newFixture() *fixture {
+	return &fixture{
+		syncTarget: &tmcv1alpha1.SyncTarget{ObjectMeta: metav1.ObjectMeta{Name: "edge-1"}},
+		distributions: []*workloadv1alpha1.WorkloadDistribution{{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"},
+			Spec: workloadv1alpha1.WorkloadDistributionSpec{TargetOverrides: []workloadv1alpha1.TargetOverride{
+				{From: "edge-1", To: "edge-2"},
+				{From: "edge-3", To: "edge-4"},
+			}},
+			Status: workloadv1alpha1.WorkloadDistributionStatus{Targets: []workloadv1alpha1.TargetPlacement{{SyncTarget: "edge-1"}}},
+		}},
+		groups: []*tmcv1alpha1.SyncTargetGroup{{
+			ObjectMeta: metav1.ObjectMeta{Name: "edge"},
+			Spec:       tmcv1alpha1.SyncTargetGroupSpec{Members: []tmcv1alpha1.SyncTargetGroupMember{{Name: "edge-1"}, {Name: "edge-2"}}},
+		}},
+		locations: []*placementv1alpha1.DataLocation{{
+			ObjectMeta: metav1.ObjectMeta{Name: "orders"},
+			Spec:       placementv1alpha1.DataLocationSpec{SyncTargets: []string{"edge-2", "edge-1"}},
+		}},
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	f := newFixture()
+	c := f.controller()
+	reconcile := func() {
+		t.Helper()
+		require.NoError(t, c.reconcile(context.Background(), "root:org", f.syncTarget))
+	}
+
+	reconcile()
+	require.Equal(t, []string{tmcv1alpha1.SyncTargetFinalizer}, f.syncTarget.Finalizers)
+
+	f.syncTarget.DeletionTimestamp = &metav1.Time{Time: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	reconcile()
+	require.Equal(t, tmcv1alpha1.WaitingForWorkloadsReason, conditions.GetReason(f.syncTarget, tmcv1alpha1.TornDown))
+	require.Equal(t, "1 workloads are still placed on the SyncTarget: default/app", conditions.GetMessage(f.syncTarget, tmcv1alpha1.TornDown))
+
+	f.distributions[0].Status.Targets = []workloadv1alpha1.TargetPlacement{{SyncTarget: "edge-2"}}
+	reconcile()
+	require.Equal(t, tmcv1alpha1.WaitingForSyncerReason, conditions.GetReason(f.syncTarget, tmcv1alpha1.TornDown))
+	require.Equal(t, []string{tmcv1alpha1.SyncTargetFinalizer}, f.syncTarget.Finalizers)
+
+	conditions.Set(f.syncTarget, &conditionsv1alpha1.Condition{Type: tmcv1alpha1.DownstreamResourcesRemoved, Status: corev1.ConditionTrue})
+	reconcile()
+	require.Empty(t, f.syncTarget.Finalizers)
+	require.Equal(t, []tmcv1alpha1.SyncTargetGroupMember{{Name: "edge-2"}}, f.groups[0].Spec.Members)
+	require.Equal(t, []string{"edge-2"}, f.locations[0].Spec.SyncTargets)
+	require.Equal(t, []workloadv1alpha1.TargetOverride{{From: "edge-3", To: "edge-4"}}, f.distributions[0].Spec.TargetOverrides)
+}
+
+func TestReconcileForceDelete(t *testing.T) {
+	f := newFixture()
+	f.syncTarget.Finalizers = []string{"other", tmcv1alpha1.SyncTargetFinalizer}
+	f.syncTarget.DeletionTimestamp = &metav1.Time{Time: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	f.syncTarget.Annotations = map[string]string{tmcv1alpha1.AnnotationForceDelete: "true"}
+
+	require.NoError(t, f.controller().reconcile(context.Background(), "root:org", f.syncTarget))
+	require.Equal(t, []string{"other"}, f.syncTarget.Finalizers, "workloads and the syncer are not waited for")
+	require.Equal(t, []tmcv1alpha1.SyncTargetGroupMember{{Name: "edge-2"}}, f.groups[0].Spec.Members)
+}
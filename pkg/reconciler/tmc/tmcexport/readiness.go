@@ -0,0 +1,167 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tmcexport
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Component is a part of TMC that must run for the TMC APIs to be served,
+// e.g. the controller reconciling a TMC resource.
+type Component string
+
+const (
+	// PlacementController places WorkloadDistributions on SyncTargets.
+	PlacementController Component = "PlacementController"
+	// StatusAggregationController aggregates the statuses of distributed
+	// workloads.
+	StatusAggregationController Component = "StatusAggregationController"
+)
+
+const (
+	// WaitingForCachesReason is the reason of a component waiting for its
+	// informers to sync.
+	WaitingForCachesReason = "WaitingForCaches"
+	// StoppedReason is the reason of a component that stopped, e.g. when the
+	// process lost the leader election.
+	StoppedReason = "Stopped"
+)
+
+// ComponentStatus is why a component is not ready.
+type ComponentStatus struct {
+	Component Component
+	Reason    string
+	Message   string
+}
+
+// Readiness tracks which of the components the TMC APIs need are ready in
+// this process. Components are required once installed and are not ready
+// until they report otherwise.
+type Readiness struct {
+	lock      sync.RWMutex
+	notReady  map[Component]ComponentStatus
+	required  map[Component]bool
+	listeners map[int]func()
+	nextID    int
+}
+
+// NewReadiness returns a Readiness without required components.
+func NewReadiness() *Readiness {
+	return &Readiness{
+		notReady:  map[Component]ComponentStatus{},
+		required:  map[Component]bool{},
+		listeners: map[int]func(){},
+	}
+}
+
+// Require makes the TMC APIs wait for component, which waits for its
+// caches until reported ready. Requiring a component again keeps its state.
+func (r *Readiness) Require(component Component) {
+	r.lock.Lock()
+	if r.required[component] {
+		r.lock.Unlock()
+		return
+	}
+	r.required[component] = true
+	r.notReady[component] = ComponentStatus{
+		Component: component,
+		Reason:    WaitingForCachesReason,
+		Message:   "informers have not synced",
+	}
+	r.lock.Unlock()
+	r.notify()
+}
+
+// SetReady reports component as ready.
+func (r *Readiness) SetReady(component Component) {
+	r.lock.Lock()
+	if _, found := r.notReady[component]; !found {
+		r.lock.Unlock()
+		return
+	}
+	delete(r.notReady, component)
+	r.lock.Unlock()
+	r.notify()
+}
+
+// SetNotReady reports component as not ready, for reason.
+func (r *Readiness) SetNotReady(component Component, reason, message string) {
+	status := ComponentStatus{Component: component, Reason: reason, Message: message}
+	r.lock.Lock()
+	if r.notReady[component] == status {
+		r.lock.Unlock()
+		return
+	}
+	r.notReady[component] = status
+	r.lock.Unlock()
+	r.notify()
+}
+
+// NotReady returns the required components that are not ready, sorted by
+// component.
+func (r *Readiness) NotReady() []ComponentStatus {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	var statuses []ComponentStatus
+	for component, status := range r.notReady {
+		if r.required[component] {
+			statuses = append(statuses, status)
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Component < statuses[j].Component })
+	return statuses
+}
+
+// AddListener calls listener whenever the readiness of a component changes,
+// until the returned function is called.
+func (r *Readiness) AddListener(listener func()) (remove func()) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	id := r.nextID
+	r.nextID++
+	r.listeners[id] = listener
+	return func() {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		delete(r.listeners, id)
+	}
+}
+
+// Run wraps run, which runs component until ctx is done, to report the
+// component ready while it runs.
+func (r *Readiness) Run(component Component, run func(ctx context.Context)) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		r.SetReady(component)
+		defer r.SetNotReady(component, StoppedReason, "not running in this process")
+		run(ctx)
+	}
+}
+
+func (r *Readiness) notify() {
+	r.lock.RLock()
+	listeners := make([]func(), 0, len(r.listeners))
+	for _, listener := range r.listeners {
+		listeners = append(listeners, listener)
+	}
+	r.lock.RUnlock()
+	for _, listener := range listeners {
+		listener()
+	}
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tmcexport
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	kcpcache "github.com/kcp-dev/apimachinery/v2/pkg/cache"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/reconciler/committer"
+	"github.com/kcp-dev/kcp/pkg/tmc/workspacequeue"
+	apisv1alpha2 "github.com/kcp-dev/kcp/sdk/apis/apis/v1alpha2"
+	kcpclientset "github.com/kcp-dev/kcp/sdk/client/clientset/versioned/cluster"
+	apisv1alpha2client "github.com/kcp-dev/kcp/sdk/client/clientset/versioned/typed/apis/v1alpha2"
+	apisv1alpha2informers "github.com/kcp-dev/kcp/sdk/client/informers/externalversions/apis/v1alpha2"
+)
+
+const (
+	ControllerName = "kcp-tmc-tmcexport"
+)
+
+// NewController returns a controller that reports on the APIExports of the
+// TMC APIs whether the components in readiness are ready to reconcile the
+// TMC resources of workspaces binding them.
+func NewController(
+	readiness *Readiness,
+	apiExportInformer apisv1alpha2informers.APIExportClusterInformer,
+	kcpClusterClient kcpclientset.ClusterInterface,
+) (*controller, error) {
+	c := &controller{
+		queue:     workspacequeue.NewRateLimitingQueue(ControllerName),
+		readiness: readiness,
+		listAPIExports: func() ([]*apisv1alpha2.APIExport, error) {
+			return apiExportInformer.Lister().List(labels.Everything())
+		},
+		getAPIExport: func(clusterName logicalcluster.Name, name string) (*apisv1alpha2.APIExport, error) {
+			return apiExportInformer.Lister().Cluster(clusterName).Get(name)
+		},
+		commit: committer.NewCommitter[*APIExport, Patcher, *APIExportSpec, *APIExportStatus](kcpClusterClient.ApisV1alpha2().APIExports()),
+	}
+
+	_, _ = apiExportInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueueAPIExport(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueueAPIExport(obj) },
+	})
+
+	return c, nil
+}
+
+type APIExport = apisv1alpha2.APIExport
+type APIExportSpec = apisv1alpha2.APIExportSpec
+type APIExportStatus = apisv1alpha2.APIExportStatus
+type Patcher = apisv1alpha2client.APIExportInterface
+type Resource = committer.Resource[*APIExportSpec, *APIExportStatus]
+type CommitFunc = func(context.Context, *Resource, *Resource) error
+
+// controller maintains the TMCControllersReady condition of the APIExports
+// of the TMC APIs.
+type controller struct {
+	queue workqueue.TypedRateLimitingInterface[string]
+
+	readiness *Readiness
+
+	listAPIExports func() ([]*apisv1alpha2.APIExport, error)
+	getAPIExport   func(clusterName logicalcluster.Name, name string) (*apisv1alpha2.APIExport, error)
+
+	commit CommitFunc
+}
+
+// enqueueAPIExport enqueues obj if it is an APIExport of the TMC APIs.
+func (c *controller) enqueueAPIExport(obj interface{}) {
+	apiExport, ok := obj.(*apisv1alpha2.APIExport)
+	if !ok {
+		utilruntime.HandleError(fmt.Errorf("expected *APIExport, got %T", obj))
+		return
+	}
+	if !ExportsTMC(apiExport) {
+		return
+	}
+	key, err := kcpcache.MetaClusterNamespaceKeyFunc(apiExport)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+
+	logger := logging.WithQueueKey(logging.WithReconciler(klog.Background(), ControllerName), key)
+	logger.V(4).Info("queueing APIExport")
+	c.queue.Add(key)
+}
+
+// enqueueAllAPIExports enqueues the APIExports of the TMC APIs, when the
+// readiness of a component changed.
+func (c *controller) enqueueAllAPIExports() {
+	apiExports, err := c.listAPIExports()
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, apiExport := range apiExports {
+		c.enqueueAPIExport(apiExport)
+	}
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	logger := logging.WithReconciler(klog.FromContext(ctx), ControllerName)
+	ctx = klog.NewContext(ctx, logger)
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	// The readiness of the components is shared by the controllers of
+	// successive leader elections, stop listening with this one.
+	defer c.readiness.AddListener(c.enqueueAllAPIExports)()
+	// Readiness changed while the informer was syncing.
+	c.enqueueAllAPIExports()
+
+	for range numThreads {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+
+	logger := logging.WithQueueKey(klog.FromContext(ctx), key)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(4).Info("processing key")
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		utilruntime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", ControllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) error {
+	clusterName, _, name, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return nil
+	}
+
+	old, err := c.getAPIExport(clusterName, name)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	obj := old.DeepCopy()
+
+	logger := logging.WithObject(klog.FromContext(ctx), obj)
+	ctx = klog.NewContext(ctx, logger)
+
+	c.reconcile(ctx, obj)
+
+	oldResource := &Resource{ObjectMeta: old.ObjectMeta, Spec: &old.Spec, Status: &old.Status}
+	newResource := &Resource{ObjectMeta: obj.ObjectMeta, Spec: &obj.Spec, Status: &obj.Status}
+	return c.commit(ctx, oldResource, newResource)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tmcexport
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	apisv1alpha2 "github.com/kcp-dev/kcp/sdk/apis/apis/v1alpha2"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+const (
+	// TMCControllersReady is the condition of an APIExport of the TMC APIs
+	// telling whether the TMC controllers are ready to reconcile the TMC
+	// resources of workspaces binding it. New APIBindings to the APIExport
+	// are rejected while it is false.
+	TMCControllersReady conditionsv1alpha1.ConditionType = "TMCControllersReady"
+
+	// ControllersNotReadyReason is the reason of TMCControllersReady when a
+	// required component is not ready. The message lists them, with their
+	// own reasons.
+	ControllersNotReadyReason = "ControllersNotReady"
+)
+
+// tmcGroups are the API groups of the TMC APIs.
+var tmcGroups = sets.New(
+	tmcv1alpha1.SchemeGroupVersion.Group,
+	placementv1alpha1.SchemeGroupVersion.Group,
+	workloadv1alpha1.SchemeGroupVersion.Group,
+)
+
+// ExportsTMC returns whether apiExport exports a resource of the TMC APIs.
+func ExportsTMC(apiExport *apisv1alpha2.APIExport) bool {
+	for _, resource := range apiExport.Spec.Resources {
+		if tmcGroups.Has(resource.Group) {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcile sets the TMCControllersReady condition of apiExport.
+func (c *controller) reconcile(ctx context.Context, apiExport *apisv1alpha2.APIExport) {
+	logger := klog.FromContext(ctx)
+
+	notReady := c.readiness.NotReady()
+	if len(notReady) == 0 {
+		if !conditions.IsTrue(apiExport, TMCControllersReady) {
+			logger.V(2).Info("TMC controllers are ready")
+		}
+		conditions.MarkTrue(apiExport, TMCControllersReady)
+		return
+	}
+
+	message := notReadyMessage(notReady)
+	if conditions.GetMessage(apiExport, TMCControllersReady) != message {
+		logger.V(2).Info("TMC controllers are not ready", "reason", message)
+	}
+	conditions.MarkFalse(apiExport, TMCControllersReady, ControllersNotReadyReason, conditionsv1alpha1.ConditionSeverityError, "%s", message)
+}
+
+// notReadyMessage explains why the components of statuses are not ready,
+// e.g. "PlacementController: WaitingForCaches: informers have not synced".
+func notReadyMessage(statuses []ComponentStatus) string {
+	reasons := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		reasons = append(reasons, fmt.Sprintf("%s: %s: %s", status.Component, status.Reason, status.Message))
+	}
+	return strings.Join(reasons, "; ")
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tmcexport
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apisv1alpha2 "github.com/kcp-dev/kcp/sdk/apis/apis/v1alpha2"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+)
+
+func TestReadiness(t *testing.T) {
+	r := NewReadiness()
+	notifications := 0
+	r.AddListener(func() { notifications++ })
+	require.Empty(t, r.NotReady())
+
+	r.Require(PlacementController)
+	r.Require(PlacementController)
+	require.Equal(t, 1, notifications, "requiring a component again keeps its state")
+	require.Equal(t, []ComponentStatus{{
+		Component: PlacementController,
+		Reason:    WaitingForCachesReason,
+		Message:   "informers have not synced",
+	}}, r.NotReady())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	running := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Run(PlacementController, func(ctx context.Context) {
+			close(running)
+			<-ctx.Done()
+		})(ctx)
+	}()
+	<-running
+	require.Empty(t, r.NotReady())
+
+	cancel()
+	<-done
+	require.Equal(t, []ComponentStatus{{
+		Component: PlacementController,
+		Reason:    StoppedReason,
+		Message:   "not running in this process",
+	}}, r.NotReady())
+	require.Equal(t, 3, notifications)
+
+	r.SetNotReady("Other", "Unknown", "not required")
+	require.Len(t, r.NotReady(), 1, "components that are not required are ignored")
+}
+
+func TestReconcile(t *testing.T) {
+	r := NewReadiness()
+	c := &controller{readiness: r}
+	apiExport := &apisv1alpha2.APIExport{
+		ObjectMeta: metav1.ObjectMeta{Name: "tmc.kcp.io"},
+		Spec: apisv1alpha2.APIExportSpec{Resources: []apisv1alpha2.ResourceSchema{
+			{Group: "tmc.kcp.io", Name: "synctargets", Schema: "v1.synctargets.tmc.kcp.io"},
+		}},
+	}
+	r.Require(PlacementController)
+	r.Require("StatusAggregationController")
+	r.SetNotReady("StatusAggregationController", StoppedReason, "not running in this process")
+	c.reconcile(context.Background(), apiExport)
+	require.True(t, conditions.IsFalse(apiExport, TMCControllersReady))
+	require.Equal(t, ControllersNotReadyReason, conditions.GetReason(apiExport, TMCControllersReady))
+	require.Equal(t,
+		"PlacementController: WaitingForCaches: informers have not synced; StatusAggregationController: Stopped: not running in this process",
+		conditions.GetMessage(apiExport, TMCControllersReady))
+
+	r.SetReady(PlacementController)
+	r.SetReady("StatusAggregationController")
+	c.reconcile(context.Background(), apiExport)
+	require.True(t, conditions.IsTrue(apiExport, TMCControllersReady))
+}
+
+func TestExportsTMC(t *testing.T) {
+	export := func(group string) *apisv1alpha2.APIExport {
+		return &apisv1alpha2.APIExport{Spec: apisv1alpha2.APIExportSpec{Resources: []apisv1alpha2.ResourceSchema{
+			{Group: group, Name: "things", Schema: "v1.things." + group},
+		}}}
+	}
+	require.True(t, ExportsTMC(export("tmc.kcp.io")))
+	require.True(t, ExportsTMC(export("placement.kcp.io")))
+	require.True(t, ExportsTMC(export("workload.kcp.io")))
+	require.False(t, ExportsTMC(export("tenancy.kcp.io")))
+	require.False(t, ExportsTMC(&apisv1alpha2.APIExport{}))
+}
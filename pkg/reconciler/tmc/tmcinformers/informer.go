@@ -0,0 +1,545 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tmcinformers provides informers for TMC resources that watch all
+// logical clusters with a single wildcard (cluster=*) watch, and hand events
+// to reconcilers by logical cluster. When the client may not watch across
+// logical clusters, the informers fall back to one watch per workspace, for
+// the workspaces the factory is told about and those with cluster event
+// handlers.
+package tmcinformers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	kcpcache "github.com/kcp-dev/apimachinery/v2/pkg/cache"
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	kcpinformers "github.com/kcp-dev/client-go/informers"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	kcpindexers "github.com/kcp-dev/kcp/pkg/indexers"
+)
+
+const (
+	resyncPeriod = 10 * time.Hour
+
+	// permissionRetryInterval is how often Start retries the wildcard
+	// permission check when it fails for reasons other than a denial.
+	permissionRetryInterval = 5 * time.Second
+)
+
+// Source returns wildcard informers. The server's
+// DiscoveringDynamicSharedInformerFactory is a Source.
+type Source interface {
+	ForResource(gvr schema.GroupVersionResource) (kcpinformers.GenericClusterInformer, error)
+}
+
+// Factory hands out one shared Informer per TMC resource, so controllers that
+// watch the same resource share a single watch.
+type Factory struct {
+	source Source
+
+	// canWatchAll reports whether the client may list and watch gvr across
+	// all logical clusters.
+	canWatchAll func(ctx context.Context, gvr schema.GroupVersionResource) (bool, error)
+	// newWorkspaceInformer returns an informer for gvr in a single workspace.
+	newWorkspaceInformer func(clusterName logicalcluster.Name, gvr schema.GroupVersionResource) cache.SharedIndexInformer
+
+	lock       sync.Mutex
+	informers  map[schema.GroupVersionResource]*Informer
+	workspaces map[logicalcluster.Name]bool
+}
+
+// NewFactory returns a Factory that takes wildcard informers from source. The
+// client is used to check for the wildcard permission and, without it, to
+// watch each workspace on its own. Workspaces lists the workspaces watched in
+// that case, in addition to those added with WatchWorkspace and those that
+// have cluster event handlers.
+func NewFactory(source Source, client kcpdynamic.ClusterInterface, workspaces ...logicalcluster.Name) *Factory {
+	f := &Factory{
+		source: source,
+		canWatchAll: func(ctx context.Context, gvr schema.GroupVersionResource) (bool, error) {
+			_, err := client.Resource(gvr).List(ctx, metav1.ListOptions{Limit: 1})
+			if apierrors.IsForbidden(err) {
+				return false, nil
+			}
+			return err == nil, err
+		},
+		newWorkspaceInformer: func(clusterName logicalcluster.Name, gvr schema.GroupVersionResource) cache.SharedIndexInformer {
+			return dynamicinformer.NewFilteredDynamicInformer(
+				client.Cluster(clusterName.Path()),
+				gvr,
+				metav1.NamespaceAll,
+				resyncPeriod,
+				cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+				nil,
+			).Informer()
+		},
+		informers:  map[schema.GroupVersionResource]*Informer{},
+		workspaces: map[logicalcluster.Name]bool{},
+	}
+	for _, clusterName := range workspaces {
+		f.workspaces[clusterName] = true
+	}
+	return f
+}
+
+// ForResource returns the Informer for gvr, creating it if needed. The
+// Informer must be started with Start before it delivers events.
+func (f *Factory) ForResource(gvr schema.GroupVersionResource) *Informer {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if inf, ok := f.informers[gvr]; ok {
+		return inf
+	}
+	inf := &Informer{
+		factory:         f,
+		gvr:             gvr,
+		clusterHandlers: map[logicalcluster.Name][]cache.ResourceEventHandler{},
+		workspaces:      map[logicalcluster.Name]*workspaceWatch{},
+	}
+	f.informers[gvr] = inf
+	return inf
+}
+
+// WatchWorkspace adds a workspace to those watched without the wildcard
+// permission, e.g. when it is created. It has no effect on informers
+// watching all logical clusters.
+func (f *Factory) WatchWorkspace(clusterName logicalcluster.Name) {
+	for _, inf := range f.setWorkspace(clusterName, true) {
+		inf.lock.Lock()
+		if inf.started && inf.wildcard == nil {
+			inf.watchWorkspaceLockHeld(clusterName)
+		}
+		inf.lock.Unlock()
+	}
+}
+
+// ForgetWorkspace stops watching a workspace added with WatchWorkspace, e.g.
+// when it is deleted, unless cluster event handlers of it remain.
+func (f *Factory) ForgetWorkspace(clusterName logicalcluster.Name) {
+	for _, inf := range f.setWorkspace(clusterName, false) {
+		inf.lock.Lock()
+		if w, ok := inf.workspaces[clusterName]; ok && len(inf.clusterHandlers[clusterName]) == 0 {
+			w.cancel()
+			delete(inf.workspaces, clusterName)
+		}
+		inf.lock.Unlock()
+	}
+}
+
+// setWorkspace records whether clusterName is watched, and returns the
+// informers.
+func (f *Factory) setWorkspace(clusterName logicalcluster.Name, watched bool) []*Informer {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if watched {
+		f.workspaces[clusterName] = true
+	} else {
+		delete(f.workspaces, clusterName)
+	}
+	informers := make([]*Informer, 0, len(f.informers))
+	for _, inf := range f.informers {
+		informers = append(informers, inf)
+	}
+	return informers
+}
+
+func (f *Factory) workspaceNames() []logicalcluster.Name {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	names := make([]logicalcluster.Name, 0, len(f.workspaces))
+	for clusterName := range f.workspaces {
+		names = append(names, clusterName)
+	}
+	return names
+}
+
+// Informer delivers the events of one TMC resource. Handlers added with
+// AddEventHandler see every logical cluster, handlers added with
+// AddClusterEventHandler only see their own.
+type Informer struct {
+	factory *Factory
+	gvr     schema.GroupVersionResource
+
+	startOnce sync.Once
+
+	lock                 sync.RWMutex
+	ctx                  context.Context
+	started              bool
+	wildcard             kcpinformers.GenericClusterInformer
+	wildcardRegistration cache.ResourceEventHandlerRegistration
+	workspaces           map[logicalcluster.Name]*workspaceWatch
+	indexers             cache.Indexers
+	handlers             []cache.ResourceEventHandler
+	clusterHandlers      map[logicalcluster.Name][]cache.ResourceEventHandler
+}
+
+// workspaceWatch is the watch of a single workspace, without the wildcard
+// permission.
+type workspaceWatch struct {
+	informer     cache.SharedIndexInformer
+	registration cache.ResourceEventHandlerRegistration
+	cancel       context.CancelFunc
+}
+
+// Start decides between a wildcard watch and per-workspace watches, and
+// starts delivering events. It is safe to call Start more than once; only the
+// first call has an effect. It returns an error only if ctx is done before the
+// decision is made.
+func (i *Informer) Start(ctx context.Context) error {
+	var err error
+	i.startOnce.Do(func() {
+		err = i.start(ctx)
+	})
+	return err
+}
+
+func (i *Informer) start(ctx context.Context) error {
+	logger := klog.FromContext(ctx).WithValues("gvr", i.gvr)
+
+	var wildcard bool
+	if err := wait.PollUntilContextCancel(ctx, permissionRetryInterval, true, func(ctx context.Context) (bool, error) {
+		allowed, err := i.factory.canWatchAll(ctx, i.gvr)
+		if err != nil {
+			logger.Error(err, "failed to check for the wildcard watch permission")
+			return false, nil
+		}
+		wildcard = allowed
+		return true, nil
+	}); err != nil {
+		return err
+	}
+
+	var clusterInformer kcpinformers.GenericClusterInformer
+	if wildcard {
+		var err error
+		if clusterInformer, err = i.factory.source.ForResource(i.gvr); err != nil {
+			return err
+		}
+	}
+	workspaces := i.factory.workspaceNames()
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	i.ctx = ctx
+	i.started = true
+
+	if wildcard {
+		logger.V(2).Info("watching all logical clusters")
+		i.wildcard = clusterInformer
+		kcpindexers.AddIfNotPresentOrDie(clusterInformer.Informer().GetIndexer(), copyIndexers(i.indexers))
+		registration, err := clusterInformer.Informer().AddEventHandler(&dispatcher{informer: i})
+		if err != nil {
+			return err
+		}
+		i.wildcardRegistration = registration
+		return nil
+	}
+
+	logger.Info("wildcard watch not permitted, watching workspaces one by one")
+	for _, clusterName := range workspaces {
+		i.watchWorkspaceLockHeld(clusterName)
+	}
+	for clusterName := range i.clusterHandlers {
+		i.watchWorkspaceLockHeld(clusterName)
+	}
+	if len(i.workspaces) == 0 {
+		logger.Info("no workspaces to watch yet")
+	}
+	return nil
+}
+
+// watchWorkspaceLockHeld starts a watch on a single workspace, unless it is
+// already watched. The caller must hold the write lock.
+func (i *Informer) watchWorkspaceLockHeld(clusterName logicalcluster.Name) {
+	if _, ok := i.workspaces[clusterName]; ok {
+		return
+	}
+	informer := i.factory.newWorkspaceInformer(clusterName, i.gvr)
+	kcpindexers.AddIfNotPresentOrDie(informer.GetIndexer(), copyIndexers(i.indexers))
+	registration, err := informer.AddEventHandler(&dispatcher{informer: i, clusterName: clusterName})
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	ctx, cancel := context.WithCancel(i.ctx)
+	i.workspaces[clusterName] = &workspaceWatch{informer: informer, registration: registration, cancel: cancel}
+	go informer.Run(ctx.Done())
+}
+
+// Wildcard returns true if the Informer watches all logical clusters at once.
+// It returns false before Start.
+func (i *Informer) Wildcard() bool {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	return i.wildcard != nil
+}
+
+// HasSynced returns true once the Informer is started and every watch it
+// started has delivered its initial list to the handlers.
+func (i *Informer) HasSynced() bool {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	if !i.started {
+		return false
+	}
+	if i.wildcardRegistration != nil && !i.wildcardRegistration.HasSynced() {
+		return false
+	}
+	for _, w := range i.workspaces {
+		if !w.registration.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// AddEventHandler adds a handler for the events of every logical cluster.
+// A handler added after Start receives the objects already known as adds.
+func (i *Informer) AddEventHandler(handler cache.ResourceEventHandler) {
+	i.lock.Lock()
+	i.handlers = append(i.handlers, handler)
+	started := i.started
+	i.lock.Unlock()
+
+	if started {
+		i.replay(handler, nil)
+	}
+}
+
+// AddClusterEventHandler adds a handler for the events of a single logical
+// cluster. Without the wildcard permission, this starts a watch on that
+// workspace if there is none yet. A handler added after Start receives the
+// objects already known as adds.
+func (i *Informer) AddClusterEventHandler(clusterName logicalcluster.Name, handler cache.ResourceEventHandler) {
+	i.lock.Lock()
+	i.clusterHandlers[clusterName] = append(i.clusterHandlers[clusterName], handler)
+	started := i.started
+	_, watched := i.workspaces[clusterName]
+	if started && i.wildcard == nil {
+		i.watchWorkspaceLockHeld(clusterName)
+	}
+	i.lock.Unlock()
+
+	// A new workspace watch delivers the initial objects by itself.
+	if started && (i.Wildcard() || watched) {
+		i.replay(handler, &clusterName)
+	}
+}
+
+// Lister returns a lister for the objects of a single logical cluster. It
+// lists nothing if the logical cluster is not watched.
+func (i *Informer) Lister(clusterName logicalcluster.Name) cache.GenericLister {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	if i.wildcard != nil {
+		return i.wildcard.Lister().ByCluster(clusterName)
+	}
+	if w, ok := i.workspaces[clusterName]; ok {
+		return cache.NewGenericLister(w.informer.GetIndexer(), i.gvr.GroupResource())
+	}
+	return cache.NewGenericLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}), i.gvr.GroupResource())
+}
+
+// List lists the objects of all watched logical clusters that match
+// selector.
+func (i *Informer) List(selector labels.Selector) ([]runtime.Object, error) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	if i.wildcard != nil {
+		return i.wildcard.Lister().List(selector)
+	}
+	var objs []runtime.Object
+	for _, w := range i.workspaces {
+		err := cache.ListAll(w.informer.GetIndexer(), selector, func(obj interface{}) {
+			if obj, ok := obj.(runtime.Object); ok {
+				objs = append(objs, obj)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return objs, nil
+}
+
+// AddIndexers adds indexers to the watches of the Informer. Indexers added
+// before Start are added to the watches it starts.
+func (i *Informer) AddIndexers(indexers cache.Indexers) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if i.indexers == nil {
+		i.indexers = cache.Indexers{}
+	}
+	for name, indexFunc := range indexers {
+		i.indexers[name] = indexFunc
+	}
+	if i.wildcard != nil {
+		kcpindexers.AddIfNotPresentOrDie(i.wildcard.Informer().GetIndexer(), copyIndexers(indexers))
+	}
+	for _, w := range i.workspaces {
+		kcpindexers.AddIfNotPresentOrDie(w.informer.GetIndexer(), copyIndexers(indexers))
+	}
+}
+
+// GetByKey returns the object with the given cluster-aware key.
+func (i *Informer) GetByKey(key string) (interface{}, bool, error) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	if i.wildcard != nil {
+		return i.wildcard.Informer().GetIndexer().GetByKey(key)
+	}
+	clusterName, namespace, name, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		return nil, false, err
+	}
+	w, ok := i.workspaces[clusterName]
+	if !ok {
+		return nil, false, nil
+	}
+	if namespace != "" {
+		name = namespace + "/" + name
+	}
+	return w.informer.GetIndexer().GetByKey(name)
+}
+
+// ByIndex returns the objects of all watched logical clusters whose index
+// of the given name contains indexedValue. The index must have been added
+// with AddIndexers.
+func (i *Informer) ByIndex(indexName, indexedValue string) ([]interface{}, error) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	if i.wildcard != nil {
+		return i.wildcard.Informer().GetIndexer().ByIndex(indexName, indexedValue)
+	}
+	var objs []interface{}
+	for _, w := range i.workspaces {
+		matches, err := w.informer.GetIndexer().ByIndex(indexName, indexedValue)
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, matches...)
+	}
+	return objs, nil
+}
+
+// copyIndexers returns a copy of indexers, which AddIfNotPresentOrDie
+// modifies.
+func copyIndexers(indexers cache.Indexers) cache.Indexers {
+	c := make(cache.Indexers, len(indexers))
+	for name, indexFunc := range indexers {
+		c[name] = indexFunc
+	}
+	return c
+}
+
+// replay hands the objects already known to handler as adds, either of one
+// logical cluster or, if clusterName is nil, of all of them.
+func (i *Informer) replay(handler cache.ResourceEventHandler, clusterName *logicalcluster.Name) {
+	var objs []runtime.Object
+	if clusterName != nil {
+		var err error
+		if objs, err = i.Lister(*clusterName).List(labels.Everything()); err != nil {
+			utilruntime.HandleError(err)
+			return
+		}
+	} else {
+		var err error
+		if objs, err = i.List(labels.Everything()); err != nil {
+			utilruntime.HandleError(err)
+			return
+		}
+	}
+
+	for _, obj := range objs {
+		handler.OnAdd(obj, true)
+	}
+}
+
+// handlersFor returns the handlers that receive the events of clusterName.
+func (i *Informer) handlersFor(clusterName logicalcluster.Name) []cache.ResourceEventHandler {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	handlers := make([]cache.ResourceEventHandler, 0, len(i.handlers)+len(i.clusterHandlers[clusterName]))
+	handlers = append(handlers, i.handlers...)
+	handlers = append(handlers, i.clusterHandlers[clusterName]...)
+	return handlers
+}
+
+// dispatcher hands the events of a watch to the handlers of the logical
+// cluster each object belongs to. For a workspace watch, clusterName is that
+// workspace; for the wildcard watch it is empty and taken from the object.
+type dispatcher struct {
+	informer    *Informer
+	clusterName logicalcluster.Name
+}
+
+func (d *dispatcher) clusterOf(obj interface{}) logicalcluster.Name {
+	if !d.clusterName.Empty() {
+		return d.clusterName
+	}
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return ""
+	}
+	return logicalcluster.From(accessor)
+}
+
+func (d *dispatcher) OnAdd(obj interface{}, isInInitialList bool) {
+	for _, handler := range d.informer.handlersFor(d.clusterOf(obj)) {
+		handler.OnAdd(obj, isInInitialList)
+	}
+}
+
+func (d *dispatcher) OnUpdate(oldObj, newObj interface{}) {
+	for _, handler := range d.informer.handlersFor(d.clusterOf(newObj)) {
+		handler.OnUpdate(oldObj, newObj)
+	}
+}
+
+func (d *dispatcher) OnDelete(obj interface{}) {
+	for _, handler := range d.informer.handlersFor(d.clusterOf(obj)) {
+		handler.OnDelete(obj)
+	}
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tmcinformers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+)
+
+var syncTargetsGVR = schema.GroupVersionResource{Group: "tmc.kcp.io", Version: "v1alpha1", Resource: "synctargets"}
+
+func newSyncTarget(clusterName logicalcluster.Name, name string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("tmc.kcp.io/v1alpha1")
+	u.SetKind("SyncTarget")
+	u.SetName(name)
+	u.SetAnnotations(map[string]string{logicalcluster.AnnotationKey: clusterName.String()})
+	return u
+}
+
+// recorder records the names of the objects it is told about.
+type recorder struct {
+	lock  sync.Mutex
+	added []string
+}
+
+func (r *recorder) OnAdd(obj interface{}, _ bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.added = append(r.added, obj.(*unstructured.Unstructured).GetName())
+}
+
+func (r *recorder) OnUpdate(_, _ interface{}) {}
+func (r *recorder) OnDelete(_ interface{})    {}
+
+func (r *recorder) names() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	names := append([]string(nil), r.added...)
+	sort.Strings(names)
+	return names
+}
+
+func TestDispatcherScopesByCluster(t *testing.T) {
+	inf := NewFactory(nil, nil).ForResource(syncTargetsGVR)
+
+	all, east, west := &recorder{}, &recorder{}, &recorder{}
+	inf.AddEventHandler(all)
+	inf.AddClusterEventHandler("east", east)
+	inf.AddClusterEventHandler("west", west)
+
+	d := &dispatcher{informer: inf}
+	d.OnAdd(newSyncTarget("east", "a"), true)
+	d.OnAdd(newSyncTarget("west", "b"), true)
+	d.OnAdd(newSyncTarget("north", "c"), true)
+
+	require.Equal(t, []string{"a", "b", "c"}, all.names())
+	require.Equal(t, []string{"a"}, east.names())
+	require.Equal(t, []string{"b"}, west.names())
+}
+
+func TestDispatcherHandlesTombstones(t *testing.T) {
+	inf := NewFactory(nil, nil).ForResource(syncTargetsGVR)
+
+	var deleted []string
+	inf.AddClusterEventHandler("east", cache.ResourceEventHandlerFuncs{
+		DeleteFunc: func(obj interface{}) {
+			deleted = append(deleted, obj.(cache.DeletedFinalStateUnknown).Key)
+		},
+	})
+
+	d := &dispatcher{informer: inf}
+	d.OnDelete(cache.DeletedFinalStateUnknown{Key: "east|a", Obj: newSyncTarget("east", "a")})
+	d.OnDelete(cache.DeletedFinalStateUnknown{Key: "west|b", Obj: newSyncTarget("west", "b")})
+
+	require.Equal(t, []string{"east|a"}, deleted)
+}
+
+func TestFallbackWatchesEachWorkspace(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	clients := map[logicalcluster.Name]*dynamicfake.FakeDynamicClient{}
+	for clusterName, names := range map[logicalcluster.Name][]string{
+		"east":  {"a"},
+		"west":  {"b"},
+		"north": {"c"},
+	} {
+		var objs []runtime.Object
+		for _, name := range names {
+			objs = append(objs, newSyncTarget(clusterName, name))
+		}
+		clients[clusterName] = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{syncTargetsGVR: "SyncTargetList"}, objs...)
+	}
+
+	var watched []logicalcluster.Name
+	f := NewFactory(nil, nil, "east")
+	f.canWatchAll = func(context.Context, schema.GroupVersionResource) (bool, error) {
+		return false, nil
+	}
+	f.newWorkspaceInformer = func(clusterName logicalcluster.Name, gvr schema.GroupVersionResource) cache.SharedIndexInformer {
+		watched = append(watched, clusterName)
+		return dynamicinformer.NewFilteredDynamicInformer(clients[clusterName], gvr, "", 0, cache.Indexers{}, nil).Informer()
+	}
+
+	inf := f.ForResource(syncTargetsGVR)
+	require.Same(t, inf, f.ForResource(syncTargetsGVR), "informers are shared")
+
+	all, west := &recorder{}, &recorder{}
+	inf.AddEventHandler(all)
+	inf.AddClusterEventHandler("west", west)
+
+	require.NoError(t, inf.Start(ctx))
+	require.False(t, inf.Wildcard())
+	require.Eventually(t, inf.HasSynced, wait.ForeverTestTimeout, 10*time.Millisecond)
+	require.ElementsMatch(t, []logicalcluster.Name{"east", "west"}, watched)
+	require.Equal(t, []string{"a", "b"}, all.names())
+	require.Equal(t, []string{"b"}, west.names())
+
+	obj, err := inf.Lister("east").Get("a")
+	require.NoError(t, err)
+	require.Equal(t, "a", obj.(*unstructured.Unstructured).GetName())
+	objs, err := inf.Lister("north").List(labels.Everything())
+	require.NoError(t, err)
+	require.Empty(t, objs, "north is not watched yet")
+
+	// A handler for a new workspace starts a watch on it.
+	north := &recorder{}
+	inf.AddClusterEventHandler("north", north)
+	require.Eventually(t, func() bool { return len(north.names()) == 1 }, wait.ForeverTestTimeout, 10*time.Millisecond)
+	require.Equal(t, []string{"c"}, north.names())
+	require.Eventually(t, func() bool { return len(all.names()) == 3 }, wait.ForeverTestTimeout, 10*time.Millisecond)
+
+	// A late handler for a watched workspace is told about the known objects.
+	lateEast := &recorder{}
+	inf.AddClusterEventHandler("east", lateEast)
+	require.Equal(t, []string{"a"}, lateEast.names())
+	require.Len(t, watched, 3)
+}
+
+// fallbackTimeout bounds the waits for watches against the test server.
+const fallbackTimeout = 5 * time.Second
+
+// TestFallbackWithoutWildcardPermission runs the factory against a server
+// that denies wildcard requests, with the client of NewFactory.
+func TestFallbackWithoutWildcardPermission(t *testing.T) {
+	objects := map[string][]string{"east": {"a"}, "west": {"b"}}
+	stop := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		clusterName, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/clusters/"), "/")
+		if clusterName == "*" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"Status","status":"Failure","reason":"Forbidden","code":403}`))
+			return
+		}
+		if r.URL.Query().Get("watch") == "true" {
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+			case <-stop:
+			}
+			return
+		}
+		list := &unstructured.UnstructuredList{Object: map[string]interface{}{"apiVersion": "tmc.kcp.io/v1alpha1", "kind": "SyncTargetList"}}
+		list.SetResourceVersion("1")
+		for _, name := range objects[clusterName] {
+			list.Items = append(list.Items, *newSyncTarget(logicalcluster.Name(clusterName), name))
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	}))
+	t.Cleanup(server.Close)
+	// Cleanups run last in first out: end the watches before the server
+	// waits for their connections.
+	t.Cleanup(func() { close(stop) })
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	client, err := kcpdynamic.NewForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+	f := NewFactory(nil, client, "east")
+	inf := f.ForResource(syncTargetsGVR)
+	all := &recorder{}
+	inf.AddEventHandler(all)
+
+	require.NoError(t, inf.Start(ctx))
+	require.False(t, inf.Wildcard(), "wildcard watches are forbidden")
+	require.Eventually(t, inf.HasSynced, fallbackTimeout, 10*time.Millisecond)
+	require.Equal(t, []string{"a"}, all.names())
+
+	f.WatchWorkspace("west")
+	require.Eventually(t, func() bool { return len(all.names()) == 2 }, fallbackTimeout, 10*time.Millisecond)
+	objs, err := inf.List(labels.Everything())
+	require.NoError(t, err)
+	require.Len(t, objs, 2)
+
+	f.ForgetWorkspace("west")
+	objs, err = inf.Lister("west").List(labels.Everything())
+	require.NoError(t, err)
+	require.Empty(t, objs, "forgotten workspaces are not watched")
+	objs, err = inf.List(labels.Everything())
+	require.NoError(t, err)
+	require.Len(t, objs, 1)
+}
@@ -0,0 +1,301 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadrollout
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	kcpcache "github.com/kcp-dev/apimachinery/v2/pkg/cache"
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/reconciler/dynamicrestmapper"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/tmcinformers"
+	"github.com/kcp-dev/kcp/pkg/tmc/workspacequeue"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+const (
+	ControllerName = "kcp-tmc-workload-rollout"
+
+	// byWorkload indexes WorkloadDistributions by their workload.
+	byWorkload = "workloadrollout-byWorkload"
+
+	// syncWait is how long to wait before retrying a WorkloadDistribution
+	// whose workload informer has not synced yet.
+	syncWait = time.Second
+)
+
+// WorkloadDistributionsGVR is the resource whose rollouts are managed.
+var WorkloadDistributionsGVR = workloadv1alpha1.SchemeGroupVersion.WithResource("workloaddistributions")
+
+// NewController returns a controller that rolls the workloads of
+// WorkloadDistributions with a rollout strategy out onto their SyncTargets
+// in waves. A wave starts once the targets of the previous one stayed
+// healthy, according to the statuses they report on the workload, and is
+// rolled back when too many of its targets fail. workloadInformer returns
+// the started informer of a workload resource.
+func NewController(
+	distributionClusterInformer *tmcinformers.Informer,
+	workloadInformer func(gvr schema.GroupVersionResource) *tmcinformers.Informer,
+	dynamicClusterClient kcpdynamic.ClusterInterface,
+	dynRESTMapper *dynamicrestmapper.DynamicRESTMapper,
+) (*controller, error) {
+	c := &controller{
+		queue:    workspacequeue.NewRateLimitingQueue(ControllerName),
+		now:      time.Now,
+		informed: map[schema.GroupVersionResource]*tmcinformers.Informer{},
+		getDistribution: func(clusterName logicalcluster.Name, namespace, name string) (*workloadv1alpha1.WorkloadDistribution, error) {
+			obj, err := distributionClusterInformer.Lister(clusterName).ByNamespace(namespace).Get(name)
+			if err != nil {
+				return nil, err
+			}
+			distribution := &workloadv1alpha1.WorkloadDistribution{}
+			return distribution, fromUnstructured(obj, distribution)
+		},
+		restMapping: func(clusterName logicalcluster.Name, gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+			return dynRESTMapper.ForCluster(clusterName).RESTMapping(gvk.GroupKind(), gvk.Version)
+		},
+		updateStatus: func(ctx context.Context, clusterName logicalcluster.Name, distribution *workloadv1alpha1.WorkloadDistribution) error {
+			u, err := toUnstructured(distribution)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(WorkloadDistributionsGVR).Namespace(distribution.Namespace).UpdateStatus(ctx, u, metav1.UpdateOptions{})
+			return err
+		},
+	}
+	c.getWorkload = func(clusterName logicalcluster.Name, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, bool, error) {
+		informer := c.informWorkloads(gvr, workloadInformer, distributionClusterInformer)
+		if !informer.HasSynced() {
+			return nil, false, nil
+		}
+		obj, err := informer.Lister(clusterName).ByNamespace(namespace).Get(name)
+		if err != nil {
+			return nil, true, err
+		}
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return nil, true, fmt.Errorf("expected *unstructured.Unstructured, got %T", obj)
+		}
+		return u, true, nil
+	}
+
+	distributionClusterInformer.AddIndexers(cache.Indexers{byWorkload: indexByWorkload})
+	distributionClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueue(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+	})
+
+	return c, nil
+}
+
+// controller rolls out distributed workloads onto their SyncTargets.
+type controller struct {
+	queue workqueue.TypedRateLimitingInterface[string]
+
+	now func() time.Time
+
+	// informed are the informers of the workload resources the controller
+	// handles events of.
+	informedLock sync.Mutex
+	informed     map[schema.GroupVersionResource]*tmcinformers.Informer
+
+	getDistribution func(clusterName logicalcluster.Name, namespace, name string) (*workloadv1alpha1.WorkloadDistribution, error)
+	restMapping     func(clusterName logicalcluster.Name, gvk schema.GroupVersionKind) (*meta.RESTMapping, error)
+	// getWorkload returns the workload, and false if the informer of its
+	// resource has not synced yet.
+	getWorkload  func(clusterName logicalcluster.Name, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, bool, error)
+	updateStatus func(ctx context.Context, clusterName logicalcluster.Name, distribution *workloadv1alpha1.WorkloadDistribution) error
+}
+
+// informWorkloads returns the informer of the workload resource gvr, and
+// enqueues the WorkloadDistributions of its objects when they change.
+func (c *controller) informWorkloads(gvr schema.GroupVersionResource, workloadInformer func(gvr schema.GroupVersionResource) *tmcinformers.Informer, distributionClusterInformer *tmcinformers.Informer) *tmcinformers.Informer {
+	c.informedLock.Lock()
+	defer c.informedLock.Unlock()
+
+	if informer, found := c.informed[gvr]; found {
+		return informer
+	}
+	informer := workloadInformer(gvr)
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueueDistributionsOf(distributionClusterInformer, obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueueDistributionsOf(distributionClusterInformer, obj) },
+	})
+	c.informed[gvr] = informer
+	return informer
+}
+
+// workloadKey identifies a workload across logical clusters and versions.
+func workloadKey(clusterName logicalcluster.Name, namespace, group, kind, name string) string {
+	return strings.Join([]string{clusterName.String(), namespace, group, kind, name}, "|")
+}
+
+// indexByWorkload indexes only distributions with a rollout strategy, the
+// others are not rolled out.
+func indexByWorkload(obj interface{}) ([]string, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("expected *unstructured.Unstructured, got %T", obj)
+	}
+	distribution := &workloadv1alpha1.WorkloadDistribution{}
+	if err := fromUnstructured(u, distribution); err != nil {
+		return nil, err
+	}
+	if distribution.Spec.RolloutStrategy == nil {
+		return nil, nil
+	}
+	gv, err := schema.ParseGroupVersion(distribution.Spec.WorkloadRef.APIVersion)
+	if err != nil {
+		return nil, nil
+	}
+	return []string{workloadKey(logicalcluster.From(u), u.GetNamespace(), gv.Group, distribution.Spec.WorkloadRef.Kind, distribution.Spec.WorkloadRef.Name)}, nil
+}
+
+func (c *controller) enqueue(obj interface{}) {
+	key, err := kcpcache.DeletionHandlingMetaClusterNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+
+	logger := logging.WithQueueKey(logging.WithReconciler(klog.Background(), ControllerName), key)
+	logger.V(4).Info("queueing WorkloadDistribution")
+	c.queue.Add(key)
+}
+
+// enqueueDistributionsOf enqueues the WorkloadDistributions of a workload.
+func (c *controller) enqueueDistributionsOf(distributionClusterInformer *tmcinformers.Informer, obj interface{}) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		utilruntime.HandleError(fmt.Errorf("expected *unstructured.Unstructured, got %T", obj))
+		return
+	}
+	distributions, err := distributionClusterInformer.ByIndex(byWorkload, workloadKey(logicalcluster.From(u), u.GetNamespace(), u.GroupVersionKind().Group, u.GetKind(), u.GetName()))
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, distribution := range distributions {
+		c.enqueue(distribution)
+	}
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	logger := logging.WithReconciler(klog.FromContext(ctx), ControllerName)
+	ctx = klog.NewContext(ctx, logger)
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	for range numThreads {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+
+	logger := logging.WithQueueKey(klog.FromContext(ctx), key)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(4).Info("processing key")
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	requeueAfter, err := c.process(ctx, key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", ControllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	if requeueAfter > 0 {
+		c.queue.AddAfter(key, requeueAfter)
+	}
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) (time.Duration, error) {
+	clusterName, namespace, name, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return 0, nil
+	}
+
+	distribution, err := c.getDistribution(clusterName, namespace, name)
+	if errors.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !distribution.DeletionTimestamp.IsZero() {
+		return 0, nil
+	}
+
+	return c.reconcile(ctx, clusterName, distribution)
+}
+
+func fromUnstructured(obj runtime.Object, into interface{}) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("expected *unstructured.Unstructured, got %T", obj)
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, into)
+}
+
+func toUnstructured(obj interface{}) (*unstructured.Unstructured, error) {
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: raw}, nil
+}
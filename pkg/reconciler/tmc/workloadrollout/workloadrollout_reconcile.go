@@ -0,0 +1,330 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadrollout
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	aggregation "github.com/kcp-dev/kcp/pkg/statusaggregation"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+const (
+	defaultAnalysisPeriod   = 5 * time.Minute
+	defaultProgressDeadline = 10 * time.Minute
+)
+
+// reconcile advances the rollout of the workload of distribution.
+func (c *controller) reconcile(ctx context.Context, clusterName logicalcluster.Name, distribution *workloadv1alpha1.WorkloadDistribution) (time.Duration, error) {
+	logger := klog.FromContext(ctx)
+
+	strategy := distribution.Spec.RolloutStrategy
+	if strategy == nil {
+		if distribution.Status.Rollout == nil {
+			return 0, nil
+		}
+		// Without a strategy, the workload is synced to all its targets.
+		d := distribution.DeepCopy()
+		d.Status.Rollout = nil
+		logger.V(2).Info("clearing rollout status")
+		if err := c.updateStatus(ctx, clusterName, d); err != nil && !errors.IsNotFound(err) {
+			return 0, err
+		}
+		return 0, nil
+	}
+
+	targets := make([]string, 0, len(distribution.Status.Targets))
+	for _, target := range distribution.Status.Targets {
+		targets = append(targets, target.SyncTarget)
+	}
+	if len(targets) == 0 && distribution.Status.Rollout == nil {
+		// Not placed yet.
+		return 0, nil
+	}
+
+	ref := distribution.Spec.WorkloadRef
+	gvk := schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind)
+	mapping, err := c.restMapping(clusterName, gvk)
+	if err != nil {
+		return 0, fmt.Errorf("failed to map %s: %w", gvk, err)
+	}
+	workload, synced, err := c.getWorkload(clusterName, mapping.Resource, distribution.Namespace, ref.Name)
+	if !synced {
+		logger.V(4).Info("waiting for the informer of the workload resource to sync", "resource", mapping.Resource)
+		return syncWait, nil
+	}
+	if errors.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// The syncers of the SyncTargets of the waves rolled out so far report
+	// the status of the workload in their annotations. The syncers of later
+	// waves do not sync the workload before it is rolled out to them, see
+	// WorkloadDistribution.SyncedTargets.
+	statuses, err := aggregation.TargetStatuses(workload, targets)
+	if err != nil {
+		// The status is reported again when it changes.
+		utilruntime.HandleError(fmt.Errorf("failed to read the statuses of %s %s|%s/%s: %w", gvk.Kind, clusterName, workload.GetNamespace(), workload.GetName(), err))
+		return 0, nil
+	}
+	health := make(map[string]targetHealth, len(statuses))
+	for target, status := range statuses {
+		health[target] = healthOf(status)
+	}
+
+	rollout, requeueAfter := plan(strategy, distribution.Status.Rollout, targets, workload.GetGeneration(), health, c.now())
+	if equality.Semantic.DeepEqual(rollout, distribution.Status.Rollout) {
+		return requeueAfter, nil
+	}
+
+	d := distribution.DeepCopy()
+	d.Status.Rollout = rollout
+	logger.V(2).Info("updating rollout status", "phase", rollout.Phase, "wave", rollout.Wave, "syncedTargets", rollout.SyncedTargets)
+	if err := c.updateStatus(ctx, clusterName, d); err != nil {
+		if errors.IsNotFound(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return requeueAfter, nil
+}
+
+// targetHealth is the health of a workload on a SyncTarget that reported
+// its status.
+type targetHealth int
+
+const (
+	// targetProgressing means the workload is not healthy yet.
+	targetProgressing targetHealth = iota
+	targetHealthy
+	targetFailed
+)
+
+// healthyPhases are the status.phase values of healthy objects, e.g. of
+// pods, namespaces and volume claims.
+var healthyPhases = sets.New("Running", "Succeeded", "Active", "Bound")
+
+// healthOf returns the health of the workload with the status reported by a
+// SyncTarget. Workloads are healthy when their Ready or Available condition
+// is True, or else when they are in a healthy phase, or else as soon as they
+// report a status.
+func healthOf(status map[string]interface{}) targetHealth {
+	conditions, _, _ := unstructured.NestedSlice(status, "conditions")
+	var found bool
+	for _, condition := range conditions {
+		c, ok := condition.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch c["type"] {
+		case "Ready", "Available":
+			if c["status"] == string(metav1.ConditionTrue) {
+				return targetHealthy
+			}
+			found = true
+		case "Progressing":
+			// Deployments that do not progress fail.
+			if c["status"] == string(metav1.ConditionFalse) && c["reason"] == "ProgressDeadlineExceeded" {
+				return targetFailed
+			}
+		}
+	}
+	if found {
+		return targetProgressing
+	}
+
+	phase, found, _ := unstructured.NestedString(status, "phase")
+	switch {
+	case !found:
+		return targetHealthy
+	case phase == "Failed":
+		return targetFailed
+	case healthyPhases.Has(phase):
+		return targetHealthy
+	}
+	return targetProgressing
+}
+
+// plan returns the rollout of a workload placed on targets from the current
+// one, and when to plan again if nothing changes. health holds the health of
+// the targets that reported a status.
+func plan(strategy *workloadv1alpha1.RolloutStrategy, current *workloadv1alpha1.RolloutStatus, targets []string, generation int64, health map[string]targetHealth, now time.Time) (*workloadv1alpha1.RolloutStatus, time.Duration) {
+	r := current.DeepCopy()
+	if r == nil {
+		// Targets reporting a status run the workload already, e.g. because
+		// the strategy was set after the workload was placed.
+		r = &workloadv1alpha1.RolloutStatus{Phase: workloadv1alpha1.RolloutProgressing, ObservedWorkloadGeneration: generation, ObservedTargets: targets}
+		for _, target := range targets {
+			if _, reported := health[target]; reported {
+				r.SyncedTargets = append(r.SyncedTargets, target)
+			}
+		}
+	}
+
+	// Targets the workload moved off are no longer synced, except in
+	// BlueGreen rollouts until the new targets are healthy.
+	placed := sets.New(targets...)
+	var synced []string
+	for _, target := range r.SyncedTargets {
+		if placed.Has(target) {
+			synced = append(synced, target)
+		} else if strategy.Type == workloadv1alpha1.RolloutBlueGreen && !slices.Contains(r.RetiringTargets, target) {
+			r.RetiringTargets = append(r.RetiringTargets, target)
+		}
+	}
+	r.RetiringTargets = slices.DeleteFunc(r.RetiringTargets, func(target string) bool {
+		if placed.Has(target) && !slices.Contains(synced, target) {
+			// The workload moved back while it still runs there.
+			synced = append(synced, target)
+		}
+		return placed.Has(target)
+	})
+	r.SyncedTargets = synced
+	r.WaveTargets = slices.DeleteFunc(r.WaveTargets, func(target string) bool { return !placed.Has(target) })
+
+	changed := generation != r.ObservedWorkloadGeneration || !sets.New(r.ObservedTargets...).Equal(placed)
+	switch {
+	case r.Phase == workloadv1alpha1.RolloutRolledBack && !changed:
+		return r, 0
+	case r.Phase == workloadv1alpha1.RolloutRolledBack, r.Phase == workloadv1alpha1.RolloutComplete && len(unsynced(r, targets)) > 0:
+		r.Phase = workloadv1alpha1.RolloutProgressing
+		r.Wave = 0
+		r.WaveTargets = nil
+		r.WaveStartTime = nil
+		r.HealthySince = nil
+		r.FailedTargets = nil
+	}
+	r.ObservedWorkloadGeneration = generation
+	r.ObservedTargets = targets
+
+	analysisPeriod, progressDeadline := defaultAnalysisPeriod, defaultProgressDeadline
+	if strategy.AnalysisPeriod != nil {
+		analysisPeriod = strategy.AnalysisPeriod.Duration
+	}
+	if strategy.ProgressDeadline != nil {
+		progressDeadline = strategy.ProgressDeadline.Duration
+	}
+
+	for {
+		if len(r.WaveTargets) == 0 {
+			pending := unsynced(r, targets)
+			if len(pending) == 0 {
+				r.Phase = workloadv1alpha1.RolloutComplete
+				r.RetiringTargets = nil
+				r.WaveStartTime = nil
+				r.HealthySince = nil
+				r.Message = fmt.Sprintf("synced to all %d targets", len(r.SyncedTargets))
+				return r, 0
+			}
+			r.WaveTargets = pending[:waveSize(strategy, r.Wave, len(pending))]
+			r.SyncedTargets = append(r.SyncedTargets, r.WaveTargets...)
+			r.Wave++
+			start := metav1.NewTime(now)
+			r.WaveStartTime = &start
+			r.HealthySince = nil
+		}
+
+		deadline := r.WaveStartTime.Add(progressDeadline)
+		var failed, waiting []string
+		for _, target := range r.WaveTargets {
+			h, reported := health[target]
+			switch {
+			case reported && h == targetHealthy:
+			case reported && h == targetFailed, !now.Before(deadline):
+				failed = append(failed, target)
+			default:
+				waiting = append(waiting, target)
+			}
+		}
+
+		if len(failed) > int(strategy.MaxFailedTargets) {
+			r.SyncedTargets = slices.DeleteFunc(r.SyncedTargets, func(target string) bool { return slices.Contains(r.WaveTargets, target) })
+			r.Phase = workloadv1alpha1.RolloutRolledBack
+			r.FailedTargets = failed
+			r.WaveTargets = nil
+			r.WaveStartTime = nil
+			r.HealthySince = nil
+			r.Message = fmt.Sprintf("wave %d failed on %s", r.Wave, strings.Join(failed, ", "))
+			return r, 0
+		}
+		if len(waiting) > 0 {
+			r.HealthySince = nil
+			r.Message = fmt.Sprintf("wave %d: waiting for %s to become healthy", r.Wave, strings.Join(waiting, ", "))
+			return r, deadline.Sub(now)
+		}
+
+		if r.HealthySince == nil {
+			since := metav1.NewTime(now)
+			r.HealthySince = &since
+		}
+		if end := r.HealthySince.Add(analysisPeriod); now.Before(end) {
+			r.Message = fmt.Sprintf("wave %d: healthy, analyzing until %s", r.Wave, end.UTC().Format(time.RFC3339))
+			return r, end.Sub(now)
+		}
+		r.WaveTargets = nil
+	}
+}
+
+// unsynced returns the targets the workload is not synced to yet, in order.
+func unsynced(r *workloadv1alpha1.RolloutStatus, targets []string) []string {
+	var pending []string
+	for _, target := range targets {
+		if !slices.Contains(r.SyncedTargets, target) {
+			pending = append(pending, target)
+		}
+	}
+	return pending
+}
+
+// waveSize returns the number of the pending targets the wave after wave
+// syncs to.
+func waveSize(strategy *workloadv1alpha1.RolloutStrategy, wave int32, pending int) int {
+	size := pending
+	switch strategy.Type {
+	case workloadv1alpha1.RolloutCanary:
+		if wave == 0 {
+			size = 1
+			if strategy.CanaryTargets != nil {
+				size = int(*strategy.CanaryTargets)
+			}
+		}
+	case workloadv1alpha1.RolloutProgressive:
+		size = 1
+		if strategy.WaveSize != nil {
+			size = int(*strategy.WaveSize)
+		}
+	}
+	return max(1, min(size, pending))
+}
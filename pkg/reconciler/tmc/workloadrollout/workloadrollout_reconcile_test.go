@@ -0,0 +1,174 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadrollout
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var start = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newStrategy(strategyType workloadv1alpha1.RolloutStrategyType) *workloadv1alpha1.RolloutStrategy {
+	return &workloadv1alpha1.RolloutStrategy{
+		Type:             strategyType,
+		AnalysisPeriod:   &metav1.Duration{Duration: time.Minute},
+		ProgressDeadline: &metav1.Duration{Duration: 10 * time.Minute},
+	}
+}
+
+func healthyTargets(targets ...string) map[string]targetHealth {
+	health := map[string]targetHealth{}
+	for _, target := range targets {
+		health[target] = targetHealthy
+	}
+	return health
+}
+
+func TestPlanCanary(t *testing.T) {
+	strategy := newStrategy(workloadv1alpha1.RolloutCanary)
+	targets := []string{"a", "b", "c"}
+
+	r, requeueAfter := plan(strategy, nil, targets, 1, nil, start)
+	require.Equal(t, workloadv1alpha1.RolloutProgressing, r.Phase)
+	require.Equal(t, int32(1), r.Wave)
+	require.Equal(t, []string{"a"}, r.SyncedTargets, "the first wave syncs to one canary target")
+	require.Equal(t, 10*time.Minute, requeueAfter, "waits until the progress deadline")
+
+	now := start.Add(2 * time.Minute)
+	r, requeueAfter = plan(strategy, r, targets, 1, healthyTargets("a"), now)
+	require.Equal(t, []string{"a"}, r.SyncedTargets)
+	require.Equal(t, now, r.HealthySince.Time)
+	require.Equal(t, time.Minute, requeueAfter, "analyzes the healthy canary")
+
+	now = now.Add(time.Minute)
+	r, _ = plan(strategy, r, targets, 1, healthyTargets("a"), now)
+	require.Equal(t, int32(2), r.Wave)
+	require.Equal(t, []string{"a", "b", "c"}, r.SyncedTargets, "the second wave syncs to the rest")
+	require.Equal(t, []string{"b", "c"}, r.WaveTargets)
+
+	now = now.Add(2 * time.Minute)
+	r, _ = plan(strategy, r, targets, 1, healthyTargets("a", "b", "c"), now)
+	r, requeueAfter = plan(strategy, r, targets, 1, healthyTargets("a", "b", "c"), now.Add(time.Minute))
+	require.Equal(t, workloadv1alpha1.RolloutComplete, r.Phase)
+	require.Equal(t, "synced to all 3 targets", r.Message)
+	require.Zero(t, requeueAfter)
+}
+
+func TestPlanProgressive(t *testing.T) {
+	strategy := newStrategy(workloadv1alpha1.RolloutProgressive)
+	strategy.WaveSize = ptr.To[int32](2)
+	strategy.AnalysisPeriod = &metav1.Duration{}
+	targets := []string{"a", "b", "c", "d", "e"}
+
+	r, _ := plan(strategy, nil, targets, 1, nil, start)
+	require.Equal(t, []string{"a", "b"}, r.SyncedTargets)
+
+	r, _ = plan(strategy, r, targets, 1, healthyTargets("a", "b"), start.Add(time.Minute))
+	require.Equal(t, int32(2), r.Wave)
+	require.Equal(t, []string{"c", "d"}, r.WaveTargets)
+
+	r, _ = plan(strategy, r, targets, 1, healthyTargets("a", "b", "c", "d"), start.Add(2*time.Minute))
+	require.Equal(t, int32(3), r.Wave)
+	require.Equal(t, []string{"e"}, r.WaveTargets)
+}
+
+func TestPlanRollback(t *testing.T) {
+	strategy := newStrategy(workloadv1alpha1.RolloutProgressive)
+	strategy.WaveSize = ptr.To[int32](2)
+	targets := []string{"a", "b", "c"}
+
+	r, _ := plan(strategy, nil, targets, 1, nil, start)
+	r, _ = plan(strategy, r, targets, 1, map[string]targetHealth{"a": targetHealthy, "b": targetFailed}, start.Add(time.Minute))
+	require.Equal(t, workloadv1alpha1.RolloutRolledBack, r.Phase)
+	require.Empty(t, r.SyncedTargets, "the failed wave is no longer synced")
+	require.Equal(t, []string{"b"}, r.FailedTargets)
+	require.Equal(t, "wave 1 failed on b", r.Message)
+
+	rolledBack := r
+	r, _ = plan(strategy, rolledBack, targets, 1, nil, start.Add(time.Hour))
+	require.Equal(t, workloadv1alpha1.RolloutRolledBack, r.Phase, "stays rolled back while nothing changes")
+	require.Empty(t, r.SyncedTargets)
+
+	r, _ = plan(strategy, rolledBack, targets, 2, nil, start.Add(time.Hour))
+	require.Equal(t, workloadv1alpha1.RolloutProgressing, r.Phase, "starts again once the workload changes")
+	require.Equal(t, int32(1), r.Wave)
+	require.Equal(t, []string{"a", "b"}, r.SyncedTargets)
+}
+
+func TestPlanProgressDeadline(t *testing.T) {
+	strategy := newStrategy(workloadv1alpha1.RolloutCanary)
+	strategy.MaxFailedTargets = 1
+	targets := []string{"a", "b"}
+
+	r, _ := plan(strategy, nil, targets, 1, nil, start)
+	r, _ = plan(strategy, r, targets, 1, map[string]targetHealth{"a": targetProgressing}, start.Add(10*time.Minute))
+	require.Equal(t, workloadv1alpha1.RolloutProgressing, r.Phase, "one failed target is tolerated")
+	require.Equal(t, start.Add(10*time.Minute), r.HealthySince.Time)
+}
+
+func TestPlanBlueGreen(t *testing.T) {
+	strategy := newStrategy(workloadv1alpha1.RolloutBlueGreen)
+
+	r, _ := plan(strategy, nil, []string{"blue"}, 1, healthyTargets("blue"), start)
+	require.Equal(t, workloadv1alpha1.RolloutComplete, r.Phase, "targets reporting a status are synced already")
+
+	targets := []string{"green-1", "green-2"}
+	r, _ = plan(strategy, r, targets, 1, nil, start.Add(time.Minute))
+	require.Equal(t, workloadv1alpha1.RolloutProgressing, r.Phase)
+	require.Equal(t, []string{"green-1", "green-2"}, r.SyncedTargets, "all new targets are synced at once")
+	require.Equal(t, []string{"blue"}, r.RetiringTargets, "the old target is kept")
+
+	r, _ = plan(strategy, r, targets, 1, healthyTargets("green-1", "green-2"), start.Add(2*time.Minute))
+	require.Equal(t, []string{"blue"}, r.RetiringTargets)
+	r, _ = plan(strategy, r, targets, 1, healthyTargets("green-1", "green-2"), start.Add(3*time.Minute))
+	require.Equal(t, workloadv1alpha1.RolloutComplete, r.Phase)
+	require.Empty(t, r.RetiringTargets, "the old target is released once the new ones are healthy")
+}
+
+func TestHealthOf(t *testing.T) {
+	for name, tc := range map[string]struct {
+		status map[string]interface{}
+		want   targetHealth
+	}{
+		"no conditions or phase": {status: map[string]interface{}{"replicas": int64(2)}, want: targetHealthy},
+		"available": {status: map[string]interface{}{"conditions": []interface{}{
+			map[string]interface{}{"type": "Available", "status": "True"},
+		}}, want: targetHealthy},
+		"not ready": {status: map[string]interface{}{"conditions": []interface{}{
+			map[string]interface{}{"type": "Ready", "status": "False"},
+		}}, want: targetProgressing},
+		"progress deadline exceeded": {status: map[string]interface{}{"conditions": []interface{}{
+			map[string]interface{}{"type": "Available", "status": "False"},
+			map[string]interface{}{"type": "Progressing", "status": "False", "reason": "ProgressDeadlineExceeded"},
+		}}, want: targetFailed},
+		"running":       {status: map[string]interface{}{"phase": "Running"}, want: targetHealthy},
+		"pending phase": {status: map[string]interface{}{"phase": "Pending"}, want: targetProgressing},
+		"failed phase":  {status: map[string]interface{}{"phase": "Failed"}, want: targetFailed},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, healthOf(tc.status))
+		})
+	}
+}
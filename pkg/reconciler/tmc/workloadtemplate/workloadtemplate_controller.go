@@ -0,0 +1,272 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadtemplate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	kcpcache "github.com/kcp-dev/apimachinery/v2/pkg/cache"
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/reconciler/dynamicrestmapper"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/tmcinformers"
+	"github.com/kcp-dev/kcp/pkg/tmc/workspacequeue"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+const (
+	ControllerName = "kcp-tmc-workloadtemplate"
+)
+
+var (
+	// WorkloadTemplatesGVR is the resource the generator reconciles.
+	WorkloadTemplatesGVR = workloadv1alpha1.SchemeGroupVersion.WithResource("workloadtemplates")
+)
+
+// NewController returns a controller that stamps out one variant of the
+// object in a WorkloadTemplate for every location of the selected
+// SyncTargets, and deletes variants of locations no longer selected.
+func NewController(
+	templateClusterInformer *tmcinformers.Informer,
+	syncTargetClusterInformer *tmcinformers.Informer,
+	dynamicClusterClient kcpdynamic.ClusterInterface,
+	dynRESTMapper *dynamicrestmapper.DynamicRESTMapper,
+) (*controller, error) {
+	c := &controller{
+		queue: workspacequeue.NewRateLimitingQueue(ControllerName),
+		getTemplate: func(clusterName logicalcluster.Name, namespace, name string) (*workloadv1alpha1.WorkloadTemplate, error) {
+			obj, err := templateClusterInformer.Lister(clusterName).ByNamespace(namespace).Get(name)
+			if err != nil {
+				return nil, err
+			}
+			template := &workloadv1alpha1.WorkloadTemplate{}
+			return template, fromUnstructured(obj, template)
+		},
+		listSyncTargets: func(clusterName logicalcluster.Name) ([]*tmcv1alpha1.SyncTarget, error) {
+			objs, err := syncTargetClusterInformer.Lister(clusterName).List(labels.Everything())
+			if err != nil {
+				return nil, err
+			}
+			syncTargets := make([]*tmcv1alpha1.SyncTarget, 0, len(objs))
+			for _, obj := range objs {
+				syncTarget := &tmcv1alpha1.SyncTarget{}
+				if err := fromUnstructured(obj, syncTarget); err != nil {
+					return nil, err
+				}
+				syncTargets = append(syncTargets, syncTarget)
+			}
+			return syncTargets, nil
+		},
+		restMapping: func(clusterName logicalcluster.Name, gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+			return dynRESTMapper.ForCluster(clusterName).RESTMapping(gvk.GroupKind(), gvk.Version)
+		},
+		getVariant: func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+			return dynamicClusterClient.Cluster(clusterName.Path()).Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		},
+		listVariants: func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, namespace, templateName string) ([]unstructured.Unstructured, error) {
+			list, err := dynamicClusterClient.Cluster(clusterName.Path()).Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{
+				LabelSelector: labels.SelectorFromSet(labels.Set{workloadv1alpha1.LabelTemplate: templateName}).String(),
+			})
+			if err != nil {
+				return nil, err
+			}
+			return list.Items, nil
+		},
+		createVariant: func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+			_, err := dynamicClusterClient.Cluster(clusterName.Path()).Resource(gvr).Namespace(obj.GetNamespace()).Create(ctx, obj, metav1.CreateOptions{})
+			return err
+		},
+		updateVariant: func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+			_, err := dynamicClusterClient.Cluster(clusterName.Path()).Resource(gvr).Namespace(obj.GetNamespace()).Update(ctx, obj, metav1.UpdateOptions{})
+			return err
+		},
+		deleteVariant: func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, namespace, name string) error {
+			return dynamicClusterClient.Cluster(clusterName.Path()).Resource(gvr).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+		},
+		updateTemplateStatus: func(ctx context.Context, clusterName logicalcluster.Name, template *workloadv1alpha1.WorkloadTemplate) error {
+			u, err := toUnstructured(template)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(WorkloadTemplatesGVR).Namespace(template.Namespace).UpdateStatus(ctx, u, metav1.UpdateOptions{})
+			return err
+		},
+	}
+
+	templateClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueue(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+	})
+	syncTargetClusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueueTemplatesInCluster(templateClusterInformer, obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueueTemplatesInCluster(templateClusterInformer, obj) },
+		DeleteFunc: func(obj interface{}) { c.enqueueTemplatesInCluster(templateClusterInformer, obj) },
+	})
+
+	return c, nil
+}
+
+// controller generates per-location variants of WorkloadTemplates.
+type controller struct {
+	queue workqueue.TypedRateLimitingInterface[string]
+
+	getTemplate          func(clusterName logicalcluster.Name, namespace, name string) (*workloadv1alpha1.WorkloadTemplate, error)
+	listSyncTargets      func(clusterName logicalcluster.Name) ([]*tmcv1alpha1.SyncTarget, error)
+	restMapping          func(clusterName logicalcluster.Name, gvk schema.GroupVersionKind) (*meta.RESTMapping, error)
+	getVariant           func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error)
+	listVariants         func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, namespace, templateName string) ([]unstructured.Unstructured, error)
+	createVariant        func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error
+	updateVariant        func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error
+	deleteVariant        func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, namespace, name string) error
+	updateTemplateStatus func(ctx context.Context, clusterName logicalcluster.Name, template *workloadv1alpha1.WorkloadTemplate) error
+}
+
+func (c *controller) enqueue(obj interface{}) {
+	key, err := kcpcache.DeletionHandlingMetaClusterNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+
+	logger := logging.WithQueueKey(logging.WithReconciler(klog.Background(), ControllerName), key)
+	logger.V(4).Info("queueing WorkloadTemplate")
+	c.queue.Add(key)
+}
+
+// enqueueTemplatesInCluster enqueues all templates in the logical cluster of
+// a SyncTarget, as the set of locations may have changed.
+func (c *controller) enqueueTemplatesInCluster(templateClusterInformer *tmcinformers.Informer, obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		utilruntime.HandleError(fmt.Errorf("expected *unstructured.Unstructured, got %T", obj))
+		return
+	}
+
+	templates, err := templateClusterInformer.Lister(logicalcluster.From(u)).List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, template := range templates {
+		c.enqueue(template)
+	}
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	logger := logging.WithReconciler(klog.FromContext(ctx), ControllerName)
+	ctx = klog.NewContext(ctx, logger)
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	for range numThreads {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+
+	logger := logging.WithQueueKey(klog.FromContext(ctx), key)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(4).Info("processing key")
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		utilruntime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", ControllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) error {
+	clusterName, namespace, name, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return nil
+	}
+
+	template, err := c.getTemplate(clusterName, namespace, name)
+	if errors.IsNotFound(err) {
+		// Variants are garbage collected through their owner reference.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !template.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	return c.reconcile(ctx, clusterName, template)
+}
+
+func fromUnstructured(obj runtime.Object, into interface{}) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("expected *unstructured.Unstructured, got %T", obj)
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, into)
+}
+
+func toUnstructured(obj interface{}) (*unstructured.Unstructured, error) {
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: raw}, nil
+}
@@ -0,0 +1,311 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadtemplate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/naming"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var (
+	invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+	unresolvedValue  = regexp.MustCompile(`\$\{values\.[^}]*\}`)
+)
+
+func (c *controller) reconcile(ctx context.Context, clusterName logicalcluster.Name, template *workloadv1alpha1.WorkloadTemplate) error {
+	logger := klog.FromContext(ctx)
+
+	t := template.DeepCopy()
+	t.Status.ObservedGeneration = t.Generation
+	generateErr := c.generate(ctx, clusterName, t)
+
+	if equality.Semantic.DeepEqual(template.Status, t.Status) {
+		return generateErr
+	}
+	logger.V(2).Info("updating WorkloadTemplate status", "variants", len(t.Status.Variants))
+	if err := c.updateTemplateStatus(ctx, clusterName, t); err != nil {
+		return utilerrors.NewAggregate([]error{generateErr, err})
+	}
+	return generateErr
+}
+
+// generate writes the variants of t and records them in its status. Errors
+// that retrying cannot fix are only reported in the status.
+func (c *controller) generate(ctx context.Context, clusterName logicalcluster.Name, t *workloadv1alpha1.WorkloadTemplate) error {
+	logger := klog.FromContext(ctx)
+
+	syncTargets, err := c.listSyncTargets(clusterName)
+	if err != nil {
+		return err
+	}
+	locations, err := Locations(t, syncTargets)
+	if err != nil {
+		conditions.MarkFalse(t, workloadv1alpha1.TemplateGenerated, workloadv1alpha1.InvalidTemplateReason, conditionsv1alpha1.ConditionSeverityError, "%v", err)
+		return nil
+	}
+
+	variants := make([]*unstructured.Unstructured, 0, len(locations))
+	for _, location := range locations {
+		variant, err := RenderVariant(t, location)
+		if err != nil {
+			conditions.MarkFalse(t, workloadv1alpha1.TemplateGenerated, workloadv1alpha1.InvalidTemplateReason, conditionsv1alpha1.ConditionSeverityError, "%v", err)
+			return nil
+		}
+		variants = append(variants, variant)
+	}
+
+	gvk, err := templateGVK(t)
+	if err != nil {
+		conditions.MarkFalse(t, workloadv1alpha1.TemplateGenerated, workloadv1alpha1.InvalidTemplateReason, conditionsv1alpha1.ConditionSeverityError, "%v", err)
+		return nil
+	}
+	mapping, err := c.restMapping(clusterName, gvk)
+	if err != nil {
+		// The resource may not be known yet, e.g. while an APIBinding is set up.
+		conditions.MarkFalse(t, workloadv1alpha1.TemplateGenerated, workloadv1alpha1.GenerationFailedReason, conditionsv1alpha1.ConditionSeverityWarning,
+			"Unknown resource for %s: %v", gvk, err)
+		return err
+	}
+	gvr := mapping.Resource
+
+	var errs []error
+	desired := sets.New[string]()
+	t.Status.Variants = nil
+	for i, variant := range variants {
+		desired.Insert(variant.GetName())
+		t.Status.Variants = append(t.Status.Variants, workloadv1alpha1.TemplateVariant{Location: locations[i], Name: variant.GetName()})
+
+		existing, err := c.getVariant(ctx, clusterName, gvr, variant.GetNamespace(), variant.GetName())
+		if errors.IsNotFound(err) {
+			logger.V(2).Info("creating variant", "location", locations[i], "name", variant.GetName())
+			if err := c.createVariant(ctx, clusterName, gvr, variant); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if existing.GetLabels()[workloadv1alpha1.LabelTemplate] != t.Name {
+			errs = append(errs, fmt.Errorf("%s %s/%s exists and is not generated by this template", gvk.Kind, variant.GetNamespace(), variant.GetName()))
+			continue
+		}
+
+		updated := mergeVariant(existing, variant)
+		if equality.Semantic.DeepEqual(existing, updated) {
+			continue
+		}
+		logger.V(2).Info("updating variant", "location", locations[i], "name", variant.GetName())
+		if err := c.updateVariant(ctx, clusterName, gvr, updated); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	generated, err := c.listVariants(ctx, clusterName, gvr, t.Namespace, t.Name)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, obj := range generated {
+		if desired.Has(obj.GetName()) {
+			continue
+		}
+		logger.V(2).Info("deleting variant of unselected location", "location", obj.GetLabels()[workloadv1alpha1.LabelLocation], "name", obj.GetName())
+		if err := c.deleteVariant(ctx, clusterName, gvr, obj.GetNamespace(), obj.GetName()); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := utilerrors.NewAggregate(errs); err != nil {
+		conditions.MarkFalse(t, workloadv1alpha1.TemplateGenerated, workloadv1alpha1.GenerationFailedReason, conditionsv1alpha1.ConditionSeverityError, "%v", err)
+		return err
+	}
+	conditions.MarkTrue(t, workloadv1alpha1.TemplateGenerated)
+	return nil
+}
+
+// Locations returns the sorted, distinct locations of the SyncTargets
+// selected by the template.
+func Locations(t *workloadv1alpha1.WorkloadTemplate, syncTargets []*tmcv1alpha1.SyncTarget) ([]string, error) {
+	selector := labels.Everything()
+	if t.Spec.LocationSelector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(t.Spec.LocationSelector); err != nil {
+			return nil, fmt.Errorf("invalid location selector: %w", err)
+		}
+	}
+
+	locations := sets.New[string]()
+	for _, syncTarget := range syncTargets {
+		if syncTarget.Spec.Location != "" && selector.Matches(labels.Set(syncTarget.Labels)) {
+			locations.Insert(syncTarget.Spec.Location)
+		}
+	}
+	return sets.List(locations), nil
+}
+
+// RenderVariant returns the object of the template for the given location.
+func RenderVariant(t *workloadv1alpha1.WorkloadTemplate, location string) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	if err := json.Unmarshal(t.Spec.Template.Raw, &obj.Object); err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	if obj.GetAPIVersion() == "" || obj.GetKind() == "" || obj.GetName() == "" {
+		return nil, fmt.Errorf("invalid template: apiVersion, kind and metadata.name are required")
+	}
+
+	var config workloadv1alpha1.LocationConfig
+	for _, c := range t.Spec.Locations {
+		if c.Location == location {
+			config = c
+			break
+		}
+	}
+
+	oldnew := []string{"${location}", location}
+	keys := make([]string, 0, len(config.Values))
+	for k := range config.Values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		oldnew = append(oldnew, "${values."+k+"}", config.Values[k])
+	}
+	replacer := strings.NewReplacer(oldnew...)
+
+	var unresolved []string
+	obj.Object = substitute(obj.Object, func(s string) string {
+		s = replacer.Replace(s)
+		unresolved = append(unresolved, unresolvedValue.FindAllString(s, -1)...)
+		return s
+	}).(map[string]interface{})
+	if len(unresolved) > 0 {
+		return nil, fmt.Errorf("invalid template: location %q has no value for %s", location, strings.Join(sets.List(sets.New(unresolved...)), ", "))
+	}
+
+	base := obj.GetName()
+	labels := merge(obj.GetLabels(), config.Labels, map[string]string{
+		workloadv1alpha1.LabelTemplate: t.Name,
+		workloadv1alpha1.LabelLocation: sanitize(location),
+	})
+	annotations := merge(obj.GetAnnotations(), config.Annotations)
+	delete(obj.Object, "metadata")
+	delete(obj.Object, "status")
+	obj.SetName(VariantName(base, location))
+	obj.SetNamespace(t.Namespace)
+	obj.SetLabels(labels)
+	if len(annotations) > 0 {
+		obj.SetAnnotations(annotations)
+	}
+	obj.SetOwnerReferences([]metav1.OwnerReference{{
+		APIVersion: workloadv1alpha1.SchemeGroupVersion.String(),
+		Kind:       "WorkloadTemplate",
+		Name:       t.Name,
+		UID:        t.UID,
+		Controller: ptr.To(true),
+	}})
+	return obj, nil
+}
+
+// VariantName returns the name of the variant of base for location.
+func VariantName(base, location string) string {
+	return naming.Bounded(base+"-"+sanitize(location), validation.DNS1123SubdomainMaxLength)
+}
+
+func sanitize(location string) string {
+	return strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(location), "-"), "-")
+}
+
+func templateGVK(t *workloadv1alpha1.WorkloadTemplate) (schema.GroupVersionKind, error) {
+	var typeMeta metav1.TypeMeta
+	if err := json.Unmarshal(t.Spec.Template.Raw, &typeMeta); err != nil {
+		return schema.GroupVersionKind{}, fmt.Errorf("invalid template: %w", err)
+	}
+	return typeMeta.GroupVersionKind(), nil
+}
+
+// mergeVariant returns existing with the generated content of desired,
+// keeping fields set by others such as status and server-side metadata.
+func mergeVariant(existing, desired *unstructured.Unstructured) *unstructured.Unstructured {
+	updated := existing.DeepCopy()
+	for k, v := range desired.Object {
+		if k == "metadata" || k == "status" {
+			continue
+		}
+		updated.Object[k] = v
+	}
+	updated.SetLabels(merge(existing.GetLabels(), desired.GetLabels()))
+	if annotations := merge(existing.GetAnnotations(), desired.GetAnnotations()); len(annotations) > 0 {
+		updated.SetAnnotations(annotations)
+	}
+	updated.SetOwnerReferences(desired.GetOwnerReferences())
+	return updated
+}
+
+// substitute applies fn to all strings in v.
+func substitute(v interface{}, fn func(string) string) interface{} {
+	switch v := v.(type) {
+	case string:
+		return fn(v)
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = substitute(e, fn)
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = substitute(e, fn)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func merge(maps ...map[string]string) map[string]string {
+	out := map[string]string{}
+	for _, m := range maps {
+		for k, v := range m {
+			out[k] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadtemplate
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var deploymentsGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+func newTemplate() *workloadv1alpha1.WorkloadTemplate {
+	return &workloadv1alpha1.WorkloadTemplate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", UID: "uid", Generation: 2},
+		Spec: workloadv1alpha1.WorkloadTemplateSpec{
+			Template: runtime.RawExtension{Raw: []byte(`{
+				"apiVersion": "apps/v1",
+				"kind": "Deployment",
+				"metadata": {"name": "web", "labels": {"app": "web"}, "resourceVersion": "1"},
+				"spec": {"template": {"spec": {"containers": [{"name": "web", "env": [
+					{"name": "REGION", "value": "${location}"},
+					{"name": "DB", "value": "${values.db}"}
+				]}]}}}
+			}`)},
+			LocationSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "prod"}},
+			Locations: []workloadv1alpha1.LocationConfig{
+				{Location: "EU West", Labels: map[string]string{"zone": "eu"}, Values: map[string]string{"db": "db.eu"}},
+				{Location: "us", Values: map[string]string{"db": "db.us"}},
+			},
+		},
+	}
+}
+
+func syncTarget(name, location, tier string) *tmcv1alpha1.SyncTarget {
+	return &tmcv1alpha1.SyncTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"tier": tier}},
+		Spec:       tmcv1alpha1.SyncTargetSpec{Location: location},
+	}
+}
+
+func TestRenderVariant(t *testing.T) {
+	variant, err := RenderVariant(newTemplate(), "EU West")
+	require.NoError(t, err)
+
+	require.Equal(t, "web-eu-west", variant.GetName())
+	require.Equal(t, "default", variant.GetNamespace())
+	require.Empty(t, variant.GetResourceVersion())
+	require.Equal(t, map[string]string{
+		"app":                          "web",
+		"zone":                         "eu",
+		workloadv1alpha1.LabelTemplate: "web",
+		workloadv1alpha1.LabelLocation: "eu-west",
+	}, variant.GetLabels())
+	require.Equal(t, "uid", string(variant.GetOwnerReferences()[0].UID))
+
+	containers, _, _ := unstructured.NestedSlice(variant.Object, "spec", "template", "spec", "containers")
+	env := containers[0].(map[string]interface{})["env"].([]interface{})
+	require.Equal(t, "EU West", env[0].(map[string]interface{})["value"])
+	require.Equal(t, "db.eu", env[1].(map[string]interface{})["value"])
+
+	_, err = RenderVariant(newTemplate(), "asia")
+	require.ErrorContains(t, err, `location "asia" has no value for ${values.db}`)
+}
+
+func TestVariantName(t *testing.T) {
+	require.Equal(t, "web-eu-west", VariantName("web", "EU West"))
+
+	long := strings.Repeat("region-", 40)
+	a, b := VariantName("web", long+"a"), VariantName("web", long+"b")
+	require.NotEqual(t, a, b)
+	require.Empty(t, validation.IsDNS1123Subdomain(a))
+	require.Equal(t, a, VariantName("web", long+"a"))
+}
+
+func TestReconcile(t *testing.T) {
+	variants := map[string]*unstructured.Unstructured{}
+	stale, err := RenderVariant(newTemplate(), "us")
+	require.NoError(t, err)
+	stale.SetName("web-gone")
+	variants["web-gone"] = stale
+	unrelated := &unstructured.Unstructured{}
+	unrelated.SetName("web-us")
+	variants["web-us"] = unrelated
+
+	var status *workloadv1alpha1.WorkloadTemplate
+	c := &controller{
+		listSyncTargets: func(clusterName logicalcluster.Name) ([]*tmcv1alpha1.SyncTarget, error) {
+			return []*tmcv1alpha1.SyncTarget{
+				syncTarget("eu-1", "EU West", "prod"),
+				syncTarget("eu-2", "EU West", "prod"),
+				syncTarget("us-1", "us", "prod"),
+				syncTarget("dev-1", "dev", "dev"),
+			}, nil
+		},
+		restMapping: func(clusterName logicalcluster.Name, gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+			return &meta.RESTMapping{Resource: deploymentsGVR}, nil
+		},
+		getVariant: func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+			if v, ok := variants[name]; ok {
+				return v, nil
+			}
+			return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+		},
+		listVariants: func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, namespace, templateName string) ([]unstructured.Unstructured, error) {
+			var out []unstructured.Unstructured
+			for _, v := range variants {
+				if v.GetLabels()[workloadv1alpha1.LabelTemplate] == templateName {
+					out = append(out, *v)
+				}
+			}
+			return out, nil
+		},
+		createVariant: func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+			variants[obj.GetName()] = obj
+			return nil
+		},
+		updateVariant: func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+			variants[obj.GetName()] = obj
+			return nil
+		},
+		deleteVariant: func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, namespace, name string) error {
+			delete(variants, name)
+			return nil
+		},
+		updateTemplateStatus: func(ctx context.Context, clusterName logicalcluster.Name, template *workloadv1alpha1.WorkloadTemplate) error {
+			status = template
+			return nil
+		},
+	}
+
+	err = c.reconcile(context.Background(), "root:org", newTemplate())
+	require.ErrorContains(t, err, "web-us exists and is not generated by this template")
+
+	var names []string
+	for name := range variants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	require.Equal(t, []string{"web-eu-west", "web-us"}, names, "stale variant is deleted, foreign object is left alone")
+	require.Equal(t, []workloadv1alpha1.TemplateVariant{{Location: "EU West", Name: "web-eu-west"}, {Location: "us", Name: "web-us"}}, status.Status.Variants)
+	require.Equal(t, int64(2), status.Status.ObservedGeneration)
+	require.Equal(t, workloadv1alpha1.GenerationFailedReason, conditions.GetReason(status, workloadv1alpha1.TemplateGenerated))
+
+	delete(variants, "web-us")
+	require.NoError(t, c.reconcile(context.Background(), "root:org", status))
+	require.True(t, conditions.IsTrue(status, workloadv1alpha1.TemplateGenerated))
+	require.Contains(t, variants, "web-us")
+}
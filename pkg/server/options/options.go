@@ -36,6 +36,7 @@ import (
 	kcpadmission "github.com/kcp-dev/kcp/pkg/admission"
 	kcpfeatures "github.com/kcp-dev/kcp/pkg/features"
 	"github.com/kcp-dev/kcp/pkg/server/options/batteries"
+	"github.com/kcp-dev/kcp/pkg/tmc/retry"
 )
 
 type Options struct {
@@ -69,6 +70,42 @@ type ExtraOptions struct {
 	ConversionCELTransformationTimeout    time.Duration
 	RootIdentitiesFile                    string
 	BatteriesIncluded                     []string
+	// TMCPlacementQueueShares are the shares of workspaces, by logical cluster name, in the queue of the
+	// TMC placement controller.
+	TMCPlacementQueueShares map[string]int
+	// TMCPlacementSharding runs the TMC placement controller on every replica instead of on the leader only,
+	// each replica placing in a share of the workspaces.
+	TMCPlacementSharding bool
+	// TMCSyncerImage is the syncer image in the bootstrap manifests of self-registered SyncTargets.
+	TMCSyncerImage string
+	// TMCCarbonIntensityProviderURL is the URL of the service the TMC placement controller asks for the
+	// carbon intensity of SyncTargets not publishing it. Empty disables it.
+	TMCCarbonIntensityProviderURL string
+	// TMCPlacementCheckpointEncryptionConfig is the file configuring the keys the checkpoints of the TMC
+	// placement controller are encrypted with. Empty disables encryption.
+	TMCPlacementCheckpointEncryptionConfig string
+	// TMCPlacementExtendersConfig is the file configuring the out-of-process extenders PlacementPolicies
+	// can delegate placement to. Empty configures none.
+	TMCPlacementExtendersConfig string
+	// TMCPlacementDecisionRecords records the decisions of the TMC placement controller as
+	// PlacementDecisionRecords in the workspaces of the workloads.
+	TMCPlacementDecisionRecords bool
+	// TMCPlacementDecisionRecordMaxAge is the age after which PlacementDecisionRecords are purged. Zero keeps
+	// them regardless of age.
+	TMCPlacementDecisionRecordMaxAge time.Duration
+	// TMCPlacementDecisionRecordsPerWorkload is the number of most recent PlacementDecisionRecords kept per
+	// workload. Zero keeps all.
+	TMCPlacementDecisionRecordsPerWorkload int
+	// TMCPlacementDecisionMetrics exports metrics of the decisions of the TMC placement controller, aggregated
+	// from its PlacementDecisionRecords. Requires TMCPlacementDecisionRecords.
+	TMCPlacementDecisionMetrics bool
+	// TMCControllerRetryProfile is the retry profile the workqueues of the TMC controllers back off by.
+	// Empty keeps the backoff of the default controller rate limiter.
+	TMCControllerRetryProfile string
+	// TMCWorkqueueConfigMap is the ConfigMap in the leader election namespace of the local admin workspace
+	// configuring the rates at which workspaces add items to the workqueues of the TMC controllers. Empty
+	// leaves workspaces unlimited.
+	TMCWorkqueueConfigMap string
 	// DEVELOPMENT ONLY. AdditionalMappingsFile is the path to a file that contains additional mappings
 	// for the mini-front-proxy to use. The file should be in the format of the
 	// --miniproxy-mapping-file flag of the front-proxy. Do NOT expose this flag to users via main server options.
@@ -114,6 +151,11 @@ func NewOptions(rootDir string) *Options {
 			DiscoveryPollInterval:              60 * time.Second,
 			ExperimentalBindFreePort:           false,
 			ConversionCELTransformationTimeout: time.Second,
+			TMCSyncerImage:                     "ghcr.io/kcp-dev/kcp/syncer:main",
+			TMCPlacementDecisionRecordMaxAge:   7 * 24 * time.Hour,
+
+			TMCPlacementDecisionRecordsPerWorkload: 20,
+			TMCWorkqueueConfigMap:                  "kcp-tmc-workqueue",
 
 			BatteriesIncluded: sets.List[string](batteries.Defaults),
 		},
@@ -172,6 +214,19 @@ func (o *Options) AddFlags(fss *cliflag.NamedFlagSets) {
 	fs.BoolVar(&o.Extra.ExperimentalBindFreePort, "experimental-bind-free-port", o.Extra.ExperimentalBindFreePort, "Bind to a free port. --secure-port must be 0. Use the admin.kubeconfig to extract the chosen port.")
 	fs.MarkHidden("experimental-bind-free-port") //nolint:errcheck
 
+	fs.StringToIntVar(&o.Extra.TMCPlacementQueueShares, "tmc-placement-queue-shares", o.Extra.TMCPlacementQueueShares, "Shares of workspaces in the TMC placement queue, as <logical cluster name>=<shares>. Workspaces without shares have one. A workspace with twice the shares of another is served twice as often while both have workloads waiting for placement.")
+	fs.BoolVar(&o.Extra.TMCPlacementSharding, "tmc-placement-sharding", o.Extra.TMCPlacementSharding, "Run the TMC placement controller on every replica instead of on the leader only. Workspaces are spread over the replicas by consistent hashing, each replica holding a Lease in the leader election namespace, and move between replicas when they join or leave.")
+	fs.StringVar(&o.Extra.TMCSyncerImage, "tmc-syncer-image", o.Extra.TMCSyncerImage, "Syncer image in the bootstrap manifests of SyncTargets registered through the onboarding virtual workspace.")
+	fs.StringVar(&o.Extra.TMCCarbonIntensityProviderURL, "tmc-carbon-intensity-provider-url", o.Extra.TMCCarbonIntensityProviderURL, "URL of a service returning the carbon intensity of an electricity grid region, asked with GET <url>?region=<region> for SyncTargets not publishing their carbon intensity. Empty disables it.")
+	fs.StringVar(&o.Extra.TMCPlacementCheckpointEncryptionConfig, "tmc-placement-checkpoint-encryption-config", o.Extra.TMCPlacementCheckpointEncryptionConfig, "File configuring the keys the checkpoints of the TMC placement controller are encrypted with. The first key encrypts, all keys decrypt. Not encrypted if empty.")
+	fs.StringVar(&o.Extra.TMCPlacementExtendersConfig, "tmc-placement-extenders-config", o.Extra.TMCPlacementExtendersConfig, "File configuring the gRPC placement extenders PlacementPolicies select in spec.extender, by name, address and TLS settings.")
+	fs.BoolVar(&o.Extra.TMCPlacementDecisionRecords, "tmc-placement-decision-records", o.Extra.TMCPlacementDecisionRecords, "Record the decisions of the TMC placement controller as PlacementDecisionRecords in the workspaces of the workloads. Decisions not changing the outcome are added as attempts to the latest record.")
+	fs.DurationVar(&o.Extra.TMCPlacementDecisionRecordMaxAge, "tmc-placement-decision-record-max-age", o.Extra.TMCPlacementDecisionRecordMaxAge, "Age after which PlacementDecisionRecords are purged. Zero keeps them regardless of age.")
+	fs.IntVar(&o.Extra.TMCPlacementDecisionRecordsPerWorkload, "tmc-placement-decision-records-per-workload", o.Extra.TMCPlacementDecisionRecordsPerWorkload, "Number of most recent PlacementDecisionRecords kept per workload. Zero keeps all.")
+	fs.BoolVar(&o.Extra.TMCPlacementDecisionMetrics, "tmc-placement-decision-metrics", o.Extra.TMCPlacementDecisionMetrics, "Export the latency, strategies and rejection reasons of the decisions of the TMC placement controller, and the placement footprint per workspace, on /metrics. Requires --tmc-placement-decision-records.")
+	fs.StringVar(&o.Extra.TMCControllerRetryProfile, "tmc-controller-retry-profile", o.Extra.TMCControllerRetryProfile, "Retry profile the TMC controllers back off failed reconciliations by: fast, standard or conservative. Empty keeps the backoff of the default controller rate limiter.")
+	fs.StringVar(&o.Extra.TMCWorkqueueConfigMap, "tmc-workqueue-configmap", o.Extra.TMCWorkqueueConfigMap, "ConfigMap in the leader election namespace of the local admin workspace configuring, in its config.yaml key, the default and per-workspace qps and burst at which workspaces add items to the workqueues of the TMC controllers before their items are served after those of other workspaces. Empty leaves workspaces unlimited.")
+
 	fs.DurationVar(&o.Extra.ConversionCELTransformationTimeout, "conversion-cel-transformation-timeout", o.Extra.ConversionCELTransformationTimeout, "Maximum amount of time that CEL transformations may take per object conversion.")
 
 	fs.StringSliceVar(&o.Extra.BatteriesIncluded, "batteries-included", o.Extra.BatteriesIncluded, fmt.Sprintf(
@@ -233,6 +288,16 @@ func (o *CompletedOptions) Validate() []error {
 		errs = append(errs, fmt.Errorf("battery %s enabled which requires %s as well", batteries.User, batteries.Admin))
 	}
 
+	if o.Extra.TMCControllerRetryProfile != "" {
+		if _, err := retry.ForProfile(retry.Profile(o.Extra.TMCControllerRetryProfile)); err != nil {
+			errs = append(errs, fmt.Errorf("--tmc-controller-retry-profile: %w", err))
+		}
+	}
+
+	if o.Extra.TMCPlacementDecisionMetrics && !o.Extra.TMCPlacementDecisionRecords {
+		errs = append(errs, fmt.Errorf("--tmc-placement-decision-records is required if --tmc-placement-decision-metrics is set"))
+	}
+
 	if o.Extra.LogicalClusterAdminKubeconfig != "" && o.Extra.ShardExternalURL == "" {
 		errs = append(errs, fmt.Errorf("--shard-external-url is required if --logical-cluster-admin-kubeconfig is set"))
 	}
@@ -311,6 +376,14 @@ func (o *Options) Complete(ctx context.Context, rootDir string) (*CompletedOptio
 		o.GenericControlPlane.SecureServing.Listener = listener
 	}
 
+	// The TMC admission plugins are only on by default with the TMC APIs.
+	// Feature gates are parsed after the defaults are set in NewOptions.
+	if !kcpfeatures.TMCAPIsEnabled() {
+		admissionOptions := o.GenericControlPlane.Admission.GenericAdmission
+		off := kcpadmission.TMCAdmissionPlugins().Difference(sets.New[string](admissionOptions.EnablePlugins...))
+		admissionOptions.DisablePlugins = sets.List[string](sets.New[string](admissionOptions.DisablePlugins...).Union(off))
+	}
+
 	if err := o.Controllers.Complete(rootDir); err != nil {
 		return nil, err
 	}
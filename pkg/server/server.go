@@ -22,6 +22,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"sync"
 	"time"
 
 	extensionsapiserver "k8s.io/apiextensions-apiserver/pkg/apiserver"
@@ -52,12 +53,16 @@ import (
 	configrootphase0 "github.com/kcp-dev/kcp/config/root-phase0"
 	configshard "github.com/kcp-dev/kcp/config/shard"
 	systemcrds "github.com/kcp-dev/kcp/config/system-crds"
+	configtmc "github.com/kcp-dev/kcp/config/tmc"
 	bootstrappolicy "github.com/kcp-dev/kcp/pkg/authorization/bootstrap"
+	kcpfeatures "github.com/kcp-dev/kcp/pkg/features"
 	"github.com/kcp-dev/kcp/pkg/informer"
 	metadataclient "github.com/kcp-dev/kcp/pkg/metadata"
 	"github.com/kcp-dev/kcp/pkg/reconciler/cache/replication"
 	"github.com/kcp-dev/kcp/pkg/reconciler/dynamicrestmapper"
 	"github.com/kcp-dev/kcp/pkg/reconciler/kubequota"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/tmcexport"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/tmcinformers"
 	"github.com/kcp-dev/kcp/pkg/server/options/batteries"
 	virtualrootapiserver "github.com/kcp-dev/kcp/pkg/virtual/framework/rootapiserver"
 	"github.com/kcp-dev/kcp/sdk/apis/core"
@@ -85,6 +90,14 @@ type Server struct {
 	rootPhase1FinishedCh chan struct{}
 
 	controllers map[string]*controllerWrapper
+
+	// tmcReadiness tracks the TMC components of this process the TMC APIs
+	// wait for.
+	tmcReadiness *tmcexport.Readiness
+	// tmcInformers are the informers of the TMC resources shared by the TMC
+	// controllers, created by installTMCInformers.
+	tmcInformersLock sync.Mutex
+	tmcInformers     *tmcinformers.Factory
 }
 
 func (s *Server) AddPostStartHook(name string, hook genericapiserver.PostStartHookFunc) error {
@@ -101,6 +114,7 @@ func NewServer(c CompletedConfig) (*Server, error) {
 		syncedCh:             make(chan struct{}),
 		rootPhase1FinishedCh: make(chan struct{}),
 		controllers:          make(map[string]*controllerWrapper),
+		tmcReadiness:         tmcexport.NewReadiness(),
 	}
 
 	notFoundHandler := notfoundhandler.New(c.GenericConfig.Serializer, genericapifilters.NoMuxAndDiscoveryIncompleteKey)
@@ -382,6 +396,12 @@ func (s *Server) installControllers(ctx context.Context, controllerConfig *rest.
 		}
 	}
 
+	if s.Options.Controllers.EnableAll || enabled.Has("tmc") {
+		if err := s.installTMCControllers(ctx, controllerConfig); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -514,6 +534,18 @@ func (s *Server) Run(ctx context.Context) error {
 			}
 			logger.Info("bootstrapped root workspace phase 0")
 
+			if kcpfeatures.TMCAPIsEnabled() {
+				if err := configtmc.Bootstrap(hookCtx,
+					s.ApiExtensionsClusterClient.Cluster(core.RootCluster.Path()).Discovery(),
+					s.DynamicClusterClient.Cluster(core.RootCluster.Path()),
+					sets.New(s.Options.Extra.BatteriesIncluded...),
+				); err != nil {
+					logger.Error(err, "failed to bootstrap the TMC APIs in the root workspace")
+					return nil // don't klog.Fatal. This only happens when context is cancelled.
+				}
+				logger.Info("bootstrapped the TMC APIs in the root workspace")
+			}
+
 			logger.Info("getting kcp APIExport identities")
 			if err := wait.PollUntilContextCancel(hookCtx, time.Millisecond*500, true, func(ctx context.Context) (bool, error) {
 				if err := s.resolveIdentities(ctx); err != nil {
@@ -635,6 +667,13 @@ func (s *Server) Run(ctx context.Context) error {
 	if err := s.installControllers(ctx, controllerConfig, gvrs); err != nil {
 		return err
 	}
+	if err := s.installTMCDashboard(ctx); err != nil {
+		return err
+	}
+	if err := s.installTMCShardedPlacementController(ctx, controllerConfig); err != nil {
+		return err
+	}
+	s.installTMCFeaturez(ctx)
 
 	// Adding this to bootup sequence to not cause re-initialization errors
 	if err := s.AddPreShutdownHook(kubequota.ControllerName, func() error {
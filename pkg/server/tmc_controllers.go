@@ -0,0 +1,952 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/util/wait"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	controlplaneapiserver "k8s.io/kubernetes/pkg/controlplane/apiserver"
+
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/crypto/encryption"
+	"github.com/kcp-dev/kcp/pkg/dashboard"
+	kcpfeatures "github.com/kcp-dev/kcp/pkg/features"
+	"github.com/kcp-dev/kcp/pkg/featurez"
+	"github.com/kcp-dev/kcp/pkg/placement/carbon"
+	"github.com/kcp-dev/kcp/pkg/placement/checkpoint"
+	"github.com/kcp-dev/kcp/pkg/placement/decision"
+	"github.com/kcp-dev/kcp/pkg/placement/extender"
+	"github.com/kcp-dev/kcp/pkg/placement/fairqueue"
+	"github.com/kcp-dev/kcp/pkg/placement/framework"
+	"github.com/kcp-dev/kcp/pkg/placement/rightplacement"
+	"github.com/kcp-dev/kcp/pkg/placement/sharding"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/clusterprofile"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/drain"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/eviction"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/featurestatus"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/guardrail"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/heartbeat"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/onboarding"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/placement"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/policyrollout"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/provisioning"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/resourcequota"
+	rightplacementcontroller "github.com/kcp-dev/kcp/pkg/reconciler/tmc/rightplacement"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/serviceimport"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/statusaggregation"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/synctargetgroup"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/teardown"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/tmcexport"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/tmcinformers"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/workloadrollout"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/workloadtemplate"
+	"github.com/kcp-dev/kcp/pkg/tmc/retry"
+	"github.com/kcp-dev/kcp/pkg/tmc/workspacequeue"
+	kcpclientset "github.com/kcp-dev/kcp/sdk/client/clientset/versioned/cluster"
+)
+
+// installTMCControllers installs the TMC controllers if the TMCControllers
+// feature gate is enabled. The TMC API groups are not part of the SDK, so the
+// controllers share dynamic informers of the TMC informer factory.
+func (s *Server) installTMCControllers(ctx context.Context, config *rest.Config) error {
+	if !kcpfeatures.TMCControllersEnabled() {
+		return nil
+	}
+
+	if err := s.installTMCInformers(config); err != nil {
+		return err
+	}
+	if profile := s.Options.Extra.TMCControllerRetryProfile; profile != "" {
+		strategy, err := retry.ForProfile(retry.Profile(profile))
+		if err != nil {
+			return err
+		}
+		retry.SetControllers(strategy)
+	}
+	if name := s.Options.Extra.TMCWorkqueueConfigMap; name != "" {
+		if err := s.installTMCWorkqueueConfig(name); err != nil {
+			return err
+		}
+	}
+
+	if err := s.installTMCClusterProfileController(ctx, config); err != nil {
+		return err
+	}
+	if err := s.installTMCWorkloadTemplateController(ctx, config); err != nil {
+		return err
+	}
+	if err := s.installTMCGuardrailController(ctx, config); err != nil {
+		return err
+	}
+	if err := s.installTMCSyncTargetGroupController(ctx, config); err != nil {
+		return err
+	}
+	if err := s.installTMCOnboardingController(ctx, config); err != nil {
+		return err
+	}
+	if err := s.installTMCHeartbeatController(ctx, config); err != nil {
+		return err
+	}
+	if err := s.installTMCFeatureStatusController(ctx, config); err != nil {
+		return err
+	}
+	if err := s.installTMCExportController(ctx, config); err != nil {
+		return err
+	}
+
+	if kcpfeatures.TMCPlacementEnabled() {
+		if err := s.installTMCPolicyRolloutController(ctx, config); err != nil {
+			return err
+		}
+		if err := s.installTMCPlacementController(ctx, config); err != nil {
+			return err
+		}
+		if err := s.installTMCRightPlacementController(ctx, config); err != nil {
+			return err
+		}
+		if err := s.installTMCTeardownController(ctx, config); err != nil {
+			return err
+		}
+		if err := s.installTMCDrainController(ctx, config); err != nil {
+			return err
+		}
+		if err := s.installTMCProvisioningController(ctx, config); err != nil {
+			return err
+		}
+		if err := s.installTMCEvictionController(ctx, config); err != nil {
+			return err
+		}
+		if err := s.installTMCStatusAggregationController(ctx, config); err != nil {
+			return err
+		}
+		if err := s.installTMCWorkloadRolloutController(ctx, config); err != nil {
+			return err
+		}
+		if err := s.installTMCServiceImportController(ctx, config); err != nil {
+			return err
+		}
+		if err := s.installTMCResourceQuotaController(ctx, config); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// installTMCInformers creates the TMC informer factory shared by the TMC
+// controllers of this process, unless it exists already, e.g. from a
+// previous leader election. Without the wildcard permission, its informers
+// watch the logical clusters of this shard.
+func (s *Server) installTMCInformers(config *rest.Config) error {
+	s.tmcInformersLock.Lock()
+	defer s.tmcInformersLock.Unlock()
+	if s.tmcInformers != nil {
+		return nil
+	}
+
+	dynamicClusterClient, err := kcpdynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	tmcInformers := tmcinformers.NewFactory(s.DiscoveringDynamicSharedInformerFactory, dynamicClusterClient)
+	_, err = s.KcpSharedInformerFactory.Core().V1alpha1().LogicalClusters().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if accessor, err := meta.Accessor(obj); err == nil {
+				tmcInformers.WatchWorkspace(logicalcluster.From(accessor))
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if accessor, err := meta.Accessor(obj); err == nil {
+				tmcInformers.ForgetWorkspace(logicalcluster.From(accessor))
+			}
+		},
+	})
+	if err != nil {
+		return err
+	}
+	s.tmcInformers = tmcInformers
+	return nil
+}
+
+// tmcWorkqueueConfigInterval is how often the workqueue configuration is
+// read.
+const tmcWorkqueueConfigInterval = 30 * time.Second
+
+// installTMCWorkqueueConfig follows the ConfigMap configuring the rates of
+// workspaces in the workqueues of the TMC controllers, on every replica, as
+// the workqueues of controllers waiting for leadership are created already.
+func (s *Server) installTMCWorkqueueConfig(name string) error {
+	localAdminClient, err := s.tmcLocalAdminClient("kcp-tmc-workqueue-config")
+	if err != nil {
+		return err
+	}
+	return s.AddPostStartHook("kcp-tmc-workqueue-config", func(hookContext genericapiserver.PostStartHookContext) error {
+		ctx := klog.NewContext(hookContext, klog.FromContext(hookContext).WithValues("postStartHook", "kcp-tmc-workqueue-config"))
+		go workspacequeue.WatchConfigMap(ctx, localAdminClient, s.Options.Controllers.LeaderElectionNamespace, name, tmcWorkqueueConfigInterval)
+		return nil
+	})
+}
+
+// waitForTMCInformers returns a WaitFunc that starts informers and waits
+// until they synced. The informers are started with startCtx, the context
+// of the installation, as they are shared across leader elections.
+func waitForTMCInformers(startCtx context.Context, informers ...*tmcinformers.Informer) WaitFunc {
+	return func(ctx context.Context, _ *Server) error {
+		for _, informer := range informers {
+			if err := informer.Start(startCtx); err != nil {
+				return err
+			}
+		}
+		return wait.PollUntilContextCancel(ctx, waitPollInterval, true, func(ctx context.Context) (bool, error) {
+			for _, informer := range informers {
+				if !informer.HasSynced() {
+					return false, nil
+				}
+			}
+			return true, nil
+		})
+	}
+}
+
+func (s *Server) installTMCClusterProfileController(ctx context.Context, config *rest.Config) error {
+	config = rest.CopyConfig(config)
+	config = rest.AddUserAgent(config, clusterprofile.ControllerName)
+
+	dynamicClusterClient, err := kcpdynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	syncTargetInformer := s.tmcInformers.ForResource(clusterprofile.SyncTargetsGVR)
+
+	c, err := clusterprofile.NewController("", syncTargetInformer, dynamicClusterClient)
+	if err != nil {
+		return err
+	}
+
+	return s.registerController(&controllerWrapper{
+		Name: clusterprofile.ControllerName,
+		Wait: waitForTMCInformers(ctx, syncTargetInformer),
+		Runner: func(ctx context.Context) {
+			c.Start(ctx, 2)
+		},
+	})
+}
+
+func (s *Server) installTMCPolicyRolloutController(ctx context.Context, config *rest.Config) error {
+	config = rest.CopyConfig(config)
+	config = rest.AddUserAgent(config, policyrollout.ControllerName)
+
+	dynamicClusterClient, err := kcpdynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	policyInformer := s.tmcInformers.ForResource(policyrollout.PlacementPoliciesGVR)
+	revisionInformer := s.tmcInformers.ForResource(policyrollout.PlacementPolicyRevisionsGVR)
+	distributionInformer := s.tmcInformers.ForResource(policyrollout.WorkloadDistributionsGVR)
+
+	c, err := policyrollout.NewController(policyInformer, revisionInformer, distributionInformer, dynamicClusterClient)
+	if err != nil {
+		return err
+	}
+
+	return s.registerController(&controllerWrapper{
+		Name: policyrollout.ControllerName,
+		Wait: waitForTMCInformers(ctx, policyInformer, revisionInformer, distributionInformer),
+		Runner: func(ctx context.Context) {
+			c.Start(ctx, 2)
+		},
+	})
+}
+
+func (s *Server) installTMCPlacementController(ctx context.Context, config *rest.Config) error {
+	if s.Options.Extra.TMCPlacementSharding {
+		// Run on every replica by installTMCShardedPlacementController.
+		return nil
+	}
+	controller, err := s.newTMCPlacementController(ctx, config, nil)
+	if err != nil {
+		return err
+	}
+	return s.registerController(controller)
+}
+
+// installTMCShardedPlacementController runs the placement controller on every
+// replica instead of on the leader only, each replica placing in the
+// workspaces assigned to it.
+func (s *Server) installTMCShardedPlacementController(ctx context.Context, config *rest.Config) error {
+	if !kcpfeatures.TMCControllersEnabled() || !kcpfeatures.TMCPlacementEnabled() || !s.Options.Extra.TMCPlacementSharding {
+		return nil
+	}
+	if err := s.installTMCInformers(config); err != nil {
+		return err
+	}
+
+	localAdminClient, err := s.tmcLocalAdminClient(placement.ControllerName)
+	if err != nil {
+		return err
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	distributionInformer := s.tmcInformers.ForResource(policyrollout.WorkloadDistributionsGVR)
+	sharder := sharding.New(localAdminClient, s.Options.Controllers.LeaderElectionNamespace, hostname+"_"+string(uuid.NewUUID()), sharding.Options{
+		Workspaces: func() []string {
+			objs, err := distributionInformer.List(labels.Everything())
+			if err != nil {
+				return nil
+			}
+			workspaces := sets.New[string]()
+			for _, obj := range objs {
+				if accessor, err := meta.Accessor(obj); err == nil {
+					workspaces.Insert(logicalcluster.From(accessor).String())
+				}
+			}
+			return sets.List(workspaces)
+		},
+	})
+	controller, err := s.newTMCPlacementController(ctx, config, sharder)
+	if err != nil {
+		return err
+	}
+
+	return s.AddPostStartHook("kcp-tmc-start-sharded-placement", func(hookContext genericapiserver.PostStartHookContext) error {
+		ctx := klog.NewContext(hookContext, klog.FromContext(hookContext).WithValues("postStartHook", "kcp-tmc-start-sharded-placement"))
+		go sharder.Run(ctx)
+		go s.runController(ctx, controller)
+		return nil
+	})
+}
+
+// newTMCPlacementController returns the placement controller, sharded by
+// sharder unless nil. The placement state is checkpointed for the next
+// leader only if it is not sharded.
+func (s *Server) newTMCPlacementController(ctx context.Context, config *rest.Config, sharder *sharding.Sharder) (*controllerWrapper, error) {
+	config = rest.CopyConfig(config)
+	config = rest.AddUserAgent(config, placement.ControllerName)
+
+	dynamicClusterClient, err := kcpdynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	distributionInformer := s.tmcInformers.ForResource(policyrollout.WorkloadDistributionsGVR)
+	policyInformer := s.tmcInformers.ForResource(policyrollout.PlacementPoliciesGVR)
+	revisionInformer := s.tmcInformers.ForResource(policyrollout.PlacementPolicyRevisionsGVR)
+	syncTargetInformer := s.tmcInformers.ForResource(clusterprofile.SyncTargetsGVR)
+	profileInformer := s.tmcInformers.ForResource(placement.SchedulingProfilesGVR)
+	groupInformer := s.tmcInformers.ForResource(placement.SyncTargetGroupsGVR)
+	dataLocationInformer := s.tmcInformers.ForResource(placement.DataLocationsGVR)
+	advancedInformer := s.tmcInformers.ForResource(placement.WorkloadPlacementAdvancedGVR)
+	quotaInformer := s.tmcInformers.ForResource(resourcequota.MultiClusterResourceQuotasGVR)
+
+	var carbonProvider carbon.Provider
+	if providerURL := s.Options.Extra.TMCCarbonIntensityProviderURL; providerURL != "" {
+		if carbonProvider, err = carbon.NewHTTPProvider(providerURL); err != nil {
+			return nil, err
+		}
+	}
+
+	if path := s.Options.Extra.TMCPlacementExtendersConfig; path != "" {
+		config, err := extender.LoadConfiguration(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := extender.Register(framework.DefaultRegistry, config); err != nil {
+			return nil, err
+		}
+	}
+
+	var recorder *decision.Recorder
+	var exporter *decision.Exporter
+	if s.Options.Extra.TMCPlacementDecisionRecords {
+		storage := decision.NewCRDStorage(dynamicClusterClient)
+		config := decision.RecorderConfig{
+			Retention: decision.RetentionPolicy{
+				MaxAge:                s.Options.Extra.TMCPlacementDecisionRecordMaxAge,
+				MaxRecordsPerWorkload: s.Options.Extra.TMCPlacementDecisionRecordsPerWorkload,
+			},
+			EnableMetrics: s.Options.Extra.TMCPlacementDecisionMetrics,
+		}
+		recorder = decision.NewRecorder(storage, config)
+		if config.EnableMetrics {
+			// Served on /metrics of the server with all other metrics.
+			exporter = decision.NewExporter(storage, config)
+		}
+	}
+
+	c, err := placement.NewController(fairqueue.Options{Shares: s.Options.Extra.TMCPlacementQueueShares}, carbonProvider, sharder, recorder, distributionInformer, policyInformer, revisionInformer, syncTargetInformer, profileInformer, groupInformer, dataLocationInformer, advancedInformer, quotaInformer, dynamicClusterClient)
+	if err != nil {
+		return nil, err
+	}
+
+	run := func(ctx context.Context) { c.Start(ctx, 2) }
+	if sharder == nil {
+		// The placement state is handed over between replicas through the
+		// local admin workspace, like the leader election lease.
+		checkpointClient, err := s.tmcLocalAdminClient(placement.ControllerName)
+		if err != nil {
+			return nil, err
+		}
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, err
+		}
+		var checkpointEncryption encryption.Provider
+		if path := s.Options.Extra.TMCPlacementCheckpointEncryptionConfig; path != "" {
+			if checkpointEncryption, err = encryption.LoadKeyring(path); err != nil {
+				return nil, err
+			}
+		}
+		checkpointer := checkpoint.New(
+			checkpoint.NewConfigMapStore(checkpointClient, s.Options.Controllers.LeaderElectionNamespace, placement.CheckpointName, checkpointEncryption),
+			hostname, checkpoint.Options{}, c.Snapshot, c.Restore,
+		)
+		run = func(ctx context.Context) {
+			checkpointer.Restore(ctx)
+			go checkpointer.Run(ctx)
+			c.Start(ctx, 2)
+		}
+	}
+	if recorder != nil {
+		start := run
+		run = func(ctx context.Context) {
+			go recorder.Run(ctx)
+			if exporter != nil {
+				go exporter.Run(ctx)
+			}
+			start(ctx)
+		}
+	}
+
+	s.tmcReadiness.Require(tmcexport.PlacementController)
+	return &controllerWrapper{
+		Name:   placement.ControllerName,
+		Wait:   waitForTMCInformers(ctx, distributionInformer, policyInformer, revisionInformer, syncTargetInformer, profileInformer, groupInformer, dataLocationInformer, advancedInformer, quotaInformer),
+		Runner: s.tmcReadiness.Run(tmcexport.PlacementController, run),
+	}, nil
+}
+
+// tmcLocalAdminClient returns a client of the local admin workspace, where
+// the leader election lease is.
+func (s *Server) tmcLocalAdminClient(userAgent string) (kubernetes.Interface, error) {
+	config := rest.CopyConfig(s.GenericConfig.LoopbackClientConfig)
+	config = rest.AddUserAgent(config, userAgent)
+	var err error
+	config.Host, err = url.JoinPath(config.Host, controlplaneapiserver.LocalAdminCluster.Path().RequestPath())
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewForConfig(config)
+}
+
+func (s *Server) installTMCWorkloadTemplateController(ctx context.Context, config *rest.Config) error {
+	config = rest.CopyConfig(config)
+	config = rest.AddUserAgent(config, workloadtemplate.ControllerName)
+
+	dynamicClusterClient, err := kcpdynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	templateInformer := s.tmcInformers.ForResource(workloadtemplate.WorkloadTemplatesGVR)
+	syncTargetInformer := s.tmcInformers.ForResource(clusterprofile.SyncTargetsGVR)
+
+	c, err := workloadtemplate.NewController(templateInformer, syncTargetInformer, dynamicClusterClient, s.DynRESTMapper)
+	if err != nil {
+		return err
+	}
+
+	return s.registerController(&controllerWrapper{
+		Name: workloadtemplate.ControllerName,
+		Wait: waitForTMCInformers(ctx, templateInformer, syncTargetInformer),
+		Runner: func(ctx context.Context) {
+			c.Start(ctx, 2)
+		},
+	})
+}
+
+func (s *Server) installTMCGuardrailController(ctx context.Context, config *rest.Config) error {
+	config = rest.CopyConfig(config)
+	config = rest.AddUserAgent(config, guardrail.ControllerName)
+
+	dynamicClusterClient, err := kcpdynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	syncTargetInformer := s.tmcInformers.ForResource(clusterprofile.SyncTargetsGVR)
+
+	c, err := guardrail.NewController(syncTargetInformer, dynamicClusterClient)
+	if err != nil {
+		return err
+	}
+
+	return s.registerController(&controllerWrapper{
+		Name: guardrail.ControllerName,
+		Wait: waitForTMCInformers(ctx, syncTargetInformer),
+		Runner: func(ctx context.Context) {
+			c.Start(ctx, 2)
+		},
+	})
+}
+
+func (s *Server) installTMCEvictionController(ctx context.Context, config *rest.Config) error {
+	config = rest.CopyConfig(config)
+	config = rest.AddUserAgent(config, eviction.ControllerName)
+
+	dynamicClusterClient, err := kcpdynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	policyInformer := s.tmcInformers.ForResource(eviction.EvictionPoliciesGVR)
+	syncTargetInformer := s.tmcInformers.ForResource(clusterprofile.SyncTargetsGVR)
+
+	c, err := eviction.NewController(policyInformer, syncTargetInformer, dynamicClusterClient)
+	if err != nil {
+		return err
+	}
+
+	return s.registerController(&controllerWrapper{
+		Name: eviction.ControllerName,
+		Wait: waitForTMCInformers(ctx, policyInformer, syncTargetInformer),
+		Runner: func(ctx context.Context) {
+			c.Start(ctx, 2)
+		},
+	})
+}
+
+func (s *Server) installTMCStatusAggregationController(ctx context.Context, config *rest.Config) error {
+	config = rest.CopyConfig(config)
+	config = rest.AddUserAgent(config, statusaggregation.ControllerName)
+
+	dynamicClusterClient, err := kcpdynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	distributionInformer := s.tmcInformers.ForResource(policyrollout.WorkloadDistributionsGVR)
+	policyInformer := s.tmcInformers.ForResource(statusaggregation.StatusAggregationPoliciesGVR)
+	// The informers of the distributed workload resources are started once
+	// a workload of the resource is distributed.
+	workloadInformer := func(gvr schema.GroupVersionResource) *tmcinformers.Informer {
+		informer := s.tmcInformers.ForResource(gvr)
+		go func() { _ = informer.Start(ctx) }()
+		return informer
+	}
+
+	c, err := statusaggregation.NewController(distributionInformer, policyInformer, workloadInformer, dynamicClusterClient, s.DynRESTMapper)
+	if err != nil {
+		return err
+	}
+
+	s.tmcReadiness.Require(tmcexport.StatusAggregationController)
+	return s.registerController(&controllerWrapper{
+		Name: statusaggregation.ControllerName,
+		Wait: waitForTMCInformers(ctx, distributionInformer, policyInformer),
+		Runner: s.tmcReadiness.Run(tmcexport.StatusAggregationController, func(ctx context.Context) {
+			c.Start(ctx, 2)
+		}),
+	})
+}
+
+func (s *Server) installTMCWorkloadRolloutController(ctx context.Context, config *rest.Config) error {
+	config = rest.CopyConfig(config)
+	config = rest.AddUserAgent(config, workloadrollout.ControllerName)
+
+	dynamicClusterClient, err := kcpdynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	distributionInformer := s.tmcInformers.ForResource(workloadrollout.WorkloadDistributionsGVR)
+	workloadInformer := func(gvr schema.GroupVersionResource) *tmcinformers.Informer {
+		informer := s.tmcInformers.ForResource(gvr)
+		go func() { _ = informer.Start(ctx) }()
+		return informer
+	}
+
+	c, err := workloadrollout.NewController(distributionInformer, workloadInformer, dynamicClusterClient, s.DynRESTMapper)
+	if err != nil {
+		return err
+	}
+
+	return s.registerController(&controllerWrapper{
+		Name: workloadrollout.ControllerName,
+		Wait: waitForTMCInformers(ctx, distributionInformer),
+		Runner: func(ctx context.Context) {
+			c.Start(ctx, 2)
+		},
+	})
+}
+
+func (s *Server) installTMCResourceQuotaController(ctx context.Context, config *rest.Config) error {
+	config = rest.CopyConfig(config)
+	config = rest.AddUserAgent(config, resourcequota.ControllerName)
+
+	dynamicClusterClient, err := kcpdynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	quotaInformer := s.tmcInformers.ForResource(resourcequota.MultiClusterResourceQuotasGVR)
+	distributionInformer := s.tmcInformers.ForResource(resourcequota.WorkloadDistributionsGVR)
+	// The informers of the distributed workload resources are started once
+	// a workload of the resource is distributed.
+	workloadInformer := func(gvr schema.GroupVersionResource) *tmcinformers.Informer {
+		informer := s.tmcInformers.ForResource(gvr)
+		go func() { _ = informer.Start(ctx) }()
+		return informer
+	}
+
+	c, err := resourcequota.NewController(quotaInformer, distributionInformer, workloadInformer, dynamicClusterClient, s.DynRESTMapper)
+	if err != nil {
+		return err
+	}
+
+	return s.registerController(&controllerWrapper{
+		Name: resourcequota.ControllerName,
+		Wait: waitForTMCInformers(ctx, quotaInformer, distributionInformer),
+		Runner: func(ctx context.Context) {
+			c.Start(ctx, 2)
+		},
+	})
+}
+
+func (s *Server) installTMCServiceImportController(ctx context.Context, config *rest.Config) error {
+	config = rest.CopyConfig(config)
+	config = rest.AddUserAgent(config, serviceimport.ControllerName)
+
+	dynamicClusterClient, err := kcpdynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	exportInformer := s.tmcInformers.ForResource(serviceimport.ServiceExportsGVR)
+	importInformer := s.tmcInformers.ForResource(serviceimport.ServiceImportsGVR)
+	serviceInformer := s.tmcInformers.ForResource(serviceimport.ServicesGVR)
+
+	c, err := serviceimport.NewController(exportInformer, importInformer, serviceInformer, dynamicClusterClient)
+	if err != nil {
+		return err
+	}
+
+	return s.registerController(&controllerWrapper{
+		Name: serviceimport.ControllerName,
+		Wait: waitForTMCInformers(ctx, exportInformer, importInformer, serviceInformer),
+		Runner: func(ctx context.Context) {
+			c.Start(ctx, 2)
+		},
+	})
+}
+
+func (s *Server) installTMCFeatureStatusController(ctx context.Context, config *rest.Config) error {
+	config = rest.CopyConfig(config)
+	config = rest.AddUserAgent(config, featurestatus.ControllerName)
+
+	dynamicClusterClient, err := kcpdynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	featureStatusInformer := s.tmcInformers.ForResource(featurestatus.TMCFeatureStatusesGVR)
+	syncTargetInformer := s.tmcInformers.ForResource(clusterprofile.SyncTargetsGVR)
+	informers := []*tmcinformers.Informer{featureStatusInformer, syncTargetInformer}
+	var distributionInformer *tmcinformers.Informer
+	if kcpfeatures.TMCPlacementEnabled() {
+		distributionInformer = s.tmcInformers.ForResource(policyrollout.WorkloadDistributionsGVR)
+		informers = append(informers, distributionInformer)
+	}
+
+	c, err := featurestatus.NewController(featureStatusInformer, syncTargetInformer, distributionInformer, dynamicClusterClient)
+	if err != nil {
+		return err
+	}
+
+	return s.registerController(&controllerWrapper{
+		Name: featurestatus.ControllerName,
+		Wait: waitForTMCInformers(ctx, informers...),
+		Runner: func(ctx context.Context) {
+			c.Start(ctx, 2)
+		},
+	})
+}
+
+func (s *Server) installTMCExportController(_ context.Context, config *rest.Config) error {
+	config = rest.CopyConfig(config)
+	config = rest.AddUserAgent(config, tmcexport.ControllerName)
+
+	kcpClusterClient, err := kcpclientset.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	c, err := tmcexport.NewController(s.tmcReadiness, s.KcpSharedInformerFactory.Apis().V1alpha2().APIExports(), kcpClusterClient)
+	if err != nil {
+		return err
+	}
+
+	return s.registerController(&controllerWrapper{
+		Name: tmcexport.ControllerName,
+		Wait: func(ctx context.Context, s *Server) error {
+			return wait.PollUntilContextCancel(ctx, waitPollInterval, true, func(ctx context.Context) (bool, error) {
+				return s.KcpSharedInformerFactory.Apis().V1alpha2().APIExports().Informer().HasSynced(), nil
+			})
+		},
+		Runner: func(ctx context.Context) {
+			c.Start(ctx, 2)
+		},
+	})
+}
+
+func (s *Server) installTMCOnboardingController(ctx context.Context, config *rest.Config) error {
+	config = rest.CopyConfig(config)
+	config = rest.AddUserAgent(config, onboarding.ControllerName)
+
+	dynamicClusterClient, err := kcpdynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	syncTargetInformer := s.tmcInformers.ForResource(clusterprofile.SyncTargetsGVR)
+
+	c, err := onboarding.NewController(onboarding.Options{
+		ExternalURL: s.CompletedConfig.ShardExternalURL,
+		SyncerImage: s.Options.Extra.TMCSyncerImage,
+	}, syncTargetInformer, dynamicClusterClient)
+	if err != nil {
+		return err
+	}
+
+	return s.registerController(&controllerWrapper{
+		Name: onboarding.ControllerName,
+		Wait: waitForTMCInformers(ctx, syncTargetInformer),
+		Runner: func(ctx context.Context) {
+			c.Start(ctx, 2)
+		},
+	})
+}
+
+func (s *Server) installTMCHeartbeatController(ctx context.Context, config *rest.Config) error {
+	config = rest.CopyConfig(config)
+	config = rest.AddUserAgent(config, heartbeat.ControllerName)
+
+	dynamicClusterClient, err := kcpdynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	syncTargetInformer := s.tmcInformers.ForResource(clusterprofile.SyncTargetsGVR)
+	leaseInformer := s.tmcInformers.ForResource(heartbeat.LeasesGVR)
+
+	c, err := heartbeat.NewController(syncTargetInformer, leaseInformer, dynamicClusterClient)
+	if err != nil {
+		return err
+	}
+
+	return s.registerController(&controllerWrapper{
+		Name: heartbeat.ControllerName,
+		Wait: waitForTMCInformers(ctx, syncTargetInformer, leaseInformer),
+		Runner: func(ctx context.Context) {
+			c.Start(ctx, 2)
+		},
+	})
+}
+
+func (s *Server) installTMCSyncTargetGroupController(ctx context.Context, config *rest.Config) error {
+	config = rest.CopyConfig(config)
+	config = rest.AddUserAgent(config, synctargetgroup.ControllerName)
+
+	dynamicClusterClient, err := kcpdynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	groupInformer := s.tmcInformers.ForResource(placement.SyncTargetGroupsGVR)
+	syncTargetInformer := s.tmcInformers.ForResource(clusterprofile.SyncTargetsGVR)
+
+	c, err := synctargetgroup.NewController(groupInformer, syncTargetInformer, dynamicClusterClient)
+	if err != nil {
+		return err
+	}
+
+	return s.registerController(&controllerWrapper{
+		Name: synctargetgroup.ControllerName,
+		Wait: waitForTMCInformers(ctx, groupInformer, syncTargetInformer),
+		Runner: func(ctx context.Context) {
+			c.Start(ctx, 2)
+		},
+	})
+}
+
+func (s *Server) installTMCRightPlacementController(ctx context.Context, config *rest.Config) error {
+	config = rest.CopyConfig(config)
+	config = rest.AddUserAgent(config, rightplacementcontroller.ControllerName)
+
+	dynamicClusterClient, err := kcpdynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	recommendationInformer := s.tmcInformers.ForResource(rightplacement.RecommendationsGVR)
+	planInformer := s.tmcInformers.ForResource(rightplacement.RebalancePlansGVR)
+	distributionInformer := s.tmcInformers.ForResource(policyrollout.WorkloadDistributionsGVR)
+
+	c, err := rightplacementcontroller.NewController(recommendationInformer, planInformer, distributionInformer, dynamicClusterClient)
+	if err != nil {
+		return err
+	}
+
+	return s.registerController(&controllerWrapper{
+		Name: rightplacementcontroller.ControllerName,
+		Wait: waitForTMCInformers(ctx, recommendationInformer, planInformer, distributionInformer),
+		Runner: func(ctx context.Context) {
+			c.Start(ctx, 2)
+		},
+	})
+}
+
+func (s *Server) installTMCTeardownController(ctx context.Context, config *rest.Config) error {
+	config = rest.CopyConfig(config)
+	config = rest.AddUserAgent(config, teardown.ControllerName)
+
+	dynamicClusterClient, err := kcpdynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	syncTargetInformer := s.tmcInformers.ForResource(clusterprofile.SyncTargetsGVR)
+	distributionInformer := s.tmcInformers.ForResource(policyrollout.WorkloadDistributionsGVR)
+	groupInformer := s.tmcInformers.ForResource(placement.SyncTargetGroupsGVR)
+	dataLocationInformer := s.tmcInformers.ForResource(placement.DataLocationsGVR)
+
+	c, err := teardown.NewController(syncTargetInformer, distributionInformer, groupInformer, dataLocationInformer, dynamicClusterClient)
+	if err != nil {
+		return err
+	}
+
+	return s.registerController(&controllerWrapper{
+		Name: teardown.ControllerName,
+		Wait: waitForTMCInformers(ctx, syncTargetInformer, distributionInformer, groupInformer, dataLocationInformer),
+		Runner: func(ctx context.Context) {
+			c.Start(ctx, 2)
+		},
+	})
+}
+
+func (s *Server) installTMCDrainController(ctx context.Context, config *rest.Config) error {
+	config = rest.CopyConfig(config)
+	config = rest.AddUserAgent(config, drain.ControllerName)
+
+	dynamicClusterClient, err := kcpdynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	syncTargetInformer := s.tmcInformers.ForResource(clusterprofile.SyncTargetsGVR)
+	distributionInformer := s.tmcInformers.ForResource(policyrollout.WorkloadDistributionsGVR)
+
+	c, err := drain.NewController(syncTargetInformer, distributionInformer, dynamicClusterClient)
+	if err != nil {
+		return err
+	}
+
+	return s.registerController(&controllerWrapper{
+		Name: drain.ControllerName,
+		Wait: waitForTMCInformers(ctx, syncTargetInformer, distributionInformer),
+		Runner: func(ctx context.Context) {
+			c.Start(ctx, 2)
+		},
+	})
+}
+
+func (s *Server) installTMCProvisioningController(ctx context.Context, config *rest.Config) error {
+	config = rest.CopyConfig(config)
+	config = rest.AddUserAgent(config, provisioning.ControllerName)
+
+	dynamicClusterClient, err := kcpdynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	syncTargetInformer := s.tmcInformers.ForResource(clusterprofile.SyncTargetsGVR)
+	distributionInformer := s.tmcInformers.ForResource(policyrollout.WorkloadDistributionsGVR)
+
+	c, err := provisioning.NewController(syncTargetInformer, distributionInformer, dynamicClusterClient)
+	if err != nil {
+		return err
+	}
+
+	return s.registerController(&controllerWrapper{
+		Name: provisioning.ControllerName,
+		Wait: waitForTMCInformers(ctx, syncTargetInformer, distributionInformer),
+		Runner: func(ctx context.Context) {
+			c.Start(ctx, 2)
+		},
+	})
+}
+
+// installTMCFeaturez serves the TMC feature gates of the process. It is
+// served whether TMC is enabled or not, so operators can tell which
+// servers run with TMC, and must only be installed once.
+func (s *Server) installTMCFeaturez(_ context.Context) {
+	s.Apis.GenericAPIServer.Handler.NonGoRestfulMux.Handle(featurez.Path, featurez.Handler("kcp", featurez.Process))
+}
+
+// installTMCDashboard serves the read-only dashboard API. It is served
+// behind the authentication and authorization of the non-resource paths,
+// and must only be installed once because the mux does not allow
+// registering a path twice.
+func (s *Server) installTMCDashboard(_ context.Context) error {
+	if !kcpfeatures.TMCControllersEnabled() {
+		return nil
+	}
+
+	syncTargetInformer, err := s.DiscoveringDynamicSharedInformerFactory.ForResource(clusterprofile.SyncTargetsGVR)
+	if err != nil {
+		return err
+	}
+	distributionInformer, err := s.DiscoveringDynamicSharedInformerFactory.ForResource(policyrollout.WorkloadDistributionsGVR)
+	if err != nil {
+		return err
+	}
+
+	s.Apis.GenericAPIServer.Handler.NonGoRestfulMux.HandlePrefix(dashboard.Path, dashboard.NewServer(syncTargetInformer, distributionInformer, dashboard.Options{}).Handler())
+	return nil
+}
@@ -0,0 +1,461 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package statusaggregation aggregates the statuses an object placed on
+// several SyncTargets has on each of them into a single status. The syncer
+// of each SyncTarget reports the status of its copy in an annotation of the
+// object, see TargetAnnotation, and the status aggregation controller
+// writes the aggregate to the status of the object.
+//
+// Status fields are aggregated by the strategy configured for their
+// resource, built in for the workload resources of the apps and batch
+// groups, or set by StatusAggregationPolicies. Conditions are true if they
+// are true on all SyncTargets, and status.phase is the worst phase of all
+// SyncTargets.
+package statusaggregation
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/cel-go/cel"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/naming"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+const (
+	// ValuesVariable is the CEL variable holding the values of a field.
+	ValuesVariable = "values"
+	// StatusesVariable is the CEL variable holding the statuses by
+	// SyncTarget.
+	StatusesVariable = "statuses"
+	// TargetsVariable is the CEL variable holding the number of SyncTargets.
+	TargetsVariable = "targets"
+
+	// costLimit is the cost after which the evaluation of an expression is
+	// aborted.
+	costLimit = 1_000_000
+
+	// notReportedReason is the reason of the condition of a SyncTarget that
+	// did not report it.
+	notReportedReason = "NotReported"
+)
+
+// replicaFields are the replica counters of the built-in workload
+// resources, summed over all SyncTargets.
+var replicaFields = map[schema.GroupResource][]string{
+	{Group: "apps", Resource: "deployments"}:  {"replicas", "updatedReplicas", "readyReplicas", "availableReplicas", "unavailableReplicas"},
+	{Group: "apps", Resource: "statefulsets"}: {"replicas", "readyReplicas", "currentReplicas", "updatedReplicas", "availableReplicas"},
+	{Group: "apps", Resource: "replicasets"}:  {"replicas", "fullyLabeledReplicas", "readyReplicas", "availableReplicas"},
+	{Group: "apps", Resource: "daemonsets"}: {
+		"currentNumberScheduled", "numberMisscheduled", "desiredNumberScheduled", "numberReady",
+		"updatedNumberScheduled", "numberAvailable", "numberUnavailable",
+	},
+	{Group: "batch", Resource: "jobs"}: {"active", "succeeded", "failed", "ready"},
+}
+
+// phaseSeverity orders phases from the best to the worst. Phases that are
+// not listed are as bad as Unknown.
+var phaseSeverity = map[string]int{
+	"Succeeded":   0,
+	"Complete":    0,
+	"Bound":       1,
+	"Active":      1,
+	"Running":     1,
+	"Pending":     2,
+	"Terminating": 3,
+	"Unknown":     4,
+	"Lost":        5,
+	"Failed":      5,
+}
+
+// TargetAnnotation returns the annotation in which the syncer of syncTarget
+// reports the status of its copy of an object.
+func TargetAnnotation(syncTarget string) string {
+	return workloadv1alpha1.AnnotationPrefixTargetStatus + naming.Bounded(syncTarget, validation.LabelValueMaxLength)
+}
+
+// TargetStatuses returns the statuses reported on obj for the given
+// SyncTargets, by SyncTarget. SyncTargets that did not report a status are
+// left out.
+func TargetStatuses(obj *unstructured.Unstructured, syncTargets []string) (map[string]map[string]interface{}, error) {
+	annotations := obj.GetAnnotations()
+	statuses := make(map[string]map[string]interface{}, len(syncTargets))
+	for _, syncTarget := range syncTargets {
+		raw, found := annotations[TargetAnnotation(syncTarget)]
+		if !found {
+			continue
+		}
+		status := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(raw), &status); err != nil {
+			return nil, fmt.Errorf("invalid status reported by SyncTarget %q: %w", syncTarget, err)
+		}
+		// JSON numbers are decoded as float64, but unstructured integers
+		// are int64.
+		statuses[syncTarget] = normalize(status).(map[string]interface{})
+	}
+	return statuses, nil
+}
+
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = normalize(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalize(item)
+		}
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v)
+		}
+	}
+	return v
+}
+
+// Selects returns whether policy applies to the resource gr.
+func Selects(policy *workloadv1alpha1.StatusAggregationPolicy, gr schema.GroupResource) bool {
+	for _, resource := range policy.Spec.Resources {
+		if resource == gr.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// Aggregator aggregates the statuses of the objects of a resource.
+type Aggregator struct {
+	fields []field
+}
+
+type field struct {
+	path       []string
+	strategy   workloadv1alpha1.AggregationStrategy
+	expression string
+	program    cel.Program
+}
+
+// New returns the Aggregator of the resource gr, with the built-in fields of
+// the resource and the fields of the policies, which must select gr. The
+// policy with the greatest name wins when several aggregate a field. It
+// fails if the expression of a policy does not compile.
+func New(gr schema.GroupResource, policies []*workloadv1alpha1.StatusAggregationPolicy) (*Aggregator, error) {
+	fields := map[string]workloadv1alpha1.AggregatedField{}
+	for _, path := range replicaFields[gr] {
+		fields[path] = workloadv1alpha1.AggregatedField{Path: path, Strategy: workloadv1alpha1.AggregationSum}
+	}
+	policies = append([]*workloadv1alpha1.StatusAggregationPolicy(nil), policies...)
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Name < policies[j].Name })
+	for _, policy := range policies {
+		for _, f := range policy.Spec.Fields {
+			fields[f.Path] = f
+		}
+	}
+
+	a := &Aggregator{}
+	for path, f := range fields {
+		if path == "conditions" || path == "phase" {
+			return nil, fmt.Errorf("status field %q is always aggregated", path)
+		}
+		compiled := field{path: strings.Split(path, "."), strategy: f.Strategy, expression: f.Expression}
+		if slices.Contains(compiled.path, "") {
+			return nil, fmt.Errorf("invalid status field %q", path)
+		}
+		switch f.Strategy {
+		case workloadv1alpha1.AggregationSum, workloadv1alpha1.AggregationMin, workloadv1alpha1.AggregationMax, workloadv1alpha1.AggregationAllTrue:
+		case workloadv1alpha1.AggregationCEL:
+			program, err := compile(f.Expression)
+			if err != nil {
+				return nil, fmt.Errorf("invalid expression of status field %q: %w", path, err)
+			}
+			compiled.program = program
+		default:
+			return nil, fmt.Errorf("unknown aggregation strategy %q of status field %q", f.Strategy, path)
+		}
+		a.fields = append(a.fields, compiled)
+	}
+	sort.Slice(a.fields, func(i, j int) bool {
+		return strings.Join(a.fields[i].path, ".") < strings.Join(a.fields[j].path, ".")
+	})
+	return a, nil
+}
+
+func compile(expression string) (cel.Program, error) {
+	env, err := cel.NewEnv(
+		cel.Variable(ValuesVariable, cel.ListType(cel.DynType)),
+		cel.Variable(StatusesVariable, cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable(TargetsVariable, cel.IntType),
+	)
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	return env.Program(ast, cel.CostLimit(costLimit))
+}
+
+// Aggregate returns the status aggregated from the statuses reported by the
+// SyncTargets, by SyncTarget, of an object placed on syncTargets. It only
+// holds the aggregated fields, the conditions and the phase, to be applied
+// to the status of the object, whose current status is current. Conditions
+// keep their last transition time from current while their status does not
+// change.
+func (a *Aggregator) Aggregate(current map[string]interface{}, statuses map[string]map[string]interface{}, syncTargets []string, now time.Time) (map[string]interface{}, error) {
+	aggregated := map[string]interface{}{}
+	for _, f := range a.fields {
+		var values []interface{}
+		for _, syncTarget := range syncTargets {
+			if v, found, _ := unstructured.NestedFieldNoCopy(statuses[syncTarget], f.path...); found {
+				values = append(values, v)
+			}
+		}
+		v, found, err := f.aggregate(values, statuses, len(syncTargets))
+		if err != nil {
+			return nil, fmt.Errorf("failed to aggregate status field %q: %w", strings.Join(f.path, "."), err)
+		}
+		if found {
+			if err := unstructured.SetNestedField(aggregated, v, f.path...); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if phase, found := worstPhase(statuses, syncTargets); found {
+		aggregated["phase"] = phase
+	}
+	currentConditions, _, _ := unstructured.NestedSlice(current, "conditions")
+	if conditions := aggregateConditions(currentConditions, statuses, syncTargets, now); len(conditions) > 0 {
+		aggregated["conditions"] = conditions
+	}
+	return aggregated, nil
+}
+
+func (f *field) aggregate(values []interface{}, statuses map[string]map[string]interface{}, targets int) (interface{}, bool, error) {
+	switch f.strategy {
+	case workloadv1alpha1.AggregationAllTrue:
+		for _, v := range values {
+			b, ok := v.(bool)
+			if !ok {
+				return nil, false, fmt.Errorf("%v is not a boolean", v)
+			}
+			if !b {
+				return false, true, nil
+			}
+		}
+		return len(values) == targets, true, nil
+	case workloadv1alpha1.AggregationCEL:
+		byTarget := make(map[string]interface{}, len(statuses))
+		for syncTarget, status := range statuses {
+			byTarget[syncTarget] = status
+		}
+		if values == nil {
+			values = []interface{}{}
+		}
+		out, _, err := f.program.Eval(map[string]interface{}{
+			ValuesVariable:   values,
+			StatusesVariable: byTarget,
+			TargetsVariable:  int64(targets),
+		})
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to evaluate %q: %w", f.expression, err)
+		}
+		switch v := out.Value().(type) {
+		case int64, float64, bool, string:
+			return v, true, nil
+		case uint64:
+			return int64(v), true, nil
+		default:
+			return nil, false, fmt.Errorf("%q evaluated to %s, not a number, boolean or string", f.expression, out.Type().TypeName())
+		}
+	}
+
+	if len(values) == 0 {
+		return nil, false, nil
+	}
+	var result interface{}
+	for _, v := range values {
+		switch v.(type) {
+		case int64, float64:
+		default:
+			return nil, false, fmt.Errorf("%v is not a number", v)
+		}
+		if result == nil {
+			result = v
+			continue
+		}
+		switch f.strategy {
+		case workloadv1alpha1.AggregationSum:
+			result = add(result, v)
+		case workloadv1alpha1.AggregationMin:
+			if less(v, result) {
+				result = v
+			}
+		case workloadv1alpha1.AggregationMax:
+			if less(result, v) {
+				result = v
+			}
+		}
+	}
+	return result, true, nil
+}
+
+func add(a, b interface{}) interface{} {
+	ai, aInt := a.(int64)
+	bi, bInt := b.(int64)
+	if aInt && bInt {
+		return ai + bi
+	}
+	return toFloat(a) + toFloat(b)
+}
+
+func less(a, b interface{}) bool {
+	return toFloat(a) < toFloat(b)
+}
+
+func toFloat(v interface{}) float64 {
+	if i, ok := v.(int64); ok {
+		return float64(i)
+	}
+	return v.(float64)
+}
+
+// worstPhase returns the worst phase of the SyncTargets, if any reports a
+// phase. SyncTargets that did not report a status are Pending.
+func worstPhase(statuses map[string]map[string]interface{}, syncTargets []string) (string, bool) {
+	worst, found := "", false
+	for _, syncTarget := range syncTargets {
+		phase := "Pending"
+		if status, reported := statuses[syncTarget]; reported {
+			p, ok := status["phase"].(string)
+			if !ok {
+				continue
+			}
+			found = true
+			phase = p
+		}
+		if worst == "" || severity(phase) > severity(worst) {
+			worst = phase
+		}
+	}
+	return worst, found
+}
+
+func severity(phase string) int {
+	if s, found := phaseSeverity[phase]; found {
+		return s
+	}
+	return phaseSeverity["Unknown"]
+}
+
+// conditionSeverity orders condition statuses from the best to the worst.
+var conditionSeverity = map[string]int{"True": 0, "Unknown": 1, "False": 2}
+
+// aggregateConditions returns a condition per type reported by any
+// SyncTarget, with the worst status of all SyncTargets, and the reason of
+// the first SyncTarget with that status. The message lists the status of
+// every SyncTarget.
+func aggregateConditions(current []interface{}, statuses map[string]map[string]interface{}, syncTargets []string, now time.Time) []interface{} {
+	byType := map[string]map[string]map[string]interface{}{}
+	for syncTarget, status := range statuses {
+		conditions, _, _ := unstructured.NestedSlice(status, "conditions")
+		for _, c := range conditions {
+			condition, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			conditionType, _ := condition["type"].(string)
+			if conditionType == "" {
+				continue
+			}
+			if byType[conditionType] == nil {
+				byType[conditionType] = map[string]map[string]interface{}{}
+			}
+			byType[conditionType][syncTarget] = condition
+		}
+	}
+	types := make([]string, 0, len(byType))
+	for conditionType := range byType {
+		types = append(types, conditionType)
+	}
+	sort.Strings(types)
+
+	currentByType := map[string]map[string]interface{}{}
+	for _, c := range current {
+		if condition, ok := c.(map[string]interface{}); ok {
+			if conditionType, _ := condition["type"].(string); conditionType != "" {
+				currentByType[conditionType] = condition
+			}
+		}
+	}
+
+	var aggregated []interface{}
+	for _, conditionType := range types {
+		status, reason := "", ""
+		messages := make([]string, 0, len(syncTargets))
+		for _, syncTarget := range syncTargets {
+			s, r, m := "Unknown", notReportedReason, "not reported"
+			if condition, found := byType[conditionType][syncTarget]; found {
+				s, _ = condition["status"].(string)
+				r, _ = condition["reason"].(string)
+				m, _ = condition["message"].(string)
+				if _, known := conditionSeverity[s]; !known {
+					s = "Unknown"
+				}
+			}
+			if status == "" || conditionSeverity[s] > conditionSeverity[status] {
+				status, reason = s, r
+			}
+			message := syncTarget + ": " + s
+			switch {
+			case r != "" && m != "":
+				message += " (" + r + ": " + m + ")"
+			case r != "":
+				message += " (" + r + ")"
+			}
+			messages = append(messages, message)
+		}
+
+		condition := map[string]interface{}{
+			"type":               conditionType,
+			"status":             status,
+			"message":            strings.Join(messages, "; "),
+			"lastTransitionTime": now.UTC().Format(time.RFC3339),
+		}
+		if reason != "" {
+			condition["reason"] = reason
+		}
+		if old, found := currentByType[conditionType]; found && old["status"] == status {
+			if t, ok := old["lastTransitionTime"].(string); ok {
+				condition["lastTransitionTime"] = t
+			}
+		}
+		aggregated = append(aggregated, condition)
+	}
+	return aggregated
+}
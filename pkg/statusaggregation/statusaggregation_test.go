@@ -0,0 +1,186 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statusaggregation
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var deployments = schema.GroupResource{Group: "apps", Resource: "deployments"}
+
+func TestTargetStatuses(t *testing.T) {
+	long := strings.Repeat("a", 100)
+	require.Equal(t, "status.workload.kcp.io/east", TargetAnnotation("east"))
+	require.LessOrEqual(t, len(strings.TrimPrefix(TargetAnnotation(long), workloadv1alpha1.AnnotationPrefixTargetStatus)), 63)
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAnnotations(map[string]string{
+		TargetAnnotation("east"): `{"readyReplicas":2,"ratio":0.5}`,
+		TargetAnnotation(long):   `{"readyReplicas":1}`,
+		TargetAnnotation("gone"): `{"readyReplicas":7}`,
+	})
+	statuses, err := TargetStatuses(obj, []string{"east", long, "west"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]map[string]interface{}{
+		"east": {"readyReplicas": int64(2), "ratio": 0.5},
+		long:   {"readyReplicas": int64(1)},
+	}, statuses)
+
+	obj.SetAnnotations(map[string]string{TargetAnnotation("east"): `{`})
+	_, err = TargetStatuses(obj, []string{"east"})
+	require.Error(t, err)
+}
+
+func TestAggregate(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	earlier := now.Add(-time.Hour).Format(time.RFC3339)
+	targets := []string{"east", "west", "north"}
+	statuses := map[string]map[string]interface{}{
+		"east": {
+			"replicas":      int64(3),
+			"readyReplicas": int64(3),
+			"phase":         "Running",
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Available", "status": "True", "reason": "MinimumReplicasAvailable"},
+				map[string]interface{}{"type": "Progressing", "status": "True", "reason": "NewReplicaSetAvailable"},
+			},
+		},
+		"west": {
+			"replicas":      int64(2),
+			"readyReplicas": int64(1),
+			"phase":         "Failed",
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Available", "status": "False", "reason": "MinimumReplicasUnavailable", "message": "1 of 2 ready"},
+				map[string]interface{}{"type": "Progressing", "status": "True", "reason": "NewReplicaSetAvailable"},
+			},
+		},
+	}
+	current := map[string]interface{}{
+		"conditions": []interface{}{
+			map[string]interface{}{"type": "Available", "status": "False", "lastTransitionTime": earlier},
+			map[string]interface{}{"type": "Progressing", "status": "True", "lastTransitionTime": earlier},
+		},
+	}
+
+	a, err := New(deployments, nil)
+	require.NoError(t, err)
+	status, err := a.Aggregate(current, statuses, targets, now)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"replicas":      int64(5),
+		"readyReplicas": int64(4),
+		"phase":         "Failed",
+		"conditions": []interface{}{
+			map[string]interface{}{
+				"type":               "Available",
+				"status":             "False",
+				"reason":             "MinimumReplicasUnavailable",
+				"message":            "east: True (MinimumReplicasAvailable); west: False (MinimumReplicasUnavailable: 1 of 2 ready); north: Unknown (NotReported: not reported)",
+				"lastTransitionTime": earlier,
+			},
+			map[string]interface{}{
+				"type":               "Progressing",
+				"status":             "Unknown",
+				"reason":             "NotReported",
+				"message":            "east: True (NewReplicaSetAvailable); west: True (NewReplicaSetAvailable); north: Unknown (NotReported: not reported)",
+				"lastTransitionTime": now.Format(time.RFC3339),
+			},
+		},
+	}, status)
+}
+
+func TestAggregateWithPolicies(t *testing.T) {
+	targets := []string{"east", "west"}
+	statuses := map[string]map[string]interface{}{
+		"east": {"readyReplicas": int64(3), "load": 0.5, "synced": true, "result": map[string]interface{}{"state": "Complete"}},
+		"west": {"readyReplicas": int64(1), "load": int64(2), "synced": true, "result": map[string]interface{}{"state": "Complete"}},
+	}
+	policies := []*workloadv1alpha1.StatusAggregationPolicy{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "b"},
+			Spec: workloadv1alpha1.StatusAggregationPolicySpec{
+				Resources: []string{"deployments.apps"},
+				Fields: []workloadv1alpha1.AggregatedField{
+					{Path: "readyReplicas", Strategy: workloadv1alpha1.AggregationMin},
+					{Path: "load", Strategy: workloadv1alpha1.AggregationMax},
+					{Path: "synced", Strategy: workloadv1alpha1.AggregationAllTrue},
+					{Path: "result.state", Strategy: workloadv1alpha1.AggregationCEL, Expression: "size(values) == targets && values.all(v, v == 'Complete') ? 'Complete' : 'Pending'"},
+					{Path: "reported", Strategy: workloadv1alpha1.AggregationCEL, Expression: "size(statuses)"},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "a"},
+			Spec: workloadv1alpha1.StatusAggregationPolicySpec{
+				Resources: []string{"deployments.apps"},
+				Fields: []workloadv1alpha1.AggregatedField{
+					{Path: "readyReplicas", Strategy: workloadv1alpha1.AggregationMax},
+				},
+			},
+		},
+	}
+	require.True(t, Selects(policies[0], deployments))
+	require.False(t, Selects(policies[0], schema.GroupResource{Group: "apps", Resource: "statefulsets"}))
+
+	a, err := New(deployments, policies)
+	require.NoError(t, err)
+	status, err := a.Aggregate(nil, statuses, targets, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"readyReplicas": int64(1),
+		"load":          int64(2),
+		"synced":        true,
+		"result":        map[string]interface{}{"state": "Complete"},
+		"reported":      int64(2),
+	}, status)
+
+	// A SyncTarget that did not report fails AllTrue.
+	status, err = a.Aggregate(nil, statuses, append(targets, "north"), time.Now())
+	require.NoError(t, err)
+	require.Equal(t, false, status["synced"])
+	require.Equal(t, map[string]interface{}{"state": "Pending"}, status["result"])
+
+	statuses["west"]["synced"] = "yes"
+	_, err = a.Aggregate(nil, statuses, targets, time.Now())
+	require.Error(t, err)
+}
+
+func TestNewRejectsInvalidFields(t *testing.T) {
+	for name, field := range map[string]workloadv1alpha1.AggregatedField{
+		"invalid expression": {Path: "x", Strategy: workloadv1alpha1.AggregationCEL, Expression: "values +"},
+		"unknown strategy":   {Path: "x", Strategy: "Average"},
+		"conditions":         {Path: "conditions", Strategy: workloadv1alpha1.AggregationSum},
+		"empty field name":   {Path: "a..b", Strategy: workloadv1alpha1.AggregationSum},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := New(deployments, []*workloadv1alpha1.StatusAggregationPolicy{{
+				Spec: workloadv1alpha1.StatusAggregationPolicySpec{Resources: []string{"deployments.apps"}, Fields: []workloadv1alpha1.AggregatedField{field}},
+			}})
+			require.Error(t, err)
+		})
+	}
+}
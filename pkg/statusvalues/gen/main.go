@@ -0,0 +1,281 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command gen generates the valid condition types and phases of the TMC API
+// kinds from the constants of their API packages.
+//
+// The condition types of a kind are the ConditionType constants in the
+// block documented "Conditions and ConditionReasons for the <Kind> object.",
+// its phases are the constants of the status fields whose type name ends in
+// Phase. Only kinds with a status subresource are included.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	modulePath = "github.com/kcp-dev/kcp"
+	version    = "v1alpha1"
+)
+
+// groups are the TMC API packages, relative to sdk/apis.
+var groups = []string{"placement", "tmc", "workload"}
+
+var (
+	conditionsDoc = regexp.MustCompile(`Conditions and ConditionReasons for the (\w+) object`)
+	groupNameTag  = regexp.MustCompile(`\+groupName=(\S+)`)
+)
+
+func main() {
+	root := flag.String("root", ".", "Root of the repository")
+	output := flag.String("output", "pkg/statusvalues/zz_generated.statusvalues.go", "File to write, relative to the root")
+	flag.Parse()
+
+	src, err := generate(*root)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(filepath.Join(*root, *output), src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+type kind struct {
+	name           string
+	conditionTypes []string
+	phases         []phase
+}
+
+type phase struct {
+	path   []string
+	values []string
+}
+
+type group struct {
+	dir, name, alias string
+	kinds            []kind
+}
+
+// generate returns the source of the registry of the API packages below root.
+func generate(root string) ([]byte, error) {
+	var gs []group
+	for _, dir := range groups {
+		g, err := parseGroup(root, dir)
+		if err != nil {
+			return nil, err
+		}
+		gs = append(gs, g)
+	}
+
+	boilerplate, err := os.ReadFile(filepath.Join(root, "hack", "boilerplate", "boilerplate.generatego.txt"))
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	b.Write(boilerplate)
+	b.WriteString("\n// Code generated by pkg/statusvalues/gen. DO NOT EDIT.\n\npackage statusvalues\n\n")
+	b.WriteString("import (\n")
+	b.WriteString("\"k8s.io/apimachinery/pkg/runtime/schema\"\n\n")
+	for _, g := range gs {
+		fmt.Fprintf(&b, "%s %q\n", g.alias, modulePath+"/sdk/apis/"+g.dir+"/"+version)
+	}
+	b.WriteString("conditionsv1alpha1 \"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1\"\n")
+	b.WriteString(")\n\n")
+	b.WriteString("// TMCKinds are the condition types and phases of the TMC API kinds with a\n// status subresource.\n")
+	b.WriteString("var TMCKinds = []Kind{\n")
+	for _, g := range gs {
+		for _, k := range g.kinds {
+			fmt.Fprintf(&b, "{\nGroupKind: schema.GroupKind{Group: %q, Kind: %q},\n", g.name, k.name)
+			if len(k.conditionTypes) > 0 {
+				b.WriteString("ConditionTypes: []conditionsv1alpha1.ConditionType{\n")
+				for _, c := range k.conditionTypes {
+					fmt.Fprintf(&b, "%s.%s,\n", g.alias, c)
+				}
+				b.WriteString("},\n")
+			}
+			if len(k.phases) > 0 {
+				b.WriteString("Phases: []Phase{\n")
+				for _, p := range k.phases {
+					fmt.Fprintf(&b, "{\nPath: %#v,\nValues: []string{\n", p.path)
+					for _, v := range p.values {
+						fmt.Fprintf(&b, "string(%s.%s),\n", g.alias, v)
+					}
+					b.WriteString("},\n},\n")
+				}
+				b.WriteString("},\n")
+			}
+			b.WriteString("},\n")
+		}
+	}
+	b.WriteString("}\n")
+
+	return format.Source(b.Bytes())
+}
+
+func parseGroup(root, dir string) (group, error) {
+	g := group{dir: dir, alias: dir + version}
+	pkgDir := filepath.Join(root, "sdk", "apis", dir, version)
+
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, pkgDir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go") && !strings.HasPrefix(fi.Name(), "zz_generated")
+	}, parser.ParseComments)
+	if err != nil {
+		return g, err
+	}
+	pkg, ok := pkgs[version]
+	if !ok {
+		return g, fmt.Errorf("no package %s in %s", version, pkgDir)
+	}
+
+	var (
+		structs   = map[string]*ast.StructType{}
+		withSub   []string
+		condTypes = map[string][]string{}
+		consts    = map[string][]string{}
+	)
+	// Walk the files sorted by name, so that constants are in a stable order.
+	files := make([]string, 0, len(pkg.Files))
+	for name := range pkg.Files {
+		files = append(files, name)
+	}
+	sort.Strings(files)
+	for _, name := range files {
+		f := pkg.Files[name]
+		if m := groupNameTag.FindStringSubmatch(f.Doc.Text()); m != nil {
+			g.name = m[1]
+		}
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			switch gd.Tok {
+			case token.TYPE:
+				for _, spec := range gd.Specs {
+					ts := spec.(*ast.TypeSpec)
+					st, ok := ts.Type.(*ast.StructType)
+					if !ok {
+						continue
+					}
+					structs[ts.Name.Name] = st
+					doc := ts.Doc
+					if doc == nil {
+						doc = gd.Doc
+					}
+					if doc != nil && strings.Contains(doc.Text(), "+kubebuilder:subresource:status") {
+						withSub = append(withSub, ts.Name.Name)
+					}
+				}
+			case token.CONST:
+				var kindName string
+				if m := conditionsDoc.FindStringSubmatch(gd.Doc.Text()); m != nil {
+					kindName = m[1]
+				}
+				for _, spec := range gd.Specs {
+					vs := spec.(*ast.ValueSpec)
+					switch t := vs.Type.(type) {
+					case *ast.SelectorExpr:
+						if kindName != "" && t.Sel.Name == "ConditionType" {
+							for _, n := range vs.Names {
+								condTypes[kindName] = append(condTypes[kindName], n.Name)
+							}
+						}
+					case *ast.Ident:
+						for _, n := range vs.Names {
+							consts[t.Name] = append(consts[t.Name], n.Name)
+						}
+					}
+				}
+			}
+		}
+	}
+	if g.name == "" {
+		return g, fmt.Errorf("no +groupName in %s", pkgDir)
+	}
+
+	sort.Strings(withSub)
+	for _, name := range withSub {
+		k := kind{name: name, conditionTypes: condTypes[name]}
+		for _, field := range structs[name].Fields.List {
+			if jsonName(field) == "status" {
+				k.phases = findPhases(structs, consts, field.Type, []string{"status"}, map[string]bool{})
+			}
+		}
+		if len(k.conditionTypes) == 0 && len(k.phases) == 0 {
+			continue
+		}
+		g.kinds = append(g.kinds, k)
+	}
+	return g, nil
+}
+
+// findPhases returns the phase fields below the type expression at path.
+// Items of lists are denoted by "[]" in the path.
+func findPhases(structs map[string]*ast.StructType, consts map[string][]string, expr ast.Expr, path []string, visiting map[string]bool) []phase {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return findPhases(structs, consts, t.X, path, visiting)
+	case *ast.ArrayType:
+		return findPhases(structs, consts, t.Elt, append(path[:len(path):len(path)], "[]"), visiting)
+	case *ast.Ident:
+		if strings.HasSuffix(t.Name, "Phase") && len(consts[t.Name]) > 0 {
+			return []phase{{path: append([]string(nil), path...), values: consts[t.Name]}}
+		}
+		st, ok := structs[t.Name]
+		if !ok || visiting[t.Name] {
+			return nil
+		}
+		visiting[t.Name] = true
+		defer delete(visiting, t.Name)
+
+		var phases []phase
+		for _, field := range st.Fields.List {
+			name := jsonName(field)
+			if name == "" || name == "-" {
+				continue
+			}
+			phases = append(phases, findPhases(structs, consts, field.Type, append(path[:len(path):len(path)], name), visiting)...)
+		}
+		return phases
+	}
+	return nil
+}
+
+func jsonName(field *ast.Field) string {
+	if field.Tag == nil || len(field.Names) == 0 {
+		return ""
+	}
+	tag := reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
+	name, _, _ := strings.Cut(tag.Get("json"), ",")
+	return name
+}
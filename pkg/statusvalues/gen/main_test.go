@@ -0,0 +1,35 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratedIsUpToDate(t *testing.T) {
+	root := filepath.Join("..", "..", "..")
+	src, err := generate(root)
+	require.NoError(t, err)
+
+	existing, err := os.ReadFile(filepath.Join(root, "pkg", "statusvalues", "zz_generated.statusvalues.go"))
+	require.NoError(t, err)
+	require.Equal(t, string(existing), string(src), "run hack/update-codegen-tmc.sh")
+}
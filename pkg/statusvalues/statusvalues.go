@@ -0,0 +1,211 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package statusvalues validates the conditions and phases written to the
+// status of TMC objects, so that a typo in a controller is rejected instead
+// of persisted. The valid values are generated from the constants of the
+// API packages by pkg/statusvalues/gen; run hack/update-codegen-tmc.sh after
+// adding a condition type or phase.
+package statusvalues
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+)
+
+// Kind lists the valid condition types and phases of an API kind.
+type Kind struct {
+	schema.GroupKind
+	// ConditionTypes are the types of status.conditions.
+	ConditionTypes []conditionsv1alpha1.ConditionType
+	// Phases are the phase fields of the status.
+	Phases []Phase
+}
+
+// Phase is a phase field and its valid values.
+type Phase struct {
+	// Path is the path of the field, "[]" standing for the items of a list,
+	// e.g. status, rollout, phase.
+	Path []string
+	// Values are the valid values of the field.
+	Values []string
+}
+
+var (
+	conditionStatuses   = []corev1.ConditionStatus{corev1.ConditionTrue, corev1.ConditionFalse, corev1.ConditionUnknown}
+	conditionSeverities = []conditionsv1alpha1.ConditionSeverity{
+		conditionsv1alpha1.ConditionSeverityNone,
+		conditionsv1alpha1.ConditionSeverityError,
+		conditionsv1alpha1.ConditionSeverityWarning,
+		conditionsv1alpha1.ConditionSeverityInfo,
+	}
+)
+
+// Default is the registry of TMCKinds.
+var Default = NewRegistry(TMCKinds...)
+
+// Registry looks up the valid status values of kinds.
+type Registry struct {
+	kinds map[schema.GroupKind]Kind
+}
+
+// NewRegistry returns a registry of the given kinds.
+func NewRegistry(kinds ...Kind) *Registry {
+	r := &Registry{kinds: make(map[schema.GroupKind]Kind, len(kinds))}
+	for _, k := range kinds {
+		r.kinds[k.GroupKind] = k
+	}
+	return r
+}
+
+// Has returns whether the registry validates the status of the kind.
+func (r *Registry) Has(gk schema.GroupKind) bool {
+	_, ok := r.kinds[gk]
+	return ok
+}
+
+// Validate validates the conditions and phases in the status of obj, an
+// object of the given kind. Conditions and phases unchanged from old, which
+// may be nil, are not validated, so that objects persisted before a value
+// was removed from the API can still be updated.
+func (r *Registry) Validate(gk schema.GroupKind, obj, old map[string]interface{}) field.ErrorList {
+	k, ok := r.kinds[gk]
+	if !ok {
+		return nil
+	}
+
+	var errs field.ErrorList
+	conditions, err := conditionsOf(obj)
+	if err != nil {
+		return field.ErrorList{field.Invalid(field.NewPath("status", "conditions"), nil, err.Error())}
+	}
+	// An invalid old object is validated as a whole.
+	oldConditions, _ := conditionsOf(old)
+	errs = append(errs, validateConditions(conditions, oldConditions, k.ConditionTypes, field.NewPath("status", "conditions"))...)
+
+	for _, p := range k.Phases {
+		oldValues := map[string]interface{}{}
+		for _, v := range values(old, p.Path, nil) {
+			oldValues[v.path.String()] = v.value
+		}
+		for _, v := range values(obj, p.Path, nil) {
+			if old, ok := oldValues[v.path.String()]; ok && old == v.value {
+				continue
+			}
+			if s, ok := v.value.(string); !ok || !slices.Contains(p.Values, s) {
+				errs = append(errs, field.NotSupported(v.path, v.value, p.Values))
+			}
+		}
+	}
+	return errs
+}
+
+// ValidateConditions validates that conditions have one of the given types
+// at most once, a valid status and severity, and a reason when false.
+func ValidateConditions(conditions conditionsv1alpha1.Conditions, types []conditionsv1alpha1.ConditionType, path *field.Path) field.ErrorList {
+	return validateConditions(conditions, nil, types, path)
+}
+
+func validateConditions(conditions, old conditionsv1alpha1.Conditions, types []conditionsv1alpha1.ConditionType, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	seen := sets.New[conditionsv1alpha1.ConditionType]()
+	for i, c := range conditions {
+		condPath := path.Index(i)
+		if seen.Has(c.Type) {
+			errs = append(errs, field.Duplicate(condPath.Child("type"), c.Type))
+		}
+		seen.Insert(c.Type)
+		if slices.ContainsFunc(old, func(o conditionsv1alpha1.Condition) bool { return reflect.DeepEqual(o, c) }) {
+			continue
+		}
+
+		switch {
+		case c.Type == "":
+			errs = append(errs, field.Required(condPath.Child("type"), ""))
+		case !slices.Contains(types, c.Type):
+			errs = append(errs, field.NotSupported(condPath.Child("type"), c.Type, types))
+		}
+		if !slices.Contains(conditionStatuses, c.Status) {
+			errs = append(errs, field.NotSupported(condPath.Child("status"), c.Status, conditionStatuses))
+		}
+		if !slices.Contains(conditionSeverities, c.Severity) {
+			errs = append(errs, field.NotSupported(condPath.Child("severity"), c.Severity, conditionSeverities))
+		}
+		if c.Status == corev1.ConditionFalse && c.Reason == "" {
+			errs = append(errs, field.Required(condPath.Child("reason"), "tell why the condition is false"))
+		}
+	}
+	return errs
+}
+
+func conditionsOf(obj map[string]interface{}) (conditionsv1alpha1.Conditions, error) {
+	raw, ok, err := unstructured.NestedFieldNoCopy(obj, "status", "conditions")
+	if err != nil || !ok {
+		return nil, err
+	}
+	var status struct {
+		Conditions conditionsv1alpha1.Conditions `json:"conditions"`
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(map[string]interface{}{"conditions": raw}, &status); err != nil {
+		return nil, fmt.Errorf("invalid conditions: %w", err)
+	}
+	return status.Conditions, nil
+}
+
+type value struct {
+	path  *field.Path
+	value interface{}
+}
+
+// values returns the values at path below obj, expanding lists.
+func values(obj interface{}, path []string, at *field.Path) []value {
+	if len(path) == 0 {
+		return []value{{path: at, value: obj}}
+	}
+	if path[0] == "[]" {
+		items, ok := obj.([]interface{})
+		if !ok {
+			return nil
+		}
+		var vs []value
+		for i, item := range items {
+			vs = append(vs, values(item, path[1:], at.Index(i))...)
+		}
+		return vs
+	}
+	m, ok := obj.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	child, ok := m[path[0]]
+	if !ok || child == nil {
+		return nil
+	}
+	if at == nil {
+		return values(child, path[1:], field.NewPath(path[0]))
+	}
+	return values(child, path[1:], at.Child(path[0]))
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statusvalues
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+)
+
+func TestValidate(t *testing.T) {
+	policy := placementv1alpha1.Kind("PlacementPolicy")
+	condition := func(typ, status, reason string) map[string]interface{} {
+		c := map[string]interface{}{"type": typ, "status": status, "lastTransitionTime": "2025-01-01T00:00:00Z"}
+		if reason != "" {
+			c["reason"] = reason
+		}
+		return c
+	}
+	status := func(phase string, conditions ...interface{}) map[string]interface{} {
+		s := map[string]interface{}{"conditions": conditions}
+		if phase != "" {
+			s["rollout"] = map[string]interface{}{"revision": "r2", "phase": phase}
+		}
+		return map[string]interface{}{"status": s}
+	}
+
+	tests := map[string]struct {
+		obj, old map[string]interface{}
+		wantErrs []string
+	}{
+		"valid": {
+			obj: status("Promoted", condition("RolloutHealthy", "False", "RolledBack")),
+		},
+		"no status": {
+			obj: map[string]interface{}{},
+		},
+		"unknown phase": {
+			obj:      status("Promotd"),
+			wantErrs: []string{`status.rollout.phase: Unsupported value: "Promotd": supported values: "Progressing", "Promoted", "RolledBack", "AwaitingApproval"`},
+		},
+		"unknown condition type": {
+			obj:      status("", condition("RolloutHealty", "True", "")),
+			wantErrs: []string{`status.conditions[0].type: Unsupported value: "RolloutHealty": supported values: "RolloutHealthy"`},
+		},
+		"malformed condition": {
+			obj: status("", condition("RolloutHealthy", "false", ""), map[string]interface{}{"type": "RolloutHealthy", "status": "False", "severity": "Fatal"}),
+			wantErrs: []string{
+				`status.conditions[0].status: Unsupported value: "false": supported values: "True", "False", "Unknown"`,
+				`status.conditions[1].type: Duplicate value: "RolloutHealthy"`,
+				`status.conditions[1].severity: Unsupported value: "Fatal": supported values: "", "Error", "Warning", "Info"`,
+				`status.conditions[1].reason: Required value: tell why the condition is false`,
+			},
+		},
+		"unchanged invalid values are kept": {
+			obj: status("Aborted", condition("Legacy", "True", ""), condition("RolloutHealthy", "True", "")),
+			old: status("Aborted", condition("Legacy", "True", "")),
+		},
+		"changed invalid values": {
+			obj: status("Aborted", condition("Legacy", "False", "")),
+			old: status("Progressing", condition("Legacy", "True", "")),
+			wantErrs: []string{
+				`status.conditions[0].type: Unsupported value: "Legacy": supported values: "RolloutHealthy"`,
+				`status.conditions[0].reason: Required value: tell why the condition is false`,
+				`status.rollout.phase: Unsupported value: "Aborted": supported values: "Progressing", "Promoted", "RolledBack", "AwaitingApproval"`,
+			},
+		},
+		"conditions that are not a list": {
+			obj:      map[string]interface{}{"status": map[string]interface{}{"conditions": "Ready"}},
+			wantErrs: []string{`status.conditions: Invalid value: "null": invalid conditions: `},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			errs := Default.Validate(policy, tc.obj, tc.old)
+			require.Len(t, errs, len(tc.wantErrs), "%v", errs)
+			for i, err := range errs {
+				require.Contains(t, err.Error(), tc.wantErrs[i])
+			}
+		})
+	}
+}
+
+func TestValidateUnknownKind(t *testing.T) {
+	require.False(t, Default.Has(placementv1alpha1.Kind("SchedulingProfile")))
+	require.Empty(t, Default.Validate(placementv1alpha1.Kind("SchedulingProfile"), map[string]interface{}{
+		"status": map[string]interface{}{"conditions": []interface{}{map[string]interface{}{"type": "Anything"}}},
+	}, nil))
+}
+
+func TestValues(t *testing.T) {
+	obj := map[string]interface{}{"status": map[string]interface{}{"targets": []interface{}{
+		map[string]interface{}{"phase": "A"},
+		map[string]interface{}{},
+		map[string]interface{}{"phase": "B"},
+	}}}
+	vs := values(obj, []string{"status", "targets", "[]", "phase"}, nil)
+	require.Len(t, vs, 2)
+	require.Equal(t, "status.targets[0].phase", vs[0].path.String())
+	require.Equal(t, "A", vs[0].value)
+	require.Equal(t, "status.targets[2].phase", vs[1].path.String())
+	require.Equal(t, "B", vs[1].value)
+}
@@ -0,0 +1,379 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package admissionfeedback surfaces admission webhook denials of the
+// physical cluster on the upstream objects in kcp. When a webhook rejects a
+// synced object, the upstream object gets a SyncFailed condition and a
+// Warning event carrying the message of the webhook verbatim. Writes that
+// failed because a webhook could not be reached are retried with backoff.
+package admissionfeedback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/tmc/retry"
+)
+
+const (
+	// ComponentName is the source component of the events.
+	ComponentName = "kcp-syncer"
+
+	// SyncFailed is the condition type set on upstream objects that the
+	// physical cluster refuses.
+	SyncFailed = "SyncFailed"
+
+	// AdmissionDeniedReason is the reason of the condition and events when
+	// an admission webhook denied the object.
+	AdmissionDeniedReason = "AdmissionDenied"
+	// WebhookUnavailableReason is the reason of the condition and events
+	// when an admission webhook could not be called and the retries are
+	// exhausted.
+	WebhookUnavailableReason = "WebhookUnavailable"
+	// SyncedReason is the reason of the condition once the object is synced
+	// again.
+	SyncedReason = "Synced"
+
+	defaultFieldManager = "kcp-syncer-admission-feedback"
+	defaultMaxRetries   = 5
+	defaultBackoff      = 5 * time.Second
+	defaultMaxBackoff   = 5 * time.Minute
+)
+
+var eventsGVR = schema.GroupVersionResource{Version: "v1", Resource: "events"}
+
+var (
+	// deniedPattern matches the message of the API server when a webhook
+	// denies a request, e.g. `admission webhook "policy.example.com" denied
+	// the request: replicas must be at most 3`.
+	deniedPattern = regexp.MustCompile(`admission webhook "([^"]+)" denied the request(?::\s*(.*))?`)
+	// unavailablePattern matches the message of the API server when a
+	// webhook failing closed could not be called.
+	unavailablePattern = regexp.MustCompile(`failed calling webhook "([^"]+)"`)
+)
+
+// ErrDenied is matched by errors.Is for Denials.
+var ErrDenied = errors.New("admission denied")
+
+// Denial is a rejection of an object by an admission webhook of the
+// physical cluster.
+type Denial struct {
+	// Webhook is the name of the webhook.
+	Webhook string
+	// Message is the message of the webhook, verbatim.
+	Message string
+}
+
+func (d *Denial) Error() string {
+	return fmt.Sprintf("admission webhook %q denied the request: %s", d.Webhook, d.Message)
+}
+
+// Is makes errors.Is(err, ErrDenied) work.
+func (d *Denial) Is(target error) bool {
+	return target == ErrDenied
+}
+
+// Classification is the kind of a downstream write error.
+type Classification int
+
+const (
+	// Other is an error not caused by admission webhooks.
+	Other Classification = iota
+	// Denied is a webhook denying the object. Retrying does not help
+	// until the object changes.
+	Denied
+	// Unavailable is a webhook that could not be called. Retrying may
+	// help.
+	Unavailable
+)
+
+// Classify returns the kind of err, and for denials the denial.
+func Classify(err error) (Classification, *Denial) {
+	if err == nil {
+		return Other, nil
+	}
+	var denial *Denial
+	if errors.As(err, &denial) {
+		return Denied, denial
+	}
+	var status apierrors.APIStatus
+	if !errors.As(err, &status) {
+		return Other, nil
+	}
+	message := status.Status().Message
+	if match := deniedPattern.FindStringSubmatch(message); match != nil {
+		return Denied, &Denial{Webhook: match[1], Message: match[2]}
+	}
+	if unavailablePattern.MatchString(message) {
+		return Unavailable, nil
+	}
+	return Other, nil
+}
+
+// RetryPolicy configures retries of writes that failed because a webhook
+// was unavailable. Zero values mean the defaults.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries before the outage is reported
+	// upstream. Retries continue after that with MaxBackoff.
+	MaxRetries int
+	// Backoff is the delay before the first retry. It doubles with every
+	// retry.
+	Backoff time.Duration
+	// MaxBackoff bounds the delay.
+	MaxBackoff time.Duration
+}
+
+// Result tells the caller whether to retry the write.
+type Result struct {
+	// RequeueAfter is the delay after which the write should be retried,
+	// or zero if it should not be retried until the object changes.
+	RequeueAfter time.Duration
+}
+
+// ApplyStatusFunc applies the status of obj in the given logical cluster.
+type ApplyStatusFunc func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error
+
+// CreateEventFunc creates event in the given logical cluster.
+type CreateEventFunc func(ctx context.Context, clusterName logicalcluster.Name, event *corev1.Event) error
+
+type key struct {
+	clusterName logicalcluster.Name
+	gvr         schema.GroupVersionResource
+	namespace   string
+	name        string
+}
+
+type state struct {
+	retries int
+	// reported is the reason of the SyncFailed condition set upstream, if
+	// any.
+	reported string
+	// message is the last reported message, to not repeat events.
+	message string
+}
+
+// Reporter reports the results of downstream writes on the upstream
+// objects.
+type Reporter struct {
+	policy      RetryPolicy
+	applyStatus ApplyStatusFunc
+	createEvent CreateEventFunc
+	now         func() time.Time
+
+	lock   sync.Mutex
+	states map[key]*state
+}
+
+// NewReporter returns a reporter writing conditions and events with the
+// given kcp client.
+func NewReporter(client kcpdynamic.ClusterInterface, policy RetryPolicy) *Reporter {
+	return newReporter(
+		func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+			_, err := client.Cluster(clusterName.Path()).Resource(gvr).Namespace(obj.GetNamespace()).ApplyStatus(ctx, obj.GetName(), obj, metav1.ApplyOptions{
+				FieldManager: defaultFieldManager,
+				Force:        true,
+			})
+			return err
+		},
+		func(ctx context.Context, clusterName logicalcluster.Name, event *corev1.Event) error {
+			raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(event)
+			if err != nil {
+				return err
+			}
+			u := &unstructured.Unstructured{Object: raw}
+			u.SetAPIVersion("v1")
+			u.SetKind("Event")
+			_, err = client.Cluster(clusterName.Path()).Resource(eventsGVR).Namespace(event.Namespace).Create(ctx, u, metav1.CreateOptions{})
+			return err
+		},
+		policy,
+	)
+}
+
+func newReporter(applyStatus ApplyStatusFunc, createEvent CreateEventFunc, policy RetryPolicy) *Reporter {
+	if policy.MaxRetries <= 0 {
+		policy.MaxRetries = defaultMaxRetries
+	}
+	if policy.Backoff <= 0 {
+		policy.Backoff = defaultBackoff
+	}
+	if policy.MaxBackoff < policy.Backoff {
+		policy.MaxBackoff = max(defaultMaxBackoff, policy.Backoff)
+	}
+	return &Reporter{
+		policy:      policy,
+		applyStatus: applyStatus,
+		createEvent: createEvent,
+		now:         time.Now,
+		states:      map[key]*state{},
+	}
+}
+
+// Report handles the result writeErr of writing the upstream object to the
+// physical cluster. Webhook denials are reported upstream right away.
+// Writes failing because of webhook outages are to be retried after the
+// returned delay, and are reported once the retries are exhausted. After
+// a successful write, a reported condition is cleared.
+//
+// The returned error is about writing the report, not about writeErr.
+func (r *Reporter) Report(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, upstream *unstructured.Unstructured, writeErr error) (Result, error) {
+	k := key{clusterName: clusterName, gvr: gvr, namespace: upstream.GetNamespace(), name: upstream.GetName()}
+	classification, denial := Classify(writeErr)
+
+	r.lock.Lock()
+	s := r.states[k]
+	if s == nil {
+		s = &state{}
+	}
+	var result Result
+	var reason, message string
+	switch {
+	case writeErr == nil:
+		if s.reported == "" {
+			delete(r.states, k)
+			r.lock.Unlock()
+			return Result{}, nil
+		}
+		reason = SyncedReason
+	case classification == Denied:
+		s.retries = 0
+		reason, message = AdmissionDeniedReason, denial.Error()
+	case classification == Unavailable:
+		s.retries++
+		result.RequeueAfter = r.backoff(s.retries)
+		if s.retries <= r.policy.MaxRetries {
+			r.states[k] = s
+			r.lock.Unlock()
+			return result, nil
+		}
+		reason, message = WebhookUnavailableReason, writeErr.Error()
+	default:
+		// Not ours to report.
+		r.lock.Unlock()
+		return Result{}, nil
+	}
+	if s.reported == reason && s.message == message {
+		r.states[k] = s
+		r.lock.Unlock()
+		return result, nil
+	}
+	r.lock.Unlock()
+
+	logger := klog.FromContext(ctx).WithValues("cluster", clusterName, "resource", gvr.String(), "namespace", k.namespace, "name", k.name)
+	if err := r.applyStatus(ctx, clusterName, gvr, r.condition(upstream, reason, message)); err != nil {
+		return result, err
+	}
+	if reason != SyncedReason {
+		logger.V(2).Info("physical cluster refused object", "reason", reason, "message", message)
+		if err := r.createEvent(ctx, clusterName, r.newEvent(upstream, reason, message)); err != nil {
+			// Events are best effort, the condition is set.
+			logger.V(2).Info("failed to create event", "err", err)
+		}
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if reason == SyncedReason {
+		delete(r.states, k)
+	} else {
+		s.reported, s.message = reason, message
+		r.states[k] = s
+	}
+	return result, nil
+}
+
+// Forget drops the state of a deleted upstream object.
+func (r *Reporter) Forget(clusterName logicalcluster.Name, gvr schema.GroupVersionResource, namespace, name string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.states, key{clusterName: clusterName, gvr: gvr, namespace: namespace, name: name})
+}
+
+func (r *Reporter) backoff(retries int) time.Duration {
+	return retry.Strategy{InitialBackoff: r.policy.Backoff, MaxBackoff: r.policy.MaxBackoff, Factor: 2}.Backoff(retries)
+}
+
+// condition returns the apply configuration of the SyncFailed condition of
+// upstream. The condition is owned by its own field manager, so it is kept
+// when the syncer writes the downstream status.
+func (r *Reporter) condition(upstream *unstructured.Unstructured, reason, message string) *unstructured.Unstructured {
+	status := metav1.ConditionTrue
+	if reason == SyncedReason {
+		status = metav1.ConditionFalse
+	}
+	condition := map[string]interface{}{
+		"type":               SyncFailed,
+		"status":             string(status),
+		"reason":             reason,
+		"message":            message,
+		"lastTransitionTime": metav1.NewTime(r.now()).UTC().Format(time.RFC3339),
+	}
+	applied := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"conditions": []interface{}{condition},
+		},
+	}}
+	applied.SetAPIVersion(upstream.GetAPIVersion())
+	applied.SetKind(upstream.GetKind())
+	applied.SetNamespace(upstream.GetNamespace())
+	applied.SetName(upstream.GetName())
+	return applied
+}
+
+func (r *Reporter) newEvent(upstream *unstructured.Unstructured, reason, message string) *corev1.Event {
+	now := metav1.NewTime(r.now())
+	namespace := upstream.GetNamespace()
+	if namespace == "" {
+		// Events of cluster-scoped objects go to the default namespace.
+		namespace = metav1.NamespaceDefault
+	}
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      fmt.Sprintf("%s.%x", upstream.GetName(), now.UnixNano()),
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion:      upstream.GetAPIVersion(),
+			Kind:            upstream.GetKind(),
+			Namespace:       upstream.GetNamespace(),
+			Name:            upstream.GetName(),
+			UID:             upstream.GetUID(),
+			ResourceVersion: upstream.GetResourceVersion(),
+		},
+		Type:           corev1.EventTypeWarning,
+		Reason:         reason,
+		Message:        message,
+		Source:         corev1.EventSource{Component: ComponentName},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+}
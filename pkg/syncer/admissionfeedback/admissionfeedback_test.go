@@ -0,0 +1,168 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admissionfeedback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+)
+
+var deploymentsGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+func denied() error {
+	return apierrors.NewForbidden(deploymentsGVR.GroupResource(), "web", errors.New(`admission webhook "replicas.policy.example.com" denied the request: replicas must be at most 3`))
+}
+
+func unavailable() error {
+	return apierrors.NewInternalError(errors.New(`Internal error occurred: failed calling webhook "replicas.policy.example.com": failed to call webhook: context deadline exceeded`))
+}
+
+func TestClassify(t *testing.T) {
+	classification, denial := Classify(denied())
+	require.Equal(t, Denied, classification)
+	require.Equal(t, &Denial{Webhook: "replicas.policy.example.com", Message: "replicas must be at most 3"}, denial)
+	require.ErrorIs(t, denial, ErrDenied)
+
+	classification, _ = Classify(fmt.Errorf("creating: %w", denied()))
+	require.Equal(t, Denied, classification, "wrapped errors")
+
+	classification, _ = Classify(unavailable())
+	require.Equal(t, Unavailable, classification)
+
+	classification, _ = Classify(apierrors.NewConflict(deploymentsGVR.GroupResource(), "web", errors.New("conflict")))
+	require.Equal(t, Other, classification)
+	classification, _ = Classify(errors.New("connection refused"))
+	require.Equal(t, Other, classification)
+}
+
+type fake struct {
+	applied []*unstructured.Unstructured
+	events  []*corev1.Event
+}
+
+func newFakeReporter(policy RetryPolicy) (*Reporter, *fake) {
+	f := &fake{}
+	r := newReporter(
+		func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+			f.applied = append(f.applied, obj)
+			return nil
+		},
+		func(ctx context.Context, clusterName logicalcluster.Name, event *corev1.Event) error {
+			f.events = append(f.events, event)
+			return nil
+		},
+		policy,
+	)
+	r.now = func() time.Time { return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC) }
+	return r, f
+}
+
+func condition(t *testing.T, obj *unstructured.Unstructured) map[string]interface{} {
+	t.Helper()
+	conditions, found, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Len(t, conditions, 1)
+	return conditions[0].(map[string]interface{})
+}
+
+func upstream() *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("apps/v1")
+	u.SetKind("Deployment")
+	u.SetNamespace("default")
+	u.SetName("web")
+	u.SetUID("uid")
+	return u
+}
+
+func TestReportDenial(t *testing.T) {
+	ctx := context.Background()
+	clusterName := logicalcluster.Name("root")
+	r, f := newFakeReporter(RetryPolicy{})
+
+	result, err := r.Report(ctx, clusterName, deploymentsGVR, upstream(), denied())
+	require.NoError(t, err)
+	require.Zero(t, result.RequeueAfter, "denials are not retried")
+	require.Len(t, f.applied, 1)
+	c := condition(t, f.applied[0])
+	require.Equal(t, SyncFailed, c["type"])
+	require.Equal(t, "True", c["status"])
+	require.Equal(t, AdmissionDeniedReason, c["reason"])
+	require.Contains(t, c["message"], "replicas must be at most 3")
+	require.Len(t, f.events, 1)
+	require.Equal(t, corev1.EventTypeWarning, f.events[0].Type)
+	require.Equal(t, "web", f.events[0].InvolvedObject.Name)
+	require.Contains(t, f.events[0].Message, "replicas must be at most 3")
+
+	// The same denial again is not reported twice.
+	_, err = r.Report(ctx, clusterName, deploymentsGVR, upstream(), denied())
+	require.NoError(t, err)
+	require.Len(t, f.applied, 1)
+	require.Len(t, f.events, 1)
+
+	// Success clears the condition.
+	_, err = r.Report(ctx, clusterName, deploymentsGVR, upstream(), nil)
+	require.NoError(t, err)
+	require.Len(t, f.applied, 2)
+	c = condition(t, f.applied[1])
+	require.Equal(t, "False", c["status"])
+	require.Equal(t, SyncedReason, c["reason"])
+	require.Len(t, f.events, 1)
+
+	// Further successes are not reported.
+	_, err = r.Report(ctx, clusterName, deploymentsGVR, upstream(), nil)
+	require.NoError(t, err)
+	require.Len(t, f.applied, 2)
+}
+
+func TestReportUnavailable(t *testing.T) {
+	ctx := context.Background()
+	clusterName := logicalcluster.Name("root")
+	r, f := newFakeReporter(RetryPolicy{MaxRetries: 2, Backoff: time.Second, MaxBackoff: 3 * time.Second})
+
+	var delays []time.Duration
+	for range 4 {
+		result, err := r.Report(ctx, clusterName, deploymentsGVR, upstream(), unavailable())
+		require.NoError(t, err)
+		delays = append(delays, result.RequeueAfter)
+	}
+	require.Equal(t, []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}, delays)
+
+	// Reported once the retries are exhausted, and only once.
+	require.Len(t, f.applied, 1)
+	c := condition(t, f.applied[0])
+	require.Equal(t, WebhookUnavailableReason, c["reason"])
+	require.Len(t, f.events, 1)
+
+	// Other errors are not reported.
+	_, err := r.Report(ctx, clusterName, deploymentsGVR, upstream(), errors.New("connection refused"))
+	require.NoError(t, err)
+	require.Len(t, f.applied, 1)
+}
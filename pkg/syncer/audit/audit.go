@@ -0,0 +1,467 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package audit records every mutation the syncer performs on the physical
+// cluster, for compliance: who performed it, what was changed, as a JSON
+// merge patch against the previous state of the object, and when. Events
+// are appended to a local log file that is rotated by size, can optionally
+// be shipped to an upstream collector, and can be queried through the
+// diagnostics endpoint. Lines of the log file can be encrypted, and are
+// then base64 encoded.
+//
+// Diffs of objects marked sensitive are only written to encrypted log files
+// and never shipped. Decryptions of sensitive objects are recorded too, so
+// that every access to their plaintext is audited.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/spf13/pflag"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/pkg/crypto/encryption"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+const (
+	defaultMaxSizeMB    = 100
+	defaultMaxBackups   = 5
+	defaultShipInterval = 10 * time.Second
+	defaultQueryLimit   = 100
+)
+
+// Verb is the kind of a mutation.
+type Verb string
+
+const (
+	VerbCreate Verb = "create"
+	VerbUpdate Verb = "update"
+	VerbPatch  Verb = "patch"
+	VerbDelete Verb = "delete"
+	// VerbDecrypt records the decryption of a sensitive object before it
+	// is applied.
+	VerbDecrypt Verb = "decrypt"
+)
+
+// Options configure the audit log.
+type Options struct {
+	// Path is the file events are appended to. Auditing is disabled if
+	// empty.
+	Path string
+	// MaxSizeMB is the size at which the file is rotated.
+	MaxSizeMB int
+	// MaxBackups is the number of rotated files kept.
+	MaxBackups int
+	// ShipURL is an upstream collector events are POSTed to in batches, as
+	// a JSON array. Events are only kept locally if empty.
+	ShipURL string
+	// ShipInterval is the time between two batches.
+	ShipInterval time.Duration
+	// EncryptionConfig is the file configuring the keys the log file is
+	// encrypted with, see encryption.Config. Not encrypted if empty.
+	EncryptionConfig string
+}
+
+// NewOptions returns options with auditing disabled.
+func NewOptions() *Options {
+	return &Options{
+		MaxSizeMB:    defaultMaxSizeMB,
+		MaxBackups:   defaultMaxBackups,
+		ShipInterval: defaultShipInterval,
+	}
+}
+
+// AddFlags adds the audit flags to the flag set.
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.Path, "mutation-audit-log-path", o.Path, "File recording every mutation of the syncer on the physical cluster, one JSON event per line. Disabled if empty.")
+	fs.IntVar(&o.MaxSizeMB, "mutation-audit-log-maxsize", o.MaxSizeMB, "Size in megabytes at which the mutation audit log is rotated.")
+	fs.IntVar(&o.MaxBackups, "mutation-audit-log-maxbackup", o.MaxBackups, "Number of rotated mutation audit log files to keep.")
+	fs.StringVar(&o.ShipURL, "mutation-audit-ship-url", o.ShipURL, "URL of an upstream collector that mutation audit events are POSTed to in batches. Events are only kept locally if empty.")
+	fs.DurationVar(&o.ShipInterval, "mutation-audit-ship-interval", o.ShipInterval, "Time between two batches of mutation audit events shipped upstream.")
+	fs.StringVar(&o.EncryptionConfig, "mutation-audit-log-encryption-config", o.EncryptionConfig, "File configuring the keys the mutation audit log is encrypted with. The first key encrypts, all keys decrypt. Not encrypted if empty.")
+}
+
+// Validate validates the options.
+func (o *Options) Validate() error {
+	if o.Path == "" {
+		if o.ShipURL != "" {
+			return fmt.Errorf("--mutation-audit-ship-url requires --mutation-audit-log-path")
+		}
+		if o.EncryptionConfig != "" {
+			return fmt.Errorf("--mutation-audit-log-encryption-config requires --mutation-audit-log-path")
+		}
+		return nil
+	}
+	if o.MaxSizeMB <= 0 || o.MaxBackups < 0 {
+		return fmt.Errorf("--mutation-audit-log-maxsize must be positive and --mutation-audit-log-maxbackup must not be negative")
+	}
+	if o.ShipURL != "" {
+		if u, err := url.Parse(o.ShipURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("--mutation-audit-ship-url must be an http or https URL")
+		}
+		if o.ShipInterval <= 0 {
+			return fmt.Errorf("--mutation-audit-ship-interval must be positive")
+		}
+	}
+	return nil
+}
+
+// Event is an audited mutation.
+type Event struct {
+	Time time.Time `json:"time"`
+	// SyncTarget is the target whose syncer performed the mutation.
+	SyncTarget string `json:"syncTarget"`
+	// Controller is the syncer controller that performed it.
+	Controller string `json:"controller"`
+	Verb       Verb   `json:"verb"`
+	Group      string `json:"group,omitempty"`
+	Version    string `json:"version"`
+	Resource   string `json:"resource"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	// Diff is a JSON merge patch from the previous to the new state of the
+	// object, the whole object for creations, and empty for deletions.
+	Diff json.RawMessage `json:"diff,omitempty"`
+	// Redacted is set if the diff was left out because the object is
+	// sensitive.
+	Redacted bool `json:"redacted,omitempty"`
+}
+
+// Mutation is a change made by a syncer controller.
+type Mutation struct {
+	Controller string
+	Verb       Verb
+	Resource   schema.GroupVersionResource
+	// Old is the object before the mutation, nil for creations.
+	Old *unstructured.Unstructured
+	// New is the object after the mutation, nil for deletions.
+	New *unstructured.Unstructured
+}
+
+// Log records mutations. A nil *Log records nothing, so callers do not need
+// to check whether auditing is enabled.
+type Log struct {
+	syncTarget string
+	*sink
+}
+
+// sink is where the events of a log and of the logs of other SyncTargets
+// derived from it go, see Log.ForSyncTarget.
+type sink struct {
+	now func() time.Time
+
+	lock sync.Mutex
+	file *rotatingFile
+	// keyring encrypts the lines of the file, if set.
+	keyring *encryption.Keyring
+
+	shipper *shipper
+}
+
+// NewLog opens the audit log of the SyncTarget. It returns nil if auditing
+// is disabled.
+func NewLog(options *Options, syncTarget string) (*Log, error) {
+	if options.Path == "" {
+		return nil, nil
+	}
+	var keyring *encryption.Keyring
+	if options.EncryptionConfig != "" {
+		var err error
+		if keyring, err = encryption.LoadKeyring(options.EncryptionConfig); err != nil {
+			return nil, err
+		}
+	}
+	file, err := openRotatingFile(options.Path, int64(options.MaxSizeMB)*1024*1024, options.MaxBackups)
+	if err != nil {
+		return nil, err
+	}
+	l := &Log{syncTarget: syncTarget, sink: &sink{now: time.Now, file: file, keyring: keyring}}
+	if options.ShipURL != "" {
+		l.shipper = newShipper(options.ShipURL, options.ShipInterval)
+	}
+	return l, nil
+}
+
+// ForSyncTarget returns a log recording the mutations of the syncer of
+// another SyncTarget into the same file and collector, e.g. for the
+// syncers of all SyncTargets of a process.
+func (l *Log) ForSyncTarget(syncTarget string) *Log {
+	if l == nil {
+		return nil
+	}
+	return &Log{syncTarget: syncTarget, sink: l.sink}
+}
+
+// Run ships events upstream until ctx is done, if shipping is configured.
+func (l *Log) Run(ctx context.Context) {
+	if l == nil || l.shipper == nil {
+		return
+	}
+	l.shipper.run(ctx)
+}
+
+// Record appends the mutation to the log. Failing to do so is logged but
+// does not fail the mutation, which already happened.
+func (l *Log) Record(ctx context.Context, m Mutation) {
+	if l == nil {
+		return
+	}
+	event, err := l.event(m)
+	if err != nil {
+		klog.FromContext(ctx).Error(err, "failed to compute the diff of a mutation for the audit log", "resource", m.Resource)
+	}
+	l.write(ctx, event, sensitive(m.Old) || sensitive(m.New))
+}
+
+// RecordDecryption appends the decryption of a sensitive object by a syncer
+// controller to the log.
+func (l *Log) RecordDecryption(ctx context.Context, controller string, resource schema.GroupVersionResource, namespace, name string) {
+	if l == nil {
+		return
+	}
+	l.write(ctx, Event{
+		Time:       l.now(),
+		SyncTarget: l.syncTarget,
+		Controller: controller,
+		Verb:       VerbDecrypt,
+		Group:      resource.Group,
+		Version:    resource.Version,
+		Resource:   resource.Resource,
+		Namespace:  namespace,
+		Name:       name,
+	}, false)
+}
+
+// write appends event to the log file and ships it. The diff of sensitive
+// objects is redacted unless the file is encrypted, and always when
+// shipped.
+func (l *Log) write(ctx context.Context, event Event, sensitive bool) {
+	redacted := event
+	if sensitive && event.Diff != nil {
+		redacted.Diff = nil
+		redacted.Redacted = true
+	}
+	if l.keyring == nil {
+		event = redacted
+	}
+	line, err := json.Marshal(event)
+	if err != nil {
+		klog.FromContext(ctx).Error(err, "failed to encode audit event")
+		return
+	}
+	if l.keyring != nil {
+		encrypted, err := l.keyring.Encrypt(ctx, line)
+		if err != nil {
+			klog.FromContext(ctx).Error(err, "failed to encrypt audit event")
+			return
+		}
+		line = []byte(base64.StdEncoding.EncodeToString(encrypted))
+	}
+
+	l.lock.Lock()
+	err = l.file.writeLine(line)
+	l.lock.Unlock()
+	if err != nil {
+		klog.FromContext(ctx).Error(err, "failed to write audit event")
+	}
+	if l.shipper != nil {
+		l.shipper.add(redacted)
+	}
+}
+
+// sensitive returns whether obj is marked sensitive.
+func sensitive(obj *unstructured.Unstructured) bool {
+	return obj != nil && obj.GetAnnotations()[workloadv1alpha1.AnnotationSensitive] == "true"
+}
+
+func (l *Log) event(m Mutation) (Event, error) {
+	event := Event{
+		Time:       l.now(),
+		SyncTarget: l.syncTarget,
+		Controller: m.Controller,
+		Verb:       m.Verb,
+		Group:      m.Resource.Group,
+		Version:    m.Resource.Version,
+		Resource:   m.Resource.Resource,
+	}
+	obj := m.New
+	if obj == nil {
+		obj = m.Old
+	}
+	if obj != nil {
+		event.Namespace = obj.GetNamespace()
+		event.Name = obj.GetName()
+	}
+	var err error
+	event.Diff, err = diff(m.Old, m.New)
+	return event, err
+}
+
+// diff returns a merge patch from old to new, ignoring managed fields.
+func diff(old, new *unstructured.Unstructured) (json.RawMessage, error) {
+	if new == nil {
+		return nil, nil
+	}
+	newJSON, err := json.Marshal(withoutManagedFields(new))
+	if err != nil {
+		return nil, err
+	}
+	if old == nil {
+		return newJSON, nil
+	}
+	oldJSON, err := json.Marshal(withoutManagedFields(old))
+	if err != nil {
+		return nil, err
+	}
+	return jsonpatch.CreateMergePatch(oldJSON, newJSON)
+}
+
+func withoutManagedFields(obj *unstructured.Unstructured) map[string]interface{} {
+	obj = obj.DeepCopy()
+	unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
+	return obj.Object
+}
+
+// Close closes the log file.
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.file.close()
+}
+
+// Filter selects events in queries. Empty fields match everything.
+type Filter struct {
+	SyncTarget string
+	Resource   string
+	Namespace  string
+	Name       string
+	Verb       Verb
+	Since      time.Time
+	// Limit is the maximum number of events returned, the most recent ones.
+	Limit int
+}
+
+// FilterFromQuery parses a filter from the query parameters syncTarget,
+// resource, namespace, name, verb, since (RFC 3339) and limit.
+func FilterFromQuery(query url.Values) (Filter, error) {
+	f := Filter{
+		SyncTarget: query.Get("syncTarget"),
+		Resource:   query.Get("resource"),
+		Namespace:  query.Get("namespace"),
+		Name:       query.Get("name"),
+		Verb:       Verb(query.Get("verb")),
+		Limit:      defaultQueryLimit,
+	}
+	if s := query.Get("since"); s != "" {
+		since, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return Filter{}, fmt.Errorf("invalid since %q: %w", s, err)
+		}
+		f.Since = since
+	}
+	if s := query.Get("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit <= 0 {
+			return Filter{}, fmt.Errorf("invalid limit %q", s)
+		}
+		f.Limit = limit
+	}
+	return f, nil
+}
+
+func (f Filter) matches(e *Event) bool {
+	return (f.SyncTarget == "" || f.SyncTarget == e.SyncTarget) &&
+		(f.Resource == "" || f.Resource == e.Resource) &&
+		(f.Namespace == "" || f.Namespace == e.Namespace) &&
+		(f.Name == "" || f.Name == e.Name) &&
+		(f.Verb == "" || f.Verb == e.Verb) &&
+		!e.Time.Before(f.Since)
+}
+
+// Query returns the most recent events matching the filter from the log
+// and its rotated files, oldest first. Lines that cannot be decrypted, e.g.
+// because they were encrypted with a key that has been removed, are
+// skipped.
+func (l *Log) Query(f Filter) ([]Event, error) {
+	if l == nil {
+		return nil, nil
+	}
+	l.lock.Lock()
+	paths := l.file.paths()
+	l.lock.Unlock()
+
+	var events []Event
+	err := readLines(paths, func(line []byte) {
+		line, err := l.decrypt(line)
+		if err != nil {
+			return
+		}
+		var e Event
+		if err := json.Unmarshal(line, &e); err != nil || !f.matches(&e) {
+			return
+		}
+		events = append(events, e)
+		if f.Limit > 0 && len(events) > 2*f.Limit {
+			events = append(events[:0], events[len(events)-f.Limit:]...)
+		}
+	})
+	if f.Limit > 0 && len(events) > f.Limit {
+		events = events[len(events)-f.Limit:]
+	}
+	return events, err
+}
+
+// decrypt returns the JSON of a line of the log file. Lines written before
+// encryption was enabled are plain JSON.
+func (l *Log) decrypt(line []byte) ([]byte, error) {
+	if l.keyring == nil || bytes.HasPrefix(line, []byte("{")) {
+		return line, nil
+	}
+	encrypted, err := base64.StdEncoding.DecodeString(string(line))
+	if err != nil {
+		return nil, err
+	}
+	return l.keyring.Decrypt(context.TODO(), encrypted)
+}
+
+// Serve answers diagnostics queries, see FilterFromQuery.
+func (l *Log) Serve(query url.Values) (interface{}, error) {
+	f, err := FilterFromQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	events, err := l.Query(f)
+	if events == nil {
+		events = []Event{}
+	}
+	return events, err
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var deployments = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+func deployment(name string, replicas int64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"namespace":     "kcp-abc",
+			"name":          name,
+			"managedFields": []interface{}{map[string]interface{}{"manager": "syncer"}},
+		},
+		"spec": map[string]interface{}{"replicas": replicas},
+	}}
+}
+
+func TestRecordAndQuery(t *testing.T) {
+	options := NewOptions()
+	options.Path = filepath.Join(t.TempDir(), "audit", "mutations.log")
+	l, err := NewLog(options, "root:org:edge-1")
+	require.NoError(t, err)
+	defer l.Close()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { now = now.Add(time.Minute); return now }
+
+	ctx := context.Background()
+	l.Record(ctx, Mutation{Controller: "spec", Verb: VerbCreate, Resource: deployments, New: deployment("nginx", 1)})
+	l.Record(ctx, Mutation{Controller: "spec", Verb: VerbUpdate, Resource: deployments, Old: deployment("nginx", 1), New: deployment("nginx", 3)})
+	l.Record(ctx, Mutation{Controller: "spec", Verb: VerbCreate, Resource: deployments, New: deployment("redis", 1)})
+	l.Record(ctx, Mutation{Controller: "spec", Verb: VerbDelete, Resource: deployments, Old: deployment("nginx", 3)})
+
+	events, err := l.Query(Filter{Name: "nginx"})
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	require.Equal(t, "root:org:edge-1", events[0].SyncTarget)
+	require.Equal(t, "kcp-abc", events[0].Namespace)
+	require.NotContains(t, string(events[0].Diff), "managedFields")
+	require.JSONEq(t, `{"spec":{"replicas":3}}`, string(events[1].Diff))
+	require.Equal(t, VerbDelete, events[2].Verb)
+	require.Empty(t, events[2].Diff)
+
+	events, err = l.Query(Filter{Verb: VerbCreate, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, "redis", events[0].Name, "the most recent events are returned")
+
+	result, err := l.Serve(url.Values{"since": {now.Format(time.RFC3339)}})
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	_, err = l.Serve(url.Values{"limit": {"0"}})
+	require.Error(t, err)
+
+	l.ForSyncTarget("root:org:edge-2").Record(ctx, Mutation{Controller: "spec", Verb: VerbCreate, Resource: deployments, New: deployment("nginx", 1)})
+	events, err = l.Query(Filter{SyncTarget: "root:org:edge-2"})
+	require.NoError(t, err)
+	require.Len(t, events, 1, "logs of other SyncTargets write to the same file")
+	require.Equal(t, "root:org:edge-2", events[0].SyncTarget)
+
+	var disabled *Log
+	require.Nil(t, disabled.ForSyncTarget("root:org:edge-2"))
+	disabled.Record(ctx, Mutation{Verb: VerbCreate, Resource: deployments, New: deployment("nginx", 1)})
+	events, err = disabled.Query(Filter{})
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
+
+func TestEncryptedLog(t *testing.T) {
+	dir := t.TempDir()
+	options := NewOptions()
+	options.Path = filepath.Join(dir, "mutations.log")
+	plain, err := NewLog(options, "root:org:edge-1")
+	require.NoError(t, err)
+	ctx := context.Background()
+	plain.Record(ctx, Mutation{Controller: "spec", Verb: VerbCreate, Resource: deployments, New: deployment("nginx", 1)})
+	require.NoError(t, plain.Close())
+
+	options.EncryptionConfig = filepath.Join(dir, "encryption.yaml")
+	require.NoError(t, os.WriteFile(options.EncryptionConfig, []byte(`keys:
+- id: "1"
+  algorithm: aes-gcm
+  secret: MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=
+`), 0o600))
+	l, err := NewLog(options, "root:org:edge-1")
+	require.NoError(t, err)
+	defer l.Close()
+	l.Record(ctx, Mutation{Controller: "spec", Verb: VerbUpdate, Resource: deployments, Old: deployment("nginx", 1), New: deployment("nginx", 3)})
+
+	data, err := os.ReadFile(options.Path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	require.Contains(t, lines[0], "nginx", "events written before encryption was enabled stay readable")
+	require.NotContains(t, lines[1], "nginx")
+
+	events, err := l.Query(Filter{Name: "nginx"})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, VerbUpdate, events[1].Verb)
+}
+
+func TestRecordRedactsSensitiveObjects(t *testing.T) {
+	options := NewOptions()
+	options.Path = filepath.Join(t.TempDir(), "mutations.log")
+	l, err := NewLog(options, "root:org:edge-1")
+	require.NoError(t, err)
+	defer l.Close()
+
+	ctx := context.Background()
+	secret := deployment("nginx", 1)
+	secret.SetAnnotations(map[string]string{workloadv1alpha1.AnnotationSensitive: "true"})
+	l.Record(ctx, Mutation{Controller: "spec", Verb: VerbCreate, Resource: deployments, New: secret})
+	l.RecordDecryption(ctx, "batcher", deployments, "kcp-abc", "nginx")
+
+	events, err := l.Query(Filter{Name: "nginx"})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.True(t, events[0].Redacted, "the diff of sensitive objects is not written to unencrypted logs")
+	require.Empty(t, events[0].Diff)
+	require.Equal(t, VerbDecrypt, events[1].Verb)
+	require.Equal(t, "batcher", events[1].Controller)
+}
+
+func TestRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mutations.log")
+	f, err := openRotatingFile(path, 10, 2)
+	require.NoError(t, err)
+	for _, line := range []string{"one", "two", "six", "ten"} {
+		require.NoError(t, f.writeLine([]byte(line)))
+		require.NoError(t, f.writeLine([]byte(line)))
+	}
+	require.NoError(t, f.close())
+
+	require.Equal(t, []string{path + ".2", path + ".1", path}, f.paths())
+	var lines []string
+	require.NoError(t, readLines(f.paths(), func(line []byte) { lines = append(lines, string(line)) }))
+	require.Equal(t, []string{"two", "two", "six", "six", "ten", "ten"}, lines, "the oldest file is dropped")
+	_, err = os.Stat(path + ".3")
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestShipper(t *testing.T) {
+	s := newShipper("http://collector.example.com", time.Second)
+	var shipped []Event
+	fail := true
+	s.post = func(_ context.Context, body []byte) error {
+		if fail {
+			return errors.New("unavailable")
+		}
+		var batch []Event
+		require.NoError(t, json.Unmarshal(body, &batch))
+		shipped = append(shipped, batch...)
+		return nil
+	}
+
+	s.add(Event{Name: "a"})
+	s.ship(context.Background())
+	require.Empty(t, shipped)
+	s.add(Event{Name: "b"})
+
+	fail = false
+	s.ship(context.Background())
+	require.Equal(t, []Event{{Name: "a"}, {Name: "b"}}, shipped, "failed batches are retried in order")
+
+	for i := 0; i < maxPending+5; i++ {
+		s.add(Event{})
+	}
+	require.Len(t, s.pending, maxPending)
+	require.Equal(t, 5, s.dropped)
+}
+
+func TestValidate(t *testing.T) {
+	o := NewOptions()
+	require.NoError(t, o.Validate())
+	o.ShipURL = "https://collector.example.com"
+	require.Error(t, o.Validate(), "shipping requires a local log")
+	o.Path = "/var/log/syncer/mutations.log"
+	require.NoError(t, o.Validate())
+	o.ShipURL = "collector:443"
+	require.Error(t, o.Validate())
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// maxPending bounds the events kept in memory while the collector is
+// unavailable. The oldest are dropped first; they remain in the local log.
+const maxPending = 10000
+
+// shipper POSTs events to an upstream collector in batches.
+type shipper struct {
+	url      string
+	interval time.Duration
+	post     func(ctx context.Context, body []byte) error
+
+	lock    sync.Mutex
+	pending []Event
+	dropped int
+}
+
+func newShipper(url string, interval time.Duration) *shipper {
+	client := &http.Client{Timeout: 30 * time.Second}
+	return &shipper{
+		url:      url,
+		interval: interval,
+		post: func(ctx context.Context, body []byte) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+			if err != nil {
+				return err
+			}
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := client.Do(req)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+				return fmt.Errorf("collector answered %s", resp.Status)
+			}
+			return nil
+		},
+	}
+}
+
+func (s *shipper) add(e Event) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(s.pending) == maxPending {
+		s.pending = s.pending[1:]
+		s.dropped++
+	}
+	s.pending = append(s.pending, e)
+}
+
+func (s *shipper) run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// Flush what is left, but do not hold up shutdown for long.
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			s.ship(flushCtx) //nolint:contextcheck
+			cancel()
+			return
+		case <-ticker.C:
+			s.ship(ctx)
+		}
+	}
+}
+
+// ship sends the pending events, keeping them for the next attempt if the
+// collector fails.
+func (s *shipper) ship(ctx context.Context) {
+	logger := klog.FromContext(ctx).WithValues("url", s.url)
+	s.lock.Lock()
+	batch := s.pending
+	s.pending = nil
+	if s.dropped > 0 {
+		logger.Info("dropped audit events not shipped in time, they are only in the local log", "count", s.dropped)
+		s.dropped = 0
+	}
+	s.lock.Unlock()
+	if len(batch) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(batch)
+	if err == nil {
+		err = s.post(ctx, body)
+	}
+	if err == nil {
+		return
+	}
+	logger.Error(err, "failed to ship audit events, retrying", "count", len(batch))
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.pending = append(batch, s.pending...)
+	if excess := len(s.pending) - maxPending; excess > 0 {
+		s.pending = s.pending[excess:]
+		s.dropped += excess
+	}
+}
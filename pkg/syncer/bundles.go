@@ -0,0 +1,62 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/bundles"
+	corev1alpha1 "github.com/kcp-dev/kcp/sdk/apis/core/v1alpha1"
+)
+
+var logicalClustersGVR = corev1alpha1.SchemeGroupVersion.WithResource("logicalclusters")
+
+// bundleInputs sends the annotations of the LogicalCluster of the workspace
+// and the SyncTarget last read every interval until ctx is done, for
+// bundles.Watch. Nothing is sent while the LogicalCluster cannot be read.
+func (s *syncer) bundleInputs(ctx context.Context, interval time.Duration) <-chan bundles.Inputs {
+	inputs := make(chan bundles.Inputs)
+	go func() {
+		defer close(inputs)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			lc, err := s.upstream.Resource(logicalClustersGVR).Get(ctx, corev1alpha1.LogicalClusterName, metav1.GetOptions{})
+			if err != nil {
+				utilruntime.HandleError(fmt.Errorf("failed to get the sync bundles of the workspace of SyncTarget %s: %w", s.target, err))
+			} else {
+				select {
+				case inputs <- bundles.Inputs{Annotations: lc.GetAnnotations(), SyncTarget: s.syncTarget.Load()}:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return inputs
+}
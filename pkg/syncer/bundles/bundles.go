@@ -0,0 +1,181 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bundles adds curated sets of resources adjacent to workloads to
+// the resources a syncer syncs, e.g. the ServiceMonitors and PodMonitors
+// scraping a Deployment. A workspace opts into bundles by listing them in
+// the AnnotationSyncBundles annotation of its LogicalCluster:
+//
+//	tmc.kcp.io/sync-bundles: observability
+//
+// Resources of a bundle are only synced to physical clusters serving their
+// API, as reported in the capabilities of the SyncTarget. Other clusters
+// are skipped silently, since not every cluster runs e.g. the Prometheus
+// operator.
+package bundles
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/controllermanager"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// AnnotationSyncBundles on the LogicalCluster of a workspace is the
+// comma-separated list of bundles synced for its workloads.
+const AnnotationSyncBundles = "tmc.kcp.io/sync-bundles"
+
+// Bundle is a named set of resources synced together.
+type Bundle struct {
+	Name      string
+	Resources []schema.GroupVersionResource
+}
+
+// Observability are the resources of the Prometheus operator monitoring
+// workloads.
+var Observability = Bundle{
+	Name: "observability",
+	Resources: []schema.GroupVersionResource{
+		{Group: "monitoring.coreos.com", Version: "v1", Resource: "servicemonitors"},
+		{Group: "monitoring.coreos.com", Version: "v1", Resource: "podmonitors"},
+		{Group: "monitoring.coreos.com", Version: "v1", Resource: "prometheusrules"},
+	},
+}
+
+// Known are the bundles by name.
+var Known = map[string]Bundle{
+	Observability.Name: Observability,
+}
+
+// Enabled returns the known bundles listed in annotations, and the unknown
+// names.
+func Enabled(annotations map[string]string) ([]Bundle, []string) {
+	var enabled []Bundle
+	var unknown []string
+	seen := sets.New[string]()
+	for _, name := range strings.Split(annotations[AnnotationSyncBundles], ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen.Has(name) {
+			continue
+		}
+		seen.Insert(name)
+		if b, found := Known[name]; found {
+			enabled = append(enabled, b)
+		} else {
+			unknown = append(unknown, name)
+		}
+	}
+	return enabled, unknown
+}
+
+// Served returns the API versions served by the physical cluster of
+// target, as apiVersion, or nil if they were not harvested yet.
+func Served(target *tmcv1alpha1.SyncTarget) sets.Set[string] {
+	if target == nil || target.Status.Capabilities == nil {
+		return nil
+	}
+	return sets.New(target.Status.Capabilities.APIs...)
+}
+
+// Apply returns config with the resources of bundles whose API version is
+// served. Resources configured explicitly keep their configuration.
+func Apply(config controllermanager.Config, bundles []Bundle, served sets.Set[string]) controllermanager.Config {
+	out := make(controllermanager.Config, len(config))
+	for gvr, rc := range config {
+		out[gvr] = rc
+	}
+	for _, b := range bundles {
+		for _, gvr := range b.Resources {
+			if !served.Has(gvr.GroupVersion().String()) {
+				continue
+			}
+			if _, found := out[gvr]; !found {
+				out[gvr] = controllermanager.ResourceConfig{}
+			}
+		}
+	}
+	return out
+}
+
+// Inputs are what the bundles synced by a syncer depend on.
+type Inputs struct {
+	// Annotations of the LogicalCluster of the workspace.
+	Annotations map[string]string
+	// SyncTarget of the syncer.
+	SyncTarget *tmcv1alpha1.SyncTarget
+}
+
+// Watch returns the configurations from configs with the bundles enabled
+// by the latest inputs applied, for controllermanager.Manager.Run. A new
+// configuration is sent whenever either changes the result. The channel is
+// closed when configs is closed or ctx is done.
+func Watch(ctx context.Context, configs <-chan controllermanager.Config, inputs <-chan Inputs) <-chan controllermanager.Config {
+	out := make(chan controllermanager.Config)
+	logger := klog.FromContext(ctx).WithName("bundles")
+
+	go func() {
+		defer close(out)
+
+		var config, last controllermanager.Config
+		var in Inputs
+		var reportedUnknown []string
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case c, ok := <-configs:
+				if !ok {
+					return
+				}
+				config = c
+			case i, ok := <-inputs:
+				if !ok {
+					inputs = nil
+					continue
+				}
+				in = i
+			}
+			if config == nil {
+				continue
+			}
+
+			enabled, unknown := Enabled(in.Annotations)
+			if len(unknown) > 0 && !reflect.DeepEqual(unknown, reportedUnknown) {
+				logger.Info("ignoring unknown sync bundles", "annotation", AnnotationSyncBundles, "bundles", unknown)
+			}
+			reportedUnknown = unknown
+
+			next := Apply(config, enabled, Served(in.SyncTarget))
+			if last != nil && reflect.DeepEqual(last, next) {
+				continue
+			}
+			select {
+			case out <- next:
+				last = next
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
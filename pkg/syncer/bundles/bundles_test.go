@@ -0,0 +1,100 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundles
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/controllermanager"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+var (
+	deploymentsGVR     = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	serviceMonitorsGVR = schema.GroupVersionResource{Group: "monitoring.coreos.com", Version: "v1", Resource: "servicemonitors"}
+	podMonitorsGVR     = schema.GroupVersionResource{Group: "monitoring.coreos.com", Version: "v1", Resource: "podmonitors"}
+	rulesGVR           = schema.GroupVersionResource{Group: "monitoring.coreos.com", Version: "v1", Resource: "prometheusrules"}
+)
+
+func target(apis ...string) *tmcv1alpha1.SyncTarget {
+	return &tmcv1alpha1.SyncTarget{Status: tmcv1alpha1.SyncTargetStatus{
+		Capabilities: &tmcv1alpha1.SyncTargetCapabilities{APIs: apis},
+	}}
+}
+
+func TestEnabled(t *testing.T) {
+	enabled, unknown := Enabled(map[string]string{AnnotationSyncBundles: "observability, tracing,observability"})
+	require.Equal(t, []Bundle{Observability}, enabled)
+	require.Equal(t, []string{"tracing"}, unknown)
+
+	enabled, unknown = Enabled(nil)
+	require.Empty(t, enabled)
+	require.Empty(t, unknown)
+}
+
+func TestApply(t *testing.T) {
+	config := controllermanager.Config{
+		deploymentsGVR:     {},
+		serviceMonitorsGVR: {Workers: 4},
+	}
+
+	require.Equal(t, controllermanager.Config{
+		deploymentsGVR:     {},
+		serviceMonitorsGVR: {Workers: 4},
+		podMonitorsGVR:     {},
+		rulesGVR:           {},
+	}, Apply(config, []Bundle{Observability}, Served(target("apps/v1", "monitoring.coreos.com/v1"))))
+
+	require.Equal(t, config, Apply(config, []Bundle{Observability}, Served(target("apps/v1"))), "clusters without the monitoring CRDs are skipped")
+	require.Equal(t, config, Apply(config, []Bundle{Observability}, Served(&tmcv1alpha1.SyncTarget{})), "capabilities are not harvested yet")
+	require.Len(t, config, 2, "the configuration is not modified")
+}
+
+func TestWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	configs := make(chan controllermanager.Config)
+	inputs := make(chan Inputs)
+	out := Watch(ctx, configs, inputs)
+
+	configs <- controllermanager.Config{deploymentsGVR: {}}
+	require.Equal(t, controllermanager.Config{deploymentsGVR: {}}, <-out)
+
+	inputs <- Inputs{
+		Annotations: map[string]string{AnnotationSyncBundles: "observability"},
+		SyncTarget:  target("monitoring.coreos.com/v1"),
+	}
+	require.Len(t, <-out, 4)
+
+	// Unchanged results are not sent again.
+	inputs <- Inputs{
+		Annotations: map[string]string{AnnotationSyncBundles: "observability,tracing"},
+		SyncTarget:  target("monitoring.coreos.com/v1"),
+	}
+	inputs <- Inputs{SyncTarget: target("monitoring.coreos.com/v1")}
+	require.Equal(t, controllermanager.Config{deploymentsGVR: {}}, <-out)
+
+	close(configs)
+	_, ok := <-out
+	require.False(t, ok)
+}
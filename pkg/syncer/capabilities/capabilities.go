@@ -0,0 +1,197 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package capabilities harvests what a physical cluster offers to
+// workloads: its Kubernetes version, the enabled feature gates of its API
+// server, the served API versions, and the installed addons, i.e. CSI
+// drivers and extended node resources such as nvidia.com/gpu of the GPU
+// operator. The syncer reports them in the status of its SyncTarget, where
+// the placement engine matches the requirements of PlacementPolicies
+// against them.
+package capabilities
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// featureEnabledMetric is the API server metric reporting feature gates,
+// available since Kubernetes 1.26.
+const featureEnabledMetric = "kubernetes_feature_enabled"
+
+// Capabilities of a physical cluster.
+type Capabilities struct {
+	KubernetesVersion string
+	// FeatureGates is nil if the API server does not report them.
+	FeatureGates []string
+	APIs         []string
+	Addons       []string
+}
+
+// Harvester harvests the capabilities of a physical cluster.
+type Harvester struct {
+	serverVersion func() (string, error)
+	apiVersions   func() ([]string, error)
+	metrics       func(ctx context.Context) ([]byte, error)
+	csiDrivers    func(ctx context.Context) ([]string, error)
+	nodes         func(ctx context.Context) ([]corev1.Node, error)
+	now           func() time.Time
+}
+
+// NewHarvester returns a harvester for the physical cluster of client.
+func NewHarvester(client kubernetes.Interface) *Harvester {
+	return &Harvester{
+		serverVersion: func() (string, error) {
+			info, err := client.Discovery().ServerVersion()
+			if err != nil {
+				return "", err
+			}
+			return info.GitVersion, nil
+		},
+		apiVersions: func() ([]string, error) {
+			groups, err := client.Discovery().ServerGroups()
+			if err != nil {
+				return nil, err
+			}
+			return metav1.ExtractGroupVersions(groups), nil
+		},
+		metrics: func(ctx context.Context) ([]byte, error) {
+			return client.Discovery().RESTClient().Get().AbsPath("/metrics").DoRaw(ctx)
+		},
+		csiDrivers: func(ctx context.Context) ([]string, error) {
+			list, err := client.StorageV1().CSIDrivers().List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			names := make([]string, 0, len(list.Items))
+			for _, driver := range list.Items {
+				names = append(names, driver.Name)
+			}
+			return names, nil
+		},
+		nodes: func(ctx context.Context) ([]corev1.Node, error) {
+			list, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			return list.Items, nil
+		},
+		now: time.Now,
+	}
+}
+
+// Harvest harvests the capabilities. Feature gates are skipped if the
+// syncer may not read the metrics of the API server.
+func (h *Harvester) Harvest(ctx context.Context) (*Capabilities, error) {
+	var c Capabilities
+	var err error
+	if c.KubernetesVersion, err = h.serverVersion(); err != nil {
+		return nil, fmt.Errorf("failed to get the server version: %w", err)
+	}
+	if c.APIs, err = h.apiVersions(); err != nil {
+		return nil, fmt.Errorf("failed to discover API versions: %w", err)
+	}
+	c.APIs = sets.List(sets.New(c.APIs...))
+
+	metrics, err := h.metrics(ctx)
+	switch {
+	case apierrors.IsForbidden(err) || apierrors.IsNotFound(err):
+		klog.FromContext(ctx).V(2).Info("cannot read the metrics of the physical cluster, not reporting feature gates", "err", err)
+	case err != nil:
+		return nil, fmt.Errorf("failed to get metrics: %w", err)
+	default:
+		c.FeatureGates = enabledFeatureGates(metrics)
+	}
+
+	addons := sets.New[string]()
+	drivers, err := h.csiDrivers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list CSI drivers: %w", err)
+	}
+	addons.Insert(drivers...)
+	nodes, err := h.nodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	for _, node := range nodes {
+		for name := range node.Status.Allocatable {
+			if extendedResource(name) {
+				addons.Insert(string(name))
+			}
+		}
+	}
+	c.Addons = sets.List(addons)
+	return &c, nil
+}
+
+// extendedResource is whether name is a resource offered by a device
+// plugin or similar addon rather than by Kubernetes itself.
+func extendedResource(name corev1.ResourceName) bool {
+	domain, _, found := strings.Cut(string(name), "/")
+	if !found {
+		return false
+	}
+	return domain != "kubernetes.io" && !strings.HasSuffix(domain, ".kubernetes.io")
+}
+
+// enabledFeatureGates returns the names of the feature gates reported as
+// enabled in metrics in the Prometheus text format, e.g.
+// kubernetes_feature_enabled{name="SidecarContainers",stage="BETA"} 1.
+func enabledFeatureGates(metrics []byte) []string {
+	enabled := sets.New[string]()
+	scanner := bufio.NewScanner(bytes.NewReader(metrics))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, featureEnabledMetric+"{") {
+			continue
+		}
+		labels, value, found := strings.Cut(strings.TrimPrefix(line, featureEnabledMetric+"{"), "} ")
+		if !found || strings.TrimSpace(value) != "1" {
+			continue
+		}
+		for _, label := range strings.Split(labels, ",") {
+			if name, found := strings.CutPrefix(label, `name="`); found {
+				enabled.Insert(strings.TrimSuffix(name, `"`))
+			}
+		}
+	}
+	return sets.List(enabled)
+}
+
+// SetStatus reports the capabilities in the status of the SyncTarget.
+func (h *Harvester) SetStatus(target *tmcv1alpha1.SyncTarget, c *Capabilities) {
+	target.Status.KubernetesVersion = c.KubernetesVersion
+	now := metav1.NewTime(h.now())
+	target.Status.Capabilities = &tmcv1alpha1.SyncTargetCapabilities{
+		FeatureGates:    c.FeatureGates,
+		APIs:            c.APIs,
+		Addons:          c.Addons,
+		LastHarvestTime: &now,
+	}
+}
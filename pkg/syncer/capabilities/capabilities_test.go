@@ -0,0 +1,87 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capabilities
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+const metrics = `# HELP kubernetes_feature_enabled [BETA] This metric records the data about the stage and enablement of a k8s feature.
+# TYPE kubernetes_feature_enabled gauge
+kubernetes_feature_enabled{name="EphemeralContainers",stage=""} 1
+kubernetes_feature_enabled{name="SidecarContainers",stage="BETA"} 1
+kubernetes_feature_enabled{name="InPlacePodVerticalScaling",stage="ALPHA"} 0
+apiserver_request_total{code="200",verb="GET"} 1
+`
+
+func TestHarvest(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&storagev1.CSIDriver{ObjectMeta: metav1.ObjectMeta{Name: "ebs.csi.aws.com"}},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "gpu-1"},
+			Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("8"),
+				"hugepages-2Mi":    resource.MustParse("0"),
+				"nvidia.com/gpu":   resource.MustParse("2"),
+				"example.kubernetes.io/internal-resource": resource.MustParse("1"),
+				"kubernetes.io/batch-cpu":                 resource.MustParse("1"),
+			}},
+		},
+	)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := NewHarvester(client)
+	h.serverVersion = func() (string, error) { return "v1.30.2", nil }
+	h.apiVersions = func() ([]string, error) { return []string{"v1", "apps/v1", "snapshot.storage.k8s.io/v1", "v1"}, nil }
+	h.metrics = func(context.Context) ([]byte, error) { return []byte(metrics), nil }
+	h.now = func() time.Time { return now }
+
+	c, err := h.Harvest(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, &Capabilities{
+		KubernetesVersion: "v1.30.2",
+		FeatureGates:      []string{"EphemeralContainers", "SidecarContainers"},
+		APIs:              []string{"apps/v1", "snapshot.storage.k8s.io/v1", "v1"},
+		Addons:            []string{"ebs.csi.aws.com", "nvidia.com/gpu"},
+	}, c)
+
+	target := &tmcv1alpha1.SyncTarget{}
+	h.SetStatus(target, c)
+	require.Equal(t, "v1.30.2", target.Status.KubernetesVersion)
+	require.Equal(t, c.Addons, target.Status.Capabilities.Addons)
+	require.True(t, target.Status.Capabilities.LastHarvestTime.Time.Equal(now))
+
+	h.metrics = func(context.Context) ([]byte, error) {
+		return nil, apierrors.NewForbidden(schema.GroupResource{}, "", nil)
+	}
+	c, err = h.Harvest(context.Background())
+	require.NoError(t, err, "feature gates are optional")
+	require.Nil(t, c.FeatureGates)
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package capacity reports the capacity of a physical cluster from its
+// nodes. The syncer reports it in the status of its SyncTarget, where the
+// placement engine scores SyncTargets by their allocatable share and
+// admits workloads whose PlacementPolicy requires resources only to
+// SyncTargets with enough of them allocatable.
+package capacity
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// Capacity of a physical cluster.
+type Capacity struct {
+	// Capacity is the sum of the capacity of all nodes.
+	Capacity corev1.ResourceList
+	// Allocatable is the sum of the allocatable resources of the nodes
+	// accepting pods, i.e. ready and schedulable ones.
+	Allocatable corev1.ResourceList
+}
+
+// Reporter collects the capacity of a physical cluster.
+type Reporter struct {
+	nodes func(ctx context.Context) ([]corev1.Node, error)
+}
+
+// NewReporter returns a reporter for the physical cluster of client.
+func NewReporter(client kubernetes.Interface) *Reporter {
+	return &Reporter{
+		nodes: func(ctx context.Context) ([]corev1.Node, error) {
+			list, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			return list.Items, nil
+		},
+	}
+}
+
+// Collect sums the capacity of the nodes.
+func (r *Reporter) Collect(ctx context.Context) (*Capacity, error) {
+	nodes, err := r.nodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c := &Capacity{Capacity: corev1.ResourceList{}, Allocatable: corev1.ResourceList{}}
+	for i := range nodes {
+		node := &nodes[i]
+		add(c.Capacity, node.Status.Capacity)
+		if acceptsPods(node) {
+			add(c.Allocatable, node.Status.Allocatable)
+		}
+	}
+	return c, nil
+}
+
+// SetStatus reports the capacity in the status of the SyncTarget.
+func (r *Reporter) SetStatus(target *tmcv1alpha1.SyncTarget, c *Capacity) {
+	capacity, allocatable := c.Capacity.DeepCopy(), c.Allocatable.DeepCopy()
+	target.Status.Capacity = &capacity
+	target.Status.Allocatable = &allocatable
+}
+
+func add(total, resources corev1.ResourceList) {
+	for name, quantity := range resources {
+		sum := total[name]
+		sum.Add(quantity)
+		total[name] = sum
+	}
+}
+
+// acceptsPods is whether new pods can be scheduled onto node.
+func acceptsPods(node *corev1.Node) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	for _, c := range node.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
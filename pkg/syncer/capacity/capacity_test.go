@@ -0,0 +1,69 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func node(name string, ready, unschedulable bool, cpu, allocatableCPU string) *corev1.Node {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+		Status: corev1.NodeStatus{
+			Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}},
+			Capacity:    corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+			Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(allocatableCPU)},
+		},
+	}
+}
+
+func TestCollect(t *testing.T) {
+	gpu := node("gpu-1", true, false, "8", "7500m")
+	gpu.Status.Allocatable["nvidia.com/gpu"] = resource.MustParse("2")
+	r := NewReporter(fake.NewSimpleClientset(
+		node("worker-1", true, false, "4", "3500m"),
+		node("not-ready", false, false, "4", "3500m"),
+		node("cordoned", true, true, "4", "3500m"),
+		gpu,
+	))
+
+	c, err := r.Collect(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "20", c.Capacity.Cpu().String())
+	require.Equal(t, "11", c.Allocatable.Cpu().String(), "only nodes accepting pods are allocatable")
+	require.Equal(t, "2", c.Allocatable.Name("nvidia.com/gpu", resource.DecimalSI).String())
+
+	target := &tmcv1alpha1.SyncTarget{}
+	r.SetStatus(target, c)
+	require.Equal(t, "20", target.Status.Capacity.Cpu().String())
+	require.Equal(t, "11", target.Status.Allocatable.Cpu().String())
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/clusterscoped"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// arbitrated returns whether the objects of the resource of the controller
+// are arbitrated with those of other workspaces, i.e. whether it is a
+// cluster-scoped resource of the SyncTarget, see package clusterscoped.
+func (c *controller) arbitrated() bool {
+	if c.namespaced {
+		return false
+	}
+	_, found := c.arbiter.Policy(c.gvr.GroupResource())
+	return found
+}
+
+// resolve writes the downstream object of an arbitrated resource as the
+// collision policy of the resource decides. Collisions are reported in the
+// status of the SyncTarget, see reportConflicts, and retried until they are
+// resolved.
+func (c *controller) resolve(ctx context.Context, obj *unstructured.Unstructured) error {
+	decision, err := c.arbiter.Resolve(ctx, c.gvr, c.clusterName, obj)
+	if err != nil {
+		return err
+	}
+	return c.write(ctx, decision)
+}
+
+// release changes the downstream object of an arbitrated resource when the
+// upstream object is no longer synced, deleting it once no workspace owns
+// it anymore.
+func (c *controller) release(ctx context.Context, name string) error {
+	decision, err := c.arbiter.Release(ctx, c.gvr, c.clusterName, name)
+	if err != nil || decision == nil {
+		return err
+	}
+	return c.write(ctx, decision)
+}
+
+// write carries out a decision of the arbiter.
+func (c *controller) write(ctx context.Context, decision *clusterscoped.Decision) error {
+	switch decision.Action {
+	case clusterscoped.ActionCreate, clusterscoped.ActionUpdate:
+		obj := decision.Object.DeepCopy()
+		// Objects of others are adopted as they are read.
+		obj.SetManagedFields(nil)
+		obj.SetResourceVersion("")
+		obj.SetUID("")
+		obj.SetCreationTimestamp(metav1.Time{})
+		obj.SetGeneration(0)
+		unstructured.RemoveNestedField(obj.Object, "status")
+		return c.applyDownstream(ctx, obj)
+	case clusterscoped.ActionDelete:
+		klog.FromContext(ctx).V(2).Info("deleting downstream object released by all workspaces")
+		return c.deleteDownstream(ctx, "", decision.Object.GetName())
+	}
+	return nil
+}
+
+// reportConflicts sets the ClusterScopedResourcesSynced condition of a
+// SyncTarget with cluster-scoped resources, see clusterscoped.Arbiter.
+func (s *syncer) reportConflicts(_ context.Context, syncTarget *tmcv1alpha1.SyncTarget) error {
+	if len(syncTarget.Spec.ClusterScopedResources) == 0 {
+		conditions.Delete(syncTarget, tmcv1alpha1.ClusterScopedResourcesSynced)
+		return nil
+	}
+	s.arbiter.SetCondition(syncTarget)
+	return nil
+}
@@ -0,0 +1,363 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package clusterscoped arbitrates the cluster-scoped objects, e.g. CRDs,
+// ClusterRoles or PriorityClasses, that workspaces sync to a shared
+// physical cluster. Downstream objects record the workspaces owning them in
+// an annotation, and name collisions with objects of other workspaces or of
+// the cluster administrator are resolved by the collision policy of the
+// resource in the SyncTarget, or reported as conflicts.
+package clusterscoped
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/naming"
+	"github.com/kcp-dev/kcp/pkg/syncer/semanticdiff"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+const (
+	// AnnotationOwners is set on downstream cluster-scoped objects to the
+	// comma separated, sorted workspaces owning them.
+	AnnotationOwners = "tmc.kcp.io/owners"
+
+	// AnnotationAdopted is set on downstream objects of the cluster
+	// administrator adopted by workspaces. They are not deleted when the
+	// last workspace releases them.
+	AnnotationAdopted = "tmc.kcp.io/adopted"
+
+	// maxConflictsInMessage bounds the conflicts listed in the condition.
+	maxConflictsInMessage = 5
+)
+
+var (
+	// ErrNotSynced is returned for resources not listed in the
+	// cluster-scoped resources of the SyncTarget.
+	ErrNotSynced = errors.New("cluster-scoped resource not synced to the SyncTarget")
+	// ErrCollision is wrapped by CollisionError.
+	ErrCollision = errors.New("cluster-scoped object collision")
+)
+
+// CollisionError is returned for objects colliding with a downstream object
+// the workspace does not own alone.
+type CollisionError struct {
+	Resource  schema.GroupResource
+	Name      string
+	Workspace logicalcluster.Name
+	// Owners are the workspaces owning the downstream object, or empty for
+	// objects of the cluster administrator.
+	Owners []string
+	// Differs is true if the downstream object is shared or adoptable, but
+	// differs from the object of the workspace.
+	Differs bool
+}
+
+func (e *CollisionError) Error() string {
+	owner := "an object of the cluster administrator"
+	if len(e.Owners) > 0 {
+		owner = fmt.Sprintf("the object of workspaces %s", strings.Join(e.Owners, ", "))
+	}
+	if e.Differs {
+		owner += " with different content"
+	}
+	return fmt.Sprintf("%s %q of workspace %s collides with %s", e.Resource, e.Name, e.Workspace, owner)
+}
+
+func (e *CollisionError) Unwrap() error {
+	return ErrCollision
+}
+
+// Action is what the syncer does downstream to resolve an object.
+type Action string
+
+const (
+	// ActionNone leaves the downstream object as it is.
+	ActionNone Action = "None"
+	// ActionCreate creates the object.
+	ActionCreate Action = "Create"
+	// ActionUpdate updates the object.
+	ActionUpdate Action = "Update"
+	// ActionDelete deletes the object.
+	ActionDelete Action = "Delete"
+)
+
+// Decision is the downstream write resolving an object.
+type Decision struct {
+	Action Action
+	// Object is the object to create, update or delete, with the
+	// downstream name and ownership annotations set.
+	Object *unstructured.Unstructured
+}
+
+// Arbiter resolves the cluster-scoped objects synced to one SyncTarget.
+type Arbiter struct {
+	getObject func(ctx context.Context, gvr schema.GroupVersionResource, name string) (*unstructured.Unstructured, error)
+
+	lock     sync.Mutex
+	policies map[schema.GroupResource]tmcv1alpha1.CollisionPolicy
+	// conflicts are keyed by resource, workspace and upstream name.
+	conflicts map[string]*CollisionError
+}
+
+// NewArbiter returns an arbiter for the given cluster-scoped resources of a
+// SyncTarget, reading downstream objects with client.
+func NewArbiter(resources []tmcv1alpha1.ClusterScopedResource, client dynamic.Interface) *Arbiter {
+	a := &Arbiter{
+		getObject: func(ctx context.Context, gvr schema.GroupVersionResource, name string) (*unstructured.Unstructured, error) {
+			return client.Resource(gvr).Get(ctx, name, metav1.GetOptions{})
+		},
+		conflicts: map[string]*CollisionError{},
+	}
+	a.SetResources(resources)
+	return a
+}
+
+// SetResources replaces the cluster-scoped resources, e.g. after the
+// SyncTarget changed. Objects already synced are kept.
+func (a *Arbiter) SetResources(resources []tmcv1alpha1.ClusterScopedResource) {
+	policies := make(map[schema.GroupResource]tmcv1alpha1.CollisionPolicy, len(resources))
+	for _, r := range resources {
+		policy := r.CollisionPolicy
+		if policy == "" {
+			policy = tmcv1alpha1.CollisionPolicyReject
+		}
+		policies[schema.GroupResource{Group: r.Group, Resource: r.Resource}] = policy
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.policies = policies
+}
+
+// Policy returns the collision policy of the resource, and false if the
+// resource is not synced.
+func (a *Arbiter) Policy(resource schema.GroupResource) (tmcv1alpha1.CollisionPolicy, bool) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	policy, found := a.policies[resource]
+	return policy, found
+}
+
+// DownstreamName returns the name of the downstream object for an object of
+// the workspace. With the Prefix policy, it carries a short hash of the
+// workspace.
+func DownstreamName(policy tmcv1alpha1.CollisionPolicy, workspace logicalcluster.Name, name string) string {
+	if policy != tmcv1alpha1.CollisionPolicyPrefix {
+		return name
+	}
+	return naming.Prefixed(name, validation.DNS1123SubdomainMaxLength, workspace.String())
+}
+
+// Resolve decides how the desired object of the workspace is written
+// downstream. It returns an error wrapping ErrNotSynced for resources not
+// synced, and a CollisionError for objects colliding with downstream
+// objects, which is reported as a conflict until the object is resolved or
+// released.
+func (a *Arbiter) Resolve(ctx context.Context, gvr schema.GroupVersionResource, workspace logicalcluster.Name, desired *unstructured.Unstructured) (*Decision, error) {
+	policy, found := a.Policy(gvr.GroupResource())
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrNotSynced, gvr.GroupResource())
+	}
+	if policy != tmcv1alpha1.CollisionPolicyPrefix && naming.HasReservedPrefix(desired.GetName()) {
+		return nil, fmt.Errorf("%s %q cannot be synced under its own name because it starts like a prefixed name", gvr.GroupResource(), desired.GetName())
+	}
+	key := conflictKey(gvr.GroupResource(), workspace, desired.GetName())
+
+	obj := desired.DeepCopy()
+	obj.SetName(DownstreamName(policy, workspace, desired.GetName()))
+	if policy == tmcv1alpha1.CollisionPolicyPrefix {
+		naming.SetUpstream(obj, naming.Identity{Workspace: workspace, Name: desired.GetName()})
+	}
+	obj.SetResourceVersion("")
+	obj.SetUID("")
+
+	existing, err := a.getObject(ctx, gvr, obj.GetName())
+	if apierrors.IsNotFound(err) {
+		a.resolved(key)
+		setOwners(obj, sets.New[string](workspace.String()), false)
+		return &Decision{Action: ActionCreate, Object: obj}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	owners := ownersOf(existing)
+	adopted := existing.GetAnnotations()[AnnotationAdopted] == "true"
+	identical := semanticdiff.EqualContent(gvr.GroupResource(), existing, obj)
+	switch {
+	case owners.Has(workspace.String()) && owners.Len() == 1 && !adopted:
+		setOwners(obj, owners, false)
+		a.resolved(key)
+		if semanticdiff.Unchanged(gvr.GroupResource(), obj, existing) {
+			return &Decision{Action: ActionNone, Object: existing}, nil
+		}
+		obj.SetResourceVersion(existing.GetResourceVersion())
+		return &Decision{Action: ActionUpdate, Object: obj}, nil
+	case owners.Has(workspace.String()) && identical:
+		a.resolved(key)
+		return &Decision{Action: ActionNone, Object: existing}, nil
+	case !owners.Has(workspace.String()) && policy == tmcv1alpha1.CollisionPolicyAdoptIfIdentical && identical:
+		// Objects without owners belong to the cluster administrator.
+		adopted = adopted || owners.Len() == 0
+		adopt := existing.DeepCopy()
+		setOwners(adopt, owners.Insert(workspace.String()), adopted)
+		a.resolved(key)
+		return &Decision{Action: ActionUpdate, Object: adopt}, nil
+	}
+
+	// A shared object must stay identical for all its owners.
+	others := owners.Clone().Delete(workspace.String())
+	collision := &CollisionError{
+		Resource:  gvr.GroupResource(),
+		Name:      obj.GetName(),
+		Workspace: workspace,
+		Owners:    sets.List(others),
+		Differs:   owners.Has(workspace.String()) || policy == tmcv1alpha1.CollisionPolicyAdoptIfIdentical,
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.conflicts[key] = collision
+	return nil, collision
+}
+
+// Release decides how the downstream object is changed when the object of
+// the workspace is no longer synced. The object is deleted when the last
+// owning workspace releases it, unless it was adopted from the cluster
+// administrator. It returns nil if the workspace does not own the object.
+func (a *Arbiter) Release(ctx context.Context, gvr schema.GroupVersionResource, workspace logicalcluster.Name, name string) (*Decision, error) {
+	a.resolved(conflictKey(gvr.GroupResource(), workspace, name))
+
+	policy, found := a.Policy(gvr.GroupResource())
+	if !found {
+		policy = tmcv1alpha1.CollisionPolicyReject
+	}
+	existing, err := a.getObject(ctx, gvr, DownstreamName(policy, workspace, name))
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	owners := ownersOf(existing)
+	if !owners.Has(workspace.String()) {
+		return nil, nil
+	}
+	owners.Delete(workspace.String())
+	adopted := existing.GetAnnotations()[AnnotationAdopted] == "true"
+	if owners.Len() == 0 && !adopted {
+		return &Decision{Action: ActionDelete, Object: existing}, nil
+	}
+
+	obj := existing.DeepCopy()
+	setOwners(obj, owners, adopted)
+	return &Decision{Action: ActionUpdate, Object: obj}, nil
+}
+
+// Conflicts returns the current conflicts, sorted by resource and name.
+func (a *Arbiter) Conflicts() []*CollisionError {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	conflicts := make([]*CollisionError, 0, len(a.conflicts))
+	for _, c := range a.conflicts {
+		conflicts = append(conflicts, c)
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].Resource != conflicts[j].Resource {
+			return conflicts[i].Resource.String() < conflicts[j].Resource.String()
+		}
+		if conflicts[i].Name != conflicts[j].Name {
+			return conflicts[i].Name < conflicts[j].Name
+		}
+		return conflicts[i].Workspace < conflicts[j].Workspace
+	})
+	return conflicts
+}
+
+// SetCondition sets the ClusterScopedResourcesSynced condition of the
+// SyncTarget from the current conflicts.
+func (a *Arbiter) SetCondition(target *tmcv1alpha1.SyncTarget) {
+	conflicts := a.Conflicts()
+	if len(conflicts) == 0 {
+		conditions.MarkTrue(target, tmcv1alpha1.ClusterScopedResourcesSynced)
+		return
+	}
+
+	messages := make([]string, 0, maxConflictsInMessage)
+	for i, c := range conflicts {
+		if i == maxConflictsInMessage {
+			messages = append(messages, fmt.Sprintf("and %d more", len(conflicts)-i))
+			break
+		}
+		messages = append(messages, c.Error())
+	}
+	conditions.MarkFalse(target, tmcv1alpha1.ClusterScopedResourcesSynced, tmcv1alpha1.CollisionReason, conditionsv1alpha1.ConditionSeverityWarning, "%s", strings.Join(messages, "; "))
+}
+
+func (a *Arbiter) resolved(key string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	delete(a.conflicts, key)
+}
+
+func conflictKey(resource schema.GroupResource, workspace logicalcluster.Name, name string) string {
+	return resource.String() + "|" + workspace.String() + "|" + name
+}
+
+func ownersOf(obj *unstructured.Unstructured) sets.Set[string] {
+	owners := sets.New[string]()
+	for _, owner := range strings.Split(obj.GetAnnotations()[AnnotationOwners], ",") {
+		if owner != "" {
+			owners.Insert(owner)
+		}
+	}
+	return owners
+}
+
+func setOwners(obj *unstructured.Unstructured, owners sets.Set[string], adopted bool) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	delete(annotations, AnnotationOwners)
+	delete(annotations, AnnotationAdopted)
+	if owners.Len() > 0 {
+		annotations[AnnotationOwners] = strings.Join(sets.List(owners), ",")
+		if adopted {
+			annotations[AnnotationAdopted] = "true"
+		}
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	obj.SetAnnotations(annotations)
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterscoped
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/naming"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+var (
+	clusterRoles    = schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}
+	priorityClasses = schema.GroupVersionResource{Group: "scheduling.k8s.io", Version: "v1", Resource: "priorityclasses"}
+	crds            = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+)
+
+type fakeCluster map[string]*unstructured.Unstructured
+
+// apply writes the decision like the syncer would.
+func (f fakeCluster) apply(d *Decision) {
+	switch d.Action {
+	case ActionCreate, ActionUpdate:
+		d.Object.SetResourceVersion("1")
+		f[d.Object.GetName()] = d.Object
+	case ActionDelete:
+		delete(f, d.Object.GetName())
+	}
+}
+
+func (f fakeCluster) arbiter(resources ...tmcv1alpha1.ClusterScopedResource) *Arbiter {
+	a := &Arbiter{
+		getObject: func(_ context.Context, gvr schema.GroupVersionResource, name string) (*unstructured.Unstructured, error) {
+			if obj, found := f[name]; found {
+				return obj.DeepCopy(), nil
+			}
+			return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+		},
+		conflicts: map[string]*CollisionError{},
+	}
+	a.SetResources(resources)
+	return a
+}
+
+func object(name string, rules ...string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	obj.SetName(name)
+	obj.SetResourceVersion("1")
+	var content []interface{}
+	for _, r := range rules {
+		content = append(content, r)
+	}
+	obj.Object["rules"] = content
+	return obj
+}
+
+func TestReject(t *testing.T) {
+	f := fakeCluster{"admin": object("admin", "*")}
+	a := f.arbiter(tmcv1alpha1.ClusterScopedResource{Group: clusterRoles.Group, Resource: clusterRoles.Resource})
+	ctx := context.Background()
+
+	d, err := a.Resolve(ctx, clusterRoles, "ws-a", object("reader", "get"))
+	require.NoError(t, err)
+	require.Equal(t, ActionCreate, d.Action)
+	require.Equal(t, "reader", d.Object.GetName())
+	require.Empty(t, d.Object.GetResourceVersion())
+	require.Equal(t, "ws-a", d.Object.GetAnnotations()[AnnotationOwners])
+	f.apply(d)
+
+	d, err = a.Resolve(ctx, clusterRoles, "ws-a", object("reader", "get", "list"))
+	require.NoError(t, err)
+	require.Equal(t, ActionUpdate, d.Action)
+	require.Equal(t, "1", d.Object.GetResourceVersion())
+	f.apply(d)
+
+	d, err = a.Resolve(ctx, clusterRoles, "ws-a", object("reader", "get", "list"))
+	require.NoError(t, err)
+	require.Equal(t, ActionNone, d.Action, "unchanged objects are not written")
+
+	_, err = a.Resolve(ctx, clusterRoles, "ws-b", object("reader", "get", "list"))
+	require.EqualError(t, err, `clusterroles.rbac.authorization.k8s.io "reader" of workspace ws-b collides with the object of workspaces ws-a`)
+	require.True(t, errors.Is(err, ErrCollision))
+
+	_, err = a.Resolve(ctx, clusterRoles, "ws-b", object("admin", "*"))
+	require.EqualError(t, err, `clusterroles.rbac.authorization.k8s.io "admin" of workspace ws-b collides with an object of the cluster administrator`)
+	require.Len(t, a.Conflicts(), 2)
+
+	_, err = a.Resolve(ctx, priorityClasses, "ws-a", object("high"))
+	require.True(t, errors.Is(err, ErrNotSynced))
+
+	_, err = a.Resolve(ctx, clusterRoles, "ws-a", object("tmc-0123abcd-reader", "get"))
+	require.EqualError(t, err, `clusterroles.rbac.authorization.k8s.io "tmc-0123abcd-reader" cannot be synced under its own name because it starts like a prefixed name`)
+
+	d, err = a.Release(ctx, clusterRoles, "ws-b", "reader")
+	require.NoError(t, err)
+	require.Nil(t, d, "ws-b does not own the object")
+	require.Len(t, a.Conflicts(), 1, "released objects are no conflicts")
+
+	d, err = a.Release(ctx, clusterRoles, "ws-a", "reader")
+	require.NoError(t, err)
+	require.Equal(t, ActionDelete, d.Action)
+	f.apply(d)
+	require.NotContains(t, f, "reader")
+}
+
+func TestPrefix(t *testing.T) {
+	f := fakeCluster{"high": object("high")}
+	a := f.arbiter(tmcv1alpha1.ClusterScopedResource{Group: priorityClasses.Group, Resource: priorityClasses.Resource, CollisionPolicy: tmcv1alpha1.CollisionPolicyPrefix})
+	ctx := context.Background()
+
+	names := map[logicalcluster.Name]string{}
+	for _, ws := range []logicalcluster.Name{"ws-a", "ws-b"} {
+		d, err := a.Resolve(ctx, priorityClasses, ws, object("high"))
+		require.NoError(t, err)
+		require.Equal(t, ActionCreate, d.Action)
+		require.Equal(t, DownstreamName(tmcv1alpha1.CollisionPolicyPrefix, ws, "high"), d.Object.GetName())
+		upstream, found := naming.UpstreamOf(d.Object)
+		require.True(t, found)
+		require.Equal(t, naming.Identity{Workspace: ws, Name: "high"}, upstream)
+		f.apply(d)
+		names[ws] = d.Object.GetName()
+	}
+	require.NotEqual(t, names["ws-a"], names["ws-b"])
+	require.Regexp(t, `^tmc-[0-9a-f]{8}-high$`, names["ws-a"])
+	require.Empty(t, a.Conflicts())
+
+	d, err := a.Release(ctx, priorityClasses, "ws-a", "high")
+	require.NoError(t, err)
+	require.Equal(t, ActionDelete, d.Action)
+	require.Equal(t, names["ws-a"], d.Object.GetName())
+}
+
+func TestAdoptIfIdentical(t *testing.T) {
+	f := fakeCluster{"widgets.example.com": object("widgets.example.com", "v1")}
+	a := f.arbiter(tmcv1alpha1.ClusterScopedResource{Group: crds.Group, Resource: crds.Resource, CollisionPolicy: tmcv1alpha1.CollisionPolicyAdoptIfIdentical})
+	ctx := context.Background()
+
+	d, err := a.Resolve(ctx, crds, "ws-a", object("widgets.example.com", "v1"))
+	require.NoError(t, err)
+	require.Equal(t, ActionUpdate, d.Action, "identical objects of the cluster administrator are adopted")
+	require.Equal(t, "ws-a", d.Object.GetAnnotations()[AnnotationOwners])
+	require.Equal(t, "true", d.Object.GetAnnotations()[AnnotationAdopted])
+	f.apply(d)
+
+	d, err = a.Resolve(ctx, crds, "ws-b", object("widgets.example.com", "v1"))
+	require.NoError(t, err)
+	require.Equal(t, "ws-a,ws-b", d.Object.GetAnnotations()[AnnotationOwners])
+	f.apply(d)
+
+	d, err = a.Resolve(ctx, crds, "ws-b", object("widgets.example.com", "v1"))
+	require.NoError(t, err)
+	require.Equal(t, ActionNone, d.Action)
+
+	_, err = a.Resolve(ctx, crds, "ws-b", object("widgets.example.com", "v2"))
+	require.EqualError(t, err, `customresourcedefinitions.apiextensions.k8s.io "widgets.example.com" of workspace ws-b collides with the object of workspaces ws-a with different content`)
+	_, err = a.Resolve(ctx, crds, "ws-c", object("widgets.example.com", "v2"))
+	require.Error(t, err)
+
+	target := &tmcv1alpha1.SyncTarget{}
+	a.SetCondition(target)
+	require.True(t, conditions.IsFalse(target, tmcv1alpha1.ClusterScopedResourcesSynced))
+	require.Equal(t, tmcv1alpha1.CollisionReason, conditions.GetReason(target, tmcv1alpha1.ClusterScopedResourcesSynced))
+	require.Equal(t, conditionsv1alpha1.ConditionSeverityWarning, *conditions.GetSeverity(target, tmcv1alpha1.ClusterScopedResourcesSynced))
+	require.Contains(t, conditions.GetMessage(target, tmcv1alpha1.ClusterScopedResourcesSynced), "of workspace ws-c collides")
+
+	for _, ws := range []logicalcluster.Name{"ws-a", "ws-b", "ws-c"} {
+		d, err := a.Release(ctx, crds, ws, "widgets.example.com")
+		require.NoError(t, err)
+		if ws == "ws-c" {
+			require.Nil(t, d)
+			continue
+		}
+		require.Equal(t, ActionUpdate, d.Action, "adopted objects are not deleted")
+		f.apply(d)
+	}
+	require.Empty(t, f["widgets.example.com"].GetAnnotations())
+
+	a.SetCondition(target)
+	require.True(t, conditions.IsTrue(target, tmcv1alpha1.ClusterScopedResourcesSynced))
+}
@@ -0,0 +1,822 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/placement/closure"
+	"github.com/kcp-dev/kcp/pkg/syncer/admissionfeedback"
+	"github.com/kcp-dev/kcp/pkg/syncer/audit"
+	"github.com/kcp-dev/kcp/pkg/syncer/clusterscoped"
+	"github.com/kcp-dev/kcp/pkg/syncer/controllermanager"
+	"github.com/kcp-dev/kcp/pkg/syncer/diagnostics"
+	"github.com/kcp-dev/kcp/pkg/syncer/endpoint"
+	"github.com/kcp-dev/kcp/pkg/syncer/fanout"
+	"github.com/kcp-dev/kcp/pkg/syncer/guardrail"
+	"github.com/kcp-dev/kcp/pkg/syncer/heartbeat"
+	"github.com/kcp-dev/kcp/pkg/syncer/multitarget"
+	"github.com/kcp-dev/kcp/pkg/syncer/naming"
+	"github.com/kcp-dev/kcp/pkg/syncer/pause"
+	"github.com/kcp-dev/kcp/pkg/syncer/priority"
+	"github.com/kcp-dev/kcp/pkg/syncer/priorityclass"
+	"github.com/kcp-dev/kcp/pkg/syncer/propagation"
+	"github.com/kcp-dev/kcp/pkg/syncer/ratelimit"
+	"github.com/kcp-dev/kcp/pkg/syncer/relocation"
+	"github.com/kcp-dev/kcp/pkg/syncer/schedulingdefaults"
+	"github.com/kcp-dev/kcp/pkg/syncer/semanticdiff"
+	"github.com/kcp-dev/kcp/pkg/syncer/sensitive"
+	"github.com/kcp-dev/kcp/pkg/syncer/status"
+	"github.com/kcp-dev/kcp/pkg/syncer/transport/bandwidth"
+	"github.com/kcp-dev/kcp/pkg/syncer/transport/batch"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+const (
+	// fieldManager is the field manager of the downstream objects.
+	fieldManager = "kcp-syncer"
+
+	// auditController and auditNamespaceController are the controllers
+	// downstream mutations are audited for, see package audit.
+	auditController          = "spec"
+	auditNamespaceController = "namespace"
+
+	defaultWorkers        = 2
+	defaultResyncInterval = 10 * time.Minute
+	// criticalPollInterval is how often the controllers of critical
+	// resources check whether they synced the objects queued at their start.
+	criticalPollInterval = 100 * time.Millisecond
+)
+
+var namespacesGVR = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+// syncer is the syncer of one SyncTarget.
+type syncer struct {
+	target      multitarget.Target
+	clusterName logicalcluster.Name
+	// key is the value of LabelSyncTarget of the downstream objects.
+	key string
+
+	// upstream talks to the logical cluster of the SyncTarget through the
+	// syncer virtual workspace.
+	upstream   dynamic.Interface
+	downstream dynamic.Interface
+	// downstreamKube talks to the physical cluster, e.g. to report what it
+	// offers. Nothing is reported without it.
+	downstreamKube kubernetes.Interface
+	mapper         meta.RESTMapper
+
+	// placement tracks the upstream objects placed on the SyncTarget, the
+	// only ones synced. Changes are queued in the controller of their kind
+	// in controllers.
+	placement   *placement
+	controllers sync.Map
+
+	// namespaces are the downstream namespaces known to exist.
+	namespaces sync.Map
+	// arbiter resolves collisions of the objects of the cluster-scoped
+	// resources of the SyncTarget with those of other workspaces.
+	arbiter *clusterscoped.Arbiter
+
+	// syncTarget is the SyncTarget last read.
+	syncTarget atomic.Pointer[tmcv1alpha1.SyncTarget]
+	// policies are the PropagationPolicies of the workspace last read.
+	policies atomic.Pointer[[]*workloadv1alpha1.PropagationPolicy]
+	// priorityClasses are the WorkloadPriorityClasses of the workspace last
+	// read by name. priorityClassMapper maps them to the PriorityClasses of
+	// the physical cluster, if set.
+	priorityClasses     atomic.Pointer[map[string]*workloadv1alpha1.WorkloadPriorityClass]
+	priorityClassMapper *priorityclass.Mapper
+	// schedulingDefaults are the SchedulingDefaults of the workspace last
+	// read.
+	schedulingDefaults atomic.Pointer[[]*workloadv1alpha1.SchedulingDefaults]
+	// diagnostics serves the queues of the controllers, if set.
+	diagnostics *diagnostics.Server
+	// audit records the downstream mutations. Nothing is recorded if nil.
+	audit *audit.Log
+	// pause holds the controllers, the downstream writes and the status
+	// writer while the SyncTarget is paused.
+	pause *pause.Gate
+	// critical holds the controllers of resources that are not critical
+	// until those of critical resources have synced.
+	critical *criticalGate
+	// reporters set the status of the SyncTarget, see reportStatus.
+	reporters []statusReporter
+	// heartbeat renews the heartbeat Lease of the SyncTarget.
+	heartbeat *heartbeat.Heartbeat
+	// statusWriter writes the status of downstream objects back, if status
+	// is synced.
+	statusWriter *status.Writer
+	// feedback reports the objects refused by admission webhooks of the
+	// physical cluster on the upstream objects, if set. Batched writes are
+	// not reported.
+	feedback *admissionfeedback.Reporter
+	// limiter is the bandwidth budget of the SyncTarget.
+	limiter *bandwidth.Limiter
+	// rateLimiter limits the requests to the physical cluster with the
+	// settings of the SyncTarget, see rateLimits.For, if set.
+	rateLimiter *ratelimit.Limiter
+	rateLimits  *ratelimit.Options
+	// relocations follows moves of the workspace of the SyncTarget, if
+	// set, see workspacePath.
+	relocations *relocation.Handler
+	// endpoint routes the requests to the syncer virtual workspace, if it
+	// is followed.
+	endpoint *endpoint.Endpoint
+	// works delivers the downstream objects as ManifestWorks instead of
+	// applying them, in the ManifestWork delivery mode.
+	works *manifestWorks
+	// guardrails tracks the objects synced to the SyncTarget against its
+	// guardrails, see package guardrail.
+	guardrails *guardrail.Tracker
+	// batcher coalesces the downstream writes, if they are batched. The
+	// writes are sent by the downstreamWriter of their resource in writers.
+	batcher *batch.Batcher
+	writers sync.Map
+}
+
+// downstreamWriter writes the downstream objects of a resource.
+type downstreamWriter struct {
+	apply  func(ctx context.Context, obj *unstructured.Unstructured) error
+	delete func(ctx context.Context, namespace, name string) error
+	// preserveConflicts ignores conflicts with fields changed by others.
+	preserveConflicts bool
+}
+
+func newSyncer(target multitarget.Target, clusterName logicalcluster.Name, upstream, downstream dynamic.Interface, mapper meta.RESTMapper) *syncer {
+	s := &syncer{
+		target:      target,
+		clusterName: clusterName,
+		key:         SyncTargetKey(clusterName, target.Name),
+		upstream:    upstream,
+		downstream:  downstream,
+		mapper:      mapper,
+		placement:   newPlacement(target, upstream, mapper, defaultResyncInterval),
+		arbiter:     clusterscoped.NewArbiter(nil, downstream),
+		pause:       pause.NewGate(target.Name),
+		critical:    newCriticalGate(),
+		guardrails:  guardrail.NewTracker(nil),
+	}
+	s.placement.changed = s.placementChanged
+	return s
+}
+
+// placementChanged queues the objects placed on the SyncTarget or no
+// longer placed in the controllers of their kinds.
+func (s *syncer) placementChanged(refs []closure.Reference) {
+	if s.works != nil {
+		s.works.trigger()
+	}
+	for _, ref := range refs {
+		if value, found := s.controllers.Load(ref.GroupKind); found {
+			value.(*controller).queue.Add(cache.NewObjectName(ref.Namespace, ref.Name).String())
+		}
+	}
+}
+
+// newController returns the controller of a resource, see
+// controllermanager.ControllerFactory.
+func (s *syncer) newController(gvr schema.GroupVersionResource, config controllermanager.ResourceConfig) (controllermanager.Controller, error) {
+	if config.Direction == workloadv1alpha1.SyncDirectionBidirectional {
+		return nil, fmt.Errorf("%s is configured to sync in both directions, which the syncer does not support", gvr)
+	}
+	kind, err := s.mapper.KindFor(gvr)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not served by the physical cluster: %w", gvr, err)
+	}
+	mapping, err := s.mapper.RESTMapping(kind.GroupKind(), gvr.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	resync := config.ResyncInterval.Duration
+	if resync == 0 {
+		resync = defaultResyncInterval
+	}
+	workers := config.Workers
+	if workers == 0 {
+		workers = defaultWorkers
+	}
+	selector := labels.SelectorFromSet(labels.Set{LabelSyncTarget: s.key}).String()
+	classifier := priority.NewClassifier(map[schema.GroupResource]priority.Class{gvr.GroupResource(): config.Priority})
+
+	c := &controller{
+		syncer:           s,
+		gvr:              gvr,
+		kind:             kind.GroupKind(),
+		config:           config,
+		namespaced:       mapping.Scope.Name() == meta.RESTScopeNameNamespace,
+		workers:          workers,
+		class:            classifier.Classify(gvr.GroupResource(), nil),
+		upstreamInformer: dynamicinformer.NewFilteredDynamicInformer(s.upstream, gvr, metav1.NamespaceAll, resync, cache.Indexers{}, nil).Informer(),
+		downstreamInformer: dynamicinformer.NewFilteredDynamicInformer(s.downstream, gvr, metav1.NamespaceAll, resync, cache.Indexers{}, func(options *metav1.ListOptions) {
+			options.LabelSelector = selector
+		}).Informer(),
+	}
+	// Objects are handed to the workers by their class, so that critical
+	// ones are synced first when everything is queued again after a
+	// reconnect or a cold start.
+	c.queue = priority.NewRateLimitingQueue(multitarget.QueueName(s.target, gvr.String()), func(key string) priority.Class {
+		obj, exists, err := c.upstreamInformer.GetIndexer().GetByKey(key)
+		if err != nil || !exists {
+			return c.class
+		}
+		return classifier.Classify(gvr.GroupResource(), obj.(*unstructured.Unstructured))
+	})
+	if c.class == priority.Critical {
+		c.criticalSynced = s.critical.add()
+	}
+	writer := &downstreamWriter{preserveConflicts: config.ConflictStrategy == workloadv1alpha1.ConflictStrategyPreserve}
+	writer.apply = func(ctx context.Context, obj *unstructured.Unstructured) error {
+		if c.namespaced {
+			if err := s.ensureNamespace(ctx, upstreamNamespace(obj)); err != nil {
+				return err
+			}
+		}
+		old := c.existing(obj.GetNamespace(), obj.GetName())
+		applied, err := s.downstream.Resource(gvr).Namespace(obj.GetNamespace()).Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{
+			FieldManager: fieldManager,
+			Force:        config.ConflictStrategy != workloadv1alpha1.ConflictStrategyPreserve,
+		})
+		if apierrors.IsNotFound(err) && c.namespaced {
+			// The downstream namespace was deleted, create it again.
+			s.namespaces.Delete(obj.GetNamespace())
+		}
+		if err != nil {
+			return err
+		}
+		verb := audit.VerbPatch
+		if old == nil {
+			verb = audit.VerbCreate
+		}
+		s.audit.Record(ctx, audit.Mutation{Controller: auditController, Verb: verb, Resource: gvr, Old: old, New: applied})
+		return nil
+	}
+	writer.delete = func(ctx context.Context, namespace, name string) error {
+		options := metav1.DeleteOptions{}
+		if config.DeletionPropagation != "" {
+			options.PropagationPolicy = &config.DeletionPropagation
+		}
+		old := c.existing(namespace, name)
+		if err := s.downstream.Resource(gvr).Namespace(namespace).Delete(ctx, name, options); err != nil {
+			return err
+		}
+		if old == nil {
+			old = &unstructured.Unstructured{}
+			old.SetNamespace(namespace)
+			old.SetName(name)
+		}
+		s.audit.Record(ctx, audit.Mutation{Controller: auditController, Verb: audit.VerbDelete, Resource: gvr, Old: old})
+		return nil
+	}
+	if s.works != nil {
+		// Objects no longer placed leave their ManifestWorks, see
+		// manifestWorks.sync.
+		writer.apply = s.works.apply
+	}
+	c.applyDownstream, c.deleteDownstream = writer.apply, writer.delete
+	if s.batcher != nil {
+		s.writers.Store(gvr, writer)
+		c.applyDownstream = func(ctx context.Context, obj *unstructured.Unstructured) error {
+			s.batcher.Add(batch.Operation{ClusterName: s.clusterName, GVR: gvr, Namespace: obj.GetNamespace(), Name: obj.GetName(), Object: obj})
+			return nil
+		}
+		c.deleteDownstream = func(ctx context.Context, namespace, name string) error {
+			s.batcher.Add(batch.Operation{ClusterName: s.clusterName, GVR: gvr, Namespace: namespace, Name: name})
+			return nil
+		}
+	}
+
+	c.upstreamHandler, err = c.upstreamInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueue(obj)
+			c.resyncPlacement(obj)
+		},
+		UpdateFunc: func(old, obj interface{}) {
+			c.enqueue(obj)
+			if referencesChanged(old.(*unstructured.Unstructured), obj.(*unstructured.Unstructured)) {
+				c.resyncPlacement(obj)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.enqueue(obj)
+			c.resyncPlacement(obj)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.downstreamInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{ //nolint:errcheck
+		AddFunc: c.writeStatus,
+		UpdateFunc: func(old, obj interface{}) {
+			c.enqueueDownstream(obj)
+			if !equality.Semantic.DeepEqual(old.(*unstructured.Unstructured).Object["status"], obj.(*unstructured.Unstructured).Object["status"]) {
+				c.writeStatus(obj)
+			}
+		},
+		DeleteFunc: c.enqueueDownstream,
+	})
+	s.controllers.Store(c.kind, c)
+	return c, nil
+}
+
+// sendBatch writes a batch of downstream objects of a resource, see
+// batch.SendFunc. Operations on resources without a writer are dropped.
+func (s *syncer) sendBatch(ctx context.Context, gvr schema.GroupVersionResource, ops []batch.Operation) map[int]error {
+	failed := map[int]error{}
+	value, found := s.writers.Load(gvr)
+	if !found {
+		return failed
+	}
+	writer := value.(*downstreamWriter)
+	for i, op := range ops {
+		if op.Object == nil {
+			if err := writer.delete(ctx, op.Namespace, op.Name); err != nil && !apierrors.IsNotFound(err) {
+				failed[i] = err
+			}
+			continue
+		}
+		err := writer.apply(ctx, op.Object)
+		if apierrors.IsConflict(err) && writer.preserveConflicts {
+			continue
+		}
+		if err != nil {
+			failed[i] = err
+		}
+	}
+	return failed
+}
+
+// controller syncs the objects of one resource from the workspace of the
+// SyncTarget down to the physical cluster. Downstream objects changed or
+// deleted by others are synced again.
+type controller struct {
+	*syncer
+
+	gvr        schema.GroupVersionResource
+	kind       schema.GroupKind
+	config     controllermanager.ResourceConfig
+	namespaced bool
+	workers    int
+	// class is the sync priority class of the resource. criticalSynced
+	// releases the controllers held by syncer.critical, for a critical
+	// resource.
+	class          priority.Class
+	criticalSynced func()
+
+	queue              workqueue.TypedRateLimitingInterface[string]
+	upstreamInformer   cache.SharedIndexInformer
+	upstreamHandler    cache.ResourceEventHandlerRegistration
+	downstreamInformer cache.SharedIndexInformer
+	// active counts the keys being processed.
+	active atomic.Int32
+
+	applyDownstream  func(ctx context.Context, obj *unstructured.Unstructured) error
+	deleteDownstream func(ctx context.Context, namespace, name string) error
+}
+
+func (c *controller) enqueue(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.queue.Add(key)
+}
+
+// enqueueDownstream queues the upstream object of a downstream object.
+func (c *controller) enqueueDownstream(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	id, found := naming.UpstreamOf(m)
+	if !found || id.Workspace != c.clusterName {
+		return
+	}
+	c.queue.Add(cache.NewObjectName(id.Namespace, id.Name).String())
+}
+
+// existing returns the downstream object in the informer, or nil.
+func (c *controller) existing(namespace, name string) *unstructured.Unstructured {
+	obj, exists, err := c.downstreamInformer.GetIndexer().GetByKey(cache.NewObjectName(namespace, name).String())
+	if err != nil || !exists {
+		return nil
+	}
+	return obj.(*unstructured.Unstructured)
+}
+
+// resyncPlacement resolves the placement again if a placed object changed,
+// as its changes may change the objects exported with it.
+func (c *controller) resyncPlacement(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	if c.placement.Placed(closure.Reference{GroupKind: c.kind, Namespace: m.GetNamespace(), Name: m.GetName()}) {
+		c.placement.Resync()
+	}
+}
+
+// referencesChanged returns whether an update may have changed the objects
+// referenced by an object, i.e. changed more than its status and metadata
+// other than labels.
+func referencesChanged(old, obj *unstructured.Unstructured) bool {
+	if !equality.Semantic.DeepEqual(old.GetLabels(), obj.GetLabels()) {
+		return true
+	}
+	for field, value := range obj.Object {
+		if field != "metadata" && field != "status" && !equality.Semantic.DeepEqual(old.Object[field], value) {
+			return true
+		}
+	}
+	for field := range old.Object {
+		if _, found := obj.Object[field]; !found {
+			return true
+		}
+	}
+	return false
+}
+
+// placed returns whether an upstream object is placed on the SyncTarget.
+// All objects of the cluster-scoped resources of the SyncTarget are.
+func (c *controller) placed(namespace, name string) bool {
+	if syncTarget := c.syncTarget.Load(); syncTarget != nil && !c.namespaced {
+		for _, r := range syncTarget.Spec.ClusterScopedResources {
+			if r.Group == c.gvr.Group && r.Resource == c.gvr.Resource {
+				return true
+			}
+		}
+	}
+	return c.placement.Placed(closure.Reference{GroupKind: c.kind, Namespace: namespace, Name: name})
+}
+
+// writeStatus queues the status of a downstream object to be reported on its
+// upstream object, see syncer.newStatusWriter, unless status is not synced
+// or the upstream object is gone or no longer placed.
+func (c *controller) writeStatus(obj interface{}) {
+	if c.statusWriter == nil {
+		return
+	}
+	downstream, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	objStatus, found := downstream.Object["status"]
+	if !found {
+		return
+	}
+	id, found := naming.UpstreamOf(downstream)
+	if !found || id.Workspace != c.clusterName {
+		return
+	}
+	if _, exists, err := c.upstreamInformer.GetIndexer().GetByKey(cache.NewObjectName(id.Namespace, id.Name).String()); err != nil || !exists {
+		return
+	}
+	if !c.placed(id.Namespace, id.Name) {
+		return
+	}
+	upstream := &unstructured.Unstructured{Object: map[string]interface{}{"status": objStatus}}
+	upstream.SetAPIVersion(downstream.GetAPIVersion())
+	upstream.SetKind(downstream.GetKind())
+	upstream.SetNamespace(id.Namespace)
+	upstream.SetName(id.Name)
+	c.statusWriter.Write(c.clusterName, c.gvr, upstream)
+}
+
+// Run runs the controller until ctx is done.
+func (c *controller) Run(ctx context.Context) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+	if c.criticalSynced != nil {
+		defer c.criticalSynced()
+	}
+
+	logger := klog.FromContext(ctx).WithValues("gvr", c.gvr.String())
+	ctx = klog.NewContext(ctx, logger)
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	if c.diagnostics != nil {
+		name := multitarget.QueueName(c.target, c.gvr.String())
+		c.diagnostics.RegisterQueue(name, diagnostics.LengthInspector(name, c.queue))
+		defer c.diagnostics.UnregisterQueue(name)
+	}
+
+	go c.upstreamInformer.Run(ctx.Done())
+	go c.downstreamInformer.Run(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), c.upstreamInformer.HasSynced, c.upstreamHandler.HasSynced, c.downstreamInformer.HasSynced, c.placement.HasSynced) {
+		return
+	}
+
+	if c.criticalSynced != nil {
+		go func() {
+			// The objects queued at the start are synced once the queue
+			// ran empty.
+			if err := wait.PollUntilContextCancel(ctx, criticalPollInterval, true, func(context.Context) (bool, error) {
+				return c.queue.Len() == 0 && c.active.Load() == 0, nil
+			}); err == nil {
+				logger.V(2).Info("synced the objects of critical resource")
+			}
+			c.criticalSynced()
+		}()
+	} else if err := c.critical.wait(ctx); err != nil {
+		return
+	}
+
+	for range c.workers {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+// Ready returns whether the informers and the placement have synced.
+func (c *controller) Ready() bool {
+	return c.upstreamInformer.HasSynced() && c.downstreamInformer.HasSynced() && c.placement.HasSynced()
+}
+
+// startWorker processes the queue while the SyncTarget is not paused.
+// Changes queued while paused are coalesced until it is resumed.
+func (c *controller) startWorker(ctx context.Context) {
+	for c.pause.Wait(ctx) == nil && c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	c.active.Add(1)
+	defer c.active.Add(-1)
+	defer c.queue.Done(key)
+
+	logger := klog.FromContext(ctx).WithValues("key", key)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(4).Info("processing key")
+
+	if err := c.process(ctx, key); err != nil {
+		utilruntime.HandleError(fmt.Errorf("failed to sync %s %q: %w", c.gvr, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return nil
+	}
+	downstreamNamespace := ""
+	if namespace != "" {
+		downstreamNamespace = naming.Namespace(c.clusterName, namespace)
+	}
+
+	obj, exists, err := c.upstreamInformer.GetIndexer().GetByKey(key)
+	if err != nil {
+		return err
+	}
+	placed := c.placed(namespace, name)
+	var upstream *unstructured.Unstructured
+	if exists {
+		upstream = obj.(*unstructured.Unstructured)
+	} else if placed {
+		// The syncer virtual workspace passes only the changes of objects
+		// that were placed at the time, so newly placed objects that did
+		// not change since are read.
+		upstream, err = c.upstream.Resource(c.gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			upstream = nil
+		} else if err != nil {
+			return err
+		}
+	}
+	if upstream == nil || upstream.GetDeletionTimestamp() != nil || !placed {
+		c.guardrails.Remove(c.guardrailKey(key))
+		if c.feedback != nil {
+			c.feedback.Forget(c.clusterName, c.gvr, namespace, name)
+		}
+		if c.arbitrated() {
+			return c.release(ctx, name)
+		}
+		if _, exists, err := c.downstreamInformer.GetIndexer().GetByKey(cache.NewObjectName(downstreamNamespace, name).String()); err != nil || !exists {
+			return err
+		}
+		klog.FromContext(ctx).V(2).Info("deleting downstream object")
+		if err := c.deleteDownstream(ctx, downstreamNamespace, name); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	if !c.namespaced && !c.arbitrated() {
+		if err := c.checkOwner(ctx, name); err != nil {
+			return err
+		}
+	}
+	downstreamObj, err := c.downstreamObject(upstream)
+	if err != nil {
+		return err
+	}
+	if err := c.mapPriorityClass(ctx, downstreamObj); err != nil {
+		return err
+	}
+	if err := c.admit(key, downstreamObj); errors.Is(err, guardrail.ErrGuardrailExceeded) {
+		// Tried again later, as other objects may be removed meanwhile.
+		klog.FromContext(ctx).V(2).Info("not syncing object beyond the guardrails of the SyncTarget", "err", err)
+		c.queue.AddAfter(key, guardrailRetryInterval)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if c.arbitrated() {
+		return c.resolve(ctx, downstreamObj)
+	}
+	if existing := c.existing(downstreamObj.GetNamespace(), downstreamObj.GetName()); existing != nil && semanticdiff.Unchanged(c.gvr.GroupResource(), downstreamObj, existing) {
+		// Applying would not change the downstream object.
+		return c.reportAdmission(ctx, key, upstream, nil)
+	}
+	err = c.applyDownstream(ctx, downstreamObj)
+	if apierrors.IsConflict(err) && c.config.ConflictStrategy == workloadv1alpha1.ConflictStrategyPreserve {
+		// Fields changed by others are kept.
+		klog.FromContext(ctx).V(4).Info("keeping downstream fields changed by others", "err", err)
+		return nil
+	}
+	return c.reportAdmission(ctx, key, upstream, err)
+}
+
+// reportAdmission reports the result of applying the downstream object of
+// upstream on it, see admissionfeedback.Reporter, and returns the error to
+// retry the key with, if any. Objects denied by admission webhooks are not
+// retried until they change, and writes failing because a webhook is
+// unavailable are retried after the backoff of the reporter.
+func (c *controller) reportAdmission(ctx context.Context, key string, upstream *unstructured.Unstructured, err error) error {
+	if c.feedback == nil || c.batcher != nil {
+		return err
+	}
+	result, reportErr := c.feedback.Report(ctx, c.clusterName, c.gvr, upstream, err)
+	if reportErr != nil {
+		utilruntime.HandleError(fmt.Errorf("failed to report the admission of %s %q: %w", c.gvr, key, reportErr))
+	}
+	if result.RequeueAfter > 0 {
+		c.queue.AddAfter(key, result.RequeueAfter)
+		return nil
+	}
+	if classification, _ := admissionfeedback.Classify(err); classification == admissionfeedback.Denied {
+		return nil
+	}
+	return err
+}
+
+// checkOwner returns an error if a cluster-scoped downstream object of the
+// given name exists that is not synced for the SyncTarget.
+func (c *controller) checkOwner(ctx context.Context, name string) error {
+	existing, err := c.downstream.Resource(c.gvr).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if existing.GetLabels()[LabelSyncTarget] != c.key {
+		return fmt.Errorf("downstream %s %s exists and is not synced by this syncer", c.gvr.GroupResource(), name)
+	}
+	if id, found := naming.UpstreamOf(existing); found && id.Workspace != c.clusterName {
+		return fmt.Errorf("downstream %s %s is synced from logical cluster %s", c.gvr.GroupResource(), name, id.Workspace)
+	}
+	return nil
+}
+
+// downstreamObject returns the downstream copy of an upstream object: its
+// content without status, rendered for the SyncTarget if it is a fan-out
+// ConfigMap, in the downstream namespace, with the labels and annotations
+// the PropagationPolicies of the workspace propagate and the tolerations
+// and node selectors its SchedulingDefaults inject, labeled for the
+// SyncTarget, still marked sensitive if it is, and recording the upstream
+// identity. It fails if the object,
+// the policies or the defaults reference metadata the SyncTarget does not
+// have, or if they are invalid, see fanout.Render, propagation.Apply and
+// schedulingdefaults.Apply.
+func (c *controller) downstreamObject(upstream *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	syncTarget := c.syncTarget.Load()
+	rendered := upstream
+	if fanout.Enabled(upstream) {
+		if syncTarget == nil {
+			return nil, fmt.Errorf("cannot render ConfigMap %s/%s before the SyncTarget is read", upstream.GetNamespace(), upstream.GetName())
+		}
+		var err error
+		if rendered, err = fanout.Render(upstream, syncTarget); err != nil {
+			return nil, fmt.Errorf("failed to render ConfigMap %s/%s: %w", upstream.GetNamespace(), upstream.GetName(), err)
+		}
+	}
+	var policies []*workloadv1alpha1.PropagationPolicy
+	if p := c.policies.Load(); p != nil {
+		policies = *p
+	}
+	propagated, err := propagation.Apply(rendered, c.gvr.GroupResource(), policies, syncTarget)
+	if err != nil {
+		return nil, fmt.Errorf("failed to propagate the labels and annotations: %w", err)
+	}
+	if d := c.schedulingDefaults.Load(); d != nil && len(*d) > 0 {
+		if syncTarget == nil {
+			return nil, fmt.Errorf("cannot apply the SchedulingDefaults to %s/%s before the SyncTarget is read", upstream.GetNamespace(), upstream.GetName())
+		}
+		// After propagation, so that object selectors see the propagated
+		// labels.
+		if propagated, err = schedulingdefaults.Apply(propagated, *d, syncTarget); err != nil {
+			return nil, fmt.Errorf("failed to apply the SchedulingDefaults: %w", err)
+		}
+	}
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	for field, value := range propagated.Object {
+		if field == "metadata" || field == "status" {
+			continue
+		}
+		obj.Object[field] = value
+	}
+	obj.SetName(upstream.GetName())
+	if namespace := upstream.GetNamespace(); namespace != "" {
+		obj.SetNamespace(naming.Namespace(c.clusterName, namespace))
+	}
+	objLabels := propagated.GetLabels()
+	if objLabels == nil {
+		objLabels = map[string]string{}
+	}
+	objLabels[LabelSyncTarget] = c.key
+	obj.SetLabels(objLabels)
+	annotations := propagated.GetAnnotations()
+	if sensitive.Sensitive(upstream) {
+		// Kept, unlike the other internal annotations, so that the object
+		// stays encrypted while queued and redacted in the audit log, see
+		// package sensitive.
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[workloadv1alpha1.AnnotationSensitive] = "true"
+	}
+	if len(annotations) > 0 {
+		obj.SetAnnotations(annotations)
+	}
+	naming.SetUpstream(obj, naming.Identity{
+		Workspace: c.clusterName,
+		Path:      c.workspacePath(),
+		Namespace: upstream.GetNamespace(),
+		Name:      upstream.GetName(),
+	})
+	return obj, nil
+}
+
+// upstreamNamespace returns the upstream namespace of a downstream object.
+func upstreamNamespace(obj *unstructured.Unstructured) string {
+	return obj.GetAnnotations()[naming.AnnotationUpstreamNamespace]
+}
@@ -0,0 +1,398 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/clusterscoped"
+	"github.com/kcp-dev/kcp/pkg/syncer/controllermanager"
+	"github.com/kcp-dev/kcp/pkg/syncer/multitarget"
+	"github.com/kcp-dev/kcp/pkg/syncer/naming"
+	"github.com/kcp-dev/kcp/pkg/syncer/status"
+	"github.com/kcp-dev/kcp/pkg/syncer/transport/batch"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var (
+	configMapsGVR      = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	deploymentsGVR     = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	clusterRolesGVR    = schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}
+	serviceMonitorsGVR = schema.GroupVersionResource{Group: "monitoring.coreos.com", Version: "v1", Resource: "servicemonitors"}
+)
+
+func newTestController(t *testing.T, gvr schema.GroupVersionResource, config controllermanager.ResourceConfig, downstreamObjects ...runtime.Object) *controller {
+	t.Helper()
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"}, meta.RESTScopeRoot)
+	listKinds := map[schema.GroupVersionResource]string{configMapsGVR: "ConfigMapList", clusterRolesGVR: "ClusterRoleList", distributionsGVR: "WorkloadDistributionList"}
+	upstream := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+	downstream := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, downstreamObjects...)
+
+	s := newSyncer(multitarget.Target{Path: logicalcluster.NewPath("root:org"), Name: "edge"}, "abc", upstream, downstream, mapper)
+	s.setSyncTarget(&tmcv1alpha1.SyncTarget{Spec: tmcv1alpha1.SyncTargetSpec{
+		ClusterScopedResources: []tmcv1alpha1.ClusterScopedResource{{Group: "rbac.authorization.k8s.io", Resource: "clusterroles"}},
+	}})
+	place(t, s, newDistribution("default", "app", "ConfigMap", "edge"))
+	c, err := s.newController(gvr, config)
+	require.NoError(t, err)
+	return c.(*controller)
+}
+
+// newDistribution returns a WorkloadDistribution of a core workload synced
+// to the targets.
+func newDistribution(namespace, name, kind string, targets ...string) *workloadv1alpha1.WorkloadDistribution {
+	d := &workloadv1alpha1.WorkloadDistribution{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: workloadv1alpha1.WorkloadDistributionSpec{
+			WorkloadRef: workloadv1alpha1.WorkloadReference{APIVersion: "v1", Kind: kind, Name: name},
+		},
+	}
+	for _, target := range targets {
+		d.Status.Targets = append(d.Status.Targets, workloadv1alpha1.TargetPlacement{SyncTarget: target})
+	}
+	return d
+}
+
+// place replaces the WorkloadDistributions of the syncer and resolves its
+// placement.
+func place(t *testing.T, s *syncer, distributions ...*workloadv1alpha1.WorkloadDistribution) {
+	t.Helper()
+	objs := make([]interface{}, 0, len(distributions))
+	for _, d := range distributions {
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(d)
+		require.NoError(t, err)
+		objs = append(objs, &unstructured.Unstructured{Object: u})
+	}
+	require.NoError(t, s.placement.informer.GetStore().Replace(objs, ""))
+	require.NoError(t, s.placement.process(context.Background()))
+}
+
+func newObject(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+func TestDownstreamObject(t *testing.T) {
+	c := newTestController(t, configMapsGVR, controllermanager.ResourceConfig{})
+
+	upstream := newObject("v1", "ConfigMap", "default", "app")
+	upstream.SetUID("uid")
+	upstream.SetResourceVersion("42")
+	upstream.SetLabels(map[string]string{"app": "web", "workload.kcp.io/priority-class": "critical"})
+	upstream.SetAnnotations(map[string]string{"kcp.io/cluster": "abc"})
+	upstream.Object["data"] = map[string]interface{}{"key": "value"}
+	upstream.Object["status"] = map[string]interface{}{"phase": "Ready"}
+
+	obj, err := c.downstreamObject(upstream)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"data":       map[string]interface{}{"key": "value"},
+		"metadata": map[string]interface{}{
+			"name":      "app",
+			"namespace": naming.Namespace("abc", "default"),
+			"labels":    map[string]interface{}{"app": "web", LabelSyncTarget: SyncTargetKey("abc", "edge")},
+			"annotations": map[string]interface{}{
+				naming.AnnotationUpstreamWorkspace: "abc",
+				naming.AnnotationUpstreamPath:      "root:org",
+				naming.AnnotationUpstreamNamespace: "default",
+				naming.AnnotationUpstreamName:      "app",
+			},
+		},
+	}, obj.Object)
+}
+
+func TestDownstreamObjectFollowsPropagationPolicies(t *testing.T) {
+	c := newTestController(t, configMapsGVR, controllermanager.ResourceConfig{})
+	upstream := newObject("v1", "ConfigMap", "default", "app")
+	upstream.SetLabels(map[string]string{"app": "web", "team": "a"})
+	upstream.SetAnnotations(map[string]string{"owner": "team-a"})
+
+	c.setPropagationPolicies([]*workloadv1alpha1.PropagationPolicy{{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge"},
+		Spec: workloadv1alpha1.PropagationPolicySpec{
+			Resources:   []string{"configmaps"},
+			Labels:      workloadv1alpha1.MetadataPropagation{Strip: []string{"team"}, Add: map[string]string{"env": "edge"}},
+			Annotations: workloadv1alpha1.MetadataPropagation{Propagate: []string{"none"}},
+		},
+	}})
+	obj, err := c.downstreamObject(upstream)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"app": "web", "env": "edge", LabelSyncTarget: SyncTargetKey("abc", "edge")}, obj.GetLabels())
+	require.NotContains(t, obj.GetAnnotations(), "owner")
+
+	c.setPropagationPolicies([]*workloadv1alpha1.PropagationPolicy{{
+		ObjectMeta: metav1.ObjectMeta{Name: "invalid"},
+		Spec: workloadv1alpha1.PropagationPolicySpec{
+			ObjectSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Bogus"}}},
+		},
+	}})
+	_, err = c.downstreamObject(upstream)
+	require.Error(t, err, "objects are not synced with an invalid policy")
+}
+
+func TestProcess(t *testing.T) {
+	downstreamNamespace := naming.Namespace("abc", "default")
+	owned := func(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+		obj := newObject(apiVersion, kind, namespace, name)
+		obj.SetLabels(map[string]string{LabelSyncTarget: SyncTargetKey("abc", "edge")})
+		naming.SetUpstream(obj, naming.Identity{Workspace: "abc", Name: name})
+		return obj
+	}
+	ownedByWorkspace := func(obj *unstructured.Unstructured) *unstructured.Unstructured {
+		annotations := obj.GetAnnotations()
+		annotations[clusterscoped.AnnotationOwners] = "abc"
+		obj.SetAnnotations(annotations)
+		return obj
+	}
+
+	tests := map[string]struct {
+		gvr        schema.GroupVersionResource
+		config     controllermanager.ResourceConfig
+		key        string
+		upstream   *unstructured.Unstructured
+		downstream []runtime.Object
+		applyErr   error
+
+		wantApplied []string
+		wantDeleted []string
+		wantErr     bool
+	}{
+		"new object is applied": {
+			gvr:         configMapsGVR,
+			key:         "default/app",
+			upstream:    newObject("v1", "ConfigMap", "default", "app"),
+			wantApplied: []string{downstreamNamespace + "/app"},
+		},
+		"deleted object is deleted downstream": {
+			gvr:         configMapsGVR,
+			key:         "default/app",
+			downstream:  []runtime.Object{owned("v1", "ConfigMap", downstreamNamespace, "app")},
+			wantDeleted: []string{downstreamNamespace + "/app"},
+		},
+		"deleted object without downstream copy": {
+			gvr: configMapsGVR,
+			key: "default/app",
+		},
+		"object that is not placed is not applied": {
+			gvr:      configMapsGVR,
+			key:      "default/kube-root-ca.crt",
+			upstream: newObject("v1", "ConfigMap", "default", "kube-root-ca.crt"),
+		},
+		"object no longer placed is deleted downstream": {
+			gvr:         configMapsGVR,
+			key:         "default/other",
+			upstream:    newObject("v1", "ConfigMap", "default", "other"),
+			downstream:  []runtime.Object{owned("v1", "ConfigMap", downstreamNamespace, "other")},
+			wantDeleted: []string{downstreamNamespace + "/other"},
+		},
+		"cluster-scoped object of others is not overwritten": {
+			gvr:        clusterRolesGVR,
+			key:        "admin",
+			upstream:   newObject("rbac.authorization.k8s.io/v1", "ClusterRole", "", "admin"),
+			downstream: []runtime.Object{newObject("rbac.authorization.k8s.io/v1", "ClusterRole", "", "admin")},
+			wantErr:    true,
+		},
+		"own cluster-scoped object is applied": {
+			gvr:         clusterRolesGVR,
+			key:         "reader",
+			upstream:    newObject("rbac.authorization.k8s.io/v1", "ClusterRole", "", "reader"),
+			downstream:  []runtime.Object{ownedByWorkspace(owned("rbac.authorization.k8s.io/v1", "ClusterRole", "", "reader"))},
+			wantApplied: []string{"reader"},
+		},
+		"cluster-scoped object released by its last workspace is deleted": {
+			gvr:         clusterRolesGVR,
+			key:         "reader",
+			downstream:  []runtime.Object{ownedByWorkspace(owned("rbac.authorization.k8s.io/v1", "ClusterRole", "", "reader"))},
+			wantDeleted: []string{"reader"},
+		},
+		"conflicts are kept with the preserve strategy": {
+			gvr:         configMapsGVR,
+			config:      controllermanager.ResourceConfig{ConflictStrategy: workloadv1alpha1.ConflictStrategyPreserve},
+			key:         "default/app",
+			upstream:    newObject("v1", "ConfigMap", "default", "app"),
+			applyErr:    apierrors.NewConflict(configMapsGVR.GroupResource(), "app", nil),
+			wantApplied: []string{downstreamNamespace + "/app"},
+		},
+		"conflicts fail with the overwrite strategy": {
+			gvr:         configMapsGVR,
+			key:         "default/app",
+			upstream:    newObject("v1", "ConfigMap", "default", "app"),
+			applyErr:    apierrors.NewConflict(configMapsGVR.GroupResource(), "app", nil),
+			wantApplied: []string{downstreamNamespace + "/app"},
+			wantErr:     true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := newTestController(t, tt.gvr, tt.config, tt.downstream...)
+			if tt.upstream != nil {
+				require.NoError(t, c.upstreamInformer.GetIndexer().Add(tt.upstream))
+			}
+			for _, obj := range tt.downstream {
+				require.NoError(t, c.downstreamInformer.GetIndexer().Add(obj))
+			}
+			var applied, deleted []string
+			c.applyDownstream = func(ctx context.Context, obj *unstructured.Unstructured) error {
+				applied = append(applied, key(obj.GetNamespace(), obj.GetName()))
+				return tt.applyErr
+			}
+			c.deleteDownstream = func(ctx context.Context, namespace, name string) error {
+				deleted = append(deleted, key(namespace, name))
+				return nil
+			}
+
+			err := c.process(context.Background(), tt.key)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantApplied, applied)
+			require.Equal(t, tt.wantDeleted, deleted)
+		})
+	}
+}
+
+func TestEnqueueDownstream(t *testing.T) {
+	c := newTestController(t, configMapsGVR, controllermanager.ResourceConfig{})
+
+	obj := newObject("v1", "ConfigMap", naming.Namespace("abc", "default"), "app")
+	naming.SetUpstream(obj, naming.Identity{Workspace: "abc", Namespace: "default", Name: "app"})
+	c.enqueueDownstream(obj)
+	other := newObject("v1", "ConfigMap", naming.Namespace("def", "default"), "app")
+	naming.SetUpstream(other, naming.Identity{Workspace: "def", Namespace: "default", Name: "other"})
+	c.enqueueDownstream(other)
+
+	require.Equal(t, 1, c.queue.Len())
+	item, _ := c.queue.Get()
+	require.Equal(t, "default/app", item, "the upstream object is queued")
+}
+
+func TestWriteStatus(t *testing.T) {
+	downstream := func(workspace logicalcluster.Name, name string, withStatus bool) *unstructured.Unstructured {
+		obj := newObject("v1", "ConfigMap", naming.Namespace(workspace, "default"), name)
+		naming.SetUpstream(obj, naming.Identity{Workspace: workspace, Namespace: "default", Name: name})
+		if withStatus {
+			obj.Object["status"] = map[string]interface{}{"phase": "Ready"}
+		}
+		return obj
+	}
+
+	tests := map[string]struct {
+		obj         *unstructured.Unstructured
+		wantPending int
+	}{
+		"status is written": {
+			obj:         downstream("abc", "app", true),
+			wantPending: 1,
+		},
+		"object without status": {
+			obj: downstream("abc", "app", false),
+		},
+		"object of another workspace": {
+			obj: downstream("def", "app", true),
+		},
+		"upstream object is gone": {
+			obj: downstream("abc", "gone", true),
+		},
+		"upstream object not rolled out to the SyncTarget": {
+			obj: downstream("abc", "canary", true),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := newTestController(t, configMapsGVR, controllermanager.ResourceConfig{})
+			require.NoError(t, c.upstreamInformer.GetIndexer().Add(newObject("v1", "ConfigMap", "default", "app")))
+			require.NoError(t, c.upstreamInformer.GetIndexer().Add(newObject("v1", "ConfigMap", "default", "canary")))
+			canary := newDistribution("default", "canary", "ConfigMap", "west", "edge")
+			canary.Spec.RolloutStrategy = &workloadv1alpha1.RolloutStrategy{Type: workloadv1alpha1.RolloutCanary}
+			canary.Status.Rollout = &workloadv1alpha1.RolloutStatus{Phase: workloadv1alpha1.RolloutProgressing, SyncedTargets: []string{"west"}}
+			place(t, c.syncer, newDistribution("default", "app", "ConfigMap", "edge"), canary)
+			c.statusWriter = status.NewWriter(nil, status.Options{})
+
+			c.writeStatus(tt.obj)
+			require.Equal(t, tt.wantPending, c.statusWriter.Pending())
+		})
+	}
+}
+
+func TestBatchedWrites(t *testing.T) {
+	s := newTestController(t, configMapsGVR, controllermanager.ResourceConfig{}).syncer
+	s.batcher = batch.NewBatcher(s.sendBatch, batch.Options{})
+	c, err := s.newController(configMapsGVR, controllermanager.ResourceConfig{ConflictStrategy: workloadv1alpha1.ConflictStrategyPreserve})
+	require.NoError(t, err)
+	batched := c.(*controller)
+
+	require.NoError(t, batched.applyDownstream(context.Background(), newObject("v1", "ConfigMap", "ns", "app")))
+	require.NoError(t, batched.deleteDownstream(context.Background(), "ns", "old"))
+	require.Equal(t, 2, batched.batcher.Pending(), "writes are queued")
+
+	var applied, deleted []string
+	value, _ := batched.writers.Load(configMapsGVR)
+	writer := value.(*downstreamWriter)
+	writer.apply = func(ctx context.Context, obj *unstructured.Unstructured) error {
+		applied = append(applied, key(obj.GetNamespace(), obj.GetName()))
+		return apierrors.NewConflict(configMapsGVR.GroupResource(), obj.GetName(), nil)
+	}
+	writer.delete = func(ctx context.Context, namespace, name string) error {
+		deleted = append(deleted, key(namespace, name))
+		return apierrors.NewServiceUnavailable("unavailable")
+	}
+	batched.batcher.Flush(context.Background())
+
+	require.Equal(t, []string{"ns/app"}, applied)
+	require.Equal(t, []string{"ns/old"}, deleted)
+	require.Equal(t, 1, batched.batcher.Pending(), "the failed delete is sent again, the preserved conflict is not")
+}
+
+func TestNewControllerRejectsBidirectionalSync(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	s := newSyncer(multitarget.Target{Path: logicalcluster.NewPath("root:org"), Name: "edge"}, "abc", nil, nil, mapper)
+	_, err := s.newController(configMapsGVR, controllermanager.ResourceConfig{Direction: workloadv1alpha1.SyncDirectionBidirectional})
+	require.Error(t, err)
+	_, err = s.newController(configMapsGVR, controllermanager.ResourceConfig{})
+	require.Error(t, err, "resources the physical cluster does not serve are rejected")
+}
+
+func key(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "/" + name
+}
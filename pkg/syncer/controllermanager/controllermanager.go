@@ -0,0 +1,407 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controllermanager runs the per-GVR resource controllers of the
+// syncer and reconfigures them at runtime: when the syncer configuration
+// changes, e.g. its file or the SyncConfigurations of its workspace, only
+// controllers of added, removed or changed resources are started, drained
+// or restarted, while all others keep running.
+package controllermanager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/priority"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+const (
+	defaultDrainTimeout = 30 * time.Second
+	defaultSoakTimeout  = 2 * time.Minute
+	defaultSoakPeriod   = 10 * time.Second
+)
+
+// ResourceConfig is the configuration of the controller of one resource.
+type ResourceConfig struct {
+	// TransformPolicy names the transformation policy applied to the
+	// resource, if any.
+	TransformPolicy string `json:"transformPolicy,omitempty"`
+	// Workers is the number of workers of the controller. Zero means the
+	// default.
+	Workers int `json:"workers,omitempty"`
+	// Priority is the sync priority class of objects of the resource. Empty
+	// means the default class of the resource.
+	Priority priority.Class `json:"priority,omitempty"`
+	// Direction is the direction objects of the resource are synced in.
+	// Empty means down only.
+	Direction workloadv1alpha1.SyncDirection `json:"direction,omitempty"`
+	// ResyncInterval is how often synced objects are reconciled even if
+	// they did not change. Zero means the default.
+	ResyncInterval metav1.Duration `json:"resyncInterval,omitempty"`
+	// ConflictStrategy decides what happens to fields of downstream objects
+	// changed by others than the syncer. Empty means they are overwritten.
+	ConflictStrategy workloadv1alpha1.ConflictStrategy `json:"conflictStrategy,omitempty"`
+	// DeletionPropagation is the propagation policy downstream objects are
+	// deleted with. Empty means background deletion.
+	DeletionPropagation metav1.DeletionPropagation `json:"deletionPropagation,omitempty"`
+}
+
+// Validate returns an error if the configuration is invalid.
+func (rc ResourceConfig) Validate() error {
+	if rc.Workers < 0 {
+		return fmt.Errorf("workers must not be negative")
+	}
+	if err := rc.Priority.Validate(); err != nil {
+		return err
+	}
+	switch rc.Direction {
+	case "", workloadv1alpha1.SyncDirectionDown, workloadv1alpha1.SyncDirectionBidirectional:
+	default:
+		return fmt.Errorf("unknown direction %q", rc.Direction)
+	}
+	if rc.ResyncInterval.Duration < 0 {
+		return fmt.Errorf("resyncInterval must not be negative")
+	}
+	switch rc.ConflictStrategy {
+	case "", workloadv1alpha1.ConflictStrategyOverwrite, workloadv1alpha1.ConflictStrategyPreserve:
+	default:
+		return fmt.Errorf("unknown conflictStrategy %q", rc.ConflictStrategy)
+	}
+	switch rc.DeletionPropagation {
+	case "", metav1.DeletePropagationOrphan, metav1.DeletePropagationBackground, metav1.DeletePropagationForeground:
+	default:
+		return fmt.Errorf("unknown deletionPropagation %q", rc.DeletionPropagation)
+	}
+	return nil
+}
+
+// Config is the set of synced resources and their configuration.
+type Config map[schema.GroupVersionResource]ResourceConfig
+
+// Priorities returns the configured sync priority classes by resource, for
+// use with priority.NewClassifier.
+func (c Config) Priorities() map[schema.GroupResource]priority.Class {
+	priorities := map[schema.GroupResource]priority.Class{}
+	for gvr, rc := range c {
+		if rc.Priority != "" {
+			priorities[gvr.GroupResource()] = rc.Priority
+		}
+	}
+	return priorities
+}
+
+// Controller is a per-GVR resource controller.
+type Controller interface {
+	// Run runs the controller until ctx is done. It returns once all workers
+	// have drained.
+	Run(ctx context.Context)
+	// Ready returns true once the controller has synced and is healthy.
+	Ready() bool
+}
+
+// ControllerFactory creates the controller of a resource.
+type ControllerFactory func(gvr schema.GroupVersionResource, config ResourceConfig) (Controller, error)
+
+// Options configure the rollout of configuration changes.
+type Options struct {
+	// DrainTimeout bounds waiting for a stopped controller to drain.
+	DrainTimeout time.Duration
+	// SoakTimeout bounds waiting for a restarted controller to become ready.
+	SoakTimeout time.Duration
+	// SoakPeriod is the time a restarted controller must stay ready before
+	// the next changed controller is restarted. Zero only waits for
+	// readiness.
+	SoakPeriod time.Duration
+}
+
+// Delta is the difference between two configurations.
+type Delta struct {
+	Added   []schema.GroupVersionResource
+	Removed []schema.GroupVersionResource
+	Changed []schema.GroupVersionResource
+}
+
+// Empty returns true if the configurations are equal.
+func (d Delta) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// ComputeDelta returns the resources added, removed and changed going from
+// the old to the new configuration, each sorted.
+func ComputeDelta(oldConfig, newConfig Config) Delta {
+	var d Delta
+	for gvr, newResource := range newConfig {
+		oldResource, found := oldConfig[gvr]
+		switch {
+		case !found:
+			d.Added = append(d.Added, gvr)
+		case oldResource != newResource:
+			d.Changed = append(d.Changed, gvr)
+		}
+	}
+	for gvr := range oldConfig {
+		if _, found := newConfig[gvr]; !found {
+			d.Removed = append(d.Removed, gvr)
+		}
+	}
+	sortGVRs(d.Added)
+	sortGVRs(d.Removed)
+	sortGVRs(d.Changed)
+	return d
+}
+
+type runningController struct {
+	controller Controller
+	config     ResourceConfig
+	cancel     context.CancelFunc
+	done       chan struct{}
+}
+
+// Manager runs one controller per configured resource.
+type Manager struct {
+	factory ControllerFactory
+	options Options
+
+	lock    sync.Mutex
+	running map[schema.GroupVersionResource]*runningController
+}
+
+// DefaultOptions returns the default rollout options.
+func DefaultOptions() Options {
+	return Options{
+		DrainTimeout: defaultDrainTimeout,
+		SoakTimeout:  defaultSoakTimeout,
+		SoakPeriod:   defaultSoakPeriod,
+	}
+}
+
+// NewManager returns a manager creating controllers with the given factory.
+func NewManager(factory ControllerFactory, options Options) *Manager {
+	if options.DrainTimeout <= 0 {
+		options.DrainTimeout = defaultDrainTimeout
+	}
+	if options.SoakTimeout <= 0 {
+		options.SoakTimeout = defaultSoakTimeout
+	}
+	return &Manager{
+		factory: factory,
+		options: options,
+		running: map[schema.GroupVersionResource]*runningController{},
+	}
+}
+
+// Run applies every configuration received from configs until ctx is done,
+// and stops all controllers afterwards. A failed rollout is logged; the next
+// configuration is applied against what is actually running.
+func (m *Manager) Run(ctx context.Context, configs <-chan Config) {
+	defer utilruntime.HandleCrash()
+	defer m.stopAll(klog.FromContext(ctx))
+
+	logger := klog.FromContext(ctx).WithName("controllermanager")
+	ctx = klog.NewContext(ctx, logger)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case config, ok := <-configs:
+			if !ok {
+				<-ctx.Done()
+				return
+			}
+			if err := m.Apply(ctx, config); err != nil {
+				utilruntime.HandleError(fmt.Errorf("failed to apply syncer configuration: %w", err))
+			}
+		}
+	}
+}
+
+// Apply reconciles the running controllers with config. Removed resources
+// are drained, added resources are started, and changed resources are
+// restarted one by one, each soaking before the next. If a restarted
+// controller does not become ready, the rollout stops and the remaining
+// changed controllers keep their previous configuration.
+func (m *Manager) Apply(ctx context.Context, config Config) error {
+	logger := klog.FromContext(ctx)
+
+	delta := ComputeDelta(m.Config(), config)
+	if delta.Empty() {
+		return nil
+	}
+	logger.Info("applying syncer configuration change", "added", len(delta.Added), "removed", len(delta.Removed), "changed", len(delta.Changed))
+
+	for _, gvr := range delta.Removed {
+		m.stop(logger, gvr)
+	}
+
+	var errs []error
+	for _, gvr := range delta.Added {
+		if err := m.start(ctx, gvr, config[gvr]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return utilerrors.NewAggregate(errs)
+	}
+
+	for i, gvr := range delta.Changed {
+		m.stop(logger, gvr)
+		if err := m.start(ctx, gvr, config[gvr]); err != nil {
+			return err
+		}
+		if err := m.soak(ctx, gvr); err != nil {
+			return fmt.Errorf("aborted rollout with %d of %d changed controllers pending: %w", len(delta.Changed)-i-1, len(delta.Changed), err)
+		}
+	}
+
+	return nil
+}
+
+// Config returns the configuration of the running controllers.
+func (m *Manager) Config() Config {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	config := make(Config, len(m.running))
+	for gvr, r := range m.running {
+		config[gvr] = r.config
+	}
+	return config
+}
+
+func (m *Manager) start(ctx context.Context, gvr schema.GroupVersionResource, config ResourceConfig) error {
+	controller, err := m.factory(gvr, config)
+	if err != nil {
+		return fmt.Errorf("failed to create controller for %s: %w", gvr, err)
+	}
+
+	controllerCtx, cancel := context.WithCancel(ctx)
+	r := &runningController{
+		controller: controller,
+		config:     config,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+	go func() {
+		defer close(r.done)
+		defer utilruntime.HandleCrash()
+		controller.Run(controllerCtx)
+	}()
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.running[gvr] = r
+
+	klog.FromContext(ctx).V(2).Info("started resource controller", "gvr", gvr.String())
+	return nil
+}
+
+func (m *Manager) stop(logger klog.Logger, gvr schema.GroupVersionResource) {
+	m.lock.Lock()
+	r, found := m.running[gvr]
+	delete(m.running, gvr)
+	m.lock.Unlock()
+	if !found {
+		return
+	}
+
+	r.cancel()
+	select {
+	case <-r.done:
+		logger.V(2).Info("stopped resource controller", "gvr", gvr.String())
+	case <-time.After(m.options.DrainTimeout):
+		logger.Info("resource controller did not drain in time, abandoning it", "gvr", gvr.String(), "timeout", m.options.DrainTimeout)
+	}
+}
+
+func (m *Manager) stopAll(logger klog.Logger) {
+	m.lock.Lock()
+	gvrs := make([]schema.GroupVersionResource, 0, len(m.running))
+	for gvr := range m.running {
+		gvrs = append(gvrs, gvr)
+	}
+	m.lock.Unlock()
+
+	var wg sync.WaitGroup
+	for _, gvr := range gvrs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.stop(logger, gvr)
+		}()
+	}
+	wg.Wait()
+}
+
+// soak waits for the controller of gvr to become ready and to stay ready
+// for the soak period.
+func (m *Manager) soak(ctx context.Context, gvr schema.GroupVersionResource) error {
+	m.lock.Lock()
+	r, found := m.running[gvr]
+	m.lock.Unlock()
+	if !found {
+		return fmt.Errorf("controller for %s is not running", gvr)
+	}
+
+	soakCtx, cancel := context.WithTimeout(ctx, m.options.SoakTimeout)
+	defer cancel()
+	var readySince time.Time
+	err := wait.PollUntilContextCancel(soakCtx, soakPollInterval(m.options.SoakPeriod), true, func(ctx context.Context) (bool, error) {
+		select {
+		case <-r.done:
+			return false, fmt.Errorf("controller for %s exited", gvr)
+		default:
+		}
+		if !r.controller.Ready() {
+			readySince = time.Time{}
+			return false, nil
+		}
+		if readySince.IsZero() {
+			readySince = time.Now()
+		}
+		return time.Since(readySince) >= m.options.SoakPeriod, nil
+	})
+	if err != nil {
+		return fmt.Errorf("controller for %s did not soak: %w", gvr, err)
+	}
+	return nil
+}
+
+func soakPollInterval(period time.Duration) time.Duration {
+	interval := period / 10
+	if interval < 10*time.Millisecond {
+		return 10 * time.Millisecond
+	}
+	if interval > time.Second {
+		return time.Second
+	}
+	return interval
+}
+
+func sortGVRs(gvrs []schema.GroupVersionResource) {
+	sort.Slice(gvrs, func(i, j int) bool { return gvrs[i].String() < gvrs[j].String() })
+}
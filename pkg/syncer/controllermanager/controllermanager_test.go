@@ -0,0 +1,152 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllermanager
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/priority"
+)
+
+var (
+	deployments = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	services    = schema.GroupVersionResource{Version: "v1", Resource: "services"}
+	configmaps  = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+)
+
+type fakeController struct {
+	ready   atomic.Bool
+	stopped atomic.Bool
+}
+
+func (c *fakeController) Run(ctx context.Context) {
+	<-ctx.Done()
+	c.stopped.Store(true)
+}
+
+func (c *fakeController) Ready() bool { return c.ready.Load() }
+
+type fakeFactory struct {
+	lock       sync.Mutex
+	created    map[schema.GroupVersionResource][]*fakeController
+	neverReady map[string]bool
+}
+
+func (f *fakeFactory) create(gvr schema.GroupVersionResource, config ResourceConfig) (Controller, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	c := &fakeController{}
+	c.ready.Store(!f.neverReady[config.TransformPolicy])
+	f.created[gvr] = append(f.created[gvr], c)
+	return c, nil
+}
+
+func (f *fakeFactory) controllers(gvr schema.GroupVersionResource) []*fakeController {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.created[gvr]
+}
+
+func TestComputeDelta(t *testing.T) {
+	d := ComputeDelta(
+		Config{deployments: {}, services: {TransformPolicy: "a"}},
+		Config{services: {TransformPolicy: "b"}, configmaps: {}},
+	)
+	require.Equal(t, []schema.GroupVersionResource{configmaps}, d.Added)
+	require.Equal(t, []schema.GroupVersionResource{deployments}, d.Removed)
+	require.Equal(t, []schema.GroupVersionResource{services}, d.Changed)
+	require.True(t, ComputeDelta(Config{services: {}}, Config{services: {}}).Empty())
+}
+
+func TestApply(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	f := &fakeFactory{created: map[schema.GroupVersionResource][]*fakeController{}, neverReady: map[string]bool{"broken": true}}
+	m := NewManager(f.create, Options{SoakTimeout: 200 * time.Millisecond})
+
+	require.NoError(t, m.Apply(ctx, Config{deployments: {}, services: {}}))
+	require.Len(t, f.controllers(deployments), 1)
+	require.Len(t, f.controllers(services), 1)
+
+	// Changing one resource restarts only that controller.
+	require.NoError(t, m.Apply(ctx, Config{deployments: {}, services: {TransformPolicy: "a"}}))
+	require.Len(t, f.controllers(deployments), 1)
+	require.False(t, f.controllers(deployments)[0].stopped.Load())
+	require.Len(t, f.controllers(services), 2)
+	require.Eventually(t, f.controllers(services)[0].stopped.Load, time.Second, 10*time.Millisecond)
+
+	// Removing a resource drains its controller.
+	require.NoError(t, m.Apply(ctx, Config{services: {TransformPolicy: "a"}}))
+	require.True(t, f.controllers(deployments)[0].stopped.Load())
+	require.Equal(t, Config{services: {TransformPolicy: "a"}}, m.Config())
+}
+
+func TestApplyAbortsRolloutOnFailedSoak(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	f := &fakeFactory{created: map[schema.GroupVersionResource][]*fakeController{}, neverReady: map[string]bool{"broken": true}}
+	m := NewManager(f.create, Options{SoakTimeout: 100 * time.Millisecond})
+	require.NoError(t, m.Apply(ctx, Config{configmaps: {}, services: {}}))
+
+	err := m.Apply(ctx, Config{configmaps: {TransformPolicy: "broken"}, services: {TransformPolicy: "broken"}})
+	require.ErrorContains(t, err, "1 of 2 changed controllers pending")
+
+	// The first changed controller is restarted, the second is untouched.
+	require.Len(t, f.controllers(configmaps), 2)
+	require.Len(t, f.controllers(services), 1)
+	require.False(t, f.controllers(services)[0].stopped.Load())
+	require.Equal(t, Config{configmaps: {TransformPolicy: "broken"}, services: {}}, m.Config())
+}
+
+func TestParseConfig(t *testing.T) {
+	config, err := ParseConfig([]byte(`
+resources:
+- group: apps
+  version: v1
+  resource: deployments
+  transformPolicy: strip-replicas
+  priority: critical
+- version: v1
+  resource: services
+  workers: 4
+`))
+	require.NoError(t, err)
+	require.Equal(t, Config{
+		deployments: {TransformPolicy: "strip-replicas", Priority: priority.Critical},
+		services:    {Workers: 4},
+	}, config)
+	require.Equal(t, map[schema.GroupResource]priority.Class{deployments.GroupResource(): priority.Critical}, config.Priorities())
+
+	_, err = ParseConfig([]byte("resources:\n- version: v1\n  resource: services\n  priority: urgent\n"))
+	require.ErrorContains(t, err, `unknown sync priority class "urgent"`)
+
+	_, err = ParseConfig([]byte("resources:\n- version: v1\n  resource: services\n- version: v1\n  resource: services\n"))
+	require.ErrorContains(t, err, "duplicate resource")
+
+	_, err = ParseConfig([]byte("resources:\n- version: v1\n  resource: services\n  direction: Up\n"))
+	require.ErrorContains(t, err, `unknown direction "Up"`)
+}
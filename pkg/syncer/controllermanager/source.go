@@ -0,0 +1,112 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllermanager
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/yaml"
+)
+
+// FileConfig is the on-disk format of the syncer resource configuration,
+// e.g. mounted from a ConfigMap:
+//
+//	resources:
+//	- group: apps
+//	  version: v1
+//	  resource: deployments
+//	  transformPolicy: strip-replicas
+//	  priority: critical
+type FileConfig struct {
+	Resources []FileResource `json:"resources"`
+}
+
+// FileResource configures one resource in a FileConfig.
+type FileResource struct {
+	Group    string `json:"group,omitempty"`
+	Version  string `json:"version"`
+	Resource string `json:"resource"`
+
+	ResourceConfig `json:",inline"`
+}
+
+// ParseConfig parses a YAML or JSON FileConfig.
+func ParseConfig(data []byte) (Config, error) {
+	var fc FileConfig
+	if err := yaml.UnmarshalStrict(data, &fc); err != nil {
+		return nil, err
+	}
+
+	config := make(Config, len(fc.Resources))
+	for i, r := range fc.Resources {
+		if r.Version == "" || r.Resource == "" {
+			return nil, fmt.Errorf("resources[%d]: version and resource are required", i)
+		}
+		if err := r.ResourceConfig.Validate(); err != nil {
+			return nil, fmt.Errorf("resources[%d]: %w", i, err)
+		}
+		gvr := schema.GroupVersionResource{Group: r.Group, Version: r.Version, Resource: r.Resource}
+		if _, found := config[gvr]; found {
+			return nil, fmt.Errorf("resources[%d]: duplicate resource %s", i, gvr)
+		}
+		config[gvr] = r.ResourceConfig
+	}
+	return config, nil
+}
+
+// WatchFile polls the configuration file at path and sends its content
+// whenever it changes, starting with the initial content. Unreadable or
+// invalid content is reported and skipped, keeping the last good
+// configuration in place. The channel is closed when ctx is done.
+func WatchFile(ctx context.Context, path string, interval time.Duration) <-chan Config {
+	configs := make(chan Config)
+
+	go func() {
+		defer close(configs)
+
+		var last Config
+		wait.UntilWithContext(ctx, func(ctx context.Context) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				utilruntime.HandleError(fmt.Errorf("failed to read syncer configuration %s: %w", path, err))
+				return
+			}
+			config, err := ParseConfig(data)
+			if err != nil {
+				utilruntime.HandleError(fmt.Errorf("invalid syncer configuration %s: %w", path, err))
+				return
+			}
+			if last != nil && reflect.DeepEqual(last, config) {
+				return
+			}
+			select {
+			case configs <- config:
+				last = config
+			case <-ctx.Done():
+			}
+		}, interval)
+	}()
+
+	return configs
+}
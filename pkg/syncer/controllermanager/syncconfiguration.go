@@ -0,0 +1,151 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllermanager
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// SyncConfigurationSource returns the SyncTarget of the syncer and the
+// SyncConfigurations of its workspace.
+type SyncConfigurationSource func(ctx context.Context) (*tmcv1alpha1.SyncTarget, []*workloadv1alpha1.SyncConfiguration, error)
+
+// Overlay returns base with the settings of the SyncConfigurations
+// selecting syncTarget applied to the resources they configure. Resources
+// not in base are not synced, and their SyncConfigurations are ignored.
+func Overlay(base Config, syncTarget *tmcv1alpha1.SyncTarget, syncConfigs []*workloadv1alpha1.SyncConfiguration) (Config, error) {
+	sorted := make([]*workloadv1alpha1.SyncConfiguration, len(syncConfigs))
+	copy(sorted, syncConfigs)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	config := make(Config, len(base))
+	for gvr, rc := range base {
+		config[gvr] = rc
+	}
+	for _, sc := range sorted {
+		if sc.Spec.SyncTargetSelector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(sc.Spec.SyncTargetSelector)
+			if err != nil {
+				return nil, fmt.Errorf("invalid SyncConfiguration %q: %w", sc.Name, err)
+			}
+			if !selector.Matches(labels.Set(syncTarget.Labels)) {
+				continue
+			}
+		}
+		for gvr, rc := range config {
+			if gvr.Group != sc.Spec.Group || gvr.Resource != sc.Spec.Resource {
+				continue
+			}
+			if sc.Spec.Direction != "" {
+				rc.Direction = sc.Spec.Direction
+			}
+			if sc.Spec.ResyncInterval != nil {
+				rc.ResyncInterval = *sc.Spec.ResyncInterval
+			}
+			if sc.Spec.ConflictStrategy != "" {
+				rc.ConflictStrategy = sc.Spec.ConflictStrategy
+			}
+			if sc.Spec.TransformationProfile != "" {
+				rc.TransformPolicy = sc.Spec.TransformationProfile
+			}
+			if sc.Spec.DeletionPropagation != "" {
+				rc.DeletionPropagation = sc.Spec.DeletionPropagation
+			}
+			if err := rc.Validate(); err != nil {
+				return nil, fmt.Errorf("invalid SyncConfiguration %q: %w", sc.Name, err)
+			}
+			config[gvr] = rc
+		}
+	}
+	return config, nil
+}
+
+// WatchSyncConfigurations sends the configurations received from base
+// overlaid with the SyncConfigurations of source, which is polled, whenever
+// either changes. Nothing is sent before the first configuration of base
+// and the SyncConfigurations are known.
+// Failures to read or apply SyncConfigurations are reported and skipped,
+// keeping the last good configuration in place. The channel is closed when
+// ctx is done or base is closed.
+func WatchSyncConfigurations(ctx context.Context, base <-chan Config, source SyncConfigurationSource, interval time.Duration) <-chan Config {
+	configs := make(chan Config)
+
+	go func() {
+		defer close(configs)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var current, last Config
+		var syncTarget *tmcv1alpha1.SyncTarget
+		var syncConfigs []*workloadv1alpha1.SyncConfiguration
+		refresh := func() {
+			st, scs, err := source(ctx)
+			if err != nil {
+				utilruntime.HandleError(fmt.Errorf("failed to get SyncConfigurations: %w", err))
+				return
+			}
+			syncTarget, syncConfigs = st, scs
+		}
+		refresh()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case config, ok := <-base:
+				if !ok {
+					return
+				}
+				current = config
+			case <-ticker.C:
+				refresh()
+			}
+			if current == nil || syncTarget == nil {
+				continue
+			}
+
+			config, err := Overlay(current, syncTarget, syncConfigs)
+			if err != nil {
+				utilruntime.HandleError(err)
+				continue
+			}
+			if last != nil && reflect.DeepEqual(last, config) {
+				continue
+			}
+			select {
+			case configs <- config:
+				last = config
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return configs
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllermanager
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var edgeTarget = &tmcv1alpha1.SyncTarget{ObjectMeta: metav1.ObjectMeta{Name: "edge-1", Labels: map[string]string{"edge": "true"}}}
+
+func newSyncConfiguration(name, group, resource string, spec workloadv1alpha1.SyncConfigurationSpec) *workloadv1alpha1.SyncConfiguration {
+	spec.Group, spec.Resource = group, resource
+	return &workloadv1alpha1.SyncConfiguration{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec}
+}
+
+func TestOverlay(t *testing.T) {
+	base := Config{deployments: {TransformPolicy: "strip-replicas", Workers: 2}, services: {}}
+	config, err := Overlay(base, edgeTarget, []*workloadv1alpha1.SyncConfiguration{
+		newSyncConfiguration("b-deployments", "apps", "deployments", workloadv1alpha1.SyncConfigurationSpec{
+			ConflictStrategy: workloadv1alpha1.ConflictStrategyPreserve,
+		}),
+		newSyncConfiguration("a-deployments", "apps", "deployments", workloadv1alpha1.SyncConfigurationSpec{
+			Direction:             workloadv1alpha1.SyncDirectionBidirectional,
+			ResyncInterval:        &metav1.Duration{Duration: time.Minute},
+			ConflictStrategy:      workloadv1alpha1.ConflictStrategyOverwrite,
+			TransformationProfile: "edge",
+			DeletionPropagation:   metav1.DeletePropagationForeground,
+		}),
+		newSyncConfiguration("cloud-services", "", "services", workloadv1alpha1.SyncConfigurationSpec{
+			SyncTargetSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"edge": "false"}},
+			Direction:          workloadv1alpha1.SyncDirectionBidirectional,
+		}),
+		newSyncConfiguration("configmaps", "", "configmaps", workloadv1alpha1.SyncConfigurationSpec{
+			Direction: workloadv1alpha1.SyncDirectionBidirectional,
+		}),
+	})
+	require.NoError(t, err)
+	require.Equal(t, Config{
+		deployments: {
+			TransformPolicy:     "edge",
+			Workers:             2,
+			Direction:           workloadv1alpha1.SyncDirectionBidirectional,
+			ResyncInterval:      metav1.Duration{Duration: time.Minute},
+			ConflictStrategy:    workloadv1alpha1.ConflictStrategyPreserve,
+			DeletionPropagation: metav1.DeletePropagationForeground,
+		},
+		services: {},
+	}, config, "later SyncConfigurations win, unselected targets and unsynced resources are skipped")
+	require.Equal(t, ResourceConfig{}, base[services], "base is not modified")
+	require.Equal(t, "strip-replicas", base[deployments].TransformPolicy, "base is not modified")
+
+	_, err = Overlay(base, edgeTarget, []*workloadv1alpha1.SyncConfiguration{
+		newSyncConfiguration("broken", "", "services", workloadv1alpha1.SyncConfigurationSpec{ResyncInterval: &metav1.Duration{Duration: -time.Second}}),
+	})
+	require.ErrorContains(t, err, `invalid SyncConfiguration "broken"`)
+}
+
+func TestWatchSyncConfigurations(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var lock sync.Mutex
+	var syncConfigs []*workloadv1alpha1.SyncConfiguration
+	source := func(ctx context.Context) (*tmcv1alpha1.SyncTarget, []*workloadv1alpha1.SyncConfiguration, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		return edgeTarget, syncConfigs, nil
+	}
+
+	base := make(chan Config)
+	configs := WatchSyncConfigurations(ctx, base, source, 10*time.Millisecond)
+
+	base <- Config{services: {}}
+	require.Equal(t, Config{services: {}}, <-configs)
+
+	// SyncConfigurations are hot-reloaded.
+	lock.Lock()
+	syncConfigs = []*workloadv1alpha1.SyncConfiguration{
+		newSyncConfiguration("services", "", "services", workloadv1alpha1.SyncConfigurationSpec{Direction: workloadv1alpha1.SyncDirectionBidirectional}),
+	}
+	lock.Unlock()
+	require.Equal(t, Config{services: {Direction: workloadv1alpha1.SyncDirectionBidirectional}}, <-configs)
+
+	// So are changes of the base configuration.
+	base <- Config{services: {Workers: 2}}
+	require.Equal(t, Config{services: {Workers: 2, Direction: workloadv1alpha1.SyncDirectionBidirectional}}, <-configs)
+
+	close(base)
+	_, ok := <-configs
+	require.False(t, ok)
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"sync"
+)
+
+// criticalGate holds the workers of the controllers of resources that are
+// not critical, see priority.Critical, while controllers of critical
+// resources have not synced the objects queued at their start. After a cold
+// start, workloads then find their Secrets and RBAC downstream.
+type criticalGate struct {
+	lock    sync.Mutex
+	pending int
+	// synced is closed while no controller is pending.
+	synced chan struct{}
+}
+
+func newCriticalGate() *criticalGate {
+	g := &criticalGate{synced: make(chan struct{})}
+	close(g.synced)
+	return g
+}
+
+// add registers a pending controller. The returned func marks it synced and
+// may be called more than once.
+func (g *criticalGate) add() func() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if g.pending == 0 {
+		g.synced = make(chan struct{})
+	}
+	g.pending++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			g.lock.Lock()
+			defer g.lock.Unlock()
+			g.pending--
+			if g.pending == 0 {
+				close(g.synced)
+			}
+		})
+	}
+}
+
+// wait blocks until no controller is pending or ctx is done.
+func (g *criticalGate) wait(ctx context.Context) error {
+	g.lock.Lock()
+	synced := g.synced
+	g.lock.Unlock()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-synced:
+		return nil
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/teardown"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// tearDown waits for the SyncTarget to be deleted and for the workloads to
+// move off it, which keep being synced until then. It then stops syncing by
+// calling stopSyncing, and removes the downstream objects of the resources
+// returned by resources and the downstream namespaces, reporting the
+// progress in the DownstreamResourcesRemoved condition that the SyncTarget
+// finalizer waits for. tearDown returns once everything is removed, or when
+// ctx is done.
+func (s *syncer) tearDown(ctx context.Context, interval time.Duration, resources func() []schema.GroupVersionResource, stopSyncing func()) {
+	logger := klog.FromContext(ctx)
+
+	err := wait.PollUntilContextCancel(ctx, interval, true, func(ctx context.Context) (bool, error) {
+		syncTarget := s.syncTarget.Load()
+		return syncTarget != nil && syncTarget.DeletionTimestamp != nil && s.placement.HasSynced() && s.placement.Empty(), nil
+	})
+	if err != nil {
+		return
+	}
+	gvrs := resources()
+	stopSyncing()
+	if !slices.Contains(gvrs, namespacesGVR) {
+		// Namespaces last, once their objects are gone.
+		gvrs = append(gvrs, namespacesGVR)
+	}
+	logger.Info("SyncTarget is being deleted, removing downstream objects", "resources", len(gvrs))
+
+	remover := teardown.NewRemover(s.downstream)
+	selector := labels.SelectorFromSet(labels.Set{LabelSyncTarget: s.key})
+	err = wait.PollUntilContextCancel(ctx, interval, true, func(ctx context.Context) (bool, error) {
+		remaining, err := remover.Remove(ctx, gvrs, selector)
+		if err != nil {
+			utilruntime.HandleError(fmt.Errorf("failed to remove the downstream objects of SyncTarget %s: %w", s.target, err))
+		}
+		if err := s.reportTeardown(ctx, remaining, err); err != nil {
+			utilruntime.HandleError(fmt.Errorf("failed to report the teardown of SyncTarget %s: %w", s.target, err))
+			return false, nil
+		}
+		return remaining == 0 && err == nil, nil
+	})
+	if err == nil {
+		logger.Info("Removed downstream objects")
+	}
+}
+
+// reportTeardown sets the DownstreamResourcesRemoved condition of the
+// SyncTarget.
+func (s *syncer) reportTeardown(ctx context.Context, remaining int, removeErr error) error {
+	return s.updateSyncTargetStatus(ctx, func(syncTarget *tmcv1alpha1.SyncTarget) error {
+		teardown.SetCondition(syncTarget, remaining, removeErr)
+		return nil
+	})
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/multitarget"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func TestTearDown(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), wait.ForeverTestTimeout)
+	defer cancel()
+
+	key := SyncTargetKey("abc", "edge")
+	synced := func(obj *unstructured.Unstructured) *unstructured.Unstructured {
+		obj.SetLabels(map[string]string{LabelSyncTarget: key})
+		return obj
+	}
+	now := metav1.Now()
+	syncTarget := &tmcv1alpha1.SyncTarget{TypeMeta: metav1.TypeMeta{APIVersion: tmcv1alpha1.SchemeGroupVersion.String(), Kind: "SyncTarget"}, ObjectMeta: metav1.ObjectMeta{Name: "edge", DeletionTimestamp: &now, Finalizers: []string{tmcv1alpha1.SyncTargetFinalizer}}}
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(syncTarget)
+	require.NoError(t, err)
+	listKinds := map[schema.GroupVersionResource]string{configMapsGVR: "ConfigMapList", namespacesGVR: "NamespaceList", syncTargetsGVR: "SyncTargetList", distributionsGVR: "WorkloadDistributionList"}
+	upstream := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, &unstructured.Unstructured{Object: u})
+	downstream := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds,
+		synced(newObject("v1", "ConfigMap", "kcp-abc-default", "app")),
+		newObject("v1", "ConfigMap", "kube-system", "other"),
+		synced(newObject("v1", "Namespace", "", "kcp-abc-default")),
+	)
+	// The fake client does not implement deleting collections.
+	downstream.PrependReactor("delete-collection", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		deleteCollection := action.(clienttesting.DeleteCollectionAction)
+		selector := deleteCollection.GetListRestrictions().Labels
+		kinds := map[schema.GroupVersionResource]string{configMapsGVR: "ConfigMap", namespacesGVR: "Namespace"}
+		list, err := downstream.Tracker().List(action.GetResource(), action.GetResource().GroupVersion().WithKind(kinds[action.GetResource()]), "")
+		if err != nil {
+			return true, nil, err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return true, nil, err
+		}
+		for _, item := range items {
+			obj := item.(*unstructured.Unstructured)
+			if !selector.Matches(labels.Set(obj.GetLabels())) {
+				continue
+			}
+			if err := downstream.Tracker().Delete(action.GetResource(), obj.GetNamespace(), obj.GetName()); err != nil {
+				return true, nil, err
+			}
+		}
+		return true, nil, nil
+	})
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	s := newSyncer(multitarget.Target{Path: logicalcluster.NewPath("root:org"), Name: "edge"}, "abc", upstream, downstream, mapper)
+	place(t, s, newDistribution("default", "app", "ConfigMap", "edge"))
+	s.setSyncTarget(syncTarget)
+
+	stopped := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.tearDown(ctx, 10*time.Millisecond, func() []schema.GroupVersionResource {
+			return []schema.GroupVersionResource{configMapsGVR}
+		}, func() { close(stopped) })
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case <-stopped:
+		t.Fatal("syncing stopped while a workload is placed on the SyncTarget")
+	default:
+	}
+	place(t, s)
+	<-done
+	require.NoError(t, ctx.Err())
+	<-stopped
+
+	remaining, err := downstream.Resource(configMapsGVR).List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, remaining.Items, 1, "objects synced for others are kept")
+	namespaces, err := downstream.Resource(namespacesGVR).List(ctx, metav1.ListOptions{LabelSelector: labels.SelectorFromSet(labels.Set{LabelSyncTarget: key}).String()})
+	require.NoError(t, err)
+	require.Empty(t, namespaces.Items)
+
+	got, err := getSyncTarget(ctx, upstream, "edge")
+	require.NoError(t, err)
+	require.True(t, conditions.IsTrue(got, tmcv1alpha1.DownstreamResourcesRemoved))
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/pprof"
+	"time"
+
+	"github.com/prometheus/common/expfmt"
+
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/component-base/version"
+
+	"github.com/kcp-dev/kcp/pkg/featurez"
+)
+
+// Files contained in a support bundle.
+const (
+	BundleVersionFile    = "version.json"
+	BundleConfigFile     = "config.json"
+	BundleQueuesFile     = "queues.json"
+	BundleErrorsFile     = "errors.json"
+	BundleFeaturesFile   = "features.json"
+	BundleGoroutinesFile = "goroutines.txt"
+	BundleMetricsFile    = "metrics.txt"
+)
+
+// BundleFileName returns the conventional file name of a bundle taken at the
+// given time.
+func BundleFileName(t time.Time) string {
+	return fmt.Sprintf("syncer-bundle-%s.tar.gz", t.UTC().Format("20060102T150405Z"))
+}
+
+type bundleVersion struct {
+	Version   string    `json:"version"`
+	GoVersion string    `json:"goVersion"`
+	Taken     time.Time `json:"taken"`
+}
+
+// WriteBundle writes a gzipped tarball with the syncer version, its
+// configuration, queue states, recent errors, feature gates, a goroutine
+// dump and a snapshot of all registered metrics.
+func (s *Server) WriteBundle(w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+
+	files := []struct {
+		name    string
+		content func() ([]byte, error)
+	}{
+		{BundleVersionFile, func() ([]byte, error) {
+			return marshal(bundleVersion{Version: version.Get().String(), GoVersion: runtime.Version(), Taken: now})
+		}},
+		{BundleConfigFile, func() ([]byte, error) { return marshal(s.config) }},
+		{BundleQueuesFile, func() ([]byte, error) { return marshal(s.Queues()) }},
+		{BundleErrorsFile, func() ([]byte, error) { return marshal(s.Errors()) }},
+		{BundleFeaturesFile, func() ([]byte, error) { return marshal(featurez.Sorted(s.FeatureGates())) }},
+		{BundleGoroutinesFile, func() ([]byte, error) { return goroutineDump(), nil }},
+		{BundleMetricsFile, metricsSnapshot},
+	}
+	for _, f := range files {
+		content, err := f.content()
+		if err != nil {
+			// A broken section must not prevent collecting the rest.
+			content = []byte(fmt.Sprintf("failed to collect %s: %v\n", f.name, err))
+		}
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    f.name,
+			Mode:    0o644,
+			Size:    int64(len(content)),
+			ModTime: now,
+		}); err != nil {
+			return err
+		}
+		if _, err := tw.Write(content); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func marshal(v interface{}) ([]byte, error) {
+	return json.MarshalIndent(v, "", "  ")
+}
+
+func goroutineDump() []byte {
+	var buf bytes.Buffer
+	_ = pprof.Lookup("goroutine").WriteTo(&buf, 2)
+	return buf.Bytes()
+}
+
+func metricsSnapshot() ([]byte, error) {
+	families, err := legacyregistry.DefaultGatherer.Gather()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	for _, mf := range families {
+		if _, err := expfmt.MetricFamilyToText(&buf, mf); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
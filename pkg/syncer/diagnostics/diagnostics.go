@@ -0,0 +1,313 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package diagnostics serves runtime diagnostics of the workload syncer:
+// pprof profiles, on-demand goroutine and queue-state dumps, the most recent
+// errors, queries of the mutation audit log, the feature gates of the syncer
+// and a support bundle combining the dumps.
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/pkg/featurez"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+const (
+	// PathPrefix is the path all diagnostics endpoints are served under.
+	PathPrefix = "/debug/"
+
+	// GoroutinesPath serves a full goroutine dump as text.
+	GoroutinesPath = "/debug/dump/goroutines"
+	// QueuesPath serves the state of all registered queues as JSON.
+	QueuesPath = "/debug/dump/queues"
+	// ErrorsPath serves the most recent errors as JSON.
+	ErrorsPath = "/debug/dump/errors"
+	// AuditPath serves the mutation audit events matching the query
+	// parameters as JSON.
+	AuditPath = "/debug/audit"
+	// BundlePath serves a gzipped tarball with all dumps, the configuration
+	// and a metrics snapshot.
+	BundlePath = "/debug/bundle"
+
+	defaultMaxErrors     = 100
+	defaultMaxQueueItems = 50
+)
+
+// Options configure the diagnostics server.
+type Options struct {
+	// BindAddress is the address the server listens on. Diagnostics are
+	// disabled if empty.
+	BindAddress string
+	// MaxErrors is the number of recent errors kept in memory.
+	MaxErrors int
+}
+
+// NewOptions returns diagnostics options with diagnostics disabled.
+func NewOptions() *Options {
+	return &Options{
+		MaxErrors: defaultMaxErrors,
+	}
+}
+
+// AddFlags adds the diagnostics flags to the flag set.
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.BindAddress, "diagnostics-bind-address", o.BindAddress, "Address to serve pprof and diagnostics dumps on, e.g. 127.0.0.1:6060. Disabled if empty.")
+	fs.IntVar(&o.MaxErrors, "diagnostics-max-errors", o.MaxErrors, "Number of recent errors kept for diagnostics dumps.")
+}
+
+// Validate validates the options.
+func (o *Options) Validate() error {
+	if o.BindAddress != "" {
+		if _, _, err := net.SplitHostPort(o.BindAddress); err != nil {
+			return fmt.Errorf("invalid --diagnostics-bind-address %q: %w", o.BindAddress, err)
+		}
+	}
+	if o.MaxErrors <= 0 {
+		return fmt.Errorf("--diagnostics-max-errors must be positive")
+	}
+	return nil
+}
+
+// QueueState is a summary of the contents of a work queue.
+type QueueState struct {
+	// Name identifies the queue, usually by the controller owning it.
+	Name string `json:"name"`
+	// Length is the number of items waiting to be processed.
+	Length int `json:"length"`
+	// Processing are the keys currently being worked on, if known.
+	Processing []string `json:"processing,omitempty"`
+	// Sample is a bounded sample of waiting keys, if known.
+	Sample []string `json:"sample,omitempty"`
+}
+
+// AuditQuery answers queries of the mutation audit log.
+type AuditQuery func(query url.Values) (interface{}, error)
+
+// QueueInspector reports the state of a queue.
+type QueueInspector func() QueueState
+
+// LengthInspector returns an inspector for queues that only expose their
+// length, like client-go work queues.
+func LengthInspector(name string, queue interface{ Len() int }) QueueInspector {
+	return func() QueueState {
+		return QueueState{Name: name, Length: queue.Len()}
+	}
+}
+
+// Server collects and serves diagnostics. It is safe for concurrent use.
+type Server struct {
+	options Options
+	config  interface{}
+
+	lock   sync.RWMutex
+	queues map[string]QueueInspector
+
+	errors       *errorRing
+	audit        AuditQuery
+	featureGates func() []tmcv1alpha1.FeatureGate
+}
+
+// NewServer returns a diagnostics server. The config is included, as JSON,
+// in support bundles; it must not contain credentials.
+func NewServer(options *Options, config interface{}) *Server {
+	return &Server{
+		options:      *options,
+		config:       config,
+		queues:       map[string]QueueInspector{},
+		errors:       newErrorRing(options.MaxErrors),
+		featureGates: featurez.Process,
+	}
+}
+
+// RegisterQueue makes the state of a queue part of queue dumps. Registering
+// the same name twice replaces the previous inspector.
+func (s *Server) RegisterQueue(name string, inspector QueueInspector) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.queues[name] = inspector
+}
+
+// UnregisterQueue removes a queue from queue dumps, e.g. when its controller
+// is stopped.
+func (s *Server) UnregisterQueue(name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.queues, name)
+}
+
+// SetAuditQuery makes the mutation audit log queryable.
+func (s *Server) SetAuditQuery(query AuditQuery) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.audit = query
+}
+
+// SetFeatureGates sets the feature gates served at /featurez. They default
+// to the TMC feature gates of the process.
+func (s *Server) SetFeatureGates(gates func() []tmcv1alpha1.FeatureGate) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.featureGates = gates
+}
+
+// FeatureGates returns the feature gates of the syncer.
+func (s *Server) FeatureGates() []tmcv1alpha1.FeatureGate {
+	s.lock.RLock()
+	gates := s.featureGates
+	s.lock.RUnlock()
+	return gates()
+}
+
+// RecordError remembers an error for error dumps.
+func (s *Server) RecordError(err error, msg string, keysAndValues ...interface{}) {
+	s.errors.add(err, msg, keysAndValues...)
+}
+
+// InstallErrorHandler records every error passed to utilruntime.HandleError.
+func (s *Server) InstallErrorHandler() {
+	utilruntime.ErrorHandlers = append(utilruntime.ErrorHandlers, func(_ context.Context, err error, msg string, keysAndValues ...interface{}) {
+		s.RecordError(err, msg, keysAndValues...)
+	})
+}
+
+// Queues returns the state of all registered queues, sorted by name.
+func (s *Server) Queues() []QueueState {
+	s.lock.RLock()
+	inspectors := make([]QueueInspector, 0, len(s.queues))
+	for _, inspector := range s.queues {
+		inspectors = append(inspectors, inspector)
+	}
+	s.lock.RUnlock()
+
+	states := make([]QueueState, 0, len(inspectors))
+	for _, inspector := range inspectors {
+		state := inspector()
+		if len(state.Sample) > defaultMaxQueueItems {
+			state.Sample = state.Sample[:defaultMaxQueueItems]
+		}
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states
+}
+
+// Errors returns the recent errors, oldest first.
+func (s *Server) Errors() []RecordedError {
+	return s.errors.list()
+}
+
+// Handler returns the handler serving all diagnostics endpoints.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc(GoroutinesPath, s.serveGoroutines)
+	mux.HandleFunc(QueuesPath, s.serveJSON(func() interface{} { return s.Queues() }))
+	mux.HandleFunc(ErrorsPath, s.serveJSON(func() interface{} { return s.Errors() }))
+	mux.HandleFunc(AuditPath, s.serveAudit)
+	mux.HandleFunc(BundlePath, s.serveBundle)
+	mux.Handle(featurez.Path, featurez.Handler("syncer", s.FeatureGates))
+	return mux
+}
+
+// Run serves diagnostics until ctx is done. It returns immediately if no
+// bind address is configured.
+func (s *Server) Run(ctx context.Context) error {
+	if s.options.BindAddress == "" {
+		return nil
+	}
+	logger := klog.FromContext(ctx).WithValues("address", s.options.BindAddress)
+
+	server := &http.Server{
+		Addr:              s.options.BindAddress,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx) //nolint:contextcheck
+	}()
+
+	logger.Info("Serving syncer diagnostics")
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) serveGoroutines(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write(goroutineDump())
+}
+
+func (s *Server) serveJSON(get func() interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(get()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+
+func (s *Server) serveAudit(w http.ResponseWriter, r *http.Request) {
+	s.lock.RLock()
+	query := s.audit
+	s.lock.RUnlock()
+	if query == nil {
+		http.Error(w, "mutation audit log is disabled", http.StatusNotFound)
+		return
+	}
+	result, err := query(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.serveJSON(func() interface{} { return result })(w, r)
+}
+
+func (s *Server) serveBundle(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", BundleFileName(time.Now())))
+	if err := s.WriteBundle(w); err != nil {
+		// Headers are already sent, so the best we can do is to truncate
+		// the bundle and log.
+		klog.Background().Error(err, "failed to write syncer support bundle")
+	}
+}
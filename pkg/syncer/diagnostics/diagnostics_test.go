@@ -0,0 +1,163 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/kcp-dev/kcp/pkg/featurez"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func TestErrorRing(t *testing.T) {
+	r := newErrorRing(3)
+	r.add(errors.New("a"), "")
+	r.add(errors.New("a"), "")
+	r.add(errors.New("b"), "", "key", "ns/name")
+	r.add(errors.New("c"), "")
+	r.add(errors.New("d"), "")
+
+	got := r.list()
+	require.Len(t, got, 3)
+	require.Equal(t, "b", got[0].Error)
+	require.Equal(t, map[string]string{"key": "ns/name"}, got[0].Values)
+	require.Equal(t, "d", got[2].Error)
+
+	r = newErrorRing(3)
+	r.add(errors.New("a"), "")
+	r.add(errors.New("a"), "")
+	require.Len(t, r.list(), 1)
+	require.Equal(t, 2, r.list()[0].Count)
+}
+
+func TestHandler(t *testing.T) {
+	s := NewServer(NewOptions(), map[string]string{"syncTarget": "us-east-1"})
+	queue := workqueue.NewTyped[string]()
+	defer queue.ShutDown()
+	queue.Add("root:org|default/nginx")
+	s.RegisterQueue("b", LengthInspector("b", queue))
+	s.RegisterQueue("a", func() QueueState { return QueueState{Name: "a", Sample: make([]string, 2*defaultMaxQueueItems)} })
+	s.RecordError(errors.New("boom"), "failed to sync")
+
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	var queues []QueueState
+	getJSON(t, server.URL+QueuesPath, &queues)
+	require.Len(t, queues, 2)
+	require.Equal(t, "a", queues[0].Name)
+	require.Len(t, queues[0].Sample, defaultMaxQueueItems)
+	require.Equal(t, 1, queues[1].Length)
+
+	var errs []RecordedError
+	getJSON(t, server.URL+ErrorsPath, &errs)
+	require.Len(t, errs, 1)
+	require.Equal(t, "boom", errs[0].Error)
+
+	resp, err := http.Get(server.URL + GoroutinesPath)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.Contains(t, string(body), "goroutine")
+
+	resp, err = http.Get(server.URL + "/debug/pprof/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var report featurez.Report
+	getJSON(t, server.URL+featurez.Path, &report)
+	require.Equal(t, "syncer", report.Component)
+	require.Contains(t, report.FeatureGates, tmcv1alpha1.FeatureGate{Name: "TMCFeature", Enabled: false})
+	s.SetFeatureGates(func() []tmcv1alpha1.FeatureGate {
+		return []tmcv1alpha1.FeatureGate{{Name: "Observer", Enabled: true}, {Name: "Capacity", Enabled: false}}
+	})
+	getJSON(t, server.URL+featurez.Path, &report)
+	require.Equal(t, []tmcv1alpha1.FeatureGate{{Name: "Capacity", Enabled: false}, {Name: "Observer", Enabled: true}}, report.FeatureGates)
+
+	resp, err = http.Get(server.URL + AuditPath)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode, "audit log is disabled")
+
+	s.SetAuditQuery(func(query url.Values) (interface{}, error) {
+		if query.Get("limit") == "x" {
+			return nil, errors.New("invalid limit")
+		}
+		return []string{query.Get("name")}, nil
+	})
+	var audit []string
+	getJSON(t, server.URL+AuditPath+"?name=nginx", &audit)
+	require.Equal(t, []string{"nginx"}, audit)
+	resp, err = http.Get(server.URL + AuditPath + "?limit=x")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestBundle(t *testing.T) {
+	s := NewServer(NewOptions(), map[string]string{"syncTarget": "us-east-1"})
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + BundlePath)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	gz, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+	files := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[hdr.Name] = string(content)
+	}
+
+	for _, name := range []string{BundleVersionFile, BundleConfigFile, BundleQueuesFile, BundleErrorsFile, BundleFeaturesFile, BundleGoroutinesFile, BundleMetricsFile} {
+		require.Contains(t, files, name)
+	}
+	require.Contains(t, files[BundleConfigFile], "us-east-1")
+}
+
+func getJSON(t *testing.T, url string, into interface{}) {
+	t.Helper()
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode, fmt.Sprintf("GET %s", url))
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(into))
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RecordedError is an error kept for diagnostics.
+type RecordedError struct {
+	Time    time.Time         `json:"time"`
+	Message string            `json:"message,omitempty"`
+	Error   string            `json:"error"`
+	Values  map[string]string `json:"values,omitempty"`
+	// Count is the number of consecutive occurrences of the same error.
+	Count int `json:"count"`
+}
+
+// errorRing keeps the last n errors. Consecutive repetitions of the same
+// error are folded into one entry so a hot error loop does not evict
+// everything else.
+type errorRing struct {
+	lock    sync.Mutex
+	entries []RecordedError
+	next    int
+	full    bool
+	last    int
+}
+
+func newErrorRing(size int) *errorRing {
+	if size <= 0 {
+		size = defaultMaxErrors
+	}
+	return &errorRing{entries: make([]RecordedError, size), last: -1}
+}
+
+func (r *errorRing) add(err error, msg string, keysAndValues ...interface{}) {
+	if err == nil {
+		return
+	}
+	entry := RecordedError{
+		Time:    time.Now(),
+		Message: msg,
+		Error:   err.Error(),
+		Count:   1,
+	}
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		if entry.Values == nil {
+			entry.Values = map[string]string{}
+		}
+		entry.Values[fmt.Sprint(keysAndValues[i])] = fmt.Sprint(keysAndValues[i+1])
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.last >= 0 {
+		if prev := &r.entries[r.last]; prev.Error == entry.Error && prev.Message == entry.Message {
+			prev.Count++
+			prev.Time = entry.Time
+			return
+		}
+	}
+	r.entries[r.next] = entry
+	r.last = r.next
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+func (r *errorRing) list() []RecordedError {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.full {
+		return append([]RecordedError(nil), r.entries[:r.next]...)
+	}
+	out := make([]RecordedError, 0, len(r.entries))
+	out = append(out, r.entries[r.next:]...)
+	return append(out, r.entries[:r.next]...)
+}
@@ -0,0 +1,444 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package endpoint follows the syncer virtual workspace of a SyncTarget
+// when it moves to another shard. The clients of the syncer are built once
+// against the URL the syncer started with; their requests are routed to
+// the URL currently published in status.virtualWorkspaces of the
+// SyncTarget.
+//
+// When the URL changes on the same server, open watches are moved without
+// the informers noticing: the watch is opened again at the new URL from the
+// resource version of the last event received, the resume token, and its
+// events continue on the same stream. Resource versions are only meaningful
+// to the shard, and its etcd, that issued them: when the URL moves to
+// another server, i.e. another scheme or host, or the server cannot resume
+// from there, the stream ends and the informer lists again. Resuming
+// requires JSON watch streams; other watches end on a URL change.
+package endpoint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// moveTimeout bounds how long Set waits for open watches to be moved.
+const moveTimeout = 10 * time.Second
+
+// Endpoint is the syncer virtual workspace URL of a SyncTarget.
+type Endpoint struct {
+	syncTarget string
+	// initial is the URL the clients are configured with.
+	initial *url.URL
+
+	lock    sync.Mutex
+	current *url.URL
+	// generation is incremented whenever current changes.
+	generation int64
+	streams    map[*stream]struct{}
+}
+
+// New returns the endpoint of the SyncTarget, starting at syncerURL.
+func New(syncTarget, syncerURL string) (*Endpoint, error) {
+	u, err := parse(syncerURL)
+	if err != nil {
+		return nil, err
+	}
+	return &Endpoint{
+		syncTarget: syncTarget,
+		initial:    u,
+		current:    u,
+		streams:    map[*stream]struct{}{},
+	}, nil
+}
+
+func parse(syncerURL string) (*url.URL, error) {
+	u, err := url.Parse(syncerURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid syncer virtual workspace URL %q: %w", syncerURL, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid syncer virtual workspace URL %q: scheme and host are required", syncerURL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawPath, u.RawQuery, u.Fragment = "", "", ""
+	return u, nil
+}
+
+// URL returns the current URL.
+func (e *Endpoint) URL() string {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.current.String()
+}
+
+// Update follows the URL published in the status of target, and returns
+// whether it changed. The current URL is kept as long as it is published,
+// and when none is.
+func (e *Endpoint) Update(target *tmcv1alpha1.SyncTarget) (bool, error) {
+	vws := target.Status.VirtualWorkspaces
+	if len(vws) == 0 {
+		return false, nil
+	}
+	current := e.URL()
+	for _, vw := range vws {
+		if u, err := parse(vw.SyncerURL); err == nil && u.String() == current {
+			return false, nil
+		}
+	}
+	return e.Set(vws[0].SyncerURL)
+}
+
+// Set moves the endpoint to syncerURL, and returns whether it changed.
+// Open watches are moved to the new URL: Set returns once they have been
+// resumed there or ended, or after moveTimeout for watches whose events are
+// not being read.
+func (e *Endpoint) Set(syncerURL string) (bool, error) {
+	u, err := parse(syncerURL)
+	if err != nil {
+		return false, err
+	}
+
+	e.lock.Lock()
+	if u.String() == e.current.String() {
+		e.lock.Unlock()
+		return false, nil
+	}
+	klog.Background().V(2).Info("syncer virtual workspace moved", "syncTarget", e.syncTarget, "from", e.current.String(), "to", u.String())
+	e.current = u
+	e.generation++
+	generation := e.generation
+	streams := make([]*stream, 0, len(e.streams))
+	for s := range e.streams {
+		streams = append(streams, s)
+	}
+	e.lock.Unlock()
+
+	endpointChanges.WithLabelValues(e.syncTarget).Inc()
+	var moving []<-chan struct{}
+	for _, s := range streams {
+		if moved := s.interrupt(generation); moved != nil {
+			moving = append(moving, moved)
+		}
+	}
+	timeout := time.NewTimer(moveTimeout)
+	defer timeout.Stop()
+	for _, moved := range moving {
+		select {
+		case <-moved:
+		case <-timeout.C:
+			klog.Background().V(2).Info("timed out waiting for watches to move", "syncTarget", e.syncTarget, "to", u.String())
+			return true, nil
+		}
+	}
+	return true, nil
+}
+
+// Config returns a copy of config for the syncer virtual workspace whose
+// requests follow the endpoint. Its host is the URL the endpoint started
+// with.
+func (e *Endpoint) Config(config *rest.Config) *rest.Config {
+	config = rest.CopyConfig(config)
+	config.Host = e.initial.String()
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &roundTripper{delegate: rt, endpoint: e}
+	})
+	return config
+}
+
+// target returns the current URL and its generation.
+func (e *Endpoint) target() (*url.URL, int64) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.current, e.generation
+}
+
+// rewrite returns u, a URL below the initial URL, below base instead.
+func (e *Endpoint) rewrite(u *url.URL, base *url.URL) *url.URL {
+	out := *u
+	out.Scheme, out.Host = base.Scheme, base.Host
+	out.Path = base.Path + strings.TrimPrefix(u.Path, e.initial.Path)
+	out.RawPath = ""
+	return &out
+}
+
+func (e *Endpoint) register(s *stream) {
+	e.lock.Lock()
+	e.streams[s] = struct{}{}
+	generation := e.generation
+	e.lock.Unlock()
+	// The endpoint may have moved while the watch was opened.
+	s.interrupt(generation)
+}
+
+func (e *Endpoint) unregister(s *stream) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	delete(e.streams, s)
+}
+
+type roundTripper struct {
+	delegate http.RoundTripper
+	endpoint *Endpoint
+}
+
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	base, generation := rt.endpoint.target()
+	if !isWatch(req) {
+		return rt.send(req.Context(), req, base, req.URL.Query())
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	resp, err := rt.send(ctx, req, base, req.URL.Query())
+	if err != nil || resp.StatusCode != http.StatusOK || !isJSON(resp) {
+		if err != nil {
+			cancel()
+		} else {
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+		}
+		return resp, err
+	}
+
+	s := &stream{
+		rt:         rt,
+		req:        req,
+		server:     server(base),
+		body:       resp.Body,
+		cancel:     cancel,
+		decoder:    json.NewDecoder(resp.Body),
+		generation: generation,
+	}
+	resp.Body = s
+	rt.endpoint.register(s)
+	return resp, nil
+}
+
+// send sends req to base, with the given query.
+func (rt *roundTripper) send(ctx context.Context, req *http.Request, base *url.URL, query url.Values) (*http.Response, error) {
+	out := req.Clone(ctx)
+	out.URL = rt.endpoint.rewrite(req.URL, base)
+	out.URL.RawQuery = query.Encode()
+	out.Host = ""
+	return rt.delegate.RoundTrip(out)
+}
+
+// server returns the scheme and host of u. Resource versions received from
+// one server cannot be used on another.
+func server(u *url.URL) string {
+	return u.Scheme + "://" + u.Host
+}
+
+func isWatch(req *http.Request) bool {
+	switch req.URL.Query().Get("watch") {
+	case "true", "1":
+		return req.Method == http.MethodGet
+	}
+	return false
+}
+
+func isJSON(resp *http.Response) bool {
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return err == nil && mediaType == "application/json"
+}
+
+// stream is the body of a JSON watch. It passes the events through whole
+// and remembers the resource version of the last one, so that the watch
+// can be resumed at another URL.
+type stream struct {
+	rt  *roundTripper
+	req *http.Request
+	// server is where the events come from, see server().
+	server string
+
+	// decoder, resourceVersion and buf are only used by Read.
+	decoder         *json.Decoder
+	resourceVersion string
+	buf             bytes.Buffer
+
+	lock sync.Mutex
+	body io.ReadCloser
+	// cancel aborts the request of body. Unlike closing body, it does not
+	// let the transport drain the response for a while to reuse the
+	// connection, during which the server still sends events there.
+	cancel     context.CancelFunc
+	generation int64
+	closed     bool
+	// moved is closed once an interrupted stream has been resumed or has
+	// ended.
+	moved chan struct{}
+}
+
+type watchEvent struct {
+	Type   string `json:"type"`
+	Object struct {
+		Metadata struct {
+			ResourceVersion string `json:"resourceVersion"`
+		} `json:"metadata"`
+	} `json:"object"`
+}
+
+func (s *stream) Read(p []byte) (int, error) {
+	for s.buf.Len() == 0 {
+		var event json.RawMessage
+		if err := s.decoder.Decode(&event); err != nil {
+			if err := s.resume(err); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		var e watchEvent
+		if err := json.Unmarshal(event, &e); err == nil && e.Type != "ERROR" && e.Object.Metadata.ResourceVersion != "" {
+			s.resourceVersion = e.Object.Metadata.ResourceVersion
+		}
+		s.buf.Write(event)
+		s.buf.WriteByte('\n')
+	}
+	return s.buf.Read(p)
+}
+
+// resume opens the watch again at the current URL, from the last resource
+// version received, if the stream ended with err because the endpoint moved.
+// It returns the error to end the stream with, or nil if it was resumed. A
+// stream that cannot be resumed at the new URL ends with io.EOF.
+func (s *stream) resume(err error) error {
+	base, generation := s.rt.endpoint.target()
+	s.lock.Lock()
+	moved := !s.closed && generation != s.generation
+	s.lock.Unlock()
+	if !moved {
+		s.settle()
+		return err
+	}
+	resumed := false
+	defer func() {
+		if !resumed {
+			s.settle()
+		}
+	}()
+
+	syncTarget := s.rt.endpoint.syncTarget
+	logger := klog.FromContext(s.req.Context()).WithValues("syncTarget", syncTarget, "url", base.String(), "resourceVersion", s.resourceVersion)
+	if server(base) != s.server {
+		// Another shard would interpret the resource version against its
+		// own etcd, and skip or replay events. The informer lists again.
+		logger.V(2).Info("syncer virtual workspace moved to another server, ending watch", "from", s.server)
+		watchResumes.WithLabelValues(syncTarget, "ended").Inc()
+		return io.EOF
+	}
+	query := s.req.URL.Query()
+	if s.resourceVersion != "" {
+		query.Set("resourceVersion", s.resourceVersion)
+	}
+	ctx, cancel := context.WithCancel(s.req.Context())
+	resp, err := s.rt.send(ctx, s.req, base, query)
+	if err != nil || resp.StatusCode != http.StatusOK || !isJSON(resp) {
+		defer cancel()
+		if err == nil {
+			// E.g. 410 Gone if the new shard cannot resume from the
+			// resource version. The informer lists again.
+			resp.Body.Close()
+			err = fmt.Errorf("unexpected answer %q", resp.Status)
+		}
+		logger.V(2).Info("failed to resume watch, ending it", "err", err)
+		watchResumes.WithLabelValues(syncTarget, "ended").Inc()
+		return io.EOF
+	}
+
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		cancel()
+		resp.Body.Close()
+		return io.EOF
+	}
+	s.body = resp.Body
+	s.cancel = cancel
+	s.decoder = json.NewDecoder(resp.Body)
+	s.generation = generation
+	s.lock.Unlock()
+	logger.V(2).Info("resumed watch")
+	watchResumes.WithLabelValues(syncTarget, "resumed").Inc()
+	resumed = true
+
+	// The endpoint may have moved again while the watch was resumed.
+	if _, latest := s.rt.endpoint.target(); s.interrupt(latest) == nil {
+		s.settle()
+	}
+	return nil
+}
+
+// interrupt ends the current response if it is older than generation, so
+// that Read resumes the watch. It returns a channel closed once the stream
+// has been moved, or nil if it does not need to be.
+func (s *stream) interrupt(generation int64) <-chan struct{} {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed || s.generation >= generation {
+		return nil
+	}
+	s.cancel()
+	if s.moved == nil {
+		s.moved = make(chan struct{})
+	}
+	return s.moved
+}
+
+// settle releases those waiting for the stream to be moved.
+func (s *stream) settle() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.moved != nil {
+		close(s.moved)
+		s.moved = nil
+	}
+}
+
+func (s *stream) Close() error {
+	s.lock.Lock()
+	s.closed = true
+	err := s.body.Close()
+	s.cancel()
+	s.lock.Unlock()
+	s.settle()
+	s.rt.endpoint.unregister(s)
+	return err
+}
+
+// cancelOnClose cancels the context of a request when its body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// shard serves a watch of ConfigMaps sending one event per resource version
+// it is told, and records the requests it receives.
+type shard struct {
+	*httptest.Server
+	lock     sync.Mutex
+	requests []string
+	events   chan string
+	status   int
+}
+
+func newShard(t *testing.T) *shard {
+	s := &shard{events: make(chan string, 10), status: http.StatusOK}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.lock.Lock()
+		s.requests = append(s.requests, r.URL.Path+"?"+r.URL.RawQuery)
+		status := s.status
+		s.lock.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"Status","status":"Failure","code":410,"reason":"Expired"}`))
+			return
+		}
+		if r.URL.Query().Get("watch") == "" {
+			_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"c","namespace":"default","resourceVersion":"1"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case rv := <-s.events:
+				fmt.Fprintf(w, `{"type":"MODIFIED","object":{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"c","namespace":"default","resourceVersion":%q}}}`+"\n", rv)
+				w.(http.Flusher).Flush()
+			}
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *shard) received() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+func next(t *testing.T, w watch.Interface) string {
+	t.Helper()
+	select {
+	case event, ok := <-w.ResultChan():
+		require.True(t, ok, "watch ended")
+		require.Equal(t, watch.Modified, event.Type)
+		return event.Object.(*corev1.ConfigMap).ResourceVersion
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatal("no event received")
+	}
+	return ""
+}
+
+func TestEndpointRoutesRequests(t *testing.T) {
+	a, b := newShard(t), newShard(t)
+	e, err := New("east", a.URL+"/services/syncer/root:org/east/")
+	require.NoError(t, err)
+	client, err := kubernetes.NewForConfig(e.Config(&rest.Config{Host: "https://ignored"}))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = client.CoreV1().ConfigMaps("default").Get(ctx, "c", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	changed, err := e.Update(&tmcv1alpha1.SyncTarget{Status: tmcv1alpha1.SyncTargetStatus{VirtualWorkspaces: []tmcv1alpha1.VirtualWorkspace{
+		{SyncerURL: b.URL + "/services/syncer/root:org/east"},
+		{SyncerURL: a.URL + "/services/syncer/root:org/east"},
+	}}})
+	require.NoError(t, err)
+	require.False(t, changed, "the current URL is kept while it is published")
+
+	changed, err = e.Update(&tmcv1alpha1.SyncTarget{Status: tmcv1alpha1.SyncTargetStatus{VirtualWorkspaces: []tmcv1alpha1.VirtualWorkspace{
+		{SyncerURL: b.URL + "/shard-2/services/syncer/root:org/east"},
+	}}})
+	require.NoError(t, err)
+	require.True(t, changed)
+	_, err = client.CoreV1().ConfigMaps("default").Get(ctx, "c", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	changed, err = e.Update(&tmcv1alpha1.SyncTarget{})
+	require.NoError(t, err)
+	require.False(t, changed, "the current URL is kept if none is published")
+
+	require.Equal(t, []string{"/services/syncer/root:org/east/api/v1/namespaces/default/configmaps/c?"}, a.received())
+	require.Equal(t, []string{"/shard-2/services/syncer/root:org/east/api/v1/namespaces/default/configmaps/c?"}, b.received())
+}
+
+func TestEndpointResumesWatches(t *testing.T) {
+	a := newShard(t)
+	e, err := New("east", a.URL)
+	require.NoError(t, err)
+	client, err := kubernetes.NewForConfig(e.Config(&rest.Config{}))
+	require.NoError(t, err)
+
+	w, err := client.CoreV1().ConfigMaps("default").Watch(context.Background(), metav1.ListOptions{ResourceVersion: "1"})
+	require.NoError(t, err)
+	defer w.Stop()
+
+	a.events <- "2"
+	a.events <- "3"
+	require.Equal(t, "2", next(t, w))
+	require.Equal(t, "3", next(t, w))
+
+	_, err = e.Set(a.URL + "/moved")
+	require.NoError(t, err)
+	a.events <- "4"
+	require.Equal(t, "4", next(t, w), "the watch continues at the new URL")
+	require.Equal(t, "/moved/api/v1/namespaces/default/configmaps?resourceVersion=3&watch=true", a.received()[1], "the watch resumes from the last event")
+
+	a.lock.Lock()
+	a.status = http.StatusGone
+	a.lock.Unlock()
+	_, err = e.Set(a.URL + "/again")
+	require.NoError(t, err)
+	requireEnded(t, w, "the watch ends if it cannot be resumed")
+}
+
+func TestEndpointEndsWatchesAcrossShards(t *testing.T) {
+	a, b := newShard(t), newShard(t)
+	e, err := New("east", a.URL)
+	require.NoError(t, err)
+	client, err := kubernetes.NewForConfig(e.Config(&rest.Config{}))
+	require.NoError(t, err)
+
+	w, err := client.CoreV1().ConfigMaps("default").Watch(context.Background(), metav1.ListOptions{ResourceVersion: "1"})
+	require.NoError(t, err)
+	defer w.Stop()
+	a.events <- "2"
+	require.Equal(t, "2", next(t, w))
+
+	_, err = e.Set(b.URL)
+	require.NoError(t, err)
+	requireEnded(t, w, "resource versions of one shard are not used on another")
+	require.Empty(t, b.received())
+}
+
+func requireEnded(t *testing.T, w watch.Interface, msg string) {
+	t.Helper()
+	select {
+	case _, ok := <-w.ResultChan():
+		require.False(t, ok, msg)
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatal("watch did not end")
+	}
+}
+
+func TestNewValidatesURL(t *testing.T) {
+	_, err := New("east", "/services/syncer")
+	require.ErrorContains(t, err, "scheme and host are required")
+}
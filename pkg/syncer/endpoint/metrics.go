@@ -0,0 +1,57 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"sync"
+
+	compbasemetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+var (
+	endpointChanges = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Name:           "syncer_virtual_workspace_endpoint_changes_total",
+			Help:           "Number of times the syncer virtual workspace URL of a SyncTarget changed.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"sync_target"},
+	)
+	watchResumes = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Name:           "syncer_watch_resumes_total",
+			Help:           "Number of watches moved to a new syncer virtual workspace URL, by result: resumed from the last resource version, or ended so that the informer lists again.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"sync_target", "result"},
+	)
+)
+
+var registerMetrics sync.Once
+
+// Register metrics.
+func Register() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(endpointChanges)
+		legacyregistry.MustRegister(watchResumes)
+	})
+}
+
+func init() {
+	Register()
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fanout renders ConfigMaps per SyncTarget before they are synced.
+// A single ConfigMap in the workspace annotated with
+// workload.kcp.io/config-fanout can reference the metadata of the target it
+// is synced to, so that applications get location-specific configuration
+// without maintaining a copy per location upstream.
+//
+// The data values of the ConfigMap may contain these variables:
+//
+//	${target.name}                the name of the SyncTarget
+//	${target.location}            the location of the SyncTarget
+//	${target.kubernetesVersion}   the Kubernetes version of the cluster
+//	${target.labels.<key>}        a label of the SyncTarget
+//	${target.annotations.<key>}   an annotation of the SyncTarget
+//	${target.endpoints.<name>}    the endpoints.tmc.kcp.io/<name> annotation
+//
+// $${ renders as a literal ${.
+package fanout
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var variable = regexp.MustCompile(`\$(\$)?\{([^}]*)\}`)
+
+// Enabled returns whether obj is a ConfigMap to be rendered per SyncTarget.
+func Enabled(obj *unstructured.Unstructured) bool {
+	if obj.GetAPIVersion() != "v1" || obj.GetKind() != "ConfigMap" {
+		return false
+	}
+	_, found := obj.GetAnnotations()[workloadv1alpha1.AnnotationConfigFanOut]
+	return found
+}
+
+// Render returns obj rendered for the SyncTarget, without the fan-out
+// annotation. Objects that are not enabled are returned unchanged. It fails
+// if a rendered value references an unknown variable or metadata the target
+// does not have, in which case the ConfigMap must not be synced to it.
+func Render(obj *unstructured.Unstructured, syncTarget *tmcv1alpha1.SyncTarget) (*unstructured.Unstructured, error) {
+	if !Enabled(obj) {
+		return obj, nil
+	}
+
+	rendered := obj.DeepCopy()
+	annotations := rendered.GetAnnotations()
+	keys := annotations[workloadv1alpha1.AnnotationConfigFanOut]
+	delete(annotations, workloadv1alpha1.AnnotationConfigFanOut)
+	rendered.SetAnnotations(annotations)
+
+	data, _, err := unstructured.NestedStringMap(rendered.Object, "data")
+	if err != nil {
+		return nil, fmt.Errorf("invalid ConfigMap data: %w", err)
+	}
+	var selected sets.Set[string]
+	if keys != "true" {
+		selected = sets.New[string]()
+		for _, key := range strings.Split(keys, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				selected.Insert(key)
+			}
+		}
+	}
+
+	var unresolved []string
+	for key, value := range data {
+		if selected != nil && !selected.Has(key) {
+			continue
+		}
+		var missing []string
+		data[key], missing = expand(value, syncTarget)
+		unresolved = append(unresolved, missing...)
+	}
+	if len(unresolved) > 0 {
+		return nil, unresolvedError(syncTarget, unresolved)
+	}
+	if len(data) > 0 {
+		if err := unstructured.SetNestedStringMap(rendered.Object, data, "data"); err != nil {
+			return nil, err
+		}
+	}
+	return rendered, nil
+}
+
+// Expand replaces the variables in value with the metadata of the
+// SyncTarget. It fails if value references an unknown variable or metadata
+// the target does not have.
+func Expand(value string, syncTarget *tmcv1alpha1.SyncTarget) (string, error) {
+	expanded, unresolved := expand(value, syncTarget)
+	if len(unresolved) > 0 {
+		return "", unresolvedError(syncTarget, unresolved)
+	}
+	return expanded, nil
+}
+
+// expand returns value with its variables replaced, and the variables it
+// could not resolve.
+func expand(value string, syncTarget *tmcv1alpha1.SyncTarget) (string, []string) {
+	var unresolved []string
+	expanded := variable.ReplaceAllStringFunc(value, func(match string) string {
+		groups := variable.FindStringSubmatch(match)
+		if groups[1] != "" {
+			return match[1:]
+		}
+		resolved, ok := resolve(groups[2], syncTarget)
+		if !ok {
+			unresolved = append(unresolved, match)
+		}
+		return resolved
+	})
+	return expanded, unresolved
+}
+
+func unresolvedError(syncTarget *tmcv1alpha1.SyncTarget, unresolved []string) error {
+	return fmt.Errorf("SyncTarget %q has no value for %s", syncTarget.Name, strings.Join(sets.List(sets.New(unresolved...)), ", "))
+}
+
+func resolve(name string, syncTarget *tmcv1alpha1.SyncTarget) (string, bool) {
+	switch name {
+	case "target.name":
+		return syncTarget.Name, true
+	case "target.location":
+		return syncTarget.Spec.Location, syncTarget.Spec.Location != ""
+	case "target.kubernetesVersion":
+		return syncTarget.Status.KubernetesVersion, syncTarget.Status.KubernetesVersion != ""
+	}
+	if key, found := strings.CutPrefix(name, "target.labels."); found {
+		value, ok := syncTarget.Labels[key]
+		return value, ok
+	}
+	if key, found := strings.CutPrefix(name, "target.annotations."); found {
+		value, ok := syncTarget.Annotations[key]
+		return value, ok
+	}
+	if endpoint, found := strings.CutPrefix(name, "target.endpoints."); found {
+		value, ok := syncTarget.Annotations[tmcv1alpha1.AnnotationEndpointPrefix+endpoint]
+		return value, ok
+	}
+	return "", false
+}
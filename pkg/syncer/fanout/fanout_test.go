@@ -0,0 +1,87 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fanout
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+func configMap(fanout string, data map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"namespace": "default",
+			"name":      "settings",
+		},
+		"data": data,
+	}}
+	if fanout != "" {
+		obj.SetAnnotations(map[string]string{workloadv1alpha1.AnnotationConfigFanOut: fanout, "keep": "me"})
+	}
+	return obj
+}
+
+func TestRender(t *testing.T) {
+	syncTarget := &tmcv1alpha1.SyncTarget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "us-east-1a",
+			Labels:      map[string]string{"topology.kubernetes.io/region": "us-east-1"},
+			Annotations: map[string]string{tmcv1alpha1.AnnotationEndpointPrefix + "registry": "registry.us-east-1.example.com"},
+		},
+		Spec: tmcv1alpha1.SyncTargetSpec{Location: "us-east"},
+	}
+
+	rendered, err := Render(configMap("true", map[string]interface{}{
+		"app.properties": "region=${target.labels.topology.kubernetes.io/region}\ncluster=${target.name}\n",
+		"registry":       "${target.endpoints.registry}/app:v1",
+		"literal":        "$${target.name} in ${target.location}",
+	}), syncTarget)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"app.properties": "region=us-east-1\ncluster=us-east-1a\n",
+		"registry":       "registry.us-east-1.example.com/app:v1",
+		"literal":        "${target.name} in us-east",
+	}, rendered.Object["data"])
+	require.Equal(t, map[string]string{"keep": "me"}, rendered.GetAnnotations(), "the directive is not synced")
+
+	rendered, err = Render(configMap("a, b", map[string]interface{}{
+		"a": "${target.name}",
+		"b": "${target.location}",
+		"c": "${target.name}",
+	}), syncTarget)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"a": "us-east-1a", "b": "us-east", "c": "${target.name}"}, rendered.Object["data"], "only the listed keys are rendered")
+
+	_, err = Render(configMap("true", map[string]interface{}{
+		"a": "${target.labels.tier} ${target.kubernetesVersion} ${target.bogus} ${target.labels.tier}",
+	}), syncTarget)
+	require.EqualError(t, err, `SyncTarget "us-east-1a" has no value for ${target.bogus}, ${target.kubernetesVersion}, ${target.labels.tier}`)
+
+	plain := configMap("", map[string]interface{}{"a": "${target.name}"})
+	rendered, err = Render(plain, syncTarget)
+	require.NoError(t, err)
+	require.Same(t, plain, rendered, "ConfigMaps without the annotation are not rendered")
+}
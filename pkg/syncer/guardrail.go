@@ -0,0 +1,52 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// guardrailRetryInterval is how often objects beyond the guardrails of the
+// SyncTarget are tried again.
+const guardrailRetryInterval = 30 * time.Second
+
+// admit records the downstream object of key in the guardrails of the
+// SyncTarget, see guardrail.Tracker.Admit.
+func (c *controller) admit(key string, obj *unstructured.Unstructured) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return c.guardrails.Admit(c.guardrailKey(key), int64(len(data)))
+}
+
+func (c *controller) guardrailKey(key string) string {
+	return c.gvr.String() + "/" + key
+}
+
+// reportUsage reports the objects synced to the SyncTarget in its status,
+// which its guardrails are checked against, see SyncTargetGuardrails.
+func (s *syncer) reportUsage(_ context.Context, syncTarget *tmcv1alpha1.SyncTarget) error {
+	usage := s.guardrails.Usage()
+	syncTarget.Status.Usage = &usage
+	return nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package guardrail enforces the guardrails of a SyncTarget while syncing:
+// it tracks the objects synced to the target and refuses new objects, or
+// growing existing ones, beyond the configured limits.
+package guardrail
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// ErrGuardrailExceeded is returned for objects that do not fit into the
+// guardrails of the SyncTarget.
+var ErrGuardrailExceeded = errors.New("SyncTarget guardrail exceeded")
+
+// Tracker tracks the size of the objects synced to a SyncTarget.
+type Tracker struct {
+	lock       sync.Mutex
+	guardrails *tmcv1alpha1.SyncTargetGuardrails
+	sizes      map[string]int64
+	bytes      int64
+}
+
+// NewTracker returns a tracker enforcing the given guardrails, which may be
+// nil for no limits.
+func NewTracker(guardrails *tmcv1alpha1.SyncTargetGuardrails) *Tracker {
+	return &Tracker{guardrails: guardrails.DeepCopy(), sizes: map[string]int64{}}
+}
+
+// SetGuardrails replaces the guardrails, e.g. after the SyncTarget changed.
+// Objects already synced are kept even if they exceed the new limits.
+func (t *Tracker) SetGuardrails(guardrails *tmcv1alpha1.SyncTargetGuardrails) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.guardrails = guardrails.DeepCopy()
+}
+
+// Admit records that the object with the given key is synced with the given
+// manifest size. It returns ErrGuardrailExceeded and records nothing if a
+// new object, or the growth of an existing one, exceeds the guardrails.
+// Shrinking objects are always admitted.
+func (t *Tracker) Admit(key string, size int64) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	old, found := t.sizes[key]
+	if t.guardrails != nil {
+		if !found && t.guardrails.MaxObjects != nil && int64(len(t.sizes)) >= *t.guardrails.MaxObjects {
+			return fmt.Errorf("%w: at most %d objects", ErrGuardrailExceeded, *t.guardrails.MaxObjects)
+		}
+		if size > old && t.guardrails.MaxManifestBytes != nil && t.bytes-old+size > t.guardrails.MaxManifestBytes.Value() {
+			return fmt.Errorf("%w: at most %d manifest bytes", ErrGuardrailExceeded, t.guardrails.MaxManifestBytes.Value())
+		}
+	}
+	t.sizes[key] = size
+	t.bytes += size - old
+	return nil
+}
+
+// Remove forgets an object that is no longer synced.
+func (t *Tracker) Remove(key string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.bytes -= t.sizes[key]
+	delete(t.sizes, key)
+}
+
+// Usage returns the usage to report in the SyncTarget status.
+func (t *Tracker) Usage() tmcv1alpha1.SyncTargetUsage {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return tmcv1alpha1.SyncTargetUsage{Objects: int64(len(t.sizes)), ManifestBytes: t.bytes}
+}
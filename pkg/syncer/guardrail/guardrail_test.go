@@ -0,0 +1,56 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package guardrail
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func TestTracker(t *testing.T) {
+	tracker := NewTracker(&tmcv1alpha1.SyncTargetGuardrails{
+		MaxObjects:       ptr.To[int64](2),
+		MaxManifestBytes: ptr.To(resource.MustParse("1Ki")),
+	})
+
+	require.NoError(t, tracker.Admit("a", 400))
+	require.NoError(t, tracker.Admit("b", 500))
+	require.ErrorIs(t, tracker.Admit("c", 1), ErrGuardrailExceeded, "too many objects")
+	require.ErrorIs(t, tracker.Admit("b", 700), ErrGuardrailExceeded, "too many bytes")
+	require.NoError(t, tracker.Admit("b", 600), "growing within the limit")
+	require.Equal(t, tmcv1alpha1.SyncTargetUsage{Objects: 2, ManifestBytes: 1000}, tracker.Usage())
+
+	tracker.SetGuardrails(&tmcv1alpha1.SyncTargetGuardrails{MaxManifestBytes: ptr.To(resource.MustParse("500"))})
+	require.NoError(t, tracker.Admit("b", 550), "shrinking is always admitted")
+	require.ErrorIs(t, tracker.Admit("c", 1), ErrGuardrailExceeded)
+
+	tracker.Remove("b")
+	tracker.Remove("unknown")
+	require.NoError(t, tracker.Admit("c", 100))
+	require.Equal(t, tmcv1alpha1.SyncTargetUsage{Objects: 2, ManifestBytes: 500}, tracker.Usage())
+
+	unlimited := NewTracker(nil)
+	for _, key := range []string{"a", "b", "c"} {
+		require.NoError(t, unlimited.Admit(key, 1<<20))
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package heartbeat renews the heartbeat Lease of a SyncTarget for its
+// syncer. The Lease is in the workspace of the SyncTarget, where the
+// heartbeat controller of kcp creates it with the heartbeat timeout of the
+// SyncTarget as its duration, and marks the SyncTarget not ready when the
+// Lease is not renewed in time. The syncer renews it every quarter of its
+// duration, also while the SyncTarget is paused.
+package heartbeat
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coordinationv1client "k8s.io/client-go/kubernetes/typed/coordination/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+const (
+	// LeaseNamespace is the namespace of the heartbeat Leases in the
+	// workspaces of their SyncTargets.
+	LeaseNamespace = metav1.NamespaceDefault
+
+	// minRenewInterval keeps Leases with very short durations from being
+	// renewed in a tight loop.
+	minRenewInterval = time.Second
+	// retryInterval is how long to wait before retrying when the Lease
+	// cannot be read, e.g. as kcp did not create it yet.
+	retryInterval = 5 * time.Second
+)
+
+// LeaseName returns the name of the heartbeat Lease of a SyncTarget.
+func LeaseName(syncTarget string) string {
+	return "kcp-syncer-heartbeat-" + syncTarget
+}
+
+// RenewInterval returns how often the heartbeat Lease is renewed.
+func RenewInterval(lease *coordinationv1.Lease) time.Duration {
+	duration := tmcv1alpha1.DefaultHeartbeatTimeout
+	if d := lease.Spec.LeaseDurationSeconds; d != nil && *d > 0 {
+		duration = time.Duration(*d) * time.Second
+	}
+	return max(duration/4, minRenewInterval)
+}
+
+// Heartbeat renews the heartbeat Lease of a SyncTarget.
+type Heartbeat struct {
+	leases   coordinationv1client.LeaseInterface
+	name     string
+	identity string
+	now      func() time.Time
+}
+
+// New returns a heartbeat renewing the Lease of syncTarget with client,
+// which talks to the workspace of the SyncTarget. identity tells the
+// syncer instance holding the Lease, e.g. its pod name.
+func New(client coordinationv1client.LeasesGetter, syncTarget, identity string) *Heartbeat {
+	return &Heartbeat{
+		leases:   client.Leases(LeaseNamespace),
+		name:     LeaseName(syncTarget),
+		identity: identity,
+		now:      time.Now,
+	}
+}
+
+// Run renews the Lease until ctx is done.
+func (h *Heartbeat) Run(ctx context.Context) {
+	logger := klog.FromContext(ctx).WithValues("lease", h.name)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		next, err := h.Renew(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Error(err, "failed to renew syncer heartbeat")
+		}
+		timer.Reset(next)
+	}
+}
+
+// Renew renews the Lease once, and returns when to renew it next. The Lease
+// is not created if it is missing, as the syncer may only update it.
+func (h *Heartbeat) Renew(ctx context.Context) (time.Duration, error) {
+	lease, err := h.leases.Get(ctx, h.name, metav1.GetOptions{})
+	if err != nil {
+		return retryInterval, fmt.Errorf("failed to get heartbeat lease %s/%s: %w", LeaseNamespace, h.name, err)
+	}
+	next := RenewInterval(lease)
+
+	now := metav1.NewMicroTime(h.now())
+	lease = lease.DeepCopy()
+	if ptr.Deref(lease.Spec.HolderIdentity, "") != h.identity {
+		if lease.Spec.HolderIdentity != nil {
+			lease.Spec.LeaseTransitions = ptr.To(ptr.Deref(lease.Spec.LeaseTransitions, 0) + 1)
+		}
+		lease.Spec.HolderIdentity = ptr.To(h.identity)
+		lease.Spec.AcquireTime = &now
+	}
+	lease.Spec.RenewTime = &now
+	if _, err := h.leases.Update(ctx, lease, metav1.UpdateOptions{}); err != nil {
+		return next, fmt.Errorf("failed to update heartbeat lease %s/%s: %w", LeaseNamespace, h.name, err)
+	}
+	return next, nil
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package heartbeat
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func TestRenew(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	h := New(client.CoordinationV1(), "edge-1", "syncer-a")
+	h.now = func() time.Time { return now }
+
+	_, err := h.Renew(ctx)
+	require.Error(t, err, "the lease is created by kcp")
+
+	_, err = client.CoordinationV1().Leases(LeaseNamespace).Create(ctx, &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Namespace: LeaseNamespace, Name: LeaseName("edge-1")},
+		Spec:       coordinationv1.LeaseSpec{LeaseDurationSeconds: ptr.To[int32](40)},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	next, err := h.Renew(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 10*time.Second, next)
+	lease, err := client.CoordinationV1().Leases(LeaseNamespace).Get(ctx, LeaseName("edge-1"), metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "syncer-a", *lease.Spec.HolderIdentity)
+	require.True(t, lease.Spec.RenewTime.Time.Equal(now))
+	require.True(t, lease.Spec.AcquireTime.Time.Equal(now))
+	require.Nil(t, lease.Spec.LeaseTransitions)
+
+	now = now.Add(10 * time.Second)
+	h = New(client.CoordinationV1(), "edge-1", "syncer-b")
+	h.now = func() time.Time { return now }
+	_, err = h.Renew(ctx)
+	require.NoError(t, err)
+	lease, err = client.CoordinationV1().Leases(LeaseNamespace).Get(ctx, LeaseName("edge-1"), metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "syncer-b", *lease.Spec.HolderIdentity)
+	require.True(t, lease.Spec.RenewTime.Time.Equal(now))
+	require.Equal(t, int32(1), *lease.Spec.LeaseTransitions, "another syncer took over")
+}
+
+func TestRenewInterval(t *testing.T) {
+	require.Equal(t, 15*time.Second, RenewInterval(&coordinationv1.Lease{}))
+	require.Equal(t, time.Second, RenewInterval(&coordinationv1.Lease{Spec: coordinationv1.LeaseSpec{LeaseDurationSeconds: ptr.To[int32](2)}}))
+}
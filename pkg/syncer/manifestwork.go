@@ -0,0 +1,284 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/pkg/placement/closure"
+	"github.com/kcp-dev/kcp/pkg/syncer/audit"
+	"github.com/kcp-dev/kcp/pkg/syncer/naming"
+	"github.com/kcp-dev/kcp/pkg/syncer/workapi"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// auditManifestWorkController is the controller the writes of ManifestWorks
+// are audited for, see package audit.
+const auditManifestWorkController = "manifestwork"
+
+// manifestWorks delivers the downstream objects of a SyncTarget in the
+// ManifestWork delivery mode, see workapi.UsesManifestWork. The physical
+// cluster of the syncer is then the hub of Open Cluster Management: the
+// downstream objects are not applied, but bundled per placed workload into
+// a ManifestWork in the namespace of the managed cluster, which the work
+// agent of the cluster applies.
+type manifestWorks struct {
+	*syncer
+	namespace string
+	// changed triggers a sync before the next interval.
+	changed chan struct{}
+
+	lock sync.Mutex
+	// objects are the downstream objects by the reference of their upstream
+	// objects, as the controllers would have applied them.
+	objects map[closure.Reference]*unstructured.Unstructured
+	// applied are the ManifestWorks last applied by name.
+	applied map[string]*unstructured.Unstructured
+}
+
+func newManifestWorks(s *syncer, namespace string) *manifestWorks {
+	return &manifestWorks{
+		syncer:    s,
+		namespace: namespace,
+		changed:   make(chan struct{}, 1),
+		objects:   map[closure.Reference]*unstructured.Unstructured{},
+		applied:   map[string]*unstructured.Unstructured{},
+	}
+}
+
+// apply records the downstream object of an upstream object, to be
+// delivered with the ManifestWorks of the workloads it is placed with, see
+// downstreamWriter.
+func (w *manifestWorks) apply(_ context.Context, obj *unstructured.Unstructured) error {
+	id, found := naming.UpstreamOf(obj)
+	if !found {
+		return fmt.Errorf("downstream %s %s has no upstream identity", obj.GetKind(), obj.GetName())
+	}
+	ref := closure.Reference{GroupKind: obj.GroupVersionKind().GroupKind(), Namespace: id.Namespace, Name: id.Name}
+	w.lock.Lock()
+	w.objects[ref] = obj
+	w.lock.Unlock()
+	w.trigger()
+	return nil
+}
+
+// trigger makes Run sync before the next interval.
+func (w *manifestWorks) trigger() {
+	select {
+	case w.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Run syncs the ManifestWorks every interval and on changes until ctx is
+// done.
+func (w *manifestWorks) Run(ctx context.Context, interval time.Duration) {
+	defer utilruntime.HandleCrash()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if w.placement.HasSynced() && !w.pause.Paused() {
+			if err := w.sync(ctx); err != nil {
+				utilruntime.HandleError(fmt.Errorf("failed to sync the ManifestWorks of SyncTarget %s: %w", w.target, err))
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-w.changed:
+		}
+	}
+}
+
+// sync applies a ManifestWork per workload placed on the SyncTarget with
+// the downstream objects recorded so far, and deletes the ManifestWorks of
+// workloads no longer placed.
+func (w *manifestWorks) sync(ctx context.Context) error {
+	desired, err := w.desired()
+	if err != nil {
+		return err
+	}
+	client := w.downstream.Resource(workapi.ManifestWorksGVR).Namespace(w.namespace)
+	existing, err := client.List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{LabelSyncTarget: w.key}).String(),
+	})
+	if err != nil {
+		return err
+	}
+	found := sets.New[string]()
+	for i := range existing.Items {
+		found.Insert(existing.Items[i].GetName())
+	}
+
+	var errs []error
+	for name, work := range desired {
+		w.lock.Lock()
+		old := w.applied[name]
+		w.lock.Unlock()
+		if old != nil && found.Has(name) && equality.Semantic.DeepEqual(old.Object["spec"], work.Object["spec"]) {
+			continue
+		}
+		applied, err := client.Apply(ctx, name, work, metav1.ApplyOptions{FieldManager: fieldManager, Force: true})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		verb := audit.VerbPatch
+		if !found.Has(name) {
+			verb = audit.VerbCreate
+		}
+		w.audit.Record(ctx, audit.Mutation{Controller: auditManifestWorkController, Verb: verb, Resource: workapi.ManifestWorksGVR, Old: old, New: applied})
+		w.lock.Lock()
+		w.applied[name] = work
+		w.lock.Unlock()
+	}
+
+	for i := range existing.Items {
+		name := existing.Items[i].GetName()
+		if _, found := desired[name]; found {
+			continue
+		}
+		klog.FromContext(ctx).V(2).Info("deleting ManifestWork of workload no longer placed", "manifestWork", name)
+		if err := client.Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, err)
+			continue
+		}
+		w.audit.Record(ctx, audit.Mutation{Controller: auditManifestWorkController, Verb: audit.VerbDelete, Resource: workapi.ManifestWorksGVR, Old: &existing.Items[i]})
+		w.lock.Lock()
+		delete(w.applied, name)
+		w.lock.Unlock()
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// desired returns the ManifestWorks of the workloads placed on the
+// SyncTarget by name. Objects no longer placed are forgotten.
+func (w *manifestWorks) desired() (map[string]*unstructured.Unstructured, error) {
+	workloads := w.placement.Workloads()
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	placed := sets.New[closure.Reference]()
+	for _, refs := range workloads {
+		placed = placed.Union(refs)
+	}
+	for ref := range w.objects {
+		if !placed.Has(ref) {
+			delete(w.objects, ref)
+		}
+	}
+
+	works := make(map[string]*unstructured.Unstructured, len(workloads))
+	for workload, refs := range workloads {
+		bundle := workapi.Bundle{
+			Workspace:  w.clusterName,
+			Workload:   workloadName(workload),
+			SyncTarget: w.target.Name,
+		}
+		namespaces := sets.New[string]()
+		sorted := refs.UnsortedList()
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i].String() < sorted[j].String() })
+		for _, ref := range sorted {
+			obj, found := w.objects[ref]
+			if !found {
+				// Not synced by its controller yet.
+				continue
+			}
+			if namespace := obj.GetNamespace(); namespace != "" && !namespaces.Has(namespace) {
+				namespaces.Insert(namespace)
+				bundle.Objects = append(bundle.Objects, w.downstreamNamespace(ref.Namespace))
+			}
+			bundle.Objects = append(bundle.Objects, obj)
+		}
+		if len(bundle.Objects) == 0 {
+			continue
+		}
+		work, err := workapi.ToManifestWork(bundle, w.namespace)
+		if err != nil {
+			return nil, err
+		}
+		// Labeled like the other downstream objects, so that they are
+		// removed on teardown.
+		workLabels := work.GetLabels()
+		workLabels[LabelSyncTarget] = w.key
+		work.SetLabels(workLabels)
+		works[work.GetName()] = work
+	}
+	return works, nil
+}
+
+// downstreamNamespace returns the downstream namespace of a namespace of
+// the workspace, bundled with the objects in it.
+func (w *manifestWorks) downstreamNamespace(namespace string) *unstructured.Unstructured {
+	ns := &unstructured.Unstructured{}
+	ns.SetAPIVersion("v1")
+	ns.SetKind("Namespace")
+	ns.SetName(naming.Namespace(w.clusterName, namespace))
+	ns.SetLabels(map[string]string{LabelSyncTarget: w.key})
+	naming.SetUpstream(ns, naming.Identity{Workspace: w.clusterName, Path: w.workspacePath(), Name: namespace})
+	return ns
+}
+
+// report sets the ManifestWorksApplied condition of the SyncTarget from the
+// status the work agent reports in the ManifestWorks.
+func (w *manifestWorks) report(ctx context.Context, syncTarget *tmcv1alpha1.SyncTarget) error {
+	list, err := w.downstream.Resource(workapi.ManifestWorksGVR).Namespace(w.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{LabelSyncTarget: w.key}).String(),
+	})
+	if err != nil {
+		return err
+	}
+	var statuses []workapi.ResourceStatus
+	for i := range list.Items {
+		s, err := workapi.ParseStatus(&list.Items[i])
+		if err != nil {
+			return err
+		}
+		statuses = append(statuses, s...)
+	}
+	workapi.SetCondition(syncTarget, statuses)
+	return nil
+}
+
+// workloadName returns the name of a workload in its ManifestWork.
+func workloadName(ref closure.Reference) string {
+	parts := []string{strings.ToLower(ref.Kind)}
+	if ref.Group != "" {
+		parts = append(parts, strings.ReplaceAll(ref.Group, ".", "-"))
+	}
+	if ref.Namespace != "" {
+		parts = append(parts, ref.Namespace)
+	}
+	return strings.Join(append(parts, ref.Name), "-")
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multitarget
+
+import (
+	"sync"
+
+	compbasemetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+var (
+	targetUp = compbasemetrics.NewGaugeVec(
+		&compbasemetrics.GaugeOpts{
+			Name:           "syncer_target_up",
+			Help:           "Whether the syncer of a SyncTarget is running, 1 or 0.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"sync_target"},
+	)
+	targetRestarts = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Name:           "syncer_target_restarts_total",
+			Help:           "Number of restarts of the syncer of a SyncTarget after failures.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"sync_target"},
+	)
+)
+
+var registerMetrics sync.Once
+
+// Register metrics.
+func Register() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(targetUp)
+		legacyregistry.MustRegister(targetRestarts)
+	})
+}
+
+func init() {
+	Register()
+}
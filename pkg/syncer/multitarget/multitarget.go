@@ -0,0 +1,279 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package multitarget runs the syncers of several SyncTargets in one
+// process. Every target gets its own connections to kcp and the physical
+// cluster, its own queues and its own metric label values, and runs in
+// isolation: a target that fails or panics is restarted with backoff while
+// the others keep syncing.
+package multitarget
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+)
+
+const (
+	defaultRestartBackoff    = time.Second
+	defaultMaxRestartBackoff = 5 * time.Minute
+)
+
+// Target is a SyncTarget served by the process.
+type Target struct {
+	// Path is the workspace of the SyncTarget.
+	Path logicalcluster.Path
+	// Name is the name of the SyncTarget.
+	Name string
+}
+
+// String returns the target as <workspace path>:<name>.
+func (t Target) String() string {
+	return t.Path.Join(t.Name).String()
+}
+
+// ParseTarget parses a target given as <workspace path>:<name>.
+func ParseTarget(s string) (Target, error) {
+	path, name := logicalcluster.NewPath(s).Split()
+	if path.Empty() || name == "" || !path.IsValid() {
+		return Target{}, fmt.Errorf("invalid SyncTarget %q, expected <workspace path>:<name>", s)
+	}
+	return Target{Path: path, Name: name}, nil
+}
+
+// QueueName returns the name of a queue of the target, as registered for
+// diagnostics.
+func QueueName(target Target, queue string) string {
+	return target.String() + "/" + queue
+}
+
+// Options configure the SyncTargets served by the process.
+type Options struct {
+	// SyncTargets are the targets, as <workspace path>:<name>.
+	SyncTargets []string
+	// RestartBackoff is the delay before a failed target is restarted the
+	// first time. It doubles with every failure up to MaxRestartBackoff.
+	RestartBackoff time.Duration
+	// MaxRestartBackoff bounds the restart delay. A target that ran for
+	// longer than that before failing is restarted after RestartBackoff.
+	MaxRestartBackoff time.Duration
+}
+
+// NewOptions returns options without targets.
+func NewOptions() *Options {
+	return &Options{
+		RestartBackoff:    defaultRestartBackoff,
+		MaxRestartBackoff: defaultMaxRestartBackoff,
+	}
+}
+
+// AddFlags adds the multi-target flags to the flag set.
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.StringSliceVar(&o.SyncTargets, "sync-targets", o.SyncTargets, "SyncTargets served by this syncer, as <workspace path>:<name>, e.g. root:org:team:edge-1. Each target is synced in isolation.")
+	fs.DurationVar(&o.RestartBackoff, "sync-target-restart-backoff", o.RestartBackoff, "Initial delay before restarting the syncer of a failed SyncTarget.")
+	fs.DurationVar(&o.MaxRestartBackoff, "sync-target-max-restart-backoff", o.MaxRestartBackoff, "Maximum delay before restarting the syncer of a failed SyncTarget.")
+}
+
+// Validate validates the options.
+func (o *Options) Validate() error {
+	targets, err := o.Targets()
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("--sync-targets must name at least one SyncTarget")
+	}
+	if o.RestartBackoff <= 0 || o.MaxRestartBackoff < o.RestartBackoff {
+		return fmt.Errorf("--sync-target-restart-backoff must be positive and at most --sync-target-max-restart-backoff")
+	}
+	return nil
+}
+
+// Targets returns the parsed targets.
+func (o *Options) Targets() ([]Target, error) {
+	targets := make([]Target, 0, len(o.SyncTargets))
+	seen := map[Target]bool{}
+	for _, s := range o.SyncTargets {
+		target, err := ParseTarget(strings.TrimSpace(s))
+		if err != nil {
+			return nil, err
+		}
+		if seen[target] {
+			return nil, fmt.Errorf("SyncTarget %s is given twice", target)
+		}
+		seen[target] = true
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// RunFunc runs the syncer of one target until ctx is done. It returns an
+// error if the target fails and must be restarted.
+type RunFunc func(ctx context.Context, target Target, upstream, downstream *rest.Config) error
+
+// TargetStatus is the state of the syncer of one target.
+type TargetStatus struct {
+	Target   string `json:"target"`
+	Running  bool   `json:"running"`
+	Restarts int    `json:"restarts"`
+	// LastError is the error of the last failure, if any.
+	LastError string `json:"lastError,omitempty"`
+}
+
+// Supervisor runs and restarts the syncers of the targets.
+type Supervisor struct {
+	options              Options
+	upstream, downstream *rest.Config
+	run                  RunFunc
+	now                  func() time.Time
+
+	lock     sync.Mutex
+	statuses map[Target]*TargetStatus
+}
+
+// NewSupervisor returns a supervisor running the syncer of every target
+// with run, with isolated copies of the upstream and downstream configs.
+func NewSupervisor(options *Options, upstream, downstream *rest.Config, run RunFunc) *Supervisor {
+	return &Supervisor{
+		options:    *options,
+		upstream:   upstream,
+		downstream: downstream,
+		run:        run,
+		now:        time.Now,
+		statuses:   map[Target]*TargetStatus{},
+	}
+}
+
+// Run runs the syncers of the targets until ctx is done, and returns once
+// all of them stopped.
+func (s *Supervisor) Run(ctx context.Context, targets []Target) {
+	var wg sync.WaitGroup
+	for _, target := range targets {
+		s.lock.Lock()
+		s.statuses[target] = &TargetStatus{Target: target.String()}
+		s.lock.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.supervise(ctx, target)
+		}()
+	}
+	wg.Wait()
+}
+
+// Statuses returns the state of all targets, sorted by target.
+func (s *Supervisor) Statuses() []TargetStatus {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	statuses := make([]TargetStatus, 0, len(s.statuses))
+	for _, status := range s.statuses {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Target < statuses[j].Target })
+	return statuses
+}
+
+func (s *Supervisor) supervise(ctx context.Context, target Target) {
+	logger := klog.FromContext(ctx).WithValues("syncTarget", target.String())
+	ctx = klog.NewContext(ctx, logger)
+	label := target.String()
+	defer targetUp.WithLabelValues(label).Set(0)
+
+	backoff := s.options.RestartBackoff
+	for {
+		s.setRunning(target, true, nil)
+		targetUp.WithLabelValues(label).Set(1)
+		started := s.now()
+		err := s.runIsolated(ctx, target)
+		targetUp.WithLabelValues(label).Set(0)
+
+		if ctx.Err() != nil {
+			s.setRunning(target, false, nil)
+			return
+		}
+		if err == nil {
+			err = fmt.Errorf("syncer stopped unexpectedly")
+		}
+		s.setRunning(target, false, err)
+		targetRestarts.WithLabelValues(label).Inc()
+
+		if s.now().Sub(started) > s.options.MaxRestartBackoff {
+			backoff = s.options.RestartBackoff
+		}
+		logger.Error(err, "syncer of SyncTarget failed, restarting", "backoff", backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, s.options.MaxRestartBackoff)
+	}
+}
+
+// runIsolated runs the syncer of the target, turning panics into errors so
+// that they do not take down the other targets.
+func (s *Supervisor) runIsolated(ctx context.Context, target Target) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			utilruntime.HandleError(fmt.Errorf("syncer of SyncTarget %s panicked: %v", target, r))
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return s.run(ctx, target, IsolatedConfig(s.upstream, target), IsolatedConfig(s.downstream, target))
+}
+
+func (s *Supervisor) setRunning(target Target, running bool, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	status := s.statuses[target]
+	status.Running = running
+	if err != nil {
+		status.Restarts++
+		status.LastError = err.Error()
+	}
+}
+
+// IsolatedConfig returns a copy of config that does not share connections
+// with other configs, identifying the target in the user agent. Client-go
+// shares transports between configs with equal TLS settings unless they
+// dial themselves.
+func IsolatedConfig(config *rest.Config, target Target) *rest.Config {
+	if config == nil {
+		return nil
+	}
+	isolated := rest.CopyConfig(config)
+	if isolated.UserAgent == "" {
+		isolated.UserAgent = rest.DefaultKubernetesUserAgent()
+	}
+	isolated.UserAgent += " sync-target/" + target.String()
+	if isolated.Dial == nil {
+		isolated.Dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+	}
+	return isolated
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multitarget
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/client-go/rest"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+)
+
+func TestParseTarget(t *testing.T) {
+	target, err := ParseTarget("root:org:edge-1")
+	require.NoError(t, err)
+	require.Equal(t, Target{Path: logicalcluster.NewPath("root:org"), Name: "edge-1"}, target)
+	require.Equal(t, "root:org:edge-1", target.String())
+
+	for _, s := range []string{"", "edge-1", "root:", "root::edge-1"} {
+		_, err := ParseTarget(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	o := NewOptions()
+	require.Error(t, o.Validate())
+
+	o.SyncTargets = []string{"root:a:one", "root:b:two"}
+	require.NoError(t, o.Validate())
+
+	o.SyncTargets = []string{"root:a:one", "root:a:one"}
+	require.Error(t, o.Validate())
+}
+
+func TestIsolatedConfig(t *testing.T) {
+	config := &rest.Config{Host: "https://kcp", UserAgent: "syncer"}
+	target := Target{Path: logicalcluster.NewPath("root:org"), Name: "edge-1"}
+
+	isolated := IsolatedConfig(config, target)
+	require.Equal(t, "syncer sync-target/root:org:edge-1", isolated.UserAgent)
+	require.NotNil(t, isolated.Dial)
+	require.Nil(t, config.Dial, "the original config must not change")
+	require.Equal(t, "syncer", config.UserAgent)
+}
+
+func TestSupervisorIsolatesFailures(t *testing.T) {
+	healthy := Target{Path: logicalcluster.NewPath("root:a"), Name: "healthy"}
+	failing := Target{Path: logicalcluster.NewPath("root:b"), Name: "failing"}
+
+	var healthyStarts, failingStarts atomic.Int32
+	run := func(ctx context.Context, target Target, upstream, downstream *rest.Config) error {
+		require.Contains(t, upstream.UserAgent, target.String())
+		require.Contains(t, downstream.UserAgent, target.String())
+		switch target {
+		case healthy:
+			healthyStarts.Add(1)
+			<-ctx.Done()
+			return nil
+		default:
+			if failingStarts.Add(1)%2 == 0 {
+				panic("boom")
+			}
+			return errors.New("connection refused")
+		}
+	}
+
+	options := NewOptions()
+	options.RestartBackoff = time.Millisecond
+	options.MaxRestartBackoff = 4 * time.Millisecond
+	s := NewSupervisor(options, &rest.Config{}, &rest.Config{}, run)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Run(ctx, []Target{healthy, failing})
+	}()
+
+	require.Eventually(t, func() bool { return failingStarts.Load() >= 5 }, wait, tick)
+	statuses := s.Statuses()
+	require.Len(t, statuses, 2)
+	require.Equal(t, "root:a:healthy", statuses[0].Target)
+	require.True(t, statuses[0].Running)
+	require.Zero(t, statuses[0].Restarts)
+	require.Equal(t, "root:b:failing", statuses[1].Target)
+	require.GreaterOrEqual(t, statuses[1].Restarts, 4)
+	require.NotEmpty(t, statuses[1].LastError)
+	require.Equal(t, int32(1), healthyStarts.Load(), "a failing target must not restart the others")
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(wait):
+		t.Fatal("supervisor did not stop")
+	}
+	for _, status := range s.Statuses() {
+		require.False(t, status.Running, status.Target)
+	}
+}
+
+const (
+	wait = 5 * time.Second
+	tick = 10 * time.Millisecond
+)
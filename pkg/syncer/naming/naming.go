@@ -0,0 +1,152 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package naming derives the names of downstream objects from their
+// upstream identity. Names only depend on their inputs, so a syncer computes
+// the same names after a restart or an upgrade, and names that are too long
+// are shortened with a hash of the full name instead of being truncated, so
+// that they stay distinct. The upstream identity of renamed objects is kept
+// in annotations, so downstream objects can be mapped back.
+package naming
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+)
+
+const (
+	// HashLength is the length of the hashes in generated names.
+	HashLength = 8
+
+	// ReservedPrefix starts the names generated by Prefixed. Names with a
+	// reserved prefix must not be synced under their own name, because they
+	// cannot be told apart from generated names.
+	ReservedPrefix = "tmc-"
+
+	// AnnotationUpstreamWorkspace is set on renamed downstream objects to
+	// the logical cluster of the upstream object.
+	AnnotationUpstreamWorkspace = "tmc.kcp.io/upstream-workspace"
+	// AnnotationUpstreamNamespace is set on renamed downstream objects to
+	// the namespace of the upstream object, if it is namespaced.
+	AnnotationUpstreamNamespace = "tmc.kcp.io/upstream-namespace"
+	// AnnotationUpstreamName is set on renamed downstream objects to the
+	// name of the upstream object.
+	AnnotationUpstreamName = "tmc.kcp.io/upstream-name"
+	// AnnotationUpstreamPath is set on renamed downstream objects to the
+	// workspace path of the upstream object, if known. Unlike the logical
+	// cluster, the path changes when the workspace is moved.
+	AnnotationUpstreamPath = "tmc.kcp.io/upstream-path"
+)
+
+var reservedPrefix = regexp.MustCompile(`^` + ReservedPrefix + `[0-9a-f]{8}-`)
+
+// Hash returns a hash of parts of HashLength hex characters. Parts are
+// separated, so that ("a", "bc") and ("ab", "c") hash differently. A single
+// part hashes to the first characters of its hex encoded SHA-256.
+func Hash(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])[:HashLength]
+}
+
+// Bounded returns name if it is at most maxLen long. Longer names are cut
+// and end with a dash and the hash of the full name, so names that only
+// differ after the cut stay distinct. maxLen must leave room for more than
+// the hash.
+func Bounded(name string, maxLen int) string {
+	if len(name) <= maxLen {
+		return name
+	}
+	// Cutting must not leave a dash or a dot in front of the hash, which
+	// is not a valid DNS subdomain.
+	return strings.TrimRight(name[:maxLen-HashLength-1], "-.") + "-" + Hash(name)
+}
+
+// Prefix returns the reserved prefix of names generated for scope.
+func Prefix(scope ...string) string {
+	return ReservedPrefix + Hash(scope...) + "-"
+}
+
+// Prefixed returns name prefixed with the prefix of scope, bounded to
+// maxLen. Objects of different scopes, like workspaces, thereby get
+// different names in a shared downstream namespace or cluster.
+func Prefixed(name string, maxLen int, scope ...string) string {
+	return Bounded(Prefix(scope...)+name, maxLen)
+}
+
+// HasReservedPrefix returns whether name starts like the names generated by
+// Prefixed.
+func HasReservedPrefix(name string) bool {
+	return reservedPrefix.MatchString(name)
+}
+
+// Namespace returns the downstream namespace of a namespace of the
+// workspace.
+func Namespace(workspace logicalcluster.Name, namespace string) string {
+	return Prefixed(namespace, validation.DNS1123LabelMaxLength, workspace.String())
+}
+
+// Identity identifies an upstream object.
+type Identity struct {
+	Workspace logicalcluster.Name
+	// Path is the path of the workspace for humans, or empty if unknown.
+	Path      logicalcluster.Path
+	Namespace string
+	Name      string
+}
+
+// SetUpstream records the upstream identity on a downstream object.
+func SetUpstream(obj metav1.Object, id Identity) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[AnnotationUpstreamWorkspace] = id.Workspace.String()
+	annotations[AnnotationUpstreamName] = id.Name
+	if id.Namespace != "" {
+		annotations[AnnotationUpstreamNamespace] = id.Namespace
+	} else {
+		delete(annotations, AnnotationUpstreamNamespace)
+	}
+	if !id.Path.Empty() {
+		annotations[AnnotationUpstreamPath] = id.Path.String()
+	} else {
+		delete(annotations, AnnotationUpstreamPath)
+	}
+	obj.SetAnnotations(annotations)
+}
+
+// UpstreamOf returns the upstream identity recorded on a downstream object.
+// It returns false for objects without a recorded identity.
+func UpstreamOf(obj metav1.Object) (Identity, bool) {
+	annotations := obj.GetAnnotations()
+	workspace, name := annotations[AnnotationUpstreamWorkspace], annotations[AnnotationUpstreamName]
+	if workspace == "" || name == "" {
+		return Identity{}, false
+	}
+	return Identity{
+		Workspace: logicalcluster.Name(workspace),
+		Path:      logicalcluster.NewPath(annotations[AnnotationUpstreamPath]),
+		Namespace: annotations[AnnotationUpstreamNamespace],
+		Name:      name,
+	}, true
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package naming
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+)
+
+func TestHash(t *testing.T) {
+	require.Equal(t, Hash("root:org"), Hash("root:org"))
+	require.Len(t, Hash("root:org"), HashLength)
+	require.NotEqual(t, Hash("a", "bc"), Hash("ab", "c"))
+	require.NotEqual(t, Hash("a", "b"), Hash("a-b"))
+	// Single parts hash as before this package existed.
+	require.Equal(t, "91231aa3", Hash("root:org"))
+}
+
+func TestBounded(t *testing.T) {
+	require.Equal(t, "short", Bounded("short", 10))
+	require.Equal(t, "exactly-10", Bounded("exactly-10", 10))
+
+	long := strings.Repeat("a", 300)
+	bounded := Bounded(long, validation.DNS1123SubdomainMaxLength)
+	require.Len(t, bounded, validation.DNS1123SubdomainMaxLength)
+	require.True(t, strings.HasSuffix(bounded, "-"+Hash(long)))
+	require.Equal(t, bounded, Bounded(bounded, validation.DNS1123SubdomainMaxLength), "bounding is idempotent")
+
+	// Cutting at a dash or a dot keeps the name valid.
+	for _, name := range []string{"abcdefghi-.-jklmnopqrstuvwxyz", "abcdefghi.jklmnopqrstuvwxyz", "abcdefghij-klmnopqrstuvwxyz"} {
+		bounded := Bounded(name, 20)
+		require.LessOrEqual(t, len(bounded), 20, bounded)
+		require.Empty(t, validation.IsDNS1123Subdomain(bounded), bounded)
+	}
+}
+
+func TestPrefixed(t *testing.T) {
+	name := Prefixed("web", validation.DNS1123SubdomainMaxLength, "root:org")
+	require.Equal(t, "tmc-91231aa3-web", name)
+	require.True(t, HasReservedPrefix(name))
+	require.False(t, HasReservedPrefix("web"))
+	require.False(t, HasReservedPrefix("tmc-web"))
+	require.False(t, HasReservedPrefix("tmc-91231AA3-web"))
+
+	long := Prefixed(strings.Repeat("x", 300), validation.DNS1123SubdomainMaxLength, "root:org")
+	require.Len(t, long, validation.DNS1123SubdomainMaxLength)
+	require.True(t, strings.HasPrefix(long, Prefix("root:org")))
+}
+
+// TestCollisions checks that names of every combination of workspaces,
+// namespaces and names, including long ones that only differ after the
+// maximum length, are distinct, valid and stable.
+func TestCollisions(t *testing.T) {
+	workspaces := []logicalcluster.Name{"root", "root:org", "root:org:team", "2v7ac4kj0nl5jzpm", "2v7ac4kj0nl5jzpn"}
+	long := strings.Repeat("long-", 60)
+	names := []string{"a", "a-b", "a.b", "ab", "tmc-91231aa3-web", "web"}
+	for i := 0; i < 20; i++ {
+		names = append(names, fmt.Sprintf("%s%d", long, i), fmt.Sprintf("%s%d", long[:240], i), fmt.Sprintf("%s-%d", long[:61], i))
+	}
+	namespaces := []string{"default", "kube-system", long[:62] + "a", long[:62] + "b", long[:70]}
+
+	type input struct {
+		workspace logicalcluster.Name
+		name      string
+	}
+	seenNames := map[string]input{}
+	seenNamespaces := map[string]input{}
+	for _, ws := range workspaces {
+		for _, name := range names {
+			got := Prefixed(name, validation.DNS1123SubdomainMaxLength, ws.String())
+			require.Equal(t, got, Prefixed(name, validation.DNS1123SubdomainMaxLength, ws.String()), "names are deterministic")
+			require.Empty(t, validation.IsDNS1123Subdomain(got), got)
+			require.True(t, HasReservedPrefix(got), got)
+			if other, found := seenNames[got]; found {
+				t.Fatalf("%v and %v both map to %q", other, input{ws, name}, got)
+			}
+			seenNames[got] = input{ws, name}
+		}
+		for _, ns := range namespaces {
+			got := Namespace(ws, ns)
+			require.Empty(t, validation.IsDNS1123Label(got), got)
+			if other, found := seenNamespaces[got]; found {
+				t.Fatalf("%v and %v both map to %q", other, input{ws, ns}, got)
+			}
+			seenNamespaces[got] = input{ws, ns}
+		}
+	}
+}
+
+func TestUpstream(t *testing.T) {
+	obj := &metav1.ObjectMeta{Annotations: map[string]string{"other": "kept"}}
+	_, found := UpstreamOf(obj)
+	require.False(t, found)
+
+	id := Identity{Workspace: "2v7ac4kj0nl5jzpm", Path: logicalcluster.NewPath("root:org"), Namespace: "default", Name: "web"}
+	SetUpstream(obj, id)
+	got, found := UpstreamOf(obj)
+	require.True(t, found)
+	require.Equal(t, id, got)
+	require.Equal(t, "kept", obj.Annotations["other"])
+
+	SetUpstream(obj, Identity{Workspace: "2v7ac4kj0nl5jzpm", Name: "cluster-wide"})
+	got, found = UpstreamOf(obj)
+	require.True(t, found)
+	require.Equal(t, Identity{Workspace: "2v7ac4kj0nl5jzpm", Name: "cluster-wide"}, got)
+	require.NotContains(t, obj.Annotations, AnnotationUpstreamPath)
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package observer runs the syncer in observer mode, for evaluating TMC
+// against production clusters before granting the syncer write access. An
+// observer syncer performs discovery, reports upstream status, harvests
+// capacity and runs health checks, but never changes the physical cluster:
+// its client to the physical cluster rejects every mutating request before
+// it is sent. The SyncTarget reports the mode in its ObserveOnly condition,
+// and no workloads are placed on it.
+package observer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// Mode is how the syncer treats the physical cluster.
+type Mode string
+
+const (
+	// ModeSync syncs workloads to the physical cluster.
+	ModeSync Mode = "sync"
+	// ModeObserver only observes the physical cluster.
+	ModeObserver Mode = "observer"
+)
+
+// reviewPathRegexp matches the review APIs, which are created but do not
+// change the cluster, e.g. the access reviews checking the permissions of
+// the syncer.
+var reviewPathRegexp = regexp.MustCompile(`^/apis/(authorization|authentication)\.k8s\.io/[^/]+/(namespaces/[^/]+/)?[a-z]+reviews$`)
+
+// Options configure the mode of the syncer.
+type Options struct {
+	// Mode is the mode of the syncer.
+	Mode Mode
+}
+
+// NewOptions returns options syncing workloads.
+func NewOptions() *Options {
+	return &Options{
+		Mode: ModeSync,
+	}
+}
+
+// AddFlags adds the mode flag to the flag set.
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar((*string)(&o.Mode), "mode", string(o.Mode), "Mode of the syncer: sync, or observer to report discovery, status, capacity and health without changing the physical cluster.")
+}
+
+// Validate validates the options.
+func (o *Options) Validate() error {
+	switch o.Mode {
+	case ModeSync, ModeObserver:
+		return nil
+	}
+	return fmt.Errorf("--mode must be %s or %s", ModeSync, ModeObserver)
+}
+
+// Observer returns whether the syncer runs in observer mode.
+func (o *Options) Observer() bool {
+	return o.Mode == ModeObserver
+}
+
+// Guard returns config unchanged in sync mode. In observer mode, it returns
+// a copy whose requests are not sent if they would change the cluster:
+// creations other than reviews, updates, patches and deletions, unless they
+// are dry runs. They fail with a Forbidden error instead, which syncer
+// controllers already handle like missing permissions.
+func (o *Options) Guard(config *rest.Config, syncTarget string) *rest.Config {
+	if !o.Observer() {
+		return config
+	}
+	config = rest.CopyConfig(config)
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &guard{delegate: rt, syncTarget: syncTarget}
+	})
+	return config
+}
+
+type guard struct {
+	delegate   http.RoundTripper
+	syncTarget string
+}
+
+func (g *guard) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !Mutating(req) {
+		return g.delegate.RoundTrip(req)
+	}
+	blockedMutations.WithLabelValues(g.syncTarget, verb(req.Method)).Inc()
+	klog.FromContext(req.Context()).V(2).Info("not sending mutation in observer mode", "method", req.Method, "path", req.URL.Path)
+
+	status := apierrors.NewForbidden(schema.GroupResource{}, "", fmt.Errorf("the syncer of SyncTarget %q runs in observer mode and does not change the physical cluster", g.syncTarget)).Status()
+	status.APIVersion, status.Kind = "v1", "Status"
+	body, err := json.Marshal(&status)
+	if err != nil {
+		return nil, err
+	}
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", http.StatusForbidden, http.StatusText(http.StatusForbidden)),
+		StatusCode:    http.StatusForbidden,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(strings.NewReader(string(body))),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// Mutating returns whether the request would change the cluster.
+func Mutating(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	case http.MethodPost:
+		if reviewPathRegexp.MatchString(req.URL.Path) {
+			return false
+		}
+	}
+	for _, dryRun := range req.URL.Query()["dryRun"] {
+		if dryRun == metav1.DryRunAll {
+			return false
+		}
+	}
+	return true
+}
+
+func verb(method string) string {
+	switch method {
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut:
+		return "update"
+	case http.MethodPatch:
+		return "patch"
+	case http.MethodDelete:
+		return "delete"
+	}
+	return strings.ToLower(method)
+}
+
+// SetCondition sets the ObserveOnly condition of target from the mode.
+func SetCondition(target *tmcv1alpha1.SyncTarget, mode Mode) {
+	if mode != ModeObserver {
+		conditions.Delete(target, tmcv1alpha1.ObserveOnly)
+		return
+	}
+	conditions.Set(target, &conditionsv1alpha1.Condition{
+		Type:     tmcv1alpha1.ObserveOnly,
+		Status:   corev1.ConditionTrue,
+		Severity: conditionsv1alpha1.ConditionSeverityInfo,
+		Reason:   tmcv1alpha1.ObserverModeReason,
+		Message:  "The syncer runs in observer mode and does not change the physical cluster. No workloads are placed on the SyncTarget.",
+	})
+}
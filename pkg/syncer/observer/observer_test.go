@@ -0,0 +1,107 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package observer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func TestGuard(t *testing.T) {
+	var lock sync.Mutex
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		received = append(received, r.Method+" "+r.URL.Path)
+		lock.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api/v1/namespaces/default/configmaps/c" && r.Method == http.MethodGet:
+			_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"c","namespace":"default"}}`))
+		case r.URL.Path == "/api/v1/namespaces/default/configmaps" && r.Method == http.MethodPost:
+			_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"c","namespace":"default"}}`))
+		default:
+			_, _ = w.Write([]byte(`{"apiVersion":"authorization.k8s.io/v1","kind":"SelfSubjectAccessReview","status":{"allowed":true}}`))
+		}
+	}))
+	defer server.Close()
+
+	o := NewOptions()
+	config := &rest.Config{Host: server.URL}
+	require.Same(t, config, o.Guard(config, "east"), "sync mode does not guard")
+
+	o.Mode = ModeObserver
+	require.NoError(t, o.Validate())
+	client, err := kubernetes.NewForConfig(o.Guard(config, "east"))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = client.CoreV1().ConfigMaps("default").Get(ctx, "c", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "c"}}
+	_, err = client.CoreV1().ConfigMaps("default").Create(ctx, cm, metav1.CreateOptions{})
+	require.True(t, apierrors.IsForbidden(err), "unexpected error: %v", err)
+	require.ErrorContains(t, err, `the syncer of SyncTarget "east" runs in observer mode`)
+	_, err = client.CoreV1().ConfigMaps("default").Update(ctx, cm, metav1.UpdateOptions{})
+	require.True(t, apierrors.IsForbidden(err), "unexpected error: %v", err)
+	err = client.CoreV1().ConfigMaps("default").Delete(ctx, "c", metav1.DeleteOptions{})
+	require.True(t, apierrors.IsForbidden(err), "unexpected error: %v", err)
+
+	_, err = client.CoreV1().ConfigMaps("default").Create(ctx, cm, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+	require.NoError(t, err, "dry runs do not change the cluster")
+	_, err = client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{}, metav1.CreateOptions{})
+	require.NoError(t, err, "reviews do not change the cluster")
+
+	require.Equal(t, []string{
+		"GET /api/v1/namespaces/default/configmaps/c",
+		"POST /api/v1/namespaces/default/configmaps",
+		"POST /apis/authorization.k8s.io/v1/selfsubjectaccessreviews",
+	}, received)
+}
+
+func TestOptionsValidate(t *testing.T) {
+	o := NewOptions()
+	require.NoError(t, o.Validate())
+	o.Mode = "readonly"
+	require.EqualError(t, o.Validate(), "--mode must be sync or observer")
+}
+
+func TestSetCondition(t *testing.T) {
+	target := &tmcv1alpha1.SyncTarget{}
+	SetCondition(target, ModeObserver)
+	require.True(t, conditions.IsTrue(target, tmcv1alpha1.ObserveOnly))
+	require.Equal(t, tmcv1alpha1.ObserverModeReason, conditions.GetReason(target, tmcv1alpha1.ObserveOnly))
+
+	SetCondition(target, ModeSync)
+	require.Nil(t, conditions.Get(target, tmcv1alpha1.ObserveOnly))
+}
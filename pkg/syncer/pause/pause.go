@@ -0,0 +1,171 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pause holds the syncer of a SyncTarget while its spec.paused is
+// set. Unlike cordoning with spec.unschedulable, which only keeps new
+// workloads off the target, pausing stops changing the physical cluster and
+// writing status back to kcp. Nothing is dropped: requests changing the
+// physical cluster wait until the SyncTarget is resumed, and fail only when
+// their deadline passes first, so that the controllers sending them requeue
+// their work. The status writer holds its pending updates. Leases, Events
+// and the heartbeat of the SyncTarget are written while paused, so that
+// leader election and health reporting keep working. The SyncTarget reports the pause
+// in its Paused condition, and the placement engine keeps new workloads off
+// it while leaving the placed ones where they are.
+package pause
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/observer"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// defaultMaxHold bounds how long a request without deadline is held while
+// paused.
+const defaultMaxHold = time.Minute
+
+// exemptPathRegexp matches the requests that are sent while paused: leases,
+// e.g. of the leader election, Events, and the SyncTarget, whose status
+// carries the heartbeat. Paths may be below a workspace or virtual
+// workspace prefix.
+var exemptPathRegexp = regexp.MustCompile(`/apis/(coordination|events)\.k8s\.io/|/api/v1/(namespaces/[^/]+/)?events(/|$)|/apis/tmc\.kcp\.io/[^/]+/synctargets(/|$)`)
+
+// Gate is the paused state of the syncer of one SyncTarget.
+type Gate struct {
+	syncTarget string
+
+	lock   sync.Mutex
+	paused bool
+	// resumed is closed when the gate is resumed.
+	resumed chan struct{}
+}
+
+// NewGate returns a gate of the SyncTarget that is not paused.
+func NewGate(syncTarget string) *Gate {
+	return &Gate{syncTarget: syncTarget}
+}
+
+// Update pauses or resumes the gate from the spec of target, and returns
+// whether that changed it. A SyncTarget being deleted is never paused, so
+// that the syncer can remove the downstream resources.
+func (g *Gate) Update(target *tmcv1alpha1.SyncTarget) bool {
+	return g.Set(target.Spec.Paused && target.DeletionTimestamp.IsZero())
+}
+
+// Set pauses or resumes the gate, and returns whether that changed it.
+func (g *Gate) Set(paused bool) bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if g.paused == paused {
+		return false
+	}
+	g.paused = paused
+	if paused {
+		g.resumed = make(chan struct{})
+	} else {
+		close(g.resumed)
+	}
+	return true
+}
+
+// Paused returns whether the gate is paused.
+func (g *Gate) Paused() bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return g.paused
+}
+
+// Wait blocks while the gate is paused. It returns the error of ctx if ctx
+// is done first.
+func (g *Gate) Wait(ctx context.Context) error {
+	g.lock.Lock()
+	paused, resumed := g.paused, g.resumed
+	g.lock.Unlock()
+	if !paused {
+		return nil
+	}
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Guard returns a copy of config whose requests changing the cluster wait
+// while the gate is paused. Reads are sent, so that informers stay in sync,
+// and so are the exempt writes, see exemptPathRegexp. Requests wait until
+// their context is done, or at most the timeout of config, or a minute
+// without timeout.
+func (g *Gate) Guard(config *rest.Config) *rest.Config {
+	config = rest.CopyConfig(config)
+	maxHold := config.Timeout
+	if maxHold <= 0 {
+		maxHold = defaultMaxHold
+	}
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &guard{delegate: rt, gate: g, maxHold: maxHold}
+	})
+	return config
+}
+
+type guard struct {
+	delegate http.RoundTripper
+	gate     *Gate
+	maxHold  time.Duration
+}
+
+func (g *guard) RoundTrip(req *http.Request) (*http.Response, error) {
+	if observer.Mutating(req) && !exemptPathRegexp.MatchString(req.URL.Path) && g.gate.Paused() {
+		klog.FromContext(req.Context()).V(4).Info("holding mutation while the SyncTarget is paused", "syncTarget", g.gate.syncTarget, "method", req.Method, "path", req.URL.Path)
+		ctx, cancel := context.WithTimeout(req.Context(), g.maxHold)
+		defer cancel()
+		if err := g.gate.Wait(ctx); err != nil {
+			if req.Body != nil {
+				_ = req.Body.Close()
+			}
+			return nil, fmt.Errorf("SyncTarget %s is paused: %w", g.gate.syncTarget, err)
+		}
+	}
+	return g.delegate.RoundTrip(req)
+}
+
+// SetCondition sets the Paused condition of target.
+func SetCondition(target *tmcv1alpha1.SyncTarget, paused bool) {
+	if !paused {
+		conditions.Delete(target, tmcv1alpha1.Paused)
+		return
+	}
+	conditions.Set(target, &conditionsv1alpha1.Condition{
+		Type:     tmcv1alpha1.Paused,
+		Status:   corev1.ConditionTrue,
+		Severity: conditionsv1alpha1.ConditionSeverityInfo,
+		Reason:   tmcv1alpha1.SyncPausedReason,
+		Message:  "The syncer does not change the physical cluster or write status until spec.paused is cleared. No new workloads are placed on the SyncTarget.",
+	})
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pause
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func TestGate(t *testing.T) {
+	g := NewGate("east")
+	require.NoError(t, g.Wait(context.Background()), "a new gate is not paused")
+
+	target := &tmcv1alpha1.SyncTarget{Spec: tmcv1alpha1.SyncTargetSpec{Paused: true}}
+	require.True(t, g.Update(target))
+	require.False(t, g.Update(target))
+	require.True(t, g.Paused())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, g.Wait(ctx), context.DeadlineExceeded)
+
+	done := make(chan error)
+	go func() { done <- g.Wait(context.Background()) }()
+	now := metav1.Now()
+	target.DeletionTimestamp = &now
+	require.True(t, g.Update(target), "SyncTargets being deleted are resumed")
+	require.NoError(t, <-done)
+}
+
+func TestGuard(t *testing.T) {
+	var lock sync.Mutex
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		received = append(received, r.Method+" "+r.URL.Path)
+		lock.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"c","namespace":"default"}}`))
+	}))
+	defer server.Close()
+
+	g := NewGate("east")
+	g.Set(true)
+	client, err := kubernetes.NewForConfig(g.Guard(&rest.Config{Host: server.URL}))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = client.CoreV1().ConfigMaps("default").Get(ctx, "c", metav1.GetOptions{})
+	require.NoError(t, err, "reads are sent while paused")
+
+	created := make(chan error)
+	go func() {
+		_, err := client.CoreV1().ConfigMaps("default").Create(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "c"}}, metav1.CreateOptions{})
+		created <- err
+	}()
+	select {
+	case err := <-created:
+		t.Fatalf("mutation was sent while paused: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	g.Set(false)
+	require.NoError(t, <-created, "held mutations are sent once resumed")
+	require.Equal(t, []string{
+		"GET /api/v1/namespaces/default/configmaps/c",
+		"POST /api/v1/namespaces/default/configmaps",
+	}, received)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	g.Set(true)
+	cancel()
+	err = client.CoreV1().ConfigMaps("default").Delete(cancelled, "c", metav1.DeleteOptions{})
+	require.ErrorIs(t, err, context.Canceled)
+
+	deadline, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	err = client.CoreV1().ConfigMaps("default").Delete(deadline, "c", metav1.DeleteOptions{})
+	require.ErrorIs(t, err, context.DeadlineExceeded, "held mutations fail when their deadline passes")
+
+	timeoutClient, err := kubernetes.NewForConfig(g.Guard(&rest.Config{Host: server.URL, Timeout: 50 * time.Millisecond}))
+	require.NoError(t, err)
+	err = timeoutClient.CoreV1().ConfigMaps("default").Delete(ctx, "c", metav1.DeleteOptions{})
+	require.ErrorContains(t, err, "SyncTarget east is paused", "held mutations fail after the timeout of the config")
+
+	lock.Lock()
+	received = nil
+	lock.Unlock()
+	_, err = client.CoordinationV1().Leases("kube-system").Create(ctx, &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Name: "syncer"}}, metav1.CreateOptions{})
+	require.NoError(t, err, "leases are written while paused")
+	_, err = client.CoreV1().Events("default").Create(ctx, &corev1.Event{ObjectMeta: metav1.ObjectMeta{Name: "e"}}, metav1.CreateOptions{})
+	require.NoError(t, err, "events are written while paused")
+	require.Equal(t, []string{
+		"POST /apis/coordination.k8s.io/v1/namespaces/kube-system/leases",
+		"POST /api/v1/namespaces/default/events",
+	}, received)
+}
+
+func TestExemptPaths(t *testing.T) {
+	for path, exempt := range map[string]bool{
+		"/apis/coordination.k8s.io/v1/namespaces/kube-system/leases/syncer":                   true,
+		"/api/v1/namespaces/default/events":                                                   true,
+		"/apis/events.k8s.io/v1/namespaces/default/events":                                    true,
+		"/clusters/root:org/apis/tmc.kcp.io/v1alpha1/synctargets/east/status":                 true,
+		"/services/syncer/root:org/east/clusters/*/apis/tmc.kcp.io/v1alpha1/synctargets/east": true,
+		"/api/v1/namespaces/events/configmaps/c":                                              false,
+		"/api/v1/namespaces/default/eventsources":                                             false,
+		"/apis/apps/v1/namespaces/default/deployments/web/status":                             false,
+	} {
+		require.Equal(t, exempt, exemptPathRegexp.MatchString(path), path)
+	}
+}
+
+func TestSetCondition(t *testing.T) {
+	target := &tmcv1alpha1.SyncTarget{}
+	SetCondition(target, true)
+	require.True(t, conditions.IsTrue(target, tmcv1alpha1.Paused))
+	require.Equal(t, tmcv1alpha1.SyncPausedReason, conditions.GetReason(target, tmcv1alpha1.Paused))
+
+	SetCondition(target, false)
+	require.Nil(t, conditions.Get(target, tmcv1alpha1.Paused))
+}
@@ -0,0 +1,327 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package permissions computes the RBAC rules the syncer needs on a
+// physical cluster for the synced resources and enabled features, renders
+// them as Role and ClusterRole manifests, and checks which of them the
+// service account of the syncer lacks, so that missing permissions are
+// reported on the SyncTarget before syncing fails.
+package permissions
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/client-go/kubernetes"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// maxMissingInMessage bounds the missing permissions listed in the condition.
+const maxMissingInMessage = 5
+
+var (
+	// syncVerbs are needed on every synced resource.
+	syncVerbs = []string{"get", "list", "watch", "create", "update", "patch", "delete"}
+	// readVerbs are needed on resources the syncer only observes.
+	readVerbs = []string{"get", "list", "watch"}
+)
+
+// Features are the optional syncer features needing permissions.
+type Features struct {
+	// Capacity reports the capacity of the physical cluster from its nodes.
+	Capacity bool
+	// PriorityClasses creates the PriorityClasses of synced pods.
+	PriorityClasses bool
+	// LeaderElection elects a leader among syncer replicas with a lease in
+	// the syncer namespace.
+	LeaderElection bool
+	// Observer runs the syncer in observer mode, which only reads from the
+	// physical cluster. Observer syncers do not elect a leader.
+	Observer bool
+}
+
+// FeatureGates returns the features as feature gates, reported by the syncer
+// next to its TMC feature gates.
+func (f Features) FeatureGates() []tmcv1alpha1.FeatureGate {
+	return []tmcv1alpha1.FeatureGate{
+		{Name: "Capacity", Enabled: f.Capacity},
+		{Name: "LeaderElection", Enabled: f.LeaderElection},
+		{Name: "Observer", Enabled: f.Observer},
+		{Name: "PriorityClasses", Enabled: f.PriorityClasses},
+	}
+}
+
+// Requirements are what the syncer syncs to a physical cluster.
+type Requirements struct {
+	// Resources are the synced namespaced resources.
+	Resources []schema.GroupResource
+	// ClusterScopedResources are the synced cluster-scoped resources.
+	ClusterScopedResources []schema.GroupResource
+	// Features are the enabled features.
+	Features Features
+}
+
+// ForSyncTarget returns the requirements of syncing resources to target,
+// including the cluster-scoped resources of the target.
+func ForSyncTarget(target *tmcv1alpha1.SyncTarget, resources []schema.GroupVersionResource, features Features) Requirements {
+	req := Requirements{Features: features}
+	for _, gvr := range resources {
+		req.Resources = append(req.Resources, gvr.GroupResource())
+	}
+	for _, r := range target.Spec.ClusterScopedResources {
+		req.ClusterScopedResources = append(req.ClusterScopedResources, schema.GroupResource{Group: r.Group, Resource: r.Resource})
+	}
+	return req
+}
+
+// ClusterRules returns the cluster-wide rules the syncer needs.
+func (r Requirements) ClusterRules() []rbacv1.PolicyRule {
+	b := ruleBuilder{}
+	verbs := syncVerbs
+	if r.Features.Observer {
+		verbs = readVerbs
+	}
+	// Downstream namespaces are created for the synced namespaces.
+	b.add(schema.GroupResource{Resource: "namespaces"}, verbs...)
+	for _, gr := range r.Resources {
+		b.add(gr, verbs...)
+	}
+	for _, gr := range r.ClusterScopedResources {
+		b.add(gr, verbs...)
+	}
+	if r.Features.Capacity {
+		b.add(schema.GroupResource{Resource: "nodes"}, readVerbs...)
+	}
+	if r.Features.PriorityClasses {
+		priorityClassVerbs := []string{"get", "list", "watch", "create"}
+		if r.Features.Observer {
+			priorityClassVerbs = readVerbs
+		}
+		b.add(schema.GroupResource{Group: "scheduling.k8s.io", Resource: "priorityclasses"}, priorityClassVerbs...)
+	}
+	return b.rules()
+}
+
+// NamespaceRules returns the rules the syncer needs in its own namespace.
+func (r Requirements) NamespaceRules() []rbacv1.PolicyRule {
+	b := ruleBuilder{}
+	if r.Features.LeaderElection && !r.Features.Observer {
+		b.add(schema.GroupResource{Group: "coordination.k8s.io", Resource: "leases"}, "get", "create", "update")
+	}
+	return b.rules()
+}
+
+// ruleBuilder collects verbs per resource and merges resources with the
+// same verbs into one rule per group.
+type ruleBuilder map[schema.GroupResource]sets.Set[string]
+
+func (b ruleBuilder) add(gr schema.GroupResource, verbs ...string) {
+	if b[gr] == nil {
+		b[gr] = sets.New[string]()
+	}
+	b[gr].Insert(verbs...)
+}
+
+func (b ruleBuilder) rules() []rbacv1.PolicyRule {
+	type ruleKey struct{ group, verbs string }
+	merged := map[ruleKey]*rbacv1.PolicyRule{}
+	for gr, verbs := range b {
+		sorted := sortedVerbs(verbs)
+		k := ruleKey{group: gr.Group, verbs: strings.Join(sorted, ",")}
+		rule, found := merged[k]
+		if !found {
+			rule = &rbacv1.PolicyRule{APIGroups: []string{gr.Group}, Verbs: sorted}
+			merged[k] = rule
+		}
+		rule.Resources = append(rule.Resources, gr.Resource)
+	}
+
+	rules := make([]rbacv1.PolicyRule, 0, len(merged))
+	for _, rule := range merged {
+		sort.Strings(rule.Resources)
+		rules = append(rules, *rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].APIGroups[0] != rules[j].APIGroups[0] {
+			return rules[i].APIGroups[0] < rules[j].APIGroups[0]
+		}
+		return rules[i].Resources[0] < rules[j].Resources[0]
+	})
+	return rules
+}
+
+// sortedVerbs sorts verbs in the order of syncVerbs, so that rules read
+// naturally.
+func sortedVerbs(verbs sets.Set[string]) []string {
+	order := map[string]int{}
+	for i, verb := range syncVerbs {
+		order[verb] = i
+	}
+	sorted := sets.List(verbs)
+	sort.SliceStable(sorted, func(i, j int) bool { return order[sorted[i]] < order[sorted[j]] })
+	return sorted
+}
+
+// Manifests returns a ClusterRole and ClusterRoleBinding, and if needed a
+// Role and RoleBinding in namespace, named name and granting the
+// requirements to the service account namespace/serviceAccount.
+func Manifests(name, namespace, serviceAccount string, req Requirements) []runtime.Object {
+	subjects := []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Namespace: namespace, Name: serviceAccount}}
+	objs := []runtime.Object{
+		&rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Rules:      req.ClusterRules(),
+		},
+		&rbacv1.ClusterRoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: name},
+			Subjects:   subjects,
+		},
+	}
+	if rules := req.NamespaceRules(); len(rules) > 0 {
+		objs = append(objs,
+			&rbacv1.Role{
+				TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "Role"},
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+				Rules:      rules,
+			},
+			&rbacv1.RoleBinding{
+				TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"},
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: name},
+				Subjects:   subjects,
+			},
+		)
+	}
+	return objs
+}
+
+// Permission is a verb on a resource, in a namespace or cluster-wide.
+type Permission struct {
+	Namespace string
+	Verb      string
+	Resource  schema.GroupResource
+}
+
+func (p Permission) String() string {
+	if p.Namespace == "" {
+		return fmt.Sprintf("%s %s", p.Verb, p.Resource)
+	}
+	return fmt.Sprintf("%s %s in namespace %s", p.Verb, p.Resource, p.Namespace)
+}
+
+// ReviewFunc returns whether the reviewed subject may perform attrs.
+type ReviewFunc func(ctx context.Context, attrs authorizationv1.ResourceAttributes) (bool, error)
+
+// SelfReview reviews the permissions of the user of client, e.g. of the
+// syncer itself.
+func SelfReview(client kubernetes.Interface) ReviewFunc {
+	return func(ctx context.Context, attrs authorizationv1.ResourceAttributes) (bool, error) {
+		review, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attrs},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return false, err
+		}
+		return review.Status.Allowed, nil
+	}
+}
+
+// ServiceAccountReview reviews the permissions of the service account
+// namespace/name with client, which must be allowed to create
+// SubjectAccessReviews.
+func ServiceAccountReview(client kubernetes.Interface, namespace, name string) ReviewFunc {
+	user := serviceaccount.MakeUsername(namespace, name)
+	groups := serviceaccount.MakeGroupNames(namespace)
+	return func(ctx context.Context, attrs authorizationv1.ResourceAttributes) (bool, error) {
+		review, err := client.AuthorizationV1().SubjectAccessReviews().Create(ctx, &authorizationv1.SubjectAccessReview{
+			Spec: authorizationv1.SubjectAccessReviewSpec{User: user, Groups: groups, ResourceAttributes: &attrs},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return false, err
+		}
+		return review.Status.Allowed, nil
+	}
+}
+
+// Missing returns the permissions of req that review denies, with the
+// namespace rules checked in namespace.
+func Missing(ctx context.Context, review ReviewFunc, namespace string, req Requirements) ([]Permission, error) {
+	var missing []Permission
+	check := func(namespace string, rules []rbacv1.PolicyRule) error {
+		for _, rule := range rules {
+			for _, group := range rule.APIGroups {
+				for _, resource := range rule.Resources {
+					for _, verb := range rule.Verbs {
+						allowed, err := review(ctx, authorizationv1.ResourceAttributes{
+							Namespace: namespace,
+							Verb:      verb,
+							Group:     group,
+							Resource:  resource,
+						})
+						if err != nil {
+							return fmt.Errorf("failed to review %s %s: %w", verb, schema.GroupResource{Group: group, Resource: resource}, err)
+						}
+						if !allowed {
+							missing = append(missing, Permission{Namespace: namespace, Verb: verb, Resource: schema.GroupResource{Group: group, Resource: resource}})
+						}
+					}
+				}
+			}
+		}
+		return nil
+	}
+	if err := check("", req.ClusterRules()); err != nil {
+		return nil, err
+	}
+	if err := check(namespace, req.NamespaceRules()); err != nil {
+		return nil, err
+	}
+	return missing, nil
+}
+
+// SetCondition sets the SyncerAuthorized condition of target from the
+// missing permissions.
+func SetCondition(target *tmcv1alpha1.SyncTarget, missing []Permission) {
+	if len(missing) == 0 {
+		conditions.MarkTrue(target, tmcv1alpha1.SyncerAuthorized)
+		return
+	}
+
+	messages := make([]string, 0, maxMissingInMessage)
+	for i, p := range missing {
+		if i == maxMissingInMessage {
+			messages = append(messages, fmt.Sprintf("and %d more", len(missing)-i))
+			break
+		}
+		messages = append(messages, p.String())
+	}
+	conditions.MarkFalse(target, tmcv1alpha1.SyncerAuthorized, tmcv1alpha1.MissingPermissionsReason, conditionsv1alpha1.ConditionSeverityError, "syncer may not %s", strings.Join(messages, "; "))
+}
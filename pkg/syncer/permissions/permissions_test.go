@@ -0,0 +1,112 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package permissions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func testRequirements() Requirements {
+	target := &tmcv1alpha1.SyncTarget{Spec: tmcv1alpha1.SyncTargetSpec{
+		ClusterScopedResources: []tmcv1alpha1.ClusterScopedResource{{Group: "rbac.authorization.k8s.io", Resource: "clusterroles"}},
+	}}
+	return ForSyncTarget(target, []schema.GroupVersionResource{
+		{Group: "apps", Version: "v1", Resource: "deployments"},
+		{Version: "v1", Resource: "configmaps"},
+		{Version: "v1", Resource: "services"},
+	}, Features{Capacity: true, LeaderElection: true})
+}
+
+func TestRules(t *testing.T) {
+	req := testRequirements()
+	require.Equal(t, []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"configmaps", "namespaces", "services"}, Verbs: syncVerbs},
+		{APIGroups: []string{""}, Resources: []string{"nodes"}, Verbs: readVerbs},
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: syncVerbs},
+		{APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"clusterroles"}, Verbs: syncVerbs},
+	}, req.ClusterRules())
+	require.Equal(t, []rbacv1.PolicyRule{
+		{APIGroups: []string{"coordination.k8s.io"}, Resources: []string{"leases"}, Verbs: []string{"get", "create", "update"}},
+	}, req.NamespaceRules())
+
+	require.Empty(t, Requirements{}.NamespaceRules())
+
+	req.Features.Observer = true
+	require.Equal(t, []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"configmaps", "namespaces", "nodes", "services"}, Verbs: readVerbs},
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: readVerbs},
+		{APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"clusterroles"}, Verbs: readVerbs},
+	}, req.ClusterRules(), "observers only read")
+	require.Empty(t, req.NamespaceRules(), "observers do not elect a leader")
+}
+
+func TestManifests(t *testing.T) {
+	objs := Manifests("kcp-syncer-edge", "kcp-syncer", "syncer", testRequirements())
+	require.Len(t, objs, 4)
+	binding := objs[1].(*rbacv1.ClusterRoleBinding)
+	require.Equal(t, "kcp-syncer-edge", binding.RoleRef.Name)
+	require.Equal(t, []rbacv1.Subject{{Kind: "ServiceAccount", Namespace: "kcp-syncer", Name: "syncer"}}, binding.Subjects)
+	role := objs[2].(*rbacv1.Role)
+	require.Equal(t, "kcp-syncer", role.Namespace)
+
+	require.Len(t, Manifests("kcp-syncer-edge", "kcp-syncer", "syncer", Requirements{}), 2)
+}
+
+func TestMissing(t *testing.T) {
+	var reviewed []authorizationv1.ResourceAttributes
+	review := func(ctx context.Context, attrs authorizationv1.ResourceAttributes) (bool, error) {
+		reviewed = append(reviewed, attrs)
+		// The service account may do everything but delete deployments and
+		// update leases.
+		switch {
+		case attrs.Resource == "deployments" && attrs.Verb == "delete":
+			return false, nil
+		case attrs.Resource == "leases" && attrs.Verb == "update":
+			return false, nil
+		}
+		return true, nil
+	}
+
+	missing, err := Missing(context.Background(), review, "kcp-syncer", testRequirements())
+	require.NoError(t, err)
+	require.Equal(t, []Permission{
+		{Verb: "delete", Resource: schema.GroupResource{Group: "apps", Resource: "deployments"}},
+		{Namespace: "kcp-syncer", Verb: "update", Resource: schema.GroupResource{Group: "coordination.k8s.io", Resource: "leases"}},
+	}, missing)
+	require.Len(t, reviewed, 5*7+3+3)
+
+	target := &tmcv1alpha1.SyncTarget{}
+	SetCondition(target, missing)
+	require.True(t, conditions.IsFalse(target, tmcv1alpha1.SyncerAuthorized))
+	require.Equal(t, tmcv1alpha1.MissingPermissionsReason, conditions.GetReason(target, tmcv1alpha1.SyncerAuthorized))
+	require.Equal(t, conditionsv1alpha1.ConditionSeverityError, *conditions.GetSeverity(target, tmcv1alpha1.SyncerAuthorized))
+	require.Equal(t, "syncer may not delete deployments.apps; update leases.coordination.k8s.io in namespace kcp-syncer", conditions.GetMessage(target, tmcv1alpha1.SyncerAuthorized))
+
+	SetCondition(target, nil)
+	require.True(t, conditions.IsTrue(target, tmcv1alpha1.SyncerAuthorized))
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/pkg/placement/closure"
+	"github.com/kcp-dev/kcp/pkg/syncer/multitarget"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// placementKey is the only key of the placement queue, coalescing the
+// changes of the WorkloadDistributions and of the placed objects.
+const placementKey = "placement"
+
+var distributionsGVR = workloadv1alpha1.SchemeGroupVersion.WithResource("workloaddistributions")
+
+// placement tracks the objects placed on the SyncTarget: the workloads of
+// the WorkloadDistributions synced to it, and the objects exported with
+// them, see closure.Placed. While a rollout is in progress, these are the
+// workloads of the waves rolled out to the SyncTarget so far. Only placed
+// objects are synced.
+type placement struct {
+	syncTarget string
+	informer   cache.SharedIndexInformer
+	get        closure.GetFunc
+	queue      workqueue.TypedRateLimitingInterface[string]
+	// changed is called with the objects placed or no longer placed.
+	changed func(refs []closure.Reference)
+
+	lock   sync.RWMutex
+	placed sets.Set[closure.Reference]
+	// workloads are the placed objects per placed workload.
+	workloads map[closure.Reference]sets.Set[closure.Reference]
+	synced    bool
+}
+
+func newPlacement(target multitarget.Target, upstream dynamic.Interface, mapper meta.RESTMapper, resync time.Duration) *placement {
+	p := &placement{
+		syncTarget: target.Name,
+		informer:   dynamicinformer.NewFilteredDynamicInformer(upstream, distributionsGVR, metav1.NamespaceAll, resync, cache.Indexers{}, nil).Informer(),
+		queue: workqueue.NewTypedRateLimitingQueueWithConfig(
+			workqueue.DefaultTypedControllerRateLimiter[string](),
+			workqueue.TypedRateLimitingQueueConfig[string]{Name: multitarget.QueueName(target, "placement")},
+		),
+		placed: sets.New[closure.Reference](),
+	}
+	p.get = func(ctx context.Context, ref closure.Reference) (*unstructured.Unstructured, error) {
+		mapping, err := mapper.RESTMapping(ref.GroupKind)
+		if meta.IsNoMatchError(err) {
+			// Kinds the physical cluster does not serve are not synced.
+			return nil, apierrors.NewNotFound(schema.GroupResource{Group: ref.Group, Resource: ref.Kind}, ref.Name)
+		} else if err != nil {
+			return nil, err
+		}
+		return upstream.Resource(mapping.Resource).Namespace(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	}
+	p.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{ //nolint:errcheck
+		AddFunc:    func(interface{}) { p.Resync() },
+		UpdateFunc: func(interface{}, interface{}) { p.Resync() },
+		DeleteFunc: func(interface{}) { p.Resync() },
+	})
+	return p
+}
+
+// Resync queues the placement to be resolved again, e.g. because a placed
+// object changed the objects exported with it.
+func (p *placement) Resync() {
+	p.queue.Add(placementKey)
+}
+
+// Placed returns whether the object is placed on the SyncTarget.
+func (p *placement) Placed(ref closure.Reference) bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.placed.Has(ref)
+}
+
+// Empty returns whether no objects are placed on the SyncTarget.
+func (p *placement) Empty() bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.placed.Len() == 0
+}
+
+// Workloads returns the objects placed on the SyncTarget per workload,
+// see closure.PlacedWorkloads. The result must not be changed.
+func (p *placement) Workloads() map[closure.Reference]sets.Set[closure.Reference] {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.workloads
+}
+
+// HasSynced returns whether the placement was resolved once. Until then,
+// nothing is known to be placed, and nothing may be deleted downstream.
+func (p *placement) HasSynced() bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.synced
+}
+
+// Run resolves the placement until ctx is done.
+func (p *placement) Run(ctx context.Context) {
+	defer utilruntime.HandleCrash()
+	defer p.queue.ShutDown()
+
+	go p.informer.Run(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), p.informer.HasSynced) {
+		return
+	}
+	p.Resync()
+	go wait.UntilWithContext(ctx, p.startWorker, time.Second)
+
+	<-ctx.Done()
+}
+
+func (p *placement) startWorker(ctx context.Context) {
+	for p.processNextWorkItem(ctx) {
+	}
+}
+
+func (p *placement) processNextWorkItem(ctx context.Context) bool {
+	key, quit := p.queue.Get()
+	if quit {
+		return false
+	}
+	defer p.queue.Done(key)
+
+	if err := p.process(ctx); err != nil {
+		utilruntime.HandleError(fmt.Errorf("failed to resolve the objects placed on SyncTarget %s: %w", p.syncTarget, err))
+		p.queue.AddRateLimited(key)
+		return true
+	}
+	p.queue.Forget(key)
+	return true
+}
+
+// process resolves the objects placed by the WorkloadDistributions, and
+// reports those whose placement changed.
+func (p *placement) process(ctx context.Context) error {
+	objs := p.informer.GetStore().List()
+	distributions := make([]*workloadv1alpha1.WorkloadDistribution, 0, len(objs))
+	for _, obj := range objs {
+		d := &workloadv1alpha1.WorkloadDistribution{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.(*unstructured.Unstructured).Object, d); err != nil {
+			utilruntime.HandleError(err)
+			continue
+		}
+		distributions = append(distributions, d)
+	}
+	workloads, err := closure.PlacedWorkloads(ctx, distributions, p.syncTarget, p.get)
+	if err != nil {
+		return err
+	}
+	placed := sets.New[closure.Reference]()
+	for _, objs := range workloads {
+		placed = placed.Union(objs)
+	}
+
+	p.lock.Lock()
+	changed := placed.SymmetricDifference(p.placed).UnsortedList()
+	p.placed, p.workloads, p.synced = placed, workloads, true
+	p.lock.Unlock()
+
+	klog.FromContext(ctx).V(4).Info("resolved placement", "objects", placed.Len(), "changed", len(changed))
+	if p.changed != nil && len(changed) > 0 {
+		p.changed(changed)
+	}
+	return nil
+}
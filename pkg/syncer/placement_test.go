@@ -0,0 +1,74 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kcp-dev/kcp/pkg/placement/closure"
+	"github.com/kcp-dev/kcp/pkg/syncer/controllermanager"
+	"github.com/kcp-dev/kcp/pkg/syncer/naming"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+func TestPlacement(t *testing.T) {
+	ctx := context.Background()
+	c := newTestController(t, configMapsGVR, controllermanager.ResourceConfig{})
+	_, err := c.upstream.Resource(configMapsGVR).Namespace("default").Create(ctx, newObject("v1", "ConfigMap", "default", "web"), metav1.CreateOptions{})
+	require.NoError(t, err)
+	var applied, deleted []string
+	c.applyDownstream = func(ctx context.Context, obj *unstructured.Unstructured) error {
+		applied = append(applied, key(obj.GetNamespace(), obj.GetName()))
+		return nil
+	}
+	c.deleteDownstream = func(ctx context.Context, namespace, name string) error {
+		deleted = append(deleted, key(namespace, name))
+		return nil
+	}
+	next := func() string {
+		require.Equal(t, 1, c.queue.Len())
+		item, _ := c.queue.Get()
+		c.queue.Done(item)
+		return item
+	}
+
+	web := newDistribution("default", "web", "ConfigMap", "west", "edge")
+	web.Spec.RolloutStrategy = &workloadv1alpha1.RolloutStrategy{Type: workloadv1alpha1.RolloutCanary}
+	web.Status.Rollout = &workloadv1alpha1.RolloutStatus{Phase: workloadv1alpha1.RolloutProgressing, SyncedTargets: []string{"west"}}
+	place(t, c.syncer, newDistribution("default", "app", "ConfigMap", "edge"), web)
+	require.False(t, c.placement.Placed(closure.Reference{GroupKind: c.kind, Namespace: "default", Name: "web"}), "the canary wave does not include the SyncTarget")
+	require.Equal(t, 0, c.queue.Len())
+
+	web.Status.Rollout.SyncedTargets = append(web.Status.Rollout.SyncedTargets, "edge")
+	place(t, c.syncer, newDistribution("default", "app", "ConfigMap", "edge"), web)
+	require.Equal(t, "default/web", next(), "newly placed objects are queued")
+	require.NoError(t, c.process(ctx, "default/web"))
+	require.Equal(t, []string{naming.Namespace("abc", "default") + "/web"}, applied, "objects missing from the informer are read")
+
+	downstream := newObject("v1", "ConfigMap", naming.Namespace("abc", "default"), "web")
+	require.NoError(t, c.downstreamInformer.GetIndexer().Add(downstream))
+	place(t, c.syncer, newDistribution("default", "app", "ConfigMap", "edge"))
+	require.Equal(t, "default/web", next(), "objects no longer placed are queued")
+	require.NoError(t, c.process(ctx, "default/web"))
+	require.Equal(t, []string{naming.Namespace("abc", "default") + "/web"}, deleted)
+}
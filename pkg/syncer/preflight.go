@@ -0,0 +1,153 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/controllermanager"
+	"github.com/kcp-dev/kcp/pkg/syncer/multitarget"
+	"github.com/kcp-dev/kcp/pkg/syncer/permissions"
+	"github.com/kcp-dev/kcp/pkg/syncer/preflight"
+	apisv1alpha2 "github.com/kcp-dev/kcp/sdk/apis/apis/v1alpha2"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+var apiBindingsGVR = apisv1alpha2.SchemeGroupVersion.WithResource("apibindings")
+
+// checkPreflight runs the pre-flight checks of the syncer of target, unless
+// they are skipped, and returns an error if the syncer must not start, see
+// preflight.Options.Gate. workspace is the config of kcp outside of any
+// workspace.
+func checkPreflight(ctx context.Context, options *Options, target multitarget.Target, workspace, downstream *rest.Config) error {
+	if options.Preflight.Skip {
+		return nil
+	}
+	checks, err := preflightChecks(options, target, workspace, downstream)
+	if err != nil {
+		return err
+	}
+	return options.Preflight.Gate(ctx, preflight.Run(ctx, options.Preflight, target.String(), checks))
+}
+
+// preflightChecks returns the pre-flight checks of the syncer of target.
+func preflightChecks(options *Options, target multitarget.Target, workspace, downstream *rest.Config) ([]preflight.Check, error) {
+	kcpDiscovery, err := discovery.NewDiscoveryClientForConfig(workspace)
+	if err != nil {
+		return nil, err
+	}
+	workspaceClient, err := kcpdynamic.NewForConfig(workspace)
+	if err != nil {
+		return nil, err
+	}
+	client := workspaceClient.Cluster(target.Path)
+	serverTime, err := preflight.ServerTime(workspace)
+	if err != nil {
+		return nil, err
+	}
+	kcpGates, err := preflight.KCPFeatureGates(workspace)
+	if err != nil {
+		return nil, err
+	}
+	downstreamKube, err := kubernetes.NewForConfig(downstream)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := options.initialResources()
+	if err != nil {
+		return nil, err
+	}
+
+	// The SyncTarget is read by the SyncTarget check, which the downstream
+	// RBAC check runs after, to require its cluster-scoped resources.
+	var syncTarget *tmcv1alpha1.SyncTarget
+	readSyncTarget := func(ctx context.Context) (*tmcv1alpha1.SyncTarget, error) {
+		var err error
+		syncTarget, err = getSyncTarget(ctx, client, target.Name)
+		return syncTarget, err
+	}
+	review := permissions.SelfReview(downstreamKube)
+
+	return []preflight.Check{
+		preflight.KCPConnectivity(kcpDiscovery),
+		preflight.APIBinding(func(ctx context.Context) ([]apisv1alpha2.APIBinding, error) {
+			list, err := client.Resource(apiBindingsGVR).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			bindings := make([]apisv1alpha2.APIBinding, len(list.Items))
+			for i := range list.Items {
+				if err := runtime.DefaultUnstructuredConverter.FromUnstructured(list.Items[i].Object, &bindings[i]); err != nil {
+					return nil, err
+				}
+			}
+			return bindings, nil
+		}),
+		preflight.SyncTarget(target.Name, readSyncTarget),
+		{
+			Name:      preflight.DownstreamRBACCheck,
+			Critical:  true,
+			DependsOn: []string{preflight.SyncTargetCheck},
+			Run: func(ctx context.Context) error {
+				req := permissions.ForSyncTarget(syncTarget, resources, options.permissionFeatures())
+				return preflight.DownstreamRBAC(target.Name, review, "", req).Run(ctx)
+			},
+		},
+		preflight.ClockSkew(serverTime, time.Now, options.Preflight.MaxClockSkew),
+		preflight.FeatureGates(target.Name, kcpGates),
+	}, nil
+}
+
+// initialResources returns the resources synced at start, before the
+// SyncConfigurations of the workspace are overlaid.
+func (o *Options) initialResources() ([]schema.GroupVersionResource, error) {
+	config := DefaultResources()
+	if o.ResourceConfig != "" {
+		data, err := os.ReadFile(o.ResourceConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read --resource-config: %w", err)
+		}
+		if config, err = controllermanager.ParseConfig(data); err != nil {
+			return nil, fmt.Errorf("failed to parse --resource-config: %w", err)
+		}
+	}
+	return slices.Collect(maps.Keys(config)), nil
+}
+
+// permissionFeatures returns the features of the syncer that need
+// permissions on the physical cluster.
+func (o *Options) permissionFeatures() permissions.Features {
+	return permissions.Features{
+		Capacity:        o.CapacityInterval > 0,
+		PriorityClasses: true,
+		Observer:        o.Observer.Observer(),
+	}
+}
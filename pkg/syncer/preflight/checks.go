@@ -0,0 +1,263 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+
+	"github.com/kcp-dev/kcp/pkg/featurez"
+	"github.com/kcp-dev/kcp/pkg/syncer/permissions"
+	apisv1alpha2 "github.com/kcp-dev/kcp/sdk/apis/apis/v1alpha2"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// Names of the checks.
+const (
+	KCPConnectivityCheck = "kcp-connectivity"
+	APIBindingCheck      = "tmc-apibinding"
+	SyncTargetCheck      = "synctarget"
+	DownstreamRBACCheck  = "downstream-rbac"
+	ClockSkewCheck       = "clock-skew"
+	FeatureGatesCheck    = "feature-gates"
+)
+
+// credentialsRemediation fixes rejected kcp credentials.
+const credentialsRemediation = "the kcp credentials of the syncer are invalid or expired, regenerate the kubeconfig of the syncer from its service account in the workspace of the SyncTarget"
+
+// KCPConnectivity checks that kcp can be reached with client.
+func KCPConnectivity(client discovery.ServerVersionInterface) Check {
+	return Check{
+		Name:     KCPConnectivityCheck,
+		Critical: true,
+		Run: func(context.Context) error {
+			if _, err := client.ServerVersion(); err != nil {
+				if apierrors.IsUnauthorized(err) {
+					return Remediate(err, credentialsRemediation)
+				}
+				return Remediate(err, "check that the server of the kcp kubeconfig of the syncer is reachable from the physical cluster, through proxies and firewalls, and that its CA is trusted")
+			}
+			return nil
+		},
+	}
+}
+
+// APIBinding checks that the workspace of the SyncTarget binds the TMC APIs,
+// with the APIBindings returned by list.
+func APIBinding(list func(ctx context.Context) ([]apisv1alpha2.APIBinding, error)) Check {
+	return Check{
+		Name:      APIBindingCheck,
+		Critical:  true,
+		DependsOn: []string{KCPConnectivityCheck},
+		Run: func(ctx context.Context) error {
+			bindings, err := list(ctx)
+			if err != nil {
+				if apierrors.IsForbidden(err) {
+					return Remediate(err, "allow the syncer to list apibindings.apis.kcp.io in the workspace of the SyncTarget")
+				}
+				return err
+			}
+			var unbound []string
+			for _, binding := range bindings {
+				for _, resource := range binding.Status.BoundResources {
+					if resource.Group != tmcv1alpha1.SchemeGroupVersion.Group || resource.Resource != "synctargets" {
+						continue
+					}
+					if binding.Status.Phase == apisv1alpha2.APIBindingPhaseBound {
+						return nil
+					}
+					unbound = append(unbound, binding.Name)
+				}
+			}
+			if len(unbound) > 0 {
+				return Remediate(fmt.Errorf("APIBinding %s of the TMC APIs is not bound", strings.Join(unbound, ", ")), "check the conditions of the APIBinding, e.g. whether the TMC controllers are ready")
+			}
+			return Remediate(errors.New("no APIBinding binds the TMC APIs"), "bind the APIExport of the TMC APIs in the workspace of the SyncTarget, e.g. with kubectl kcp bind apiexport")
+		},
+	}
+}
+
+// SyncTarget checks that the SyncTarget name exists and that the syncer may
+// read it with its kcp credentials, with get.
+func SyncTarget(name string, get func(ctx context.Context) (*tmcv1alpha1.SyncTarget, error)) Check {
+	return Check{
+		Name:      SyncTargetCheck,
+		Critical:  true,
+		DependsOn: []string{APIBindingCheck},
+		Run: func(ctx context.Context) error {
+			syncTarget, err := get(ctx)
+			switch {
+			case apierrors.IsNotFound(err):
+				return Remediate(fmt.Errorf("SyncTarget %q does not exist", name), "create the SyncTarget %q in the workspace of the kcp kubeconfig of the syncer, or start the syncer with the name of an existing one", name)
+			case apierrors.IsUnauthorized(err):
+				return Remediate(err, credentialsRemediation)
+			case apierrors.IsForbidden(err):
+				return Remediate(err, "allow the service account of the syncer to get and update SyncTarget %q, e.g. by regenerating the syncer manifests", name)
+			case err != nil:
+				return err
+			case !syncTarget.DeletionTimestamp.IsZero():
+				return Remediate(fmt.Errorf("SyncTarget %q is being deleted", name), "wait for the teardown of the SyncTarget and create it again, or stop the syncer")
+			}
+			return nil
+		},
+	}
+}
+
+// DownstreamRBAC checks that the syncer has the permissions of req on the
+// physical cluster, with the namespace rules checked in namespace.
+func DownstreamRBAC(syncTarget string, review permissions.ReviewFunc, namespace string, req permissions.Requirements) Check {
+	return Check{
+		Name:     DownstreamRBACCheck,
+		Critical: true,
+		Run: func(ctx context.Context) error {
+			missing, err := permissions.Missing(ctx, review, namespace, req)
+			if err != nil {
+				return Remediate(err, "check that the physical cluster is reachable with the downstream kubeconfig of the syncer")
+			}
+			if len(missing) == 0 {
+				return nil
+			}
+			listed := make([]string, 0, len(missing))
+			for _, p := range missing {
+				listed = append(listed, p.String())
+			}
+			return Remediate(fmt.Errorf("syncer may not %s", strings.Join(listed, "; ")), "apply the output of kubectl tmc syncer-rbac %s to the physical cluster", syncTarget)
+		},
+	}
+}
+
+// ClockSkew checks that the clock of the syncer is at most maxSkew off the
+// clock of kcp, returned by serverTime.
+func ClockSkew(serverTime func(ctx context.Context) (time.Time, error), now func() time.Time, maxSkew time.Duration) Check {
+	return Check{
+		Name:      ClockSkewCheck,
+		Critical:  true,
+		DependsOn: []string{KCPConnectivityCheck},
+		Run: func(ctx context.Context) error {
+			before := now()
+			server, err := serverTime(ctx)
+			if err != nil {
+				return err
+			}
+			// The server time is taken somewhere during the request.
+			local := before.Add(now().Sub(before) / 2)
+			skew := local.Sub(server)
+			if skew < 0 {
+				skew = -skew
+			}
+			// The Date header has a resolution of a second.
+			if skew > maxSkew+time.Second {
+				return Remediate(fmt.Errorf("the clock of the syncer is %s off the clock of kcp, more than %s", skew.Round(time.Second), maxSkew), "synchronize the clocks of the nodes of the physical cluster and of kcp with NTP")
+			}
+			return nil
+		},
+	}
+}
+
+// FeatureGates checks that the TMC feature gates kcp reports, with gates,
+// agree with those of the syncer. It is not critical: the TMC controllers
+// report mismatches later, too.
+func FeatureGates(syncTarget string, gates func(ctx context.Context) ([]tmcv1alpha1.FeatureGate, error)) Check {
+	return Check{
+		Name:      FeatureGatesCheck,
+		DependsOn: []string{KCPConnectivityCheck},
+		Run: func(ctx context.Context) error {
+			kcpGates, err := gates(ctx)
+			if err != nil {
+				return err
+			}
+			mismatches := featurez.Mismatches("kcp", kcpGates, []tmcv1alpha1.SyncTargetFeatureGates{{Name: syncTarget, FeatureGates: featurez.Process()}})
+			if len(mismatches) == 0 {
+				return nil
+			}
+			messages := make([]string, 0, len(mismatches))
+			for _, mismatch := range mismatches {
+				messages = append(messages, mismatch.Message)
+			}
+			return Remediate(errors.New(strings.Join(messages, "; ")), "start the syncer with the --feature-gates of kcp")
+		},
+	}
+}
+
+// ServerTime returns a function returning the time of the server of config,
+// from the Date header of its response to a version request.
+func ServerTime(config *rest.Config) (func(ctx context.Context) (time.Time, error), error) {
+	client, err := rest.HTTPClientFor(config)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) (time.Time, error) {
+		resp, err := get(ctx, client, config, "/version")
+		if err != nil {
+			return time.Time{}, err
+		}
+		resp.Body.Close()
+		date, err := http.ParseTime(resp.Header.Get("Date"))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("kcp did not return its time: %w", err)
+		}
+		return date, nil
+	}, nil
+}
+
+// KCPFeatureGates returns a function returning the TMC feature gates kcp
+// serves at featurez.Path.
+func KCPFeatureGates(config *rest.Config) (func(ctx context.Context) ([]tmcv1alpha1.FeatureGate, error), error) {
+	client, err := rest.HTTPClientFor(config)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) ([]tmcv1alpha1.FeatureGate, error) {
+		resp, err := get(ctx, client, config, featurez.Path)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("kcp returned %s for %s", resp.Status, featurez.Path)
+		}
+		var report featurez.Report
+		if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+			return nil, fmt.Errorf("failed to decode the feature gates of kcp: %w", err)
+		}
+		return report.FeatureGates, nil
+	}, nil
+}
+
+// get requests path from the server of config, outside of any workspace.
+func get(ctx context.Context, client *http.Client, config *rest.Config, path string) (*http.Response, error) {
+	u, err := url.Parse(config.Host)
+	if err != nil {
+		return nil, err
+	}
+	u.Path, u.RawQuery = path, ""
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return client.Do(req)
+}
@@ -0,0 +1,264 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package preflight validates the environment of the syncer before it enters
+// its sync loops: the connection to kcp and the TMC APIs bound in the
+// workspace, the SyncTarget and the credentials of the syncer, its
+// permissions on the physical cluster, clock skew and feature gate
+// consistency. Every failed check tells how to fix it, and the results are
+// collected in a report. The syncer refuses to start while critical checks
+// fail, unless it is allowed to start degraded.
+package preflight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	defaultTimeout      = 30 * time.Second
+	defaultMaxClockSkew = 30 * time.Second
+)
+
+// Options configure the pre-flight checks.
+type Options struct {
+	// Skip starts the syncer without checks.
+	Skip bool
+	// AllowDegraded starts the syncer even though critical checks fail.
+	AllowDegraded bool
+	// Timeout bounds every check.
+	Timeout time.Duration
+	// MaxClockSkew is the largest tolerated difference between the clocks
+	// of the syncer and kcp.
+	MaxClockSkew time.Duration
+	// ReportFile is where the report is written as JSON, if set.
+	ReportFile string
+}
+
+// NewOptions returns options running all checks and refusing to start on
+// failures.
+func NewOptions() *Options {
+	return &Options{
+		Timeout:      defaultTimeout,
+		MaxClockSkew: defaultMaxClockSkew,
+	}
+}
+
+// AddFlags adds the pre-flight flags to the flag set.
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&o.Skip, "preflight-skip", o.Skip, "Start without running the pre-flight checks.")
+	fs.BoolVar(&o.AllowDegraded, "preflight-allow-degraded", o.AllowDegraded, "Start even though critical pre-flight checks fail. The failures are still reported.")
+	fs.DurationVar(&o.Timeout, "preflight-timeout", o.Timeout, "Timeout of every pre-flight check.")
+	fs.DurationVar(&o.MaxClockSkew, "preflight-max-clock-skew", o.MaxClockSkew, "Largest tolerated difference between the clocks of the syncer and kcp.")
+	fs.StringVar(&o.ReportFile, "preflight-report-file", o.ReportFile, "File the pre-flight report is written to as JSON.")
+}
+
+// Validate validates the options.
+func (o *Options) Validate() error {
+	if o.Timeout <= 0 {
+		return fmt.Errorf("--preflight-timeout must be positive")
+	}
+	if o.MaxClockSkew <= 0 {
+		return fmt.Errorf("--preflight-max-clock-skew must be positive")
+	}
+	return nil
+}
+
+// Check is a pre-flight check.
+type Check struct {
+	// Name identifies the check in the report, e.g. kcp-connectivity.
+	Name string
+	// Critical checks keep the syncer from starting when they fail. Other
+	// failures are reported as warnings.
+	Critical bool
+	// DependsOn are the checks that must pass for this one to run. It is
+	// skipped otherwise.
+	DependsOn []string
+	// Run returns why the check fails, ideally an *Error telling how to
+	// fix it.
+	Run func(ctx context.Context) error
+}
+
+// Error is a failed check with its remediation.
+type Error struct {
+	Err         error
+	Remediation string
+}
+
+// Remediate returns err with a remediation.
+func Remediate(err error, format string, args ...interface{}) error {
+	return &Error{Err: err, Remediation: fmt.Sprintf(format, args...)}
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+func (e *Error) Unwrap() error { return e.Err }
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	// StatusPassed means the check passed.
+	StatusPassed Status = "Passed"
+	// StatusFailed means a critical check failed.
+	StatusFailed Status = "Failed"
+	// StatusWarning means a check that is not critical failed.
+	StatusWarning Status = "Warning"
+	// StatusSkipped means a check the check depends on did not pass.
+	StatusSkipped Status = "Skipped"
+)
+
+// Result is the outcome of a check.
+type Result struct {
+	Check       string `json:"check"`
+	Status      Status `json:"status"`
+	Critical    bool   `json:"critical,omitempty"`
+	Message     string `json:"message,omitempty"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// Report is the outcome of all checks.
+type Report struct {
+	Time       time.Time `json:"time"`
+	SyncTarget string    `json:"syncTarget"`
+	Results    []Result  `json:"results"`
+}
+
+// Ready returns whether no critical check failed or was skipped.
+func (r *Report) Ready() bool {
+	return len(r.Blocking()) == 0
+}
+
+// Blocking returns the results of the critical checks that did not pass.
+func (r *Report) Blocking() []Result {
+	var blocking []Result
+	for _, result := range r.Results {
+		if result.Critical && result.Status != StatusPassed {
+			blocking = append(blocking, result)
+		}
+	}
+	return blocking
+}
+
+// String returns the report as text, one line per check followed by the
+// remediation of failed checks.
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "pre-flight report of SyncTarget %s:\n", r.SyncTarget)
+	for _, result := range r.Results {
+		fmt.Fprintf(&b, "  %-7s %s", result.Status, result.Check)
+		if result.Message != "" {
+			fmt.Fprintf(&b, ": %s", result.Message)
+		}
+		b.WriteString("\n")
+		if result.Remediation != "" {
+			fmt.Fprintf(&b, "          fix: %s\n", result.Remediation)
+		}
+	}
+	return b.String()
+}
+
+// Run runs checks in order with the timeout of options and returns their
+// report.
+func Run(ctx context.Context, options *Options, syncTarget string, checks []Check) *Report {
+	logger := klog.FromContext(ctx)
+
+	report := &Report{Time: time.Now(), SyncTarget: syncTarget}
+	passed := map[string]bool{}
+	for _, check := range checks {
+		result := Result{Check: check.Name, Critical: check.Critical}
+		var missing []string
+		for _, dependency := range check.DependsOn {
+			if !passed[dependency] {
+				missing = append(missing, dependency)
+			}
+		}
+		if len(missing) > 0 {
+			result.Status = StatusSkipped
+			result.Message = fmt.Sprintf("%s did not pass", strings.Join(missing, ", "))
+			report.Results = append(report.Results, result)
+			continue
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, options.Timeout)
+		err := check.Run(checkCtx)
+		cancel()
+		switch {
+		case err == nil:
+			result.Status = StatusPassed
+			passed[check.Name] = true
+		case check.Critical:
+			result.Status = StatusFailed
+		default:
+			result.Status = StatusWarning
+		}
+		if err != nil {
+			result.Message = err.Error()
+			var remediable *Error
+			if errors.As(err, &remediable) {
+				result.Remediation = remediable.Remediation
+			}
+		}
+		logger.V(2).Info("pre-flight check done", "check", result.Check, "status", result.Status, "message", result.Message)
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+// Gate returns an error listing the blocking failures of report with their
+// remediation, unless the report is ready or the options allow starting
+// degraded. It writes the report to the report file, if set.
+func (o *Options) Gate(ctx context.Context, report *Report) error {
+	logger := klog.FromContext(ctx)
+
+	if o.ReportFile != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(o.ReportFile, data, 0o644); err != nil {
+			return fmt.Errorf("failed to write the pre-flight report: %w", err)
+		}
+	}
+
+	blocking := report.Blocking()
+	if len(blocking) == 0 {
+		logger.Info("pre-flight checks passed", "syncTarget", report.SyncTarget)
+		return nil
+	}
+	if o.AllowDegraded {
+		logger.Info("starting degraded despite failed pre-flight checks\n" + report.String())
+		return nil
+	}
+
+	messages := make([]string, 0, len(blocking))
+	for _, result := range blocking {
+		message := fmt.Sprintf("%s: %s", result.Check, result.Message)
+		if result.Remediation != "" {
+			message += " (fix: " + result.Remediation + ")"
+		}
+		messages = append(messages, message)
+	}
+	return fmt.Errorf("pre-flight checks of SyncTarget %s failed, start with --preflight-allow-degraded to start anyway: %s", report.SyncTarget, strings.Join(messages, "; "))
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+
+	"github.com/kcp-dev/kcp/pkg/featurez"
+	apisv1alpha2 "github.com/kcp-dev/kcp/sdk/apis/apis/v1alpha2"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func passing(name string, dependsOn ...string) Check {
+	return Check{Name: name, Critical: true, DependsOn: dependsOn, Run: func(context.Context) error { return nil }}
+}
+
+func failing(name string, critical bool, err error) Check {
+	return Check{Name: name, Critical: critical, Run: func(context.Context) error { return err }}
+}
+
+func TestRun(t *testing.T) {
+	report := Run(context.Background(), NewOptions(), "edge-1", []Check{
+		passing("a"),
+		failing("b", true, Remediate(errors.New("b is broken"), "fix b")),
+		passing("c", "a", "b"),
+		failing("d", false, errors.New("d is odd")),
+	})
+
+	require.Equal(t, []Result{
+		{Check: "a", Status: StatusPassed, Critical: true},
+		{Check: "b", Status: StatusFailed, Critical: true, Message: "b is broken", Remediation: "fix b"},
+		{Check: "c", Status: StatusSkipped, Critical: true, Message: "b did not pass"},
+		{Check: "d", Status: StatusWarning, Message: "d is odd"},
+	}, report.Results)
+	require.False(t, report.Ready())
+	require.Len(t, report.Blocking(), 2)
+	require.Contains(t, report.String(), "fix: fix b")
+}
+
+func TestGate(t *testing.T) {
+	report := Run(context.Background(), NewOptions(), "edge-1", []Check{
+		failing("b", true, Remediate(errors.New("b is broken"), "fix b")),
+		failing("d", false, errors.New("d is odd")),
+	})
+
+	options := NewOptions()
+	options.ReportFile = filepath.Join(t.TempDir(), "report.json")
+	err := options.Gate(context.Background(), report)
+	require.EqualError(t, err, "pre-flight checks of SyncTarget edge-1 failed, start with --preflight-allow-degraded to start anyway: b: b is broken (fix: fix b)")
+	data, err := os.ReadFile(options.ReportFile)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"remediation": "fix b"`)
+
+	options.AllowDegraded = true
+	require.NoError(t, options.Gate(context.Background(), report))
+}
+
+func TestAPIBinding(t *testing.T) {
+	binding := apisv1alpha2.APIBinding{}
+	binding.Name = "tmc"
+	binding.Status.BoundResources = []apisv1alpha2.BoundAPIResource{{Group: "tmc.kcp.io", Resource: "synctargets"}}
+	list := func(context.Context) ([]apisv1alpha2.APIBinding, error) {
+		return []apisv1alpha2.APIBinding{binding}, nil
+	}
+
+	err := APIBinding(list).Run(context.Background())
+	require.EqualError(t, err, "APIBinding tmc of the TMC APIs is not bound")
+
+	binding.Status.Phase = apisv1alpha2.APIBindingPhaseBound
+	require.NoError(t, APIBinding(list).Run(context.Background()))
+
+	err = APIBinding(func(context.Context) ([]apisv1alpha2.APIBinding, error) { return nil, nil }).Run(context.Background())
+	var remediable *Error
+	require.ErrorAs(t, err, &remediable)
+	require.Contains(t, remediable.Remediation, "kubectl kcp bind apiexport")
+}
+
+func TestSyncTarget(t *testing.T) {
+	notFound := func(context.Context) (*tmcv1alpha1.SyncTarget, error) {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Group: "tmc.kcp.io", Resource: "synctargets"}, "edge-1")
+	}
+	err := SyncTarget("edge-1", notFound).Run(context.Background())
+	require.EqualError(t, err, `SyncTarget "edge-1" does not exist`)
+
+	unauthorized := func(context.Context) (*tmcv1alpha1.SyncTarget, error) {
+		return nil, apierrors.NewUnauthorized("token expired")
+	}
+	var remediable *Error
+	require.ErrorAs(t, SyncTarget("edge-1", unauthorized).Run(context.Background()), &remediable)
+	require.Equal(t, credentialsRemediation, remediable.Remediation)
+}
+
+func TestClockSkew(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	serverTime := func(skew time.Duration) func(context.Context) (time.Time, error) {
+		return func(context.Context) (time.Time, error) { return now.Add(skew), nil }
+	}
+
+	require.NoError(t, ClockSkew(serverTime(-20*time.Second), clock, 30*time.Second).Run(context.Background()))
+	err := ClockSkew(serverTime(2*time.Minute), clock, 30*time.Second).Run(context.Background())
+	require.EqualError(t, err, "the clock of the syncer is 2m0s off the clock of kcp, more than 30s")
+}
+
+func TestFeatureGates(t *testing.T) {
+	same := func(context.Context) ([]tmcv1alpha1.FeatureGate, error) { return featurez.Process(), nil }
+	require.NoError(t, FeatureGates("edge-1", same).Run(context.Background()))
+
+	flipped := func(context.Context) ([]tmcv1alpha1.FeatureGate, error) {
+		gates := featurez.Process()
+		gates[0].Enabled = !gates[0].Enabled
+		return gates, nil
+	}
+	err := FeatureGates("edge-1", flipped).Run(context.Background())
+	require.ErrorContains(t, err, featurez.Process()[0].Name+" is")
+	require.ErrorContains(t, err, "in kcp")
+}
+
+func TestServerTime(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Date", "Wed, 01 Jan 2025 00:00:00 GMT")
+	}))
+	defer server.Close()
+
+	serverTime, err := ServerTime(&rest.Config{Host: server.URL + "/clusters/root:edge"})
+	require.NoError(t, err)
+	date, err := serverTime(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), date.UTC())
+	require.Equal(t, "/version", path, "the workspace path is dropped")
+}
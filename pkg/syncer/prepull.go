@@ -0,0 +1,335 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/kcp-dev/kcp/pkg/placement/closure"
+	"github.com/kcp-dev/kcp/pkg/statusaggregation"
+	"github.com/kcp-dev/kcp/pkg/syncer/naming"
+	"github.com/kcp-dev/kcp/pkg/syncer/prepull"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// prePullFieldManager applies the annotation with the progress of pulling
+// the images of a workload on the SyncTarget to its WorkloadDistribution.
+const prePullFieldManager = "kcp-syncer-prepull"
+
+// prePulls pulls the images of the workloads placed on the SyncTarget whose
+// WorkloadDistributions have spec.prePull onto the nodes of the physical
+// cluster, see package prepull. The progress on the SyncTarget is reported
+// in the annotation of the SyncTarget on the WorkloadDistribution, see
+// statusaggregation.TargetAnnotation, and the ImagesPrePulled condition of
+// the WorkloadDistribution is true once it is true on all its SyncTargets.
+type prePulls struct {
+	*syncer
+	puller *prepull.Puller
+	now    func() time.Time
+
+	lock sync.Mutex
+	// pulls are the images pulled last by workload. Their pre-pull
+	// DaemonSets are deleted when the workloads no longer pre-pull.
+	pulls map[string]*pull
+}
+
+// pull is the pulling of the images of a workload.
+type pull struct {
+	namespace string
+	// name is the name of the pre-pull DaemonSet of the images.
+	name string
+	// started is when the images were first pulled, as the timeout of
+	// pulling starts then.
+	started time.Time
+	// done is whether the images are pulled. The DaemonSet is deleted
+	// then, and not created again.
+	done bool
+}
+
+func newPrePulls(s *syncer, puller *prepull.Puller) *prePulls {
+	return &prePulls{
+		syncer: s,
+		puller: puller,
+		now:    time.Now,
+		pulls:  map[string]*pull{},
+	}
+}
+
+// Run pulls the images every interval until ctx is done.
+func (p *prePulls) Run(ctx context.Context, interval time.Duration) {
+	defer utilruntime.HandleCrash()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if p.placement.HasSynced() && !p.pause.Paused() {
+			if err := p.sync(ctx); err != nil {
+				utilruntime.HandleError(fmt.Errorf("failed to pre-pull the images of the workloads of SyncTarget %s: %w", p.target, err))
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sync pulls the images of the workloads of the WorkloadDistributions with
+// spec.prePull placed on the SyncTarget, and reports the progress.
+func (p *prePulls) sync(ctx context.Context) error {
+	var errs []error
+	pulling := map[string]bool{}
+	for _, obj := range p.placement.informer.GetStore().List() {
+		u := obj.(*unstructured.Unstructured)
+		d := &workloadv1alpha1.WorkloadDistribution{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, d); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if d.Spec.PrePull == nil || !closure.PlacedOn(d, p.target.Name) {
+			continue
+		}
+		ref, err := closure.WorkloadReference(d)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		spec, found, err := p.podSpec(ref)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !found {
+			// Not synced, or not running pods.
+			continue
+		}
+		workload := workloadName(ref)
+		pulling[workload] = true
+		condition, err := p.pull(ctx, d, workload, spec)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := p.report(ctx, u, d, condition); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	p.lock.Lock()
+	stale := map[string]string{}
+	for workload, pull := range p.pulls {
+		if !pulling[workload] {
+			stale[workload] = pull.namespace
+		}
+	}
+	p.lock.Unlock()
+	for workload, namespace := range stale {
+		if err := p.puller.Cleanup(ctx, namespace, workload); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		p.lock.Lock()
+		delete(p.pulls, workload)
+		p.lock.Unlock()
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// podSpec returns the pod spec of an upstream workload, and false if it is
+// not synced or does not run pods.
+func (p *prePulls) podSpec(ref closure.Reference) (*corev1.PodSpec, bool, error) {
+	path, found := podSpecPaths[ref.GroupKind]
+	if !found {
+		return nil, false, nil
+	}
+	value, found := p.controllers.Load(ref.GroupKind)
+	if !found {
+		return nil, false, nil
+	}
+	obj, exists, err := value.(*controller).upstreamInformer.GetIndexer().GetByKey(cache.NewObjectName(ref.Namespace, ref.Name).String())
+	if err != nil || !exists {
+		return nil, false, err
+	}
+	m, found, err := unstructured.NestedMap(obj.(*unstructured.Unstructured).Object, path...)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	spec := &corev1.PodSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(m, spec); err != nil {
+		return nil, false, err
+	}
+	return spec, true, nil
+}
+
+// pull pulls the images of the pod spec of a workload into its downstream
+// namespace, and returns the ImagesPrePulled condition of the SyncTarget.
+func (p *prePulls) pull(ctx context.Context, d *workloadv1alpha1.WorkloadDistribution, workload string, spec *corev1.PodSpec) (*conditionsv1alpha1.Condition, error) {
+	namespace := naming.Namespace(p.clusterName, d.Namespace)
+	name := prepull.Name(workload, prepull.Images(spec))
+	timeout := prepull.Timeout(d)
+
+	p.lock.Lock()
+	current, found := p.pulls[workload]
+	if !found || current.name != name || current.namespace != namespace {
+		current = &pull{namespace: namespace, name: name, started: p.now()}
+		p.pulls[workload] = current
+	}
+	done, started := current.done, current.started
+	p.lock.Unlock()
+
+	progress := prepull.Progress{}
+	var err error
+	if !done {
+		if progress, err = p.puller.Pull(ctx, namespace, workload, spec); err != nil {
+			return nil, err
+		}
+		if progress.Complete() {
+			// Pulled images stay on the nodes.
+			if err := p.puller.Cleanup(ctx, namespace, workload); err != nil {
+				return nil, err
+			}
+			p.lock.Lock()
+			current.done = true
+			p.lock.Unlock()
+			done = true
+		} else if p.now().Sub(started) > timeout {
+			err = fmt.Errorf("%w: pulled onto %d of %d nodes within %s", prepull.ErrTimeout, progress.Pulled, progress.Nodes, timeout)
+		}
+	}
+
+	scratch := &workloadv1alpha1.WorkloadDistribution{}
+	if done {
+		conditions.MarkTrue(scratch, workloadv1alpha1.ImagesPrePulled)
+	} else {
+		prepull.SetCondition(scratch, progress, err)
+	}
+	return conditions.Get(scratch, workloadv1alpha1.ImagesPrePulled), nil
+}
+
+// report applies the ImagesPrePulled condition of the SyncTarget in its
+// annotation on the WorkloadDistribution, and sets the ImagesPrePulled
+// condition of the WorkloadDistribution from those of all its SyncTargets.
+// The syncers of its SyncTargets set the same condition, as they see the
+// same annotations.
+func (p *prePulls) report(ctx context.Context, u *unstructured.Unstructured, d *workloadv1alpha1.WorkloadDistribution, condition *conditionsv1alpha1.Condition) error {
+	client := p.upstream.Resource(distributionsGVR).Namespace(d.Namespace)
+	annotation := statusaggregation.TargetAnnotation(p.target.Name)
+	statuses, err := statusaggregation.TargetStatuses(u, d.SyncedTargets())
+	if err != nil {
+		return err
+	}
+	if reported := targetCondition(statuses[p.target.Name]); reported == nil || !sameCondition(reported, condition) {
+		data, err := json.Marshal(map[string]interface{}{"conditions": []*conditionsv1alpha1.Condition{condition}})
+		if err != nil {
+			return err
+		}
+		applied := &unstructured.Unstructured{}
+		applied.SetAPIVersion(u.GetAPIVersion())
+		applied.SetKind(u.GetKind())
+		applied.SetNamespace(d.Namespace)
+		applied.SetName(d.Name)
+		applied.SetAnnotations(map[string]string{annotation: string(data)})
+		if _, err := client.Apply(ctx, d.Name, applied, metav1.ApplyOptions{FieldManager: prePullFieldManager + "-" + p.target.Name, Force: true}); err != nil {
+			return fmt.Errorf("failed to report the pre-pulled images of WorkloadDistribution %s/%s: %w", d.Namespace, d.Name, err)
+		}
+		// The condition is set once the annotation is seen.
+		return nil
+	}
+
+	updated := d.DeepCopy()
+	setPrePullCondition(updated, statuses)
+	if current := conditions.Get(d, workloadv1alpha1.ImagesPrePulled); current != nil && sameCondition(current, conditions.Get(updated, workloadv1alpha1.ImagesPrePulled)) {
+		return nil
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(updated)
+	if err != nil {
+		return err
+	}
+	if _, err := client.UpdateStatus(ctx, &unstructured.Unstructured{Object: obj}, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to set the ImagesPrePulled condition of WorkloadDistribution %s/%s: %w", d.Namespace, d.Name, err)
+	}
+	return nil
+}
+
+// setPrePullCondition sets the ImagesPrePulled condition of the
+// distribution from the conditions reported by its SyncTargets: failed if
+// pulling failed on any of them, true if the images are pulled on all of
+// them, and in progress otherwise.
+func setPrePullCondition(d *workloadv1alpha1.WorkloadDistribution, statuses map[string]map[string]interface{}) {
+	var failed, pending []string
+	for _, syncTarget := range d.SyncedTargets() {
+		condition := targetCondition(statuses[syncTarget])
+		switch {
+		case condition == nil:
+			pending = append(pending, syncTarget+": not reported")
+		case condition.Reason == workloadv1alpha1.PrePullFailedReason:
+			failed = append(failed, syncTarget+": "+condition.Message)
+		case condition.Status != corev1.ConditionTrue:
+			pending = append(pending, syncTarget+": "+condition.Message)
+		}
+	}
+	sort.Strings(failed)
+	sort.Strings(pending)
+	switch {
+	case len(failed) > 0:
+		conditions.MarkFalse(d, workloadv1alpha1.ImagesPrePulled, workloadv1alpha1.PrePullFailedReason, conditionsv1alpha1.ConditionSeverityError, "%s", strings.Join(failed, "; "))
+	case len(pending) > 0:
+		conditions.MarkFalse(d, workloadv1alpha1.ImagesPrePulled, workloadv1alpha1.PrePullInProgressReason, conditionsv1alpha1.ConditionSeverityInfo, "%s", strings.Join(pending, "; "))
+	default:
+		conditions.MarkTrue(d, workloadv1alpha1.ImagesPrePulled)
+	}
+}
+
+// targetCondition returns the ImagesPrePulled condition in the status a
+// SyncTarget reported, or nil.
+func targetCondition(status map[string]interface{}) *conditionsv1alpha1.Condition {
+	reported := &workloadv1alpha1.WorkloadDistributionStatus{}
+	if status == nil || runtime.DefaultUnstructuredConverter.FromUnstructured(status, reported) != nil {
+		return nil
+	}
+	i := slices.IndexFunc(reported.Conditions, func(c conditionsv1alpha1.Condition) bool {
+		return c.Type == workloadv1alpha1.ImagesPrePulled
+	})
+	if i < 0 {
+		return nil
+	}
+	return &reported.Conditions[i]
+}
+
+// sameCondition returns whether the conditions only differ in their
+// transition time.
+func sameCondition(a, b *conditionsv1alpha1.Condition) bool {
+	return a.Status == b.Status && a.Reason == b.Reason && a.Severity == b.Severity && a.Message == b.Message
+}
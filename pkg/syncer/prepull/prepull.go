@@ -0,0 +1,348 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package prepull pulls the images of workloads onto the nodes of a physical
+// cluster before they are needed, so that large images do not delay the
+// start of pods when canaries are placed. A DaemonSet with a container per
+// image runs on the nodes the workload may be scheduled to. Its containers
+// run a command that may not exist in the image: only whether the image is
+// present on the node is observed, not whether the container starts. The
+// DaemonSet is deleted once the images are pulled.
+package prepull
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+const (
+	// LabelWorkload is set on pre-pull DaemonSets to a hash of the
+	// workload they pull the images of.
+	LabelWorkload = "workload.kcp.io/prepull-workload"
+	// LabelPrePull is set on pre-pull DaemonSets and their pods to the name
+	// of the DaemonSet.
+	LabelPrePull = "workload.kcp.io/prepull"
+
+	// DefaultTimeout is how long images are pulled if spec.prePull.timeout
+	// is not set.
+	DefaultTimeout = 10 * time.Minute
+)
+
+// ErrTimeout is returned if the images were not pulled in time.
+var ErrTimeout = errors.New("images not pre-pulled in time")
+
+// pullCommand is the command of the pre-pull containers. Images without it
+// fail to start, which does not matter as the image is pulled by then.
+var pullCommand = []string{"true"}
+
+// pullFailures are the waiting reasons of containers whose image cannot be
+// pulled.
+var pullFailures = map[string]bool{
+	"ErrImagePull":      true,
+	"ImagePullBackOff":  true,
+	"InvalidImageName":  true,
+	"ErrImageNeverPull": true,
+}
+
+// Progress reports how far the images of a workload are pulled.
+type Progress struct {
+	// Nodes is the number of nodes the images are pulled onto.
+	Nodes int32
+	// Pulled is the number of nodes all images are pulled onto.
+	Pulled int32
+	// Failing maps images failing to be pulled to the reason, e.g.
+	// ImagePullBackOff.
+	Failing map[string]string
+
+	// observed is whether Nodes reflects the current DaemonSet.
+	observed bool
+}
+
+// Complete returns whether all images are pulled onto all nodes.
+func (p Progress) Complete() bool {
+	return p.observed && p.Pulled >= p.Nodes
+}
+
+// Puller pulls the images of workloads onto the nodes of one physical
+// cluster.
+type Puller struct {
+	getDaemonSet    func(ctx context.Context, namespace, name string) (*appsv1.DaemonSet, error)
+	listDaemonSets  func(ctx context.Context, namespace string, selector labels.Selector) ([]appsv1.DaemonSet, error)
+	createDaemonSet func(ctx context.Context, ds *appsv1.DaemonSet) error
+	deleteDaemonSet func(ctx context.Context, namespace, name string) error
+	listPods        func(ctx context.Context, namespace string, selector labels.Selector) ([]corev1.Pod, error)
+}
+
+// NewPuller returns a puller managing pre-pull DaemonSets with client.
+func NewPuller(client kubernetes.Interface) *Puller {
+	return &Puller{
+		getDaemonSet: func(ctx context.Context, namespace, name string) (*appsv1.DaemonSet, error) {
+			return client.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		},
+		listDaemonSets: func(ctx context.Context, namespace string, selector labels.Selector) ([]appsv1.DaemonSet, error) {
+			list, err := client.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+			if err != nil {
+				return nil, err
+			}
+			return list.Items, nil
+		},
+		createDaemonSet: func(ctx context.Context, ds *appsv1.DaemonSet) error {
+			_, err := client.AppsV1().DaemonSets(ds.Namespace).Create(ctx, ds, metav1.CreateOptions{})
+			return err
+		},
+		deleteDaemonSet: func(ctx context.Context, namespace, name string) error {
+			return client.AppsV1().DaemonSets(namespace).Delete(ctx, name, metav1.DeleteOptions{PropagationPolicy: ptr.To(metav1.DeletePropagationBackground)})
+		},
+		listPods: func(ctx context.Context, namespace string, selector labels.Selector) ([]corev1.Pod, error) {
+			list, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+			if err != nil {
+				return nil, err
+			}
+			return list.Items, nil
+		},
+	}
+}
+
+// Images returns the images of the containers and init containers of the
+// pod spec, sorted and without duplicates.
+func Images(spec *corev1.PodSpec) []string {
+	seen := map[string]bool{}
+	var images []string
+	for _, cs := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for _, c := range cs {
+			if c.Image != "" && !seen[c.Image] {
+				seen[c.Image] = true
+				images = append(images, c.Image)
+			}
+		}
+	}
+	sort.Strings(images)
+	return images
+}
+
+// Name returns the name of the pre-pull DaemonSet of the images of a
+// workload.
+func Name(workload string, images []string) string {
+	return "kcp-prepull-" + hash(workload+"\n"+strings.Join(images, "\n"))
+}
+
+// Timeout returns how long the images of the distribution are pulled.
+func Timeout(d *workloadv1alpha1.WorkloadDistribution) time.Duration {
+	if d.Spec.PrePull == nil || d.Spec.PrePull.Timeout == nil {
+		return DefaultTimeout
+	}
+	return d.Spec.PrePull.Timeout.Duration
+}
+
+// Pull ensures the images of the pod spec of a workload are being pulled in
+// the namespace, and returns the progress. Pre-pull DaemonSets of previous
+// images of the workload are deleted.
+func (p *Puller) Pull(ctx context.Context, namespace, workload string, spec *corev1.PodSpec) (Progress, error) {
+	images := Images(spec)
+	if len(images) == 0 {
+		return Progress{observed: true}, nil
+	}
+	name := Name(workload, images)
+
+	stale, err := p.listDaemonSets(ctx, namespace, labels.SelectorFromSet(labels.Set{LabelWorkload: hash(workload)}))
+	if err != nil {
+		return Progress{}, fmt.Errorf("failed to list pre-pull DaemonSets: %w", err)
+	}
+	for _, ds := range stale {
+		if ds.Name == name {
+			continue
+		}
+		if err := p.deleteDaemonSet(ctx, namespace, ds.Name); err != nil && !apierrors.IsNotFound(err) {
+			return Progress{}, fmt.Errorf("failed to delete pre-pull DaemonSet %s/%s: %w", namespace, ds.Name, err)
+		}
+	}
+
+	ds, err := p.getDaemonSet(ctx, namespace, name)
+	if apierrors.IsNotFound(err) {
+		klog.FromContext(ctx).V(2).Info("creating pre-pull DaemonSet", "namespace", namespace, "name", name, "images", images)
+		if err := p.createDaemonSet(ctx, daemonSet(namespace, name, workload, images, spec)); err != nil && !apierrors.IsAlreadyExists(err) {
+			return Progress{}, fmt.Errorf("failed to create pre-pull DaemonSet %s/%s: %w", namespace, name, err)
+		}
+		return Progress{}, nil
+	}
+	if err != nil {
+		return Progress{}, fmt.Errorf("failed to get pre-pull DaemonSet %s/%s: %w", namespace, name, err)
+	}
+
+	progress := Progress{
+		Nodes:    ds.Status.DesiredNumberScheduled,
+		observed: ds.Status.ObservedGeneration >= ds.Generation,
+	}
+	pods, err := p.listPods(ctx, namespace, labels.SelectorFromSet(labels.Set{LabelPrePull: name}))
+	if err != nil {
+		return Progress{}, fmt.Errorf("failed to list pre-pull pods: %w", err)
+	}
+	for i := range pods {
+		if pulled(&pods[i], &progress) {
+			progress.Pulled++
+		}
+	}
+	return progress, nil
+}
+
+// pulled returns whether all images of the pre-pull pod are present on its
+// node, and records the images failing to be pulled.
+func pulled(pod *corev1.Pod, progress *Progress) bool {
+	images := map[string]string{}
+	for _, c := range pod.Spec.Containers {
+		images[c.Name] = c.Image
+	}
+	done := 0
+	for _, status := range pod.Status.ContainerStatuses {
+		switch {
+		case status.ImageID != "":
+			done++
+		case status.State.Waiting != nil && pullFailures[status.State.Waiting.Reason]:
+			if progress.Failing == nil {
+				progress.Failing = map[string]string{}
+			}
+			progress.Failing[images[status.Name]] = status.State.Waiting.Reason
+		}
+	}
+	return done == len(pod.Spec.Containers)
+}
+
+// Wait pulls the images of the pod spec of a workload until they are
+// pulled onto all nodes, polling with the given interval. It returns an
+// error wrapping ErrTimeout if they are not pulled within the timeout.
+func (p *Puller) Wait(ctx context.Context, namespace, workload string, spec *corev1.PodSpec, interval, timeout time.Duration) (Progress, error) {
+	var progress Progress
+	err := wait.PollUntilContextTimeout(ctx, interval, timeout, true, func(ctx context.Context) (bool, error) {
+		var err error
+		progress, err = p.Pull(ctx, namespace, workload, spec)
+		if err != nil {
+			klog.FromContext(ctx).V(4).Info("failed to pre-pull images", "namespace", namespace, "workload", workload, "err", err)
+			return false, nil
+		}
+		return progress.Complete(), nil
+	})
+	if err != nil && ctx.Err() == nil {
+		return progress, fmt.Errorf("%w: pulled onto %d of %d nodes within %s", ErrTimeout, progress.Pulled, progress.Nodes, timeout)
+	}
+	return progress, err
+}
+
+// Cleanup deletes the pre-pull DaemonSets of a workload.
+func (p *Puller) Cleanup(ctx context.Context, namespace, workload string) error {
+	dss, err := p.listDaemonSets(ctx, namespace, labels.SelectorFromSet(labels.Set{LabelWorkload: hash(workload)}))
+	if err != nil {
+		return fmt.Errorf("failed to list pre-pull DaemonSets: %w", err)
+	}
+	for _, ds := range dss {
+		if err := p.deleteDaemonSet(ctx, namespace, ds.Name); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete pre-pull DaemonSet %s/%s: %w", namespace, ds.Name, err)
+		}
+	}
+	return nil
+}
+
+// SetCondition sets the ImagesPrePulled condition of the distribution from
+// the progress of pulling its images, and the error of Wait.
+func SetCondition(d *workloadv1alpha1.WorkloadDistribution, progress Progress, err error) {
+	switch {
+	case errors.Is(err, ErrTimeout):
+		conditions.MarkFalse(d, workloadv1alpha1.ImagesPrePulled, workloadv1alpha1.PrePullFailedReason, conditionsv1alpha1.ConditionSeverityError, "%s%s", err, failing(progress))
+	case err == nil && progress.Complete():
+		conditions.MarkTrue(d, workloadv1alpha1.ImagesPrePulled)
+	default:
+		conditions.MarkFalse(d, workloadv1alpha1.ImagesPrePulled, workloadv1alpha1.PrePullInProgressReason, conditionsv1alpha1.ConditionSeverityInfo, "pulled onto %d of %d nodes%s", progress.Pulled, progress.Nodes, failing(progress))
+	}
+}
+
+func failing(progress Progress) string {
+	if len(progress.Failing) == 0 {
+		return ""
+	}
+	var images []string
+	for image, reason := range progress.Failing {
+		images = append(images, fmt.Sprintf("%s (%s)", image, reason))
+	}
+	sort.Strings(images)
+	return ", failing: " + strings.Join(images, ", ")
+}
+
+func daemonSet(namespace, name, workload string, images []string, spec *corev1.PodSpec) *appsv1.DaemonSet {
+	podLabels := map[string]string{LabelPrePull: name}
+	podSpec := corev1.PodSpec{
+		NodeSelector:                  spec.NodeSelector,
+		Tolerations:                   spec.Tolerations,
+		ImagePullSecrets:              spec.ImagePullSecrets,
+		ServiceAccountName:            spec.ServiceAccountName,
+		AutomountServiceAccountToken:  ptr.To(false),
+		TerminationGracePeriodSeconds: ptr.To(int64(0)),
+	}
+	if spec.Affinity != nil && spec.Affinity.NodeAffinity != nil {
+		podSpec.Affinity = &corev1.Affinity{NodeAffinity: spec.Affinity.NodeAffinity}
+	}
+	for i, image := range images {
+		podSpec.Containers = append(podSpec.Containers, corev1.Container{
+			Name:            fmt.Sprintf("image-%d", i),
+			Image:           image,
+			Command:         pullCommand,
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1m"), corev1.ResourceMemory: resource.MustParse("8Mi")},
+				Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m"), corev1.ResourceMemory: resource.MustParse("16Mi")},
+			},
+		})
+	}
+
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Labels:    map[string]string{LabelWorkload: hash(workload), LabelPrePull: name},
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: podLabels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: podLabels},
+				Spec:       podSpec,
+			},
+		},
+	}
+}
+
+func hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])[:16]
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prepull
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+func TestPuller(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	p := NewPuller(client)
+
+	spec := &corev1.PodSpec{
+		InitContainers: []corev1.Container{{Name: "init", Image: "registry/init:1"}},
+		Containers:     []corev1.Container{{Name: "app", Image: "registry/app:1"}, {Name: "sidecar", Image: "registry/init:1"}},
+		NodeSelector:   map[string]string{"pool": "gpu"},
+	}
+	require.Equal(t, []string{"registry/app:1", "registry/init:1"}, Images(spec))
+
+	progress, err := p.Pull(ctx, "ns", "deployments/app", spec)
+	require.NoError(t, err)
+	require.False(t, progress.Complete())
+
+	name := Name("deployments/app", Images(spec))
+	ds, err := client.AppsV1().DaemonSets("ns").Get(ctx, name, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, ds.Spec.Template.Spec.Containers, 2)
+	require.Equal(t, map[string]string{"pool": "gpu"}, ds.Spec.Template.Spec.NodeSelector)
+
+	ds.Status = appsv1.DaemonSetStatus{DesiredNumberScheduled: 2}
+	_, err = client.AppsV1().DaemonSets("ns").UpdateStatus(ctx, ds, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	pod := func(node string, statuses ...corev1.ContainerStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name + "-" + node, Labels: map[string]string{LabelPrePull: name}},
+			Spec:       ds.Spec.Template.Spec,
+			Status:     corev1.PodStatus{ContainerStatuses: statuses},
+		}
+	}
+	_, err = client.CoreV1().Pods("ns").Create(ctx, pod("a",
+		corev1.ContainerStatus{Name: "image-0", ImageID: "sha256:app"},
+		corev1.ContainerStatus{Name: "image-1", ImageID: "sha256:init"},
+	), metav1.CreateOptions{})
+	require.NoError(t, err)
+	failing := pod("b",
+		corev1.ContainerStatus{Name: "image-0", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+		corev1.ContainerStatus{Name: "image-1", ImageID: "sha256:init"},
+	)
+	_, err = client.CoreV1().Pods("ns").Create(ctx, failing, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	progress, err = p.Pull(ctx, "ns", "deployments/app", spec)
+	require.NoError(t, err)
+	require.False(t, progress.Complete())
+	require.Equal(t, int32(2), progress.Nodes)
+	require.Equal(t, int32(1), progress.Pulled)
+	require.Equal(t, map[string]string{"registry/app:1": "ImagePullBackOff"}, progress.Failing)
+
+	d := &workloadv1alpha1.WorkloadDistribution{}
+	SetCondition(d, progress, nil)
+	require.Equal(t, workloadv1alpha1.PrePullInProgressReason, conditions.GetReason(d, workloadv1alpha1.ImagesPrePulled))
+	require.Equal(t, "pulled onto 1 of 2 nodes, failing: registry/app:1 (ImagePullBackOff)", conditions.GetMessage(d, workloadv1alpha1.ImagesPrePulled))
+
+	_, err = p.Wait(ctx, "ns", "deployments/app", spec, time.Millisecond, 10*time.Millisecond)
+	require.ErrorIs(t, err, ErrTimeout)
+	SetCondition(d, progress, err)
+	require.Equal(t, workloadv1alpha1.PrePullFailedReason, conditions.GetReason(d, workloadv1alpha1.ImagesPrePulled))
+
+	failing.Status.ContainerStatuses[0] = corev1.ContainerStatus{Name: "image-0", ImageID: "sha256:app"}
+	_, err = client.CoreV1().Pods("ns").UpdateStatus(ctx, failing, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	progress, err = p.Wait(ctx, "ns", "deployments/app", spec, time.Millisecond, time.Second)
+	require.NoError(t, err)
+	require.True(t, progress.Complete())
+	SetCondition(d, progress, nil)
+	require.True(t, conditions.IsTrue(d, workloadv1alpha1.ImagesPrePulled))
+
+	// new images replace the DaemonSet of the previous ones
+	spec.Containers[0].Image = "registry/app:2"
+	_, err = p.Pull(ctx, "ns", "deployments/app", spec)
+	require.NoError(t, err)
+	dss, err := client.AppsV1().DaemonSets("ns").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, dss.Items, 1)
+	require.Equal(t, Name("deployments/app", Images(spec)), dss.Items[0].Name)
+
+	require.NoError(t, p.Cleanup(ctx, "ns", "deployments/app"))
+	dss, err = client.AppsV1().DaemonSets("ns").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Empty(t, dss.Items)
+}
+
+func TestPullWithoutImages(t *testing.T) {
+	progress, err := NewPuller(fake.NewSimpleClientset()).Pull(context.Background(), "ns", "deployments/app", &corev1.PodSpec{})
+	require.NoError(t, err)
+	require.True(t, progress.Complete())
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package priority implements sync priority classes. After the syncer
+// reconnects or starts cold, every object is queued again; a priority queue
+// replays critical objects such as Secrets and RBAC before background ones,
+// bounding the recovery time of key workloads.
+package priority
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// Class is a sync priority class.
+type Class string
+
+const (
+	// Critical objects are synced before all others.
+	Critical Class = "critical"
+	// Standard is the class of objects without a specific class.
+	Standard Class = "standard"
+	// Background objects are synced after all others.
+	Background Class = "background"
+)
+
+// Classes lists the classes from highest to lowest priority.
+var Classes = []Class{Critical, Standard, Background}
+
+// Validate returns an error if c is neither empty nor a known class.
+func (c Class) Validate() error {
+	switch c {
+	case "", Critical, Standard, Background:
+		return nil
+	}
+	return fmt.Errorf("unknown sync priority class %q, must be one of %v", c, Classes)
+}
+
+func (c Class) rank() int {
+	switch c {
+	case Critical:
+		return 0
+	case Background:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// defaultCritical are resources that workloads cannot start without.
+var defaultCritical = map[schema.GroupResource]bool{
+	{Resource: "namespaces"}:                                              true,
+	{Resource: "secrets"}:                                                 true,
+	{Resource: "serviceaccounts"}:                                         true,
+	{Group: "rbac.authorization.k8s.io", Resource: "roles"}:               true,
+	{Group: "rbac.authorization.k8s.io", Resource: "rolebindings"}:        true,
+	{Group: "rbac.authorization.k8s.io", Resource: "clusterroles"}:        true,
+	{Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings"}: true,
+}
+
+// Classifier assigns sync priority classes to objects.
+type Classifier struct {
+	resources map[schema.GroupResource]Class
+}
+
+// NewClassifier returns a classifier that uses the given class per resource,
+// falling back to critical for Namespaces, Secrets, ServiceAccounts and RBAC,
+// and standard for everything else.
+func NewClassifier(resources map[schema.GroupResource]Class) *Classifier {
+	return &Classifier{resources: resources}
+}
+
+// Classify returns the class of an object of the given resource. The
+// workload.kcp.io/sync-priority annotation of the object takes precedence
+// over the class of the resource. Invalid annotations are ignored.
+func (c *Classifier) Classify(gr schema.GroupResource, obj metav1.Object) Class {
+	if obj != nil {
+		if class := Class(obj.GetAnnotations()[workloadv1alpha1.AnnotationSyncPriority]); class != "" && class.Validate() == nil {
+			return class
+		}
+	}
+	if class, found := c.resources[gr]; found && class != "" {
+		return class
+	}
+	if defaultCritical[gr] {
+		return Critical
+	}
+	return Standard
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package priority
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+func TestClassify(t *testing.T) {
+	deployments := schema.GroupResource{Group: "apps", Resource: "deployments"}
+	jobs := schema.GroupResource{Group: "batch", Resource: "jobs"}
+	c := NewClassifier(map[schema.GroupResource]Class{jobs: Background})
+
+	annotated := func(class string) metav1.Object {
+		return &metav1.ObjectMeta{Annotations: map[string]string{workloadv1alpha1.AnnotationSyncPriority: class}}
+	}
+
+	require.Equal(t, Critical, c.Classify(schema.GroupResource{Resource: "secrets"}, &metav1.ObjectMeta{}))
+	require.Equal(t, Critical, c.Classify(schema.GroupResource{Group: "rbac.authorization.k8s.io", Resource: "rolebindings"}, nil))
+	require.Equal(t, Standard, c.Classify(deployments, &metav1.ObjectMeta{}))
+	require.Equal(t, Background, c.Classify(jobs, &metav1.ObjectMeta{}))
+	require.Equal(t, Critical, c.Classify(deployments, annotated("critical")), "annotation wins over the default")
+	require.Equal(t, Standard, c.Classify(jobs, annotated("standard")), "annotation wins over the policy")
+	require.Equal(t, Background, c.Classify(jobs, annotated("urgent")), "invalid annotations are ignored")
+}
+
+func TestQueueOrdersByClass(t *testing.T) {
+	classes := map[string]Class{
+		"app":      Standard,
+		"secret":   Critical,
+		"cronjob":  Background,
+		"app2":     Standard,
+		"rbac":     Critical,
+		"unlisted": "",
+	}
+	q := NewRateLimitingQueue[string]("test", func(key string) Class { return classes[key] })
+	defer q.ShutDown()
+
+	for _, key := range []string{"cronjob", "app", "secret", "unlisted", "app2", "rbac"} {
+		q.Add(key)
+	}
+
+	// Re-adding a queued item with a changed class moves it.
+	classes["app2"] = Critical
+	q.Add("app2")
+
+	var order []string
+	for q.Len() > 0 {
+		key, _ := q.Get()
+		order = append(order, key)
+		q.Done(key)
+	}
+	require.Equal(t, []string{"secret", "rbac", "app2", "app", "unlisted", "cronjob"}, order)
+}
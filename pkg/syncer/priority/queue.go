@@ -0,0 +1,103 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package priority
+
+import (
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/kcp-dev/kcp/pkg/tmc/retry"
+)
+
+// queue is a workqueue.Queue with one FIFO per class. Pop returns items of
+// the highest class first.
+type queue[T comparable] struct {
+	classOf func(item T) Class
+	fifos   [3][]T
+	ranks   map[T]int
+}
+
+// NewQueue returns the storage of a workqueue that hands out items by their
+// class, as returned by classOf, and in FIFO order within a class.
+func NewQueue[T comparable](classOf func(item T) Class) workqueue.Queue[T] {
+	return &queue[T]{classOf: classOf, ranks: map[T]int{}}
+}
+
+// NewRateLimitingQueue returns a rate limiting workqueue that hands out
+// items by their class.
+func NewRateLimitingQueue[T comparable](name string, classOf func(item T) Class) workqueue.TypedRateLimitingInterface[T] {
+	return workqueue.NewTypedRateLimitingQueueWithConfig(
+		retry.ControllerRateLimiter[T](),
+		workqueue.TypedRateLimitingQueueConfig[T]{
+			Name: name,
+			DelayingQueue: workqueue.NewTypedDelayingQueueWithConfig(workqueue.TypedDelayingQueueConfig[T]{
+				Name: name,
+				Queue: workqueue.NewTypedWithConfig(workqueue.TypedQueueConfig[T]{
+					Name:  name,
+					Queue: NewQueue(classOf),
+				}),
+			}),
+		},
+	)
+}
+
+// Touch moves an item that is queued again to its current class, which
+// may have changed, e.g. through an annotation.
+func (q *queue[T]) Touch(item T) {
+	old, found := q.ranks[item]
+	if !found {
+		return
+	}
+	rank := q.classOf(item).rank()
+	if rank == old {
+		return
+	}
+	for i, other := range q.fifos[old] {
+		if other == item {
+			q.fifos[old] = append(q.fifos[old][:i], q.fifos[old][i+1:]...)
+			break
+		}
+	}
+	q.fifos[rank] = append(q.fifos[rank], item)
+	q.ranks[item] = rank
+}
+
+func (q *queue[T]) Push(item T) {
+	rank := q.classOf(item).rank()
+	q.fifos[rank] = append(q.fifos[rank], item)
+	q.ranks[item] = rank
+}
+
+func (q *queue[T]) Len() int {
+	return len(q.fifos[0]) + len(q.fifos[1]) + len(q.fifos[2])
+}
+
+func (q *queue[T]) Pop() T {
+	for rank := range q.fifos {
+		if len(q.fifos[rank]) == 0 {
+			continue
+		}
+		item := q.fifos[rank][0]
+		// Avoid memory leaks of the backing array, as in the default queue.
+		var zero T
+		q.fifos[rank][0] = zero
+		q.fifos[rank] = q.fifos[rank][1:]
+		delete(q.ranks, item)
+		return item
+	}
+	var zero T
+	return zero
+}
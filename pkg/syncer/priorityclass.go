@@ -0,0 +1,128 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var priorityClassesGVR = workloadv1alpha1.SchemeGroupVersion.WithResource("workloadpriorityclasses")
+
+// podSpecPaths are the paths of the pod spec in the objects of the kinds
+// running pods.
+var podSpecPaths = map[schema.GroupKind][]string{
+	{Kind: "Pod"}:                        {"spec"},
+	{Kind: "ReplicationController"}:      {"spec", "template", "spec"},
+	{Group: "apps", Kind: "Deployment"}:  {"spec", "template", "spec"},
+	{Group: "apps", Kind: "ReplicaSet"}:  {"spec", "template", "spec"},
+	{Group: "apps", Kind: "StatefulSet"}: {"spec", "template", "spec"},
+	{Group: "apps", Kind: "DaemonSet"}:   {"spec", "template", "spec"},
+	{Group: "batch", Kind: "Job"}:        {"spec", "template", "spec"},
+	{Group: "batch", Kind: "CronJob"}:    {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// workloadPriorityClasses returns the WorkloadPriorityClasses of the
+// workspace of the SyncTarget by name.
+func (s *syncer) workloadPriorityClasses(ctx context.Context) (map[string]*workloadv1alpha1.WorkloadPriorityClass, error) {
+	list, err := s.upstream.Resource(priorityClassesGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	classes := make(map[string]*workloadv1alpha1.WorkloadPriorityClass, len(list.Items))
+	for i := range list.Items {
+		class := &workloadv1alpha1.WorkloadPriorityClass{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(list.Items[i].Object, class); err != nil {
+			return nil, err
+		}
+		classes[class.Name] = class
+	}
+	return classes, nil
+}
+
+// setWorkloadPriorityClasses records the WorkloadPriorityClasses last read.
+// If they changed, the objects running pods are synced again, as the
+// PriorityClasses of their pods may change.
+func (s *syncer) setWorkloadPriorityClasses(classes map[string]*workloadv1alpha1.WorkloadPriorityClass) {
+	previous := s.priorityClasses.Swap(&classes)
+	if previous == nil || equality.Semantic.DeepEqual(priorityClassSpecs(*previous), priorityClassSpecs(classes)) {
+		return
+	}
+	s.controllers.Range(func(_, value interface{}) bool {
+		c := value.(*controller)
+		if _, found := podSpecPaths[c.kind]; found {
+			for _, key := range c.upstreamInformer.GetStore().ListKeys() {
+				c.queue.Add(key)
+			}
+		}
+		return true
+	})
+}
+
+func priorityClassSpecs(classes map[string]*workloadv1alpha1.WorkloadPriorityClass) map[string]workloadv1alpha1.WorkloadPriorityClassSpec {
+	specs := make(map[string]workloadv1alpha1.WorkloadPriorityClassSpec, len(classes))
+	for name, class := range classes {
+		specs[name] = class.Spec
+	}
+	return specs
+}
+
+// getPriorityClass returns the WorkloadPriorityClass of the given name last
+// read, see priorityclass.NewMapper.
+func (s *syncer) getPriorityClass(name string) (*workloadv1alpha1.WorkloadPriorityClass, error) {
+	if classes := s.priorityClasses.Load(); classes != nil {
+		if class, found := (*classes)[name]; found {
+			return class, nil
+		}
+	}
+	return nil, apierrors.NewNotFound(priorityClassesGVR.GroupResource(), name)
+}
+
+// mapPriorityClass replaces the priority class of the pods of a downstream
+// object by the PriorityClass of the physical cluster, see
+// priorityclass.Mapper.MapPodSpec.
+func (c *controller) mapPriorityClass(ctx context.Context, obj *unstructured.Unstructured) error {
+	path, found := podSpecPaths[c.kind]
+	if !found || c.priorityClassMapper == nil {
+		return nil
+	}
+	name, _, err := unstructured.NestedString(obj.Object, append(slices.Clone(path), "priorityClassName")...)
+	if err != nil || name == "" {
+		return err
+	}
+	spec := &corev1.PodSpec{PriorityClassName: name}
+	if err := c.priorityClassMapper.MapPodSpec(ctx, spec); err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedField(obj.Object, spec.PriorityClassName, append(slices.Clone(path), "priorityClassName")...); err != nil {
+		return err
+	}
+	// Resolved from the PriorityClass of the physical cluster on admission.
+	unstructured.RemoveNestedField(obj.Object, append(slices.Clone(path), "priority")...)
+	unstructured.RemoveNestedField(obj.Object, append(slices.Clone(path), "preemptionPolicy")...)
+	return nil
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package priorityclass maps the WorkloadPriorityClasses of a workspace to
+// PriorityClasses of a physical cluster. Pods are synced with the name of
+// the downstream PriorityClass, which is created with the value and
+// preemption policy of the workspace class if absent, so that preemption on
+// the physical cluster roughly matches the intent of the workspace.
+package priorityclass
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	schedulingapi "k8s.io/kubernetes/pkg/apis/scheduling"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var (
+	// ErrReserved is returned for pods or classes using priorities reserved
+	// for system components.
+	ErrReserved = errors.New("reserved priority")
+	// ErrUnavailable is returned if the downstream PriorityClass is missing
+	// and may not be created, or does not match the workspace class.
+	ErrUnavailable = errors.New("priority class unavailable")
+)
+
+// Mapper maps priority classes of pods synced to one physical cluster.
+type Mapper struct {
+	getClass            func(name string) (*workloadv1alpha1.WorkloadPriorityClass, error)
+	getPriorityClass    func(ctx context.Context, name string) (*schedulingv1.PriorityClass, error)
+	createPriorityClass func(ctx context.Context, pc *schedulingv1.PriorityClass) error
+
+	lock sync.Mutex
+	// ensured holds the downstream classes known to match a workspace class.
+	ensured map[string]workloadv1alpha1.WorkloadPriorityClassSpec
+}
+
+// NewMapper returns a mapper looking up workspace classes with getClass,
+// which returns a NotFound error for unknown classes, and managing the
+// PriorityClasses of the physical cluster with client.
+func NewMapper(getClass func(name string) (*workloadv1alpha1.WorkloadPriorityClass, error), client kubernetes.Interface) *Mapper {
+	return &Mapper{
+		getClass: getClass,
+		getPriorityClass: func(ctx context.Context, name string) (*schedulingv1.PriorityClass, error) {
+			return client.SchedulingV1().PriorityClasses().Get(ctx, name, metav1.GetOptions{})
+		},
+		createPriorityClass: func(ctx context.Context, pc *schedulingv1.PriorityClass) error {
+			_, err := client.SchedulingV1().PriorityClasses().Create(ctx, pc, metav1.CreateOptions{})
+			return err
+		},
+		ensured: map[string]workloadv1alpha1.WorkloadPriorityClassSpec{},
+	}
+}
+
+// Validate returns an error wrapping ErrReserved if the class uses a
+// priority value or name reserved for system components.
+func Validate(class *workloadv1alpha1.WorkloadPriorityClass) error {
+	if class.Spec.Value > schedulingapi.HighestUserDefinablePriority {
+		return fmt.Errorf("%w: value %d of priority class %q exceeds %d", ErrReserved, class.Spec.Value, class.Name, schedulingapi.HighestUserDefinablePriority)
+	}
+	for _, name := range []string{class.Name, class.DownstreamPriorityClassName()} {
+		if strings.HasPrefix(name, schedulingapi.SystemPriorityClassPrefix) {
+			return fmt.Errorf("%w: priority class name %q starts with %q", ErrReserved, name, schedulingapi.SystemPriorityClassPrefix)
+		}
+	}
+	return nil
+}
+
+// MapPodSpec replaces the priority class of the pod spec by the downstream
+// PriorityClass, creating it if absent and allowed by the creation policy.
+// The priority and preemption policy are cleared so that they are resolved
+// from the downstream class on admission.
+func (m *Mapper) MapPodSpec(ctx context.Context, spec *corev1.PodSpec) error {
+	if spec.PriorityClassName == "" {
+		return nil
+	}
+	if strings.HasPrefix(spec.PriorityClassName, schedulingapi.SystemPriorityClassPrefix) {
+		return fmt.Errorf("%w: pods synced from workspaces cannot use priority class %q", ErrReserved, spec.PriorityClassName)
+	}
+
+	class, err := m.getClass(spec.PriorityClassName)
+	if err != nil {
+		return fmt.Errorf("failed to get WorkloadPriorityClass %q: %w", spec.PriorityClassName, err)
+	}
+	if err := Validate(class); err != nil {
+		return err
+	}
+	downstream := class.DownstreamPriorityClassName()
+	if err := m.ensure(ctx, class, downstream); err != nil {
+		return err
+	}
+
+	spec.PriorityClassName = downstream
+	spec.Priority = nil
+	spec.PreemptionPolicy = nil
+	return nil
+}
+
+func (m *Mapper) ensure(ctx context.Context, class *workloadv1alpha1.WorkloadPriorityClass, downstream string) error {
+	m.lock.Lock()
+	ensured, found := m.ensured[downstream]
+	m.lock.Unlock()
+	if found && equalSpec(ensured, class.Spec) {
+		return nil
+	}
+
+	existing, err := m.getPriorityClass(ctx, downstream)
+	switch {
+	case apierrors.IsNotFound(err):
+		if class.Spec.CreationPolicy == workloadv1alpha1.PriorityClassCreateNever {
+			return fmt.Errorf("%w: PriorityClass %q does not exist and creation policy is %s", ErrUnavailable, downstream, class.Spec.CreationPolicy)
+		}
+		klog.FromContext(ctx).V(2).Info("creating PriorityClass", "priorityClass", downstream, "value", class.Spec.Value)
+		// If it was created concurrently, it is checked on the next attempt.
+		if err := m.createPriorityClass(ctx, priorityClass(class, downstream)); err != nil {
+			return fmt.Errorf("failed to create PriorityClass %q: %w", downstream, err)
+		}
+	case err != nil:
+		return fmt.Errorf("failed to get PriorityClass %q: %w", downstream, err)
+	case class.Spec.CreationPolicy != workloadv1alpha1.PriorityClassCreateNever && existing.Value != class.Spec.Value:
+		// A class of the cluster administrator, or one created for a
+		// different workspace class. Using it would change preemption.
+		return fmt.Errorf("%w: PriorityClass %q has value %d, expected %d", ErrUnavailable, downstream, existing.Value, class.Spec.Value)
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.ensured[downstream] = *class.Spec.DeepCopy()
+	return nil
+}
+
+// Forget drops what is known about the downstream PriorityClass, e.g. after
+// it was deleted, so that it is checked again on its next use.
+func (m *Mapper) Forget(downstream string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.ensured, downstream)
+}
+
+func priorityClass(class *workloadv1alpha1.WorkloadPriorityClass, downstream string) *schedulingv1.PriorityClass {
+	return &schedulingv1.PriorityClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   downstream,
+			Labels: map[string]string{workloadv1alpha1.LabelPriorityClass: class.Name},
+		},
+		Value:            class.Spec.Value,
+		PreemptionPolicy: class.Spec.PreemptionPolicy,
+		Description:      class.Spec.Description,
+	}
+}
+
+func equalSpec(a, b workloadv1alpha1.WorkloadPriorityClassSpec) bool {
+	return a.Value == b.Value && a.CreationPolicy == b.CreationPolicy && a.DownstreamName == b.DownstreamName
+}
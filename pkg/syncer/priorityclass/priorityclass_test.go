@@ -0,0 +1,115 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package priorityclass
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+type fakeCluster struct {
+	classes         map[string]*workloadv1alpha1.WorkloadPriorityClass
+	priorityClasses map[string]*schedulingv1.PriorityClass
+	gets            int
+}
+
+func (f *fakeCluster) mapper() *Mapper {
+	return &Mapper{
+		getClass: func(name string) (*workloadv1alpha1.WorkloadPriorityClass, error) {
+			if class, found := f.classes[name]; found {
+				return class, nil
+			}
+			return nil, apierrors.NewNotFound(workloadv1alpha1.Resource("workloadpriorityclasses"), name)
+		},
+		getPriorityClass: func(_ context.Context, name string) (*schedulingv1.PriorityClass, error) {
+			f.gets++
+			if pc, found := f.priorityClasses[name]; found {
+				return pc, nil
+			}
+			return nil, apierrors.NewNotFound(schedulingv1.Resource("priorityclasses"), name)
+		},
+		createPriorityClass: func(_ context.Context, pc *schedulingv1.PriorityClass) error {
+			f.priorityClasses[pc.Name] = pc
+			return nil
+		},
+		ensured: map[string]workloadv1alpha1.WorkloadPriorityClassSpec{},
+	}
+}
+
+func class(name string, spec workloadv1alpha1.WorkloadPriorityClassSpec) *workloadv1alpha1.WorkloadPriorityClass {
+	return &workloadv1alpha1.WorkloadPriorityClass{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec}
+}
+
+func TestMapPodSpec(t *testing.T) {
+	f := &fakeCluster{
+		classes: map[string]*workloadv1alpha1.WorkloadPriorityClass{
+			"high":     class("high", workloadv1alpha1.WorkloadPriorityClassSpec{Value: 1000, PreemptionPolicy: ptr.To(corev1.PreemptNever), Description: "important"}),
+			"batch":    class("batch", workloadv1alpha1.WorkloadPriorityClassSpec{Value: 10, DownstreamName: "tenant-batch", CreationPolicy: workloadv1alpha1.PriorityClassCreateNever}),
+			"conflict": class("conflict", workloadv1alpha1.WorkloadPriorityClassSpec{Value: 5, DownstreamName: "admin"}),
+			"missing":  class("missing", workloadv1alpha1.WorkloadPriorityClassSpec{Value: 5, CreationPolicy: workloadv1alpha1.PriorityClassCreateNever}),
+			"huge":     class("huge", workloadv1alpha1.WorkloadPriorityClassSpec{Value: 2000000000}),
+			"sneaky":   class("sneaky", workloadv1alpha1.WorkloadPriorityClassSpec{Value: 1, DownstreamName: "system-cluster-critical"}),
+		},
+		priorityClasses: map[string]*schedulingv1.PriorityClass{
+			"tenant-batch": {ObjectMeta: metav1.ObjectMeta{Name: "tenant-batch"}, Value: 7},
+			"admin":        {ObjectMeta: metav1.ObjectMeta{Name: "admin"}, Value: 100},
+		},
+	}
+	m := f.mapper()
+	ctx := context.Background()
+
+	spec := &corev1.PodSpec{PriorityClassName: "high", Priority: ptr.To[int32](1000)}
+	require.NoError(t, m.MapPodSpec(ctx, spec))
+	require.Equal(t, &corev1.PodSpec{PriorityClassName: "high"}, spec)
+	require.Equal(t, &schedulingv1.PriorityClass{
+		ObjectMeta:       metav1.ObjectMeta{Name: "high", Labels: map[string]string{workloadv1alpha1.LabelPriorityClass: "high"}},
+		Value:            1000,
+		PreemptionPolicy: ptr.To(corev1.PreemptNever),
+		Description:      "important",
+	}, f.priorityClasses["high"], "created if absent")
+
+	gets := f.gets
+	require.NoError(t, m.MapPodSpec(ctx, &corev1.PodSpec{PriorityClassName: "high"}))
+	require.Equal(t, gets, f.gets, "known classes are not looked up again")
+	m.Forget("high")
+	require.NoError(t, m.MapPodSpec(ctx, &corev1.PodSpec{PriorityClassName: "high"}))
+	require.Equal(t, gets+1, f.gets)
+
+	spec = &corev1.PodSpec{PriorityClassName: "batch"}
+	require.NoError(t, m.MapPodSpec(ctx, spec), "existing classes are used as is with creation policy Never")
+	require.Equal(t, "tenant-batch", spec.PriorityClassName)
+
+	require.NoError(t, m.MapPodSpec(ctx, &corev1.PodSpec{}), "pods without priority class are not changed")
+
+	require.ErrorIs(t, m.MapPodSpec(ctx, &corev1.PodSpec{PriorityClassName: "conflict"}), ErrUnavailable)
+	require.ErrorIs(t, m.MapPodSpec(ctx, &corev1.PodSpec{PriorityClassName: "missing"}), ErrUnavailable)
+	require.NotContains(t, f.priorityClasses, "missing")
+	require.ErrorIs(t, m.MapPodSpec(ctx, &corev1.PodSpec{PriorityClassName: "huge"}), ErrReserved)
+	require.ErrorIs(t, m.MapPodSpec(ctx, &corev1.PodSpec{PriorityClassName: "sneaky"}), ErrReserved)
+	require.ErrorIs(t, m.MapPodSpec(ctx, &corev1.PodSpec{PriorityClassName: "system-node-critical"}), ErrReserved)
+	require.True(t, apierrors.IsNotFound(m.MapPodSpec(ctx, &corev1.PodSpec{PriorityClassName: "unknown"})))
+}
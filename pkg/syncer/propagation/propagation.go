@@ -0,0 +1,178 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package propagation decides which labels and annotations of a workspace
+// object its downstream copy carries, following the PropagationPolicies of
+// the workspace. Labels and annotations of the kcp.io domain and its
+// subdomains are internal to the workspace and never propagated, with or
+// without a policy. Objects are propagated before the syncer sets its own
+// downstream metadata, e.g. the upstream identity of renamed objects.
+package propagation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/fanout"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// internalDomain is the domain of labels and annotations that never leave
+// the workspace.
+const internalDomain = "kcp.io"
+
+// Internal returns true if key belongs to the kcp.io domain or one of its
+// subdomains, e.g. kcp.io/cluster or workload.kcp.io/sync-priority.
+func Internal(key string) bool {
+	prefix, _, found := strings.Cut(key, "/")
+	if !found {
+		return false
+	}
+	return prefix == internalDomain || strings.HasSuffix(prefix, "."+internalDomain)
+}
+
+// Selects returns whether policy applies to obj, an object of resource gr.
+func Selects(policy *workloadv1alpha1.PropagationPolicy, gr schema.GroupResource, obj *unstructured.Unstructured) (bool, error) {
+	if len(policy.Spec.Resources) > 0 {
+		found := false
+		for _, resource := range policy.Spec.Resources {
+			if resource == "*" || resource == gr.String() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	if policy.Spec.ObjectSelector == nil {
+		return true, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(policy.Spec.ObjectSelector)
+	if err != nil {
+		return false, fmt.Errorf("invalid object selector of PropagationPolicy %q: %w", policy.Name, err)
+	}
+	return selector.Matches(labels.Set(obj.GetLabels())), nil
+}
+
+// Apply returns a copy of obj, an object of resource gr, with the labels and
+// annotations its downstream copy on syncTarget carries. Policies selecting
+// obj are applied in the order of their names, each to the result of the
+// previous one. It fails if a policy is invalid, or adds a value that
+// references metadata the target does not have, in which case obj must not
+// be synced to it.
+func Apply(obj *unstructured.Unstructured, gr schema.GroupResource, policies []*workloadv1alpha1.PropagationPolicy, syncTarget *tmcv1alpha1.SyncTarget) (*unstructured.Unstructured, error) {
+	var selected []*workloadv1alpha1.PropagationPolicy
+	for _, policy := range policies {
+		ok, err := Selects(policy, gr, obj)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			selected = append(selected, policy)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].Name < selected[j].Name
+	})
+
+	objLabels := withoutInternal(obj.GetLabels())
+	objAnnotations := withoutInternal(obj.GetAnnotations())
+	for _, policy := range selected {
+		var err error
+		if objLabels, err = propagate(objLabels, policy.Spec.Labels, syncTarget); err != nil {
+			return nil, fmt.Errorf("PropagationPolicy %q: labels: %w", policy.Name, err)
+		}
+		if objAnnotations, err = propagate(objAnnotations, policy.Spec.Annotations, syncTarget); err != nil {
+			return nil, fmt.Errorf("PropagationPolicy %q: annotations: %w", policy.Name, err)
+		}
+	}
+	for key, value := range objLabels {
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid value %q of label %q: %s", value, key, strings.Join(errs, ", "))
+		}
+	}
+
+	propagated := obj.DeepCopy()
+	propagated.SetLabels(nilIfEmpty(objLabels))
+	propagated.SetAnnotations(nilIfEmpty(objAnnotations))
+	return propagated, nil
+}
+
+func withoutInternal(m map[string]string) map[string]string {
+	out := make(map[string]string, len(m))
+	for key, value := range m {
+		if !Internal(key) {
+			out[key] = value
+		}
+	}
+	return out
+}
+
+func nilIfEmpty(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+// propagate applies the rules of a policy to the labels or annotations m.
+func propagate(m map[string]string, rules workloadv1alpha1.MetadataPropagation, syncTarget *tmcv1alpha1.SyncTarget) (map[string]string, error) {
+	out := make(map[string]string, len(m)+len(rules.Add))
+	for key, value := range m {
+		if len(rules.Propagate) > 0 && !matchesAny(rules.Propagate, key) {
+			continue
+		}
+		if matchesAny(rules.Strip, key) {
+			continue
+		}
+		out[key] = value
+	}
+	for key, value := range rules.Add {
+		if Internal(key) {
+			return nil, fmt.Errorf("cannot add %q of the %s domain", key, internalDomain)
+		}
+		expanded, err := fanout.Expand(value, syncTarget)
+		if err != nil {
+			return nil, fmt.Errorf("cannot add %q: %w", key, err)
+		}
+		out[key] = expanded
+	}
+	return out, nil
+}
+
+// matchesAny returns whether key matches one of patterns, exactly or, for
+// patterns ending with "*", by prefix.
+func matchesAny(patterns []string, key string) bool {
+	for _, pattern := range patterns {
+		if prefix, found := strings.CutSuffix(pattern, "*"); found {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		} else if key == pattern {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package propagation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var deployments = schema.GroupResource{Group: "apps", Resource: "deployments"}
+
+func newDeployment(labels, annotations map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("apps/v1")
+	obj.SetKind("Deployment")
+	obj.SetNamespace("default")
+	obj.SetName("web")
+	obj.SetLabels(labels)
+	obj.SetAnnotations(annotations)
+	return obj
+}
+
+func newPolicy(name string, spec workloadv1alpha1.PropagationPolicySpec) *workloadv1alpha1.PropagationPolicy {
+	return &workloadv1alpha1.PropagationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       spec,
+	}
+}
+
+func TestInternal(t *testing.T) {
+	for key, internal := range map[string]bool{
+		"kcp.io/cluster":                   true,
+		"workload.kcp.io/sync-priority":    true,
+		"experimental.tmc.kcp.io/anything": true,
+		"app":                              false,
+		"app.kubernetes.io/name":           false,
+		"notkcp.io/key":                    false,
+		"kcp.io.example.com/key":           false,
+	} {
+		require.Equal(t, internal, Internal(key), key)
+	}
+}
+
+func TestApply(t *testing.T) {
+	syncTarget := &tmcv1alpha1.SyncTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "us-east-1", Labels: map[string]string{"region": "us-east"}},
+		Spec:       tmcv1alpha1.SyncTargetSpec{Location: "us-east"},
+	}
+	obj := newDeployment(
+		map[string]string{"app": "web", "team": "payments", "internal.example.com/cost-center": "42", "workload.kcp.io/template": "web"},
+		map[string]string{"kcp.io/cluster": "root:org", "description": "web frontend", "kubectl.kubernetes.io/last-applied-configuration": "{}"},
+	)
+
+	tests := map[string]struct {
+		policies        []*workloadv1alpha1.PropagationPolicy
+		wantLabels      map[string]string
+		wantAnnotations map[string]string
+		wantErr         string
+	}{
+		"no policy copies everything but internal metadata": {
+			wantLabels:      map[string]string{"app": "web", "team": "payments", "internal.example.com/cost-center": "42"},
+			wantAnnotations: map[string]string{"description": "web frontend", "kubectl.kubernetes.io/last-applied-configuration": "{}"},
+		},
+		"propagate, strip and add": {
+			policies: []*workloadv1alpha1.PropagationPolicy{newPolicy("default", workloadv1alpha1.PropagationPolicySpec{
+				Labels: workloadv1alpha1.MetadataPropagation{
+					Strip: []string{"internal.example.com/*"},
+					Add:   map[string]string{"topology.example.com/region": "${target.labels.region}", "managed-by": "kcp"},
+				},
+				Annotations: workloadv1alpha1.MetadataPropagation{
+					Propagate: []string{"description"},
+					Add:       map[string]string{"example.com/location": "${target.location} (${target.name})"},
+				},
+			})},
+			wantLabels:      map[string]string{"app": "web", "team": "payments", "topology.example.com/region": "us-east", "managed-by": "kcp"},
+			wantAnnotations: map[string]string{"description": "web frontend", "example.com/location": "us-east (us-east-1)"},
+		},
+		"policies apply in name order": {
+			policies: []*workloadv1alpha1.PropagationPolicy{
+				newPolicy("b-strip", workloadv1alpha1.PropagationPolicySpec{
+					Labels: workloadv1alpha1.MetadataPropagation{Strip: []string{"team"}},
+				}),
+				newPolicy("a-add", workloadv1alpha1.PropagationPolicySpec{
+					Labels: workloadv1alpha1.MetadataPropagation{Propagate: []string{"app"}, Add: map[string]string{"team": "platform"}},
+				}),
+			},
+			wantLabels:      map[string]string{"app": "web"},
+			wantAnnotations: map[string]string{"description": "web frontend", "kubectl.kubernetes.io/last-applied-configuration": "{}"},
+		},
+		"policies for other resources do not apply": {
+			policies: []*workloadv1alpha1.PropagationPolicy{newPolicy("services", workloadv1alpha1.PropagationPolicySpec{
+				Resources: []string{"services"},
+				Labels:    workloadv1alpha1.MetadataPropagation{Propagate: []string{"app"}},
+			})},
+			wantLabels:      map[string]string{"app": "web", "team": "payments", "internal.example.com/cost-center": "42"},
+			wantAnnotations: map[string]string{"description": "web frontend", "kubectl.kubernetes.io/last-applied-configuration": "{}"},
+		},
+		"object selector": {
+			policies: []*workloadv1alpha1.PropagationPolicy{
+				newPolicy("payments", workloadv1alpha1.PropagationPolicySpec{
+					Resources:      []string{"deployments.apps"},
+					ObjectSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}},
+					Annotations:    workloadv1alpha1.MetadataPropagation{Propagate: []string{"none"}},
+				}),
+				newPolicy("billing", workloadv1alpha1.PropagationPolicySpec{
+					ObjectSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "billing"}},
+					Labels:         workloadv1alpha1.MetadataPropagation{Propagate: []string{"none"}},
+				}),
+			},
+			wantLabels: map[string]string{"app": "web", "team": "payments", "internal.example.com/cost-center": "42"},
+		},
+		"internal metadata cannot be added": {
+			policies: []*workloadv1alpha1.PropagationPolicy{newPolicy("default", workloadv1alpha1.PropagationPolicySpec{
+				Annotations: workloadv1alpha1.MetadataPropagation{Add: map[string]string{"tmc.kcp.io/owners": "root:org"}},
+			})},
+			wantErr: `PropagationPolicy "default": annotations: cannot add "tmc.kcp.io/owners" of the kcp.io domain`,
+		},
+		"missing target metadata": {
+			policies: []*workloadv1alpha1.PropagationPolicy{newPolicy("default", workloadv1alpha1.PropagationPolicySpec{
+				Labels: workloadv1alpha1.MetadataPropagation{Add: map[string]string{"zone": "${target.labels.zone}"}},
+			})},
+			wantErr: `PropagationPolicy "default": labels: cannot add "zone": SyncTarget "us-east-1" has no value for ${target.labels.zone}`,
+		},
+		"invalid label value": {
+			policies: []*workloadv1alpha1.PropagationPolicy{newPolicy("default", workloadv1alpha1.PropagationPolicySpec{
+				Labels: workloadv1alpha1.MetadataPropagation{Add: map[string]string{"where": "${target.location} (${target.name})"}},
+			})},
+			wantErr: `invalid value "us-east (us-east-1)" of label "where"`,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			propagated, err := Apply(obj, deployments, tc.policies, syncTarget)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantLabels, propagated.GetLabels())
+			require.Equal(t, tc.wantAnnotations, propagated.GetAnnotations())
+			require.Equal(t, "root:org", obj.GetAnnotations()["kcp.io/cluster"], "the workspace object is not modified")
+		})
+	}
+}
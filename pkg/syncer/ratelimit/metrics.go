@@ -0,0 +1,66 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"sync"
+
+	compbasemetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+var (
+	throttleSeconds = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Name:           "syncer_physical_cluster_client_throttle_seconds_total",
+			Help:           "Time requests to the physical cluster of a SyncTarget waited for the client-side rate limiter.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"sync_target"},
+	)
+	throttledResponses = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Name:           "syncer_physical_cluster_throttled_responses_total",
+			Help:           "Number of 429 Too Many Requests answers of the physical cluster of a SyncTarget.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"sync_target"},
+	)
+	currentQPS = compbasemetrics.NewGaugeVec(
+		&compbasemetrics.GaugeOpts{
+			Name:           "syncer_physical_cluster_client_qps",
+			Help:           "Current client-side rate limit towards the physical cluster of a SyncTarget, in requests per second.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"sync_target"},
+	)
+)
+
+var registerMetrics sync.Once
+
+// Register metrics.
+func Register() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(throttleSeconds)
+		legacyregistry.MustRegister(throttledResponses)
+		legacyregistry.MustRegister(currentQPS)
+	})
+}
+
+func init() {
+	Register()
+}
@@ -0,0 +1,286 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ratelimit limits the requests of the syncer to physical clusters.
+// The defaults come from the syncer flags and can be overridden per
+// SyncTarget in spec.clientRateLimit. The limit adapts: every 429 Too Many
+// Requests answer of the physical cluster halves the rate, down to a
+// minimum, and the rate recovers step by step once the cluster stops
+// throttling.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/klog/v2"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+const (
+	defaultQPS              = 50
+	defaultBurst            = 100
+	defaultMinQPS           = 1
+	defaultRecoveryInterval = 30 * time.Second
+)
+
+// Options are the defaults for all SyncTargets.
+type Options struct {
+	// QPS is the sustained rate towards a physical cluster.
+	QPS float32
+	// Burst is the burst allowed above QPS.
+	Burst int
+	// MinQPS is the rate below which throttling answers do not push.
+	MinQPS float32
+	// RecoveryInterval is the time without throttling after which the rate
+	// is doubled again, up to the configured rate.
+	RecoveryInterval time.Duration
+}
+
+// NewOptions returns the default options.
+func NewOptions() *Options {
+	return &Options{
+		QPS:              defaultQPS,
+		Burst:            defaultBurst,
+		MinQPS:           defaultMinQPS,
+		RecoveryInterval: defaultRecoveryInterval,
+	}
+}
+
+// AddFlags adds the rate limit flags to the flag set.
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.Float32Var(&o.QPS, "physical-cluster-qps", o.QPS, "Default requests per second to a physical cluster. Overridden by spec.clientRateLimit.qps of the SyncTarget.")
+	fs.IntVar(&o.Burst, "physical-cluster-burst", o.Burst, "Default burst of requests to a physical cluster. Overridden by spec.clientRateLimit.burst of the SyncTarget.")
+	fs.Float32Var(&o.MinQPS, "physical-cluster-min-qps", o.MinQPS, "Requests per second to a physical cluster below which 429 answers do not lower the rate further.")
+	fs.DurationVar(&o.RecoveryInterval, "physical-cluster-rate-recovery-interval", o.RecoveryInterval, "Time without 429 answers after which a lowered rate to a physical cluster is doubled again.")
+}
+
+// Validate validates the options.
+func (o *Options) Validate() error {
+	if o.QPS <= 0 || o.Burst <= 0 {
+		return fmt.Errorf("--physical-cluster-qps and --physical-cluster-burst must be positive")
+	}
+	if o.MinQPS <= 0 || o.MinQPS > o.QPS {
+		return fmt.Errorf("--physical-cluster-min-qps must be positive and at most --physical-cluster-qps")
+	}
+	if o.RecoveryInterval <= 0 {
+		return fmt.Errorf("--physical-cluster-rate-recovery-interval must be positive")
+	}
+	return nil
+}
+
+// Settings is the effective rate limit of one SyncTarget.
+type Settings struct {
+	QPS   float32
+	Burst int
+}
+
+// For returns the settings of target, i.e. the defaults overridden by its
+// spec.clientRateLimit.
+func (o *Options) For(target *tmcv1alpha1.SyncTarget) Settings {
+	settings := Settings{QPS: o.QPS, Burst: o.Burst}
+	if target == nil || target.Spec.ClientRateLimit == nil {
+		return settings
+	}
+	if qps := target.Spec.ClientRateLimit.QPS; qps != nil && *qps > 0 {
+		settings.QPS = float32(*qps)
+	}
+	if burst := target.Spec.ClientRateLimit.Burst; burst != nil && *burst > 0 {
+		settings.Burst = int(*burst)
+	}
+	return settings
+}
+
+// Limiter is an adaptive client-side rate limiter for one SyncTarget.
+type Limiter struct {
+	syncTarget string
+	// floorQPS is the minimum rate of the options, minQPS that of the
+	// settings.
+	floorQPS         float32
+	recoveryInterval time.Duration
+	now              func() time.Time
+	newBucket        func(qps float32, burst int) flowcontrol.RateLimiter
+
+	lock          sync.Mutex
+	settings      Settings
+	minQPS        float32
+	qps           float32
+	bucket        flowcontrol.RateLimiter
+	lastThrottled time.Time
+	lastRecovered time.Time
+}
+
+// NewLimiter returns a limiter for the SyncTarget with the given settings.
+// syncTarget labels the metrics.
+func NewLimiter(syncTarget string, settings Settings, options *Options) *Limiter {
+	l := &Limiter{
+		syncTarget:       syncTarget,
+		floorQPS:         options.MinQPS,
+		settings:         settings,
+		minQPS:           min(options.MinQPS, settings.QPS),
+		recoveryInterval: options.RecoveryInterval,
+		now:              time.Now,
+		newBucket:        flowcontrol.NewTokenBucketRateLimiter,
+	}
+	l.setQPS(settings.QPS)
+	return l
+}
+
+var _ flowcontrol.RateLimiter = &Limiter{}
+
+// TryAccept returns true if a token is available immediately.
+func (l *Limiter) TryAccept() bool {
+	return l.current().TryAccept()
+}
+
+// Accept blocks until a token is available.
+func (l *Limiter) Accept() {
+	start := l.now()
+	l.current().Accept()
+	l.observeWait(start)
+}
+
+// Wait blocks until a token is available or ctx is done.
+func (l *Limiter) Wait(ctx context.Context) error {
+	start := l.now()
+	err := l.current().Wait(ctx)
+	l.observeWait(start)
+	return err
+}
+
+// Stop stops the limiter.
+func (l *Limiter) Stop() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.bucket.Stop()
+}
+
+// QPS returns the current rate.
+func (l *Limiter) QPS() float32 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.qps
+}
+
+// SetSettings changes the settings of the limiter, e.g. when
+// spec.clientRateLimit of the SyncTarget changed. A rate lowered by
+// throttling stays lowered unless the new rate is lower.
+func (l *Limiter) SetSettings(settings Settings) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if settings == l.settings {
+		return
+	}
+	qps := min(l.qps, settings.QPS)
+	if l.qps >= l.settings.QPS {
+		qps = settings.QPS
+	}
+	l.settings = settings
+	l.minQPS = min(l.floorQPS, settings.QPS)
+	l.setQPSLocked(qps)
+}
+
+// Throttled records a 429 answer of the physical cluster and halves the
+// rate, but at most once per second so that a burst of throttled requests
+// in flight counts once.
+func (l *Limiter) Throttled() {
+	throttledResponses.WithLabelValues(l.syncTarget).Inc()
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	now := l.now()
+	if now.Sub(l.lastThrottled) < time.Second {
+		return
+	}
+	l.lastThrottled = now
+	l.lastRecovered = now
+	if qps := max(l.qps/2, l.minQPS); qps != l.qps {
+		l.setQPSLocked(qps)
+	}
+}
+
+// current returns the token bucket, doubling the rate first if the
+// physical cluster did not throttle for a recovery interval.
+func (l *Limiter) current() flowcontrol.RateLimiter {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.qps < l.settings.QPS && l.now().Sub(l.lastRecovered) >= l.recoveryInterval {
+		l.lastRecovered = l.now()
+		l.setQPSLocked(min(2*l.qps, l.settings.QPS))
+	}
+	return l.bucket
+}
+
+func (l *Limiter) setQPS(qps float32) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.setQPSLocked(qps)
+}
+
+func (l *Limiter) setQPSLocked(qps float32) {
+	if l.bucket != nil {
+		l.bucket.Stop()
+		klog.Background().V(2).Info("changing rate limit towards physical cluster", "syncTarget", l.syncTarget, "qps", qps, "previous", l.qps)
+	}
+	l.qps = qps
+	l.bucket = l.newBucket(qps, l.settings.Burst)
+	currentQPS.WithLabelValues(l.syncTarget).Set(float64(qps))
+}
+
+func (l *Limiter) observeWait(start time.Time) {
+	if waited := l.now().Sub(start); waited > 0 {
+		throttleSeconds.WithLabelValues(l.syncTarget).Add(waited.Seconds())
+	}
+}
+
+// WrapTransport returns a round tripper reporting 429 answers to l.
+func (l *Limiter) WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &roundTripper{limiter: l, delegate: rt}
+}
+
+type roundTripper struct {
+	limiter  *Limiter
+	delegate http.RoundTripper
+}
+
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.delegate.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		rt.limiter.Throttled()
+	}
+	return resp, err
+}
+
+// Configure returns a copy of config for the physical cluster of the
+// SyncTarget, limited by an adaptive limiter with settings. The limiter is
+// returned to be stopped when the syncer of the target stops.
+func Configure(config *rest.Config, syncTarget string, settings Settings, options *Options) (*rest.Config, *Limiter) {
+	limiter := NewLimiter(syncTarget, settings, options)
+	config = rest.CopyConfig(config)
+	config.QPS = settings.QPS
+	config.Burst = settings.Burst
+	config.RateLimiter = limiter
+	config.Wrap(limiter.WrapTransport)
+	return config, limiter
+}
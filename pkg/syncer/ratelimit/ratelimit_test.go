@@ -0,0 +1,122 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func TestFor(t *testing.T) {
+	o := NewOptions()
+	require.Equal(t, Settings{QPS: defaultQPS, Burst: defaultBurst}, o.For(&tmcv1alpha1.SyncTarget{}))
+
+	target := &tmcv1alpha1.SyncTarget{Spec: tmcv1alpha1.SyncTargetSpec{
+		ClientRateLimit: &tmcv1alpha1.ClientRateLimit{QPS: ptr.To[int32](5)},
+	}}
+	require.Equal(t, Settings{QPS: 5, Burst: defaultBurst}, o.For(target))
+
+	target.Spec.ClientRateLimit.Burst = ptr.To[int32](7)
+	require.Equal(t, Settings{QPS: 5, Burst: 7}, o.For(target))
+}
+
+func TestLimiterAdapts(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	o := NewOptions()
+	o.MinQPS = 3
+	l := NewLimiter("root:org:edge-1", Settings{QPS: 16, Burst: 20}, o)
+	l.now = func() time.Time { return now }
+	l.lastRecovered = now
+	require.Equal(t, float32(16), l.QPS())
+
+	l.Throttled()
+	require.Equal(t, float32(8), l.QPS())
+	l.Throttled()
+	require.Equal(t, float32(8), l.QPS(), "throttled answers within a second count once")
+
+	now = now.Add(time.Second)
+	l.Throttled()
+	require.Equal(t, float32(4), l.QPS())
+	now = now.Add(time.Second)
+	l.Throttled()
+	require.Equal(t, float32(3), l.QPS(), "rate must not drop below the minimum")
+
+	now = now.Add(o.RecoveryInterval - time.Second)
+	require.True(t, l.TryAccept())
+	require.Equal(t, float32(3), l.QPS(), "no recovery before the interval passed")
+
+	now = now.Add(time.Second)
+	l.TryAccept()
+	require.Equal(t, float32(6), l.QPS())
+	now = now.Add(o.RecoveryInterval)
+	l.TryAccept()
+	require.Equal(t, float32(12), l.QPS())
+	now = now.Add(o.RecoveryInterval)
+	l.TryAccept()
+	require.Equal(t, float32(16), l.QPS(), "rate must not recover beyond the settings")
+	l.Stop()
+}
+
+func TestLimiterSetSettings(t *testing.T) {
+	l := NewLimiter("root:org:edge-1", Settings{QPS: 16, Burst: 20}, NewOptions())
+	defer l.Stop()
+	l.SetSettings(Settings{QPS: 32, Burst: 40})
+	require.Equal(t, float32(32), l.QPS(), "an unthrottled rate follows the settings")
+
+	l.Throttled()
+	require.Equal(t, float32(16), l.QPS())
+	l.SetSettings(Settings{QPS: 64, Burst: 40})
+	require.Equal(t, float32(16), l.QPS(), "a throttled rate stays lowered")
+	l.SetSettings(Settings{QPS: 8, Burst: 40})
+	require.Equal(t, float32(8), l.QPS(), "a throttled rate is lowered to a lower setting")
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestConfigure(t *testing.T) {
+	config, l := Configure(&rest.Config{Host: "https://edge-1"}, "root:org:edge-1", Settings{QPS: 10, Burst: 15}, NewOptions())
+	defer l.Stop()
+	require.Equal(t, float32(10), config.QPS)
+	require.Equal(t, 15, config.Burst)
+	require.Same(t, l, config.RateLimiter)
+
+	status := http.StatusOK
+	rt := config.WrapTransport(roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: status}, nil
+	}))
+	req, err := http.NewRequest(http.MethodGet, "https://edge-1/api", nil)
+	require.NoError(t, err)
+
+	_, err = rt.RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, float32(10), l.QPS())
+
+	status = http.StatusTooManyRequests
+	_, err = rt.RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, float32(5), l.QPS())
+}
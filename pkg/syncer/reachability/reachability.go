@@ -0,0 +1,148 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package reachability probes the endpoints configured in the
+// reachabilityProbes of a SyncTarget from the physical cluster, e.g. image
+// registries, databases or other targets, and reports in the status of the
+// SyncTarget which of them are reachable and how long they took to answer.
+// The placement engine penalizes targets that cannot reach the endpoints a
+// policy requires.
+package reachability
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// DefaultTimeout bounds probing a single endpoint.
+const DefaultTimeout = 5 * time.Second
+
+// Prober probes endpoints.
+type Prober struct {
+	timeout time.Duration
+	now     func() time.Time
+	dial    func(ctx context.Context, network, address string) (net.Conn, error)
+	client  *http.Client
+}
+
+// NewProber returns a prober giving up on endpoints after timeout, or after
+// DefaultTimeout if it is zero.
+func NewProber(timeout time.Duration) *Prober {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	dialer := &net.Dialer{}
+	return &Prober{
+		timeout: timeout,
+		now:     time.Now,
+		dial:    dialer.DialContext,
+		client: &http.Client{
+			// A redirect is an answer of the endpoint.
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+	}
+}
+
+// Probe probes the endpoints in parallel and returns the results sorted by
+// name.
+func (p *Prober) Probe(ctx context.Context, probes []tmcv1alpha1.ReachabilityProbe) []tmcv1alpha1.EndpointReachability {
+	results := make([]tmcv1alpha1.EndpointReachability, len(probes))
+	var wg sync.WaitGroup
+	for i, probe := range probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = p.probe(ctx, probe)
+		}()
+	}
+	wg.Wait()
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	return results
+}
+
+func (p *Prober) probe(ctx context.Context, probe tmcv1alpha1.ReachabilityProbe) tmcv1alpha1.EndpointReachability {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	result := tmcv1alpha1.EndpointReachability{Name: probe.Name, LastProbeTime: metav1.NewTime(p.now())}
+	start := time.Now()
+	var err error
+	if strings.HasPrefix(probe.Address, "http://") || strings.HasPrefix(probe.Address, "https://") {
+		err = p.get(ctx, probe.Address)
+	} else {
+		err = p.connect(ctx, probe.Address)
+	}
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	latency := time.Since(start).Milliseconds()
+	result.Reachable = true
+	result.LatencyMilliseconds = &latency
+	return result
+}
+
+func (p *Prober) connect(ctx context.Context, address string) error {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return fmt.Errorf("invalid address %q, expected host:port or an http or https URL", address)
+	}
+	conn, err := p.dial(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func (p *Prober) get(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// SetStatus reports the results in the status of the SyncTarget, dropping
+// results of endpoints no longer probed.
+func SetStatus(target *tmcv1alpha1.SyncTarget, results []tmcv1alpha1.EndpointReachability) {
+	probed := map[string]bool{}
+	for _, probe := range target.Spec.ReachabilityProbes {
+		probed[probe.Name] = true
+	}
+	reachability := make([]tmcv1alpha1.EndpointReachability, 0, len(results))
+	for _, r := range results {
+		if probed[r.Name] {
+			reachability = append(reachability, r)
+		}
+	}
+	if len(reachability) == 0 {
+		reachability = nil
+	}
+	target.Status.Reachability = reachability
+}
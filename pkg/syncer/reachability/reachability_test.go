@@ -0,0 +1,73 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reachability
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func TestProbe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closed := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	results := NewProber(0).Probe(context.Background(), []tmcv1alpha1.ReachabilityProbe{
+		{Name: "registry", Address: server.URL},
+		{Name: "database", Address: server.Listener.Addr().String()},
+		{Name: "closed", Address: closed},
+		{Name: "invalid", Address: "database"},
+	})
+	require.Len(t, results, 4)
+
+	byName := map[string]tmcv1alpha1.EndpointReachability{}
+	for _, r := range results {
+		byName[r.Name] = r
+		require.False(t, r.LastProbeTime.IsZero())
+	}
+	require.Equal(t, []string{"closed", "database", "invalid", "registry"}, []string{results[0].Name, results[1].Name, results[2].Name, results[3].Name})
+	require.True(t, byName["registry"].Reachable, "any HTTP response counts as reachable")
+	require.NotNil(t, byName["registry"].LatencyMilliseconds)
+	require.True(t, byName["database"].Reachable)
+	require.False(t, byName["closed"].Reachable)
+	require.Nil(t, byName["closed"].LatencyMilliseconds)
+	require.NotEmpty(t, byName["closed"].Message)
+	require.Equal(t, `invalid address "database", expected host:port or an http or https URL`, byName["invalid"].Message)
+}
+
+func TestSetStatus(t *testing.T) {
+	target := &tmcv1alpha1.SyncTarget{Spec: tmcv1alpha1.SyncTargetSpec{ReachabilityProbes: []tmcv1alpha1.ReachabilityProbe{{Name: "registry", Address: "registry:443"}}}}
+	SetStatus(target, []tmcv1alpha1.EndpointReachability{{Name: "registry", Reachable: true}, {Name: "removed"}})
+	require.Equal(t, []tmcv1alpha1.EndpointReachability{{Name: "registry", Reachable: true}}, target.Status.Reachability)
+
+	target.Spec.ReachabilityProbes = nil
+	SetStatus(target, nil)
+	require.Nil(t, target.Status.Reachability)
+}
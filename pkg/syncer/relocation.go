@@ -0,0 +1,58 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/relocation"
+)
+
+// workspacePath returns the path of the workspace of the SyncTarget
+// recorded on downstream objects. It follows moves of the workspace, see
+// package relocation.
+func (s *syncer) workspacePath() logicalcluster.Path {
+	if s.relocations == nil {
+		return s.target.Path
+	}
+	return s.relocations.Path(s.target)
+}
+
+// followWorkspace checks every interval whether the workspace of the
+// SyncTarget moved, and relabels the downstream objects of the resources
+// with its new path if so, until ctx is done.
+func (s *syncer) followWorkspace(ctx context.Context, interval time.Duration, resources func() []schema.GroupVersionResource) {
+	relabeler := relocation.NewRelabeler(s.downstream)
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		gvrs := resources()
+		if !slices.Contains(gvrs, namespacesGVR) {
+			gvrs = append(gvrs, namespacesGVR)
+		}
+		if _, err := s.relocations.Follow(ctx, s.target, relabeler, gvrs); err != nil {
+			utilruntime.HandleError(fmt.Errorf("failed to follow the workspace of SyncTarget %s: %w", s.target, err))
+		}
+	}, interval)
+}
@@ -0,0 +1,264 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package relocation keeps syncers running when the workspace of their
+// SyncTarget is moved. Syncers are configured with workspace paths, which
+// change when a workspace is moved in the hierarchy, while the logical
+// cluster of the workspace, which placements, downstream names and the
+// recorded upstream identity of downstream objects are keyed by, does not.
+// The handler resolves the path of a target to its logical cluster once,
+// so that the syncer addresses the workspace by its logical cluster, and
+// follows the path of the logical cluster. When it changes, the new path
+// is recorded and downstream objects are relabeled with it, without
+// placing or syncing anything again.
+package relocation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/multitarget"
+	"github.com/kcp-dev/kcp/pkg/syncer/naming"
+	"github.com/kcp-dev/kcp/sdk/apis/core"
+	corev1alpha1 "github.com/kcp-dev/kcp/sdk/apis/core/v1alpha1"
+	kcpclientset "github.com/kcp-dev/kcp/sdk/client/clientset/versioned/cluster"
+)
+
+const defaultInterval = time.Minute
+
+// Options configure how often workspaces are checked for moves.
+type Options struct {
+	// Interval between checks of the path of the workspace of a target.
+	Interval time.Duration
+}
+
+// NewOptions returns the default options.
+func NewOptions() *Options {
+	return &Options{
+		Interval: defaultInterval,
+	}
+}
+
+// AddFlags adds the relocation flags to the flag set.
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&o.Interval, "workspace-relocation-interval", o.Interval, "Interval between checks whether the workspace of a SyncTarget was moved.")
+}
+
+// Validate validates the options.
+func (o *Options) Validate() error {
+	if o.Interval <= 0 {
+		return fmt.Errorf("--workspace-relocation-interval must be positive")
+	}
+	return nil
+}
+
+// Relocation is a move of the workspace of a target.
+type Relocation struct {
+	Target  multitarget.Target
+	Cluster logicalcluster.Name
+	From    logicalcluster.Path
+	To      logicalcluster.Path
+}
+
+type location struct {
+	cluster logicalcluster.Name
+	path    logicalcluster.Path
+}
+
+// Handler follows the workspaces of targets. It is shared by the syncers
+// of the process, so that targets whose workspace was moved keep resolving
+// when their syncer restarts.
+type Handler struct {
+	getLogicalCluster func(ctx context.Context, path logicalcluster.Path) (*corev1alpha1.LogicalCluster, error)
+
+	lock      sync.Mutex
+	locations map[multitarget.Target]*location
+}
+
+// NewHandler returns a handler that looks up workspaces with the kcp
+// client.
+func NewHandler(kcpClusterClient kcpclientset.ClusterInterface) *Handler {
+	return &Handler{
+		getLogicalCluster: func(ctx context.Context, path logicalcluster.Path) (*corev1alpha1.LogicalCluster, error) {
+			return kcpClusterClient.Cluster(path).CoreV1alpha1().LogicalClusters().Get(ctx, corev1alpha1.LogicalClusterName, metav1.GetOptions{})
+		},
+		locations: map[multitarget.Target]*location{},
+	}
+}
+
+// Resolve returns the target addressed by the logical cluster of its
+// workspace. The path of the target is only resolved the first time, so
+// that targets whose workspace was moved keep resolving.
+func (h *Handler) Resolve(ctx context.Context, target multitarget.Target) (multitarget.Target, error) {
+	h.lock.Lock()
+	known, found := h.locations[target]
+	h.lock.Unlock()
+	if found {
+		return multitarget.Target{Path: known.cluster.Path(), Name: target.Name}, nil
+	}
+
+	lc, err := h.getLogicalCluster(ctx, target.Path)
+	if err != nil {
+		return multitarget.Target{}, fmt.Errorf("failed to resolve the workspace of SyncTarget %s: %w", target, err)
+	}
+	cluster := logicalcluster.From(lc)
+	if cluster.Empty() {
+		return multitarget.Target{}, fmt.Errorf("workspace %s of SyncTarget %s has no logical cluster", target.Path, target)
+	}
+	h.lock.Lock()
+	h.locations[target] = &location{cluster: cluster, path: pathOf(lc, target.Path)}
+	h.lock.Unlock()
+	return multitarget.Target{Path: cluster.Path(), Name: target.Name}, nil
+}
+
+// Check returns the relocation of the workspace of the target since the
+// last check, or nil if it did not move. The target must be resolved.
+func (h *Handler) Check(ctx context.Context, target multitarget.Target) (*Relocation, error) {
+	h.lock.Lock()
+	known, found := h.locations[target]
+	h.lock.Unlock()
+	if !found {
+		return nil, fmt.Errorf("SyncTarget %s is not resolved", target)
+	}
+
+	lc, err := h.getLogicalCluster(ctx, known.cluster.Path())
+	if err != nil {
+		return nil, err
+	}
+	path := pathOf(lc, known.path)
+	if path == known.path {
+		return nil, nil
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	relocation := &Relocation{Target: target, Cluster: known.cluster, From: known.path, To: path}
+	h.locations[target] = &location{cluster: known.cluster, path: path}
+	return relocation, nil
+}
+
+// Path returns the current path of the workspace of the target, or its
+// configured path if it is not resolved.
+func (h *Handler) Path(target multitarget.Target) logicalcluster.Path {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if known, found := h.locations[target]; found {
+		return known.path
+	}
+	return target.Path
+}
+
+// Follow checks the workspace of the target and relabels the downstream
+// objects of the resources if it moved. It returns the relocation, or nil
+// if the workspace did not move. A failed relabeling is retried on the
+// next check.
+func (h *Handler) Follow(ctx context.Context, target multitarget.Target, relabeler *Relabeler, resources []schema.GroupVersionResource) (*Relocation, error) {
+	relocation, err := h.Check(ctx, target)
+	if err != nil || relocation == nil {
+		return nil, err
+	}
+	logger := klog.FromContext(ctx).WithValues("cluster", relocation.Cluster, "from", relocation.From, "to", relocation.To)
+	logger.Info("workspace of SyncTarget moved, relabeling downstream objects")
+	relocations.WithLabelValues(target.String()).Inc()
+
+	relabeled, err := relabeler.Relabel(ctx, resources, *relocation)
+	if err != nil {
+		// Check again next time, from the old path.
+		h.lock.Lock()
+		h.locations[target] = &location{cluster: relocation.Cluster, path: relocation.From}
+		h.lock.Unlock()
+		return nil, fmt.Errorf("failed to relabel downstream objects of logical cluster %s: %w", relocation.Cluster, err)
+	}
+	logger.V(2).Info("relabeled downstream objects", "count", relabeled)
+	return relocation, nil
+}
+
+func pathOf(lc *corev1alpha1.LogicalCluster, fallback logicalcluster.Path) logicalcluster.Path {
+	if path, found := lc.Annotations[core.LogicalClusterPathAnnotationKey]; found && path != "" {
+		return logicalcluster.NewPath(path)
+	}
+	return fallback
+}
+
+// Relabeler rewrites the recorded upstream path of downstream objects.
+type Relabeler struct {
+	list  func(ctx context.Context, gvr schema.GroupVersionResource) ([]unstructured.Unstructured, error)
+	patch func(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string, patch []byte) error
+}
+
+// NewRelabeler returns a relabeler for the physical cluster of client.
+func NewRelabeler(client dynamic.Interface) *Relabeler {
+	return &Relabeler{
+		list: func(ctx context.Context, gvr schema.GroupVersionResource) ([]unstructured.Unstructured, error) {
+			list, err := client.Resource(gvr).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			return list.Items, nil
+		},
+		patch: func(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string, patch []byte) error {
+			_, err := client.Resource(gvr).Namespace(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+			return err
+		},
+	}
+}
+
+// Relabel records the new path on the downstream objects of the resources
+// that come from the moved logical cluster. Objects without a recorded path
+// are left alone. It returns the number of relabeled objects.
+func (r *Relabeler) Relabel(ctx context.Context, resources []schema.GroupVersionResource, relocation Relocation) (int, error) {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{naming.AnnotationUpstreamPath: relocation.To.String()},
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	relabeled := 0
+	for _, gvr := range resources {
+		objs, err := r.list(ctx, gvr)
+		if err != nil {
+			return relabeled, fmt.Errorf("failed to list %s: %w", gvr.GroupResource(), err)
+		}
+		for i := range objs {
+			id, found := naming.UpstreamOf(&objs[i])
+			if !found || id.Workspace != relocation.Cluster || id.Path.Empty() || id.Path == relocation.To {
+				continue
+			}
+			if err := r.patch(ctx, gvr, objs[i].GetNamespace(), objs[i].GetName(), patch); err != nil {
+				return relabeled, fmt.Errorf("failed to relabel %s %s: %w", gvr.GroupResource(), objs[i].GetName(), err)
+			}
+			relabeled++
+		}
+	}
+	return relabeled, nil
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package relocation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/multitarget"
+	"github.com/kcp-dev/kcp/pkg/syncer/naming"
+	"github.com/kcp-dev/kcp/sdk/apis/core"
+	corev1alpha1 "github.com/kcp-dev/kcp/sdk/apis/core/v1alpha1"
+)
+
+var configMaps = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+// fakeWorkspaces serves the logical cluster of a workspace by its logical
+// cluster name and by its current path only.
+type fakeWorkspaces struct {
+	cluster logicalcluster.Name
+	path    string
+}
+
+func (f *fakeWorkspaces) get(_ context.Context, path logicalcluster.Path) (*corev1alpha1.LogicalCluster, error) {
+	if path.String() != f.path && path != f.cluster.Path() {
+		return nil, errors.New("not found")
+	}
+	return &corev1alpha1.LogicalCluster{ObjectMeta: metav1.ObjectMeta{
+		Name: corev1alpha1.LogicalClusterName,
+		Annotations: map[string]string{
+			logicalcluster.AnnotationKey:         f.cluster.String(),
+			core.LogicalClusterPathAnnotationKey: f.path,
+		},
+	}}, nil
+}
+
+func downstreamObject(name string, id naming.Identity) unstructured.Unstructured {
+	u := unstructured.Unstructured{}
+	u.SetName(name)
+	u.SetNamespace("default")
+	naming.SetUpstream(&u, id)
+	return u
+}
+
+func TestHandler(t *testing.T) {
+	ctx := context.Background()
+	workspaces := &fakeWorkspaces{cluster: "2v7ac4kj0nl5jzpm", path: "root:org:team"}
+	h := NewHandler(nil)
+	h.getLogicalCluster = workspaces.get
+
+	target := multitarget.Target{Path: logicalcluster.NewPath("root:org:team"), Name: "edge"}
+	_, err := h.Check(ctx, target)
+	require.Error(t, err, "targets must be resolved first")
+
+	resolved, err := h.Resolve(ctx, target)
+	require.NoError(t, err)
+	require.Equal(t, multitarget.Target{Path: logicalcluster.NewPath("2v7ac4kj0nl5jzpm"), Name: "edge"}, resolved)
+
+	relocation, err := h.Check(ctx, target)
+	require.NoError(t, err)
+	require.Nil(t, relocation)
+
+	// The workspace moves: the old path stops resolving, the target keeps
+	// resolving by its logical cluster.
+	workspaces.path = "root:other:team"
+	resolved, err = h.Resolve(ctx, target)
+	require.NoError(t, err)
+	require.Equal(t, "2v7ac4kj0nl5jzpm", resolved.Path.String())
+
+	relocation, err = h.Check(ctx, target)
+	require.NoError(t, err)
+	require.Equal(t, &Relocation{
+		Target:  target,
+		Cluster: "2v7ac4kj0nl5jzpm",
+		From:    logicalcluster.NewPath("root:org:team"),
+		To:      logicalcluster.NewPath("root:other:team"),
+	}, relocation)
+	require.Equal(t, "root:other:team", h.Path(target).String())
+
+	relocation, err = h.Check(ctx, target)
+	require.NoError(t, err)
+	require.Nil(t, relocation, "a move is reported once")
+
+	_, err = h.Resolve(ctx, multitarget.Target{Path: logicalcluster.NewPath("root:missing"), Name: "edge"})
+	require.Error(t, err)
+}
+
+func TestFollow(t *testing.T) {
+	ctx := context.Background()
+	workspaces := &fakeWorkspaces{cluster: "2v7ac4kj0nl5jzpm", path: "root:org:team"}
+	h := NewHandler(nil)
+	h.getLogicalCluster = workspaces.get
+
+	objs := []unstructured.Unstructured{
+		downstreamObject("moved", naming.Identity{Workspace: "2v7ac4kj0nl5jzpm", Path: logicalcluster.NewPath("root:org:team"), Name: "moved"}),
+		downstreamObject("other-workspace", naming.Identity{Workspace: "1qxyz", Path: logicalcluster.NewPath("root:org:team"), Name: "other"}),
+		downstreamObject("without-path", naming.Identity{Workspace: "2v7ac4kj0nl5jzpm", Name: "without-path"}),
+		{Object: map[string]interface{}{"metadata": map[string]interface{}{"name": "unmanaged"}}},
+	}
+	var patched []string
+	failPatch := true
+	r := &Relabeler{
+		list: func(_ context.Context, gvr schema.GroupVersionResource) ([]unstructured.Unstructured, error) {
+			require.Equal(t, configMaps, gvr)
+			return objs, nil
+		},
+		patch: func(_ context.Context, _ schema.GroupVersionResource, namespace, name string, patch []byte) error {
+			if failPatch {
+				return errors.New("conflict")
+			}
+			patched = append(patched, namespace+"/"+name)
+			require.JSONEq(t, `{"metadata":{"annotations":{"tmc.kcp.io/upstream-path":"root:other:team"}}}`, string(patch))
+			return nil
+		},
+	}
+
+	target := multitarget.Target{Path: logicalcluster.NewPath("root:org:team"), Name: "edge"}
+	_, err := h.Resolve(ctx, target)
+	require.NoError(t, err)
+	resources := []schema.GroupVersionResource{configMaps}
+	relocation, err := h.Follow(ctx, target, r, resources)
+	require.NoError(t, err)
+	require.Nil(t, relocation)
+	require.Empty(t, patched)
+
+	workspaces.path = "root:other:team"
+	_, err = h.Follow(ctx, target, r, resources)
+	require.Error(t, err)
+	require.Equal(t, "root:org:team", h.Path(target).String(), "failed relabeling is retried")
+
+	failPatch = false
+	relocation, err = h.Follow(ctx, target, r, resources)
+	require.NoError(t, err)
+	require.Equal(t, logicalcluster.NewPath("root:other:team"), relocation.To)
+	require.Equal(t, []string{"default/moved"}, patched)
+	require.Equal(t, "root:other:team", h.Path(target).String())
+}
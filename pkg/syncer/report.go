@@ -0,0 +1,121 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/reachability"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// statusReporter sets what the syncer reports in the status of its
+// SyncTarget, see syncer.reportStatus.
+type statusReporter func(ctx context.Context, syncTarget *tmcv1alpha1.SyncTarget) error
+
+// reportStatus updates the status of the SyncTarget with the reporters of
+// the syncer every interval until ctx is done.
+func (s *syncer) reportStatus(ctx context.Context, interval time.Duration) {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		err := s.updateSyncTargetStatus(ctx, func(syncTarget *tmcv1alpha1.SyncTarget) error {
+			var errs []error
+			for _, report := range s.reporters {
+				// A failed reporter does not hold back the others.
+				if err := report(ctx, syncTarget); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			return utilerrors.NewAggregate(errs)
+		})
+		if err != nil {
+			utilruntime.HandleError(fmt.Errorf("failed to report the status of SyncTarget %s: %w", s.target, err))
+		}
+	}, interval)
+}
+
+// everyInterval returns a reporter calling report at most every interval,
+// for reports too expensive to be made every time the status is reported.
+// The status reported last is kept in between.
+func everyInterval(interval time.Duration, report statusReporter) statusReporter {
+	var last time.Time
+	return func(ctx context.Context, syncTarget *tmcv1alpha1.SyncTarget) error {
+		if !last.IsZero() && time.Since(last) < interval {
+			return nil
+		}
+		if err := report(ctx, syncTarget); err != nil {
+			return err
+		}
+		last = time.Now()
+		return nil
+	}
+}
+
+// reportReachability returns a reporter of the reachability of the
+// endpoints of spec.reachabilityProbes of the SyncTarget, see package
+// reachability. They are probed at most every interval, and when they
+// change.
+func reportReachability(prober *reachability.Prober, interval time.Duration) statusReporter {
+	var last time.Time
+	var probed []tmcv1alpha1.ReachabilityProbe
+	var results []tmcv1alpha1.EndpointReachability
+	return func(ctx context.Context, syncTarget *tmcv1alpha1.SyncTarget) error {
+		probes := syncTarget.Spec.ReachabilityProbes
+		if last.IsZero() || time.Since(last) >= interval || !equality.Semantic.DeepEqual(probes, probed) {
+			results = prober.Probe(ctx, probes)
+			probed, last = probes, time.Now()
+		}
+		reachability.SetStatus(syncTarget, results)
+		return nil
+	}
+}
+
+// updateSyncTargetStatus reads the SyncTarget, changes its status with
+// mutate and writes it back if it changed, even if mutate fails. A
+// SyncTarget that is gone needs no update.
+func (s *syncer) updateSyncTargetStatus(ctx context.Context, mutate func(syncTarget *tmcv1alpha1.SyncTarget) error) error {
+	syncTarget, err := getSyncTarget(ctx, s.upstream, s.target.Name)
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	updated := syncTarget.DeepCopy()
+	mutateErr := mutate(updated)
+	if equality.Semantic.DeepEqual(syncTarget.Status, updated.Status) {
+		return mutateErr
+	}
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(updated)
+	if err != nil {
+		return err
+	}
+	_, err = s.upstream.Resource(syncTargetsGVR).UpdateStatus(ctx, &unstructured.Unstructured{Object: u}, metav1.UpdateOptions{})
+	if apierrors.IsNotFound(err) {
+		err = nil
+	}
+	return utilerrors.NewAggregate([]error{mutateErr, err})
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var schedulingDefaultsGVR = workloadv1alpha1.SchemeGroupVersion.WithResource("schedulingdefaults")
+
+// workspaceSchedulingDefaults returns the SchedulingDefaults of the
+// workspace of the SyncTarget.
+func (s *syncer) workspaceSchedulingDefaults(ctx context.Context) ([]*workloadv1alpha1.SchedulingDefaults, error) {
+	list, err := s.upstream.Resource(schedulingDefaultsGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defaults := make([]*workloadv1alpha1.SchedulingDefaults, 0, len(list.Items))
+	for i := range list.Items {
+		d := &workloadv1alpha1.SchedulingDefaults{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(list.Items[i].Object, d); err != nil {
+			return nil, err
+		}
+		defaults = append(defaults, d)
+	}
+	return defaults, nil
+}
+
+// setSchedulingDefaults records the SchedulingDefaults last read. If they
+// changed, the objects running pods are synced again, as the tolerations
+// and node selectors of their pods may change, see package
+// schedulingdefaults.
+func (s *syncer) setSchedulingDefaults(defaults []*workloadv1alpha1.SchedulingDefaults) {
+	previous := s.schedulingDefaults.Swap(&defaults)
+	if previous == nil || equality.Semantic.DeepEqual(schedulingDefaultsSpecs(*previous), schedulingDefaultsSpecs(defaults)) {
+		return
+	}
+	s.controllers.Range(func(_, value interface{}) bool {
+		c := value.(*controller)
+		if _, found := podSpecPaths[c.kind]; found {
+			for _, key := range c.upstreamInformer.GetStore().ListKeys() {
+				c.queue.Add(key)
+			}
+		}
+		return true
+	})
+}
+
+func schedulingDefaultsSpecs(defaults []*workloadv1alpha1.SchedulingDefaults) map[string]workloadv1alpha1.SchedulingDefaultsSpec {
+	specs := make(map[string]workloadv1alpha1.SchedulingDefaultsSpec, len(defaults))
+	for _, d := range defaults {
+		specs[d.Name] = d.Spec
+	}
+	return specs
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package schedulingdefaults injects the tolerations and node selectors of
+// the SchedulingDefaults of a workspace into the pod specs of the downstream
+// copies of its workloads. Defaults are applied after labels and
+// annotations are propagated, so that object selectors see the labels of
+// the workspace object.
+package schedulingdefaults
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/fanout"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// podSpecPaths are the paths of the pod spec in kinds running pods.
+var podSpecPaths = map[schema.GroupKind][]string{
+	{Kind: "Pod"}:                        {"spec"},
+	{Group: "apps", Kind: "Deployment"}:  {"spec", "template", "spec"},
+	{Group: "apps", Kind: "ReplicaSet"}:  {"spec", "template", "spec"},
+	{Group: "apps", Kind: "StatefulSet"}: {"spec", "template", "spec"},
+	{Group: "apps", Kind: "DaemonSet"}:   {"spec", "template", "spec"},
+	{Group: "batch", Kind: "Job"}:        {"spec", "template", "spec"},
+	{Group: "batch", Kind: "CronJob"}:    {"spec", "jobTemplate", "spec", "template", "spec"},
+	{Kind: "ReplicationController"}:      {"spec", "template", "spec"},
+}
+
+// Selects returns whether defaults apply to obj synced to syncTarget.
+func Selects(defaults *workloadv1alpha1.SchedulingDefaults, obj *unstructured.Unstructured, syncTarget *tmcv1alpha1.SyncTarget) (bool, error) {
+	for _, s := range []struct {
+		field    string
+		selector *metav1.LabelSelector
+		labels   map[string]string
+	}{
+		{"object", defaults.Spec.ObjectSelector, obj.GetLabels()},
+		{"target", defaults.Spec.TargetSelector, syncTarget.Labels},
+	} {
+		if s.selector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(s.selector)
+		if err != nil {
+			return false, fmt.Errorf("invalid %s selector of SchedulingDefaults %q: %w", s.field, defaults.Name, err)
+		}
+		if !selector.Matches(labels.Set(s.labels)) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Apply returns a copy of obj, the downstream copy of a workload on
+// syncTarget, with the tolerations and node selector of the defaults
+// selecting it injected into its pod spec. Defaults are applied in the
+// order of their names, each to the result of the previous one. Objects
+// without a pod spec are returned as they are. It fails if defaults are
+// invalid, or reference metadata the target does not have, in which case
+// obj must not be synced to it.
+func Apply(obj *unstructured.Unstructured, defaults []*workloadv1alpha1.SchedulingDefaults, syncTarget *tmcv1alpha1.SyncTarget) (*unstructured.Unstructured, error) {
+	path, found := podSpecPaths[obj.GroupVersionKind().GroupKind()]
+	if !found {
+		return obj, nil
+	}
+	var selected []*workloadv1alpha1.SchedulingDefaults
+	for _, d := range defaults {
+		ok, err := Selects(d, obj, syncTarget)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			selected = append(selected, d)
+		}
+	}
+	if len(selected) == 0 {
+		return obj, nil
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].Name < selected[j].Name
+	})
+
+	spec, found, err := unstructured.NestedMap(obj.Object, path...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pod spec: %w", err)
+	}
+	if !found {
+		return obj, nil
+	}
+	nodeSelector, _, err := unstructured.NestedStringMap(spec, "nodeSelector")
+	if err != nil {
+		return nil, fmt.Errorf("invalid node selector: %w", err)
+	}
+	tolerations, err := tolerationsOf(spec)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range selected {
+		override := d.Spec.Precedence == workloadv1alpha1.SchedulingDefaultsPrecedenceDefaults
+		if nodeSelector, err = mergeNodeSelector(nodeSelector, d.Spec.NodeSelector, override, syncTarget); err != nil {
+			return nil, fmt.Errorf("SchedulingDefaults %q: %w", d.Name, err)
+		}
+		tolerations = mergeTolerations(tolerations, d.Spec.Tolerations, override)
+	}
+
+	if len(nodeSelector) > 0 {
+		if err := unstructured.SetNestedStringMap(spec, nodeSelector, "nodeSelector"); err != nil {
+			return nil, err
+		}
+	}
+	if len(tolerations) > 0 {
+		items := make([]interface{}, 0, len(tolerations))
+		for i := range tolerations {
+			item, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&tolerations[i])
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		spec["tolerations"] = items
+	}
+	injected := obj.DeepCopy()
+	if err := unstructured.SetNestedMap(injected.Object, spec, path...); err != nil {
+		return nil, err
+	}
+	return injected, nil
+}
+
+func tolerationsOf(spec map[string]interface{}) ([]corev1.Toleration, error) {
+	items, _, err := unstructured.NestedSlice(spec, "tolerations")
+	if err != nil {
+		return nil, fmt.Errorf("invalid tolerations: %w", err)
+	}
+	tolerations := make([]corev1.Toleration, 0, len(items))
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid toleration %v", item)
+		}
+		var t corev1.Toleration
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(m, &t); err != nil {
+			return nil, fmt.Errorf("invalid toleration: %w", err)
+		}
+		tolerations = append(tolerations, t)
+	}
+	return tolerations, nil
+}
+
+// mergeNodeSelector adds the node selector of defaults to the one of a
+// workload, overriding the values of the workload if override is set.
+func mergeNodeSelector(workload, defaults map[string]string, override bool, syncTarget *tmcv1alpha1.SyncTarget) (map[string]string, error) {
+	out := make(map[string]string, len(workload)+len(defaults))
+	for key, value := range workload {
+		out[key] = value
+	}
+	for key, value := range defaults {
+		if _, found := out[key]; found && !override {
+			continue
+		}
+		expanded, err := fanout.Expand(value, syncTarget)
+		if err != nil {
+			return nil, fmt.Errorf("node selector %q: %w", key, err)
+		}
+		out[key] = expanded
+	}
+	return out, nil
+}
+
+// mergeTolerations adds the tolerations of defaults to the ones of a
+// workload. A toleration with the key and effect of one of the workload
+// replaces it only if override is set.
+func mergeTolerations(workload, defaults []corev1.Toleration, override bool) []corev1.Toleration {
+	out := slices.Clone(workload)
+	for _, d := range defaults {
+		i := slices.IndexFunc(out, func(t corev1.Toleration) bool {
+			return t.Key == d.Key && t.Effect == d.Effect
+		})
+		switch {
+		case i < 0:
+			out = append(out, d)
+		case override:
+			out[i] = d
+		}
+	}
+	return out
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedulingdefaults
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+func newDeployment() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"namespace": "default", "name": "web", "labels": map[string]interface{}{"tier": "frontend"}},
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+			"containers":   []interface{}{map[string]interface{}{"name": "web", "image": "nginx"}},
+			"nodeSelector": map[string]interface{}{"pool": "general"},
+			"tolerations": []interface{}{
+				map[string]interface{}{"key": "edge", "operator": "Equal", "value": "workload", "effect": "NoSchedule"},
+			},
+		}}},
+	}}
+}
+
+func newDefaults(name string, precedence workloadv1alpha1.SchedulingDefaultsPrecedence) *workloadv1alpha1.SchedulingDefaults {
+	return &workloadv1alpha1.SchedulingDefaults{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: workloadv1alpha1.SchedulingDefaultsSpec{
+			NodeSelector: map[string]string{"pool": "dedicated", "zone": "${target.labels.zone}"},
+			Tolerations: []corev1.Toleration{
+				{Key: "edge", Operator: corev1.TolerationOpEqual, Value: "defaults", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "gpu", Operator: corev1.TolerationOpExists},
+			},
+			Precedence: precedence,
+		},
+	}
+}
+
+var edgeTarget = &tmcv1alpha1.SyncTarget{ObjectMeta: metav1.ObjectMeta{Name: "edge-1", Labels: map[string]string{"zone": "a", "edge": "true"}}}
+
+func podSpec(t *testing.T, obj *unstructured.Unstructured) (map[string]string, []interface{}) {
+	t.Helper()
+	nodeSelector, _, err := unstructured.NestedStringMap(obj.Object, "spec", "template", "spec", "nodeSelector")
+	require.NoError(t, err)
+	tolerations, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "tolerations")
+	require.NoError(t, err)
+	return nodeSelector, tolerations
+}
+
+func TestApplyWorkloadPrecedence(t *testing.T) {
+	obj := newDeployment()
+	injected, err := Apply(obj, []*workloadv1alpha1.SchedulingDefaults{newDefaults("edge", "")}, edgeTarget)
+	require.NoError(t, err)
+
+	nodeSelector, tolerations := podSpec(t, injected)
+	require.Equal(t, map[string]string{"pool": "general", "zone": "a"}, nodeSelector)
+	require.Equal(t, []interface{}{
+		map[string]interface{}{"key": "edge", "operator": "Equal", "value": "workload", "effect": "NoSchedule"},
+		map[string]interface{}{"key": "gpu", "operator": "Exists"},
+	}, tolerations)
+
+	nodeSelector, _ = podSpec(t, obj)
+	require.Equal(t, map[string]string{"pool": "general"}, nodeSelector, "obj is not modified")
+}
+
+func TestApplyDefaultsPrecedence(t *testing.T) {
+	injected, err := Apply(newDeployment(), []*workloadv1alpha1.SchedulingDefaults{
+		newDefaults("b-enforce", workloadv1alpha1.SchedulingDefaultsPrecedenceDefaults),
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "a-spot"},
+			Spec:       workloadv1alpha1.SchedulingDefaultsSpec{NodeSelector: map[string]string{"pool": "spot"}},
+		},
+	}, edgeTarget)
+	require.NoError(t, err)
+
+	nodeSelector, tolerations := podSpec(t, injected)
+	require.Equal(t, map[string]string{"pool": "dedicated", "zone": "a"}, nodeSelector, "the defaults applied last win")
+	require.Equal(t, []interface{}{
+		map[string]interface{}{"key": "edge", "operator": "Equal", "value": "defaults", "effect": "NoSchedule"},
+		map[string]interface{}{"key": "gpu", "operator": "Exists"},
+	}, tolerations)
+}
+
+func TestApplySelectors(t *testing.T) {
+	defaults := newDefaults("edge", "")
+	defaults.Spec.TargetSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"edge": "true"}}
+	defaults.Spec.ObjectSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "frontend"}}
+	all := []*workloadv1alpha1.SchedulingDefaults{defaults}
+
+	injected, err := Apply(newDeployment(), all, edgeTarget)
+	require.NoError(t, err)
+	nodeSelector, _ := podSpec(t, injected)
+	require.Equal(t, "a", nodeSelector["zone"])
+
+	obj := newDeployment()
+	injected, err = Apply(obj, all, &tmcv1alpha1.SyncTarget{ObjectMeta: metav1.ObjectMeta{Name: "cloud-1"}})
+	require.NoError(t, err)
+	require.Same(t, obj, injected, "targets not selected are not injected")
+
+	obj.SetLabels(map[string]string{"tier": "backend"})
+	injected, err = Apply(obj, all, edgeTarget)
+	require.NoError(t, err)
+	require.Same(t, obj, injected, "workloads not selected are not injected")
+
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap"}}
+	injected, err = Apply(configMap, all, edgeTarget)
+	require.NoError(t, err)
+	require.Same(t, configMap, injected, "objects without pods are not injected")
+}
+
+func TestApplyUnresolvedVariable(t *testing.T) {
+	_, err := Apply(newDeployment(), []*workloadv1alpha1.SchedulingDefaults{newDefaults("edge", "")}, &tmcv1alpha1.SyncTarget{ObjectMeta: metav1.ObjectMeta{Name: "cloud-1"}})
+	require.ErrorContains(t, err, `SchedulingDefaults "edge"`)
+}
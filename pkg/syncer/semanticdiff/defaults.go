@@ -0,0 +1,198 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package semanticdiff
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// The defaults of the built-in resources most commonly synced, as set by
+// the API server. Fields that are defaulted to values derived from other
+// fields or from cluster configuration are ignored instead.
+func init() {
+	deployments := schema.GroupResource{Group: "apps", Resource: "deployments"}
+	AddNormalizer(deployments, func(content map[string]interface{}) {
+		spec := child(content, "spec")
+		setDefault(spec, "replicas", int64(1))
+		setDefault(spec, "revisionHistoryLimit", int64(10))
+		setDefault(spec, "progressDeadlineSeconds", int64(600))
+		strategy := child(spec, "strategy")
+		setDefault(strategy, "type", "RollingUpdate")
+		if strategy["type"] == "RollingUpdate" {
+			rollingUpdate := child(strategy, "rollingUpdate")
+			setDefault(rollingUpdate, "maxSurge", "25%")
+			setDefault(rollingUpdate, "maxUnavailable", "25%")
+		}
+		podTemplate(spec)
+	})
+	IgnoreAnnotations(deployments, "deployment.kubernetes.io/revision")
+
+	AddNormalizer(schema.GroupResource{Group: "apps", Resource: "statefulsets"}, func(content map[string]interface{}) {
+		spec := child(content, "spec")
+		setDefault(spec, "replicas", int64(1))
+		setDefault(spec, "revisionHistoryLimit", int64(10))
+		setDefault(spec, "podManagementPolicy", "OrderedReady")
+		strategy := child(spec, "updateStrategy")
+		setDefault(strategy, "type", "RollingUpdate")
+		if strategy["type"] == "RollingUpdate" {
+			setDefault(child(strategy, "rollingUpdate"), "partition", int64(0))
+		}
+		podTemplate(spec)
+	})
+
+	AddNormalizer(schema.GroupResource{Group: "apps", Resource: "daemonsets"}, func(content map[string]interface{}) {
+		spec := child(content, "spec")
+		setDefault(spec, "revisionHistoryLimit", int64(10))
+		strategy := child(spec, "updateStrategy")
+		setDefault(strategy, "type", "RollingUpdate")
+		if strategy["type"] == "RollingUpdate" {
+			rollingUpdate := child(strategy, "rollingUpdate")
+			setDefault(rollingUpdate, "maxUnavailable", int64(1))
+			setDefault(rollingUpdate, "maxSurge", int64(0))
+		}
+		podTemplate(spec)
+	})
+
+	AddNormalizer(schema.GroupResource{Group: "batch", Resource: "jobs"}, func(content map[string]interface{}) {
+		spec := child(content, "spec")
+		setDefault(spec, "backoffLimit", int64(6))
+		setDefault(spec, "completionMode", "NonIndexed")
+		setDefault(spec, "suspend", false)
+		// The selector and its pod labels are generated by the server.
+		delete(spec, "selector")
+		delete(spec, "manualSelector")
+		if template, ok := spec["template"].(map[string]interface{}); ok {
+			if metadata, ok := template["metadata"].(map[string]interface{}); ok {
+				if labels, ok := metadata["labels"].(map[string]interface{}); ok {
+					for _, label := range []string{"controller-uid", "job-name", "batch.kubernetes.io/controller-uid", "batch.kubernetes.io/job-name"} {
+						delete(labels, label)
+					}
+				}
+			}
+		}
+		podTemplate(spec)
+	})
+
+	AddNormalizer(schema.GroupResource{Resource: "pods"}, func(content map[string]interface{}) {
+		spec := child(content, "spec")
+		// Assigned by the scheduler.
+		delete(spec, "nodeName")
+		podSpec(spec)
+	})
+
+	AddNormalizer(schema.GroupResource{Resource: "services"}, func(content map[string]interface{}) {
+		spec := child(content, "spec")
+		setDefault(spec, "type", "ClusterIP")
+		setDefault(spec, "sessionAffinity", "None")
+		setDefault(spec, "internalTrafficPolicy", "Cluster")
+		// Allocated by the server.
+		for _, field := range []string{"clusterIP", "clusterIPs", "ipFamilies", "ipFamilyPolicy"} {
+			delete(spec, field)
+		}
+		for _, port := range items(spec, "ports") {
+			setDefault(port, "protocol", "TCP")
+			setDefault(port, "targetPort", port["port"])
+			delete(port, "nodePort")
+		}
+	})
+
+	AddNormalizer(schema.GroupResource{Resource: "secrets"}, func(content map[string]interface{}) {
+		setDefault(content, "type", "Opaque")
+	})
+
+	AddNormalizer(schema.GroupResource{Group: "scheduling.k8s.io", Resource: "priorityclasses"}, func(content map[string]interface{}) {
+		setDefault(content, "preemptionPolicy", "PreemptLowerPriority")
+		setDefault(content, "globalDefault", false)
+	})
+}
+
+// podTemplate normalizes the pod template of a workload spec.
+func podTemplate(spec map[string]interface{}) {
+	template := child(spec, "template")
+	if metadata, ok := template["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "creationTimestamp")
+	}
+	podSpec(child(template, "spec"))
+}
+
+func podSpec(spec map[string]interface{}) {
+	setDefault(spec, "restartPolicy", "Always")
+	setDefault(spec, "terminationGracePeriodSeconds", int64(30))
+	setDefault(spec, "dnsPolicy", "ClusterFirst")
+	setDefault(spec, "schedulerName", "default-scheduler")
+	// Set by the service account admission plugin.
+	delete(spec, "serviceAccount")
+	for _, field := range []string{"containers", "initContainers"} {
+		for _, container := range items(spec, field) {
+			setDefault(container, "terminationMessagePath", "/dev/termination-log")
+			setDefault(container, "terminationMessagePolicy", "File")
+			setDefault(container, "imagePullPolicy", imagePullPolicy(container["image"]))
+			for _, port := range items(container, "ports") {
+				setDefault(port, "protocol", "TCP")
+			}
+		}
+	}
+}
+
+// imagePullPolicy returns the default pull policy of an image: Always for
+// the latest tag, IfNotPresent otherwise.
+func imagePullPolicy(image interface{}) string {
+	s, _ := image.(string)
+	if strings.Contains(s, "@") {
+		return "IfNotPresent"
+	}
+	name := s[strings.LastIndex(s, "/")+1:]
+	if i := strings.LastIndex(name, ":"); i < 0 || name[i+1:] == "latest" {
+		return "Always"
+	}
+	return "IfNotPresent"
+}
+
+// child returns the map of a field, adding it if missing. Fields of another
+// type are left alone and an unattached map is returned.
+func child(m map[string]interface{}, field string) map[string]interface{} {
+	switch c := m[field].(type) {
+	case map[string]interface{}:
+		return c
+	case nil:
+		added := map[string]interface{}{}
+		m[field] = added
+		return added
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// items returns the maps in the list of a field.
+func items(m map[string]interface{}, field string) []map[string]interface{} {
+	list, _ := m[field].([]interface{})
+	out := make([]map[string]interface{}, 0, len(list))
+	for _, e := range list {
+		if item, ok := e.(map[string]interface{}); ok {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func setDefault(m map[string]interface{}, field string, value interface{}) {
+	if _, found := m[field]; !found && value != nil {
+		m[field] = value
+	}
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package semanticdiff
+
+import (
+	"sync"
+
+	compbasemetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+var avoidedWrites = compbasemetrics.NewCounterVec(
+	&compbasemetrics.CounterOpts{
+		Name:           "syncer_avoided_writes_total",
+		Help:           "Number of downstream writes skipped because the desired object is semantically equal to the existing one, by resource.",
+		StabilityLevel: compbasemetrics.ALPHA,
+	},
+	[]string{"resource"},
+)
+
+var registerMetrics sync.Once
+
+// Register metrics.
+func Register() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(avoidedWrites)
+	})
+}
+
+func init() {
+	Register()
+}
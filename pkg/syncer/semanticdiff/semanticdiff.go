@@ -0,0 +1,193 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package semanticdiff compares desired and existing downstream objects
+// semantically, so that the syncer skips writes that would not change
+// anything. Fields populated by the server are ignored, fields the API
+// server defaults are filled in on both sides for the resources whose
+// defaults are known, empty values equal absent ones, and numbers compare
+// by value regardless of their Go type.
+package semanticdiff
+
+import (
+	"math"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Normalizer fills in the defaults of a resource, or removes fields the
+// server populates, in the content of an object, i.e. the object without
+// apiVersion, kind, metadata and status. Numbers in content are int64 or
+// float64.
+type Normalizer func(content map[string]interface{})
+
+var (
+	lock        sync.RWMutex
+	normalizers = map[schema.GroupResource][]Normalizer{}
+	// ignoredAnnotations are annotations the server or its controllers
+	// set, by resource.
+	ignoredAnnotations = map[schema.GroupResource][]string{}
+)
+
+// AddNormalizer adds a normalizer for the objects of a resource. Normalizers
+// run in the order they were added.
+func AddNormalizer(resource schema.GroupResource, normalizer Normalizer) {
+	lock.Lock()
+	defer lock.Unlock()
+	normalizers[resource] = append(normalizers[resource], normalizer)
+}
+
+// IgnoreAnnotations ignores annotations set on the objects of a resource by
+// the server or its controllers.
+func IgnoreAnnotations(resource schema.GroupResource, keys ...string) {
+	lock.Lock()
+	defer lock.Unlock()
+	ignoredAnnotations[resource] = append(ignoredAnnotations[resource], keys...)
+}
+
+// Equal returns whether writing desired would not change existing: their
+// labels, annotations and content are semantically equal.
+func Equal(resource schema.GroupResource, desired, existing *unstructured.Unstructured) bool {
+	return equalStringMaps(desired.GetLabels(), existing.GetLabels(), nil) &&
+		equalStringMaps(desired.GetAnnotations(), existing.GetAnnotations(), ignored(resource)) &&
+		EqualContent(resource, desired, existing)
+}
+
+// EqualContent returns whether the content of two objects, ignoring
+// metadata and status, is semantically equal.
+func EqualContent(resource schema.GroupResource, a, b *unstructured.Unstructured) bool {
+	return equality.Semantic.DeepEqual(Normalize(resource, a), Normalize(resource, b))
+}
+
+// Unchanged is Equal, and counts the writes avoided when it returns true.
+func Unchanged(resource schema.GroupResource, desired, existing *unstructured.Unstructured) bool {
+	if !Equal(resource, desired, existing) {
+		return false
+	}
+	avoidedWrites.WithLabelValues(resource.String()).Inc()
+	return true
+}
+
+// Normalize returns the normalized content of obj, without apiVersion,
+// kind, metadata and status. obj is not modified.
+func Normalize(resource schema.GroupResource, obj *unstructured.Unstructured) map[string]interface{} {
+	content := map[string]interface{}{}
+	for k, v := range obj.Object {
+		switch k {
+		case "apiVersion", "kind", "metadata", "status":
+		default:
+			content[k] = v
+		}
+	}
+	content, _ = canonical(content).(map[string]interface{})
+	if content == nil {
+		content = map[string]interface{}{}
+	}
+
+	lock.RLock()
+	ns := normalizers[resource]
+	lock.RUnlock()
+	for _, n := range ns {
+		n(content)
+	}
+	// Defaults may have added empty values.
+	content, _ = canonical(content).(map[string]interface{})
+	return content
+}
+
+func ignored(resource schema.GroupResource) []string {
+	lock.RLock()
+	defer lock.RUnlock()
+	return ignoredAnnotations[resource]
+}
+
+// canonical returns a copy of v with numbers as int64 if integral and
+// float64 otherwise, and without nil values, empty maps and empty slices.
+// It returns nil for values that are empty.
+func canonical(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			if c := canonical(e); c != nil {
+				out[k] = c
+			}
+		}
+		if len(out) == 0 {
+			return nil
+		}
+		return out
+	case []interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		out := make([]interface{}, len(v))
+		for i, e := range v {
+			out[i] = canonical(e)
+		}
+		return out
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case int64:
+		return v
+	case float32:
+		return canonicalFloat(float64(v))
+	case float64:
+		return canonicalFloat(v)
+	default:
+		return v
+	}
+}
+
+func canonicalFloat(f float64) interface{} {
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return int64(f)
+	}
+	return f
+}
+
+func equalStringMaps(a, b map[string]string, ignored []string) bool {
+	n := 0
+	for k, v := range a {
+		if contains(ignored, k) {
+			continue
+		}
+		if w, found := b[k]; !found || w != v {
+			return false
+		}
+		n++
+	}
+	for k := range b {
+		if !contains(ignored, k) {
+			n--
+		}
+	}
+	return n == 0
+}
+
+func contains(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
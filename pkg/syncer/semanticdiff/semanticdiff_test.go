@@ -0,0 +1,144 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package semanticdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/component-base/metrics/testutil"
+)
+
+var deployments = schema.GroupResource{Group: "apps", Resource: "deployments"}
+
+func deployment(spec map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"spec":       spec,
+	}}
+	obj.SetNamespace("default")
+	obj.SetName("web")
+	obj.SetLabels(map[string]string{"app": "web"})
+	return obj
+}
+
+func TestEqual(t *testing.T) {
+	desired := deployment(map[string]interface{}{
+		"replicas": int64(2),
+		"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"app": "web"}},
+		"template": map[string]interface{}{
+			"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "web"}},
+			"spec": map[string]interface{}{
+				"containers": []interface{}{map[string]interface{}{
+					"name":  "web",
+					"image": "nginx:1.27",
+					"ports": []interface{}{map[string]interface{}{"containerPort": float64(80)}},
+				}},
+			},
+		},
+	})
+
+	existing := deployment(map[string]interface{}{
+		"replicas":                int64(2),
+		"revisionHistoryLimit":    int64(10),
+		"progressDeadlineSeconds": int64(600),
+		"strategy": map[string]interface{}{
+			"type":          "RollingUpdate",
+			"rollingUpdate": map[string]interface{}{"maxSurge": "25%", "maxUnavailable": "25%"},
+		},
+		"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"app": "web"}},
+		"template": map[string]interface{}{
+			"metadata": map[string]interface{}{"creationTimestamp": nil, "labels": map[string]interface{}{"app": "web"}},
+			"spec": map[string]interface{}{
+				"restartPolicy":                 "Always",
+				"terminationGracePeriodSeconds": int64(30),
+				"dnsPolicy":                     "ClusterFirst",
+				"schedulerName":                 "default-scheduler",
+				"securityContext":               map[string]interface{}{},
+				"containers": []interface{}{map[string]interface{}{
+					"name":                     "web",
+					"image":                    "nginx:1.27",
+					"imagePullPolicy":          "IfNotPresent",
+					"terminationMessagePath":   "/dev/termination-log",
+					"terminationMessagePolicy": "File",
+					"resources":                map[string]interface{}{},
+					"ports":                    []interface{}{map[string]interface{}{"containerPort": int64(80), "protocol": "TCP"}},
+				}},
+			},
+		},
+	})
+	existing.SetUID("1234")
+	existing.SetResourceVersion("42")
+	existing.SetGeneration(3)
+	existing.SetAnnotations(map[string]string{"deployment.kubernetes.io/revision": "3"})
+	existing.Object["status"] = map[string]interface{}{"replicas": int64(2)}
+
+	require.True(t, Equal(deployments, desired, existing), "defaulted and server-populated fields are ignored")
+
+	changed := existing.DeepCopy()
+	require.NoError(t, unstructured.SetNestedField(changed.Object, "Never", "spec", "template", "spec", "restartPolicy"))
+	require.False(t, Equal(deployments, desired, changed), "fields differing from their default are compared")
+
+	changed = existing.DeepCopy()
+	changed.SetLabels(map[string]string{"app": "web", "tier": "frontend"})
+	require.False(t, Equal(deployments, desired, changed), "labels are compared")
+
+	latest := desired.DeepCopy()
+	require.NoError(t, unstructured.SetNestedSlice(latest.Object, []interface{}{map[string]interface{}{"name": "web", "image": "nginx"}}, "spec", "template", "spec", "containers"))
+	require.False(t, Equal(deployments, latest, existing), "untagged images are pulled always")
+
+	require.True(t, EqualContent(deployments, desired, existing))
+	changed = existing.DeepCopy()
+	changed.SetAnnotations(map[string]string{"owner": "someone"})
+	require.True(t, EqualContent(deployments, desired, changed), "content ignores metadata")
+}
+
+func TestNormalizeUnknownResource(t *testing.T) {
+	widgets := schema.GroupResource{Group: "example.com", Resource: "widgets"}
+	a := &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{"size": 3, "tags": []interface{}{}}}}
+	b := &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{"size": float64(3)}}}
+	require.True(t, EqualContent(widgets, a, b))
+
+	b.Object["spec"].(map[string]interface{})["color"] = "blue"
+	require.False(t, EqualContent(widgets, a, b), "fields without known defaults are compared")
+
+	AddNormalizer(widgets, func(content map[string]interface{}) {
+		setDefault(child(content, "spec"), "color", "blue")
+	})
+	require.True(t, EqualContent(widgets, a, b))
+	require.Equal(t, map[string]interface{}{"size": 3, "tags": []interface{}{}}, a.Object["spec"], "objects are not modified")
+}
+
+func TestUnchangedCountsAvoidedWrites(t *testing.T) {
+	avoidedWrites.Reset()
+	secrets := schema.GroupResource{Resource: "secrets"}
+	desired := &unstructured.Unstructured{Object: map[string]interface{}{"data": map[string]interface{}{"key": "dmFsdWU="}}}
+	existing := desired.DeepCopy()
+	existing.Object["type"] = "Opaque"
+
+	require.True(t, Unchanged(secrets, desired, existing))
+	existing.Object["type"] = "kubernetes.io/tls"
+	require.False(t, Unchanged(secrets, desired, existing))
+
+	count, err := testutil.GetCounterMetricValue(avoidedWrites.WithLabelValues("secrets"))
+	require.NoError(t, err)
+	require.Equal(t, float64(1), count)
+}
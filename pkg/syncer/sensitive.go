@@ -0,0 +1,47 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"fmt"
+
+	"github.com/kcp-dev/kcp/pkg/crypto/encryption"
+	"github.com/kcp-dev/kcp/pkg/syncer/sensitive"
+	"github.com/kcp-dev/kcp/pkg/syncer/transport/batch"
+)
+
+// newBatcher returns the batcher of the downstream writes. Sensitive
+// objects are kept encrypted with the keys of
+// options.SensitiveEncryptionConfig while they are queued, and their
+// decryption is audited, see package sensitive.
+func (s *syncer) newBatcher(options *Options) (*batch.Batcher, error) {
+	batchOptions := batch.Options{
+		FlushInterval: options.BatchInterval,
+		MaxBatchBytes: options.BatchMaxBytes,
+	}
+	if s.limiter != nil {
+		batchOptions.BandwidthLimit = s.limiter.Limit
+	}
+	if options.SensitiveEncryptionConfig != "" {
+		keyring, err := encryption.LoadKeyring(options.SensitiveEncryptionConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load --sensitive-encryption-config: %w", err)
+		}
+		batchOptions.Envelope = sensitive.NewEnvelope(keyring, s.audit)
+	}
+	return batch.NewBatcher(s.sendBatch, batchOptions), nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sensitive keeps objects marked sensitive encrypted while the
+// syncer holds them, e.g. queued in the batcher, and decrypts them in memory
+// only right before they are applied. Every decryption is recorded in the
+// mutation audit log. Objects are encrypted with the same providers as the
+// other data TMC components keep, see package encryption.
+package sensitive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	compbasemetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	"github.com/kcp-dev/kcp/pkg/crypto/encryption"
+	"github.com/kcp-dev/kcp/pkg/syncer/audit"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var decryptions = compbasemetrics.NewCounterVec(
+	&compbasemetrics.CounterOpts{
+		Name:           "syncer_sensitive_decryptions_total",
+		Help:           "Number of decryptions of sensitive objects before they are applied, by resource and result.",
+		StabilityLevel: compbasemetrics.ALPHA,
+	},
+	[]string{"resource", "result"},
+)
+
+var registerMetrics sync.Once
+
+// Register metrics.
+func Register() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(decryptions)
+	})
+}
+
+func init() {
+	Register()
+}
+
+// ErrUndecodable is returned by Open for an object that was decrypted but
+// cannot be decoded. Unlike decryption failures, e.g. while a key rotation
+// is in progress, it does not go away by trying again.
+var ErrUndecodable = errors.New("undecodable sensitive object")
+
+// Sensitive returns whether obj is marked sensitive.
+func Sensitive(obj *unstructured.Unstructured) bool {
+	return obj != nil && obj.GetAnnotations()[workloadv1alpha1.AnnotationSensitive] == "true"
+}
+
+// Envelope encrypts sensitive objects and audits their decryption.
+type Envelope struct {
+	provider encryption.Provider
+	log      *audit.Log
+}
+
+// NewEnvelope returns an envelope encrypting with provider and recording
+// decryptions in log, unless nil.
+func NewEnvelope(provider encryption.Provider, log *audit.Log) *Envelope {
+	return &Envelope{provider: provider, log: log}
+}
+
+// Seal returns the encrypted object.
+func (e *Envelope) Seal(ctx context.Context, obj *unstructured.Unstructured) ([]byte, error) {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return e.provider.Encrypt(ctx, data)
+}
+
+// Open decrypts an object of resource returned by Seal for controller,
+// which is about to apply it, and records the decryption.
+func (e *Envelope) Open(ctx context.Context, controller string, resource schema.GroupVersionResource, sealed []byte) (*unstructured.Unstructured, error) {
+	data, err := e.provider.Decrypt(ctx, sealed)
+	if err != nil {
+		decryptions.WithLabelValues(resource.String(), "failure").Inc()
+		return nil, fmt.Errorf("failed to decrypt sensitive %s: %w", resource, err)
+	}
+	// The content is decoded as is: objects need not have their kind set
+	// to be sealed, and Unstructured.UnmarshalJSON requires it.
+	obj := &unstructured.Unstructured{}
+	if err := json.Unmarshal(data, &obj.Object); err != nil || obj.Object == nil {
+		decryptions.WithLabelValues(resource.String(), "failure").Inc()
+		return nil, fmt.Errorf("%w of %s: %v", ErrUndecodable, resource, err)
+	}
+	decryptions.WithLabelValues(resource.String(), "success").Inc()
+	e.log.RecordDecryption(ctx, controller, resource, obj.GetNamespace(), obj.GetName())
+	return obj, nil
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sensitive
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kcp-dev/kcp/pkg/crypto/encryption"
+	"github.com/kcp-dev/kcp/pkg/syncer/audit"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var secretsGVR = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+
+func TestEnvelope(t *testing.T) {
+	ctx := context.Background()
+	key, err := encryption.NewAESGCMKey("1", []byte("0123456789abcdef"))
+	require.NoError(t, err)
+	keyring, err := encryption.NewKeyring(key, nil, false)
+	require.NoError(t, err)
+	options := audit.NewOptions()
+	options.Path = filepath.Join(t.TempDir(), "mutations.log")
+	log, err := audit.NewLog(options, "root:org:edge-1")
+	require.NoError(t, err)
+	defer log.Close()
+
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"namespace":   "kcp-abc",
+			"name":        "db-password",
+			"annotations": map[string]interface{}{workloadv1alpha1.AnnotationSensitive: "true"},
+		},
+		"stringData": map[string]interface{}{"password": "hunter2"},
+	}}
+	require.True(t, Sensitive(secret))
+	require.False(t, Sensitive(&unstructured.Unstructured{Object: map[string]interface{}{}}))
+
+	e := NewEnvelope(keyring, log)
+	sealed, err := e.Seal(ctx, secret)
+	require.NoError(t, err)
+	require.NotContains(t, string(sealed), "hunter2")
+
+	opened, err := e.Open(ctx, "spec", secretsGVR, sealed)
+	require.NoError(t, err)
+	require.Equal(t, secret, opened)
+
+	events, err := log.Query(audit.Filter{Verb: audit.VerbDecrypt})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, "spec", events[0].Controller)
+	require.Equal(t, "db-password", events[0].Name)
+
+	_, err = e.Open(ctx, "spec", secretsGVR, []byte("tampered"))
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrUndecodable)
+
+	garbage, err := keyring.Encrypt(ctx, []byte("not json"))
+	require.NoError(t, err)
+	_, err = e.Open(ctx, "spec", secretsGVR, garbage)
+	require.ErrorIs(t, err, ErrUndecodable)
+}
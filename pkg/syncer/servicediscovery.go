@@ -0,0 +1,180 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/naming"
+	"github.com/kcp-dev/kcp/pkg/syncer/servicediscovery"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var (
+	serviceExportsGVR = workloadv1alpha1.SchemeGroupVersion.WithResource("serviceexports")
+	serviceImportsGVR = workloadv1alpha1.SchemeGroupVersion.WithResource("serviceimports")
+)
+
+// discoverServices reports the endpoints of the exported Services on the
+// physical cluster and mirrors the ServiceImports of the workspace onto it
+// every interval until ctx is done, see package servicediscovery.
+// ServiceImports are not mirrored if mirror is nil, e.g. in observer mode.
+func (s *syncer) discoverServices(ctx context.Context, mirror *servicediscovery.Mirror, interval time.Duration) {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := s.reportServiceEndpoints(ctx); err != nil {
+			utilruntime.HandleError(fmt.Errorf("failed to report the endpoints of the exported Services of SyncTarget %s: %w", s.target, err))
+		}
+		if mirror == nil {
+			return
+		}
+		if err := s.mirrorServiceImports(ctx, mirror); err != nil {
+			utilruntime.HandleError(fmt.Errorf("failed to mirror the ServiceImports of SyncTarget %s: %w", s.target, err))
+		}
+	}, interval)
+}
+
+// reportServiceEndpoints reports the ready endpoints of the exported
+// Services synced to the physical cluster on their ServiceExports, and
+// removes the reports of the Services no longer synced.
+func (s *syncer) reportServiceEndpoints(ctx context.Context) error {
+	exports, err := s.upstream.Resource(serviceExportsGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	annotation := servicediscovery.EndpointsAnnotation(s.target.Name)
+	var errs []error
+	for _, export := range exports.Items {
+		endpoints, err := s.exportedEndpoints(ctx, export.GetNamespace(), export.GetName())
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		var reported string
+		if endpoints != nil {
+			raw, err := json.Marshal(endpoints)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			reported = string(raw)
+		}
+		if export.GetAnnotations()[annotation] == reported {
+			continue
+		}
+		patch, err := servicediscovery.ReportPatch(s.target.Name, endpoints)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, err := s.upstream.Resource(serviceExportsGVR).Namespace(export.GetNamespace()).Patch(ctx, export.GetName(), types.MergePatchType, patch, metav1.PatchOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to report the endpoints of ServiceExport %s/%s: %w", export.GetNamespace(), export.GetName(), err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// exportedEndpoints returns the endpoints on the physical cluster of the
+// Service namespace/name of the workspace, or nil if the Service is not
+// synced to it.
+func (s *syncer) exportedEndpoints(ctx context.Context, namespace, name string) (*workloadv1alpha1.ServiceEndpoints, error) {
+	downstreamNamespace := naming.Namespace(s.clusterName, namespace)
+	service, err := s.downstreamKube.CoreV1().Services(downstreamNamespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if service.Labels[LabelSyncTarget] != s.key {
+		return nil, nil
+	}
+	slices, err := s.downstreamKube.DiscoveryV1().EndpointSlices(downstreamNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{discoveryv1.LabelServiceName: name}).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	endpoints := servicediscovery.Endpoints(s.target.Name, slices.Items)
+	return &endpoints, nil
+}
+
+// mirrorServiceImports mirrors the ServiceImports of the namespaces synced
+// to the physical cluster onto it, and removes the mirrors of the
+// ServiceImports that are gone.
+func (s *syncer) mirrorServiceImports(ctx context.Context, mirror *servicediscovery.Mirror) error {
+	imports, err := s.upstream.Resource(serviceImportsGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	var errs []error
+	wanted := map[string]sets.Set[string]{}
+	for _, u := range imports.Items {
+		imp := &workloadv1alpha1.ServiceImport{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, imp); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		namespace := naming.Namespace(s.clusterName, imp.Namespace)
+		if wanted[namespace] == nil {
+			wanted[namespace] = sets.New[string]()
+		}
+		wanted[namespace].Insert(imp.Name)
+		if _, err := s.downstreamKube.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+			// Nothing of the namespace is synced to the physical cluster.
+			continue
+		} else if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := mirror.Apply(ctx, namespace, imp); err != nil {
+			errs = append(errs, fmt.Errorf("failed to mirror ServiceImport %s/%s: %w", imp.Namespace, imp.Name, err))
+		}
+	}
+
+	mirrored, err := mirror.Mirrored(ctx)
+	if err != nil {
+		return utilerrors.NewAggregate(append(errs, err))
+	}
+	prefix := naming.Prefix(s.clusterName.String())
+	for namespace, names := range mirrored {
+		if !strings.HasPrefix(namespace, prefix) {
+			// A namespace of another workspace.
+			continue
+		}
+		for name := range names.Difference(wanted[namespace]) {
+			if err := mirror.Remove(ctx, namespace, name); err != nil {
+				errs = append(errs, fmt.Errorf("failed to remove the mirror of ServiceImport %s in namespace %s: %w", name, namespace, err))
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,377 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package servicediscovery mirrors Services across the physical clusters of
+// the SyncTargets a namespace is synced to. For every ServiceExport, the
+// syncer reports the ready endpoints of the exported Service on its physical
+// cluster in an annotation of the ServiceExport, and the TMC controllers
+// collect the endpoints of all SyncTargets in the ServiceImport of the same
+// name. The syncer then mirrors the ServiceImport onto its physical cluster
+// as a Service without selector, named after the exported Service with the
+// prefix imported-, and one EndpointSlice per other SyncTarget and address
+// family, so that workloads resolve the Service wherever its pods run.
+package servicediscovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/naming"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+const (
+	// ManagedBy is the value of the managed-by label of the Services and
+	// EndpointSlices mirrored by the syncer.
+	ManagedBy = "servicediscovery.syncer.tmc.kcp.io"
+	// AnnotationImport is set on the mirrored Services to the name of
+	// their ServiceImport, whose mirror name may be cut, see ImportedName.
+	AnnotationImport = "servicediscovery.syncer.tmc.kcp.io/import"
+)
+
+// EndpointsAnnotation returns the annotation of ServiceExports in which the
+// syncer of syncTarget reports the endpoints of the exported Service.
+func EndpointsAnnotation(syncTarget string) string {
+	return workloadv1alpha1.AnnotationPrefixExportedEndpoints + naming.Bounded(syncTarget, validation.LabelValueMaxLength)
+}
+
+// Endpoints returns the ready endpoints of an exported Service on
+// syncTarget, from its EndpointSlices.
+func Endpoints(syncTarget string, slices []discoveryv1.EndpointSlice) workloadv1alpha1.ServiceEndpoints {
+	endpoints := workloadv1alpha1.ServiceEndpoints{SyncTarget: syncTarget}
+	addresses := sets.New[string]()
+	ports := map[string]workloadv1alpha1.ServicePort{}
+	for _, slice := range slices {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+				continue
+			}
+			addresses.Insert(endpoint.Addresses...)
+		}
+		for _, port := range slice.Ports {
+			if port.Port == nil {
+				continue
+			}
+			p := workloadv1alpha1.ServicePort{Port: *port.Port, AppProtocol: port.AppProtocol}
+			if port.Name != nil {
+				p.Name = *port.Name
+			}
+			if port.Protocol != nil {
+				p.Protocol = *port.Protocol
+			}
+			ports[fmt.Sprintf("%s/%s/%d", p.Name, p.Protocol, p.Port)] = p
+		}
+	}
+	endpoints.Addresses = sets.List(addresses)
+	for _, key := range sets.List(sets.KeySet(ports)) {
+		endpoints.Ports = append(endpoints.Ports, ports[key])
+	}
+	return endpoints
+}
+
+// ReportPatch returns the merge patch of a ServiceExport reporting the
+// endpoints of its Service on a SyncTarget, or removing the report of
+// syncTarget if endpoints is nil, e.g. when the Service was deleted.
+func ReportPatch(syncTarget string, endpoints *workloadv1alpha1.ServiceEndpoints) ([]byte, error) {
+	var value interface{}
+	if endpoints != nil {
+		raw, err := json.Marshal(endpoints)
+		if err != nil {
+			return nil, err
+		}
+		value = string(raw)
+	}
+	return json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				EndpointsAnnotation(syncTarget): value,
+			},
+		},
+	})
+}
+
+// Reported returns the endpoints the syncers reported on export, sorted by
+// SyncTarget. Malformed reports are skipped and returned as an error along
+// with the others.
+func Reported(export *workloadv1alpha1.ServiceExport) ([]workloadv1alpha1.ServiceEndpoints, error) {
+	var reported []workloadv1alpha1.ServiceEndpoints
+	var errs []error
+	for key, value := range export.Annotations {
+		if !strings.HasPrefix(key, workloadv1alpha1.AnnotationPrefixExportedEndpoints) {
+			continue
+		}
+		var endpoints workloadv1alpha1.ServiceEndpoints
+		if err := json.Unmarshal([]byte(value), &endpoints); err != nil {
+			errs = append(errs, fmt.Errorf("invalid endpoints in annotation %s: %w", key, err))
+			continue
+		}
+		if endpoints.SyncTarget == "" || EndpointsAnnotation(endpoints.SyncTarget) != key {
+			errs = append(errs, fmt.Errorf("endpoints in annotation %s are reported for SyncTarget %q", key, endpoints.SyncTarget))
+			continue
+		}
+		reported = append(reported, endpoints)
+	}
+	sort.Slice(reported, func(i, j int) bool { return reported[i].SyncTarget < reported[j].SyncTarget })
+	return reported, utilerrors.NewAggregate(errs)
+}
+
+// ImportedName returns the name of the Service mirroring the imported
+// Service name.
+func ImportedName(name string) string {
+	return naming.Bounded("imported-"+name, validation.DNS1035LabelMaxLength)
+}
+
+// Desired returns the Service and EndpointSlices mirroring imp in namespace
+// on the physical cluster of syncTarget. Endpoints on syncTarget itself are
+// left out, they are served by the exported Service.
+func Desired(imp *workloadv1alpha1.ServiceImport, syncTarget, namespace string) (*corev1.Service, []*discoveryv1.EndpointSlice) {
+	name := ImportedName(imp.Name)
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   namespace,
+			Name:        name,
+			Labels:      map[string]string{discoveryv1.LabelManagedBy: ManagedBy},
+			Annotations: map[string]string{AnnotationImport: imp.Name},
+		},
+		Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP},
+	}
+	if imp.Spec.Type == workloadv1alpha1.Headless {
+		service.Spec.ClusterIP = corev1.ClusterIPNone
+	}
+	for _, port := range imp.Spec.Ports {
+		service.Spec.Ports = append(service.Spec.Ports, corev1.ServicePort{
+			Name:        port.Name,
+			Protocol:    protocol(port.Protocol),
+			AppProtocol: port.AppProtocol,
+			Port:        port.Port,
+			TargetPort:  intstr.FromInt32(port.Port),
+		})
+	}
+
+	var slices []*discoveryv1.EndpointSlice
+	for _, target := range imp.Status.Targets {
+		if target.SyncTarget == syncTarget || len(target.Addresses) == 0 {
+			continue
+		}
+		byType := map[discoveryv1.AddressType][]string{}
+		for _, address := range target.Addresses {
+			addressType := addressTypeOf(address)
+			byType[addressType] = append(byType[addressType], address)
+		}
+		for _, addressType := range []discoveryv1.AddressType{discoveryv1.AddressTypeIPv4, discoveryv1.AddressTypeIPv6, discoveryv1.AddressTypeFQDN} {
+			addresses := byType[addressType]
+			if len(addresses) == 0 {
+				continue
+			}
+			slice := &discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: namespace,
+					Name:      naming.Bounded(fmt.Sprintf("%s-%s-%s", name, target.SyncTarget, strings.ToLower(string(addressType))), validation.DNS1123SubdomainMaxLength),
+					Labels: map[string]string{
+						discoveryv1.LabelServiceName: name,
+						discoveryv1.LabelManagedBy:   ManagedBy,
+					},
+				},
+				AddressType: addressType,
+			}
+			for _, address := range addresses {
+				slice.Endpoints = append(slice.Endpoints, discoveryv1.Endpoint{
+					Addresses:  []string{address},
+					Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)},
+				})
+			}
+			for _, port := range target.Ports {
+				slice.Ports = append(slice.Ports, discoveryv1.EndpointPort{
+					Name:        ptr.To(port.Name),
+					Protocol:    ptr.To(protocol(port.Protocol)),
+					AppProtocol: port.AppProtocol,
+					Port:        ptr.To(port.Port),
+				})
+			}
+			slices = append(slices, slice)
+		}
+	}
+	return service, slices
+}
+
+func addressTypeOf(address string) discoveryv1.AddressType {
+	ip := net.ParseIP(address)
+	switch {
+	case ip == nil:
+		return discoveryv1.AddressTypeFQDN
+	case ip.To4() != nil:
+		return discoveryv1.AddressTypeIPv4
+	default:
+		return discoveryv1.AddressTypeIPv6
+	}
+}
+
+func protocol(p corev1.Protocol) corev1.Protocol {
+	if p == "" {
+		return corev1.ProtocolTCP
+	}
+	return p
+}
+
+// Mirror mirrors ServiceImports onto the physical cluster of a SyncTarget.
+type Mirror struct {
+	client     kubernetes.Interface
+	syncTarget string
+}
+
+// NewMirror returns a mirror writing to the physical cluster of syncTarget
+// with client.
+func NewMirror(client kubernetes.Interface, syncTarget string) *Mirror {
+	return &Mirror{client: client, syncTarget: syncTarget}
+}
+
+// Apply mirrors imp into the downstream namespace, and deletes the
+// EndpointSlices of SyncTargets that no longer have endpoints.
+func (m *Mirror) Apply(ctx context.Context, namespace string, imp *workloadv1alpha1.ServiceImport) error {
+	service, slices := Desired(imp, m.syncTarget, namespace)
+	if err := m.applyService(ctx, service); err != nil {
+		return err
+	}
+
+	existing, err := m.client.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{LabelSelector: sliceSelector(service.Name)})
+	if err != nil {
+		return err
+	}
+	current := make(map[string]*discoveryv1.EndpointSlice, len(existing.Items))
+	for i := range existing.Items {
+		current[existing.Items[i].Name] = &existing.Items[i]
+	}
+	for _, slice := range slices {
+		found, ok := current[slice.Name]
+		delete(current, slice.Name)
+		if !ok {
+			if _, err := m.client.DiscoveryV1().EndpointSlices(namespace).Create(ctx, slice, metav1.CreateOptions{}); err != nil {
+				return fmt.Errorf("failed to create EndpointSlice %s/%s: %w", namespace, slice.Name, err)
+			}
+			continue
+		}
+		if found.AddressType == slice.AddressType && equality.Semantic.DeepEqual(found.Endpoints, slice.Endpoints) && equality.Semantic.DeepEqual(found.Ports, slice.Ports) {
+			continue
+		}
+		updated := found.DeepCopy()
+		updated.Endpoints, updated.Ports = slice.Endpoints, slice.Ports
+		if _, err := m.client.DiscoveryV1().EndpointSlices(namespace).Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update EndpointSlice %s/%s: %w", namespace, slice.Name, err)
+		}
+	}
+	for name := range current {
+		if err := m.client.DiscoveryV1().EndpointSlices(namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete EndpointSlice %s/%s: %w", namespace, name, err)
+		}
+	}
+	return nil
+}
+
+// Remove deletes the mirror of the ServiceImport name from the downstream
+// namespace.
+func (m *Mirror) Remove(ctx context.Context, namespace, name string) error {
+	name = ImportedName(name)
+	service, err := m.client.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return err
+	case service.Labels[discoveryv1.LabelManagedBy] == ManagedBy:
+		if err := m.client.CoreV1().Services(namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return m.client.DiscoveryV1().EndpointSlices(namespace).DeleteCollection(ctx, metav1.DeleteOptions{}, metav1.ListOptions{LabelSelector: sliceSelector(name)})
+}
+
+// Mirrored returns the names of the ServiceImports mirrored onto the
+// physical cluster by downstream namespace.
+func (m *Mirror) Mirrored(ctx context.Context) (map[string]sets.Set[string], error) {
+	services, err := m.client.CoreV1().Services(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{discoveryv1.LabelManagedBy: ManagedBy}).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	mirrored := map[string]sets.Set[string]{}
+	for _, service := range services.Items {
+		name := service.Annotations[AnnotationImport]
+		if name == "" {
+			continue
+		}
+		if mirrored[service.Namespace] == nil {
+			mirrored[service.Namespace] = sets.New[string]()
+		}
+		mirrored[service.Namespace].Insert(name)
+	}
+	return mirrored, nil
+}
+
+// applyService creates or updates the mirrored Service. Services of the same
+// name not mirrored by the syncer are left alone.
+func (m *Mirror) applyService(ctx context.Context, service *corev1.Service) error {
+	services := m.client.CoreV1().Services(service.Namespace)
+	existing, err := services.Get(ctx, service.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = services.Create(ctx, service, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if existing.Labels[discoveryv1.LabelManagedBy] != ManagedBy {
+		return fmt.Errorf("service %s/%s exists and is not mirrored by the syncer", service.Namespace, service.Name)
+	}
+	if (existing.Spec.ClusterIP == corev1.ClusterIPNone) != (service.Spec.ClusterIP == corev1.ClusterIPNone) {
+		// The cluster IP of a Service cannot change.
+		if err := services.Delete(ctx, service.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		_, err = services.Create(ctx, service, metav1.CreateOptions{})
+		return err
+	}
+	if equality.Semantic.DeepEqual(existing.Spec.Ports, service.Spec.Ports) {
+		return nil
+	}
+	updated := existing.DeepCopy()
+	updated.Spec.Ports = service.Spec.Ports
+	_, err = services.Update(ctx, updated, metav1.UpdateOptions{})
+	return err
+}
+
+func sliceSelector(serviceName string) string {
+	return labels.SelectorFromSet(labels.Set{
+		discoveryv1.LabelServiceName: serviceName,
+		discoveryv1.LabelManagedBy:   ManagedBy,
+	}).String()
+}
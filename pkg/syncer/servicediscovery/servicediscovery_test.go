@@ -0,0 +1,156 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servicediscovery
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var httpPort = workloadv1alpha1.ServicePort{Name: "http", Protocol: corev1.ProtocolTCP, Port: 8080}
+
+func TestEndpoints(t *testing.T) {
+	slices := []discoveryv1.EndpointSlice{
+		{
+			Endpoints: []discoveryv1.Endpoint{
+				{Addresses: []string{"10.0.0.2"}},
+				{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)}},
+				{Addresses: []string{"10.0.0.3"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false)}},
+			},
+			Ports: []discoveryv1.EndpointPort{{Name: ptr.To("http"), Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To[int32](8080)}},
+		},
+		{
+			Endpoints: []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.1"}}},
+			Ports:     []discoveryv1.EndpointPort{{Name: ptr.To("http"), Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To[int32](8080)}},
+		},
+	}
+
+	require.Equal(t, workloadv1alpha1.ServiceEndpoints{
+		SyncTarget: "edge-1",
+		Addresses:  []string{"10.0.0.1", "10.0.0.2"},
+		Ports:      []workloadv1alpha1.ServicePort{httpPort},
+	}, Endpoints("edge-1", slices))
+}
+
+func TestReported(t *testing.T) {
+	export := &workloadv1alpha1.ServiceExport{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"unrelated": "x"}}}
+	for _, endpoints := range []workloadv1alpha1.ServiceEndpoints{
+		{SyncTarget: "edge-2", Addresses: []string{"10.1.0.1"}},
+		{SyncTarget: "edge-1", Addresses: []string{"10.0.0.1"}},
+	} {
+		raw, err := json.Marshal(endpoints)
+		require.NoError(t, err)
+		export.Annotations[EndpointsAnnotation(endpoints.SyncTarget)] = string(raw)
+	}
+
+	reported, err := Reported(export)
+	require.NoError(t, err)
+	require.Equal(t, []string{"edge-1", "edge-2"}, []string{reported[0].SyncTarget, reported[1].SyncTarget})
+
+	export.Annotations[EndpointsAnnotation("edge-3")] = `{"syncTarget":"edge-1"}`
+	reported, err = Reported(export)
+	require.ErrorContains(t, err, `reported for SyncTarget "edge-1"`)
+	require.Len(t, reported, 2, "valid reports are kept")
+
+	patch, err := ReportPatch("edge-1", nil)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"metadata":{"annotations":{"`+EndpointsAnnotation("edge-1")+`":null}}}`, string(patch))
+}
+
+func newImport() *workloadv1alpha1.ServiceImport {
+	return &workloadv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec: workloadv1alpha1.ServiceImportSpec{
+			Type:  workloadv1alpha1.ClusterSetIP,
+			Ports: []workloadv1alpha1.ServicePort{httpPort},
+		},
+		Status: workloadv1alpha1.ServiceImportStatus{Targets: []workloadv1alpha1.ServiceEndpoints{
+			{SyncTarget: "edge-1", Addresses: []string{"10.0.0.1"}, Ports: []workloadv1alpha1.ServicePort{httpPort}},
+			{SyncTarget: "edge-2", Addresses: []string{"10.1.0.1", "fd00::1"}, Ports: []workloadv1alpha1.ServicePort{httpPort}},
+			{SyncTarget: "edge-3"},
+		}},
+	}
+}
+
+func TestDesired(t *testing.T) {
+	service, slices := Desired(newImport(), "edge-1", "kcp-abc")
+	require.Equal(t, "imported-web", service.Name)
+	require.Equal(t, "kcp-abc", service.Namespace)
+	require.Empty(t, service.Spec.Selector)
+	require.Empty(t, service.Spec.ClusterIP)
+	require.Equal(t, int32(8080), service.Spec.Ports[0].TargetPort.IntVal)
+
+	var names []string
+	for _, slice := range slices {
+		names = append(names, slice.Name)
+		require.Equal(t, "imported-web", slice.Labels[discoveryv1.LabelServiceName])
+	}
+	require.Equal(t, []string{"imported-web-edge-2-ipv4", "imported-web-edge-2-ipv6"}, names, "endpoints of the own and empty targets are left out")
+	require.Equal(t, discoveryv1.AddressTypeIPv6, slices[1].AddressType)
+
+	imp := newImport()
+	imp.Spec.Type = workloadv1alpha1.Headless
+	service, _ = Desired(imp, "edge-1", "kcp-abc")
+	require.Equal(t, corev1.ClusterIPNone, service.Spec.ClusterIP)
+}
+
+func TestMirrorApply(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	mirror := NewMirror(client, "edge-1")
+
+	imp := newImport()
+	require.NoError(t, mirror.Apply(ctx, "kcp-abc", imp))
+	slices, err := client.DiscoveryV1().EndpointSlices("kcp-abc").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, slices.Items, 2)
+
+	imp.Status.Targets[1].Addresses = []string{"10.1.0.2"}
+	require.NoError(t, mirror.Apply(ctx, "kcp-abc", imp))
+	slices, err = client.DiscoveryV1().EndpointSlices("kcp-abc").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, slices.Items, 1, "the IPv6 slice is deleted")
+	require.Equal(t, []string{"10.1.0.2"}, slices.Items[0].Endpoints[0].Addresses)
+
+	imp.Spec.Type = workloadv1alpha1.Headless
+	require.NoError(t, mirror.Apply(ctx, "kcp-abc", imp))
+	service, err := client.CoreV1().Services("kcp-abc").Get(ctx, "imported-web", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, corev1.ClusterIPNone, service.Spec.ClusterIP)
+
+	mirrored, err := mirror.Mirrored(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]sets.Set[string]{"kcp-abc": sets.New("web")}, mirrored)
+}
+
+func TestMirrorApplyUnmanagedService(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "kcp-abc", Name: "imported-web"}})
+	err := NewMirror(client, "edge-1").Apply(context.Background(), "kcp-abc", newImport())
+	require.ErrorContains(t, err, "not mirrored by the syncer")
+}
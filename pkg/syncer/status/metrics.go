@@ -0,0 +1,131 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"sync"
+
+	compbasemetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const (
+	resultSuccess  = "success"
+	resultConflict = "conflict"
+	resultNotFound = "not_found"
+	resultError    = "error"
+)
+
+// The ratio of syncer_status_writes_total to syncer_status_updates_total is
+// the write amplification: below 1 when updates are coalesced, above 1 when
+// writes are retried.
+var (
+	statusUpdates = compbasemetrics.NewCounter(
+		&compbasemetrics.CounterOpts{
+			Name:           "syncer_status_updates_total",
+			Help:           "Number of status updates of synced objects queued for writing to kcp.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+	)
+	statusUpdatesCoalesced = compbasemetrics.NewCounter(
+		&compbasemetrics.CounterOpts{
+			Name:           "syncer_status_updates_coalesced_total",
+			Help:           "Number of status updates replaced by a later update of the same object before being written.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+	)
+	statusUpdatesRequeued = compbasemetrics.NewCounter(
+		&compbasemetrics.CounterOpts{
+			Name:           "syncer_status_updates_requeued_total",
+			Help:           "Number of status updates queued again to be written by a later flush because their write failed.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+	)
+	statusWrites = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Name:           "syncer_status_writes_total",
+			Help:           "Number of status writes to kcp, including retries, by result.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"result"},
+	)
+	pendingBytes = compbasemetrics.NewGauge(
+		&compbasemetrics.GaugeOpts{
+			Name:           "syncer_status_pending_bytes",
+			Help:           "Size of the serialized status updates held in memory until they are written.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+	)
+	pendingBytesWatermark = compbasemetrics.NewGauge(
+		&compbasemetrics.GaugeOpts{
+			Name:           "syncer_status_pending_bytes_high_watermark",
+			Help:           "Highest size of the serialized status updates held in memory since the syncer started.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+	)
+	statusUpdatesSpilled = compbasemetrics.NewCounter(
+		&compbasemetrics.CounterOpts{
+			Name:           "syncer_status_updates_spilled_total",
+			Help:           "Number of status updates spilled to disk because they exceeded the memory bounds.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+	)
+	spilledBytes = compbasemetrics.NewGauge(
+		&compbasemetrics.GaugeOpts{
+			Name:           "syncer_status_spilled_bytes",
+			Help:           "Size of the status updates spilled to disk until they are written.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+	)
+	writeBlockedSeconds = compbasemetrics.NewCounter(
+		&compbasemetrics.CounterOpts{
+			Name:           "syncer_status_write_blocked_seconds_total",
+			Help:           "Time status updates waited for memory to be freed because they exceeded the memory bounds and no spill directory is configured.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+	)
+	batchSize = compbasemetrics.NewHistogram(
+		&compbasemetrics.HistogramOpts{
+			Name:           "syncer_status_batch_size",
+			Help:           "Number of objects per flushed workspace batch.",
+			Buckets:        []float64{1, 5, 10, 50, 100, 250, 500, 1000},
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+	)
+)
+
+var registerMetrics sync.Once
+
+// Register metrics.
+func Register() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(statusUpdates)
+		legacyregistry.MustRegister(statusUpdatesCoalesced)
+		legacyregistry.MustRegister(statusUpdatesRequeued)
+		legacyregistry.MustRegister(statusWrites)
+		legacyregistry.MustRegister(pendingBytes)
+		legacyregistry.MustRegister(pendingBytesWatermark)
+		legacyregistry.MustRegister(statusUpdatesSpilled)
+		legacyregistry.MustRegister(spilledBytes)
+		legacyregistry.MustRegister(writeBlockedSeconds)
+		legacyregistry.MustRegister(batchSize)
+	})
+}
+
+func init() {
+	Register()
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// Projection selects the fields of the status written back to kcp, as
+// paths relative to the status. An empty projection selects the whole
+// status.
+type Projection [][]string
+
+// NewProjection parses the fields of spec.upstreamSync of a SyncTarget.
+func NewProjection(fields []string) (Projection, error) {
+	var p Projection
+	for _, field := range fields {
+		path := strings.TrimSpace(field)
+		path = strings.TrimSuffix(strings.TrimPrefix(path, "{"), "}")
+		path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+		segments := strings.Split(path, ".")
+		if segments[0] != "status" {
+			return nil, fmt.Errorf("invalid upstream sync field %q: only fields under status are written back", field)
+		}
+		for _, s := range segments {
+			if s == "" || strings.ContainsAny(s, "[]*") {
+				return nil, fmt.Errorf("invalid upstream sync field %q: expected a dot-separated path", field)
+			}
+		}
+		if len(segments) == 1 {
+			// The whole status.
+			return nil, nil
+		}
+		p = append(p, segments[1:])
+	}
+	return p, nil
+}
+
+// ProjectionFor returns the projection of resource configured by
+// upstreamSync, which may be nil.
+func ProjectionFor(upstreamSync *tmcv1alpha1.UpstreamSync, resource schema.GroupResource) (Projection, error) {
+	if upstreamSync == nil {
+		return nil, nil
+	}
+	for _, r := range upstreamSync.Resources {
+		if r.Group == resource.Group && r.Resource == resource.Resource {
+			return NewProjection(r.Fields)
+		}
+	}
+	return NewProjection(upstreamSync.Fields)
+}
+
+// Apply returns the fields of status selected by the projection. Selected
+// fields missing from status are left out.
+func (p Projection) Apply(status interface{}) interface{} {
+	m, ok := status.(map[string]interface{})
+	if len(p) == 0 || !ok {
+		return status
+	}
+	projected := map[string]interface{}{}
+	for _, path := range p {
+		project(projected, m, path)
+	}
+	return projected
+}
+
+func project(into, from map[string]interface{}, path []string) {
+	value, found := from[path[0]]
+	if !found {
+		return
+	}
+	if len(path) == 1 {
+		into[path[0]] = runtime.DeepCopyJSONValue(value)
+		return
+	}
+	fromChild, ok := value.(map[string]interface{})
+	if !ok {
+		return
+	}
+	intoChild, ok := into[path[0]].(map[string]interface{})
+	if !ok {
+		intoChild = map[string]interface{}{}
+	}
+	project(intoChild, fromChild, path[1:])
+	if len(intoChild) > 0 {
+		into[path[0]] = intoChild
+	}
+}
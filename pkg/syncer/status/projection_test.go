@@ -0,0 +1,74 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func TestNewProjection(t *testing.T) {
+	p, err := NewProjection([]string{"status.conditions", "$.status.loadBalancer.ingress", "{.status.readyReplicas}"})
+	require.NoError(t, err)
+	require.Equal(t, Projection{{"conditions"}, {"loadBalancer", "ingress"}, {"readyReplicas"}}, p)
+
+	p, err = NewProjection([]string{"status.conditions", ".status"})
+	require.NoError(t, err)
+	require.Nil(t, p, "status selects the whole status")
+
+	_, err = NewProjection([]string{"spec.replicas"})
+	require.ErrorContains(t, err, "only fields under status")
+	_, err = NewProjection([]string{"status.conditions[0]"})
+	require.ErrorContains(t, err, "dot-separated path")
+	_, err = NewProjection([]string{"status..conditions"})
+	require.Error(t, err)
+}
+
+func TestProjectionApply(t *testing.T) {
+	status := map[string]interface{}{
+		"readyReplicas": int64(2),
+		"conditions":    []interface{}{map[string]interface{}{"type": "Available"}},
+		"loadBalancer":  map[string]interface{}{"ingress": []interface{}{"10.0.0.1"}, "other": "x"},
+	}
+	p := Projection{{"conditions"}, {"loadBalancer", "ingress"}, {"observedGeneration"}, {"replicas", "missing"}}
+	require.Equal(t, map[string]interface{}{
+		"conditions":   []interface{}{map[string]interface{}{"type": "Available"}},
+		"loadBalancer": map[string]interface{}{"ingress": []interface{}{"10.0.0.1"}},
+	}, p.Apply(status))
+	require.Equal(t, status, Projection(nil).Apply(status))
+}
+
+func TestProjectionFor(t *testing.T) {
+	upstreamSync := &tmcv1alpha1.UpstreamSync{
+		Fields:    []string{"status.conditions"},
+		Resources: []tmcv1alpha1.UpstreamSyncResource{{Group: "apps", Resource: "deployments", Fields: []string{"status.readyReplicas"}}},
+	}
+	p, err := ProjectionFor(upstreamSync, schema.GroupResource{Group: "apps", Resource: "deployments"})
+	require.NoError(t, err)
+	require.Equal(t, Projection{{"readyReplicas"}}, p)
+	p, err = ProjectionFor(upstreamSync, schema.GroupResource{Resource: "services"})
+	require.NoError(t, err)
+	require.Equal(t, Projection{{"conditions"}}, p)
+	p, err = ProjectionFor(nil, schema.GroupResource{Resource: "services"})
+	require.NoError(t, err)
+	require.Nil(t, p)
+}
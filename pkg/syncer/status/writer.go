@@ -0,0 +1,619 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package status writes the status of synced objects back to kcp. Updates
+// are collected per workspace and flushed periodically, so that an object
+// whose status changes several times within a flush interval is written
+// only once. Writes use server-side apply on the status subresource and
+// retry conflicts with jittered backoff. Updates whose write failed
+// otherwise, e.g. because the connection was reset or the server is
+// unavailable, are queued again and written by a later flush, backing off
+// exponentially, unless a newer status of the object replaced them.
+//
+// The memory held by pending updates can be bounded. Updates beyond the
+// bound are spilled to disk until they are written, or, without a spill
+// directory, make the informers block until a flush frees memory, so that
+// status bursts on large clusters cannot exhaust the memory of the syncer.
+//
+// The status written can be narrowed down to some of its fields by a
+// Projection, usually configured by spec.upstreamSync of the SyncTarget.
+//
+// Objects placed on several SyncTargets have a status per SyncTarget. The
+// writer of a SyncTarget then reports the status in an annotation of the
+// object instead, which the status aggregation controller aggregates into
+// its status.
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/klog/v2"
+
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/statusaggregation"
+	"github.com/kcp-dev/kcp/pkg/syncer/naming"
+	"github.com/kcp-dev/kcp/pkg/tmc/retry"
+)
+
+const (
+	defaultFieldManager  = "kcp-syncer-status"
+	defaultFlushInterval = time.Second
+	defaultMaxBatchSize  = 500
+	defaultConcurrency   = 4
+
+	// maxRequeueBackoff bounds the delay before an update whose write
+	// failed is written again.
+	maxRequeueBackoff = time.Minute
+
+	// maxFieldManagerLength is the maximum length of a field manager.
+	maxFieldManagerLength = 128
+)
+
+// Options configure a Writer. Zero values mean the defaults.
+type Options struct {
+	// FieldManager is the field manager of the status writes.
+	FieldManager string
+	// FlushInterval is how often pending updates are written.
+	FlushInterval time.Duration
+	// MaxBatchSize is the number of pending updates of a workspace that
+	// triggers a flush of the workspace before the interval passed.
+	MaxBatchSize int
+	// Retry is how writes failing with a conflict or because the server is
+	// overloaded are retried. Defaults to the standard retry profile.
+	Retry *retry.Strategy
+	// MaxRetries overrides the retries of Retry. Negative values disable
+	// retries.
+	MaxRetries int
+	// RetryBackoff overrides the delay before the first retry of Retry.
+	RetryBackoff time.Duration
+	// Concurrency is the number of workspaces flushed in parallel.
+	Concurrency int
+	// MaxPendingBytes bounds the memory held by pending updates, measured
+	// as the size of their serialized status. Zero means unbounded.
+	MaxPendingBytes int64
+	// MaxPendingBytesPerResource bounds the memory held by pending updates
+	// of a single resource, so that a burst of one resource does not take
+	// the memory of all others. Zero means unbounded.
+	MaxPendingBytesPerResource int64
+	// SpillDir is the directory updates exceeding the memory bounds are
+	// spilled to until they are written. Without it, Write blocks until a
+	// flush frees enough memory.
+	SpillDir string
+	// Paused holds back flushes while it returns true, e.g. while the
+	// SyncTarget is paused. Pending updates are kept and written once it
+	// returns false, and Write blocks or spills when they exceed the memory
+	// bounds meanwhile.
+	Paused func() bool
+	// SyncTarget, if set, makes the writer report the status in the
+	// annotation of the SyncTarget, see statusaggregation.TargetAnnotation,
+	// for the status to be aggregated with the statuses reported by other
+	// SyncTargets. Each SyncTarget manages its annotation with a field
+	// manager of its own.
+	SyncTarget string
+	// Projection returns the fields of the status of a resource that are
+	// written, see ProjectionFor. The whole status is written if nil.
+	Projection func(gvr schema.GroupVersionResource) Projection
+}
+
+// ApplyFunc applies the status of obj in the given logical cluster.
+type ApplyFunc func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error
+
+type key struct {
+	gvr       schema.GroupVersionResource
+	namespace string
+	name      string
+}
+
+// update is a pending status update, held in memory or spilled to disk.
+type update struct {
+	obj  *unstructured.Unstructured
+	size int64
+	// spilled is the file the update is spilled to, if obj is nil.
+	spilled string
+	// failures is the number of flushes that failed to write the update,
+	// which is not written again before notBefore.
+	failures  int
+	notBefore time.Time
+}
+
+// Writer batches status updates per workspace.
+type Writer struct {
+	options Options
+	apply   ApplyFunc
+	retry   retry.Strategy
+
+	lock    sync.Mutex
+	pending map[logicalcluster.Name]map[key]*update
+	full    chan logicalcluster.Name
+	// pressure is signalled when Write waits for memory to be freed.
+	pressure chan struct{}
+	// freed is broadcast when memory is freed or the writer stops.
+	freed   *sync.Cond
+	stopped bool
+
+	// memory is the size of the updates held in memory, in total and per
+	// resource, including those being written.
+	memory          int64
+	resourceMemory  map[schema.GroupVersionResource]int64
+	memoryWatermark int64
+	spillDir        string
+	spillSequence   int
+}
+
+// NewWriter returns a writer applying status with the given client.
+func NewWriter(client kcpdynamic.ClusterInterface, options Options) *Writer {
+	w := newWriter(nil, options)
+	w.apply = func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+		if w.options.SyncTarget != "" {
+			_, err := client.Cluster(clusterName.Path()).Resource(gvr).Namespace(obj.GetNamespace()).Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{
+				FieldManager: w.options.FieldManager,
+				Force:        true,
+			})
+			return err
+		}
+		_, err := client.Cluster(clusterName.Path()).Resource(gvr).Namespace(obj.GetNamespace()).ApplyStatus(ctx, obj.GetName(), obj, metav1.ApplyOptions{
+			FieldManager: w.options.FieldManager,
+			Force:        true,
+		})
+		return err
+	}
+	return w
+}
+
+func newWriter(apply ApplyFunc, options Options) *Writer {
+	if options.FieldManager == "" {
+		options.FieldManager = defaultFieldManager
+	}
+	if options.SyncTarget != "" {
+		options.FieldManager = naming.Bounded(options.FieldManager+"-"+options.SyncTarget, maxFieldManagerLength)
+	}
+	if options.FlushInterval <= 0 {
+		options.FlushInterval = defaultFlushInterval
+	}
+	if options.MaxBatchSize <= 0 {
+		options.MaxBatchSize = defaultMaxBatchSize
+	}
+	strategy := retry.Standard()
+	if options.Retry != nil {
+		strategy = *options.Retry
+	}
+	switch {
+	case options.MaxRetries > 0:
+		strategy.MaxRetries = options.MaxRetries
+	case options.MaxRetries < 0:
+		strategy.MaxRetries = 0
+	}
+	if options.RetryBackoff > 0 {
+		strategy.InitialBackoff = options.RetryBackoff
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = defaultConcurrency
+	}
+	w := &Writer{
+		options:        options,
+		apply:          apply,
+		retry:          strategy,
+		pending:        map[logicalcluster.Name]map[key]*update{},
+		full:           make(chan logicalcluster.Name, 1),
+		pressure:       make(chan struct{}, 1),
+		resourceMemory: map[schema.GroupVersionResource]int64{},
+	}
+	w.freed = sync.NewCond(&w.lock)
+	return w
+}
+
+// Write queues the status of obj to be written to the given logical
+// cluster. It replaces a status of the same object that is not written yet.
+func (w *Writer) Write(clusterName logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) {
+	status, found := obj.Object["status"]
+	if !found {
+		return
+	}
+	if w.options.Projection != nil {
+		status = w.options.Projection(gvr).Apply(status)
+	}
+	applied := &unstructured.Unstructured{Object: map[string]interface{}{"status": status}}
+	if w.options.SyncTarget != "" {
+		data, err := json.Marshal(status)
+		if err != nil {
+			utilruntime.HandleError(fmt.Errorf("failed to serialize status of %s %s/%s: %w", gvr, obj.GetNamespace(), obj.GetName(), err))
+			return
+		}
+		applied = &unstructured.Unstructured{Object: map[string]interface{}{}}
+		applied.SetAnnotations(map[string]string{statusaggregation.TargetAnnotation(w.options.SyncTarget): string(data)})
+	}
+	applied.SetAPIVersion(obj.GetAPIVersion())
+	applied.SetKind(obj.GetKind())
+	applied.SetNamespace(obj.GetNamespace())
+	applied.SetName(obj.GetName())
+
+	data, err := applied.MarshalJSON()
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("failed to serialize status of %s %s/%s: %w", gvr, obj.GetNamespace(), obj.GetName(), err))
+		return
+	}
+	size := int64(len(data))
+	k := key{gvr: gvr, namespace: obj.GetNamespace(), name: obj.GetName()}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	statusUpdates.Inc()
+	spill := false
+	if w.exceedsMemory(clusterName, k, size) {
+		if w.options.SpillDir != "" {
+			spill = true
+		} else {
+			start := time.Now()
+			for w.exceedsMemory(clusterName, k, size) && !w.stopped {
+				select {
+				case w.pressure <- struct{}{}:
+				default:
+				}
+				w.freed.Wait()
+			}
+			writeBlockedSeconds.Add(time.Since(start).Seconds())
+		}
+	}
+
+	batch, found := w.pending[clusterName]
+	if !found {
+		batch = map[key]*update{}
+		w.pending[clusterName] = batch
+	}
+	if prev, found := batch[k]; found {
+		statusUpdatesCoalesced.Inc()
+		w.drop(k.gvr, prev)
+	}
+	u := &update{obj: applied, size: size}
+	if spill {
+		if err := w.spill(u, data); err != nil {
+			// Rather hold the update in memory than lose it.
+			utilruntime.HandleError(fmt.Errorf("failed to spill status update: %w", err))
+		}
+	}
+	if u.obj != nil {
+		w.allocate(k.gvr, size)
+	}
+	batch[k] = u
+	if len(batch) == w.options.MaxBatchSize {
+		select {
+		case w.full <- clusterName:
+		default:
+		}
+	}
+}
+
+// exceedsMemory returns whether holding an update of the given size in
+// memory exceeds the memory bounds. The update it replaces is accounted
+// for, and an update larger than the bounds is allowed if no other memory
+// is held, so that it is not blocked forever.
+func (w *Writer) exceedsMemory(clusterName logicalcluster.Name, k key, size int64) bool {
+	if prev, found := w.pending[clusterName][k]; found && prev.obj != nil {
+		size -= prev.size
+	}
+	if max := w.options.MaxPendingBytes; max > 0 && w.memory > 0 && w.memory+size > max {
+		return true
+	}
+	if max := w.options.MaxPendingBytesPerResource; max > 0 && w.resourceMemory[k.gvr] > 0 && w.resourceMemory[k.gvr]+size > max {
+		return true
+	}
+	return false
+}
+
+// spill writes the update to the spill directory and releases its object.
+func (w *Writer) spill(u *update, data []byte) error {
+	if w.spillDir == "" {
+		if err := os.MkdirAll(w.options.SpillDir, 0o700); err != nil {
+			return err
+		}
+		// A directory of its own, so that cleaning up cannot remove
+		// anything but spilled updates.
+		dir, err := os.MkdirTemp(w.options.SpillDir, "status-")
+		if err != nil {
+			return err
+		}
+		w.spillDir = dir
+	}
+	w.spillSequence++
+	path := filepath.Join(w.spillDir, fmt.Sprintf("%d.json", w.spillSequence))
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return err
+	}
+	u.obj, u.spilled = nil, path
+	statusUpdatesSpilled.Inc()
+	spilledBytes.Add(float64(u.size))
+	return nil
+}
+
+// unspill reads a spilled update and removes it from disk. The update is
+// left on disk if it cannot be read.
+func (w *Writer) unspill(u *update) (*unstructured.Unstructured, error) {
+	data, err := os.ReadFile(u.spilled)
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	os.Remove(u.spilled) //nolint:errcheck
+	spilledBytes.Add(-float64(u.size))
+	return obj, nil
+}
+
+// drop discards a pending update that was not taken for writing.
+func (w *Writer) drop(gvr schema.GroupVersionResource, u *update) {
+	if u.obj != nil {
+		w.release(gvr, u.size)
+		return
+	}
+	os.Remove(u.spilled) //nolint:errcheck
+	spilledBytes.Add(-float64(u.size))
+}
+
+func (w *Writer) allocate(gvr schema.GroupVersionResource, size int64) {
+	w.memory += size
+	w.resourceMemory[gvr] += size
+	pendingBytes.Add(float64(size))
+	if w.memory > w.memoryWatermark {
+		w.memoryWatermark = w.memory
+		pendingBytesWatermark.Set(float64(w.memory))
+	}
+}
+
+func (w *Writer) release(gvr schema.GroupVersionResource, size int64) {
+	w.memory -= size
+	if w.resourceMemory[gvr] -= size; w.resourceMemory[gvr] <= 0 {
+		delete(w.resourceMemory, gvr)
+	}
+	pendingBytes.Add(-float64(size))
+	w.freed.Broadcast()
+}
+
+// Run flushes pending updates until ctx is done, and a last time after,
+// unless the writer is paused.
+func (w *Writer) Run(ctx context.Context) {
+	defer utilruntime.HandleCrash()
+
+	logger := klog.FromContext(ctx)
+	logger.Info("Starting status writer")
+	defer logger.Info("Shutting down status writer")
+
+	ticker := time.NewTicker(w.options.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// Write what is left without retrying for long.
+			if !w.paused() {
+				flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), w.options.FlushInterval)
+				w.Flush(flushCtx)
+				cancel()
+			}
+			w.stop()
+			return
+		case <-ticker.C:
+			if !w.paused() {
+				w.Flush(ctx)
+			}
+		case clusterName := <-w.full:
+			// Full workspaces of a paused writer are flushed with the
+			// others once it is resumed.
+			if !w.paused() {
+				w.flushWorkspace(ctx, clusterName, w.take(clusterName))
+			}
+		case <-w.pressure:
+			if !w.paused() {
+				w.Flush(ctx)
+			}
+		}
+	}
+}
+
+func (w *Writer) paused() bool {
+	return w.options.Paused != nil && w.options.Paused()
+}
+
+// stop releases writers waiting for memory and removes spilled updates
+// that could not be written.
+func (w *Writer) stop() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.stopped = true
+	w.freed.Broadcast()
+	if w.spillDir != "" {
+		os.RemoveAll(w.spillDir) //nolint:errcheck
+		w.spillDir = ""
+	}
+}
+
+// Flush writes all pending updates, flushing up to Concurrency workspaces
+// in parallel.
+func (w *Writer) Flush(ctx context.Context) {
+	w.lock.Lock()
+	pending := w.pending
+	w.pending = map[logicalcluster.Name]map[key]*update{}
+	w.lock.Unlock()
+
+	sem := make(chan struct{}, w.options.Concurrency)
+	var wg sync.WaitGroup
+	for clusterName, batch := range pending {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			w.flushWorkspace(ctx, clusterName, batch)
+		}()
+	}
+	wg.Wait()
+}
+
+func (w *Writer) take(clusterName logicalcluster.Name) map[key]*update {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	batch := w.pending[clusterName]
+	delete(w.pending, clusterName)
+	return batch
+}
+
+func (w *Writer) flushWorkspace(ctx context.Context, clusterName logicalcluster.Name, batch map[key]*update) {
+	if len(batch) == 0 {
+		return
+	}
+	logger := klog.FromContext(ctx).WithValues("cluster", clusterName)
+	logger.V(4).Info("writing status", "objects", len(batch))
+	batchSize.Observe(float64(len(batch)))
+	now := time.Now()
+	for k, u := range batch {
+		if u.notBefore.After(now) {
+			// Backing off after a failed write.
+			w.requeue(clusterName, k, u, false)
+			continue
+		}
+		obj := u.obj
+		if obj == nil {
+			var err error
+			if obj, err = w.unspill(u); err != nil {
+				// The update stays on disk and is read again by a later
+				// flush, unless a newer status replaces it.
+				logger.Error(err, "failed to read spilled status", "resource", k.gvr.String(), "namespace", k.namespace, "name", k.name, "failures", u.failures+1)
+				failed := &update{size: u.size, spilled: u.spilled, failures: u.failures + 1}
+				failed.notBefore = time.Now().Add(w.requeueDelay(failed.failures))
+				statusUpdatesRequeued.Inc()
+				w.requeue(clusterName, k, failed, false)
+				continue
+			}
+		}
+		err := w.write(ctx, clusterName, k, obj)
+		if err != nil {
+			logger.V(2).Info("failed to write status", "resource", k.gvr.String(), "namespace", k.namespace, "name", k.name, "failures", u.failures+1, "err", err)
+		}
+		if err != nil && !retry.Permanent(err) {
+			failed := &update{obj: obj, size: u.size, failures: u.failures + 1}
+			failed.notBefore = time.Now().Add(w.requeueDelay(failed.failures))
+			statusUpdatesRequeued.Inc()
+			w.requeue(clusterName, k, failed, u.obj == nil)
+			continue
+		}
+		if u.obj != nil {
+			w.lock.Lock()
+			w.release(k.gvr, u.size)
+			w.lock.Unlock()
+		}
+	}
+}
+
+// requeue puts u back into the pending updates, unless a newer status of
+// the object is pending or the writer stopped. unspilled is whether u was
+// read back from disk, and its memory is not accounted for yet. Requeued
+// updates are held in memory even beyond the memory bounds, as they were
+// accepted already.
+func (w *Writer) requeue(clusterName logicalcluster.Name, k key, u *update, unspilled bool) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if unspilled {
+		w.allocate(k.gvr, u.size)
+	}
+	batch, found := w.pending[clusterName]
+	if _, superseded := batch[k]; superseded || w.stopped {
+		w.drop(k.gvr, u)
+		return
+	}
+	if !found {
+		batch = map[key]*update{}
+		w.pending[clusterName] = batch
+	}
+	batch[k] = u
+}
+
+// requeueDelay returns the jittered delay before an update is written again
+// after the given number of failed flushes.
+func (w *Writer) requeueDelay(failures int) time.Duration {
+	return retry.Strategy{
+		InitialBackoff: w.options.FlushInterval,
+		MaxBackoff:     maxRequeueBackoff,
+		Factor:         2,
+		Jitter:         w.retry.Jitter,
+	}.Delay(failures)
+}
+
+// write applies obj, retrying conflicts and overload errors unless a newer
+// status of the object is pending, which is written with the next flush.
+func (w *Writer) write(ctx context.Context, clusterName logicalcluster.Name, k key, obj *unstructured.Unstructured) error {
+	retriable := func(err error) bool {
+		return retry.Transient(err) && !w.superseded(clusterName, k)
+	}
+	return w.retry.Do(ctx, retriable, func(ctx context.Context) error {
+		err := w.apply(ctx, clusterName, k.gvr, obj)
+		statusWrites.WithLabelValues(result(err)).Inc()
+		return err
+	})
+}
+
+func (w *Writer) superseded(clusterName logicalcluster.Name, k key) bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	_, found := w.pending[clusterName][k]
+	return found
+}
+
+func result(err error) string {
+	switch {
+	case err == nil:
+		return resultSuccess
+	case apierrors.IsConflict(err):
+		return resultConflict
+	case apierrors.IsNotFound(err):
+		// The object was deleted upstream after the status changed.
+		return resultNotFound
+	default:
+		return resultError
+	}
+}
+
+// Pending returns the number of updates not written yet.
+func (w *Writer) Pending() int {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	n := 0
+	for _, batch := range w.pending {
+		n += len(batch)
+	}
+	return n
+}
+
+// PendingBytes returns the size of the updates held in memory, including
+// those being written.
+func (w *Writer) PendingBytes() int64 {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.memory
+}
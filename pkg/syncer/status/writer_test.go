@@ -0,0 +1,419 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+)
+
+var deploymentsGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+type fakeAPI struct {
+	lock    sync.Mutex
+	applied []string
+	status  map[string]interface{}
+	errs    map[string][]error
+}
+
+func newFakeAPI() *fakeAPI {
+	return &fakeAPI{status: map[string]interface{}{}, errs: map[string][]error{}}
+}
+
+func (f *fakeAPI) apply(_ context.Context, clusterName logicalcluster.Name, _ schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	id := fmt.Sprintf("%s|%s/%s", clusterName, obj.GetNamespace(), obj.GetName())
+	f.applied = append(f.applied, id)
+	if errs := f.errs[id]; len(errs) > 0 {
+		f.errs[id] = errs[1:]
+		return errs[0]
+	}
+	f.status[id] = obj.Object["status"]
+	return nil
+}
+
+func deployment(name string, replicas int64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"namespace": "default", "name": name, "labels": map[string]interface{}{"app": name}},
+		"spec":       map[string]interface{}{"replicas": replicas},
+		"status":     map[string]interface{}{"readyReplicas": replicas},
+	}}
+}
+
+func TestWriterCoalescesUpdates(t *testing.T) {
+	api := newFakeAPI()
+	w := newWriter(api.apply, Options{})
+
+	for i := range 10 {
+		w.Write("root:a", deploymentsGVR, deployment("web", int64(i)))
+	}
+	w.Write("root:a", deploymentsGVR, deployment("db", 1))
+	w.Write("root:b", deploymentsGVR, deployment("web", 1))
+	require.Equal(t, 3, w.Pending())
+
+	w.Flush(context.Background())
+	require.Len(t, api.applied, 3, "one write per object")
+	require.Equal(t, map[string]interface{}{"readyReplicas": int64(9)}, api.status["root:a|default/web"], "the latest status is written")
+	require.Equal(t, 0, w.Pending())
+}
+
+func TestWriterAppliesOnlyStatus(t *testing.T) {
+	var got *unstructured.Unstructured
+	w := newWriter(func(_ context.Context, _ logicalcluster.Name, _ schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+		got = obj
+		return nil
+	}, Options{})
+
+	w.Write("root:a", deploymentsGVR, deployment("web", 2))
+	w.Write("root:a", deploymentsGVR, &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1", "kind": "Deployment", "metadata": map[string]interface{}{"name": "no-status"},
+	}})
+	w.Flush(context.Background())
+
+	require.Equal(t, map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"namespace": "default", "name": "web"},
+		"status":     map[string]interface{}{"readyReplicas": int64(2)},
+	}, got.Object)
+}
+
+func TestWriterProjectsStatus(t *testing.T) {
+	api := newFakeAPI()
+	w := newWriter(api.apply, Options{Projection: func(schema.GroupVersionResource) Projection {
+		return Projection{{"conditions"}}
+	}})
+
+	obj := deployment("web", 2)
+	obj.Object["status"].(map[string]interface{})["conditions"] = []interface{}{map[string]interface{}{"type": "Available", "status": "True"}}
+	w.Write("root:a", deploymentsGVR, obj)
+	w.Flush(context.Background())
+
+	require.Equal(t, map[string]interface{}{
+		"conditions": []interface{}{map[string]interface{}{"type": "Available", "status": "True"}},
+	}, api.status["root:a|default/web"], "fields not projected are not written")
+	require.Equal(t, int64(2), obj.Object["status"].(map[string]interface{})["readyReplicas"], "obj is not modified")
+}
+
+func TestWriterReportsStatusOfSyncTarget(t *testing.T) {
+	var got *unstructured.Unstructured
+	w := newWriter(func(_ context.Context, _ logicalcluster.Name, _ schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+		got = obj
+		return nil
+	}, Options{SyncTarget: "east"})
+	require.Equal(t, "kcp-syncer-status-east", w.options.FieldManager)
+
+	w.Write("root:a", deploymentsGVR, deployment("web", 2))
+	w.Flush(context.Background())
+
+	require.Equal(t, map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"namespace":   "default",
+			"name":        "web",
+			"annotations": map[string]interface{}{"status.workload.kcp.io/east": `{"readyReplicas":2}`},
+		},
+	}, got.Object)
+}
+
+func TestWriterRetries(t *testing.T) {
+	conflict := apierrors.NewConflict(schema.GroupResource{Group: "apps", Resource: "deployments"}, "web", fmt.Errorf("changed"))
+	forbidden := apierrors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "deployments"}, "db", fmt.Errorf("denied"))
+
+	api := newFakeAPI()
+	api.errs["root:a|default/web"] = []error{conflict, apierrors.NewTooManyRequests("slow down", 1)}
+	api.errs["root:a|default/db"] = []error{forbidden}
+	api.errs["root:a|default/cache"] = []error{conflict, conflict, conflict}
+	w := newWriter(api.apply, Options{RetryBackoff: time.Millisecond, MaxRetries: 2})
+
+	w.Write("root:a", deploymentsGVR, deployment("web", 1))
+	w.Write("root:a", deploymentsGVR, deployment("db", 1))
+	w.Write("root:a", deploymentsGVR, deployment("cache", 1))
+	w.Flush(context.Background())
+
+	count := map[string]int{}
+	for _, id := range api.applied {
+		count[id]++
+	}
+	require.Equal(t, map[string]int{
+		"root:a|default/web":   3,
+		"root:a|default/db":    1,
+		"root:a|default/cache": 3,
+	}, count, "conflicts and overload are retried up to MaxRetries, other errors are not")
+	require.Contains(t, api.status, "root:a|default/web")
+	require.NotContains(t, api.status, "root:a|default/cache")
+}
+
+func TestWriterStopsRetryingSupersededStatus(t *testing.T) {
+	conflict := apierrors.NewConflict(schema.GroupResource{Group: "apps", Resource: "deployments"}, "web", fmt.Errorf("changed"))
+
+	var w *Writer
+	api := newFakeAPI()
+	api.errs["root:a|default/web"] = []error{conflict}
+	w = newWriter(func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+		if len(api.applied) == 0 {
+			// A newer status arrives while the first write is in flight.
+			w.Write(clusterName, gvr, deployment("web", 5))
+		}
+		return api.apply(ctx, clusterName, gvr, obj)
+	}, Options{RetryBackoff: time.Millisecond})
+
+	w.Write("root:a", deploymentsGVR, deployment("web", 1))
+	w.Flush(context.Background())
+	require.Len(t, api.applied, 1, "the stale status is not retried")
+
+	w.Flush(context.Background())
+	require.Equal(t, map[string]interface{}{"readyReplicas": int64(5)}, api.status["root:a|default/web"])
+}
+
+func TestWriterRequeuesFailedWrites(t *testing.T) {
+	reset := fmt.Errorf("read tcp: %w", syscall.ECONNRESET)
+	notFound := apierrors.NewNotFound(schema.GroupResource{Group: "apps", Resource: "deployments"}, "db")
+
+	api := newFakeAPI()
+	api.errs["root:a|default/web"] = []error{reset}
+	api.errs["root:a|default/db"] = []error{notFound}
+	w := newWriter(api.apply, Options{FlushInterval: time.Hour})
+
+	w.Write("root:a", deploymentsGVR, deployment("web", 1))
+	w.Write("root:a", deploymentsGVR, deployment("db", 1))
+	w.Flush(context.Background())
+	require.Len(t, api.applied, 2, "transport errors are not retried within the flush")
+	require.Equal(t, 1, w.Pending(), "the failed update is queued again, unless the object is gone")
+
+	w.Flush(context.Background())
+	require.Len(t, api.applied, 2, "the failed update is not written again before its backoff")
+	require.NotZero(t, w.PendingBytes(), "the failed update is held in memory")
+
+	w.Write("root:a", deploymentsGVR, deployment("web", 2))
+	require.Equal(t, 1, w.Pending())
+	w.Flush(context.Background())
+	require.Equal(t, map[string]interface{}{"readyReplicas": int64(2)}, api.status["root:a|default/web"], "a newer status replaces the failed one")
+	require.Equal(t, 0, w.Pending())
+	require.Zero(t, w.PendingBytes())
+}
+
+func TestWriterRetriesRequeuedWritesAfterBackoff(t *testing.T) {
+	api := newFakeAPI()
+	api.errs["root:a|default/web"] = []error{apierrors.NewServiceUnavailable("unavailable")}
+	w := newWriter(api.apply, Options{FlushInterval: time.Millisecond})
+
+	w.Write("root:a", deploymentsGVR, deployment("web", 1))
+	w.Flush(context.Background())
+	require.NotContains(t, api.status, "root:a|default/web")
+
+	require.Eventually(t, func() bool {
+		w.Flush(context.Background())
+		api.lock.Lock()
+		defer api.lock.Unlock()
+		_, found := api.status["root:a|default/web"]
+		return found
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "the failed update is written by a later flush")
+	require.Equal(t, 0, w.Pending())
+}
+
+func TestWriterFlushesFullBatches(t *testing.T) {
+	api := newFakeAPI()
+	w := newWriter(api.apply, Options{FlushInterval: time.Hour, MaxBatchSize: 3})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Run(ctx)
+	}()
+
+	for i := range 3 {
+		w.Write("root:a", deploymentsGVR, deployment(fmt.Sprintf("web-%d", i), 1))
+	}
+	w.Write("root:b", deploymentsGVR, deployment("web", 1))
+	require.Eventually(t, func() bool { return w.Pending() == 1 }, wait.ForeverTestTimeout, 10*time.Millisecond, "the full workspace is flushed before the interval")
+
+	cancel()
+	<-done
+	require.Len(t, api.applied, 4, "pending updates are flushed on shutdown")
+}
+
+func TestWriterHoldsUpdatesWhilePaused(t *testing.T) {
+	api := newFakeAPI()
+	var paused atomic.Bool
+	paused.Store(true)
+	w := newWriter(api.apply, Options{FlushInterval: 10 * time.Millisecond, MaxBatchSize: 1, Paused: paused.Load})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Run(ctx)
+	}()
+
+	w.Write("root:a", deploymentsGVR, deployment("web", 1))
+	w.Write("root:b", deploymentsGVR, deployment("web", 1))
+	time.Sleep(50 * time.Millisecond)
+	api.lock.Lock()
+	require.Empty(t, api.applied, "nothing is written while paused")
+	api.lock.Unlock()
+	require.Equal(t, 2, w.Pending(), "updates are kept while paused")
+
+	paused.Store(false)
+	require.Eventually(t, func() bool { return w.Pending() == 0 }, wait.ForeverTestTimeout, 10*time.Millisecond, "updates are written once resumed")
+	cancel()
+	<-done
+	require.Len(t, api.applied, 2)
+}
+
+func TestWriterSpillsBeyondMemoryBound(t *testing.T) {
+	api := newFakeAPI()
+	size := int64(len(mustMarshal(t, "web-0")))
+	spillDir := t.TempDir()
+	w := newWriter(api.apply, Options{MaxPendingBytes: 2 * size, SpillDir: spillDir})
+
+	for i := range 5 {
+		w.Write("root:a", deploymentsGVR, deployment(fmt.Sprintf("web-%d", i), 1))
+	}
+	require.Equal(t, 5, w.Pending())
+	require.Equal(t, 2*size, w.PendingBytes(), "updates beyond the bound are not held in memory")
+	require.Len(t, spilled(t, spillDir), 3)
+
+	w.Write("root:a", deploymentsGVR, deployment("web-4", 7))
+	require.Len(t, spilled(t, spillDir), 3, "a spilled update replaced by a newer one is removed")
+
+	w.Flush(context.Background())
+	require.Len(t, api.applied, 5)
+	require.Equal(t, map[string]interface{}{"readyReplicas": int64(7)}, api.status["root:a|default/web-4"], "spilled updates are written")
+	require.Zero(t, w.PendingBytes())
+	require.Empty(t, spilled(t, spillDir))
+}
+
+func TestWriterRequeuesUnreadableSpilledUpdates(t *testing.T) {
+	api := newFakeAPI()
+	size := int64(len(mustMarshal(t, "web-0")))
+	spillDir := t.TempDir()
+	w := newWriter(api.apply, Options{FlushInterval: time.Millisecond, MaxPendingBytes: size, SpillDir: spillDir})
+
+	w.Write("root:a", deploymentsGVR, deployment("web-0", 1))
+	w.Write("root:a", deploymentsGVR, deployment("web-1", 1))
+	files := spilled(t, spillDir)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(files[0], []byte("{"), 0o600))
+
+	w.Flush(context.Background())
+	require.Len(t, api.applied, 1)
+	require.Equal(t, 1, w.Pending(), "the unreadable update is kept")
+	require.Len(t, spilled(t, spillDir), 1)
+
+	require.NoError(t, os.WriteFile(files[0], data, 0o600))
+	require.Eventually(t, func() bool {
+		w.Flush(context.Background())
+		return w.Pending() == 0
+	}, wait.ForeverTestTimeout, 10*time.Millisecond)
+	require.Equal(t, map[string]interface{}{"readyReplicas": int64(1)}, api.status["root:a|default/web-1"], "the update is written once readable")
+	require.Empty(t, spilled(t, spillDir))
+}
+
+func TestWriterBoundsMemoryPerResource(t *testing.T) {
+	api := newFakeAPI()
+	size := int64(len(mustMarshal(t, "web-0")))
+	spillDir := t.TempDir()
+	w := newWriter(api.apply, Options{MaxPendingBytesPerResource: size, SpillDir: spillDir})
+
+	w.Write("root:a", deploymentsGVR, deployment("web-0", 1))
+	w.Write("root:a", deploymentsGVR, deployment("web-1", 1))
+	w.Write("root:a", schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "statefulsets"}, deployment("web-2", 1))
+	require.Equal(t, 2*size, w.PendingBytes())
+	require.Len(t, spilled(t, spillDir), 1, "only the resource beyond its bound spills")
+}
+
+func TestWriterBlocksWithoutSpillDir(t *testing.T) {
+	api := newFakeAPI()
+	size := int64(len(mustMarshal(t, "web-0")))
+	w := newWriter(api.apply, Options{FlushInterval: time.Hour, MaxPendingBytes: size})
+
+	w.Write("root:a", deploymentsGVR, deployment("web-0", 1))
+	w.Write("root:a", deploymentsGVR, deployment("web-0", 2))
+	require.Equal(t, size, w.PendingBytes(), "replacing an update does not need more memory")
+
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		w.Write("root:a", deploymentsGVR, deployment("web-1", 1))
+	}()
+	select {
+	case <-written:
+		t.Fatal("write beyond the memory bound did not block")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Run(ctx)
+	}()
+	<-written
+	require.Eventually(t, func() bool {
+		api.lock.Lock()
+		defer api.lock.Unlock()
+		return len(api.applied) > 0
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "the blocked write triggers a flush")
+
+	cancel()
+	<-done
+	require.Len(t, api.applied, 2)
+	require.Zero(t, w.PendingBytes())
+}
+
+func mustMarshal(t *testing.T, name string) []byte {
+	t.Helper()
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"status": deployment(name, 1).Object["status"]}}
+	obj.SetAPIVersion("apps/v1")
+	obj.SetKind("Deployment")
+	obj.SetNamespace("default")
+	obj.SetName(name)
+	data, err := obj.MarshalJSON()
+	require.NoError(t, err)
+	return data
+}
+
+func spilled(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+	require.NoError(t, err)
+	return files
+}
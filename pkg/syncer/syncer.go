@@ -0,0 +1,827 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package syncer runs the syncer of a SyncTarget on its physical cluster.
+// The syncer reads its SyncTarget once from the workspace to learn its
+// logical cluster, and afterwards talks to the workspace only through the
+// syncer virtual workspace: it renews the heartbeat Lease of the SyncTarget
+// and syncs the objects of the configured resources down to the physical
+// cluster. Only the objects placed on the SyncTarget are synced: the
+// workloads of the WorkloadDistributions synced to it, see
+// WorkloadDistribution.SyncedTargets, and the objects exported with them,
+// see closure.Placed. Objects no longer placed are deleted downstream. The
+// status of the downstream objects is reported in an annotation of their
+// upstream objects per SyncTarget, see status.Writer, and aggregated into
+// their status by the status aggregation controller. Objects that admission
+// webhooks of the physical cluster deny are reported on their upstream
+// objects, see package admissionfeedback. Objects beyond the guardrails of
+// the SyncTarget are not synced, and the objects synced are reported in its
+// status, see package guardrail.
+//
+// The synced resources come from the resource configuration, see
+// controllermanager.ParseConfig, overlaid with the SyncConfigurations of the
+// workspace and the resources of the sync bundles it opts into, see package
+// bundles. Critical objects, such as Secrets and RBAC, are synced before the
+// others, see package priority. Namespaced objects are synced into a
+// downstream namespace per namespace of the workspace, see naming.Namespace.
+// Name collisions of the objects of the cluster-scoped resources of the
+// SyncTarget with objects of other workspaces or of the cluster
+// administrator are resolved by the collision policy of the resource, see
+// package clusterscoped. Every downstream object carries the LabelSyncTarget
+// label of its SyncTarget and records its upstream identity, see
+// naming.SetUpstream. Its other labels and annotations follow the
+// PropagationPolicies of the workspace, see package propagation. The
+// recorded path of the workspace follows moves of the workspace, see package
+// relocation. The priority classes of pods are mapped to the PriorityClasses
+// of the physical cluster, see package priorityclass. The SchedulingDefaults
+// of the workspace inject tolerations and node selectors into the pods, see
+// package schedulingdefaults. ConfigMaps annotated for fan-out are rendered
+// with the metadata of the SyncTarget, see package fanout. The images of
+// workloads whose WorkloadDistributions ask for it are pulled onto the nodes
+// of the physical cluster ahead of time, see package prepull. The endpoints
+// of the Services exported with ServiceExports are reported, and the
+// ServiceImports of the workspace are mirrored onto the physical cluster, so
+// that workloads resolve Services whose pods run on other SyncTargets, see
+// package servicediscovery. In the ManifestWork delivery mode of the
+// SyncTarget, the physical cluster is the hub of Open Cluster Management,
+// and the downstream objects are delivered per workload in ManifestWorks,
+// see package workapi.
+//
+// Once the SyncTarget is being deleted and no workloads are placed on it
+// anymore, the syncer stops syncing and removes what it synced to the
+// physical cluster, see package teardown.
+//
+// The syncer reports in the status of its SyncTarget how it runs, and what
+// the physical cluster offers to workloads, see packages capabilities and
+// capacity, and which endpoints the physical cluster reaches, see package
+// reachability. In observer mode, see package observer, it does not change
+// the physical cluster, and the ObserveOnly condition of the SyncTarget
+// keeps workloads off it. While the SyncTarget is paused, see package pause,
+// the syncer holds its changes of the physical cluster and its status
+// updates, and reports the pause in the Paused condition.
+//
+// The traffic with the workspace is compressed, see package compression, and
+// limited to the bandwidth budget of the SyncTarget, see package bandwidth.
+// Requests to the physical cluster are rate limited, and slow down while it
+// throttles them, see package ratelimit. Downstream writes that would not
+// change the downstream object are skipped, see package semanticdiff, and
+// the others can be coalesced per resource, see package batch. Objects
+// marked sensitive stay encrypted while their writes are queued, and are
+// decrypted only to be sent, see package sensitive. The syncer can follow
+// the syncer virtual workspace to the URL published in the status of the
+// SyncTarget, see package endpoint.
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/klog/v2"
+
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	kcpkubernetesclientset "github.com/kcp-dev/client-go/kubernetes"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	virtualoptions "github.com/kcp-dev/kcp/cmd/virtual-workspaces/options"
+	"github.com/kcp-dev/kcp/pkg/syncer/admissionfeedback"
+	"github.com/kcp-dev/kcp/pkg/syncer/audit"
+	"github.com/kcp-dev/kcp/pkg/syncer/bundles"
+	"github.com/kcp-dev/kcp/pkg/syncer/capabilities"
+	"github.com/kcp-dev/kcp/pkg/syncer/capacity"
+	"github.com/kcp-dev/kcp/pkg/syncer/controllermanager"
+	"github.com/kcp-dev/kcp/pkg/syncer/diagnostics"
+	"github.com/kcp-dev/kcp/pkg/syncer/endpoint"
+	"github.com/kcp-dev/kcp/pkg/syncer/heartbeat"
+	"github.com/kcp-dev/kcp/pkg/syncer/multitarget"
+	"github.com/kcp-dev/kcp/pkg/syncer/naming"
+	"github.com/kcp-dev/kcp/pkg/syncer/observer"
+	"github.com/kcp-dev/kcp/pkg/syncer/pause"
+	"github.com/kcp-dev/kcp/pkg/syncer/preflight"
+	"github.com/kcp-dev/kcp/pkg/syncer/prepull"
+	"github.com/kcp-dev/kcp/pkg/syncer/priorityclass"
+	"github.com/kcp-dev/kcp/pkg/syncer/ratelimit"
+	"github.com/kcp-dev/kcp/pkg/syncer/reachability"
+	"github.com/kcp-dev/kcp/pkg/syncer/relocation"
+	"github.com/kcp-dev/kcp/pkg/syncer/servicediscovery"
+	"github.com/kcp-dev/kcp/pkg/syncer/status"
+	"github.com/kcp-dev/kcp/pkg/syncer/transport/bandwidth"
+	"github.com/kcp-dev/kcp/pkg/syncer/transport/compression"
+	"github.com/kcp-dev/kcp/pkg/syncer/workapi"
+	virtualsyncer "github.com/kcp-dev/kcp/pkg/virtual/syncer"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// LabelSyncTarget is set on downstream objects to the SyncTargetKey of the
+// SyncTarget they are synced for.
+const LabelSyncTarget = "tmc.kcp.io/sync-target"
+
+const (
+	defaultConfigInterval       = 10 * time.Second
+	defaultCapabilitiesInterval = 10 * time.Minute
+	defaultCapacityInterval     = time.Minute
+	defaultReachabilityInterval = time.Minute
+
+	defaultStatusFlushInterval = time.Second
+	defaultStatusMaxBatchSize  = 500
+	defaultStatusConcurrency   = 4
+)
+
+var (
+	syncTargetsGVR         = tmcv1alpha1.SchemeGroupVersion.WithResource("synctargets")
+	syncConfigurationsGVR  = workloadv1alpha1.SchemeGroupVersion.WithResource("syncconfigurations")
+	propagationPoliciesGVR = workloadv1alpha1.SchemeGroupVersion.WithResource("propagationpolicies")
+)
+
+// DefaultResources are synced without a resource configuration. Secrets
+// are not among them, so that they only leave the workspace for physical
+// clusters configured to sync them.
+func DefaultResources() controllermanager.Config {
+	return controllermanager.Config{
+		corev1.SchemeGroupVersion.WithResource("configmaps"):                               {},
+		corev1.SchemeGroupVersion.WithResource("services"):                                 {},
+		schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}: {},
+	}
+}
+
+// SyncTargetKey returns the value of LabelSyncTarget of the downstream
+// objects of a SyncTarget. SyncTargets of different workspaces may share a
+// name, so the key is a hash of both.
+func SyncTargetKey(clusterName logicalcluster.Name, syncTarget string) string {
+	return naming.Hash(clusterName.String(), syncTarget)
+}
+
+// Options configure the syncers of the process.
+type Options struct {
+	// ResourceConfig is the file of the resource configuration, see
+	// controllermanager.ParseConfig. DefaultResources are synced without it.
+	ResourceConfig string
+	// ConfigInterval is how often the resource configuration and the
+	// SyncConfigurations are checked for changes.
+	ConfigInterval time.Duration
+	// Identity identifies the syncer in the heartbeat Lease of its
+	// SyncTarget. Defaults to the host name, i.e. the pod name.
+	Identity string
+	// Compression are the algorithms the syncer accepts for bodies from
+	// the syncer virtual workspace, in preference order. Request bodies are
+	// compressed with the first. Bodies are not compressed if empty.
+	Compression []string
+	// BatchInterval is how often coalesced downstream writes are sent.
+	// Downstream writes are sent one by one if zero.
+	BatchInterval time.Duration
+	// BatchMaxBytes bounds the size of the objects written per resource
+	// and batch. Zero means the default of the batcher.
+	BatchMaxBytes int64
+	// SensitiveEncryptionConfig is the file configuring the keys objects
+	// marked sensitive are encrypted with while their writes are batched,
+	// see encryption.Config and package sensitive. They are kept in
+	// plaintext if empty.
+	SensitiveEncryptionConfig string
+	// CapabilitiesInterval is how often the capabilities of the physical
+	// cluster are harvested and reported in the status of the SyncTarget,
+	// see package capabilities. They are not reported if zero.
+	CapabilitiesInterval time.Duration
+	// CapacityInterval is how often the capacity of the physical cluster
+	// is collected and reported in the status of the SyncTarget, see
+	// package capacity. It is not reported if zero.
+	CapacityInterval time.Duration
+	// ReachabilityInterval is how often the endpoints of
+	// spec.reachabilityProbes of the SyncTarget are probed from the
+	// physical cluster, see package reachability. They are not probed if
+	// zero.
+	ReachabilityInterval time.Duration
+	// ManifestWorkNamespace is the namespace of the managed cluster on the
+	// hub of Open Cluster Management the ManifestWorks of SyncTargets in
+	// the ManifestWork delivery mode are created in, see package workapi.
+	// Defaults to the name of the SyncTarget.
+	ManifestWorkNamespace string
+	// FollowVirtualWorkspace routes the requests of the syncer to the
+	// syncer virtual workspace URL published in status.virtualWorkspaces
+	// of the SyncTarget, moving open watches when it changes.
+	FollowVirtualWorkspace bool
+
+	// SyncStatus enables writing the status of downstream objects back to
+	// their upstream objects.
+	SyncStatus bool
+	// StatusFlushInterval is how often pending status updates are written.
+	StatusFlushInterval time.Duration
+	// StatusMaxBatchSize is the number of pending status updates that
+	// triggers a write before the flush interval passed.
+	StatusMaxBatchSize int
+	// StatusConcurrency is the number of workspaces whose status updates
+	// are written in parallel.
+	StatusConcurrency int
+	// StatusMaxPendingBytes bounds the memory held by pending status
+	// updates. Zero means unbounded.
+	StatusMaxPendingBytes int64
+	// StatusMaxPendingBytesPerResource bounds the memory held by pending
+	// status updates of a single resource. Zero means unbounded.
+	StatusMaxPendingBytesPerResource int64
+	// StatusSpillDir is the directory status updates beyond the memory
+	// bounds are spilled to. Without it, the informers block until pending
+	// updates are written.
+	StatusSpillDir string
+
+	// Observer is the mode of the syncer, see package observer.
+	Observer *observer.Options
+	// Preflight configures the checks run before syncing starts, see
+	// package preflight.
+	Preflight *preflight.Options
+	// RateLimit limits the requests to the physical cluster, unless the
+	// SyncTarget sets its own limits, see package ratelimit.
+	RateLimit *ratelimit.Options
+	// Relocation configures how often the workspaces of the SyncTargets
+	// are checked for moves, see Relocations.
+	Relocation *relocation.Options
+
+	// Diagnostics serves the queues of the syncers, if set. It is shared
+	// by the syncers of the process, and set by the syncer binary rather
+	// than by flags.
+	Diagnostics *diagnostics.Server `json:"-"`
+	// AuditLog records the mutations of the syncers on the physical
+	// cluster, if set, see package audit. It is shared by the syncers of
+	// the process, and set by the syncer binary rather than by flags.
+	AuditLog *audit.Log `json:"-"`
+	// Relocations follows moves of the workspaces of the SyncTargets, if
+	// set, see package relocation. It is shared by the syncers of the
+	// process, so that SyncTargets whose workspace moved keep resolving
+	// when their syncer restarts, and set by the syncer binary rather than
+	// by flags.
+	Relocations *relocation.Handler `json:"-"`
+}
+
+// NewOptions returns the default options.
+func NewOptions() *Options {
+	identity, _ := os.Hostname()
+	return &Options{
+		ConfigInterval:       defaultConfigInterval,
+		CapabilitiesInterval: defaultCapabilitiesInterval,
+		CapacityInterval:     defaultCapacityInterval,
+		ReachabilityInterval: defaultReachabilityInterval,
+		Identity:             identity,
+		Compression:          []string{string(compression.Zstd), string(compression.Gzip)},
+		SyncStatus:           true,
+		StatusFlushInterval:  defaultStatusFlushInterval,
+		StatusMaxBatchSize:   defaultStatusMaxBatchSize,
+		StatusConcurrency:    defaultStatusConcurrency,
+		Observer:             observer.NewOptions(),
+		Preflight:            preflight.NewOptions(),
+		RateLimit:            ratelimit.NewOptions(),
+		Relocation:           relocation.NewOptions(),
+	}
+}
+
+// AddFlags adds the syncer flags to the flag set.
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.ResourceConfig, "resource-config", o.ResourceConfig, "File of the resource configuration listing the synced resources. Config maps, services and deployments are synced without it.")
+	fs.DurationVar(&o.ConfigInterval, "resource-config-interval", o.ConfigInterval, "Interval between checks of the resource configuration and the SyncConfigurations for changes.")
+	fs.StringVar(&o.Identity, "identity", o.Identity, "Identity of the syncer in the heartbeat Lease of its SyncTargets. Defaults to the host name.")
+	fs.StringSliceVar(&o.Compression, "compression", o.Compression, "Algorithms bodies exchanged with the syncer virtual workspace are compressed with, in preference order. One of zstd and gzip. Disabled if empty.")
+	fs.DurationVar(&o.BatchInterval, "sync-batch-interval", o.BatchInterval, "Interval between batches of downstream writes, coalescing the writes of an object in between. Writes are sent one by one if zero.")
+	fs.Int64Var(&o.BatchMaxBytes, "sync-batch-max-bytes", o.BatchMaxBytes, "Bound of the size of the objects written per resource and batch, in bytes. 0 means 1MiB. Batches are further bounded to a second of the bandwidth limit of the SyncTarget.")
+	fs.StringVar(&o.SensitiveEncryptionConfig, "sensitive-encryption-config", o.SensitiveEncryptionConfig, "File configuring the keys objects marked sensitive are encrypted with while their writes are batched. The first key encrypts, all keys decrypt. Kept in plaintext if empty.")
+	fs.DurationVar(&o.CapabilitiesInterval, "capabilities-interval", o.CapabilitiesInterval, "Interval between harvests of the Kubernetes version, feature gates, APIs and addons of the physical cluster reported in the status of the SyncTarget. Not reported if zero.")
+	fs.DurationVar(&o.CapacityInterval, "capacity-interval", o.CapacityInterval, "Interval between reports of the capacity and allocatable resources of the nodes of the physical cluster in the status of the SyncTarget. Not reported if zero.")
+	fs.DurationVar(&o.ReachabilityInterval, "reachability-interval", o.ReachabilityInterval, "Interval between probes of the endpoints of the reachability probes of the SyncTarget from the physical cluster. Not probed if zero.")
+	fs.StringVar(&o.ManifestWorkNamespace, "manifestwork-namespace", o.ManifestWorkNamespace, "Namespace of the managed cluster on the Open Cluster Management hub the ManifestWorks of SyncTargets with the ManifestWork delivery mode are created in. Defaults to the name of the SyncTarget.")
+	fs.BoolVar(&o.FollowVirtualWorkspace, "follow-virtual-workspace", o.FollowVirtualWorkspace, "Follow the syncer virtual workspace to the URL published in the status of the SyncTarget, e.g. when it moves to another shard.")
+	fs.BoolVar(&o.SyncStatus, "sync-status", o.SyncStatus, "Write the status of downstream objects back to their upstream objects.")
+	fs.DurationVar(&o.StatusFlushInterval, "status-flush-interval", o.StatusFlushInterval, "Interval between writes of pending status updates.")
+	fs.IntVar(&o.StatusMaxBatchSize, "status-max-batch-size", o.StatusMaxBatchSize, "Number of pending status updates of a workspace that triggers a write before the flush interval passed.")
+	fs.IntVar(&o.StatusConcurrency, "status-concurrency", o.StatusConcurrency, "Number of workspaces whose status updates are written in parallel.")
+	fs.Int64Var(&o.StatusMaxPendingBytes, "status-max-pending-bytes", o.StatusMaxPendingBytes, "Bound of the memory held by pending status updates, in bytes. 0 means unbounded.")
+	fs.Int64Var(&o.StatusMaxPendingBytesPerResource, "status-max-pending-bytes-per-resource", o.StatusMaxPendingBytesPerResource, "Bound of the memory held by pending status updates of a single resource, in bytes. 0 means unbounded.")
+	fs.StringVar(&o.StatusSpillDir, "status-spill-dir", o.StatusSpillDir, "Directory status updates beyond the memory bounds are spilled to. Without it, syncing waits for pending status updates to be written.")
+	o.Observer.AddFlags(fs)
+	o.Preflight.AddFlags(fs)
+	o.RateLimit.AddFlags(fs)
+	o.Relocation.AddFlags(fs)
+}
+
+// Validate validates the options.
+func (o *Options) Validate() error {
+	if o.ConfigInterval <= 0 {
+		return fmt.Errorf("--resource-config-interval must be positive")
+	}
+	if o.Identity == "" {
+		return fmt.Errorf("--identity must not be empty")
+	}
+	if err := o.compressionSettings().Validate(); err != nil {
+		return fmt.Errorf("--compression: %w", err)
+	}
+	if o.CapabilitiesInterval < 0 || o.CapacityInterval < 0 || o.ReachabilityInterval < 0 {
+		return fmt.Errorf("--capabilities-interval, --capacity-interval and --reachability-interval must not be negative")
+	}
+	if o.BatchInterval < 0 || o.BatchMaxBytes < 0 {
+		return fmt.Errorf("--sync-batch-interval and --sync-batch-max-bytes must not be negative")
+	}
+	if o.StatusFlushInterval <= 0 {
+		return fmt.Errorf("--status-flush-interval must be positive")
+	}
+	if o.StatusMaxBatchSize <= 0 {
+		return fmt.Errorf("--status-max-batch-size must be positive")
+	}
+	if o.StatusConcurrency <= 0 {
+		return fmt.Errorf("--status-concurrency must be positive")
+	}
+	if o.StatusMaxPendingBytes < 0 || o.StatusMaxPendingBytesPerResource < 0 {
+		return fmt.Errorf("--status-max-pending-bytes and --status-max-pending-bytes-per-resource must not be negative")
+	}
+	if err := o.Observer.Validate(); err != nil {
+		return err
+	}
+	if err := o.Preflight.Validate(); err != nil {
+		return err
+	}
+	if err := o.RateLimit.Validate(); err != nil {
+		return err
+	}
+	if err := o.Relocation.Validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Run runs the syncer of target until ctx is done. upstream is the config of
+// the syncer for kcp, e.g. of the kubeconfig generated by kubectl tmc
+// workload-syncer generate, downstream the config for the physical cluster.
+func Run(ctx context.Context, options *Options, target multitarget.Target, upstream, downstream *rest.Config) error {
+	logger := klog.FromContext(ctx)
+
+	kcpURL, err := url.Parse(upstream.Host)
+	if err != nil {
+		return err
+	}
+	kcpURL.Path = ""
+	workspaceConfig := rest.CopyConfig(upstream)
+	workspaceConfig.Host = kcpURL.String()
+	// A moved workspace is addressed by its logical cluster, see package
+	// relocation.
+	workspace := target
+	if options.Relocations != nil {
+		if workspace, err = options.Relocations.Resolve(ctx, target); err != nil {
+			return err
+		}
+	}
+	// A failed check fails the syncer, which is restarted with backoff, see
+	// multitarget.Supervisor.
+	if err := checkPreflight(ctx, options, workspace, workspaceConfig, downstream); err != nil {
+		return err
+	}
+	workspaceClient, err := kcpdynamic.NewForConfig(workspaceConfig)
+	if err != nil {
+		return err
+	}
+	syncTarget, err := getSyncTarget(ctx, workspaceClient.Cluster(workspace.Path), target.Name)
+	if err != nil {
+		return fmt.Errorf("failed to get SyncTarget %s: %w", target, err)
+	}
+	clusterName := logicalcluster.From(syncTarget)
+	if clusterName.Empty() {
+		return fmt.Errorf("SyncTarget %s has no logical cluster", target)
+	}
+
+	// All other requests go through the syncer virtual workspace, which
+	// confines them to what the syncer of the SyncTarget may access.
+	virtualConfig := rest.CopyConfig(upstream)
+	virtualConfig.Host = kcpURL.JoinPath(virtualoptions.DefaultRootPathPrefix, virtualsyncer.VirtualWorkspaceName, virtualsyncer.SyncerID(target.Name)).String()
+	// The bandwidth budget counts the bytes on the wire, i.e. compressed.
+	limiter := bandwidth.NewLimiter(target.String(), bandwidth.LimitFor(syncTarget))
+	virtualConfig.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return bandwidth.NewRoundTripper(rt, limiter)
+	})
+	if len(options.Compression) > 0 {
+		settings := options.compressionSettings()
+		virtualConfig.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return compression.NewRoundTripper(rt, target.String(), settings)
+		})
+	}
+	var ep *endpoint.Endpoint
+	if options.FollowVirtualWorkspace {
+		// Outermost, as watches are moved by decoding their events.
+		if ep, err = endpoint.New(target.String(), virtualConfig.Host); err != nil {
+			return err
+		}
+		virtualConfig = ep.Config(virtualConfig)
+	}
+	virtualClient, err := kcpdynamic.NewForConfig(virtualConfig)
+	if err != nil {
+		return err
+	}
+	virtualKubeClient, err := kcpkubernetesclientset.NewForConfig(virtualConfig)
+	if err != nil {
+		return err
+	}
+	gate := pause.NewGate(target.Name)
+	gate.Update(syncTarget)
+	downstream = guardDownstream(downstream, options, target, gate)
+	downstream, rateLimiter := ratelimit.Configure(downstream, target.String(), options.RateLimit.For(syncTarget), options.RateLimit)
+	defer rateLimiter.Stop()
+	downstreamClient, err := dynamic.NewForConfig(downstream)
+	if err != nil {
+		return err
+	}
+	downstreamKube, err := kubernetes.NewForConfig(downstream)
+	if err != nil {
+		return err
+	}
+	downstreamDiscovery, err := discovery.NewDiscoveryClientForConfig(downstream)
+	if err != nil {
+		return err
+	}
+
+	s := newSyncer(target, clusterName, virtualClient.Cluster(clusterName.Path()), downstreamClient,
+		restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(downstreamDiscovery)))
+	s.downstreamKube = downstreamKube
+	s.limiter = limiter
+	s.rateLimits, s.rateLimiter = options.RateLimit, rateLimiter
+	s.relocations = options.Relocations
+	s.endpoint = ep
+	s.pause = gate
+	s.setSyncTarget(syncTarget)
+	s.heartbeat = heartbeat.New(virtualKubeClient.Cluster(clusterName.Path()).CoordinationV1(), target.Name, options.Identity)
+	if options.SyncStatus {
+		s.statusWriter = s.newStatusWriter(virtualClient, options)
+	}
+	s.feedback = admissionfeedback.NewReporter(virtualClient, admissionfeedback.RetryPolicy{})
+	return s.run(klog.NewContext(ctx, logger.WithValues("cluster", clusterName)), options)
+}
+
+// guardDownstream returns the config of the physical cluster used by the
+// syncer of target. Its mutations wait while gate is paused, see
+// pause.Gate.Guard, and are not sent in observer mode, see
+// observer.Options.Guard.
+func guardDownstream(downstream *rest.Config, options *Options, target multitarget.Target, gate *pause.Gate) *rest.Config {
+	return options.Observer.Guard(gate.Guard(downstream), target.String())
+}
+
+// run runs the syncer until ctx is done: its heartbeat, status writer and
+// batcher, if set, the placement, the pre-pulling of images, the report of
+// the status of the SyncTarget, the controllers of the synced resources,
+// the discovery of exported Services and the following of moves of the
+// workspace, until the SyncTarget is torn down.
+func (s *syncer) run(ctx context.Context, options *Options) error {
+	logger := klog.FromContext(ctx)
+	logger.Info("Starting syncer", "mode", options.Observer.Mode)
+	defer logger.Info("Shutting down syncer")
+
+	s.diagnostics = options.Diagnostics
+	s.audit = options.AuditLog.ForSyncTarget(s.target.String())
+	if options.BatchInterval > 0 && !options.Observer.Observer() {
+		// Nothing is written downstream in observer mode.
+		batcher, err := s.newBatcher(options)
+		if err != nil {
+			return err
+		}
+		s.batcher = batcher
+	}
+	if s.diagnostics != nil {
+		name := multitarget.QueueName(s.target, "placement")
+		s.diagnostics.RegisterQueue(name, diagnostics.LengthInspector(name, s.placement.queue))
+		defer s.diagnostics.UnregisterQueue(name)
+	}
+
+	if s.heartbeat != nil {
+		go s.heartbeat.Run(ctx)
+	}
+	if s.statusWriter != nil {
+		go s.statusWriter.Run(ctx)
+	}
+	if s.batcher != nil {
+		go s.batcher.Run(ctx)
+	}
+
+	if s.downstreamKube != nil {
+		s.priorityClassMapper = priorityclass.NewMapper(s.getPriorityClass, s.downstreamKube)
+	}
+	go s.placement.Run(ctx)
+	if syncTarget := s.syncTarget.Load(); syncTarget != nil && workapi.UsesManifestWork(syncTarget) {
+		// The delivery mode is read when the syncer starts.
+		namespace := options.ManifestWorkNamespace
+		if namespace == "" {
+			namespace = s.target.Name
+		}
+		logger.Info("Delivering workloads as ManifestWorks", "namespace", namespace)
+		s.works = newManifestWorks(s, namespace)
+		go s.works.Run(ctx, options.ConfigInterval)
+		s.reporters = append(s.reporters, s.works.report)
+	}
+
+	if s.downstreamKube != nil && !options.Observer.Observer() {
+		go newPrePulls(s, prepull.NewPuller(s.downstreamKube)).Run(ctx, options.ConfigInterval)
+	}
+	if s.downstreamKube != nil {
+		var mirror *servicediscovery.Mirror
+		if !options.Observer.Observer() {
+			mirror = servicediscovery.NewMirror(s.downstreamKube, s.target.Name)
+		}
+		go s.discoverServices(ctx, mirror, options.ConfigInterval)
+	}
+
+	mode := options.Observer.Mode
+	s.reporters = append(s.reporters, func(ctx context.Context, syncTarget *tmcv1alpha1.SyncTarget) error {
+		observer.SetCondition(syncTarget, mode)
+		pause.SetCondition(syncTarget, s.pause.Paused())
+		return nil
+	}, s.reportConflicts, s.reportUsage)
+	if s.downstreamKube != nil && options.CapabilitiesInterval > 0 {
+		harvester := capabilities.NewHarvester(s.downstreamKube)
+		s.reporters = append(s.reporters, everyInterval(options.CapabilitiesInterval, func(ctx context.Context, syncTarget *tmcv1alpha1.SyncTarget) error {
+			c, err := harvester.Harvest(ctx)
+			if err != nil {
+				return err
+			}
+			harvester.SetStatus(syncTarget, c)
+			return nil
+		}))
+	}
+	if s.downstreamKube != nil && options.CapacityInterval > 0 {
+		reporter := capacity.NewReporter(s.downstreamKube)
+		s.reporters = append(s.reporters, everyInterval(options.CapacityInterval, func(ctx context.Context, syncTarget *tmcv1alpha1.SyncTarget) error {
+			c, err := reporter.Collect(ctx)
+			if err != nil {
+				return err
+			}
+			reporter.SetStatus(syncTarget, c)
+			return nil
+		}))
+	}
+	if options.ReachabilityInterval > 0 {
+		s.reporters = append(s.reporters, reportReachability(reachability.NewProber(0), options.ReachabilityInterval))
+	}
+	go s.reportStatus(ctx, options.ConfigInterval)
+
+	base := make(chan controllermanager.Config, 1)
+	configs := (<-chan controllermanager.Config)(base)
+	if options.ResourceConfig != "" {
+		configs = controllermanager.WatchFile(ctx, options.ResourceConfig, options.ConfigInterval)
+	} else {
+		base <- DefaultResources()
+	}
+	configs = controllermanager.WatchSyncConfigurations(ctx, configs, s.syncConfigurations, options.ConfigInterval)
+	configs = bundles.Watch(ctx, configs, s.bundleInputs(ctx, options.ConfigInterval))
+	manager := controllermanager.NewManager(s.newController, controllermanager.DefaultOptions())
+	syncCtx, stopSyncing := context.WithCancel(ctx)
+	defer stopSyncing()
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		manager.Run(syncCtx, configs)
+	}()
+
+	synced := func() []schema.GroupVersionResource {
+		gvrs := slices.Collect(maps.Keys(manager.Config()))
+		if s.works != nil {
+			gvrs = append(gvrs, workapi.ManifestWorksGVR)
+		}
+		return gvrs
+	}
+	if s.relocations != nil {
+		go s.followWorkspace(ctx, options.Relocation.Interval, synced)
+	}
+	s.tearDown(ctx, options.ConfigInterval, synced, func() {
+		stopSyncing()
+		<-stopped
+	})
+	<-ctx.Done()
+	<-stopped
+	return nil
+}
+
+// newStatusWriter returns the writer of the status of the downstream
+// objects. The status is reported in the annotation of the SyncTarget on
+// the upstream objects, see statusaggregation.TargetAnnotation, and the
+// status aggregation controller owns their status.
+func (s *syncer) newStatusWriter(client kcpdynamic.ClusterInterface, options *Options) *status.Writer {
+	return status.NewWriter(client, status.Options{
+		FlushInterval: options.StatusFlushInterval,
+		MaxBatchSize:  options.StatusMaxBatchSize,
+		Concurrency:   options.StatusConcurrency,
+		Projection:    s.projection,
+		SyncTarget:    s.target.Name,
+		Paused:        s.pause.Paused,
+
+		MaxPendingBytes:            options.StatusMaxPendingBytes,
+		MaxPendingBytesPerResource: options.StatusMaxPendingBytesPerResource,
+		SpillDir:                   options.StatusSpillDir,
+	})
+}
+
+func (o *Options) compressionSettings() compression.Settings {
+	settings := compression.DefaultSettings()
+	settings.Algorithms = nil
+	for _, algorithm := range o.Compression {
+		settings.Algorithms = append(settings.Algorithms, compression.Algorithm(algorithm))
+	}
+	return settings
+}
+
+func getSyncTarget(ctx context.Context, client dynamic.Interface, name string) (*tmcv1alpha1.SyncTarget, error) {
+	u, err := client.Resource(syncTargetsGVR).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	syncTarget := &tmcv1alpha1.SyncTarget{}
+	return syncTarget, runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, syncTarget)
+}
+
+// syncConfigurations returns the SyncTarget and the SyncConfigurations of
+// its workspace. The PropagationPolicies, WorkloadPriorityClasses and
+// SchedulingDefaults of the workspace are read along.
+func (s *syncer) syncConfigurations(ctx context.Context) (*tmcv1alpha1.SyncTarget, []*workloadv1alpha1.SyncConfiguration, error) {
+	syncTarget, err := getSyncTarget(ctx, s.upstream, s.target.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+	list, err := s.upstream.Resource(syncConfigurationsGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	syncConfigs := make([]*workloadv1alpha1.SyncConfiguration, 0, len(list.Items))
+	for i := range list.Items {
+		sc := &workloadv1alpha1.SyncConfiguration{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(list.Items[i].Object, sc); err != nil {
+			return nil, nil, err
+		}
+		syncConfigs = append(syncConfigs, sc)
+	}
+	policies, err := s.propagationPolicies(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	priorityClasses, err := s.workloadPriorityClasses(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	schedulingDefaults, err := s.workspaceSchedulingDefaults(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.setSyncTarget(syncTarget)
+	s.setPropagationPolicies(policies)
+	s.setWorkloadPriorityClasses(priorityClasses)
+	s.setSchedulingDefaults(schedulingDefaults)
+	return syncTarget, syncConfigs, nil
+}
+
+// propagationPolicies returns the PropagationPolicies of the workspace of
+// the SyncTarget.
+func (s *syncer) propagationPolicies(ctx context.Context) ([]*workloadv1alpha1.PropagationPolicy, error) {
+	list, err := s.upstream.Resource(propagationPoliciesGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	policies := make([]*workloadv1alpha1.PropagationPolicy, 0, len(list.Items))
+	for i := range list.Items {
+		policy := &workloadv1alpha1.PropagationPolicy{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(list.Items[i].Object, policy); err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// setPropagationPolicies records the PropagationPolicies last read. If they
+// changed, all upstream objects are synced again, as their downstream
+// labels and annotations may change.
+func (s *syncer) setPropagationPolicies(policies []*workloadv1alpha1.PropagationPolicy) {
+	previous := s.policies.Swap(&policies)
+	if previous == nil || policySpecsEqual(*previous, policies) {
+		return
+	}
+	s.controllers.Range(func(_, value interface{}) bool {
+		c := value.(*controller)
+		for _, key := range c.upstreamInformer.GetStore().ListKeys() {
+			c.queue.Add(key)
+		}
+		return true
+	})
+}
+
+func policySpecsEqual(a, b []*workloadv1alpha1.PropagationPolicy) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || !equality.Semantic.DeepEqual(a[i].Spec, b[i].Spec) {
+			return false
+		}
+	}
+	return true
+}
+
+// setSyncTarget records the SyncTarget last read, pauses or resumes the
+// syncer, applies its guardrails, bandwidth and client rate limits and
+// follows its syncer virtual workspace URL. All upstream objects are synced
+// again if the metadata they may be rendered with changed, see package
+// fanout, or if the guardrails changed, as objects beyond the previous
+// guardrails may fit now.
+func (s *syncer) setSyncTarget(syncTarget *tmcv1alpha1.SyncTarget) {
+	previous := s.syncTarget.Swap(syncTarget)
+	s.guardrails.SetGuardrails(syncTarget.Spec.Guardrails)
+	if previous != nil && (metadataChanged(previous, syncTarget) || !equality.Semantic.DeepEqual(previous.Spec.Guardrails, syncTarget.Spec.Guardrails)) {
+		s.controllers.Range(func(_, value interface{}) bool {
+			c := value.(*controller)
+			for _, key := range c.upstreamInformer.GetStore().ListKeys() {
+				c.queue.Add(key)
+			}
+			return true
+		})
+	}
+	s.arbiter.SetResources(syncTarget.Spec.ClusterScopedResources)
+	if s.pause.Update(syncTarget) {
+		klog.Background().Info("SyncTarget paused or resumed", "syncTarget", s.target.String(), "paused", s.pause.Paused())
+	}
+	if s.limiter != nil {
+		s.limiter.SetLimit(bandwidth.LimitFor(syncTarget))
+	}
+	if s.rateLimiter != nil {
+		s.rateLimiter.SetSettings(s.rateLimits.For(syncTarget))
+	}
+	if s.endpoint != nil {
+		if _, err := s.endpoint.Update(syncTarget); err != nil {
+			utilruntime.HandleError(fmt.Errorf("failed to follow the syncer virtual workspace of SyncTarget %s: %w", s.target, err))
+		}
+	}
+}
+
+// metadataChanged returns whether the metadata of a SyncTarget that
+// downstream objects may be rendered with changed, see fanout.Expand.
+func metadataChanged(old, syncTarget *tmcv1alpha1.SyncTarget) bool {
+	return old.Spec.Location != syncTarget.Spec.Location ||
+		old.Status.KubernetesVersion != syncTarget.Status.KubernetesVersion ||
+		!equality.Semantic.DeepEqual(old.Labels, syncTarget.Labels) ||
+		!equality.Semantic.DeepEqual(old.Annotations, syncTarget.Annotations)
+}
+
+// projection returns the fields of the status of a resource written back,
+// as configured by spec.upstreamSync of the SyncTarget.
+func (s *syncer) projection(gvr schema.GroupVersionResource) status.Projection {
+	syncTarget := s.syncTarget.Load()
+	if syncTarget == nil {
+		return nil
+	}
+	p, err := status.ProjectionFor(syncTarget.Spec.UpstreamSync, gvr.GroupResource())
+	if err != nil {
+		// Invalid fields select the whole status rather than none of it.
+		return nil
+	}
+	return p
+}
+
+// ensureNamespace creates the downstream namespace of a namespace of the
+// workspace, unless it was created before.
+func (s *syncer) ensureNamespace(ctx context.Context, namespace string) error {
+	name := naming.Namespace(s.clusterName, namespace)
+	if _, found := s.namespaces.Load(name); found {
+		return nil
+	}
+	ns := &unstructured.Unstructured{}
+	ns.SetAPIVersion("v1")
+	ns.SetKind("Namespace")
+	ns.SetName(name)
+	ns.SetLabels(map[string]string{LabelSyncTarget: s.key})
+	naming.SetUpstream(ns, naming.Identity{Workspace: s.clusterName, Path: s.workspacePath(), Name: namespace})
+	created, err := s.downstream.Resource(namespacesGVR).Apply(ctx, name, ns, metav1.ApplyOptions{FieldManager: fieldManager, Force: true})
+	if err != nil {
+		return fmt.Errorf("failed to create downstream namespace %s: %w", name, err)
+	}
+	s.audit.Record(ctx, audit.Mutation{Controller: auditNamespaceController, Verb: audit.VerbCreate, Resource: namespacesGVR, New: created})
+	s.namespaces.Store(name, struct{}{})
+	return nil
+}
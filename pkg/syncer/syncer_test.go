@@ -0,0 +1,1169 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	kcptesting "github.com/kcp-dev/client-go/third_party/k8s.io/client-go/testing"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/statusaggregation"
+	"github.com/kcp-dev/kcp/pkg/syncer/admissionfeedback"
+	"github.com/kcp-dev/kcp/pkg/syncer/audit"
+	"github.com/kcp-dev/kcp/pkg/syncer/bundles"
+	"github.com/kcp-dev/kcp/pkg/syncer/clusterscoped"
+	"github.com/kcp-dev/kcp/pkg/syncer/diagnostics"
+	"github.com/kcp-dev/kcp/pkg/syncer/endpoint"
+	"github.com/kcp-dev/kcp/pkg/syncer/multitarget"
+	"github.com/kcp-dev/kcp/pkg/syncer/naming"
+	"github.com/kcp-dev/kcp/pkg/syncer/observer"
+	"github.com/kcp-dev/kcp/pkg/syncer/pause"
+	"github.com/kcp-dev/kcp/pkg/syncer/ratelimit"
+	"github.com/kcp-dev/kcp/pkg/syncer/relocation"
+	"github.com/kcp-dev/kcp/pkg/syncer/servicediscovery"
+	"github.com/kcp-dev/kcp/pkg/syncer/transport/bandwidth"
+	"github.com/kcp-dev/kcp/pkg/syncer/workapi"
+	"github.com/kcp-dev/kcp/sdk/apis/core"
+	corev1alpha1 "github.com/kcp-dev/kcp/sdk/apis/core/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+	kcpfakeclient "github.com/kcp-dev/kcp/sdk/client/clientset/versioned/cluster/fake"
+)
+
+// newTestSyncer returns a syncer of the SyncTarget edge in logical cluster
+// abc, with fake clients serving the DefaultResources.
+func newTestSyncer(t *testing.T, syncTarget *tmcv1alpha1.SyncTarget, downstreamObjects ...runtime.Object) (s *syncer, upstream, downstream *dynamicfake.FakeDynamicClient) {
+	t.Helper()
+	syncTarget = syncTarget.DeepCopy()
+	syncTarget.APIVersion, syncTarget.Kind = tmcv1alpha1.SchemeGroupVersion.String(), "SyncTarget"
+	syncTarget.Name = "edge"
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(syncTarget)
+	require.NoError(t, err)
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Service"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"}, meta.RESTScopeRoot)
+	mapper.Add(schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}, meta.RESTScopeNamespace)
+	listKinds := map[schema.GroupVersionResource]string{
+		configMapsGVR:                                           "ConfigMapList",
+		{Version: "v1", Resource: "services"}:                   "ServiceList",
+		{Group: "apps", Version: "v1", Resource: "deployments"}: "DeploymentList",
+		clusterRolesGVR:                                         "ClusterRoleList",
+		serviceMonitorsGVR:                                      "ServiceMonitorList",
+		namespacesGVR:                                           "NamespaceList",
+		syncTargetsGVR:                                          "SyncTargetList",
+		syncConfigurationsGVR:                                   "SyncConfigurationList",
+		propagationPoliciesGVR:                                  "PropagationPolicyList",
+		priorityClassesGVR:                                      "WorkloadPriorityClassList",
+		schedulingDefaultsGVR:                                   "SchedulingDefaultsList",
+		serviceExportsGVR:                                       "ServiceExportList",
+		serviceImportsGVR:                                       "ServiceImportList",
+		workapi.ManifestWorksGVR:                                "ManifestWorkList",
+		distributionsGVR:                                        "WorkloadDistributionList",
+	}
+	lc := newObject(corev1alpha1.SchemeGroupVersion.String(), "LogicalCluster", "", corev1alpha1.LogicalClusterName)
+	upstream = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, &unstructured.Unstructured{Object: u}, lc)
+	downstream = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, downstreamObjects...)
+	s = newSyncer(multitarget.Target{Path: logicalcluster.NewPath("root:org"), Name: "edge"}, "abc", upstream, downstream, mapper)
+	s.setSyncTarget(syncTarget)
+	return s, upstream, downstream
+}
+
+// startTestSyncer runs s with options until the test ends.
+func startTestSyncer(t *testing.T, s *syncer, options *Options) context.Context {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), wait.ForeverTestTimeout)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := s.run(ctx, options); err != nil {
+			t.Errorf("run failed: %v", err)
+		}
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return ctx
+}
+
+// eventuallySyncTarget waits for the SyncTarget to satisfy condition.
+func eventuallySyncTarget(t *testing.T, ctx context.Context, s *syncer, condition func(syncTarget *tmcv1alpha1.SyncTarget) bool, msg string) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		syncTarget, err := getSyncTarget(ctx, s.upstream, s.target.Name)
+		return err == nil && condition(syncTarget)
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, msg)
+}
+
+func testOptions() *Options {
+	options := NewOptions()
+	options.ConfigInterval = 10 * time.Millisecond
+	return options
+}
+
+func TestSetSyncTarget(t *testing.T) {
+	target := multitarget.Target{Path: logicalcluster.NewPath("root:org"), Name: "edge"}
+	s := newSyncer(target, "abc", nil, nil, nil)
+	s.limiter = bandwidth.NewLimiter(target.String(), 0)
+	var err error
+	s.endpoint, err = endpoint.New(target.String(), "https://shard-1/services/syncer/kcp-syncer-edge")
+	require.NoError(t, err)
+
+	limit := resource.MustParse("1Mi")
+	syncTarget := &tmcv1alpha1.SyncTarget{
+		Spec: tmcv1alpha1.SyncTargetSpec{
+			Connection: &tmcv1alpha1.SyncTargetConnection{BandwidthLimit: &limit},
+		},
+		Status: tmcv1alpha1.SyncTargetStatus{
+			VirtualWorkspaces: []tmcv1alpha1.VirtualWorkspace{{SyncerURL: "https://shard-2/services/syncer/kcp-syncer-edge"}},
+		},
+	}
+	s.setSyncTarget(syncTarget)
+
+	require.Same(t, syncTarget, s.syncTarget.Load())
+	require.Equal(t, int64(1024*1024), s.limiter.Limit())
+	require.Equal(t, "https://shard-2/services/syncer/kcp-syncer-edge", s.endpoint.URL())
+
+	s.setSyncTarget(&tmcv1alpha1.SyncTarget{})
+	require.Zero(t, s.limiter.Limit(), "the limit is lifted")
+	require.Equal(t, "https://shard-2/services/syncer/kcp-syncer-edge", s.endpoint.URL(), "the URL is kept while none is published")
+}
+
+func TestStatusWriterReportsStatusOfSyncTarget(t *testing.T) {
+	requests := make(chan *http.Request, 1)
+	bodies := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		requests <- req
+		bodies <- string(body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+	client, err := kcpdynamic.NewForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+
+	s := newSyncer(multitarget.Target{Path: logicalcluster.NewPath("root:org"), Name: "edge"}, "abc", nil, nil, nil)
+	options := NewOptions()
+	options.StatusFlushInterval = 10 * time.Millisecond
+	w := s.newStatusWriter(client, options)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+
+	obj := newObject("v1", "ConfigMap", "default", "app")
+	obj.Object["status"] = map[string]interface{}{"phase": "Ready"}
+	w.Write("abc", configMapsGVR, obj)
+
+	req := <-requests
+	require.Equal(t, http.MethodPatch, req.Method)
+	require.Equal(t, "/clusters/abc/api/v1/namespaces/default/configmaps/app", req.URL.Path, "the main resource is patched, not its status")
+	require.Equal(t, "application/apply-patch+yaml", req.Header.Get("Content-Type"))
+	require.Equal(t, "kcp-syncer-status-edge", req.URL.Query().Get("fieldManager"))
+	require.JSONEq(t, `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"namespace":"default","name":"app","annotations":{"`+
+		statusaggregation.TargetAnnotation("edge")+`":"{\"phase\":\"Ready\"}"}}}`, <-bodies)
+}
+
+func TestRunReportsObserverMode(t *testing.T) {
+	s, _, _ := newTestSyncer(t, &tmcv1alpha1.SyncTarget{})
+	options := testOptions()
+	options.Observer.Mode = observer.ModeObserver
+	ctx := startTestSyncer(t, s, options)
+
+	eventuallySyncTarget(t, ctx, s, func(syncTarget *tmcv1alpha1.SyncTarget) bool {
+		return conditions.IsTrue(syncTarget, tmcv1alpha1.ObserveOnly)
+	}, "the SyncTarget reports observer mode")
+}
+
+func TestRunReportsSyncMode(t *testing.T) {
+	syncTarget := &tmcv1alpha1.SyncTarget{}
+	observer.SetCondition(syncTarget, observer.ModeObserver)
+	s, _, _ := newTestSyncer(t, syncTarget)
+	ctx := startTestSyncer(t, s, testOptions())
+
+	eventuallySyncTarget(t, ctx, s, func(syncTarget *tmcv1alpha1.SyncTarget) bool {
+		return conditions.Get(syncTarget, tmcv1alpha1.ObserveOnly) == nil
+	}, "the condition of an earlier observer is removed")
+}
+
+func TestGuardDownstream(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		methods = append(methods, req.Method)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"namespace":"default","name":"app"}}`))
+	}))
+	defer server.Close()
+	target := multitarget.Target{Path: logicalcluster.NewPath("root:org"), Name: "edge"}
+	ctx := context.Background()
+
+	options := NewOptions()
+	options.Observer.Mode = observer.ModeObserver
+	client, err := dynamic.NewForConfig(guardDownstream(&rest.Config{Host: server.URL}, options, target, pause.NewGate("edge")))
+	require.NoError(t, err)
+	_, err = client.Resource(configMapsGVR).Namespace("default").Get(ctx, "app", metav1.GetOptions{})
+	require.NoError(t, err)
+	err = client.Resource(configMapsGVR).Namespace("default").Delete(ctx, "app", metav1.DeleteOptions{})
+	require.True(t, apierrors.IsForbidden(err), "observers do not change the physical cluster: %v", err)
+	require.Equal(t, []string{http.MethodGet}, methods)
+
+	client, err = dynamic.NewForConfig(guardDownstream(&rest.Config{Host: server.URL}, NewOptions(), target, pause.NewGate("edge")))
+	require.NoError(t, err)
+	require.NoError(t, client.Resource(configMapsGVR).Namespace("default").Delete(ctx, "app", metav1.DeleteOptions{}))
+	require.Equal(t, []string{http.MethodGet, http.MethodDelete}, methods)
+}
+
+func TestRunGatesOnPreflightChecks(t *testing.T) {
+	var paths []string
+	var lock sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		lock.Lock()
+		paths = append(paths, req.URL.Path)
+		lock.Unlock()
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	upstream := &rest.Config{Host: server.URL + "/clusters/root:org"}
+	downstream := &rest.Config{Host: server.URL}
+	target := multitarget.Target{Path: logicalcluster.NewPath("root:org"), Name: "edge"}
+	options := NewOptions()
+
+	err := Run(context.Background(), options, target, upstream, downstream)
+	require.ErrorContains(t, err, "pre-flight checks of SyncTarget root:org:edge failed")
+	require.ErrorContains(t, err, "kcp-connectivity")
+	require.NotContains(t, paths, "/clusters/root:org/apis/tmc.kcp.io/v1alpha1/synctargets/edge", "the SyncTarget is not read if kcp cannot be reached")
+
+	options.Preflight.Skip = true
+	err = Run(context.Background(), options, target, upstream, downstream)
+	require.ErrorContains(t, err, "failed to get SyncTarget", "the syncer starts without checks")
+}
+
+func TestRunHoldsWhilePaused(t *testing.T) {
+	s, upstream, downstream := newTestSyncer(t, &tmcv1alpha1.SyncTarget{Spec: tmcv1alpha1.SyncTargetSpec{Paused: true}})
+	var lock sync.Mutex
+	var applied []string
+	downstream.PrependReactor("patch", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		applied = append(applied, action.GetResource().Resource+"/"+action.(clienttesting.PatchAction).GetName())
+		return true, &unstructured.Unstructured{}, nil
+	})
+	appliedConfigMap := func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return slices.Contains(applied, "configmaps/app")
+	}
+	createUpstream(t, upstream, distributionsGVR, newDistribution("default", "app", "ConfigMap", "edge"))
+	_, err := upstream.Resource(configMapsGVR).Namespace("default").Create(context.Background(), newObject("v1", "ConfigMap", "default", "app"), metav1.CreateOptions{})
+	require.NoError(t, err)
+	ctx := startTestSyncer(t, s, testOptions())
+
+	eventuallySyncTarget(t, ctx, s, func(syncTarget *tmcv1alpha1.SyncTarget) bool {
+		return conditions.IsTrue(syncTarget, tmcv1alpha1.Paused)
+	}, "the SyncTarget reports the pause")
+	require.Eventually(t, s.placement.HasSynced, wait.ForeverTestTimeout, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	require.False(t, appliedConfigMap(), "nothing is synced while paused")
+
+	syncTarget, err := getSyncTarget(ctx, upstream, "edge")
+	require.NoError(t, err)
+	syncTarget.Spec.Paused = false
+	createUpstream(t, upstream, syncTargetsGVR, syncTarget)
+	require.Eventually(t, appliedConfigMap, wait.ForeverTestTimeout, 10*time.Millisecond, "changes queued while paused are synced once resumed")
+	eventuallySyncTarget(t, ctx, s, func(syncTarget *tmcv1alpha1.SyncTarget) bool {
+		return conditions.Get(syncTarget, tmcv1alpha1.Paused) == nil
+	}, "the SyncTarget reports the resume")
+}
+
+// createUpstream creates or updates obj upstream.
+func createUpstream(t *testing.T, upstream *dynamicfake.FakeDynamicClient, gvr schema.GroupVersionResource, obj runtime.Object) {
+	t.Helper()
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	require.NoError(t, err)
+	o := &unstructured.Unstructured{Object: u}
+	if o.GetKind() == "" {
+		o.SetAPIVersion(gvr.GroupVersion().String())
+		o.SetKind(map[schema.GroupVersionResource]string{distributionsGVR: "WorkloadDistribution", syncTargetsGVR: "SyncTarget", propagationPoliciesGVR: "PropagationPolicy", priorityClassesGVR: "WorkloadPriorityClass", schedulingDefaultsGVR: "SchedulingDefaults"}[gvr])
+	}
+	if _, err := upstream.Resource(gvr).Namespace(o.GetNamespace()).Create(context.Background(), o, metav1.CreateOptions{}); err == nil {
+		return
+	}
+	_, err = upstream.Resource(gvr).Namespace(o.GetNamespace()).Update(context.Background(), o, metav1.UpdateOptions{})
+	require.NoError(t, err)
+}
+
+// trackApplies makes the server-side applies of client create or update
+// the applied objects, which the fake client does not do.
+func trackApplies(client *dynamicfake.FakeDynamicClient) {
+	client.PrependReactor("patch", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		patch := action.(clienttesting.PatchAction)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(patch.GetPatch()); err != nil {
+			return true, nil, err
+		}
+		tracker := client.Tracker()
+		if _, err := tracker.Get(action.GetResource(), patch.GetNamespace(), patch.GetName()); apierrors.IsNotFound(err) {
+			return true, obj, tracker.Create(action.GetResource(), obj, patch.GetNamespace())
+		}
+		return true, obj, tracker.Update(action.GetResource(), obj, patch.GetNamespace())
+	})
+}
+
+// newTestCluster returns a client of a physical cluster with a node
+// offering GPUs and a CSI driver.
+func newTestCluster(t *testing.T) kubernetes.Interface {
+	t.Helper()
+	responses := map[string]string{
+		"/version":                           `{"gitVersion":"v1.33.1"}`,
+		"/api":                               `{"kind":"APIVersions","versions":["v1"]}`,
+		"/apis":                              `{"kind":"APIGroupList","apiVersion":"v1","groups":[{"name":"apps","versions":[{"groupVersion":"apps/v1","version":"v1"}]}]}`,
+		"/metrics":                           `kubernetes_feature_enabled{name="SidecarContainers",stage="BETA"} 1` + "\n",
+		"/apis/storage.k8s.io/v1/csidrivers": `{"kind":"CSIDriverList","apiVersion":"storage.k8s.io/v1","items":[{"metadata":{"name":"ebs.csi.aws.com"}}]}`,
+		"/api/v1/nodes": `{"kind":"NodeList","apiVersion":"v1","items":[{"metadata":{"name":"node-1"},` +
+			`"status":{"capacity":{"cpu":"8","nvidia.com/gpu":"1"},"allocatable":{"cpu":"7","nvidia.com/gpu":"1"},"conditions":[{"type":"Ready","status":"True"}]}}]}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, found := responses[req.URL.Path]
+		if !found {
+			http.NotFound(w, req)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	client, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+	return client
+}
+
+func TestRunReportsCapabilities(t *testing.T) {
+	s, _, _ := newTestSyncer(t, &tmcv1alpha1.SyncTarget{})
+	s.downstreamKube = newTestCluster(t)
+	ctx := startTestSyncer(t, s, testOptions())
+
+	eventuallySyncTarget(t, ctx, s, func(syncTarget *tmcv1alpha1.SyncTarget) bool {
+		return syncTarget.Status.Capabilities != nil
+	}, "the capabilities of the physical cluster are reported")
+	syncTarget, err := getSyncTarget(ctx, s.upstream, "edge")
+	require.NoError(t, err)
+	require.Equal(t, "v1.33.1", syncTarget.Status.KubernetesVersion)
+	require.Equal(t, []string{"SidecarContainers"}, syncTarget.Status.Capabilities.FeatureGates)
+	require.Equal(t, []string{"apps/v1", "v1"}, syncTarget.Status.Capabilities.APIs)
+	require.Equal(t, []string{"ebs.csi.aws.com", "nvidia.com/gpu"}, syncTarget.Status.Capabilities.Addons)
+}
+
+func TestRunReportsCapacity(t *testing.T) {
+	s, _, _ := newTestSyncer(t, &tmcv1alpha1.SyncTarget{})
+	s.downstreamKube = newTestCluster(t)
+	ctx := startTestSyncer(t, s, testOptions())
+
+	eventuallySyncTarget(t, ctx, s, func(syncTarget *tmcv1alpha1.SyncTarget) bool {
+		return syncTarget.Status.Allocatable != nil
+	}, "the capacity of the physical cluster is reported")
+	syncTarget, err := getSyncTarget(ctx, s.upstream, "edge")
+	require.NoError(t, err)
+	require.True(t, resource.MustParse("8").Equal((*syncTarget.Status.Capacity)[corev1.ResourceCPU]))
+	require.True(t, resource.MustParse("7").Equal((*syncTarget.Status.Allocatable)[corev1.ResourceCPU]))
+	require.True(t, resource.MustParse("1").Equal((*syncTarget.Status.Allocatable)["nvidia.com/gpu"]))
+}
+
+func TestRunReportsReachability(t *testing.T) {
+	registry := httptest.NewServer(http.NotFoundHandler())
+	defer registry.Close()
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	require.NoError(t, closed.Close())
+	s, _, _ := newTestSyncer(t, &tmcv1alpha1.SyncTarget{Spec: tmcv1alpha1.SyncTargetSpec{
+		ReachabilityProbes: []tmcv1alpha1.ReachabilityProbe{
+			{Name: "registry", Address: registry.URL},
+			{Name: "database", Address: closed.Addr().String()},
+		},
+	}})
+	ctx := startTestSyncer(t, s, testOptions())
+
+	eventuallySyncTarget(t, ctx, s, func(syncTarget *tmcv1alpha1.SyncTarget) bool {
+		return len(syncTarget.Status.Reachability) == 2
+	}, "the probed endpoints are reported")
+	syncTarget, err := getSyncTarget(ctx, s.upstream, "edge")
+	require.NoError(t, err)
+	require.Equal(t, "database", syncTarget.Status.Reachability[0].Name)
+	require.False(t, syncTarget.Status.Reachability[0].Reachable, "the closed port is not reachable")
+	require.Equal(t, "registry", syncTarget.Status.Reachability[1].Name)
+	require.True(t, syncTarget.Status.Reachability[1].Reachable, "any HTTP response counts as reachable")
+	require.NotNil(t, syncTarget.Status.Reachability[1].LatencyMilliseconds)
+}
+
+func TestRunAppliesClientRateLimit(t *testing.T) {
+	s, upstream, _ := newTestSyncer(t, &tmcv1alpha1.SyncTarget{})
+	s.rateLimits = ratelimit.NewOptions()
+	s.rateLimiter = ratelimit.NewLimiter("root:org:edge", s.rateLimits.For(nil), s.rateLimits)
+	defer s.rateLimiter.Stop()
+	ctx := startTestSyncer(t, s, testOptions())
+	require.Equal(t, s.rateLimits.QPS, s.rateLimiter.QPS())
+
+	syncTarget, err := getSyncTarget(ctx, upstream, "edge")
+	require.NoError(t, err)
+	syncTarget.Spec.ClientRateLimit = &tmcv1alpha1.ClientRateLimit{QPS: ptr.To[int32](5)}
+	createUpstream(t, upstream, syncTargetsGVR, syncTarget)
+	require.Eventually(t, func() bool {
+		return s.rateLimiter.QPS() == 5
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "the rate limit of the SyncTarget is applied")
+}
+
+func TestRunFollowsWorkspaceMoves(t *testing.T) {
+	s, upstream, downstream := newTestSyncer(t, &tmcv1alpha1.SyncTarget{})
+	trackApplies(downstream)
+	var lock sync.Mutex
+	path := "root:org"
+	kcpClient := kcpfakeclient.NewSimpleClientset()
+	kcpClient.PrependReactor("get", "logicalclusters", func(action kcptesting.Action) (bool, runtime.Object, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		return true, &corev1alpha1.LogicalCluster{ObjectMeta: metav1.ObjectMeta{
+			Name:        corev1alpha1.LogicalClusterName,
+			Annotations: map[string]string{logicalcluster.AnnotationKey: "abc", core.LogicalClusterPathAnnotationKey: path},
+		}}, nil
+	})
+	s.relocations = relocation.NewHandler(kcpClient)
+	_, err := s.relocations.Resolve(context.Background(), s.target)
+	require.NoError(t, err)
+	createUpstream(t, upstream, distributionsGVR, newDistribution("default", "app", "ConfigMap", "edge"))
+	_, err = upstream.Resource(configMapsGVR).Namespace("default").Create(context.Background(), newObject("v1", "ConfigMap", "default", "app"), metav1.CreateOptions{})
+	require.NoError(t, err)
+	options := testOptions()
+	options.Relocation.Interval = 10 * time.Millisecond
+	ctx := startTestSyncer(t, s, options)
+
+	recordedPath := func(name string) string {
+		obj, err := downstream.Resource(configMapsGVR).Namespace(naming.Namespace("abc", "default")).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return ""
+		}
+		return obj.GetAnnotations()[naming.AnnotationUpstreamPath]
+	}
+	require.Eventually(t, func() bool {
+		return recordedPath("app") == "root:org"
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "the configmap is synced with the path of the workspace")
+
+	lock.Lock()
+	path = "root:other"
+	lock.Unlock()
+	require.Eventually(t, func() bool {
+		return recordedPath("app") == "root:other"
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "the configmap is relabeled with the new path of the workspace")
+
+	createUpstream(t, upstream, distributionsGVR, newDistribution("default", "new", "ConfigMap", "edge"))
+	_, err = upstream.Resource(configMapsGVR).Namespace("default").Create(ctx, newObject("v1", "ConfigMap", "default", "new"), metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return recordedPath("new") == "root:other"
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "objects synced after the move record the new path")
+}
+
+func TestRunServesQueueDiagnostics(t *testing.T) {
+	s, _, _ := newTestSyncer(t, &tmcv1alpha1.SyncTarget{})
+	server := diagnostics.NewServer(diagnostics.NewOptions(), nil)
+	options := testOptions()
+	options.Diagnostics = server
+	startTestSyncer(t, s, options)
+
+	require.Eventually(t, func() bool {
+		var names []string
+		for _, queue := range server.Queues() {
+			names = append(names, queue.Name)
+		}
+		return slices.Equal([]string{"root:org:edge//v1, Resource=configmaps", "root:org:edge//v1, Resource=services", "root:org:edge/apps/v1, Resource=deployments", "root:org:edge/placement"}, names)
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "the queues of the placement and the controllers are served")
+}
+
+func TestRunSyncsCriticalObjectsFirst(t *testing.T) {
+	s, upstream, downstream := newTestSyncer(t, &tmcv1alpha1.SyncTarget{})
+	var lock sync.Mutex
+	var applied []string
+	downstream.PrependReactor("patch", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if resource := action.GetResource().Resource; resource != "namespaces" {
+			// Slow enough for the deployment to overtake the config maps
+			// if it was not held.
+			time.Sleep(50 * time.Millisecond)
+			lock.Lock()
+			defer lock.Unlock()
+			applied = append(applied, resource+"/"+action.(clienttesting.PatchAction).GetName())
+		}
+		return true, &unstructured.Unstructured{}, nil
+	})
+	appliedObjects := func() []string {
+		lock.Lock()
+		defer lock.Unlock()
+		return slices.Clone(applied)
+	}
+	resourceConfig := filepath.Join(t.TempDir(), "resources.yaml")
+	require.NoError(t, os.WriteFile(resourceConfig, []byte(`resources:
+- version: v1
+  resource: configmaps
+  priority: critical
+  workers: 1
+- group: apps
+  version: v1
+  resource: deployments
+`), 0o600))
+
+	archive := newObject("v1", "ConfigMap", "default", "archive")
+	archive.SetAnnotations(map[string]string{workloadv1alpha1.AnnotationSyncPriority: "background"})
+	deployment := newDistribution("default", "web", "Deployment", "edge")
+	deployment.Spec.WorkloadRef.APIVersion = "apps/v1"
+	for _, d := range []*workloadv1alpha1.WorkloadDistribution{newDistribution("default", "archive", "ConfigMap", "edge"), newDistribution("default", "credentials", "ConfigMap", "edge"), deployment} {
+		createUpstream(t, upstream, distributionsGVR, d)
+	}
+	for _, obj := range []*unstructured.Unstructured{archive, newObject("v1", "ConfigMap", "default", "credentials")} {
+		_, err := upstream.Resource(configMapsGVR).Namespace("default").Create(context.Background(), obj, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+	_, err := upstream.Resource(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}).Namespace("default").Create(context.Background(), newObject("apps/v1", "Deployment", "default", "web"), metav1.CreateOptions{})
+	require.NoError(t, err)
+	options := testOptions()
+	options.ResourceConfig = resourceConfig
+	startTestSyncer(t, s, options)
+
+	require.Eventually(t, func() bool {
+		return len(appliedObjects()) == 3
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "all objects are synced")
+	require.Equal(t, []string{"configmaps/credentials", "configmaps/archive", "deployments/web"}, appliedObjects(),
+		"the objects of critical resources are synced first, in the order of their own class")
+}
+
+func TestRunAuditsDownstreamMutations(t *testing.T) {
+	s, upstream, downstream := newTestSyncer(t, &tmcv1alpha1.SyncTarget{})
+	downstream.PrependReactor("patch", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		patch := action.(clienttesting.PatchAction)
+		obj := &unstructured.Unstructured{}
+		obj.SetNamespace(patch.GetNamespace())
+		obj.SetName(patch.GetName())
+		return true, obj, nil
+	})
+	createUpstream(t, upstream, distributionsGVR, newDistribution("default", "app", "ConfigMap", "edge"))
+	_, err := upstream.Resource(configMapsGVR).Namespace("default").Create(context.Background(), newObject("v1", "ConfigMap", "default", "app"), metav1.CreateOptions{})
+	require.NoError(t, err)
+	auditOptions := audit.NewOptions()
+	auditOptions.Path = filepath.Join(t.TempDir(), "mutations.log")
+	auditLog, err := audit.NewLog(auditOptions, "")
+	require.NoError(t, err)
+	defer auditLog.Close()
+	options := testOptions()
+	options.AuditLog = auditLog
+	startTestSyncer(t, s, options)
+
+	var events []audit.Event
+	require.Eventually(t, func() bool {
+		events, err = auditLog.Query(audit.Filter{SyncTarget: "root:org:edge", Verb: audit.VerbCreate})
+		return err == nil && len(events) == 2
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "the downstream namespace and object are audited")
+	require.Equal(t, "namespaces", events[0].Resource)
+	require.Equal(t, naming.Namespace("abc", "default"), events[0].Name)
+	require.Equal(t, "configmaps", events[1].Resource)
+	require.Equal(t, naming.Namespace("abc", "default"), events[1].Namespace)
+	require.Equal(t, "app", events[1].Name)
+}
+
+func TestRunDecryptsSensitiveObjectsOnlyToSendThem(t *testing.T) {
+	s, upstream, downstream := newTestSyncer(t, &tmcv1alpha1.SyncTarget{})
+	trackApplies(downstream)
+	createUpstream(t, upstream, distributionsGVR, newDistribution("default", "app", "ConfigMap", "edge"))
+	cm := newObject("v1", "ConfigMap", "default", "app")
+	cm.SetAnnotations(map[string]string{workloadv1alpha1.AnnotationSensitive: "true"})
+	require.NoError(t, unstructured.SetNestedField(cm.Object, "hunter2", "data", "password"))
+	_, err := upstream.Resource(configMapsGVR).Namespace("default").Create(context.Background(), cm, metav1.CreateOptions{})
+	require.NoError(t, err)
+	dir := t.TempDir()
+	auditOptions := audit.NewOptions()
+	auditOptions.Path = filepath.Join(dir, "mutations.log")
+	auditLog, err := audit.NewLog(auditOptions, "")
+	require.NoError(t, err)
+	defer auditLog.Close()
+	options := testOptions()
+	options.AuditLog = auditLog
+	options.BatchInterval = 10 * time.Millisecond
+	options.SensitiveEncryptionConfig = filepath.Join(dir, "encryption.yaml")
+	require.NoError(t, os.WriteFile(options.SensitiveEncryptionConfig, []byte(`keys:
+- id: "1"
+  algorithm: aes-gcm
+  secret: MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=
+`), 0o600))
+	ctx := startTestSyncer(t, s, options)
+
+	require.Eventually(t, func() bool {
+		obj, err := downstream.Resource(configMapsGVR).Namespace(naming.Namespace("abc", "default")).Get(ctx, "app", metav1.GetOptions{})
+		if err != nil {
+			return false
+		}
+		password, _, _ := unstructured.NestedString(obj.Object, "data", "password")
+		return password == "hunter2"
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "the sensitive configmap is synced in plaintext")
+	var events []audit.Event
+	require.Eventually(t, func() bool {
+		events, err = auditLog.Query(audit.Filter{SyncTarget: "root:org:edge", Verb: audit.VerbDecrypt})
+		return err == nil && len(events) == 1
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "the decryption is audited")
+	require.Equal(t, "configmaps", events[0].Resource)
+	require.Equal(t, "app", events[0].Name)
+}
+
+func TestRunAppliesPropagationPolicies(t *testing.T) {
+	s, upstream, downstream := newTestSyncer(t, &tmcv1alpha1.SyncTarget{})
+	var lock sync.Mutex
+	applied := map[string]string{}
+	downstream.PrependReactor("patch", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(action.(clienttesting.PatchAction).GetPatch()); err != nil {
+			return true, nil, err
+		}
+		if action.GetResource() == configMapsGVR {
+			lock.Lock()
+			defer lock.Unlock()
+			applied = obj.GetLabels()
+		}
+		return true, obj, nil
+	})
+	labelsApplied := func() map[string]string {
+		lock.Lock()
+		defer lock.Unlock()
+		return applied
+	}
+	policy := &workloadv1alpha1.PropagationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "strip-team"},
+		Spec: workloadv1alpha1.PropagationPolicySpec{
+			Labels: workloadv1alpha1.MetadataPropagation{Strip: []string{"team"}},
+		},
+	}
+	createUpstream(t, upstream, propagationPoliciesGVR, policy)
+	createUpstream(t, upstream, distributionsGVR, newDistribution("default", "app", "ConfigMap", "edge"))
+	cm := newObject("v1", "ConfigMap", "default", "app")
+	cm.SetLabels(map[string]string{"app": "web", "team": "a"})
+	_, err := upstream.Resource(configMapsGVR).Namespace("default").Create(context.Background(), cm, metav1.CreateOptions{})
+	require.NoError(t, err)
+	startTestSyncer(t, s, testOptions())
+
+	require.Eventually(t, func() bool {
+		return maps.Equal(labelsApplied(), map[string]string{"app": "web", LabelSyncTarget: s.key})
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "the label stripped by the policy is not synced")
+
+	policy.Spec.Labels.Strip = nil
+	createUpstream(t, upstream, propagationPoliciesGVR, policy)
+	require.Eventually(t, func() bool {
+		return maps.Equal(labelsApplied(), map[string]string{"app": "web", "team": "a", LabelSyncTarget: s.key})
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "the object is synced again when the policy changes")
+}
+
+func TestRunEnforcesGuardrails(t *testing.T) {
+	s, upstream, downstream := newTestSyncer(t, &tmcv1alpha1.SyncTarget{Spec: tmcv1alpha1.SyncTargetSpec{
+		Guardrails: &tmcv1alpha1.SyncTargetGuardrails{MaxObjects: ptr.To[int64](1)},
+	}})
+	trackApplies(downstream)
+	for _, name := range []string{"a", "b"} {
+		createUpstream(t, upstream, distributionsGVR, newDistribution("default", name, "ConfigMap", "edge"))
+		_, err := upstream.Resource(configMapsGVR).Namespace("default").Create(context.Background(), newObject("v1", "ConfigMap", "default", name), metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+	ctx := startTestSyncer(t, s, testOptions())
+
+	synced := func() int {
+		list, err := downstream.Resource(configMapsGVR).Namespace(naming.Namespace("abc", "default")).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return 0
+		}
+		return len(list.Items)
+	}
+	eventuallySyncTarget(t, ctx, s, func(syncTarget *tmcv1alpha1.SyncTarget) bool {
+		return syncTarget.Status.Usage != nil && syncTarget.Status.Usage.Objects == 1 && syncTarget.Status.Usage.ManifestBytes > 0
+	}, "the usage is reported")
+	require.Never(t, func() bool { return synced() > 1 }, 200*time.Millisecond, 10*time.Millisecond, "objects beyond the guardrails are not synced")
+	require.Equal(t, 1, synced())
+
+	syncTarget, err := upstream.Resource(syncTargetsGVR).Get(ctx, "edge", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NoError(t, unstructured.SetNestedField(syncTarget.Object, int64(2), "spec", "guardrails", "maxObjects"))
+	_, err = upstream.Resource(syncTargetsGVR).Update(ctx, syncTarget, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return synced() == 2 }, wait.ForeverTestTimeout, 10*time.Millisecond, "objects that fit into raised guardrails are synced")
+}
+
+func TestRunSkipsUnchangedApplies(t *testing.T) {
+	s, upstream, downstream := newTestSyncer(t, &tmcv1alpha1.SyncTarget{})
+	trackApplies(downstream)
+	var applies atomic.Int32
+	downstream.PrependReactor("patch", "configmaps", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		applies.Add(1)
+		return false, nil, nil
+	})
+	createUpstream(t, upstream, distributionsGVR, newDistribution("default", "app", "ConfigMap", "edge"))
+	cm := newObject("v1", "ConfigMap", "default", "app")
+	require.NoError(t, unstructured.SetNestedField(cm.Object, "a", "data", "key"))
+	_, err := upstream.Resource(configMapsGVR).Namespace("default").Create(context.Background(), cm, metav1.CreateOptions{})
+	require.NoError(t, err)
+	ctx := startTestSyncer(t, s, testOptions())
+
+	synced := func(value string) bool {
+		obj, err := downstream.Resource(configMapsGVR).Namespace(naming.Namespace("abc", "default")).Get(ctx, "app", metav1.GetOptions{})
+		if err != nil {
+			return false
+		}
+		data, _, _ := unstructured.NestedString(obj.Object, "data", "key")
+		// Compared with the object in the informer.
+		c, found := s.controllers.Load(schema.GroupKind{Kind: "ConfigMap"})
+		return data == value && found && c.(*controller).existing(obj.GetNamespace(), obj.GetName()) != nil
+	}
+	require.Eventually(t, func() bool { return synced("a") }, wait.ForeverTestTimeout, 10*time.Millisecond, "the configmap is synced")
+	require.Equal(t, int32(1), applies.Load())
+
+	// Updates are synced again, but not applied if nothing changed.
+	_, err = upstream.Resource(configMapsGVR).Namespace("default").Update(ctx, cm, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.Never(t, func() bool { return applies.Load() > 1 }, 200*time.Millisecond, 10*time.Millisecond, "unchanged objects are not applied again")
+
+	require.NoError(t, unstructured.SetNestedField(cm.Object, "b", "data", "key"))
+	_, err = upstream.Resource(configMapsGVR).Namespace("default").Update(ctx, cm, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return synced("b") }, wait.ForeverTestTimeout, 10*time.Millisecond, "changed objects are applied")
+}
+
+func TestRunSyncsBundles(t *testing.T) {
+	s, upstream, downstream := newTestSyncer(t, &tmcv1alpha1.SyncTarget{
+		Status: tmcv1alpha1.SyncTargetStatus{
+			Capabilities: &tmcv1alpha1.SyncTargetCapabilities{APIs: []string{"v1", "monitoring.coreos.com/v1"}},
+		},
+	})
+	trackApplies(downstream)
+	monitor := newDistribution("default", "web", "ServiceMonitor", "edge")
+	monitor.Spec.WorkloadRef.APIVersion = "monitoring.coreos.com/v1"
+	createUpstream(t, upstream, distributionsGVR, monitor)
+	_, err := upstream.Resource(serviceMonitorsGVR).Namespace("default").Create(context.Background(), newObject("monitoring.coreos.com/v1", "ServiceMonitor", "default", "web"), metav1.CreateOptions{})
+	require.NoError(t, err)
+	ctx := startTestSyncer(t, s, testOptions())
+
+	synced := func() bool {
+		_, err := downstream.Resource(serviceMonitorsGVR).Namespace(naming.Namespace("abc", "default")).Get(ctx, "web", metav1.GetOptions{})
+		return err == nil
+	}
+	require.Never(t, synced, 200*time.Millisecond, 10*time.Millisecond, "bundles are only synced for workspaces opting into them")
+
+	lc, err := upstream.Resource(logicalClustersGVR).Get(ctx, corev1alpha1.LogicalClusterName, metav1.GetOptions{})
+	require.NoError(t, err)
+	lc.SetAnnotations(map[string]string{bundles.AnnotationSyncBundles: "observability"})
+	_, err = upstream.Resource(logicalClustersGVR).Update(ctx, lc, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, synced, wait.ForeverTestTimeout, 10*time.Millisecond, "the servicemonitor is synced once the workspace opts into the observability bundle")
+}
+
+func TestRunMapsPriorityClasses(t *testing.T) {
+	s, upstream, downstream := newTestSyncer(t, &tmcv1alpha1.SyncTarget{})
+	downstreamKube := kubefake.NewSimpleClientset()
+	s.downstreamKube = downstreamKube
+	trackApplies(downstream)
+	createUpstream(t, upstream, priorityClassesGVR, &workloadv1alpha1.WorkloadPriorityClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "high"},
+		Spec:       workloadv1alpha1.WorkloadPriorityClassSpec{Value: 1000, DownstreamName: "tenant-high"},
+	})
+	deployment := newDistribution("default", "web", "Deployment", "edge")
+	deployment.Spec.WorkloadRef.APIVersion = "apps/v1"
+	createUpstream(t, upstream, distributionsGVR, deployment)
+	web := newObject("apps/v1", "Deployment", "default", "web")
+	require.NoError(t, unstructured.SetNestedField(web.Object, map[string]interface{}{"priorityClassName": "high", "priority": int64(1000)}, "spec", "template", "spec"))
+	_, err := upstream.Resource(deploymentsGVR).Namespace("default").Create(context.Background(), web, metav1.CreateOptions{})
+	require.NoError(t, err)
+	options := testOptions()
+	// The fake clientset serves neither metrics nor nodes.
+	options.CapabilitiesInterval, options.CapacityInterval = 0, 0
+	ctx := startTestSyncer(t, s, options)
+
+	var synced *unstructured.Unstructured
+	require.Eventually(t, func() bool {
+		synced, err = downstream.Resource(deploymentsGVR).Namespace(naming.Namespace("abc", "default")).Get(ctx, "web", metav1.GetOptions{})
+		return err == nil
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "the deployment is synced")
+	podSpec, _, err := unstructured.NestedMap(synced.Object, "spec", "template", "spec")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"priorityClassName": "tenant-high"}, podSpec, "the pods use the PriorityClass of the physical cluster")
+	priorityClass, err := downstreamKube.SchedulingV1().PriorityClasses().Get(ctx, "tenant-high", metav1.GetOptions{})
+	require.NoError(t, err, "the PriorityClass is created on the physical cluster")
+	require.Equal(t, int32(1000), priorityClass.Value)
+}
+
+func TestRunAppliesSchedulingDefaults(t *testing.T) {
+	s, upstream, downstream := newTestSyncer(t, &tmcv1alpha1.SyncTarget{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"pool": "edge-pool"}}})
+	trackApplies(downstream)
+	createUpstream(t, upstream, schedulingDefaultsGVR, &workloadv1alpha1.SchedulingDefaults{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge"},
+		Spec: workloadv1alpha1.SchedulingDefaultsSpec{
+			NodeSelector: map[string]string{"node-pool": "${target.labels.pool}"},
+			Tolerations:  []corev1.Toleration{{Key: "edge", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}},
+		},
+	})
+	deployment := newDistribution("default", "web", "Deployment", "edge")
+	deployment.Spec.WorkloadRef.APIVersion = "apps/v1"
+	createUpstream(t, upstream, distributionsGVR, deployment)
+	web := newObject("apps/v1", "Deployment", "default", "web")
+	require.NoError(t, unstructured.SetNestedField(web.Object, map[string]interface{}{"nodeSelector": map[string]interface{}{"disk": "ssd"}}, "spec", "template", "spec"))
+	_, err := upstream.Resource(deploymentsGVR).Namespace("default").Create(context.Background(), web, metav1.CreateOptions{})
+	require.NoError(t, err)
+	ctx := startTestSyncer(t, s, testOptions())
+
+	var synced *unstructured.Unstructured
+	require.Eventually(t, func() bool {
+		synced, err = downstream.Resource(deploymentsGVR).Namespace(naming.Namespace("abc", "default")).Get(ctx, "web", metav1.GetOptions{})
+		return err == nil
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "the deployment is synced")
+	podSpec, _, err := unstructured.NestedMap(synced.Object, "spec", "template", "spec")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"nodeSelector": map[string]interface{}{"disk": "ssd", "node-pool": "edge-pool"},
+		"tolerations":  []interface{}{map[string]interface{}{"key": "edge", "operator": "Exists", "effect": "NoSchedule"}},
+	}, podSpec, "the pods tolerate the taints and are pinned to the node pool of the SyncTarget")
+}
+
+func TestRunRendersFanOutConfigMaps(t *testing.T) {
+	s, upstream, downstream := newTestSyncer(t, &tmcv1alpha1.SyncTarget{Spec: tmcv1alpha1.SyncTargetSpec{Location: "us-east"}})
+	trackApplies(downstream)
+	createUpstream(t, upstream, distributionsGVR, newDistribution("default", "settings", "ConfigMap", "edge"))
+	settings := newObject("v1", "ConfigMap", "default", "settings")
+	settings.SetAnnotations(map[string]string{workloadv1alpha1.AnnotationConfigFanOut: "true"})
+	settings.Object["data"] = map[string]interface{}{"region": "${target.location}"}
+	_, err := upstream.Resource(configMapsGVR).Namespace("default").Create(context.Background(), settings, metav1.CreateOptions{})
+	require.NoError(t, err)
+	ctx := startTestSyncer(t, s, testOptions())
+
+	rendered := func() map[string]string {
+		obj, err := downstream.Resource(configMapsGVR).Namespace(naming.Namespace("abc", "default")).Get(ctx, "settings", metav1.GetOptions{})
+		if err != nil {
+			return nil
+		}
+		require.NotContains(t, obj.GetAnnotations(), workloadv1alpha1.AnnotationConfigFanOut)
+		data, _, _ := unstructured.NestedStringMap(obj.Object, "data")
+		return data
+	}
+	require.Eventually(t, func() bool {
+		return maps.Equal(rendered(), map[string]string{"region": "us-east"})
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "the ConfigMap is rendered for the SyncTarget")
+
+	syncTarget, err := getSyncTarget(ctx, upstream, "edge")
+	require.NoError(t, err)
+	syncTarget.Spec.Location = "eu-west"
+	createUpstream(t, upstream, syncTargetsGVR, syncTarget)
+	require.Eventually(t, func() bool {
+		return maps.Equal(rendered(), map[string]string{"region": "eu-west"})
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "the ConfigMap is rendered again when the SyncTarget changes")
+}
+
+func TestRunArbitratesClusterScopedObjects(t *testing.T) {
+	admin := newObject("rbac.authorization.k8s.io/v1", "ClusterRole", "", "admin")
+	admin.Object["rules"] = []interface{}{map[string]interface{}{"verbs": []interface{}{"*"}}}
+	s, upstream, downstream := newTestSyncer(t, &tmcv1alpha1.SyncTarget{Spec: tmcv1alpha1.SyncTargetSpec{
+		ClusterScopedResources: []tmcv1alpha1.ClusterScopedResource{{Group: "rbac.authorization.k8s.io", Resource: "clusterroles"}},
+	}}, admin.DeepCopy())
+	trackApplies(downstream)
+	for _, name := range []string{"admin", "reader"} {
+		_, err := upstream.Resource(clusterRolesGVR).Create(context.Background(), newObject("rbac.authorization.k8s.io/v1", "ClusterRole", "", name), metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+	resourceConfig := filepath.Join(t.TempDir(), "resources.yaml")
+	require.NoError(t, os.WriteFile(resourceConfig, []byte(`resources:
+- group: rbac.authorization.k8s.io
+  version: v1
+  resource: clusterroles
+`), 0o600))
+	options := testOptions()
+	options.ResourceConfig = resourceConfig
+	ctx := startTestSyncer(t, s, options)
+
+	require.Eventually(t, func() bool {
+		reader, err := downstream.Resource(clusterRolesGVR).Get(ctx, "reader", metav1.GetOptions{})
+		return err == nil && reader.GetAnnotations()[clusterscoped.AnnotationOwners] == "abc"
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "the object is synced and owned by the workspace")
+	eventuallySyncTarget(t, ctx, s, func(syncTarget *tmcv1alpha1.SyncTarget) bool {
+		return conditions.IsFalse(syncTarget, tmcv1alpha1.ClusterScopedResourcesSynced) &&
+			conditions.GetReason(syncTarget, tmcv1alpha1.ClusterScopedResourcesSynced) == tmcv1alpha1.CollisionReason &&
+			strings.Contains(conditions.GetMessage(syncTarget, tmcv1alpha1.ClusterScopedResourcesSynced), `"admin"`)
+	}, "the collision with the object of the cluster administrator is reported")
+	existing, err := downstream.Resource(clusterRolesGVR).Get(ctx, "admin", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, admin.Object, existing.Object, "the object of the cluster administrator is kept")
+}
+
+func TestRunReportsAdmissionDenials(t *testing.T) {
+	var lock sync.Mutex
+	bodies := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		lock.Lock()
+		bodies[req.Method+" "+req.URL.Path] = string(body)
+		lock.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+	reported := func(request string) string {
+		lock.Lock()
+		defer lock.Unlock()
+		return bodies[request]
+	}
+	client, err := kcpdynamic.NewForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+
+	s, upstream, downstream := newTestSyncer(t, &tmcv1alpha1.SyncTarget{})
+	s.feedback = admissionfeedback.NewReporter(client, admissionfeedback.RetryPolicy{})
+	downstream.PrependReactor("patch", "configmaps", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, &apierrors.StatusError{ErrStatus: metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    http.StatusBadRequest,
+			Message: `admission webhook "policy.example.com" denied the request: data must not be empty`,
+		}}
+	})
+	downstream.PrependReactor("patch", "namespaces", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, &unstructured.Unstructured{}, nil
+	})
+	createUpstream(t, upstream, distributionsGVR, newDistribution("default", "app", "ConfigMap", "edge"))
+	_, err = upstream.Resource(configMapsGVR).Namespace("default").Create(context.Background(), newObject("v1", "ConfigMap", "default", "app"), metav1.CreateOptions{})
+	require.NoError(t, err)
+	startTestSyncer(t, s, testOptions())
+
+	require.Eventually(t, func() bool {
+		return strings.Contains(reported("PATCH /clusters/abc/api/v1/namespaces/default/configmaps/app/status"), `"reason":"AdmissionDenied"`)
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "the denial is reported in the SyncFailed condition of the upstream object")
+	require.Contains(t, reported("PATCH /clusters/abc/api/v1/namespaces/default/configmaps/app/status"), "data must not be empty", "the message of the webhook is reported verbatim")
+	require.Eventually(t, func() bool {
+		return strings.Contains(reported("POST /clusters/abc/api/v1/namespaces/default/events"), "data must not be empty")
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "an event is recorded")
+}
+
+func TestRunPrePullsImages(t *testing.T) {
+	s, upstream, downstream := newTestSyncer(t, &tmcv1alpha1.SyncTarget{})
+	trackApplies(downstream)
+	// Applies of the annotation of the SyncTarget keep the other fields.
+	upstream.PrependReactor("patch", "workloaddistributions", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		patch := action.(clienttesting.PatchAction)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		applied := &unstructured.Unstructured{}
+		if err := applied.UnmarshalJSON(patch.GetPatch()); err != nil {
+			return true, nil, err
+		}
+		existing, err := upstream.Tracker().Get(action.GetResource(), patch.GetNamespace(), patch.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		obj := existing.(*unstructured.Unstructured).DeepCopy()
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		maps.Copy(annotations, applied.GetAnnotations())
+		obj.SetAnnotations(annotations)
+		return true, obj, upstream.Tracker().Update(action.GetResource(), obj, patch.GetNamespace())
+	})
+	downstreamKube := kubefake.NewSimpleClientset()
+	downstreamKube.PrependReactor("create", "daemonsets", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		// A node to pull onto.
+		action.(clienttesting.CreateAction).GetObject().(*appsv1.DaemonSet).Status.DesiredNumberScheduled = 1
+		return false, nil, nil
+	})
+	s.downstreamKube = downstreamKube
+	web := newDistribution("default", "web", "Deployment", "edge")
+	web.Spec.WorkloadRef.APIVersion = "apps/v1"
+	web.Spec.PrePull = &workloadv1alpha1.ImagePrePull{}
+	createUpstream(t, upstream, distributionsGVR, web)
+	deployment := newObject("apps/v1", "Deployment", "default", "web")
+	require.NoError(t, unstructured.SetNestedSlice(deployment.Object, []interface{}{map[string]interface{}{"name": "app", "image": "registry.example.com/app:v2"}}, "spec", "template", "spec", "containers"))
+	_, err := upstream.Resource(deploymentsGVR).Namespace("default").Create(context.Background(), deployment, metav1.CreateOptions{})
+	require.NoError(t, err)
+	options := testOptions()
+	// The fake clientset serves neither metrics nor nodes.
+	options.CapabilitiesInterval, options.CapacityInterval = 0, 0
+	ctx := startTestSyncer(t, s, options)
+
+	prePulled := func() *conditionsv1alpha1.Condition {
+		obj, err := upstream.Resource(distributionsGVR).Namespace("default").Get(ctx, "web", metav1.GetOptions{})
+		require.NoError(t, err)
+		d := &workloadv1alpha1.WorkloadDistribution{}
+		require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, d))
+		return conditions.Get(d, workloadv1alpha1.ImagesPrePulled)
+	}
+	namespace := naming.Namespace("abc", "default")
+	require.Eventually(t, func() bool {
+		c := prePulled()
+		return c != nil && c.Reason == workloadv1alpha1.PrePullInProgressReason && c.Message == "edge: pulled onto 0 of 1 nodes"
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "the progress of pulling is reported")
+	daemonSets, err := downstreamKube.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, daemonSets.Items, 1)
+	ds := daemonSets.Items[0]
+	require.Equal(t, "registry.example.com/app:v2", ds.Spec.Template.Spec.Containers[0].Image)
+
+	_, err = downstreamKube.CoreV1().Pods(namespace).Create(ctx, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: ds.Name + "-node-1", Labels: ds.Spec.Template.Labels},
+		Spec:       ds.Spec.Template.Spec,
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+			{Name: ds.Spec.Template.Spec.Containers[0].Name, ImageID: "registry.example.com/app@sha256:0123"},
+		}},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		c := prePulled()
+		return c != nil && c.Status == corev1.ConditionTrue
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "the images are reported pulled")
+	_, err = downstreamKube.AppsV1().DaemonSets(namespace).Get(ctx, ds.Name, metav1.GetOptions{})
+	require.True(t, apierrors.IsNotFound(err), "the pre-pull DaemonSet is deleted once the images are pulled")
+}
+
+func TestRunDiscoversServices(t *testing.T) {
+	s, upstream, _ := newTestSyncer(t, &tmcv1alpha1.SyncTarget{})
+	namespace := naming.Namespace("abc", "default")
+	port := workloadv1alpha1.ServicePort{Name: "http", Protocol: corev1.ProtocolTCP, Port: 8080}
+	downstreamKube := kubefake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "web", Labels: map[string]string{LabelSyncTarget: s.key}}},
+		&discoveryv1.EndpointSlice{
+			ObjectMeta:  metav1.ObjectMeta{Namespace: namespace, Name: "web-1", Labels: map[string]string{discoveryv1.LabelServiceName: "web"}},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints:   []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.1"}}},
+			Ports:       []discoveryv1.EndpointPort{{Name: ptr.To("http"), Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(int32(8080))}},
+		},
+	)
+	s.downstreamKube = downstreamKube
+	createUpstream(t, upstream, serviceExportsGVR, &workloadv1alpha1.ServiceExport{
+		TypeMeta:   metav1.TypeMeta{APIVersion: workloadv1alpha1.SchemeGroupVersion.String(), Kind: "ServiceExport"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+	})
+	createUpstream(t, upstream, serviceImportsGVR, &workloadv1alpha1.ServiceImport{
+		TypeMeta:   metav1.TypeMeta{APIVersion: workloadv1alpha1.SchemeGroupVersion.String(), Kind: "ServiceImport"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api"},
+		Spec:       workloadv1alpha1.ServiceImportSpec{Type: workloadv1alpha1.ClusterSetIP, Ports: []workloadv1alpha1.ServicePort{port}},
+		Status: workloadv1alpha1.ServiceImportStatus{Targets: []workloadv1alpha1.ServiceEndpoints{
+			{SyncTarget: "west", Addresses: []string{"10.1.0.1"}, Ports: []workloadv1alpha1.ServicePort{port}},
+		}},
+	})
+	options := testOptions()
+	options.CapabilitiesInterval, options.CapacityInterval = 0, 0
+	ctx := startTestSyncer(t, s, options)
+
+	require.Eventually(t, func() bool {
+		export, err := upstream.Resource(serviceExportsGVR).Namespace("default").Get(ctx, "web", metav1.GetOptions{})
+		return err == nil && strings.Contains(export.GetAnnotations()[servicediscovery.EndpointsAnnotation("edge")], "10.0.0.1")
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "the endpoints of the exported Service are reported")
+	require.Eventually(t, func() bool {
+		slices, err := downstreamKube.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{LabelSelector: discoveryv1.LabelServiceName + "=imported-api"})
+		return err == nil && len(slices.Items) == 1 && slices.Items[0].Endpoints[0].Addresses[0] == "10.1.0.1"
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "the ServiceImport is mirrored")
+
+	require.NoError(t, upstream.Resource(serviceImportsGVR).Namespace("default").Delete(ctx, "api", metav1.DeleteOptions{}))
+	require.Eventually(t, func() bool {
+		_, err := downstreamKube.CoreV1().Services(namespace).Get(ctx, "imported-api", metav1.GetOptions{})
+		return apierrors.IsNotFound(err)
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "the mirror of the deleted ServiceImport is removed")
+}
+
+func TestRunDeliversManifestWorks(t *testing.T) {
+	s, upstream, downstream := newTestSyncer(t, &tmcv1alpha1.SyncTarget{Spec: tmcv1alpha1.SyncTargetSpec{DeliveryMode: tmcv1alpha1.DeliveryModeManifestWork}})
+	trackApplies(downstream)
+	createUpstream(t, upstream, distributionsGVR, newDistribution("default", "app", "ConfigMap", "edge"))
+	_, err := upstream.Resource(configMapsGVR).Namespace("default").Create(context.Background(), newObject("v1", "ConfigMap", "default", "app"), metav1.CreateOptions{})
+	require.NoError(t, err)
+	options := testOptions()
+	options.ManifestWorkNamespace = "cluster1"
+	ctx := startTestSyncer(t, s, options)
+
+	name := workapi.WorkName("abc", "configmap-default-app")
+	var work *unstructured.Unstructured
+	require.Eventually(t, func() bool {
+		work, err = downstream.Resource(workapi.ManifestWorksGVR).Namespace("cluster1").Get(ctx, name, metav1.GetOptions{})
+		return err == nil
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "the workload is delivered as a ManifestWork")
+	require.Equal(t, s.key, work.GetLabels()[LabelSyncTarget])
+	manifests, _, err := unstructured.NestedSlice(work.Object, "spec", "workload", "manifests")
+	require.NoError(t, err)
+	require.Len(t, manifests, 2, "the downstream namespace is delivered with the object")
+	require.Equal(t, "Namespace", manifests[0].(map[string]interface{})["kind"])
+	configMap := &unstructured.Unstructured{Object: manifests[1].(map[string]interface{})}
+	require.Equal(t, naming.Namespace("abc", "default"), configMap.GetNamespace())
+	_, err = downstream.Resource(configMapsGVR).Namespace(naming.Namespace("abc", "default")).Get(ctx, "app", metav1.GetOptions{})
+	require.True(t, apierrors.IsNotFound(err), "the object is not applied to the hub")
+
+	require.NoError(t, unstructured.SetNestedField(work.Object, map[string]interface{}{
+		"resourceStatus": map[string]interface{}{"manifests": []interface{}{map[string]interface{}{
+			"resourceMeta": map[string]interface{}{"resource": "configmaps", "namespace": configMap.GetNamespace(), "name": "app"},
+			"conditions": []interface{}{map[string]interface{}{
+				"type": "Applied", "status": "False", "reason": "AppliedManifestFailed", "message": "quota exceeded", "lastTransitionTime": "2025-01-01T00:00:00Z",
+			}},
+		}}},
+	}, "status"))
+	_, err = downstream.Resource(workapi.ManifestWorksGVR).Namespace("cluster1").Update(ctx, work, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	eventuallySyncTarget(t, ctx, s, func(syncTarget *tmcv1alpha1.SyncTarget) bool {
+		return conditions.IsFalse(syncTarget, tmcv1alpha1.ManifestWorksApplied) &&
+			strings.Contains(conditions.GetMessage(syncTarget, tmcv1alpha1.ManifestWorksApplied), "quota exceeded")
+	}, "the SyncTarget reports the manifests the work agent did not apply")
+
+	require.NoError(t, upstream.Resource(distributionsGVR).Namespace("default").Delete(ctx, "app", metav1.DeleteOptions{}))
+	require.Eventually(t, func() bool {
+		_, err := downstream.Resource(workapi.ManifestWorksGVR).Namespace("cluster1").Get(ctx, name, metav1.GetOptions{})
+		return apierrors.IsNotFound(err)
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "the ManifestWork of a workload no longer placed is deleted")
+}
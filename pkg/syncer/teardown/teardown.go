@@ -0,0 +1,97 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package teardown removes what a syncer synced to its physical cluster
+// once its SyncTarget is being deleted, and reports the progress in the
+// DownstreamResourcesRemoved condition, which the SyncTarget finalizer in
+// kcp waits for before the SyncTarget goes away.
+package teardown
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// Remover deletes synced objects from a physical cluster.
+type Remover struct {
+	deleteCollection func(ctx context.Context, gvr schema.GroupVersionResource, selector labels.Selector) error
+	count            func(ctx context.Context, gvr schema.GroupVersionResource, selector labels.Selector) (int, error)
+}
+
+// NewRemover returns a remover deleting objects with client.
+func NewRemover(client dynamic.Interface) *Remover {
+	return &Remover{
+		deleteCollection: func(ctx context.Context, gvr schema.GroupVersionResource, selector labels.Selector) error {
+			return client.Resource(gvr).Namespace(metav1.NamespaceAll).DeleteCollection(ctx, metav1.DeleteOptions{}, metav1.ListOptions{LabelSelector: selector.String()})
+		},
+		count: func(ctx context.Context, gvr schema.GroupVersionResource, selector labels.Selector) (int, error) {
+			list, err := client.Resource(gvr).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+			if err != nil {
+				return 0, err
+			}
+			return len(list.Items), nil
+		},
+	}
+}
+
+// Remove deletes the objects of the resources matching the selector, i.e.
+// those the syncer synced, and returns how many are left, e.g. because
+// their finalizers did not run yet. Resources that do not exist on the
+// physical cluster are skipped.
+func (r *Remover) Remove(ctx context.Context, resources []schema.GroupVersionResource, selector labels.Selector) (int, error) {
+	logger := klog.FromContext(ctx)
+	remaining := 0
+	for _, gvr := range resources {
+		if err := r.deleteCollection(ctx, gvr, selector); apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return 0, fmt.Errorf("failed to delete %s: %w", gvr, err)
+		}
+		n, err := r.count(ctx, gvr, selector)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list %s: %w", gvr, err)
+		}
+		if n > 0 {
+			logger.V(4).Info("downstream objects still being deleted", "resource", gvr, "count", n)
+		}
+		remaining += n
+	}
+	return remaining, nil
+}
+
+// SetCondition sets the DownstreamResourcesRemoved condition of target from
+// the result of Remove.
+func SetCondition(target *tmcv1alpha1.SyncTarget, remaining int, err error) {
+	switch {
+	case err != nil:
+		conditions.MarkFalse(target, tmcv1alpha1.DownstreamResourcesRemoved, tmcv1alpha1.DownstreamResourcesRemainingReason, conditionsv1alpha1.ConditionSeverityWarning, "%v", err)
+	case remaining > 0:
+		conditions.MarkFalse(target, tmcv1alpha1.DownstreamResourcesRemoved, tmcv1alpha1.DownstreamResourcesRemainingReason, conditionsv1alpha1.ConditionSeverityInfo, "%d objects are still being deleted", remaining)
+	default:
+		conditions.MarkTrue(target, tmcv1alpha1.DownstreamResourcesRemoved)
+	}
+}
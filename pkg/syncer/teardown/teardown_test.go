@@ -0,0 +1,71 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package teardown
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func TestRemove(t *testing.T) {
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	widgets := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	configMaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	selector := labels.SelectorFromSet(labels.Set{"tmc.kcp.io/sync-target": "edge-1"})
+
+	objects := map[schema.GroupVersionResource]int{deployments: 2, configMaps: 3}
+	var deleted []schema.GroupVersionResource
+	r := &Remover{
+		deleteCollection: func(_ context.Context, gvr schema.GroupVersionResource, s labels.Selector) error {
+			require.Equal(t, selector.String(), s.String())
+			if gvr == widgets {
+				return apierrors.NewNotFound(gvr.GroupResource(), "")
+			}
+			deleted = append(deleted, gvr)
+			if gvr == configMaps {
+				objects[gvr] = 0
+			}
+			return nil
+		},
+		count: func(_ context.Context, gvr schema.GroupVersionResource, _ labels.Selector) (int, error) {
+			return objects[gvr], nil
+		},
+	}
+
+	remaining, err := r.Remove(context.Background(), []schema.GroupVersionResource{deployments, widgets, configMaps}, selector)
+	require.NoError(t, err)
+	require.Equal(t, 2, remaining, "deployments are held by finalizers")
+	require.Equal(t, []schema.GroupVersionResource{deployments, configMaps}, deleted)
+
+	target := &tmcv1alpha1.SyncTarget{}
+	SetCondition(target, remaining, nil)
+	require.Equal(t, "2 objects are still being deleted", conditions.GetMessage(target, tmcv1alpha1.DownstreamResourcesRemoved))
+	SetCondition(target, 0, errors.New("forbidden"))
+	require.Equal(t, tmcv1alpha1.DownstreamResourcesRemainingReason, conditions.GetReason(target, tmcv1alpha1.DownstreamResourcesRemoved))
+	SetCondition(target, 0, nil)
+	require.True(t, conditions.IsTrue(target, tmcv1alpha1.DownstreamResourcesRemoved))
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bandwidth enforces the bandwidth budget of a syncer connection,
+// configured by spec.connection.bandwidthLimit of its SyncTarget, for edge
+// clusters on constrained links.
+//
+// The round tripper is meant to sit below the compression round tripper,
+// closest to the wire, so that the budget counts compressed bytes:
+//
+//	compression.NewRoundTripper(bandwidth.NewRoundTripper(transport, limiter), ...)
+//
+// Request bodies and response bodies, including watch streams, share the
+// budget and wait for it while they are read.
+package bandwidth
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// minBurst is the smallest burst of a limited connection, so that small
+// budgets do not split every read into tiny waits.
+const minBurst = 4 * 1024
+
+// LimitFor returns the bandwidth budget of target in bytes per second, or
+// zero if it is unlimited.
+func LimitFor(target *tmcv1alpha1.SyncTarget) int64 {
+	if target == nil || target.Spec.Connection == nil || target.Spec.Connection.BandwidthLimit == nil {
+		return 0
+	}
+	return max(target.Spec.Connection.BandwidthLimit.Value(), 0)
+}
+
+// Limiter is the bandwidth budget of one SyncTarget, shared by all its
+// requests.
+type Limiter struct {
+	syncTarget string
+	now        func() time.Time
+
+	lock    sync.RWMutex
+	limit   int64
+	limiter *rate.Limiter
+}
+
+// NewLimiter returns a limiter of bytesPerSecond for syncTarget, which
+// labels the metrics. Zero means unlimited.
+func NewLimiter(syncTarget string, bytesPerSecond int64) *Limiter {
+	l := &Limiter{
+		syncTarget: syncTarget,
+		now:        time.Now,
+	}
+	l.SetLimit(bytesPerSecond)
+	return l
+}
+
+// SetLimit changes the budget to bytesPerSecond, e.g. when the SyncTarget
+// changed. Zero means unlimited. The burst is a second of budget.
+func (l *Limiter) SetLimit(bytesPerSecond int64) {
+	bytesPerSecond = max(bytesPerSecond, 0)
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.limiter != nil && bytesPerSecond == l.limit {
+		return
+	}
+	l.limit = bytesPerSecond
+	bandwidthLimit.WithLabelValues(l.syncTarget).Set(float64(bytesPerSecond))
+	if bytesPerSecond == 0 {
+		l.limiter = nil
+		return
+	}
+	burst := int(min(max(bytesPerSecond, minBurst), math.MaxInt32))
+	if l.limiter == nil {
+		l.limiter = rate.NewLimiter(rate.Limit(bytesPerSecond), burst)
+		return
+	}
+	l.limiter.SetLimit(rate.Limit(bytesPerSecond))
+	l.limiter.SetBurst(burst)
+}
+
+// Limit returns the budget in bytes per second, or zero if unlimited.
+func (l *Limiter) Limit() int64 {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	return l.limit
+}
+
+// WaitN blocks until n bytes may be transferred in direction, or ctx is
+// done.
+func (l *Limiter) WaitN(ctx context.Context, n int, direction string) error {
+	transferredBytes.WithLabelValues(l.syncTarget, direction).Add(float64(n))
+	l.lock.RLock()
+	limiter := l.limiter
+	l.lock.RUnlock()
+	if limiter == nil {
+		return nil
+	}
+
+	start := l.now()
+	defer func() {
+		waitSeconds.WithLabelValues(l.syncTarget, direction).Add(l.now().Sub(start).Seconds())
+	}()
+	for n > 0 {
+		chunk := min(n, limiter.Burst())
+		if err := limiter.WaitN(ctx, chunk); err != nil {
+			return err
+		}
+		n -= chunk
+	}
+	return nil
+}
+
+// NewRoundTripper returns a round tripper making request and response
+// bodies wait for the budget of limiter.
+func NewRoundTripper(delegate http.RoundTripper, limiter *Limiter) http.RoundTripper {
+	return &roundTripper{delegate: delegate, limiter: limiter}
+}
+
+type roundTripper struct {
+	delegate http.RoundTripper
+	limiter  *Limiter
+}
+
+var _ utilnet.RoundTripperWrapper = &roundTripper{}
+
+func (rt *roundTripper) WrappedRoundTripper() http.RoundTripper {
+	return rt.delegate
+}
+
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if req.Body != nil && req.Body != http.NoBody {
+		req = req.Clone(ctx)
+		req.Body = &limitedReadCloser{ReadCloser: req.Body, ctx: ctx, limiter: rt.limiter, direction: directionSent}
+		if getBody := req.GetBody; getBody != nil {
+			req.GetBody = func() (io.ReadCloser, error) {
+				body, err := getBody()
+				if err != nil {
+					return nil, err
+				}
+				return &limitedReadCloser{ReadCloser: body, ctx: ctx, limiter: rt.limiter, direction: directionSent}, nil
+			}
+		}
+	}
+
+	resp, err := rt.delegate.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Body != nil && resp.Body != http.NoBody {
+		resp.Body = &limitedReadCloser{ReadCloser: resp.Body, ctx: ctx, limiter: rt.limiter, direction: directionReceived}
+	}
+	return resp, nil
+}
+
+// limitedReadCloser waits for the budget of the bytes read.
+type limitedReadCloser struct {
+	io.ReadCloser
+	ctx       context.Context
+	limiter   *Limiter
+	direction string
+}
+
+func (r *limitedReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		if waitErr := r.limiter.WaitN(r.ctx, n, r.direction); waitErr != nil && err == nil {
+			err = waitErr
+		}
+	}
+	return n, err
+}
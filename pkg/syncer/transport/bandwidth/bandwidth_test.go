@@ -0,0 +1,87 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bandwidth
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestLimitFor(t *testing.T) {
+	require.Zero(t, LimitFor(&tmcv1alpha1.SyncTarget{}))
+	limit := resource.MustParse("512Ki")
+	require.Equal(t, int64(512*1024), LimitFor(&tmcv1alpha1.SyncTarget{Spec: tmcv1alpha1.SyncTargetSpec{
+		Connection: &tmcv1alpha1.SyncTargetConnection{BandwidthLimit: &limit},
+	}}))
+}
+
+func TestRoundTripperWaitsForBudget(t *testing.T) {
+	limiter := NewLimiter("edge", 64*1024)
+	rt := NewRoundTripper(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body))}, nil
+	}), limiter)
+
+	// The burst is a second of budget, and the request and the response
+	// share it, so echoing a burst waits for about a second.
+	payload := strings.Repeat("x", 64*1024)
+	start := time.Now()
+	req, err := http.NewRequest(http.MethodPost, "https://kcp/apis", strings.NewReader(payload))
+	require.NoError(t, err)
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, payload, string(body))
+	require.GreaterOrEqual(t, time.Since(start), 900*time.Millisecond)
+
+	// Unlimited connections do not wait.
+	limiter.SetLimit(0)
+	require.Zero(t, limiter.Limit())
+	start = time.Now()
+	require.NoError(t, limiter.WaitN(context.Background(), 10*1024*1024, directionSent))
+	require.Less(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestWaitStopsWithContext(t *testing.T) {
+	limiter := NewLimiter("edge", 4*1024)
+	require.NoError(t, limiter.WaitN(context.Background(), 4*1024, directionSent))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.Error(t, limiter.WaitN(ctx, 4*1024, directionSent))
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bandwidth
+
+import (
+	"sync"
+
+	compbasemetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const (
+	directionSent     = "sent"
+	directionReceived = "received"
+)
+
+var (
+	transferredBytes = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Name:           "syncer_transport_bandwidth_bytes_total",
+			Help:           "Number of bytes on the wire of the connection of a SyncTarget to kcp, by direction.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"sync_target", "direction"},
+	)
+	waitSeconds = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Name:           "syncer_transport_bandwidth_wait_seconds_total",
+			Help:           "Time transfers of the connection of a SyncTarget to kcp waited for the bandwidth budget, by direction.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"sync_target", "direction"},
+	)
+	bandwidthLimit = compbasemetrics.NewGaugeVec(
+		&compbasemetrics.GaugeOpts{
+			Name:           "syncer_transport_bandwidth_limit_bytes",
+			Help:           "Bandwidth budget of the connection of a SyncTarget to kcp, in bytes per second. Zero means unlimited.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"sync_target"},
+	)
+)
+
+var registerMetrics sync.Once
+
+// Register metrics.
+func Register() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(transferredBytes)
+		legacyregistry.MustRegister(waitSeconds)
+		legacyregistry.MustRegister(bandwidthLimit)
+	})
+}
+
+func init() {
+	Register()
+}
@@ -0,0 +1,327 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package batch coalesces the sync operations of a syncer per resource
+// before they are sent, for edge clusters on constrained links. Operations
+// on the same object replace each other until they are sent, so that an
+// object changing several times while the link is busy is sent once.
+//
+// Batches are sent per resource, in a stable order, and bounded by size so
+// that a single resource cannot take the whole bandwidth budget of the
+// SyncTarget, see package bandwidth. Sending a batch waits for the budget,
+// during which further operations are coalesced.
+//
+// Objects marked sensitive are kept encrypted while pending if an envelope
+// is configured, and decrypted right before their batch is sent.
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/sensitive"
+	"github.com/kcp-dev/kcp/pkg/tmc/retry"
+)
+
+const (
+	// controllerName names the batcher in the audit log.
+	controllerName = "batcher"
+
+	defaultFlushInterval = 100 * time.Millisecond
+	defaultMaxBatchBytes = 1024 * 1024
+)
+
+// Operation is a sync operation on an object.
+type Operation struct {
+	// ClusterName is the logical cluster of the object, if any.
+	ClusterName logicalcluster.Name
+	GVR         schema.GroupVersionResource
+	Namespace   string
+	Name        string
+	// Object is the object to write, or nil to delete the object.
+	Object *unstructured.Unstructured
+}
+
+// SendFunc sends the operations of a batch, all of the same resource. It
+// returns the operations that failed, with their error, to be sent again.
+type SendFunc func(ctx context.Context, gvr schema.GroupVersionResource, ops []Operation) map[int]error
+
+// Options configure a Batcher. Zero values mean the defaults.
+type Options struct {
+	// FlushInterval is how often pending operations are sent.
+	FlushInterval time.Duration
+	// MaxBatchBytes bounds the serialized size of the objects of a batch.
+	// Operations beyond it are sent with the next flush. A single larger
+	// operation is sent alone.
+	MaxBatchBytes int64
+	// BandwidthLimit returns the bandwidth budget of the SyncTarget in
+	// bytes per second, or zero if unlimited. A limited batch is bounded to
+	// one second of budget if that is less than MaxBatchBytes.
+	BandwidthLimit func() int64
+	// Envelope encrypts the objects of pending operations on sensitive
+	// objects. Sensitive objects are kept in plaintext if nil.
+	Envelope *sensitive.Envelope
+}
+
+type key struct {
+	clusterName logicalcluster.Name
+	gvr         schema.GroupVersionResource
+	namespace   string
+	name        string
+}
+
+type pending struct {
+	op Operation
+	// sealed is the encrypted object of an operation on a sensitive
+	// object, whose Object is nil until it is sent.
+	sealed   []byte
+	size     int64
+	sequence int
+	failures int
+	// notBefore delays an operation whose send failed.
+	notBefore time.Time
+}
+
+// Batcher coalesces operations per resource until they are sent.
+type Batcher struct {
+	options Options
+	send    SendFunc
+	now     func() time.Time
+
+	lock     sync.Mutex
+	pending  map[schema.GroupVersionResource]map[key]*pending
+	sequence int
+}
+
+// NewBatcher returns a batcher sending batches with send.
+func NewBatcher(send SendFunc, options Options) *Batcher {
+	if options.FlushInterval <= 0 {
+		options.FlushInterval = defaultFlushInterval
+	}
+	if options.MaxBatchBytes <= 0 {
+		options.MaxBatchBytes = defaultMaxBatchBytes
+	}
+	return &Batcher{
+		options: options,
+		send:    send,
+		now:     time.Now,
+		pending: map[schema.GroupVersionResource]map[key]*pending{},
+	}
+}
+
+// Add queues op, replacing a pending operation on the same object.
+func (b *Batcher) Add(op Operation) {
+	var size int64
+	var sealed []byte
+	switch {
+	case op.Object != nil && b.options.Envelope != nil && sensitive.Sensitive(op.Object):
+		var err error
+		if sealed, err = b.options.Envelope.Seal(context.TODO(), op.Object); err != nil {
+			utilruntime.HandleError(fmt.Errorf("failed to encrypt sensitive %s %s/%s: %w", op.GVR, op.Namespace, op.Name, err))
+			return
+		}
+		op.Object = nil
+		size = int64(len(sealed))
+	case op.Object != nil:
+		data, err := op.Object.MarshalJSON()
+		if err != nil {
+			utilruntime.HandleError(fmt.Errorf("failed to serialize %s %s/%s: %w", op.GVR, op.Namespace, op.Name, err))
+			return
+		}
+		size = int64(len(data))
+	}
+	k := key{clusterName: op.ClusterName, gvr: op.GVR, namespace: op.Namespace, name: op.Name}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	batch, found := b.pending[op.GVR]
+	if !found {
+		batch = map[key]*pending{}
+		b.pending[op.GVR] = batch
+	}
+	operationsQueued.WithLabelValues(op.GVR.String()).Inc()
+	if _, found := batch[k]; found {
+		operationsCoalesced.WithLabelValues(op.GVR.String()).Inc()
+	}
+	b.sequence++
+	batch[k] = &pending{op: op, sealed: sealed, size: size, sequence: b.sequence}
+}
+
+// Pending returns the number of operations not sent yet.
+func (b *Batcher) Pending() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	n := 0
+	for _, batch := range b.pending {
+		n += len(batch)
+	}
+	return n
+}
+
+// Run sends pending operations until ctx is done.
+func (b *Batcher) Run(ctx context.Context) {
+	defer utilruntime.HandleCrash()
+
+	logger := klog.FromContext(ctx)
+	logger.Info("Starting sync operation batcher")
+	defer logger.Info("Shutting down sync operation batcher")
+
+	ticker := time.NewTicker(b.options.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.Flush(ctx)
+		}
+	}
+}
+
+// Flush sends one batch per resource, in the order of the resources.
+func (b *Batcher) Flush(ctx context.Context) {
+	b.lock.Lock()
+	gvrs := make([]schema.GroupVersionResource, 0, len(b.pending))
+	for gvr := range b.pending {
+		gvrs = append(gvrs, gvr)
+	}
+	b.lock.Unlock()
+	sort.Slice(gvrs, func(i, j int) bool { return gvrs[i].String() < gvrs[j].String() })
+
+	for _, gvr := range gvrs {
+		if ctx.Err() != nil {
+			return
+		}
+		keys, ops := b.open(ctx, gvr)
+		if len(ops) == 0 {
+			continue
+		}
+		batchSize.WithLabelValues(gvr.String()).Observe(float64(len(ops)))
+		failed := b.send(ctx, gvr, ops)
+		for i, err := range failed {
+			klog.FromContext(ctx).V(2).Info("failed to send sync operation", "resource", gvr.String(), "namespace", ops[i].Namespace, "name", ops[i].Name, "err", err)
+			b.requeue(keys[i])
+		}
+	}
+}
+
+// maxBatchBytes returns the bound of the size of a batch.
+func (b *Batcher) maxBatchBytes() int64 {
+	if b.options.BandwidthLimit != nil {
+		if limit := b.options.BandwidthLimit(); limit > 0 {
+			return min(b.options.MaxBatchBytes, limit)
+		}
+	}
+	return b.options.MaxBatchBytes
+}
+
+// take removes the next batch of gvr from the pending operations, oldest
+// first, and returns it with its pending entries.
+func (b *Batcher) take(gvr schema.GroupVersionResource) ([]*taken, []Operation) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := b.now()
+	var candidates []*taken
+	for k, p := range b.pending[gvr] {
+		if p.notBefore.After(now) {
+			continue
+		}
+		candidates = append(candidates, &taken{key: k, pending: p})
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].pending.sequence < candidates[j].pending.sequence })
+
+	maxBytes := b.maxBatchBytes()
+	var keys []*taken
+	var ops []Operation
+	var size int64
+	for _, c := range candidates {
+		if len(ops) > 0 && size+c.pending.size > maxBytes {
+			break
+		}
+		size += c.pending.size
+		delete(b.pending[gvr], c.key)
+		keys = append(keys, c)
+		ops = append(ops, c.pending.op)
+	}
+	if len(b.pending[gvr]) == 0 {
+		delete(b.pending, gvr)
+	}
+	return keys, ops
+}
+
+// open takes the next batch of gvr and decrypts its sensitive objects.
+// Operations whose object cannot be decrypted, e.g. while a key rotation is
+// in progress, are queued again. Operations whose object cannot be decoded
+// are dropped.
+func (b *Batcher) open(ctx context.Context, gvr schema.GroupVersionResource) ([]*taken, []Operation) {
+	keys, ops := b.take(gvr)
+	opened := keys[:0]
+	openedOps := ops[:0]
+	for i, t := range keys {
+		op := ops[i]
+		if t.pending.sealed != nil {
+			obj, err := b.options.Envelope.Open(ctx, controllerName, gvr, t.pending.sealed)
+			if errors.Is(err, sensitive.ErrUndecodable) {
+				utilruntime.HandleError(fmt.Errorf("dropping sync operation on %s %s/%s: %w", gvr, op.Namespace, op.Name, err))
+				continue
+			} else if err != nil {
+				klog.FromContext(ctx).V(2).Info("failed to decrypt sync operation", "resource", gvr.String(), "namespace", op.Namespace, "name", op.Name, "err", err)
+				b.requeue(t)
+				continue
+			}
+			op.Object = obj
+		}
+		opened = append(opened, t)
+		openedOps = append(openedOps, op)
+	}
+	return opened, openedOps
+}
+
+type taken struct {
+	key     key
+	pending *pending
+}
+
+// requeue puts a failed operation back, unless a newer operation on the
+// object is pending, backing off by the standard retry profile.
+func (b *Batcher) requeue(t *taken) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	batch, found := b.pending[t.key.gvr]
+	if _, superseded := batch[t.key]; superseded {
+		return
+	}
+	if !found {
+		batch = map[key]*pending{}
+		b.pending[t.key.gvr] = batch
+	}
+	t.pending.failures++
+	t.pending.notBefore = b.now().Add(retry.Standard().Delay(t.pending.failures))
+	operationsRequeued.WithLabelValues(t.key.gvr.String()).Inc()
+	batch[t.key] = t.pending
+}
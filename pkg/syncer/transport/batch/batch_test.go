@@ -0,0 +1,165 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package batch
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kcp-dev/kcp/pkg/crypto/encryption"
+	"github.com/kcp-dev/kcp/pkg/syncer/sensitive"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+var (
+	configMapsGVR  = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	deploymentsGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+)
+
+func op(gvr schema.GroupVersionResource, name, data string) Operation {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"data": map[string]interface{}{"value": data}}}
+	obj.SetName(name)
+	obj.SetNamespace("default")
+	return Operation{GVR: gvr, Namespace: "default", Name: name, Object: obj}
+}
+
+type sent struct {
+	gvr   schema.GroupVersionResource
+	names []string
+}
+
+func recorder(batches *[]sent, fail map[string]bool) SendFunc {
+	return func(_ context.Context, gvr schema.GroupVersionResource, ops []Operation) map[int]error {
+		s := sent{gvr: gvr}
+		failed := map[int]error{}
+		for i, op := range ops {
+			s.names = append(s.names, op.Name)
+			if fail[op.Name] {
+				failed[i] = errors.New("connection reset")
+			}
+		}
+		*batches = append(*batches, s)
+		return failed
+	}
+}
+
+func TestBatcherCoalescesPerResource(t *testing.T) {
+	var batches []sent
+	b := NewBatcher(recorder(&batches, nil), Options{})
+
+	b.Add(op(deploymentsGVR, "web", "1"))
+	b.Add(op(configMapsGVR, "config", "1"))
+	b.Add(op(deploymentsGVR, "db", "1"))
+	b.Add(op(deploymentsGVR, "web", "2"))
+	b.Add(Operation{GVR: configMapsGVR, Namespace: "default", Name: "config"})
+	require.Equal(t, 3, b.Pending())
+
+	b.Flush(context.Background())
+	require.Equal(t, []sent{
+		{gvr: configMapsGVR, names: []string{"config"}},
+		{gvr: deploymentsGVR, names: []string{"db", "web"}},
+	}, batches)
+	require.Equal(t, 0, b.Pending())
+}
+
+func TestBatcherBoundsBatchBySize(t *testing.T) {
+	var batches []sent
+	limit := int64(0)
+	b := NewBatcher(recorder(&batches, nil), Options{
+		MaxBatchBytes:  1024 * 1024,
+		BandwidthLimit: func() int64 { return limit },
+	})
+	large := strings.Repeat("x", 600)
+
+	b.Add(op(configMapsGVR, "a", large))
+	b.Add(op(configMapsGVR, "b", large))
+	limit = 1000
+	b.Flush(context.Background())
+	require.Equal(t, []sent{{gvr: configMapsGVR, names: []string{"a"}}}, batches, "a batch holds at most a second of budget")
+
+	b.Flush(context.Background())
+	require.Equal(t, []sent{
+		{gvr: configMapsGVR, names: []string{"a"}},
+		{gvr: configMapsGVR, names: []string{"b"}},
+	}, batches)
+}
+
+func TestBatcherRequeuesFailedOperations(t *testing.T) {
+	var batches []sent
+	fail := map[string]bool{"web": true, "db": true}
+	b := NewBatcher(recorder(&batches, fail), Options{})
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	b.Add(op(deploymentsGVR, "web", "1"))
+	b.Add(op(deploymentsGVR, "db", "1"))
+	b.Add(op(deploymentsGVR, "cache", "1"))
+	b.Flush(context.Background())
+	require.Equal(t, 2, b.Pending(), "failed operations are queued again")
+
+	// A newer operation supersedes the failed one and is not delayed.
+	fail["web"] = false
+	b.Add(op(deploymentsGVR, "web", "2"))
+	b.Flush(context.Background())
+	require.Equal(t, sent{gvr: deploymentsGVR, names: []string{"web"}}, batches[1], "the failed operation backs off")
+
+	fail["db"] = false
+	now = now.Add(time.Minute)
+	b.Flush(context.Background())
+	require.Equal(t, sent{gvr: deploymentsGVR, names: []string{"db"}}, batches[2])
+	require.Equal(t, 0, b.Pending())
+}
+
+func TestBatcherKeepsSensitiveObjectsEncrypted(t *testing.T) {
+	key, err := encryption.NewAESGCMKey("1", []byte("0123456789abcdef"))
+	require.NoError(t, err)
+	keyring, err := encryption.NewKeyring(key, nil, false)
+	require.NoError(t, err)
+
+	var objects []*unstructured.Unstructured
+	b := NewBatcher(func(_ context.Context, _ schema.GroupVersionResource, ops []Operation) map[int]error {
+		for _, op := range ops {
+			objects = append(objects, op.Object)
+		}
+		return nil
+	}, Options{Envelope: sensitive.NewEnvelope(keyring, nil)})
+
+	secret := op(configMapsGVR, "credentials", "hunter2")
+	secret.Object.SetAnnotations(map[string]string{workloadv1alpha1.AnnotationSensitive: "true"})
+	b.Add(secret)
+	b.Add(op(configMapsGVR, "config", "debug"))
+
+	for _, batch := range b.pending {
+		for _, p := range batch {
+			if p.op.Name == "credentials" {
+				require.Nil(t, p.op.Object)
+				require.NotContains(t, string(p.sealed), "hunter2", "sensitive objects are encrypted while pending")
+			}
+		}
+	}
+
+	b.Flush(context.Background())
+	require.Equal(t, []*unstructured.Unstructured{secret.Object, op(configMapsGVR, "config", "debug").Object}, objects, "sensitive objects are decrypted before they are sent")
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package compression implements negotiated payload compression for the
+// channel between the workload syncer and the syncer virtual workspace.
+//
+// The syncer advertises the algorithms it supports in Accept-Encoding, in
+// preference order, and compresses request bodies with its preferred
+// algorithm. The virtual workspace picks the first advertised algorithm it
+// also supports for responses, including long-running watch streams.
+package compression
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Algorithm is the name of a content coding as used in HTTP headers.
+type Algorithm string
+
+const (
+	// Identity disables compression.
+	Identity Algorithm = "identity"
+	// Gzip is the gzip content coding.
+	Gzip Algorithm = "gzip"
+	// Zstd is the zstandard content coding.
+	Zstd Algorithm = "zstd"
+)
+
+// Level selects the speed/ratio trade-off of a codec.
+type Level string
+
+const (
+	LevelFastest Level = "Fastest"
+	LevelDefault Level = "Default"
+	LevelBest    Level = "Best"
+)
+
+// DefaultMinSize is the body size below which payloads are sent uncompressed.
+const DefaultMinSize = 1024
+
+// Settings configures compression for a single syncer connection.
+type Settings struct {
+	// Algorithms lists the accepted algorithms in preference order. An empty
+	// list disables compression.
+	Algorithms []Algorithm
+	// Level is the compression level used for outgoing payloads.
+	Level Level
+	// MinSize is the request body size in bytes under which no compression
+	// is applied. Zero means DefaultMinSize.
+	MinSize int64
+}
+
+// DefaultSettings prefers zstd and falls back to gzip.
+func DefaultSettings() Settings {
+	return Settings{
+		Algorithms: []Algorithm{Zstd, Gzip},
+		Level:      LevelDefault,
+		MinSize:    DefaultMinSize,
+	}
+}
+
+// Validate checks that all algorithms and the level are known.
+func (s Settings) Validate() error {
+	for _, a := range s.Algorithms {
+		if _, ok := codecs[a]; !ok && a != Identity {
+			return fmt.Errorf("unsupported compression algorithm %q", a)
+		}
+	}
+	switch s.Level {
+	case "", LevelFastest, LevelDefault, LevelBest:
+	default:
+		return fmt.Errorf("unsupported compression level %q", s.Level)
+	}
+	if s.MinSize < 0 {
+		return fmt.Errorf("minimum size must not be negative, got %d", s.MinSize)
+	}
+	return nil
+}
+
+func (s Settings) minSize() int64 {
+	if s.MinSize == 0 {
+		return DefaultMinSize
+	}
+	return s.MinSize
+}
+
+// AcceptEncoding renders the Accept-Encoding header value for the settings.
+func (s Settings) AcceptEncoding() string {
+	names := make([]string, 0, len(s.Algorithms))
+	for _, a := range s.Algorithms {
+		if a == Identity {
+			continue
+		}
+		names = append(names, string(a))
+	}
+	return strings.Join(names, ", ")
+}
+
+// preferred returns the first algorithm that can actually compress.
+func (s Settings) preferred() Algorithm {
+	for _, a := range s.Algorithms {
+		if _, ok := codecs[a]; ok {
+			return a
+		}
+	}
+	return Identity
+}
+
+// Negotiate returns the first algorithm from the Accept-Encoding header value
+// that is also listed in supported. Quality values of zero exclude an
+// algorithm; other quality values are ignored and header order wins.
+func Negotiate(acceptEncoding string, supported []Algorithm) Algorithm {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(part, ";")
+		name := Algorithm(strings.ToLower(strings.TrimSpace(fields[0])))
+		if name == "" || excluded(fields[1:]) {
+			continue
+		}
+		for _, s := range supported {
+			if s == name {
+				if _, ok := codecs[s]; ok {
+					return s
+				}
+			}
+		}
+	}
+	return Identity
+}
+
+func excluded(params []string) bool {
+	for _, p := range params {
+		p = strings.ReplaceAll(strings.TrimSpace(p), " ", "")
+		if p == "q=0" || p == "q=0.0" || p == "q=0.00" || p == "q=0.000" {
+			return true
+		}
+	}
+	return false
+}
+
+// codec creates compressing writers and decompressing readers.
+type codec interface {
+	NewWriter(w io.Writer, level Level) (writeFlushCloser, error)
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+type writeFlushCloser interface {
+	io.WriteCloser
+	Flush() error
+}
+
+var codecs = map[Algorithm]codec{
+	Gzip: gzipCodec{},
+	Zstd: zstdCodec{},
+}
+
+type gzipCodec struct{}
+
+func (gzipCodec) NewWriter(w io.Writer, level Level) (writeFlushCloser, error) {
+	l := gzip.DefaultCompression
+	switch level {
+	case LevelFastest:
+		l = gzip.BestSpeed
+	case LevelBest:
+		l = gzip.BestCompression
+	}
+	return gzip.NewWriterLevel(w, l)
+}
+
+func (gzipCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+type zstdCodec struct{}
+
+func (zstdCodec) NewWriter(w io.Writer, level Level) (writeFlushCloser, error) {
+	l := zstd.SpeedDefault
+	switch level {
+	case LevelFastest:
+		l = zstd.SpeedFastest
+	case LevelBest:
+		l = zstd.SpeedBestCompression
+	}
+	return zstd.NewWriter(w, zstd.WithEncoderLevel(l))
+}
+
+func (zstdCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	d, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return d.IOReadCloser(), nil
+}
+
+// NewReader wraps r with a decompressor for the given algorithm.
+func NewReader(algorithm Algorithm, r io.Reader) (io.ReadCloser, error) {
+	if algorithm == Identity || algorithm == "" {
+		return io.NopCloser(r), nil
+	}
+	c, ok := codecs[algorithm]
+	if !ok {
+		return nil, fmt.Errorf("unsupported content encoding %q", algorithm)
+	}
+	return c.NewReader(r)
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compression
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := map[string]struct {
+		accept    string
+		supported []Algorithm
+		want      Algorithm
+	}{
+		"empty header":            {accept: "", supported: []Algorithm{Zstd, Gzip}, want: Identity},
+		"client order wins":       {accept: "gzip, zstd", supported: []Algorithm{Zstd, Gzip}, want: Gzip},
+		"unsupported skipped":     {accept: "br, zstd", supported: []Algorithm{Zstd}, want: Zstd},
+		"zero quality excluded":   {accept: "zstd;q=0, gzip", supported: []Algorithm{Zstd, Gzip}, want: Gzip},
+		"case insensitive":        {accept: "GZIP", supported: []Algorithm{Gzip}, want: Gzip},
+		"server disabled":         {accept: "gzip", supported: nil, want: Identity},
+		"identity never selected": {accept: "identity", supported: []Algorithm{Identity}, want: Identity},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tt.want, Negotiate(tt.accept, tt.supported))
+		})
+	}
+}
+
+func TestSettingsValidate(t *testing.T) {
+	require.NoError(t, DefaultSettings().Validate())
+	require.Error(t, Settings{Algorithms: []Algorithm{"br"}}.Validate())
+	require.Error(t, Settings{Level: "Ludicrous"}.Validate())
+	require.Error(t, Settings{MinSize: -1}.Validate())
+}
+
+func TestRoundTrip(t *testing.T) {
+	payload := strings.Repeat(`{"apiVersion":"v1","kind":"ConfigMap"}`, 200)
+
+	for _, algorithm := range []Algorithm{Gzip, Zstd} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			settings := Settings{Algorithms: []Algorithm{algorithm}, MinSize: 16}
+
+			var gotEncoding string
+			server := httptest.NewServer(WithCompression(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				body, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+				require.Equal(t, payload, string(body))
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write(body)
+			}), settings, nil))
+			defer server.Close()
+
+			wire := &recordingRoundTripper{delegate: http.DefaultTransport, onRequest: func(req *http.Request) {
+				gotEncoding = req.Header.Get("Content-Encoding")
+			}}
+			client := &http.Client{Transport: NewRoundTripper(wire, "test", settings)}
+
+			resp, err := client.Post(server.URL, "application/json", strings.NewReader(payload))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.Equal(t, payload, string(body))
+			require.Equal(t, string(algorithm), gotEncoding)
+			require.Equal(t, string(algorithm), wire.responseEncoding)
+			require.Empty(t, resp.Header.Get("Content-Encoding"))
+		})
+	}
+}
+
+func TestHandlerNoBody(t *testing.T) {
+	handler := WithCompression(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), DefaultSettings(), nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/", nil)
+	req.Header.Set("Accept-Encoding", "zstd")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Empty(t, rec.Header().Get("Content-Encoding"))
+	require.Zero(t, rec.Body.Len())
+}
+
+func TestHandlerHidesAcceptEncoding(t *testing.T) {
+	handler := WithCompression(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		require.Empty(t, req.Header.Get("Accept-Encoding"), "the response is encoded by the handler")
+		_, _ = w.Write([]byte("ok"))
+	}), DefaultSettings(), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "zstd")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	require.Equal(t, "zstd", rec.Header().Get("Content-Encoding"))
+}
+
+func TestHandlerRejectsUnknownEncoding(t *testing.T) {
+	handler := WithCompression(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		t.Fatal("handler must not be called")
+	}), DefaultSettings(), nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("x"))
+	req.Header.Set("Content-Encoding", "br")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+}
+
+type recordingRoundTripper struct {
+	delegate         http.RoundTripper
+	onRequest        func(req *http.Request)
+	responseEncoding string
+}
+
+func (rt *recordingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.onRequest(req)
+	resp, err := rt.delegate.RoundTrip(req)
+	if err == nil {
+		rt.responseEncoding = resp.Header.Get("Content-Encoding")
+	}
+	return resp, err
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compression
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"k8s.io/klog/v2"
+)
+
+// ConnectionNameFunc returns the metric label identifying the connection a
+// request belongs to, usually the SyncTarget name.
+type ConnectionNameFunc func(req *http.Request) string
+
+// WithCompression is the virtual workspace side of the negotiation. It
+// decodes compressed request bodies and encodes responses with the first
+// algorithm from the request's Accept-Encoding header that is also in
+// settings.Algorithms. Responses are flushed through the encoder so watch
+// streams keep working. The delegate does not see the Accept-Encoding of
+// responses encoded here, so that a proxying delegate does not forward it
+// and return an encoded response to be encoded again.
+func WithCompression(delegate http.Handler, settings Settings, connectionName ConnectionNameFunc) http.Handler {
+	if connectionName == nil {
+		connectionName = func(*http.Request) string { return "" }
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		connection := connectionName(req)
+
+		if encoding := req.Header.Get("Content-Encoding"); encoding != "" && req.Body != nil {
+			algorithm := Algorithm(encoding)
+			counted := &countingReader{delegate: req.Body}
+			body, err := NewReader(algorithm, counted)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+				return
+			}
+			original := req.Body
+			req = req.Clone(req.Context())
+			req.Body = &observingReadCloser{
+				ReadCloser: body,
+				wire:       counted,
+				underlying: original,
+				done: func(uncompressed, compressed int64) {
+					observe(connection, algorithm, directionReceived, uncompressed, compressed)
+				},
+			}
+			req.Header.Del("Content-Encoding")
+			req.ContentLength = -1
+		}
+
+		if isUpgrade(req) {
+			delegate.ServeHTTP(w, req)
+			return
+		}
+		algorithm := Negotiate(req.Header.Get("Accept-Encoding"), settings.Algorithms)
+		if algorithm == Identity {
+			delegate.ServeHTTP(w, req)
+			return
+		}
+
+		req = req.Clone(req.Context())
+		req.Header.Del("Accept-Encoding")
+		cw := &compressingResponseWriter{
+			ResponseWriter: w,
+			algorithm:      algorithm,
+			level:          settings.Level,
+			connection:     connection,
+		}
+		defer func() {
+			if err := cw.Close(); err != nil {
+				klog.FromContext(req.Context()).V(4).Info("failed to finish compressed response", "err", err)
+			}
+		}()
+		delegate.ServeHTTP(cw, req)
+	})
+}
+
+type compressingResponseWriter struct {
+	http.ResponseWriter
+	algorithm  Algorithm
+	level      Level
+	connection string
+
+	wroteHeader bool
+	noBody      bool
+	wire        *countingWriter
+	encoder     writeFlushCloser
+	n           int64
+}
+
+var _ http.Flusher = &compressingResponseWriter{}
+
+func (w *compressingResponseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.noBody = code == http.StatusNoContent || code == http.StatusNotModified || code < http.StatusOK
+	if !w.noBody && w.Header().Get("Content-Encoding") == "" {
+		w.Header().Set("Content-Encoding", string(w.algorithm))
+		w.Header().Add("Vary", "Accept-Encoding")
+		w.Header().Del("Content-Length")
+	} else {
+		// the handler encoded the body itself, or there is none
+		w.noBody = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *compressingResponseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.noBody {
+		return w.ResponseWriter.Write(p)
+	}
+	if err := w.ensureEncoder(); err != nil {
+		return 0, err
+	}
+	n, err := w.encoder.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+func (w *compressingResponseWriter) ensureEncoder() error {
+	if w.encoder != nil {
+		return nil
+	}
+	w.wire = &countingWriter{delegate: w.ResponseWriter}
+	encoder, err := codecs[w.algorithm].NewWriter(w.wire, w.level)
+	if err != nil {
+		return fmt.Errorf("failed to create %s encoder: %w", w.algorithm, err)
+	}
+	w.encoder = encoder
+	return nil
+}
+
+func (w *compressingResponseWriter) Flush() {
+	if w.encoder != nil {
+		if err := w.encoder.Flush(); err != nil {
+			return
+		}
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close terminates the compressed stream. A response that set the encoding
+// header but never wrote a body still gets a valid, empty stream.
+func (w *compressingResponseWriter) Close() error {
+	if !w.wroteHeader || w.noBody {
+		return nil
+	}
+	if err := w.ensureEncoder(); err != nil {
+		return err
+	}
+	err := w.encoder.Close()
+	observe(w.connection, w.algorithm, directionSent, w.n, w.wire.n)
+	return err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *compressingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+type countingWriter struct {
+	delegate io.Writer
+	n        int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.delegate.Write(p)
+	w.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compression
+
+import (
+	"sync"
+
+	compbasemetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const (
+	directionSent     = "sent"
+	directionReceived = "received"
+)
+
+var (
+	uncompressedBytes = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Name:           "syncer_transport_uncompressed_bytes_total",
+			Help:           "Number of payload bytes before compression, by connection, algorithm and direction.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"connection", "algorithm", "direction"},
+	)
+	compressedBytes = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Name:           "syncer_transport_compressed_bytes_total",
+			Help:           "Number of payload bytes on the wire after compression, by connection, algorithm and direction.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"connection", "algorithm", "direction"},
+	)
+	compressionRatio = compbasemetrics.NewHistogramVec(
+		&compbasemetrics.HistogramOpts{
+			Name:           "syncer_transport_compression_ratio",
+			Help:           "Ratio of uncompressed to compressed size per payload, by connection and algorithm.",
+			Buckets:        []float64{1, 1.5, 2, 3, 4, 6, 8, 12, 16, 32},
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"connection", "algorithm"},
+	)
+)
+
+var registerMetrics sync.Once
+
+// Register metrics.
+func Register() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(uncompressedBytes)
+		legacyregistry.MustRegister(compressedBytes)
+		legacyregistry.MustRegister(compressionRatio)
+	})
+}
+
+func init() {
+	Register()
+}
+
+func observe(connection string, algorithm Algorithm, direction string, uncompressed, compressed int64) {
+	if compressed == 0 {
+		return
+	}
+	uncompressedBytes.WithLabelValues(connection, string(algorithm), direction).Add(float64(uncompressed))
+	compressedBytes.WithLabelValues(connection, string(algorithm), direction).Add(float64(compressed))
+	compressionRatio.WithLabelValues(connection, string(algorithm)).Observe(float64(uncompressed) / float64(compressed))
+}
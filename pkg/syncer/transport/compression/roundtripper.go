@@ -0,0 +1,174 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compression
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+)
+
+// NewRoundTripper returns a round tripper for the syncer side of the
+// connection. It advertises the configured algorithms, compresses request
+// bodies of at least Settings.MinSize bytes with the preferred algorithm and
+// transparently decompresses encoded responses.
+//
+// The connection name is used as metric label, and should identify the
+// SyncTarget the connection belongs to.
+func NewRoundTripper(delegate http.RoundTripper, connection string, settings Settings) http.RoundTripper {
+	return &roundTripper{
+		delegate:   delegate,
+		connection: connection,
+		settings:   settings,
+	}
+}
+
+type roundTripper struct {
+	delegate   http.RoundTripper
+	connection string
+	settings   Settings
+}
+
+var _ utilnet.RoundTripperWrapper = &roundTripper{}
+
+func (rt *roundTripper) WrappedRoundTripper() http.RoundTripper {
+	return rt.delegate
+}
+
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(rt.settings.Algorithms) == 0 || isUpgrade(req) {
+		return rt.delegate.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Accept-Encoding", rt.settings.AcceptEncoding())
+
+	if err := rt.compressBody(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := rt.delegate.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm := Algorithm(resp.Header.Get("Content-Encoding"))
+	if _, ok := codecs[algorithm]; !ok {
+		return resp, nil
+	}
+	counted := &countingReader{delegate: resp.Body}
+	body, err := NewReader(algorithm, counted)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	resp.Body = &observingReadCloser{
+		ReadCloser: body,
+		wire:       counted,
+		underlying: resp.Body,
+		done: func(uncompressed, compressed int64) {
+			observe(rt.connection, algorithm, directionReceived, uncompressed, compressed)
+		},
+	}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+func (rt *roundTripper) compressBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.Header.Get("Content-Encoding") != "" {
+		return nil
+	}
+	if req.ContentLength >= 0 && req.ContentLength < rt.settings.minSize() {
+		return nil
+	}
+	algorithm := rt.settings.preferred()
+	if algorithm == Identity {
+		return nil
+	}
+
+	defer req.Body.Close()
+	var buf bytes.Buffer
+	w, err := codecs[algorithm].NewWriter(&buf, rt.settings.Level)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(w, req.Body)
+	if err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	observe(rt.connection, algorithm, directionSent, n, int64(buf.Len()))
+
+	compressed := buf.Bytes()
+	req.Body = io.NopCloser(bytes.NewReader(compressed))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(compressed)), nil
+	}
+	req.ContentLength = int64(len(compressed))
+	req.Header.Set("Content-Encoding", string(algorithm))
+	return nil
+}
+
+func isUpgrade(req *http.Request) bool {
+	return req.Header.Get("Upgrade") != ""
+}
+
+type countingReader struct {
+	delegate io.Reader
+	n        int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.delegate.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// observingReadCloser counts decompressed bytes and records metrics once on
+// Close.
+type observingReadCloser struct {
+	io.ReadCloser
+	wire       *countingReader
+	underlying io.Closer
+	done       func(uncompressed, compressed int64)
+
+	n    int64
+	once sync.Once
+}
+
+func (r *observingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+func (r *observingReadCloser) Close() error {
+	r.once.Do(func() { r.done(r.n, r.wire.n) })
+	err := r.ReadCloser.Close()
+	if uerr := r.underlying.Close(); err == nil {
+		err = uerr
+	}
+	return err
+}
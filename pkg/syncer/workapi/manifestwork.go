@@ -0,0 +1,192 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package workapi translates the desired state of a SyncTarget into
+// ManifestWork bundles compatible with the Open Cluster Management work API,
+// so fleets can mix clusters running the TMC syncer with clusters running an
+// OCM work agent. The same Bundle produced from a placement decision feeds
+// either delivery mode.
+package workapi
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/naming"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+const (
+	// LabelSyncTarget is set on ManifestWorks to the SyncTarget they are rendered for.
+	LabelSyncTarget = "tmc.kcp.io/sync-target"
+	// LabelWorkload is set on ManifestWorks to the workload they bundle.
+	LabelWorkload = "tmc.kcp.io/workload"
+	// AnnotationWorkspace records the logical cluster the workload comes from.
+	AnnotationWorkspace = "tmc.kcp.io/workspace"
+)
+
+// ManifestWorksGVR is the OCM ManifestWork resource.
+var ManifestWorksGVR = schema.GroupVersionResource{Group: "work.open-cluster-management.io", Version: "v1", Resource: "manifestworks"}
+
+// Bundle is the desired state of one workload on one SyncTarget, as decided
+// by placement.
+type Bundle struct {
+	// Workspace is the logical cluster the workload lives in.
+	Workspace logicalcluster.Name
+	// Workload names the placed workload.
+	Workload string
+	// SyncTarget is the name of the target the bundle is destined for.
+	SyncTarget string
+	// Objects are the manifests to apply, already transformed for the target.
+	Objects []*unstructured.Unstructured
+}
+
+// UsesManifestWork returns true if workloads for the SyncTarget must be
+// delivered as ManifestWorks rather than through the syncer.
+func UsesManifestWork(syncTarget *tmcv1alpha1.SyncTarget) bool {
+	return syncTarget.Spec.DeliveryMode == tmcv1alpha1.DeliveryModeManifestWork
+}
+
+// ToManifestWork renders the bundle as a ManifestWork in the given cluster
+// namespace, the namespace the OCM hub reserves for the managed cluster.
+// Manifests are stripped of server-populated fields and ordered so that
+// Namespaces and CRDs are applied before the objects depending on them.
+func ToManifestWork(bundle Bundle, clusterNamespace string) (*unstructured.Unstructured, error) {
+	if bundle.Workload == "" || bundle.SyncTarget == "" {
+		return nil, fmt.Errorf("bundle needs a workload and a SyncTarget name")
+	}
+
+	objs := make([]*unstructured.Unstructured, 0, len(bundle.Objects))
+	for _, obj := range bundle.Objects {
+		objs = append(objs, sanitize(obj))
+	}
+	sort.SliceStable(objs, func(i, j int) bool {
+		return applyOrder(objs[i]) < applyOrder(objs[j])
+	})
+
+	manifests := make([]interface{}, 0, len(objs))
+	configs := make([]interface{}, 0, len(objs))
+	for _, obj := range objs {
+		manifests = append(manifests, obj.Object)
+
+		gvk := obj.GroupVersionKind()
+		configs = append(configs, map[string]interface{}{
+			"resourceIdentifier": map[string]interface{}{
+				"group":     gvk.Group,
+				"resource":  resourceFor(gvk.Kind),
+				"namespace": obj.GetNamespace(),
+				"name":      obj.GetName(),
+			},
+			"feedbackRules": []interface{}{
+				map[string]interface{}{"type": "WellKnownStatus"},
+			},
+		})
+	}
+
+	work := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"workload": map[string]interface{}{
+				"manifests": manifests,
+			},
+			"deleteOption": map[string]interface{}{
+				"propagationPolicy": "Foreground",
+			},
+			"manifestConfigs": configs,
+		},
+	}}
+	work.SetAPIVersion(ManifestWorksGVR.GroupVersion().String())
+	work.SetKind("ManifestWork")
+	work.SetNamespace(clusterNamespace)
+	work.SetName(WorkName(bundle.Workspace, bundle.Workload))
+	work.SetLabels(map[string]string{
+		LabelSyncTarget: bundle.SyncTarget,
+		LabelWorkload:   truncateLabelValue(bundle.Workload),
+	})
+	work.SetAnnotations(map[string]string{
+		AnnotationWorkspace: bundle.Workspace.String(),
+	})
+	return work, nil
+}
+
+// WorkName returns a stable ManifestWork name for a workload in a workspace.
+// Workloads of different workspaces can land in the same cluster namespace,
+// so the name carries a short hash of the workspace.
+func WorkName(workspace logicalcluster.Name, workload string) string {
+	return naming.Prefixed(workload, validation.DNS1123SubdomainMaxLength, workspace.String())
+}
+
+// serverFields are metadata fields the hub must not carry into manifests.
+var serverFields = [][]string{
+	{"metadata", "resourceVersion"},
+	{"metadata", "uid"},
+	{"metadata", "generation"},
+	{"metadata", "creationTimestamp"},
+	{"metadata", "deletionTimestamp"},
+	{"metadata", "deletionGracePeriodSeconds"},
+	{"metadata", "managedFields"},
+	{"metadata", "ownerReferences"},
+	{"metadata", "finalizers"},
+	{"metadata", "selfLink"},
+	{"status"},
+}
+
+func sanitize(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	obj = obj.DeepCopy()
+	for _, path := range serverFields {
+		unstructured.RemoveNestedField(obj.Object, path...)
+	}
+	if annotations := obj.GetAnnotations(); annotations != nil {
+		delete(annotations, logicalcluster.AnnotationKey)
+		if len(annotations) == 0 {
+			annotations = nil
+		}
+		obj.SetAnnotations(annotations)
+	}
+	return obj
+}
+
+func applyOrder(obj *unstructured.Unstructured) int {
+	switch obj.GroupVersionKind().GroupKind() {
+	case schema.GroupKind{Kind: "Namespace"}:
+		return 0
+	case schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}:
+		return 1
+	case schema.GroupKind{Kind: "ServiceAccount"}, schema.GroupKind{Kind: "Secret"}, schema.GroupKind{Kind: "ConfigMap"}:
+		return 2
+	}
+	return 3
+}
+
+// resourceFor guesses the plural resource name of a kind, following the
+// same rules as the REST mapper's UnsafeGuessKindToResource.
+func resourceFor(kind string) string {
+	plural, _ := meta.UnsafeGuessKindToResource(schema.GroupVersionKind{Kind: kind})
+	return plural.Resource
+}
+
+func truncateLabelValue(v string) string {
+	if len(v) > validation.LabelValueMaxLength {
+		return v[:validation.LabelValueMaxLength]
+	}
+	return v
+}
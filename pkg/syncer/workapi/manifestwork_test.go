@@ -0,0 +1,110 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+)
+
+func TestToManifestWork(t *testing.T) {
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":            "web",
+			"namespace":       "shop",
+			"resourceVersion": "42",
+			"uid":             "abc",
+			"annotations":     map[string]interface{}{logicalcluster.AnnotationKey: "root:shop"},
+		},
+		"spec":   map[string]interface{}{"replicas": int64(2)},
+		"status": map[string]interface{}{"readyReplicas": int64(2)},
+	}}
+	namespace := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata":   map[string]interface{}{"name": "shop"},
+	}}
+
+	work, err := ToManifestWork(Bundle{
+		Workspace:  "root:shop",
+		Workload:   "web",
+		SyncTarget: "edge-1",
+		Objects:    []*unstructured.Unstructured{deployment, namespace},
+	}, "edge-1")
+	require.NoError(t, err)
+
+	require.Equal(t, "edge-1", work.GetNamespace())
+	require.Equal(t, WorkName("root:shop", "web"), work.GetName())
+	require.Equal(t, "edge-1", work.GetLabels()[LabelSyncTarget])
+
+	manifests, _, err := unstructured.NestedSlice(work.Object, "spec", "workload", "manifests")
+	require.NoError(t, err)
+	require.Len(t, manifests, 2)
+	require.Equal(t, "Namespace", manifests[0].(map[string]interface{})["kind"], "namespaces must be applied first")
+
+	applied := &unstructured.Unstructured{Object: manifests[1].(map[string]interface{})}
+	require.Empty(t, applied.GetResourceVersion())
+	require.Empty(t, applied.GetUID())
+	require.Empty(t, applied.GetAnnotations())
+	_, found, _ := unstructured.NestedMap(applied.Object, "status")
+	require.False(t, found)
+
+	configs, _, _ := unstructured.NestedSlice(work.Object, "spec", "manifestConfigs")
+	resource, _, _ := unstructured.NestedString(configs[1].(map[string]interface{}), "resourceIdentifier", "resource")
+	require.Equal(t, "deployments", resource)
+
+	// the input must not be mutated
+	require.Equal(t, "42", deployment.GetResourceVersion())
+}
+
+func TestWorkName(t *testing.T) {
+	require.NotEqual(t, WorkName("root:a", "web"), WorkName("root:b", "web"))
+	require.Equal(t, WorkName("root:a", "web"), WorkName("root:a", "web"))
+	require.LessOrEqual(t, len(WorkName("root:a", strings.Repeat("x", 300))), 253)
+}
+
+func TestParseStatus(t *testing.T) {
+	work := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"resourceStatus": map[string]interface{}{
+				"manifests": []interface{}{
+					map[string]interface{}{
+						"resourceMeta": map[string]interface{}{"group": "apps", "resource": "deployments", "namespace": "shop", "name": "web"},
+						"conditions": []interface{}{
+							map[string]interface{}{"type": "Applied", "status": "False", "reason": "AppliedManifestFailed", "message": "denied by webhook", "lastTransitionTime": "2025-01-01T00:00:00Z"},
+						},
+					},
+				},
+			},
+		},
+	}}
+
+	statuses, err := ParseStatus(work)
+	require.NoError(t, err)
+	require.Equal(t, []ResourceStatus{{
+		Group: "apps", Resource: "deployments", Namespace: "shop", Name: "web",
+		Message: "denied by webhook",
+	}}, statuses)
+}
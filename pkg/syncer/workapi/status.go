@@ -0,0 +1,121 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workapi
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/util/conditions"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// ResourceStatus is the state of one manifest as reported by the work agent.
+type ResourceStatus struct {
+	Group     string
+	Resource  string
+	Namespace string
+	Name      string
+
+	// Applied is true once the agent applied the manifest.
+	Applied bool
+	// Available is true once the resource exists on the managed cluster.
+	Available bool
+	// Message carries the agent's explanation when the manifest is not applied.
+	Message string
+}
+
+type workStatus struct {
+	Conditions     []metav1.Condition `json:"conditions,omitempty"`
+	ResourceStatus struct {
+		Manifests []struct {
+			ResourceMeta struct {
+				Group     string `json:"group"`
+				Resource  string `json:"resource"`
+				Namespace string `json:"namespace"`
+				Name      string `json:"name"`
+			} `json:"resourceMeta"`
+			Conditions []metav1.Condition `json:"conditions,omitempty"`
+		} `json:"manifests,omitempty"`
+	} `json:"resourceStatus"`
+}
+
+// ParseStatus extracts the per-manifest state from a ManifestWork, so the
+// upstream status path can treat ManifestWork targets like syncer targets.
+func ParseStatus(work *unstructured.Unstructured) ([]ResourceStatus, error) {
+	raw, found, err := unstructured.NestedMap(work.Object, "status")
+	if err != nil || !found {
+		return nil, err
+	}
+	var status workStatus
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &status); err != nil {
+		return nil, fmt.Errorf("failed to decode ManifestWork status: %w", err)
+	}
+
+	result := make([]ResourceStatus, 0, len(status.ResourceStatus.Manifests))
+	for _, m := range status.ResourceStatus.Manifests {
+		rs := ResourceStatus{
+			Group:     m.ResourceMeta.Group,
+			Resource:  m.ResourceMeta.Resource,
+			Namespace: m.ResourceMeta.Namespace,
+			Name:      m.ResourceMeta.Name,
+			Applied:   meta.IsStatusConditionTrue(m.Conditions, "Applied"),
+			Available: meta.IsStatusConditionTrue(m.Conditions, "Available"),
+		}
+		if c := meta.FindStatusCondition(m.Conditions, "Applied"); c != nil && c.Status != metav1.ConditionTrue {
+			rs.Message = c.Message
+		}
+		result = append(result, rs)
+	}
+	return result, nil
+}
+
+// maxListed bounds the manifests listed in the ManifestWorksApplied
+// condition.
+const maxListed = 5
+
+// SetCondition sets the ManifestWorksApplied condition of target from the
+// state of the manifests of its ManifestWorks.
+func SetCondition(target *tmcv1alpha1.SyncTarget, statuses []ResourceStatus) {
+	var pending []string
+	for _, s := range statuses {
+		if s.Applied {
+			continue
+		}
+		entry := fmt.Sprintf("%s %s/%s", s.Resource, s.Namespace, s.Name)
+		if s.Message != "" {
+			entry += ": " + s.Message
+		}
+		pending = append(pending, entry)
+	}
+	if len(pending) == 0 {
+		conditions.MarkTrue(target, tmcv1alpha1.ManifestWorksApplied)
+		return
+	}
+	message := strings.Join(pending[:min(len(pending), maxListed)], "; ")
+	if len(pending) > maxListed {
+		message += fmt.Sprintf(" and %d more", len(pending)-maxListed)
+	}
+	conditions.MarkFalse(target, tmcv1alpha1.ManifestWorksApplied, tmcv1alpha1.ManifestsNotAppliedReason, conditionsv1alpha1.ConditionSeverityWarning,
+		"The work agent did not apply %d manifests: %s", len(pending), message)
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package credentials resolves the SyncTargetCredentials the TMC controllers
+// authenticate to the webhooks of SyncTargets with.
+package credentials
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+var secretsGVR = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+
+// Credentials are resolved SyncTargetCredentials.
+type Credentials struct {
+	// Token is sent as bearer token, if not empty.
+	Token string
+	// Certificate is presented as client certificate, if not nil.
+	Certificate *tls.Certificate
+	// certificateKey identifies the certificate and key, see ClientKey.
+	certificateKey string
+}
+
+// BearerToken returns the token of the credentials, if any.
+func (c *Credentials) BearerToken() string {
+	if c == nil {
+		return ""
+	}
+	return c.Token
+}
+
+// ClientKey identifies the client certificate of the credentials, so that
+// HTTP clients can be reused per certificate. The token is sent per request.
+func (c *Credentials) ClientKey() string {
+	if c == nil {
+		return ""
+	}
+	return c.certificateKey
+}
+
+// SecretGetter returns a Secret of a workspace.
+type SecretGetter func(ctx context.Context, clusterName logicalcluster.Name, namespace, name string) (*corev1.Secret, error)
+
+// DynamicSecretGetter returns a SecretGetter reading Secrets live with
+// client, so that credentials are not cached by the controllers.
+func DynamicSecretGetter(client kcpdynamic.ClusterInterface) SecretGetter {
+	return func(ctx context.Context, clusterName logicalcluster.Name, namespace, name string) (*corev1.Secret, error) {
+		u, err := client.Cluster(clusterName.Path()).Resource(secretsGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		secret := &corev1.Secret{}
+		return secret, runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, secret)
+	}
+}
+
+// ResolveFunc resolves the credentials of a SyncTarget in a workspace.
+type ResolveFunc func(ctx context.Context, clusterName logicalcluster.Name, syncTarget string, credentials *tmcv1alpha1.SyncTargetCredentials) (*Credentials, error)
+
+// NewResolver returns a ResolveFunc reading referenced Secrets with
+// getSecret. The Secrets must be labeled with LabelCredentialsFor set to the
+// SyncTarget: the TMC controllers read them with their own permissions, and
+// must not send Secrets the owners of the workspace did not mean for the
+// webhooks of the SyncTarget.
+func NewResolver(getSecret SecretGetter) ResolveFunc {
+	return func(ctx context.Context, clusterName logicalcluster.Name, syncTarget string, credentials *tmcv1alpha1.SyncTargetCredentials) (*Credentials, error) {
+		if credentials == nil {
+			return nil, nil
+		}
+		get := func(ref tmcv1alpha1.SecretReference) (*corev1.Secret, error) {
+			secret, err := getSecret(ctx, clusterName, ref.Namespace, ref.Name)
+			if err != nil {
+				return nil, err
+			}
+			if secret.Labels[tmcv1alpha1.LabelCredentialsFor] != syncTarget {
+				return nil, fmt.Errorf("not labeled %s=%s", tmcv1alpha1.LabelCredentialsFor, syncTarget)
+			}
+			return secret, nil
+		}
+
+		resolved := &Credentials{}
+		if ref := credentials.TokenSecretRef; ref != nil {
+			secret, err := get(ref.SecretReference)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get token secret %s/%s: %w", ref.Namespace, ref.Name, err)
+			}
+			token, found := secret.Data[ref.Key]
+			if !found {
+				return nil, fmt.Errorf("token secret %s/%s has no key %q", ref.Namespace, ref.Name, ref.Key)
+			}
+			resolved.Token = string(token)
+		}
+
+		if ref := credentials.ClientCertificateSecretRef; ref != nil {
+			secret, err := get(*ref)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get client certificate secret %s/%s: %w", ref.Namespace, ref.Name, err)
+			}
+			certificate, key := secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]
+			if len(certificate) == 0 || len(key) == 0 {
+				return nil, fmt.Errorf("client certificate secret %s/%s needs the keys %s and %s", ref.Namespace, ref.Name, corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+			}
+			pair, err := tls.X509KeyPair(certificate, key)
+			if err != nil {
+				return nil, fmt.Errorf("invalid client certificate in secret %s/%s: %w", ref.Namespace, ref.Name, err)
+			}
+			resolved.Certificate = &pair
+			resolved.certificateKey = string(certificate) + "\x00" + string(key)
+		}
+		return resolved, nil
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/utils/ptr"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func TestResolve(t *testing.T) {
+	certificate, key, err := certutil.GenerateSelfSignedCertKey("webhook-client", nil, nil)
+	require.NoError(t, err)
+
+	labels := map[string]string{tmcv1alpha1.LabelCredentialsFor: "east"}
+	secrets := map[string]*corev1.Secret{
+		"root:org/default/token":     {ObjectMeta: metav1.ObjectMeta{Labels: labels}, Data: map[string][]byte{"token": []byte("from-secret")}},
+		"root:org/default/tls":       {ObjectMeta: metav1.ObjectMeta{Labels: labels}, Data: map[string][]byte{corev1.TLSCertKey: certificate, corev1.TLSPrivateKeyKey: key}},
+		"root:org/default/empty":     {ObjectMeta: metav1.ObjectMeta{Labels: labels}},
+		"root:org/default/invalid":   {ObjectMeta: metav1.ObjectMeta{Labels: labels}, Data: map[string][]byte{corev1.TLSCertKey: certificate, corev1.TLSPrivateKeyKey: []byte("not a key")}},
+		"root:org/default/unlabeled": {Data: map[string][]byte{"token": []byte("from-secret")}},
+		"root:org/default/west":      {ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{tmcv1alpha1.LabelCredentialsFor: "west"}}, Data: map[string][]byte{"token": []byte("from-secret")}},
+	}
+	resolve := NewResolver(func(_ context.Context, clusterName logicalcluster.Name, namespace, name string) (*corev1.Secret, error) {
+		secret, found := secrets[clusterName.String()+"/"+namespace+"/"+name]
+		if !found {
+			return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, name)
+		}
+		return secret, nil
+	})
+	ref := func(name string) tmcv1alpha1.SecretReference {
+		return tmcv1alpha1.SecretReference{Namespace: "default", Name: name}
+	}
+	tokenRef := func(name, key string) *tmcv1alpha1.SecretKeyReference {
+		return &tmcv1alpha1.SecretKeyReference{SecretReference: ref(name), Key: key}
+	}
+
+	tests := map[string]struct {
+		credentials *tmcv1alpha1.SyncTargetCredentials
+		wantToken   string
+		wantCert    bool
+		wantErr     string
+	}{
+		"no credentials": {},
+		"referenced token": {
+			credentials: &tmcv1alpha1.SyncTargetCredentials{TokenSecretRef: tokenRef("token", "token")},
+			wantToken:   "from-secret",
+		},
+		"unlabeled token secret": {
+			credentials: &tmcv1alpha1.SyncTargetCredentials{TokenSecretRef: tokenRef("unlabeled", "token")},
+			wantErr:     "failed to get token secret default/unlabeled: not labeled tmc.kcp.io/credentials-for=east",
+		},
+		"token secret of another SyncTarget": {
+			credentials: &tmcv1alpha1.SyncTargetCredentials{TokenSecretRef: tokenRef("west", "token")},
+			wantErr:     "failed to get token secret default/west: not labeled tmc.kcp.io/credentials-for=east",
+		},
+		"missing token key": {
+			credentials: &tmcv1alpha1.SyncTargetCredentials{TokenSecretRef: tokenRef("token", "password")},
+			wantErr:     `token secret default/token has no key "password"`,
+		},
+		"missing token secret": {
+			credentials: &tmcv1alpha1.SyncTargetCredentials{TokenSecretRef: tokenRef("missing", "token")},
+			wantErr:     `failed to get token secret default/missing: secrets "missing" not found`,
+		},
+		"referenced client certificate": {
+			credentials: &tmcv1alpha1.SyncTargetCredentials{ClientCertificateSecretRef: ptr.To(ref("tls"))},
+			wantCert:    true,
+		},
+		"client certificate secret without key": {
+			credentials: &tmcv1alpha1.SyncTargetCredentials{ClientCertificateSecretRef: ptr.To(ref("empty"))},
+			wantErr:     "client certificate secret default/empty needs the keys tls.crt and tls.key",
+		},
+		"invalid client certificate": {
+			credentials: &tmcv1alpha1.SyncTargetCredentials{ClientCertificateSecretRef: ptr.To(ref("invalid"))},
+			wantErr:     "invalid client certificate in secret default/invalid: tls: failed to find any PEM data in key input",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := resolve(context.Background(), logicalcluster.Name("root:org"), "east", tt.credentials)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantToken, got.BearerToken())
+			require.Equal(t, tt.wantCert, got != nil && got.Certificate != nil)
+			require.Equal(t, tt.wantCert, got.ClientKey() != "")
+		})
+	}
+}
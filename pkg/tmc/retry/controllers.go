@@ -0,0 +1,51 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+)
+
+var (
+	controllersLock sync.RWMutex
+	// controllers is how the workqueues of the TMC controllers back off.
+	// It defaults to the backoff of the default controller rate limiter.
+	controllers = Strategy{
+		InitialBackoff: 5 * time.Millisecond,
+		MaxBackoff:     1000 * time.Second,
+		Factor:         2,
+	}
+)
+
+// SetControllers sets how the workqueues of the TMC controllers created
+// afterwards back off, e.g. from the configuration of the process.
+func SetControllers(s Strategy) {
+	controllersLock.Lock()
+	defer controllersLock.Unlock()
+	controllers = s
+}
+
+// ControllerRateLimiter returns the rate limiter of the workqueue of a TMC
+// controller.
+func ControllerRateLimiter[T comparable]() workqueue.TypedRateLimiter[T] {
+	controllersLock.RLock()
+	defer controllersLock.RUnlock()
+	return RateLimiter[T](controllers)
+}
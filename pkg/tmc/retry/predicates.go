@@ -0,0 +1,74 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+)
+
+// Predicate decides whether an error is retried.
+type Predicate func(err error) bool
+
+// Conflict retries conflicts only.
+func Conflict(err error) bool {
+	return apierrors.IsConflict(err)
+}
+
+// Overloaded retries errors of a server that is overloaded or timed out.
+func Overloaded(err error) bool {
+	return apierrors.IsTooManyRequests(err) || apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err)
+}
+
+// Transient retries conflicts and errors of an overloaded server, which
+// are expected to go away by themselves.
+func Transient(err error) bool {
+	return Conflict(err) || Overloaded(err)
+}
+
+// Unreachable retries errors of a server that could not be reached or
+// closed the connection, and that was unavailable.
+func Unreachable(err error) bool {
+	return apierrors.IsServiceUnavailable(err) || apierrors.IsInternalError(err) ||
+		utilnet.IsConnectionRefused(err) || utilnet.IsConnectionReset(err) || utilnet.IsProbableEOF(err)
+}
+
+// Permanent returns whether retrying the same request cannot succeed,
+// e.g. because the object was deleted or the request is invalid.
+func Permanent(err error) bool {
+	return apierrors.IsNotFound(err) || apierrors.IsGone(err) || apierrors.IsInvalid(err) || apierrors.IsBadRequest(err) ||
+		apierrors.IsMethodNotSupported(err) || apierrors.IsUnsupportedMediaType(err) || apierrors.IsRequestEntityTooLargeError(err)
+}
+
+// Any retries errors any of predicates retries.
+func Any(predicates ...Predicate) Predicate {
+	return func(err error) bool {
+		for _, p := range predicates {
+			if p(err) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not retries errors p does not retry.
+func Not(p Predicate) Predicate {
+	return func(err error) bool {
+		return !p(err)
+	}
+}
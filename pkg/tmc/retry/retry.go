@@ -0,0 +1,209 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package retry configures how TMC components retry failed operations, so
+// that the status writer, the workqueues of the TMC controllers and the
+// syncer back off alike. A Strategy is chosen by a named Profile, fast,
+// standard or conservative, and can be overridden field by field through a
+// Config, which is serializable to be part of a component configuration.
+//
+// Whether an error is retried is decided by predicates over the error
+// taxonomy of the API machinery: Transient errors, e.g. conflicts or an
+// overloaded server, are retried, Permanent errors, e.g. a deleted object or
+// an invalid request, are not.
+package retry
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// Profile names a Strategy.
+type Profile string
+
+const (
+	// ProfileFast retries quickly and gives up soon, for operations that
+	// are cheap and whose callers retry anyway.
+	ProfileFast Profile = "fast"
+	// ProfileStandard is the default.
+	ProfileStandard Profile = "standard"
+	// ProfileConservative backs off long, for operations against servers
+	// that are likely overloaded.
+	ProfileConservative Profile = "conservative"
+)
+
+// Strategy is how an operation is retried.
+type Strategy struct {
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff bounds the delay before a retry, before jitter. Zero
+	// means unbounded.
+	MaxBackoff time.Duration
+	// Factor multiplies the delay with every retry.
+	Factor float64
+	// Jitter is the maximum factor by which delays are extended, so that
+	// clients that failed at the same time do not retry in lockstep.
+	Jitter float64
+	// MaxRetries is the number of retries before giving up.
+	MaxRetries int
+}
+
+var profiles = map[Profile]Strategy{
+	ProfileFast: {
+		InitialBackoff: 5 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Factor:         2,
+		Jitter:         0.1,
+		MaxRetries:     3,
+	},
+	ProfileStandard: {
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Minute,
+		Factor:         2,
+		Jitter:         1.0,
+		MaxRetries:     5,
+	},
+	ProfileConservative: {
+		InitialBackoff: time.Second,
+		MaxBackoff:     5 * time.Minute,
+		Factor:         2,
+		Jitter:         1.0,
+		MaxRetries:     10,
+	},
+}
+
+// ForProfile returns the Strategy of profile. The empty profile is
+// ProfileStandard.
+func ForProfile(profile Profile) (Strategy, error) {
+	if profile == "" {
+		profile = ProfileStandard
+	}
+	s, found := profiles[profile]
+	if !found {
+		return Strategy{}, fmt.Errorf("unknown retry profile %q, must be one of %s, %s or %s", profile, ProfileFast, ProfileStandard, ProfileConservative)
+	}
+	return s, nil
+}
+
+// Standard returns the Strategy of ProfileStandard.
+func Standard() Strategy {
+	return profiles[ProfileStandard]
+}
+
+// Backoff returns the delay before retry number retry, counting from 1,
+// before jitter.
+func (s Strategy) Backoff(retry int) time.Duration {
+	delay := float64(s.InitialBackoff)
+	factor := max(s.Factor, 1)
+	for i := 1; i < retry && (s.MaxBackoff <= 0 || delay < float64(s.MaxBackoff)); i++ {
+		delay *= factor
+	}
+	if s.MaxBackoff > 0 {
+		delay = math.Min(delay, float64(s.MaxBackoff))
+	}
+	return time.Duration(delay)
+}
+
+// Delay returns the jittered delay before retry number retry, counting
+// from 1.
+func (s Strategy) Delay(retry int) time.Duration {
+	if s.Jitter <= 0 {
+		return s.Backoff(retry)
+	}
+	return wait.Jitter(s.Backoff(retry), s.Jitter)
+}
+
+// Do calls fn until it succeeds, returns an error retriable rejects, or
+// MaxRetries retries failed. It stops waiting for a retry when ctx is done,
+// returning the last error.
+func (s Strategy) Do(ctx context.Context, retriable Predicate, fn func(ctx context.Context) error) error {
+	for retry := 1; ; retry++ {
+		err := fn(ctx)
+		if err == nil || !retriable(err) || retry > s.MaxRetries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(s.Delay(retry)):
+		}
+	}
+}
+
+// RateLimiter returns a workqueue rate limiter backing off items by s,
+// and all items together like the default controller rate limiter, with 10
+// qps and a burst of 100. Retries of workqueues are not bounded, so
+// MaxRetries and Jitter do not apply.
+func RateLimiter[T comparable](s Strategy) workqueue.TypedRateLimiter[T] {
+	return workqueue.NewTypedMaxOfRateLimiter(
+		workqueue.NewTypedItemExponentialFailureRateLimiter[T](s.InitialBackoff, s.MaxBackoff),
+		&workqueue.TypedBucketRateLimiter[T]{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+	)
+}
+
+// Config configures a Strategy by a profile and overrides of its fields.
+// Unset fields keep the values of the profile.
+type Config struct {
+	// Profile is fast, standard or conservative. Defaults to standard.
+	Profile Profile `json:"profile,omitempty"`
+	// InitialBackoff overrides the delay before the first retry.
+	InitialBackoff *metav1.Duration `json:"initialBackoff,omitempty"`
+	// MaxBackoff overrides the maximum delay before a retry.
+	MaxBackoff *metav1.Duration `json:"maxBackoff,omitempty"`
+	// Jitter overrides the maximum factor delays are extended by.
+	Jitter *float64 `json:"jitter,omitempty"`
+	// MaxRetries overrides the number of retries.
+	MaxRetries *int `json:"maxRetries,omitempty"`
+}
+
+// Strategy returns the configured Strategy.
+func (c Config) Strategy() (Strategy, error) {
+	s, err := ForProfile(c.Profile)
+	if err != nil {
+		return Strategy{}, err
+	}
+	if c.InitialBackoff != nil {
+		s.InitialBackoff = c.InitialBackoff.Duration
+	}
+	if c.MaxBackoff != nil {
+		s.MaxBackoff = c.MaxBackoff.Duration
+	}
+	if c.Jitter != nil {
+		s.Jitter = *c.Jitter
+	}
+	if c.MaxRetries != nil {
+		s.MaxRetries = *c.MaxRetries
+	}
+	switch {
+	case s.InitialBackoff <= 0:
+		return Strategy{}, fmt.Errorf("initialBackoff must be positive, got %s", s.InitialBackoff)
+	case s.MaxBackoff < s.InitialBackoff:
+		return Strategy{}, fmt.Errorf("maxBackoff %s must not be less than initialBackoff %s", s.MaxBackoff, s.InitialBackoff)
+	case s.Jitter < 0:
+		return Strategy{}, fmt.Errorf("jitter must not be negative, got %v", s.Jitter)
+	case s.MaxRetries < 0:
+		return Strategy{}, fmt.Errorf("maxRetries must not be negative, got %d", s.MaxRetries)
+	}
+	return s, nil
+}
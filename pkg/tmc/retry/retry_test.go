@@ -0,0 +1,127 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+)
+
+var deployments = schema.GroupResource{Group: "apps", Resource: "deployments"}
+
+func TestBackoff(t *testing.T) {
+	s := Strategy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Factor: 2}
+	var delays []time.Duration
+	for retry := 1; retry <= 5; retry++ {
+		delays = append(delays, s.Backoff(retry))
+	}
+	require.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}, delays)
+
+	s.Jitter = 0.5
+	for range 100 {
+		delay := s.Delay(2)
+		require.GreaterOrEqual(t, delay, 2*time.Second)
+		require.LessOrEqual(t, delay, 3*time.Second)
+	}
+}
+
+func TestDo(t *testing.T) {
+	conflict := apierrors.NewConflict(deployments, "web", errors.New("changed"))
+	forbidden := apierrors.NewForbidden(deployments, "web", errors.New("denied"))
+	s := Strategy{InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, MaxRetries: 2}
+
+	tests := map[string]struct {
+		errs      []error
+		wantCalls int
+		wantErr   error
+	}{
+		"success":                  {wantCalls: 1},
+		"retried until success":    {errs: []error{conflict, apierrors.NewTooManyRequests("slow down", 1)}, wantCalls: 3},
+		"retried up to MaxRetries": {errs: []error{conflict, conflict, conflict, conflict}, wantCalls: 3, wantErr: conflict},
+		"not retriable":            {errs: []error{forbidden}, wantCalls: 1, wantErr: forbidden},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			calls := 0
+			err := s.Do(context.Background(), Transient, func(context.Context) error {
+				calls++
+				if calls <= len(tc.errs) {
+					return tc.errs[calls-1]
+				}
+				return nil
+			})
+			require.Equal(t, tc.wantErr, err)
+			require.Equal(t, tc.wantCalls, calls)
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls := 0
+	err := Strategy{InitialBackoff: time.Hour, MaxBackoff: time.Hour, MaxRetries: 5}.Do(ctx, Transient, func(context.Context) error {
+		calls++
+		return conflict
+	})
+	require.Equal(t, conflict, err)
+	require.Equal(t, 1, calls, "retries stop when the context is done")
+}
+
+func TestPredicates(t *testing.T) {
+	conflict := apierrors.NewConflict(deployments, "web", errors.New("changed"))
+	reset := fmt.Errorf("read tcp: %w", syscall.ECONNRESET)
+	notFound := apierrors.NewNotFound(deployments, "web")
+
+	require.True(t, Transient(conflict))
+	require.True(t, Transient(apierrors.NewServerTimeout(deployments, "update", 1)))
+	require.False(t, Transient(reset))
+	require.True(t, Unreachable(reset))
+	require.True(t, Unreachable(apierrors.NewServiceUnavailable("unavailable")))
+	require.True(t, Permanent(notFound))
+	require.False(t, Permanent(conflict))
+	require.True(t, Any(Transient, Unreachable)(reset))
+	require.True(t, Not(Permanent)(conflict))
+}
+
+func TestConfig(t *testing.T) {
+	s, err := Config{}.Strategy()
+	require.NoError(t, err)
+	require.Equal(t, Standard(), s)
+
+	s, err = Config{
+		Profile:    ProfileConservative,
+		MaxBackoff: &metav1.Duration{Duration: time.Minute},
+		MaxRetries: ptr.To(3),
+	}.Strategy()
+	require.NoError(t, err)
+	require.Equal(t, Strategy{InitialBackoff: time.Second, MaxBackoff: time.Minute, Factor: 2, Jitter: 1.0, MaxRetries: 3}, s)
+
+	_, err = Config{Profile: "eager"}.Strategy()
+	require.Error(t, err)
+	_, err = Config{Profile: ProfileFast, MaxBackoff: &metav1.Duration{Duration: time.Millisecond}}.Strategy()
+	require.Error(t, err, "the max backoff is below the initial backoff of the profile")
+}
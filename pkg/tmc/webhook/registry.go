@@ -0,0 +1,184 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhook serves the conversion and defaulting webhooks of the TMC
+// API groups, so that new versions of their kinds can be served next to
+// the versions existing clients use.
+//
+// Kinds are registered with a hub version, usually their storage version,
+// and spoke versions converting from and to the hub. Objects are converted
+// between any two versions through the hub, and defaulted in the hub
+// version. Converting funcs work on the content of unstructured objects, so
+// that old versions need not be kept as Go types.
+package webhook
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ConvertFunc converts the content of an object in place. The apiVersion
+// is set by the caller.
+type ConvertFunc func(obj map[string]interface{}) error
+
+// DefaultFunc sets defaults on the content of an object of the hub version
+// in place.
+type DefaultFunc func(obj map[string]interface{}) error
+
+// Spoke converts a version of a kind from and to the hub version.
+type Spoke struct {
+	ToHub   ConvertFunc
+	FromHub ConvertFunc
+}
+
+// Renaming returns a spoke whose version differs from the hub only in the
+// names of fields of the object at path: fromHub maps their names in the
+// hub version to their names in the spoke version.
+func Renaming(path []string, fromHub map[string]string) Spoke {
+	toHub := make(map[string]string, len(fromHub))
+	for hubName, spokeName := range fromHub {
+		toHub[spokeName] = hubName
+	}
+	return Spoke{
+		ToHub:   renameFields(path, toHub),
+		FromHub: renameFields(path, fromHub),
+	}
+}
+
+// renameFields returns a ConvertFunc moving the fields of the object at
+// path to their new names. Fields that are not set are skipped.
+func renameFields(path []string, renames map[string]string) ConvertFunc {
+	return func(obj map[string]interface{}) error {
+		parent, found, err := unstructured.NestedFieldNoCopy(obj, path...)
+		if err != nil || !found {
+			return err
+		}
+		fields, ok := parent.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s is not an object", strings.Join(path, "."))
+		}
+		moved := make(map[string]interface{}, len(renames))
+		for from, to := range renames {
+			if value, found := fields[from]; found {
+				moved[to] = value
+				delete(fields, from)
+			}
+		}
+		for name, value := range moved {
+			fields[name] = value
+		}
+		return nil
+	}
+}
+
+// Kind describes the versions of a kind.
+type Kind struct {
+	// Hub is the version all other versions are converted through.
+	Hub string
+	// Spokes are the other versions by name.
+	Spokes map[string]Spoke
+	// Default sets defaults on objects, if any.
+	Default DefaultFunc
+}
+
+// Registry holds the registered kinds.
+type Registry struct {
+	kinds map[schema.GroupKind]Kind
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{kinds: map[schema.GroupKind]Kind{}}
+}
+
+// Register registers the versions of the kind gk.
+func (r *Registry) Register(gk schema.GroupKind, kind Kind) error {
+	if _, found := r.kinds[gk]; found {
+		return fmt.Errorf("kind %s is already registered", gk)
+	}
+	if kind.Hub == "" {
+		return fmt.Errorf("kind %s has no hub version", gk)
+	}
+	for version, spoke := range kind.Spokes {
+		if version == kind.Hub {
+			return fmt.Errorf("hub version %s of kind %s is also a spoke", version, gk)
+		}
+		if spoke.ToHub == nil || spoke.FromHub == nil {
+			return fmt.Errorf("version %s of kind %s does not convert both from and to the hub", version, gk)
+		}
+	}
+	r.kinds[gk] = kind
+	return nil
+}
+
+// Convert returns obj converted to the given version of its group.
+func (r *Registry) Convert(obj *unstructured.Unstructured, version string) (*unstructured.Unstructured, error) {
+	gvk := obj.GroupVersionKind()
+	kind, found := r.kinds[gvk.GroupKind()]
+	if !found {
+		return nil, fmt.Errorf("kind %s is not registered", gvk.GroupKind())
+	}
+
+	out := obj.DeepCopy()
+	if gvk.Version == version {
+		return out, nil
+	}
+	if gvk.Version != kind.Hub {
+		spoke, found := kind.Spokes[gvk.Version]
+		if !found {
+			return nil, fmt.Errorf("unknown version %s of kind %s", gvk.Version, gvk.GroupKind())
+		}
+		if err := spoke.ToHub(out.Object); err != nil {
+			return nil, fmt.Errorf("failed to convert %s from %s to %s: %w", gvk.GroupKind(), gvk.Version, kind.Hub, err)
+		}
+	}
+	if version != kind.Hub {
+		spoke, found := kind.Spokes[version]
+		if !found {
+			return nil, fmt.Errorf("unknown version %s of kind %s", version, gvk.GroupKind())
+		}
+		if err := spoke.FromHub(out.Object); err != nil {
+			return nil, fmt.Errorf("failed to convert %s from %s to %s: %w", gvk.GroupKind(), kind.Hub, version, err)
+		}
+	}
+	out.SetAPIVersion(schema.GroupVersion{Group: gvk.Group, Version: version}.String())
+	return out, nil
+}
+
+// Default returns obj with defaults set. Objects of spoke versions are
+// defaulted in the hub version and converted back.
+func (r *Registry) Default(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	gvk := obj.GroupVersionKind()
+	kind, found := r.kinds[gvk.GroupKind()]
+	if !found {
+		return nil, fmt.Errorf("kind %s is not registered", gvk.GroupKind())
+	}
+	if kind.Default == nil {
+		return obj.DeepCopy(), nil
+	}
+
+	hub, err := r.Convert(obj, kind.Hub)
+	if err != nil {
+		return nil, err
+	}
+	if err := kind.Default(hub.Object); err != nil {
+		return nil, fmt.Errorf("failed to default %s: %w", gvk.GroupKind(), err)
+	}
+	return r.Convert(hub, gvk.Version)
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// ConversionPath is the path of the conversion webhook, referenced by
+	// the conversion of the CRDs.
+	ConversionPath = "/convert"
+	// DefaultingPath is the path of the defaulting webhook, referenced by
+	// a MutatingWebhookConfiguration.
+	DefaultingPath = "/default"
+)
+
+// Options configures the webhook server.
+type Options struct {
+	// BindAddress is the address to serve on.
+	BindAddress string
+	// CertFile and KeyFile are the serving certificate and key.
+	CertFile string
+	KeyFile  string
+}
+
+// Handler returns the handler serving the webhooks of r.
+func (r *Registry) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(ConversionPath, r.serveConversion)
+	mux.HandleFunc(DefaultingPath, r.serveDefaulting)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	return mux
+}
+
+// Run serves the webhooks of r over TLS until ctx is done.
+func Run(ctx context.Context, r *Registry, options Options) error {
+	logger := klog.FromContext(ctx).WithValues("address", options.BindAddress)
+
+	server := &http.Server{
+		Addr:              options.BindAddress,
+		Handler:           r.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx) //nolint:contextcheck
+	}()
+
+	logger.Info("Serving TMC conversion and defaulting webhooks")
+	if err := server.ListenAndServeTLS(options.CertFile, options.KeyFile); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (r *Registry) serveConversion(w http.ResponseWriter, req *http.Request) {
+	review := &apiextensionsv1.ConversionReview{}
+	if err := json.NewDecoder(req.Body).Decode(review); err != nil || review.Request == nil {
+		http.Error(w, fmt.Sprintf("invalid ConversionReview: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	response := &apiextensionsv1.ConversionResponse{UID: review.Request.UID}
+	converted, err := r.convertAll(review.Request.Objects, review.Request.DesiredAPIVersion)
+	if err != nil {
+		response.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+	} else {
+		response.ConvertedObjects = converted
+		response.Result = metav1.Status{Status: metav1.StatusSuccess}
+	}
+	review.Request, review.Response = nil, response
+	writeJSON(w, review)
+}
+
+func (r *Registry) convertAll(objects []runtime.RawExtension, apiVersion string) ([]runtime.RawExtension, error) {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid desired apiVersion %q: %w", apiVersion, err)
+	}
+	converted := make([]runtime.RawExtension, 0, len(objects))
+	for _, raw := range objects {
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(raw.Raw); err != nil {
+			return nil, err
+		}
+		if obj.GroupVersionKind().Group != gv.Group {
+			return nil, fmt.Errorf("cannot convert %s to group %s", obj.GroupVersionKind(), gv.Group)
+		}
+		out, err := r.Convert(obj, gv.Version)
+		if err != nil {
+			return nil, err
+		}
+		data, err := out.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, runtime.RawExtension{Raw: data})
+	}
+	return converted, nil
+}
+
+func (r *Registry) serveDefaulting(w http.ResponseWriter, req *http.Request) {
+	review := &admissionv1.AdmissionReview{}
+	if err := json.NewDecoder(req.Body).Decode(review); err != nil || review.Request == nil {
+		http.Error(w, fmt.Sprintf("invalid AdmissionReview: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	response := &admissionv1.AdmissionResponse{UID: review.Request.UID, Allowed: true}
+	patch, err := r.defaultingPatch(review.Request.Object.Raw)
+	switch {
+	case err != nil:
+		response.Allowed = false
+		response.Result = &metav1.Status{Status: metav1.StatusFailure, Code: http.StatusBadRequest, Message: err.Error()}
+	case patch != nil:
+		patchType := admissionv1.PatchTypeJSONPatch
+		response.Patch, response.PatchType = patch, &patchType
+	}
+	review.Request, review.Response = nil, response
+	writeJSON(w, review)
+}
+
+// defaultingPatch returns the JSON patch setting the defaults of the
+// object, or nil if it has all defaults set. The patch replaces changed
+// top-level fields, e.g. spec, as a whole.
+func (r *Registry) defaultingPatch(raw []byte) ([]byte, error) {
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(raw); err != nil {
+		return nil, err
+	}
+	defaulted, err := r.Default(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	type operation struct {
+		Op    string      `json:"op"`
+		Path  string      `json:"path"`
+		Value interface{} `json:"value,omitempty"`
+	}
+	var patch []operation
+	for key, value := range defaulted.Object {
+		if old, found := obj.Object[key]; !found || !reflect.DeepEqual(old, value) {
+			patch = append(patch, operation{Op: "add", Path: "/" + escapePointer(key), Value: value})
+		}
+	}
+	for key := range obj.Object {
+		if _, found := defaulted.Object[key]; !found {
+			patch = append(patch, operation{Op: "remove", Path: "/" + escapePointer(key)})
+		}
+	}
+	if len(patch) == 0 {
+		return nil, nil
+	}
+	sort.Slice(patch, func(i, j int) bool { return patch[i].Path < patch[j].Path })
+	return json.Marshal(patch)
+}
+
+// escapePointer escapes a key for use in a JSON pointer.
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+func writeJSON(w http.ResponseWriter, obj interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(obj); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
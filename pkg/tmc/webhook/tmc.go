@@ -0,0 +1,86 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// spokes are the versions of the kinds of the TMC API groups next to
+// v1alpha1.
+var spokes = map[schema.GroupKind]map[string]Spoke{
+	// PlacementPolicy v1alpha2 names the strategy fields after what they
+	// decide.
+	placementv1alpha1.Kind("PlacementPolicy"): {
+		"v1alpha2": Renaming([]string{"spec"}, map[string]string{
+			"strategy":        "placementStrategy",
+			"numberOfTargets": "targetCount",
+		}),
+	},
+}
+
+// NewTMCRegistry returns a registry of the kinds of the TMC API groups,
+// with v1alpha1 as hub and the versions of spokes converted from and to
+// v1alpha1.
+func NewTMCRegistry() (*Registry, error) {
+	scheme := runtime.NewScheme()
+	builders := []func(*runtime.Scheme) error{
+		tmcv1alpha1.AddToScheme,
+		placementv1alpha1.AddToScheme,
+		workloadv1alpha1.AddToScheme,
+	}
+	for _, add := range builders {
+		if err := add(scheme); err != nil {
+			return nil, err
+		}
+	}
+
+	r := NewRegistry()
+	for _, gv := range []schema.GroupVersion{tmcv1alpha1.SchemeGroupVersion, placementv1alpha1.SchemeGroupVersion, workloadv1alpha1.SchemeGroupVersion} {
+		for _, gk := range kindsOf(scheme, gv) {
+			if err := r.Register(gk, Kind{Hub: gv.Version, Spokes: spokes[gk]}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return r, nil
+}
+
+// kindsOf returns the kinds of objects of gv in scheme, leaving out lists
+// and options.
+func kindsOf(scheme *runtime.Scheme, gv schema.GroupVersion) []schema.GroupKind {
+	var kinds []schema.GroupKind
+	for kind := range scheme.KnownTypes(gv) {
+		obj, err := scheme.New(gv.WithKind(kind))
+		if err != nil {
+			continue
+		}
+		if _, ok := obj.(metav1.Object); ok {
+			kinds = append(kinds, gv.WithKind(kind).GroupKind())
+		}
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i].Kind < kinds[j].Kind })
+	return kinds
+}
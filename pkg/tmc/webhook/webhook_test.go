@@ -0,0 +1,226 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/stretchr/testify/require"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	metafuzzer "k8s.io/apimachinery/pkg/apis/meta/fuzzer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+)
+
+const fuzzIters = 200
+
+func newPlacementPolicyRegistry(t *testing.T, defaults DefaultFunc) *Registry {
+	t.Helper()
+	r := NewRegistry()
+	require.NoError(t, r.Register(placementv1alpha1.Kind("PlacementPolicy"), Kind{
+		Hub:     "v1alpha1",
+		Spokes:  spokes[placementv1alpha1.Kind("PlacementPolicy")],
+		Default: defaults,
+	}))
+	return r
+}
+
+func fuzzPlacementPolicy(t *testing.T, f interface{ Fill(interface{}) }) *unstructured.Unstructured {
+	t.Helper()
+	policy := &placementv1alpha1.PlacementPolicy{}
+	f.Fill(policy)
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(policy)
+	require.NoError(t, err)
+	obj := &unstructured.Unstructured{Object: raw}
+	obj.SetGroupVersionKind(placementv1alpha1.SchemeGroupVersion.WithKind("PlacementPolicy"))
+	return obj
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	r := newPlacementPolicyRegistry(t, nil)
+	seed := rand.Int63()
+	f := fuzzer.FuzzerFor(metafuzzer.Funcs, rand.NewSource(seed), serializer.NewCodecFactory(runtime.NewScheme()))
+
+	for range fuzzIters {
+		original := fuzzPlacementPolicy(t, f)
+
+		v1alpha2, err := r.Convert(original, "v1alpha2")
+		require.NoError(t, err, "seed %d", seed)
+		require.Equal(t, "placement.kcp.io/v1alpha2", v1alpha2.GetAPIVersion())
+		_, found, _ := unstructured.NestedFieldNoCopy(v1alpha2.Object, "spec", "strategy")
+		require.False(t, found, "seed %d: strategy is renamed in v1alpha2", seed)
+
+		v1alpha1, err := r.Convert(v1alpha2, "v1alpha1")
+		require.NoError(t, err, "seed %d", seed)
+		require.Equal(t, original, v1alpha1, "seed %d", seed)
+
+		again, err := r.Convert(v1alpha1, "v1alpha2")
+		require.NoError(t, err, "seed %d", seed)
+		require.Equal(t, v1alpha2, again, "seed %d", seed)
+	}
+}
+
+func TestConvertUnknown(t *testing.T) {
+	r := newPlacementPolicyRegistry(t, nil)
+	policy := &unstructured.Unstructured{}
+	policy.SetGroupVersionKind(placementv1alpha1.SchemeGroupVersion.WithKind("PlacementPolicy"))
+
+	_, err := r.Convert(policy, "v1beta1")
+	require.ErrorContains(t, err, "unknown version v1beta1")
+
+	other := &unstructured.Unstructured{}
+	other.SetGroupVersionKind(placementv1alpha1.SchemeGroupVersion.WithKind("DataLocation"))
+	_, err = r.Convert(other, "v1alpha2")
+	require.ErrorContains(t, err, "is not registered")
+}
+
+func TestRegister(t *testing.T) {
+	r := newPlacementPolicyRegistry(t, nil)
+	require.ErrorContains(t, r.Register(placementv1alpha1.Kind("PlacementPolicy"), Kind{Hub: "v1alpha1"}), "already registered")
+	require.ErrorContains(t, r.Register(placementv1alpha1.Kind("DataLocation"), Kind{}), "no hub version")
+	require.ErrorContains(t, r.Register(placementv1alpha1.Kind("DataLocation"), Kind{
+		Hub:    "v1alpha1",
+		Spokes: map[string]Spoke{"v1alpha2": {ToHub: Renaming(nil, nil).ToHub}},
+	}), "does not convert both")
+}
+
+func TestNewTMCRegistry(t *testing.T) {
+	r, err := NewTMCRegistry()
+	require.NoError(t, err)
+	require.Contains(t, r.kinds, placementv1alpha1.Kind("PlacementPolicy"))
+	require.Contains(t, r.kinds, placementv1alpha1.Kind("PlacementPolicyRevision"))
+	require.NotContains(t, r.kinds, placementv1alpha1.Kind("PlacementPolicyList"))
+	require.NotContains(t, r.kinds, placementv1alpha1.Kind("WatchEvent"))
+	require.Contains(t, r.kinds[placementv1alpha1.Kind("PlacementPolicy")].Spokes, "v1alpha2")
+}
+
+func TestRenaming(t *testing.T) {
+	spoke := Renaming([]string{"spec"}, map[string]string{"a": "b", "c": "d"})
+
+	obj := map[string]interface{}{"spec": map[string]interface{}{"c": "x", "e": "y"}}
+	require.NoError(t, spoke.FromHub(obj))
+	require.Equal(t, map[string]interface{}{"spec": map[string]interface{}{"d": "x", "e": "y"}}, obj, "missing fields are skipped")
+	require.NoError(t, spoke.ToHub(obj))
+	require.Equal(t, map[string]interface{}{"spec": map[string]interface{}{"c": "x", "e": "y"}}, obj)
+
+	noSpec := map[string]interface{}{"metadata": map[string]interface{}{}}
+	require.NoError(t, spoke.FromHub(noSpec))
+	require.ErrorContains(t, spoke.FromHub(map[string]interface{}{"spec": "x"}), "spec is not an object")
+}
+
+func TestServeConversion(t *testing.T) {
+	r := newPlacementPolicyRegistry(t, nil)
+	policy := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "placement.kcp.io/v1alpha1",
+		"kind":       "PlacementPolicy",
+		"metadata":   map[string]interface{}{"name": "policy"},
+		"spec":       map[string]interface{}{"strategy": "Spread", "numberOfTargets": int64(2)},
+	}}
+	data, err := policy.MarshalJSON()
+	require.NoError(t, err)
+
+	body, err := json.Marshal(&apiextensionsv1.ConversionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1", Kind: "ConversionReview"},
+		Request: &apiextensionsv1.ConversionRequest{
+			UID:               "1",
+			DesiredAPIVersion: "placement.kcp.io/v1alpha2",
+			Objects:           []runtime.RawExtension{{Raw: data}},
+		},
+	})
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodPost, ConversionPath, bytes.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	review := &apiextensionsv1.ConversionReview{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), review))
+	require.Equal(t, "1", string(review.Response.UID))
+	require.Equal(t, metav1.StatusSuccess, review.Response.Result.Status, review.Response.Result.Message)
+	require.Len(t, review.Response.ConvertedObjects, 1)
+	converted := &unstructured.Unstructured{}
+	require.NoError(t, converted.UnmarshalJSON(review.Response.ConvertedObjects[0].Raw))
+	require.Equal(t, "placement.kcp.io/v1alpha2", converted.GetAPIVersion())
+	require.Equal(t, map[string]interface{}{"placementStrategy": "Spread", "targetCount": int64(2)}, converted.Object["spec"])
+}
+
+func TestServeDefaulting(t *testing.T) {
+	r := newPlacementPolicyRegistry(t, func(obj map[string]interface{}) error {
+		if _, found, _ := unstructured.NestedString(obj, "spec", "strategy"); found {
+			return nil
+		}
+		return unstructured.SetNestedField(obj, "Spread", "spec", "strategy")
+	})
+
+	review := func(t *testing.T, obj map[string]interface{}) *admissionv1.AdmissionResponse {
+		t.Helper()
+		data, err := json.Marshal(obj)
+		require.NoError(t, err)
+		body, err := json.Marshal(&admissionv1.AdmissionReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+			Request:  &admissionv1.AdmissionRequest{UID: "1", Object: runtime.RawExtension{Raw: data}},
+		})
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		r.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodPost, DefaultingPath, bytes.NewReader(body)))
+		require.Equal(t, http.StatusOK, w.Code)
+		resp := &admissionv1.AdmissionReview{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), resp))
+		require.True(t, resp.Response.Allowed)
+		return resp.Response
+	}
+
+	// Objects of spoke versions are defaulted in the hub version.
+	policy := map[string]interface{}{
+		"apiVersion": "placement.kcp.io/v1alpha2",
+		"kind":       "PlacementPolicy",
+		"metadata":   map[string]interface{}{"name": "policy"},
+		"spec":       map[string]interface{}{"targetCount": int64(2)},
+	}
+	resp := review(t, policy)
+	require.NotNil(t, resp.PatchType)
+	patch, err := jsonpatch.DecodePatch(resp.Patch)
+	require.NoError(t, err)
+	original, err := json.Marshal(policy)
+	require.NoError(t, err)
+	patched, err := patch.Apply(original)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"placementStrategy":"Spread","targetCount":2}`, string(mustField(t, patched, "spec")))
+
+	// Defaulted objects are not patched.
+	policy["spec"] = map[string]interface{}{"placementStrategy": "Singleton"}
+	require.Nil(t, review(t, policy).Patch)
+}
+
+func mustField(t *testing.T, data []byte, key string) []byte {
+	t.Helper()
+	var obj map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(data, &obj))
+	return obj[key]
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacequeue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// ConfigMapKey is the key of the configuration in its ConfigMap.
+const ConfigMapKey = "config.yaml"
+
+// Limit is the rate at which a workspace adds items to the queue of a
+// controller before they are throttled.
+type Limit struct {
+	// QPS is the sustained rate. Zero means unlimited.
+	QPS float64 `json:"qps,omitempty"`
+	// Burst is the number of items added at once. Defaults to QPS, and at
+	// least 1.
+	Burst int `json:"burst,omitempty"`
+}
+
+func (l Limit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return max(int(l.QPS), 1)
+}
+
+// Config configures the rates of workspaces, in every queue.
+type Config struct {
+	// Default is the limit of the workspaces not in Workspaces.
+	Default Limit `json:"default,omitempty"`
+	// Workspaces are the limits of workspaces by logical cluster name.
+	Workspaces map[string]Limit `json:"workspaces,omitempty"`
+}
+
+var (
+	configLock sync.RWMutex
+	config     = &Config{}
+)
+
+// SetConfig sets the rates of workspaces in all queues.
+func SetConfig(c *Config) {
+	configLock.Lock()
+	defer configLock.Unlock()
+	config = c
+}
+
+func limitOf(workspace string) Limit {
+	configLock.RLock()
+	defer configLock.RUnlock()
+	if limit, found := config.Workspaces[workspace]; found {
+		return limit
+	}
+	return config.Default
+}
+
+// ParseConfig returns the YAML or JSON configuration in data.
+func ParseConfig(data []byte) (*Config, error) {
+	c := &Config{}
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, fmt.Errorf("failed to decode workqueue configuration: %w", err)
+	}
+	if err := c.Default.validate(); err != nil {
+		return nil, fmt.Errorf("invalid default limit: %w", err)
+	}
+	for workspace, limit := range c.Workspaces {
+		if err := limit.validate(); err != nil {
+			return nil, fmt.Errorf("invalid limit of workspace %q: %w", workspace, err)
+		}
+	}
+	return c, nil
+}
+
+func (l Limit) validate() error {
+	if l.QPS < 0 {
+		return fmt.Errorf("qps must not be negative")
+	}
+	if l.Burst < 0 {
+		return fmt.Errorf("burst must not be negative")
+	}
+	return nil
+}
+
+// WatchConfigMap sets the configuration from the key ConfigMapKey of the
+// ConfigMap namespace/name every interval until ctx is done. Workspaces
+// are not rate limited while the ConfigMap does not exist. An invalid
+// configuration is reported and the previous one is kept.
+func WatchConfigMap(ctx context.Context, client kubernetes.Interface, namespace, name string, interval time.Duration) {
+	logger := klog.FromContext(ctx).WithValues("configMap", namespace+"/"+name)
+	var resourceVersion string
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		cm, err := client.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			if resourceVersion != "" {
+				logger.Info("workqueue configuration removed, workspaces are no longer rate limited")
+				SetConfig(&Config{})
+				resourceVersion = ""
+			}
+			return
+		}
+		if err != nil {
+			logger.Error(err, "failed to get workqueue configuration")
+			return
+		}
+		if cm.ResourceVersion == resourceVersion {
+			return
+		}
+		resourceVersion = cm.ResourceVersion
+		c, err := ParseConfig([]byte(cm.Data[ConfigMapKey]))
+		if err != nil {
+			logger.Error(err, "ignoring invalid workqueue configuration")
+			return
+		}
+		logger.Info("workqueue configuration changed", "workspaces", len(c.Workspaces))
+		SetConfig(c)
+	}, interval)
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacequeue
+
+import (
+	"sync"
+
+	compbasemetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+var (
+	depth = compbasemetrics.NewGaugeVec(
+		&compbasemetrics.GaugeOpts{
+			Name:           "tmc_workqueue_workspace_depth",
+			Help:           "Number of items queued in the workqueues of the TMC controllers, by queue and workspace.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"name", "workspace"},
+	)
+	throttledItems = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Name:           "tmc_workqueue_throttled_items_total",
+			Help:           "Number of items added to the workqueues of the TMC controllers above the rate of their workspace, by queue and workspace.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"name", "workspace"},
+	)
+)
+
+var registerMetrics sync.Once
+
+// Register metrics.
+func Register() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(depth)
+		legacyregistry.MustRegister(throttledItems)
+	})
+}
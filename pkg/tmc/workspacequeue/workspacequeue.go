@@ -0,0 +1,171 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package workspacequeue provides the workqueue of the TMC controllers,
+// which keeps a workspace flooding a controller with changes from starving
+// the reconciliation of the other workspaces. Every workspace has a token
+// bucket per queue. Items added within the rate of their workspace go to
+// the priority lane, the others to the throttled lane, which is served once
+// the priority lane is empty or its oldest item waited longer than the
+// starvation threshold. Failed items back off as with
+// retry.ControllerRateLimiter.
+//
+// The rates are configured by SetConfig, usually from a ConfigMap followed
+// by WatchConfigMap. Workspaces are not rate limited by default.
+package workspacequeue
+
+import (
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"k8s.io/client-go/util/workqueue"
+
+	kcpcache "github.com/kcp-dev/apimachinery/v2/pkg/cache"
+
+	"github.com/kcp-dev/kcp/pkg/tmc/retry"
+)
+
+// starvationThreshold is how long an item may wait in the throttled lane
+// before it is served ahead of the priority lane.
+const starvationThreshold = 30 * time.Second
+
+type entry struct {
+	item      string
+	workspace string
+	added     time.Time
+}
+
+// queue is a workqueue.Queue with a priority and a throttled lane.
+type queue struct {
+	name    string
+	limitOf func(workspace string) Limit
+	now     func() time.Time
+
+	limiters map[string]*rate.Limiter
+	// lanes are the priority and the throttled lane.
+	lanes [2][]entry
+	depth map[string]int
+}
+
+// NewQueue returns the storage of the workqueue name, which hands out the
+// items of workspaces exceeding their rate after the others. Items are
+// cluster-aware keys, as returned by kcpcache.MetaClusterNamespaceKeyFunc.
+func NewQueue(name string) workqueue.Queue[string] {
+	return newQueue(name, limitOf)
+}
+
+func newQueue(name string, limitOf func(workspace string) Limit) *queue {
+	return &queue{
+		name:     name,
+		limitOf:  limitOf,
+		now:      time.Now,
+		limiters: map[string]*rate.Limiter{},
+		depth:    map[string]int{},
+	}
+}
+
+// NewRateLimitingQueue returns the rate limiting workqueue name of a TMC
+// controller.
+func NewRateLimitingQueue(name string) workqueue.TypedRateLimitingInterface[string] {
+	Register()
+	return workqueue.NewTypedRateLimitingQueueWithConfig(
+		retry.ControllerRateLimiter[string](),
+		workqueue.TypedRateLimitingQueueConfig[string]{
+			Name: name,
+			DelayingQueue: workqueue.NewTypedDelayingQueueWithConfig(workqueue.TypedDelayingQueueConfig[string]{
+				Name: name,
+				Queue: workqueue.NewTypedWithConfig(workqueue.TypedQueueConfig[string]{
+					Name:  name,
+					Queue: NewQueue(name),
+				}),
+			}),
+		},
+	)
+}
+
+// Touch keeps the lane of an item queued again, which was charged already.
+func (q *queue) Touch(item string) {}
+
+func (q *queue) Push(item string) {
+	e := entry{item: item, workspace: workspaceOf(item), added: q.now()}
+	lane := 0
+	if !q.allow(e.workspace, e.added) {
+		lane = 1
+		throttledItems.WithLabelValues(q.name, e.workspace).Inc()
+	}
+	q.lanes[lane] = append(q.lanes[lane], e)
+	q.depth[e.workspace]++
+	depth.WithLabelValues(q.name, e.workspace).Set(float64(q.depth[e.workspace]))
+}
+
+func (q *queue) Len() int {
+	return len(q.lanes[0]) + len(q.lanes[1])
+}
+
+func (q *queue) Pop() string {
+	lane := 0
+	if len(q.lanes[1]) > 0 && (len(q.lanes[0]) == 0 || q.now().Sub(q.lanes[1][0].added) >= starvationThreshold) {
+		lane = 1
+	}
+	if len(q.lanes[lane]) == 0 {
+		return ""
+	}
+	e := q.lanes[lane][0]
+	// Avoid memory leaks of the backing array, as in the default queue.
+	q.lanes[lane][0] = entry{}
+	q.lanes[lane] = q.lanes[lane][1:]
+
+	q.depth[e.workspace]--
+	if q.depth[e.workspace] > 0 {
+		depth.WithLabelValues(q.name, e.workspace).Set(float64(q.depth[e.workspace]))
+	} else {
+		// Idle workspaces are dropped to bound the series.
+		delete(q.depth, e.workspace)
+		depth.Delete(map[string]string{"name": q.name, "workspace": e.workspace})
+	}
+	return e.item
+}
+
+// allow takes a token from the bucket of the workspace, following changes
+// of its limit.
+func (q *queue) allow(workspace string, now time.Time) bool {
+	limit := q.limitOf(workspace)
+	if limit.QPS <= 0 {
+		delete(q.limiters, workspace)
+		return true
+	}
+	burst := limit.burst()
+	limiter, found := q.limiters[workspace]
+	if !found {
+		limiter = rate.NewLimiter(rate.Limit(limit.QPS), burst)
+		q.limiters[workspace] = limiter
+	} else if limiter.Limit() != rate.Limit(limit.QPS) || limiter.Burst() != burst {
+		limiter.SetLimitAt(now, rate.Limit(limit.QPS))
+		limiter.SetBurstAt(now, burst)
+	}
+	return limiter.AllowN(now, 1)
+}
+
+// workspaceOf returns the logical cluster of a key, or "" if the key is not
+// cluster-aware.
+func workspaceOf(key string) string {
+	clusterName, _, _, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		return ""
+	}
+	return clusterName.String()
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacequeue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	kcpcache "github.com/kcp-dev/apimachinery/v2/pkg/cache"
+)
+
+func TestQueueThrottlesWorkspaces(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	limits := map[string]Limit{"root:noisy": {QPS: 1, Burst: 2}}
+	q := newQueue("test", func(workspace string) Limit { return limits[workspace] })
+	q.now = func() time.Time { return now }
+
+	key := func(workspace, name string) string { return kcpcache.ToClusterAwareKey(workspace, "default", name) }
+	for _, k := range []string{key("root:noisy", "a"), key("root:noisy", "b"), key("root:noisy", "c"), key("root:quiet", "a")} {
+		q.Push(k)
+	}
+	require.Equal(t, 4, q.Len())
+	require.Equal(t, map[string]int{"root:noisy": 3, "root:quiet": 1}, q.depth)
+
+	var popped []string
+	for q.Len() > 0 {
+		popped = append(popped, q.Pop())
+	}
+	require.Equal(t, []string{key("root:noisy", "a"), key("root:noisy", "b"), key("root:quiet", "a"), key("root:noisy", "c")}, popped,
+		"items above the rate of their workspace are served after the others")
+	require.Empty(t, q.depth)
+
+	q.Push(key("root:noisy", "d"))
+	now = now.Add(starvationThreshold)
+	q.Push(key("root:quiet", "b"))
+	require.Equal(t, key("root:noisy", "d"), q.Pop(), "throttled items do not starve")
+	require.Equal(t, key("root:quiet", "b"), q.Pop())
+
+	limits["root:noisy"] = Limit{}
+	for _, name := range []string{"e", "f", "g", "h"} {
+		q.Push(key("root:noisy", name))
+	}
+	require.Empty(t, q.lanes[1], "limits follow the configuration")
+	require.NotContains(t, q.limiters, "root:noisy")
+}
+
+func TestParseConfig(t *testing.T) {
+	c, err := ParseConfig([]byte(`
+default:
+  qps: 50
+workspaces:
+  root:noisy:
+    qps: 5
+    burst: 10
+`))
+	require.NoError(t, err)
+	require.Equal(t, &Config{Default: Limit{QPS: 50}, Workspaces: map[string]Limit{"root:noisy": {QPS: 5, Burst: 10}}}, c)
+	require.Equal(t, 50, c.Default.burst())
+
+	_, err = ParseConfig([]byte("workspaces:\n  root:noisy:\n    qps: -1\n"))
+	require.ErrorContains(t, err, `workspace "root:noisy"`)
+	_, err = ParseConfig([]byte("default:\n  rate: 1\n"))
+	require.Error(t, err, "unknown fields are rejected")
+}
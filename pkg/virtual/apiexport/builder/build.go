@@ -71,6 +71,7 @@ func BuildVirtualWorkspace(
 	kubeClusterClient, deepSARClient kcpkubernetesclientset.ClusterInterface,
 	kcpClusterClient kcpclientset.ClusterInterface,
 	cachedKcpInformers, kcpInformers kcpinformers.SharedInformerFactory,
+	listCache *forwardingregistry.ListCacheOptions,
 ) ([]rootapiserver.NamedVirtualWorkspace, error) {
 	if !strings.HasSuffix(rootPathPrefix, "/") {
 		rootPathPrefix += "/"
@@ -161,14 +162,7 @@ func BuildVirtualWorkspace(
 				func(apiResourceSchema *apisv1alpha1.APIResourceSchema, version string, identityHash string, optionalLabelRequirements labels.Requirements) (apidefinition.APIDefinition, error) {
 					ctx, cancelFn := context.WithCancel(context.Background())
 
-					var wrapper forwardingregistry.StorageWrapper
-					if len(optionalLabelRequirements) > 0 {
-						wrapper = forwardingregistry.WithLabelSelector(func(_ context.Context) labels.Requirements {
-							return optionalLabelRequirements
-						})
-					}
-
-					storageBuilder := provideDelegatingRestStorage(ctx, impersonatedDynamicClientGetter, identityHash, wrapper)
+					storageBuilder := provideDelegatingRestStorage(ctx, impersonatedDynamicClientGetter, identityHash, storageWrapper(optionalLabelRequirements, listCache))
 					def, err := apiserver.CreateServingInfoFor(mainConfig, apiResourceSchema, version, storageBuilder)
 					if err != nil {
 						cancelFn()
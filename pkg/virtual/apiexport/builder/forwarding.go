@@ -51,6 +51,26 @@ func provideAPIExportFilteredRestStorage(ctx context.Context, dynamicClusterClie
 	return registry.ProvideReadOnlyRestStorage(ctx, dynamicClusterClientFunc, registry.WithStaticLabelSelector(requirements), nil)
 }
 
+// storageWrapper returns the wrapper of the storage of a bound resource,
+// filtering by labelRequirements if any and caching lists if listCache is
+// not nil. It returns nil if there is nothing to wrap.
+func storageWrapper(labelRequirements labels.Requirements, listCache *registry.ListCacheOptions) registry.StorageWrapper {
+	var wrappers registry.StorageWrappers
+	if len(labelRequirements) > 0 {
+		wrappers = append(wrappers, registry.WithLabelSelector(func(_ context.Context) labels.Requirements {
+			return labelRequirements
+		}))
+	}
+	if listCache != nil {
+		// Lists are cached after filtering, keyed by the impersonated user.
+		wrappers = append(wrappers, registry.WithListCache(*listCache))
+	}
+	if len(wrappers) == 0 {
+		return nil
+	}
+	return &wrappers
+}
+
 // provideDelegatingRestStorage returns a forwarding storage build function, with an optional storage wrapper e.g. to add label based filtering.
 func provideDelegatingRestStorage(ctx context.Context, dynamicClusterClientFunc registry.DynamicClusterClientFunc, apiExportIdentityHash string, wrapper registry.StorageWrapper) apiserver.RestProviderFunc {
 	return func(resource schema.GroupVersionResource, kind schema.GroupVersionKind, listKind schema.GroupVersionKind, typer runtime.ObjectTyper, tableConvertor rest.TableConvertor, namespaceScoped bool, schemaValidator validation.SchemaValidator, subresourcesSchemaValidator map[string]validation.SchemaValidator, structuralSchema *structuralschema.Structural) (mainStorage rest.Storage, subresourceStorages map[string]rest.Storage) {
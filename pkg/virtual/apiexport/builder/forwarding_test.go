@@ -0,0 +1,69 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apiserver/pkg/authentication/user"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	registry "github.com/kcp-dev/kcp/pkg/virtual/framework/forwardingregistry"
+)
+
+func TestStorageWrapper(t *testing.T) {
+	require.Nil(t, storageWrapper(nil, nil), "nothing to wrap")
+
+	requirement, err := labels.NewRequirement("app", selection.Equals, []string{"web"})
+	require.NoError(t, err)
+
+	var calls int
+	var selectors []string
+	store := &registry.StoreFuncs{}
+	store.ListerFunc = func(_ context.Context, options *internalversion.ListOptions) (runtime.Object, error) {
+		calls++
+		selectors = append(selectors, options.LabelSelector.String())
+		list := &unstructured.UnstructuredList{Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMapList"}}
+		list.SetResourceVersion("42")
+		return list, nil
+	}
+	storageWrapper(labels.Requirements{*requirement}, &registry.ListCacheOptions{TTL: time.Minute}).
+		Decorate(schema.GroupResource{Resource: "configmaps"}, store)
+
+	ctx := genericapirequest.WithCluster(context.Background(), genericapirequest.Cluster{Name: "root:org"})
+	ctx = genericapirequest.WithUser(ctx, &user.DefaultInfo{Name: "syncer"})
+	for range 2 {
+		_, err := store.ListerFunc(ctx, &internalversion.ListOptions{ResourceVersion: "0"})
+		require.NoError(t, err)
+	}
+	require.Equal(t, 1, calls, "lists with resourceVersion=0 are cached")
+	require.Equal(t, []string{"app=web"}, selectors, "the label requirements still apply")
+
+	_, err = store.ListerFunc(ctx, &internalversion.ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 2, calls, "consistent lists are not cached")
+}
@@ -17,7 +17,9 @@ limitations under the License.
 package options
 
 import (
+	"fmt"
 	"path"
+	"time"
 
 	"github.com/spf13/pflag"
 
@@ -27,12 +29,17 @@ import (
 
 	"github.com/kcp-dev/kcp/pkg/authorization"
 	"github.com/kcp-dev/kcp/pkg/virtual/apiexport/builder"
+	"github.com/kcp-dev/kcp/pkg/virtual/framework/forwardingregistry"
 	"github.com/kcp-dev/kcp/pkg/virtual/framework/rootapiserver"
 	kcpclientset "github.com/kcp-dev/kcp/sdk/client/clientset/versioned/cluster"
 	kcpinformers "github.com/kcp-dev/kcp/sdk/client/informers/externalversions"
 )
 
-type APIExport struct{}
+type APIExport struct {
+	// ListCacheTTL is how long lists with resourceVersion=0 are served from
+	// a cache. Lists are not cached if zero.
+	ListCacheTTL time.Duration
+}
 
 func New() *APIExport {
 	return &APIExport{}
@@ -42,6 +49,8 @@ func (o *APIExport) AddFlags(flags *pflag.FlagSet, prefix string) {
 	if o == nil {
 		return
 	}
+
+	flags.DurationVar(&o.ListCacheTTL, prefix+"apiexport-list-cache-ttl", o.ListCacheTTL, "How long lists with resourceVersion=0 through the APIExport virtual workspace are served from a cache, paginated with limit and continue. Cached lists are per user. Disabled if zero.")
 }
 
 func (o *APIExport) Validate(flagPrefix string) []error {
@@ -50,6 +59,10 @@ func (o *APIExport) Validate(flagPrefix string) []error {
 	}
 	errs := []error{}
 
+	if o.ListCacheTTL < 0 {
+		errs = append(errs, fmt.Errorf("--%sapiexport-list-cache-ttl must not be negative", flagPrefix))
+	}
+
 	return errs
 }
 
@@ -72,5 +85,10 @@ func (o *APIExport) NewVirtualWorkspaces(
 		return nil, err
 	}
 
-	return builder.BuildVirtualWorkspace(path.Join(rootPathPrefix, builder.VirtualWorkspaceName), config, kubeClusterClient, deepSARClient, kcpClusterClient, cachedKcpInformers, wildcardKcpInformers)
+	var listCache *forwardingregistry.ListCacheOptions
+	if o.ListCacheTTL > 0 {
+		listCache = &forwardingregistry.ListCacheOptions{TTL: o.ListCacheTTL}
+	}
+
+	return builder.BuildVirtualWorkspace(path.Join(rootPathPrefix, builder.VirtualWorkspaceName), config, kubeClusterClient, deepSARClient, kcpClusterClient, cachedKcpInformers, wildcardKcpInformers, listCache)
 }
@@ -0,0 +1,100 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package forwardingregistry
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/apiserver/pkg/warning"
+
+	"github.com/kcp-dev/kcp/pkg/deprecation"
+)
+
+// WithDeprecationWarnings returns a StorageWrapper that adds a Warning header
+// for every deprecated field of the registry set in the objects returned by
+// get, list, create and update of the given version. Each warning is added
+// once per request.
+func WithDeprecationWarnings(registry *deprecation.Registry, version string) StorageWrapper {
+	return StorageWrapperFunc(func(groupResource schema.GroupResource, storage *StoreFuncs) {
+		gvr := groupResource.WithVersion(version)
+		if !registry.Has(gvr) {
+			return
+		}
+		warn := func(ctx context.Context, objs ...unstructured.Unstructured) {
+			warned := sets.New[string]()
+			for _, obj := range objs {
+				for _, f := range registry.Find(gvr, obj.Object) {
+					if w := f.Warning(); !warned.Has(w) {
+						warned.Insert(w)
+						warning.AddWarning(ctx, "", w)
+					}
+				}
+			}
+		}
+		warnObject := func(ctx context.Context, obj runtime.Object) {
+			switch obj := obj.(type) {
+			case *unstructured.Unstructured:
+				warn(ctx, *obj)
+			case *unstructured.UnstructuredList:
+				warn(ctx, obj.Items...)
+			}
+		}
+
+		delegateGetter := storage.GetterFunc
+		storage.GetterFunc = func(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+			obj, err := delegateGetter.Get(ctx, name, options)
+			if err == nil {
+				warnObject(ctx, obj)
+			}
+			return obj, err
+		}
+
+		delegateLister := storage.ListerFunc
+		storage.ListerFunc = func(ctx context.Context, options *internalversion.ListOptions) (runtime.Object, error) {
+			obj, err := delegateLister.List(ctx, options)
+			if err == nil {
+				warnObject(ctx, obj)
+			}
+			return obj, err
+		}
+
+		delegateCreater := storage.CreaterFunc
+		storage.CreaterFunc = func(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+			created, err := delegateCreater.Create(ctx, obj, createValidation, options)
+			if err == nil {
+				warnObject(ctx, created)
+			}
+			return created, err
+		}
+
+		delegateUpdater := storage.UpdaterFunc
+		storage.UpdaterFunc = func(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
+			updated, created, err := delegateUpdater.Update(ctx, name, objInfo, createValidation, updateValidation, forceAllowCreate, options)
+			if err == nil {
+				warnObject(ctx, updated)
+			}
+			return updated, created, err
+		}
+	})
+}
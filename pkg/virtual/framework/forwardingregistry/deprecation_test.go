@@ -0,0 +1,96 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package forwardingregistry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/warning"
+
+	"github.com/kcp-dev/kcp/pkg/deprecation"
+)
+
+type warningRecorder struct {
+	warnings []string
+}
+
+func (r *warningRecorder) AddWarning(_, text string) {
+	r.warnings = append(r.warnings, text)
+}
+
+func TestWithDeprecationWarnings(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "tmc.kcp.io", Version: "v1alpha1", Resource: "widgets"}
+	registry := deprecation.NewRegistry(deprecation.Field{Resource: gvr, Kind: "Widget", Path: "spec.size", Message: "use spec.replicas instead"})
+
+	widget := func(name string, spec map[string]interface{}) unstructured.Unstructured {
+		return unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "tmc.kcp.io/v1alpha1",
+			"kind":       "Widget",
+			"metadata":   map[string]interface{}{"name": name},
+			"spec":       spec,
+		}}
+	}
+	storage := &StoreFuncs{
+		GetterFunc: func(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+			obj := widget(name, map[string]interface{}{"replicas": int64(1)})
+			if name == "old" {
+				obj = widget(name, map[string]interface{}{"size": int64(1)})
+			}
+			return &obj, nil
+		},
+		ListerFunc: func(ctx context.Context, options *internalversion.ListOptions) (runtime.Object, error) {
+			return &unstructured.UnstructuredList{Items: []unstructured.Unstructured{
+				widget("a", map[string]interface{}{"size": int64(1)}),
+				widget("b", map[string]interface{}{"size": int64(2)}),
+				widget("c", map[string]interface{}{"replicas": int64(3)}),
+			}}, nil
+		},
+	}
+	other := &StoreFuncs{GetterFunc: storage.GetterFunc}
+	WithDeprecationWarnings(registry, "v1alpha1").Decorate(gvr.GroupResource(), storage)
+	WithDeprecationWarnings(registry, "v1beta1").Decorate(gvr.GroupResource(), other)
+
+	expected := "tmc.kcp.io/v1alpha1 Widget spec.size is deprecated: use spec.replicas instead"
+
+	rec := &warningRecorder{}
+	_, err := storage.Get(warning.WithWarningRecorder(context.Background(), rec), "old", &metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{expected}, rec.warnings)
+
+	rec = &warningRecorder{}
+	_, err = storage.Get(warning.WithWarningRecorder(context.Background(), rec), "new", &metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Empty(t, rec.warnings)
+
+	rec = &warningRecorder{}
+	_, err = storage.List(warning.WithWarningRecorder(context.Background(), rec), &internalversion.ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{expected}, rec.warnings, "warnings are added once per request")
+
+	rec = &warningRecorder{}
+	_, err = other.Get(warning.WithWarningRecorder(context.Background(), rec), "old", &metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Empty(t, rec.warnings, "other versions are not decorated")
+}
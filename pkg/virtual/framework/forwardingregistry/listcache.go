@@ -0,0 +1,358 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package forwardingregistry
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/utils/clock"
+)
+
+const (
+	defaultListCacheTTL           = 5 * time.Second
+	defaultListCachePageRetention = time.Minute
+	defaultListCacheFillPageSize  = 500
+	defaultListCacheMaxEntries    = 100
+
+	// ListCacheContinuePrefix marks continue tokens issued by the list
+	// cache, as opposed to those of the backend.
+	ListCacheContinuePrefix = "vwcache."
+)
+
+// ListCacheOptions configure WithListCache. Zero values mean the defaults.
+type ListCacheOptions struct {
+	// TTL is how long a cached list serves new list requests.
+	TTL time.Duration
+	// PageRetention is how long a cached list serves the continue tokens it
+	// issued, counted from when it was cached. Later pages fail as expired,
+	// like continue tokens of a compacted resource version.
+	PageRetention time.Duration
+	// FillPageSize is the page size used to list from the backend.
+	FillPageSize int64
+	// MaxEntries bounds the number of cached lists per resource.
+	MaxEntries int
+	// Scope returns the part of the cache key that comes from the request
+	// context, e.g. the SyncTarget of a syncer, if wrappers applied before
+	// the list cache change the list depending on the context.
+	Scope func(ctx context.Context) string
+}
+
+// WithListCache caches list requests with resourceVersion=0, which allow
+// any resource version, for a short time. Syncers listing tens of thousands
+// of objects on reconnect are served from the cache, paginated with limit
+// and continue, instead of each causing a scan of the backend storage.
+// Other list requests are forwarded to the backend, which paginates them.
+//
+// Cached lists are keyed by the requesting user, cluster, namespace, scope
+// and selectors. Continue tokens carry a random ID and are only honored for
+// requests with the same key, others fail as expired.
+func WithListCache(options ListCacheOptions) StorageWrapper {
+	return StorageWrapperFunc(func(_ schema.GroupResource, storage *StoreFuncs) {
+		storage.ListerFunc = NewCachingLister(storage.ListerFunc, options)
+	})
+}
+
+// NewCachingLister returns a ListerFunc caching the lists of delegate as
+// WithListCache does, for handlers that forward requests without a store.
+// The request context must carry the cluster, user and namespace.
+func NewCachingLister(delegate ListerFunc, options ListCacheOptions) ListerFunc {
+	return newListCache(delegate, options, clock.RealClock{}).List
+}
+
+type listCache struct {
+	delegate ListerFunc
+	options  ListCacheOptions
+	clock    clock.PassiveClock
+
+	lock    sync.Mutex
+	nextSeq uint64
+	byKey   map[string]*listSnapshot
+	byID    map[string]*listSnapshot
+	filling map[string]*listFill
+}
+
+type listSnapshot struct {
+	id string
+	// seq orders snapshots by creation for eviction.
+	seq     uint64
+	key     string
+	created time.Time
+	list    *unstructured.UnstructuredList
+}
+
+type listFill struct {
+	done     chan struct{}
+	snapshot *listSnapshot
+	err      error
+}
+
+type listCacheContinue struct {
+	ID     string `json:"id"`
+	Offset int    `json:"offset"`
+}
+
+func newListCache(delegate ListerFunc, options ListCacheOptions, clock clock.PassiveClock) *listCache {
+	if options.TTL <= 0 {
+		options.TTL = defaultListCacheTTL
+	}
+	if options.PageRetention < options.TTL {
+		options.PageRetention = max(defaultListCachePageRetention, options.TTL)
+	}
+	if options.FillPageSize <= 0 {
+		options.FillPageSize = defaultListCacheFillPageSize
+	}
+	if options.MaxEntries <= 0 {
+		options.MaxEntries = defaultListCacheMaxEntries
+	}
+	return &listCache{
+		delegate: delegate,
+		options:  options,
+		clock:    clock,
+		byKey:    map[string]*listSnapshot{},
+		byID:     map[string]*listSnapshot{},
+		filling:  map[string]*listFill{},
+	}
+}
+
+// List serves the list request from the cache if possible.
+func (c *listCache) List(ctx context.Context, options *internalversion.ListOptions) (runtime.Object, error) {
+	token, cached := strings.CutPrefix(options.Continue, ListCacheContinuePrefix)
+	if !cached && (options.ResourceVersion != "0" || options.Continue != "" || options.Watch) {
+		return c.delegate(ctx, options)
+	}
+
+	key, err := c.key(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	if cached {
+		return c.continueList(key, token, options.Limit)
+	}
+	snapshot, err := c.snapshot(ctx, key, options)
+	if err != nil {
+		return nil, err
+	}
+	return c.page(snapshot, 0, options.Limit), nil
+}
+
+func (c *listCache) key(ctx context.Context, options *internalversion.ListOptions) (string, error) {
+	cluster, err := genericapirequest.ValidClusterFrom(ctx)
+	if err != nil {
+		return "", apiErrorBadRequest(err)
+	}
+	user, found := genericapirequest.UserFrom(ctx)
+	if !found {
+		return "", apierrors.NewInternalError(fmt.Errorf("no user in the request context"))
+	}
+	groups := append([]string(nil), user.GetGroups()...)
+	sort.Strings(groups)
+	namespace, _ := genericapirequest.NamespaceFrom(ctx)
+	parts := []string{
+		user.GetName(), user.GetUID(), strings.Join(groups, ","),
+		cluster.Name.String(), strconv.FormatBool(cluster.Wildcard), namespace,
+	}
+	if c.options.Scope != nil {
+		parts = append(parts, c.options.Scope(ctx))
+	}
+	if options.LabelSelector != nil {
+		parts = append(parts, options.LabelSelector.String())
+	} else {
+		parts = append(parts, "")
+	}
+	if options.FieldSelector != nil {
+		parts = append(parts, options.FieldSelector.String())
+	} else {
+		parts = append(parts, "")
+	}
+	return strings.Join(parts, "|"), nil
+}
+
+// snapshot returns the cached list for key, listing it from the backend if
+// it is missing or stale. Concurrent requests for the same key wait for a
+// single backend list.
+func (c *listCache) snapshot(ctx context.Context, key string, options *internalversion.ListOptions) (*listSnapshot, error) {
+	c.lock.Lock()
+	c.expire()
+	if snapshot, found := c.byKey[key]; found && c.clock.Since(snapshot.created) < c.options.TTL {
+		c.lock.Unlock()
+		return snapshot, nil
+	}
+	if fill, found := c.filling[key]; found {
+		c.lock.Unlock()
+		select {
+		case <-fill.done:
+			return fill.snapshot, fill.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	fill := &listFill{done: make(chan struct{})}
+	c.filling[key] = fill
+	c.lock.Unlock()
+
+	list, err := c.fill(ctx, options)
+	var id string
+	if err == nil {
+		id, err = newSnapshotID()
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.filling, key)
+	if err == nil {
+		c.nextSeq++
+		fill.snapshot = &listSnapshot{id: id, seq: c.nextSeq, key: key, created: c.clock.Now(), list: list}
+		c.byKey[key] = fill.snapshot
+		c.byID[fill.snapshot.id] = fill.snapshot
+		c.evict()
+	}
+	fill.err = err
+	close(fill.done)
+	return fill.snapshot, fill.err
+}
+
+// fill lists all pages from the backend.
+func (c *listCache) fill(ctx context.Context, options *internalversion.ListOptions) (*unstructured.UnstructuredList, error) {
+	pageOptions := options.DeepCopy()
+	pageOptions.ResourceVersion = ""
+	pageOptions.Limit = c.options.FillPageSize
+
+	var list *unstructured.UnstructuredList
+	for {
+		obj, err := c.delegate(ctx, pageOptions)
+		if err != nil {
+			return nil, err
+		}
+		page, ok := obj.(*unstructured.UnstructuredList)
+		if !ok {
+			return nil, fmt.Errorf("expected an UnstructuredList, got %T", obj)
+		}
+		if list == nil {
+			list = page
+		} else {
+			list.Items = append(list.Items, page.Items...)
+		}
+		if page.GetContinue() == "" {
+			break
+		}
+		pageOptions.Continue = page.GetContinue()
+	}
+	list.SetContinue("")
+	list.SetRemainingItemCount(nil)
+	return list, nil
+}
+
+// newSnapshotID returns a random ID, so that continue tokens cannot be
+// guessed.
+func newSnapshotID() (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// continueList serves the page of the continue token if it was issued for
+// a request with the same key.
+func (c *listCache) continueList(key, token string, limit int64) (runtime.Object, error) {
+	var cont listCacheContinue
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil {
+		err = json.Unmarshal(raw, &cont)
+	}
+	if err != nil || cont.Offset < 0 {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid continue token %q", ListCacheContinuePrefix+token))
+	}
+
+	c.lock.Lock()
+	c.expire()
+	snapshot, found := c.byID[cont.ID]
+	c.lock.Unlock()
+	if !found || snapshot.key != key || cont.Offset > len(snapshot.list.Items) {
+		return nil, apierrors.NewResourceExpired("the cached list has expired, please restart the list")
+	}
+	return c.page(snapshot, cont.Offset, limit), nil
+}
+
+// page returns the items of the snapshot starting at offset, up to limit
+// items if limit is positive.
+func (c *listCache) page(snapshot *listSnapshot, offset int, limit int64) *unstructured.UnstructuredList {
+	end := len(snapshot.list.Items)
+	if limit > 0 && int64(end-offset) > limit {
+		end = offset + int(limit)
+	}
+
+	page := &unstructured.UnstructuredList{Object: runtime.DeepCopyJSON(snapshot.list.Object)}
+	page.Items = make([]unstructured.Unstructured, 0, end-offset)
+	for i := range snapshot.list.Items[offset:end] {
+		page.Items = append(page.Items, *snapshot.list.Items[offset+i].DeepCopy())
+	}
+	if remaining := int64(len(snapshot.list.Items) - end); remaining > 0 {
+		raw, _ := json.Marshal(listCacheContinue{ID: snapshot.id, Offset: end})
+		page.SetContinue(ListCacheContinuePrefix + base64.RawURLEncoding.EncodeToString(raw))
+		page.SetRemainingItemCount(&remaining)
+	}
+	return page
+}
+
+// expire drops snapshots past their page retention. It must be called with
+// the lock held.
+func (c *listCache) expire() {
+	for id, snapshot := range c.byID {
+		if c.clock.Since(snapshot.created) >= c.options.PageRetention {
+			c.drop(id)
+		}
+	}
+}
+
+// evict drops the oldest snapshots beyond MaxEntries. It must be called
+// with the lock held.
+func (c *listCache) evict() {
+	for len(c.byID) > c.options.MaxEntries {
+		var oldest *listSnapshot
+		for _, snapshot := range c.byID {
+			if oldest == nil || snapshot.seq < oldest.seq {
+				oldest = snapshot
+			}
+		}
+		c.drop(oldest.id)
+	}
+}
+
+func (c *listCache) drop(id string) {
+	snapshot := c.byID[id]
+	delete(c.byID, id)
+	if c.byKey[snapshot.key] == snapshot {
+		delete(c.byKey, snapshot.key)
+	}
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package forwardingregistry
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/authentication/user"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+)
+
+// fakeBackend paginates a fixed list of ConfigMaps with continue tokens
+// holding the offset, and counts the objects it lists.
+type fakeBackend struct {
+	items  []unstructured.Unstructured
+	listed atomic.Int64
+	calls  atomic.Int64
+}
+
+func newFakeBackend(n int) *fakeBackend {
+	b := &fakeBackend{}
+	for i := range n {
+		obj := unstructured.Unstructured{}
+		obj.SetAPIVersion("v1")
+		obj.SetKind("ConfigMap")
+		obj.SetNamespace("default")
+		obj.SetName(fmt.Sprintf("cm-%05d", i))
+		obj.SetLabels(map[string]string{"app": "web"})
+		obj.Object["data"] = map[string]interface{}{"key": "value"}
+		b.items = append(b.items, obj)
+	}
+	return b
+}
+
+func (b *fakeBackend) list(_ context.Context, options *internalversion.ListOptions) (runtime.Object, error) {
+	b.calls.Add(1)
+	start := 0
+	if options.Continue != "" {
+		var err error
+		if start, err = strconv.Atoi(options.Continue); err != nil {
+			return nil, apierrors.NewBadRequest("invalid continue")
+		}
+	}
+	end := len(b.items)
+	if options.Limit > 0 && int64(end-start) > options.Limit {
+		end = start + int(options.Limit)
+	}
+	list := &unstructured.UnstructuredList{Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMapList"}}
+	list.SetResourceVersion("42")
+	for _, item := range b.items[start:end] {
+		list.Items = append(list.Items, *item.DeepCopy())
+	}
+	if end < len(b.items) {
+		list.SetContinue(strconv.Itoa(end))
+	}
+	b.listed.Add(int64(end - start))
+	return list, nil
+}
+
+func listContext(clusterName logicalcluster.Name) context.Context {
+	return userListContext(clusterName, "syncer")
+}
+
+func userListContext(clusterName logicalcluster.Name, userName string) context.Context {
+	ctx := genericapirequest.WithCluster(context.Background(), genericapirequest.Cluster{Name: clusterName})
+	ctx = genericapirequest.WithUser(ctx, &user.DefaultInfo{Name: userName, Groups: []string{"system:authenticated"}})
+	return genericapirequest.WithNamespace(ctx, "default")
+}
+
+// listAll lists all pages and returns the names of the items.
+func listAll(t testing.TB, list ListerFunc, ctx context.Context, options *internalversion.ListOptions) []string {
+	t.Helper()
+	var names []string
+	options = options.DeepCopy()
+	for {
+		obj, err := list(ctx, options)
+		require.NoError(t, err)
+		page := obj.(*unstructured.UnstructuredList)
+		for _, item := range page.Items {
+			names = append(names, item.GetName())
+		}
+		if page.GetContinue() == "" {
+			return names
+		}
+		options.Continue = page.GetContinue()
+	}
+}
+
+func TestListCache(t *testing.T) {
+	backend := newFakeBackend(25)
+	clock := clocktesting.NewFakePassiveClock(time.Now())
+	cache := newListCache(backend.list, ListCacheOptions{TTL: time.Second, PageRetention: time.Minute, FillPageSize: 10}, clock)
+	ctx := listContext("root:a")
+
+	names := listAll(t, cache.List, ctx, &internalversion.ListOptions{ResourceVersion: "0", Limit: 7})
+	require.Len(t, names, 25)
+	require.Equal(t, "cm-00000", names[0])
+	require.Equal(t, "cm-00024", names[24])
+	require.EqualValues(t, 3, backend.calls.Load(), "the cache is filled in pages of 10")
+
+	obj, err := cache.List(ctx, &internalversion.ListOptions{ResourceVersion: "0", Limit: 20})
+	require.NoError(t, err)
+	page := obj.(*unstructured.UnstructuredList)
+	require.Len(t, page.Items, 20)
+	require.Equal(t, "42", page.GetResourceVersion())
+	require.EqualValues(t, 5, *page.GetRemainingItemCount())
+	require.EqualValues(t, 3, backend.calls.Load(), "served from the cache")
+
+	listAll(t, cache.List, listContext("root:b"), &internalversion.ListOptions{ResourceVersion: "0"})
+	listAll(t, cache.List, ctx, &internalversion.ListOptions{ResourceVersion: "0", LabelSelector: labels.SelectorFromSet(labels.Set{"app": "web"})})
+	require.EqualValues(t, 9, backend.calls.Load(), "other workspaces and selectors are cached separately")
+
+	clock.SetTime(clock.Now().Add(2 * time.Second))
+	obj, err = cache.List(ctx, &internalversion.ListOptions{ResourceVersion: "0", Limit: 20, Continue: page.GetContinue()})
+	require.NoError(t, err, "continue tokens outlive the TTL")
+	require.Len(t, obj.(*unstructured.UnstructuredList).Items, 5)
+	listAll(t, cache.List, ctx, &internalversion.ListOptions{ResourceVersion: "0"})
+	require.EqualValues(t, 12, backend.calls.Load(), "stale lists are refreshed")
+
+	clock.SetTime(clock.Now().Add(time.Minute))
+	_, err = cache.List(ctx, &internalversion.ListOptions{Continue: page.GetContinue()})
+	require.True(t, apierrors.IsResourceExpired(err), "got %v", err)
+
+	names = listAll(t, cache.List, ctx, &internalversion.ListOptions{Limit: 10})
+	require.Len(t, names, 25)
+	require.EqualValues(t, 15, backend.calls.Load(), "consistent lists are paginated by the backend")
+}
+
+func TestListCacheContinueScope(t *testing.T) {
+	backend := newFakeBackend(10)
+	cache := newListCache(backend.list, ListCacheOptions{}, clocktesting.NewFakePassiveClock(time.Now()))
+	ctx := listContext("root:a")
+
+	obj, err := cache.List(ctx, &internalversion.ListOptions{ResourceVersion: "0", Limit: 5})
+	require.NoError(t, err)
+	token := obj.(*unstructured.UnstructuredList).GetContinue()
+	require.NotEmpty(t, token)
+
+	for name, ctx := range map[string]context.Context{
+		"other user":      userListContext("root:a", "mallory"),
+		"other workspace": listContext("root:b"),
+	} {
+		_, err := cache.List(ctx, &internalversion.ListOptions{Continue: token})
+		require.True(t, apierrors.IsResourceExpired(err), "%s: got %v", name, err)
+	}
+	_, err = cache.List(ctx, &internalversion.ListOptions{Continue: token, LabelSelector: labels.SelectorFromSet(labels.Set{"app": "web"})})
+	require.True(t, apierrors.IsResourceExpired(err), "other selectors: got %v", err)
+
+	obj, err = cache.List(ctx, &internalversion.ListOptions{Continue: token})
+	require.NoError(t, err)
+	require.Len(t, obj.(*unstructured.UnstructuredList).Items, 5)
+
+	for id := range cache.byID {
+		require.Len(t, id, 32, "IDs are random 128 bit values")
+	}
+}
+
+func TestListCacheEviction(t *testing.T) {
+	backend := newFakeBackend(1)
+	cache := newListCache(backend.list, ListCacheOptions{MaxEntries: 2}, clocktesting.NewFakePassiveClock(time.Now()))
+
+	for _, clusterName := range []logicalcluster.Name{"root:a", "root:b", "root:c", "root:a"} {
+		listAll(t, cache.List, listContext(clusterName), &internalversion.ListOptions{ResourceVersion: "0"})
+	}
+	require.EqualValues(t, 4, backend.calls.Load(), "the oldest list was evicted")
+	require.Len(t, cache.byID, 2)
+}
+
+// BenchmarkListCache measures listing a workspace with 50k objects in pages
+// of 500, as syncers do on reconnect.
+func BenchmarkListCache(b *testing.B) {
+	backend := newFakeBackend(50000)
+	ctx := listContext("root:a")
+	options := &internalversion.ListOptions{ResourceVersion: "0", Limit: 500}
+
+	b.Run("uncached", func(b *testing.B) {
+		backend.listed.Store(0)
+		b.ReportAllocs()
+		for range b.N {
+			listAll(b, backend.list, ctx, options)
+		}
+		b.ReportMetric(float64(backend.listed.Load())/float64(b.N), "backend-objects/op")
+	})
+	b.Run("cached", func(b *testing.B) {
+		cache := newListCache(backend.list, ListCacheOptions{TTL: time.Hour}, clocktesting.NewFakePassiveClock(time.Now()))
+		listAll(b, cache.List, ctx, options)
+		backend.listed.Store(0)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for range b.N {
+			listAll(b, cache.List, ctx, options)
+		}
+		b.ReportMetric(float64(backend.listed.Load())/float64(b.N), "backend-objects/op")
+	})
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	kcpkubernetesclientset "github.com/kcp-dev/client-go/kubernetes"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/authorization"
+	"github.com/kcp-dev/kcp/pkg/authorization/delegated"
+	"github.com/kcp-dev/kcp/pkg/virtual/framework"
+	"github.com/kcp-dev/kcp/pkg/virtual/framework/handler"
+	"github.com/kcp-dev/kcp/pkg/virtual/framework/rootapiserver"
+	"github.com/kcp-dev/kcp/pkg/virtual/onboarding"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// BuildVirtualWorkspace returns the onboarding virtual workspace.
+func BuildVirtualWorkspace(
+	rootPathPrefix string,
+	dynamicClusterClient kcpdynamic.ClusterInterface,
+	kubeClusterClient kcpkubernetesclientset.ClusterInterface,
+) []rootapiserver.NamedVirtualWorkspace {
+	if !strings.HasSuffix(rootPathPrefix, "/") {
+		rootPathPrefix += "/"
+	}
+
+	registrar := newRegistrar(dynamicClusterClient)
+	return []rootapiserver.NamedVirtualWorkspace{{
+		Name: onboarding.VirtualWorkspaceName,
+		VirtualWorkspace: &handler.VirtualWorkspace{
+			RootPathResolver: framework.RootPathResolverFunc(func(urlPath string, ctx context.Context) (accepted bool, prefixToStrip string, completedContext context.Context) {
+				cluster, prefixToStrip, ok := digestURL(urlPath, rootPathPrefix)
+				if !ok {
+					return false, "", ctx
+				}
+				return true, prefixToStrip, genericapirequest.WithCluster(ctx, cluster)
+			}),
+			Authorizer: newAuthorizer(kubeClusterClient),
+			ReadyChecker: framework.ReadyFunc(func() error {
+				return nil
+			}),
+			HandlerFactory: handler.HandlerFactory(func(genericapiserver.CompletedConfig) (http.Handler, error) {
+				return registrar, nil
+			}),
+		},
+	}}
+}
+
+// digestURL accepts paths like
+//
+//	/services/onboarding/clusters/<logical cluster>/registrations
+//	/services/onboarding/clusters/<logical cluster>/tokenregistrations
+//
+// of a single logical cluster, and returns the prefix up to it.
+func digestURL(urlPath, rootPathPrefix string) (genericapirequest.Cluster, string, bool) {
+	rest, found := strings.CutPrefix(urlPath, rootPathPrefix+"clusters/")
+	if !found {
+		return genericapirequest.Cluster{}, "", false
+	}
+	parts := strings.SplitN(rest, "/", 2)
+	if len(parts) < 2 || !(strings.HasPrefix(parts[1], "registrations") || parts[1] == "tokenregistrations") {
+		return genericapirequest.Cluster{}, "", false
+	}
+	name := logicalcluster.Name(parts[0])
+	if !name.IsValid() {
+		return genericapirequest.Cluster{}, "", false
+	}
+	return genericapirequest.Cluster{Name: name}, rootPathPrefix + "clusters/" + parts[0], true
+}
+
+// registrationAuthorizer allows requests of users that may create the
+// registration subresource of synctargets in the workspace. Token
+// registrations are allowed for everyone, the bootstrap token is checked,
+// and their rate limited, by the handler.
+type registrationAuthorizer struct {
+	newDelegatedAuthorizer func(clusterName logicalcluster.Name) (authorizer.Authorizer, error)
+}
+
+func newAuthorizer(kubeClusterClient kcpkubernetesclientset.ClusterInterface) authorizer.Authorizer {
+	var a authorizer.Authorizer = &registrationAuthorizer{
+		newDelegatedAuthorizer: func(clusterName logicalcluster.Name) (authorizer.Authorizer, error) {
+			return delegated.NewDelegatedAuthorizer(clusterName, kubeClusterClient, delegated.Options{})
+		},
+	}
+	return authorization.NewDecorator("virtual.onboarding.authorization.kcp.io", a).AddAuditLogging().AddAnonymization().AddReasonAnnotation()
+}
+
+func (a *registrationAuthorizer) Authorize(ctx context.Context, attr authorizer.Attributes) (authorizer.Decision, string, error) {
+	cluster, err := genericapirequest.ValidClusterFrom(ctx)
+	if err != nil {
+		return authorizer.DecisionNoOpinion, "", fmt.Errorf("error getting valid cluster from context: %w", err)
+	}
+	// The path is relative to the logical cluster.
+	if attr.GetPath() == tokenRegistrationsPath && attr.GetVerb() == "post" {
+		return authorizer.DecisionAllow, "token registration", nil
+	}
+	authz, err := a.newDelegatedAuthorizer(cluster.Name)
+	if err != nil {
+		return authorizer.DecisionNoOpinion, "", err
+	}
+	dec, reason, err := authz.Authorize(ctx, authorizer.AttributesRecord{
+		User:            attr.GetUser(),
+		Verb:            "create",
+		APIGroup:        tmcv1alpha1.SchemeGroupVersion.Group,
+		APIVersion:      tmcv1alpha1.SchemeGroupVersion.Version,
+		Resource:        "synctargets",
+		Subresource:     "registration",
+		ResourceRequest: true,
+	})
+	if err != nil {
+		return authorizer.DecisionNoOpinion, "", fmt.Errorf("error authorizing registration in workspace %q: %w", cluster.Name, err)
+	}
+	if dec == authorizer.DecisionAllow {
+		return authorizer.DecisionAllow, fmt.Sprintf("workspace: %q RBAC decision: %v", cluster.Name, reason), nil
+	}
+	return authorizer.DecisionDeny, fmt.Sprintf("workspace: %q RBAC decision: %v", cluster.Name, reason), nil
+}
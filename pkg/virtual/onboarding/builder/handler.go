@@ -0,0 +1,485 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/wait"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/klog/v2"
+
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/clusterprofile"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/onboarding"
+	onboardingvw "github.com/kcp-dev/kcp/pkg/virtual/onboarding"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+// maxRequestBytes bounds the body of signed requests.
+const maxRequestBytes = 64 * 1024
+
+const (
+	// tokenBootstrapTimeout bounds how long token registrations wait for
+	// the bootstrap manifests before they are answered with the
+	// registration status, and retried.
+	tokenBootstrapTimeout = 30 * time.Second
+	tokenPollInterval     = time.Second
+
+	// tokenRegistrationQPS and tokenRegistrationBurst limit the token
+	// registrations of all workspaces, which are not authenticated.
+	tokenRegistrationQPS   = 5
+	tokenRegistrationBurst = 20
+)
+
+// tokenRegistrationsPath is the path of token registrations below the
+// logical cluster.
+const tokenRegistrationsPath = "/tokenregistrations"
+
+var (
+	secretsGVR         = corev1.SchemeGroupVersion.WithResource("secrets")
+	bootstrapTokensGVR = tmcv1alpha1.SchemeGroupVersion.WithResource("synctargetbootstraptokens")
+)
+
+// registrar serves registration and bootstrap requests.
+type registrar struct {
+	now              func() time.Time
+	nonces           *nonces
+	pollInterval     time.Duration
+	bootstrapTimeout time.Duration
+	// tokenRegistrations limits the rate of token registrations.
+	tokenRegistrations *rate.Limiter
+
+	getSyncTarget        func(ctx context.Context, clusterName logicalcluster.Name, name string) (*tmcv1alpha1.SyncTarget, error)
+	createSyncTarget     func(ctx context.Context, clusterName logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) error
+	getManifests         func(ctx context.Context, clusterName logicalcluster.Name, syncTarget string) ([]byte, error)
+	getBootstrapToken    func(ctx context.Context, clusterName logicalcluster.Name, name string) (*tmcv1alpha1.SyncTargetBootstrapToken, error)
+	updateBootstrapToken func(ctx context.Context, clusterName logicalcluster.Name, token *tmcv1alpha1.SyncTargetBootstrapToken) error
+}
+
+func newRegistrar(dynamicClusterClient kcpdynamic.ClusterInterface) *registrar {
+	return &registrar{
+		now:                time.Now,
+		nonces:             newNonces(),
+		pollInterval:       tokenPollInterval,
+		bootstrapTimeout:   tokenBootstrapTimeout,
+		tokenRegistrations: rate.NewLimiter(tokenRegistrationQPS, tokenRegistrationBurst),
+		getSyncTarget: func(ctx context.Context, clusterName logicalcluster.Name, name string) (*tmcv1alpha1.SyncTarget, error) {
+			u, err := dynamicClusterClient.Cluster(clusterName.Path()).Resource(clusterprofile.SyncTargetsGVR).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			syncTarget := &tmcv1alpha1.SyncTarget{}
+			return syncTarget, runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, syncTarget)
+		},
+		createSyncTarget: func(ctx context.Context, clusterName logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) error {
+			raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(syncTarget)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(clusterprofile.SyncTargetsGVR).Create(ctx, &unstructured.Unstructured{Object: raw}, metav1.CreateOptions{})
+			return err
+		},
+		getManifests: func(ctx context.Context, clusterName logicalcluster.Name, syncTarget string) ([]byte, error) {
+			u, err := dynamicClusterClient.Cluster(clusterName.Path()).Resource(secretsGVR).Namespace(onboarding.BootstrapNamespace).Get(ctx, onboarding.BootstrapSecretName(syncTarget), metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				return nil, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			secret := &corev1.Secret{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, secret); err != nil {
+				return nil, err
+			}
+			return secret.Data[onboarding.ManifestsKey], nil
+		},
+		getBootstrapToken: func(ctx context.Context, clusterName logicalcluster.Name, name string) (*tmcv1alpha1.SyncTargetBootstrapToken, error) {
+			u, err := dynamicClusterClient.Cluster(clusterName.Path()).Resource(bootstrapTokensGVR).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			token := &tmcv1alpha1.SyncTargetBootstrapToken{}
+			return token, runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, token)
+		},
+		updateBootstrapToken: func(ctx context.Context, clusterName logicalcluster.Name, token *tmcv1alpha1.SyncTargetBootstrapToken) error {
+			raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(token)
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName.Path()).Resource(bootstrapTokensGVR).UpdateStatus(ctx, &unstructured.Unstructured{Object: raw}, metav1.UpdateOptions{})
+			return err
+		},
+	}
+}
+
+func (r *registrar) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	cluster, err := genericapirequest.ValidClusterFrom(req.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.URL.Path == tokenRegistrationsPath {
+		if !r.tokenRegistrations.Allow() {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "too many token registrations, retry later", http.StatusTooManyRequests)
+			return
+		}
+		r.registerWithToken(w, req, cluster.Name)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+
+	signed := &onboardingvw.SignedRequest{}
+	if err := json.NewDecoder(io.LimitReader(req.Body, maxRequestBytes)).Decode(signed); err != nil {
+		http.Error(w, fmt.Sprintf("invalid signed request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && parts[0] == "registrations":
+		r.register(w, req, cluster.Name, signed)
+	case len(parts) == 3 && parts[0] == "registrations" && parts[2] == "bootstrap":
+		r.bootstrap(w, req, cluster.Name, parts[1], signed)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func (r *registrar) register(w http.ResponseWriter, req *http.Request, clusterName logicalcluster.Name, signed *onboardingvw.SignedRequest) {
+	ctx := req.Context()
+
+	// The public key is taken from the payload, so the signature proves
+	// possession of the private key before anything else is looked at.
+	var unverified onboardingvw.Registration
+	if err := json.Unmarshal(signed.Payload, &unverified); err != nil {
+		http.Error(w, fmt.Sprintf("invalid registration: %v", err), http.StatusBadRequest)
+		return
+	}
+	var registration onboardingvw.Registration
+	if err := onboardingvw.Verify(signed, unverified.PublicKey, &registration); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err := onboardingvw.CheckTimestamp(registration.Timestamp, r.now()); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if !r.useNonce(w, registration.Nonce, registration.Timestamp) {
+		return
+	}
+	if errs := validateRegistration(registration.Name, registration.Labels); len(errs) > 0 {
+		http.Error(w, errs.ToAggregate().Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	existing, err := r.getSyncTarget(ctx, clusterName, registration.Name)
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	case existing.Spec.Registration != nil && existing.Spec.Registration.PublicKey == registration.PublicKey:
+		// Retried registrations are answered with their current state.
+		r.writeStatus(w, http.StatusOK, existing)
+		return
+	default:
+		http.Error(w, fmt.Sprintf("SyncTarget %q already exists", registration.Name), http.StatusConflict)
+		return
+	}
+
+	requestedBy := ""
+	if user, ok := genericapirequest.UserFrom(ctx); ok {
+		requestedBy = user.GetName()
+	}
+	syncTarget := &tmcv1alpha1.SyncTarget{
+		TypeMeta:   metav1.TypeMeta{APIVersion: tmcv1alpha1.SchemeGroupVersion.String(), Kind: "SyncTarget"},
+		ObjectMeta: metav1.ObjectMeta{Name: registration.Name, Labels: registration.Labels},
+		Spec: tmcv1alpha1.SyncTargetSpec{
+			Location: registration.Location,
+			Registration: &tmcv1alpha1.SyncTargetRegistration{
+				PublicKey:   registration.PublicKey,
+				RequestedBy: requestedBy,
+			},
+		},
+	}
+	if err := r.createSyncTarget(ctx, clusterName, syncTarget); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			http.Error(w, fmt.Sprintf("SyncTarget %q already exists", registration.Name), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	klog.FromContext(ctx).Info("SyncTarget registered, pending approval", "cluster", clusterName, "syncTarget", registration.Name, "requestedBy", requestedBy)
+	r.writeStatus(w, http.StatusCreated, syncTarget)
+}
+
+func (r *registrar) bootstrap(w http.ResponseWriter, req *http.Request, clusterName logicalcluster.Name, name string, signed *onboardingvw.SignedRequest) {
+	ctx := req.Context()
+
+	syncTarget, err := r.getSyncTarget(ctx, clusterName, name)
+	if apierrors.IsNotFound(err) || (err == nil && syncTarget.Spec.Registration == nil) {
+		http.Error(w, fmt.Sprintf("no registration of SyncTarget %q", name), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if syncTarget.Spec.Registration.PublicKey == "" {
+		http.Error(w, fmt.Sprintf("SyncTarget %q was registered with a bootstrap token", name), http.StatusForbidden)
+		return
+	}
+	var bootstrap onboardingvw.BootstrapRequest
+	if err := onboardingvw.Verify(signed, syncTarget.Spec.Registration.PublicKey, &bootstrap); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err := onboardingvw.CheckTimestamp(bootstrap.Timestamp, r.now()); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if bootstrap.Name != name {
+		http.Error(w, fmt.Sprintf("request is signed for SyncTarget %q", bootstrap.Name), http.StatusForbidden)
+		return
+	}
+	if !r.useNonce(w, bootstrap.Nonce, bootstrap.Timestamp) {
+		return
+	}
+
+	if !syncTarget.Spec.Registration.Approved {
+		r.writeStatus(w, http.StatusAccepted, syncTarget)
+		return
+	}
+	manifests, err := r.getManifests(ctx, clusterName, name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(manifests) == 0 {
+		// Approved, but the manifests are not generated yet.
+		r.writeStatus(w, http.StatusAccepted, syncTarget)
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	_, _ = w.Write(manifests)
+}
+
+// registerWithToken registers an approved SyncTarget for the holder of a
+// bootstrap token and answers with its bootstrap manifests. Requests are
+// idempotent, so that those answered before the manifests were generated
+// can be retried. Only the request creating the SyncTarget waits for the
+// manifests, retries are answered with what is there.
+func (r *registrar) registerWithToken(w http.ResponseWriter, req *http.Request, clusterName logicalcluster.Name) {
+	ctx := req.Context()
+
+	var registration onboardingvw.TokenRegistration
+	if err := json.NewDecoder(io.LimitReader(req.Body, maxRequestBytes)).Decode(&registration); err != nil {
+		http.Error(w, fmt.Sprintf("invalid token registration: %v", err), http.StatusBadRequest)
+		return
+	}
+	tokenName, secret, err := onboardingvw.ParseBootstrapToken(registration.Token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	token, err := r.getBootstrapToken(ctx, clusterName, tokenName)
+	if apierrors.IsNotFound(err) || (err == nil && !onboardingvw.CheckBootstrapTokenSecret(secret, token.Spec.SecretHash)) {
+		http.Error(w, "invalid bootstrap token", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if token.Spec.Expiration != nil && !r.now().Before(token.Spec.Expiration.Time) {
+		http.Error(w, "bootstrap token is expired", http.StatusForbidden)
+		return
+	}
+	errs := validateRegistration(registration.Name, registration.Labels)
+	if !strings.HasPrefix(registration.Name, token.Spec.NamePrefix) {
+		errs = append(errs, field.Invalid(field.NewPath("name"), registration.Name, fmt.Sprintf("must start with %q", token.Spec.NamePrefix)))
+	}
+	for _, key := range sets.List(sets.KeySet(registration.Labels)) {
+		if !slices.Contains(token.Spec.AllowedLabels, key) {
+			errs = append(errs, field.Forbidden(field.NewPath("labels").Key(key), "not allowed by the bootstrap token"))
+		}
+	}
+	if len(errs) > 0 {
+		http.Error(w, errs.ToAggregate().Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	created := false
+	syncTarget, err := r.getSyncTarget(ctx, clusterName, registration.Name)
+	switch {
+	case apierrors.IsNotFound(err):
+		if syncTarget, err = r.createWithToken(ctx, clusterName, token, &registration); err != nil {
+			var status apierrors.APIStatus
+			if errors.As(err, &status) {
+				http.Error(w, err.Error(), int(status.Status().Code))
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		created = true
+		klog.FromContext(ctx).Info("SyncTarget registered with bootstrap token", "cluster", clusterName, "syncTarget", registration.Name, "bootstrapToken", tokenName)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	case syncTarget.Spec.Registration == nil || syncTarget.Spec.Registration.BootstrapToken != tokenName:
+		http.Error(w, fmt.Sprintf("SyncTarget %q already exists", registration.Name), http.StatusConflict)
+		return
+	}
+
+	// The onboarding controller mints the credentials of the syncer and
+	// renders the manifests.
+	manifests, err := r.getManifests(ctx, clusterName, registration.Name)
+	if err == nil && len(manifests) == 0 && created {
+		err = wait.PollUntilContextTimeout(ctx, r.pollInterval, r.bootstrapTimeout, false, func(ctx context.Context) (bool, error) {
+			var err error
+			manifests, err = r.getManifests(ctx, clusterName, registration.Name)
+			return len(manifests) > 0, err
+		})
+	}
+	if err != nil && !wait.Interrupted(err) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(manifests) == 0 {
+		r.writeStatus(w, http.StatusAccepted, syncTarget)
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	_, _ = w.Write(manifests)
+}
+
+// createWithToken records the registration in the status of token, which
+// fails on concurrent registrations, and creates the approved SyncTarget.
+// The labels of registration are allowed by token.
+func (r *registrar) createWithToken(ctx context.Context, clusterName logicalcluster.Name, token *tmcv1alpha1.SyncTargetBootstrapToken, registration *onboardingvw.TokenRegistration) (*tmcv1alpha1.SyncTarget, error) {
+	if !slices.Contains(token.Status.Registrations, registration.Name) {
+		if int32(len(token.Status.Registrations)) >= token.Spec.Limit() {
+			return nil, apierrors.NewForbidden(bootstrapTokensGVR.GroupResource(), token.Name, errors.New("bootstrap token is used up"))
+		}
+		token = token.DeepCopy()
+		token.Status.Registrations = append(token.Status.Registrations, registration.Name)
+		if err := r.updateBootstrapToken(ctx, clusterName, token); err != nil {
+			return nil, err
+		}
+	}
+
+	labels := map[string]string{}
+	for k, v := range registration.Labels {
+		labels[k] = v
+	}
+	for k, v := range token.Spec.Labels {
+		labels[k] = v
+	}
+	location := registration.Location
+	if token.Spec.Location != "" {
+		location = token.Spec.Location
+	}
+	requestedBy := ""
+	if user, ok := genericapirequest.UserFrom(ctx); ok {
+		requestedBy = user.GetName()
+	}
+	syncTarget := &tmcv1alpha1.SyncTarget{
+		TypeMeta:   metav1.TypeMeta{APIVersion: tmcv1alpha1.SchemeGroupVersion.String(), Kind: "SyncTarget"},
+		ObjectMeta: metav1.ObjectMeta{Name: registration.Name, Labels: labels},
+		Spec: tmcv1alpha1.SyncTargetSpec{
+			Location: location,
+			Registration: &tmcv1alpha1.SyncTargetRegistration{
+				BootstrapToken: token.Name,
+				RequestedBy:    requestedBy,
+				Approved:       true,
+			},
+		},
+	}
+	if err := r.createSyncTarget(ctx, clusterName, syncTarget); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return nil, apierrors.NewConflict(clusterprofile.SyncTargetsGVR.GroupResource(), registration.Name, err)
+		}
+		return nil, err
+	}
+	return syncTarget, nil
+}
+
+// useNonce records the nonce of a verified request, answering the request
+// and returning false if it must not be served.
+func (r *registrar) useNonce(w http.ResponseWriter, nonce string, timestamp time.Time) bool {
+	err := r.nonces.use(nonce, timestamp, r.now())
+	switch {
+	case errors.Is(err, errTooManyNonces):
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return false
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+func (r *registrar) writeStatus(w http.ResponseWriter, code int, syncTarget *tmcv1alpha1.SyncTarget) {
+	fingerprint, _ := onboardingvw.Fingerprint(syncTarget.Spec.Registration.PublicKey)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(onboardingvw.RegistrationStatus{
+		Name:        syncTarget.Name,
+		Fingerprint: fingerprint,
+		Approved:    syncTarget.Spec.Registration.Approved,
+	})
+}
+
+func validateRegistration(name string, labels map[string]string) field.ErrorList {
+	var errs field.ErrorList
+	for _, msg := range validation.IsDNS1123Subdomain(name) {
+		errs = append(errs, field.Invalid(field.NewPath("name"), name, msg))
+	}
+	errs = append(errs, metav1validation.ValidateLabels(labels, field.NewPath("labels"))...)
+	return errs
+}
@@ -0,0 +1,392 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	onboardingvw "github.com/kcp-dev/kcp/pkg/virtual/onboarding"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+)
+
+func TestRegistrar(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	publicKey, err := onboardingvw.EncodePublicKey(key.Public())
+	require.NoError(t, err)
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	targets := map[string]*tmcv1alpha1.SyncTarget{}
+	manifests := map[string][]byte{}
+	r := &registrar{
+		now:    func() time.Time { return now },
+		nonces: newNonces(),
+		getSyncTarget: func(_ context.Context, _ logicalcluster.Name, name string) (*tmcv1alpha1.SyncTarget, error) {
+			if t, ok := targets[name]; ok {
+				return t.DeepCopy(), nil
+			}
+			return nil, apierrors.NewNotFound(tmcv1alpha1.Resource("synctargets"), name)
+		},
+		createSyncTarget: func(_ context.Context, _ logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) error {
+			targets[syncTarget.Name] = syncTarget.DeepCopy()
+			return nil
+		},
+		getManifests: func(_ context.Context, _ logicalcluster.Name, name string) ([]byte, error) {
+			return manifests[name], nil
+		},
+	}
+
+	// do signs payload with a fresh nonce, unless nonce is set.
+	do := func(path string, payload interface{}, signer ed25519.PrivateKey) *httptest.ResponseRecorder {
+		nonce, err := onboardingvw.NewNonce()
+		require.NoError(t, err)
+		switch p := payload.(type) {
+		case onboardingvw.Registration:
+			if p.Nonce == "" {
+				p.Nonce = nonce
+			}
+			payload = p
+		case onboardingvw.BootstrapRequest:
+			if p.Nonce == "" {
+				p.Nonce = nonce
+			}
+			payload = p
+		}
+		signed, err := onboardingvw.Sign(payload, signer)
+		require.NoError(t, err)
+		body, err := json.Marshal(signed)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		ctx := genericapirequest.WithCluster(req.Context(), genericapirequest.Cluster{Name: "root:org"})
+		ctx = genericapirequest.WithUser(ctx, &user.DefaultInfo{Name: "admin@east"})
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req.WithContext(ctx))
+		return rec
+	}
+	registration := onboardingvw.Registration{Name: "east", Location: "us-east", Labels: map[string]string{"region": "us-east"}, PublicKey: publicKey, Timestamp: now}
+
+	// Signed with a key other than the one in the payload.
+	rec := do("/registrations", registration, otherKey)
+	require.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+
+	stale := registration
+	stale.Timestamp = now.Add(-time.Hour)
+	rec = do("/registrations", stale, key)
+	require.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+
+	invalid := registration
+	invalid.Name = "East_1"
+	rec = do("/registrations", invalid, key)
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+
+	rec = do("/registrations", registration, key)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	require.Equal(t, "us-east", targets["east"].Spec.Location)
+	require.Equal(t, "admin@east", targets["east"].Spec.Registration.RequestedBy)
+	require.False(t, targets["east"].Spec.Registration.Approved)
+
+	// Retries are idempotent, other keys conflict.
+	rec = do("/registrations", registration, key)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	otherPublicKey, err := onboardingvw.EncodePublicKey(otherKey.Public())
+	require.NoError(t, err)
+	takeover := registration
+	takeover.PublicKey = otherPublicKey
+	rec = do("/registrations", takeover, otherKey)
+	require.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+
+	bootstrap := onboardingvw.BootstrapRequest{Name: "east", Timestamp: now}
+	rec = do("/registrations/east/bootstrap", bootstrap, otherKey)
+	require.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+	rec = do("/registrations/west/bootstrap", bootstrap, key)
+	require.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+
+	rec = do("/registrations/east/bootstrap", bootstrap, key)
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	var status onboardingvw.RegistrationStatus
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	require.False(t, status.Approved)
+	fingerprint, err := onboardingvw.Fingerprint(publicKey)
+	require.NoError(t, err)
+	require.Equal(t, fingerprint, status.Fingerprint)
+
+	targets["east"].Spec.Registration.Approved = true
+	rec = do("/registrations/east/bootstrap", bootstrap, key)
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+
+	manifests["east"] = []byte("kind: Namespace\n")
+	captured := bootstrap
+	captured.Nonce = "captured"
+	rec = do("/registrations/east/bootstrap", captured, key)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Equal(t, "application/yaml", rec.Header().Get("Content-Type"))
+	require.Equal(t, "kind: Namespace\n", rec.Body.String())
+
+	// Captured requests cannot be replayed, not even for other names.
+	rec = do("/registrations/east/bootstrap", captured, key)
+	require.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+	require.Contains(t, rec.Body.String(), "nonce was used before")
+	reused := registration
+	reused.Nonce = "captured"
+	rec = do("/registrations", reused, key)
+	require.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+}
+
+func TestRegistrarToken(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	secret, err := onboardingvw.NewBootstrapTokenSecret()
+	require.NoError(t, err)
+
+	tokens := map[string]*tmcv1alpha1.SyncTargetBootstrapToken{
+		"edge": {
+			ObjectMeta: metav1.ObjectMeta{Name: "edge"},
+			Spec: tmcv1alpha1.SyncTargetBootstrapTokenSpec{
+				SecretHash:    onboardingvw.HashBootstrapTokenSecret(secret),
+				Expiration:    &metav1.Time{Time: now.Add(time.Hour)},
+				UsageLimit:    2,
+				NamePrefix:    "edge-",
+				Location:      "edge",
+				Labels:        map[string]string{"tier": "edge"},
+				AllowedLabels: []string{"tier", "zone"},
+			},
+		},
+	}
+	targets := map[string]*tmcv1alpha1.SyncTarget{"edge-taken": {ObjectMeta: metav1.ObjectMeta{Name: "edge-taken"}}}
+	manifests := map[string][]byte{}
+	manifestReads := 0
+	r := &registrar{
+		now:                func() time.Time { return now },
+		nonces:             newNonces(),
+		pollInterval:       time.Millisecond,
+		bootstrapTimeout:   10 * time.Millisecond,
+		tokenRegistrations: rate.NewLimiter(rate.Inf, 0),
+		getSyncTarget: func(_ context.Context, _ logicalcluster.Name, name string) (*tmcv1alpha1.SyncTarget, error) {
+			if t, ok := targets[name]; ok {
+				return t.DeepCopy(), nil
+			}
+			return nil, apierrors.NewNotFound(tmcv1alpha1.Resource("synctargets"), name)
+		},
+		createSyncTarget: func(_ context.Context, _ logicalcluster.Name, syncTarget *tmcv1alpha1.SyncTarget) error {
+			targets[syncTarget.Name] = syncTarget.DeepCopy()
+			return nil
+		},
+		getManifests: func(_ context.Context, _ logicalcluster.Name, name string) ([]byte, error) {
+			manifestReads++
+			return manifests[name], nil
+		},
+		getBootstrapToken: func(_ context.Context, _ logicalcluster.Name, name string) (*tmcv1alpha1.SyncTargetBootstrapToken, error) {
+			if t, ok := tokens[name]; ok {
+				return t.DeepCopy(), nil
+			}
+			return nil, apierrors.NewNotFound(tmcv1alpha1.Resource("synctargetbootstraptokens"), name)
+		},
+		updateBootstrapToken: func(_ context.Context, _ logicalcluster.Name, token *tmcv1alpha1.SyncTargetBootstrapToken) error {
+			tokens[token.Name] = token.DeepCopy()
+			return nil
+		},
+	}
+
+	do := func(registration onboardingvw.TokenRegistration) *httptest.ResponseRecorder {
+		body, err := json.Marshal(registration)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, "/tokenregistrations", bytes.NewReader(body))
+		ctx := genericapirequest.WithCluster(req.Context(), genericapirequest.Cluster{Name: "root:org"})
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req.WithContext(ctx))
+		return rec
+	}
+	registration := onboardingvw.TokenRegistration{Token: "edge." + secret, Name: "edge-1", Location: "us-east", Labels: map[string]string{"tier": "cloud", "zone": "a"}}
+
+	for _, token := range []string{"edge.wrong", "other." + secret} {
+		invalid := registration
+		invalid.Token = token
+		rec := do(invalid)
+		require.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+	}
+	unprefixed := registration
+	unprefixed.Name = "cloud-1"
+	rec := do(unprefixed)
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+	unallowed := registration
+	unallowed.Labels = map[string]string{"zone": "a", "gpu": "true"}
+	rec = do(unallowed)
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+	require.Contains(t, rec.Body.String(), "labels[gpu]")
+	require.NotContains(t, targets, "edge-1")
+	taken := registration
+	taken.Name = "edge-taken"
+	rec = do(taken)
+	require.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+
+	rec = do(registration)
+	require.Equal(t, http.StatusAccepted, rec.Code, "the manifests are not generated yet: %s", rec.Body.String())
+	syncTarget := targets["edge-1"]
+	require.Equal(t, "edge", syncTarget.Spec.Location)
+	require.Equal(t, map[string]string{"tier": "edge", "zone": "a"}, syncTarget.Labels)
+	require.True(t, syncTarget.Spec.Registration.Approved)
+	require.Equal(t, "edge", syncTarget.Spec.Registration.BootstrapToken)
+	require.Equal(t, []string{"edge-1"}, tokens["edge"].Status.Registrations)
+	require.Greater(t, manifestReads, 1, "the registration waits for the manifests")
+
+	// Retries do not wait for the manifests.
+	manifestReads = 0
+	rec = do(registration)
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	require.Equal(t, 1, manifestReads)
+
+	// Retries do not use the token up.
+	manifests["edge-1"] = []byte("kind: Namespace\n")
+	rec = do(registration)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Equal(t, "kind: Namespace\n", rec.Body.String())
+	require.Equal(t, []string{"edge-1"}, tokens["edge"].Status.Registrations)
+
+	second := registration
+	second.Name = "edge-2"
+	rec = do(second)
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	third := registration
+	third.Name = "edge-3"
+	rec = do(third)
+	require.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+	require.Contains(t, rec.Body.String(), "used up")
+
+	now = now.Add(2 * time.Hour)
+	rec = do(registration)
+	require.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+	require.Contains(t, rec.Body.String(), "expired")
+
+	// Token registrations are not served by the signed bootstrap requests.
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signed, err := onboardingvw.Sign(onboardingvw.BootstrapRequest{Name: "edge-1", Timestamp: now, Nonce: "n"}, key)
+	require.NoError(t, err)
+	body, err := json.Marshal(signed)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/registrations/edge-1/bootstrap", bytes.NewReader(body))
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req.WithContext(genericapirequest.WithCluster(req.Context(), genericapirequest.Cluster{Name: "root:org"})))
+	require.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+	require.Contains(t, rec.Body.String(), "bootstrap token")
+}
+
+func TestRegistrarLimitsTokenRegistrations(t *testing.T) {
+	r := &registrar{tokenRegistrations: rate.NewLimiter(rate.Every(time.Hour), 1)}
+	do := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/tokenregistrations", bytes.NewReader([]byte("{")))
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req.WithContext(genericapirequest.WithCluster(req.Context(), genericapirequest.Cluster{Name: "root:org"})))
+		return rec
+	}
+
+	rec := do()
+	require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+	rec = do()
+	require.Equal(t, http.StatusTooManyRequests, rec.Code, rec.Body.String())
+	require.Equal(t, "1", rec.Header().Get("Retry-After"))
+}
+
+func TestAuthorizeTokenRegistrations(t *testing.T) {
+	a := &registrationAuthorizer{
+		newDelegatedAuthorizer: func(logicalcluster.Name) (authorizer.Authorizer, error) {
+			return authorizer.AuthorizerFunc(func(context.Context, authorizer.Attributes) (authorizer.Decision, string, error) {
+				return authorizer.DecisionDeny, "denied", nil
+			}), nil
+		},
+	}
+	ctx := genericapirequest.WithCluster(context.Background(), genericapirequest.Cluster{Name: "root:org"})
+	anonymous := &user.DefaultInfo{Name: user.Anonymous}
+
+	tests := map[string]struct {
+		verb string
+		path string
+		want authorizer.Decision
+	}{
+		"token registration":      {verb: "post", path: "/tokenregistrations", want: authorizer.DecisionAllow},
+		"other verb":              {verb: "get", path: "/tokenregistrations", want: authorizer.DecisionDeny},
+		"suffix of another path":  {verb: "post", path: "/registrations/tokenregistrations", want: authorizer.DecisionDeny},
+		"signed registration":     {verb: "post", path: "/registrations", want: authorizer.DecisionDeny},
+		"trailing path separator": {verb: "post", path: "/tokenregistrations/", want: authorizer.DecisionDeny},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			dec, _, err := a.Authorize(ctx, authorizer.AttributesRecord{User: anonymous, Verb: tt.verb, Path: tt.path})
+			require.NoError(t, err)
+			require.Equal(t, tt.want, dec)
+		})
+	}
+}
+
+func TestNonces(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	n := newNonces()
+
+	require.Error(t, n.use("", now, now), "nonces are required")
+	require.NoError(t, n.use("a", now, now))
+	require.ErrorIs(t, n.use("a", now, now.Add(onboardingvw.MaxClockSkew)), errNonceUsed)
+	// Once the timestamp expired, the request is rejected by its timestamp.
+	require.NoError(t, n.use("a", now, now.Add(onboardingvw.MaxClockSkew+time.Second)))
+
+	n.expiries = map[string]time.Time{}
+	for i := range maxNonces {
+		n.expiries[fmt.Sprintf("n%d", i)] = now.Add(time.Duration(i%2) * time.Hour)
+	}
+	require.NoError(t, n.use("b", now, now.Add(time.Minute)), "expired nonces are forgotten")
+	require.Len(t, n.expiries, maxNonces/2+1)
+	for i := range maxNonces {
+		n.expiries[fmt.Sprintf("m%d", i)] = now.Add(time.Hour)
+	}
+	require.ErrorIs(t, n.use("c", now, now), errTooManyNonces)
+}
+
+func TestDigestURL(t *testing.T) {
+	cluster, prefix, ok := digestURL("/services/onboarding/clusters/2v7ac4kj0nl5jzpm/registrations/east/bootstrap", "/services/onboarding/")
+	require.True(t, ok)
+	require.Equal(t, logicalcluster.Name("2v7ac4kj0nl5jzpm"), cluster.Name)
+	require.Equal(t, "/services/onboarding/clusters/2v7ac4kj0nl5jzpm", prefix)
+
+	_, prefix, ok = digestURL("/services/onboarding/clusters/2v7ac4kj0nl5jzpm/tokenregistrations", "/services/onboarding/")
+	require.True(t, ok)
+	require.Equal(t, "/services/onboarding/clusters/2v7ac4kj0nl5jzpm", prefix)
+
+	_, _, ok = digestURL("/services/onboarding/clusters/*/registrations", "/services/onboarding/")
+	require.False(t, ok)
+	_, _, ok = digestURL("/services/onboarding/clusters/2v7ac4kj0nl5jzpm/synctargets", "/services/onboarding/")
+	require.False(t, ok)
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	onboardingvw "github.com/kcp-dev/kcp/pkg/virtual/onboarding"
+)
+
+// maxNonces bounds the nonces recorded at once.
+const maxNonces = 100000
+
+var (
+	errNonceUsed     = errors.New("request nonce was used before")
+	errTooManyNonces = errors.New("too many signed requests, retry later")
+)
+
+// nonces records the nonces of accepted requests until their timestamp
+// expires, after which the requests are rejected by their timestamp. Nonces
+// are recorded in memory, per server, which is why MaxClockSkew is short.
+type nonces struct {
+	lock sync.Mutex
+	// expiries are the times after which the nonces can be forgotten.
+	expiries map[string]time.Time
+}
+
+func newNonces() *nonces {
+	return &nonces{expiries: map[string]time.Time{}}
+}
+
+// use records the nonce of a request signed at timestamp, failing if it was
+// used before.
+func (n *nonces) use(nonce string, timestamp, now time.Time) error {
+	if err := onboardingvw.CheckNonce(nonce); err != nil {
+		return err
+	}
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if expiry, found := n.expiries[nonce]; found && !now.After(expiry) {
+		return errNonceUsed
+	}
+	if len(n.expiries) >= maxNonces {
+		for used, expiry := range n.expiries {
+			if now.After(expiry) {
+				delete(n.expiries, used)
+			}
+		}
+		if len(n.expiries) >= maxNonces {
+			return errTooManyNonces
+		}
+	}
+	n.expiries[nonce] = timestamp.Add(onboardingvw.MaxClockSkew)
+	return nil
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package onboarding and its sub-packages provide the Onboarding Virtual
+// Workspace, where the administrator of a physical cluster registers it as
+// a SyncTarget without write access to SyncTargets:
+//
+//	POST /services/onboarding/clusters/<logical cluster>/registrations
+//
+// creates a SyncTarget pending approval from a Registration signed with a
+// private key of the physical cluster. Once an administrator of the
+// workspace approved it, e.g. with kubectl tmc approve-synctarget, the
+// syncer bootstrap manifests are generated and handed out to requests
+// signed with the same key:
+//
+//	POST /services/onboarding/clusters/<logical cluster>/registrations/<name>/bootstrap
+//
+// Both requests require the create verb on the registration subresource of
+// synctargets in the workspace.
+//
+// Physical clusters without credentials for kcp register with a
+// SyncTargetBootstrapToken of the workspace instead:
+//
+//	POST /services/onboarding/clusters/<logical cluster>/tokenregistrations
+//
+// creates an approved SyncTarget from a TokenRegistration and answers with
+// the syncer bootstrap manifests, whose credentials are scoped to the
+// SyncTarget. The token is the only credential of these requests.
+//
+// Signed requests carry a timestamp and a nonce, see NewNonce. A request is
+// accepted only while its timestamp is within MaxClockSkew of the server
+// time, and only once by each virtual workspace server: used nonces are
+// kept in memory, so a request captured within MaxClockSkew can be replayed
+// once against another replica, or after a restart. Capturing a request
+// takes breaking its TLS connection; a replayed registration is answered
+// like a retry.
+package onboarding
+
+const VirtualWorkspaceName string = "onboarding"
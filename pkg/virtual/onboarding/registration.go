@@ -0,0 +1,199 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onboarding
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MaxClockSkew bounds how old or how far in the future the timestamp of a
+// signed request may be, so that captured requests cannot be replayed
+// later. It is kept short as nonces are only recorded per server, see the
+// package documentation.
+const MaxClockSkew = 30 * time.Second
+
+// maxNonceLength bounds the length of nonces.
+const maxNonceLength = 64
+
+// SignedRequest is the body of all requests to the onboarding virtual
+// workspace. Payload is a JSON encoded Registration or BootstrapRequest.
+type SignedRequest struct {
+	Payload []byte `json:"payload"`
+	// Signature of the payload, made with ed25519, or with ECDSA over the
+	// SHA-256 of the payload.
+	Signature []byte `json:"signature"`
+}
+
+// Registration asks for a SyncTarget for a physical cluster.
+type Registration struct {
+	// Name is the name of the SyncTarget.
+	Name string `json:"name"`
+	// Location is the location of the SyncTarget.
+	Location string `json:"location,omitempty"`
+	// Labels are the labels of the SyncTarget.
+	Labels map[string]string `json:"labels,omitempty"`
+	// PublicKey is the PEM encoded PKIX public key the request is signed
+	// with.
+	PublicKey string `json:"publicKey"`
+	// Timestamp is when the request was signed.
+	Timestamp time.Time `json:"timestamp"`
+	// Nonce is a random value, see NewNonce, used by a single request so
+	// that it cannot be replayed while its timestamp is valid.
+	Nonce string `json:"nonce"`
+}
+
+// BootstrapRequest asks for the bootstrap manifests of an approved
+// registration.
+type BootstrapRequest struct {
+	// Name is the name of the SyncTarget.
+	Name string `json:"name"`
+	// Timestamp is when the request was signed.
+	Timestamp time.Time `json:"timestamp"`
+	// Nonce is a random value, see NewNonce, used by a single request so
+	// that it cannot be replayed while its timestamp is valid.
+	Nonce string `json:"nonce"`
+}
+
+// RegistrationStatus is the answer to registration and bootstrap requests
+// that do not return manifests.
+type RegistrationStatus struct {
+	Name string `json:"name"`
+	// Fingerprint identifies the public key, for the approver to compare.
+	Fingerprint string `json:"fingerprint"`
+	Approved    bool   `json:"approved"`
+}
+
+// Sign signs the JSON encoding of payload with key, which must be an
+// ed25519 or ECDSA key.
+func Sign(payload interface{}, key crypto.Signer) (*SignedRequest, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	var signature []byte
+	switch key.Public().(type) {
+	case ed25519.PublicKey:
+		signature, err = key.Sign(rand.Reader, data, crypto.Hash(0))
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(data)
+		signature, err = key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, expected ed25519 or ECDSA", key.Public())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &SignedRequest{Payload: data, Signature: signature}, nil
+}
+
+// Verify checks the signature of req against the PEM encoded public key and
+// decodes the payload into into.
+func Verify(req *SignedRequest, publicKeyPEM string, into interface{}) error {
+	key, err := ParsePublicKey(publicKeyPEM)
+	if err != nil {
+		return err
+	}
+	valid := false
+	switch key := key.(type) {
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(key, req.Payload, req.Signature)
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(req.Payload)
+		valid = ecdsa.VerifyASN1(key, digest[:], req.Signature)
+	}
+	if !valid {
+		return errors.New("invalid signature")
+	}
+	return json.Unmarshal(req.Payload, into)
+}
+
+// ParsePublicKey parses a PEM encoded PKIX ed25519 or ECDSA public key.
+func ParsePublicKey(publicKeyPEM string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, errors.New("public key must be a PEM encoded PUBLIC KEY block")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	switch key.(type) {
+	case ed25519.PublicKey, *ecdsa.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T, expected ed25519 or ECDSA", key)
+	}
+}
+
+// Fingerprint returns the SHA-256 fingerprint of the PEM encoded public key
+// in the format used by ssh, e.g. SHA256:Zm9v...
+func Fingerprint(publicKeyPEM string) (string, error) {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil {
+		return "", errors.New("public key must be PEM encoded")
+	}
+	sum := sha256.Sum256(block.Bytes)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:]), nil
+}
+
+// EncodePublicKey returns the PEM encoding of key.
+func EncodePublicKey(key crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
+
+// CheckTimestamp rejects timestamps more than MaxClockSkew away from now.
+func CheckTimestamp(timestamp, now time.Time) error {
+	if d := now.Sub(timestamp); d > MaxClockSkew || d < -MaxClockSkew {
+		return fmt.Errorf("request timestamp %s is more than %s away from the server time", timestamp.UTC().Format(time.RFC3339), MaxClockSkew)
+	}
+	return nil
+}
+
+// NewNonce returns a random nonce for a signed request.
+func NewNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CheckNonce rejects nonces that are empty or too long to be recorded.
+func CheckNonce(nonce string) error {
+	if nonce == "" {
+		return errors.New("request has no nonce")
+	}
+	if len(nonce) > maxNonceLength {
+		return fmt.Errorf("request nonce is longer than %d characters", maxNonceLength)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onboarding
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerify(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	for name, key := range map[string]crypto.Signer{"ed25519": edKey, "ecdsa": ecKey} {
+		t.Run(name, func(t *testing.T) {
+			publicKey, err := EncodePublicKey(key.Public())
+			require.NoError(t, err)
+
+			want := BootstrapRequest{Name: "east", Timestamp: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+			signed, err := Sign(want, key)
+			require.NoError(t, err)
+
+			var got BootstrapRequest
+			require.NoError(t, Verify(signed, publicKey, &got))
+			require.Equal(t, want, got)
+
+			tampered := &SignedRequest{Payload: []byte(strings.Replace(string(signed.Payload), "east", "west", 1)), Signature: signed.Signature}
+			require.Error(t, Verify(tampered, publicKey, &got))
+
+			_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+			require.NoError(t, err)
+			otherPublicKey, err := EncodePublicKey(otherKey.Public())
+			require.NoError(t, err)
+			require.Error(t, Verify(signed, otherPublicKey, &got))
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	publicKey, err := EncodePublicKey(key.Public())
+	require.NoError(t, err)
+
+	fingerprint, err := Fingerprint(publicKey)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(fingerprint, "SHA256:"), fingerprint)
+
+	again, err := Fingerprint(publicKey)
+	require.NoError(t, err)
+	require.Equal(t, fingerprint, again)
+
+	_, err = Fingerprint("not a key")
+	require.Error(t, err)
+}
+
+func TestCheckTimestamp(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, CheckTimestamp(now.Add(-10*time.Second), now))
+	require.NoError(t, CheckTimestamp(now.Add(10*time.Second), now))
+	require.Error(t, CheckTimestamp(now.Add(-MaxClockSkew-time.Second), now))
+	require.Error(t, CheckTimestamp(now.Add(MaxClockSkew+time.Second), now))
+}
+
+func TestNonce(t *testing.T) {
+	nonce, err := NewNonce()
+	require.NoError(t, err)
+	require.NoError(t, CheckNonce(nonce))
+	other, err := NewNonce()
+	require.NoError(t, err)
+	require.NotEqual(t, nonce, other)
+
+	require.Error(t, CheckNonce(""))
+	require.Error(t, CheckNonce(strings.Repeat("a", maxNonceLength+1)))
+}
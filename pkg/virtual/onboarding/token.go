@@ -0,0 +1,79 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onboarding
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// tokenSecretBytes is the number of random bytes of the secret part of
+// bootstrap tokens.
+const tokenSecretBytes = 24
+
+// TokenRegistration asks for a SyncTarget, its credentials and its syncer
+// bootstrap manifests with a bootstrap token, by physical clusters without
+// credentials for kcp.
+type TokenRegistration struct {
+	// Token is the bootstrap token, <name>.<secret>.
+	Token string `json:"token"`
+	// Name is the name of the SyncTarget.
+	Name string `json:"name"`
+	// Location is the location of the SyncTarget, unless the token sets
+	// one.
+	Location string `json:"location,omitempty"`
+	// Labels are the labels of the SyncTarget, merged with those of the
+	// token. Only the keys allowed by the token may be requested.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// NewBootstrapTokenSecret returns a random secret for a bootstrap token.
+// The token is <name of the SyncTargetBootstrapToken>.<secret>, and the
+// SyncTargetBootstrapToken stores HashBootstrapTokenSecret(secret).
+func NewBootstrapTokenSecret() (string, error) {
+	b := make([]byte, tokenSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// HashBootstrapTokenSecret returns the hex encoded SHA-256 hash of secret.
+func HashBootstrapTokenSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseBootstrapToken splits token into the name of its
+// SyncTargetBootstrapToken and its secret.
+func ParseBootstrapToken(token string) (name, secret string, err error) {
+	name, secret, found := strings.Cut(token, ".")
+	if !found || name == "" || secret == "" {
+		return "", "", errors.New("bootstrap token must be of the form <name>.<secret>")
+	}
+	return name, secret, nil
+}
+
+// CheckBootstrapTokenSecret returns whether secret matches hash, in
+// constant time.
+func CheckBootstrapTokenSecret(secret, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashBootstrapTokenSecret(secret)), []byte(hash)) == 1
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onboarding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBootstrapToken(t *testing.T) {
+	secret, err := NewBootstrapTokenSecret()
+	require.NoError(t, err)
+	require.Len(t, secret, 2*tokenSecretBytes)
+
+	name, parsed, err := ParseBootstrapToken("edge." + secret)
+	require.NoError(t, err)
+	require.Equal(t, "edge", name)
+	require.Equal(t, secret, parsed)
+	for _, invalid := range []string{"edge", ".secret", "edge."} {
+		_, _, err := ParseBootstrapToken(invalid)
+		require.Error(t, err, invalid)
+	}
+
+	hash := HashBootstrapTokenSecret(secret)
+	require.Regexp(t, `^[0-9a-f]{64}$`, hash)
+	require.True(t, CheckBootstrapTokenSecret(secret, hash))
+	require.False(t, CheckBootstrapTokenSecret("wrong", hash))
+}
@@ -25,10 +25,13 @@ import (
 
 	kcpkubernetesinformers "github.com/kcp-dev/client-go/informers"
 
+	kcpfeatures "github.com/kcp-dev/kcp/pkg/features"
 	apiexportoptions "github.com/kcp-dev/kcp/pkg/virtual/apiexport/options"
 	"github.com/kcp-dev/kcp/pkg/virtual/framework/rootapiserver"
 	initializingworkspacesoptions "github.com/kcp-dev/kcp/pkg/virtual/initializingworkspaces/options"
+	onboardingoptions "github.com/kcp-dev/kcp/pkg/virtual/onboarding/options"
 	replicationoptions "github.com/kcp-dev/kcp/pkg/virtual/replication/options"
+	synceroptions "github.com/kcp-dev/kcp/pkg/virtual/syncer/options"
 	kcpinformers "github.com/kcp-dev/kcp/sdk/client/informers/externalversions"
 )
 
@@ -37,12 +40,14 @@ const virtualWorkspacesFlagPrefix = "virtual-workspaces-"
 type Options struct {
 	APIExport              *apiexportoptions.APIExport
 	InitializingWorkspaces *initializingworkspacesoptions.InitializingWorkspaces
+	Syncer                 *synceroptions.Syncer
 }
 
 func NewOptions() *Options {
 	return &Options{
 		APIExport:              apiexportoptions.New(),
 		InitializingWorkspaces: initializingworkspacesoptions.New(),
+		Syncer:                 synceroptions.New(),
 	}
 }
 
@@ -51,12 +56,15 @@ func (o *Options) Validate() []error {
 
 	errs = append(errs, o.APIExport.Validate(virtualWorkspacesFlagPrefix)...)
 	errs = append(errs, o.InitializingWorkspaces.Validate(virtualWorkspacesFlagPrefix)...)
+	errs = append(errs, o.Syncer.Validate(virtualWorkspacesFlagPrefix)...)
 
 	return errs
 }
 
 func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	o.APIExport.AddFlags(fs, virtualWorkspacesFlagPrefix)
 	o.InitializingWorkspaces.AddFlags(fs, virtualWorkspacesFlagPrefix)
+	o.Syncer.AddFlags(fs, virtualWorkspacesFlagPrefix)
 }
 
 func (o *Options) NewVirtualWorkspaces(
@@ -86,7 +94,19 @@ func (o *Options) NewVirtualWorkspaces(
 		return nil, err
 	}
 
-	all, err := Merge(apiexports, initializingworkspaces, replications)
+	var onboardings, syncers []rootapiserver.NamedVirtualWorkspace
+	if kcpfeatures.TMCControllersEnabled() {
+		onboardings, err = onboardingoptions.New().NewVirtualWorkspaces(rootPathPrefix, config)
+		if err != nil {
+			return nil, err
+		}
+		syncers, err = o.Syncer.NewVirtualWorkspaces(rootPathPrefix, config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	all, err := Merge(apiexports, initializingworkspaces, replications, onboardings, syncers)
 	if err != nil {
 		return nil, err
 	}
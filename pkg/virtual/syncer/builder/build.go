@@ -0,0 +1,187 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/client-go/rest"
+
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	kcpdynamicinformer "github.com/kcp-dev/client-go/dynamic/dynamicinformer"
+	kcpkubernetesclientset "github.com/kcp-dev/client-go/kubernetes"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/authorization"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/onboarding"
+	"github.com/kcp-dev/kcp/pkg/syncer/transport/compression"
+	"github.com/kcp-dev/kcp/pkg/virtual/framework"
+	"github.com/kcp-dev/kcp/pkg/virtual/framework/forwardingregistry"
+	"github.com/kcp-dev/kcp/pkg/virtual/framework/handler"
+	"github.com/kcp-dev/kcp/pkg/virtual/framework/rootapiserver"
+	"github.com/kcp-dev/kcp/pkg/virtual/syncer"
+)
+
+// resyncPeriod is the resync period of the SyncTarget and
+// WorkloadDistribution informers.
+const resyncPeriod = 10 * time.Hour
+
+// BuildVirtualWorkspace returns the syncer virtual workspace. Requests are
+// forwarded to the kcp server of cfg with the credentials of cfg, once the
+// virtual workspace has authorized them. Lists with resourceVersion=0 are
+// served from a cache if listCache is not nil. Request and response bodies
+// are compressed as negotiated with the syncer if compressionSettings is not
+// nil, see compression.WithCompression.
+func BuildVirtualWorkspace(
+	rootPathPrefix string,
+	cfg *rest.Config,
+	dynamicClusterClient kcpdynamic.ClusterInterface,
+	kubeClusterClient kcpkubernetesclientset.ClusterInterface,
+	listCache *forwardingregistry.ListCacheOptions,
+	compressionSettings *compression.Settings,
+) ([]rootapiserver.NamedVirtualWorkspace, error) {
+	if !strings.HasSuffix(rootPathPrefix, "/") {
+		rootPathPrefix += "/"
+	}
+
+	forwardedHost, err := url.Parse(cfg.Host)
+	if err != nil {
+		return nil, err
+	}
+	informers := kcpdynamicinformer.NewDynamicSharedInformerFactory(dynamicClusterClient, resyncPeriod)
+	f, err := newForwarder(forwardedHost, cfg, informers, dynamicClusterClient, kubeClusterClient, listCache)
+	if err != nil {
+		return nil, err
+	}
+
+	return []rootapiserver.NamedVirtualWorkspace{{
+		Name: syncer.VirtualWorkspaceName,
+		VirtualWorkspace: &handler.VirtualWorkspace{
+			RootPathResolver: framework.RootPathResolverFunc(func(urlPath string, ctx context.Context) (accepted bool, prefixToStrip string, completedContext context.Context) {
+				cluster, syncTarget, prefixToStrip, ok := digestURL(urlPath, rootPathPrefix)
+				if !ok {
+					return false, "", ctx
+				}
+				return true, prefixToStrip, withSyncTarget(genericapirequest.WithCluster(ctx, cluster), syncTarget)
+			}),
+			Authorizer: newAuthorizer(),
+			ReadyChecker: framework.ReadyFunc(func() error {
+				return nil
+			}),
+			HandlerFactory: handler.HandlerFactory(func(rootAPIServerConfig genericapiserver.CompletedConfig) (http.Handler, error) {
+				// Until the informers have synced, resource requests are
+				// answered with 503 Service Unavailable.
+				if err := rootAPIServerConfig.AddPostStartHook(syncer.VirtualWorkspaceName, func(hookContext genericapiserver.PostStartHookContext) error {
+					informers.Start(hookContext.Done())
+					return nil
+				}); err != nil {
+					return nil, err
+				}
+				if compressionSettings != nil {
+					return compression.WithCompression(f, *compressionSettings, func(req *http.Request) string {
+						syncTarget, _ := syncTargetFrom(req.Context())
+						return syncTarget
+					}), nil
+				}
+				return f, nil
+			}),
+		},
+	}}, nil
+}
+
+// digestURL accepts paths like
+//
+//	/services/syncer/<syncer-id>/clusters/<logical cluster>/apis/apps/v1/deployments
+//
+// of a single logical cluster, and returns the SyncTarget of the syncer ID
+// and the prefix up to the logical cluster.
+func digestURL(urlPath, rootPathPrefix string) (genericapirequest.Cluster, string, string, bool) {
+	withoutPrefix, found := strings.CutPrefix(urlPath, rootPathPrefix)
+	if !found {
+		return genericapirequest.Cluster{}, "", "", false
+	}
+	parts := strings.SplitN(withoutPrefix, "/", 4)
+	if len(parts) < 3 || parts[1] != "clusters" {
+		return genericapirequest.Cluster{}, "", "", false
+	}
+	syncTarget, ok := syncer.SyncTargetName(parts[0])
+	if !ok {
+		return genericapirequest.Cluster{}, "", "", false
+	}
+	name := logicalcluster.Name(parts[2])
+	if !name.IsValid() {
+		return genericapirequest.Cluster{}, "", "", false
+	}
+	return genericapirequest.Cluster{Name: name}, syncTarget, rootPathPrefix + strings.Join(parts[:3], "/"), true
+}
+
+type syncTargetKeyType string
+
+// syncTargetKey is a context key that contains the name of the SyncTarget
+// of the syncer ID of a request.
+const syncTargetKey syncTargetKeyType = "SyncTarget"
+
+func withSyncTarget(ctx context.Context, syncTarget string) context.Context {
+	return context.WithValue(ctx, syncTargetKey, syncTarget)
+}
+
+func syncTargetFrom(ctx context.Context) (string, bool) {
+	syncTarget, ok := ctx.Value(syncTargetKey).(string)
+	return syncTarget, ok
+}
+
+// syncerAuthorizer allows requests of the service account of the syncer of
+// the SyncTarget in the workspace of the request, and denies all others.
+// Which resources the syncer may access is checked by the forwarder, as
+// requests are forwarded with the credentials of the virtual workspace.
+type syncerAuthorizer struct{}
+
+func newAuthorizer() authorizer.Authorizer {
+	var a authorizer.Authorizer = &syncerAuthorizer{}
+	return authorization.NewDecorator("virtual.syncer.authorization.kcp.io", a).AddAuditLogging().AddAnonymization().AddReasonAnnotation()
+}
+
+func (a *syncerAuthorizer) Authorize(ctx context.Context, attr authorizer.Attributes) (authorizer.Decision, string, error) {
+	cluster, err := genericapirequest.ValidClusterFrom(ctx)
+	if err != nil {
+		return authorizer.DecisionNoOpinion, "", fmt.Errorf("error getting valid cluster from context: %w", err)
+	}
+	syncTarget, ok := syncTargetFrom(ctx)
+	if !ok {
+		return authorizer.DecisionNoOpinion, "", fmt.Errorf("error getting SyncTarget from context")
+	}
+	user := attr.GetUser()
+	if user == nil {
+		return authorizer.DecisionDeny, "no user", nil
+	}
+
+	username := serviceaccount.MakeUsername(onboarding.BootstrapNamespace, syncer.SyncerID(syncTarget))
+	clusters := user.GetExtra()[serviceaccount.ClusterNameKey]
+	if user.GetName() != username || len(clusters) != 1 || clusters[0] != cluster.Name.String() {
+		return authorizer.DecisionDeny, fmt.Sprintf("workspace: %q user is not the syncer of SyncTarget %q", cluster.Name, syncTarget), nil
+	}
+	return authorizer.DecisionAllow, fmt.Sprintf("workspace: %q syncer of SyncTarget %q", cluster.Name, syncTarget), nil
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// filterResponse returns the ModifyResponse func of a reverse proxy that
+// drops the objects filter rejects from JSON lists and watches. Modified
+// objects a watch stops passing turn into deletions carrying only their
+// name, so that watchers forget objects they may no longer see.
+func filterResponse(ctx context.Context, filter objectFilter, isWatch bool) func(*http.Response) error {
+	return func(resp *http.Response) error {
+		if resp.StatusCode != http.StatusOK {
+			return nil
+		}
+		if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != runtime.ContentTypeJSON {
+			_ = resp.Body.Close()
+			return fmt.Errorf("unexpected content type %q of a filtered response", mediaType)
+		}
+		if isWatch {
+			resp.Body = filterWatch(ctx, resp.Body, filter)
+			return nil
+		}
+
+		data, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		if data, err = filterList(ctx, data, filter); err != nil {
+			return err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+		resp.ContentLength = int64(len(data))
+		resp.Header.Set("Content-Length", strconv.Itoa(len(data)))
+		return nil
+	}
+}
+
+// filterList drops the items of the JSON list that filter rejects, keeping
+// the others as they are.
+func filterList(ctx context.Context, data []byte, filter objectFilter) ([]byte, error) {
+	list := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	raw, found := list["items"]
+	if !found {
+		return data, nil
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, err
+	}
+	visibleItems := make([]json.RawMessage, 0, len(items))
+	for _, item := range items {
+		obj := &unstructured.Unstructured{}
+		if err := utiljson.Unmarshal(item, &obj.Object); err != nil {
+			return nil, err
+		}
+		visible, err := filter(ctx, obj)
+		if err != nil {
+			return nil, err
+		}
+		if visible {
+			visibleItems = append(visibleItems, item)
+		}
+	}
+	raw, err := json.Marshal(visibleItems)
+	if err != nil {
+		return nil, err
+	}
+	list["items"] = raw
+	return json.Marshal(list)
+}
+
+// filterWatch returns the JSON watch stream of body without the events of
+// the objects filter rejects.
+func filterWatch(ctx context.Context, body io.ReadCloser, filter objectFilter) io.ReadCloser {
+	r, w := io.Pipe()
+	go func() {
+		defer body.Close()
+		_ = w.CloseWithError(copyWatch(ctx, w, body, filter))
+	}()
+	return &filteredWatch{PipeReader: r, body: body}
+}
+
+// filteredWatch closes the upstream watch when the filtered one is closed.
+type filteredWatch struct {
+	*io.PipeReader
+	body io.Closer
+}
+
+func (w *filteredWatch) Close() error {
+	_ = w.body.Close()
+	return w.PipeReader.Close()
+}
+
+func copyWatch(ctx context.Context, w io.Writer, body io.Reader, filter objectFilter) error {
+	decoder := json.NewDecoder(body)
+	encoder := json.NewEncoder(w)
+	for {
+		var event metav1.WatchEvent
+		if err := decoder.Decode(&event); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		switch watch.EventType(event.Type) {
+		case watch.Added, watch.Modified, watch.Deleted:
+			obj := &unstructured.Unstructured{}
+			if err := utiljson.Unmarshal(event.Object.Raw, &obj.Object); err != nil {
+				return err
+			}
+			visible, err := filter(ctx, obj)
+			if err != nil {
+				return err
+			}
+			if visible {
+				break
+			}
+			if watch.EventType(event.Type) != watch.Modified {
+				continue
+			}
+			tombstone := &unstructured.Unstructured{}
+			tombstone.SetAPIVersion(obj.GetAPIVersion())
+			tombstone.SetKind(obj.GetKind())
+			tombstone.SetNamespace(obj.GetNamespace())
+			tombstone.SetName(obj.GetName())
+			tombstone.SetUID(obj.GetUID())
+			tombstone.SetResourceVersion(obj.GetResourceVersion())
+			if event.Object.Raw, err = tombstone.MarshalJSON(); err != nil {
+				return err
+			}
+			event.Type = string(watch.Deleted)
+		}
+		if err := encoder.Encode(&event); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestFilterResponse(t *testing.T) {
+	placed := func(_ context.Context, obj *unstructured.Unstructured) (bool, error) {
+		return obj.GetName() == "web", nil
+	}
+	response := func(body string) *http.Response {
+		return &http.Response{
+			StatusCode:    http.StatusOK,
+			Header:        http.Header{"Content-Type": {"application/json"}},
+			Body:          io.NopCloser(strings.NewReader(body)),
+			ContentLength: int64(len(body)),
+		}
+	}
+
+	resp := response(`{"kind":"ConfigMapList","apiVersion":"v1","metadata":{"resourceVersion":"7"},"items":[` +
+		`{"metadata":{"namespace":"default","name":"web","generation":1000000}},` +
+		`{"metadata":{"namespace":"default","name":"kube-root-ca.crt"}}]}`)
+	require.NoError(t, filterResponse(context.Background(), placed, false)(resp))
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"kind":"ConfigMapList","apiVersion":"v1","metadata":{"resourceVersion":"7"},"items":[`+
+		`{"metadata":{"namespace":"default","name":"web","generation":1000000}}]}`, string(data))
+	require.Equal(t, int64(len(data)), resp.ContentLength)
+
+	resp = response(`{"type":"ADDED","object":{"kind":"ConfigMap","apiVersion":"v1","metadata":{"namespace":"default","name":"web"}}}
+{"type":"ADDED","object":{"kind":"ConfigMap","apiVersion":"v1","metadata":{"namespace":"default","name":"other"}}}
+{"type":"MODIFIED","object":{"kind":"ConfigMap","apiVersion":"v1","metadata":{"namespace":"default","name":"other","resourceVersion":"8"},"data":{"secret":"value"}}}
+{"type":"BOOKMARK","object":{"kind":"ConfigMap","apiVersion":"v1","metadata":{"resourceVersion":"9"}}}
+`)
+	resp.ContentLength = -1
+	require.NoError(t, filterResponse(context.Background(), placed, true)(resp))
+	decoder := json.NewDecoder(resp.Body)
+	var events []string
+	for {
+		var event metav1.WatchEvent
+		if err := decoder.Decode(&event); err == io.EOF {
+			break
+		} else {
+			require.NoError(t, err)
+		}
+		events = append(events, event.Type+" "+string(event.Object.Raw))
+	}
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, []string{
+		`ADDED {"kind":"ConfigMap","apiVersion":"v1","metadata":{"namespace":"default","name":"web"}}`,
+		`DELETED {"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"other","namespace":"default","resourceVersion":"8"}}`,
+		`BOOKMARK {"kind":"ConfigMap","apiVersion":"v1","metadata":{"resourceVersion":"9"}}`,
+	}, events, "objects that are not placed are dropped, or deleted without their content")
+
+	resp = response(`{}`)
+	resp.Header.Set("Content-Type", "application/vnd.kubernetes.protobuf")
+	require.Error(t, filterResponse(context.Background(), placed, false)(resp), "unfiltered responses are not passed on")
+}
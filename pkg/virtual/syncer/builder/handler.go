@@ -0,0 +1,783 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/types"
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/transport"
+	"sigs.k8s.io/yaml"
+
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	kcpdynamicinformer "github.com/kcp-dev/client-go/dynamic/dynamicinformer"
+	kcpkubernetesclientset "github.com/kcp-dev/client-go/kubernetes"
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/placement/closure"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/clusterprofile"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/policyrollout"
+	"github.com/kcp-dev/kcp/pkg/statusaggregation"
+	"github.com/kcp-dev/kcp/pkg/syncer/heartbeat"
+	"github.com/kcp-dev/kcp/pkg/syncer/servicediscovery"
+	"github.com/kcp-dev/kcp/pkg/virtual/framework/forwardingregistry"
+	apisv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/apis/v1alpha1"
+	corev1alpha1 "github.com/kcp-dev/kcp/sdk/apis/core/v1alpha1"
+	placementv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/placement/v1alpha1"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tenancy/v1alpha1"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+const (
+	// kindCacheSize bounds the resource kinds cached from discovery.
+	kindCacheSize = 4096
+	// kindCacheTTL is how long resource kinds are cached.
+	kindCacheTTL = 10 * time.Minute
+	// placementCacheSize bounds the SyncTargets whose placed objects are
+	// cached.
+	placementCacheSize = 1024
+	// placementCacheTTL is how long the objects placed on a SyncTarget are
+	// cached. An object newly referenced by a placed workload is not found
+	// for up to this long.
+	placementCacheTTL = 10 * time.Second
+	// maxStatusPatchBytes bounds the apply patches of the annotations with
+	// the status of objects.
+	maxStatusPatchBytes = 512 * 1024
+)
+
+var (
+	errorScheme = runtime.NewScheme()
+	errorCodecs = serializer.NewCodecFactory(errorScheme)
+)
+
+func init() {
+	errorScheme.AddUnversionedTypes(metav1.Unversioned,
+		&metav1.Status{},
+	)
+}
+
+var (
+	leasesGR              = coordinationv1.SchemeGroupVersion.WithResource("leases").GroupResource()
+	eventsGR              = corev1.SchemeGroupVersion.WithResource("events").GroupResource()
+	namespacesGR          = corev1.SchemeGroupVersion.WithResource("namespaces").GroupResource()
+	distributionsGR       = policyrollout.WorkloadDistributionsGVR.GroupResource()
+	syncConfigurationsGR  = workloadv1alpha1.SchemeGroupVersion.WithResource("syncconfigurations").GroupResource()
+	propagationPoliciesGR = workloadv1alpha1.SchemeGroupVersion.WithResource("propagationpolicies").GroupResource()
+	priorityClassesGR     = workloadv1alpha1.SchemeGroupVersion.WithResource("workloadpriorityclasses").GroupResource()
+	schedulingDefaultsGR  = workloadv1alpha1.SchemeGroupVersion.WithResource("schedulingdefaults").GroupResource()
+	logicalClustersGR     = corev1alpha1.SchemeGroupVersion.WithResource("logicalclusters").GroupResource()
+	serviceExportsGR      = workloadv1alpha1.SchemeGroupVersion.WithResource("serviceexports").GroupResource()
+	serviceImportsGR      = workloadv1alpha1.SchemeGroupVersion.WithResource("serviceimports").GroupResource()
+
+	readVerbs = sets.New("get", "list", "watch")
+
+	// protectedGroups are the API groups of the objects the access of
+	// syncers is decided from, e.g. SyncTargets and WorkloadDistributions,
+	// and of the permissions of the workspace. They are never assigned to a
+	// SyncTarget, whatever its spec or the workloads placed on it say, so
+	// that a syncer cannot widen its own access.
+	protectedGroups = sets.New(
+		tmcv1alpha1.SchemeGroupVersion.Group,
+		workloadv1alpha1.SchemeGroupVersion.Group,
+		placementv1alpha1.SchemeGroupVersion.Group,
+		rbacv1.GroupName,
+		apisv1alpha1.SchemeGroupVersion.Group,
+		corev1alpha1.SchemeGroupVersion.Group,
+		tenancyv1alpha1.SchemeGroupVersion.Group,
+	)
+)
+
+// closureKinds are the kinds referenced by the built-in reference
+// extractors of transitive closures, see closure.NewExtractors. As of all
+// kinds assigned by WorkloadDistributions, only the objects in the closure
+// of a workload placed on the SyncTarget are accessible.
+var closureKinds = []schema.GroupKind{
+	{Kind: "ConfigMap"},
+	{Kind: "Secret"},
+	{Kind: "ServiceAccount"},
+	{Kind: "PersistentVolumeClaim"},
+}
+
+// forwarder forwards the requests of syncers for the resources assigned to
+// their SyncTarget to the workspace, with the credentials of the virtual
+// workspace. It is where the access of syncers is decided.
+type forwarder struct {
+	forwardedHost *url.URL
+	transport     http.RoundTripper
+
+	hasSynced         func() bool
+	getSyncTarget     func(clusterName logicalcluster.Name, name string) (*tmcv1alpha1.SyncTarget, error)
+	listDistributions func(clusterName logicalcluster.Name) ([]*workloadv1alpha1.WorkloadDistribution, error)
+	// kindFor returns the kind of a resource, or a NotFound error.
+	kindFor func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource) (string, error)
+	// getObject returns the referenced object, or a NotFound error. It is
+	// used to resolve the closures of workloads.
+	getObject func(ctx context.Context, clusterName logicalcluster.Name, ref closure.Reference) (*unstructured.Unstructured, error)
+	// listFor returns the caching lister of a resource, see
+	// serveCachedList. Lists are not cached if nil.
+	listFor func(gvr schema.GroupVersionResource) forwardingregistry.ListerFunc
+
+	placementsOnce sync.Once
+	placements     *utilcache.LRUExpireCache
+}
+
+// newForwarder returns a forwarder with the transport of cfg, reading
+// SyncTargets and WorkloadDistributions from informers of the factory.
+// Lists are cached if listCache is not nil.
+func newForwarder(forwardedHost *url.URL, cfg *rest.Config, informers kcpdynamicinformer.DynamicSharedInformerFactory, dynamicClusterClient kcpdynamic.ClusterInterface, kubeClusterClient kcpkubernetesclientset.ClusterInterface, listCache *forwardingregistry.ListCacheOptions) (*forwarder, error) {
+	rt, err := rest.TransportFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+	syncTargets := informers.ForResource(clusterprofile.SyncTargetsGVR)
+	distributions := informers.ForResource(policyrollout.WorkloadDistributionsGVR)
+	resourceFor := cachedResources(func(_ context.Context, clusterName logicalcluster.Name) ([]*restmapper.APIGroupResources, error) {
+		return restmapper.GetAPIGroupResources(kubeClusterClient.Cluster(clusterName.Path()).Discovery())
+	})
+
+	f := &forwarder{
+		forwardedHost: forwardedHost,
+		transport:     rt,
+		hasSynced: func() bool {
+			return syncTargets.Informer().HasSynced() && distributions.Informer().HasSynced()
+		},
+		getSyncTarget: func(clusterName logicalcluster.Name, name string) (*tmcv1alpha1.SyncTarget, error) {
+			obj, err := syncTargets.Lister().ByCluster(clusterName).Get(name)
+			if err != nil {
+				return nil, err
+			}
+			syncTarget := &tmcv1alpha1.SyncTarget{}
+			return syncTarget, fromObject(obj, syncTarget)
+		},
+		listDistributions: func(clusterName logicalcluster.Name) ([]*workloadv1alpha1.WorkloadDistribution, error) {
+			objs, err := distributions.Lister().ByCluster(clusterName).List(labels.Everything())
+			if err != nil {
+				return nil, err
+			}
+			list := make([]*workloadv1alpha1.WorkloadDistribution, 0, len(objs))
+			for _, obj := range objs {
+				d := &workloadv1alpha1.WorkloadDistribution{}
+				if err := fromObject(obj, d); err != nil {
+					return nil, err
+				}
+				list = append(list, d)
+			}
+			return list, nil
+		},
+		kindFor: cachedKinds(func(_ context.Context, clusterName logicalcluster.Name, gv schema.GroupVersion) (*metav1.APIResourceList, error) {
+			return kubeClusterClient.Cluster(clusterName.Path()).Discovery().ServerResourcesForGroupVersion(gv.String())
+		}),
+		getObject: func(ctx context.Context, clusterName logicalcluster.Name, ref closure.Reference) (*unstructured.Unstructured, error) {
+			gvr, err := resourceFor(ctx, clusterName, ref.GroupKind)
+			if err != nil {
+				return nil, err
+			}
+			return dynamicClusterClient.Cluster(clusterName.Path()).Resource(gvr).Namespace(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		},
+	}
+	if _, err := distributions.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: f.forgetPlacements,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			f.forgetPlacements(oldObj)
+			f.forgetPlacements(newObj)
+		},
+		DeleteFunc: f.forgetPlacements,
+	}); err != nil {
+		return nil, err
+	}
+	if listCache != nil {
+		options := *listCache
+		options.Scope = func(ctx context.Context) string {
+			syncTarget, _ := syncTargetFrom(ctx)
+			return syncTarget
+		}
+		f.listFor = cachedListers(options, func(gvr schema.GroupVersionResource) forwardingregistry.ListerFunc {
+			return dynamicLister(dynamicClusterClient, gvr)
+		})
+	}
+	return f, nil
+}
+
+// fromObject converts an unstructured object of an informer.
+func fromObject(obj runtime.Object, into interface{}) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unexpected object type %T", obj)
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, into)
+}
+
+func (f *forwarder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	cluster, err := genericapirequest.ValidClusterFrom(ctx)
+	if err != nil {
+		responsewriters.ErrorNegotiated(apierrors.NewBadRequest(err.Error()), errorCodecs, schema.GroupVersion{}, w, req)
+		return
+	}
+	syncTarget, ok := syncTargetFrom(ctx)
+	if !ok {
+		responsewriters.ErrorNegotiated(apierrors.NewInternalError(fmt.Errorf("no SyncTarget found in the context")), errorCodecs, schema.GroupVersion{}, w, req)
+		return
+	}
+
+	var filter objectFilter
+	isWatch := false
+	if info, ok := genericapirequest.RequestInfoFrom(ctx); ok && info.IsResourceRequest {
+		if !f.hasSynced() {
+			responsewriters.ErrorNegotiated(apierrors.NewServiceUnavailable("the syncer virtual workspace is not ready yet"), errorCodecs, schema.GroupVersion{}, w, req)
+			return
+		}
+		if filter, err = f.check(ctx, cluster.Name, syncTarget, info, req); err != nil {
+			responsewriters.ErrorNegotiated(err, errorCodecs, schema.GroupVersion{Group: info.APIGroup, Version: info.APIVersion}, w, req)
+			return
+		}
+		if f.serveCachedList(w, req, info, filter) {
+			return
+		}
+		if info.Verb != "list" && info.Verb != "watch" {
+			filter = nil
+		}
+		isWatch = info.Verb == "watch"
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Director: func(request *http.Request) {
+			for _, header := range []string{
+				"Authorization",
+				transport.ImpersonateUserHeader,
+				transport.ImpersonateUIDHeader,
+				transport.ImpersonateGroupHeader,
+			} {
+				request.Header.Del(header)
+			}
+			for key := range request.Header {
+				if strings.HasPrefix(key, transport.ImpersonateUserExtraHeaderPrefix) {
+					request.Header.Del(key)
+				}
+			}
+			request.URL.Scheme = f.forwardedHost.Scheme
+			request.URL.Host = f.forwardedHost.Host
+			request.URL.Path = strings.TrimSuffix(f.forwardedHost.Path, "/") + cluster.Name.Path().RequestPath() + request.URL.Path
+			request.URL.RawPath = ""
+			if filter != nil {
+				// Filtered responses are decoded, so they are requested
+				// as JSON and decompressed by the transport.
+				request.Header.Set("Accept", runtime.ContentTypeJSON)
+				request.Header.Del("Accept-Encoding")
+			}
+		},
+		Transport: f.transport,
+	}
+	if filter != nil {
+		proxy.ModifyResponse = filterResponse(ctx, filter, isWatch)
+	}
+	proxy.ServeHTTP(w, req)
+}
+
+// objectFilter returns whether the syncer may see an object of a list or
+// watch.
+type objectFilter func(ctx context.Context, obj *unstructured.Unstructured) (bool, error)
+
+// check returns nil if the syncer of the SyncTarget may make the resource
+// request. The syncer may
+//
+//   - read its SyncTarget and update its status,
+//   - renew its heartbeat Lease,
+//   - create events, e.g. about objects the physical cluster refuses,
+//   - read namespaces, SyncConfigurations, PropagationPolicies,
+//     WorkloadPriorityClasses and SchedulingDefaults,
+//   - get the LogicalCluster of the workspace, e.g. for the sync bundles
+//     the workspace opts into,
+//   - read the WorkloadDistributions placed on the SyncTarget, update their
+//     status and apply the annotation with their status on the SyncTarget,
+//     e.g. how far the images of their workloads are pre-pulled,
+//   - read the ServiceExports and ServiceImports of the namespaces with
+//     objects placed on the SyncTarget, and report the endpoints of the
+//     exported Services on the SyncTarget in the ServiceExports, see
+//     package servicediscovery, and
+//   - read the objects placed on the SyncTarget, update their status and
+//     apply the annotation with their status on the SyncTarget, see
+//     statusaggregation.TargetAnnotation. These are the workloads of the
+//     WorkloadDistributions and the objects exported with them, and the
+//     objects of the cluster-scoped resources assigned to the SyncTarget.
+//
+// Requests for other resources and objects are not found. The objects of
+// lists and watches are filtered with the returned filter, if not nil. The
+// spec of the SyncTarget, which decides about the assigned cluster-scoped
+// resources, is only written by the owners of the workspace.
+func (f *forwarder) check(ctx context.Context, clusterName logicalcluster.Name, syncTarget string, info *genericapirequest.RequestInfo, req *http.Request) (objectFilter, error) {
+	gvr := schema.GroupVersionResource{Group: info.APIGroup, Version: info.APIVersion, Resource: info.Resource}
+	gr := gvr.GroupResource()
+	forbidden := func(reason string) error {
+		return apierrors.NewForbidden(gr, info.Name, fmt.Errorf("syncer of SyncTarget %q %s", syncTarget, reason))
+	}
+
+	switch gr {
+	case clusterprofile.SyncTargetsGVR.GroupResource():
+		if !readVerbs.Has(info.Verb) && !(info.Subresource == "status" && sets.New("update", "patch").Has(info.Verb)) {
+			return nil, forbidden(fmt.Sprintf("may not %s synctargets, only update their status", info.Verb))
+		}
+		if info.Name == "" && req.URL.Query().Get("fieldSelector") != fields.OneTermEqualSelector("metadata.name", syncTarget).String() {
+			return nil, forbidden("may only list and watch its own SyncTarget, with a metadata.name field selector")
+		}
+		if info.Name != "" && info.Name != syncTarget {
+			return nil, apierrors.NewNotFound(gr, info.Name)
+		}
+		return nil, nil
+	case leasesGR:
+		if !sets.New("get", "update", "patch").Has(info.Verb) {
+			return nil, forbidden(fmt.Sprintf("may not %s leases", info.Verb))
+		}
+		if info.Namespace != heartbeat.LeaseNamespace || info.Name != heartbeat.LeaseName(syncTarget) {
+			return nil, apierrors.NewNotFound(gr, info.Name)
+		}
+		return nil, nil
+	case eventsGR:
+		if info.Verb != "create" {
+			return nil, forbidden(fmt.Sprintf("may not %s events, only create them", info.Verb))
+		}
+		return nil, nil
+	case namespacesGR, syncConfigurationsGR, propagationPoliciesGR, priorityClassesGR, schedulingDefaultsGR:
+		if !readVerbs.Has(info.Verb) {
+			return nil, forbidden(fmt.Sprintf("may not %s %s", info.Verb, gr))
+		}
+		return nil, nil
+	case logicalClustersGR:
+		if info.Verb != "get" {
+			return nil, forbidden(fmt.Sprintf("may not %s logicalclusters, only get them", info.Verb))
+		}
+		if info.Name != corev1alpha1.LogicalClusterName {
+			return nil, apierrors.NewNotFound(gr, info.Name)
+		}
+		return nil, nil
+	case serviceExportsGR, serviceImportsGR:
+		return f.checkServiceDiscovery(ctx, clusterName, syncTarget, info, req)
+	case distributionsGR:
+		if err := checkPlacedRequest(syncTarget, info, req); err != nil {
+			return nil, err
+		}
+		return f.checkDistribution(clusterName, syncTarget, info)
+	}
+
+	if protectedGroups.Has(gr.Group) {
+		return nil, apierrors.NewNotFound(gr, info.Name)
+	}
+	gk, wholeKind, err := f.assigned(ctx, clusterName, syncTarget, gvr)
+	if err != nil {
+		return nil, apierrors.NewInternalError(err)
+	}
+	if gk.Empty() {
+		return nil, apierrors.NewNotFound(gr, info.Name)
+	}
+	if err := checkPlacedRequest(syncTarget, info, req); err != nil {
+		return nil, err
+	}
+	if wholeKind {
+		return nil, nil
+	}
+
+	visible := func(ctx context.Context, namespace, name string) (bool, error) {
+		placed, err := f.placed(ctx, clusterName, syncTarget)
+		if err != nil {
+			return false, err
+		}
+		return placed.Has(closure.Reference{GroupKind: gk, Namespace: namespace, Name: name}), nil
+	}
+	if info.Name != "" {
+		ok, err := visible(ctx, info.Namespace, info.Name)
+		if err != nil {
+			return nil, apierrors.NewInternalError(err)
+		}
+		if !ok {
+			return nil, apierrors.NewNotFound(gr, info.Name)
+		}
+	}
+	return func(ctx context.Context, obj *unstructured.Unstructured) (bool, error) {
+		return visible(ctx, obj.GetNamespace(), obj.GetName())
+	}, nil
+}
+
+// checkPlacedRequest returns nil if the syncer of the SyncTarget may make
+// the request to an object placed on the SyncTarget: read it, update its
+// status, or apply the annotation with its status on the SyncTarget.
+func checkPlacedRequest(syncTarget string, info *genericapirequest.RequestInfo, req *http.Request) error {
+	gr := schema.GroupResource{Group: info.APIGroup, Resource: info.Resource}
+	forbidden := func(reason string) error {
+		return apierrors.NewForbidden(gr, info.Name, fmt.Errorf("syncer of SyncTarget %q %s", syncTarget, reason))
+	}
+	switch {
+	case readVerbs.Has(info.Verb):
+		return nil
+	case info.Subresource == "status" && sets.New("update", "patch").Has(info.Verb):
+		return nil
+	case info.Subresource == "" && info.Verb == "patch":
+		if mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); mediaType != string(types.ApplyPatchType) {
+			return forbidden("may only apply the annotation with its status")
+		}
+		patch, err := readPatch(req)
+		if err != nil {
+			return err
+		}
+		if !onlyAnnotation(patch, statusaggregation.TargetAnnotation(syncTarget)) {
+			return forbidden("may only apply the annotation with its status")
+		}
+		return nil
+	}
+	return forbidden(fmt.Sprintf("may not %s %s", info.Verb, gr))
+}
+
+// readPatch decodes the body of a patch request, bounded to
+// maxStatusPatchBytes, and keeps it to be forwarded.
+func readPatch(req *http.Request) (map[string]interface{}, error) {
+	data, err := io.ReadAll(io.LimitReader(req.Body, maxStatusPatchBytes+1))
+	if err != nil {
+		return nil, apierrors.NewBadRequest(err.Error())
+	}
+	if len(data) > maxStatusPatchBytes {
+		return nil, apierrors.NewRequestEntityTooLargeError(fmt.Sprintf("limit is %d bytes", maxStatusPatchBytes))
+	}
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	req.ContentLength = int64(len(data))
+	patch := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &patch); err != nil {
+		return nil, apierrors.NewBadRequest(err.Error())
+	}
+	return patch, nil
+}
+
+// checkServiceDiscovery returns the filter of the ServiceExports and
+// ServiceImports of the namespaces with objects placed on the SyncTarget,
+// or NotFound for the others. Besides reading them, the syncer may only
+// report the endpoints of the exported Services on the SyncTarget in their
+// ServiceExports, see servicediscovery.ReportPatch.
+func (f *forwarder) checkServiceDiscovery(ctx context.Context, clusterName logicalcluster.Name, syncTarget string, info *genericapirequest.RequestInfo, req *http.Request) (objectFilter, error) {
+	gr := schema.GroupResource{Group: info.APIGroup, Resource: info.Resource}
+	forbidden := func(reason string) error {
+		return apierrors.NewForbidden(gr, info.Name, fmt.Errorf("syncer of SyncTarget %q %s", syncTarget, reason))
+	}
+	switch {
+	case readVerbs.Has(info.Verb):
+	case gr == serviceExportsGR && info.Subresource == "" && info.Verb == "patch":
+		if mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); mediaType != string(types.MergePatchType) {
+			return nil, forbidden("may only report the endpoints of the exported Service")
+		}
+		patch, err := readPatch(req)
+		if err != nil {
+			return nil, err
+		}
+		if !onlyAnnotation(patch, servicediscovery.EndpointsAnnotation(syncTarget)) {
+			return nil, forbidden("may only report the endpoints of the exported Service")
+		}
+	default:
+		return nil, forbidden(fmt.Sprintf("may not %s %s", info.Verb, gr))
+	}
+
+	visible := func(ctx context.Context, namespace string) (bool, error) {
+		placed, err := f.placed(ctx, clusterName, syncTarget)
+		if err != nil {
+			return false, err
+		}
+		for ref := range placed {
+			if ref.Namespace == namespace {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	if info.Name != "" {
+		ok, err := visible(ctx, info.Namespace)
+		if err != nil {
+			return nil, apierrors.NewInternalError(err)
+		}
+		if !ok {
+			return nil, apierrors.NewNotFound(gr, info.Name)
+		}
+	}
+	return func(ctx context.Context, obj *unstructured.Unstructured) (bool, error) {
+		return visible(ctx, obj.GetNamespace())
+	}, nil
+}
+
+// onlyAnnotation returns whether the patch sets nothing but annotation, or
+// removes it.
+func onlyAnnotation(patch map[string]interface{}, annotation string) bool {
+	for field := range patch {
+		if !sets.New("apiVersion", "kind", "metadata").Has(field) {
+			return false
+		}
+	}
+	metadata, _, err := unstructured.NestedMap(patch, "metadata")
+	if err != nil {
+		return false
+	}
+	for field := range metadata {
+		if !sets.New("name", "namespace", "annotations").Has(field) {
+			return false
+		}
+	}
+	annotations, _, err := unstructured.NestedMap(patch, "metadata", "annotations")
+	if err != nil {
+		return false
+	}
+	for key, value := range annotations {
+		if _, ok := value.(string); key != annotation || (!ok && value != nil) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkDistribution returns the filter of the WorkloadDistributions placed
+// on the SyncTarget, or NotFound if the request is for another one.
+func (f *forwarder) checkDistribution(clusterName logicalcluster.Name, syncTarget string, info *genericapirequest.RequestInfo) (objectFilter, error) {
+	if info.Name != "" {
+		distributions, err := f.listDistributions(clusterName)
+		if err != nil {
+			return nil, apierrors.NewInternalError(err)
+		}
+		placed := false
+		for _, d := range distributions {
+			if d.Namespace == info.Namespace && d.Name == info.Name {
+				placed = closure.PlacedOn(d, syncTarget)
+				break
+			}
+		}
+		if !placed {
+			return nil, apierrors.NewNotFound(distributionsGR, info.Name)
+		}
+	}
+	return func(_ context.Context, obj *unstructured.Unstructured) (bool, error) {
+		d := &workloadv1alpha1.WorkloadDistribution{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, d); err != nil {
+			return false, err
+		}
+		return closure.PlacedOn(d, syncTarget), nil
+	}, nil
+}
+
+// placementCacheKey is a SyncTarget of a logical cluster.
+type placementCacheKey struct {
+	clusterName logicalcluster.Name
+	syncTarget  string
+}
+
+// placed returns the objects placed on the SyncTarget, see closure.Placed,
+// cached for placementCacheTTL or until a WorkloadDistribution of the
+// SyncTarget changes.
+func (f *forwarder) placed(ctx context.Context, clusterName logicalcluster.Name, syncTarget string) (sets.Set[closure.Reference], error) {
+	f.placementsOnce.Do(func() {
+		f.placements = utilcache.NewLRUExpireCache(placementCacheSize)
+	})
+	key := placementCacheKey{clusterName: clusterName, syncTarget: syncTarget}
+	if placed, ok := f.placements.Get(key); ok {
+		return placed.(sets.Set[closure.Reference]), nil
+	}
+
+	distributions, err := f.listDistributions(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	placed, err := closure.Placed(ctx, distributions, syncTarget, func(ctx context.Context, ref closure.Reference) (*unstructured.Unstructured, error) {
+		return f.getObject(ctx, clusterName, ref)
+	})
+	if err != nil {
+		return nil, err
+	}
+	f.placements.Add(key, placed, placementCacheTTL)
+	return placed, nil
+}
+
+// forgetPlacements drops the cached objects placed on the SyncTargets of
+// the WorkloadDistribution.
+func (f *forwarder) forgetPlacements(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	d := &workloadv1alpha1.WorkloadDistribution{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, d); err != nil {
+		return
+	}
+	f.placementsOnce.Do(func() {
+		f.placements = utilcache.NewLRUExpireCache(placementCacheSize)
+	})
+	for _, syncTarget := range d.SyncedTargets() {
+		f.placements.Remove(placementCacheKey{clusterName: logicalcluster.From(u), syncTarget: syncTarget})
+	}
+}
+
+// assigned returns the kind of the resource if it is assigned to the
+// SyncTarget, and whether all its objects are, as for the cluster-scoped
+// resources of the spec of the SyncTarget. Otherwise only the objects
+// placed on the SyncTarget are.
+func (f *forwarder) assigned(ctx context.Context, clusterName logicalcluster.Name, syncTargetName string, gvr schema.GroupVersionResource) (schema.GroupKind, bool, error) {
+	syncTarget, err := f.getSyncTarget(clusterName, syncTargetName)
+	if apierrors.IsNotFound(err) {
+		return schema.GroupKind{}, false, nil
+	} else if err != nil {
+		return schema.GroupKind{}, false, err
+	}
+	wholeKind := false
+	for _, r := range syncTarget.Spec.ClusterScopedResources {
+		if r.Group == gvr.Group && r.Resource == gvr.Resource {
+			wholeKind = true
+			break
+		}
+	}
+
+	var kinds sets.Set[schema.GroupKind]
+	if !wholeKind {
+		distributions, err := f.listDistributions(clusterName)
+		if err != nil {
+			return schema.GroupKind{}, false, err
+		}
+		if kinds = assignedKinds(syncTargetName, distributions); kinds.Len() == 0 {
+			return schema.GroupKind{}, false, nil
+		}
+	}
+	kind, err := f.kindFor(ctx, clusterName, gvr)
+	if apierrors.IsNotFound(err) {
+		return schema.GroupKind{}, false, nil
+	} else if err != nil {
+		return schema.GroupKind{}, false, err
+	}
+	gk := schema.GroupKind{Group: gvr.Group, Kind: kind}
+	if !wholeKind && !kinds.Has(gk) {
+		return schema.GroupKind{}, false, nil
+	}
+	return gk, wholeKind, nil
+}
+
+// assignedKinds returns the kinds of the workloads placed on the SyncTarget,
+// and of the objects exported with them.
+func assignedKinds(syncTarget string, distributions []*workloadv1alpha1.WorkloadDistribution) sets.Set[schema.GroupKind] {
+	kinds := sets.New[schema.GroupKind]()
+	for _, d := range distributions {
+		if !closure.PlacedOn(d, syncTarget) {
+			continue
+		}
+		ref, err := closure.WorkloadReference(d)
+		if err != nil {
+			continue
+		}
+		kinds.Insert(ref.GroupKind)
+		if d.Spec.Closure == nil || d.Spec.Closure.Mode != workloadv1alpha1.ClosureModeTransitive {
+			continue
+		}
+		kinds.Insert(closureKinds...)
+		for _, ref := range d.Spec.Closure.References {
+			kinds.Insert(schema.GroupKind{Group: ref.TargetGroup, Kind: ref.TargetKind})
+		}
+	}
+	return kinds
+}
+
+// kindCacheKey is a resource of a logical cluster.
+type kindCacheKey struct {
+	clusterName logicalcluster.Name
+	gvr         schema.GroupVersionResource
+}
+
+// cachedKinds caches the kinds of resources from discovery, including
+// resources that are not served, for kindCacheTTL.
+func cachedKinds(discover func(ctx context.Context, clusterName logicalcluster.Name, gv schema.GroupVersion) (*metav1.APIResourceList, error)) func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource) (string, error) {
+	kinds := utilcache.NewLRUExpireCache(kindCacheSize)
+	return func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource) (string, error) {
+		key := kindCacheKey{clusterName: clusterName, gvr: gvr}
+		if kind, ok := kinds.Get(key); ok {
+			if kind == "" {
+				return "", apierrors.NewNotFound(gvr.GroupResource(), "")
+			}
+			return kind.(string), nil
+		}
+		resources, err := discover(ctx, clusterName, gvr.GroupVersion())
+		if err != nil && !apierrors.IsNotFound(err) {
+			return "", err
+		}
+		kind := ""
+		if resources != nil {
+			for _, r := range resources.APIResources {
+				if r.Name == gvr.Resource {
+					kind = r.Kind
+					break
+				}
+			}
+		}
+		kinds.Add(key, kind, kindCacheTTL)
+		if kind == "" {
+			return "", apierrors.NewNotFound(gvr.GroupResource(), "")
+		}
+		return kind, nil
+	}
+}
+
+// cachedResources returns a func mapping kinds to resources by discovery,
+// caching the mapper of each logical cluster for kindCacheTTL.
+func cachedResources(discover func(ctx context.Context, clusterName logicalcluster.Name) ([]*restmapper.APIGroupResources, error)) func(ctx context.Context, clusterName logicalcluster.Name, gk schema.GroupKind) (schema.GroupVersionResource, error) {
+	mappers := utilcache.NewLRUExpireCache(kindCacheSize)
+	return func(ctx context.Context, clusterName logicalcluster.Name, gk schema.GroupKind) (schema.GroupVersionResource, error) {
+		mapper, ok := mappers.Get(clusterName)
+		if !ok {
+			groupResources, err := discover(ctx, clusterName)
+			if err != nil {
+				return schema.GroupVersionResource{}, err
+			}
+			mapper = restmapper.NewDiscoveryRESTMapper(groupResources)
+			mappers.Add(clusterName, mapper, kindCacheTTL)
+		}
+		mapping, err := mapper.(meta.RESTMapper).RESTMapping(gk)
+		if meta.IsNoMatchError(err) {
+			return schema.GroupVersionResource{}, apierrors.NewNotFound(schema.GroupResource{Group: gk.Group, Resource: gk.Kind}, "")
+		} else if err != nil {
+			return schema.GroupVersionResource{}, err
+		}
+		return mapping.Resource, nil
+	}
+}
@@ -0,0 +1,718 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/restmapper"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"github.com/kcp-dev/kcp/pkg/placement/closure"
+	"github.com/kcp-dev/kcp/pkg/statusaggregation"
+	"github.com/kcp-dev/kcp/pkg/syncer/heartbeat"
+	"github.com/kcp-dev/kcp/pkg/syncer/servicediscovery"
+	"github.com/kcp-dev/kcp/pkg/virtual/framework/forwardingregistry"
+	corev1alpha1 "github.com/kcp-dev/kcp/sdk/apis/core/v1alpha1"
+	tmcv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/tmc/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+func TestDigestURL(t *testing.T) {
+	tests := map[string]struct {
+		path       string
+		cluster    logicalcluster.Name
+		syncTarget string
+		prefix     string
+		accepted   bool
+	}{
+		"resource": {
+			path:       "/services/syncer/kcp-syncer-east/clusters/abc123/apis/apps/v1/deployments",
+			cluster:    "abc123",
+			syncTarget: "east",
+			prefix:     "/services/syncer/kcp-syncer-east/clusters/abc123",
+			accepted:   true,
+		},
+		"workspace": {
+			path:       "/services/syncer/kcp-syncer-east/clusters/root",
+			cluster:    "root",
+			syncTarget: "east",
+			prefix:     "/services/syncer/kcp-syncer-east/clusters/root",
+			accepted:   true,
+		},
+		"not a syncer ID":  {path: "/services/syncer/east/clusters/root/api/v1/namespaces"},
+		"empty SyncTarget": {path: "/services/syncer/kcp-syncer-/clusters/root/api/v1/namespaces"},
+		"no cluster":       {path: "/services/syncer/kcp-syncer-east/api/v1/namespaces"},
+		"wildcard cluster": {path: "/services/syncer/kcp-syncer-east/clusters/*/api/v1/namespaces"},
+		"other workspace":  {path: "/services/onboarding/clusters/root/registrations"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cluster, syncTarget, prefix, accepted := digestURL(tt.path, "/services/syncer/")
+			require.Equal(t, tt.accepted, accepted)
+			require.Equal(t, tt.cluster, cluster.Name)
+			require.Equal(t, tt.syncTarget, syncTarget)
+			require.Equal(t, tt.prefix, prefix)
+		})
+	}
+}
+
+func TestSyncerAuthorizer(t *testing.T) {
+	syncer := func(name, cluster string) user.Info {
+		return &user.DefaultInfo{
+			Name:  serviceaccount.MakeUsername("default", name),
+			Extra: map[string][]string{serviceaccount.ClusterNameKey: {cluster}},
+		}
+	}
+	tests := map[string]struct {
+		user     user.Info
+		expected authorizer.Decision
+	}{
+		"syncer":                      {user: syncer("kcp-syncer-east", "abc123"), expected: authorizer.DecisionAllow},
+		"syncer of another target":    {user: syncer("kcp-syncer-west", "abc123"), expected: authorizer.DecisionDeny},
+		"syncer of another workspace": {user: syncer("kcp-syncer-east", "def456"), expected: authorizer.DecisionDeny},
+		"user":                        {user: &user.DefaultInfo{Name: "admin", Groups: []string{"system:masters"}}, expected: authorizer.DecisionDeny},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := withSyncTarget(genericapirequest.WithCluster(context.Background(), genericapirequest.Cluster{Name: "abc123"}), "east")
+			dec, _, err := (&syncerAuthorizer{}).Authorize(ctx, authorizer.AttributesRecord{User: tt.user})
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, dec)
+		})
+	}
+}
+
+func TestForwarder(t *testing.T) {
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	distribution := func(kind string, closure *workloadv1alpha1.ExportClosure, targets ...string) *workloadv1alpha1.WorkloadDistribution {
+		d := &workloadv1alpha1.WorkloadDistribution{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+			Spec: workloadv1alpha1.WorkloadDistributionSpec{
+				WorkloadRef: workloadv1alpha1.WorkloadReference{APIVersion: "apps/v1", Kind: kind, Name: "web"},
+				Closure:     closure,
+			},
+		}
+		for _, target := range targets {
+			d.Status.Targets = append(d.Status.Targets, workloadv1alpha1.TargetPlacement{SyncTarget: target})
+		}
+		return d
+	}
+	rollingOut := func(d *workloadv1alpha1.WorkloadDistribution, synced ...string) *workloadv1alpha1.WorkloadDistribution {
+		d.Spec.RolloutStrategy = &workloadv1alpha1.RolloutStrategy{}
+		d.Status.Rollout = &workloadv1alpha1.RolloutStatus{SyncedTargets: synced}
+		return d
+	}
+	kinds := map[schema.GroupVersionResource]string{
+		deployments: "Deployment",
+		{Group: "storage.k8s.io", Version: "v1", Resource: "storageclasses"}:          "StorageClass",
+		{Group: "apps", Version: "v1", Resource: "statefulsets"}:                      "StatefulSet",
+		{Version: "v1", Resource: "secrets"}:                                          "Secret",
+		{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}: "ClusterRole",
+	}
+	web := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"namespace": "default", "name": "web"},
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+			"volumes": []interface{}{map[string]interface{}{"secret": map[string]interface{}{"secretName": "creds"}}},
+		}}},
+	}}
+	creds := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"namespace": "default", "name": "creds"},
+	}}
+	transitive := &workloadv1alpha1.ExportClosure{Mode: workloadv1alpha1.ClosureModeTransitive}
+
+	tests := map[string]struct {
+		path          string
+		info          *genericapirequest.RequestInfo
+		body          string
+		contentType   string
+		distributions []*workloadv1alpha1.WorkloadDistribution
+		expectedCode  int
+		expectedPath  string
+		notSynced     bool
+	}{
+		"placed workload": {
+			path:          "/apis/apps/v1/namespaces/default/deployments",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "list", APIGroup: "apps", APIVersion: "v1", Resource: "deployments", Namespace: "default"},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", nil, "west", "east")},
+			expectedCode:  http.StatusOK,
+			expectedPath:  "/clusters/abc123/apis/apps/v1/namespaces/default/deployments",
+		},
+		"workload placed elsewhere": {
+			path:          "/apis/apps/v1/deployments",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "list", APIGroup: "apps", APIVersion: "v1", Resource: "deployments"},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", nil, "west")},
+			expectedCode:  http.StatusNotFound,
+		},
+		"other kind": {
+			path:          "/apis/apps/v1/statefulsets",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "list", APIGroup: "apps", APIVersion: "v1", Resource: "statefulsets"},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", nil, "east")},
+			expectedCode:  http.StatusNotFound,
+		},
+		"referenced secret": {
+			path:          "/api/v1/namespaces/default/secrets/creds",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "get", APIVersion: "v1", Resource: "secrets", Namespace: "default", Name: "creds"},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", transitive, "east")},
+			expectedCode:  http.StatusOK,
+			expectedPath:  "/clusters/abc123/api/v1/namespaces/default/secrets/creds",
+		},
+		"watch referenced secret": {
+			path:          "/api/v1/namespaces/default/secrets?fieldSelector=metadata.name%3Dcreds",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "watch", APIVersion: "v1", Resource: "secrets", Namespace: "default"},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", transitive, "east")},
+			expectedCode:  http.StatusOK,
+			expectedPath:  "/clusters/abc123/api/v1/namespaces/default/secrets",
+		},
+		"unreferenced secret": {
+			path:          "/api/v1/namespaces/default/secrets/other",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "get", APIVersion: "v1", Resource: "secrets", Namespace: "default", Name: "other"},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", transitive, "east")},
+			expectedCode:  http.StatusNotFound,
+		},
+		"list secrets": {
+			path:          "/api/v1/secrets",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "list", APIVersion: "v1", Resource: "secrets"},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", transitive, "east")},
+			expectedCode:  http.StatusOK,
+			expectedPath:  "/clusters/abc123/api/v1/secrets",
+		},
+		"placed object": {
+			path:          "/apis/apps/v1/namespaces/default/deployments/web",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "get", APIGroup: "apps", APIVersion: "v1", Resource: "deployments", Namespace: "default", Name: "web"},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", nil, "east")},
+			expectedCode:  http.StatusOK,
+			expectedPath:  "/clusters/abc123/apis/apps/v1/namespaces/default/deployments/web",
+		},
+		"object not placed": {
+			path:          "/apis/apps/v1/namespaces/default/deployments/api",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "get", APIGroup: "apps", APIVersion: "v1", Resource: "deployments", Namespace: "default", Name: "api"},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", nil, "east")},
+			expectedCode:  http.StatusNotFound,
+		},
+		"object not rolled out yet": {
+			path:          "/apis/apps/v1/namespaces/default/deployments/web",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "get", APIGroup: "apps", APIVersion: "v1", Resource: "deployments", Namespace: "default", Name: "web"},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{rollingOut(distribution("Deployment", nil, "west", "east"), "west")},
+			expectedCode:  http.StatusNotFound,
+		},
+		"delete placed object": {
+			path:          "/apis/apps/v1/namespaces/default/deployments/web",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "delete", APIGroup: "apps", APIVersion: "v1", Resource: "deployments", Namespace: "default", Name: "web"},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", nil, "east")},
+			expectedCode:  http.StatusForbidden,
+		},
+		"create object": {
+			path:          "/apis/apps/v1/namespaces/default/deployments",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "create", APIGroup: "apps", APIVersion: "v1", Resource: "deployments", Namespace: "default"},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", nil, "east")},
+			expectedCode:  http.StatusForbidden,
+		},
+		"update status": {
+			path:          "/apis/apps/v1/namespaces/default/deployments/web/status",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "update", APIGroup: "apps", APIVersion: "v1", Resource: "deployments", Subresource: "status", Namespace: "default", Name: "web"},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", nil, "east")},
+			expectedCode:  http.StatusOK,
+			expectedPath:  "/clusters/abc123/apis/apps/v1/namespaces/default/deployments/web/status",
+		},
+		"apply status annotation": {
+			path:          "/apis/apps/v1/namespaces/default/deployments/web",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "patch", APIGroup: "apps", APIVersion: "v1", Resource: "deployments", Namespace: "default", Name: "web"},
+			body:          `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"namespace":"default","name":"web","annotations":{"` + statusaggregation.TargetAnnotation("east") + `":"{}"}}}`,
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", nil, "east")},
+			expectedCode:  http.StatusOK,
+			expectedPath:  "/clusters/abc123/apis/apps/v1/namespaces/default/deployments/web",
+		},
+		"apply status annotation of another target": {
+			path:          "/apis/apps/v1/namespaces/default/deployments/web",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "patch", APIGroup: "apps", APIVersion: "v1", Resource: "deployments", Namespace: "default", Name: "web"},
+			body:          `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"namespace":"default","name":"web","annotations":{"` + statusaggregation.TargetAnnotation("west") + `":"{}"}}}`,
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", nil, "east")},
+			expectedCode:  http.StatusForbidden,
+		},
+		"apply spec": {
+			path:          "/apis/apps/v1/namespaces/default/deployments/web",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "patch", APIGroup: "apps", APIVersion: "v1", Resource: "deployments", Namespace: "default", Name: "web"},
+			body:          `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"namespace":"default","name":"web"},"spec":{"replicas":0}}`,
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", nil, "east")},
+			expectedCode:  http.StatusForbidden,
+		},
+		"workloaddistributions": {
+			path:          "/apis/workload.kcp.io/v1alpha1/workloaddistributions",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "watch", APIGroup: workloadv1alpha1.SchemeGroupVersion.Group, APIVersion: "v1alpha1", Resource: "workloaddistributions"},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", nil, "east")},
+			expectedCode:  http.StatusOK,
+			expectedPath:  "/clusters/abc123/apis/workload.kcp.io/v1alpha1/workloaddistributions",
+		},
+		"workloaddistribution placed elsewhere": {
+			path:          "/apis/workload.kcp.io/v1alpha1/namespaces/default/workloaddistributions/web",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "get", APIGroup: workloadv1alpha1.SchemeGroupVersion.Group, APIVersion: "v1alpha1", Resource: "workloaddistributions", Namespace: "default", Name: "web"},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", nil, "west")},
+			expectedCode:  http.StatusNotFound,
+		},
+		"update workloaddistribution": {
+			path:          "/apis/workload.kcp.io/v1alpha1/namespaces/default/workloaddistributions/web",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "update", APIGroup: workloadv1alpha1.SchemeGroupVersion.Group, APIVersion: "v1alpha1", Resource: "workloaddistributions", Namespace: "default", Name: "web"},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", nil, "east")},
+			expectedCode:  http.StatusForbidden,
+		},
+		"update workloaddistribution status": {
+			path:          "/apis/workload.kcp.io/v1alpha1/namespaces/default/workloaddistributions/web/status",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "update", APIGroup: workloadv1alpha1.SchemeGroupVersion.Group, APIVersion: "v1alpha1", Resource: "workloaddistributions", Subresource: "status", Namespace: "default", Name: "web"},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", nil, "east")},
+			expectedCode:  http.StatusOK,
+			expectedPath:  "/clusters/abc123/apis/workload.kcp.io/v1alpha1/namespaces/default/workloaddistributions/web/status",
+		},
+		"update status of workloaddistribution placed elsewhere": {
+			path:          "/apis/workload.kcp.io/v1alpha1/namespaces/default/workloaddistributions/web/status",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "update", APIGroup: workloadv1alpha1.SchemeGroupVersion.Group, APIVersion: "v1alpha1", Resource: "workloaddistributions", Subresource: "status", Namespace: "default", Name: "web"},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", nil, "west")},
+			expectedCode:  http.StatusNotFound,
+		},
+		"apply workloaddistribution status annotation": {
+			path:          "/apis/workload.kcp.io/v1alpha1/namespaces/default/workloaddistributions/web",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "patch", APIGroup: workloadv1alpha1.SchemeGroupVersion.Group, APIVersion: "v1alpha1", Resource: "workloaddistributions", Namespace: "default", Name: "web"},
+			body:          `{"apiVersion":"workload.kcp.io/v1alpha1","kind":"WorkloadDistribution","metadata":{"namespace":"default","name":"web","annotations":{"` + statusaggregation.TargetAnnotation("east") + `":"{}"}}}`,
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", nil, "east")},
+			expectedCode:  http.StatusOK,
+			expectedPath:  "/clusters/abc123/apis/workload.kcp.io/v1alpha1/namespaces/default/workloaddistributions/web",
+		},
+		"serviceimport": {
+			path:          "/apis/workload.kcp.io/v1alpha1/namespaces/default/serviceimports/web",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "get", APIGroup: workloadv1alpha1.SchemeGroupVersion.Group, APIVersion: "v1alpha1", Resource: "serviceimports", Namespace: "default", Name: "web"},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", nil, "east")},
+			expectedCode:  http.StatusOK,
+			expectedPath:  "/clusters/abc123/apis/workload.kcp.io/v1alpha1/namespaces/default/serviceimports/web",
+		},
+		"serviceimport of a namespace placed elsewhere": {
+			path:          "/apis/workload.kcp.io/v1alpha1/namespaces/default/serviceimports/web",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "get", APIGroup: workloadv1alpha1.SchemeGroupVersion.Group, APIVersion: "v1alpha1", Resource: "serviceimports", Namespace: "default", Name: "web"},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", nil, "west")},
+			expectedCode:  http.StatusNotFound,
+		},
+		"report exported endpoints": {
+			path:          "/apis/workload.kcp.io/v1alpha1/namespaces/default/serviceexports/web",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "patch", APIGroup: workloadv1alpha1.SchemeGroupVersion.Group, APIVersion: "v1alpha1", Resource: "serviceexports", Namespace: "default", Name: "web"},
+			body:          `{"metadata":{"annotations":{"` + servicediscovery.EndpointsAnnotation("east") + `":null}}}`,
+			contentType:   "application/merge-patch+json",
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", nil, "east")},
+			expectedCode:  http.StatusOK,
+			expectedPath:  "/clusters/abc123/apis/workload.kcp.io/v1alpha1/namespaces/default/serviceexports/web",
+		},
+		"report exported endpoints of another target": {
+			path:          "/apis/workload.kcp.io/v1alpha1/namespaces/default/serviceexports/web",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "patch", APIGroup: workloadv1alpha1.SchemeGroupVersion.Group, APIVersion: "v1alpha1", Resource: "serviceexports", Namespace: "default", Name: "web"},
+			body:          `{"metadata":{"annotations":{"` + servicediscovery.EndpointsAnnotation("west") + `":"{}"}}}`,
+			contentType:   "application/merge-patch+json",
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", nil, "east")},
+			expectedCode:  http.StatusForbidden,
+		},
+		"delete serviceexport": {
+			path:          "/apis/workload.kcp.io/v1alpha1/namespaces/default/serviceexports/web",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "delete", APIGroup: workloadv1alpha1.SchemeGroupVersion.Group, APIVersion: "v1alpha1", Resource: "serviceexports", Namespace: "default", Name: "web"},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", nil, "east")},
+			expectedCode:  http.StatusForbidden,
+		},
+		"update referenced secret": {
+			path:          "/api/v1/namespaces/default/secrets/creds",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "update", APIVersion: "v1", Resource: "secrets", Namespace: "default", Name: "creds"},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", transitive, "east")},
+			expectedCode:  http.StatusForbidden,
+		},
+		"no closure": {
+			path:          "/api/v1/namespaces/default/secrets/creds",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "get", APIVersion: "v1", Resource: "secrets", Namespace: "default", Name: "creds"},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", nil, "east")},
+			expectedCode:  http.StatusNotFound,
+		},
+		"protected group": {
+			path:          "/apis/rbac.authorization.k8s.io/v1/clusterroles",
+			info:          &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "list", APIGroup: "rbac.authorization.k8s.io", APIVersion: "v1", Resource: "clusterroles"},
+			distributions: []*workloadv1alpha1.WorkloadDistribution{distribution("Deployment", &workloadv1alpha1.ExportClosure{Mode: workloadv1alpha1.ClosureModeTransitive, References: []workloadv1alpha1.ReferenceField{{Group: "apps", Kind: "Deployment", Path: "spec.role", TargetGroup: "rbac.authorization.k8s.io", TargetKind: "ClusterRole"}}}, "east")},
+			expectedCode:  http.StatusNotFound,
+		},
+		"delete cluster-scoped object": {
+			path:         "/apis/storage.k8s.io/v1/storageclasses/fast",
+			info:         &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "delete", APIGroup: "storage.k8s.io", APIVersion: "v1", Resource: "storageclasses", Name: "fast"},
+			expectedCode: http.StatusForbidden,
+		},
+		"cluster-scoped resource": {
+			path:         "/apis/storage.k8s.io/v1/storageclasses",
+			info:         &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "list", APIGroup: "storage.k8s.io", APIVersion: "v1", Resource: "storageclasses"},
+			expectedCode: http.StatusOK,
+			expectedPath: "/clusters/abc123/apis/storage.k8s.io/v1/storageclasses",
+		},
+		"namespaces": {
+			path:         "/api/v1/namespaces",
+			info:         &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "list", APIVersion: "v1", Resource: "namespaces"},
+			expectedCode: http.StatusOK,
+			expectedPath: "/clusters/abc123/api/v1/namespaces",
+		},
+		"delete namespace": {
+			path:         "/api/v1/namespaces/default",
+			info:         &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "delete", APIVersion: "v1", Resource: "namespaces", Name: "default"},
+			expectedCode: http.StatusForbidden,
+		},
+		"syncconfigurations": {
+			path:         "/apis/workload.kcp.io/v1alpha1/syncconfigurations",
+			info:         &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "watch", APIGroup: workloadv1alpha1.SchemeGroupVersion.Group, APIVersion: "v1alpha1", Resource: "syncconfigurations"},
+			expectedCode: http.StatusOK,
+			expectedPath: "/clusters/abc123/apis/workload.kcp.io/v1alpha1/syncconfigurations",
+		},
+		"propagationpolicies": {
+			path:         "/apis/workload.kcp.io/v1alpha1/propagationpolicies",
+			info:         &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "list", APIGroup: workloadv1alpha1.SchemeGroupVersion.Group, APIVersion: "v1alpha1", Resource: "propagationpolicies"},
+			expectedCode: http.StatusOK,
+			expectedPath: "/clusters/abc123/apis/workload.kcp.io/v1alpha1/propagationpolicies",
+		},
+		"create propagationpolicy": {
+			path:         "/apis/workload.kcp.io/v1alpha1/propagationpolicies",
+			info:         &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "create", APIGroup: workloadv1alpha1.SchemeGroupVersion.Group, APIVersion: "v1alpha1", Resource: "propagationpolicies"},
+			expectedCode: http.StatusForbidden,
+		},
+		"workloadpriorityclass": {
+			path:         "/apis/workload.kcp.io/v1alpha1/workloadpriorityclasses/high",
+			info:         &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "get", APIGroup: workloadv1alpha1.SchemeGroupVersion.Group, APIVersion: "v1alpha1", Resource: "workloadpriorityclasses", Name: "high"},
+			expectedCode: http.StatusOK,
+			expectedPath: "/clusters/abc123/apis/workload.kcp.io/v1alpha1/workloadpriorityclasses/high",
+		},
+		"schedulingdefaults": {
+			path:         "/apis/workload.kcp.io/v1alpha1/schedulingdefaults",
+			info:         &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "list", APIGroup: workloadv1alpha1.SchemeGroupVersion.Group, APIVersion: "v1alpha1", Resource: "schedulingdefaults"},
+			expectedCode: http.StatusOK,
+			expectedPath: "/clusters/abc123/apis/workload.kcp.io/v1alpha1/schedulingdefaults",
+		},
+		"logicalcluster": {
+			path:         "/apis/core.kcp.io/v1alpha1/logicalclusters/cluster",
+			info:         &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "get", APIGroup: corev1alpha1.SchemeGroupVersion.Group, APIVersion: "v1alpha1", Resource: "logicalclusters", Name: "cluster"},
+			expectedCode: http.StatusOK,
+			expectedPath: "/clusters/abc123/apis/core.kcp.io/v1alpha1/logicalclusters/cluster",
+		},
+		"update logicalcluster": {
+			path:         "/apis/core.kcp.io/v1alpha1/logicalclusters/cluster",
+			info:         &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "update", APIGroup: corev1alpha1.SchemeGroupVersion.Group, APIVersion: "v1alpha1", Resource: "logicalclusters", Name: "cluster"},
+			expectedCode: http.StatusForbidden,
+		},
+		"delete workloadpriorityclass": {
+			path:         "/apis/workload.kcp.io/v1alpha1/workloadpriorityclasses/high",
+			info:         &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "delete", APIGroup: workloadv1alpha1.SchemeGroupVersion.Group, APIVersion: "v1alpha1", Resource: "workloadpriorityclasses", Name: "high"},
+			expectedCode: http.StatusForbidden,
+		},
+		"create event": {
+			path:         "/api/v1/namespaces/default/events",
+			info:         &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "create", APIVersion: "v1", Resource: "events", Namespace: "default"},
+			expectedCode: http.StatusOK,
+			expectedPath: "/clusters/abc123/api/v1/namespaces/default/events",
+		},
+		"list events": {
+			path:         "/api/v1/namespaces/default/events",
+			info:         &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "list", APIVersion: "v1", Resource: "events", Namespace: "default"},
+			expectedCode: http.StatusForbidden,
+		},
+		"heartbeat lease": {
+			path:         "/apis/coordination.k8s.io/v1/namespaces/default/leases/" + heartbeat.LeaseName("east"),
+			info:         &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "update", APIGroup: "coordination.k8s.io", APIVersion: "v1", Resource: "leases", Namespace: heartbeat.LeaseNamespace, Name: heartbeat.LeaseName("east")},
+			expectedCode: http.StatusOK,
+			expectedPath: "/clusters/abc123/apis/coordination.k8s.io/v1/namespaces/default/leases/" + heartbeat.LeaseName("east"),
+		},
+		"lease of another target": {
+			path:         "/apis/coordination.k8s.io/v1/namespaces/default/leases/" + heartbeat.LeaseName("west"),
+			info:         &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "get", APIGroup: "coordination.k8s.io", APIVersion: "v1", Resource: "leases", Namespace: heartbeat.LeaseNamespace, Name: heartbeat.LeaseName("west")},
+			expectedCode: http.StatusNotFound,
+		},
+		"list leases": {
+			path:         "/apis/coordination.k8s.io/v1/namespaces/default/leases",
+			info:         &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "list", APIGroup: "coordination.k8s.io", APIVersion: "v1", Resource: "leases", Namespace: heartbeat.LeaseNamespace},
+			expectedCode: http.StatusForbidden,
+		},
+		"watch own synctarget": {
+			path:         "/apis/tmc.kcp.io/v1alpha1/synctargets?fieldSelector=metadata.name%3Deast",
+			info:         &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "watch", APIGroup: tmcv1alpha1.SchemeGroupVersion.Group, APIVersion: "v1alpha1", Resource: "synctargets"},
+			expectedCode: http.StatusOK,
+			expectedPath: "/clusters/abc123/apis/tmc.kcp.io/v1alpha1/synctargets",
+		},
+		"list all synctargets": {
+			path:         "/apis/tmc.kcp.io/v1alpha1/synctargets",
+			info:         &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "list", APIGroup: tmcv1alpha1.SchemeGroupVersion.Group, APIVersion: "v1alpha1", Resource: "synctargets"},
+			expectedCode: http.StatusForbidden,
+		},
+		"another synctarget": {
+			path:         "/apis/tmc.kcp.io/v1alpha1/synctargets/west",
+			info:         &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "get", APIGroup: tmcv1alpha1.SchemeGroupVersion.Group, APIVersion: "v1alpha1", Resource: "synctargets", Name: "west"},
+			expectedCode: http.StatusNotFound,
+		},
+		"not synced": {
+			path:         "/apis/apps/v1/deployments",
+			info:         &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "list", APIGroup: "apps", APIVersion: "v1", Resource: "deployments"},
+			notSynced:    true,
+			expectedCode: http.StatusServiceUnavailable,
+		},
+		"synctargets": {
+			path:         "/apis/tmc.kcp.io/v1alpha1/synctargets/east/status",
+			info:         &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "update", APIGroup: tmcv1alpha1.SchemeGroupVersion.Group, APIVersion: "v1alpha1", Resource: "synctargets", Subresource: "status", Name: "east"},
+			expectedCode: http.StatusOK,
+			expectedPath: "/clusters/abc123/apis/tmc.kcp.io/v1alpha1/synctargets/east/status",
+		},
+		"synctarget spec": {
+			path:         "/apis/tmc.kcp.io/v1alpha1/synctargets/east",
+			info:         &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "patch", APIGroup: tmcv1alpha1.SchemeGroupVersion.Group, APIVersion: "v1alpha1", Resource: "synctargets", Name: "east"},
+			expectedCode: http.StatusForbidden,
+		},
+		"discovery": {
+			path:         "/apis",
+			info:         &genericapirequest.RequestInfo{Path: "/apis"},
+			expectedCode: http.StatusOK,
+			expectedPath: "/clusters/abc123/apis",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var forwarded *http.Request
+			f := &forwarder{
+				forwardedHost: &url.URL{Scheme: "https", Host: "kcp.example.com:6443"},
+				transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					forwarded = req
+					return &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Content-Type": {"application/json"}}, Body: io.NopCloser(strings.NewReader("{}"))}, nil
+				}),
+				hasSynced: func() bool { return !tt.notSynced },
+				getSyncTarget: func(_ logicalcluster.Name, name string) (*tmcv1alpha1.SyncTarget, error) {
+					return &tmcv1alpha1.SyncTarget{
+						ObjectMeta: metav1.ObjectMeta{Name: name},
+						Spec: tmcv1alpha1.SyncTargetSpec{
+							ClusterScopedResources: []tmcv1alpha1.ClusterScopedResource{{Group: "storage.k8s.io", Resource: "storageclasses"}},
+						},
+					}, nil
+				},
+				listDistributions: func(logicalcluster.Name) ([]*workloadv1alpha1.WorkloadDistribution, error) {
+					return tt.distributions, nil
+				},
+				kindFor: func(_ context.Context, _ logicalcluster.Name, gvr schema.GroupVersionResource) (string, error) {
+					if kind, ok := kinds[gvr]; ok {
+						return kind, nil
+					}
+					return "", apierrors.NewNotFound(gvr.GroupResource(), "")
+				},
+				getObject: func(_ context.Context, _ logicalcluster.Name, ref closure.Reference) (*unstructured.Unstructured, error) {
+					for _, obj := range []*unstructured.Unstructured{web, creds} {
+						if obj.GroupVersionKind().GroupKind() == ref.GroupKind && obj.GetNamespace() == ref.Namespace && obj.GetName() == ref.Name {
+							return obj, nil
+						}
+					}
+					return nil, apierrors.NewNotFound(schema.GroupResource{Resource: ref.Kind}, ref.Name)
+				},
+			}
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.body != "" {
+				req = httptest.NewRequest(http.MethodPatch, tt.path, strings.NewReader(tt.body))
+				contentType := "application/apply-patch+yaml"
+				if tt.contentType != "" {
+					contentType = tt.contentType
+				}
+				req.Header.Set("Content-Type", contentType)
+			}
+			req.Header.Set("Authorization", "Bearer syncer-token")
+			ctx := genericapirequest.WithCluster(req.Context(), genericapirequest.Cluster{Name: "abc123"})
+			ctx = genericapirequest.WithUser(ctx, &user.DefaultInfo{Name: "system:serviceaccount:default:kcp-syncer-east"})
+			ctx = genericapirequest.WithRequestInfo(ctx, tt.info)
+			ctx = withSyncTarget(ctx, "east")
+			w := httptest.NewRecorder()
+			f.ServeHTTP(w, req.WithContext(ctx))
+
+			require.Equal(t, tt.expectedCode, w.Code, w.Body.String())
+			if tt.expectedPath == "" {
+				require.Nil(t, forwarded)
+				return
+			}
+			require.NotNil(t, forwarded)
+			require.Equal(t, "kcp.example.com:6443", forwarded.URL.Host)
+			require.Equal(t, tt.expectedPath, forwarded.URL.Path)
+			require.Empty(t, forwarded.Header.Get("Authorization"))
+		})
+	}
+}
+
+func TestForwarderListCache(t *testing.T) {
+	listed := 0
+	f := &forwarder{
+		forwardedHost: &url.URL{Scheme: "https", Host: "kcp.example.com:6443"},
+		transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Content-Type": {"application/json"}}, Body: io.NopCloser(strings.NewReader(`{"kind":"DeploymentList","apiVersion":"apps/v1","items":[]}`))}, nil
+		}),
+		hasSynced: func() bool { return true },
+		getSyncTarget: func(_ logicalcluster.Name, name string) (*tmcv1alpha1.SyncTarget, error) {
+			return &tmcv1alpha1.SyncTarget{ObjectMeta: metav1.ObjectMeta{Name: name}}, nil
+		},
+		listDistributions: func(logicalcluster.Name) ([]*workloadv1alpha1.WorkloadDistribution, error) {
+			var distributions []*workloadv1alpha1.WorkloadDistribution
+			for _, name := range []string{"a", "b"} {
+				distributions = append(distributions, &workloadv1alpha1.WorkloadDistribution{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+					Spec:       workloadv1alpha1.WorkloadDistributionSpec{WorkloadRef: workloadv1alpha1.WorkloadReference{APIVersion: "apps/v1", Kind: "Deployment", Name: name}},
+					Status:     workloadv1alpha1.WorkloadDistributionStatus{Targets: []workloadv1alpha1.TargetPlacement{{SyncTarget: "east"}}},
+				})
+			}
+			return distributions, nil
+		},
+		kindFor: func(context.Context, logicalcluster.Name, schema.GroupVersionResource) (string, error) {
+			return "Deployment", nil
+		},
+		getObject: func(_ context.Context, _ logicalcluster.Name, ref closure.Reference) (*unstructured.Unstructured, error) {
+			return &unstructured.Unstructured{Object: map[string]interface{}{
+				"kind": "Deployment", "apiVersion": "apps/v1",
+				"metadata": map[string]interface{}{"namespace": ref.Namespace, "name": ref.Name},
+			}}, nil
+		},
+		listFor: cachedListers(forwardingregistry.ListCacheOptions{TTL: time.Minute}, func(gvr schema.GroupVersionResource) forwardingregistry.ListerFunc {
+			return func(ctx context.Context, options *metainternalversion.ListOptions) (runtime.Object, error) {
+				listed++
+				namespace, _ := genericapirequest.NamespaceFrom(ctx)
+				list := &unstructured.UnstructuredList{Object: map[string]interface{}{"kind": "DeploymentList", "apiVersion": "apps/v1"}}
+				for _, name := range []string{"a", "b", "c"} {
+					list.Items = append(list.Items, unstructured.Unstructured{Object: map[string]interface{}{
+						"kind": "Deployment", "apiVersion": "apps/v1",
+						"metadata": map[string]interface{}{"namespace": namespace, "name": name},
+					}})
+				}
+				return list, nil
+			}
+		}),
+	}
+	list := func(query string) (int, *unstructured.UnstructuredList) {
+		req := httptest.NewRequest(http.MethodGet, "/apis/apps/v1/namespaces/default/deployments?"+query, nil)
+		ctx := genericapirequest.WithCluster(req.Context(), genericapirequest.Cluster{Name: "abc123"})
+		ctx = genericapirequest.WithUser(ctx, &user.DefaultInfo{Name: "system:serviceaccount:default:kcp-syncer-east"})
+		ctx = genericapirequest.WithRequestInfo(ctx, &genericapirequest.RequestInfo{IsResourceRequest: true, Verb: "list", APIGroup: "apps", APIVersion: "v1", Resource: "deployments", Namespace: "default"})
+		ctx = withSyncTarget(ctx, "east")
+		w := httptest.NewRecorder()
+		f.ServeHTTP(w, req.WithContext(ctx))
+		list := &unstructured.UnstructuredList{}
+		require.NoError(t, list.UnmarshalJSON(w.Body.Bytes()))
+		return w.Code, list
+	}
+
+	code, page := list("resourceVersion=0&limit=2")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, page.Items, 2)
+	require.Equal(t, "default", page.Items[0].GetNamespace())
+	code, page = list("limit=2&continue=" + url.QueryEscape(page.GetContinue()))
+	require.Equal(t, http.StatusOK, code)
+	require.Empty(t, page.Items, "c is not placed on the SyncTarget")
+	require.Empty(t, page.GetContinue())
+	code, _ = list("resourceVersion=0")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, 1, listed, "lists are served from the cache")
+
+	code, _ = list("")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, 1, listed, "lists without resourceVersion=0 are forwarded")
+}
+
+func TestCachedKinds(t *testing.T) {
+	calls := 0
+	kindFor := cachedKinds(func(_ context.Context, _ logicalcluster.Name, gv schema.GroupVersion) (*metav1.APIResourceList, error) {
+		calls++
+		return &metav1.APIResourceList{GroupVersion: gv.String(), APIResources: []metav1.APIResource{{Name: "deployments", Kind: "Deployment"}}}, nil
+	})
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	widgets := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "widgets"}
+
+	for i := 0; i < 2; i++ {
+		kind, err := kindFor(context.Background(), "abc123", deployments)
+		require.NoError(t, err)
+		require.Equal(t, "Deployment", kind)
+		_, err = kindFor(context.Background(), "abc123", widgets)
+		require.True(t, apierrors.IsNotFound(err), err)
+	}
+	require.Equal(t, 2, calls)
+
+	_, err := kindFor(context.Background(), "def456", deployments)
+	require.NoError(t, err)
+	require.Equal(t, 3, calls)
+}
+
+func TestCachedResources(t *testing.T) {
+	calls := 0
+	resourceFor := cachedResources(func(context.Context, logicalcluster.Name) ([]*restmapper.APIGroupResources, error) {
+		calls++
+		return []*restmapper.APIGroupResources{{
+			Group:              metav1.APIGroup{Name: "apps", Versions: []metav1.GroupVersionForDiscovery{{GroupVersion: "apps/v1", Version: "v1"}}, PreferredVersion: metav1.GroupVersionForDiscovery{GroupVersion: "apps/v1", Version: "v1"}},
+			VersionedResources: map[string][]metav1.APIResource{"v1": {{Name: "deployments", Kind: "Deployment", Namespaced: true}}},
+		}}, nil
+	})
+
+	for i := 0; i < 2; i++ {
+		gvr, err := resourceFor(context.Background(), "abc123", schema.GroupKind{Group: "apps", Kind: "Deployment"})
+		require.NoError(t, err)
+		require.Equal(t, schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, gvr)
+		_, err = resourceFor(context.Background(), "abc123", schema.GroupKind{Group: "apps", Kind: "Widget"})
+		require.True(t, apierrors.IsNotFound(err), err)
+	}
+	require.Equal(t, 1, calls)
+}
+
+func TestAssignedKinds(t *testing.T) {
+	d := &workloadv1alpha1.WorkloadDistribution{
+		Spec: workloadv1alpha1.WorkloadDistributionSpec{
+			WorkloadRef: workloadv1alpha1.WorkloadReference{APIVersion: "example.com/v1", Kind: "Widget", Name: "w"},
+			Closure: &workloadv1alpha1.ExportClosure{
+				Mode:       workloadv1alpha1.ClosureModeTransitive,
+				References: []workloadv1alpha1.ReferenceField{{Group: "example.com", Kind: "Widget", Path: "spec.gadget", TargetGroup: "example.com", TargetKind: "Gadget"}},
+			},
+		},
+		Status: workloadv1alpha1.WorkloadDistributionStatus{Targets: []workloadv1alpha1.TargetPlacement{{SyncTarget: "east"}}},
+	}
+	require.ElementsMatch(t, []schema.GroupKind{
+		{Group: "example.com", Kind: "Widget"},
+		{Group: "example.com", Kind: "Gadget"},
+		{Kind: "ConfigMap"},
+		{Kind: "Secret"},
+		{Kind: "ServiceAccount"},
+		{Kind: "PersistentVolumeClaim"},
+	}, assignedKinds("east", []*workloadv1alpha1.WorkloadDistribution{d}).UnsortedList())
+	require.Empty(t, assignedKinds("west", []*workloadv1alpha1.WorkloadDistribution{d}))
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metainternalversionscheme "k8s.io/apimachinery/pkg/apis/meta/internalversion/scheme"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+
+	"github.com/kcp-dev/kcp/pkg/virtual/framework/forwardingregistry"
+)
+
+// cachedListers returns the caching lister of a resource, creating it on
+// first use with the lister of delegateFor.
+func cachedListers(options forwardingregistry.ListCacheOptions, delegateFor func(gvr schema.GroupVersionResource) forwardingregistry.ListerFunc) func(gvr schema.GroupVersionResource) forwardingregistry.ListerFunc {
+	var lock sync.Mutex
+	listers := map[schema.GroupVersionResource]forwardingregistry.ListerFunc{}
+	return func(gvr schema.GroupVersionResource) forwardingregistry.ListerFunc {
+		lock.Lock()
+		defer lock.Unlock()
+		lister, found := listers[gvr]
+		if !found {
+			lister = forwardingregistry.NewCachingLister(delegateFor(gvr), options)
+			listers[gvr] = lister
+		}
+		return lister
+	}
+}
+
+// dynamicLister returns a lister of the resource in the cluster and
+// namespace of the request context.
+func dynamicLister(client kcpdynamic.ClusterInterface, gvr schema.GroupVersionResource) forwardingregistry.ListerFunc {
+	return func(ctx context.Context, options *metainternalversion.ListOptions) (runtime.Object, error) {
+		cluster, err := genericapirequest.ValidClusterFrom(ctx)
+		if err != nil {
+			return nil, err
+		}
+		namespace, _ := genericapirequest.NamespaceFrom(ctx)
+		v1Options := metav1.ListOptions{}
+		if err := metainternalversion.Convert_internalversion_ListOptions_To_v1_ListOptions(options, &v1Options, nil); err != nil {
+			return nil, err
+		}
+		return client.Cluster(cluster.Name.Path()).Resource(gvr).Namespace(namespace).List(ctx, v1Options)
+	}
+}
+
+// serveCachedList serves list requests with resourceVersion=0, and the
+// continue tokens of cached lists, from the list cache, dropping the objects
+// filter rejects if it is not nil. It returns false for other requests,
+// which are forwarded.
+func (f *forwarder) serveCachedList(w http.ResponseWriter, req *http.Request, info *genericapirequest.RequestInfo, filter objectFilter) bool {
+	if f.listFor == nil || info.Verb != "list" {
+		return false
+	}
+	query := req.URL.Query()
+	if query.Get("resourceVersion") != "0" && !strings.HasPrefix(query.Get("continue"), forwardingregistry.ListCacheContinuePrefix) {
+		return false
+	}
+	// Lists are cached as JSON, tables and protobuf are forwarded.
+	if accept := req.Header.Get("Accept"); strings.Contains(accept, "protobuf") || strings.Contains(accept, "as=") {
+		return false
+	}
+
+	gv := schema.GroupVersion{Group: info.APIGroup, Version: info.APIVersion}
+	options := &metainternalversion.ListOptions{}
+	if err := metainternalversionscheme.ParameterCodec.DecodeParameters(query, metav1.SchemeGroupVersion, options); err != nil {
+		responsewriters.ErrorNegotiated(apierrors.NewBadRequest(err.Error()), errorCodecs, gv, w, req)
+		return true
+	}
+	if options.Watch {
+		return false
+	}
+
+	ctx := genericapirequest.WithNamespace(req.Context(), info.Namespace)
+	obj, err := f.listFor(gv.WithResource(info.Resource))(ctx, options)
+	if err != nil {
+		responsewriters.ErrorNegotiated(err, errorCodecs, gv, w, req)
+		return true
+	}
+	list, ok := obj.(*unstructured.UnstructuredList)
+	if !ok {
+		responsewriters.ErrorNegotiated(apierrors.NewInternalError(fmt.Errorf("unexpected list type %T", obj)), errorCodecs, gv, w, req)
+		return true
+	}
+	if filter != nil {
+		filtered := *list
+		filtered.Items = nil
+		for i := range list.Items {
+			visible, err := filter(ctx, &list.Items[i])
+			if err != nil {
+				responsewriters.ErrorNegotiated(apierrors.NewInternalError(err), errorCodecs, gv, w, req)
+				return true
+			}
+			if visible {
+				filtered.Items = append(filtered.Items, list.Items[i])
+			}
+		}
+		list = &filtered
+	}
+	data, err := list.MarshalJSON()
+	if err != nil {
+		responsewriters.ErrorNegotiated(apierrors.NewInternalError(err), errorCodecs, gv, w, req)
+		return true
+	}
+	w.Header().Set("Content-Type", runtime.ContentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+	return true
+}
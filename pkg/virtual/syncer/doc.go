@@ -0,0 +1,74 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package syncer and its sub-packages provide the Syncer Virtual Workspace,
+// through which the syncer of a SyncTarget accesses the workspace of the
+// SyncTarget:
+//
+//	/services/syncer/<syncer-id>/clusters/<logical cluster>/...
+//
+// The syncer ID is the name of the service account the syncer authenticates
+// as in the workspace, see SyncerID. Syncers authenticate with the
+// service-account token of the kubeconfig they were onboarded with, not with
+// a client certificate: the identity is checked on the authenticated user,
+// whatever the credential, so a certificate-based identity can be added to
+// the authenticator without changing the virtual workspace. Requests of
+// other users, and of the service account of the same name in other
+// workspaces, are forbidden.
+//
+// Requests are forwarded to the workspace with the credentials of the
+// virtual workspace, after it has checked that the syncer may make them.
+// Syncers may
+//
+//   - get, list and watch their own SyncTarget, and update and patch its
+//     status,
+//   - get, update and patch their heartbeat Lease,
+//   - get, list and watch namespaces and SyncConfigurations,
+//   - get, list and watch the WorkloadDistributions that sync their workload
+//     to the SyncTarget, and
+//   - get, list and watch the objects placed on the SyncTarget, update and
+//     patch their status, and apply the annotation with their status on the
+//     SyncTarget, see statusaggregation.TargetAnnotation. These are the
+//     workloads synced to the SyncTarget, the objects exported with them,
+//     and the objects of the cluster-scoped resources of the SyncTarget.
+//
+// Workloads are synced to the SyncTargets of the waves rolled out so far,
+// see WorkloadDistribution.SyncedTargets. Requests for other resources and
+// objects are answered with NotFound, and lists and watches only return
+// objects the syncer may get. Discovery is forwarded unfiltered.
+package syncer
+
+import (
+	"strings"
+
+	"github.com/kcp-dev/kcp/pkg/reconciler/tmc/onboarding"
+)
+
+const VirtualWorkspaceName string = "syncer"
+
+// SyncerID returns the syncer ID of a SyncTarget.
+func SyncerID(syncTarget string) string {
+	return onboarding.SyncerName(syncTarget)
+}
+
+// SyncTargetName returns the name of the SyncTarget of a syncer ID.
+func SyncTargetName(syncerID string) (string, bool) {
+	name, found := strings.CutPrefix(syncerID, onboarding.SyncerName(""))
+	if !found || name == "" {
+		return "", false
+	}
+	return name, true
+}
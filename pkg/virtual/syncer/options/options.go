@@ -0,0 +1,122 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"k8s.io/client-go/rest"
+
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	kcpkubernetesclientset "github.com/kcp-dev/client-go/kubernetes"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/transport/compression"
+	"github.com/kcp-dev/kcp/pkg/virtual/framework/forwardingregistry"
+	"github.com/kcp-dev/kcp/pkg/virtual/framework/rootapiserver"
+	"github.com/kcp-dev/kcp/pkg/virtual/syncer"
+	"github.com/kcp-dev/kcp/pkg/virtual/syncer/builder"
+)
+
+type Syncer struct {
+	// ListCacheTTL is how long lists with resourceVersion=0 are served from
+	// a cache. Lists are not cached if zero.
+	ListCacheTTL time.Duration
+	// Compression are the algorithms request and response bodies are
+	// compressed with if the syncer accepts them, in preference order.
+	// Bodies are not compressed if empty.
+	Compression []string
+}
+
+func New() *Syncer {
+	return &Syncer{
+		ListCacheTTL: 5 * time.Second,
+		Compression:  []string{string(compression.Zstd), string(compression.Gzip)},
+	}
+}
+
+func (o *Syncer) AddFlags(flags *pflag.FlagSet, prefix string) {
+	if o == nil {
+		return
+	}
+
+	flags.DurationVar(&o.ListCacheTTL, prefix+"syncer-list-cache-ttl", o.ListCacheTTL, "How long lists with resourceVersion=0 through the syncer virtual workspace are served from a cache, paginated with limit and continue, e.g. when syncers reconnect. Cached lists are per syncer. Disabled if zero.")
+	flags.StringSliceVar(&o.Compression, prefix+"syncer-compression", o.Compression, "Algorithms bodies through the syncer virtual workspace are compressed with if the syncer accepts them, in preference order. One of zstd and gzip. Disabled if empty.")
+}
+
+func (o *Syncer) Validate(flagPrefix string) []error {
+	if o == nil {
+		return nil
+	}
+	errs := []error{}
+
+	if o.ListCacheTTL < 0 {
+		errs = append(errs, fmt.Errorf("--%ssyncer-list-cache-ttl must not be negative", flagPrefix))
+	}
+	if err := o.compressionSettings().Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("--%ssyncer-compression: %w", flagPrefix, err))
+	}
+
+	return errs
+}
+
+func (o *Syncer) NewVirtualWorkspaces(
+	rootPathPrefix string,
+	config *rest.Config,
+) (workspaces []rootapiserver.NamedVirtualWorkspace, err error) {
+	config = rest.AddUserAgent(rest.CopyConfig(config), "syncer-virtual-workspace")
+	kubeClusterClient, err := kcpkubernetesclientset.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	dynamicClusterClient, err := kcpdynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var listCache *forwardingregistry.ListCacheOptions
+	if o.ListCacheTTL > 0 {
+		listCache = &forwardingregistry.ListCacheOptions{TTL: o.ListCacheTTL}
+	}
+
+	var compressionSettings *compression.Settings
+	if len(o.Compression) > 0 {
+		settings := o.compressionSettings()
+		compressionSettings = &settings
+	}
+
+	return builder.BuildVirtualWorkspace(
+		path.Join(rootPathPrefix, syncer.VirtualWorkspaceName),
+		config,
+		dynamicClusterClient,
+		kubeClusterClient,
+		listCache,
+		compressionSettings,
+	)
+}
+
+func (o *Syncer) compressionSettings() compression.Settings {
+	settings := compression.DefaultSettings()
+	settings.Algorithms = nil
+	for _, algorithm := range o.Compression {
+		settings.Algorithms = append(settings.Algorithms, compression.Algorithm(algorithm))
+	}
+	return settings
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DataLocation is a hint that a dataset or service, named like the
+// DataLocation, is present on some SyncTargets. PlacementPolicies refer to
+// it in data affinity terms to place workloads close to their data.
+//
+// +crd
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:scope=Cluster,categories=kcp
+// +kubebuilder:printcolumn:name="Size",type="string",JSONPath=`.spec.size`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type DataLocation struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the desired state.
+	// +optional
+	Spec DataLocationSpec `json:"spec,omitempty"`
+}
+
+// DataLocationSpec holds where the dataset is present.
+type DataLocationSpec struct {
+	// SyncTargets are the names of the SyncTargets the dataset is present on.
+	//
+	// +optional
+	// +listType=set
+	SyncTargets []string `json:"syncTargets,omitempty"`
+
+	// Size of the dataset. Placing a workload away from its data is assumed
+	// to cost transfer in proportion to the size, so that placement prefers
+	// the targets hosting the largest part of the data of a workload.
+	// Defaults to 1Gi.
+	//
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+}
+
+// DefaultDataSize is the size of datasets that do not declare one.
+var DefaultDataSize = resource.MustParse("1Gi")
+
+// DataLocationList is a list of DataLocation resources.
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DataLocationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []DataLocation `json:"items"`
+}
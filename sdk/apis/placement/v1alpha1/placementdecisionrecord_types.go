@@ -0,0 +1,163 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/workload/v1alpha1"
+)
+
+// PlacementDecisionRecord records one placement decision for a
+// WorkloadDistribution of the workspace: what was decided and why, for
+// auditing where workloads went. The placement controller creates them and
+// purges them according to its retention policy. The name of a record is
+// its decision ID.
+//
+// +crd
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:scope=Cluster,categories=kcp
+// +kubebuilder:printcolumn:name="Namespace",type="string",JSONPath=`.spec.namespace`
+// +kubebuilder:printcolumn:name="Distribution",type="string",JSONPath=`.spec.distribution`
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=`.spec.status`
+// +kubebuilder:printcolumn:name="Time",type="date",JSONPath=`.spec.time`
+type PlacementDecisionRecord struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the recorded decision.
+	// +optional
+	Spec PlacementDecisionRecordSpec `json:"spec,omitempty"`
+}
+
+// PlacementDecisionRecordSpec is a recorded placement decision.
+type PlacementDecisionRecordSpec struct {
+	// Namespace of the WorkloadDistribution.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	Namespace string `json:"namespace"`
+
+	// Distribution is the name of the WorkloadDistribution.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Distribution string `json:"distribution"`
+
+	// Policy is the PlacementPolicy the decision was made with.
+	//
+	// +optional
+	Policy string `json:"policy,omitempty"`
+
+	// PolicyRevision is the revision of the policy the decision was made
+	// with.
+	//
+	// +optional
+	PolicyRevision string `json:"policyRevision,omitempty"`
+
+	// Strategy the decision was made with.
+	//
+	// +optional
+	Strategy PlacementStrategy `json:"strategy,omitempty"`
+
+	// Status is the outcome of the decision: Succeeded, Failed or Pending.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=Succeeded;Failed;Pending
+	Status string `json:"status"`
+
+	// Message explains the outcome.
+	//
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Targets are the chosen SyncTargets.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=syncTarget
+	Targets []workloadv1alpha1.TargetPlacement `json:"targets,omitempty"`
+
+	// Rejected maps names of infeasible SyncTargets to the reason.
+	//
+	// +optional
+	Rejected map[string]string `json:"rejected,omitempty"`
+
+	// Scores maps names of feasible SyncTargets to their weighted score.
+	//
+	// +optional
+	Scores map[string]int32 `json:"scores,omitempty"`
+
+	// ScorerScores maps names of scorers to the scores they gave the
+	// feasible SyncTargets, before weighting.
+	//
+	// +optional
+	ScorerScores map[string]map[string]int32 `json:"scorerScores,omitempty"`
+
+	// Time the decision was made at.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	Time metav1.Time `json:"time"`
+
+	// Duration is how long the decision took.
+	//
+	// +optional
+	Duration metav1.Duration `json:"duration,omitempty"`
+
+	// Attempts are earlier attempts with the same outcome, e.g. retries
+	// after conflicts.
+	//
+	// +optional
+	// +listType=atomic
+	Attempts []PlacementDecisionAttempt `json:"attempts,omitempty"`
+}
+
+// PlacementDecisionAttempt is an attempt of making a decision.
+type PlacementDecisionAttempt struct {
+	// Time of the attempt.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	Time metav1.Time `json:"time"`
+
+	// Status is the outcome of the attempt.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	Status string `json:"status"`
+
+	// Message explains the outcome.
+	//
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// PlacementDecisionRecordList is a list of PlacementDecisionRecord resources.
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type PlacementDecisionRecordList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []PlacementDecisionRecord `json:"items"`
+}
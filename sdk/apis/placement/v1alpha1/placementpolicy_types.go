@@ -0,0 +1,746 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+)
+
+// PlacementPolicy describes where the workloads distributed with it are
+// placed. Changes to the placement-relevant part of the spec are recorded as
+// PlacementPolicyRevisions and rolled out to the affected workloads in
+// stages.
+//
+// +crd
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories=kcp
+// +kubebuilder:printcolumn:name="Strategy",type="string",JSONPath=`.spec.strategy`
+// +kubebuilder:printcolumn:name="Current",type="string",JSONPath=`.status.currentRevision`,priority=1
+// +kubebuilder:printcolumn:name="Update",type="string",JSONPath=`.status.updateRevision`,priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type PlacementPolicy struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the desired state.
+	// +optional
+	Spec PlacementPolicySpec `json:"spec,omitempty"`
+
+	// Status communicates the observed state.
+	// +optional
+	Status PlacementPolicyStatus `json:"status,omitempty"`
+}
+
+// PlacementPolicySpec holds the desired state of the PlacementPolicy.
+type PlacementPolicySpec struct {
+	// LocationSelector selects the SyncTargets eligible for placement by
+	// their labels. An empty selector selects all SyncTargets.
+	//
+	// +optional
+	LocationSelector *metav1.LabelSelector `json:"locationSelector,omitempty"`
+
+	// SyncTargetGroup is the name of a SyncTargetGroup. Only its members are
+	// eligible for placement, and the replicas of a workload are split over
+	// the chosen members in proportion to their weights.
+	//
+	// +optional
+	SyncTargetGroup string `json:"syncTargetGroup,omitempty"`
+
+	// Constraints are CEL expressions evaluated for every SyncTarget. A
+	// SyncTarget must satisfy all required ones to be eligible for
+	// placement, in addition to the location selector, and is preferred by
+	// the Constraints scorer by the weights of the preferred ones it
+	// satisfies.
+	//
+	// +optional
+	// +listType=atomic
+	Constraints []TargetConstraint `json:"constraints,omitempty"`
+
+	// DataAffinity places workloads close to the datasets and services they
+	// use, as registered in DataLocations.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=dataLocation
+	DataAffinity []DataAffinityTerm `json:"dataAffinity,omitempty"`
+
+	// WorkloadAffinity places workloads with, or never with, other
+	// workloads, selected by the labels of their WorkloadDistributions.
+	//
+	// +optional
+	// +listType=atomic
+	WorkloadAffinity []WorkloadAffinityTerm `json:"workloadAffinity,omitempty"`
+
+	// RequiredEndpoints are endpoints the workloads must reach, by the
+	// names of the reachability probes of the SyncTargets. The Reachability
+	// scorer penalizes targets that cannot reach them.
+	//
+	// +optional
+	// +listType=set
+	RequiredEndpoints []string `json:"requiredEndpoints,omitempty"`
+
+	// Requirements are capabilities a SyncTarget must have to be eligible
+	// for placement, matched against the capabilities its syncer reports.
+	//
+	// +optional
+	Requirements *TargetRequirements `json:"requirements,omitempty"`
+
+	// SchedulerProfile selects scheduler plugins registered with the
+	// placement controller, which filter, score and bind SyncTargets in
+	// addition to the built-in ones.
+	//
+	// +optional
+	SchedulerProfile *SchedulerProfile `json:"schedulerProfile,omitempty"`
+
+	// Extender delegates filtering, scoring and binding of SyncTargets to an
+	// out-of-process placement extender configured on the placement
+	// controller, after the scheduler plugins.
+	//
+	// +optional
+	Extender *ExtenderReference `json:"extender,omitempty"`
+
+	// TopologyKey is the SyncTarget label whose values are the failure
+	// domains the HighAvailability strategy places in. SyncTargets without
+	// the label are not eligible. Defaults to the location of the SyncTargets.
+	//
+	// +optional
+	TopologyKey string `json:"topologyKey,omitempty"`
+
+	// TopologySpreadConstraints spread the SyncTargets a workload is placed
+	// on over topology domains, like pod topology spread constraints spread
+	// pods over nodes. The domains of a SyncTarget are the values of the
+	// topology key in its labels or, if it has none, in the labels of its
+	// cells, so that a SyncTarget spanning several zones counts in each.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=topologyKey
+	// +listMapKey=whenUnsatisfiable
+	TopologySpreadConstraints []TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// Tolerations let workloads be placed in SyncTarget cells with matching
+	// taints. A SyncTarget is eligible if one of its cells has only tolerated
+	// NoSchedule and NoExecute taints, and SyncTargets without cells always
+	// are. Untolerated PreferNoSchedule taints lower the score of a
+	// SyncTarget. Workloads are moved off SyncTargets whose cells get
+	// NoExecute taints they do not tolerate, or tolerate for less time than
+	// passed since the taint was added.
+	//
+	// +optional
+	// +listType=atomic
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Strategy decides how many of the eligible SyncTargets a workload is
+	// placed on. Defaults to the default strategy of the SchedulingProfile
+	// of the workspace.
+	//
+	// +optional
+	// +kubebuilder:validation:Enum=Singleton;HighAvailability;Spread;WeightedSpread
+	Strategy PlacementStrategy `json:"strategy,omitempty"`
+
+	// NumberOfTargets is the number of SyncTargets a workload is placed on
+	// with the HighAvailability and Spread strategies. Spread places on all
+	// eligible targets if unset.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	NumberOfTargets *int32 `json:"numberOfTargets,omitempty"`
+
+	// LocationWeights are the weights of the locations of SyncTargets with
+	// the WeightedSpread strategy: weights 3 for eu and 1 for us place three
+	// replicas in eu for every one in us. The replicas of a location are
+	// split evenly between its SyncTargets. SyncTargets in locations
+	// without a positive weight are not eligible, and the replicas of
+	// locations without feasible SyncTargets go to the other locations in
+	// proportion to their weights.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=location
+	LocationWeights []LocationWeight `json:"locationWeights,omitempty"`
+
+	// Rollout controls how changes of this policy reach affected workloads.
+	// Changes apply to all workloads at once if unset.
+	//
+	// +optional
+	Rollout *PolicyRollout `json:"rollout,omitempty"`
+
+	// DecisionTTL is how long a placement decision is trusted. Decisions
+	// older than that are revalidated against the current SyncTargets, and
+	// workloads whose targets are no longer ready or no longer satisfy the
+	// policy are placed anew. Decisions are only revisited on changes if
+	// unset.
+	// +optional
+	DecisionTTL *metav1.Duration `json:"decisionTTL,omitempty"`
+
+	// RevisionHistoryLimit is the number of old PlacementPolicyRevisions
+	// kept for rollback.
+	//
+	// +optional
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=0
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+}
+
+// TargetConstraint is a CEL expression that decides whether a SyncTarget is
+// eligible for, or preferred by, placement.
+type TargetConstraint struct {
+	// Name identifies the constraint in messages and placement decisions.
+	// Names are unique within a policy.
+	//
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Expression is a CEL expression that evaluates to a bool. The SyncTarget
+	// is available as the variable "target", e.g.
+	// `target.metadata.labels["tier"] == "gold"`.
+	//
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Expression string `json:"expression"`
+
+	// Message is reported for SyncTargets that do not satisfy the
+	// expression. Defaults to the expression.
+	//
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Type is Required to only place on SyncTargets satisfying the
+	// expression, or Preferred to prefer them by the Constraints scorer.
+	// SyncTargets the expression fails to evaluate for do not satisfy it.
+	//
+	// +optional
+	// +kubebuilder:default=Required
+	// +kubebuilder:validation:Enum=Required;Preferred
+	Type ConstraintType `json:"type,omitempty"`
+
+	// Weight of a preferred constraint relative to the other preferred
+	// constraints. Defaults to 1.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Weight int32 `json:"weight,omitempty"`
+}
+
+// ConstraintType is how strictly a constraint applies.
+type ConstraintType string
+
+const (
+	// ConstraintRequired only places on SyncTargets satisfying the constraint.
+	ConstraintRequired ConstraintType = "Required"
+	// ConstraintPreferred prefers SyncTargets satisfying the constraint.
+	ConstraintPreferred ConstraintType = "Preferred"
+)
+
+// TargetRequirements are capabilities required from a SyncTarget.
+type TargetRequirements struct {
+	// MinKubernetesVersion is the oldest Kubernetes version of the physical
+	// cluster the workloads run on, e.g. v1.29.
+	//
+	// +optional
+	MinKubernetesVersion string `json:"minKubernetesVersion,omitempty"`
+
+	// FeatureGates must be enabled on the physical cluster, e.g.
+	// SidecarContainers.
+	//
+	// +optional
+	// +listType=set
+	FeatureGates []string `json:"featureGates,omitempty"`
+
+	// APIs must be served by the physical cluster, as apiVersion, e.g.
+	// snapshot.storage.k8s.io/v1.
+	//
+	// +optional
+	// +listType=set
+	APIs []string `json:"apis,omitempty"`
+
+	// Addons must be installed on the physical cluster: the names of CSI
+	// drivers, e.g. ebs.csi.aws.com, or of extended node resources, e.g.
+	// nvidia.com/gpu for the GPU operator.
+	//
+	// +optional
+	// +listType=set
+	Addons []string `json:"addons,omitempty"`
+
+	// Resources must be allocatable on the physical cluster, summed over
+	// its ready and schedulable nodes as reported by the syncer, e.g.
+	// nvidia.com/gpu: 2.
+	//
+	// +optional
+	Resources corev1.ResourceList `json:"resources,omitempty"`
+}
+
+// TopologySpreadConstraint bounds how unevenly the SyncTargets of a
+// workload are spread over topology domains.
+type TopologySpreadConstraint struct {
+	// TopologyKey is the label of SyncTargets or of their cells whose values
+	// are the topology domains, e.g. topology.kubernetes.io/zone.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	TopologyKey string `json:"topologyKey"`
+
+	// MaxSkew is the largest allowed difference between the number of
+	// chosen SyncTargets in a domain and in the domain with the fewest,
+	// among the domains of all feasible SyncTargets.
+	//
+	// +optional
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	MaxSkew int32 `json:"maxSkew,omitempty"`
+
+	// WhenUnsatisfiable is DoNotSchedule to place on fewer SyncTargets
+	// rather than exceed the skew, and to only place on SyncTargets with the
+	// topology key, or ScheduleAnyway to only prefer SyncTargets that keep
+	// the skew low.
+	//
+	// +optional
+	// +kubebuilder:default=DoNotSchedule
+	// +kubebuilder:validation:Enum=DoNotSchedule;ScheduleAnyway
+	WhenUnsatisfiable corev1.UnsatisfiableConstraintAction `json:"whenUnsatisfiable,omitempty"`
+}
+
+// LocationWeight is the weight of a location.
+type LocationWeight struct {
+	// Location of SyncTargets.
+	//
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Location string `json:"location"`
+
+	// Weight of the location relative to the other locations.
+	//
+	// +required
+	// +kubebuilder:validation:Minimum=0
+	Weight int32 `json:"weight"`
+}
+
+// SchedulerProfile selects scheduler plugins.
+type SchedulerProfile struct {
+	// Plugins are the scheduler plugins to run, in order. Placement fails
+	// while one of them is not registered.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Plugins []SchedulerPlugin `json:"plugins,omitempty"`
+}
+
+// SchedulerPlugin selects a scheduler plugin.
+type SchedulerPlugin struct {
+	// Name the plugin is registered with.
+	//
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Weight of the scores of the plugin relative to the built-in scorers,
+	// from 0 to 100. Only applies to plugins that score SyncTargets.
+	// Defaults to 20.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	Weight *int32 `json:"weight,omitempty"`
+}
+
+// ExtenderReference selects a placement extender.
+type ExtenderReference struct {
+	// Name the extender is configured with on the placement controller.
+	// Placement fails while it is not configured.
+	//
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Weight of the scores of the extender relative to the built-in
+	// scorers, from 0 to 100. Defaults to 20.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	Weight *int32 `json:"weight,omitempty"`
+}
+
+// DataAffinityTerm relates placement to the SyncTargets a dataset is present on.
+type DataAffinityTerm struct {
+	// DataLocation is the name of the DataLocation of the dataset.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	DataLocation string `json:"dataLocation"`
+
+	// Type is Required to only place on SyncTargets the dataset is present
+	// on, or Preferred to prefer them by the DataGravity scorer.
+	//
+	// +optional
+	// +kubebuilder:default=Preferred
+	// +kubebuilder:validation:Enum=Required;Preferred
+	Type DataAffinityType `json:"type,omitempty"`
+}
+
+// DataAffinityType is how strictly a data affinity term applies.
+type DataAffinityType string
+
+const (
+	// DataAffinityRequired only places on SyncTargets hosting the dataset.
+	DataAffinityRequired DataAffinityType = "Required"
+	// DataAffinityPreferred prefers SyncTargets hosting the dataset.
+	DataAffinityPreferred DataAffinityType = "Preferred"
+)
+
+// WorkloadAffinityTerm relates placement to the SyncTargets other workloads
+// are placed on.
+type WorkloadAffinityTerm struct {
+	// Relation is PlaceWith to place on SyncTargets the selected workloads
+	// are placed on, or NeverColocateWith to place on SyncTargets none of
+	// them is placed on.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=PlaceWith;NeverColocateWith
+	Relation WorkloadAffinityRelation `json:"relation"`
+
+	// Selector selects the other workloads by the labels of their
+	// WorkloadDistributions. A workload never selects itself.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// Namespaces are the namespaces of the other workloads. Defaults to the
+	// namespace of the workload.
+	//
+	// +optional
+	// +listType=set
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Type is Required to only place on SyncTargets satisfying the term, or
+	// Preferred to prefer them by the WorkloadAffinity scorer. A required
+	// PlaceWith term selecting the workload itself is ignored while no
+	// selected workload is placed, so that the first of a group can be
+	// placed.
+	//
+	// +optional
+	// +kubebuilder:default=Preferred
+	// +kubebuilder:validation:Enum=Required;Preferred
+	Type WorkloadAffinityType `json:"type,omitempty"`
+
+	// Weight of a preferred term relative to the other preferred terms.
+	//
+	// +optional
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Weight int32 `json:"weight,omitempty"`
+}
+
+// WorkloadAffinityRelation is how a workload relates to the workloads a
+// workload affinity term selects.
+type WorkloadAffinityRelation string
+
+const (
+	// WorkloadAffinityPlaceWith places on SyncTargets the selected workloads
+	// are placed on.
+	WorkloadAffinityPlaceWith WorkloadAffinityRelation = "PlaceWith"
+	// WorkloadAffinityNeverColocateWith places on SyncTargets none of the
+	// selected workloads is placed on.
+	WorkloadAffinityNeverColocateWith WorkloadAffinityRelation = "NeverColocateWith"
+)
+
+// WorkloadAffinityType is how strictly a workload affinity term applies.
+type WorkloadAffinityType string
+
+const (
+	// WorkloadAffinityRequired only places on SyncTargets satisfying the term.
+	WorkloadAffinityRequired WorkloadAffinityType = "Required"
+	// WorkloadAffinityPreferred prefers SyncTargets satisfying the term.
+	WorkloadAffinityPreferred WorkloadAffinityType = "Preferred"
+)
+
+// PlacementStrategy is the strategy used to choose SyncTargets.
+type PlacementStrategy string
+
+const (
+	// PlacementStrategySingleton places a workload on exactly one SyncTarget.
+	PlacementStrategySingleton PlacementStrategy = "Singleton"
+	// PlacementStrategyHighAvailability places a workload on at least two
+	// SyncTargets in different locations.
+	PlacementStrategyHighAvailability PlacementStrategy = "HighAvailability"
+	// PlacementStrategySpread spreads replicas of a workload evenly over
+	// SyncTargets.
+	PlacementStrategySpread PlacementStrategy = "Spread"
+	// PlacementStrategyWeightedSpread spreads replicas of a workload over
+	// SyncTargets in proportion to the weights of their locations.
+	PlacementStrategyWeightedSpread PlacementStrategy = "WeightedSpread"
+)
+
+// PolicyRollout configures the staged rollout of a new policy revision. The
+// new revision first applies to a canary share of the affected workloads,
+// and is promoted to all of them once the canaries are placed and healthy
+// for the analysis period, and approved if approval is required, or rolled
+// back if too many canaries fail.
+type PolicyRollout struct {
+	// CanaryPercent is the share of affected workloads, at least one, that
+	// receive a new revision first.
+	//
+	// +optional
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	CanaryPercent int32 `json:"canaryPercent,omitempty"`
+
+	// AnalysisPeriod is how long all canaries must be placed and ready
+	// before the revision is promoted.
+	//
+	// +optional
+	AnalysisPeriod *metav1.Duration `json:"analysisPeriod,omitempty"`
+
+	// MaxFailedPercent is the share of failed canaries, in percent, tolerated
+	// before the revision is rolled back.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	MaxFailedPercent int32 `json:"maxFailedPercent,omitempty"`
+
+	// RequireApproval makes a rollout wait for approval once its canaries
+	// passed the analysis, before the revision is promoted to all affected
+	// workloads. Rollouts are approved by setting status.rollout.approval,
+	// e.g. with kubectl tmc approve.
+	//
+	// +optional
+	RequireApproval bool `json:"requireApproval,omitempty"`
+}
+
+// PlacementPolicyStatus communicates the observed state of the PlacementPolicy.
+type PlacementPolicyStatus struct {
+	// ObservedGeneration is the generation the status was computed for.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// CurrentRevision is the revision all affected workloads that are not
+	// canaries are placed with.
+	// +optional
+	CurrentRevision string `json:"currentRevision,omitempty"`
+
+	// UpdateRevision is the revision of the current spec.
+	// +optional
+	UpdateRevision string `json:"updateRevision,omitempty"`
+
+	// Rollout reports the progress of rolling out UpdateRevision.
+	// +optional
+	Rollout *PolicyRolloutStatus `json:"rollout,omitempty"`
+
+	// History records the approval gates passed by rollouts, the latest
+	// last, at most MaxRolloutHistory of them.
+	// +optional
+	// +listType=atomic
+	History []RolloutGateRecord `json:"history,omitempty"`
+
+	// Current processing state of the PlacementPolicy.
+	// +optional
+	Conditions conditionsv1alpha1.Conditions `json:"conditions,omitempty"`
+}
+
+// PolicyRolloutPhase is the phase of a policy rollout.
+type PolicyRolloutPhase string
+
+const (
+	// PolicyRolloutProgressing means canaries are being placed with the
+	// update revision and analyzed.
+	PolicyRolloutProgressing PolicyRolloutPhase = "Progressing"
+	// PolicyRolloutPromoted means the update revision applies to all
+	// affected workloads.
+	PolicyRolloutPromoted PolicyRolloutPhase = "Promoted"
+	// PolicyRolloutRolledBack means too many canaries failed and all
+	// affected workloads are back on the current revision.
+	PolicyRolloutRolledBack PolicyRolloutPhase = "RolledBack"
+	// PolicyRolloutAwaitingApproval means the canaries passed the analysis
+	// and the rollout waits for approval to promote the update revision.
+	PolicyRolloutAwaitingApproval PolicyRolloutPhase = "AwaitingApproval"
+)
+
+// PolicyRolloutStatus reports the progress of a staged rollout.
+type PolicyRolloutStatus struct {
+	// Revision is the revision being rolled out.
+	Revision string `json:"revision"`
+
+	// Phase is the phase of the rollout.
+	Phase PolicyRolloutPhase `json:"phase"`
+
+	// StartTime is when the canaries received the revision.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// Canaries is the number of workloads placed with the revision.
+	Canaries int32 `json:"canaries"`
+
+	// HealthyCanaries is the number of canaries placed and ready.
+	HealthyCanaries int32 `json:"healthyCanaries"`
+
+	// FailedCanaries is the number of canaries that failed placement or are
+	// not ready.
+	FailedCanaries int32 `json:"failedCanaries"`
+
+	// PausedWorkloads is the number of affected workloads that are paused
+	// and stay on the revision they are placed with.
+	// +optional
+	PausedWorkloads int32 `json:"pausedWorkloads,omitempty"`
+
+	// Approval approves promoting the revision. It is set with a status
+	// update, and the approver and time are recorded by admission.
+	// +optional
+	Approval *RolloutApproval `json:"approval,omitempty"`
+}
+
+// RolloutApproval approves promoting a rollout.
+type RolloutApproval struct {
+	// Revision is the revision approved. It must be the revision of the
+	// rollout.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Revision string `json:"revision"`
+
+	// ApprovedBy is the user who approved. It is set by admission.
+	// +optional
+	ApprovedBy string `json:"approvedBy,omitempty"`
+
+	// ApprovedAt is when the rollout was approved. It is set by admission.
+	// +optional
+	ApprovedAt *metav1.Time `json:"approvedAt,omitempty"`
+
+	// Message explains the approval.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// RolloutGate is a gate a rollout passes.
+type RolloutGate string
+
+const (
+	// RolloutGateApproval is passed when an approved rollout is promoted.
+	RolloutGateApproval RolloutGate = "Approval"
+)
+
+// MaxRolloutHistory bounds the history of a PlacementPolicy.
+const MaxRolloutHistory = 10
+
+// RolloutGateRecord records a gate passed by a rollout.
+type RolloutGateRecord struct {
+	// Gate is the gate passed.
+	Gate RolloutGate `json:"gate"`
+
+	// Revision is the revision rolled out.
+	Revision string `json:"revision"`
+
+	// ApprovedBy is the user who opened the gate.
+	// +optional
+	ApprovedBy string `json:"approvedBy,omitempty"`
+
+	// ApprovedAt is when the gate was opened.
+	// +optional
+	ApprovedAt *metav1.Time `json:"approvedAt,omitempty"`
+
+	// PassedAt is when the rollout passed the gate.
+	PassedAt metav1.Time `json:"passedAt"`
+
+	// Message is the message of the approval.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// PlacementPolicyList is a list of PlacementPolicy resources.
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type PlacementPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []PlacementPolicy `json:"items"`
+}
+
+// PlacementPolicyRevision is an immutable snapshot of the placement-relevant
+// spec of a PlacementPolicy. It is named <policy>-<hash> and owned by the
+// policy.
+//
+// +crd
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:scope=Cluster,categories=kcp
+// +kubebuilder:printcolumn:name="Policy",type="string",JSONPath=`.metadata.labels.placement\.kcp\.io/policy`
+// +kubebuilder:printcolumn:name="Revision",type="integer",JSONPath=`.revision`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type PlacementPolicyRevision struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Revision orders the revisions of a policy, starting at 1.
+	Revision int64 `json:"revision"`
+
+	// Spec is the policy spec of this revision, without rollout settings.
+	Spec PlacementPolicySpec `json:"spec"`
+}
+
+// PlacementPolicyRevisionList is a list of PlacementPolicyRevision resources.
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type PlacementPolicyRevisionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []PlacementPolicyRevision `json:"items"`
+}
+
+const (
+	// LabelPolicy is set on PlacementPolicyRevisions to the name of their policy.
+	LabelPolicy = "placement.kcp.io/policy"
+)
+
+// Conditions and ConditionReasons for the PlacementPolicy object.
+const (
+	// PolicyRolloutHealthy means the latest rollout was promoted or is
+	// progressing within its failure budget.
+	PolicyRolloutHealthy conditionsv1alpha1.ConditionType = "RolloutHealthy"
+
+	// RolloutRolledBackReason indicates the update revision was rolled back.
+	RolloutRolledBackReason = "RolledBack"
+	// RolloutProgressingReason indicates canaries are being analyzed.
+	RolloutProgressingReason = "Progressing"
+)
+
+func (in *PlacementPolicy) SetConditions(c conditionsv1alpha1.Conditions) {
+	in.Status.Conditions = c
+}
+
+func (in *PlacementPolicy) GetConditions() conditionsv1alpha1.Conditions {
+	return in.Status.Conditions
+}
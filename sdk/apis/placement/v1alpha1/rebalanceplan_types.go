@@ -0,0 +1,164 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RebalancePlanName is the name of the RebalancePlan of a namespace.
+const RebalancePlanName = "rebalance"
+
+// RebalancePlan describes the moves of workloads in a namespace the
+// right-placement recommender intends to make, before any is made: the
+// expected improvement of the placements and the disruption of moving the
+// workloads. The moves are made when the plan is accepted, by an operator
+// setting spec.action to Accepted, or by the recommender when it approves
+// plans automatically and the plan is within its thresholds.
+//
+// +crd
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:scope=Namespaced,categories=kcp
+// +kubebuilder:printcolumn:name="Moves",type="integer",JSONPath=`.spec.moveCount`
+// +kubebuilder:printcolumn:name="Score Delta",type="integer",JSONPath=`.spec.scoreDelta`
+// +kubebuilder:printcolumn:name="Skew",type="integer",JSONPath=`.spec.skewAfter`,priority=1
+// +kubebuilder:printcolumn:name="Disrupted",type="integer",JSONPath=`.spec.disruptedReplicas`
+// +kubebuilder:printcolumn:name="Action",type="string",JSONPath=`.spec.action`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type RebalancePlan struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the plan and the action taken on it.
+	// +optional
+	Spec RebalancePlanSpec `json:"spec,omitempty"`
+}
+
+// RebalancePlanSpec holds the intended moves, their expected effect, and
+// the action taken on them.
+type RebalancePlanSpec struct {
+	// Moves are the intended moves, one per RightPlacementRecommendation.
+	//
+	// +optional
+	Moves []RebalanceMove `json:"moves,omitempty"`
+
+	// MoveCount is the number of moves.
+	//
+	// +optional
+	MoveCount int32 `json:"moveCount,omitempty"`
+
+	// ScoreDelta is the expected improvement of the placements, the sum of
+	// the score deltas of the moves.
+	//
+	// +optional
+	ScoreDelta int32 `json:"scoreDelta,omitempty"`
+
+	// SkewBefore is the difference between the most and the least replicas
+	// of the workloads of the namespace placed on a SyncTarget today.
+	//
+	// +optional
+	SkewBefore int32 `json:"skewBefore,omitempty"`
+
+	// SkewAfter is the skew after the moves.
+	//
+	// +optional
+	SkewAfter int32 `json:"skewAfter,omitempty"`
+
+	// DisruptedReplicas is the disruption cost of the plan: the replicas
+	// restarted on another SyncTarget by the moves. Workloads without
+	// replicas count as one.
+	//
+	// +optional
+	DisruptedReplicas int32 `json:"disruptedReplicas,omitempty"`
+
+	// Approval is Automatic when the recommender accepted the plan within
+	// its thresholds.
+	//
+	// +optional
+	// +kubebuilder:validation:Enum=Manual;Automatic
+	Approval RebalanceApproval `json:"approval,omitempty"`
+
+	// Action is the decision on the plan. Accepting it accepts all its moves.
+	//
+	// +optional
+	// +kubebuilder:default=Pending
+	// +kubebuilder:validation:Enum=Pending;Accepted;Dismissed
+	Action RecommendationAction `json:"action,omitempty"`
+}
+
+// RebalanceMove is an intended move of a workload.
+type RebalanceMove struct {
+	// Recommendation is the name of the RightPlacementRecommendation of the
+	// move, in the same namespace.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	Recommendation string `json:"recommendation"`
+
+	// DistributionRef references the WorkloadDistribution in the same
+	// namespace.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	DistributionRef LocalReference `json:"distributionRef"`
+
+	// From is the SyncTarget the workload is placed on.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	From string `json:"from"`
+
+	// To is the SyncTarget the workload moves to.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	To string `json:"to"`
+
+	// ScoreDelta is the expected improvement of the move, the sum of its
+	// cost saving and headroom gain in percent.
+	//
+	// +optional
+	ScoreDelta int32 `json:"scoreDelta,omitempty"`
+
+	// Replicas moved, for scalable workloads.
+	//
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+}
+
+// RebalanceApproval is how a RebalancePlan is accepted.
+type RebalanceApproval string
+
+const (
+	// RebalanceApprovalManual means plans are accepted by an operator.
+	RebalanceApprovalManual RebalanceApproval = "Manual"
+	// RebalanceApprovalAutomatic means plans within the thresholds of the
+	// recommender are accepted by the recommender.
+	RebalanceApprovalAutomatic RebalanceApproval = "Automatic"
+)
+
+// RebalancePlanList is a list of RebalancePlan resources.
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type RebalancePlanList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []RebalancePlan `json:"items"`
+}
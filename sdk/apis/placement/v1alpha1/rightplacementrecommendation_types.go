@@ -0,0 +1,182 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+)
+
+// RightPlacementRecommendation recommends moving a distributed workload from
+// one SyncTarget to another, as found by analyzing the placement history and
+// the utilization of the SyncTargets. Operators accept a recommendation by
+// setting spec.action to Accepted, which adds a target override to the
+// WorkloadDistribution.
+//
+// +crd
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,categories=kcp
+// +kubebuilder:printcolumn:name="Distribution",type="string",JSONPath=`.spec.distributionRef.name`
+// +kubebuilder:printcolumn:name="From",type="string",JSONPath=`.spec.from`
+// +kubebuilder:printcolumn:name="To",type="string",JSONPath=`.spec.to`
+// +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=`.spec.reason`
+// +kubebuilder:printcolumn:name="Action",type="string",JSONPath=`.spec.action`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type RightPlacementRecommendation struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the desired state.
+	// +optional
+	Spec RightPlacementRecommendationSpec `json:"spec,omitempty"`
+
+	// Status communicates the observed state.
+	// +optional
+	Status RightPlacementRecommendationStatus `json:"status,omitempty"`
+}
+
+// RightPlacementRecommendationSpec holds the recommendation and the action
+// taken on it.
+type RightPlacementRecommendationSpec struct {
+	// DistributionRef references the WorkloadDistribution in the same
+	// namespace.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	DistributionRef LocalReference `json:"distributionRef"`
+
+	// From is the SyncTarget the workload is placed on.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	From string `json:"from"`
+
+	// To is the SyncTarget the workload is recommended to move to.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	To string `json:"to"`
+
+	// Reason is the main benefit of the move.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=LowerCost;MoreHeadroom
+	Reason RecommendationReason `json:"reason"`
+
+	// CostSavingPercent is the estimated cost saving of the move.
+	//
+	// +optional
+	CostSavingPercent int32 `json:"costSavingPercent,omitempty"`
+
+	// HeadroomGainPercent is the gain of allocatable CPU share of the target
+	// capacity, in percentage points.
+	//
+	// +optional
+	HeadroomGainPercent int32 `json:"headroomGainPercent,omitempty"`
+
+	// Message explains the recommendation.
+	//
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Action is the decision of the operator on the recommendation.
+	//
+	// +optional
+	// +kubebuilder:default=Pending
+	// +kubebuilder:validation:Enum=Pending;Accepted;Dismissed
+	Action RecommendationAction `json:"action,omitempty"`
+}
+
+// LocalReference references an object in the same namespace by name.
+type LocalReference struct {
+	// Name of the object.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// RecommendationReason is the main benefit of a recommended move.
+type RecommendationReason string
+
+const (
+	// RecommendationReasonLowerCost means the recommended target is cheaper.
+	RecommendationReasonLowerCost RecommendationReason = "LowerCost"
+	// RecommendationReasonMoreHeadroom means the recommended target has more
+	// allocatable capacity left.
+	RecommendationReasonMoreHeadroom RecommendationReason = "MoreHeadroom"
+)
+
+// RecommendationAction is the decision of an operator on a recommendation.
+type RecommendationAction string
+
+const (
+	// RecommendationActionPending means the recommendation awaits a decision.
+	RecommendationActionPending RecommendationAction = "Pending"
+	// RecommendationActionAccepted means the recommendation is applied to the
+	// WorkloadDistribution.
+	RecommendationActionAccepted RecommendationAction = "Accepted"
+	// RecommendationActionDismissed means the recommendation is not applied,
+	// and not recommended again.
+	RecommendationActionDismissed RecommendationAction = "Dismissed"
+)
+
+// RightPlacementRecommendationStatus communicates the observed state of the
+// RightPlacementRecommendation.
+type RightPlacementRecommendationStatus struct {
+	// Current processing state of the RightPlacementRecommendation.
+	// +optional
+	Conditions conditionsv1alpha1.Conditions `json:"conditions,omitempty"`
+}
+
+// RightPlacementRecommendationList is a list of RightPlacementRecommendation
+// resources.
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type RightPlacementRecommendationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []RightPlacementRecommendation `json:"items"`
+}
+
+// Conditions and ConditionReasons for the RightPlacementRecommendation object.
+const (
+	// RecommendationApplied means the target override of an accepted
+	// recommendation was added to the WorkloadDistribution.
+	RecommendationApplied conditionsv1alpha1.ConditionType = "Applied"
+
+	// DistributionNotFoundReason indicates the referenced WorkloadDistribution
+	// does not exist.
+	DistributionNotFoundReason = "DistributionNotFound"
+)
+
+func (in *RightPlacementRecommendation) SetConditions(c conditionsv1alpha1.Conditions) {
+	in.Status.Conditions = c
+}
+
+func (in *RightPlacementRecommendation) GetConditions() conditionsv1alpha1.Conditions {
+	return in.Status.Conditions
+}
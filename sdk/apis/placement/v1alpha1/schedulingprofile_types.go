@@ -0,0 +1,188 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SchedulingProfile tunes how the placement engine ranks SyncTargets in a
+// workspace. Only the profile named "default" is used; workspaces without it
+// use the default profile:
+//
+//	scorers:
+//	- name: Locality
+//	  weight: 50
+//	- name: Balance
+//	  weight: 30
+//	- name: Cost
+//	  weight: 20
+//	- name: DataGravity
+//	  weight: 50
+//	- name: Reachability
+//	  weight: 50
+//	defaultStrategy: Spread
+//
+// +crd
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:scope=Cluster,categories=kcp
+// +kubebuilder:printcolumn:name="Strategy",type="string",JSONPath=`.spec.defaultStrategy`
+// +kubebuilder:printcolumn:name="Frozen",type="string",JSONPath=`.spec.freeze.reason`,priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type SchedulingProfile struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the desired state.
+	// +optional
+	Spec SchedulingProfileSpec `json:"spec,omitempty"`
+}
+
+// SchedulingProfileSpec holds the desired state of the SchedulingProfile.
+type SchedulingProfileSpec struct {
+	// Scorers overrides the weights of scorers, or disables them. Scorers
+	// that are not listed keep their default weight.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Scorers []ScorerConfig `json:"scorers,omitempty"`
+
+	// DefaultStrategy is the strategy of PlacementPolicies in the workspace
+	// that do not set one. Defaults to Spread.
+	//
+	// +optional
+	// +kubebuilder:validation:Enum=Singleton;HighAvailability;Spread
+	DefaultStrategy PlacementStrategy `json:"defaultStrategy,omitempty"`
+
+	// Freeze stops placement in the workspace, e.g. during incident
+	// response: WorkloadDistributions keep their current SyncTargets, and
+	// neither new placements nor moves to other targets happen. Workloads
+	// keep being synced and their status keeps being reported.
+	//
+	// +optional
+	Freeze *PlacementFreeze `json:"freeze,omitempty"`
+}
+
+// PlacementFreeze freezes placement in a workspace.
+type PlacementFreeze struct {
+	// Reason tells why placement is frozen.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Reason string `json:"reason"`
+
+	// Until unfreezes placement automatically at the given time. Placement
+	// stays frozen until the freeze is removed if unset.
+	//
+	// +optional
+	Until *metav1.Time `json:"until,omitempty"`
+
+	// FrozenBy is the user who froze placement. It is set on admission.
+	//
+	// +optional
+	FrozenBy string `json:"frozenBy,omitempty"`
+}
+
+// IsActive returns whether placement is frozen at the given time.
+func (f *PlacementFreeze) IsActive(now time.Time) bool {
+	if f == nil {
+		return false
+	}
+	return f.Until == nil || now.Before(f.Until.Time)
+}
+
+// ScorerName names a scorer of the placement engine.
+type ScorerName string
+
+const (
+	// ScorerLocality prefers SyncTargets in the locations the workload and
+	// its dependencies are placed in.
+	ScorerLocality ScorerName = "Locality"
+	// ScorerBalance prefers SyncTargets with more allocatable capacity left.
+	ScorerBalance ScorerName = "Balance"
+	// ScorerCost prefers SyncTargets with a lower cost annotation.
+	ScorerCost ScorerName = "Cost"
+	// ScorerDataGravity prefers SyncTargets hosting more of the data in the
+	// preferred data affinity terms of the policy, avoiding data transfer.
+	ScorerDataGravity ScorerName = "DataGravity"
+	// ScorerReachability prefers SyncTargets whose syncer reaches the
+	// required endpoints of the policy.
+	ScorerReachability ScorerName = "Reachability"
+	// ScorerCarbon prefers SyncTargets running on electricity with a lower
+	// carbon intensity.
+	ScorerCarbon ScorerName = "Carbon"
+	// ScorerTaintToleration prefers SyncTargets with fewer PreferNoSchedule
+	// taints on their cells that the policy does not tolerate.
+	ScorerTaintToleration ScorerName = "TaintToleration"
+	// ScorerWorkloadAffinity prefers SyncTargets satisfying more of the
+	// preferred workload affinity terms of the policy.
+	ScorerWorkloadAffinity ScorerName = "WorkloadAffinity"
+	// ScorerConstraints prefers SyncTargets satisfying more of the preferred
+	// CEL constraints of the policy.
+	ScorerConstraints ScorerName = "Constraints"
+)
+
+// Scorers are all scorers of the placement engine.
+var Scorers = []ScorerName{ScorerLocality, ScorerBalance, ScorerCost, ScorerDataGravity, ScorerReachability, ScorerCarbon, ScorerTaintToleration, ScorerWorkloadAffinity, ScorerConstraints}
+
+const (
+	// MaxScorerWeight is the highest weight of a scorer.
+	MaxScorerWeight = 100
+
+	// DefaultSchedulingProfileName is the name of the SchedulingProfile used
+	// in a workspace.
+	DefaultSchedulingProfileName = "default"
+)
+
+// ScorerConfig configures a scorer.
+type ScorerConfig struct {
+	// Name of the scorer.
+	//
+	// +required
+	// +kubebuilder:validation:Enum=Locality;Balance;Cost;DataGravity;Reachability;Carbon;TaintToleration;WorkloadAffinity;Constraints
+	Name ScorerName `json:"name"`
+
+	// Weight of the scorer relative to the other scorers, from 0 to 100.
+	// Defaults to the weight in the default profile.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	Weight *int32 `json:"weight,omitempty"`
+
+	// Disabled turns the scorer off.
+	//
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// SchedulingProfileList is a list of SchedulingProfile resources.
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type SchedulingProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []SchedulingProfile `json:"items"`
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+)
+
+// WorkloadPlacementAdvanced is a composite placement policy. Its rules
+// select workloads by the labels of their WorkloadDistributions and place
+// them with different strategies and constraints, e.g. databases on
+// HighAvailability and batch jobs on a Singleton in a cheap location.
+// WorkloadDistributions use it by referencing it with the kind
+// WorkloadPlacementAdvanced in their policyRef.
+//
+// Rules are evaluated by descending priority, and rules of equal priority
+// in the order they are listed. The first rule selecting a workload places
+// it.
+//
+// +crd
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories=kcp
+// +kubebuilder:printcolumn:name="Rules",type="integer",JSONPath=`.spec.rules.length`,priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type WorkloadPlacementAdvanced struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the desired state.
+	// +optional
+	Spec WorkloadPlacementAdvancedSpec `json:"spec,omitempty"`
+
+	// Status communicates the observed state.
+	// +optional
+	Status WorkloadPlacementAdvancedStatus `json:"status,omitempty"`
+}
+
+// WorkloadPlacementAdvancedSpec holds the desired state of the
+// WorkloadPlacementAdvanced.
+type WorkloadPlacementAdvancedSpec struct {
+	// Rules map workloads to the placement they get.
+	//
+	// +required
+	// +kubebuilder:validation:MinItems=1
+	// +listType=map
+	// +listMapKey=name
+	Rules []PlacementRule `json:"rules"`
+}
+
+// PlacementRule places the workloads it selects.
+type PlacementRule struct {
+	// Name identifies the rule.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Priority orders the rules. Rules with a higher priority are evaluated
+	// first.
+	//
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// WorkloadSelector selects WorkloadDistributions by their labels. An
+	// empty selector selects all workloads.
+	//
+	// +optional
+	WorkloadSelector *metav1.LabelSelector `json:"workloadSelector,omitempty"`
+
+	// Placement is how the selected workloads are placed. Its rollout and
+	// revision history settings do not apply: changes of a rule apply to
+	// all its workloads at once.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	Placement PlacementPolicySpec `json:"placement"`
+}
+
+// MaxListedWorkloads bounds the workloads listed per rule in the status.
+const MaxListedWorkloads = 100
+
+// WorkloadPlacementAdvancedStatus communicates the observed state of the
+// WorkloadPlacementAdvanced.
+type WorkloadPlacementAdvancedStatus struct {
+	// Rules lists the workloads matched by each rule.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Rules []PlacementRuleStatus `json:"rules,omitempty"`
+
+	// Unmatched is the number of workloads referencing this object that no
+	// rule selects. They are not placed.
+	//
+	// +optional
+	Unmatched int32 `json:"unmatched,omitempty"`
+
+	// Current processing state of the WorkloadPlacementAdvanced.
+	// +optional
+	Conditions conditionsv1alpha1.Conditions `json:"conditions,omitempty"`
+}
+
+// PlacementRuleStatus lists the workloads placed by a rule.
+type PlacementRuleStatus struct {
+	// Name is the name of the rule.
+	Name string `json:"name"`
+
+	// Matched is the number of workloads the rule places.
+	Matched int32 `json:"matched"`
+
+	// Workloads are the first MaxListedWorkloads of these workloads, as
+	// namespace/name of their WorkloadDistributions, sorted.
+	//
+	// +optional
+	// +listType=atomic
+	Workloads []string `json:"workloads,omitempty"`
+}
+
+// WorkloadPlacementAdvancedList is a list of WorkloadPlacementAdvanced resources.
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type WorkloadPlacementAdvancedList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []WorkloadPlacementAdvanced `json:"items"`
+}
+
+// Conditions and ConditionReasons for the WorkloadPlacementAdvanced object.
+const (
+	// RulesValid means all rules have valid workload selectors.
+	RulesValid conditionsv1alpha1.ConditionType = "RulesValid"
+
+	// InvalidWorkloadSelectorReason indicates a rule has an invalid
+	// workload selector. The rule selects no workloads.
+	InvalidWorkloadSelectorReason = "InvalidWorkloadSelector"
+)
+
+func (in *WorkloadPlacementAdvanced) SetConditions(c conditionsv1alpha1.Conditions) {
+	in.Status.Conditions = c
+}
+
+func (in *WorkloadPlacementAdvanced) GetConditions() conditionsv1alpha1.Conditions {
+	return in.Status.Conditions
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EvictionPolicy deschedules workloads from SyncTargets that stay tainted,
+// over-utilized or unhealthy for longer than spec.evictAfter. It sets
+// spec.evictAfter of such SyncTargets, so that the placement engine moves
+// their workloads to other targets, and clears it once the SyncTarget
+// recovers.
+//
+// A SyncTarget is tainted when a cell has a NoExecute taint, and unhealthy
+// when its SyncerReady or HeartbeatHealthy condition is false. Workloads
+// whose placement policy tolerates the NoExecute taints of one of the cells
+// stay on SyncTargets evicted only for their taints.
+//
+// +crd
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories=kcp
+// +kubebuilder:printcolumn:name="Evict After",type="string",JSONPath=`.spec.evictAfter`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type EvictionPolicy struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the desired state.
+	// +optional
+	Spec EvictionPolicySpec `json:"spec,omitempty"`
+
+	// Status communicates the observed state.
+	// +optional
+	Status EvictionPolicyStatus `json:"status,omitempty"`
+}
+
+// EvictionPolicySpec holds the desired state of the EvictionPolicy.
+type EvictionPolicySpec struct {
+	// SyncTargetSelector selects the SyncTargets the policy applies to. All
+	// SyncTargets of the workspace if empty.
+	//
+	// +optional
+	SyncTargetSelector *metav1.LabelSelector `json:"syncTargetSelector,omitempty"`
+
+	// EvictAfter is how long a SyncTarget may be tainted, over-utilized or
+	// unhealthy before its workloads are evicted.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	EvictAfter metav1.Duration `json:"evictAfter"`
+
+	// MaxUtilizationPercent is the share of the cpu or memory capacity of a
+	// SyncTarget above which it is over-utilized. SyncTargets are not
+	// evicted for their utilization if unset.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	MaxUtilizationPercent *int32 `json:"maxUtilizationPercent,omitempty"`
+}
+
+// EvictionPolicyStatus communicates the observed state of the EvictionPolicy.
+type EvictionPolicyStatus struct {
+	// SyncTargets are the selected SyncTargets that are tainted,
+	// over-utilized or unhealthy.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	SyncTargets []SyncTargetEviction `json:"syncTargets,omitempty"`
+}
+
+// SyncTargetEviction is a SyncTarget whose workloads are evicted, or will
+// be unless it recovers in time.
+type SyncTargetEviction struct {
+	// Name of the SyncTarget.
+	Name string `json:"name"`
+
+	// Reason tells why the SyncTarget is evicted, e.g. "is unhealthy".
+	Reason string `json:"reason"`
+
+	// Since is when the SyncTarget was first seen tainted, over-utilized or
+	// unhealthy.
+	Since metav1.Time `json:"since"`
+
+	// Evicted is whether the workloads of the SyncTarget are evicted.
+	// +optional
+	Evicted bool `json:"evicted,omitempty"`
+}
+
+// EvictionPolicyList is a list of EvictionPolicy resources.
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type EvictionPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []EvictionPolicy `json:"items"`
+}
+
+const (
+	// AnnotationEvictedBy is set on SyncTargets evicted by an EvictionPolicy
+	// to the name of the policy. Only then the policy clears spec.evictAfter
+	// once the SyncTarget recovers, so that evictions set by hand are kept.
+	AnnotationEvictedBy = "tmc.kcp.io/evicted-by"
+
+	// AnnotationEvictedForTaints is set to "true" on SyncTargets an
+	// EvictionPolicy evicts only because of NoExecute taints of their cells.
+	// The placement engine then moves only the workloads that do not
+	// tolerate the taints.
+	AnnotationEvictedForTaints = "tmc.kcp.io/evicted-for-taints"
+)
@@ -0,0 +1,137 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+)
+
+// MultiClusterResourceQuota limits the resources the workloads of a
+// workspace consume on all the SyncTargets they are placed on together.
+// Workloads are not placed on more SyncTargets, and not scaled up, when
+// their usage would exceed the quota.
+//
+// A workload placed on a SyncTarget uses there the cpu and memory requested
+// by the pods of its pod template times its replicas, and counts once
+// towards count/<resource>.<group>, e.g. count/deployments.apps.
+//
+// +crd
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories=kcp
+// +kubebuilder:printcolumn:name="Exceeded",type="string",JSONPath=`.status.conditions[?(@.type=="QuotaExceeded")].status`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type MultiClusterResourceQuota struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the desired state.
+	// +optional
+	Spec MultiClusterResourceQuotaSpec `json:"spec,omitempty"`
+
+	// Status communicates the observed state.
+	// +optional
+	Status MultiClusterResourceQuotaStatus `json:"status,omitempty"`
+}
+
+// MultiClusterResourceQuotaSpec holds the desired state of the
+// MultiClusterResourceQuota.
+type MultiClusterResourceQuotaSpec struct {
+	// Hard is the limit of every resource: cpu, memory, or
+	// count/<resource>.<group> for the number of placements of workloads of
+	// a resource. Resources not listed are not limited.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	Hard corev1.ResourceList `json:"hard"`
+}
+
+// MultiClusterResourceQuotaStatus communicates the observed state of the
+// MultiClusterResourceQuota.
+type MultiClusterResourceQuotaStatus struct {
+	// Hard is the enforced limit of every resource.
+	// +optional
+	Hard corev1.ResourceList `json:"hard,omitempty"`
+
+	// Used is the usage of the limited resources by all workloads of the
+	// workspace.
+	// +optional
+	Used corev1.ResourceList `json:"used,omitempty"`
+
+	// SyncTargets break down the usage by SyncTarget.
+	// +optional
+	// +listType=map
+	// +listMapKey=syncTarget
+	SyncTargets []SyncTargetResourceUsage `json:"syncTargets,omitempty"`
+
+	// Current processing state of the MultiClusterResourceQuota.
+	// +optional
+	Conditions conditionsv1alpha1.Conditions `json:"conditions,omitempty"`
+}
+
+// SyncTargetResourceUsage is the usage of the limited resources by the
+// workloads placed on one SyncTarget.
+type SyncTargetResourceUsage struct {
+	// SyncTarget is the name of the SyncTarget.
+	SyncTarget string `json:"syncTarget"`
+
+	// Used is the usage of the limited resources on the SyncTarget.
+	// +optional
+	Used corev1.ResourceList `json:"used,omitempty"`
+}
+
+// MultiClusterResourceQuotaList is a list of MultiClusterResourceQuota
+// resources.
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type MultiClusterResourceQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []MultiClusterResourceQuota `json:"items"`
+}
+
+// Conditions and ConditionReasons for the MultiClusterResourceQuota object.
+const (
+	// QuotaExceeded is true while the usage exceeds the limit of a resource,
+	// e.g. after the quota was lowered. Existing placements are kept.
+	QuotaExceeded conditionsv1alpha1.ConditionType = "QuotaExceeded"
+
+	// UsageExceedsHardReason indicates that the usage exceeds the limit of
+	// a resource.
+	UsageExceedsHardReason = "UsageExceedsHard"
+)
+
+// AnnotationResourceUsage is set on WorkloadDistributions in workspaces with
+// MultiClusterResourceQuotas to the JSON encoded resources the workload uses
+// on every SyncTarget it is placed on. It is maintained by the resource
+// quota controller.
+const AnnotationResourceUsage = "tmc.kcp.io/resource-usage"
+
+func (in *MultiClusterResourceQuota) SetConditions(c conditionsv1alpha1.Conditions) {
+	in.Status.Conditions = c
+}
+
+func (in *MultiClusterResourceQuota) GetConditions() conditionsv1alpha1.Conditions {
+	return in.Status.Conditions
+}
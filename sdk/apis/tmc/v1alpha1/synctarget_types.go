@@ -0,0 +1,997 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+)
+
+// SyncTarget describes a member cluster capable of running workloads.
+//
+// +crd
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories=kcp
+// +kubebuilder:printcolumn:name="Location",type="string",JSONPath=`.spec.location`,priority=1
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=`.status.conditions[?(@.type=="Ready")].status`,priority=2
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type SyncTarget struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the desired state.
+	// +optional
+	Spec SyncTargetSpec `json:"spec,omitempty"`
+
+	// Status communicates the observed state.
+	// +optional
+	Status SyncTargetStatus `json:"status,omitempty"`
+}
+
+// SyncTargetSpec holds the desired state of the SyncTarget (from the client).
+type SyncTargetSpec struct {
+	// Location is the name of the location this target belongs to, usually
+	// a region or availability zone. Placement selects targets by location.
+	//
+	// +optional
+	Location string `json:"location,omitempty"`
+
+	// Unschedulable controls cluster schedulability of new workloads. By
+	// default, cluster is schedulable. Workloads already placed on an
+	// unschedulable target stay, unless it is drained.
+	//
+	// +optional
+	// +kubebuilder:default=false
+	Unschedulable bool `json:"unschedulable"`
+
+	// Paused stops the syncer of the target from changing the physical
+	// cluster and from writing status back to kcp, and keeps new workloads
+	// off the target. Unlike Unschedulable, which only cordons the target,
+	// pausing also holds the workloads already placed on it as they are.
+	// Work queued while paused is carried out once the target is resumed.
+	//
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// EvictAfter controls cluster schedulability of new and existing workloads.
+	// After the EvictAfter time, any workload scheduled to the cluster
+	// will be unassigned from the cluster.
+	// By default, workloads scheduled to the cluster are not evicted.
+	//
+	// +optional
+	EvictAfter *metav1.Time `json:"evictAfter,omitempty"`
+
+	// Drain moves the workloads placed on the target to other targets a few
+	// at a time, e.g. ahead of maintenance of the physical cluster. The
+	// target is made unschedulable first. Progress is reported in
+	// status.drain and the Drained condition. Removing Drain stops the
+	// drain, but leaves the target unschedulable.
+	//
+	// +optional
+	Drain *SyncTargetDrain `json:"drain,omitempty"`
+
+	// Cells are the failure domains within the target, e.g. zones or racks,
+	// carrying topology labels and taints.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Cells []Cell `json:"cells,omitempty"`
+
+	// DeliveryMode selects how desired state reaches the physical cluster.
+	// Syncer, the default, has the workload syncer pull from the syncer
+	// virtual workspace. ManifestWork renders ManifestWork bundles that an
+	// Open Cluster Management work agent applies instead.
+	//
+	// +optional
+	// +kubebuilder:validation:Enum=Syncer;ManifestWork
+	DeliveryMode DeliveryMode `json:"deliveryMode,omitempty"`
+
+	// DisruptionWindow is claimed by the physical cluster or its upgrade
+	// tooling ahead of planned disruptions. While the window is open, no new
+	// workloads are placed on the target, and Singleton and HighAvailability
+	// workloads are moved to other targets. They move back once the window
+	// has passed.
+	//
+	// +optional
+	DisruptionWindow *DisruptionWindow `json:"disruptionWindow,omitempty"`
+
+	// Guardrails limit what is synced to the target, so that a single
+	// workspace cannot overwhelm a small cluster. A target exceeding them
+	// gets the SchedulingDisabled condition and receives no new workloads,
+	// while workloads already placed on it stay.
+	//
+	// +optional
+	Guardrails *SyncTargetGuardrails `json:"guardrails,omitempty"`
+
+	// CapacityProvider is a webhook answering capacity and feasibility for
+	// the target, for targets whose capacity is not the sum of their nodes,
+	// e.g. VM pools, serverless backends or special hardware. Its answers
+	// take precedence over the capacity reported by the syncer.
+	//
+	// +optional
+	CapacityProvider *CapacityProvider `json:"capacityProvider,omitempty"`
+
+	// ClusterScopedResources are the cluster-scoped resources, e.g. CRDs,
+	// ClusterRoles or PriorityClasses, that workspaces may sync to the
+	// target, with the policy applied when objects of different workspaces
+	// or of the cluster administrator collide on a name. Cluster-scoped
+	// resources not listed here are not synced.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=group
+	// +listMapKey=resource
+	ClusterScopedResources []ClusterScopedResource `json:"clusterScopedResources,omitempty"`
+
+	// ReachabilityProbes are endpoints the syncer probes from the physical
+	// cluster, e.g. image registries, databases or other targets. The
+	// results are reported in status.reachability, and the Reachability
+	// scorer of the placement engine prefers targets reaching the endpoints
+	// that policies require.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ReachabilityProbes []ReachabilityProbe `json:"reachabilityProbes,omitempty"`
+
+	// ClientRateLimit limits the requests of the syncer to the physical
+	// cluster. Unset fields default to the syncer flags. The syncer lowers
+	// the rate while the physical cluster answers with 429 Too Many
+	// Requests, and restores it gradually afterwards.
+	//
+	// +optional
+	ClientRateLimit *ClientRateLimit `json:"clientRateLimit,omitempty"`
+
+	// Connection configures the connection of the syncer to kcp, e.g. for
+	// edge clusters on constrained links.
+	//
+	// +optional
+	Connection *SyncTargetConnection `json:"connection,omitempty"`
+
+	// Registration is set on SyncTargets created by a registration request
+	// to the onboarding virtual workspace. Such SyncTargets receive no
+	// workloads until the registration is approved, and their syncer
+	// bootstrap manifests are generated on approval.
+	//
+	// +optional
+	Registration *SyncTargetRegistration `json:"registration,omitempty"`
+
+	// Provisioning makes the SyncTarget a placeholder for capacity that does
+	// not exist yet, e.g. a cloud autoscaling pool. Placement may choose it
+	// before its syncer is ready, which asks the provisioning webhook to
+	// create the capacity. Workloads placed on it are placed elsewhere if it
+	// is not provisioned within the timeout.
+	//
+	// +optional
+	Provisioning *SyncTargetProvisioning `json:"provisioning,omitempty"`
+
+	// HeartbeatTimeout is how long the syncer may go without renewing its
+	// heartbeat Lease before the SyncTarget is marked not ready. Defaults
+	// to 1 minute.
+	//
+	// +optional
+	HeartbeatTimeout *metav1.Duration `json:"heartbeatTimeout,omitempty"`
+
+	// HeartbeatStaleAction is what happens once the heartbeat is missing for
+	// longer than the timeout. MarkNotReady, the default, marks the
+	// SyncTarget not ready, which keeps new workloads off it. Evict also
+	// evicts the workloads placed on it, so that they are placed on other
+	// SyncTargets, until the heartbeat is back.
+	//
+	// +optional
+	// +kubebuilder:validation:Enum=MarkNotReady;Evict
+	HeartbeatStaleAction HeartbeatStaleAction `json:"heartbeatStaleAction,omitempty"`
+
+	// UpstreamSync narrows down the status the syncer writes back to kcp,
+	// to reduce conflicts with other writers and bandwidth. By default, the
+	// whole status of synced objects is written back.
+	//
+	// +optional
+	UpstreamSync *UpstreamSync `json:"upstreamSync,omitempty"`
+}
+
+// UpstreamSync selects the fields of synced objects written back to kcp.
+// Fields are dot-separated paths under status, e.g. status.conditions,
+// optionally written as JSONPath, e.g. $.status.conditions or
+// {.status.conditions}. Array elements cannot be selected individually.
+type UpstreamSync struct {
+	// Fields are the fields written back for resources not in Resources.
+	// Empty means the whole status.
+	//
+	// +optional
+	Fields []string `json:"fields,omitempty"`
+
+	// Resources override Fields per resource.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=group
+	// +listMapKey=resource
+	Resources []UpstreamSyncResource `json:"resources,omitempty"`
+}
+
+// UpstreamSyncResource selects the fields of a resource written back to
+// kcp.
+type UpstreamSyncResource struct {
+	// Group of the resource, empty for the core group.
+	//
+	// +optional
+	Group string `json:"group"`
+
+	// Resource is the plural name of the resource, e.g. deployments.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Resource string `json:"resource"`
+
+	// Fields are the fields of the resource written back. Empty means the
+	// whole status.
+	//
+	// +optional
+	Fields []string `json:"fields,omitempty"`
+}
+
+// HeartbeatStaleAction is what happens to a SyncTarget whose syncer
+// heartbeat is missing.
+type HeartbeatStaleAction string
+
+const (
+	// HeartbeatStaleActionMarkNotReady marks the SyncTarget not ready.
+	HeartbeatStaleActionMarkNotReady HeartbeatStaleAction = "MarkNotReady"
+	// HeartbeatStaleActionEvict marks the SyncTarget not ready and evicts
+	// its workloads.
+	HeartbeatStaleActionEvict HeartbeatStaleAction = "Evict"
+)
+
+// DefaultHeartbeatTimeout is the default heartbeat timeout of SyncTargets.
+const DefaultHeartbeatTimeout = time.Minute
+
+// GetHeartbeatTimeout returns the heartbeat timeout of the SyncTarget,
+// defaulted.
+func (in *SyncTargetSpec) GetHeartbeatTimeout() time.Duration {
+	if in.HeartbeatTimeout == nil || in.HeartbeatTimeout.Duration <= 0 {
+		return DefaultHeartbeatTimeout
+	}
+	return in.HeartbeatTimeout.Duration
+}
+
+// SyncTargetProvisioning is a webhook creating the capacity of a
+// provisionable SyncTarget, e.g. by scaling a cloud node pool or a Cluster
+// API MachineDeployment, and starting its syncer.
+type SyncTargetProvisioning struct {
+	// URL of the webhook. It must use https.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^https://`
+	URL string `json:"url"`
+
+	// CABundle is the PEM encoded CA bundle the webhook certificate is
+	// verified with. Defaults to the system trust roots.
+	//
+	// +optional
+	CABundle []byte `json:"caBundle,omitempty"`
+
+	// Credentials authenticate the TMC controllers to the webhook.
+	//
+	// +optional
+	Credentials *SyncTargetCredentials `json:"credentials,omitempty"`
+
+	// WebhookTimeoutSeconds is how long a webhook call may take.
+	//
+	// +optional
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=30
+	WebhookTimeoutSeconds *int32 `json:"webhookTimeoutSeconds,omitempty"`
+
+	// Timeout is how long the syncer may take to become ready after
+	// provisioning was first requested. Defaults to 15 minutes.
+	//
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// SyncTargetCredentials authenticate the TMC controllers to a webhook of a
+// SyncTarget. They are referenced in Secrets of the workspace of the
+// SyncTarget, which must be labeled with LabelCredentialsFor set to the name
+// of the SyncTarget.
+type SyncTargetCredentials struct {
+	// TokenSecretRef references the key of a Secret holding a bearer token
+	// sent to the webhook.
+	//
+	// +optional
+	TokenSecretRef *SecretKeyReference `json:"tokenSecretRef,omitempty"`
+
+	// ClientCertificateSecretRef references a Secret holding the PEM encoded
+	// client certificate and key presented to the webhook under the tls.crt
+	// and tls.key keys, e.g. of type kubernetes.io/tls.
+	//
+	// +optional
+	ClientCertificateSecretRef *SecretReference `json:"clientCertificateSecretRef,omitempty"`
+}
+
+// SecretReference references a Secret in the workspace.
+type SecretReference struct {
+	// Namespace of the Secret.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// Name of the Secret.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// SecretKeyReference references a key of a Secret in the workspace.
+type SecretKeyReference struct {
+	SecretReference `json:",inline"`
+
+	// Key in the data of the Secret.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+}
+
+// DefaultProvisioningTimeout is the default timeout of provisioning.
+const DefaultProvisioningTimeout = 15 * time.Minute
+
+// ProvisioningTimeout returns the timeout of provisioning, defaulted.
+func (in *SyncTargetProvisioning) ProvisioningTimeout() time.Duration {
+	if in.Timeout == nil || in.Timeout.Duration <= 0 {
+		return DefaultProvisioningTimeout
+	}
+	return in.Timeout.Duration
+}
+
+// SyncTargetRegistration is the self-service registration of a physical
+// cluster.
+type SyncTargetRegistration struct {
+	// PublicKey is the PEM encoded public key of the physical cluster that
+	// signed the registration request. The bootstrap manifests are only
+	// handed out to requests signed with the matching private key. It is
+	// empty for SyncTargets registered with a bootstrap token, whose
+	// manifests are only handed out to holders of the token.
+	//
+	// +optional
+	PublicKey string `json:"publicKey,omitempty"`
+
+	// BootstrapToken is the name of the SyncTargetBootstrapToken the
+	// SyncTarget was registered with, if any.
+	//
+	// +optional
+	BootstrapToken string `json:"bootstrapToken,omitempty"`
+
+	// RequestedBy is the user that sent the registration request.
+	//
+	// +optional
+	RequestedBy string `json:"requestedBy,omitempty"`
+
+	// Approved lets the SyncTarget receive workloads and triggers the
+	// generation of the bootstrap manifests.
+	//
+	// +optional
+	Approved bool `json:"approved,omitempty"`
+}
+
+// ClientRateLimit is the client-side rate limit towards a physical cluster.
+type ClientRateLimit struct {
+	// QPS is the sustained number of requests per second.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	QPS *int32 `json:"qps,omitempty"`
+
+	// Burst is the number of requests allowed in a burst above QPS.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Burst *int32 `json:"burst,omitempty"`
+}
+
+// SyncTargetConnection configures the connection of a syncer to kcp.
+type SyncTargetConnection struct {
+	// BandwidthLimit is the budget of the syncer, in bytes per second, for
+	// the traffic to and from kcp, measured after compression, e.g. 512Ki.
+	// Watch streams and requests wait for the budget, and updates of the
+	// same object queued meanwhile are coalesced. Unlimited if unset.
+	//
+	// +optional
+	BandwidthLimit *resource.Quantity `json:"bandwidthLimit,omitempty"`
+}
+
+// ReachabilityProbe is an endpoint probed from a SyncTarget.
+type ReachabilityProbe struct {
+	// Name identifies the endpoint. PlacementPolicies require endpoints by
+	// name, so the same endpoint should have the same name on all targets.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Address is a host:port probed by opening a TCP connection, or an
+	// http or https URL probed with a GET request. Any HTTP response
+	// counts as reachable.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Address string `json:"address"`
+}
+
+// ClusterScopedResource is a cluster-scoped resource synced to a SyncTarget.
+type ClusterScopedResource struct {
+	// Group of the resource, empty for the core group.
+	//
+	// +optional
+	Group string `json:"group"`
+
+	// Resource is the plural name of the resource, e.g. clusterroles.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Resource string `json:"resource"`
+
+	// CollisionPolicy decides what happens when an object already exists
+	// on the physical cluster under the name of a synced object. Prefix
+	// syncs objects under a name prefixed per workspace. Reject leaves the
+	// existing object alone and reports the conflict. AdoptIfIdentical
+	// shares the existing object if it does not differ from the synced one,
+	// and reports the conflict otherwise.
+	//
+	// +optional
+	// +kubebuilder:default=Reject
+	// +kubebuilder:validation:Enum=Prefix;Reject;AdoptIfIdentical
+	CollisionPolicy CollisionPolicy `json:"collisionPolicy,omitempty"`
+}
+
+// CollisionPolicy is how name collisions of cluster-scoped objects are
+// handled.
+type CollisionPolicy string
+
+const (
+	// CollisionPolicyPrefix prefixes downstream names per workspace.
+	CollisionPolicyPrefix CollisionPolicy = "Prefix"
+	// CollisionPolicyReject refuses to sync colliding objects.
+	CollisionPolicyReject CollisionPolicy = "Reject"
+	// CollisionPolicyAdoptIfIdentical shares identical objects.
+	CollisionPolicyAdoptIfIdentical CollisionPolicy = "AdoptIfIdentical"
+)
+
+// CapacityProvider is a webhook answering capacity queries of a SyncTarget.
+type CapacityProvider struct {
+	// URL of the webhook. It must use https.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^https://`
+	URL string `json:"url"`
+
+	// CABundle is the PEM encoded CA bundle the webhook certificate is
+	// verified with. Defaults to the system trust roots.
+	//
+	// +optional
+	CABundle []byte `json:"caBundle,omitempty"`
+
+	// Credentials authenticate the TMC controllers to the webhook.
+	//
+	// +optional
+	Credentials *SyncTargetCredentials `json:"credentials,omitempty"`
+
+	// TimeoutSeconds is how long a query may take.
+	//
+	// +optional
+	// +kubebuilder:default=5
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=30
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+
+	// FailurePolicy decides whether the target is feasible when the webhook
+	// cannot be queried. Fail makes it infeasible, Ignore falls back to the
+	// capacity reported by the syncer.
+	//
+	// +optional
+	// +kubebuilder:default=Fail
+	// +kubebuilder:validation:Enum=Fail;Ignore
+	FailurePolicy CapacityProviderFailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+// CapacityProviderFailurePolicy is how failures of a capacity provider are
+// handled.
+type CapacityProviderFailurePolicy string
+
+const (
+	// CapacityProviderFail makes the target infeasible.
+	CapacityProviderFail CapacityProviderFailurePolicy = "Fail"
+	// CapacityProviderIgnore uses the capacity reported by the syncer.
+	CapacityProviderIgnore CapacityProviderFailurePolicy = "Ignore"
+)
+
+// SyncTargetGuardrails limit the objects synced to a SyncTarget.
+type SyncTargetGuardrails struct {
+	// MaxObjects is the maximum number of objects synced to the target.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxObjects *int64 `json:"maxObjects,omitempty"`
+
+	// MaxManifestBytes is the maximum total size of the manifests synced
+	// to the target.
+	//
+	// +optional
+	MaxManifestBytes *resource.Quantity `json:"maxManifestBytes,omitempty"`
+}
+
+// Violations returns a message for every limit the usage reaches or
+// exceeds, or nil if the usage is within the guardrails.
+func (g *SyncTargetGuardrails) Violations(usage *SyncTargetUsage) []string {
+	if g == nil || usage == nil {
+		return nil
+	}
+	var violations []string
+	if g.MaxObjects != nil && usage.Objects >= *g.MaxObjects {
+		violations = append(violations, fmt.Sprintf("%d of at most %d objects synced", usage.Objects, *g.MaxObjects))
+	}
+	if g.MaxManifestBytes != nil && usage.ManifestBytes >= g.MaxManifestBytes.Value() {
+		violations = append(violations, fmt.Sprintf("%d of at most %d manifest bytes synced", usage.ManifestBytes, g.MaxManifestBytes.Value()))
+	}
+	return violations
+}
+
+// SyncTargetDrain configures the drain of a SyncTarget.
+type SyncTargetDrain struct {
+	// MaxUnavailable is how many workloads may be moving off the target at
+	// the same time. A workload is moving from when it is evicted from the
+	// target until it is ready on its other targets, like a
+	// PodDisruptionBudget allowing that many disruptions. Defaults to 1.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxUnavailable *int32 `json:"maxUnavailable,omitempty"`
+
+	// Timeout is how long the drain may wait for workloads to move. The
+	// workloads still placed on the target afterwards are evicted at once,
+	// regardless of MaxUnavailable. The drain waits as long as it takes if
+	// unset.
+	//
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// SyncTargetDrainStatus is the progress of the drain of a SyncTarget.
+type SyncTargetDrainStatus struct {
+	// StartTime is when the drain started.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	StartTime metav1.Time `json:"startTime"`
+
+	// Evicting are the workloads being moved off the target. Placement
+	// does not keep them on it.
+	//
+	// +optional
+	Evicting []DrainedWorkload `json:"evicting,omitempty"`
+
+	// Remaining is the number of workloads still placed on the target.
+	//
+	// +optional
+	Remaining int32 `json:"remaining,omitempty"`
+
+	// Moved is the number of workloads moved off the target and ready
+	// elsewhere, or deleted, since the drain started.
+	//
+	// +optional
+	Moved int32 `json:"moved,omitempty"`
+}
+
+// DrainedWorkload is the WorkloadDistribution of a workload evicted by a
+// drain.
+type DrainedWorkload struct {
+	// Namespace of the WorkloadDistribution.
+	Namespace string `json:"namespace"`
+
+	// Name of the WorkloadDistribution.
+	Name string `json:"name"`
+}
+
+// Evicts returns whether the drain evicts the workload of the
+// WorkloadDistribution with the given namespace and name.
+func (s *SyncTargetDrainStatus) Evicts(namespace, name string) bool {
+	if s == nil {
+		return false
+	}
+	for _, w := range s.Evicting {
+		if w.Namespace == namespace && w.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// DisruptionWindow is a period of planned disruption of a SyncTarget.
+type DisruptionWindow struct {
+	// Start of the window. The window starts immediately if unset.
+	//
+	// +optional
+	Start *metav1.Time `json:"start,omitempty"`
+
+	// End of the window.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	End metav1.Time `json:"end"`
+
+	// Reason for the disruption, e.g. the upgrade being performed.
+	//
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+// IsOpen returns whether the window is open at the given time.
+func (w *DisruptionWindow) IsOpen(now time.Time) bool {
+	if w == nil {
+		return false
+	}
+	if w.Start != nil && now.Before(w.Start.Time) {
+		return false
+	}
+	return now.Before(w.End.Time)
+}
+
+// DeliveryMode is the mechanism used to deliver workloads to a SyncTarget.
+type DeliveryMode string
+
+const (
+	// DeliveryModeSyncer delivers workloads through the workload syncer.
+	DeliveryModeSyncer DeliveryMode = "Syncer"
+	// DeliveryModeManifestWork delivers workloads as ManifestWork bundles.
+	DeliveryModeManifestWork DeliveryMode = "ManifestWork"
+)
+
+// Cell is a failure domain of a SyncTarget.
+type Cell struct {
+	// Name identifies the cell within the SyncTarget.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Labels are the topology labels of the cell, e.g. topology.kubernetes.io/zone.
+	//
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Taints repel placements that do not tolerate them.
+	//
+	// +optional
+	Taints []corev1.Taint `json:"taints,omitempty"`
+}
+
+// SyncTargetStatus communicates the observed state of the SyncTarget (from the controller).
+type SyncTargetStatus struct {
+	// Allocatable represents the resources that are available for scheduling.
+	// +optional
+	Allocatable *corev1.ResourceList `json:"allocatable,omitempty"`
+
+	// Capacity represents the total resources of the cluster.
+	// +optional
+	Capacity *corev1.ResourceList `json:"capacity,omitempty"`
+
+	// Usage is the amount of objects synced to the target, as reported by
+	// the syncer.
+	// +optional
+	Usage *SyncTargetUsage `json:"usage,omitempty"`
+
+	// KubernetesVersion is the version reported by the physical cluster.
+	// +optional
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+
+	// Capabilities are the features, APIs and addons of the physical
+	// cluster, as harvested by the syncer. Placement matches the
+	// requirements of PlacementPolicies against them.
+	// +optional
+	Capabilities *SyncTargetCapabilities `json:"capabilities,omitempty"`
+
+	// Current processing state of the SyncTarget.
+	// +optional
+	Conditions conditionsv1alpha1.Conditions `json:"conditions,omitempty"`
+
+	// A timestamp indicating when the syncer last reported status.
+	// +optional
+	LastSyncerHeartbeatTime *metav1.Time `json:"lastSyncerHeartbeatTime,omitempty"`
+
+	// VirtualWorkspaces contains all virtual workspace URLs.
+	// +optional
+	VirtualWorkspaces []VirtualWorkspace `json:"virtualWorkspaces,omitempty"`
+
+	// Reachability reports the endpoints of spec.reachabilityProbes as
+	// last probed by the syncer.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Reachability []EndpointReachability `json:"reachability,omitempty"`
+
+	// ProvisioningStartTime is when the capacity of a provisionable SyncTarget
+	// was first requested. Provisioning fails if the syncer is not ready
+	// within the provisioning timeout from then.
+	// +optional
+	ProvisioningStartTime *metav1.Time `json:"provisioningStartTime,omitempty"`
+
+	// SyncerFeatureGates are the feature gates of the syncer, as reported
+	// by the syncer.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	SyncerFeatureGates []FeatureGate `json:"syncerFeatureGates,omitempty"`
+
+	// Drain reports the progress of spec.drain.
+	// +optional
+	Drain *SyncTargetDrainStatus `json:"drain,omitempty"`
+}
+
+// SyncTargetCapabilities are the capabilities of a physical cluster.
+type SyncTargetCapabilities struct {
+	// FeatureGates are the enabled feature gates of the API server.
+	// +optional
+	FeatureGates []string `json:"featureGates,omitempty"`
+
+	// APIs are the served API versions, as apiVersion.
+	// +optional
+	APIs []string `json:"apis,omitempty"`
+
+	// Addons are the installed CSI drivers and the extended resources
+	// offered by nodes.
+	// +optional
+	Addons []string `json:"addons,omitempty"`
+
+	// LastHarvestTime is when the capabilities were harvested.
+	// +optional
+	LastHarvestTime *metav1.Time `json:"lastHarvestTime,omitempty"`
+}
+
+// EndpointReachability is the result of probing an endpoint from a
+// SyncTarget.
+type EndpointReachability struct {
+	// Name of the probed endpoint.
+	Name string `json:"name"`
+
+	// Reachable is whether the endpoint answered the probe.
+	Reachable bool `json:"reachable"`
+
+	// LatencyMilliseconds is how long the endpoint took to answer.
+	// +optional
+	LatencyMilliseconds *int64 `json:"latencyMilliseconds,omitempty"`
+
+	// LastProbeTime is when the endpoint was probed.
+	LastProbeTime metav1.Time `json:"lastProbeTime"`
+
+	// Message explains why the endpoint is unreachable.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// VirtualWorkspace is a syncer virtual workspace endpoint serving a SyncTarget.
+type VirtualWorkspace struct {
+	// SyncerURL is the URL of the syncer virtual workspace.
+	//
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:format:uri
+	// +required
+	SyncerURL string `json:"syncerURL"`
+}
+
+// SyncTargetUsage is the amount of objects synced to a SyncTarget.
+type SyncTargetUsage struct {
+	// Objects is the number of synced objects.
+	Objects int64 `json:"objects"`
+
+	// ManifestBytes is the total size of the synced manifests.
+	ManifestBytes int64 `json:"manifestBytes"`
+}
+
+// SyncTargetList is a list of SyncTarget resources
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type SyncTargetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []SyncTarget `json:"items"`
+}
+
+const (
+	// AnnotationCost is the relative cost of placing workloads on a
+	// SyncTarget, as a non-negative decimal number. The Cost scorer of the
+	// placement engine prefers cheaper SyncTargets.
+	AnnotationCost = "tmc.kcp.io/cost"
+
+	// AnnotationCarbonIntensity is the carbon intensity of the electricity
+	// a SyncTarget runs on, in gCO2eq/kWh, as a non-negative decimal number.
+	// The Carbon scorer of the placement engine prefers SyncTargets with a
+	// lower carbon intensity. When a carbon intensity provider is
+	// configured, it fills in the annotation for SyncTargets that do not
+	// set it.
+	AnnotationCarbonIntensity = "tmc.kcp.io/carbon-intensity"
+
+	// AnnotationCarbonRegion is the electricity grid region of a SyncTarget,
+	// as known to the carbon intensity provider. Defaults to the location of
+	// the SyncTarget.
+	AnnotationCarbonRegion = "tmc.kcp.io/carbon-region"
+
+	// AnnotationEndpointPrefix prefixes annotations naming endpoints of
+	// services at the SyncTarget, e.g. endpoints.tmc.kcp.io/registry, for
+	// use in per-location configuration.
+	AnnotationEndpointPrefix = "endpoints.tmc.kcp.io/"
+
+	// AnnotationForceDelete set to "true" lets a SyncTarget being deleted go
+	// without waiting for its workloads to be moved off and for the syncer
+	// to remove the downstream resources, e.g. when the physical cluster is
+	// gone.
+	AnnotationForceDelete = "tmc.kcp.io/force-delete"
+
+	// AnnotationSimulatedFailureUntil makes placement treat a SyncTarget as
+	// failed until the given RFC 3339 time, without touching the physical
+	// cluster, to rehearse the failover of the workloads placed on it. Set
+	// by kubectl tmc simulate-failure.
+	AnnotationSimulatedFailureUntil = "tmc.kcp.io/simulated-failure-until"
+
+	// LabelCredentialsFor marks a Secret as holding webhook credentials of
+	// the SyncTarget named by its value. The TMC controllers only send the
+	// Secrets referenced by the credentials of a SyncTarget if they carry
+	// the label, so that those who may edit the SyncTarget, but not read
+	// the Secrets of its workspace, cannot send any Secret to a webhook.
+	LabelCredentialsFor = "tmc.kcp.io/credentials-for"
+
+	// SyncTargetFinalizer holds SyncTargets being deleted until their
+	// workloads are moved off, the syncer removed the downstream resources
+	// and references to them are cleaned up.
+	SyncTargetFinalizer = "tmc.kcp.io/teardown"
+)
+
+// Conditions and ConditionReasons for the SyncTarget object.
+const (
+	// SyncerReady means the syncer is ready to transfer resources between KCP and the SyncTarget.
+	SyncerReady conditionsv1alpha1.ConditionType = "SyncerReady"
+
+	// HeartbeatHealthy means the HeartbeatManager has seen a heartbeat for the SyncTarget within the expected interval.
+	HeartbeatHealthy conditionsv1alpha1.ConditionType = "HeartbeatHealthy"
+
+	// ErrorHeartbeatMissedReason indicates that a heartbeat update was not received within the configured threshold.
+	ErrorHeartbeatMissedReason = "ErrorHeartbeat"
+
+	// WaitingForHeartbeatReason indicates that the syncer has not renewed its heartbeat Lease yet.
+	WaitingForHeartbeatReason = "WaitingForHeartbeat"
+
+	// SchedulingDisabled is true while the SyncTarget receives no new workloads.
+	SchedulingDisabled conditionsv1alpha1.ConditionType = "SchedulingDisabled"
+
+	// GuardrailExceededReason indicates that the usage of the SyncTarget reached its guardrails.
+	GuardrailExceededReason = "GuardrailExceeded"
+
+	// ClusterScopedResourcesSynced means the cluster-scoped objects of all workspaces are synced
+	// to the SyncTarget without colliding with other objects.
+	ClusterScopedResourcesSynced conditionsv1alpha1.ConditionType = "ClusterScopedResourcesSynced"
+
+	// CollisionReason indicates that cluster-scoped objects collide with objects of other workspaces
+	// or of the cluster administrator.
+	CollisionReason = "Collision"
+
+	// SyncerAuthorized means the service account of the syncer has all permissions on the physical
+	// cluster that the synced resources and enabled features require.
+	SyncerAuthorized conditionsv1alpha1.ConditionType = "SyncerAuthorized"
+
+	// MissingPermissionsReason indicates that the syncer lacks permissions on the physical cluster.
+	MissingPermissionsReason = "MissingPermissions"
+
+	// DownstreamResourcesRemoved means the syncer removed the resources it synced to the physical
+	// cluster, after the SyncTarget started being deleted.
+	DownstreamResourcesRemoved conditionsv1alpha1.ConditionType = "DownstreamResourcesRemoved"
+
+	// DownstreamResourcesRemainingReason indicates that synced resources are still being removed.
+	DownstreamResourcesRemainingReason = "DownstreamResourcesRemaining"
+
+	// TornDown reports the progress of the teardown of a SyncTarget being deleted.
+	TornDown conditionsv1alpha1.ConditionType = "TornDown"
+
+	// WaitingForWorkloadsReason indicates that workloads are still placed on the SyncTarget.
+	WaitingForWorkloadsReason = "WaitingForWorkloads"
+
+	// WaitingForSyncerReason indicates that the syncer did not remove the downstream resources yet.
+	WaitingForSyncerReason = "WaitingForSyncer"
+
+	// BootstrapReady means the syncer bootstrap manifests of a registered SyncTarget are generated.
+	BootstrapReady conditionsv1alpha1.ConditionType = "BootstrapReady"
+
+	// WaitingForApprovalReason indicates that the registration of the SyncTarget is not approved yet.
+	WaitingForApprovalReason = "WaitingForApproval"
+
+	// WaitingForTokenReason indicates that the token of the syncer service account is not issued yet.
+	WaitingForTokenReason = "WaitingForToken"
+
+	// ObserveOnly is true while the syncer runs in observer mode: it reports discovery, status, capacity and
+	// health, but does not change the physical cluster, so no workloads are placed on the SyncTarget.
+	ObserveOnly conditionsv1alpha1.ConditionType = "ObserveOnly"
+
+	// ObserverModeReason indicates that the syncer was started in observer mode.
+	ObserverModeReason = "ObserverMode"
+
+	// Paused is true while spec.paused is set and the syncer holds syncing and status collection.
+	Paused conditionsv1alpha1.ConditionType = "Paused"
+
+	// SyncPausedReason indicates that the SyncTarget was paused.
+	SyncPausedReason = "SyncPaused"
+
+	// ManifestWorksApplied means the work agent applied all manifests of the ManifestWorks of a SyncTarget
+	// in the ManifestWork delivery mode.
+	ManifestWorksApplied conditionsv1alpha1.ConditionType = "ManifestWorksApplied"
+
+	// ManifestsNotAppliedReason indicates that the work agent did not apply some manifests yet, or failed to.
+	ManifestsNotAppliedReason = "ManifestsNotApplied"
+
+	// Provisioned means the capacity of a provisionable SyncTarget exists and its syncer is ready.
+	Provisioned conditionsv1alpha1.ConditionType = "Provisioned"
+
+	// ProvisioningRequestedReason indicates that the provisioning webhook accepted to create the capacity.
+	ProvisioningRequestedReason = "ProvisioningRequested"
+
+	// ProvisioningRequestFailedReason indicates that the provisioning webhook could not be called. It is retried.
+	ProvisioningRequestFailedReason = "ProvisioningRequestFailed"
+
+	// ProvisioningFailedReason indicates that the webhook declined to provision, or that the syncer did not
+	// become ready within the timeout. Placement places workloads elsewhere.
+	ProvisioningFailedReason = "ProvisioningFailed"
+
+	// Drained reports the progress of spec.drain. It is true once no workloads are placed on the SyncTarget.
+	Drained conditionsv1alpha1.ConditionType = "Drained"
+
+	// DrainingReason indicates that workloads are still being moved off the SyncTarget.
+	DrainingReason = "Draining"
+
+	// DrainTimedOutReason indicates that the drain did not finish within spec.drain.timeout, and the remaining
+	// workloads are evicted at once.
+	DrainTimedOutReason = "DrainTimedOut"
+)
+
+func (in *SyncTarget) SetConditions(c conditionsv1alpha1.Conditions) {
+	in.Status.Conditions = c
+}
+
+func (in *SyncTarget) GetConditions() conditionsv1alpha1.Conditions {
+	return in.Status.Conditions
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SyncTargetBootstrapToken lets physical clusters register themselves as
+// SyncTargets without credentials for kcp. A cluster presenting the token
+// <name>.<secret> to the onboarding virtual workspace gets an approved
+// SyncTarget, scoped syncer credentials and the syncer deployment manifests
+// in one request.
+//
+// +crd
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories=kcp
+// +kubebuilder:printcolumn:name="Expiration",type="date",JSONPath=`.spec.expiration`
+// +kubebuilder:printcolumn:name="Limit",type="integer",JSONPath=`.spec.usageLimit`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type SyncTargetBootstrapToken struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the desired state.
+	// +optional
+	Spec SyncTargetBootstrapTokenSpec `json:"spec,omitempty"`
+
+	// Status communicates the observed state.
+	// +optional
+	Status SyncTargetBootstrapTokenStatus `json:"status,omitempty"`
+}
+
+// SyncTargetBootstrapTokenSpec holds the desired state of the
+// SyncTargetBootstrapToken.
+type SyncTargetBootstrapTokenSpec struct {
+	// SecretHash is the hex encoded SHA-256 hash of the secret part of the
+	// token. The secret itself is not stored.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[0-9a-f]{64}$`
+	SecretHash string `json:"secretHash"`
+
+	// Expiration is when the token stops being accepted. Tokens without
+	// expiration are accepted until they are deleted or used up.
+	//
+	// +optional
+	Expiration *metav1.Time `json:"expiration,omitempty"`
+
+	// UsageLimit is how many SyncTargets the token may register. Defaults
+	// to 1.
+	//
+	// +optional
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	UsageLimit int32 `json:"usageLimit,omitempty"`
+
+	// NamePrefix restricts the names of the SyncTargets the token
+	// registers.
+	//
+	// +optional
+	NamePrefix string `json:"namePrefix,omitempty"`
+
+	// Location is the location of the SyncTargets the token registers,
+	// overriding the requested one.
+	//
+	// +optional
+	Location string `json:"location,omitempty"`
+
+	// Labels are set on the SyncTargets the token registers, overriding
+	// requested labels of the same keys.
+	//
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// AllowedLabels are the keys of the labels registrations may request.
+	// Registrations requesting other labels are rejected, so that holders
+	// of the token cannot steer placement onto their SyncTargets.
+	//
+	// +optional
+	// +listType=set
+	AllowedLabels []string `json:"allowedLabels,omitempty"`
+}
+
+// SyncTargetBootstrapTokenStatus communicates the observed state of the
+// SyncTargetBootstrapToken.
+type SyncTargetBootstrapTokenStatus struct {
+	// Registrations are the names of the SyncTargets the token registered.
+	//
+	// +optional
+	// +listType=set
+	Registrations []string `json:"registrations,omitempty"`
+}
+
+// Limit returns the usage limit of the token, defaulted.
+func (in *SyncTargetBootstrapTokenSpec) Limit() int32 {
+	if in.UsageLimit <= 0 {
+		return 1
+	}
+	return in.UsageLimit
+}
+
+// SyncTargetBootstrapTokenList is a list of SyncTargetBootstrapToken
+// resources.
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type SyncTargetBootstrapTokenList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []SyncTargetBootstrapToken `json:"items"`
+}
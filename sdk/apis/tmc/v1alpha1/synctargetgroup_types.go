@@ -0,0 +1,146 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+)
+
+// SyncTargetGroup bundles SyncTargets of a fleet with heterogeneous cluster
+// sizes. A PlacementPolicy targeting the group places only on its members
+// and splits the replicas of a workload over the chosen members in
+// proportion to their weights.
+//
+// +crd
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories=kcp
+// +kubebuilder:printcolumn:name="Members",type="integer",JSONPath=`.status.members`
+// +kubebuilder:printcolumn:name="Ready",type="integer",JSONPath=`.status.readyMembers`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type SyncTargetGroup struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the desired state.
+	// +optional
+	Spec SyncTargetGroupSpec `json:"spec,omitempty"`
+
+	// Status communicates the observed state.
+	// +optional
+	Status SyncTargetGroupStatus `json:"status,omitempty"`
+}
+
+// SyncTargetGroupSpec holds the desired state of the SyncTargetGroup.
+type SyncTargetGroupSpec struct {
+	// Members are the SyncTargets of the group.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Members []SyncTargetGroupMember `json:"members,omitempty"`
+}
+
+// SyncTargetGroupMember is a SyncTarget in a group.
+type SyncTargetGroupMember struct {
+	// Name of the SyncTarget.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Weight is the share of replicas the member receives relative to the
+	// other chosen members, e.g. a member with weight 2 receives twice as
+	// many replicas as one with weight 1. Defaults to 1.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	Weight *int32 `json:"weight,omitempty"`
+}
+
+// SyncTargetGroupStatus communicates the observed state of the SyncTargetGroup.
+type SyncTargetGroupStatus struct {
+	// Members is the number of members that exist.
+	// +optional
+	Members int32 `json:"members,omitempty"`
+
+	// ReadyMembers is the number of members whose syncer is ready.
+	// +optional
+	ReadyMembers int32 `json:"readyMembers,omitempty"`
+
+	// Capacity is the sum of the capacity of the ready members.
+	// +optional
+	Capacity *corev1.ResourceList `json:"capacity,omitempty"`
+
+	// Allocatable is the sum of the allocatable resources of the ready
+	// members.
+	// +optional
+	Allocatable *corev1.ResourceList `json:"allocatable,omitempty"`
+
+	// Current processing state of the SyncTargetGroup.
+	// +optional
+	Conditions conditionsv1alpha1.Conditions `json:"conditions,omitempty"`
+}
+
+// Weight returns the weight of the member with the given name, and whether
+// it is a member.
+func (in *SyncTargetGroupSpec) Weight(name string) (int32, bool) {
+	for _, m := range in.Members {
+		if m.Name == name {
+			if m.Weight == nil {
+				return 1, true
+			}
+			return *m.Weight, true
+		}
+	}
+	return 0, false
+}
+
+// SyncTargetGroupList is a list of SyncTargetGroup resources.
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type SyncTargetGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []SyncTargetGroup `json:"items"`
+}
+
+// Conditions and ConditionReasons for the SyncTargetGroup object.
+const (
+	// MembersFound means all members of the group exist.
+	MembersFound conditionsv1alpha1.ConditionType = "MembersFound"
+
+	// MemberNotFoundReason indicates that a member SyncTarget does not exist.
+	MemberNotFoundReason = "MemberNotFound"
+)
+
+func (in *SyncTargetGroup) SetConditions(c conditionsv1alpha1.Conditions) {
+	in.Status.Conditions = c
+}
+
+func (in *SyncTargetGroup) GetConditions() conditionsv1alpha1.Conditions {
+	return in.Status.Conditions
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TMCFeatureStatusName is the name of the TMCFeatureStatus of a workspace.
+const TMCFeatureStatusName = "cluster"
+
+// TMCFeatureStatus reports which TMC features are active in a workspace. It
+// aggregates the feature gates of the kcp process running the TMC
+// controllers, the features the workspace uses, and the feature gates
+// reported by the syncers of its SyncTargets, and lists the gates the
+// controllers and a syncer disagree on. The TMC controllers maintain one
+// per workspace with SyncTargets or WorkloadDistributions, named "cluster".
+//
+// +crd
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories=kcp
+// +kubebuilder:printcolumn:name="Mismatches",type="integer",JSONPath=`.status.mismatchCount`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type TMCFeatureStatus struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Status communicates the observed state.
+	// +optional
+	Status TMCFeatureStatusStatus `json:"status,omitempty"`
+}
+
+// TMCFeatureStatusStatus communicates the observed state of the
+// TMCFeatureStatus.
+type TMCFeatureStatusStatus struct {
+	// Controller are the TMC feature gates of the kcp process running the
+	// TMC controllers.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Controller []FeatureGate `json:"controller,omitempty"`
+
+	// Workspace are the TMC features of the workspace. A feature is active
+	// if its feature gate is enabled and the workspace uses it.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Workspace []WorkspaceFeature `json:"workspace,omitempty"`
+
+	// SyncTargets are the feature gates reported by the syncers of the
+	// SyncTargets of the workspace. SyncTargets whose syncer did not report
+	// its feature gates are omitted.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	SyncTargets []SyncTargetFeatureGates `json:"syncTargets,omitempty"`
+
+	// Mismatches are the feature gates the controllers and a syncer disagree
+	// on.
+	// +optional
+	Mismatches []FeatureMismatch `json:"mismatches,omitempty"`
+
+	// MismatchCount is the number of mismatches.
+	// +optional
+	MismatchCount int32 `json:"mismatchCount,omitempty"`
+
+	// LastUpdateTime is when the status last changed.
+	// +optional
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// FeatureGate is a feature gate of a process and whether it is effectively
+// enabled, i.e. taking gates it depends on into account.
+type FeatureGate struct {
+	// Name of the feature gate.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Enabled is whether the feature gate is enabled.
+	Enabled bool `json:"enabled"`
+}
+
+// WorkspaceFeature is a TMC feature of a workspace.
+type WorkspaceFeature struct {
+	// Name of the feature gate of the feature.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Active is whether the feature is active in the workspace.
+	Active bool `json:"active"`
+
+	// Message explains why the feature is active or not.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// SyncTargetFeatureGates are the feature gates reported by the syncer of a
+// SyncTarget.
+type SyncTargetFeatureGates struct {
+	// Name of the SyncTarget.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// FeatureGates of the syncer.
+	// +optional
+	FeatureGates []FeatureGate `json:"featureGates,omitempty"`
+}
+
+// FeatureMismatch is a feature gate the controllers and the syncer of a
+// SyncTarget disagree on.
+type FeatureMismatch struct {
+	// FeatureGate is the name of the feature gate.
+	FeatureGate string `json:"featureGate"`
+
+	// SyncTarget is the name of the SyncTarget.
+	SyncTarget string `json:"syncTarget"`
+
+	// Message describes the mismatch.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// TMCFeatureStatusList is a list of TMCFeatureStatus resources.
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type TMCFeatureStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []TMCFeatureStatus `json:"items"`
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PropagationPolicy controls which labels and annotations of the objects of
+// a workspace are copied to their downstream copies on physical clusters,
+// which are stripped, and which are added.
+//
+// Without a PropagationPolicy, all labels and annotations are copied, except
+// those of the kcp.io domain and its subdomains, which are internal to the
+// workspace and never copied. When several policies select an object, they
+// are applied in the order of their names.
+//
+// +crd
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:scope=Cluster,categories=kcp
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type PropagationPolicy struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the desired state.
+	// +optional
+	Spec PropagationPolicySpec `json:"spec,omitempty"`
+}
+
+// PropagationPolicySpec holds the desired state of the PropagationPolicy.
+type PropagationPolicySpec struct {
+	// Resources selects the resources the policy applies to, as
+	// "resource.group", or "resource" for the core group. "*" selects all
+	// resources. Defaults to all resources.
+	//
+	// +optional
+	Resources []string `json:"resources,omitempty"`
+
+	// ObjectSelector selects the objects the policy applies to by their
+	// labels. Defaults to all objects.
+	//
+	// +optional
+	ObjectSelector *metav1.LabelSelector `json:"objectSelector,omitempty"`
+
+	// Labels controls the propagation of labels.
+	//
+	// +optional
+	Labels MetadataPropagation `json:"labels,omitempty"`
+
+	// Annotations controls the propagation of annotations.
+	//
+	// +optional
+	Annotations MetadataPropagation `json:"annotations,omitempty"`
+}
+
+// MetadataPropagation controls the propagation of either the labels or the
+// annotations of an object. Keys are matched exactly, or by prefix if the
+// pattern ends with "*", e.g. "app.kubernetes.io/*".
+type MetadataPropagation struct {
+	// Propagate lists the keys copied downstream. Defaults to all keys.
+	//
+	// +optional
+	Propagate []string `json:"propagate,omitempty"`
+
+	// Strip lists the keys not copied downstream, even if they match
+	// Propagate.
+	//
+	// +optional
+	Strip []string `json:"strip,omitempty"`
+
+	// Add sets keys on every downstream copy, overriding the value copied
+	// from the workspace. Values may reference the SyncTarget the copy is
+	// synced to with the variables of ConfigMap fan-out, e.g.
+	// ${target.location} or ${target.labels.<key>}.
+	//
+	// +optional
+	Add map[string]string `json:"add,omitempty"`
+}
+
+// PropagationPolicyList is a list of PropagationPolicy resources.
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type PropagationPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []PropagationPolicy `json:"items"`
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SchedulingDefaults injects tolerations and node selectors into the pods
+// of the workloads of a workspace when they are synced to physical
+// clusters, e.g. to tolerate the taints of edge nodes or to pin workloads
+// to a dedicated node pool.
+//
+// Values of the workload and of the defaults for the same node selector key
+// or toleration are resolved by the precedence of the defaults. When
+// several SchedulingDefaults select a workload, they are applied in the
+// order of their names.
+//
+// +crd
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:scope=Cluster,categories=kcp
+// +kubebuilder:printcolumn:name="Precedence",type="string",JSONPath=`.spec.precedence`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type SchedulingDefaults struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the desired state.
+	// +optional
+	Spec SchedulingDefaultsSpec `json:"spec,omitempty"`
+}
+
+// SchedulingDefaultsSpec holds the desired state of the SchedulingDefaults.
+type SchedulingDefaultsSpec struct {
+	// ObjectSelector selects the workloads the defaults apply to by their
+	// labels. Defaults to all workloads.
+	//
+	// +optional
+	ObjectSelector *metav1.LabelSelector `json:"objectSelector,omitempty"`
+
+	// TargetSelector selects the SyncTargets the defaults apply on by their
+	// labels, e.g. edge clusters. Defaults to all SyncTargets.
+	//
+	// +optional
+	TargetSelector *metav1.LabelSelector `json:"targetSelector,omitempty"`
+
+	// NodeSelector is added to the node selector of the pods. Values may
+	// reference the SyncTarget the pods are synced to with the variables of
+	// ConfigMap fan-out, e.g. ${target.labels.<key>}.
+	//
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations are added to the tolerations of the pods. Tolerations
+	// with the same key and effect are the same toleration.
+	//
+	// +optional
+	// +listType=atomic
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Precedence decides whether the workload or the defaults win for node
+	// selector keys and tolerations both set.
+	//
+	// +optional
+	// +kubebuilder:default=Workload
+	Precedence SchedulingDefaultsPrecedence `json:"precedence,omitempty"`
+}
+
+// SchedulingDefaultsPrecedence decides between values of a workload and of
+// SchedulingDefaults.
+//
+// +kubebuilder:validation:Enum=Workload;Defaults
+type SchedulingDefaultsPrecedence string
+
+const (
+	// SchedulingDefaultsPrecedenceWorkload keeps the values of the
+	// workload, the defaults only fill in what is missing.
+	SchedulingDefaultsPrecedenceWorkload SchedulingDefaultsPrecedence = "Workload"
+	// SchedulingDefaultsPrecedenceDefaults overrides the values of the
+	// workload, e.g. to enforce a node pool.
+	SchedulingDefaultsPrecedenceDefaults SchedulingDefaultsPrecedence = "Defaults"
+)
+
+// SchedulingDefaultsList is a list of SchedulingDefaults resources.
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type SchedulingDefaultsList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []SchedulingDefaults `json:"items"`
+}
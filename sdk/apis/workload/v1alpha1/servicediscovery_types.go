@@ -0,0 +1,207 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+)
+
+// ServiceExport exports the Service of the same name in its namespace to
+// all SyncTargets the namespace is synced to. The syncers report the
+// endpoints of the Service on their physical clusters, and the TMC
+// controllers collect them in the ServiceImport of the same name, which the
+// syncers mirror onto the other physical clusters. Imported endpoints must
+// be routable between the physical clusters, e.g. over a flat pod network.
+//
+// +crd
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,categories=kcp
+// +kubebuilder:printcolumn:name="Valid",type="string",JSONPath=`.status.conditions[?(@.type=="ServiceExportValid")].status`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type ServiceExport struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Status communicates the observed state.
+	// +optional
+	Status ServiceExportStatus `json:"status,omitempty"`
+}
+
+// ServiceExportStatus communicates the observed state of the ServiceExport.
+type ServiceExportStatus struct {
+	// Current processing state of the ServiceExport.
+	// +optional
+	Conditions conditionsv1alpha1.Conditions `json:"conditions,omitempty"`
+}
+
+// ServiceExportList is a list of ServiceExport resources.
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type ServiceExportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ServiceExport `json:"items"`
+}
+
+// ServiceImport holds the endpoints of an exported Service on every SyncTarget.
+// It is maintained by the TMC controllers for the ServiceExport of the same
+// name.
+//
+// +crd
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,categories=kcp
+// +kubebuilder:printcolumn:name="Type",type="string",JSONPath=`.spec.type`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type ServiceImport struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the desired state.
+	// +optional
+	Spec ServiceImportSpec `json:"spec,omitempty"`
+
+	// Status communicates the observed state.
+	// +optional
+	Status ServiceImportStatus `json:"status,omitempty"`
+}
+
+// ServiceImportSpec holds the desired state of the ServiceImport.
+type ServiceImportSpec struct {
+	// Type is ClusterSetIP for Services with a cluster IP, or Headless.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=ClusterSetIP;Headless
+	Type ServiceImportType `json:"type"`
+
+	// Ports are the ports of the exported Service.
+	//
+	// +optional
+	// +listType=atomic
+	Ports []ServicePort `json:"ports,omitempty"`
+}
+
+// ServiceImportType is the type of an imported Service.
+type ServiceImportType string
+
+const (
+	// ClusterSetIP imports a Service with a cluster IP.
+	ClusterSetIP ServiceImportType = "ClusterSetIP"
+	// Headless imports a headless Service.
+	Headless ServiceImportType = "Headless"
+)
+
+// ServicePort is a port of an exported Service.
+type ServicePort struct {
+	// Name of the port, required if the Service has several ports.
+	//
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Protocol of the port. Defaults to TCP.
+	//
+	// +optional
+	Protocol corev1.Protocol `json:"protocol,omitempty"`
+
+	// AppProtocol is the application protocol of the port.
+	//
+	// +optional
+	AppProtocol *string `json:"appProtocol,omitempty"`
+
+	// Port is the port number.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	Port int32 `json:"port"`
+}
+
+// ServiceImportStatus communicates the observed state of the ServiceImport.
+type ServiceImportStatus struct {
+	// Targets are the endpoints of the exported Service by SyncTarget.
+	// +optional
+	// +listType=map
+	// +listMapKey=syncTarget
+	Targets []ServiceEndpoints `json:"targets,omitempty"`
+}
+
+// ServiceEndpoints are the endpoints of an exported Service on one
+// SyncTarget.
+type ServiceEndpoints struct {
+	// SyncTarget is the name of the SyncTarget.
+	SyncTarget string `json:"syncTarget"`
+
+	// Addresses are the addresses of the ready endpoints.
+	// +optional
+	// +listType=set
+	Addresses []string `json:"addresses,omitempty"`
+
+	// Ports are the ports of the endpoints, which can differ from the ports
+	// of the Service.
+	// +optional
+	// +listType=atomic
+	Ports []ServicePort `json:"ports,omitempty"`
+}
+
+// ServiceImportList is a list of ServiceImport resources.
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type ServiceImportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ServiceImport `json:"items"`
+}
+
+const (
+	// AnnotationPrefixExportedEndpoints prefixes the annotations of
+	// ServiceExports in which syncers report the endpoints of the exported
+	// Service on their SyncTarget, as ServiceEndpoints JSON. The suffix is
+	// the bounded name of the SyncTarget, the SyncTarget field holds its
+	// full name.
+	AnnotationPrefixExportedEndpoints = "endpoints.workload.kcp.io/"
+)
+
+// Conditions and ConditionReasons for the ServiceExport object.
+const (
+	// ServiceExportValid means the exported Service exists and can be
+	// imported.
+	ServiceExportValid conditionsv1alpha1.ConditionType = "ServiceExportValid"
+
+	// ServiceNotFoundReason indicates the exported Service does not exist.
+	ServiceNotFoundReason = "ServiceNotFound"
+	// ServiceTypeNotSupportedReason indicates the exported Service is of a
+	// type that cannot be imported, e.g. ExternalName.
+	ServiceTypeNotSupportedReason = "ServiceTypeNotSupported"
+)
+
+func (in *ServiceExport) SetConditions(c conditionsv1alpha1.Conditions) {
+	in.Status.Conditions = c
+}
+
+func (in *ServiceExport) GetConditions() conditionsv1alpha1.Conditions {
+	return in.Status.Conditions
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StatusAggregationPolicy controls how the status fields of the objects of a
+// resource, as reported by the SyncTargets they are placed on, are
+// aggregated into the status of the object in the workspace.
+//
+// Replica counters of the workload resources of the apps and batch groups
+// are summed without a policy. The conditions of the object are always
+// aggregated, a condition being true only if it is true on all SyncTargets,
+// and so is status.phase, which takes the worst phase of all SyncTargets.
+// When several policies aggregate the same field, the policy with the
+// greatest name wins.
+//
+// +crd
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:scope=Cluster,categories=kcp
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type StatusAggregationPolicy struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the desired state.
+	// +optional
+	Spec StatusAggregationPolicySpec `json:"spec,omitempty"`
+}
+
+// StatusAggregationPolicySpec holds the desired state of the
+// StatusAggregationPolicy.
+type StatusAggregationPolicySpec struct {
+	// Resources selects the resources the policy applies to, as
+	// "resource.group", or "resource" for the core group.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	Resources []string `json:"resources"`
+
+	// Fields are the aggregated status fields.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	// +listType=map
+	// +listMapKey=path
+	Fields []AggregatedField `json:"fields"`
+}
+
+// AggregatedField aggregates a status field.
+type AggregatedField struct {
+	// Path is the field below status, as dot-separated field names, e.g.
+	// readyReplicas.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Path string `json:"path"`
+
+	// Strategy is Sum, Min or Max for numbers, AllTrue for booleans, or CEL
+	// to evaluate Expression.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=Sum;Min;Max;AllTrue;CEL
+	Strategy AggregationStrategy `json:"strategy"`
+
+	// Expression is the CEL expression of the CEL strategy, evaluating to a
+	// number, boolean or string. The variable values is the list of the
+	// field values of the SyncTargets that report the field, statuses maps
+	// the names of the SyncTargets that reported a status to it, and
+	// targets is the number of SyncTargets the object is placed on, e.g.
+	// size(values) == targets && values.all(v, v == 'Complete').
+	//
+	// +optional
+	Expression string `json:"expression,omitempty"`
+}
+
+// AggregationStrategy aggregates the values of a status field.
+type AggregationStrategy string
+
+const (
+	// AggregationSum sums the values.
+	AggregationSum AggregationStrategy = "Sum"
+	// AggregationMin takes the smallest value.
+	AggregationMin AggregationStrategy = "Min"
+	// AggregationMax takes the largest value.
+	AggregationMax AggregationStrategy = "Max"
+	// AggregationAllTrue is true if the value is true on all SyncTargets.
+	AggregationAllTrue AggregationStrategy = "AllTrue"
+	// AggregationCEL evaluates a CEL expression.
+	AggregationCEL AggregationStrategy = "CEL"
+)
+
+// StatusAggregationPolicyList is a list of StatusAggregationPolicy resources.
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type StatusAggregationPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []StatusAggregationPolicy `json:"items"`
+}
+
+const (
+	// AnnotationPrefixTargetStatus prefixes the annotations in which the
+	// syncer of each SyncTarget an object is placed on reports the status
+	// of its copy of the object, as JSON. The status aggregation controller
+	// aggregates them into the status of the object.
+	AnnotationPrefixTargetStatus = "status.workload.kcp.io/"
+)
@@ -0,0 +1,145 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SyncConfiguration configures how the syncers of the SyncTargets of a
+// workspace sync one resource, e.g. whether status is written back to kcp
+// or how often objects are resynced. Syncers watch SyncConfigurations and
+// apply changes without restarting.
+//
+// Resources not configured by a SyncConfiguration keep the configuration
+// of the syncer. When several SyncConfigurations select the same resource
+// and SyncTarget, they are applied in the order of their names, fields set
+// by later ones overriding earlier ones.
+//
+// +crd
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:scope=Cluster,categories=kcp
+// +kubebuilder:printcolumn:name="Group",type="string",JSONPath=`.spec.group`
+// +kubebuilder:printcolumn:name="Resource",type="string",JSONPath=`.spec.resource`
+// +kubebuilder:printcolumn:name="Direction",type="string",JSONPath=`.spec.direction`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type SyncConfiguration struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the desired state.
+	// +optional
+	Spec SyncConfigurationSpec `json:"spec,omitempty"`
+}
+
+// SyncConfigurationSpec holds the desired state of the SyncConfiguration.
+type SyncConfigurationSpec struct {
+	// Group is the API group of the configured resource, empty for the core
+	// group.
+	//
+	// +optional
+	Group string `json:"group"`
+
+	// Resource is the configured resource, e.g. deployments. All versions of
+	// the resource are configured.
+	//
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Resource string `json:"resource"`
+
+	// SyncTargetSelector selects the SyncTargets whose syncers are
+	// configured by their labels. Defaults to all SyncTargets.
+	//
+	// +optional
+	SyncTargetSelector *metav1.LabelSelector `json:"syncTargetSelector,omitempty"`
+
+	// Direction is the direction objects of the resource are synced in.
+	//
+	// +optional
+	// +kubebuilder:default=Down
+	Direction SyncDirection `json:"direction,omitempty"`
+
+	// ResyncInterval is how often synced objects are reconciled even if
+	// they did not change. Defaults to the resync interval of the syncer.
+	//
+	// +optional
+	ResyncInterval *metav1.Duration `json:"resyncInterval,omitempty"`
+
+	// ConflictStrategy decides what happens to fields of downstream objects
+	// changed by others than the syncer.
+	//
+	// +optional
+	// +kubebuilder:default=Overwrite
+	ConflictStrategy ConflictStrategy `json:"conflictStrategy,omitempty"`
+
+	// TransformationProfile names the transformation policy of the syncer
+	// applied to objects of the resource before they are synced.
+	//
+	// +optional
+	TransformationProfile string `json:"transformationProfile,omitempty"`
+
+	// DeletionPropagation is the propagation policy downstream objects are
+	// deleted with when they are no longer synced.
+	//
+	// +optional
+	// +kubebuilder:default=Background
+	// +kubebuilder:validation:Enum=Orphan;Background;Foreground
+	DeletionPropagation metav1.DeletionPropagation `json:"deletionPropagation,omitempty"`
+}
+
+// SyncDirection is the direction objects are synced in.
+//
+// +kubebuilder:validation:Enum=Down;Bidirectional
+type SyncDirection string
+
+const (
+	// SyncDirectionDown syncs objects from kcp to the physical clusters
+	// only. Their status is not written back.
+	SyncDirectionDown SyncDirection = "Down"
+	// SyncDirectionBidirectional syncs objects to the physical clusters and
+	// writes their status back to kcp.
+	SyncDirectionBidirectional SyncDirection = "Bidirectional"
+)
+
+// ConflictStrategy decides between the syncer and other writers of the
+// same fields of downstream objects.
+//
+// +kubebuilder:validation:Enum=Overwrite;Preserve
+type ConflictStrategy string
+
+const (
+	// ConflictStrategyOverwrite takes the fields back from other writers,
+	// kcp wins.
+	ConflictStrategyOverwrite ConflictStrategy = "Overwrite"
+	// ConflictStrategyPreserve leaves fields owned by other writers alone.
+	// The objects are reported as conflicting until the fields are given
+	// back.
+	ConflictStrategyPreserve ConflictStrategy = "Preserve"
+)
+
+// SyncConfigurationList is a list of SyncConfiguration resources.
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type SyncConfigurationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []SyncConfiguration `json:"items"`
+}
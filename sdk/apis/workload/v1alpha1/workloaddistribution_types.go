@@ -0,0 +1,599 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+)
+
+// WorkloadDistribution distributes a workload in its namespace onto
+// SyncTargets according to a PlacementPolicy.
+//
+// +crd
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,categories=kcp
+// +kubebuilder:printcolumn:name="Kind",type="string",JSONPath=`.spec.workloadRef.kind`
+// +kubebuilder:printcolumn:name="Workload",type="string",JSONPath=`.spec.workloadRef.name`
+// +kubebuilder:printcolumn:name="Policy",type="string",JSONPath=`.spec.policyRef.name`
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type WorkloadDistribution struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the desired state.
+	// +optional
+	Spec WorkloadDistributionSpec `json:"spec,omitempty"`
+
+	// Status communicates the observed state.
+	// +optional
+	Status WorkloadDistributionStatus `json:"status,omitempty"`
+}
+
+// WorkloadDistributionSpec holds the desired state of the WorkloadDistribution.
+type WorkloadDistributionSpec struct {
+	// WorkloadRef references the distributed workload in the same namespace.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	WorkloadRef WorkloadReference `json:"workloadRef"`
+
+	// PolicyRef references the PlacementPolicy in the same workspace.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	PolicyRef PolicyReference `json:"policyRef"`
+
+	// DependsOn lists WorkloadDistributions in the same namespace that must
+	// be ready before this workload is placed, e.g. a database before the
+	// application using it.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	DependsOn []DistributionDependency `json:"dependsOn,omitempty"`
+
+	// DependencyTimeout is how long to wait for dependencies before
+	// reporting a failure. Placement still proceeds once they are ready.
+	//
+	// +optional
+	DependencyTimeout *metav1.Duration `json:"dependencyTimeout,omitempty"`
+
+	// Closure opts into exporting the objects the workload references along
+	// with it, e.g. the ConfigMaps, Secrets and ServiceAccount of a
+	// Deployment.
+	//
+	// +optional
+	Closure *ExportClosure `json:"closure,omitempty"`
+
+	// TargetOverrides move the workload off SyncTargets it is placed on to
+	// other SyncTargets, e.g. from accepted RightPlacementRecommendations.
+	// The From targets are not eligible for placement, and the To targets
+	// are preferred like current ones if they are eligible.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=from
+	TargetOverrides []TargetOverride `json:"targetOverrides,omitempty"`
+
+	// Paused holds the workload on the policy revision it is placed with
+	// while its policy rolls out new revisions. The workload takes part in
+	// rollouts again once it is resumed.
+	//
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// PrePull makes the syncers pull the images of the workload onto the
+	// nodes of their physical clusters before the workload counts as ready
+	// in canary stages, so that large images do not delay its start.
+	//
+	// +optional
+	PrePull *ImagePrePull `json:"prePull,omitempty"`
+
+	// RolloutStrategy syncs the workload onto the SyncTargets it is placed
+	// on in waves, each gated on the health of the previous one, rather than
+	// onto all of them at once. Without it, the workload is synced to all
+	// its targets as soon as it is placed.
+	//
+	// +optional
+	RolloutStrategy *RolloutStrategy `json:"rolloutStrategy,omitempty"`
+}
+
+// RolloutStrategy configures how a workload is rolled out onto new
+// SyncTargets.
+type RolloutStrategy struct {
+	// Type is Canary to sync onto a few targets first and onto the rest
+	// once they are healthy, BlueGreen to sync onto all new targets at once
+	// while the workload keeps running on the targets it moves off until
+	// the new ones are healthy, or Progressive to sync onto waves of
+	// waveSize targets.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=Canary;BlueGreen;Progressive
+	Type RolloutStrategyType `json:"type"`
+
+	// CanaryTargets is the number of targets of the first wave of a Canary
+	// rollout. Defaults to 1.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	CanaryTargets *int32 `json:"canaryTargets,omitempty"`
+
+	// WaveSize is the number of targets of every wave of a Progressive
+	// rollout. Defaults to 1.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	WaveSize *int32 `json:"waveSize,omitempty"`
+
+	// AnalysisPeriod is how long all targets of a wave must stay healthy
+	// before the next wave starts. Defaults to 5 minutes.
+	//
+	// +optional
+	AnalysisPeriod *metav1.Duration `json:"analysisPeriod,omitempty"`
+
+	// ProgressDeadline is how long the targets of a wave may take to become
+	// healthy. Targets still not healthy after it count as failed. Defaults
+	// to 10 minutes.
+	//
+	// +optional
+	ProgressDeadline *metav1.Duration `json:"progressDeadline,omitempty"`
+
+	// MaxFailedTargets is the number of failed targets a wave tolerates.
+	// A wave with more failed targets is rolled back: the workload is no
+	// longer synced to its targets and the rollout stops until the workload
+	// or its targets change.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxFailedTargets int32 `json:"maxFailedTargets,omitempty"`
+}
+
+// RolloutStrategyType is a way to roll a workload out onto SyncTargets.
+type RolloutStrategyType string
+
+const (
+	// RolloutCanary syncs onto canary targets first, then onto the rest.
+	RolloutCanary RolloutStrategyType = "Canary"
+	// RolloutBlueGreen syncs onto all new targets at once and keeps the
+	// old ones until the new ones are healthy.
+	RolloutBlueGreen RolloutStrategyType = "BlueGreen"
+	// RolloutProgressive syncs onto waves of a fixed number of targets.
+	RolloutProgressive RolloutStrategyType = "Progressive"
+)
+
+// ImagePrePull configures pulling the images of a workload ahead of time.
+type ImagePrePull struct {
+	// Timeout bounds pulling the images. After it passed, pre-pulling is
+	// considered failed. Defaults to 10 minutes.
+	//
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// TargetOverride moves a workload from one SyncTarget to another.
+type TargetOverride struct {
+	// From is the SyncTarget the workload is moved off.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	From string `json:"from"`
+
+	// To is the SyncTarget the workload is moved to.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	To string `json:"to"`
+}
+
+// ExportClosure configures which referenced objects are exported with a
+// workload.
+type ExportClosure struct {
+	// Mode is None to export only the workload, or Transitive to also
+	// export the objects it references, the objects those reference, and so
+	// on. Referenced objects that do not exist are skipped.
+	//
+	// +optional
+	// +kubebuilder:default=None
+	// +kubebuilder:validation:Enum=None;Transitive
+	Mode ClosureMode `json:"mode,omitempty"`
+
+	// References are reference fields in addition to the built-in ones of
+	// pods, workload controllers and ServiceAccounts, e.g. of custom
+	// resources.
+	//
+	// +optional
+	// +listType=atomic
+	References []ReferenceField `json:"references,omitempty"`
+}
+
+// ClosureMode selects the referenced objects exported with a workload.
+type ClosureMode string
+
+const (
+	// ClosureModeNone exports only the workload.
+	ClosureModeNone ClosureMode = "None"
+	// ClosureModeTransitive exports the transitive closure of the objects
+	// referenced by the workload.
+	ClosureModeTransitive ClosureMode = "Transitive"
+)
+
+// ReferenceField declares a field of a kind that references objects of
+// another kind in the same namespace by name.
+type ReferenceField struct {
+	// Group of the referencing kind. Empty for the core group.
+	//
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// Kind of the referencing objects.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Kind string `json:"kind"`
+
+	// Path to the referenced names as dot-separated fields, where a field
+	// suffixed with [] iterates over a list, e.g.
+	// spec.template.spec.volumes[].secret.secretName.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Path string `json:"path"`
+
+	// TargetGroup is the group of the referenced kind. Empty for the core
+	// group.
+	//
+	// +optional
+	TargetGroup string `json:"targetGroup,omitempty"`
+
+	// TargetKind is the referenced kind.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	TargetKind string `json:"targetKind"`
+}
+
+// DistributionDependency is a dependency on another WorkloadDistribution.
+type DistributionDependency struct {
+	// Name of the WorkloadDistribution depended on.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Colocation restricts the SyncTargets this workload may be placed on
+	// relative to the targets of the dependency.
+	//
+	// +optional
+	// +kubebuilder:default=SameTarget
+	// +kubebuilder:validation:Enum=SameTarget;SameLocation;Any
+	Colocation DependencyColocation `json:"colocation,omitempty"`
+}
+
+// DependencyColocation is how a dependent workload is colocated with its
+// dependency.
+type DependencyColocation string
+
+const (
+	// ColocationSameTarget places only on SyncTargets the dependency is
+	// placed on.
+	ColocationSameTarget DependencyColocation = "SameTarget"
+	// ColocationSameLocation places only in locations the dependency is
+	// placed in.
+	ColocationSameLocation DependencyColocation = "SameLocation"
+	// ColocationAny only requires the dependency to be ready.
+	ColocationAny DependencyColocation = "Any"
+)
+
+// WorkloadReference references a namespaced object in the same namespace.
+type WorkloadReference struct {
+	// APIVersion of the workload, e.g. apps/v1.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the workload, e.g. Deployment.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Kind string `json:"kind"`
+
+	// Name of the workload.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// PolicyReference references a PlacementPolicy by name.
+type PolicyReference struct {
+	// Kind is PlacementPolicy, or WorkloadPlacementAdvanced for a composite
+	// policy placing workloads by rules. Defaults to PlacementPolicy.
+	//
+	// +optional
+	// +kubebuilder:validation:Enum=PlacementPolicy;WorkloadPlacementAdvanced
+	Kind string `json:"kind,omitempty"`
+
+	// Name of the policy.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+const (
+	// PlacementPolicyKind is the kind of PlacementPolicy references.
+	PlacementPolicyKind = "PlacementPolicy"
+	// WorkloadPlacementAdvancedKind is the kind of WorkloadPlacementAdvanced
+	// references.
+	WorkloadPlacementAdvancedKind = "WorkloadPlacementAdvanced"
+)
+
+// IsAdvanced returns whether the reference is to a WorkloadPlacementAdvanced.
+func (r PolicyReference) IsAdvanced() bool {
+	return r.Kind == WorkloadPlacementAdvancedKind
+}
+
+// WorkloadDistributionStatus communicates the observed state of the WorkloadDistribution.
+type WorkloadDistributionStatus struct {
+	// PolicyRevision is the PlacementPolicyRevision the current targets
+	// were chosen with.
+	// +optional
+	PolicyRevision string `json:"policyRevision,omitempty"`
+
+	// Targets are the SyncTargets the workload is placed on.
+	// +optional
+	// +listType=map
+	// +listMapKey=syncTarget
+	Targets []TargetPlacement `json:"targets,omitempty"`
+
+	// DisplacedTargets are SyncTargets the workload was moved off because
+	// of their disruption window. The workload moves back once the window
+	// has passed.
+	// +optional
+	// +listType=set
+	DisplacedTargets []string `json:"displacedTargets,omitempty"`
+
+	// LastValidatedTime is when the targets were last chosen or
+	// revalidated, if the PlacementPolicy has a decision TTL.
+	// +optional
+	LastValidatedTime *metav1.Time `json:"lastValidatedTime,omitempty"`
+
+	// Rollout is the progress of rolling the workload out onto its targets,
+	// for distributions with spec.rolloutStrategy.
+	// +optional
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+
+	// Current processing state of the WorkloadDistribution.
+	// +optional
+	Conditions conditionsv1alpha1.Conditions `json:"conditions,omitempty"`
+}
+
+// TargetPlacement is the placement of a workload on one SyncTarget.
+type TargetPlacement struct {
+	// SyncTarget is the name of the SyncTarget.
+	SyncTarget string `json:"syncTarget"`
+
+	// Location of the SyncTarget.
+	// +optional
+	Location string `json:"location,omitempty"`
+
+	// Replicas placed on the SyncTarget, for scalable workloads.
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+}
+
+// RolloutStatus is the progress of a rollout.
+type RolloutStatus struct {
+	// Phase is Progressing while waves are rolled out, Complete once the
+	// workload is synced to all its targets, and RolledBack after a wave
+	// failed.
+	Phase RolloutPhase `json:"phase"`
+
+	// ObservedWorkloadGeneration is the generation of the workload the
+	// rollout started with. A rolled back rollout starts again once the
+	// workload changes.
+	// +optional
+	ObservedWorkloadGeneration int64 `json:"observedWorkloadGeneration,omitempty"`
+
+	// ObservedTargets are the targets of status.targets the rollout started
+	// with. A rolled back rollout starts again once they change.
+	// +optional
+	// +listType=atomic
+	ObservedTargets []string `json:"observedTargets,omitempty"`
+
+	// SyncedTargets are the targets the workload is synced to. Targets of
+	// status.targets not listed wait for their wave.
+	// +optional
+	// +listType=set
+	SyncedTargets []string `json:"syncedTargets,omitempty"`
+
+	// RetiringTargets are targets the workload moved off that it stays
+	// synced to until the new targets are healthy, in BlueGreen rollouts.
+	// +optional
+	// +listType=set
+	RetiringTargets []string `json:"retiringTargets,omitempty"`
+
+	// Wave is the number of the current wave, starting at 1.
+	// +optional
+	Wave int32 `json:"wave,omitempty"`
+
+	// WaveTargets are the targets of the current wave.
+	// +optional
+	// +listType=set
+	WaveTargets []string `json:"waveTargets,omitempty"`
+
+	// WaveStartTime is when the current wave started.
+	// +optional
+	WaveStartTime *metav1.Time `json:"waveStartTime,omitempty"`
+
+	// HealthySince is since when all targets of the current wave are
+	// healthy.
+	// +optional
+	HealthySince *metav1.Time `json:"healthySince,omitempty"`
+
+	// FailedTargets are the failed targets of the wave that was rolled
+	// back.
+	// +optional
+	// +listType=set
+	FailedTargets []string `json:"failedTargets,omitempty"`
+
+	// Message describes the phase.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// RolloutPhase is the phase of a rollout.
+type RolloutPhase string
+
+const (
+	// RolloutProgressing means waves are being rolled out.
+	RolloutProgressing RolloutPhase = "Progressing"
+	// RolloutComplete means the workload is synced to all its targets.
+	RolloutComplete RolloutPhase = "Complete"
+	// RolloutRolledBack means a wave failed and was rolled back.
+	RolloutRolledBack RolloutPhase = "RolledBack"
+)
+
+// SyncedTargets returns the SyncTargets the workload is synced to: its
+// targets, unless a rollout holds some of them back or keeps retiring ones.
+func (in *WorkloadDistribution) SyncedTargets() []string {
+	if in.Spec.RolloutStrategy == nil || in.Status.Rollout == nil {
+		targets := make([]string, 0, len(in.Status.Targets))
+		for _, target := range in.Status.Targets {
+			targets = append(targets, target.SyncTarget)
+		}
+		return targets
+	}
+	targets := make([]string, 0, len(in.Status.Rollout.SyncedTargets)+len(in.Status.Rollout.RetiringTargets))
+	targets = append(targets, in.Status.Rollout.SyncedTargets...)
+	return append(targets, in.Status.Rollout.RetiringTargets...)
+}
+
+// WorkloadDistributionList is a list of WorkloadDistribution resources.
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type WorkloadDistributionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []WorkloadDistribution `json:"items"`
+}
+
+const (
+	// AnnotationPolicyRevision pins the PlacementPolicyRevision a
+	// distribution is placed with. It is managed by the policy rollout
+	// controller; placement uses the policy's current revision if unset.
+	AnnotationPolicyRevision = "placement.kcp.io/policy-revision"
+
+	// AnnotationSyncPriority assigns a workload object the sync priority
+	// class critical, standard or background. After a syncer reconnects,
+	// critical objects are synced first.
+	AnnotationSyncPriority = "workload.kcp.io/sync-priority"
+
+	// AnnotationConfigFanOut marks a ConfigMap as rendered per SyncTarget
+	// before it is synced. The value "true" renders all data keys, any other
+	// value is a comma-separated list of the keys to render.
+	AnnotationConfigFanOut = "workload.kcp.io/config-fanout"
+
+	// AnnotationSensitive marks an object as sensitive with the value
+	// "true". The syncer keeps sensitive objects encrypted until it applies
+	// them, and audits every decryption.
+	AnnotationSensitive = "workload.kcp.io/sensitive"
+)
+
+// Conditions and ConditionReasons for the WorkloadDistribution object.
+const (
+	// WorkloadPlaced means SyncTargets were chosen for the workload.
+	WorkloadPlaced conditionsv1alpha1.ConditionType = "Placed"
+
+	// WorkloadReady means the workload is ready on all its SyncTargets.
+	WorkloadReady conditionsv1alpha1.ConditionType = "Ready"
+
+	// DependenciesReady means all distributions in spec.dependsOn are ready.
+	DependenciesReady conditionsv1alpha1.ConditionType = "DependenciesReady"
+
+	// PlacementFrozen is true while placement is frozen by the SchedulingProfile of the workspace.
+	PlacementFrozen conditionsv1alpha1.ConditionType = "PlacementFrozen"
+
+	// DecisionValid reports the outcome of the last revalidation of the placement decision, for
+	// PlacementPolicies with a decision TTL.
+	DecisionValid conditionsv1alpha1.ConditionType = "DecisionValid"
+
+	// ImagesPrePulled means the images of the workload are present on the nodes of its SyncTargets,
+	// for distributions with spec.prePull.
+	ImagesPrePulled conditionsv1alpha1.ConditionType = "ImagesPrePulled"
+
+	// NoFeasibleTargetsReason indicates no SyncTarget satisfies the policy.
+	NoFeasibleTargetsReason = "NoFeasibleTargets"
+	// PolicyNotFoundReason indicates the referenced policy does not exist.
+	PolicyNotFoundReason = "PolicyNotFound"
+	// NoMatchingRuleReason indicates no rule of the referenced
+	// WorkloadPlacementAdvanced selects the workload.
+	NoMatchingRuleReason = "NoMatchingRule"
+	// SyncTargetGroupNotFoundReason indicates the SyncTargetGroup of the PlacementPolicy does not exist.
+	SyncTargetGroupNotFoundReason = "SyncTargetGroupNotFound"
+	// WaitingForDependenciesReason indicates dependencies are not ready yet.
+	WaitingForDependenciesReason = "WaitingForDependencies"
+	// DependencyNotFoundReason indicates a dependency does not exist.
+	DependencyNotFoundReason = "DependencyNotFound"
+	// DependencyCycleReason indicates the dependencies form a cycle.
+	DependencyCycleReason = "DependencyCycle"
+	// DependencyTimeoutReason indicates dependencies were not ready within
+	// spec.dependencyTimeout.
+	DependencyTimeoutReason = "DependencyTimeout"
+	// FrozenReason indicates placement is frozen by the SchedulingProfile of the workspace.
+	FrozenReason = "Frozen"
+	// DecisionInvalidReason indicates that revalidation found targets that are no longer feasible,
+	// and the workload was placed anew.
+	DecisionInvalidReason = "DecisionInvalid"
+	// PrePullInProgressReason indicates the images are still being pulled.
+	PrePullInProgressReason = "PrePullInProgress"
+	// PrePullFailedReason indicates images could not be pulled within spec.prePull.timeout.
+	PrePullFailedReason = "PrePullFailed"
+	// QuotaExceededReason indicates placing onto more SyncTargets would exceed a
+	// MultiClusterResourceQuota of the workspace.
+	QuotaExceededReason = "QuotaExceeded"
+	// WaitingForResourceUsageReason indicates the resource usage of the workload is not
+	// recorded yet, while the workspace has MultiClusterResourceQuotas.
+	WaitingForResourceUsageReason = "WaitingForResourceUsage"
+)
+
+func (in *WorkloadDistribution) SetConditions(c conditionsv1alpha1.Conditions) {
+	in.Status.Conditions = c
+}
+
+func (in *WorkloadDistribution) GetConditions() conditionsv1alpha1.Conditions {
+	return in.Status.Conditions
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WorkloadPriorityClass is a priority class of a workspace. Pods referencing
+// it by priorityClassName are synced with the equivalent PriorityClass of the
+// physical cluster, so that preemption downstream follows the priorities
+// chosen in the workspace.
+//
+// Values above 1000000000 and names starting with "system-" are reserved for
+// system components and cannot be used.
+//
+// +crd
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:scope=Cluster,categories=kcp
+// +kubebuilder:printcolumn:name="Value",type="integer",JSONPath=`.spec.value`
+// +kubebuilder:printcolumn:name="Downstream",type="string",JSONPath=`.spec.downstreamName`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type WorkloadPriorityClass struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the desired state.
+	// +optional
+	Spec WorkloadPriorityClassSpec `json:"spec,omitempty"`
+}
+
+// WorkloadPriorityClassSpec holds the desired state of the WorkloadPriorityClass.
+type WorkloadPriorityClassSpec struct {
+	// Value is the priority of pods using this class.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Maximum=1000000000
+	Value int32 `json:"value"`
+
+	// PreemptionPolicy is the policy for preempting pods with lower
+	// priority. Defaults to PreemptLowerPriority.
+	//
+	// +optional
+	PreemptionPolicy *corev1.PreemptionPolicy `json:"preemptionPolicy,omitempty"`
+
+	// Description is copied to PriorityClasses created downstream.
+	//
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// DownstreamName is the name of the PriorityClass on the physical
+	// clusters. Defaults to the name of the WorkloadPriorityClass.
+	//
+	// +optional
+	DownstreamName string `json:"downstreamName,omitempty"`
+
+	// CreationPolicy controls whether the PriorityClass is created on
+	// physical clusters that do not have it. Defaults to IfAbsent.
+	//
+	// +optional
+	// +kubebuilder:validation:Enum=IfAbsent;Never
+	CreationPolicy PriorityClassCreationPolicy `json:"creationPolicy,omitempty"`
+}
+
+// PriorityClassCreationPolicy controls the creation of downstream
+// PriorityClasses.
+type PriorityClassCreationPolicy string
+
+const (
+	// PriorityClassCreateIfAbsent creates a missing PriorityClass. An
+	// existing PriorityClass of the same name must have the same value.
+	PriorityClassCreateIfAbsent PriorityClassCreationPolicy = "IfAbsent"
+	// PriorityClassCreateNever uses the PriorityClass of the physical
+	// cluster as is, e.g. when it is managed by the cluster administrator.
+	// Pods are not synced to clusters without it.
+	PriorityClassCreateNever PriorityClassCreationPolicy = "Never"
+)
+
+// DownstreamPriorityClassName returns the name of the PriorityClass on
+// physical clusters.
+func (c *WorkloadPriorityClass) DownstreamPriorityClassName() string {
+	if c.Spec.DownstreamName != "" {
+		return c.Spec.DownstreamName
+	}
+	return c.Name
+}
+
+// LabelPriorityClass is set on PriorityClasses created on physical clusters
+// to the name of the WorkloadPriorityClass they were created for.
+const LabelPriorityClass = "workload.kcp.io/priority-class"
+
+// WorkloadPriorityClassList is a list of WorkloadPriorityClass resources.
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type WorkloadPriorityClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []WorkloadPriorityClass `json:"items"`
+}
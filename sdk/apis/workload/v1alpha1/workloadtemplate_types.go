@@ -0,0 +1,165 @@
+/*
+Copyright 2025 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/sdk/apis/third_party/conditions/apis/conditions/v1alpha1"
+)
+
+// WorkloadTemplate stamps out one variant of a workload per location, as
+// explicit objects in the namespace of the template. It is an alternative to
+// transforming a single workload per SyncTarget during sync.
+//
+// Variants are named <template object name>-<location>. String values of the
+// template can reference ${location} and ${values.<key>} of the location.
+//
+// +crd
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,categories=kcp
+// +kubebuilder:printcolumn:name="Generated",type="string",JSONPath=`.status.conditions[?(@.type=="Generated")].status`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type WorkloadTemplate struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the desired state.
+	// +optional
+	Spec WorkloadTemplateSpec `json:"spec,omitempty"`
+
+	// Status communicates the observed state.
+	// +optional
+	Status WorkloadTemplateStatus `json:"status,omitempty"`
+}
+
+// WorkloadTemplateSpec holds the desired state of the WorkloadTemplate.
+type WorkloadTemplateSpec struct {
+	// Template is the namespaced object to stamp out, including apiVersion,
+	// kind and metadata.name. The namespace is always the namespace of the
+	// WorkloadTemplate.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:EmbeddedResource
+	Template runtime.RawExtension `json:"template"`
+
+	// LocationSelector selects the SyncTargets by their labels whose
+	// locations a variant is generated for. An empty selector selects all
+	// SyncTargets.
+	//
+	// +optional
+	LocationSelector *metav1.LabelSelector `json:"locationSelector,omitempty"`
+
+	// Locations holds configuration injected into the variant of a location.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=location
+	Locations []LocationConfig `json:"locations,omitempty"`
+}
+
+// LocationConfig is the configuration of one location's variant.
+type LocationConfig struct {
+	// Location is the location of SyncTargets, as in spec.location.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Location string `json:"location"`
+
+	// Labels are added to the variant.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are added to the variant.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Values are substituted for ${values.<key>} in string values of the
+	// template.
+	// +optional
+	Values map[string]string `json:"values,omitempty"`
+}
+
+// WorkloadTemplateStatus communicates the observed state of the WorkloadTemplate.
+type WorkloadTemplateStatus struct {
+	// ObservedGeneration is the generation the variants were generated from.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Variants are the generated objects.
+	// +optional
+	// +listType=map
+	// +listMapKey=location
+	Variants []TemplateVariant `json:"variants,omitempty"`
+
+	// Current processing state of the WorkloadTemplate.
+	// +optional
+	Conditions conditionsv1alpha1.Conditions `json:"conditions,omitempty"`
+}
+
+// TemplateVariant is an object generated for a location.
+type TemplateVariant struct {
+	// Location the variant was generated for.
+	Location string `json:"location"`
+
+	// Name of the generated object.
+	Name string `json:"name"`
+}
+
+// WorkloadTemplateList is a list of WorkloadTemplate resources.
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type WorkloadTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []WorkloadTemplate `json:"items"`
+}
+
+const (
+	// LabelTemplate is set on generated variants to the name of their
+	// WorkloadTemplate.
+	LabelTemplate = "workload.kcp.io/template"
+	// LabelLocation is set on generated variants to their location.
+	LabelLocation = "workload.kcp.io/location"
+)
+
+// Conditions and ConditionReasons for the WorkloadTemplate object.
+const (
+	// TemplateGenerated means all variants are up to date.
+	TemplateGenerated conditionsv1alpha1.ConditionType = "Generated"
+
+	// InvalidTemplateReason indicates the template cannot be rendered.
+	InvalidTemplateReason = "InvalidTemplate"
+	// GenerationFailedReason indicates variants could not be written.
+	GenerationFailedReason = "GenerationFailed"
+)
+
+func (in *WorkloadTemplate) SetConditions(c conditionsv1alpha1.Conditions) {
+	in.Status.Conditions = c
+}
+
+func (in *WorkloadTemplate) GetConditions() conditionsv1alpha1.Conditions {
+	return in.Status.Conditions
+}